@@ -0,0 +1,170 @@
+// Command orgbackup dumps a single organization into a portable archive and restores
+// such an archive into another database, for moving an organization between
+// environments. The database and the secrets keys are taken from the same config file
+// and environment variables as the backend.
+//
+//	orgbackup dump -slug sdc -out sdc.json
+//	orgbackup restore -in sdc.json -slug sdc -owner admin@example.com
+package main
+
+import (
+	"NYCU-SDC/core-system-backend/internal/backup"
+	"NYCU-SDC/core-system-backend/internal/config"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/secrets"
+	"NYCU-SDC/core-system-backend/internal/tenant"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const usage = `usage:
+  orgbackup dump -slug SLUG [-out FILE]
+  orgbackup restore -in FILE -slug SLUG -owner EMAIL`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command, args := os.Args[1], os.Args[2:]
+
+	// config.Load parses the global flags, which know nothing of the subcommands
+	os.Args = os.Args[:1]
+
+	cfg, _ := config.Load()
+	if cfg.DatabaseURL == "" {
+		fmt.Fprintln(os.Stderr, "DATABASE_URL is required")
+		os.Exit(1)
+	}
+
+	logger, err := logutil.ZapDevelopmentConfig().Build()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	ctx := context.Background()
+	dbPool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		logger.Fatal("Failed to initialize database pool", zap.Error(err))
+	}
+	defer dbPool.Close()
+
+	// Workflows are dumped with their webhook URLs opened, and sealed again on restore
+	keyring, err := secrets.NewKeyring(cfg.Secrets)
+	if errors.Is(err, secrets.ErrNoKeys) && cfg.Dev {
+		keyring, err = secrets.NewDevKeyring(cfg.Secret), nil
+	}
+	if err != nil {
+		logger.Fatal("Failed to load secrets keys", zap.Error(err))
+	}
+
+	tenantService := tenant.NewService(logger, dbPool)
+	questionService := question.NewService(logger, dbPool)
+	responseService := response.NewService(logger, dbPool)
+	formService := form.NewService(logger, dbPool, responseService)
+	workflowService := workflow.NewService(logger, dbPool, questionService, keyring)
+	importerService := importer.NewService(logger, dbPool, formService, workflowService, questionService)
+	backupService := backup.NewService(logger, dbPool, tenantService, importerService, keyring)
+
+	switch command {
+	case "dump":
+		err = dump(ctx, backupService, tenantService, args)
+	case "restore":
+		err = restore(ctx, backupService, args)
+	default:
+		err = fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func dump(ctx context.Context, service *backup.Service, tenantService *tenant.Service, args []string) error {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	slug := flags.String("slug", "", "slug of the organization to dump")
+	out := flags.String("out", "", "file to write the archive to, stdout if empty")
+	_ = flags.Parse(args)
+
+	if *slug == "" {
+		return errors.New("-slug is required")
+	}
+
+	_, orgID, err := tenantService.GetSlugStatus(ctx, *slug)
+	if err != nil {
+		return fmt.Errorf("failed to find organization %s: %w", *slug, err)
+	}
+
+	archive, err := service.Dump(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive: %w", err)
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+
+	return os.WriteFile(*out, data, 0o600)
+}
+
+func restore(ctx context.Context, service *backup.Service, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := flags.String("in", "", "archive to restore")
+	slug := flags.String("slug", "", "slug of the restored organization")
+	owner := flags.String("owner", "", "email of the user who will own the restored organization")
+	_ = flags.Parse(args)
+
+	if *in == "" || *slug == "" || *owner == "" {
+		return errors.New("-in, -slug and -owner are required")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return err
+	}
+
+	var archive backup.Archive
+	err = json.Unmarshal(data, &archive)
+	if err != nil {
+		return fmt.Errorf("failed to decode archive: %w", err)
+	}
+
+	ownerID, err := service.GetUserIDByEmail(ctx, *owner)
+	if err != nil {
+		return err
+	}
+
+	result, err := service.Restore(ctx, archive, *slug, ownerID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored organization %s (%s): %d units, %d members, %d forms\n", result.Slug, result.OrgID, result.Units, result.Members, result.Forms)
+	for _, email := range result.SkippedMembers {
+		fmt.Printf("skipped member %s: no user with this email\n", email)
+	}
+
+	return nil
+}
@@ -0,0 +1,490 @@
+package announcement
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	pagutil "github.com/NYCU-SDC/summer/pkg/pagination"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Create(ctx context.Context, orgID uuid.UUID, unitID *uuid.UUID, input Input, userID uuid.UUID) (Announcement, error)
+	List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, filter Filter, page int, size int) ([]Announcement, int64, error)
+	Get(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Announcement, error)
+	Update(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input Input, userID uuid.UUID) (Announcement, error)
+	Delete(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error
+	Engagement(ctx context.Context, ids []uuid.UUID, userID uuid.UUID) (map[uuid.UUID]Engagement, error)
+	MarkRead(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error
+	React(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID, emoji string) ([]ReactionCount, error)
+	Unreact(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID, emoji string) ([]ReactionCount, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+// Request is the content of an announcement; Body is markdown, rendered by the client.
+// Visibility defaults to the whole organization.
+type Request struct {
+	Title      string `json:"title" validate:"required,max=255"`
+	Body       string `json:"body" validate:"max=50000"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=organization unit"`
+	Pinned     bool   `json:"pinned"`
+}
+
+type ReactionCountResponse struct {
+	Emoji   string `json:"emoji"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+// Response carries the engagement of the announcement: Reads counts the members who
+// read it and Read tells whether the current user did
+type Response struct {
+	ID         string                  `json:"id"`
+	UnitID     *string                 `json:"unitId,omitempty"`
+	Title      string                  `json:"title"`
+	Body       string                  `json:"body"`
+	Visibility string                  `json:"visibility"`
+	Pinned     bool                    `json:"pinned"`
+	PinnedAt   *time.Time              `json:"pinnedAt,omitempty"`
+	Reads      int64                   `json:"reads"`
+	Read       bool                    `json:"read"`
+	Reactions  []ReactionCountResponse `json:"reactions"`
+	CreatedBy  *string                 `json:"createdBy,omitempty"`
+	UpdatedBy  *string                 `json:"updatedBy,omitempty"`
+	CreatedAt  time.Time               `json:"createdAt"`
+	UpdatedAt  time.Time               `json:"updatedAt"`
+}
+
+func idString(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	value := uuid.UUID(id.Bytes).String()
+	return &value
+}
+
+func ToReactionResponses(reactions []ReactionCount) []ReactionCountResponse {
+	response := make([]ReactionCountResponse, len(reactions))
+	for i, reaction := range reactions {
+		response[i] = ReactionCountResponse{
+			Emoji:   reaction.Emoji,
+			Count:   reaction.Count,
+			Reacted: reaction.Reacted,
+		}
+	}
+	return response
+}
+
+func ToResponse(announcement Announcement, engagement Engagement) Response {
+	response := Response{
+		ID:         announcement.ID.String(),
+		UnitID:     idString(announcement.UnitID),
+		Title:      announcement.Title,
+		Body:       announcement.Body,
+		Visibility: string(announcement.Visibility),
+		Pinned:     announcement.PinnedAt.Valid,
+		Reads:      engagement.Reads,
+		Read:       engagement.Read,
+		Reactions:  ToReactionResponses(engagement.Reactions),
+		CreatedBy:  idString(announcement.CreatedBy),
+		UpdatedBy:  idString(announcement.UpdatedBy),
+		CreatedAt:  announcement.CreatedAt.Time,
+		UpdatedAt:  announcement.UpdatedAt.Time,
+	}
+	if announcement.PinnedAt.Valid {
+		response.PinnedAt = &announcement.PinnedAt.Time
+	}
+	return response
+}
+
+func (r Request) ToInput() Input {
+	visibility := AnnouncementVisibilityOrganization
+	if r.Visibility != "" {
+		visibility = AnnouncementVisibility(r.Visibility)
+	}
+
+	return Input{
+		Title:      strings.TrimSpace(r.Title),
+		Body:       r.Body,
+		Visibility: visibility,
+		Pinned:     r.Pinned,
+	}
+}
+
+// ParseFilter reads ?board=organization, ?unitId=, ?pinned= and ?search= from the query
+func ParseFilter(r *http.Request) (Filter, error) {
+	query := r.URL.Query()
+	filter := Filter{Search: strings.TrimSpace(query.Get("search"))}
+
+	switch query.Get("board") {
+	case "":
+	case "organization":
+		filter.OrgBoard = true
+	default:
+		return Filter{}, fmt.Errorf("%w: board must be organization", internal.ErrInvalidQueryParameter)
+	}
+
+	if value := query.Get("unitId"); value != "" {
+		unitID, err := uuid.Parse(value)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%w: unitId %q is not a UUID", internal.ErrInvalidQueryParameter, value)
+		}
+		filter.UnitID = &unitID
+	}
+
+	if value := query.Get("pinned"); value != "" {
+		pinned, err := strconv.ParseBool(value)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%w: pinned must be true or false", internal.ErrInvalidQueryParameter)
+		}
+		filter.Pinned = &pinned
+	}
+
+	return filter, nil
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("announcement/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// target is the board a request is about: the organization of the slug, with the
+// current user
+type target struct {
+	orgID  uuid.UUID
+	userID uuid.UUID
+}
+
+func (h *Handler) target(ctx context.Context) (target, error) {
+	currentUser, ok := user.GetFromContext(ctx)
+	if !ok {
+		return target{}, internal.ErrNoUserInContext
+	}
+
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return target{}, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return target{}, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return target{orgID: orgID, userID: currentUser.ID}, nil
+}
+
+// CreateHandler posts to the board of the organization
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	h.create(w, r, "CreateHandler", false)
+}
+
+// CreateUnitHandler posts to the board of the unit in the path
+func (h *Handler) CreateUnitHandler(w http.ResponseWriter, r *http.Request) {
+	h.create(w, r, "CreateUnitHandler", true)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request, name string, onUnit bool) {
+	traceCtx, span := h.tracer.Start(r.Context(), name)
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var unitID *uuid.UUID
+	if onUnit {
+		id, err := internal.ParseUUID(r.PathValue("id"))
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		unitID = &id
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	announcement, err := h.store.Create(traceCtx, t.orgID, unitID, req.ToInput(), t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(announcement, Engagement{Reactions: []ReactionCount{}}))
+}
+
+// ListHandler lists the board of the organization, with the posts of its units the
+// user can read
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, "ListHandler", false)
+}
+
+// ListUnitHandler lists the board of the unit in the path
+func (h *Handler) ListUnitHandler(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, "ListUnitHandler", true)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, name string, onUnit bool) {
+	traceCtx, span := h.tracer.Start(r.Context(), name)
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	factory := pagutil.NewFactory[Response](200, []string{"CreatedAt"})
+	request, err := factory.GetRequest(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	filter, err := ParseFilter(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+	if onUnit {
+		unitID, err := internal.ParseUUID(r.PathValue("id"))
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		filter.UnitID, filter.OrgBoard = &unitID, false
+	}
+
+	announcements, total, err := h.store.List(traceCtx, t.orgID, t.userID, filter, request.Page, request.Size)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	ids := make([]uuid.UUID, len(announcements))
+	for i, announcement := range announcements {
+		ids[i] = announcement.ID
+	}
+	engagement, err := h.store.Engagement(traceCtx, ids, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]Response, len(announcements))
+	for i, announcement := range announcements {
+		response[i] = ToResponse(announcement, engagement[announcement.ID])
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, factory.NewResponse(response, int(total), request.Page, request.Size))
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	announcement, err := h.store.Get(traceCtx, t.orgID, id, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	engagement, err := h.store.Engagement(traceCtx, []uuid.UUID{id}, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(announcement, engagement[id]))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	announcement, err := h.store.Update(traceCtx, t.orgID, id, req.ToInput(), t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	engagement, err := h.store.Engagement(traceCtx, []uuid.UUID{id}, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(announcement, engagement[id]))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, t.orgID, id, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// MarkReadHandler records that the current user read the announcement
+func (h *Handler) MarkReadHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "MarkReadHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.MarkRead(traceCtx, t.orgID, id, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// ReactHandler adds the reaction in the path to the announcement
+func (h *Handler) ReactHandler(w http.ResponseWriter, r *http.Request) {
+	h.reaction(w, r, "ReactHandler", h.store.React)
+}
+
+// UnreactHandler takes back the reaction in the path
+func (h *Handler) UnreactHandler(w http.ResponseWriter, r *http.Request) {
+	h.reaction(w, r, "UnreactHandler", h.store.Unreact)
+}
+
+// reaction serves both reaction routes, which answer with the reactions of the announcement
+func (h *Handler) reaction(w http.ResponseWriter, r *http.Request, name string, apply func(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID, emoji string) ([]ReactionCount, error)) {
+	traceCtx, span := h.tracer.Start(r.Context(), name)
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	reactions, err := apply(traceCtx, t.orgID, id, t.userID, r.PathValue("emoji"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToReactionResponses(reactions))
+}
@@ -0,0 +1,90 @@
+-- name: GetAccess :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id) AS org_admin,
+       EXISTS(
+           SELECT 1 FROM unit_members um
+           JOIN units ou ON ou.id = um.unit_id
+           WHERE um.member_id = @user_id
+             AND (ou.id = @org_id OR ou.org_id = @org_id)
+       ) AS org_member,
+       ARRAY(
+           SELECT um.unit_id FROM unit_members um
+           JOIN units ou ON ou.id = um.unit_id
+           WHERE um.member_id = @user_id AND ou.org_id = @org_id
+       )::uuid[] AS unit_ids;
+
+-- name: IsUnitInOrg :one
+SELECT EXISTS(SELECT 1 FROM units WHERE id = @unit_id AND org_id = @org_id);
+
+-- name: Create :one
+INSERT INTO announcements (org_id, unit_id, title, body, visibility, pinned_at, created_by, updated_by)
+VALUES (@org_id, sqlc.narg(unit_id), @title, @body, @visibility, sqlc.narg(pinned_at), @created_by, @created_by)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM announcements
+WHERE id = @id AND org_id = @org_id;
+
+-- name: List :many
+SELECT * FROM announcements
+WHERE org_id = @org_id
+  AND (visibility = 'organization' OR @read_all::boolean OR unit_id = ANY(@unit_ids::uuid[]))
+  AND (NOT @org_board::boolean OR unit_id IS NULL)
+  AND (sqlc.narg(unit_id)::uuid IS NULL OR unit_id = sqlc.narg(unit_id))
+  AND (sqlc.narg(is_pinned)::boolean IS NULL OR (pinned_at IS NOT NULL) = sqlc.narg(is_pinned))
+  AND (@search::text = '' OR title ILIKE '%' || @search::text || '%' OR body ILIKE '%' || @search::text || '%')
+ORDER BY pinned_at DESC NULLS LAST, created_at DESC
+LIMIT COALESCE(@page_limit::int, 10)
+OFFSET COALESCE(@page_offset::int, 0);
+
+-- name: Count :one
+SELECT COUNT(*) AS total
+FROM announcements
+WHERE org_id = @org_id
+  AND (visibility = 'organization' OR @read_all::boolean OR unit_id = ANY(@unit_ids::uuid[]))
+  AND (NOT @org_board::boolean OR unit_id IS NULL)
+  AND (sqlc.narg(unit_id)::uuid IS NULL OR unit_id = sqlc.narg(unit_id))
+  AND (sqlc.narg(is_pinned)::boolean IS NULL OR (pinned_at IS NOT NULL) = sqlc.narg(is_pinned))
+  AND (@search::text = '' OR title ILIKE '%' || @search::text || '%' OR body ILIKE '%' || @search::text || '%');
+
+-- name: Update :one
+UPDATE announcements
+SET title = @title,
+    body = @body,
+    visibility = @visibility,
+    pinned_at = CASE WHEN @pinned::boolean THEN COALESCE(pinned_at, now()) END,
+    updated_by = @updated_by,
+    updated_at = now()
+WHERE id = @id AND org_id = @org_id
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM announcements
+WHERE id = @id AND org_id = @org_id;
+
+-- name: CreateReaction :exec
+INSERT INTO announcement_reactions (announcement_id, user_id, emoji)
+VALUES (@announcement_id, @user_id, @emoji)
+ON CONFLICT (announcement_id, user_id, emoji) DO NOTHING;
+
+-- name: DeleteReaction :exec
+DELETE FROM announcement_reactions
+WHERE announcement_id = @announcement_id AND user_id = @user_id AND emoji = @emoji;
+
+-- name: ListReactionCounts :many
+SELECT announcement_id, emoji, COUNT(*)::bigint AS count, bool_or(user_id = @user_id)::boolean AS reacted
+FROM announcement_reactions
+WHERE announcement_id = ANY(@announcement_ids::uuid[])
+GROUP BY announcement_id, emoji
+ORDER BY announcement_id, count DESC, emoji;
+
+-- name: MarkRead :exec
+-- Keeps the first read, so reading again does not move it
+INSERT INTO announcement_reads (announcement_id, user_id)
+VALUES (@announcement_id, @user_id)
+ON CONFLICT (announcement_id, user_id) DO NOTHING;
+
+-- name: ListReadCounts :many
+SELECT announcement_id, COUNT(*)::bigint AS reads, bool_or(user_id = @user_id)::boolean AS read
+FROM announcement_reads
+WHERE announcement_id = ANY(@announcement_ids::uuid[])
+GROUP BY announcement_id;
\ No newline at end of file
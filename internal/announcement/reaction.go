@@ -0,0 +1,48 @@
+package announcement
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxReactionLength is the longest reaction in bytes, enough for the emoji sequences
+// of families and flags
+const MaxReactionLength = 32
+
+// combiningKeycap turns a digit, # or * into a keycap emoji
+const combiningKeycap = '\u20e3'
+
+// ReactionCount is the number of members who reacted to an announcement with an emoji.
+// Reacted is set when the current user is one of them.
+type ReactionCount struct {
+	Emoji   string
+	Count   int64
+	Reacted bool
+}
+
+// Engagement is how the members took an announcement: how many of them read it, whether
+// the current user did, and their reactions
+type Engagement struct {
+	Reads     int64
+	Read      bool
+	Reactions []ReactionCount
+}
+
+// validReaction accepts a single emoji or emoji sequence: no letters, spaces or control
+// characters, and at least one symbol or keycap
+func validReaction(emoji string) bool {
+	if emoji == "" || len(emoji) > MaxReactionLength || !utf8.ValidString(emoji) {
+		return false
+	}
+
+	hasSymbol := false
+	for _, r := range emoji {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+		if unicode.Is(unicode.So, r) || r == combiningKeycap {
+			hasSymbol = true
+		}
+	}
+	return hasSymbol
+}
@@ -0,0 +1,21 @@
+package announcement
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the announcement boards of an organization and its units, with the
+// reads of and reactions to each post
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/announcements", route.TenantAuthenticated, route.PermissionNone, h.ListHandler)
+	r.Handle("POST /orgs/{slug}/announcements", route.TenantAuthenticated, route.PermissionOrgAdmin, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/announcements/{id}", route.TenantAuthenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /orgs/{slug}/announcements/{id}", route.TenantAuthenticated, route.PermissionUnitMember, h.UpdateHandler)
+	r.Handle("DELETE /orgs/{slug}/announcements/{id}", route.TenantAuthenticated, route.PermissionUnitMember, h.DeleteHandler)
+	r.Handle("PUT /orgs/{slug}/announcements/{id}/read", route.TenantAuthenticated, route.PermissionNone, h.MarkReadHandler)
+	r.Handle("PUT /orgs/{slug}/announcements/{id}/reactions/{emoji}", route.TenantAuthenticated, route.PermissionNone, h.ReactHandler)
+	r.Handle("DELETE /orgs/{slug}/announcements/{id}/reactions/{emoji}", route.TenantAuthenticated, route.PermissionNone, h.UnreactHandler)
+
+	r.Handle("GET /orgs/{slug}/units/{id}/announcements", route.TenantAuthenticated, route.PermissionNone, h.ListUnitHandler)
+	r.Handle("POST /orgs/{slug}/units/{id}/announcements", route.TenantAuthenticated, route.PermissionUnitMember, h.CreateUnitHandler)
+}
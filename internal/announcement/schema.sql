@@ -0,0 +1,34 @@
+CREATE TYPE announcement_visibility AS ENUM ('organization', 'unit');
+
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    unit_id UUID DEFAULT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    visibility announcement_visibility NOT NULL DEFAULT 'organization',
+    pinned_at TIMESTAMPTZ DEFAULT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (unit_id IS NOT NULL OR visibility = 'organization')
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_board ON announcements(org_id, unit_id, pinned_at DESC NULLS LAST, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS announcement_reactions (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (announcement_id, user_id, emoji)
+);
+
+-- The first time each member read an announcement
+CREATE TABLE IF NOT EXISTS announcement_reads (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (announcement_id, user_id)
+);
\ No newline at end of file
@@ -0,0 +1,489 @@
+package announcement
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	GetAccess(ctx context.Context, arg GetAccessParams) (GetAccessRow, error)
+	IsUnitInOrg(ctx context.Context, arg IsUnitInOrgParams) (bool, error)
+	Create(ctx context.Context, arg CreateParams) (Announcement, error)
+	GetByID(ctx context.Context, arg GetByIDParams) (Announcement, error)
+	List(ctx context.Context, arg ListParams) ([]Announcement, error)
+	Count(ctx context.Context, arg CountParams) (int64, error)
+	Update(ctx context.Context, arg UpdateParams) (Announcement, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	CreateReaction(ctx context.Context, arg CreateReactionParams) error
+	DeleteReaction(ctx context.Context, arg DeleteReactionParams) error
+	ListReactionCounts(ctx context.Context, arg ListReactionCountsParams) ([]ListReactionCountsRow, error)
+	MarkRead(ctx context.Context, arg MarkReadParams) error
+	ListReadCounts(ctx context.Context, arg ListReadCountsParams) ([]ListReadCountsRow, error)
+}
+
+// Input is the content of an announcement. Body is markdown, rendered by the client.
+type Input struct {
+	Title      string
+	Body       string
+	Visibility AnnouncementVisibility
+	Pinned     bool
+}
+
+// Filter narrows the board. UnitID keeps the posts of one unit, and OrgBoard the posts
+// of the organization itself; OrgBoard takes precedence.
+type Filter struct {
+	UnitID   *uuid.UUID
+	OrgBoard bool
+	Pinned   *bool
+	Search   string
+}
+
+// access is what a user may do with the board of an organization. The owner of the
+// organization reads and manages every post; members read the posts visible to the
+// organization and those of their units, which they also post to.
+type access struct {
+	orgAdmin  bool
+	orgMember bool
+	unitIDs   []uuid.UUID
+}
+
+func (a access) canRead(announcement Announcement) bool {
+	if a.orgAdmin || (a.orgMember && announcement.Visibility == AnnouncementVisibilityOrganization) {
+		return true
+	}
+	return announcement.UnitID.Valid && slices.Contains(a.unitIDs, announcement.UnitID.Bytes)
+}
+
+// canPost allows the owner on every board, and the members of a unit on its board
+func (a access) canPost(unitID pgtype.UUID) bool {
+	return a.orgAdmin || (unitID.Valid && slices.Contains(a.unitIDs, unitID.Bytes))
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("announcement/service"),
+	}
+}
+
+// access looks up the role of the user in the organization; a user outside of it may
+// not use its board
+func (s *Service) access(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) (access, error) {
+	row, err := s.queries.GetAccess(ctx, GetAccessParams{
+		OrgID:  orgID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return access{}, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "member_id", userID.String(), logger, "check announcement access")
+	}
+	if !row.OrgAdmin && !row.OrgMember {
+		return access{}, fmt.Errorf("%w: user is not a member of the organization", internal.ErrPermissionDenied)
+	}
+	return access{orgAdmin: row.OrgAdmin, orgMember: row.OrgMember, unitIDs: row.UnitIds}, nil
+}
+
+// readable returns the announcement if the user may read it. A post hidden from the
+// user is not found.
+func (s *Service) readable(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Announcement, access, error) {
+	a, err := s.access(ctx, logger, orgID, userID)
+	if err != nil {
+		return Announcement{}, access{}, err
+	}
+
+	announcement, err := s.queries.GetByID(ctx, GetByIDParams{ID: id, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Announcement{}, access{}, internal.ErrAnnouncementNotFound
+		}
+		return Announcement{}, access{}, databaseutil.WrapDBErrorWithKeyValue(err, "announcements", "id", id.String(), logger, "get announcement")
+	}
+	if !a.canRead(announcement) {
+		return Announcement{}, access{}, internal.ErrAnnouncementNotFound
+	}
+	return announcement, a, nil
+}
+
+// editable returns the announcement if the user may change it
+func (s *Service) editable(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Announcement, error) {
+	announcement, a, err := s.readable(ctx, logger, orgID, id, userID)
+	if err != nil {
+		return Announcement{}, err
+	}
+	if !a.canPost(announcement.UnitID) {
+		return Announcement{}, fmt.Errorf("%w: only the members of the unit that posted the announcement can change it", internal.ErrPermissionDenied)
+	}
+	return announcement, nil
+}
+
+// checkVisibility refuses to hide a post of the organization board from its members,
+// as there is no unit to show it to instead
+func checkVisibility(unitID pgtype.UUID, visibility AnnouncementVisibility) error {
+	if !unitID.Valid && visibility != AnnouncementVisibilityOrganization {
+		return internal.ErrAnnouncementVisibilityInvalid
+	}
+	return nil
+}
+
+// Create posts an announcement to the board of the unit, or of the organization itself
+// when unitID is nil
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, unitID *uuid.UUID, input Input, userID uuid.UUID) (Announcement, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	var board pgtype.UUID
+	if unitID != nil {
+		board = pgtype.UUID{Bytes: *unitID, Valid: true}
+
+		inOrg, err := s.queries.IsUnitInOrg(traceCtx, IsUnitInOrgParams{UnitID: *unitID, OrgID: pgtype.UUID{Bytes: orgID, Valid: true}})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "units", "id", unitID.String(), logger, "check unit organization")
+			span.RecordError(err)
+			return Announcement{}, err
+		}
+		if !inOrg {
+			span.RecordError(internal.ErrUnitNotFound)
+			return Announcement{}, internal.ErrUnitNotFound
+		}
+	}
+
+	err := checkVisibility(board, input.Visibility)
+	if err != nil {
+		span.RecordError(err)
+		return Announcement{}, err
+	}
+
+	a, err := s.access(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Announcement{}, err
+	}
+	if !a.canPost(board) {
+		err = fmt.Errorf("%w: only the owner of the organization and the members of a unit can post to their board", internal.ErrPermissionDenied)
+		span.RecordError(err)
+		return Announcement{}, err
+	}
+
+	var pinnedAt pgtype.Timestamptz
+	if input.Pinned {
+		pinnedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	}
+
+	announcement, err := s.queries.Create(traceCtx, CreateParams{
+		OrgID:      orgID,
+		UnitID:     board,
+		Title:      input.Title,
+		Body:       input.Body,
+		Visibility: input.Visibility,
+		PinnedAt:   pinnedAt,
+		CreatedBy:  pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "announcements", "org_id", orgID.String(), logger, "create announcement")
+		span.RecordError(err)
+		return Announcement{}, err
+	}
+
+	logger.Info("Posted announcement", zap.String("announcement_id", announcement.ID.String()), zap.String("org_id", orgID.String()), zap.Bool("pinned", input.Pinned))
+
+	return announcement, nil
+}
+
+// List returns the announcements of the organization the user may read, pinned ones
+// first, then the latest
+func (s *Service) List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, filter Filter, page int, size int) ([]Announcement, int64, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	a, err := s.access(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	params := ListParams{
+		OrgID:    orgID,
+		ReadAll:  a.orgAdmin,
+		UnitIds:  a.unitIDs,
+		OrgBoard: filter.OrgBoard,
+		Search:   filter.Search,
+	}
+	if params.UnitIds == nil {
+		params.UnitIds = []uuid.UUID{}
+	}
+	if filter.UnitID != nil && !filter.OrgBoard {
+		params.UnitID = pgtype.UUID{Bytes: *filter.UnitID, Valid: true}
+	}
+	if filter.Pinned != nil {
+		params.IsPinned = pgtype.Bool{Bool: *filter.Pinned, Valid: true}
+	}
+
+	total, err := s.queries.Count(traceCtx, CountParams{
+		OrgID:    params.OrgID,
+		ReadAll:  params.ReadAll,
+		UnitIds:  params.UnitIds,
+		OrgBoard: params.OrgBoard,
+		UnitID:   params.UnitID,
+		IsPinned: params.IsPinned,
+		Search:   params.Search,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "announcements", "org_id", orgID.String(), logger, "count announcements")
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	if size > 0 {
+		params.PageLimit = int32(size)
+	}
+	if page > 0 && size > 0 {
+		params.PageOffset = int32((page - 1) * size)
+	}
+
+	announcements, err := s.queries.List(traceCtx, params)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "announcements", "org_id", orgID.String(), logger, "list announcements")
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	return announcements, total, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Announcement, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	announcement, _, err := s.readable(traceCtx, logger, orgID, id, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Announcement{}, err
+	}
+
+	return announcement, nil
+}
+
+// Update replaces the content of an announcement. A post pinned again keeps the time it
+// was first pinned, so editing it does not move it up the board.
+func (s *Service) Update(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input Input, userID uuid.UUID) (Announcement, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	current, err := s.editable(traceCtx, logger, orgID, id, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Announcement{}, err
+	}
+
+	err = checkVisibility(current.UnitID, input.Visibility)
+	if err != nil {
+		span.RecordError(err)
+		return Announcement{}, err
+	}
+
+	announcement, err := s.queries.Update(traceCtx, UpdateParams{
+		Title:      input.Title,
+		Body:       input.Body,
+		Visibility: input.Visibility,
+		Pinned:     input.Pinned,
+		UpdatedBy:  pgtype.UUID{Bytes: userID, Valid: true},
+		ID:         id,
+		OrgID:      orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrAnnouncementNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "announcements", "id", id.String(), logger, "update announcement")
+		}
+		span.RecordError(err)
+		return Announcement{}, err
+	}
+
+	logger.Info("Updated announcement", zap.String("announcement_id", id.String()), zap.Bool("pinned", input.Pinned))
+
+	return announcement, nil
+}
+
+// Delete removes an announcement together with its reactions and reads
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	_, err := s.editable(traceCtx, logger, orgID, id, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Delete(traceCtx, DeleteParams{ID: id, OrgID: orgID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "announcements", "id", id.String(), logger, "delete announcement")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		span.RecordError(internal.ErrAnnouncementNotFound)
+		return internal.ErrAnnouncementNotFound
+	}
+
+	logger.Info("Deleted announcement", zap.String("announcement_id", id.String()))
+
+	return nil
+}
+
+// Engagement counts the reads and the reactions of each announcement, reactions most
+// frequent first; every announcement asked for is present
+func (s *Service) Engagement(ctx context.Context, ids []uuid.UUID, userID uuid.UUID) (map[uuid.UUID]Engagement, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Engagement")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	reads, err := s.queries.ListReadCounts(traceCtx, ListReadCountsParams{UserID: userID, AnnouncementIds: ids})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list announcement reads")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	reactions, err := s.queries.ListReactionCounts(traceCtx, ListReactionCountsParams{UserID: userID, AnnouncementIds: ids})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list announcement reactions")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	engagement := make(map[uuid.UUID]Engagement, len(ids))
+	for _, id := range ids {
+		engagement[id] = Engagement{Reactions: []ReactionCount{}}
+	}
+	for _, row := range reads {
+		current := engagement[row.AnnouncementID]
+		current.Reads = row.Reads
+		current.Read = row.Read
+		engagement[row.AnnouncementID] = current
+	}
+	for _, row := range reactions {
+		current := engagement[row.AnnouncementID]
+		current.Reactions = append(current.Reactions, ReactionCount{
+			Emoji:   row.Emoji,
+			Count:   row.Count,
+			Reacted: row.Reacted,
+		})
+		engagement[row.AnnouncementID] = current
+	}
+
+	return engagement, nil
+}
+
+// MarkRead records that the user read an announcement they can read. Only the first
+// read is kept.
+func (s *Service) MarkRead(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "MarkRead")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	_, _, err := s.readable(traceCtx, logger, orgID, id, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.queries.MarkRead(traceCtx, MarkReadParams{AnnouncementID: id, UserID: userID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "announcement_reads", "announcement_id", id.String(), logger, "mark announcement read")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// React adds a reaction of the user to an announcement they can read, and returns its
+// reactions
+func (s *Service) React(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID, emoji string) ([]ReactionCount, error) {
+	traceCtx, span := s.tracer.Start(ctx, "React")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.reactionTarget(traceCtx, logger, orgID, id, userID, emoji)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	err = s.queries.CreateReaction(traceCtx, CreateReactionParams{AnnouncementID: id, UserID: userID, Emoji: emoji})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "announcement_reactions", "announcement_id", id.String(), logger, "create reaction")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	engagement, err := s.Engagement(traceCtx, []uuid.UUID{id}, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return engagement[id].Reactions, nil
+}
+
+// Unreact takes back a reaction of the user, and returns the reactions of the announcement
+func (s *Service) Unreact(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID, emoji string) ([]ReactionCount, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Unreact")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.reactionTarget(traceCtx, logger, orgID, id, userID, emoji)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	err = s.queries.DeleteReaction(traceCtx, DeleteReactionParams{AnnouncementID: id, UserID: userID, Emoji: emoji})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "announcement_reactions", "announcement_id", id.String(), logger, "delete reaction")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	engagement, err := s.Engagement(traceCtx, []uuid.UUID{id}, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return engagement[id].Reactions, nil
+}
+
+// reactionTarget checks the emoji and that the user can read the announcement
+func (s *Service) reactionTarget(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID, emoji string) error {
+	if !validReaction(emoji) {
+		return internal.ErrInvalidReaction
+	}
+
+	_, _, err := s.readable(ctx, logger, orgID, id, userID)
+	return err
+}
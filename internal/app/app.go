@@ -0,0 +1,246 @@
+// Package app wires the backend together: the services on the database, their
+// handlers, the middleware and the routes, and the background jobs.
+package app
+
+import (
+	"NYCU-SDC/core-system-backend/internal/config"
+	"NYCU-SDC/core-system-backend/internal/form/retention"
+	"NYCU-SDC/core-system-backend/internal/form/upload"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/leader"
+	"NYCU-SDC/core-system-backend/internal/magiclink"
+	"NYCU-SDC/core-system-backend/internal/oidc"
+	"NYCU-SDC/core-system-backend/internal/push"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"NYCU-SDC/core-system-backend/internal/secrets"
+	"NYCU-SDC/core-system-backend/internal/selftest"
+	"NYCU-SDC/core-system-backend/internal/storage"
+	"NYCU-SDC/core-system-backend/internal/testtenant"
+	"NYCU-SDC/core-system-backend/internal/trace"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/internal/web"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// shutdownTimeout bounds the time the server waits for the requests in flight to end
+const shutdownTimeout = 5 * time.Second
+
+// Info identifies the running build, from the variables set at link time
+type Info struct {
+	AppName     string
+	Version     string
+	BuildTime   string
+	CommitHash  string
+	Environment string
+}
+
+// Builder collects what the backend is built from. The external dependencies left
+// unset are built from the config; tests set fakes in their place.
+//
+// Usage:
+//
+//	application, err := app.NewBuilder(logger, cfg, info, dbPool, logLevel).
+//		WithStorage(fakeStorage).
+//		WithPushSenders(fakeSender, nil).
+//		Build()
+//	srv := httptest.NewServer(application.Handler())
+type Builder struct {
+	logger   *zap.Logger
+	cfg      config.Config
+	info     Info
+	db       *pgxpool.Pool
+	logLevel zap.AtomicLevel
+
+	storage       storage.Storage
+	uploadScanner upload.Scanner
+	webPushSender push.Sender
+	fcmSender     push.Sender
+	traceSampler  *trace.Sampler
+	panicReporter trace.PanicReporter
+	frontend      fs.FS
+}
+
+func NewBuilder(logger *zap.Logger, cfg config.Config, info Info, db *pgxpool.Pool, logLevel zap.AtomicLevel) *Builder {
+	return &Builder{
+		logger:   logger,
+		cfg:      cfg,
+		info:     info,
+		db:       db,
+		logLevel: logLevel,
+	}
+}
+
+// WithStorage keeps files in storage instead of the configured backend
+func (b *Builder) WithStorage(storage storage.Storage) *Builder {
+	b.storage = storage
+	return b
+}
+
+// WithUploadScanner scans uploads with scanner instead of the configured clamd daemon
+func (b *Builder) WithUploadScanner(scanner upload.Scanner) *Builder {
+	b.uploadScanner = scanner
+	return b
+}
+
+// WithPushSenders sends push notifications through the given senders; a nil sender
+// falls back to the configured one of its platform
+func (b *Builder) WithPushSenders(webPush push.Sender, fcm push.Sender) *Builder {
+	b.webPushSender = webPush
+	b.fcmSender = fcm
+	return b
+}
+
+// WithTraceSampler adjusts sampler through the admin API, the one installed in the
+// tracer provider. Without it the API adjusts a sampler of its own.
+func (b *Builder) WithTraceSampler(sampler *trace.Sampler) *Builder {
+	b.traceSampler = sampler
+	return b
+}
+
+// WithPanicReporter reports recovered panics to reporter instead of the configured Sentry DSN
+func (b *Builder) WithPanicReporter(reporter trace.PanicReporter) *Builder {
+	b.panicReporter = reporter
+	return b
+}
+
+// WithFrontend serves files under / instead of the embedded frontend build, when the
+// config enables serving the frontend
+func (b *Builder) WithFrontend(files fs.FS) *Builder {
+	b.frontend = files
+	return b
+}
+
+// App is the built backend, ready to serve
+type App struct {
+	logger   *zap.Logger
+	cfg      config.Config
+	services *services
+	routes   []route.Route
+	handler  http.Handler
+}
+
+// Build wires the services, handlers, middleware and routes. Nothing runs until
+// Start or Run is called.
+func (b *Builder) Build() (*App, error) {
+	s, err := b.newServices()
+	if err != nil {
+		return nil, err
+	}
+
+	middlewares, err := b.middlewares(s)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := b.routes(s, middlewares)
+	mux, err := routes.Mux()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build routes: %w", err)
+	}
+
+	// The frontend gets the paths no route matches, those under /api excepted
+	if b.cfg.ServeFrontend {
+		files := b.frontend
+		if files == nil {
+			files = web.Dist()
+		}
+		frontend, err := web.NewHandler(b.logger, files)
+		if err != nil {
+			return nil, err
+		}
+		mux.Handle("/", frontend)
+	}
+
+	return &App{
+		logger:   b.logger,
+		cfg:      b.cfg,
+		services: s,
+		routes:   routes.Routes(),
+		handler:  b.entrypoint(s, mux),
+	}, nil
+}
+
+// Handler serves every route of the API
+func (a *App) Handler() http.Handler {
+	return a.handler
+}
+
+// Routes returns the declared routes in registration order
+func (a *App) Routes() []route.Route {
+	return a.routes
+}
+
+// Start runs the background jobs until the context is done
+func (a *App) Start(ctx context.Context) {
+	s := a.services
+
+	// Log level from the config, read again on SIGHUP
+	go s.logLevel.Watch(ctx, func() (string, error) {
+		reloaded, err := config.Reload()
+		return reloaded.LogLevel, err
+	})
+
+	// Realtime events, received on every replica
+	go s.realtime.Start(ctx)
+	go s.selftest.Start(ctx, selftest.DefaultRunInterval)
+
+	// Scheduled jobs, each run by one replica at a time
+	elector := leader.NewElector(a.logger, a.cfg.DatabaseURL, leader.DefaultRetryInterval)
+	go elector.Run(ctx, "export", func(ctx context.Context) { s.export.Start(ctx, a.cfg.ExportInterval) })
+	go elector.Run(ctx, "upload_scan", func(ctx context.Context) { s.upload.Start(ctx, upload.DefaultScanInterval) })
+	go elector.Run(ctx, "inbox_resurface", func(ctx context.Context) { s.inbox.Start(ctx, inbox.DefaultResurfaceInterval) })
+	go elector.Run(ctx, "push_dispatch", func(ctx context.Context) { s.push.Start(ctx, push.DefaultDispatchInterval) })
+	go elector.Run(ctx, "member_expiry", func(ctx context.Context) { s.unit.Start(ctx, unit.DefaultExpiryInterval) })
+	go elector.Run(ctx, "oidc_cleanup", func(ctx context.Context) { s.oidc.Start(ctx, oidc.DefaultCleanupInterval) })
+	go elector.Run(ctx, "magic_link_cleanup", func(ctx context.Context) { s.magicLink.Start(ctx, magiclink.DefaultCleanupInterval) })
+	go elector.Run(ctx, "retention", func(ctx context.Context) { s.retention.Start(ctx, retention.DefaultRunInterval) })
+	go elector.Run(ctx, "test_tenant_expiry", func(ctx context.Context) { s.testTenant.Start(ctx, testtenant.DefaultExpiryInterval) })
+	go elector.Run(ctx, "secret_rotation", func(ctx context.Context) {
+		secrets.Rotate(ctx, a.logger, map[string]secrets.Rotator{"export": s.export, "ingest": s.ingest, "webhook": s.webhook, "workflow": s.workflow})
+	})
+}
+
+// Run starts the background jobs and serves on the configured host and port until the
+// context is done, then shuts the server down gracefully
+func (a *App) Run(ctx context.Context) error {
+	a.Start(ctx)
+
+	srv := &http.Server{
+		Addr:    a.cfg.Host + ":" + a.cfg.Port,
+		Handler: a.handler,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		a.logger.Info("Starting listening request", zap.String("host", a.cfg.Host), zap.String("port", a.cfg.Port))
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+	}()
+
+	// wait for context close
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("failed to start server: %w", err)
+	case <-ctx.Done():
+	}
+	a.logger.Info("Shutting down gracefully...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := srv.Shutdown(shutdownCtx)
+	if err != nil {
+		a.logger.Error("Server forced to shutdown", zap.Error(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"NYCU-SDC/core-system-backend/internal/compress"
+	"NYCU-SDC/core-system-backend/internal/cors"
+	"NYCU-SDC/core-system-backend/internal/form/delegation"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/metrics"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"NYCU-SDC/core-system-backend/internal/tenant"
+	"NYCU-SDC/core-system-backend/internal/trace"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/NYCU-SDC/summer/pkg/middleware"
+)
+
+// middlewares builds the middleware set of each access level
+func (b *Builder) middlewares(s *services) (map[route.Access]*middleware.Set, error) {
+	panicReporter := b.panicReporter
+	if panicReporter == nil && b.cfg.SentryDSN != "" {
+		sentryReporter, err := trace.NewSentryReporter(b.logger, b.cfg.SentryDSN, trace.BuildInfo{Version: b.info.Version, CommitHash: b.info.CommitHash, Environment: b.info.Environment})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Sentry reporting: %w", err)
+		}
+		panicReporter = sentryReporter
+	}
+	traceMiddleware := trace.NewMiddleware(b.logger, panicReporter)
+	metricsMiddleware, err := metrics.NewMiddleware(b.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize metrics: %w", err)
+	}
+	err = errors.Join(
+		metricsMiddleware.ObserveQueue("push_jobs", s.push.PendingJobs),
+		metricsMiddleware.ObserveQueue("upload_scans", s.upload.PendingScans),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to observe queues: %w", err)
+	}
+	jwtMiddleware := jwt.NewMiddleware(b.logger, s.validator, s.problemWriter, s.jwt)
+	delegationMiddleware := delegation.NewMiddleware(b.logger, s.problemWriter, s.delegation, s.jwt, jwtMiddleware)
+	tenantMiddleware := tenant.NewMiddleware(b.logger, b.db, s.tenant)
+	auditMiddleware := audit.NewMiddleware(b.logger, s.audit)
+
+	// Basic Middleware (Tracing and Recovery)
+	basicMiddleware := middleware.NewSet(traceMiddleware.RecoverMiddleware)
+	basicMiddleware = basicMiddleware.Append(metricsMiddleware.RecordMiddleware)
+	basicMiddleware = basicMiddleware.Append(traceMiddleware.TraceMiddleware)
+	basicMiddleware = basicMiddleware.Append(traceMiddleware.RequestIDMiddleware)
+	basicMiddleware = basicMiddleware.Append(traceMiddleware.PanicContextMiddleware)
+
+	// Auth Middleware
+	authMiddleware := middleware.NewSet(traceMiddleware.RecoverMiddleware)
+	authMiddleware = authMiddleware.Append(metricsMiddleware.RecordMiddleware)
+	authMiddleware = authMiddleware.Append(traceMiddleware.TraceMiddleware)
+	authMiddleware = authMiddleware.Append(traceMiddleware.RequestIDMiddleware)
+	authMiddleware = authMiddleware.Append(jwtMiddleware.AuthenticateMiddleware)
+	authMiddleware = authMiddleware.Append(auditMiddleware.RecordMiddleware)
+	authMiddleware = authMiddleware.Append(traceMiddleware.PanicContextMiddleware)
+
+	// Respondent Middleware (full tokens, or respondent tokens scoped to the form in the path;
+	// management tokens of the form may read)
+	respondentMiddleware := middleware.NewSet(traceMiddleware.RecoverMiddleware)
+	respondentMiddleware = respondentMiddleware.Append(metricsMiddleware.RecordMiddleware)
+	respondentMiddleware = respondentMiddleware.Append(traceMiddleware.TraceMiddleware)
+	respondentMiddleware = respondentMiddleware.Append(traceMiddleware.RequestIDMiddleware)
+	respondentMiddleware = respondentMiddleware.Append(delegationMiddleware.ViewMiddleware)
+	respondentMiddleware = respondentMiddleware.Append(auditMiddleware.RecordMiddleware)
+	respondentMiddleware = respondentMiddleware.Append(traceMiddleware.PanicContextMiddleware)
+
+	// Delegate Middleware (full tokens, or management tokens of an active delegation of the form in the path)
+	delegateMiddleware := middleware.NewSet(traceMiddleware.RecoverMiddleware)
+	delegateMiddleware = delegateMiddleware.Append(metricsMiddleware.RecordMiddleware)
+	delegateMiddleware = delegateMiddleware.Append(traceMiddleware.TraceMiddleware)
+	delegateMiddleware = delegateMiddleware.Append(traceMiddleware.RequestIDMiddleware)
+	delegateMiddleware = delegateMiddleware.Append(delegationMiddleware.ManageMiddleware)
+	delegateMiddleware = delegateMiddleware.Append(auditMiddleware.RecordMiddleware)
+	delegateMiddleware = delegateMiddleware.Append(traceMiddleware.PanicContextMiddleware)
+
+	// Kiosk Middleware (full tokens, or the tokens of check-in kiosks paired through the device flow)
+	kioskMiddleware := middleware.NewSet(traceMiddleware.RecoverMiddleware)
+	kioskMiddleware = kioskMiddleware.Append(metricsMiddleware.RecordMiddleware)
+	kioskMiddleware = kioskMiddleware.Append(traceMiddleware.TraceMiddleware)
+	kioskMiddleware = kioskMiddleware.Append(traceMiddleware.RequestIDMiddleware)
+	kioskMiddleware = kioskMiddleware.Append(jwtMiddleware.KioskMiddleware)
+	kioskMiddleware = kioskMiddleware.Append(auditMiddleware.RecordMiddleware)
+	kioskMiddleware = kioskMiddleware.Append(traceMiddleware.PanicContextMiddleware)
+
+	// Tenant-aware Middleware
+	tenantBasicMiddleware := basicMiddleware.Append(tenantMiddleware.Middleware)
+	tenantAuthMiddleware := authMiddleware.Append(tenantMiddleware.Middleware)
+
+	return map[route.Access]*middleware.Set{
+		route.Public:              basicMiddleware,
+		route.Authenticated:       authMiddleware,
+		route.Respondent:          respondentMiddleware,
+		route.Delegate:            delegateMiddleware,
+		route.Kiosk:               kioskMiddleware,
+		route.TenantPublic:        tenantBasicMiddleware,
+		route.TenantAuthenticated: tenantAuthMiddleware,
+	}, nil
+}
+
+// entrypoint wraps the routes with the middleware applied before routing
+func (b *Builder) entrypoint(s *services, mux *http.ServeMux) http.HandlerFunc {
+	var corsMiddleware cors.Middleware
+	if b.cfg.Dev {
+		corsMiddleware = cors.NewPermissiveMiddleware(b.logger)
+	} else {
+		corsMiddleware = cors.NewMiddleware(b.logger, b.cfg.AllowOrigins)
+	}
+	compressMiddleware := compress.NewMiddleware(b.logger, compress.DefaultMinSize)
+	tenantMiddleware := tenant.NewMiddleware(b.logger, b.db, s.tenant)
+
+	// Client address, CORS, compression, organization IDs resolved to slugs and Entry Point
+	return b.cfg.TrustedProxyRanges.ClientIPMiddleware(corsMiddleware.HandlerFunc(compressMiddleware.HandlerFunc(tenantMiddleware.ResolveID(mux.ServeHTTP))))
+}
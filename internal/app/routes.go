@@ -0,0 +1,219 @@
+package app
+
+import (
+	"NYCU-SDC/core-system-backend/internal/announcement"
+	"NYCU-SDC/core-system-backend/internal/attendance"
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"NYCU-SDC/core-system-backend/internal/auth"
+	"NYCU-SDC/core-system-backend/internal/avatar"
+	"NYCU-SDC/core-system-backend/internal/backup"
+	"NYCU-SDC/core-system-backend/internal/dev"
+	"NYCU-SDC/core-system-backend/internal/event"
+	"NYCU-SDC/core-system-backend/internal/finance"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/approval"
+	"NYCU-SDC/core-system-backend/internal/form/assignment"
+	"NYCU-SDC/core-system-backend/internal/form/attempt"
+	"NYCU-SDC/core-system-backend/internal/form/ballot"
+	"NYCU-SDC/core-system-backend/internal/form/checkin"
+	"NYCU-SDC/core-system-backend/internal/form/comment"
+	"NYCU-SDC/core-system-backend/internal/form/delegation"
+	"NYCU-SDC/core-system-backend/internal/form/eligibility"
+	"NYCU-SDC/core-system-backend/internal/form/export"
+	"NYCU-SDC/core-system-backend/internal/form/favorite"
+	"NYCU-SDC/core-system-backend/internal/form/grading"
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"NYCU-SDC/core-system-backend/internal/form/ingest"
+	"NYCU-SDC/core-system-backend/internal/form/payment"
+	"NYCU-SDC/core-system-backend/internal/form/pii"
+	"NYCU-SDC/core-system-backend/internal/form/pipeline"
+	"NYCU-SDC/core-system-backend/internal/form/progress"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/respondent"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/results"
+	"NYCU-SDC/core-system-backend/internal/form/retention"
+	"NYCU-SDC/core-system-backend/internal/form/submit"
+	"NYCU-SDC/core-system-backend/internal/form/upload"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/group"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/logging"
+	"NYCU-SDC/core-system-backend/internal/migration"
+	"NYCU-SDC/core-system-backend/internal/oidc"
+	"NYCU-SDC/core-system-backend/internal/profile"
+	"NYCU-SDC/core-system-backend/internal/publish"
+	"NYCU-SDC/core-system-backend/internal/push"
+	"NYCU-SDC/core-system-backend/internal/quota"
+	"NYCU-SDC/core-system-backend/internal/resource"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"NYCU-SDC/core-system-backend/internal/search"
+	"NYCU-SDC/core-system-backend/internal/selftest"
+	"NYCU-SDC/core-system-backend/internal/storage"
+	"NYCU-SDC/core-system-backend/internal/studentid"
+	"NYCU-SDC/core-system-backend/internal/tag"
+	"NYCU-SDC/core-system-backend/internal/task"
+	"NYCU-SDC/core-system-backend/internal/tenant"
+	"NYCU-SDC/core-system-backend/internal/testtenant"
+	"NYCU-SDC/core-system-backend/internal/trace"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"NYCU-SDC/core-system-backend/internal/wiki"
+
+	"github.com/NYCU-SDC/summer/pkg/middleware"
+)
+
+// routes builds the handlers on the services and has each module declare its routes on
+// a registry, under the version of the API they belong to
+func (b *Builder) routes(s *services, middlewares map[route.Access]*middleware.Set) *route.Registry {
+	authHandler := auth.NewHandler(b.logger, s.validator, s.problemWriter, s.user, s.jwt, s.jwt, s.audit, s.magicLink, b.cfg.BaseURL, b.cfg.OauthProxyBaseURL, b.info.Environment, b.cfg.Dev, b.cfg.AccessTokenExpiration, b.cfg.RefreshTokenExpiration, b.cfg.GoogleOauth)
+	userHandler := user.NewHandler(b.logger, s.validator, s.problemWriter, s.user)
+	formHandler := form.NewHandler(b.logger, s.validator, s.problemWriter, s.form, s.tenant, s.quota.Guard(quota.QuotaResourceForms))
+	questionHandler := question.NewHandler(b.logger, s.validator, s.problemWriter, s.question)
+	unitHandler := unit.NewHandler(b.logger, s.validator, s.problemWriter, s.unit, s.form, s.tenant, s.user, s.profile, s.quota.Guard(quota.QuotaResourceMembers))
+	responseHandler := response.NewHandler(b.logger, s.validator, s.problemWriter, s.response, s.question, s.pii)
+	submitHandler := submit.NewHandler(b.logger, s.validator, s.problemWriter, s.submit)
+	ingestHandler := ingest.NewHandler(b.logger, s.validator, s.problemWriter, s.ingest, s.tenant)
+	respondentHandler := respondent.NewHandler(b.logger, s.problemWriter, s.respondent, s.jwt)
+	favoriteHandler := favorite.NewHandler(b.logger, s.problemWriter, s.favorite)
+	importerHandler := importer.NewHandler(b.logger, s.validator, s.problemWriter, s.importer, s.tenant)
+	backupHandler := backup.NewHandler(b.logger, s.validator, s.problemWriter, s.backup, s.tenant)
+	testTenantHandler := testtenant.NewHandler(b.logger, s.validator, s.problemWriter, s.testTenant)
+	searchHandler := search.NewHandler(b.logger, s.problemWriter, s.search)
+	inboxHandler := inbox.NewHandler(b.logger, s.validator, s.problemWriter, s.inbox, s.form, s.unit)
+	inboxStreamHandler := inbox.NewStreamHandler(b.logger, s.problemWriter, s.realtime)
+	jwtHandler := jwt.NewHandler(b.logger, s.jwt)
+	introspectionHandler := auth.NewIntrospectionHandler(b.logger, s.problemWriter, s.jwt, b.cfg.IntrospectionClients)
+	oidcHandler := oidc.NewHandler(b.logger, s.problemWriter, s.oidc, s.jwt, s.user, b.cfg.BaseURL, b.cfg.AccessTokenExpiration)
+	auditHandler := audit.NewHandler(b.logger, s.problemWriter, s.audit)
+	migrationHandler := migration.NewHandler(b.logger, s.problemWriter, s.migration)
+	logLevelHandler := logging.NewHandler(b.logger, s.validator, s.problemWriter, s.logLevel)
+	traceSamplingHandler := trace.NewHandler(b.logger, s.validator, s.problemWriter, s.traceSampler)
+	groupHandler := group.NewHandler(b.logger, s.validator, s.problemWriter, s.group, s.tenant)
+	tagHandler := tag.NewHandler(b.logger, s.validator, s.problemWriter, s.tag, s.tenant)
+	wikiHandler := wiki.NewHandler(b.logger, s.validator, s.problemWriter, s.wiki, s.tenant)
+	taskHandler := task.NewHandler(b.logger, s.validator, s.problemWriter, s.task, s.tenant)
+	resourceHandler := resource.NewHandler(b.logger, s.validator, s.problemWriter, s.resource, s.tenant)
+	financeHandler := finance.NewHandler(b.logger, s.validator, s.problemWriter, s.finance, s.tenant)
+	profileHandler := profile.NewHandler(b.logger, s.validator, s.problemWriter, s.profile, s.tenant)
+	quotaHandler := quota.NewHandler(b.logger, s.problemWriter, s.quota, s.tenant)
+	webhookHandler := webhook.NewHandler(b.logger, s.validator, s.problemWriter, s.webhook, s.tenant)
+	studentIDHandler := studentid.NewHandler(b.logger, s.validator, s.problemWriter, s.studentID, s.tenant)
+	publishHandler := publish.NewHandler(b.logger, s.validator, s.problemWriter, s.publish)
+	tenantHandler := tenant.NewHandler(b.logger, s.validator, s.problemWriter, s.tenant)
+	workflowHandler := workflow.NewHandler(b.logger, s.validator, s.problemWriter, s.workflow)
+	eligibilityHandler := eligibility.NewHandler(b.logger, s.validator, s.problemWriter, s.eligibility)
+	pipelineHandler := pipeline.NewHandler(b.logger, s.validator, s.problemWriter, s.pipeline, s.tenant)
+	progressHandler := progress.NewHandler(b.logger, s.validator, s.problemWriter, s.progress)
+	approvalHandler := approval.NewHandler(b.logger, s.validator, s.problemWriter, s.approval)
+	assignmentHandler := assignment.NewHandler(b.logger, s.validator, s.problemWriter, s.assignment)
+	gradingHandler := grading.NewHandler(b.logger, s.validator, s.problemWriter, s.grading)
+	checkinHandler := checkin.NewHandler(b.logger, s.validator, s.problemWriter, s.checkin)
+	attendanceHandler := attendance.NewHandler(b.logger, s.validator, s.problemWriter, s.attendance, s.tenant)
+	eventHandler := event.NewHandler(b.logger, s.validator, s.problemWriter, s.event, s.tenant)
+	paymentHandler := payment.NewHandler(b.logger, s.validator, s.problemWriter, s.payment, payment.NewProviders(b.cfg.Payment))
+	resultsHandler := results.NewHandler(b.logger, s.validator, s.problemWriter, s.results)
+	ballotHandler := ballot.NewHandler(b.logger, s.validator, s.problemWriter, s.ballot)
+	delegationHandler := delegation.NewHandler(b.logger, s.validator, s.problemWriter, s.delegation)
+	announcementHandler := announcement.NewHandler(b.logger, s.validator, s.problemWriter, s.announcement, s.tenant)
+	piiHandler := pii.NewHandler(b.logger, s.validator, s.problemWriter, s.pii)
+	retentionHandler := retention.NewHandler(b.logger, s.validator, s.problemWriter, s.retention)
+	attemptHandler := attempt.NewHandler(b.logger, s.problemWriter, s.attempt)
+	commentHandler := comment.NewHandler(b.logger, s.validator, s.problemWriter, s.comment)
+	exportHandler := export.NewHandler(b.logger, s.validator, s.problemWriter, s.export)
+	uploadHandler := upload.NewHandler(b.logger, s.validator, s.problemWriter, s.upload)
+	avatarHandler := avatar.NewHandler(b.logger, s.validator, s.problemWriter, s.avatar)
+	pushHandler := push.NewHandler(b.logger, s.validator, s.problemWriter, s.push, b.cfg.Push.VAPIDPublicKey)
+	selftestHandler := selftest.NewHandler(b.logger, s.selftest)
+	favoriteMiddleware := favorite.NewMiddleware(b.logger, s.favorite)
+
+	// Route registry; every route declares its access level and permission
+	routes := route.NewRegistry(b.logger, middlewares, b.cfg.BodyLimits.Default)
+
+	// Well-known endpoints, at the root where clients look them up
+	jwt.WellKnownRoutes(routes, jwtHandler)
+	oidc.WellKnownRoutes(routes, oidcHandler)
+
+	// Storage routes, at the path the signed download URLs were issued for
+	if localStorage, ok := s.storage.(*storage.Local); ok {
+		routes.Handle("GET "+storage.LocalDownloadPath+"{key...}", route.Public, route.PermissionNone, localStorage.DownloadHandler(b.logger))
+	}
+
+	// Version 1 of the API; the unversioned paths of the clients written before it are
+	// served from the same routes
+	v1 := routes.Group(route.V1)
+	routes.Alias(route.APIPrefix, route.V1)
+
+	selftest.Routes(v1, selftestHandler)
+	auth.Routes(v1, authHandler, introspectionHandler, b.cfg.BodyLimits)
+	oidc.Routes(v1, oidcHandler, b.cfg.BodyLimits)
+
+	user.Routes(v1, userHandler)
+	audit.Routes(v1, auditHandler)
+	avatar.Routes(v1, avatarHandler, b.cfg.BodyLimits)
+	studentid.Routes(v1, studentIDHandler)
+	push.Routes(v1, pushHandler)
+
+	unit.Routes(v1, unitHandler)
+	tenant.Routes(v1, tenantHandler)
+	backup.Routes(v1, backupHandler, b.cfg.BodyLimits)
+	testtenant.Routes(v1, testTenantHandler)
+	group.Routes(v1, groupHandler)
+	tag.Routes(v1, tagHandler)
+	wiki.Routes(v1, wikiHandler)
+	task.Routes(v1, taskHandler)
+	resource.Routes(v1, resourceHandler)
+	finance.Routes(v1, financeHandler, b.cfg.BodyLimits)
+	profile.Routes(v1, profileHandler)
+	quota.Routes(v1, quotaHandler)
+	webhook.Routes(v1, webhookHandler)
+
+	form.Routes(v1, formHandler, favoriteMiddleware)
+	favorite.Routes(v1, favoriteHandler)
+	importer.Routes(v1, importerHandler)
+	publish.Routes(v1, publishHandler)
+	respondent.Routes(v1, respondentHandler)
+	eligibility.Routes(v1, eligibilityHandler)
+	pipeline.Routes(v1, pipelineHandler)
+	question.Routes(v1, questionHandler)
+	response.Routes(v1, responseHandler)
+	submit.Routes(v1, submitHandler)
+	ingest.Routes(v1, ingestHandler)
+	attempt.Routes(v1, attemptHandler)
+	comment.Routes(v1, commentHandler)
+	workflow.Routes(v1, workflowHandler)
+	progress.Routes(v1, progressHandler)
+	approval.Routes(v1, approvalHandler)
+	assignment.Routes(v1, assignmentHandler)
+	grading.Routes(v1, gradingHandler)
+	checkin.Routes(v1, checkinHandler)
+	attendance.Routes(v1, attendanceHandler)
+	event.Routes(v1, eventHandler)
+	payment.Routes(v1, paymentHandler)
+	results.Routes(v1, resultsHandler)
+	ballot.Routes(v1, ballotHandler)
+	delegation.Routes(v1, delegationHandler)
+	announcement.Routes(v1, announcementHandler)
+	pii.Routes(v1, piiHandler)
+	retention.Routes(v1, retentionHandler)
+	export.Routes(v1, exportHandler)
+	upload.Routes(v1, uploadHandler, b.cfg.BodyLimits)
+
+	inbox.Routes(v1, inboxHandler, inboxStreamHandler)
+	search.Routes(v1, searchHandler)
+
+	migration.Routes(v1, migrationHandler)
+	logging.Routes(v1, logLevelHandler)
+	trace.Routes(v1, traceSamplingHandler)
+
+	// Dev login as seeded users instead of Google, and the caught outgoing messages
+	if b.cfg.Dev {
+		devProvider := dev.NewProvider(b.cfg.BaseURL)
+		authHandler.RegisterProvider(devProvider)
+		dev.Routes(v1, dev.NewHandler(b.logger, s.problemWriter, devProvider, s.devCatcher))
+	}
+
+	return routes
+}
@@ -0,0 +1,291 @@
+package app
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/announcement"
+	"NYCU-SDC/core-system-backend/internal/attendance"
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"NYCU-SDC/core-system-backend/internal/avatar"
+	"NYCU-SDC/core-system-backend/internal/backup"
+	"NYCU-SDC/core-system-backend/internal/dev"
+	"NYCU-SDC/core-system-backend/internal/distribute"
+	"NYCU-SDC/core-system-backend/internal/event"
+	"NYCU-SDC/core-system-backend/internal/finance"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/action"
+	"NYCU-SDC/core-system-backend/internal/form/approval"
+	"NYCU-SDC/core-system-backend/internal/form/assignment"
+	"NYCU-SDC/core-system-backend/internal/form/attempt"
+	"NYCU-SDC/core-system-backend/internal/form/ballot"
+	"NYCU-SDC/core-system-backend/internal/form/checkin"
+	"NYCU-SDC/core-system-backend/internal/form/comment"
+	"NYCU-SDC/core-system-backend/internal/form/delegation"
+	"NYCU-SDC/core-system-backend/internal/form/eligibility"
+	"NYCU-SDC/core-system-backend/internal/form/export"
+	"NYCU-SDC/core-system-backend/internal/form/favorite"
+	"NYCU-SDC/core-system-backend/internal/form/grading"
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"NYCU-SDC/core-system-backend/internal/form/ingest"
+	"NYCU-SDC/core-system-backend/internal/form/payment"
+	"NYCU-SDC/core-system-backend/internal/form/pii"
+	"NYCU-SDC/core-system-backend/internal/form/pipeline"
+	"NYCU-SDC/core-system-backend/internal/form/progress"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/respondent"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/results"
+	"NYCU-SDC/core-system-backend/internal/form/retention"
+	"NYCU-SDC/core-system-backend/internal/form/submit"
+	"NYCU-SDC/core-system-backend/internal/form/upload"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/group"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/logging"
+	"NYCU-SDC/core-system-backend/internal/magiclink"
+	"NYCU-SDC/core-system-backend/internal/mail"
+	"NYCU-SDC/core-system-backend/internal/migration"
+	"NYCU-SDC/core-system-backend/internal/oidc"
+	"NYCU-SDC/core-system-backend/internal/profile"
+	"NYCU-SDC/core-system-backend/internal/publish"
+	"NYCU-SDC/core-system-backend/internal/push"
+	"NYCU-SDC/core-system-backend/internal/quota"
+	"NYCU-SDC/core-system-backend/internal/realtime"
+	"NYCU-SDC/core-system-backend/internal/resource"
+	"NYCU-SDC/core-system-backend/internal/search"
+	"NYCU-SDC/core-system-backend/internal/secrets"
+	"NYCU-SDC/core-system-backend/internal/selftest"
+	"NYCU-SDC/core-system-backend/internal/storage"
+	"NYCU-SDC/core-system-backend/internal/studentid"
+	"NYCU-SDC/core-system-backend/internal/tag"
+	"NYCU-SDC/core-system-backend/internal/task"
+	"NYCU-SDC/core-system-backend/internal/tenant"
+	"NYCU-SDC/core-system-backend/internal/testtenant"
+	"NYCU-SDC/core-system-backend/internal/trace"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"NYCU-SDC/core-system-backend/internal/wiki"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+)
+
+// services holds everything the handlers, middleware and background jobs share
+type services struct {
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+	storage       storage.Storage
+	traceSampler  *trace.Sampler
+	migration     *migration.Service
+	devCatcher    *dev.Catcher
+	keyring       *secrets.Keyring
+
+	logLevel     *logging.Level
+	user         *user.Service
+	jwt          *jwt.Service
+	tenant       *tenant.Service
+	unit         *unit.Service
+	audit        *audit.Service
+	oidc         *oidc.Service
+	magicLink    *magiclink.Service
+	webhook      *webhook.Service
+	group        *group.Service
+	tag          *tag.Service
+	wiki         *wiki.Service
+	task         *task.Service
+	resource     *resource.Service
+	finance      *finance.Service
+	profile      *profile.Service
+	quota        *quota.Service
+	studentID    *studentid.Service
+	distribute   *distribute.Service
+	question     *question.Service
+	push         *push.Service
+	realtime     *realtime.Hub
+	inbox        *inbox.Service
+	response     *response.Service
+	form         *form.Service
+	eligibility  *eligibility.Service
+	pipeline     *pipeline.Service
+	workflow     *workflow.Service
+	action       *action.Service
+	approval     *approval.Service
+	comment      *comment.Service
+	pii          *pii.Service
+	retention    *retention.Service
+	export       *export.Service
+	avatar       *avatar.Service
+	upload       *upload.Service
+	assignment   *assignment.Service
+	attempt      *attempt.Service
+	grading      *grading.Service
+	checkin      *checkin.Service
+	attendance   *attendance.Service
+	event        *event.Service
+	payment      *payment.Service
+	results      *results.Service
+	ballot       *ballot.Service
+	delegation   *delegation.Service
+	announcement *announcement.Service
+	submit       *submit.Service
+	ingest       *ingest.Service
+	publish      *publish.Service
+	respondent   *respondent.Service
+	favorite     *favorite.Service
+	importer     *importer.Service
+	backup       *backup.Service
+	testTenant   *testtenant.Service
+	search       *search.Service
+	progress     *progress.Service
+	selftest     *selftest.Service
+}
+
+// newServices builds the services on the database and the external dependencies of
+// the builder, the ones left unset being built from the config
+func (b *Builder) newServices() (*services, error) {
+	s := &services{
+		validator:     internal.NewValidator(),
+		problemWriter: internal.NewProblemWriter(),
+		traceSampler:  b.traceSampler,
+		migration:     migration.NewService(b.logger, b.cfg.MigrationSource, b.cfg.DatabaseURL),
+	}
+	if s.traceSampler == nil {
+		s.traceSampler = trace.NewSampler(b.logger, b.cfg.TraceSampling)
+	}
+
+	var err error
+	s.storage = b.storage
+	if s.storage == nil {
+		s.storage, err = storage.New(b.cfg.Storage, b.cfg.Secret, b.cfg.BaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize storage: %w", err)
+		}
+	}
+
+	uploadScanner := b.uploadScanner
+	if uploadScanner == nil && b.cfg.ClamAVAddress != "" {
+		uploadScanner = upload.NewClamAV(b.cfg.ClamAVAddress, time.Minute)
+	}
+
+	webPushSender, fcmSender := b.webPushSender, b.fcmSender
+	if webPushSender == nil && b.cfg.Push.VAPIDPrivateKey != "" {
+		webPushSender, err = push.NewWebPush(b.cfg.Push.VAPIDPublicKey, b.cfg.Push.VAPIDPrivateKey, b.cfg.Push.VAPIDSubject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize web push: %w", err)
+		}
+	}
+	if fcmSender == nil && b.cfg.Push.FCMCredentialsFile != "" {
+		fcmSender, err = push.NewFCM(context.Background(), b.cfg.Push.FCMCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize FCM: %w", err)
+		}
+	}
+
+	// Dev mode catches the notifications of the platforms left unconfigured
+	if b.cfg.Dev {
+		s.devCatcher = dev.NewCatcher(b.logger, dev.DefaultCatcherCapacity)
+		if webPushSender == nil {
+			webPushSender = s.devCatcher
+		}
+		if fcmSender == nil {
+			fcmSender = s.devCatcher
+		}
+	}
+
+	// Mail goes through the SMTP server, or is caught in dev mode without one
+	var mailSender mail.Sender
+	if b.cfg.Mail.Host != "" {
+		mailSender = mail.NewSMTP(b.cfg.Mail)
+	} else if s.devCatcher != nil {
+		mailSender = s.devCatcher
+	}
+	if b.cfg.MagicLink.Enabled && mailSender == nil {
+		return nil, fmt.Errorf("magic link login requires a mail host")
+	}
+
+	jwtKeys, err := jwt.LoadKeySet(b.cfg.JWT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT signing keys: %w", err)
+	}
+	if jwtKeys == nil {
+		b.logger.Warn("No JWT key files configured, signing access tokens with the HMAC secret")
+	}
+
+	s.keyring, err = secrets.NewKeyring(b.cfg.Secrets)
+	if errors.Is(err, secrets.ErrNoKeys) && b.cfg.Dev {
+		b.logger.Warn("No secrets key files configured, sealing integration credentials with a key derived from the secret in dev mode")
+		s.keyring, err = secrets.NewDevKeyring(b.cfg.Secret), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets keys: %w", err)
+	}
+
+	s.logLevel = logging.NewLevel(b.logger, b.logLevel)
+	s.user = user.NewService(b.logger, b.db)
+	s.jwt = jwt.NewService(b.logger, b.db, b.cfg.Secret, b.cfg.OauthProxySecret, jwtKeys, b.cfg.AccessTokenExpiration, b.cfg.RefreshTokenExpiration)
+	s.tenant = tenant.NewService(b.logger, b.db)
+	s.unit = unit.NewService(b.logger, b.db, s.tenant)
+	s.audit = audit.NewService(b.logger, b.db)
+	s.oidc = oidc.NewService(b.logger, b.db, b.cfg.OIDC.Clients)
+	s.magicLink = magiclink.NewService(b.logger, b.db, b.cfg.MagicLink, b.cfg.Secret, b.cfg.BaseURL, mailSender)
+	s.group = group.NewService(b.logger, b.db)
+	s.tag = tag.NewService(b.logger, b.db)
+	s.wiki = wiki.NewService(b.logger, b.db)
+	s.studentID = studentid.NewService(b.logger, b.db)
+	s.distribute = distribute.NewService(b.logger, s.unit, s.group)
+	s.question = question.NewService(b.logger, b.db)
+	s.push = push.NewService(b.logger, b.db, webPushSender, fcmSender)
+	s.realtime = realtime.NewHub(b.logger, b.db, b.cfg.DatabaseURL)
+	s.inbox = inbox.NewService(b.logger, b.db, inbox.Notifiers{s.push, inbox.NewStreamNotifier(s.realtime)})
+	s.task = task.NewService(b.logger, b.db, s.inbox)
+	s.resource = resource.NewService(b.logger, b.db)
+	s.finance = finance.NewService(b.logger, b.db, s.storage)
+	s.profile = profile.NewService(b.logger, b.db)
+	s.quota = quota.NewService(b.logger, b.db, b.cfg.Quota, s.inbox)
+	s.response = response.NewService(b.logger, b.db)
+	s.form = form.NewService(b.logger, b.db, s.response)
+	s.pipeline = pipeline.NewService(b.logger, b.db)
+	s.eligibility = eligibility.NewService(b.logger, b.db, s.user, s.pipeline)
+	s.workflow = workflow.NewService(b.logger, b.db, s.question, s.keyring)
+	s.webhook = webhook.NewService(b.logger, b.db, s.keyring)
+	s.action = action.NewService(b.logger, b.db, s.workflow, s.response, s.webhook)
+	s.approval = approval.NewService(b.logger, b.db, s.workflow, s.response, s.inbox, s.action)
+	s.comment = comment.NewService(b.logger, b.db)
+	s.pii = pii.NewService(b.logger, b.db)
+	s.retention = retention.NewService(b.logger, b.db)
+	s.export = export.NewService(b.logger, b.db, s.storage, s.pii, s.keyring, s.webhook)
+	s.avatar = avatar.NewService(b.logger, s.storage, s.user, b.cfg.BaseURL)
+	s.upload = upload.NewService(b.logger, b.db, s.question, s.storage, s.inbox, uploadScanner)
+	s.assignment = assignment.NewService(b.logger, b.db)
+	s.attempt = attempt.NewService(b.logger, b.db)
+	s.grading = grading.NewService(b.logger, b.db)
+	s.checkin = checkin.NewService(b.logger, b.db, b.cfg.Secret, b.cfg.BaseURL)
+	s.attendance = attendance.NewService(b.logger, b.db)
+	s.event = event.NewService(b.logger, b.db, s.attendance)
+	s.payment = payment.NewService(b.logger, b.db)
+	s.results = results.NewService(b.logger, b.db, s.question, b.cfg.Secret, b.cfg.BaseURL)
+	s.ballot = ballot.NewService(b.logger, b.db, s.form, s.question, s.eligibility)
+	s.delegation = delegation.NewService(b.logger, b.db, s.jwt, s.audit)
+	s.announcement = announcement.NewService(b.logger, b.db)
+	s.submit = submit.NewService(b.logger, s.form, s.question, s.response, s.eligibility, s.approval, s.action, s.attempt, s.assignment, s.ballot, s.event)
+	s.ingest = ingest.NewService(b.logger, b.db, s.keyring, s.form, s.question, s.response, s.ballot, s.assignment)
+	s.publish = publish.NewService(b.logger, s.distribute, s.form, s.inbox)
+	s.respondent = respondent.NewService(b.logger, b.db, s.jwt)
+	s.favorite = favorite.NewService(b.logger, b.db)
+	s.importer = importer.NewService(b.logger, b.db, s.form, s.workflow, s.question)
+	s.backup = backup.NewService(b.logger, b.db, s.tenant, s.importer, s.keyring)
+	s.testTenant = testtenant.NewService(b.logger, b.db, s.backup)
+	s.search = search.NewService(b.logger, b.db)
+	s.progress = progress.NewService(b.logger, b.db, s.workflow, s.response, s.approval, s.action)
+	s.selftest, err = selftest.NewService(b.logger, b.db, s.keyring)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize self-test: %w", err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"NYCU-SDC/core-system-backend/internal/config"
+	"NYCU-SDC/core-system-backend/internal/trace"
+	"context"
+	"errors"
+	"fmt"
+
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.6.1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// NewLogger builds the logger at the given level, for development in debug mode
+func NewLogger(cfg *config.Config, level zap.AtomicLevel, appMetadata []zap.Field) (*zap.Logger, error) {
+	var err error
+	var logger *zap.Logger
+	if cfg.Debug {
+		zapConfig := logutil.ZapDevelopmentConfig()
+		zapConfig.Level = level
+		logger, err = zapConfig.Build()
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Running in debug mode", appMetadata...)
+	} else {
+		zapConfig := logutil.ZapProductionConfig()
+		zapConfig.Level = level
+		logger, err = zapConfig.Build()
+		if err != nil {
+			return nil, err
+		}
+
+		logger = logger.With(appMetadata...)
+	}
+	defer func() {
+		err := logger.Sync()
+		if err != nil {
+			zap.S().Errorw("Failed to sync logger", zap.Error(err))
+		}
+	}()
+
+	return logger, nil
+}
+
+// NewDatabasePool opens the connection pool of the database
+func NewDatabasePool(databaseURL string) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	dbPool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, err
+	}
+	return dbPool, nil
+}
+
+// InitTelemetry sets up traces and metrics, exported to the collector when one is
+// configured. The returned logger provider is nil unless logs are exported too.
+func InitTelemetry(info Info, otelCollectorUrl string, exportLogs bool, sampler sdktrace.Sampler) (func(context.Context) error, *sdklog.LoggerProvider, error) {
+	ctx := context.Background()
+
+	serviceName := semconv.ServiceNameKey.String(info.AppName)
+	serviceVersion := semconv.ServiceVersionKey.String(info.Version)
+	serviceNamespace := semconv.ServiceNamespaceKey.String("example")
+	serviceCommitHash := attribute.String("service.commit_hash", info.CommitHash)
+	serviceEnvironment := semconv.DeploymentEnvironmentKey.String(info.Environment)
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			serviceName,
+			serviceVersion,
+			serviceNamespace,
+			serviceCommitHash,
+			serviceEnvironment,
+		),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	}
+
+	meterOptions := []sdkmetric.Option{
+		sdkmetric.WithResource(res),
+	}
+
+	var loggerProvider *sdklog.LoggerProvider
+
+	if otelCollectorUrl != "" {
+		conn, err := initGrpcConn(otelCollectorUrl)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create gRPC connection: %w", err)
+		}
+
+		traceExporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithGRPCConn(conn))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		}
+
+		bsp := sdktrace.NewBatchSpanProcessor(traceExporter)
+		options = append(options, sdktrace.WithSpanProcessor(trace.NewErrorProcessor(bsp)))
+
+		metricExporter, err := otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithGRPCConn(conn))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create metric exporter: %w", err)
+		}
+
+		meterOptions = append(meterOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+
+		if exportLogs {
+			logExporter, err := otlploggrpc.New(ctx, otlploggrpc.WithGRPCConn(conn))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to create log exporter: %w", err)
+			}
+
+			loggerProvider = sdklog.NewLoggerProvider(
+				sdklog.WithResource(res),
+				sdklog.WithProcessor(sdklog.NewBatchProcessor(logExporter)),
+			)
+		}
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(options...)
+	meterProvider := sdkmetric.NewMeterProvider(meterOptions...)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	shutdown := func(ctx context.Context) error {
+		err := errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+		if loggerProvider != nil {
+			err = errors.Join(err, loggerProvider.Shutdown(ctx))
+		}
+		return err
+	}
+
+	return shutdown, loggerProvider, nil
+}
+
+func initGrpcConn(target string) (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection: %w", err)
+	}
+
+	return conn, nil
+}
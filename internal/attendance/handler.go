@@ -0,0 +1,473 @@
+package attendance
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	GetEvent(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (AttendanceEvent, error)
+	SetEvent(ctx context.Context, formID uuid.UUID, startsAt time.Time, lateAfterMinutes int32, userID uuid.UUID) (AttendanceEvent, error)
+	DeleteEvent(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+	ListRecords(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (AttendanceEvent, []Record, error)
+	Export(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]byte, error)
+	Mark(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, status AttendanceStatus, userID uuid.UUID) (AttendanceMark, error)
+	ClearMark(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID) error
+	UnitStatistics(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, period Range, userID uuid.UUID) (UnitStatistics, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+// EventRequest sets when the event of the form starts; respondents checking in more
+// than lateAfterMinutes later are late, DefaultLateAfterMinutes when left out
+type EventRequest struct {
+	StartsAt         time.Time `json:"startsAt" validate:"required"`
+	LateAfterMinutes *int32    `json:"lateAfterMinutes" validate:"omitempty,min=0,max=1440"`
+}
+
+type EventResponse struct {
+	FormID           string    `json:"formId"`
+	StartsAt         time.Time `json:"startsAt"`
+	LateAfterMinutes int32     `json:"lateAfterMinutes"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// MarkRequest overrides the status the check-in gives a respondent
+type MarkRequest struct {
+	Status string `json:"status" validate:"required,oneof=present late absent excused"`
+}
+
+type MarkResponse struct {
+	ResponseID string    `json:"responseId"`
+	Status     string    `json:"status"`
+	MarkedAt   time.Time `json:"markedAt"`
+}
+
+type RecordResponse struct {
+	ResponseID  string     `json:"responseId"`
+	UserID      string     `json:"userId"`
+	Name        string     `json:"name"`
+	Username    string     `json:"username"`
+	Status      string     `json:"status"`
+	CheckedInAt *time.Time `json:"checkedInAt,omitempty"`
+	Marked      bool       `json:"marked"`
+}
+
+type RecordsResponse struct {
+	Event   EventResponse    `json:"event"`
+	Counts  Counts           `json:"counts"`
+	Records []RecordResponse `json:"records"`
+}
+
+type EventStatisticsResponse struct {
+	FormID   string    `json:"formId"`
+	Title    string    `json:"title"`
+	StartsAt time.Time `json:"startsAt"`
+	Counts   Counts    `json:"counts"`
+	Rate     float64   `json:"rate"`
+}
+
+type MemberStatisticsResponse struct {
+	UserID   string  `json:"userId"`
+	Name     string  `json:"name"`
+	Username string  `json:"username"`
+	Counts   Counts  `json:"counts"`
+	Rate     float64 `json:"rate"`
+}
+
+type UnitStatisticsResponse struct {
+	UnitID  string                     `json:"unitId"`
+	Events  []EventStatisticsResponse  `json:"events"`
+	Members []MemberStatisticsResponse `json:"members"`
+}
+
+func ToEventResponse(event AttendanceEvent) EventResponse {
+	return EventResponse{
+		FormID:           event.FormID.String(),
+		StartsAt:         event.StartsAt.Time,
+		LateAfterMinutes: event.LateAfterMinutes,
+		UpdatedAt:        event.UpdatedAt.Time,
+	}
+}
+
+func ToRecordsResponse(event AttendanceEvent, records []Record) RecordsResponse {
+	counts := newCounts()
+	responses := make([]RecordResponse, len(records))
+	for i, record := range records {
+		counts[record.Status]++
+		responses[i] = RecordResponse{
+			ResponseID:  record.ResponseID.String(),
+			UserID:      record.SubmittedBy.String(),
+			Name:        record.Name,
+			Username:    record.Username,
+			Status:      string(record.Status),
+			CheckedInAt: record.CheckedInAt,
+			Marked:      record.Marked,
+		}
+	}
+
+	return RecordsResponse{
+		Event:   ToEventResponse(event),
+		Counts:  counts,
+		Records: responses,
+	}
+}
+
+func ToUnitStatisticsResponse(unitID uuid.UUID, statistics UnitStatistics) UnitStatisticsResponse {
+	events := make([]EventStatisticsResponse, len(statistics.Events))
+	for i, event := range statistics.Events {
+		events[i] = EventStatisticsResponse{
+			FormID:   event.FormID.String(),
+			Title:    event.Title,
+			StartsAt: event.StartsAt,
+			Counts:   event.Counts,
+			Rate:     event.Counts.Rate(),
+		}
+	}
+
+	members := make([]MemberStatisticsResponse, len(statistics.Members))
+	for i, member := range statistics.Members {
+		members[i] = MemberStatisticsResponse{
+			UserID:   member.UserID.String(),
+			Name:     member.Name,
+			Username: member.Username,
+			Counts:   member.Counts,
+			Rate:     member.Counts.Rate(),
+		}
+	}
+
+	return UnitStatisticsResponse{
+		UnitID:  unitID.String(),
+		Events:  events,
+		Members: members,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("attendance/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// parseRange reads the optional from and before query parameters, RFC 3339 times
+func parseRange(r *http.Request) (Range, error) {
+	var period Range
+	for name, target := range map[string]*time.Time{"from": &period.From, "before": &period.Before} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return Range{}, fmt.Errorf("%w: %s must be an RFC 3339 time", internal.ErrInvalidQueryParameter, name)
+		}
+		*target = parsed
+	}
+	return period, nil
+}
+
+func (h *Handler) GetEventHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetEventHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	event, err := h.store.GetEvent(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToEventResponse(event))
+}
+
+func (h *Handler) SetEventHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetEventHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req EventRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	lateAfterMinutes := int32(DefaultLateAfterMinutes)
+	if req.LateAfterMinutes != nil {
+		lateAfterMinutes = *req.LateAfterMinutes
+	}
+
+	event, err := h.store.SetEvent(traceCtx, formID, req.StartsAt, lateAfterMinutes, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToEventResponse(event))
+}
+
+func (h *Handler) DeleteEventHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteEventHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.DeleteEvent(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) ListRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListRecordsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	event, records, err := h.store.ListRecords(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToRecordsResponse(event, records))
+}
+
+func (h *Handler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ExportHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	content, err := h.store.Export(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "attendance-"+formID.String()+".csv"))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(content)
+	if err != nil {
+		logger.Error("failed to write attendance export", zap.Error(err))
+	}
+}
+
+func (h *Handler) MarkHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "MarkHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, responseID, err := parseResponsePath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req MarkRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	mark, err := h.store.Mark(traceCtx, formID, responseID, AttendanceStatus(req.Status), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, MarkResponse{
+		ResponseID: mark.ResponseID.String(),
+		Status:     string(mark.Status),
+		MarkedAt:   mark.MarkedAt.Time,
+	})
+}
+
+func (h *Handler) ClearMarkHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ClearMarkHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, responseID, err := parseResponsePath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.ClearMark(traceCtx, formID, responseID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// UnitStatisticsHandler counts the attendance at the events of a unit, optionally
+// those starting between the from and before query parameters
+func (h *Handler) UnitStatisticsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UnitStatisticsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	slug, err := internal.GetSlugFromContext(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org slug from context: %w", err), logger)
+		return
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(traceCtx, slug)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org ID by slug: %w", err), logger)
+		return
+	}
+
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	period, err := parseRange(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	statistics, err := h.store.UnitStatistics(traceCtx, orgID, unitID, period, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToUnitStatisticsResponse(unitID, statistics))
+}
+
+func parseResponsePath(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	responseID, err := internal.ParseUUID(r.PathValue("responseId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return formID, responseID, nil
+}
@@ -0,0 +1,84 @@
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
+
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: IsUnitInOrg :one
+SELECT EXISTS(SELECT 1 FROM units WHERE id = @unit_id AND COALESCE(org_id, id) = @org_id::uuid);
+
+-- name: GetEvent :one
+SELECT * FROM attendance_events
+WHERE form_id = @form_id;
+
+-- name: UpsertEvent :one
+INSERT INTO attendance_events (form_id, starts_at, late_after_minutes, updated_by)
+VALUES (@form_id, @starts_at, @late_after_minutes, @updated_by)
+ON CONFLICT (form_id) DO UPDATE
+SET starts_at = EXCLUDED.starts_at,
+    late_after_minutes = EXCLUDED.late_after_minutes,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
+
+-- name: UpsertStart :one
+-- Moves the start of the event of the form, keeping its grace period when it is tracked already
+INSERT INTO attendance_events (form_id, starts_at, updated_by)
+VALUES (@form_id, @starts_at, @updated_by)
+ON CONFLICT (form_id) DO UPDATE
+SET starts_at = EXCLUDED.starts_at,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteEvent :execrows
+DELETE FROM attendance_events
+WHERE form_id = @form_id;
+
+-- name: ResponseExists :one
+SELECT EXISTS(SELECT 1 FROM form_responses WHERE id = @response_id AND form_id = @form_id AND NOT is_test);
+
+-- name: UpsertMark :one
+INSERT INTO attendance_marks (response_id, form_id, status, marked_by)
+VALUES (@response_id, @form_id, @status, @marked_by)
+ON CONFLICT (response_id) DO UPDATE
+SET status = EXCLUDED.status, marked_by = EXCLUDED.marked_by, marked_at = now()
+RETURNING *;
+
+-- name: DeleteMark :execrows
+DELETE FROM attendance_marks
+WHERE response_id = @response_id AND form_id = @form_id;
+
+-- name: ListRecords :many
+SELECT r.id AS response_id, r.submitted_by, u.name, u.username, c.checked_in_at, m.status AS marked_status FROM form_responses r
+LEFT JOIN users u ON u.id = r.submitted_by
+LEFT JOIN form_checkins c ON c.response_id = r.id
+LEFT JOIN attendance_marks m ON m.response_id = r.id
+WHERE r.form_id = @form_id AND NOT r.is_test
+ORDER BY u.name ASC NULLS LAST, r.created_at ASC;
+
+-- name: ListUnitEvents :many
+-- Events of the forms of the unit starting in the range, either end left open when null
+SELECT e.form_id, e.starts_at, e.late_after_minutes, f.title FROM attendance_events e
+JOIN forms f ON f.id = e.form_id
+WHERE f.unit_id = @unit_id
+  AND (sqlc.narg(starts_from)::timestamptz IS NULL OR e.starts_at >= sqlc.narg(starts_from))
+  AND (sqlc.narg(starts_before)::timestamptz IS NULL OR e.starts_at < sqlc.narg(starts_before))
+ORDER BY e.starts_at ASC;
+
+-- name: ListUnitRecords :many
+SELECT e.form_id, r.id AS response_id, r.submitted_by, u.name, u.username, c.checked_in_at, m.status AS marked_status FROM attendance_events e
+JOIN forms f ON f.id = e.form_id
+JOIN form_responses r ON r.form_id = e.form_id AND NOT r.is_test
+LEFT JOIN users u ON u.id = r.submitted_by
+LEFT JOIN form_checkins c ON c.response_id = r.id
+LEFT JOIN attendance_marks m ON m.response_id = r.id
+WHERE f.unit_id = @unit_id
+  AND (sqlc.narg(starts_from)::timestamptz IS NULL OR e.starts_at >= sqlc.narg(starts_from))
+  AND (sqlc.narg(starts_before)::timestamptz IS NULL OR e.starts_at < sqlc.narg(starts_before))
+ORDER BY e.starts_at ASC, r.created_at ASC;
\ No newline at end of file
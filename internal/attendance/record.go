@@ -0,0 +1,151 @@
+package attendance
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AttendanceStatusExpected is the status of a respondent who has not checked in while
+// they can still make it on time. It is computed, never stored.
+const AttendanceStatusExpected AttendanceStatus = "expected"
+
+// statuses lists every status a record can have, in the order the counts show them
+var statuses = []AttendanceStatus{
+	AttendanceStatusPresent,
+	AttendanceStatusLate,
+	AttendanceStatusAbsent,
+	AttendanceStatusExcused,
+	AttendanceStatusExpected,
+}
+
+// Record is the attendance of one respondent at an event. Marked is set when an admin
+// set the status by hand, overriding the check-in.
+type Record struct {
+	ResponseID  uuid.UUID
+	SubmittedBy uuid.UUID
+	Name        string
+	Username    string
+	CheckedInAt *time.Time
+	Status      AttendanceStatus
+	Marked      bool
+}
+
+// cutoff is the time from which a check-in is late
+func cutoff(startsAt time.Time, lateAfterMinutes int32) time.Time {
+	return startsAt.Add(time.Duration(lateAfterMinutes) * time.Minute)
+}
+
+// newRecord works out the status of a respondent: a mark wins over the check-in, and
+// respondents who did not check in are absent once the cutoff has passed
+func newRecord(responseID, submittedBy uuid.UUID, name, username pgtype.Text, checkedInAt pgtype.Timestamptz, marked NullAttendanceStatus, cutoff time.Time, now time.Time) Record {
+	record := Record{
+		ResponseID:  responseID,
+		SubmittedBy: submittedBy,
+		Name:        name.String,
+		Username:    username.String,
+	}
+	if checkedInAt.Valid {
+		record.CheckedInAt = &checkedInAt.Time
+	}
+
+	switch {
+	case marked.Valid:
+		record.Status = marked.AttendanceStatus
+		record.Marked = true
+	case checkedInAt.Valid && checkedInAt.Time.After(cutoff):
+		record.Status = AttendanceStatusLate
+	case checkedInAt.Valid:
+		record.Status = AttendanceStatusPresent
+	case now.Before(cutoff):
+		record.Status = AttendanceStatusExpected
+	default:
+		record.Status = AttendanceStatusAbsent
+	}
+	return record
+}
+
+// Counts tallies records by status, every status present
+type Counts map[AttendanceStatus]int
+
+func newCounts() Counts {
+	counts := make(Counts, len(statuses))
+	for _, status := range statuses {
+		counts[status] = 0
+	}
+	return counts
+}
+
+// Rate is the share of the respondents due at the event who came, late or not.
+// Excused respondents and the ones still expected are left out; 0 when none is due.
+func (c Counts) Rate() float64 {
+	attended := c[AttendanceStatusPresent] + c[AttendanceStatusLate]
+	due := attended + c[AttendanceStatusAbsent]
+	if due == 0 {
+		return 0
+	}
+	return float64(attended) / float64(due)
+}
+
+type EventStatistics struct {
+	FormID   uuid.UUID
+	Title    string
+	StartsAt time.Time
+	Counts   Counts
+}
+
+// MemberStatistics counts the events of the unit a user registered for by status
+type MemberStatistics struct {
+	UserID   uuid.UUID
+	Name     string
+	Username string
+	Counts   Counts
+}
+
+// UnitStatistics sums up the attendance at the events of a unit, the events in the
+// order they started and the members by name
+type UnitStatistics struct {
+	Events  []EventStatistics
+	Members []MemberStatistics
+}
+
+// buildCSV writes one row per respondent of the event
+func buildCSV(records []Record) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	err := writer.Write([]string{"response_id", "user_id", "name", "username", "status", "checked_in_at", "marked"})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		checkedInAt := ""
+		if record.CheckedInAt != nil {
+			checkedInAt = record.CheckedInAt.UTC().Format(time.RFC3339)
+		}
+		err = writer.Write([]string{
+			record.ResponseID.String(),
+			record.SubmittedBy.String(),
+			record.Name,
+			record.Username,
+			string(record.Status),
+			checkedInAt,
+			strconv.FormatBool(record.Marked),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
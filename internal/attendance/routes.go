@@ -0,0 +1,18 @@
+package attendance
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the events of forms, the attendance of their respondents and the
+// attendance statistics of a unit
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/attendance/event", route.Authenticated, route.PermissionOrgAdmin, h.GetEventHandler)
+	r.Handle("PUT /forms/{id}/attendance/event", route.Authenticated, route.PermissionOrgAdmin, h.SetEventHandler)
+	r.Handle("DELETE /forms/{id}/attendance/event", route.Authenticated, route.PermissionOrgAdmin, h.DeleteEventHandler)
+	r.Handle("GET /forms/{id}/attendance", route.Kiosk, route.PermissionOrgAdmin, h.ListRecordsHandler)
+	r.Handle("GET /forms/{id}/attendance/export", route.Authenticated, route.PermissionOrgAdmin, h.ExportHandler)
+	r.Handle("PUT /forms/{formId}/responses/{responseId}/attendance", route.Kiosk, route.PermissionOrgAdmin, h.MarkHandler)
+	r.Handle("DELETE /forms/{formId}/responses/{responseId}/attendance", route.Kiosk, route.PermissionOrgAdmin, h.ClearMarkHandler)
+	r.Handle("GET /orgs/{slug}/units/{id}/attendance", route.TenantAuthenticated, route.PermissionOrgAdmin, h.UnitStatisticsHandler)
+}
@@ -0,0 +1,404 @@
+package attendance
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DefaultLateAfterMinutes is the grace period after the start of an event when none is set
+const DefaultLateAfterMinutes = 10
+
+type Querier interface {
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	IsUnitInOrg(ctx context.Context, arg IsUnitInOrgParams) (bool, error)
+	GetEvent(ctx context.Context, formID uuid.UUID) (AttendanceEvent, error)
+	UpsertEvent(ctx context.Context, arg UpsertEventParams) (AttendanceEvent, error)
+	UpsertStart(ctx context.Context, arg UpsertStartParams) (AttendanceEvent, error)
+	DeleteEvent(ctx context.Context, formID uuid.UUID) (int64, error)
+	ResponseExists(ctx context.Context, arg ResponseExistsParams) (bool, error)
+	UpsertMark(ctx context.Context, arg UpsertMarkParams) (AttendanceMark, error)
+	DeleteMark(ctx context.Context, arg DeleteMarkParams) (int64, error)
+	ListRecords(ctx context.Context, formID uuid.UUID) ([]ListRecordsRow, error)
+	ListUnitEvents(ctx context.Context, arg ListUnitEventsParams) ([]ListUnitEventsRow, error)
+	ListUnitRecords(ctx context.Context, arg ListUnitRecordsParams) ([]ListUnitRecordsRow, error)
+}
+
+// Range bounds the start of the events counted in the statistics of a unit; a zero
+// time leaves that end open
+type Range struct {
+	From   time.Time
+	Before time.Time
+}
+
+func (r Range) params(unitID uuid.UUID) (pgtype.UUID, pgtype.Timestamptz, pgtype.Timestamptz) {
+	return pgtype.UUID{Bytes: unitID, Valid: true},
+		pgtype.Timestamptz{Time: r.From, Valid: !r.From.IsZero()},
+		pgtype.Timestamptz{Time: r.Before, Valid: !r.Before.IsZero()}
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("attendance/service"),
+	}
+}
+
+// requireFormAdmin allows the owner of the organization of the form only
+func (s *Service) requireFormAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func (s *Service) getEvent(ctx context.Context, logger *zap.Logger, formID uuid.UUID) (AttendanceEvent, error) {
+	event, err := s.queries.GetEvent(ctx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return AttendanceEvent{}, internal.ErrAttendanceEventNotFound
+		}
+		return AttendanceEvent{}, databaseutil.WrapDBErrorWithKeyValue(err, "attendance_events", "form_id", formID.String(), logger, "get attendance event")
+	}
+	return event, nil
+}
+
+func (s *Service) GetEvent(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (AttendanceEvent, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetEvent")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireFormAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return AttendanceEvent{}, err
+	}
+
+	event, err := s.getEvent(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return AttendanceEvent{}, err
+	}
+	return event, nil
+}
+
+// SetEvent makes the form the registration of an event starting at startsAt, check-ins
+// after the grace period counting as late
+func (s *Service) SetEvent(ctx context.Context, formID uuid.UUID, startsAt time.Time, lateAfterMinutes int32, userID uuid.UUID) (AttendanceEvent, error) {
+	traceCtx, span := s.tracer.Start(ctx, "SetEvent")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireFormAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return AttendanceEvent{}, err
+	}
+
+	event, err := s.queries.UpsertEvent(traceCtx, UpsertEventParams{
+		FormID:           formID,
+		StartsAt:         pgtype.Timestamptz{Time: startsAt, Valid: true},
+		LateAfterMinutes: lateAfterMinutes,
+		UpdatedBy:        pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "attendance_events", "form_id", formID.String(), logger, "set attendance event")
+		span.RecordError(err)
+		return AttendanceEvent{}, err
+	}
+
+	logger.Info("Set attendance event", zap.String("form_id", formID.String()), zap.Time("starts_at", startsAt))
+	return event, nil
+}
+
+// SetStart tracks attendance on the form from startsAt, keeping the grace period already
+// set. It backs the events of an organization, which check their own admins.
+func (s *Service) SetStart(ctx context.Context, formID uuid.UUID, startsAt time.Time, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "SetStart")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	_, err := s.queries.UpsertStart(traceCtx, UpsertStartParams{
+		FormID:    formID,
+		StartsAt:  pgtype.Timestamptz{Time: startsAt, Valid: true},
+		UpdatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "attendance_events", "form_id", formID.String(), logger, "set attendance event start")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteEvent stops tracking attendance on the form; its check-ins and marks are kept
+func (s *Service) DeleteEvent(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "DeleteEvent")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireFormAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.DeleteEvent(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "attendance_events", "form_id", formID.String(), logger, "delete attendance event")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrAttendanceEventNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListRecords returns the event of the form with the attendance of each of its
+// respondents, test responses left out
+func (s *Service) ListRecords(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (AttendanceEvent, []Record, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListRecords")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireFormAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return AttendanceEvent{}, nil, err
+	}
+
+	event, err := s.getEvent(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return AttendanceEvent{}, nil, err
+	}
+
+	rows, err := s.queries.ListRecords(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "list attendance records")
+		span.RecordError(err)
+		return AttendanceEvent{}, nil, err
+	}
+
+	now := time.Now()
+	eventCutoff := cutoff(event.StartsAt.Time, event.LateAfterMinutes)
+	records := make([]Record, len(rows))
+	for i, row := range rows {
+		records[i] = newRecord(row.ResponseID, row.SubmittedBy, row.Name, row.Username, row.CheckedInAt, row.MarkedStatus, eventCutoff, now)
+	}
+
+	return event, records, nil
+}
+
+// Export writes the attendance records of the form as CSV
+func (s *Service) Export(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]byte, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Export")
+	defer span.End()
+
+	_, records, err := s.ListRecords(traceCtx, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	content, err := buildCSV(records)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return content, nil
+}
+
+// Mark sets the status of the response by hand, such as excusing a respondent who let
+// the organizers know or marking present one who could not scan their code
+func (s *Service) Mark(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, status AttendanceStatus, userID uuid.UUID) (AttendanceMark, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Mark")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireFormAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return AttendanceMark{}, err
+	}
+
+	exists, err := s.queries.ResponseExists(traceCtx, ResponseExistsParams{ResponseID: responseID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "check response")
+		span.RecordError(err)
+		return AttendanceMark{}, err
+	}
+	if !exists {
+		err = internal.ErrResponseNotFound
+		span.RecordError(err)
+		return AttendanceMark{}, err
+	}
+
+	mark, err := s.queries.UpsertMark(traceCtx, UpsertMarkParams{
+		ResponseID: responseID,
+		FormID:     formID,
+		Status:     status,
+		MarkedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "attendance_marks", "response_id", responseID.String(), logger, "mark attendance")
+		span.RecordError(err)
+		return AttendanceMark{}, err
+	}
+
+	logger.Info("Marked attendance", zap.String("response_id", responseID.String()), zap.String("status", string(status)))
+	return mark, nil
+}
+
+// ClearMark removes the mark of the response, its check-in giving its status again
+func (s *Service) ClearMark(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "ClearMark")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireFormAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.DeleteMark(traceCtx, DeleteMarkParams{ResponseID: responseID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "attendance_marks", "response_id", responseID.String(), logger, "clear attendance mark")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrAttendanceMarkNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// UnitStatistics counts the attendance at the events of the unit, which must belong to
+// the organization, by event and by member
+func (s *Service) UnitStatistics(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, period Range, userID uuid.UUID) (UnitStatistics, error) {
+	traceCtx, span := s.tracer.Start(ctx, "UnitStatistics")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	isAdmin, err := s.queries.IsOrgAdmin(traceCtx, IsOrgAdminParams{OrgID: orgID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check organization admin")
+		span.RecordError(err)
+		return UnitStatistics{}, err
+	}
+	if !isAdmin {
+		err = internal.ErrNotOrgAdmin
+		span.RecordError(err)
+		return UnitStatistics{}, err
+	}
+
+	inOrg, err := s.queries.IsUnitInOrg(traceCtx, IsUnitInOrgParams{UnitID: unitID, OrgID: orgID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "units", "id", unitID.String(), logger, "check unit organization")
+		span.RecordError(err)
+		return UnitStatistics{}, err
+	}
+	if !inOrg {
+		err = internal.ErrUnitNotFound
+		span.RecordError(err)
+		return UnitStatistics{}, err
+	}
+
+	unit, from, before := period.params(unitID)
+	events, err := s.queries.ListUnitEvents(traceCtx, ListUnitEventsParams{UnitID: unit, StartsFrom: from, StartsBefore: before})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "attendance_events", "unit_id", unitID.String(), logger, "list unit attendance events")
+		span.RecordError(err)
+		return UnitStatistics{}, err
+	}
+
+	rows, err := s.queries.ListUnitRecords(traceCtx, ListUnitRecordsParams{UnitID: unit, StartsFrom: from, StartsBefore: before})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "attendance_events", "unit_id", unitID.String(), logger, "list unit attendance records")
+		span.RecordError(err)
+		return UnitStatistics{}, err
+	}
+
+	statistics := UnitStatistics{
+		Events:  make([]EventStatistics, len(events)),
+		Members: []MemberStatistics{},
+	}
+	eventIndex := make(map[uuid.UUID]int, len(events))
+	cutoffs := make([]time.Time, len(events))
+	for i, event := range events {
+		statistics.Events[i] = EventStatistics{
+			FormID:   event.FormID,
+			Title:    event.Title,
+			StartsAt: event.StartsAt.Time,
+			Counts:   newCounts(),
+		}
+		eventIndex[event.FormID] = i
+		cutoffs[i] = cutoff(event.StartsAt.Time, event.LateAfterMinutes)
+	}
+
+	now := time.Now()
+	memberIndex := make(map[uuid.UUID]int)
+	for _, row := range rows {
+		i, ok := eventIndex[row.FormID]
+		if !ok {
+			continue
+		}
+		record := newRecord(row.ResponseID, row.SubmittedBy, row.Name, row.Username, row.CheckedInAt, row.MarkedStatus, cutoffs[i], now)
+		statistics.Events[i].Counts[record.Status]++
+
+		j, ok := memberIndex[row.SubmittedBy]
+		if !ok {
+			j = len(statistics.Members)
+			memberIndex[row.SubmittedBy] = j
+			statistics.Members = append(statistics.Members, MemberStatistics{
+				UserID:   row.SubmittedBy,
+				Name:     record.Name,
+				Username: record.Username,
+				Counts:   newCounts(),
+			})
+		}
+		statistics.Members[j].Counts[record.Status]++
+	}
+
+	slices.SortFunc(statistics.Members, func(a, b MemberStatistics) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.Username, b.Username))
+	})
+
+	return statistics, nil
+}
@@ -0,0 +1,155 @@
+package audit_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedProxies_Resolve(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		forwarded  []string
+		expected   string
+	}
+
+	testCases := []testCase{
+		{name: "Direct client", proxies: []string{"10.0.0.0/8"}, remoteAddr: "203.0.113.7:5555", expected: "203.0.113.7"},
+		{
+			name:       "Header from an untrusted peer is ignored",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "203.0.113.7:5555",
+			forwarded:  []string{"198.51.100.1"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "Header is ignored with no trusted proxies",
+			remoteAddr: "10.0.0.2:5555",
+			forwarded:  []string{"198.51.100.1"},
+			expected:   "10.0.0.2",
+		},
+		{
+			name:       "Hop appended by a trusted proxy",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:5555",
+			forwarded:  []string{"203.0.113.7"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "Spoofed hops before the one the proxy appended",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:5555",
+			forwarded:  []string{"198.51.100.1, 192.0.2.9, 203.0.113.7"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "Chain of trusted proxies",
+			proxies:    []string{"10.0.0.0/8", "172.16.0.1"},
+			remoteAddr: "10.0.0.2:5555",
+			forwarded:  []string{"198.51.100.1, 203.0.113.7", "172.16.0.1"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "Every hop trusted",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:5555",
+			forwarded:  []string{"10.0.0.3"},
+			expected:   "10.0.0.3",
+		},
+		{
+			name:       "Malformed hop stops the walk",
+			proxies:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:5555",
+			forwarded:  []string{"203.0.113.7, unknown"},
+			expected:   "10.0.0.2",
+		},
+		{
+			name:       "IPv6 proxy",
+			proxies:    []string{"fd00::/8"},
+			remoteAddr: "[fd00::2]:5555",
+			forwarded:  []string{"2001:db8::7"},
+			expected:   "2001:db8::7",
+		},
+		{
+			name:       "IPv4-mapped peer",
+			proxies:    []string{"10.0.0.2"},
+			remoteAddr: "[::ffff:10.0.0.2]:5555",
+			forwarded:  []string{"203.0.113.7"},
+			expected:   "203.0.113.7",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			proxies, err := audit.ParseTrustedProxies(tc.proxies)
+			require.NoError(t, err)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			require.Equal(t, tc.expected, proxies.Resolve(r))
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		values      []string
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{name: "Ranges and addresses", values: []string{"10.0.0.0/8", " 172.16.0.1", "fd00::/8", ""}},
+		{name: "Malformed address", values: []string{"10.0.0"}, expectedErr: true},
+		{name: "Malformed range", values: []string{"10.0.0.0/33"}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := audit.ParseTrustedProxies(tc.values)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+
+	proxies, err := audit.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	var clientIP string
+	handler := proxies.ClientIPMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		clientIP = audit.ClientIP(r)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:5555"
+	r.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+	handler(httptest.NewRecorder(), r)
+	require.Equal(t, "203.0.113.7", clientIP)
+
+	// Requests the middleware did not see fall back to the peer of the connection
+	require.Equal(t, "10.0.0.2", audit.ClientIP(r))
+}
@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	pagutil "github.com/NYCU-SDC/summer/pkg/pagination"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, action NullAuditAction, page int, size int) ([]AuditLog, error)
+	CountByUser(ctx context.Context, userID uuid.UUID, action NullAuditAction) (int64, error)
+	ListSecurityEventsByUser(ctx context.Context, userID uuid.UUID, page int, size int) ([]SecurityEvent, error)
+	CountSecurityEventsByUser(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type ActivityResponse struct {
+	ID         string    `json:"id"`
+	Action     string    `json:"action"`
+	Route      string    `json:"route"`
+	Path       string    `json:"path"`
+	StatusCode int       `json:"statusCode"`
+	IPAddress  string    `json:"ipAddress"`
+	UserAgent  string    `json:"userAgent"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func ToActivityResponse(log AuditLog) ActivityResponse {
+	return ActivityResponse{
+		ID:         log.ID.String(),
+		Action:     string(log.Action),
+		Route:      log.Route,
+		Path:       log.Path,
+		StatusCode: int(log.StatusCode),
+		IPAddress:  log.IpAddress,
+		UserAgent:  log.UserAgent,
+		CreatedAt:  log.CreatedAt.Time,
+	}
+}
+
+type SecurityEventResponse struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	IPAddress string    `json:"ipAddress"`
+	UserAgent string    `json:"userAgent"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func ToSecurityEventResponse(event SecurityEvent) SecurityEventResponse {
+	return SecurityEventResponse{
+		ID:        event.ID.String(),
+		Type:      string(event.Type),
+		IPAddress: event.IpAddress,
+		UserAgent: event.UserAgent,
+		Detail:    event.Detail,
+		CreatedAt: event.CreatedAt.Time,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("audit/handler"),
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+// parseAction reads the optional action query parameter
+func parseAction(r *http.Request) (NullAuditAction, error) {
+	value := r.URL.Query().Get("action")
+	if value == "" {
+		return NullAuditAction{}, nil
+	}
+
+	action := AuditAction(value)
+	switch action {
+	case AuditActionLogin, AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionSubmit, AuditActionView:
+		return NullAuditAction{AuditAction: action, Valid: true}, nil
+	}
+	return NullAuditAction{}, internal.ErrInvalidActionParameter
+}
+
+// ActivityHandler lists the recent actions of the current user, newest first
+func (h *Handler) ActivityHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ActivityHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	factory := pagutil.NewFactory[ActivityResponse](200, []string{"CreatedAt"})
+	request, err := factory.GetRequest(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	action, err := parseAction(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	total, err := h.store.CountByUser(traceCtx, currentUser.ID, action)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	logs, err := h.store.ListByUser(traceCtx, currentUser.ID, action, request.Page, request.Size)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	items := make([]ActivityResponse, len(logs))
+	for i, log := range logs {
+		items[i] = ToActivityResponse(log)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, factory.NewResponse(items, int(total), request.Page, request.Size))
+}
+
+// SecurityEventsHandler lists the security events of the current user, newest first,
+// such as refresh tokens presented by another device than the one they were issued to
+func (h *Handler) SecurityEventsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SecurityEventsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	factory := pagutil.NewFactory[SecurityEventResponse](200, []string{"CreatedAt"})
+	request, err := factory.GetRequest(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	total, err := h.store.CountSecurityEventsByUser(traceCtx, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	events, err := h.store.ListSecurityEventsByUser(traceCtx, currentUser.ID, request.Page, request.Size)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	items := make([]SecurityEventResponse, len(events))
+	for i, event := range events {
+		items[i] = ToSecurityEventResponse(event)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, factory.NewResponse(items, int(total), request.Page, request.Size))
+}
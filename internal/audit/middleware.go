@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type Recorder interface {
+	Record(ctx context.Context, entry Entry) error
+}
+
+type Middleware struct {
+	logger   *zap.Logger
+	recorder Recorder
+}
+
+func NewMiddleware(logger *zap.Logger, recorder Recorder) *Middleware {
+	return &Middleware{
+		logger:   logger,
+		recorder: recorder,
+	}
+}
+
+// statusRecorder keeps the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// RecordMiddleware writes an audit log entry for every state-changing request of an
+// authenticated user, whether it succeeded or not, and for every read of a delegate, who
+// is not a member of the organization. It must run after authentication.
+func (m *Middleware) RecordMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		action, ok := actionOf(r)
+		if !ok {
+			next(w, r)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+
+		currentUser, ok := user.GetFromContext(r.Context())
+		if !ok {
+			return
+		}
+
+		err := m.recorder.Record(r.Context(), NewEntry(r, currentUser.ID, action, recorder.status))
+		if err != nil {
+			m.logger.Error("Failed to record audit log", zap.String("route", r.Pattern), zap.Error(err))
+		}
+	}
+}
+
+func actionOf(r *http.Request) (AuditAction, bool) {
+	switch r.Method {
+	case http.MethodGet:
+		_, delegated := internal.DelegationFromContext(r.Context())
+		return AuditActionView, delegated
+	case http.MethodPost:
+		if strings.HasSuffix(r.URL.Path, "/submit") {
+			return AuditActionSubmit, true
+		}
+		return AuditActionCreate, true
+	case http.MethodPut, http.MethodPatch:
+		return AuditActionUpdate, true
+	case http.MethodDelete:
+		return AuditActionDelete, true
+	}
+	return "", false
+}
@@ -0,0 +1,31 @@
+-- name: Create :exec
+INSERT INTO audit_logs (user_id, action, route, path, status_code, ip_address, user_agent)
+VALUES (@user_id, @action, @route, @path, @status_code, @ip_address, @user_agent);
+
+-- name: ListByUserID :many
+SELECT * FROM audit_logs
+WHERE user_id = @user_id
+  AND (sqlc.narg(action)::audit_action IS NULL OR action = sqlc.narg(action))
+ORDER BY created_at DESC
+LIMIT @page_limit::int
+OFFSET @page_offset::int;
+
+-- name: CountByUserID :one
+SELECT COUNT(*) AS total FROM audit_logs
+WHERE user_id = @user_id
+  AND (sqlc.narg(action)::audit_action IS NULL OR action = sqlc.narg(action));
+
+-- name: CreateSecurityEvent :exec
+INSERT INTO security_events (user_id, type, ip_address, user_agent, detail)
+VALUES (@user_id, @type, @ip_address, @user_agent, @detail);
+
+-- name: ListSecurityEventsByUserID :many
+SELECT * FROM security_events
+WHERE user_id = @user_id
+ORDER BY created_at DESC
+LIMIT @page_limit::int
+OFFSET @page_offset::int;
+
+-- name: CountSecurityEventsByUserID :one
+SELECT COUNT(*) AS total FROM security_events
+WHERE user_id = @user_id;
\ No newline at end of file
@@ -0,0 +1,11 @@
+package audit
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the activity log and security events of the current user
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /users/me/activity", route.Authenticated, route.PermissionSelf, h.ActivityHandler)
+	r.Handle("GET /users/me/security-events", route.Authenticated, route.PermissionSelf, h.SecurityEventsHandler)
+}
@@ -0,0 +1,222 @@
+package audit
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"net"
+	"net/http"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const maxUserAgentLength = 512
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) error
+	ListByUserID(ctx context.Context, arg ListByUserIDParams) ([]AuditLog, error)
+	CountByUserID(ctx context.Context, arg CountByUserIDParams) (int64, error)
+	CreateSecurityEvent(ctx context.Context, arg CreateSecurityEventParams) error
+	ListSecurityEventsByUserID(ctx context.Context, arg ListSecurityEventsByUserIDParams) ([]SecurityEvent, error)
+	CountSecurityEventsByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// Entry is a single action of a user. Route is the pattern the request matched,
+// e.g. "PUT /api/forms/{id}", and Path the concrete URL path.
+type Entry struct {
+	UserID     uuid.UUID
+	Action     AuditAction
+	Route      string
+	Path       string
+	StatusCode int
+	IPAddress  string
+	UserAgent  string
+}
+
+// NewEntry fills in the request details of an entry
+func NewEntry(r *http.Request, userID uuid.UUID, action AuditAction, statusCode int) Entry {
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return Entry{
+		UserID:     userID,
+		Action:     action,
+		Route:      r.Pattern,
+		Path:       r.URL.Path,
+		StatusCode: statusCode,
+		IPAddress:  ClientIP(r),
+		UserAgent:  userAgent,
+	}
+}
+
+// ClientIP returns the address ClientIPMiddleware resolved for the request, and the peer
+// address of the connection on requests it did not see
+func ClientIP(r *http.Request) string {
+	clientIP, ok := r.Context().Value(internal.ClientIPContextKey).(string)
+	if ok {
+		return clientIP
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// NewSecurityEvent fills in the request details of a security event of the user
+func NewSecurityEvent(r *http.Request, userID uuid.UUID, eventType SecurityEventType, detail string) CreateSecurityEventParams {
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return CreateSecurityEventParams{
+		UserID:    userID,
+		Type:      eventType,
+		IpAddress: ClientIP(r),
+		UserAgent: userAgent,
+		Detail:    detail,
+	}
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("audit/service"),
+	}
+}
+
+func (s *Service) Record(ctx context.Context, entry Entry) error {
+	ctx, span := s.tracer.Start(ctx, "Record")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.queries.Create(ctx, CreateParams{
+		UserID:     entry.UserID,
+		Action:     entry.Action,
+		Route:      entry.Route,
+		Path:       entry.Path,
+		StatusCode: int32(entry.StatusCode),
+		IpAddress:  entry.IPAddress,
+		UserAgent:  entry.UserAgent,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "audit_logs", "user_id", entry.UserID.String(), logger, "record audit log")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListByUser lists the most recent actions of a user first, optionally of one action only
+func (s *Service) ListByUser(ctx context.Context, userID uuid.UUID, action NullAuditAction, page int, size int) ([]AuditLog, error) {
+	ctx, span := s.tracer.Start(ctx, "ListByUser")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	params := ListByUserIDParams{
+		UserID:    userID,
+		Action:    action,
+		PageLimit: int32(size),
+	}
+	if page > 1 {
+		params.PageOffset = int32((page - 1) * size)
+	}
+
+	logs, err := s.queries.ListByUserID(ctx, params)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "audit_logs", "user_id", userID.String(), logger, "list audit logs")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+func (s *Service) CountByUser(ctx context.Context, userID uuid.UUID, action NullAuditAction) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "CountByUser")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	total, err := s.queries.CountByUserID(ctx, CountByUserIDParams{
+		UserID: userID,
+		Action: action,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "audit_logs", "user_id", userID.String(), logger, "count audit logs")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (s *Service) RecordSecurityEvent(ctx context.Context, event CreateSecurityEventParams) error {
+	ctx, span := s.tracer.Start(ctx, "RecordSecurityEvent")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.queries.CreateSecurityEvent(ctx, event)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "security_events", "user_id", event.UserID.String(), logger, "record security event")
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Warn("Recorded security event", zap.String("user_id", event.UserID.String()), zap.String("type", string(event.Type)))
+	return nil
+}
+
+// ListSecurityEventsByUser lists the most recent security events of a user first
+func (s *Service) ListSecurityEventsByUser(ctx context.Context, userID uuid.UUID, page int, size int) ([]SecurityEvent, error) {
+	ctx, span := s.tracer.Start(ctx, "ListSecurityEventsByUser")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	params := ListSecurityEventsByUserIDParams{
+		UserID:    userID,
+		PageLimit: int32(size),
+	}
+	if page > 1 {
+		params.PageOffset = int32((page - 1) * size)
+	}
+
+	events, err := s.queries.ListSecurityEventsByUserID(ctx, params)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "security_events", "user_id", userID.String(), logger, "list security events")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (s *Service) CountSecurityEventsByUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "CountSecurityEventsByUser")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	total, err := s.queries.CountSecurityEventsByUserID(ctx, userID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "security_events", "user_id", userID.String(), logger, "count security events")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	return total, nil
+}
@@ -2,10 +2,13 @@ package auth
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/audit"
 	"NYCU-SDC/core-system-backend/internal/auth/oauthprovider"
 	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/route"
 	"NYCU-SDC/core-system-backend/internal/user"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -27,13 +30,16 @@ const (
 	RefreshTokenCookieName = "refresh_token"
 )
 
+// refreshTokenCookiePaths are the paths of the refresh route, versioned and unversioned
+var refreshTokenCookiePaths = []string{route.V1 + "/auth/refresh", route.APIPrefix + "/auth/refresh"}
+
 type JWTIssuer interface {
 	New(ctx context.Context, user user.User) (string, error)
 	NewState(ctx context.Context, service, environment, callbackURL, redirectURL string) (string, error)
 	Parse(ctx context.Context, tokenString string) (user.User, error)
 	ParseState(ctx context.Context, tokenString string) (string, error)
-	GenerateRefreshToken(ctx context.Context, userID uuid.UUID) (jwt.RefreshToken, error)
-	GetUserIDByRefreshToken(ctx context.Context, refreshTokenID uuid.UUID) (uuid.UUID, error)
+	GenerateRefreshToken(ctx context.Context, userID uuid.UUID, fingerprint jwt.Fingerprint) (jwt.RefreshToken, error)
+	GetUserIDByRefreshToken(ctx context.Context, refreshTokenID uuid.UUID, fingerprint jwt.Fingerprint) (uuid.UUID, error)
 }
 
 type JWTStore interface {
@@ -46,6 +52,7 @@ type UserStore interface {
 	GetByID(ctx context.Context, id uuid.UUID) (user.UsersWithEmail, error)
 	FindOrCreate(ctx context.Context, name, username, avatarUrl string, role []string, oauthProvider, oauthProviderID string) (uuid.UUID, error)
 	CreateEmail(ctx context.Context, userID uuid.UUID, email string) error
+	GetIDByEmail(ctx context.Context, email string) (uuid.UUID, error)
 }
 
 type OAuthProvider interface {
@@ -55,6 +62,11 @@ type OAuthProvider interface {
 	GetUserInfo(ctx context.Context, token *oauth2.Token) (user.User, user.Auth, string, error)
 }
 
+type AuditRecorder interface {
+	Record(ctx context.Context, entry audit.Entry) error
+	RecordSecurityEvent(ctx context.Context, event audit.CreateSecurityEventParams) error
+}
+
 type callBackInfo struct {
 	code       string
 	oauthError string
@@ -76,8 +88,11 @@ type Handler struct {
 	userStore UserStore
 	jwtIssuer JWTIssuer
 	jwtStore  JWTStore
+	auditLog  AuditRecorder
 	provider  map[string]OAuthProvider
 
+	magicLinkStore MagicLinkStore
+
 	accessTokenExpiration  time.Duration
 	refreshTokenExpiration time.Duration
 }
@@ -89,6 +104,8 @@ func NewHandler(
 	userStore UserStore,
 	jwtIssuer JWTIssuer,
 	jwtStore JWTStore,
+	auditLog AuditRecorder,
+	magicLinkStore MagicLinkStore,
 
 	baseURL string,
 	oauthProxyBaseURL string,
@@ -123,6 +140,10 @@ func NewHandler(
 		userStore: userStore,
 		jwtIssuer: jwtIssuer,
 		jwtStore:  jwtStore,
+		auditLog:  auditLog,
+
+		magicLinkStore: magicLinkStore,
+
 		provider: map[string]OAuthProvider{
 			"google": oauthprovider.NewGoogleConfig(
 				googleOauthConfig.ClientID,
@@ -136,6 +157,11 @@ func NewHandler(
 	}
 }
 
+// RegisterProvider adds a provider to sign in with, at /auth/login/oauth/<name>
+func (h *Handler) RegisterProvider(provider OAuthProvider) {
+	h.provider[provider.Name()] = provider
+}
+
 // Oauth2Start initiates the OAuth2 flow by redirecting the user to the provider's authorization URL
 func (h *Handler) Oauth2Start(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "Oauth2Start")
@@ -164,8 +190,13 @@ func (h *Handler) Oauth2Start(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate OAuth authorization URL and redirect
-	authURL := provider.Config().AuthCodeURL(state, oauth2.AccessTypeOffline)
+	// Generate OAuth authorization URL and redirect; a login_hint preselects the account
+	options := []oauth2.AuthCodeOption{oauth2.AccessTypeOffline}
+	loginHint := r.URL.Query().Get("login_hint")
+	if loginHint != "" {
+		options = append(options, oauth2.SetAuthURLParam("login_hint", loginHint))
+	}
+	authURL := provider.Config().AuthCodeURL(state, options...)
 	http.Redirect(w, r, authURL, http.StatusFound)
 }
 
@@ -224,7 +255,7 @@ func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	accessTokenID, refreshTokenID, err := h.generateJWT(traceCtx, userID)
+	accessTokenID, refreshTokenID, err := h.generateJWT(traceCtx, r, userID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -237,22 +268,18 @@ func (h *Handler) Callback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.setAccessAndRefreshCookies(w, baseURL.Host, accessTokenID, refreshTokenID)
+	h.recordLogin(traceCtx, logger, r, userID)
 
 	redirectURL := redirectTo
 	if redirectURL == "" {
-		// If environment is "snapshot" or "no-env", meaning it should have no frontend
-		// redirect to the API endpoint, otherwise redirect to the home page
-		if h.environment == "snapshot" || h.environment == "no-env" {
-			redirectURL = "/api/users/me"
-		} else {
-			redirectURL = "/"
-		}
+		redirectURL = h.defaultRedirectURL()
 	}
 
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 }
 
-func (h *Handler) generateJWT(ctx context.Context, userID uuid.UUID) (string, string, error) {
+// generateJWT issues an access token and a refresh token bound to the client of the request
+func (h *Handler) generateJWT(ctx context.Context, r *http.Request, userID uuid.UUID) (string, string, error) {
 	traceCtx, span := h.tracer.Start(ctx, "generateJWT")
 	defer span.End()
 
@@ -277,7 +304,7 @@ func (h *Handler) generateJWT(ctx context.Context, userID uuid.UUID) (string, st
 		return "", "", err
 	}
 
-	refreshToken, err := h.jwtIssuer.GenerateRefreshToken(traceCtx, userID)
+	refreshToken, err := h.jwtIssuer.GenerateRefreshToken(traceCtx, userID, jwt.NewFingerprint(r))
 	if err != nil {
 		return "", "", err
 	}
@@ -355,8 +382,14 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, err := h.jwtIssuer.GetUserIDByRefreshToken(traceCtx, refreshTokenID)
+	fingerprint := jwt.NewFingerprint(r)
+	userID, err := h.jwtIssuer.GetUserIDByRefreshToken(traceCtx, refreshTokenID, fingerprint)
 	if err != nil {
+		if errors.Is(err, internal.ErrRefreshTokenMismatch) {
+			h.recordRefreshTokenMismatch(traceCtx, logger, r, userID, refreshTokenID)
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
 		h.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidRefreshToken, logger)
 		return
 	}
@@ -367,7 +400,7 @@ func (h *Handler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newAccessTokenID, newRefreshTokenID, err := h.generateJWT(traceCtx, userID)
+	newAccessTokenID, newRefreshTokenID, err := h.generateJWT(traceCtx, r, userID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -394,7 +427,7 @@ func (h *Handler) InternalAPITokenLogin(w http.ResponseWriter, r *http.Request)
 	var req struct {
 		UserIDStr string `json:"uid" validate:"required"`
 	}
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
@@ -411,7 +444,7 @@ func (h *Handler) InternalAPITokenLogin(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	jwtToken, refreshTokenID, err := h.generateJWT(traceCtx, uid)
+	jwtToken, refreshTokenID, err := h.generateJWT(traceCtx, r, uid)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidJWTToken, logger)
 		return
@@ -424,10 +457,29 @@ func (h *Handler) InternalAPITokenLogin(w http.ResponseWriter, r *http.Request)
 	}
 
 	h.setAccessAndRefreshCookies(w, baseURL.Host, jwtToken, refreshTokenID)
+	h.recordLogin(traceCtx, logger, r, uid)
 
 	handlerutil.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Login successful"})
 }
 
+// recordRefreshTokenMismatch reports a refresh token presented by another device in the
+// security events of its owner; a failure does not change the response
+func (h *Handler) recordRefreshTokenMismatch(ctx context.Context, logger *zap.Logger, r *http.Request, userID uuid.UUID, refreshTokenID uuid.UUID) {
+	detail := fmt.Sprintf("Refresh token %s was presented by another device than the one it was issued to and has been revoked", refreshTokenID)
+	err := h.auditLog.RecordSecurityEvent(ctx, audit.NewSecurityEvent(r, userID, audit.SecurityEventTypeRefreshTokenMismatch, detail))
+	if err != nil {
+		logger.Error("Failed to record refresh token mismatch", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+}
+
+// recordLogin adds the login to the activity of the user; a failure does not fail the login
+func (h *Handler) recordLogin(ctx context.Context, logger *zap.Logger, r *http.Request, userID uuid.UUID) {
+	err := h.auditLog.Record(ctx, audit.NewEntry(r, userID, audit.AuditActionLogin, http.StatusOK))
+	if err != nil {
+		logger.Error("Failed to record login", zap.String("user_id", userID.String()), zap.Error(err))
+	}
+}
+
 // setAccessAndRefreshCookies sets the access/refresh cookies with HTTP-only and secure flags
 func (h *Handler) setAccessAndRefreshCookies(w http.ResponseWriter, domain, accessTokenID, refreshTokenID string) {
 	var sameSite http.SameSite
@@ -448,16 +500,19 @@ func (h *Handler) setAccessAndRefreshCookies(w http.ResponseWriter, domain, acce
 		Domain:   domain,
 	})
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     RefreshTokenCookieName,
-		Value:    refreshTokenID,
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: sameSite,
-		Path:     "/api/auth/refresh",
-		MaxAge:   int(h.refreshTokenExpiration.Seconds()),
-		Domain:   domain,
-	})
+	// The cookie is only sent to the refresh route, under each prefix it is served at
+	for _, refreshPath := range refreshTokenCookiePaths {
+		http.SetCookie(w, &http.Cookie{
+			Name:     RefreshTokenCookieName,
+			Value:    refreshTokenID,
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: sameSite,
+			Path:     refreshPath,
+			MaxAge:   int(h.refreshTokenExpiration.Seconds()),
+			Domain:   domain,
+		})
+	}
 }
 
 // clearAccessAndRefreshCookies sets the access/refresh cookies to empty values and negative MaxAge
@@ -473,13 +528,15 @@ func (h *Handler) clearAccessAndRefreshCookies(w http.ResponseWriter) {
 		SameSite: http.SameSiteLaxMode,
 	})
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     RefreshTokenCookieName,
-		Value:    "",
-		Path:     "/api/auth/refresh",
-		MaxAge:   -1,
-		HttpOnly: true,
-		Secure:   true,
-		SameSite: http.SameSiteStrictMode,
-	})
+	for _, refreshPath := range refreshTokenCookiePaths {
+		http.SetCookie(w, &http.Cookie{
+			Name:     RefreshTokenCookieName,
+			Value:    "",
+			Path:     refreshPath,
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
 }
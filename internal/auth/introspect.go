@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Client is a trusted internal service allowed to introspect access tokens
+type Client struct {
+	ID     string `yaml:"client_id"`
+	Secret string `yaml:"client_secret"`
+}
+
+type Introspector interface {
+	Introspect(ctx context.Context, tokenString string) jwt.Introspection
+}
+
+// IntrospectionResponse is the RFC 7662 response; only "active" is set for inactive tokens
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	TokenType string `json:"token_type,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+	FormID    string `json:"form_id,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+	ID        string `json:"jti,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	NotBefore int64  `json:"nbf,omitempty"`
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func ToIntrospectionResponse(introspection jwt.Introspection) IntrospectionResponse {
+	if !introspection.Active {
+		return IntrospectionResponse{}
+	}
+
+	return IntrospectionResponse{
+		Active:    true,
+		TokenType: "Bearer",
+		Subject:   introspection.Subject,
+		Username:  introspection.Username,
+		Name:      introspection.Name,
+		Scope:     introspection.Scope,
+		FormID:    introspection.FormID,
+		Issuer:    introspection.Issuer,
+		ID:        introspection.ID,
+		ExpiresAt: unixOrZero(introspection.ExpiresAt),
+		IssuedAt:  unixOrZero(introspection.IssuedAt),
+		NotBefore: unixOrZero(introspection.NotBefore),
+	}
+}
+
+type IntrospectionHandler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	introspector Introspector
+	clients      []Client
+}
+
+func NewIntrospectionHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	introspector Introspector,
+	clients []Client,
+) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		logger:        logger,
+		tracer:        otel.Tracer("auth/introspection"),
+		problemWriter: problemWriter,
+		introspector:  introspector,
+		clients:       clients,
+	}
+}
+
+// authenticate accepts HTTP basic credentials or client_id and client_secret form
+// parameters, as described in RFC 6749 section 2.3.1
+func (h *IntrospectionHandler) authenticate(r *http.Request) (string, bool) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id == "" || secret == "" {
+		return "", false
+	}
+
+	for _, client := range h.clients {
+		idMatch := subtle.ConstantTimeCompare([]byte(client.ID), []byte(id)) == 1
+		secretMatch := subtle.ConstantTimeCompare([]byte(client.Secret), []byte(secret)) == 1
+		if idMatch && secretMatch {
+			return client.ID, true
+		}
+	}
+	return "", false
+}
+
+// Introspect handles POST /api/auth/introspect (RFC 7662) for the configured clients
+func (h *IntrospectionHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "Introspect")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	err := r.ParseForm()
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrMissingToken, logger)
+		return
+	}
+
+	clientID, ok := h.authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="introspection"`)
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidClient, logger)
+		return
+	}
+
+	token := r.PostForm.Get("token")
+	if token == "" {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrMissingToken, logger)
+		return
+	}
+
+	introspection := h.introspector.Introspect(traceCtx, token)
+	logger.Debug("Introspected token", zap.String("client_id", clientID), zap.Bool("active", introspection.Active))
+
+	w.Header().Set("Cache-Control", "no-store")
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToIntrospectionResponse(introspection))
+}
@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"NYCU-SDC/core-system-backend/internal/magiclink"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	// MagicLinkDeviceCookieName holds the nonce of the browser a magic link was requested
+	// from. It is Lax so it comes along when the link is opened from a mail client.
+	MagicLinkDeviceCookieName = "magic_link_device"
+
+	// magicLinkConfirmPath is the page of the frontend asking to confirm a link opened
+	// on another device than the one it was requested from
+	magicLinkConfirmPath = "/login/magic-link/confirm"
+
+	// magicLinkProvider is the provider of the accounts created by signing in with a link
+	magicLinkProvider = "email"
+)
+
+type MagicLinkStore interface {
+	TTL() time.Duration
+	Request(ctx context.Context, email string, redirectURL string, device magiclink.Device) (string, error)
+	Inspect(ctx context.Context, token string, nonce string) (magiclink.MagicLink, bool, error)
+	Consume(ctx context.Context, token string) (magiclink.MagicLink, error)
+}
+
+type MagicLinkRequest struct {
+	Email       string `json:"email" validate:"required,email,max=255"`
+	RedirectURL string `json:"r" validate:"max=2048"`
+}
+
+type MagicLinkConfirmRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// MagicLinkDeviceResponse describes where a link was requested from, for people to
+// recognize their request before confirming it on another device
+type MagicLinkDeviceResponse struct {
+	Email       string    `json:"email"`
+	IPAddress   string    `json:"ipAddress"`
+	UserAgent   string    `json:"userAgent"`
+	RequestedAt time.Time `json:"requestedAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// RequestMagicLink mails a sign in link to the address. The response is the same
+// whether or not the address belongs to someone yet.
+func (h *Handler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RequestMagicLink")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	var req MagicLinkRequest
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	nonce, err := h.magicLinkStore.Request(traceCtx, req.Email, req.RedirectURL, magiclink.Device{
+		IPAddress: audit.ClientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     MagicLinkDeviceCookieName,
+		Value:    nonce,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+		MaxAge:   int(h.magicLinkStore.TTL().Seconds()),
+	})
+
+	handlerutil.WriteJSONResponse(w, http.StatusAccepted, map[string]string{"message": "A sign in link is on its way if the address can sign in"})
+}
+
+// VerifyMagicLink is where the mailed links point to. Opened on the device that asked
+// for it the link signs in at once; elsewhere it leads to the confirmation page, which
+// also keeps mail scanners following links from using them up.
+func (h *Handler) VerifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "VerifyMagicLink")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrMissingToken, logger)
+		return
+	}
+
+	var nonce string
+	cookie, err := r.Cookie(MagicLinkDeviceCookieName)
+	if err == nil {
+		nonce = cookie.Value
+	}
+
+	_, sameDevice, err := h.magicLinkStore.Inspect(traceCtx, token, nonce)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+	if !sameDevice {
+		http.Redirect(w, r, magicLinkConfirmPath+"?token="+url.QueryEscape(token), http.StatusFound)
+		return
+	}
+
+	link, err := h.magicLinkStore.Consume(traceCtx, token)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.signInWithMagicLink(traceCtx, logger, w, r, link)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	redirectURL := link.RedirectUrl
+	if redirectURL == "" {
+		redirectURL = h.defaultRedirectURL()
+	}
+	http.Redirect(w, r, redirectURL, http.StatusFound)
+}
+
+// GetMagicLinkDevice describes the request of a link for the confirmation page
+func (h *Handler) GetMagicLinkDevice(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetMagicLinkDevice")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrMissingToken, logger)
+		return
+	}
+
+	link, _, err := h.magicLinkStore.Inspect(traceCtx, token, "")
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, MagicLinkDeviceResponse{
+		Email:       link.Email,
+		IPAddress:   link.IpAddress,
+		UserAgent:   link.UserAgent,
+		RequestedAt: link.CreatedAt.Time,
+		ExpiresAt:   link.ExpiresAt.Time,
+	})
+}
+
+// ConfirmMagicLink signs in with a link opened on another device than the one it was
+// requested from, once the person confirmed it on the confirmation page
+func (h *Handler) ConfirmMagicLink(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ConfirmMagicLink")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	var req MagicLinkConfirmRequest
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	link, err := h.magicLinkStore.Consume(traceCtx, req.Token)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.signInWithMagicLink(traceCtx, logger, w, r, link)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	redirectURL := link.RedirectUrl
+	if redirectURL == "" {
+		redirectURL = h.defaultRedirectURL()
+	}
+	handlerutil.WriteJSONResponse(w, http.StatusOK, map[string]string{"message": "Login successful", "redirect": redirectURL})
+}
+
+// signInWithMagicLink issues the session of the owner of the address, creating an
+// account named after the address the first time it signs in
+func (h *Handler) signInWithMagicLink(ctx context.Context, logger *zap.Logger, w http.ResponseWriter, r *http.Request, link magiclink.MagicLink) error {
+	userID, err := h.userStore.GetIDByEmail(ctx, link.Email)
+	if err != nil {
+		if !errors.Is(err, internal.ErrUserNotFound) {
+			return err
+		}
+
+		name, _, _ := strings.Cut(link.Email, "@")
+		userID, err = h.userStore.FindOrCreate(ctx, name, "", "", nil, magicLinkProvider, link.Email)
+		if err != nil {
+			return err
+		}
+		err = h.userStore.CreateEmail(ctx, userID, link.Email)
+		if err != nil {
+			return internal.ErrFailedToCreateEmail
+		}
+		logger.Info("Created user signing in with a magic link", zap.String("user_id", userID.String()))
+	}
+
+	accessTokenID, refreshTokenID, err := h.generateJWT(ctx, r, userID)
+	if err != nil {
+		return err
+	}
+
+	baseURL, err := url.Parse(h.baseURL)
+	if err != nil {
+		return internal.ErrInternalServerError
+	}
+
+	h.setAccessAndRefreshCookies(w, baseURL.Host, accessTokenID, refreshTokenID)
+	http.SetCookie(w, &http.Cookie{
+		Name:     MagicLinkDeviceCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	h.recordLogin(ctx, logger, r, userID)
+	return nil
+}
+
+// defaultRedirectURL is where a sign in lands without a redirect of its own
+func (h *Handler) defaultRedirectURL() string {
+	// If environment is "snapshot" or "no-env", meaning it should have no frontend
+	// redirect to the API endpoint, otherwise redirect to the home page
+	if h.environment == "snapshot" || h.environment == "no-env" {
+		return route.V1 + "/users/me"
+	}
+	return "/"
+}
@@ -0,0 +1,99 @@
+package auth_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"NYCU-SDC/core-system-backend/internal/auth"
+	"NYCU-SDC/core-system-backend/internal/auth/oauthprovider"
+	"NYCU-SDC/core-system-backend/internal/magiclink"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeMagicLinkStore limits the requests per client address, like magiclink.Service
+type fakeMagicLinkStore struct {
+	auth.MagicLinkStore
+	limit int
+
+	mu   sync.Mutex
+	byIP map[string]int
+}
+
+func (s *fakeMagicLinkStore) TTL() time.Duration {
+	return 15 * time.Minute
+}
+
+func (s *fakeMagicLinkStore) Request(_ context.Context, _ string, _ string, device magiclink.Device) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.byIP[device.IPAddress] >= s.limit {
+		return "", internal.ErrMagicLinkRateLimited
+	}
+	s.byIP[device.IPAddress]++
+	return "nonce", nil
+}
+
+func TestHandler_RequestMagicLinkRateLimitsTheClient(t *testing.T) {
+	t.Parallel()
+
+	const limit = 3
+
+	type testCase struct {
+		name       string
+		remoteAddr string
+		forwarded  func(i int) string
+		expectedIP string
+	}
+
+	testCases := []testCase{
+		{
+			name:       "Client spoofing the header directly",
+			remoteAddr: "203.0.113.7:5555",
+			forwarded:  func(i int) string { return fmt.Sprintf("198.51.100.%d", i) },
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:       "Client spoofing the header through the proxy",
+			remoteAddr: "10.0.0.2:5555",
+			forwarded:  func(i int) string { return fmt.Sprintf("198.51.100.%d, 203.0.113.7", i) },
+			expectedIP: "203.0.113.7",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &fakeMagicLinkStore{limit: limit, byIP: make(map[string]int)}
+			handler := auth.NewHandler(zap.NewNop(), internal.NewValidator(), internal.NewProblemWriter(), nil, nil, nil, nil, store,
+				"https://core-system.example.com", "", "test", false, 15*time.Minute, 720*time.Hour, oauthprovider.GoogleOauth{})
+			proxies, err := audit.ParseTrustedProxies([]string{"10.0.0.0/8"})
+			require.NoError(t, err)
+			requestMagicLink := proxies.ClientIPMiddleware(handler.RequestMagicLink)
+
+			statusCodes := make([]int, limit+2)
+			for i := range statusCodes {
+				r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login/magic-link", strings.NewReader(`{"email":"someone@example.com"}`))
+				r.Header.Set("Content-Type", "application/json")
+				r.RemoteAddr = tc.remoteAddr
+				r.Header.Set("X-Forwarded-For", tc.forwarded(i))
+				w := httptest.NewRecorder()
+
+				requestMagicLink(w, r)
+				statusCodes[i] = w.Code
+			}
+
+			require.Equal(t, []int{http.StatusAccepted, http.StatusAccepted, http.StatusAccepted, http.StatusTooManyRequests, http.StatusTooManyRequests}, statusCodes)
+			require.Equal(t, map[string]int{tc.expectedIP: limit}, store.byIP)
+		})
+	}
+}
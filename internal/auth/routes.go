@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the login, token and logout endpoints
+func Routes(r route.Router, h *Handler, introspection *IntrospectionHandler, limits route.BodyLimits) {
+	// Internal Debug route
+	r.Handle("POST /auth/login/internal", route.Public, route.PermissionNone, h.InternalAPITokenLogin).WithBodyLimit(limits.Auth)
+
+	// OAuth2 Authentication routes
+	r.Handle("GET /auth/login/oauth/{provider}", route.Public, route.PermissionNone, h.Oauth2Start)
+	r.Handle("GET /auth/login/oauth/{provider}/callback", route.Public, route.PermissionNone, h.Callback)
+
+	// Password-less sign in with a link sent by mail
+	r.Handle("POST /auth/login/magic-link", route.Public, route.PermissionNone, h.RequestMagicLink).WithBodyLimit(limits.Auth)
+	r.Handle("GET /auth/login/magic-link/verify", route.Public, route.PermissionNone, h.VerifyMagicLink)
+	r.Handle("GET /auth/login/magic-link/confirm", route.Public, route.PermissionNone, h.GetMagicLinkDevice)
+	r.Handle("POST /auth/login/magic-link/confirm", route.Public, route.PermissionNone, h.ConfirmMagicLink).WithBodyLimit(limits.Auth)
+
+	// JWT refresh route
+	r.Handle("POST /auth/refresh", route.Public, route.PermissionNone, h.RefreshToken).WithBodyLimit(limits.Auth)
+
+	// Token introspection for trusted internal services (RFC 7662)
+	r.Handle("POST /auth/introspect", route.Public, route.PermissionClient, introspection.Introspect).WithBodyLimit(limits.Auth)
+
+	r.Handle("GET /auth/logout", route.Public, route.PermissionNone, h.Logout)
+	r.Handle("POST /auth/logout", route.Public, route.PermissionNone, h.Logout).WithBodyLimit(limits.Auth)
+}
@@ -0,0 +1,118 @@
+package avatar
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/imaging"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and headers
+const multipartOverhead = 1 << 20
+
+type Store interface {
+	Upload(ctx context.Context, userID uuid.UUID, file io.Reader) (string, error)
+	DownloadURL(ctx context.Context, userID uuid.UUID, size imaging.Size) (string, error)
+}
+
+type Response struct {
+	AvatarURL string `json:"avatarUrl"`
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("avatar/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) UploadHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UploadHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, imaging.MaxFileSize+multipartOverhead)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %w", internal.ErrInvalidImage, err), logger)
+		return
+	}
+	defer func() { _ = file.Close() }()
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	avatarURL, err := h.store.Upload(traceCtx, currentUser.ID, file)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, Response{AvatarURL: avatarURL})
+}
+
+// DownloadHandler redirects to the uploaded avatar of a user. The optional size query
+// parameter selects thumbnail, medium or large.
+func (h *Handler) DownloadHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DownloadHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	userID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var size imaging.Size
+	if sizeParam := r.URL.Query().Get("size"); sizeParam != "" {
+		var ok bool
+		size, ok = imaging.ParseSize(sizeParam)
+		if !ok {
+			h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %s", internal.ErrInvalidImageSize, sizeParam), logger)
+			return
+		}
+	}
+
+	downloadURL, err := h.store.DownloadURL(traceCtx, userID, size)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	http.Redirect(w, r, downloadURL, http.StatusFound)
+}
@@ -0,0 +1,11 @@
+package avatar
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the avatar upload of the current user and the public avatar download
+func Routes(r route.Router, h *Handler, limits route.BodyLimits) {
+	r.Handle("PUT /users/me/avatar", route.Authenticated, route.PermissionSelf, h.UploadHandler).WithBodyLimit(limits.Upload)
+	r.Handle("GET /users/{id}/avatar", route.Public, route.PermissionNone, h.DownloadHandler)
+}
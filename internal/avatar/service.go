@@ -0,0 +1,115 @@
+package avatar
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/imaging"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DefaultSize is served when the download does not ask for a size
+const DefaultSize = imaging.SizeMedium
+
+type FileStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	PresignGet(ctx context.Context, key string) (string, error)
+}
+
+type UserStore interface {
+	UpdateAvatarURL(ctx context.Context, id uuid.UUID, avatarURL string) error
+}
+
+type Service struct {
+	logger    *zap.Logger
+	tracer    trace.Tracer
+	fileStore FileStore
+	userStore UserStore
+	baseURL   string
+}
+
+func NewService(logger *zap.Logger, fileStore FileStore, userStore UserStore, baseURL string) *Service {
+	return &Service{
+		logger:    logger,
+		tracer:    otel.Tracer("avatar/service"),
+		fileStore: fileStore,
+		userStore: userStore,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func key(userID uuid.UUID, size imaging.Size) string {
+	return "avatars/" + userID.String() + "/" + string(size)
+}
+
+// Upload replaces the avatar of a user. The image is cropped to a square, stripped of
+// its EXIF metadata and stored in every imaging size; the avatar URL of the user then
+// points to the avatar route of this server.
+func (s *Service) Upload(ctx context.Context, userID uuid.UUID, file io.Reader) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "Upload")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	img, err := imaging.Decode(file)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrInvalidImage, err)
+		span.RecordError(err)
+		return "", err
+	}
+
+	square := imaging.CropSquare(img)
+	for _, size := range imaging.Sizes {
+		var buf bytes.Buffer
+		contentType, err := imaging.Encode(&buf, imaging.Resize(square, size.MaxDimension()))
+		if err != nil {
+			span.RecordError(err)
+			return "", err
+		}
+
+		err = s.fileStore.Put(ctx, key(userID, size), &buf, int64(buf.Len()), contentType)
+		if err != nil {
+			span.RecordError(err)
+			return "", err
+		}
+	}
+
+	// The version busts caches holding the previous avatar
+	avatarURL := s.baseURL + user.AvatarPath(userID) + "?v=" + strconv.FormatInt(time.Now().Unix(), 10)
+	err = s.userStore.UpdateAvatarURL(ctx, userID, avatarURL)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	logger.Debug("Updated user avatar", zap.String("user_id", userID.String()))
+	return avatarURL, nil
+}
+
+// DownloadURL returns a presigned URL of the uploaded avatar in the given size
+func (s *Service) DownloadURL(ctx context.Context, userID uuid.UUID, size imaging.Size) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "DownloadURL")
+	defer span.End()
+
+	if size == "" {
+		size = DefaultSize
+	}
+
+	downloadURL, err := s.fileStore.PresignGet(ctx, key(userID, size))
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	return downloadURL, nil
+}
@@ -0,0 +1,55 @@
+package backup
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveVersion is bumped whenever the archive layout changes incompatibly
+const ArchiveVersion = 1
+
+// Archive is a portable copy of one organization: its units, memberships and form
+// definitions. IDs are those of the source database and only serve to link the entries
+// to each other; a restore gives everything new IDs. Responses are not included, they
+// belong to users of the source environment.
+type Archive struct {
+	Version    int             `json:"version" validate:"required"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	Org        ArchiveOrg      `json:"org" validate:"required"`
+	Units      []ArchiveUnit   `json:"units" validate:"dive"`
+	Members    []ArchiveMember `json:"members" validate:"dive"`
+	Forms      []ArchiveForm   `json:"forms" validate:"dive"`
+}
+
+type ArchiveOrg struct {
+	ID          uuid.UUID       `json:"id" validate:"required"`
+	Slug        string          `json:"slug"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+// ArchiveUnit is a unit below the organization. A nil or unknown ParentID places the
+// unit directly under the organization on restore.
+type ArchiveUnit struct {
+	ID          uuid.UUID       `json:"id" validate:"required"`
+	ParentID    *uuid.UUID      `json:"parentId"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+// ArchiveMember is matched to a user of the target database by email
+type ArchiveMember struct {
+	UnitID     uuid.UUID  `json:"unitId" validate:"required"`
+	Email      string     `json:"email" validate:"required,email"`
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
+}
+
+type ArchiveForm struct {
+	UnitID   uuid.UUID         `json:"unitId" validate:"required"`
+	Document importer.Document `json:"document" validate:"required"`
+}
@@ -0,0 +1,159 @@
+package backup
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	RequireAdmin(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) error
+	Dump(ctx context.Context, orgID uuid.UUID) (Archive, error)
+	Restore(ctx context.Context, archive Archive, slug string, ownerID uuid.UUID) (RestoreResult, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+// RestoreRequest restores the archive as a new organization under Slug, owned by the caller
+type RestoreRequest struct {
+	Slug    string  `json:"slug" validate:"required"`
+	Archive Archive `json:"archive" validate:"required"`
+}
+
+type RestoreResponse struct {
+	OrgID          string   `json:"orgId"`
+	Slug           string   `json:"slug"`
+	Units          int      `json:"units"`
+	Members        int      `json:"members"`
+	SkippedMembers []string `json:"skippedMembers"`
+	Forms          int      `json:"forms"`
+}
+
+func ToRestoreResponse(result RestoreResult) RestoreResponse {
+	skipped := result.SkippedMembers
+	if skipped == nil {
+		skipped = []string{}
+	}
+
+	return RestoreResponse{
+		OrgID:          result.OrgID.String(),
+		Slug:           result.Slug,
+		Units:          result.Units,
+		Members:        result.Members,
+		SkippedMembers: skipped,
+		Forms:          result.Forms,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("backup/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+func (h *Handler) BackupHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "BackupHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	slug, err := internal.GetSlugFromContext(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org slug from context: %w", err), logger)
+		return
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(traceCtx, slug)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org ID by slug: %w", err), logger)
+		return
+	}
+
+	err = h.store.RequireAdmin(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	archive, err := h.store.Dump(traceCtx, orgID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "org-"+slug+".json"))
+	handlerutil.WriteJSONResponse(w, http.StatusOK, archive)
+}
+
+// RestoreHandler restores an archive as a new organization. Only admins of the
+// deployment may, as the archive names the members to add, whoever it came from.
+func (h *Handler) RestoreHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RestoreHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+	if !user.IsAdmin(currentUser) {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrPermissionDenied, logger)
+		return
+	}
+
+	var req RestoreRequest
+	err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	result, err := h.store.Restore(traceCtx, req.Archive, req.Slug, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToRestoreResponse(result))
+}
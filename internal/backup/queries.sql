@@ -0,0 +1,50 @@
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: GetOrg :one
+SELECT u.id, u.name, u.description, u.metadata, sh.slug FROM units u
+JOIN slug_history sh ON sh.org_id = u.id AND sh.ended_at IS NULL
+WHERE u.id = @org_id AND u.type = 'organization';
+
+-- name: ListUnits :many
+SELECT id, parent_id, name, description, metadata FROM units
+WHERE org_id = @org_id AND type = 'unit'
+ORDER BY created_at ASC;
+
+-- name: ListMembers :many
+-- Members are carried by email, the one identity that is the same in both environments
+SELECT um.unit_id, um.valid_until, COALESCE((
+    SELECT e.value FROM user_emails e
+    WHERE e.user_id = um.member_id
+    ORDER BY e.created_at ASC
+    LIMIT 1
+), '')::text AS email FROM unit_members um
+JOIN units u ON u.id = um.unit_id
+WHERE u.id = @org_id OR u.org_id = @org_id
+ORDER BY um.unit_id, email;
+
+-- name: ListForms :many
+SELECT f.id, f.unit_id FROM forms f
+JOIN units u ON u.id = f.unit_id
+WHERE u.id = @org_id OR u.org_id = @org_id
+ORDER BY f.created_at ASC;
+
+-- name: CreateOrg :one
+INSERT INTO units (name, description, metadata, type)
+VALUES (@name, @description, @metadata, 'organization')
+RETURNING id;
+
+-- name: CreateUnit :one
+INSERT INTO units (name, description, metadata, type, org_id, parent_id)
+VALUES (@name, @description, @metadata, 'unit', @org_id, @parent_id)
+RETURNING id;
+
+-- name: AddMember :execrows
+INSERT INTO unit_members (unit_id, member_id, valid_until)
+SELECT @unit_id, user_id, @valid_until FROM user_emails
+WHERE value = @email
+ON CONFLICT (unit_id, member_id) DO NOTHING;
+
+-- name: GetUserIDByEmail :one
+SELECT user_id FROM user_emails
+WHERE value = @email;
\ No newline at end of file
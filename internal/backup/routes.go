@@ -0,0 +1,11 @@
+package backup
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the backup of an organization and its restore
+func Routes(r route.Router, h *Handler, limits route.BodyLimits) {
+	r.Handle("GET /orgs/{slug}/backup", route.TenantAuthenticated, route.PermissionOrgAdmin, h.BackupHandler)
+	r.Handle("POST /orgs/restore", route.Authenticated, route.PermissionAdmin, h.RestoreHandler).WithBodyLimit(limits.Upload)
+}
@@ -0,0 +1,414 @@
+package backup
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/tenant"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// slugPattern matches the slugs organizations are created with
+var slugPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+type Querier interface {
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	GetOrg(ctx context.Context, orgID uuid.UUID) (GetOrgRow, error)
+	ListUnits(ctx context.Context, orgID pgtype.UUID) ([]ListUnitsRow, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]ListMembersRow, error)
+	ListForms(ctx context.Context, orgID uuid.UUID) ([]ListFormsRow, error)
+	CreateOrg(ctx context.Context, arg CreateOrgParams) (uuid.UUID, error)
+	CreateUnit(ctx context.Context, arg CreateUnitParams) (uuid.UUID, error)
+	AddMember(ctx context.Context, arg AddMemberParams) (int64, error)
+	GetUserIDByEmail(ctx context.Context, email string) (uuid.UUID, error)
+}
+
+// DB is the pool the service runs on; a restore runs in one transaction begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type TenantStore interface {
+	SlugExists(ctx context.Context, slug string) (bool, error)
+	Create(ctx context.Context, id uuid.UUID, ownerID uuid.UUID, slug string) (tenant.Tenant, error)
+}
+
+type FormStore interface {
+	Export(ctx context.Context, formID uuid.UUID) (importer.Document, error)
+	ImportDocument(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID, document importer.Document) (importer.Result, error)
+}
+
+// RestoreResult is the organization a restore created. Members whose email has no user
+// in the target database are skipped and listed.
+type RestoreResult struct {
+	OrgID          uuid.UUID
+	Slug           string
+	Units          int
+	Members        int
+	SkippedMembers []string
+	Forms          int
+}
+
+type Service struct {
+	logger  *zap.Logger
+	db      DB
+	queries Querier
+	tracer  trace.Tracer
+
+	tenantStore TenantStore
+	formStore   FormStore
+	secretBox   workflow.SecretBox
+}
+
+func NewService(logger *zap.Logger, db DB, tenantStore TenantStore, formStore FormStore, secretBox workflow.SecretBox) *Service {
+	return &Service{
+		logger:      logger,
+		db:          db,
+		queries:     New(db),
+		tracer:      otel.Tracer("backup/service"),
+		tenantStore: tenantStore,
+		formStore:   formStore,
+		secretBox:   secretBox,
+	}
+}
+
+func toText(value string) pgtype.Text {
+	return pgtype.Text{String: value, Valid: value != ""}
+}
+
+// RequireAdmin allows the owner of the organization only. The operator command skips it.
+func (s *Service) RequireAdmin(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "RequireAdmin")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	isAdmin, err := s.queries.IsOrgAdmin(traceCtx, IsOrgAdminParams{
+		OrgID:  orgID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check organization admin")
+		span.RecordError(err)
+		return err
+	}
+	if !isAdmin {
+		err = internal.ErrNotOrgAdmin
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// GetUserIDByEmail finds the owner of a restore for the operator command
+func (s *Service) GetUserIDByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetUserIDByEmail")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	userID, err := s.queries.GetUserIDByEmail(traceCtx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = fmt.Errorf("%w: no user has the email %s", internal.ErrUserNotFound, email)
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "user_emails", "value", email, logger, "get user by email")
+		}
+		span.RecordError(err)
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+// Dump copies the organization into an archive
+func (s *Service) Dump(ctx context.Context, orgID uuid.UUID) (Archive, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Dump")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	org, err := s.queries.GetOrg(traceCtx, orgID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrUnitNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "units", "id", orgID.String(), logger, "get organization")
+		}
+		span.RecordError(err)
+		return Archive{}, err
+	}
+
+	units, err := s.queries.ListUnits(traceCtx, pgtype.UUID{Bytes: orgID, Valid: true})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "units", "org_id", orgID.String(), logger, "list units")
+		span.RecordError(err)
+		return Archive{}, err
+	}
+
+	members, err := s.queries.ListMembers(traceCtx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "org_id", orgID.String(), logger, "list members")
+		span.RecordError(err)
+		return Archive{}, err
+	}
+
+	forms, err := s.queries.ListForms(traceCtx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "org_id", orgID.String(), logger, "list forms")
+		span.RecordError(err)
+		return Archive{}, err
+	}
+
+	archive := Archive{
+		Version:    ArchiveVersion,
+		ExportedAt: time.Now(),
+		Org: ArchiveOrg{
+			ID:          org.ID,
+			Slug:        org.Slug,
+			Name:        org.Name.String,
+			Description: org.Description.String,
+			Metadata:    org.Metadata,
+		},
+		Units:   make([]ArchiveUnit, len(units)),
+		Members: make([]ArchiveMember, 0, len(members)),
+		Forms:   make([]ArchiveForm, 0, len(forms)),
+	}
+
+	for i, unit := range units {
+		archive.Units[i] = ArchiveUnit{
+			ID:          unit.ID,
+			Name:        unit.Name.String,
+			Description: unit.Description.String,
+			Metadata:    unit.Metadata,
+		}
+		if unit.ParentID.Valid {
+			parentID := uuid.UUID(unit.ParentID.Bytes)
+			archive.Units[i].ParentID = &parentID
+		}
+	}
+
+	for _, member := range members {
+		if member.Email == "" {
+			// Users without an email cannot be matched in another environment
+			continue
+		}
+		entry := ArchiveMember{UnitID: member.UnitID, Email: member.Email}
+		if member.ValidUntil.Valid {
+			entry.ValidUntil = &member.ValidUntil.Time
+		}
+		archive.Members = append(archive.Members, entry)
+	}
+
+	for _, row := range forms {
+		document, err := s.formStore.Export(traceCtx, row.ID)
+		if err != nil {
+			span.RecordError(err)
+			return Archive{}, fmt.Errorf("failed to export form %s: %w", row.ID, err)
+		}
+		archive.Forms = append(archive.Forms, ArchiveForm{UnitID: row.UnitID.Bytes, Document: document})
+	}
+
+	logger.Info("Dumped organization",
+		zap.String("org_id", orgID.String()),
+		zap.Int("units", len(archive.Units)),
+		zap.Int("members", len(archive.Members)),
+		zap.Int("forms", len(archive.Forms)))
+
+	return archive, nil
+}
+
+// Restore creates a new organization from the archive under the given slug, owned by
+// ownerID. The organization, its units, members and forms are created in one
+// transaction, so a restore that fails halfway leaves nothing behind.
+func (s *Service) Restore(ctx context.Context, archive Archive, slug string, ownerID uuid.UUID) (RestoreResult, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Restore")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if archive.Version != ArchiveVersion {
+		err := fmt.Errorf("%w: version %d is not supported, expected %d", internal.ErrBackupArchiveInvalid, archive.Version, ArchiveVersion)
+		span.RecordError(err)
+		return RestoreResult{}, err
+	}
+
+	if !slugPattern.MatchString(slug) {
+		err := internal.ErrOrgSlugInvalid
+		span.RecordError(err)
+		return RestoreResult{}, err
+	}
+
+	exists, err := s.tenantStore.SlugExists(traceCtx, slug)
+	if err != nil {
+		span.RecordError(err)
+		return RestoreResult{}, err
+	}
+	if exists {
+		err = internal.ErrOrgSlugAlreadyExists
+		span.RecordError(err)
+		return RestoreResult{}, err
+	}
+
+	tx, err := s.db.Begin(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "begin transaction")
+		span.RecordError(err)
+		return RestoreResult{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(traceCtx))
+	}()
+
+	result, err := s.restore(traceCtx, logger, tx, archive, slug, ownerID)
+	if err != nil {
+		span.RecordError(err)
+		return RestoreResult{}, err
+	}
+
+	err = tx.Commit(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "commit transaction")
+		span.RecordError(err)
+		return RestoreResult{}, err
+	}
+
+	logger.Info("Restored organization",
+		zap.String("source_org_id", archive.Org.ID.String()),
+		zap.String("org_id", result.OrgID.String()),
+		zap.String("slug", slug),
+		zap.Int("units", result.Units),
+		zap.Int("members", result.Members),
+		zap.Int("skipped_members", len(result.SkippedMembers)),
+		zap.Int("forms", result.Forms))
+
+	return result, nil
+}
+
+// restore creates the organization on the transaction, mapping every source ID of the
+// archive to the ID of the row created for it. The forms are imported by services bound
+// to the same transaction.
+func (s *Service) restore(ctx context.Context, logger *zap.Logger, tx pgx.Tx, archive Archive, slug string, ownerID uuid.UUID) (RestoreResult, error) {
+	queries := New(tx)
+	tenantStore := tenant.NewService(logger, tx)
+	questionService := question.NewService(logger, tx)
+	formService := form.NewService(logger, tx, response.NewService(logger, tx))
+	workflowService := workflow.NewService(logger, tx, questionService, s.secretBox)
+	formStore := importer.NewService(logger, tx, formService, workflowService, questionService)
+
+	orgID, err := queries.CreateOrg(ctx, CreateOrgParams{
+		Name:        toText(archive.Org.Name),
+		Description: pgtype.Text{String: archive.Org.Description, Valid: true},
+		Metadata:    archive.Org.Metadata,
+	})
+	if err != nil {
+		return RestoreResult{}, databaseutil.WrapDBError(err, logger, "create organization")
+	}
+	result := RestoreResult{OrgID: orgID, Slug: slug}
+
+	_, err = tenantStore.Create(ctx, orgID, ownerID, slug)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	ids := map[uuid.UUID]uuid.UUID{archive.Org.ID: orgID}
+	pending := archive.Units
+	for len(pending) > 0 {
+		var next []ArchiveUnit
+		for _, unit := range pending {
+			parentID := orgID
+			if unit.ParentID != nil {
+				mapped, ok := ids[*unit.ParentID]
+				if !ok && containsUnit(pending, *unit.ParentID) {
+					// The parent comes later in the archive
+					next = append(next, unit)
+					continue
+				}
+				if ok {
+					parentID = mapped
+				}
+			}
+
+			id, err := queries.CreateUnit(ctx, CreateUnitParams{
+				Name:        toText(unit.Name),
+				Description: pgtype.Text{String: unit.Description, Valid: true},
+				Metadata:    unit.Metadata,
+				OrgID:       pgtype.UUID{Bytes: orgID, Valid: true},
+				ParentID:    pgtype.UUID{Bytes: parentID, Valid: true},
+			})
+			if err != nil {
+				return RestoreResult{}, databaseutil.WrapDBErrorWithKeyValue(err, "units", "org_id", orgID.String(), logger, "create unit")
+			}
+			ids[unit.ID] = id
+			result.Units++
+		}
+		if len(next) == len(pending) {
+			return RestoreResult{}, fmt.Errorf("%w: the parents of the units form a cycle", internal.ErrBackupArchiveInvalid)
+		}
+		pending = next
+	}
+
+	for _, member := range archive.Members {
+		unitID, ok := ids[member.UnitID]
+		if !ok {
+			return RestoreResult{}, fmt.Errorf("%w: member %s belongs to unknown unit %s", internal.ErrBackupArchiveInvalid, member.Email, member.UnitID)
+		}
+
+		validUntil := pgtype.Timestamptz{}
+		if member.ValidUntil != nil {
+			validUntil = pgtype.Timestamptz{Time: *member.ValidUntil, Valid: true}
+		}
+
+		added, err := queries.AddMember(ctx, AddMemberParams{
+			UnitID:     unitID,
+			ValidUntil: validUntil,
+			Email:      member.Email,
+		})
+		if err != nil {
+			return RestoreResult{}, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", unitID.String(), logger, "add member")
+		}
+		if added == 0 {
+			result.SkippedMembers = append(result.SkippedMembers, member.Email)
+			continue
+		}
+		result.Members++
+	}
+
+	for _, entry := range archive.Forms {
+		unitID, ok := ids[entry.UnitID]
+		if !ok {
+			return RestoreResult{}, fmt.Errorf("%w: form %q belongs to unknown unit %s", internal.ErrBackupArchiveInvalid, entry.Document.Form.Title, entry.UnitID)
+		}
+
+		_, err = formStore.ImportDocument(ctx, orgID, unitID, ownerID, entry.Document)
+		if err != nil {
+			return RestoreResult{}, fmt.Errorf("failed to restore form %q: %w", entry.Document.Form.Title, err)
+		}
+		result.Forms++
+	}
+
+	return result, nil
+}
+
+func containsUnit(units []ArchiveUnit, id uuid.UUID) bool {
+	for _, unit := range units {
+		if unit.ID == id {
+			return true
+		}
+	}
+	return false
+}
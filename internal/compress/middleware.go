@@ -0,0 +1,250 @@
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"go.uber.org/zap"
+)
+
+// DefaultMinSize is the smallest body worth compressing; below it the headers and
+// framing cost more than they save
+const DefaultMinSize = 1024
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// encoder is implemented by both gzip.Writer and brotli.Writer
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	encodingBrotli: {New: func() any { return brotli.NewWriterLevel(nil, brotli.DefaultCompression) }},
+	encodingGzip:   {New: func() any { return gzip.NewWriter(nil) }},
+}
+
+type Middleware struct {
+	logger  *zap.Logger
+	minSize int
+}
+
+func NewMiddleware(logger *zap.Logger, minSize int) Middleware {
+	return Middleware{
+		logger:  logger,
+		minSize: minSize,
+	}
+}
+
+func (m Middleware) HandlerFunc(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		// Compressing would break byte ranges, and HEAD has no body to compress
+		encoding := negotiate(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next(w, r)
+			return
+		}
+
+		cw := &responseWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			minSize:        m.minSize,
+		}
+		defer func() {
+			err := cw.Close()
+			if err != nil {
+				m.logger.Warn("Failed to finish compressed response", zap.String("encoding", encoding), zap.Error(err))
+			}
+		}()
+
+		next(cw, r)
+	}
+}
+
+// negotiate picks br over gzip when the client accepts both, honouring q=0
+func negotiate(acceptEncoding string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(key, "q") {
+				parsed, err := strconv.ParseFloat(value, 64)
+				if err == nil {
+					q = parsed
+				}
+			}
+		}
+		accepted[name] = q > 0
+	}
+
+	switch {
+	case accepted[encodingBrotli]:
+		return encodingBrotli
+	case accepted[encodingGzip], accepted["*"]:
+		return encodingGzip
+	default:
+		return ""
+	}
+}
+
+// compressible skips bodies that are already compressed or have no content
+func compressible(status int, header http.Header) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "image/svg"):
+		return false
+	case strings.HasPrefix(contentType, "video/"), strings.HasPrefix(contentType, "audio/"):
+		return false
+	case strings.Contains(contentType, "zip"), strings.Contains(contentType, "compressed"):
+		return false
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		// Streams are flushed event by event, too small to gain from compression
+		return false
+	}
+	return true
+}
+
+// responseWriter holds the first minSize bytes back to decide whether compressing pays off
+type responseWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status  int
+	buffer  []byte
+	started bool
+	encoder encoder
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.started || w.status != 0 {
+		return
+	}
+	w.status = status
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.started {
+		return w.write(p)
+	}
+
+	w.buffer = append(w.buffer, p...)
+	if len(w.buffer) >= w.minSize {
+		err := w.start(true)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *responseWriter) write(p []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// start sends the headers and the held back bytes, compressed if worthwhile
+func (w *responseWriter) start(large bool) error {
+	w.started = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	header := w.ResponseWriter.Header()
+	if large && compressible(w.status, header) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+
+		w.encoder = encoderPools[w.encoding].Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buffer := w.buffer
+	w.buffer = nil
+	if len(buffer) == 0 {
+		return nil
+	}
+	_, err := w.write(buffer)
+	return err
+}
+
+// Close flushes a body that stayed below minSize uncompressed and returns the encoder to its pool
+func (w *responseWriter) Close() error {
+	if !w.started {
+		if w.status == 0 && len(w.buffer) == 0 {
+			return nil
+		}
+		err := w.start(false)
+		if err != nil {
+			return err
+		}
+	}
+	if w.encoder == nil {
+		return nil
+	}
+
+	err := w.encoder.Close()
+	w.encoder.Reset(nil)
+	encoderPools[w.encoding].Put(w.encoder)
+	w.encoder = nil
+	return err
+}
+
+func (w *responseWriter) Flush() {
+	if !w.started {
+		err := w.start(len(w.buffer) > 0)
+		if err != nil {
+			return
+		}
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
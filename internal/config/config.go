@@ -1,11 +1,25 @@
 package config
 
 import (
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"NYCU-SDC/core-system-backend/internal/auth"
 	googleOauth "NYCU-SDC/core-system-backend/internal/auth/oauthprovider"
+	"NYCU-SDC/core-system-backend/internal/form/payment"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/magiclink"
+	"NYCU-SDC/core-system-backend/internal/mail"
+	"NYCU-SDC/core-system-backend/internal/oidc"
+	"NYCU-SDC/core-system-backend/internal/push"
+	"NYCU-SDC/core-system-backend/internal/quota"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"NYCU-SDC/core-system-backend/internal/secrets"
+	"NYCU-SDC/core-system-backend/internal/storage"
+	"NYCU-SDC/core-system-backend/internal/trace"
 	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,6 +39,7 @@ type Config struct {
 	// instead of SameSite=Strict, allowing cross-site requests during development.
 	Dev                       bool                    `yaml:"dev"                envconfig:"DEV"`
 	Debug                     bool                    `yaml:"debug"              envconfig:"DEBUG"`
+	LogLevel                  string                  `yaml:"log_level"          envconfig:"LOG_LEVEL"`
 	Host                      string                  `yaml:"host"               envconfig:"HOST"`
 	Port                      string                  `yaml:"port"               envconfig:"PORT"`
 	BaseURL                   string                  `yaml:"base_url"          envconfig:"BASE_URL"`
@@ -33,14 +48,39 @@ type Config struct {
 	Secret                    string                  `yaml:"secret"             envconfig:"SECRET"`
 	DatabaseURL               string                  `yaml:"database_url"       envconfig:"DATABASE_URL"`
 	MigrationSource           string                  `yaml:"migration_source"   envconfig:"MIGRATION_SOURCE"`
+	MigrationCheck            bool                    `yaml:"migration_check"    envconfig:"MIGRATION_CHECK"`
+	SkipMigrations            bool                    `yaml:"skip_migrations"    envconfig:"SKIP_MIGRATIONS"`
+	MigrateOnly               bool                    `yaml:"migrate_only"       envconfig:"MIGRATE_ONLY"`
 	AccessTokenExpirationStr  string                  `yaml:"access_token_expiration" envconfig:"ACCESS_TOKEN_EXPIRATION"`
 	RefreshTokenExpirationStr string                  `yaml:"refresh_token_expiration" envconfig:"REFRESH_TOKEN_EXPIRATION"`
+	ExportIntervalStr         string                  `yaml:"export_interval"    envconfig:"EXPORT_INTERVAL"`
+	ClamAVAddress             string                  `yaml:"clamav_address"     envconfig:"CLAMAV_ADDRESS"`
 	OtelCollectorUrl          string                  `yaml:"otel_collector_url" envconfig:"OTEL_COLLECTOR_URL"`
+	OtelLogs                  bool                    `yaml:"otel_logs"          envconfig:"OTEL_LOGS"`
+	SentryDSN                 string                  `yaml:"sentry_dsn"         envconfig:"SENTRY_DSN"`
+	TraceSampling             trace.SamplingConfig    `yaml:"trace_sampling"`
 	AllowOrigins              []string                `yaml:"allow_origins"      envconfig:"ALLOW_ORIGINS"`
+	TrustedProxies            []string                `yaml:"trusted_proxies"    envconfig:"TRUSTED_PROXIES"`
+	ServeFrontend             bool                    `yaml:"serve_frontend"     envconfig:"SERVE_FRONTEND"`
 	GoogleOauth               googleOauth.GoogleOauth `yaml:"google_oauth"`
+	Storage                   storage.Config          `yaml:"storage"`
+	Push                      push.Config             `yaml:"push"`
+	Payment                   payment.Config          `yaml:"payment"`
+	JWT                       jwt.Config              `yaml:"jwt"`
+	Secrets                   secrets.Config          `yaml:"secrets"`
+	IntrospectionClients      []auth.Client           `yaml:"introspection_clients"`
+	OIDC                      oidc.Config             `yaml:"oidc"`
+	BodyLimits                route.BodyLimits        `yaml:"body_limits"`
+	Quota                     quota.Config            `yaml:"quota"`
+	Mail                      mail.Config             `yaml:"mail"`
+	MagicLink                 magiclink.Config        `yaml:"magic_link"`
 
 	AccessTokenExpiration  time.Duration `yaml:"-"`
 	RefreshTokenExpiration time.Duration `yaml:"-"`
+	ExportInterval         time.Duration `yaml:"-"`
+
+	// TrustedProxyRanges are the parsed TrustedProxies
+	TrustedProxyRanges audit.TrustedProxies `yaml:"-"`
 }
 
 type LogBuffer struct {
@@ -104,6 +144,78 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Parse export_interval string into time.Duration
+	if c.ExportIntervalStr != "" {
+		c.ExportInterval, err = time.ParseDuration(c.ExportIntervalStr)
+		if err != nil {
+			return fmt.Errorf("invalid export_interval: %w", err)
+		}
+		if c.ExportInterval <= 0 {
+			return fmt.Errorf("export_interval must be greater than zero")
+		}
+	}
+
+	c.TrustedProxyRanges, err = audit.ParseTrustedProxies(c.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	for _, client := range c.IntrospectionClients {
+		if client.ID == "" || client.Secret == "" {
+			return fmt.Errorf("introspection clients need both client_id and client_secret")
+		}
+	}
+
+	err = c.Storage.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.Push.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.Payment.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.JWT.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.OIDC.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.BodyLimits.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.Quota.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.Mail.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.MagicLink.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = c.TraceSampling.Validate()
+	if err != nil {
+		return err
+	}
+
 	if c.OauthProxyBaseURL != "" && c.OauthProxySecret == "" {
 		return fmt.Errorf("oauth_proxy_secret must be set when oauth_proxy_base_url is provided")
 	} else if c.OauthProxyBaseURL == "" && c.OauthProxySecret == "" {
@@ -132,6 +244,7 @@ func Load() (Config, *LogBuffer) {
 		MigrationSource:           "file://internal/database/migrations",
 		AccessTokenExpirationStr:  "15m",
 		RefreshTokenExpirationStr: "720h",
+		ExportIntervalStr:         "5m",
 		OtelCollectorUrl:          "",
 		GoogleOauth:               googleOauth.GoogleOauth{},
 	}
@@ -156,6 +269,24 @@ func Load() (Config, *LogBuffer) {
 	return *config, logger
 }
 
+// Reload reads the config file and the environment again, for the settings that can
+// change while running. Flags are left out, they cannot change.
+func Reload() (Config, error) {
+	logger := NewConfigLogger()
+
+	config, err := FromFile("config.yaml", &Config{}, logger)
+	if err != nil && !os.IsNotExist(err) {
+		return Config{}, err
+	}
+
+	config, err = FromEnv(config, logger)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return *config, nil
+}
+
 func FromFile(filePath string, config *Config, logger *LogBuffer) (*Config, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
@@ -191,9 +322,102 @@ func FromEnv(config *Config, logger *LogBuffer) (*Config, error) {
 		config.AllowOrigins = strings.Split(allowOrigins, ",")
 	}
 
+	// Trusted proxies, whose X-Forwarded-For hops give the client address
+	trustedProxies := os.Getenv("TRUSTED_PROXIES")
+	if trustedProxies != "" {
+		config.TrustedProxies = strings.Split(trustedProxies, ",")
+	}
+
+	// JWT key files, the first one signs new tokens
+	jwtKeyFiles := os.Getenv("JWT_KEY_FILES")
+	if jwtKeyFiles != "" {
+		config.JWT.KeyFiles = strings.Split(jwtKeyFiles, ",")
+	}
+	jwtHMACUntil := os.Getenv("JWT_HMAC_UNTIL")
+	if jwtHMACUntil != "" {
+		config.JWT.HMACUntilStr = jwtHMACUntil
+	}
+
+	// Secrets key files, the first one seals new integration credentials
+	secretsKeyFiles := os.Getenv("SECRETS_KEY_FILES")
+	if secretsKeyFiles != "" {
+		config.Secrets.KeyFiles = strings.Split(secretsKeyFiles, ",")
+	}
+
+	// Introspection clients as comma separated client_id:client_secret pairs
+	introspectionClients := os.Getenv("INTROSPECTION_CLIENTS")
+	if introspectionClients != "" {
+		config.IntrospectionClients = nil
+		for _, pair := range strings.Split(introspectionClients, ",") {
+			id, secret, _ := strings.Cut(pair, ":")
+			config.IntrospectionClients = append(config.IntrospectionClients, auth.Client{ID: id, Secret: secret})
+		}
+	}
+
+	// Trace sampling overrides as comma separated path_prefix=ratio pairs
+	traceSampleRoutes := os.Getenv("TRACE_SAMPLE_ROUTES")
+	if traceSampleRoutes != "" {
+		config.TraceSampling.Routes = make(map[string]float64)
+		for _, pair := range strings.Split(traceSampleRoutes, ",") {
+			prefix, value, _ := strings.Cut(pair, "=")
+			ratio, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				logger.Warn("Ignoring invalid trace sample route", err, map[string]string{"route": prefix})
+				continue
+			}
+			config.TraceSampling.Routes[prefix] = ratio
+		}
+	}
+	traceSampleRatio := os.Getenv("TRACE_SAMPLE_RATIO")
+	if traceSampleRatio != "" {
+		config.TraceSampling.RatioStr = traceSampleRatio
+	}
+
+	// Request body limits in bytes
+	for name, limit := range map[string]*int64{
+		"BODY_LIMIT_DEFAULT": &config.BodyLimits.Default,
+		"BODY_LIMIT_AUTH":    &config.BodyLimits.Auth,
+		"BODY_LIMIT_UPLOAD":  &config.BodyLimits.Upload,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			logger.Warn("Ignoring invalid body limit", err, map[string]string{"env": name})
+			continue
+		}
+		*limit = parsed
+	}
+
+	// Domains allowed to sign in with magic links, comma separated
+	magicLinkAllowedDomains := os.Getenv("MAGIC_LINK_ALLOWED_DOMAINS")
+	if magicLinkAllowedDomains != "" {
+		config.MagicLink.AllowedDomains = strings.Split(magicLinkAllowedDomains, ",")
+	}
+
+	// Default quotas of organizations, zero for unlimited
+	for name, target := range map[string]*int32{
+		"QUOTA_FORMS":   &config.Quota.Forms,
+		"QUOTA_MEMBERS": &config.Quota.Members,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			logger.Warn("Ignoring invalid quota", err, map[string]string{"env": name})
+			continue
+		}
+		*target = int32(parsed)
+	}
+
 	envConfig := &Config{
 		Debug:             os.Getenv("DEBUG") == "true",
 		Dev:               os.Getenv("DEV") == "true",
+		LogLevel:          os.Getenv("LOG_LEVEL"),
 		Host:              os.Getenv("HOST"),
 		Port:              os.Getenv("PORT"),
 		BaseURL:           os.Getenv("BASE_URL"),
@@ -202,11 +426,55 @@ func FromEnv(config *Config, logger *LogBuffer) (*Config, error) {
 		Secret:            os.Getenv("SECRET"),
 		DatabaseURL:       os.Getenv("DATABASE_URL"),
 		MigrationSource:   os.Getenv("MIGRATION_SOURCE"),
+		MigrationCheck:    os.Getenv("MIGRATION_CHECK") == "true",
+		SkipMigrations:    os.Getenv("SKIP_MIGRATIONS") == "true",
+		MigrateOnly:       os.Getenv("MIGRATE_ONLY") == "true",
 		OtelCollectorUrl:  os.Getenv("OTEL_COLLECTOR_URL"),
+		OtelLogs:          os.Getenv("OTEL_LOGS") == "true",
+		SentryDSN:         os.Getenv("SENTRY_DSN"),
+		ExportIntervalStr: os.Getenv("EXPORT_INTERVAL"),
+		ClamAVAddress:     os.Getenv("CLAMAV_ADDRESS"),
+		ServeFrontend:     os.Getenv("SERVE_FRONTEND") == "true",
 		GoogleOauth: googleOauth.GoogleOauth{
 			ClientID:     os.Getenv("GOOGLE_OAUTH_CLIENT_ID"),
 			ClientSecret: os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET"),
 		},
+		Storage: storage.Config{
+			Backend:         os.Getenv("STORAGE_BACKEND"),
+			Bucket:          os.Getenv("STORAGE_BUCKET"),
+			Endpoint:        os.Getenv("STORAGE_ENDPOINT"),
+			Region:          os.Getenv("STORAGE_REGION"),
+			AccessKeyID:     os.Getenv("STORAGE_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("STORAGE_SECRET_ACCESS_KEY"),
+			UsePathStyle:    os.Getenv("STORAGE_USE_PATH_STYLE") == "true",
+			PresignTTLStr:   os.Getenv("STORAGE_PRESIGN_TTL"),
+			LocalDir:        os.Getenv("STORAGE_LOCAL_DIR"),
+		},
+		Push: push.Config{
+			VAPIDPublicKey:     os.Getenv("PUSH_VAPID_PUBLIC_KEY"),
+			VAPIDPrivateKey:    os.Getenv("PUSH_VAPID_PRIVATE_KEY"),
+			VAPIDSubject:       os.Getenv("PUSH_VAPID_SUBJECT"),
+			FCMCredentialsFile: os.Getenv("PUSH_FCM_CREDENTIALS_FILE"),
+		},
+		Mail: mail.Config{
+			Host:     os.Getenv("MAIL_HOST"),
+			Port:     os.Getenv("MAIL_PORT"),
+			Username: os.Getenv("MAIL_USERNAME"),
+			Password: os.Getenv("MAIL_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		},
+		MagicLink: magiclink.Config{
+			Enabled: os.Getenv("MAGIC_LINK_ENABLED") == "true",
+			TTLStr:  os.Getenv("MAGIC_LINK_TTL"),
+		},
+		Payment: payment.Config{
+			ECPayMerchantID:    os.Getenv("PAYMENT_ECPAY_MERCHANT_ID"),
+			ECPayHashKey:       os.Getenv("PAYMENT_ECPAY_HASH_KEY"),
+			ECPayHashIV:        os.Getenv("PAYMENT_ECPAY_HASH_IV"),
+			NewebPayMerchantID: os.Getenv("PAYMENT_NEWEBPAY_MERCHANT_ID"),
+			NewebPayHashKey:    os.Getenv("PAYMENT_NEWEBPAY_HASH_KEY"),
+			NewebPayHashIV:     os.Getenv("PAYMENT_NEWEBPAY_HASH_IV"),
+		},
 	}
 
 	return configutil.Merge[Config](config, envConfig)
@@ -217,17 +485,29 @@ func FromFlags(config *Config) (*Config, error) {
 
 	flag.BoolVar(&flagConfig.Debug, "debug", false, "debug mode")
 	flag.BoolVar(&flagConfig.Dev, "dev", false, "dev mode")
+	flag.StringVar(&flagConfig.LogLevel, "log_level", "", "log level")
 	flag.StringVar(&flagConfig.Host, "host", "", "host")
 	flag.StringVar(&flagConfig.Port, "port", "", "port")
 	flag.StringVar(&flagConfig.BaseURL, "base_url", "", "base url")
 	flag.StringVar(&flagConfig.Secret, "secret", "", "secret")
 	flag.StringVar(&flagConfig.DatabaseURL, "database_url", "", "database url")
 	flag.StringVar(&flagConfig.MigrationSource, "migration_source", "", "migration source")
+	flag.BoolVar(&flagConfig.MigrationCheck, "check", false, "report migration drift and exit without migrating")
+	flag.BoolVar(&flagConfig.SkipMigrations, "skip_migrations", false, "start without running migrations")
+	flag.BoolVar(&flagConfig.MigrateOnly, "migrate_only", false, "run migrations and exit")
 	flag.StringVar(&flagConfig.OtelCollectorUrl, "otel_collector_url", "", "OpenTelemetry collector URL")
+	flag.BoolVar(&flagConfig.OtelLogs, "otel_logs", false, "export logs to the OpenTelemetry collector")
+	// Kept apart from flagConfig, merging it would drop the routes of the config file
+	var traceSampleRatio string
+	flag.StringVar(&traceSampleRatio, "trace_sample_ratio", "", "share of requests traced, from 0 to 1")
 	flag.StringVar(&flagConfig.GoogleOauth.ClientID, "google_oauth_client_id", "", "Google OAuth client ID")
 	flag.StringVar(&flagConfig.GoogleOauth.ClientSecret, "google_oauth_client_secret", "", "Google OAuth client secret")
 
 	flag.Parse()
 
+	if traceSampleRatio != "" {
+		config.TraceSampling.RatioStr = traceSampleRatio
+	}
+
 	return configutil.Merge[Config](config, flagConfig)
 }
@@ -10,6 +10,7 @@ import (
 type Middleware struct {
 	logger       *zap.Logger
 	allowOrigins []string
+	permissive   bool
 }
 
 func NewMiddleware(logger *zap.Logger, allowOrigins []string) Middleware {
@@ -20,6 +21,54 @@ func NewMiddleware(logger *zap.Logger, allowOrigins []string) Middleware {
 	}
 }
 
+// NewPermissiveMiddleware allows credentialed requests from any origin with any header,
+// for a frontend dev server on whatever port it started on. Dev mode only.
+func NewPermissiveMiddleware(logger *zap.Logger) Middleware {
+	logger.Warn("CORS middleware allowing every origin")
+	return Middleware{
+		logger:     logger,
+		permissive: true,
+	}
+}
+
 func (m Middleware) HandlerFunc(next http.HandlerFunc) http.HandlerFunc {
-	return corsutil.CORSMiddleware(next, m.logger, m.allowOrigins)
+	if m.permissive {
+		return permissive(next)
+	}
+
+	handler := corsutil.CORSMiddleware(next, m.logger, m.allowOrigins)
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Lets browser clients read the correlation identifier of the response and the
+		// deprecation notices of the API
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-Id, Deprecation, Sunset, Link, Deprecated-Fields")
+		handler(w, r)
+	}
+}
+
+func permissive(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-Id, ETag, Location, Deprecation, Sunset, Link, Deprecated-Fields")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+			headers := r.Header.Get("Access-Control-Request-Headers")
+			if headers != "" {
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+			}
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next(w, r)
+	}
 }
@@ -65,8 +65,11 @@ CREATE INDEX idx_units_parent_id ON units(parent_id);
 CREATE TABLE IF NOT EXISTS unit_members (
     unit_id UUID REFERENCES units(id) ON DELETE CASCADE,
     member_id UUID,
+    valid_until TIMESTAMPTZ DEFAULT NULL,
     PRIMARY KEY (unit_id, member_id)
 );
+
+CREATE INDEX idx_unit_members_valid_until ON unit_members(valid_until) WHERE valid_until IS NOT NULL;
 CREATE TYPE db_strategy AS ENUM ('shared', 'isolated');
 
 CREATE TABLE IF NOT EXISTS tenants
@@ -83,19 +86,41 @@ CREATE TABLE IF NOT EXISTS slug_history
     org_id UUID REFERENCES units(id) ON DELETE CASCADE,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     ended_at TIMESTAMPTZ DEFAULT null
-);CREATE EXTENSION IF NOT EXISTS pgcrypto;
+);CREATE TABLE IF NOT EXISTS recipient_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS recipient_group_members (
+    group_id UUID NOT NULL REFERENCES recipient_groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX idx_recipient_group_members_user_id ON recipient_group_members(user_id);CREATE EXTENSION IF NOT EXISTS pgcrypto;
 
 CREATE TABLE IF NOT EXISTS refresh_tokens (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     is_active BOOLEAN DEFAULT TRUE,
-    expiration_date TIMESTAMPTZ NOT NULL
+    expiration_date TIMESTAMPTZ NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    platform_hash BYTEA
 );-- Node type enum for workflow nodes
 CREATE TYPE node_type AS ENUM(
     'section',
     'end',
     'start',
-    'condition'
+    'condition',
+    'approval',
+    'delay',
+    'action'
 );
 
 CREATE TABLE IF NOT EXISTS workflow_versions (
@@ -111,15 +136,21 @@ CREATE TABLE IF NOT EXISTS workflow_versions (
 CREATE INDEX idx_workflow_versions_is_active ON workflow_versions(form_id, is_active) WHERE is_active = true;
 
 CREATE INDEX idx_workflow_versions_latest ON workflow_versions(form_id, updated_at DESC);
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE IF NOT EXISTS form_responses (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
     submitted_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     submitted_at TIMESTAMPTZ DEFAULT NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    is_test BOOLEAN NOT NULL DEFAULT false,
+    anonymized_at TIMESTAMPTZ DEFAULT NULL
 );
 
+CREATE INDEX IF NOT EXISTS idx_form_responses_test ON form_responses(form_id) WHERE is_test;
+
 CREATE TABLE IF NOT EXISTS answers (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
@@ -128,7 +159,20 @@ CREATE TABLE IF NOT EXISTS answers (
     value TEXT NOT NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
-);CREATE TYPE status AS ENUM(
+);
+
+CREATE TABLE IF NOT EXISTS answer_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    answer_id UUID NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+    response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    previous_value TEXT NOT NULL,
+    value TEXT NOT NULL,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_answer_revisions_response_id ON answer_revisions(response_id, created_at);CREATE TYPE status AS ENUM(
     'draft',
     'published'
 );
@@ -143,7 +187,10 @@ CREATE TABLE IF NOT EXISTS forms (
     last_editor UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     deadline TIMESTAMPTZ DEFAULT NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    randomize_questions BOOLEAN NOT NULL DEFAULT false,
+    shuffle_choices BOOLEAN NOT NULL DEFAULT false,
+    time_limit_seconds INTEGER DEFAULT NULL CHECK (time_limit_seconds > 0)
 );
 
 -- Section progress enum (for form completion tracking)
@@ -177,7 +224,8 @@ CREATE TYPE question_type AS ENUM(
     'rating',
     'ranking',
     'oauth_connect',
-    'hyperlink'
+    'hyperlink',
+    'consent'
 );
 
 CREATE TABLE IF NOT EXISTS questions(
@@ -194,7 +242,9 @@ CREATE TABLE IF NOT EXISTS questions(
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );CREATE TYPE content_type AS ENUM(
     'text',
-    'form'
+    'form',
+    'task',
+    'quota'
 );
 
 CREATE TABLE IF NOT EXISTS inbox_message(
@@ -203,14 +253,923 @@ CREATE TABLE IF NOT EXISTS inbox_message(
     type content_type NOT NULL,
     content_id UUID NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT now(),
-    updated_at TIMESTAMP NOT NULL DEFAULT now()
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    reply_to UUID DEFAULT NULL references inbox_message(id) ON DELETE CASCADE,
+    thread_id UUID DEFAULT NULL references inbox_message(id) ON DELETE CASCADE,
+    sender_id UUID DEFAULT NULL references users(id) ON DELETE SET NULL,
+    body TEXT DEFAULT NULL
 );
 
+CREATE INDEX IF NOT EXISTS idx_inbox_message_thread_id ON inbox_message(thread_id);
+
 CREATE TABLE IF NOT EXISTS user_inbox_messages (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     user_id UUID NOT NULL references users(id) ON DELETE CASCADE,
     message_id UUID NOT NULL references inbox_message(id) ON DELETE CASCADE,
     is_read boolean NOT NULL DEFAULT false,
     is_starred boolean NOT NULL DEFAULT false,
-    is_archived boolean NOT NULL DEFAULT false
+    is_archived boolean NOT NULL DEFAULT false,
+    snoozed_until TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_snoozed_until ON user_inbox_messages(snoozed_until) WHERE snoozed_until IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_user_id ON user_inbox_messages(user_id, is_archived, message_id);
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_message_id ON user_inbox_messages(message_id, user_id);
+
+CREATE TABLE IF NOT EXISTS unit_inbox_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL references units(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL references inbox_message(id) ON DELETE CASCADE,
+    assignee_id UUID DEFAULT NULL references users(id) ON DELETE SET NULL,
+    is_read boolean NOT NULL DEFAULT false,
+    is_archived boolean NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (unit_id, message_id)
+);CREATE TYPE eligibility_rule_type AS ENUM(
+    'unit_member',
+    'email_domain',
+    'attribute',
+    'group_member'
+);
+
+CREATE TABLE IF NOT EXISTS form_eligibility_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    type eligibility_rule_type NOT NULL,
+    unit_id UUID REFERENCES units(id) ON DELETE CASCADE,
+    group_id UUID REFERENCES recipient_groups(id) ON DELETE CASCADE,
+    attribute_key TEXT DEFAULT NULL,
+    value TEXT DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_form_eligibility_rules_form_id ON form_eligibility_rules(form_id);CREATE TABLE IF NOT EXISTS form_progress (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    current_section_id UUID REFERENCES sections(id) ON DELETE SET NULL,
+    visited_nodes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, user_id)
+);CREATE TYPE approval_status AS ENUM(
+    'pending',
+    'approved',
+    'rejected'
+);
+
+CREATE TABLE IF NOT EXISTS form_approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
+    node_id TEXT NOT NULL,
+    approver_unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    status approval_status NOT NULL DEFAULT 'pending',
+    comment TEXT DEFAULT NULL,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (response_id, node_id)
+);
+
+CREATE INDEX idx_form_approvals_pending ON form_approvals(approver_unit_id, created_at) WHERE status = 'pending';CREATE TYPE action_run_status AS ENUM(
+    'pending',
+    'succeeded',
+    'failed'
+);
+
+CREATE TABLE IF NOT EXISTS form_action_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    node_id TEXT NOT NULL,
+    status action_run_status NOT NULL DEFAULT 'pending',
+    error TEXT DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, user_id, node_id)
+);CREATE TABLE IF NOT EXISTS answer_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES answer_comments(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    content TEXT NOT NULL,
+    visible_to_respondent BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_answer_comments_answer ON answer_comments(response_id, question_id, created_at);CREATE TYPE export_frequency AS ENUM(
+    'daily',
+    'weekly'
+);
+
+CREATE TYPE export_destination AS ENUM(
+    'webhook',
+    's3'
+);
+
+CREATE TABLE IF NOT EXISTS form_export_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    frequency export_frequency NOT NULL,
+    destination export_destination NOT NULL,
+    target TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ DEFAULT NULL,
+    last_error TEXT DEFAULT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    masked BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX idx_form_export_schedules_due ON form_export_schedules(next_run_at) WHERE enabled;CREATE TYPE upload_status AS ENUM(
+    'pending',
+    'clean',
+    'infected'
+);
+
+CREATE TABLE IF NOT EXISTS form_uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    status upload_status NOT NULL DEFAULT 'pending',
+    scan_result TEXT DEFAULT NULL,
+    scanned_at TIMESTAMPTZ DEFAULT NULL,
+    has_variants BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_form_uploads_pending ON form_uploads(created_at) WHERE status = 'pending';CREATE TYPE push_platform AS ENUM(
+    'web',
+    'fcm'
+);
+
+CREATE TYPE push_job_status AS ENUM(
+    'pending',
+    'sent',
+    'failed'
 );
+
+CREATE TYPE push_digest AS ENUM(
+    'immediate',
+    'hourly',
+    'daily'
+);
+
+CREATE TABLE IF NOT EXISTS push_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform push_platform NOT NULL,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT DEFAULT NULL,
+    auth TEXT DEFAULT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    last_used_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices(user_id);
+
+CREATE TABLE IF NOT EXISTS push_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    form_messages BOOLEAN NOT NULL DEFAULT true,
+    text_messages BOOLEAN NOT NULL DEFAULT true,
+    digest push_digest NOT NULL DEFAULT 'immediate',
+    last_digest_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS push_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES inbox_message(id) ON DELETE CASCADE,
+    status push_job_status NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_jobs_pending ON push_jobs(next_attempt_at) WHERE status = 'pending';CREATE TYPE audit_action AS ENUM ('login', 'create', 'update', 'delete', 'submit', 'view');
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action audit_action NOT NULL,
+    route TEXT NOT NULL DEFAULT '',
+    path TEXT NOT NULL DEFAULT '',
+    status_code INT NOT NULL DEFAULT 0,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_logs_user_id_created_at ON audit_logs(user_id, created_at DESC);
+
+CREATE TYPE security_event_type AS ENUM ('refresh_token_mismatch');
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type security_event_type NOT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_security_events_user_id_created_at ON security_events(user_id, created_at DESC);CREATE TABLE IF NOT EXISTS student_ids (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    value VARCHAR(32) NOT NULL,
+    verified_at TIMESTAMPTZ DEFAULT NULL,
+    verified_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Anyone can claim an ID, but only one account can hold it verified
+CREATE UNIQUE INDEX idx_student_ids_verified_value ON student_ids(value) WHERE verified_at IS NOT NULL;
+CREATE INDEX idx_student_ids_value ON student_ids(value);CREATE TABLE IF NOT EXISTS oidc_authorization_codes (
+    code_hash VARCHAR(64) PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL DEFAULT '',
+    nonce TEXT NOT NULL DEFAULT '',
+    code_challenge TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_oidc_authorization_codes_expires_at ON oidc_authorization_codes(expires_at);
+
+CREATE TYPE device_authorization_status AS ENUM ('pending', 'approved', 'denied');
+
+CREATE TABLE IF NOT EXISTS device_authorizations (
+    device_code_hash VARCHAR(64) PRIMARY KEY,
+    user_code VARCHAR(8) NOT NULL UNIQUE,
+    client_id TEXT NOT NULL,
+    scope TEXT NOT NULL DEFAULT '',
+    status device_authorization_status NOT NULL DEFAULT 'pending',
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    last_polled_at TIMESTAMPTZ DEFAULT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_device_authorizations_expires_at ON device_authorizations(expires_at);CREATE TABLE IF NOT EXISTS form_public_access (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    enabled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS form_respondent_guests (
+    respondent_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_respondent_guests_ip_address ON form_respondent_guests(ip_address, created_at);CREATE TABLE IF NOT EXISTS form_stars (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, form_id)
+);
+
+CREATE TABLE IF NOT EXISTS form_views (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, form_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_views_user_viewed_at ON form_views(user_id, viewed_at DESC);-- Full-text indexes for GET /api/search. The 'simple' configuration does no stemming,
+-- so names and Chinese titles match the way they were typed.
+CREATE INDEX IF NOT EXISTS idx_forms_search ON forms USING GIN (to_tsvector('simple', title || ' ' || coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_units_search ON units USING GIN (to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_inbox_message_search ON inbox_message USING GIN (to_tsvector('simple', coalesce(body, ''))) WHERE type = 'text';
+CREATE INDEX IF NOT EXISTS idx_users_search ON users USING GIN (to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(username, '')));CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS form_tags (
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (form_id, tag_id)
+);
+
+CREATE INDEX idx_form_tags_tag_id ON form_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS inbox_message_tags (
+    message_id UUID NOT NULL REFERENCES inbox_message(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (message_id, tag_id)
+);
+
+CREATE INDEX idx_inbox_message_tags_tag_id ON inbox_message_tags(tag_id);CREATE TYPE assignment_strategy AS ENUM (
+    'round_robin',
+    'least_loaded'
+);
+
+-- How the responses of a form are shared out among its reviewers. An empty
+-- reviewer_ids means every member of the unit owning the form.
+CREATE TABLE IF NOT EXISTS form_assignment_settings (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    strategy assignment_strategy NOT NULL DEFAULT 'round_robin',
+    reviewer_ids UUID[] NOT NULL DEFAULT '{}',
+    rules JSONB NOT NULL DEFAULT '[]',
+    next_index INT NOT NULL DEFAULT 0,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS response_assignments (
+    response_id UUID PRIMARY KEY REFERENCES form_responses(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_response_assignments_reviewer ON response_assignments(form_id, reviewer_id);-- Points a question is worth. A choice question with correct choices is scored
+-- automatically; any other scored question is graded by hand.
+CREATE TABLE IF NOT EXISTS question_points (
+    question_id UUID PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    points INT NOT NULL CHECK (points >= 0),
+    correct_choice_ids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_points_form_id ON question_points(form_id);
+
+-- Scores given by hand. They also override the automatic score of a choice question.
+CREATE TABLE IF NOT EXISTS answer_scores (
+    response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    score INT NOT NULL CHECK (score >= 0),
+    comment TEXT,
+    graded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (response_id, question_id)
+);-- When a respondent began a timed form. There is one clock per respondent, so
+-- reloading or switching devices does not restart it.
+CREATE TABLE IF NOT EXISTS form_attempts (
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (form_id, user_id)
+);CREATE TYPE pii_masking AS ENUM(
+    'redact',
+    'hash'
+);
+
+CREATE TABLE IF NOT EXISTS pii_questions (
+    question_id UUID PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    masking pii_masking NOT NULL DEFAULT 'redact',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pii_questions_form_id ON pii_questions(form_id);CREATE TABLE IF NOT EXISTS form_retention_policies (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    delete_after_days INT DEFAULT NULL CHECK (delete_after_days > 0),
+    anonymize_after_days INT DEFAULT NULL CHECK (anonymize_after_days > 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TYPE retention_action AS ENUM(
+    'delete',
+    'anonymize'
+);
+
+CREATE TABLE IF NOT EXISTS retention_purges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL,
+    action retention_action NOT NULL,
+    after_days INT NOT NULL,
+    response_count INT NOT NULL,
+    purged_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_purges_form_id ON retention_purges(form_id, purged_at);
+
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL,
+    response_id UUID DEFAULT NULL,
+    reason TEXT NOT NULL,
+    placed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    placed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    released_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    released_at TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_holds_active ON legal_holds(form_id, response_id) WHERE released_at IS NULL;CREATE TABLE IF NOT EXISTS test_tenants (
+    org_id UUID PRIMARY KEY REFERENCES units(id) ON DELETE CASCADE,
+    label TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_tenants_expires_at ON test_tenants(expires_at);CREATE TYPE pipeline_gate AS ENUM(
+    'submitted',
+    'approved'
+);
+
+CREATE TABLE IF NOT EXISTS pipelines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pipelines_org_id ON pipelines(org_id);
+
+-- Stages are numbered from 0 in the order candidates go through them. A form is a
+-- stage of one pipeline at most; the gate is what candidates need on the stage before
+-- to take part in this one, the first stage being open to everyone the form is.
+CREATE TABLE IF NOT EXISTS pipeline_stages (
+    pipeline_id UUID NOT NULL REFERENCES pipelines(id) ON DELETE CASCADE,
+    position INT NOT NULL CHECK (position >= 0),
+    form_id UUID NOT NULL UNIQUE REFERENCES forms(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    gate pipeline_gate NOT NULL DEFAULT 'approved',
+    PRIMARY KEY (pipeline_id, position)
+);CREATE TABLE IF NOT EXISTS form_checkins (
+    response_id UUID PRIMARY KEY REFERENCES form_responses(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    checked_in_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    checked_in_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_checkins_form_id ON form_checkins(form_id, checked_in_at);CREATE TYPE attendance_status AS ENUM(
+    'present',
+    'late',
+    'absent',
+    'excused'
+);
+
+CREATE TABLE IF NOT EXISTS attendance_events (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    late_after_minutes INT NOT NULL DEFAULT 10 CHECK (late_after_minutes >= 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_events_starts_at ON attendance_events(starts_at);
+
+-- Marks set the status of a response by hand, over the one its check-in gives
+CREATE TABLE IF NOT EXISTS attendance_marks (
+    response_id UUID PRIMARY KEY REFERENCES form_responses(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    status attendance_status NOT NULL,
+    marked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    marked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_marks_form_id ON attendance_marks(form_id);CREATE TYPE payment_status AS ENUM(
+    'unpaid',
+    'pending',
+    'paid',
+    'failed',
+    'refunded',
+    'waived'
+);
+
+CREATE TABLE IF NOT EXISTS form_payment_settings (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    amount_due INT NOT NULL CHECK (amount_due >= 0),
+    currency TEXT NOT NULL DEFAULT 'TWD',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- The reference is the order number given to the payment provider, which its
+-- callbacks are matched on; the external reference is the provider's own trade number
+-- or a receipt number entered by hand.
+CREATE TABLE IF NOT EXISTS response_payments (
+    response_id UUID PRIMARY KEY REFERENCES form_responses(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    reference TEXT NOT NULL UNIQUE,
+    amount_due INT NOT NULL CHECK (amount_due >= 0),
+    amount_paid INT NOT NULL DEFAULT 0 CHECK (amount_paid >= 0),
+    status payment_status NOT NULL DEFAULT 'unpaid',
+    provider TEXT NOT NULL DEFAULT '',
+    external_reference TEXT NOT NULL DEFAULT '',
+    paid_at TIMESTAMPTZ DEFAULT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_response_payments_form_id ON response_payments(form_id);
+
+-- Every callback received, kept for reconciliation; response_id is null when the
+-- reference matched no payment
+CREATE TABLE IF NOT EXISTS payment_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider TEXT NOT NULL,
+    reference TEXT NOT NULL,
+    response_id UUID REFERENCES form_responses(id) ON DELETE SET NULL,
+    status payment_status NOT NULL,
+    amount INT NOT NULL,
+    external_reference TEXT NOT NULL DEFAULT '',
+    simulated BOOLEAN NOT NULL DEFAULT false,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_notifications_reference ON payment_notifications(reference);-- A form whose results are shared by link. The nonce is signed into the link, so sharing
+-- again with a new one revokes the links given out before.
+CREATE TABLE IF NOT EXISTS form_result_shares (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMPTZ DEFAULT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);CREATE TABLE IF NOT EXISTS form_ballots (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    enabled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Who voted, and nothing of what they voted. Only the day is kept, which turnout is
+-- counted by: a precise time would narrow the batch the ballot went into.
+CREATE TABLE IF NOT EXISTS ballot_voters (
+    form_id UUID NOT NULL REFERENCES form_ballots(form_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    voted_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    PRIMARY KEY (form_id, user_id)
+);
+
+-- What was voted, and nothing of who voted it: a random ID and no timestamp. Ballots are
+-- written in shuffled batches, apart from their voters, so neither the order of the rows
+-- nor the transaction that wrote them tells who cast one. The receipt hash lets a voter
+-- find their ballot with the receipt only they were given.
+CREATE TABLE IF NOT EXISTS ballots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES form_ballots(form_id) ON DELETE CASCADE,
+    receipt_hash BYTEA NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ballots_form_id ON ballots(form_id);
+
+CREATE TABLE IF NOT EXISTS ballot_answers (
+    ballot_id UUID NOT NULL REFERENCES ballots(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    PRIMARY KEY (ballot_id, question_id)
+);CREATE TABLE IF NOT EXISTS form_delegations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_delegations_form_id ON form_delegations(form_id);CREATE TYPE announcement_visibility AS ENUM ('organization', 'unit');
+
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    unit_id UUID DEFAULT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    visibility announcement_visibility NOT NULL DEFAULT 'organization',
+    pinned_at TIMESTAMPTZ DEFAULT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (unit_id IS NOT NULL OR visibility = 'organization')
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_board ON announcements(org_id, unit_id, pinned_at DESC NULLS LAST, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS announcement_reactions (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (announcement_id, user_id, emoji)
+);
+
+-- The first time each member read an announcement
+CREATE TABLE IF NOT EXISTS announcement_reads (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (announcement_id, user_id)
+);CREATE TYPE wiki_visibility AS ENUM ('unit', 'organization');
+
+CREATE TABLE IF NOT EXISTS wiki_pages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    slug VARCHAR(100) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    visibility wiki_visibility NOT NULL DEFAULT 'unit',
+    revision INT NOT NULL DEFAULT 1,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (unit_id, slug)
+);
+
+CREATE TABLE IF NOT EXISTS wiki_page_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    page_id UUID NOT NULL REFERENCES wiki_pages(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    summary VARCHAR(255) NOT NULL DEFAULT '',
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (page_id, revision)
+);CREATE TYPE task_status AS ENUM ('todo', 'in_progress', 'done', 'cancelled');
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status task_status NOT NULL DEFAULT 'todo',
+    due_at TIMESTAMPTZ,
+    form_id UUID REFERENCES forms(id) ON DELETE SET NULL,
+    response_id UUID REFERENCES form_responses(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_unit_id ON tasks(unit_id, status);
+
+CREATE TABLE IF NOT EXISTS task_assignees (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_assignees_user_id ON task_assignees(user_id);CREATE TABLE IF NOT EXISTS org_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    venue VARCHAR(255) NOT NULL DEFAULT '',
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    capacity INT CHECK (capacity > 0),
+    form_id UUID UNIQUE REFERENCES forms(id) ON DELETE SET NULL,
+    published BOOLEAN NOT NULL DEFAULT false,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_events_org_id ON org_events(org_id, starts_at);CREATE TYPE resource_kind AS ENUM(
+    'room',
+    'equipment'
+);
+
+CREATE TABLE IF NOT EXISTS resources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    kind resource_kind NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    location VARCHAR(255) NOT NULL DEFAULT '',
+    booking_form_id UUID REFERENCES forms(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_resources_unit_id ON resources(unit_id);
+
+CREATE TABLE IF NOT EXISTS resource_bookings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resource_id UUID NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+    booked_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    purpose TEXT NOT NULL DEFAULT '',
+    response_id UUID UNIQUE REFERENCES form_responses(id) ON DELETE CASCADE,
+    cancelled_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_bookings_time ON resource_bookings(resource_id, starts_at) WHERE cancelled_at IS NULL;CREATE TYPE budget_request_status AS ENUM(
+    'pending',
+    'approved',
+    'rejected',
+    'withdrawn'
+);
+
+-- The approval chain of an organization. A step without an approver unit is decided
+-- by the unit making the request, such as its lead.
+CREATE TABLE IF NOT EXISTS budget_approval_steps (
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    position INT NOT NULL CHECK (position >= 0),
+    name VARCHAR(100) NOT NULL,
+    approver_unit_id UUID REFERENCES units(id) ON DELETE CASCADE,
+    PRIMARY KEY (org_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS budget_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category VARCHAR(100) NOT NULL,
+    amount INT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL DEFAULT 'TWD',
+    status budget_request_status NOT NULL DEFAULT 'pending',
+    current_step INT NOT NULL DEFAULT 0,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_requests_org_id ON budget_requests(org_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_budget_requests_unit_id ON budget_requests(unit_id, created_at);
+
+-- The chain of the organization as it was when the request was made, each step resolved
+-- to the unit deciding it
+CREATE TABLE IF NOT EXISTS budget_request_steps (
+    request_id UUID NOT NULL REFERENCES budget_requests(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    approver_unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    status approval_status NOT NULL DEFAULT 'pending',
+    comment TEXT DEFAULT NULL,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ DEFAULT NULL,
+    PRIMARY KEY (request_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_request_steps_approver ON budget_request_steps(approver_unit_id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS budget_request_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id UUID NOT NULL REFERENCES budget_requests(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_request_comments_request_id ON budget_request_comments(request_id, created_at);
+
+CREATE TABLE IF NOT EXISTS budget_request_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id UUID NOT NULL REFERENCES budget_requests(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_request_attachments_request_id ON budget_request_attachments(request_id);CREATE TYPE profile_field_type AS ENUM(
+    'text',
+    'number',
+    'select'
+);
+
+CREATE TABLE IF NOT EXISTS profile_fields (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    type profile_field_type NOT NULL DEFAULT 'text',
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT false,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS profile_values (
+    field_id UUID NOT NULL REFERENCES profile_fields(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (field_id, user_id)
+);
+
+CREATE INDEX idx_profile_values_user_id ON profile_values(user_id);
+
+CREATE TABLE IF NOT EXISTS profile_prefills (
+    question_id UUID PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+    field_id UUID NOT NULL REFERENCES profile_fields(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_profile_prefills_field_id ON profile_prefills(field_id);CREATE TYPE quota_resource AS ENUM ('forms', 'members');
+
+CREATE TABLE IF NOT EXISTS org_quotas (
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    resource quota_resource NOT NULL,
+    quota_limit INTEGER NOT NULL CHECK (quota_limit >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (org_id, resource)
+);
+
+CREATE TABLE IF NOT EXISTS quota_warnings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    resource quota_resource NOT NULL,
+    threshold INTEGER NOT NULL,
+    usage INTEGER NOT NULL,
+    quota_limit INTEGER NOT NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quota_warnings_active ON quota_warnings(org_id, resource, threshold) WHERE resolved_at IS NULL;CREATE TABLE IF NOT EXISTS magic_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    redirect_url TEXT NOT NULL DEFAULT '',
+    device_hash BYTEA NOT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_magic_links_email ON magic_links(email, created_at);
+CREATE INDEX IF NOT EXISTS idx_magic_links_ip_address ON magic_links(ip_address, created_at);CREATE TABLE IF NOT EXISTS org_webhook_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_webhook_keys_org_id ON org_webhook_keys(org_id, created_at DESC);CREATE TABLE IF NOT EXISTS form_response_sources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    response_id UUID UNIQUE REFERENCES form_responses(id) ON DELETE CASCADE,
+    source VARCHAR(64) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    submitted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, source, external_id)
+);
+
+CREATE TABLE IF NOT EXISTS org_ingest_sources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    source VARCHAR(64) NOT NULL,
+    secret TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, source)
+);
\ No newline at end of file
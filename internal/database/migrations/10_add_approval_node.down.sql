@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS form_approvals;
+DROP TYPE IF EXISTS approval_status;
+
+-- Recreate the node_type enum without the approval gate node type
+CREATE TYPE node_type_old AS ENUM(
+    'section',
+    'end',
+    'start',
+    'condition'
+);
+
+DROP TYPE node_type;
+ALTER TYPE node_type_old RENAME TO node_type;
\ No newline at end of file
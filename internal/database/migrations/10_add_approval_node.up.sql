@@ -0,0 +1,34 @@
+-- Recreate the node_type enum with the approval gate node type
+CREATE TYPE node_type_new AS ENUM(
+    'section',
+    'end',
+    'start',
+    'condition',
+    'approval'
+);
+
+DROP TYPE node_type;
+ALTER TYPE node_type_new RENAME TO node_type;
+
+CREATE TYPE approval_status AS ENUM(
+    'pending',
+    'approved',
+    'rejected'
+);
+
+CREATE TABLE IF NOT EXISTS form_approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
+    node_id TEXT NOT NULL,
+    approver_unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    status approval_status NOT NULL DEFAULT 'pending',
+    comment TEXT DEFAULT NULL,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (response_id, node_id)
+);
+
+CREATE INDEX idx_form_approvals_pending ON form_approvals(approver_unit_id, created_at) WHERE status = 'pending';
\ No newline at end of file
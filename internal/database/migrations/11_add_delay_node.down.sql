@@ -0,0 +1,11 @@
+-- Recreate the node_type enum without the delay node type
+CREATE TYPE node_type_old AS ENUM(
+    'section',
+    'end',
+    'start',
+    'condition',
+    'approval'
+);
+
+DROP TYPE node_type;
+ALTER TYPE node_type_old RENAME TO node_type;
\ No newline at end of file
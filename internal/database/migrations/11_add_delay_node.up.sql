@@ -0,0 +1,12 @@
+-- Recreate the node_type enum with the delay node type
+CREATE TYPE node_type_new AS ENUM(
+    'section',
+    'end',
+    'start',
+    'condition',
+    'approval',
+    'delay'
+);
+
+DROP TYPE node_type;
+ALTER TYPE node_type_new RENAME TO node_type;
\ No newline at end of file
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS form_action_runs;
+DROP TYPE IF EXISTS action_run_status;
+
+-- Recreate the node_type enum without the action node type
+CREATE TYPE node_type_old AS ENUM(
+    'section',
+    'end',
+    'start',
+    'condition',
+    'approval',
+    'delay'
+);
+
+DROP TYPE node_type;
+ALTER TYPE node_type_old RENAME TO node_type;
\ No newline at end of file
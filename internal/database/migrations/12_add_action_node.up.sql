@@ -0,0 +1,31 @@
+-- Recreate the node_type enum with the action node type
+CREATE TYPE node_type_new AS ENUM(
+    'section',
+    'end',
+    'start',
+    'condition',
+    'approval',
+    'delay',
+    'action'
+);
+
+DROP TYPE node_type;
+ALTER TYPE node_type_new RENAME TO node_type;
+
+CREATE TYPE action_run_status AS ENUM(
+    'pending',
+    'succeeded',
+    'failed'
+);
+
+CREATE TABLE IF NOT EXISTS form_action_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    node_id TEXT NOT NULL,
+    status action_run_status NOT NULL DEFAULT 'pending',
+    error TEXT DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, user_id, node_id)
+);
\ No newline at end of file
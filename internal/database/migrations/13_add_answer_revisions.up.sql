@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS answer_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    answer_id UUID NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+    response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    previous_value TEXT NOT NULL,
+    value TEXT NOT NULL,
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_answer_revisions_response_id ON answer_revisions(response_id, created_at);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS answer_comments;
\ No newline at end of file
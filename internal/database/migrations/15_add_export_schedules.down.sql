@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS form_export_schedules;
+DROP TYPE IF EXISTS export_destination;
+DROP TYPE IF EXISTS export_frequency;
\ No newline at end of file
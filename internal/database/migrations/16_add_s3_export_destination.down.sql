@@ -0,0 +1,12 @@
+DELETE FROM form_export_schedules WHERE destination = 's3';
+
+-- Recreate the export_destination enum without the s3 destination
+CREATE TYPE export_destination_old AS ENUM(
+    'webhook'
+);
+
+ALTER TABLE form_export_schedules
+    ALTER COLUMN destination TYPE export_destination_old USING destination::TEXT::export_destination_old;
+
+DROP TYPE export_destination;
+ALTER TYPE export_destination_old RENAME TO export_destination;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TYPE export_destination ADD VALUE IF NOT EXISTS 's3';
\ No newline at end of file
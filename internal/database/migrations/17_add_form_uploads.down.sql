@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS form_uploads;
+DROP TYPE IF EXISTS upload_status;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE form_uploads DROP COLUMN IF EXISTS has_variants;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE form_uploads ADD COLUMN IF NOT EXISTS has_variants BOOLEAN NOT NULL DEFAULT false;
\ No newline at end of file
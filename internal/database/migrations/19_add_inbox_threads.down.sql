@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_inbox_message_thread_id;
+
+DELETE FROM inbox_message WHERE reply_to IS NOT NULL;
+
+ALTER TABLE inbox_message
+    DROP COLUMN IF EXISTS body,
+    DROP COLUMN IF EXISTS sender_id,
+    DROP COLUMN IF EXISTS thread_id,
+    DROP COLUMN IF EXISTS reply_to;
\ No newline at end of file
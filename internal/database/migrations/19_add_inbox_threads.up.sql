@@ -0,0 +1,7 @@
+ALTER TABLE inbox_message
+    ADD COLUMN IF NOT EXISTS reply_to UUID DEFAULT NULL references inbox_message(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS thread_id UUID DEFAULT NULL references inbox_message(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS sender_id UUID DEFAULT NULL references users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS body TEXT DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_inbox_message_thread_id ON inbox_message(thread_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS unit_inbox_messages;
\ No newline at end of file
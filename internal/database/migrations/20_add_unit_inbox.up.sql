@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS unit_inbox_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL references units(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL references inbox_message(id) ON DELETE CASCADE,
+    assignee_id UUID DEFAULT NULL references users(id) ON DELETE SET NULL,
+    is_read boolean NOT NULL DEFAULT false,
+    is_archived boolean NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (unit_id, message_id)
+);
\ No newline at end of file
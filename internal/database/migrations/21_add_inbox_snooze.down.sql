@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_inbox_messages_snoozed_until;
+
+ALTER TABLE user_inbox_messages
+    DROP COLUMN IF EXISTS snoozed_until;
\ No newline at end of file
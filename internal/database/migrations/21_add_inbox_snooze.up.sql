@@ -0,0 +1,4 @@
+ALTER TABLE user_inbox_messages
+    ADD COLUMN IF NOT EXISTS snoozed_until TIMESTAMPTZ DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_snoozed_until ON user_inbox_messages(snoozed_until) WHERE snoozed_until IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS push_jobs;
+DROP TABLE IF EXISTS push_preferences;
+DROP TABLE IF EXISTS push_devices;
+DROP TYPE IF EXISTS push_job_status;
+DROP TYPE IF EXISTS push_platform;
\ No newline at end of file
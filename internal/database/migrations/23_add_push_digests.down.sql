@@ -0,0 +1,5 @@
+ALTER TABLE push_preferences
+    DROP COLUMN IF EXISTS last_digest_at,
+    DROP COLUMN IF EXISTS digest;
+
+DROP TYPE IF EXISTS push_digest;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TYPE push_digest AS ENUM(
+    'immediate',
+    'hourly',
+    'daily'
+);
+
+ALTER TABLE push_preferences
+    ADD COLUMN IF NOT EXISTS digest push_digest NOT NULL DEFAULT 'immediate',
+    ADD COLUMN IF NOT EXISTS last_digest_at TIMESTAMPTZ NOT NULL DEFAULT now();
\ No newline at end of file
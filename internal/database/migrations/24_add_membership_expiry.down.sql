@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_unit_members_valid_until;
+
+ALTER TABLE unit_members
+    DROP COLUMN IF EXISTS valid_until;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE unit_members
+    ADD COLUMN IF NOT EXISTS valid_until TIMESTAMPTZ DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_unit_members_valid_until ON unit_members(valid_until) WHERE valid_until IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,7 @@
+DELETE FROM form_eligibility_rules WHERE group_id IS NOT NULL;
+
+ALTER TABLE form_eligibility_rules
+    DROP COLUMN IF EXISTS group_id;
+
+DROP TABLE IF EXISTS recipient_group_members;
+DROP TABLE IF EXISTS recipient_groups;
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS recipient_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS recipient_group_members (
+    group_id UUID NOT NULL REFERENCES recipient_groups(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX idx_recipient_group_members_user_id ON recipient_group_members(user_id);
+
+ALTER TYPE eligibility_rule_type ADD VALUE IF NOT EXISTS 'group_member';
+
+ALTER TABLE form_eligibility_rules
+    ADD COLUMN IF NOT EXISTS group_id UUID REFERENCES recipient_groups(id) ON DELETE CASCADE;
\ No newline at end of file
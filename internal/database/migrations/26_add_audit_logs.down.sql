@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS audit_logs;
+DROP TYPE IF EXISTS audit_action;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS student_ids;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS oidc_authorization_codes (
+    code_hash VARCHAR(64) PRIMARY KEY,
+    client_id TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL DEFAULT '',
+    nonce TEXT NOT NULL DEFAULT '',
+    code_challenge TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_oidc_authorization_codes_expires_at ON oidc_authorization_codes(expires_at);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS form_respondent_guests;
+DROP TABLE IF EXISTS form_public_access;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS form_public_access (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    enabled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Anonymous respondents minted by the public respondent token endpoint, with the client
+-- that asked for them so the endpoint can be rate limited per client.
+
+CREATE TABLE IF NOT EXISTS form_respondent_guests (
+    respondent_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_respondent_guests_ip_address ON form_respondent_guests(ip_address, created_at);
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS form_views;
+DROP TABLE IF EXISTS form_stars;
\ No newline at end of file
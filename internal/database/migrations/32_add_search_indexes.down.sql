@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_search;
+DROP INDEX IF EXISTS idx_inbox_message_search;
+DROP INDEX IF EXISTS idx_units_search;
+DROP INDEX IF EXISTS idx_forms_search;
\ No newline at end of file
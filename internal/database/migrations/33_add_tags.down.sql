@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS inbox_message_tags;
+DROP TABLE IF EXISTS form_tags;
+DROP TABLE IF EXISTS tags;
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS form_tags (
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (form_id, tag_id)
+);
+
+CREATE INDEX idx_form_tags_tag_id ON form_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS inbox_message_tags (
+    message_id UUID NOT NULL REFERENCES inbox_message(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (message_id, tag_id)
+);
+
+CREATE INDEX idx_inbox_message_tags_tag_id ON inbox_message_tags(tag_id);
\ No newline at end of file
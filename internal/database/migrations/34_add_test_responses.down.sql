@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_form_responses_test;
+ALTER TABLE form_responses DROP COLUMN IF EXISTS is_test;
\ No newline at end of file
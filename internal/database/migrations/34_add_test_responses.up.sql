@@ -0,0 +1,5 @@
+-- Responses submitted with a preview token are test data: they are left out of the
+-- response list, per question answers, exports and response counts.
+ALTER TABLE form_responses ADD COLUMN IF NOT EXISTS is_test BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_form_responses_test ON form_responses(form_id) WHERE is_test;
\ No newline at end of file
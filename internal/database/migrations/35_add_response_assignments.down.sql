@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS response_assignments;
+DROP TABLE IF EXISTS form_assignment_settings;
+DROP TYPE IF EXISTS assignment_strategy;
\ No newline at end of file
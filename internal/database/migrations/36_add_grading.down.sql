@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS answer_scores;
+DROP TABLE IF EXISTS question_points;
\ No newline at end of file
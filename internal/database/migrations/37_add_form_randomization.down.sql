@@ -0,0 +1,2 @@
+ALTER TABLE forms DROP COLUMN IF EXISTS shuffle_choices;
+ALTER TABLE forms DROP COLUMN IF EXISTS randomize_questions;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Per form options for the order in which respondents see questions and choices.
+-- The shuffle is seeded per respondent, so it stays the same across reloads.
+ALTER TABLE forms ADD COLUMN IF NOT EXISTS randomize_questions BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE forms ADD COLUMN IF NOT EXISTS shuffle_choices BOOLEAN NOT NULL DEFAULT false;
\ No newline at end of file
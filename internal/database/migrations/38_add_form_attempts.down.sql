@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS form_attempts;
+ALTER TABLE forms DROP COLUMN IF EXISTS time_limit_seconds;
\ No newline at end of file
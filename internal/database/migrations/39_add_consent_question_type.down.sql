@@ -0,0 +1,29 @@
+-- Rollback: consent questions and their answers cannot be kept without the type
+
+DELETE FROM answers WHERE type = 'consent';
+DELETE FROM questions WHERE type = 'consent';
+
+CREATE TYPE question_type_old AS ENUM(
+    'short_text',
+    'long_text',
+    'single_choice',
+    'multiple_choice',
+    'date',
+    'dropdown',
+    'detailed_multiple_choice',
+    'upload_file',
+    'linear_scale',
+    'rating',
+    'ranking',
+    'oauth_connect',
+    'hyperlink'
+);
+
+ALTER TABLE questions
+    ALTER COLUMN type TYPE question_type_old USING type::text::question_type_old;
+
+ALTER TABLE answers
+    ALTER COLUMN type TYPE question_type_old USING type::text::question_type_old;
+
+DROP TYPE question_type;
+ALTER TYPE question_type_old RENAME TO question_type;
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- Adds the consent question type. Like migration 5, the enum is recreated rather than
+-- extended with ALTER TYPE ... ADD VALUE so the migration runs within a transaction.
+
+CREATE TYPE question_type_new AS ENUM(
+    'short_text',
+    'long_text',
+    'single_choice',
+    'multiple_choice',
+    'date',
+    'dropdown',
+    'detailed_multiple_choice',
+    'upload_file',
+    'linear_scale',
+    'rating',
+    'ranking',
+    'oauth_connect',
+    'hyperlink',
+    'consent'
+);
+
+ALTER TABLE questions
+    ALTER COLUMN type TYPE question_type_new USING type::text::question_type_new;
+
+ALTER TABLE answers
+    ALTER COLUMN type TYPE question_type_new USING type::text::question_type_new;
+
+DROP TYPE question_type;
+ALTER TYPE question_type_new RENAME TO question_type;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE form_export_schedules DROP COLUMN IF EXISTS masked;
+DROP TABLE IF EXISTS pii_questions;
+DROP TYPE IF EXISTS pii_masking;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- Questions whose answers identify the respondent. Exports and statistics mask them,
+-- by redacting or hashing the answer, for callers who are not an admin of the form's
+-- organization.
+CREATE TYPE pii_masking AS ENUM(
+    'redact',
+    'hash'
+);
+
+CREATE TABLE IF NOT EXISTS pii_questions (
+    question_id UUID PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    masking pii_masking NOT NULL DEFAULT 'redact',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pii_questions_form_id ON pii_questions(form_id);
+
+-- Scheduled exports run without a caller; masked forces masking even when the creator
+-- of the schedule may see the raw answers.
+ALTER TABLE form_export_schedules ADD COLUMN IF NOT EXISTS masked BOOLEAN NOT NULL DEFAULT false;
\ No newline at end of file
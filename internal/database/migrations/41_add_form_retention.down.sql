@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS retention_purges;
+DROP TYPE IF EXISTS retention_action;
+ALTER TABLE form_responses DROP COLUMN IF EXISTS anonymized_at;
+DROP TABLE IF EXISTS form_retention_policies;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- How long the responses of a form are kept after its deadline. Anonymizing moves a
+-- response to a fresh guest user, so its answers stay but no longer identify anyone.
+CREATE TABLE IF NOT EXISTS form_retention_policies (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    delete_after_days INT DEFAULT NULL CHECK (delete_after_days > 0),
+    anonymize_after_days INT DEFAULT NULL CHECK (anonymize_after_days > 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE form_responses ADD COLUMN IF NOT EXISTS anonymized_at TIMESTAMPTZ DEFAULT NULL;
+
+CREATE TYPE retention_action AS ENUM(
+    'delete',
+    'anonymize'
+);
+
+-- Every purge the retention job ran. The form is not referenced, so the trail outlives it.
+CREATE TABLE IF NOT EXISTS retention_purges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL,
+    action retention_action NOT NULL,
+    after_days INT NOT NULL,
+    response_count INT NOT NULL,
+    purged_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_purges_form_id ON retention_purges(form_id, purged_at);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS legal_holds;
\ No newline at end of file
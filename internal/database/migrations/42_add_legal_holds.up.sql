@@ -0,0 +1,15 @@
+-- Legal holds suspend retention and deletion of a whole form, or of one response when
+-- response_id is set, until released. Released holds are kept as the record of who held
+-- what, so neither the form nor the response is referenced.
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL,
+    response_id UUID DEFAULT NULL,
+    reason TEXT NOT NULL,
+    placed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    placed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    released_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    released_at TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_holds_active ON legal_holds(form_id, response_id) WHERE released_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_inbox_messages_message_id;
+DROP INDEX IF EXISTS idx_user_inbox_messages_user_id;
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- The user inbox list filters on the owner and the archived flag on every request and
+-- joins the message by id; threads look up the copy of a message delivered to a user.
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_user_id ON user_inbox_messages(user_id, is_archived, message_id);
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_message_id ON user_inbox_messages(message_id, user_id);
\ No newline at end of file
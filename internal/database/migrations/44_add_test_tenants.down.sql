@@ -0,0 +1 @@
+DROP TABLE IF EXISTS test_tenants;
\ No newline at end of file
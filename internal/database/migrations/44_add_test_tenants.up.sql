@@ -0,0 +1,11 @@
+-- Test tenants are organizations provisioned for preview deployments and QA runs on a
+-- shared environment. Each is deleted with everything in it once it expires.
+CREATE TABLE IF NOT EXISTS test_tenants (
+    org_id UUID PRIMARY KEY REFERENCES units(id) ON DELETE CASCADE,
+    label TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_test_tenants_expires_at ON test_tenants(expires_at);
\ No newline at end of file
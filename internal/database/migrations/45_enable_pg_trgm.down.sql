@@ -0,0 +1 @@
+DROP EXTENSION IF EXISTS pg_trgm;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Trigram similarity flags near-identical answers in the duplicate report of a form
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
\ No newline at end of file
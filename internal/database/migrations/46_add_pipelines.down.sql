@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS pipeline_stages;
+DROP TABLE IF EXISTS pipelines;
+DROP TYPE IF EXISTS pipeline_gate;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS form_checkins;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS attendance_marks;
+DROP TABLE IF EXISTS attendance_events;
+DROP TYPE IF EXISTS attendance_status;
\ No newline at end of file
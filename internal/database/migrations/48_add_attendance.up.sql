@@ -0,0 +1,31 @@
+-- Attendance turns the registration form of an event into a roll: the event has a start
+-- time, respondents checking in after the grace period are late and the ones who do
+-- not show up absent.
+CREATE TYPE attendance_status AS ENUM(
+    'present',
+    'late',
+    'absent',
+    'excused'
+);
+
+CREATE TABLE IF NOT EXISTS attendance_events (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    late_after_minutes INT NOT NULL DEFAULT 10 CHECK (late_after_minutes >= 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_events_starts_at ON attendance_events(starts_at);
+
+-- Marks set the status of a response by hand, over the one its check-in gives
+CREATE TABLE IF NOT EXISTS attendance_marks (
+    response_id UUID PRIMARY KEY REFERENCES form_responses(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    status attendance_status NOT NULL,
+    marked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    marked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_marks_form_id ON attendance_marks(form_id);
\ No newline at end of file
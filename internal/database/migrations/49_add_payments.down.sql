@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS payment_notifications;
+DROP TABLE IF EXISTS response_payments;
+DROP TABLE IF EXISTS form_payment_settings;
+DROP TYPE IF EXISTS payment_status;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS form_result_shares;
\ No newline at end of file
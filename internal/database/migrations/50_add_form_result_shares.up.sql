@@ -0,0 +1,10 @@
+-- Public results pages show the aggregate answers of a form to anyone holding its link
+-- A form whose results are shared by link. The nonce is signed into the link, so sharing
+-- again with a new one revokes the links given out before.
+CREATE TABLE IF NOT EXISTS form_result_shares (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    nonce TEXT NOT NULL,
+    expires_at TIMESTAMPTZ DEFAULT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
\ No newline at end of file
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ballot_answers;
+DROP TABLE IF EXISTS ballots;
+DROP TABLE IF EXISTS ballot_voters;
+DROP TABLE IF EXISTS form_ballots;
\ No newline at end of file
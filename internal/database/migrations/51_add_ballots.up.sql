@@ -0,0 +1,34 @@
+-- Forms in ballot mode take anonymous ballots instead of responses, for elections. The
+-- voter roll and the ballots are kept apart so that no row relates a voter to a ballot.
+CREATE TABLE IF NOT EXISTS form_ballots (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    enabled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Who voted, and nothing of what they voted. Voters only keep the day they voted, which
+-- turnout is counted by: ballots are written in shuffled batches apart from their
+-- voters, and a timestamp down to the second would narrow the batch a ballot went into.
+CREATE TABLE IF NOT EXISTS ballot_voters (
+    form_id UUID NOT NULL REFERENCES form_ballots(form_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    voted_on DATE NOT NULL DEFAULT CURRENT_DATE,
+    PRIMARY KEY (form_id, user_id)
+);
+
+-- What was voted, and nothing of who voted it: a random ID and no timestamp. The
+-- receipt hash lets a voter find their ballot with the receipt only they were given.
+CREATE TABLE IF NOT EXISTS ballots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES form_ballots(form_id) ON DELETE CASCADE,
+    receipt_hash BYTEA NOT NULL UNIQUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ballots_form_id ON ballots(form_id);
+
+CREATE TABLE IF NOT EXISTS ballot_answers (
+    ballot_id UUID NOT NULL REFERENCES ballots(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    PRIMARY KEY (ballot_id, question_id)
+);
\ No newline at end of file
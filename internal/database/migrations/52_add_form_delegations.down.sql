@@ -0,0 +1,13 @@
+-- Rollback: the reads of delegates cannot be kept without the 'view' action
+
+DELETE FROM audit_logs WHERE action = 'view';
+
+CREATE TYPE audit_action_old AS ENUM ('login', 'create', 'update', 'delete', 'submit');
+
+ALTER TABLE audit_logs
+    ALTER COLUMN action TYPE audit_action_old USING action::text::audit_action_old;
+
+DROP TYPE audit_action;
+ALTER TYPE audit_action_old RENAME TO audit_action;
+
+DROP TABLE IF EXISTS form_delegations;
\ No newline at end of file
@@ -0,0 +1,27 @@
+-- Delegations let someone outside the organization, such as the co-host of an event,
+-- manage a single form for a while. Each one signs in as a user of its own, so what
+-- they do is recorded in the audit log under that user; with the new 'view' action
+-- the responses they read are recorded too. The audit action enum is recreated rather
+-- than extended with ALTER TYPE ... ADD VALUE so the migration runs within a transaction.
+CREATE TABLE IF NOT EXISTS form_delegations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_delegations_form_id ON form_delegations(form_id);
+
+CREATE TYPE audit_action_new AS ENUM ('login', 'create', 'update', 'delete', 'submit', 'view');
+
+ALTER TABLE audit_logs
+    ALTER COLUMN action TYPE audit_action_new USING action::text::audit_action_new;
+
+DROP TYPE audit_action;
+ALTER TYPE audit_action_new RENAME TO audit_action;
\ No newline at end of file
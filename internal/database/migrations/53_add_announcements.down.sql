@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS announcement_reactions;
+DROP TABLE IF EXISTS announcements;
+DROP TYPE IF EXISTS announcement_visibility;
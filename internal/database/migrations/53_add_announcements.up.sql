@@ -0,0 +1,31 @@
+-- The announcement board keeps the notices of an organization and its units where
+-- members can find them after they scroll out of their inboxes. Posts of the
+-- organization itself have no unit and are visible to the whole organization; a post
+-- of a unit may be kept to the members of the unit. Pinned posts stay at the top of
+-- the board, in the order they were pinned.
+CREATE TYPE announcement_visibility AS ENUM ('organization', 'unit');
+
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    unit_id UUID DEFAULT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    visibility announcement_visibility NOT NULL DEFAULT 'organization',
+    pinned_at TIMESTAMPTZ DEFAULT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (unit_id IS NOT NULL OR visibility = 'organization')
+);
+
+CREATE INDEX IF NOT EXISTS idx_announcements_board ON announcements(org_id, unit_id, pinned_at DESC NULLS LAST, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS announcement_reactions (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (announcement_id, user_id, emoji)
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS announcement_reads;
\ No newline at end of file
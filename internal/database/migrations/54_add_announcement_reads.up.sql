@@ -0,0 +1,9 @@
+-- The first time each member read an announcement is kept, so the board can show how
+-- many of its readers have seen a post. Reactions are kept on announcements already.
+
+CREATE TABLE IF NOT EXISTS announcement_reads (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    read_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (announcement_id, user_id)
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS wiki_page_revisions;
+DROP TABLE IF EXISTS wiki_pages;
+DROP TYPE IF EXISTS wiki_visibility;
\ No newline at end of file
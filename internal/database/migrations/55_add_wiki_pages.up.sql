@@ -0,0 +1,32 @@
+-- Wiki pages are markdown documents kept by a unit, replacing the documents units
+-- used to share elsewhere. A page is read by the members of its unit, or by every
+-- member of the organization when its visibility allows, and edited by the members of
+-- its unit. Every edit is kept as a revision, the first one included.
+CREATE TYPE wiki_visibility AS ENUM ('unit', 'organization');
+
+CREATE TABLE IF NOT EXISTS wiki_pages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    slug VARCHAR(100) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    visibility wiki_visibility NOT NULL DEFAULT 'unit',
+    revision INT NOT NULL DEFAULT 1,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (unit_id, slug)
+);
+
+CREATE TABLE IF NOT EXISTS wiki_page_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    page_id UUID NOT NULL REFERENCES wiki_pages(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    summary VARCHAR(255) NOT NULL DEFAULT '',
+    edited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (page_id, revision)
+);
\ No newline at end of file
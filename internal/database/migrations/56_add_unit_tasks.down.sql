@@ -0,0 +1,15 @@
+-- Rollback: the inbox messages about tasks cannot be kept without the 'task' content type
+
+DELETE FROM inbox_message WHERE type = 'task';
+
+CREATE TYPE content_type_old AS ENUM ('text', 'form');
+
+ALTER TABLE inbox_message
+    ALTER COLUMN type TYPE content_type_old USING type::text::content_type_old;
+
+DROP TYPE content_type;
+ALTER TYPE content_type_old RENAME TO content_type;
+
+DROP TABLE IF EXISTS task_assignees;
+DROP TABLE IF EXISTS tasks;
+DROP TYPE IF EXISTS task_status;
\ No newline at end of file
@@ -0,0 +1,40 @@
+-- Tasks are the to-dos of a unit, such as the chores of planning an event, optionally
+-- linked to the form or response they are about. People assigned to a task hear of it
+-- in their inbox through messages of the new 'task' content type. The content type enum
+-- is recreated rather than extended with ALTER TYPE ... ADD VALUE so the migration runs
+-- within a transaction.
+CREATE TYPE task_status AS ENUM ('todo', 'in_progress', 'done', 'cancelled');
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status task_status NOT NULL DEFAULT 'todo',
+    due_at TIMESTAMPTZ,
+    form_id UUID REFERENCES forms(id) ON DELETE SET NULL,
+    response_id UUID REFERENCES form_responses(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_unit_id ON tasks(unit_id, status);
+
+CREATE TABLE IF NOT EXISTS task_assignees (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_assignees_user_id ON task_assignees(user_id);
+
+CREATE TYPE content_type_new AS ENUM ('text', 'form', 'task');
+
+ALTER TABLE inbox_message
+    ALTER COLUMN type TYPE content_type_new USING type::text::content_type_new;
+
+DROP TYPE content_type;
+ALTER TYPE content_type_new RENAME TO content_type;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS org_events;
\ No newline at end of file
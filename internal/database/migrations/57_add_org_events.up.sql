@@ -0,0 +1,21 @@
+-- Events are what an organization holds, listed publicly once published. The form
+-- an event is linked to is its registration: each submitted response takes one of
+-- its places, and the attendance of the form is tracked from the start of the event.
+CREATE TABLE IF NOT EXISTS org_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    venue VARCHAR(255) NOT NULL DEFAULT '',
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    capacity INT CHECK (capacity > 0),
+    form_id UUID UNIQUE REFERENCES forms(id) ON DELETE SET NULL,
+    published BOOLEAN NOT NULL DEFAULT false,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_events_org_id ON org_events(org_id, starts_at);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS resource_bookings;
+DROP TABLE IF EXISTS resources;
+DROP TYPE IF EXISTS resource_kind;
\ No newline at end of file
@@ -0,0 +1,36 @@
+-- Rooms and equipment owned by units, booked by the time slot. A resource with a
+-- booking form routes each booking through the approval gates of that form's workflow:
+-- the booking points at the response submitted to the form and follows its approvals.
+CREATE TYPE resource_kind AS ENUM(
+    'room',
+    'equipment'
+);
+
+CREATE TABLE IF NOT EXISTS resources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    kind resource_kind NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    location VARCHAR(255) NOT NULL DEFAULT '',
+    booking_form_id UUID REFERENCES forms(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_resources_unit_id ON resources(unit_id);
+
+CREATE TABLE IF NOT EXISTS resource_bookings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resource_id UUID NOT NULL REFERENCES resources(id) ON DELETE CASCADE,
+    booked_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    purpose TEXT NOT NULL DEFAULT '',
+    response_id UUID UNIQUE REFERENCES form_responses(id) ON DELETE CASCADE,
+    cancelled_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_resource_bookings_time ON resource_bookings(resource_id, starts_at) WHERE cancelled_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS budget_request_attachments;
+DROP TABLE IF EXISTS budget_request_comments;
+DROP TABLE IF EXISTS budget_request_steps;
+DROP TABLE IF EXISTS budget_requests;
+DROP TABLE IF EXISTS budget_approval_steps;
+DROP TYPE IF EXISTS budget_request_status;
\ No newline at end of file
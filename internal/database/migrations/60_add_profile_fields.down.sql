@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS profile_prefills;
+DROP TABLE IF EXISTS profile_values;
+DROP TABLE IF EXISTS profile_fields;
+DROP TYPE IF EXISTS profile_field_type;
\ No newline at end of file
@@ -0,0 +1,38 @@
+-- Additional member profile fields defined per organization, the values members give
+-- for them and the form questions they prefill.
+CREATE TYPE profile_field_type AS ENUM(
+    'text',
+    'number',
+    'select'
+);
+
+CREATE TABLE IF NOT EXISTS profile_fields (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    type profile_field_type NOT NULL DEFAULT 'text',
+    options TEXT[] NOT NULL DEFAULT '{}',
+    required BOOLEAN NOT NULL DEFAULT false,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, key)
+);
+
+CREATE TABLE IF NOT EXISTS profile_values (
+    field_id UUID NOT NULL REFERENCES profile_fields(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (field_id, user_id)
+);
+
+CREATE INDEX idx_profile_values_user_id ON profile_values(user_id);
+
+CREATE TABLE IF NOT EXISTS profile_prefills (
+    question_id UUID PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+    field_id UUID NOT NULL REFERENCES profile_fields(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_profile_prefills_field_id ON profile_prefills(field_id);
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- Rollback: the inbox messages about quotas cannot be kept without the 'quota' content type
+
+DELETE FROM inbox_message WHERE type = 'quota';
+
+CREATE TYPE content_type_old AS ENUM ('text', 'form', 'task');
+
+ALTER TABLE inbox_message
+    ALTER COLUMN type TYPE content_type_old USING type::text::content_type_old;
+
+DROP TYPE content_type;
+ALTER TYPE content_type_old RENAME TO content_type;
+
+DROP TABLE IF EXISTS quota_warnings;
+DROP TABLE IF EXISTS org_quotas;
+DROP TYPE IF EXISTS quota_resource;
\ No newline at end of file
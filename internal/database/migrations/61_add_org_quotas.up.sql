@@ -0,0 +1,35 @@
+-- Quotas cap how many forms and members an organization holds. Limits come from the
+-- configured defaults unless org_quotas overrides them. Org admins are warned once per
+-- threshold as usage passes 80, 90 and 100 percent; the warnings are resolved when usage
+-- drops back so the next climb warns again. They reach the inbox as messages of the new
+-- 'quota' content type.
+CREATE TYPE quota_resource AS ENUM ('forms', 'members');
+
+CREATE TABLE IF NOT EXISTS org_quotas (
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    resource quota_resource NOT NULL,
+    quota_limit INTEGER NOT NULL CHECK (quota_limit >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (org_id, resource)
+);
+
+CREATE TABLE IF NOT EXISTS quota_warnings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    resource quota_resource NOT NULL,
+    threshold INTEGER NOT NULL,
+    usage INTEGER NOT NULL,
+    quota_limit INTEGER NOT NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quota_warnings_active ON quota_warnings(org_id, resource, threshold) WHERE resolved_at IS NULL;
+
+CREATE TYPE content_type_new AS ENUM ('text', 'form', 'task', 'quota');
+
+ALTER TABLE inbox_message
+    ALTER COLUMN type TYPE content_type_new USING type::text::content_type_new;
+
+DROP TYPE content_type;
+ALTER TYPE content_type_new RENAME TO content_type;
\ No newline at end of file
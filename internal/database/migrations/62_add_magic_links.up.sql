@@ -0,0 +1,18 @@
+-- Magic links sign people in with a single-use link mailed to their address. The hash of
+-- a nonce kept in a cookie of the requesting browser tells whether the link is opened on
+-- the same device; ip_address and user_agent are shown when confirming it elsewhere.
+
+CREATE TABLE IF NOT EXISTS magic_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email VARCHAR(255) NOT NULL,
+    redirect_url TEXT NOT NULL DEFAULT '',
+    device_hash BYTEA NOT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_magic_links_email ON magic_links(email, created_at);
+CREATE INDEX IF NOT EXISTS idx_magic_links_ip_address ON magic_links(ip_address, created_at);
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS security_events;
+DROP TYPE IF EXISTS security_event_type;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS platform_hash,
+    DROP COLUMN IF EXISTS user_agent;
\ No newline at end of file
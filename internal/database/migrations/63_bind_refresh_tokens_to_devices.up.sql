@@ -0,0 +1,22 @@
+-- Refresh tokens record the user agent and a hash of the platform and browser family of
+-- the client they are issued to. A token presented by another kind of client is revoked
+-- and reported to its owner in security_events. Tokens issued before have no hash and are
+-- bound to their client when next refreshed.
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS platform_hash BYTEA;
+
+CREATE TYPE security_event_type AS ENUM ('refresh_token_mismatch');
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type security_event_type NOT NULL,
+    ip_address TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_security_events_user_id_created_at ON security_events(user_id, created_at DESC);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS org_webhook_keys;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- Per-organization keys signing webhook deliveries. Secrets are sealed with the secrets
+-- keyring. A rotation sets expires_at on the keys it replaces, which keep signing next to
+-- the new key until then so receivers can switch secrets without missing a delivery.
+
+CREATE TABLE IF NOT EXISTS org_webhook_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_org_webhook_keys_org_id ON org_webhook_keys(org_id, created_at DESC);
\ No newline at end of file
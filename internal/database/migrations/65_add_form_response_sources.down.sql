@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS org_ingest_sources;
+DROP TABLE IF EXISTS form_response_sources;
\ No newline at end of file
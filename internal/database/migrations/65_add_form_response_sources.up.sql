@@ -0,0 +1,28 @@
+-- Submissions ingested from external form tools, keyed by the tool and the ID it gave
+-- the submission so a retried delivery does not create a second response. A row without
+-- a response is a delivery still being saved.
+
+CREATE TABLE IF NOT EXISTS form_response_sources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    response_id UUID UNIQUE REFERENCES form_responses(id) ON DELETE CASCADE,
+    source VARCHAR(64) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    submitted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, source, external_id)
+);
+
+-- Per-organization credentials of the external form tools posting submissions, one per
+-- source, so a source signs with its own secret rather than the keys signing outbound
+-- webhook deliveries. Secrets are sealed with the secrets keyring.
+
+CREATE TABLE IF NOT EXISTS org_ingest_sources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    source VARCHAR(64) NOT NULL,
+    secret TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, source)
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS form_eligibility_rules;
+
+DROP TYPE IF EXISTS eligibility_rule_type;
\ No newline at end of file
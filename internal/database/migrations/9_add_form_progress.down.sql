@@ -0,0 +1 @@
+DROP TABLE IF EXISTS form_progress;
\ No newline at end of file
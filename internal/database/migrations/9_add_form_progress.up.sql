@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS form_progress (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    current_section_id UUID REFERENCES sections(id) ON DELETE SET NULL,
+    visited_nodes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, user_id)
+);
\ No newline at end of file
@@ -0,0 +1,111 @@
+package dev
+
+import (
+	"NYCU-SDC/core-system-backend/internal/mail"
+	"NYCU-SDC/core-system-backend/internal/push"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultCatcherCapacity is the number of messages kept, the oldest dropped first
+const DefaultCatcherCapacity = 200
+
+// Message is an outgoing message, caught instead of sent
+type Message struct {
+	ID        uuid.UUID `json:"id"`
+	Channel   string    `json:"channel"`
+	To        uuid.UUID `json:"to"`
+	Address   string    `json:"address,omitempty"`
+	Device    uuid.UUID `json:"device"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url"`
+	MessageID string    `json:"messageId"`
+	SentAt    time.Time `json:"sentAt"`
+}
+
+// Catcher keeps the latest outgoing messages in memory for the dev mail endpoint. It
+// stands in for the push senders of the platforms left unconfigured, and for the mail
+// sender when no SMTP server is configured.
+type Catcher struct {
+	logger   *zap.Logger
+	capacity int
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewCatcher(logger *zap.Logger, capacity int) *Catcher {
+	return &Catcher{
+		logger:   logger,
+		capacity: capacity,
+	}
+}
+
+// Send implements push.Sender
+func (c *Catcher) Send(_ context.Context, device push.PushDevice, notification push.Notification) error {
+	message := Message{
+		ID:        uuid.New(),
+		Channel:   string(device.Platform),
+		To:        device.UserID,
+		Device:    device.ID,
+		Subject:   notification.Title,
+		Body:      notification.Body,
+		URL:       notification.URL,
+		MessageID: notification.MessageID,
+		SentAt:    time.Now(),
+	}
+
+	c.catch(message)
+	c.logger.Debug("Caught outgoing message", zap.String("channel", message.Channel), zap.String("to", message.To.String()), zap.String("subject", message.Subject))
+	return nil
+}
+
+// SendMail implements mail.Sender
+func (c *Catcher) SendMail(_ context.Context, outgoing mail.Message) error {
+	message := Message{
+		ID:      uuid.New(),
+		Channel: "email",
+		Address: outgoing.To,
+		Subject: outgoing.Subject,
+		Body:    outgoing.Body,
+		SentAt:  time.Now(),
+	}
+
+	c.catch(message)
+	c.logger.Debug("Caught outgoing mail", zap.String("address", message.Address), zap.String("subject", message.Subject))
+	return nil
+}
+
+func (c *Catcher) catch(message Message) {
+	c.mu.Lock()
+	c.messages = append(c.messages, message)
+	if len(c.messages) > c.capacity {
+		c.messages = slices.Delete(c.messages, 0, len(c.messages)-c.capacity)
+	}
+	c.mu.Unlock()
+}
+
+// List returns the caught messages, newest first
+func (c *Catcher) List() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	messages := make([]Message, len(c.messages))
+	for i, message := range c.messages {
+		messages[len(messages)-1-i] = message
+	}
+	return messages
+}
+
+// Clear drops every caught message
+func (c *Catcher) Clear() {
+	c.mu.Lock()
+	c.messages = nil
+	c.mu.Unlock()
+}
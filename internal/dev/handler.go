@@ -0,0 +1,108 @@
+package dev
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"html/template"
+	"net/http"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Dev login</title></head>
+<body style="font-family: sans-serif; max-width: 32rem; margin: 4rem auto">
+<h1>Sign in as</h1>
+<ul>
+{{range .Users}}<li><a href="{{.URL}}">{{.User.Name}}</a> ({{.User.Username}}{{range .User.Role}}, {{.}}{{end}})</li>
+{{end}}</ul>
+<p>Development mode only. Skip this page with <code>?login_hint=&lt;username&gt;</code> on the login URL.</p>
+</body>
+</html>
+`))
+
+type loginChoice struct {
+	User User
+	URL  string
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	provider *Provider
+	catcher  *Catcher
+}
+
+func NewHandler(logger *zap.Logger, problemWriter *problem.HttpWriter, provider *Provider, catcher *Catcher) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("dev/handler"),
+		problemWriter: problemWriter,
+		provider:      provider,
+		catcher:       catcher,
+	}
+}
+
+// LoginHandler is the authorization page of the dev provider. It signs in as the user
+// named by login_hint right away, and lists the seeded users to pick from otherwise.
+func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "LoginHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidCallbackInfo, logger)
+		return
+	}
+
+	hint := r.URL.Query().Get("login_hint")
+	if _, ok := findUser(hint); ok {
+		http.Redirect(w, r, h.provider.callbackURL(hint, state), http.StatusFound)
+		return
+	}
+
+	choices := make([]loginChoice, len(Users))
+	for i, u := range Users {
+		choices[i] = loginChoice{User: u, URL: h.provider.callbackURL(u.Username, state)}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := loginPage.Execute(w, struct{ Users []loginChoice }{Users: choices})
+	if err != nil {
+		logger.Error("Failed to render dev login page", zap.Error(err))
+	}
+}
+
+// ListUsersHandler lists the seeded users the dev provider signs in as
+func (h *Handler) ListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	_, span := h.tracer.Start(r.Context(), "ListUsersHandler")
+	defer span.End()
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, Users)
+}
+
+// ListMailHandler lists the caught messages, newest first
+func (h *Handler) ListMailHandler(w http.ResponseWriter, r *http.Request) {
+	_, span := h.tracer.Start(r.Context(), "ListMailHandler")
+	defer span.End()
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, h.catcher.List())
+}
+
+// ClearMailHandler drops the caught messages
+func (h *Handler) ClearMailHandler(w http.ResponseWriter, r *http.Request) {
+	_, span := h.tracer.Start(r.Context(), "ClearMailHandler")
+	defer span.End()
+
+	h.catcher.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
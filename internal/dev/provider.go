@@ -0,0 +1,101 @@
+// Package dev holds what the backend runs with in dev mode in place of the external
+// services: an OAuth provider signing in as seeded users instead of Google, and a
+// catcher keeping the outgoing messages in memory instead of sending them.
+package dev
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/oauth2"
+)
+
+// ProviderName is the provider path segment of the dev login,
+// e.g. /api/v1/auth/login/oauth/dev?login_hint=alice
+const ProviderName = "dev"
+
+// User is an identity the dev provider signs in as
+type User struct {
+	Username string   `json:"username"`
+	Name     string   `json:"name"`
+	Email    string   `json:"email"`
+	Role     []string `json:"role"`
+}
+
+// Users are the seeded identities, created on their first login like Google users
+var Users = []User{
+	{Username: "admin", Name: "Dev Admin", Email: "admin@dev.local", Role: []string{"user", user.RoleAdmin}},
+	{Username: "alice", Name: "Alice Chen", Email: "alice@dev.local", Role: []string{"user"}},
+	{Username: "bob", Name: "Bob Lin", Email: "bob@dev.local", Role: []string{"user"}},
+}
+
+func findUser(username string) (User, bool) {
+	i := slices.IndexFunc(Users, func(u User) bool { return u.Username == username })
+	if i < 0 {
+		return User{}, false
+	}
+	return Users[i], true
+}
+
+// Provider is an OAuth provider whose authorization page is the dev login of this
+// backend; the code it hands back is the username of the seeded user picked
+type Provider struct {
+	config *oauth2.Config
+}
+
+func NewProvider(baseURL string) *Provider {
+	return &Provider{
+		config: &oauth2.Config{
+			ClientID:    ProviderName,
+			RedirectURL: baseURL + route.V1 + "/auth/login/oauth/" + ProviderName + "/callback",
+			Endpoint: oauth2.Endpoint{
+				AuthURL: baseURL + route.V1 + "/dev/login",
+			},
+		},
+	}
+}
+
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+func (p *Provider) Config() *oauth2.Config {
+	return p.config
+}
+
+// callbackURL is where the dev login sends the browser once a user is picked
+func (p *Provider) callbackURL(username, state string) string {
+	query := url.Values{"code": {username}, "state": {state}}
+	return p.config.RedirectURL + "?" + query.Encode()
+}
+
+func (p *Provider) Exchange(_ context.Context, code string) (*oauth2.Token, error) {
+	if _, ok := findUser(code); !ok {
+		return nil, fmt.Errorf("unknown dev user %q", code)
+	}
+	return &oauth2.Token{AccessToken: code, TokenType: "Bearer"}, nil
+}
+
+func (p *Provider) GetUserInfo(_ context.Context, token *oauth2.Token) (user.User, user.Auth, string, error) {
+	u, ok := findUser(token.AccessToken)
+	if !ok {
+		return user.User{}, user.Auth{}, "", fmt.Errorf("unknown dev user %q", token.AccessToken)
+	}
+
+	userInfo := user.User{
+		Name:     pgtype.Text{String: u.Name, Valid: true},
+		Username: pgtype.Text{String: u.Username, Valid: true},
+		Role:     u.Role,
+	}
+	authInfo := user.Auth{
+		Provider:   ProviderName,
+		ProviderID: u.Username,
+	}
+
+	return userInfo, authInfo, u.Email, nil
+}
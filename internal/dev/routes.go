@@ -0,0 +1,14 @@
+package dev
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the dev login and the mail catcher; they are public and only
+// mounted in dev mode
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /dev/login", route.Public, route.PermissionNone, h.LoginHandler)
+	r.Handle("GET /dev/users", route.Public, route.PermissionNone, h.ListUsersHandler)
+	r.Handle("GET /dev/mail", route.Public, route.PermissionNone, h.ListMailHandler)
+	r.Handle("DELETE /dev/mail", route.Public, route.PermissionNone, h.ClearMailHandler)
+}
@@ -18,17 +18,23 @@ type UnitStore interface {
 	ListUnitsMembers(ctx context.Context, unitIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error)
 }
 
+type GroupStore interface {
+	ListMemberIDs(ctx context.Context, groupIDs []uuid.UUID) ([]uuid.UUID, error)
+}
+
 type Service struct {
-	logger *zap.Logger
-	tracer trace.Tracer
-	store  UnitStore
+	logger     *zap.Logger
+	tracer     trace.Tracer
+	store      UnitStore
+	groupStore GroupStore
 }
 
-func NewService(logger *zap.Logger, store UnitStore) *Service {
+func NewService(logger *zap.Logger, store UnitStore, groupStore GroupStore) *Service {
 	return &Service{
-		logger: logger,
-		store:  store,
-		tracer: otel.Tracer("distribute/service"),
+		logger:     logger,
+		store:      store,
+		groupStore: groupStore,
+		tracer:     otel.Tracer("distribute/service"),
 	}
 }
 
@@ -91,3 +97,23 @@ func (s *Service) GetRecipients(ctx context.Context, unitIDs []uuid.UUID) ([]uui
 
 	return uniq, nil
 }
+
+// GetGroupRecipients resolves the members of recipient groups
+func (s *Service) GetGroupRecipients(ctx context.Context, groupIDs []uuid.UUID) ([]uuid.UUID, error) {
+	ctx, span := s.tracer.Start(ctx, "GetGroupRecipients")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	ids, err := s.groupStore.ListMemberIDs(ctx, groupIDs)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	logger.Debug("Group recipients resolved",
+		zap.Int("group_count", len(groupIDs)),
+		zap.Int("recipients_count", len(ids)),
+	)
+
+	return ids, nil
+}
@@ -0,0 +1,112 @@
+// Package egress guards the requests the server sends to URLs its users configure, such
+// as webhooks, so they cannot reach the network the server runs in
+package egress
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrBlockedAddress = errors.New("address is not publicly routable")
+
+const (
+	dialTimeout         = 10 * time.Second
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
+// blockedPrefixes are the ranges not covered by the checks of netip.Addr that still lead
+// inside a network: shared address space, which cloud providers also serve metadata on,
+// IETF protocol assignments, benchmarking, and NAT64 and 6to4, which embed IPv4 addresses
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// localSuffixes are names that only resolve inside a network, such as the metadata
+// server of Google Cloud at metadata.google.internal
+var localSuffixes = []string{".localhost", ".local", ".internal", ".home.arpa"}
+
+// Blocked reports whether the address is loopback, private, link-local, multicast or
+// otherwise not a public unicast address. The cloud metadata address 169.254.169.254 is
+// link-local.
+func Blocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHost refuses the host of a URL when it is a blocked address or a name that only
+// resolves locally. It does not look the name up: what a name resolves to can change
+// after it was checked, so the client of NewClient checks every address it connects to.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if Blocked(addr) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+		}
+		return nil
+	}
+
+	if host == "" || host == "localhost" {
+		return fmt.Errorf("%w: %q", ErrBlockedAddress, host)
+	}
+	for _, suffix := range localSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+		}
+	}
+	return nil
+}
+
+// control runs once the address of a connection is resolved, before connecting to it
+func control(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || Blocked(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for URLs users configure. Every connection it opens,
+// redirects included, is refused when the address it resolved to is blocked. It ignores
+// the proxy of the environment, since the dialer would only see the address of the proxy.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: tlsHandshakeTimeout,
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package egress_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal/egress"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocked(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name     string
+		addr     string
+		expected bool
+	}
+
+	testCases := []testCase{
+		{name: "Public IPv4", addr: "93.184.216.34", expected: false},
+		{name: "Public IPv6", addr: "2606:2800:220:1:248:1893:25c8:1946", expected: false},
+		{name: "Loopback", addr: "127.0.0.1", expected: true},
+		{name: "IPv6 loopback", addr: "::1", expected: true},
+		{name: "Private 10/8", addr: "10.1.2.3", expected: true},
+		{name: "Private 172.16/12", addr: "172.20.0.1", expected: true},
+		{name: "Private 192.168/16", addr: "192.168.1.1", expected: true},
+		{name: "Metadata address", addr: "169.254.169.254", expected: true},
+		{name: "IPv6 link-local", addr: "fe80::1", expected: true},
+		{name: "IPv6 unique local", addr: "fd00::1", expected: true},
+		{name: "Unspecified", addr: "0.0.0.0", expected: true},
+		{name: "Shared address space", addr: "100.100.100.200", expected: true},
+		{name: "IPv4-mapped loopback", addr: "::ffff:127.0.0.1", expected: true},
+		{name: "NAT64 private", addr: "64:ff9b::a00:1", expected: true},
+		{name: "Multicast", addr: "224.0.0.1", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expected, egress.Blocked(netip.MustParseAddr(tc.addr)))
+		})
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		host        string
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{name: "Public name", host: "example.com", expectedErr: false},
+		{name: "Public address", host: "93.184.216.34", expectedErr: false},
+		{name: "Empty host", host: "", expectedErr: true},
+		{name: "Localhost", host: "localhost", expectedErr: true},
+		{name: "Localhost with trailing dot", host: "LOCALHOST.", expectedErr: true},
+		{name: "Localhost subdomain", host: "api.localhost", expectedErr: true},
+		{name: "Internal name", host: "metadata.google.internal", expectedErr: true},
+		{name: "Loopback address", host: "127.0.0.1", expectedErr: true},
+		{name: "Bracketed IPv6 loopback", host: "[::1]", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := egress.CheckHost(tc.host)
+			if tc.expectedErr {
+				require.ErrorIs(t, err, egress.ErrBlockedAddress)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewClient_RefusesLoopbackAtConnect(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// The URL names the loopback address directly, so only the dialer stands in the way
+	_, err := egress.NewClient(5 * time.Second).Get(server.URL)
+	require.ErrorIs(t, err, egress.ErrBlockedAddress)
+	require.False(t, called)
+}
+
+func TestNewClient_IgnoresEnvironmentProxy(t *testing.T) {
+	t.Parallel()
+
+	client := egress.NewClient(5 * time.Second)
+	transport := client.Transport.(*http.Transport)
+	require.Nil(t, transport.Proxy)
+	require.NotNil(t, transport.DialContext)
+}
@@ -3,13 +3,20 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/NYCU-SDC/summer/pkg/problem"
 )
 
 var (
+	// Request Errors
+	ErrInvalidRequestBody    = errors.New("invalid request body")
+	ErrRequestBodyTooLarge   = errors.New("request body too large")
+	ErrInvalidQueryParameter = errors.New("invalid query parameter")
+
 	// Auth Errors
 	ErrInvalidRefreshToken  = errors.New("invalid refresh token")
+	ErrRefreshTokenMismatch = errors.New("refresh token presented by another device")
 	ErrProviderNotFound     = errors.New("provider not found")
 	ErrNewStateFailed       = errors.New("failed to create new jwt state")
 	ErrOAuthError           = errors.New("failed to finish OAuth flow, OAuth error received")
@@ -20,12 +27,21 @@ var (
 	ErrInternalServerError  = errors.New("internal server error")
 	ErrForbiddenError       = errors.New("forbidden error")
 	ErrNotFound             = errors.New("not found")
+	ErrInvalidClient        = errors.New("invalid client credentials")
+	ErrMissingToken         = errors.New("missing token parameter")
+
+	// Magic Link Errors
+	ErrMagicLinkDisabled       = errors.New("magic link login is disabled")
+	ErrMagicLinkRequestInvalid = errors.New("invalid magic link request")
+	ErrMagicLinkInvalid        = errors.New("magic link is invalid or expired")
+	ErrMagicLinkRateLimited    = errors.New("too many magic link requests")
 
 	// JWT Authentication Errors
 	ErrMissingAuthHeader       = errors.New("missing access token")
 	ErrInvalidAuthHeaderFormat = errors.New("invalid access token")
 	ErrInvalidJWTToken         = errors.New("invalid JWT token")
 	ErrInvalidAuthUser         = errors.New("invalid authenticated user")
+	ErrRestrictedToken         = errors.New("token is restricted to another resource")
 
 	// User Errors
 	ErrUserNotFound       = errors.New("user not found")
@@ -40,23 +56,37 @@ var (
 	ErrFailedToCreateEmail  = errors.New("failed to create email record for OAuth user")
 
 	// Unit Errors
-	ErrOrgSlugNotFound      = errors.New("org slug not found")
-	ErrOrgSlugAlreadyExists = errors.New("org slug already exists")
-	ErrOrgSlugInvalid       = errors.New("org slug is invalid")
-	ErrUnitNotFound         = errors.New("unit not found")
-	ErrSlugNotBelongToUnit  = errors.New("slug not belong to unit")
+	ErrOrgSlugNotFound           = errors.New("org slug not found")
+	ErrOrgSlugAlreadyExists      = errors.New("org slug already exists")
+	ErrOrgSlugInvalid            = errors.New("org slug is invalid")
+	ErrUnitNotFound              = errors.New("unit not found")
+	ErrSlugNotBelongToUnit       = errors.New("slug not belong to unit")
+	ErrInvalidRecursiveParameter = errors.New("invalid recursive parameter")
+	ErrMemberNotFound            = errors.New("member not found")
+	ErrValidUntilInPast          = errors.New("membership expiry must be in the future")
 
 	// Inbox Errors
 	ErrInvalidIsReadParameter     = errors.New("invalid isRead parameter")
 	ErrInvalidIsStarredParameter  = errors.New("invalid isStarred parameter")
 	ErrInvalidIsArchivedParameter = errors.New("invalid isArchived parameter")
+	ErrInvalidIsSnoozedParameter  = errors.New("invalid isSnoozed parameter")
 	ErrInvalidSearchParameter     = errors.New("invalid search parameter")
 	ErrSearchTooLong              = errors.New("search string exceeds maximum length")
+	ErrInboxMessageNotFound       = errors.New("inbox message not found")
+	ErrAssigneeNotUnitMember      = errors.New("assignee is not a member of the unit")
+	ErrSnoozeInPast               = errors.New("snooze time must be in the future")
 
 	// Form Errors
-	ErrFormNotFound       = errors.New("form not found")
-	ErrFormNotDraft       = fmt.Errorf("form is not in draft status")
-	ErrFormDeadlinePassed = errors.New("form deadline has passed")
+	ErrFormNotFound          = errors.New("form not found")
+	ErrFormNotDraft          = fmt.Errorf("form is not in draft status")
+	ErrFormDeadlinePassed    = errors.New("form deadline has passed")
+	ErrFormNotPublished      = errors.New("form is not published")
+	ErrFormNotEligible       = errors.New("user is not eligible for this form")
+	ErrFormNotPublic         = errors.New("form does not accept anonymous responses")
+	ErrRespondentRateLimited = errors.New("too many anonymous respondents")
+
+	// Eligibility Errors
+	ErrInvalidEligibilityRule = errors.New("invalid eligibility rule")
 
 	// Question Errors
 	ErrQuestionNotFound           = errors.New("question not found")
@@ -64,12 +94,202 @@ var (
 	ErrValidationFailed           = errors.New("validation failed")
 	ErrInvalidSourceIDWithChoices = errors.New("cannot specify both source_id and choices")
 	ErrInvalidSourceIDForType     = errors.New("source_id is not supported for this question type")
+	ErrQuestionTypeIncompatible   = errors.New("question type is incompatible with the workflow conditions referencing it")
 
 	// Response Errors
 	ErrResponseNotFound = errors.New("response not found")
 
 	// Workflow Errors
 	ErrWorkflowValidationFailed = errors.New("workflow validation failed")
+
+	// Progress Errors
+	ErrSectionNotReachable = errors.New("section is not reachable with the current answers")
+
+	// Approval Errors
+	ErrApprovalAlreadyDecided = errors.New("approval has already been decided")
+
+	// Comment Errors
+	ErrAnswerNotFound        = errors.New("answer not found")
+	ErrCommentParentMismatch = errors.New("parent comment belongs to another answer")
+
+	// Export Errors
+	ErrExportScheduleNotFound = errors.New("export schedule not found")
+	ErrInvalidExportTarget    = errors.New("invalid export target")
+
+	// Upload Errors
+	ErrInvalidUpload = errors.New("invalid upload")
+
+	// Image Errors
+	ErrInvalidImage     = errors.New("invalid image")
+	ErrInvalidImageSize = errors.New("invalid image size")
+
+	// Group Errors
+	ErrGroupNotFound       = errors.New("group not found")
+	ErrGroupMemberNotInOrg = errors.New("group member is not a member of the organization")
+
+	// Tag Errors
+	ErrTagNotFound  = errors.New("tag not found")
+	ErrTagNameTaken = errors.New("tag name already used in the organization")
+	ErrTagNotInOrg  = errors.New("tag does not belong to the organization")
+
+	// Assignment Errors
+	ErrAssignmentNotConfigured = errors.New("response assignment is not configured for the form")
+	ErrReviewerNotInUnit       = errors.New("reviewer is not a member of the unit owning the form")
+	ErrNoReviewers             = errors.New("the form has no reviewers to assign responses to")
+
+	// Grading Errors
+	ErrQuestionNotScored     = errors.New("question has no points")
+	ErrScoreOutOfRange       = errors.New("score must be between 0 and the points of the question")
+	ErrCorrectChoiceNotValid = errors.New("correct choices must be choices of a single or multiple choice question")
+
+	// Attempt Errors
+	ErrFormNotTimed      = errors.New("form has no time limit")
+	ErrAttemptNotStarted = errors.New("timed form has not been started")
+	ErrTimeLimitExceeded = errors.New("time limit of the form has passed")
+
+	// PII Errors
+	ErrQuestionNotPII = errors.New("question is not marked as PII")
+
+	// Retention Errors
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrRetentionPolicyInvalid  = errors.New("invalid retention policy")
+	ErrLegalHold               = errors.New("held under a legal hold")
+	ErrLegalHoldExists         = errors.New("already held under a legal hold")
+	ErrLegalHoldNotFound       = errors.New("legal hold not found")
+
+	// Pipeline Errors
+	ErrPipelineNotFound      = errors.New("pipeline not found")
+	ErrPipelineStagesInvalid = errors.New("invalid pipeline stages")
+	ErrPipelineFormTaken     = errors.New("form is already a stage of a pipeline")
+
+	// Check-in Errors
+	ErrCheckinCodeInvalid = errors.New("invalid check-in code")
+
+	// Attendance Errors
+	ErrAttendanceEventNotFound = errors.New("attendance event not found")
+	ErrAttendanceMarkNotFound  = errors.New("attendance mark not found")
+
+	// Payment Errors
+	ErrPaymentSettingsNotFound    = errors.New("form does not ask for payment")
+	ErrPaymentNotFound            = errors.New("payment not found")
+	ErrPaymentProviderNotFound    = errors.New("payment provider not found")
+	ErrPaymentNotificationInvalid = errors.New("invalid payment notification")
+
+	// Results Errors
+	ErrResultShareNotFound = errors.New("form results are not shared")
+	ErrResultLinkInvalid   = errors.New("invalid or expired results link")
+
+	// Ballot Errors
+	ErrBallotNotFound         = errors.New("form is not in ballot mode")
+	ErrBallotMode             = errors.New("form takes ballots instead of responses")
+	ErrBallotAlreadyCast      = errors.New("ballot already cast")
+	ErrBallotFormHasResponses = errors.New("form with responses cannot take ballots")
+	ErrBallotsCast            = errors.New("ballots have been cast on the form")
+	ErrBallotNotMember        = errors.New("only members of the unit of the form can vote")
+	ErrBallotAnonymousAccess  = errors.New("ballot mode and anonymous access cannot be combined")
+
+	// Delegation Errors
+	ErrDelegationNotFound      = errors.New("delegation not found")
+	ErrDelegationInactive      = errors.New("delegation is revoked or expired")
+	ErrDelegationExpiryInvalid = errors.New("invalid delegation expiry")
+
+	// Announcement Errors
+	ErrAnnouncementNotFound          = errors.New("announcement not found")
+	ErrAnnouncementVisibilityInvalid = errors.New("announcements of the organization board are visible to the whole organization")
+	ErrInvalidReaction               = errors.New("reaction must be an emoji")
+
+	// Wiki Errors
+	ErrWikiPageNotFound     = errors.New("wiki page not found")
+	ErrWikiPageSlugInvalid  = errors.New("invalid wiki page slug")
+	ErrWikiPageSlugTaken    = errors.New("wiki page slug already used in the unit")
+	ErrWikiRevisionConflict = errors.New("wiki page changed since the edited revision")
+	ErrWikiRevisionNotFound = errors.New("wiki page revision not found")
+
+	// Task Errors
+	ErrTaskNotFound    = errors.New("task not found")
+	ErrTaskLinkInvalid = errors.New("invalid task link")
+
+	// Event Errors
+	ErrEventNotFound    = errors.New("event not found")
+	ErrEventTimeInvalid = errors.New("event must end after it starts")
+	ErrEventFormInvalid = errors.New("registration form is not in the organization")
+	ErrEventFormTaken   = errors.New("registration form is linked to another event")
+	ErrEventFull        = errors.New("event is full")
+
+	// Resource Errors
+	ErrResourceNotFound       = errors.New("resource not found")
+	ErrResourceFormInvalid    = errors.New("booking form is not a form of the unit")
+	ErrBookingNotFound        = errors.New("booking not found")
+	ErrBookingTimeInvalid     = errors.New("booking must end after it starts and not start in the past")
+	ErrBookingConflict        = errors.New("resource is already booked for that time")
+	ErrBookingResponseInvalid = errors.New("booking needs an unused submitted response of the requester to the booking form")
+
+	// Finance Errors
+	ErrBudgetRequestNotFound    = errors.New("budget request not found")
+	ErrBudgetRequestClosed      = errors.New("budget request is no longer pending")
+	ErrBudgetChainNotConfigured = errors.New("budget approval chain is not configured")
+	ErrBudgetChainInvalid       = errors.New("invalid budget approval chain")
+	ErrBudgetAttachmentNotFound = errors.New("budget request attachment not found")
+	ErrBudgetAttachmentInvalid  = errors.New("invalid budget request attachment")
+
+	// Profile Errors
+	ErrProfileFieldNotFound  = errors.New("profile field not found")
+	ErrProfileFieldInvalid   = errors.New("invalid profile field")
+	ErrProfileFieldKeyTaken  = errors.New("profile field key is already used in the organization")
+	ErrProfileValueInvalid   = errors.New("invalid profile value")
+	ErrProfilePrefillInvalid = errors.New("invalid profile prefill")
+
+	// Quota Errors
+	ErrQuotaExceeded = errors.New("organization quota exceeded")
+
+	// Webhook Errors
+	ErrWebhookKeyNotFound        = errors.New("webhook key not found")
+	ErrInvalidWebhookGracePeriod = errors.New("invalid webhook grace period")
+	ErrWebhookSignatureInvalid   = errors.New("invalid webhook signature")
+
+	// Ingest Errors
+	ErrInvalidIngestSubmission = errors.New("invalid ingested submission")
+	ErrIngestInProgress        = errors.New("submission is still being ingested")
+	ErrIngestSourceNotFound    = errors.New("ingest source not found")
+
+	// Backup Errors
+	ErrBackupArchiveInvalid = errors.New("invalid backup archive")
+
+	// Test Tenant Errors
+	ErrTestTenantNotFound   = errors.New("test tenant not found")
+	ErrTestTenantTTLInvalid = errors.New("invalid test tenant ttl")
+
+	// Logging Errors
+	ErrInvalidLogLevel = errors.New("invalid log level")
+
+	// Trace Errors
+	ErrInvalidSampleRatio = errors.New("invalid trace sample ratio")
+
+	// Audit Errors
+	ErrInvalidActionParameter = errors.New("invalid action parameter")
+
+	// Student ID Errors
+	ErrStudentIDNotFound = errors.New("student id not found")
+	ErrStudentIDTaken    = errors.New("student id is already verified for another user")
+	ErrNotOrgAdmin       = errors.New("user is not an admin of the organization")
+
+	// OIDC Errors
+	ErrOIDCUnknownClient          = errors.New("unknown oidc client")
+	ErrOIDCInvalidRedirectURI     = errors.New("redirect uri is not registered for the client")
+	ErrOIDCInvalidGrant           = errors.New("invalid or expired authorization code")
+	ErrOIDCUnsupportedGrantType   = errors.New("unsupported grant type")
+	ErrOIDCInvalidCodeVerifier    = errors.New("invalid code verifier")
+	ErrOIDCDeviceFlowNotAllowed   = errors.New("client is not allowed to use the device flow")
+	ErrDeviceAuthorizationPending = errors.New("authorization_pending")
+	ErrDeviceSlowDown             = errors.New("slow_down")
+	ErrDeviceCodeExpired          = errors.New("expired_token")
+	ErrDeviceAccessDenied         = errors.New("access_denied")
+	ErrDeviceUserCodeNotFound     = errors.New("user code not found or expired")
+
+	// Push Errors
+	ErrPushDeviceNotFound      = errors.New("push device not found")
+	ErrInvalidPushSubscription = errors.New("invalid push subscription")
+	ErrPushPlatformUnavailable = errors.New("push platform is not configured")
 )
 
 func NewProblemWriter() *problem.HttpWriter {
@@ -77,9 +297,24 @@ func NewProblemWriter() *problem.HttpWriter {
 }
 
 func ErrorHandler(err error) problem.Problem {
+	var maxBytesError *http.MaxBytesError
 	switch {
+	// Request Errors
+	case errors.Is(err, ErrRequestBodyTooLarge), errors.As(err, &maxBytesError):
+		return problem.Problem{
+			Title:  "Content Too Large",
+			Status: http.StatusRequestEntityTooLarge,
+			Type:   "https://developer.mozilla.org/en-US/docs/Web/HTTP/Status/413",
+			Detail: "request body too large",
+		}
+	case errors.Is(err, ErrInvalidRequestBody), errors.Is(err, ErrInvalidQueryParameter):
+		return problem.NewValidateProblem(err.Error())
+
+	// Auth Errors
 	case errors.Is(err, ErrInvalidRefreshToken):
 		return problem.NewNotFoundProblem("refresh token not found")
+	case errors.Is(err, ErrRefreshTokenMismatch):
+		return problem.NewUnauthorizedProblem("refresh token was issued to another device, sign in again")
 	case errors.Is(err, ErrProviderNotFound):
 		return problem.NewNotFoundProblem("provider not found")
 	case errors.Is(err, ErrInvalidExchangeToken):
@@ -96,6 +331,25 @@ func ErrorHandler(err error) problem.Problem {
 		return problem.NewForbiddenProblem("forbidden error")
 	case errors.Is(err, ErrNotFound):
 		return problem.NewNotFoundProblem("not found")
+	case errors.Is(err, ErrInvalidClient):
+		return problem.NewUnauthorizedProblem("invalid client credentials")
+	case errors.Is(err, ErrMissingToken):
+		return problem.NewValidateProblem("missing token parameter")
+
+	// Magic Link Errors
+	case errors.Is(err, ErrMagicLinkDisabled):
+		return problem.NewNotFoundProblem("magic link login is disabled")
+	case errors.Is(err, ErrMagicLinkRequestInvalid):
+		return problem.NewValidateProblem(err.Error())
+	case errors.Is(err, ErrMagicLinkInvalid):
+		return problem.NewUnauthorizedProblem("magic link is invalid or expired")
+	case errors.Is(err, ErrMagicLinkRateLimited):
+		return problem.Problem{
+			Title:  "Too Many Requests",
+			Status: http.StatusTooManyRequests,
+			Type:   "https://developer.mozilla.org/en-US/docs/Web/HTTP/Status/429",
+			Detail: "too many magic link requests, try again later",
+		}
 	// JWT Authentication Errors
 	case errors.Is(err, ErrMissingAuthHeader):
 		return problem.NewUnauthorizedProblem("missing access token")
@@ -105,6 +359,8 @@ func ErrorHandler(err error) problem.Problem {
 		return problem.NewUnauthorizedProblem("invalid JWT token")
 	case errors.Is(err, ErrInvalidAuthUser):
 		return problem.NewUnauthorizedProblem("invalid authenticated user")
+	case errors.Is(err, ErrRestrictedToken):
+		return problem.NewForbiddenProblem("token is restricted to another resource")
 	// User Errors
 	case errors.Is(err, ErrUserNotFound):
 		return problem.NewNotFoundProblem("user not found")
@@ -136,12 +392,33 @@ func ErrorHandler(err error) problem.Problem {
 		return problem.NewNotFoundProblem("unit not found")
 	case errors.Is(err, ErrSlugNotBelongToUnit):
 		return problem.NewNotFoundProblem("slug not belong to unit")
+	case errors.Is(err, ErrInvalidRecursiveParameter):
+		return problem.NewValidateProblem("invalid recursive parameter")
+	case errors.Is(err, ErrMemberNotFound):
+		return problem.NewNotFoundProblem("member not found")
+	case errors.Is(err, ErrValidUntilInPast):
+		return problem.NewValidateProblem("membership expiry must be in the future")
 
 	// Form Errors
 	case errors.Is(err, ErrFormNotFound):
 		return problem.NewNotFoundProblem("form not found")
 	case errors.Is(err, ErrFormNotDraft):
 		return problem.NewValidateProblem("form is not in draft status")
+	case errors.Is(err, ErrFormNotEligible):
+		return problem.NewForbiddenProblem("user is not eligible for this form")
+	case errors.Is(err, ErrFormNotPublic):
+		return problem.NewForbiddenProblem("form does not accept anonymous responses")
+	case errors.Is(err, ErrRespondentRateLimited):
+		return problem.Problem{
+			Title:  "Too Many Requests",
+			Status: http.StatusTooManyRequests,
+			Type:   "https://developer.mozilla.org/en-US/docs/Web/HTTP/Status/429",
+			Detail: "too many anonymous respondents from this client, try again later",
+		}
+
+	// Eligibility Errors
+	case errors.Is(err, ErrInvalidEligibilityRule):
+		return problem.NewValidateProblem("invalid eligibility rule")
 
 	// Inbox Errors
 	case errors.Is(err, ErrInvalidIsReadParameter):
@@ -150,12 +427,22 @@ func ErrorHandler(err error) problem.Problem {
 		return problem.NewValidateProblem("invalid isStarred parameter")
 	case errors.Is(err, ErrInvalidIsArchivedParameter):
 		return problem.NewValidateProblem("invalid isArchived parameter")
+	case errors.Is(err, ErrInvalidIsSnoozedParameter):
+		return problem.NewValidateProblem("invalid isSnoozed parameter")
 	case errors.Is(err, ErrInvalidSearchParameter):
 		return problem.NewValidateProblem("invalid search parameter")
 	case errors.Is(err, ErrSearchTooLong):
 		return problem.NewValidateProblem("search string exceeds maximum length")
+	case errors.Is(err, ErrInboxMessageNotFound):
+		return problem.NewNotFoundProblem("inbox message not found")
+	case errors.Is(err, ErrAssigneeNotUnitMember):
+		return problem.NewValidateProblem("assignee is not a member of the unit")
+	case errors.Is(err, ErrSnoozeInPast):
+		return problem.NewValidateProblem("snooze time must be in the future")
 	case errors.Is(err, ErrFormDeadlinePassed):
 		return problem.NewValidateProblem("form deadline has passed")
+	case errors.Is(err, ErrFormNotPublished):
+		return problem.NewValidateProblem("form is not published")
 
 	// Question Errors
 	case errors.Is(err, ErrQuestionNotFound):
@@ -166,6 +453,8 @@ func ErrorHandler(err error) problem.Problem {
 		return problem.NewBadRequestProblem("cannot specify both source_id and choices")
 	case errors.Is(err, ErrInvalidSourceIDForType):
 		return problem.NewBadRequestProblem("source_id is not supported for this question type")
+	case errors.Is(err, ErrQuestionTypeIncompatible):
+		return problem.NewValidateProblem("question type is incompatible with the workflow conditions referencing it")
 
 	// Response Errors
 	case errors.Is(err, ErrResponseNotFound):
@@ -178,6 +467,307 @@ func ErrorHandler(err error) problem.Problem {
 	// Workflow Errors
 	case errors.Is(err, ErrWorkflowValidationFailed):
 		return problem.NewValidateProblem("workflow validation failed")
+
+	// Progress Errors
+	case errors.Is(err, ErrSectionNotReachable):
+		return problem.NewValidateProblem("section is not reachable with the current answers")
+
+	// Approval Errors
+	case errors.Is(err, ErrApprovalAlreadyDecided):
+		return problem.NewValidateProblem("approval has already been decided")
+
+	// Comment Errors
+	case errors.Is(err, ErrAnswerNotFound):
+		return problem.NewNotFoundProblem("answer not found")
+	case errors.Is(err, ErrCommentParentMismatch):
+		return problem.NewValidateProblem("parent comment belongs to another answer")
+
+	// Export Errors
+	case errors.Is(err, ErrExportScheduleNotFound):
+		return problem.NewNotFoundProblem("export schedule not found")
+	case errors.Is(err, ErrInvalidExportTarget):
+		return problem.NewValidateProblem("invalid export target")
+
+	// Upload Errors
+	case errors.Is(err, ErrInvalidUpload):
+		return problem.NewValidateProblem("invalid upload")
+
+	// Image Errors
+	case errors.Is(err, ErrInvalidImage):
+		return problem.NewValidateProblem("invalid image")
+	case errors.Is(err, ErrInvalidImageSize):
+		return problem.NewValidateProblem("invalid image size")
+
+	// Group Errors
+	case errors.Is(err, ErrGroupNotFound):
+		return problem.NewNotFoundProblem("group not found")
+	case errors.Is(err, ErrGroupMemberNotInOrg):
+		return problem.NewValidateProblem("group member is not a member of the organization")
+
+	// Tag Errors
+	case errors.Is(err, ErrTagNotFound):
+		return problem.NewNotFoundProblem("tag not found")
+	case errors.Is(err, ErrTagNameTaken):
+		return problem.NewValidateProblem("tag name already used in the organization")
+	case errors.Is(err, ErrTagNotInOrg):
+		return problem.NewValidateProblem("tag does not belong to the organization")
+
+	// Assignment Errors
+	case errors.Is(err, ErrAssignmentNotConfigured):
+		return problem.NewNotFoundProblem("response assignment is not configured for the form")
+	case errors.Is(err, ErrReviewerNotInUnit):
+		return problem.NewValidateProblem("reviewer is not a member of the unit owning the form")
+	case errors.Is(err, ErrNoReviewers):
+		return problem.NewValidateProblem("the form has no reviewers to assign responses to")
+
+	// Grading Errors
+	case errors.Is(err, ErrQuestionNotScored):
+		return problem.NewNotFoundProblem("question has no points")
+	case errors.Is(err, ErrScoreOutOfRange):
+		return problem.NewValidateProblem("score must be between 0 and the points of the question")
+	case errors.Is(err, ErrCorrectChoiceNotValid):
+		return problem.NewValidateProblem("correct choices must be choices of a single or multiple choice question")
+
+	// Attempt Errors
+	case errors.Is(err, ErrFormNotTimed):
+		return problem.NewValidateProblem("form has no time limit")
+	case errors.Is(err, ErrAttemptNotStarted):
+		return problem.NewNotFoundProblem("timed form has not been started")
+	case errors.Is(err, ErrTimeLimitExceeded):
+		return problem.NewValidateProblem("time limit of the form has passed")
+
+	// PII Errors
+	case errors.Is(err, ErrQuestionNotPII):
+		return problem.NewNotFoundProblem("question is not marked as PII")
+
+	// Retention Errors
+	case errors.Is(err, ErrRetentionPolicyNotFound):
+		return problem.NewNotFoundProblem("retention policy not found")
+	case errors.Is(err, ErrRetentionPolicyInvalid):
+		return problem.NewValidateProblem("invalid retention policy")
+	case errors.Is(err, ErrLegalHold):
+		return problem.NewForbiddenProblem("held under a legal hold")
+	case errors.Is(err, ErrLegalHoldExists):
+		return problem.NewValidateProblem("already held under a legal hold")
+	case errors.Is(err, ErrLegalHoldNotFound):
+		return problem.NewNotFoundProblem("legal hold not found")
+
+	// Pipeline Errors
+	case errors.Is(err, ErrPipelineNotFound):
+		return problem.NewNotFoundProblem("pipeline not found")
+	case errors.Is(err, ErrPipelineStagesInvalid):
+		return problem.NewValidateProblem("invalid pipeline stages")
+	case errors.Is(err, ErrPipelineFormTaken):
+		return problem.NewValidateProblem("form is already a stage of a pipeline")
+
+	// Check-in Errors
+	case errors.Is(err, ErrCheckinCodeInvalid):
+		return problem.NewValidateProblem("invalid check-in code")
+
+	// Attendance Errors
+	case errors.Is(err, ErrAttendanceEventNotFound):
+		return problem.NewNotFoundProblem("attendance event not found")
+	case errors.Is(err, ErrAttendanceMarkNotFound):
+		return problem.NewNotFoundProblem("attendance mark not found")
+
+	// Payment Errors
+	case errors.Is(err, ErrPaymentSettingsNotFound):
+		return problem.NewNotFoundProblem("form does not ask for payment")
+	case errors.Is(err, ErrPaymentNotFound):
+		return problem.NewNotFoundProblem("payment not found")
+	case errors.Is(err, ErrPaymentProviderNotFound):
+		return problem.NewNotFoundProblem("payment provider not found")
+	case errors.Is(err, ErrPaymentNotificationInvalid):
+		return problem.NewValidateProblem("invalid payment notification")
+
+	// Results Errors
+	case errors.Is(err, ErrResultShareNotFound):
+		return problem.NewNotFoundProblem("form results are not shared")
+	case errors.Is(err, ErrResultLinkInvalid):
+		return problem.NewForbiddenProblem("invalid or expired results link")
+
+	// Ballot Errors
+	case errors.Is(err, ErrBallotNotFound):
+		return problem.NewNotFoundProblem("form is not in ballot mode")
+	case errors.Is(err, ErrBallotMode):
+		return problem.NewValidateProblem("form takes ballots instead of responses")
+	case errors.Is(err, ErrBallotAlreadyCast):
+		return problem.NewForbiddenProblem("ballot already cast")
+	case errors.Is(err, ErrBallotFormHasResponses):
+		return problem.NewValidateProblem("form with responses cannot take ballots")
+	case errors.Is(err, ErrBallotsCast):
+		return problem.NewValidateProblem("ballots have been cast on the form")
+	case errors.Is(err, ErrBallotNotMember):
+		return problem.NewForbiddenProblem("only members of the unit of the form can vote")
+	case errors.Is(err, ErrBallotAnonymousAccess):
+		return problem.NewValidateProblem("ballot mode and anonymous access cannot be combined")
+
+	// Delegation Errors
+	case errors.Is(err, ErrDelegationNotFound):
+		return problem.NewNotFoundProblem("delegation not found")
+	case errors.Is(err, ErrDelegationInactive):
+		return problem.NewUnauthorizedProblem("delegation is revoked or expired")
+	case errors.Is(err, ErrDelegationExpiryInvalid):
+		return problem.NewValidateProblem("invalid delegation expiry")
+
+	// Announcement Errors
+	case errors.Is(err, ErrAnnouncementNotFound):
+		return problem.NewNotFoundProblem("announcement not found")
+	case errors.Is(err, ErrAnnouncementVisibilityInvalid):
+		return problem.NewValidateProblem("announcements of the organization board are visible to the whole organization")
+	case errors.Is(err, ErrInvalidReaction):
+		return problem.NewValidateProblem("reaction must be an emoji")
+
+	// Wiki Errors
+	case errors.Is(err, ErrWikiPageNotFound):
+		return problem.NewNotFoundProblem("wiki page not found")
+	case errors.Is(err, ErrWikiPageSlugInvalid):
+		return problem.NewValidateProblem("invalid wiki page slug")
+	case errors.Is(err, ErrWikiPageSlugTaken):
+		return problem.NewValidateProblem("wiki page slug already used in the unit")
+	case errors.Is(err, ErrWikiRevisionConflict):
+		return problem.NewValidateProblem("wiki page changed since the edited revision")
+	case errors.Is(err, ErrWikiRevisionNotFound):
+		return problem.NewNotFoundProblem("wiki page revision not found")
+
+	// Task Errors
+	case errors.Is(err, ErrTaskNotFound):
+		return problem.NewNotFoundProblem("task not found")
+	case errors.Is(err, ErrTaskLinkInvalid):
+		return problem.NewValidateProblem("invalid task link")
+
+	// Event Errors
+	case errors.Is(err, ErrEventNotFound):
+		return problem.NewNotFoundProblem("event not found")
+	case errors.Is(err, ErrEventTimeInvalid):
+		return problem.NewValidateProblem("event must end after it starts")
+	case errors.Is(err, ErrEventFormInvalid):
+		return problem.NewValidateProblem("registration form is not in the organization")
+	case errors.Is(err, ErrEventFormTaken):
+		return problem.NewValidateProblem("registration form is linked to another event")
+	case errors.Is(err, ErrEventFull):
+		return problem.NewValidateProblem("event is full")
+
+	// Resource Errors
+	case errors.Is(err, ErrResourceNotFound):
+		return problem.NewNotFoundProblem("resource not found")
+	case errors.Is(err, ErrResourceFormInvalid):
+		return problem.NewValidateProblem("booking form is not a form of the unit")
+	case errors.Is(err, ErrBookingNotFound):
+		return problem.NewNotFoundProblem("booking not found")
+	case errors.Is(err, ErrBookingTimeInvalid):
+		return problem.NewValidateProblem("booking must end after it starts and not start in the past")
+	case errors.Is(err, ErrBookingConflict):
+		return problem.NewValidateProblem("resource is already booked for that time")
+	case errors.Is(err, ErrBookingResponseInvalid):
+		return problem.NewValidateProblem("booking needs an unused submitted response of the requester to the booking form")
+
+	// Finance Errors
+	case errors.Is(err, ErrBudgetRequestNotFound):
+		return problem.NewNotFoundProblem("budget request not found")
+	case errors.Is(err, ErrBudgetRequestClosed):
+		return problem.NewValidateProblem("budget request is no longer pending")
+	case errors.Is(err, ErrBudgetChainNotConfigured):
+		return problem.NewValidateProblem("budget approval chain is not configured")
+	case errors.Is(err, ErrBudgetChainInvalid):
+		return problem.NewValidateProblem("invalid budget approval chain")
+	case errors.Is(err, ErrBudgetAttachmentNotFound):
+		return problem.NewNotFoundProblem("budget request attachment not found")
+	case errors.Is(err, ErrBudgetAttachmentInvalid):
+		return problem.NewValidateProblem("invalid budget request attachment")
+
+	// Profile Errors
+	case errors.Is(err, ErrProfileFieldNotFound):
+		return problem.NewNotFoundProblem("profile field not found")
+	case errors.Is(err, ErrProfileFieldInvalid):
+		return problem.NewValidateProblem("invalid profile field")
+	case errors.Is(err, ErrProfileFieldKeyTaken):
+		return problem.NewValidateProblem("profile field key is already used in the organization")
+	case errors.Is(err, ErrProfileValueInvalid):
+		return problem.NewValidateProblem("invalid profile value")
+	case errors.Is(err, ErrProfilePrefillInvalid):
+		return problem.NewValidateProblem("invalid profile prefill")
+
+	// Quota Errors
+	case errors.Is(err, ErrQuotaExceeded):
+		return problem.NewForbiddenProblem("organization quota exceeded")
+
+	// Webhook Errors
+	case errors.Is(err, ErrWebhookKeyNotFound):
+		return problem.NewNotFoundProblem("webhook key not found")
+	case errors.Is(err, ErrInvalidWebhookGracePeriod):
+		return problem.NewValidateProblem(err.Error())
+	case errors.Is(err, ErrWebhookSignatureInvalid):
+		return problem.NewUnauthorizedProblem("invalid webhook signature")
+
+	// Ingest Errors
+	case errors.Is(err, ErrInvalidIngestSubmission):
+		return problem.NewValidateProblem(err.Error())
+	case errors.Is(err, ErrIngestInProgress):
+		return problem.NewValidateProblem("submission is still being ingested, retry later")
+	case errors.Is(err, ErrIngestSourceNotFound):
+		return problem.NewNotFoundProblem("ingest source not found")
+
+	// Backup Errors
+	case errors.Is(err, ErrBackupArchiveInvalid):
+		return problem.NewValidateProblem("invalid backup archive")
+
+	// Test Tenant Errors
+	case errors.Is(err, ErrTestTenantNotFound):
+		return problem.NewNotFoundProblem("test tenant not found")
+	case errors.Is(err, ErrTestTenantTTLInvalid):
+		return problem.NewValidateProblem("invalid test tenant ttl")
+
+	// Logging Errors
+	case errors.Is(err, ErrInvalidLogLevel):
+		return problem.NewValidateProblem("invalid log level")
+
+	// Trace Errors
+	case errors.Is(err, ErrInvalidSampleRatio):
+		return problem.NewValidateProblem("invalid trace sample ratio")
+
+	// Audit Errors
+	case errors.Is(err, ErrInvalidActionParameter):
+		return problem.NewValidateProblem("invalid action parameter")
+
+	// Student ID Errors
+	case errors.Is(err, ErrStudentIDNotFound):
+		return problem.NewNotFoundProblem("student id not found")
+	case errors.Is(err, ErrStudentIDTaken):
+		return problem.NewValidateProblem("student id is already verified for another user")
+	case errors.Is(err, ErrNotOrgAdmin):
+		return problem.NewForbiddenProblem("user is not an admin of the organization")
+	// OIDC Errors
+	case errors.Is(err, ErrOIDCUnknownClient):
+		return problem.NewValidateProblem("unknown oidc client")
+	case errors.Is(err, ErrOIDCInvalidRedirectURI):
+		return problem.NewValidateProblem("redirect uri is not registered for the client")
+	case errors.Is(err, ErrOIDCInvalidGrant):
+		return problem.NewValidateProblem("invalid or expired authorization code")
+	case errors.Is(err, ErrOIDCUnsupportedGrantType):
+		return problem.NewValidateProblem("unsupported grant type")
+	case errors.Is(err, ErrOIDCInvalidCodeVerifier):
+		return problem.NewValidateProblem("invalid code verifier")
+	case errors.Is(err, ErrOIDCDeviceFlowNotAllowed):
+		return problem.NewValidateProblem("client is not allowed to use the device flow")
+	case errors.Is(err, ErrDeviceAuthorizationPending):
+		return problem.NewValidateProblem("authorization_pending")
+	case errors.Is(err, ErrDeviceSlowDown):
+		return problem.NewValidateProblem("slow_down")
+	case errors.Is(err, ErrDeviceCodeExpired):
+		return problem.NewValidateProblem("expired_token")
+	case errors.Is(err, ErrDeviceAccessDenied):
+		return problem.NewValidateProblem("access_denied")
+	case errors.Is(err, ErrDeviceUserCodeNotFound):
+		return problem.NewNotFoundProblem("user code not found or expired")
+
+	// Push Errors
+	case errors.Is(err, ErrPushDeviceNotFound):
+		return problem.NewNotFoundProblem("push device not found")
+	case errors.Is(err, ErrInvalidPushSubscription):
+		return problem.NewValidateProblem("invalid push subscription")
+	case errors.Is(err, ErrPushPlatformUnavailable):
+		return problem.NewValidateProblem("push platform is not configured")
 	}
 	return problem.Problem{}
 }
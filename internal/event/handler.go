@@ -0,0 +1,356 @@
+package event
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Create(ctx context.Context, orgID uuid.UUID, input Input, userID uuid.UUID) (Summary, error)
+	ListUpcoming(ctx context.Context, orgID uuid.UUID) ([]Summary, error)
+	ListAll(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]Summary, error)
+	GetPublished(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (Summary, error)
+	Calendar(ctx context.Context, orgID uuid.UUID, calendarName string) ([]byte, error)
+	Update(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input Input, userID uuid.UUID) (Summary, error)
+	Delete(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+type Request struct {
+	Title       string    `json:"title" validate:"required,max=255"`
+	Description string    `json:"description" validate:"max=10000"`
+	Venue       string    `json:"venue" validate:"max=255"`
+	StartsAt    time.Time `json:"startsAt" validate:"required"`
+	EndsAt      time.Time `json:"endsAt" validate:"required"`
+	Capacity    *int32    `json:"capacity" validate:"omitempty,min=1"`
+	FormID      string    `json:"formId" validate:"omitempty,uuid"`
+	Published   bool      `json:"published"`
+}
+
+type Response struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	Venue         string    `json:"venue"`
+	StartsAt      time.Time `json:"startsAt"`
+	EndsAt        time.Time `json:"endsAt"`
+	Capacity      *int32    `json:"capacity,omitempty"`
+	FormID        *string   `json:"formId,omitempty"`
+	Registrations int64     `json:"registrations"`
+	Published     bool      `json:"published"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+func ToResponse(event Summary) Response {
+	response := Response{
+		ID:            event.ID.String(),
+		Title:         event.Title,
+		Description:   event.Description,
+		Venue:         event.Venue,
+		StartsAt:      event.StartsAt.Time,
+		EndsAt:        event.EndsAt.Time,
+		Registrations: event.Registrations,
+		Published:     event.Published,
+		CreatedAt:     event.CreatedAt.Time,
+		UpdatedAt:     event.UpdatedAt.Time,
+	}
+	if event.Capacity.Valid {
+		response.Capacity = &event.Capacity.Int32
+	}
+	if event.FormID.Valid {
+		formID := uuid.UUID(event.FormID.Bytes).String()
+		response.FormID = &formID
+	}
+	return response
+}
+
+// ToInput converts the request; the form ID is expected to have passed validation
+func (r Request) ToInput() Input {
+	input := Input{
+		Title:       strings.TrimSpace(r.Title),
+		Description: r.Description,
+		Venue:       strings.TrimSpace(r.Venue),
+		StartsAt:    r.StartsAt,
+		EndsAt:      r.EndsAt,
+		Capacity:    r.Capacity,
+		Published:   r.Published,
+	}
+	if r.FormID != "" {
+		input.FormID = uuid.MustParse(r.FormID)
+	}
+	return input
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("event/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// org resolves the slug and the organization it names
+func (h *Handler) org(ctx context.Context) (string, uuid.UUID, error) {
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return slug, orgID, nil
+}
+
+func writeList(w http.ResponseWriter, events []Summary) {
+	response := make([]Response, len(events))
+	for i, event := range events {
+		response[i] = ToResponse(event)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	events, err := h.store.ListUpcoming(traceCtx, orgID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	writeList(w, events)
+}
+
+func (h *Handler) ListAllHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListAllHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	events, err := h.store.ListAll(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	writeList(w, events)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	event, err := h.store.GetPublished(traceCtx, orgID, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(event))
+}
+
+// CalendarHandler serves the published events of the organization as an iCalendar
+// feed that calendar apps can subscribe to
+func (h *Handler) CalendarHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CalendarHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	slug, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	content, err := h.store.Calendar(traceCtx, orgID, slug)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", slug+".ics"))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(content)
+	if err != nil {
+		logger.Error("failed to write event calendar", zap.Error(err))
+	}
+}
+
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	event, err := h.store.Create(traceCtx, orgID, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(event))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	event, err := h.store.Update(traceCtx, orgID, id, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(event))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, orgID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,68 @@
+package event
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+// icsEscaper escapes the characters RFC 5545 reserves in TEXT values
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// writeCalendar renders the events as an iCalendar (RFC 5545) feed stamped at now
+func writeCalendar(name string, events []OrgEvent, now time.Time) []byte {
+	var buf bytes.Buffer
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:-//NYCU SDC//Core System//EN")
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	writeLine(&buf, "X-WR-CALNAME:"+icsEscaper.Replace(name))
+
+	stamp := now.UTC().Format(icsTimeLayout)
+	for _, event := range events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+event.ID.String()+"@core-system-backend")
+		writeLine(&buf, "DTSTAMP:"+stamp)
+		writeLine(&buf, "DTSTART:"+event.StartsAt.Time.UTC().Format(icsTimeLayout))
+		writeLine(&buf, "DTEND:"+event.EndsAt.Time.UTC().Format(icsTimeLayout))
+		writeLine(&buf, "LAST-MODIFIED:"+event.UpdatedAt.Time.UTC().Format(icsTimeLayout))
+		writeLine(&buf, "SUMMARY:"+icsEscaper.Replace(event.Title))
+		if event.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+icsEscaper.Replace(event.Description))
+		}
+		if event.Venue != "" {
+			writeLine(&buf, "LOCATION:"+icsEscaper.Replace(event.Venue))
+		}
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// writeLine ends the content line with CRLF, folding it so that no line exceeds 75
+// octets without splitting a UTF-8 character
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts towards its length
+		limit = 74
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
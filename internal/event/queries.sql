@@ -0,0 +1,71 @@
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: IsFormInOrg :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    WHERE f.id = @form_id AND COALESCE(u.org_id, u.id) = @org_id::uuid
+);
+
+-- name: Create :one
+INSERT INTO org_events (org_id, title, description, venue, starts_at, ends_at, capacity, form_id, published, created_by)
+VALUES (@org_id, @title, @description, @venue, @starts_at, @ends_at, @capacity, @form_id, @published, @created_by)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM org_events
+WHERE id = @id AND org_id = @org_id;
+
+-- name: ListByOrg :many
+SELECT * FROM org_events
+WHERE org_id = @org_id
+ORDER BY starts_at DESC;
+
+-- name: ListUpcoming :many
+SELECT * FROM org_events
+WHERE org_id = @org_id AND published AND ends_at > now()
+ORDER BY starts_at ASC;
+
+-- name: ListPublished :many
+SELECT * FROM org_events
+WHERE org_id = @org_id AND published
+ORDER BY starts_at ASC;
+
+-- name: Update :one
+UPDATE org_events
+SET title = @title,
+    description = @description,
+    venue = @venue,
+    starts_at = @starts_at,
+    ends_at = @ends_at,
+    capacity = @capacity,
+    form_id = @form_id,
+    published = @published,
+    updated_at = now()
+WHERE id = @id AND org_id = @org_id
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM org_events
+WHERE id = @id AND org_id = @org_id;
+
+-- name: CountRegistrations :many
+SELECT form_id, COUNT(*)::bigint AS registrations
+FROM form_responses
+WHERE form_id = ANY(@form_ids::uuid[])
+  AND submitted_at IS NOT NULL
+  AND NOT is_test
+GROUP BY form_id;
+
+-- name: GetCapacity :one
+SELECT e.capacity::int AS capacity,
+       (
+           SELECT COUNT(*) FROM form_responses r
+           WHERE r.form_id = e.form_id
+             AND r.submitted_at IS NOT NULL
+             AND NOT r.is_test
+             AND r.submitted_by <> @user_id
+       )::bigint AS taken
+FROM org_events e
+WHERE e.form_id = @form_id AND e.capacity IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,17 @@
+package event
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the events of an organization: the published ones and their calendar
+// feed are public, the rest belongs to its admins
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/events", route.TenantPublic, route.PermissionNone, h.ListHandler)
+	r.Handle("GET /orgs/{slug}/events/calendar.ics", route.TenantPublic, route.PermissionNone, h.CalendarHandler)
+	r.Handle("GET /orgs/{slug}/events/all", route.TenantAuthenticated, route.PermissionOrgAdmin, h.ListAllHandler)
+	r.Handle("POST /orgs/{slug}/events", route.TenantAuthenticated, route.PermissionOrgAdmin, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/events/{id}", route.TenantPublic, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /orgs/{slug}/events/{id}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.UpdateHandler)
+	r.Handle("DELETE /orgs/{slug}/events/{id}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.DeleteHandler)
+}
@@ -0,0 +1,417 @@
+package event
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	IsFormInOrg(ctx context.Context, arg IsFormInOrgParams) (bool, error)
+	Create(ctx context.Context, arg CreateParams) (OrgEvent, error)
+	GetByID(ctx context.Context, arg GetByIDParams) (OrgEvent, error)
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]OrgEvent, error)
+	ListUpcoming(ctx context.Context, orgID uuid.UUID) ([]OrgEvent, error)
+	ListPublished(ctx context.Context, orgID uuid.UUID) ([]OrgEvent, error)
+	Update(ctx context.Context, arg UpdateParams) (OrgEvent, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	CountRegistrations(ctx context.Context, formIds []uuid.UUID) ([]CountRegistrationsRow, error)
+	GetCapacity(ctx context.Context, arg GetCapacityParams) (GetCapacityRow, error)
+}
+
+// AttendanceStore tracks the attendance of the registration form of an event from its start
+type AttendanceStore interface {
+	SetStart(ctx context.Context, formID uuid.UUID, startsAt time.Time, userID uuid.UUID) error
+}
+
+// Input describes an event. Capacity is nil when the places are unlimited and FormID is
+// uuid.Nil when the event takes no registrations.
+type Input struct {
+	Title       string
+	Description string
+	Venue       string
+	StartsAt    time.Time
+	EndsAt      time.Time
+	Capacity    *int32
+	FormID      uuid.UUID
+	Published   bool
+}
+
+// Summary is an event together with the number of submitted registrations to its form
+type Summary struct {
+	OrgEvent
+	Registrations int64
+}
+
+type Service struct {
+	logger          *zap.Logger
+	queries         Querier
+	tracer          trace.Tracer
+	attendanceStore AttendanceStore
+}
+
+func NewService(logger *zap.Logger, db DBTX, attendanceStore AttendanceStore) *Service {
+	return &Service{
+		logger:          logger,
+		queries:         New(db),
+		tracer:          otel.Tracer("event/service"),
+		attendanceStore: attendanceStore,
+	}
+}
+
+func (s *Service) requireOrgAdmin(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsOrgAdmin(ctx, IsOrgAdminParams{OrgID: orgID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+// validate checks the times of the event and that its registration form belongs to the
+// organization
+func (s *Service) validate(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, input Input) error {
+	if !input.EndsAt.After(input.StartsAt) {
+		return internal.ErrEventTimeInvalid
+	}
+
+	if input.FormID == uuid.Nil {
+		return nil
+	}
+
+	inOrg, err := s.queries.IsFormInOrg(ctx, IsFormInOrgParams{FormID: input.FormID, OrgID: orgID})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", input.FormID.String(), logger, "check form organization")
+	}
+	if !inOrg {
+		return fmt.Errorf("%w: form %s", internal.ErrEventFormInvalid, input.FormID)
+	}
+	return nil
+}
+
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, input Input, userID uuid.UUID) (Summary, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	err = s.validate(ctx, logger, orgID, input)
+	if err != nil {
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	event, err := s.queries.Create(ctx, CreateParams{
+		OrgID:       orgID,
+		Title:       input.Title,
+		Description: input.Description,
+		Venue:       input.Venue,
+		StartsAt:    pgtype.Timestamptz{Time: input.StartsAt, Valid: true},
+		EndsAt:      pgtype.Timestamptz{Time: input.EndsAt, Valid: true},
+		Capacity:    capacity(input.Capacity),
+		FormID:      pgtype.UUID{Bytes: input.FormID, Valid: input.FormID != uuid.Nil},
+		Published:   input.Published,
+		CreatedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_events", "org_id", orgID.String(), logger, "create event")
+		if errors.Is(err, databaseutil.ErrUniqueViolation) {
+			err = internal.ErrEventFormTaken
+		}
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	s.syncAttendance(ctx, logger, event, userID)
+
+	logger.Info("Created event", zap.String("event_id", event.ID.String()), zap.String("org_id", orgID.String()))
+
+	return s.summarize(ctx, logger, event)
+}
+
+// ListUpcoming returns the published events of the organization that have not ended,
+// the soonest first
+func (s *Service) ListUpcoming(ctx context.Context, orgID uuid.UUID) ([]Summary, error) {
+	ctx, span := s.tracer.Start(ctx, "ListUpcoming")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	events, err := s.queries.ListUpcoming(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_events", "org_id", orgID.String(), logger, "list upcoming events")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	summaries, err := s.summaries(ctx, logger, events)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// ListAll returns every event of the organization, published or not, the latest first
+func (s *Service) ListAll(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]Summary, error) {
+	ctx, span := s.tracer.Start(ctx, "ListAll")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	events, err := s.queries.ListByOrg(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_events", "org_id", orgID.String(), logger, "list events")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	summaries, err := s.summaries(ctx, logger, events)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// GetPublished returns a published event; an unpublished one is not found
+func (s *Service) GetPublished(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (Summary, error) {
+	ctx, span := s.tracer.Start(ctx, "GetPublished")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	event, err := s.queries.GetByID(ctx, GetByIDParams{ID: id, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrEventNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "org_events", "id", id.String(), logger, "get event")
+		}
+		span.RecordError(err)
+		return Summary{}, err
+	}
+	if !event.Published {
+		err = internal.ErrEventNotFound
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	summary, err := s.summarize(ctx, logger, event)
+	if err != nil {
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	return summary, nil
+}
+
+// Calendar renders the published events of the organization, past ones included, as
+// an iCalendar feed named after calendarName
+func (s *Service) Calendar(ctx context.Context, orgID uuid.UUID, calendarName string) ([]byte, error) {
+	ctx, span := s.tracer.Start(ctx, "Calendar")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	events, err := s.queries.ListPublished(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_events", "org_id", orgID.String(), logger, "list published events")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return writeCalendar(calendarName, events, time.Now()), nil
+}
+
+func (s *Service) Update(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input Input, userID uuid.UUID) (Summary, error) {
+	ctx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	err = s.validate(ctx, logger, orgID, input)
+	if err != nil {
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	event, err := s.queries.Update(ctx, UpdateParams{
+		Title:       input.Title,
+		Description: input.Description,
+		Venue:       input.Venue,
+		StartsAt:    pgtype.Timestamptz{Time: input.StartsAt, Valid: true},
+		EndsAt:      pgtype.Timestamptz{Time: input.EndsAt, Valid: true},
+		Capacity:    capacity(input.Capacity),
+		FormID:      pgtype.UUID{Bytes: input.FormID, Valid: input.FormID != uuid.Nil},
+		Published:   input.Published,
+		ID:          id,
+		OrgID:       orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrEventNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "org_events", "id", id.String(), logger, "update event")
+			if errors.Is(err, databaseutil.ErrUniqueViolation) {
+				err = internal.ErrEventFormTaken
+			}
+		}
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	s.syncAttendance(ctx, logger, event, userID)
+
+	logger.Info("Updated event", zap.String("event_id", id.String()))
+
+	return s.summarize(ctx, logger, event)
+}
+
+// Delete removes the event; its registration form and the responses to it are kept
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Delete(ctx, DeleteParams{ID: id, OrgID: orgID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_events", "id", id.String(), logger, "delete event")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrEventNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted event", zap.String("event_id", id.String()))
+
+	return nil
+}
+
+// CheckCapacity is called before a response to formID is submitted. It fails with
+// ErrEventFull when the event registering on the form has no place left, the earlier
+// submissions of userID not counted; forms without a limited event always pass.
+func (s *Service) CheckCapacity(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "CheckCapacity")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	row, err := s.queries.GetCapacity(ctx, GetCapacityParams{
+		UserID: userID,
+		FormID: pgtype.UUID{Bytes: formID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_events", "form_id", formID.String(), logger, "get event capacity")
+		span.RecordError(err)
+		return err
+	}
+
+	if row.Taken >= int64(row.Capacity) {
+		err = fmt.Errorf("%w: %d of %d places taken", internal.ErrEventFull, row.Taken, row.Capacity)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// syncAttendance moves the start of the attendance of the registration form to the start
+// of the event. The event is saved already, so a failure is logged and not returned.
+func (s *Service) syncAttendance(ctx context.Context, logger *zap.Logger, event OrgEvent, userID uuid.UUID) {
+	if !event.FormID.Valid {
+		return
+	}
+
+	formID := uuid.UUID(event.FormID.Bytes)
+	err := s.attendanceStore.SetStart(ctx, formID, event.StartsAt.Time, userID)
+	if err != nil {
+		logger.Warn("Failed to sync attendance with event",
+			zap.String("event_id", event.ID.String()),
+			zap.String("form_id", formID.String()),
+			zap.Error(err))
+	}
+}
+
+func (s *Service) summarize(ctx context.Context, logger *zap.Logger, event OrgEvent) (Summary, error) {
+	summaries, err := s.summaries(ctx, logger, []OrgEvent{event})
+	if err != nil {
+		return Summary{}, err
+	}
+	return summaries[0], nil
+}
+
+func (s *Service) summaries(ctx context.Context, logger *zap.Logger, events []OrgEvent) ([]Summary, error) {
+	formIDs := make([]uuid.UUID, 0, len(events))
+	for _, event := range events {
+		if event.FormID.Valid {
+			formIDs = append(formIDs, event.FormID.Bytes)
+		}
+	}
+
+	registrations := make(map[uuid.UUID]int64, len(formIDs))
+	if len(formIDs) > 0 {
+		rows, err := s.queries.CountRegistrations(ctx, formIDs)
+		if err != nil {
+			return nil, databaseutil.WrapDBError(err, logger, "count event registrations")
+		}
+		for _, row := range rows {
+			registrations[row.FormID] = row.Registrations
+		}
+	}
+
+	summaries := make([]Summary, len(events))
+	for i, event := range events {
+		summaries[i] = Summary{OrgEvent: event}
+		if event.FormID.Valid {
+			summaries[i].Registrations = registrations[event.FormID.Bytes]
+		}
+	}
+	return summaries, nil
+}
+
+func capacity(value *int32) pgtype.Int4 {
+	if value == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *value, Valid: true}
+}
@@ -0,0 +1,53 @@
+package finance
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func formatTime(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+func buildCSV(rows []ListForExportRow) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	err := writer.Write([]string{"request_id", "created_at", "decided_at", "unit", "title", "category", "amount", "currency", "status", "requested_by", "requested_by_username"})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		err = writer.Write([]string{
+			row.ID.String(),
+			formatTime(row.CreatedAt),
+			formatTime(row.DecidedAt),
+			row.UnitName.String,
+			row.Title,
+			row.Category,
+			strconv.FormatInt(int64(row.Amount), 10),
+			row.Currency,
+			string(row.Status),
+			row.RequestedByName.String,
+			row.RequestedByUsername.String,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,759 @@
+package finance
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// multipartMemory is how much of a request is kept in memory before spilling to disk
+	multipartMemory = 32 << 20
+
+	// multipartOverhead leaves room for the multipart boundaries and headers
+	multipartOverhead = 1 << 20
+)
+
+type Store interface {
+	Chain(ctx context.Context, orgID uuid.UUID) ([]BudgetApprovalStep, error)
+	SetChain(ctx context.Context, orgID uuid.UUID, chain []ChainStep, userID uuid.UUID) ([]BudgetApprovalStep, error)
+	Create(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, input Input, userID uuid.UUID) (Detail, error)
+	ListByUnit(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, status BudgetRequestStatus, userID uuid.UUID) ([]BudgetRequest, error)
+	ListQueue(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]BudgetRequest, error)
+	Get(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Detail, error)
+	Approve(ctx context.Context, orgID uuid.UUID, id uuid.UUID, comment string, userID uuid.UUID) (Detail, error)
+	Reject(ctx context.Context, orgID uuid.UUID, id uuid.UUID, comment string, userID uuid.UUID) (Detail, error)
+	Withdraw(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Detail, error)
+	Comment(ctx context.Context, orgID uuid.UUID, id uuid.UUID, body string, userID uuid.UUID) (BudgetRequestComment, error)
+	Attach(ctx context.Context, orgID uuid.UUID, id uuid.UUID, file File, userID uuid.UUID) (BudgetRequestAttachment, error)
+	GetAttachment(ctx context.Context, orgID uuid.UUID, id uuid.UUID, attachmentID uuid.UUID, userID uuid.UUID) (BudgetRequestAttachment, string, error)
+	Export(ctx context.Context, orgID uuid.UUID, filter ExportFilter, userID uuid.UUID) ([]byte, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+type ChainStepRequest struct {
+	Name           string `json:"name" validate:"required,max=100"`
+	ApproverUnitID string `json:"approverUnitId" validate:"omitempty,uuid"`
+}
+
+type ChainRequest struct {
+	Steps []ChainStepRequest `json:"steps" validate:"max=10,dive"`
+}
+
+type ChainStepResponse struct {
+	Position       int32   `json:"position"`
+	Name           string  `json:"name"`
+	ApproverUnitID *string `json:"approverUnitId,omitempty"`
+}
+
+type Request struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description" validate:"max=10000"`
+	Category    string `json:"category" validate:"required,max=100"`
+	Amount      int32  `json:"amount" validate:"required,min=1"`
+	Currency    string `json:"currency" validate:"omitempty,len=3"`
+}
+
+type DecideRequest struct {
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+type CommentRequest struct {
+	Body string `json:"body" validate:"required,max=5000"`
+}
+
+type Response struct {
+	ID          string     `json:"id"`
+	UnitID      string     `json:"unitId"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Category    string     `json:"category"`
+	Amount      int32      `json:"amount"`
+	Currency    string     `json:"currency"`
+	Status      string     `json:"status"`
+	CurrentStep int32      `json:"currentStep"`
+	RequestedBy *string    `json:"requestedBy,omitempty"`
+	DecidedAt   *time.Time `json:"decidedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+type StepResponse struct {
+	Position       int32      `json:"position"`
+	Name           string     `json:"name"`
+	ApproverUnitID string     `json:"approverUnitId"`
+	Status         string     `json:"status"`
+	Comment        *string    `json:"comment,omitempty"`
+	DecidedBy      *string    `json:"decidedBy,omitempty"`
+	DecidedAt      *time.Time `json:"decidedAt,omitempty"`
+}
+
+type CommentResponse struct {
+	ID        string    `json:"id"`
+	AuthorID  *string   `json:"authorId,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AttachmentResponse struct {
+	ID          string    `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	UploadedBy  *string   `json:"uploadedBy,omitempty"`
+	DownloadURL string    `json:"downloadUrl,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type DetailResponse struct {
+	Response
+	Steps       []StepResponse       `json:"steps"`
+	Comments    []CommentResponse    `json:"comments"`
+	Attachments []AttachmentResponse `json:"attachments"`
+}
+
+func optionalID(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	value := uuid.UUID(id.Bytes).String()
+	return &value
+}
+
+func optionalTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func ToChainResponse(steps []BudgetApprovalStep) []ChainStepResponse {
+	response := make([]ChainStepResponse, len(steps))
+	for i, step := range steps {
+		response[i] = ChainStepResponse{
+			Position:       step.Position,
+			Name:           step.Name,
+			ApproverUnitID: optionalID(step.ApproverUnitID),
+		}
+	}
+	return response
+}
+
+func ToResponse(request BudgetRequest) Response {
+	return Response{
+		ID:          request.ID.String(),
+		UnitID:      request.UnitID.String(),
+		Title:       request.Title,
+		Description: request.Description,
+		Category:    request.Category,
+		Amount:      request.Amount,
+		Currency:    request.Currency,
+		Status:      string(request.Status),
+		CurrentStep: request.CurrentStep,
+		RequestedBy: optionalID(request.RequestedBy),
+		DecidedAt:   optionalTime(request.DecidedAt),
+		CreatedAt:   request.CreatedAt.Time,
+		UpdatedAt:   request.UpdatedAt.Time,
+	}
+}
+
+func ToCommentResponse(comment BudgetRequestComment) CommentResponse {
+	return CommentResponse{
+		ID:        comment.ID.String(),
+		AuthorID:  optionalID(comment.AuthorID),
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt.Time,
+	}
+}
+
+func ToAttachmentResponse(attachment BudgetRequestAttachment, downloadURL string) AttachmentResponse {
+	return AttachmentResponse{
+		ID:          attachment.ID.String(),
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		UploadedBy:  optionalID(attachment.UploadedBy),
+		DownloadURL: downloadURL,
+		CreatedAt:   attachment.CreatedAt.Time,
+	}
+}
+
+func ToDetailResponse(detail Detail) DetailResponse {
+	steps := make([]StepResponse, len(detail.Steps))
+	for i, step := range detail.Steps {
+		steps[i] = StepResponse{
+			Position:       step.Position,
+			Name:           step.Name,
+			ApproverUnitID: step.ApproverUnitID.String(),
+			Status:         string(step.Status),
+			DecidedBy:      optionalID(step.DecidedBy),
+			DecidedAt:      optionalTime(step.DecidedAt),
+		}
+		if step.Comment.Valid {
+			steps[i].Comment = &step.Comment.String
+		}
+	}
+
+	comments := make([]CommentResponse, len(detail.Comments))
+	for i, comment := range detail.Comments {
+		comments[i] = ToCommentResponse(comment)
+	}
+
+	attachments := make([]AttachmentResponse, len(detail.Attachments))
+	for i, attachment := range detail.Attachments {
+		attachments[i] = ToAttachmentResponse(attachment, "")
+	}
+
+	return DetailResponse{
+		Response:    ToResponse(detail.BudgetRequest),
+		Steps:       steps,
+		Comments:    comments,
+		Attachments: attachments,
+	}
+}
+
+// ToChain converts the request; the unit IDs are expected to have passed validation
+func (r ChainRequest) ToChain() []ChainStep {
+	chain := make([]ChainStep, len(r.Steps))
+	for i, step := range r.Steps {
+		chain[i] = ChainStep{Name: strings.TrimSpace(step.Name)}
+		if step.ApproverUnitID != "" {
+			chain[i].ApproverUnitID = uuid.MustParse(step.ApproverUnitID)
+		}
+	}
+	return chain
+}
+
+func (r Request) ToInput() Input {
+	currency := strings.ToUpper(r.Currency)
+	if currency == "" {
+		currency = "TWD"
+	}
+
+	return Input{
+		Title:       strings.TrimSpace(r.Title),
+		Description: r.Description,
+		Category:    strings.TrimSpace(r.Category),
+		Amount:      r.Amount,
+		Currency:    currency,
+	}
+}
+
+func parseStatus(r *http.Request) (BudgetRequestStatus, error) {
+	status := BudgetRequestStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", BudgetRequestStatusPending, BudgetRequestStatusApproved, BudgetRequestStatusRejected, BudgetRequestStatusWithdrawn:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: status must be one of pending, approved, rejected and withdrawn", internal.ErrInvalidQueryParameter)
+	}
+}
+
+// ParseExportFilter reads the status, from and before query parameters, the times in
+// RFC 3339
+func ParseExportFilter(r *http.Request) (ExportFilter, error) {
+	status, err := parseStatus(r)
+	if err != nil {
+		return ExportFilter{}, err
+	}
+
+	filter := ExportFilter{Status: status}
+	for name, target := range map[string]*time.Time{"from": &filter.From, "before": &filter.Before} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return ExportFilter{}, fmt.Errorf("%w: %s must be an RFC 3339 time", internal.ErrInvalidQueryParameter, name)
+		}
+		*target = parsed
+	}
+	return filter, nil
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("finance/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// org resolves the organization of the slug
+func (h *Handler) org(ctx context.Context) (uuid.UUID, error) {
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return orgID, nil
+}
+
+// request resolves the organization of the slug and the budget request in the path
+func (h *Handler) request(ctx context.Context, r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	orgID, err := h.org(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("requestId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return orgID, id, nil
+}
+
+func writeList(w http.ResponseWriter, requests []BudgetRequest) {
+	response := make([]Response, len(requests))
+	for i, request := range requests {
+		response[i] = ToResponse(request)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetChainHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetChainHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	steps, err := h.store.Chain(traceCtx, orgID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToChainResponse(steps))
+}
+
+func (h *Handler) SetChainHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetChainHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ChainRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	steps, err := h.store.SetChain(traceCtx, orgID, req.ToChain(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToChainResponse(steps))
+}
+
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	detail, err := h.store.Create(traceCtx, orgID, unitID, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToDetailResponse(detail))
+}
+
+// ListByUnitHandler lists the budget requests of the unit, of one status when the
+// status query parameter is given
+func (h *Handler) ListByUnitHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListByUnitHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	status, err := parseStatus(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	requests, err := h.store.ListByUnit(traceCtx, orgID, unitID, status, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	writeList(w, requests)
+}
+
+func (h *Handler) ListQueueHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListQueueHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	requests, err := h.store.ListQueue(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	writeList(w, requests)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.request(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	detail, err := h.store.Get(traceCtx, orgID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToDetailResponse(detail))
+}
+
+func (h *Handler) ApproveHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, "ApproveHandler", h.store.Approve)
+}
+
+func (h *Handler) RejectHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, "RejectHandler", h.store.Reject)
+}
+
+func (h *Handler) decide(w http.ResponseWriter, r *http.Request, name string, decide func(ctx context.Context, orgID uuid.UUID, id uuid.UUID, comment string, userID uuid.UUID) (Detail, error)) {
+	traceCtx, span := h.tracer.Start(r.Context(), name)
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.request(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req DecideRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	detail, err := decide(traceCtx, orgID, id, req.Comment, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToDetailResponse(detail))
+}
+
+func (h *Handler) WithdrawHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "WithdrawHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.request(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	detail, err := h.store.Withdraw(traceCtx, orgID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToDetailResponse(detail))
+}
+
+func (h *Handler) CommentHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CommentHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.request(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req CommentRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	comment, err := h.store.Comment(traceCtx, orgID, id, strings.TrimSpace(req.Body), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToCommentResponse(comment))
+}
+
+// AttachHandler stores the file of the multipart form field named file
+func (h *Handler) AttachHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "AttachHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.request(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxAttachmentSize+multipartOverhead)
+	err = r.ParseMultipartForm(multipartMemory)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %w", internal.ErrBudgetAttachmentInvalid, err), logger)
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %w", internal.ErrBudgetAttachmentInvalid, err), logger)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	attachment, err := h.store.Attach(traceCtx, orgID, id, File{
+		Name:        header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		Body:        file,
+	}, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToAttachmentResponse(attachment, ""))
+}
+
+func (h *Handler) GetAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetAttachmentHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.request(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	attachmentID, err := internal.ParseUUID(r.PathValue("attachmentId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	attachment, downloadURL, err := h.store.GetAttachment(traceCtx, orgID, id, attachmentID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToAttachmentResponse(attachment, downloadURL))
+}
+
+// ExportHandler serves the budget requests of the organization as a CSV file for accounting
+func (h *Handler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ExportHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	filter, err := ParseExportFilter(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	content, err := h.store.Export(traceCtx, orgID, filter, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "budget-requests-"+orgID.String()+".csv"))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(content)
+	if err != nil {
+		logger.Error("failed to write budget request export", zap.Error(err))
+	}
+}
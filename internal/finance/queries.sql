@@ -0,0 +1,140 @@
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: GetMembership :one
+SELECT EXISTS(
+    SELECT 1 FROM unit_members
+    WHERE unit_id = u.id AND member_id = @user_id
+) AS is_member
+FROM units u
+WHERE u.id = @unit_id AND (u.id = @org_id OR u.org_id = @org_id);
+
+-- name: CountUnitsInOrg :one
+SELECT COUNT(*) FROM units
+WHERE id = ANY(@unit_ids::uuid[]) AND (id = @org_id OR org_id = @org_id);
+
+-- name: IsParticipant :one
+-- Members of the requesting unit and of every unit on the chain of the request
+SELECT EXISTS(
+    SELECT 1 FROM unit_members um
+    WHERE um.member_id = @user_id
+      AND (
+          um.unit_id = @unit_id
+          OR um.unit_id IN (SELECT approver_unit_id FROM budget_request_steps WHERE request_id = @request_id)
+      )
+);
+
+-- name: IsUnitMember :one
+SELECT EXISTS(SELECT 1 FROM unit_members WHERE unit_id = @unit_id AND member_id = @user_id);
+
+-- name: ListChain :many
+SELECT * FROM budget_approval_steps
+WHERE org_id = @org_id
+ORDER BY position ASC;
+
+-- name: DeleteChain :exec
+DELETE FROM budget_approval_steps
+WHERE org_id = @org_id;
+
+-- name: CreateChainStep :one
+INSERT INTO budget_approval_steps (org_id, position, name, approver_unit_id)
+VALUES (@org_id, @position, @name, @approver_unit_id)
+RETURNING *;
+
+-- name: Create :one
+INSERT INTO budget_requests (org_id, unit_id, title, description, category, amount, currency, requested_by)
+VALUES (@org_id, @unit_id, @title, @description, @category, @amount, @currency, @requested_by)
+RETURNING *;
+
+-- name: CreateStep :exec
+INSERT INTO budget_request_steps (request_id, position, name, approver_unit_id)
+VALUES (@request_id, @position, @name, @approver_unit_id);
+
+-- name: GetByID :one
+SELECT * FROM budget_requests
+WHERE id = @id AND org_id = @org_id;
+
+-- name: GetByIDForUpdate :one
+SELECT * FROM budget_requests
+WHERE id = @id AND org_id = @org_id
+FOR UPDATE;
+
+-- name: ListByUnit :many
+SELECT * FROM budget_requests
+WHERE unit_id = @unit_id
+  AND (sqlc.narg(status)::budget_request_status IS NULL OR status = sqlc.narg(status))
+ORDER BY created_at DESC;
+
+-- name: ListQueue :many
+-- Pending requests of the organization whose current step is decided by a unit of the user
+SELECT r.* FROM budget_requests r
+JOIN budget_request_steps s ON s.request_id = r.id AND s.position = r.current_step
+WHERE r.org_id = @org_id
+  AND r.status = 'pending'
+  AND s.approver_unit_id IN (SELECT unit_id FROM unit_members WHERE member_id = @user_id)
+ORDER BY r.created_at ASC;
+
+-- name: ListForExport :many
+SELECT r.id, r.created_at, r.decided_at, u.name AS unit_name, r.title, r.category, r.amount, r.currency, r.status,
+       req.name AS requested_by_name, req.username AS requested_by_username
+FROM budget_requests r
+JOIN units u ON u.id = r.unit_id
+LEFT JOIN users req ON req.id = r.requested_by
+WHERE r.org_id = @org_id
+  AND (sqlc.narg(status)::budget_request_status IS NULL OR r.status = sqlc.narg(status))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR r.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_before)::timestamptz IS NULL OR r.created_at < sqlc.narg(created_before))
+ORDER BY r.created_at ASC;
+
+-- name: Advance :one
+UPDATE budget_requests
+SET current_step = current_step + 1,
+    updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: SetStatus :one
+UPDATE budget_requests
+SET status = @status,
+    decided_at = CASE WHEN @status::budget_request_status IN ('approved', 'rejected') THEN now() ELSE decided_at END,
+    updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: ListSteps :many
+SELECT * FROM budget_request_steps
+WHERE request_id = @request_id
+ORDER BY position ASC;
+
+-- name: DecideStep :one
+UPDATE budget_request_steps
+SET status = @status,
+    comment = @comment,
+    decided_by = @decided_by,
+    decided_at = now()
+WHERE request_id = @request_id AND position = @position AND status = 'pending'
+RETURNING *;
+
+-- name: CreateComment :one
+INSERT INTO budget_request_comments (request_id, author_id, body)
+VALUES (@request_id, @author_id, @body)
+RETURNING *;
+
+-- name: ListComments :many
+SELECT * FROM budget_request_comments
+WHERE request_id = @request_id
+ORDER BY created_at ASC;
+
+-- name: CreateAttachment :one
+INSERT INTO budget_request_attachments (request_id, filename, content_type, size, uploaded_by)
+VALUES (@request_id, @filename, @content_type, @size, @uploaded_by)
+RETURNING *;
+
+-- name: ListAttachments :many
+SELECT * FROM budget_request_attachments
+WHERE request_id = @request_id
+ORDER BY created_at ASC;
+
+-- name: GetAttachment :one
+SELECT * FROM budget_request_attachments
+WHERE id = @id AND request_id = @request_id;
\ No newline at end of file
@@ -0,0 +1,22 @@
+package finance
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the budget requests of the units of an organization and the chain approving them
+func Routes(r route.Router, h *Handler, limits route.BodyLimits) {
+	r.Handle("GET /orgs/{slug}/budget-chain", route.TenantAuthenticated, route.PermissionNone, h.GetChainHandler)
+	r.Handle("PUT /orgs/{slug}/budget-chain", route.TenantAuthenticated, route.PermissionOrgAdmin, h.SetChainHandler)
+	r.Handle("POST /orgs/{slug}/units/{id}/budget-requests", route.TenantAuthenticated, route.PermissionUnitMember, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/units/{id}/budget-requests", route.TenantAuthenticated, route.PermissionUnitMember, h.ListByUnitHandler)
+	r.Handle("GET /orgs/{slug}/budget-requests/queue", route.TenantAuthenticated, route.PermissionNone, h.ListQueueHandler)
+	r.Handle("GET /orgs/{slug}/budget-requests/export", route.TenantAuthenticated, route.PermissionOrgAdmin, h.ExportHandler)
+	r.Handle("GET /orgs/{slug}/budget-requests/{requestId}", route.TenantAuthenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("POST /orgs/{slug}/budget-requests/{requestId}/approve", route.TenantAuthenticated, route.PermissionUnitMember, h.ApproveHandler)
+	r.Handle("POST /orgs/{slug}/budget-requests/{requestId}/reject", route.TenantAuthenticated, route.PermissionUnitMember, h.RejectHandler)
+	r.Handle("POST /orgs/{slug}/budget-requests/{requestId}/withdraw", route.TenantAuthenticated, route.PermissionUnitMember, h.WithdrawHandler)
+	r.Handle("POST /orgs/{slug}/budget-requests/{requestId}/comments", route.TenantAuthenticated, route.PermissionNone, h.CommentHandler)
+	r.Handle("POST /orgs/{slug}/budget-requests/{requestId}/attachments", route.TenantAuthenticated, route.PermissionUnitMember, h.AttachHandler).WithBodyLimit(limits.Upload)
+	r.Handle("GET /orgs/{slug}/budget-requests/{requestId}/attachments/{attachmentId}", route.TenantAuthenticated, route.PermissionNone, h.GetAttachmentHandler)
+}
@@ -0,0 +1,74 @@
+CREATE TYPE budget_request_status AS ENUM(
+    'pending',
+    'approved',
+    'rejected',
+    'withdrawn'
+);
+
+-- The approval chain of an organization. A step without an approver unit is decided
+-- by the unit making the request, such as its lead.
+CREATE TABLE IF NOT EXISTS budget_approval_steps (
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    position INT NOT NULL CHECK (position >= 0),
+    name VARCHAR(100) NOT NULL,
+    approver_unit_id UUID REFERENCES units(id) ON DELETE CASCADE,
+    PRIMARY KEY (org_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS budget_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category VARCHAR(100) NOT NULL,
+    amount INT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL DEFAULT 'TWD',
+    status budget_request_status NOT NULL DEFAULT 'pending',
+    current_step INT NOT NULL DEFAULT 0,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_requests_org_id ON budget_requests(org_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_budget_requests_unit_id ON budget_requests(unit_id, created_at);
+
+-- The chain of the organization as it was when the request was made, each step resolved
+-- to the unit deciding it
+CREATE TABLE IF NOT EXISTS budget_request_steps (
+    request_id UUID NOT NULL REFERENCES budget_requests(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    approver_unit_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    status approval_status NOT NULL DEFAULT 'pending',
+    comment TEXT DEFAULT NULL,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ DEFAULT NULL,
+    PRIMARY KEY (request_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_request_steps_approver ON budget_request_steps(approver_unit_id) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS budget_request_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id UUID NOT NULL REFERENCES budget_requests(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_request_comments_request_id ON budget_request_comments(request_id, created_at);
+
+CREATE TABLE IF NOT EXISTS budget_request_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id UUID NOT NULL REFERENCES budget_requests(id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_request_attachments_request_id ON budget_request_attachments(request_id);
\ No newline at end of file
@@ -0,0 +1,761 @@
+package finance
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// MaxAttachmentSize is the largest file that can be attached to a budget request
+const MaxAttachmentSize = 20 << 20
+
+type Querier interface {
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	GetMembership(ctx context.Context, arg GetMembershipParams) (bool, error)
+	CountUnitsInOrg(ctx context.Context, arg CountUnitsInOrgParams) (int64, error)
+	IsParticipant(ctx context.Context, arg IsParticipantParams) (bool, error)
+	IsUnitMember(ctx context.Context, arg IsUnitMemberParams) (bool, error)
+	ListChain(ctx context.Context, orgID uuid.UUID) ([]BudgetApprovalStep, error)
+	DeleteChain(ctx context.Context, orgID uuid.UUID) error
+	CreateChainStep(ctx context.Context, arg CreateChainStepParams) (BudgetApprovalStep, error)
+	Create(ctx context.Context, arg CreateParams) (BudgetRequest, error)
+	CreateStep(ctx context.Context, arg CreateStepParams) error
+	GetByID(ctx context.Context, arg GetByIDParams) (BudgetRequest, error)
+	GetByIDForUpdate(ctx context.Context, arg GetByIDForUpdateParams) (BudgetRequest, error)
+	ListByUnit(ctx context.Context, arg ListByUnitParams) ([]BudgetRequest, error)
+	ListQueue(ctx context.Context, arg ListQueueParams) ([]BudgetRequest, error)
+	ListForExport(ctx context.Context, arg ListForExportParams) ([]ListForExportRow, error)
+	Advance(ctx context.Context, id uuid.UUID) (BudgetRequest, error)
+	SetStatus(ctx context.Context, arg SetStatusParams) (BudgetRequest, error)
+	ListSteps(ctx context.Context, requestID uuid.UUID) ([]BudgetRequestStep, error)
+	DecideStep(ctx context.Context, arg DecideStepParams) (BudgetRequestStep, error)
+	CreateComment(ctx context.Context, arg CreateCommentParams) (BudgetRequestComment, error)
+	ListComments(ctx context.Context, requestID uuid.UUID) ([]BudgetRequestComment, error)
+	CreateAttachment(ctx context.Context, arg CreateAttachmentParams) (BudgetRequestAttachment, error)
+	ListAttachments(ctx context.Context, requestID uuid.UUID) ([]BudgetRequestAttachment, error)
+	GetAttachment(ctx context.Context, arg GetAttachmentParams) (BudgetRequestAttachment, error)
+}
+
+// DB is the connection the service runs on; a request and its steps are written, and
+// decided, in transactions begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type FileStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	PresignGet(ctx context.Context, key string) (string, error)
+}
+
+// ChainStep is a step of the approval chain of an organization. ApproverUnitID is
+// uuid.Nil for a step decided by the unit making the request.
+type ChainStep struct {
+	Name           string
+	ApproverUnitID uuid.UUID
+}
+
+// Input describes a budget request; the amount is in the smallest unit of the currency
+type Input struct {
+	Title       string
+	Description string
+	Category    string
+	Amount      int32
+	Currency    string
+}
+
+// File is an attachment as received from the client
+type File struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
+
+// ExportFilter narrows the exported requests; zero fields match every request
+type ExportFilter struct {
+	Status BudgetRequestStatus
+	From   time.Time
+	Before time.Time
+}
+
+// Detail is a budget request with its steps, the current one being the first pending,
+// and the discussion and files attached to it
+type Detail struct {
+	BudgetRequest
+	Steps       []BudgetRequestStep
+	Comments    []BudgetRequestComment
+	Attachments []BudgetRequestAttachment
+}
+
+type Service struct {
+	logger    *zap.Logger
+	db        DB
+	queries   Querier
+	tracer    trace.Tracer
+	fileStore FileStore
+}
+
+func NewService(logger *zap.Logger, db DB, fileStore FileStore) *Service {
+	return &Service{
+		logger:    logger,
+		db:        db,
+		queries:   New(db),
+		tracer:    otel.Tracer("finance/service"),
+		fileStore: fileStore,
+	}
+}
+
+func attachmentKey(id uuid.UUID) string {
+	return "budget-attachments/" + id.String()
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+func (s *Service) isOrgAdmin(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) (bool, error) {
+	isAdmin, err := s.queries.IsOrgAdmin(ctx, IsOrgAdminParams{OrgID: orgID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		return false, databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check organization admin")
+	}
+	return isAdmin, nil
+}
+
+func (s *Service) requireOrgAdmin(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.isOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+// requireMember allows the members of the unit only. A unit outside the organization
+// is not found.
+func (s *Service) requireMember(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID) error {
+	isMember, err := s.queries.GetMembership(ctx, GetMembershipParams{
+		UserID: userID,
+		UnitID: unitID,
+		OrgID:  orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return internal.ErrUnitNotFound
+		}
+		return databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", unitID.String(), logger, "check unit membership")
+	}
+	if !isMember {
+		return fmt.Errorf("%w: only members of unit %s can manage its budget requests", internal.ErrPermissionDenied, unitID)
+	}
+	return nil
+}
+
+// requireParticipant allows the admins of the organization, the members of the
+// requesting unit and the members of the units on the chain of the request
+func (s *Service) requireParticipant(ctx context.Context, logger *zap.Logger, request BudgetRequest, userID uuid.UUID) error {
+	isParticipant, err := s.queries.IsParticipant(ctx, IsParticipantParams{
+		UserID:    userID,
+		UnitID:    request.UnitID,
+		RequestID: request.ID,
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "id", request.ID.String(), logger, "check budget request participant")
+	}
+	if isParticipant {
+		return nil
+	}
+
+	isAdmin, err := s.isOrgAdmin(ctx, logger, request.OrgID, userID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return fmt.Errorf("%w: user is not part of budget request %s", internal.ErrPermissionDenied, request.ID)
+	}
+	return nil
+}
+
+func (s *Service) get(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, id uuid.UUID) (BudgetRequest, error) {
+	request, err := s.queries.GetByID(ctx, GetByIDParams{ID: id, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return BudgetRequest{}, internal.ErrBudgetRequestNotFound
+		}
+		return BudgetRequest{}, databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "id", id.String(), logger, "get budget request")
+	}
+	return request, nil
+}
+
+// Chain returns the approval chain of the organization, in the order its steps are decided
+func (s *Service) Chain(ctx context.Context, orgID uuid.UUID) ([]BudgetApprovalStep, error) {
+	ctx, span := s.tracer.Start(ctx, "Chain")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	steps, err := s.queries.ListChain(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "budget_approval_steps", "org_id", orgID.String(), logger, "list budget approval chain")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return steps, nil
+}
+
+// SetChain replaces the approval chain of the organization. Requests made already keep
+// the chain they were made with.
+func (s *Service) SetChain(ctx context.Context, orgID uuid.UUID, chain []ChainStep, userID uuid.UUID) ([]BudgetApprovalStep, error) {
+	ctx, span := s.tracer.Start(ctx, "SetChain")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	unitIDs := make([]uuid.UUID, 0, len(chain))
+	seen := make(map[uuid.UUID]struct{}, len(chain))
+	for _, step := range chain {
+		if step.ApproverUnitID == uuid.Nil {
+			continue
+		}
+		if _, ok := seen[step.ApproverUnitID]; ok {
+			continue
+		}
+		seen[step.ApproverUnitID] = struct{}{}
+		unitIDs = append(unitIDs, step.ApproverUnitID)
+	}
+	if len(unitIDs) > 0 {
+		count, err := s.queries.CountUnitsInOrg(ctx, CountUnitsInOrgParams{UnitIds: unitIDs, OrgID: orgID})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "units", "org_id", orgID.String(), logger, "count approver units")
+			span.RecordError(err)
+			return nil, err
+		}
+		if count != int64(len(unitIDs)) {
+			err = fmt.Errorf("%w: %d approver units are not in the organization", internal.ErrBudgetChainInvalid, int64(len(unitIDs))-count)
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	steps := make([]BudgetApprovalStep, 0, len(chain))
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		err := queries.DeleteChain(ctx, orgID)
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "budget_approval_steps", "org_id", orgID.String(), logger, "delete budget approval chain")
+		}
+
+		for i, step := range chain {
+			created, err := queries.CreateChainStep(ctx, CreateChainStepParams{
+				OrgID:          orgID,
+				Position:       int32(i),
+				Name:           step.Name,
+				ApproverUnitID: pgtype.UUID{Bytes: step.ApproverUnitID, Valid: step.ApproverUnitID != uuid.Nil},
+			})
+			if err != nil {
+				return databaseutil.WrapDBErrorWithKeyValue(err, "budget_approval_steps", "org_id", orgID.String(), logger, "create budget approval step")
+			}
+			steps = append(steps, created)
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	logger.Info("Set budget approval chain", zap.String("org_id", orgID.String()), zap.Int("steps", len(steps)))
+
+	return steps, nil
+}
+
+// Create submits a budget request of the unit along the current approval chain of the
+// organization, which is copied onto the request
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, input Input, userID uuid.UUID) (Detail, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	chain, err := s.queries.ListChain(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "budget_approval_steps", "org_id", orgID.String(), logger, "list budget approval chain")
+		span.RecordError(err)
+		return Detail{}, err
+	}
+	if len(chain) == 0 {
+		err = internal.ErrBudgetChainNotConfigured
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	var request BudgetRequest
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		request, err = queries.Create(ctx, CreateParams{
+			OrgID:       orgID,
+			UnitID:      unitID,
+			Title:       input.Title,
+			Description: input.Description,
+			Category:    input.Category,
+			Amount:      input.Amount,
+			Currency:    input.Currency,
+			RequestedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "unit_id", unitID.String(), logger, "create budget request")
+		}
+
+		for i, step := range chain {
+			approverUnitID := unitID
+			if step.ApproverUnitID.Valid {
+				approverUnitID = step.ApproverUnitID.Bytes
+			}
+			err = queries.CreateStep(ctx, CreateStepParams{
+				RequestID:      request.ID,
+				Position:       int32(i),
+				Name:           step.Name,
+				ApproverUnitID: approverUnitID,
+			})
+			if err != nil {
+				return databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_steps", "request_id", request.ID.String(), logger, "create budget request step")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	logger.Info("Created budget request",
+		zap.String("request_id", request.ID.String()),
+		zap.String("unit_id", unitID.String()),
+		zap.Int32("amount", input.Amount))
+
+	return s.detail(ctx, logger, request)
+}
+
+// ListByUnit returns the budget requests of the unit, the latest first, narrowed to one
+// status when it is not empty
+func (s *Service) ListByUnit(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, status BudgetRequestStatus, userID uuid.UUID) ([]BudgetRequest, error) {
+	ctx, span := s.tracer.Start(ctx, "ListByUnit")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	requests, err := s.queries.ListByUnit(ctx, ListByUnitParams{
+		UnitID: unitID,
+		Status: NullBudgetRequestStatus{BudgetRequestStatus: status, Valid: status != ""},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "unit_id", unitID.String(), logger, "list budget requests")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// ListQueue returns the pending requests of the organization waiting on a step decided
+// by a unit the user is a member of, the oldest first
+func (s *Service) ListQueue(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]BudgetRequest, error) {
+	ctx, span := s.tracer.Start(ctx, "ListQueue")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	requests, err := s.queries.ListQueue(ctx, ListQueueParams{OrgID: orgID, UserID: userID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "org_id", orgID.String(), logger, "list budget request queue")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Detail, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	request, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	err = s.requireParticipant(ctx, logger, request, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	detail, err := s.detail(ctx, logger, request)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	return detail, nil
+}
+
+// Approve passes the current step of the request, approving the request once its last
+// step is passed
+func (s *Service) Approve(ctx context.Context, orgID uuid.UUID, id uuid.UUID, comment string, userID uuid.UUID) (Detail, error) {
+	return s.decide(ctx, orgID, id, ApprovalStatusApproved, comment, userID)
+}
+
+// Reject stops the request at its current step, rejecting it
+func (s *Service) Reject(ctx context.Context, orgID uuid.UUID, id uuid.UUID, comment string, userID uuid.UUID) (Detail, error) {
+	return s.decide(ctx, orgID, id, ApprovalStatusRejected, comment, userID)
+}
+
+// decide records the decision of a member of the unit deciding the current step of a
+// pending request. Whoever made the request cannot decide on it.
+func (s *Service) decide(ctx context.Context, orgID uuid.UUID, id uuid.UUID, status ApprovalStatus, comment string, userID uuid.UUID) (Detail, error) {
+	ctx, span := s.tracer.Start(ctx, "decide")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	var request BudgetRequest
+	err := s.inTx(ctx, logger, func(queries Querier) error {
+		var err error
+		request, err = queries.GetByIDForUpdate(ctx, GetByIDForUpdateParams{ID: id, OrgID: orgID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return internal.ErrBudgetRequestNotFound
+			}
+			return databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "id", id.String(), logger, "lock budget request")
+		}
+		if request.Status != BudgetRequestStatusPending {
+			return internal.ErrBudgetRequestClosed
+		}
+		if request.RequestedBy.Valid && request.RequestedBy.Bytes == userID {
+			return fmt.Errorf("%w: budget request %s was made by the user", internal.ErrPermissionDenied, id)
+		}
+
+		steps, err := queries.ListSteps(ctx, id)
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_steps", "request_id", id.String(), logger, "list budget request steps")
+		}
+		if int(request.CurrentStep) >= len(steps) {
+			return internal.ErrBudgetRequestClosed
+		}
+		step := steps[request.CurrentStep]
+
+		isApprover, err := queries.IsUnitMember(ctx, IsUnitMemberParams{UnitID: step.ApproverUnitID, UserID: userID})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", step.ApproverUnitID.String(), logger, "check approver unit membership")
+		}
+		if !isApprover {
+			return fmt.Errorf("%w: only members of unit %s can decide step %q", internal.ErrPermissionDenied, step.ApproverUnitID, step.Name)
+		}
+
+		_, err = queries.DecideStep(ctx, DecideStepParams{
+			Status:    status,
+			Comment:   pgtype.Text{String: comment, Valid: comment != ""},
+			DecidedBy: pgtype.UUID{Bytes: userID, Valid: true},
+			RequestID: id,
+			Position:  step.Position,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return internal.ErrBudgetRequestClosed
+			}
+			return databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_steps", "request_id", id.String(), logger, "decide budget request step")
+		}
+
+		switch {
+		case status == ApprovalStatusRejected:
+			request, err = queries.SetStatus(ctx, SetStatusParams{Status: BudgetRequestStatusRejected, ID: id})
+		case int(request.CurrentStep) == len(steps)-1:
+			request, err = queries.SetStatus(ctx, SetStatusParams{Status: BudgetRequestStatusApproved, ID: id})
+		default:
+			request, err = queries.Advance(ctx, id)
+		}
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "id", id.String(), logger, "update budget request")
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	logger.Info("Decided budget request step",
+		zap.String("request_id", id.String()),
+		zap.String("decision", string(status)),
+		zap.String("status", string(request.Status)))
+
+	return s.detail(ctx, logger, request)
+}
+
+// Withdraw lets the requesting unit take back a request that is still pending
+func (s *Service) Withdraw(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Detail, error) {
+	ctx, span := s.tracer.Start(ctx, "Withdraw")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	var request BudgetRequest
+	err := s.inTx(ctx, logger, func(queries Querier) error {
+		var err error
+		request, err = queries.GetByIDForUpdate(ctx, GetByIDForUpdateParams{ID: id, OrgID: orgID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return internal.ErrBudgetRequestNotFound
+			}
+			return databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "id", id.String(), logger, "lock budget request")
+		}
+
+		err = s.requireMember(ctx, logger, orgID, request.UnitID, userID)
+		if err != nil {
+			return err
+		}
+		if request.Status != BudgetRequestStatusPending {
+			return internal.ErrBudgetRequestClosed
+		}
+
+		request, err = queries.SetStatus(ctx, SetStatusParams{Status: BudgetRequestStatusWithdrawn, ID: id})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "id", id.String(), logger, "withdraw budget request")
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	logger.Info("Withdrew budget request", zap.String("request_id", id.String()))
+
+	return s.detail(ctx, logger, request)
+}
+
+// Comment adds a comment to the discussion of the request, open to everyone taking
+// part in it whatever its status
+func (s *Service) Comment(ctx context.Context, orgID uuid.UUID, id uuid.UUID, body string, userID uuid.UUID) (BudgetRequestComment, error) {
+	ctx, span := s.tracer.Start(ctx, "Comment")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	request, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return BudgetRequestComment{}, err
+	}
+
+	err = s.requireParticipant(ctx, logger, request, userID)
+	if err != nil {
+		span.RecordError(err)
+		return BudgetRequestComment{}, err
+	}
+
+	comment, err := s.queries.CreateComment(ctx, CreateCommentParams{
+		RequestID: id,
+		AuthorID:  pgtype.UUID{Bytes: userID, Valid: true},
+		Body:      body,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_comments", "request_id", id.String(), logger, "create budget request comment")
+		span.RecordError(err)
+		return BudgetRequestComment{}, err
+	}
+
+	return comment, nil
+}
+
+// Attach stores a file, such as a quotation or a receipt, with a pending request of
+// the unit
+func (s *Service) Attach(ctx context.Context, orgID uuid.UUID, id uuid.UUID, file File, userID uuid.UUID) (BudgetRequestAttachment, error) {
+	ctx, span := s.tracer.Start(ctx, "Attach")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	request, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, err
+	}
+
+	err = s.requireMember(ctx, logger, orgID, request.UnitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, err
+	}
+	if request.Status != BudgetRequestStatusPending {
+		err = internal.ErrBudgetRequestClosed
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, err
+	}
+	if file.Size > MaxAttachmentSize {
+		err = fmt.Errorf("%w: file exceeds the %d MiB size limit", internal.ErrBudgetAttachmentInvalid, MaxAttachmentSize>>20)
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, err
+	}
+
+	attachment, err := s.queries.CreateAttachment(ctx, CreateAttachmentParams{
+		RequestID:   id,
+		Filename:    file.Name,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		UploadedBy:  pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_attachments", "request_id", id.String(), logger, "create budget request attachment")
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, err
+	}
+
+	err = s.fileStore.Put(ctx, attachmentKey(attachment.ID), file.Body, file.Size, file.ContentType)
+	if err != nil {
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, err
+	}
+
+	logger.Info("Attached file to budget request", zap.String("request_id", id.String()), zap.String("attachment_id", attachment.ID.String()))
+
+	return attachment, nil
+}
+
+// GetAttachment returns an attachment of the request with a presigned download URL
+func (s *Service) GetAttachment(ctx context.Context, orgID uuid.UUID, id uuid.UUID, attachmentID uuid.UUID, userID uuid.UUID) (BudgetRequestAttachment, string, error) {
+	ctx, span := s.tracer.Start(ctx, "GetAttachment")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	request, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, "", err
+	}
+
+	err = s.requireParticipant(ctx, logger, request, userID)
+	if err != nil {
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, "", err
+	}
+
+	attachment, err := s.queries.GetAttachment(ctx, GetAttachmentParams{ID: attachmentID, RequestID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrBudgetAttachmentNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_attachments", "id", attachmentID.String(), logger, "get budget request attachment")
+		}
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, "", err
+	}
+
+	url, err := s.fileStore.PresignGet(ctx, attachmentKey(attachment.ID))
+	if err != nil {
+		span.RecordError(err)
+		return BudgetRequestAttachment{}, "", err
+	}
+
+	return attachment, url, nil
+}
+
+// Export renders the budget requests of the organization matching the filter as CSV
+// for accounting
+func (s *Service) Export(ctx context.Context, orgID uuid.UUID, filter ExportFilter, userID uuid.UUID) ([]byte, error) {
+	ctx, span := s.tracer.Start(ctx, "Export")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	rows, err := s.queries.ListForExport(ctx, ListForExportParams{
+		OrgID:         orgID,
+		Status:        NullBudgetRequestStatus{BudgetRequestStatus: filter.Status, Valid: filter.Status != ""},
+		CreatedFrom:   pgtype.Timestamptz{Time: filter.From, Valid: !filter.From.IsZero()},
+		CreatedBefore: pgtype.Timestamptz{Time: filter.Before, Valid: !filter.Before.IsZero()},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "budget_requests", "org_id", orgID.String(), logger, "list budget requests for export")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	content, err := buildCSV(rows)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return content, nil
+}
+
+func (s *Service) detail(ctx context.Context, logger *zap.Logger, request BudgetRequest) (Detail, error) {
+	steps, err := s.queries.ListSteps(ctx, request.ID)
+	if err != nil {
+		return Detail{}, databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_steps", "request_id", request.ID.String(), logger, "list budget request steps")
+	}
+
+	comments, err := s.queries.ListComments(ctx, request.ID)
+	if err != nil {
+		return Detail{}, databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_comments", "request_id", request.ID.String(), logger, "list budget request comments")
+	}
+
+	attachments, err := s.queries.ListAttachments(ctx, request.ID)
+	if err != nil {
+		return Detail{}, databaseutil.WrapDBErrorWithKeyValue(err, "budget_request_attachments", "request_id", request.ID.String(), logger, "list budget request attachments")
+	}
+
+	return Detail{
+		BudgetRequest: request,
+		Steps:         steps,
+		Comments:      comments,
+		Attachments:   attachments,
+	}, nil
+}
@@ -0,0 +1,382 @@
+package finance_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/finance"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// budgetDB keeps one budget request and its steps in memory, with members listing the
+// users of every unit
+type budgetDB struct {
+	request finance.BudgetRequest
+	steps   []finance.BudgetRequestStep
+	members map[uuid.UUID][]uuid.UUID
+}
+
+func (db *budgetDB) isMember(unitID uuid.UUID, userID uuid.UUID) bool {
+	for _, member := range db.members[unitID] {
+		if member == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func (db *budgetDB) Begin(context.Context) (pgx.Tx, error) {
+	return budgetTx{db: db}, nil
+}
+
+func (db *budgetDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("unexpected exec")
+}
+
+func (db *budgetDB) Query(_ context.Context, sql string, _ ...interface{}) (pgx.Rows, error) {
+	switch {
+	case strings.Contains(sql, "name: ListSteps"):
+		rows := make([]scanRow, len(db.steps))
+		for i, step := range db.steps {
+			rows[i] = stepRow(step)
+		}
+		return &scanRows{rows: rows, next: -1}, nil
+	case strings.Contains(sql, "name: ListComments"), strings.Contains(sql, "name: ListAttachments"):
+		return &scanRows{next: -1}, nil
+	}
+	return nil, errors.New("unexpected query")
+}
+
+func (db *budgetDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	switch {
+	case strings.Contains(sql, "name: GetByIDForUpdate"):
+		if args[0].(uuid.UUID) != db.request.ID || args[1].(uuid.UUID) != db.request.OrgID {
+			return scanRow(func(...any) error { return pgx.ErrNoRows })
+		}
+		return requestRow(db.request)
+	case strings.Contains(sql, "name: GetMembership"):
+		userID, unitID := args[0].(uuid.UUID), args[1].(uuid.UUID)
+		return boolRow(db.isMember(unitID, userID))
+	case strings.Contains(sql, "name: IsUnitMember"):
+		unitID, userID := args[0].(uuid.UUID), args[1].(uuid.UUID)
+		return boolRow(db.isMember(unitID, userID))
+	case strings.Contains(sql, "name: DecideStep"):
+		position := args[4].(int32)
+		for i, step := range db.steps {
+			if step.Position == position && step.Status == finance.ApprovalStatusPending {
+				db.steps[i].Status = args[0].(finance.ApprovalStatus)
+				db.steps[i].DecidedBy = args[2].(pgtype.UUID)
+				return stepRow(db.steps[i])
+			}
+		}
+		return scanRow(func(...any) error { return pgx.ErrNoRows })
+	case strings.Contains(sql, "name: SetStatus"):
+		db.request.Status = args[0].(finance.BudgetRequestStatus)
+		return requestRow(db.request)
+	case strings.Contains(sql, "name: Advance"):
+		db.request.CurrentStep++
+		return requestRow(db.request)
+	}
+	return scanRow(func(...any) error { return errors.New("unexpected query") })
+}
+
+// budgetTx runs the queries of a transaction straight on the request, the tests never
+// roll back a transaction that wrote
+type budgetTx struct {
+	pgx.Tx
+	db *budgetDB
+}
+
+func (tx budgetTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx budgetTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.db.Query(ctx, sql, args...)
+}
+
+func (tx budgetTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.db.QueryRow(ctx, sql, args...)
+}
+
+func (tx budgetTx) Commit(context.Context) error   { return nil }
+func (tx budgetTx) Rollback(context.Context) error { return nil }
+
+type scanRow func(dest ...any) error
+
+func (r scanRow) Scan(dest ...any) error { return r(dest...) }
+
+func boolRow(value bool) scanRow {
+	return func(dest ...any) error {
+		*dest[0].(*bool) = value
+		return nil
+	}
+}
+
+func requestRow(request finance.BudgetRequest) scanRow {
+	return func(dest ...any) error {
+		*dest[0].(*uuid.UUID) = request.ID
+		*dest[1].(*uuid.UUID) = request.OrgID
+		*dest[2].(*uuid.UUID) = request.UnitID
+		*dest[3].(*string) = request.Title
+		*dest[4].(*string) = request.Description
+		*dest[5].(*string) = request.Category
+		*dest[6].(*int32) = request.Amount
+		*dest[7].(*string) = request.Currency
+		*dest[8].(*finance.BudgetRequestStatus) = request.Status
+		*dest[9].(*int32) = request.CurrentStep
+		*dest[10].(*pgtype.UUID) = request.RequestedBy
+		*dest[11].(*pgtype.Timestamptz) = request.DecidedAt
+		*dest[12].(*pgtype.Timestamptz) = request.CreatedAt
+		*dest[13].(*pgtype.Timestamptz) = request.UpdatedAt
+		return nil
+	}
+}
+
+func stepRow(step finance.BudgetRequestStep) scanRow {
+	return func(dest ...any) error {
+		*dest[0].(*uuid.UUID) = step.RequestID
+		*dest[1].(*int32) = step.Position
+		*dest[2].(*string) = step.Name
+		*dest[3].(*uuid.UUID) = step.ApproverUnitID
+		*dest[4].(*finance.ApprovalStatus) = step.Status
+		*dest[5].(*pgtype.Text) = step.Comment
+		*dest[6].(*pgtype.UUID) = step.DecidedBy
+		*dest[7].(*pgtype.Timestamptz) = step.DecidedAt
+		return nil
+	}
+}
+
+// scanRows returns the rows of a query one by one from Next
+type scanRows struct {
+	pgx.Rows
+	rows []scanRow
+	next int
+}
+
+func (r *scanRows) Next() bool {
+	r.next++
+	return r.next < len(r.rows)
+}
+
+func (r *scanRows) Scan(dest ...any) error { return r.rows[r.next](dest...) }
+func (r *scanRows) Close()                 {}
+func (r *scanRows) Err() error             { return nil }
+
+// chain is a request of the unit requestingUnit going through the treasurer and then
+// the board, each step decided by the members of its unit
+type chain struct {
+	orgID          uuid.UUID
+	requestingUnit uuid.UUID
+	treasurer      uuid.UUID
+	board          uuid.UUID
+	requester      uuid.UUID
+	member         uuid.UUID
+	treasurerUser  uuid.UUID
+	boardUser      uuid.UUID
+}
+
+func newChain() chain {
+	return chain{
+		orgID:          uuid.New(),
+		requestingUnit: uuid.New(),
+		treasurer:      uuid.New(),
+		board:          uuid.New(),
+		requester:      uuid.New(),
+		member:         uuid.New(),
+		treasurerUser:  uuid.New(),
+		boardUser:      uuid.New(),
+	}
+}
+
+func (c chain) db(status finance.BudgetRequestStatus, currentStep int32) *budgetDB {
+	requestID := uuid.New()
+	steps := []finance.BudgetRequestStep{
+		{RequestID: requestID, Position: 0, Name: "Treasurer", ApproverUnitID: c.treasurer, Status: finance.ApprovalStatusPending},
+		{RequestID: requestID, Position: 1, Name: "Board", ApproverUnitID: c.board, Status: finance.ApprovalStatusPending},
+	}
+	for i := int32(0); i < currentStep; i++ {
+		steps[i].Status = finance.ApprovalStatusApproved
+	}
+
+	return &budgetDB{
+		request: finance.BudgetRequest{
+			ID:          requestID,
+			OrgID:       c.orgID,
+			UnitID:      c.requestingUnit,
+			Status:      status,
+			CurrentStep: currentStep,
+			RequestedBy: pgtype.UUID{Bytes: c.requester, Valid: true},
+		},
+		steps: steps,
+		members: map[uuid.UUID][]uuid.UUID{
+			c.requestingUnit: {c.requester, c.member},
+			// The requester also sits in the treasury, and still cannot decide on their own request
+			c.treasurer: {c.treasurerUser, c.requester},
+			c.board:     {c.boardUser},
+		},
+	}
+}
+
+func TestService_Decide(t *testing.T) {
+	t.Parallel()
+
+	c := newChain()
+
+	type testCase struct {
+		name           string
+		status         finance.BudgetRequestStatus
+		currentStep    int32
+		reject         bool
+		userID         uuid.UUID
+		expectedErr    error
+		expectedStatus finance.BudgetRequestStatus
+		expectedStep   int32
+	}
+
+	testCases := []testCase{
+		{
+			name:           "Approving the first step advances the request",
+			status:         finance.BudgetRequestStatusPending,
+			userID:         c.treasurerUser,
+			expectedStatus: finance.BudgetRequestStatusPending,
+			expectedStep:   1,
+		},
+		{
+			name:           "Approving the last step approves the request",
+			status:         finance.BudgetRequestStatusPending,
+			currentStep:    1,
+			userID:         c.boardUser,
+			expectedStatus: finance.BudgetRequestStatusApproved,
+			expectedStep:   1,
+		},
+		{
+			name:           "Rejecting a step rejects the request",
+			status:         finance.BudgetRequestStatusPending,
+			reject:         true,
+			userID:         c.treasurerUser,
+			expectedStatus: finance.BudgetRequestStatusRejected,
+		},
+		{
+			name:        "Approver of a later step cannot decide the current one",
+			status:      finance.BudgetRequestStatusPending,
+			userID:      c.boardUser,
+			expectedErr: internal.ErrPermissionDenied,
+		},
+		{
+			name:        "Member of the requesting unit cannot decide",
+			status:      finance.BudgetRequestStatusPending,
+			userID:      c.member,
+			expectedErr: internal.ErrPermissionDenied,
+		},
+		{
+			name:        "Requester cannot decide their own request",
+			status:      finance.BudgetRequestStatusPending,
+			userID:      c.requester,
+			expectedErr: internal.ErrPermissionDenied,
+		},
+		{
+			name:        "Withdrawn request",
+			status:      finance.BudgetRequestStatusWithdrawn,
+			userID:      c.treasurerUser,
+			expectedErr: internal.ErrBudgetRequestClosed,
+		},
+		{
+			name:        "Rejected request",
+			status:      finance.BudgetRequestStatusRejected,
+			reject:      true,
+			userID:      c.treasurerUser,
+			expectedErr: internal.ErrBudgetRequestClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := c.db(tc.status, tc.currentStep)
+			service := finance.NewService(zap.NewNop(), db, nil)
+
+			decide := service.Approve
+			decision := finance.ApprovalStatusApproved
+			if tc.reject {
+				decide = service.Reject
+				decision = finance.ApprovalStatusRejected
+			}
+
+			detail, err := decide(context.Background(), c.orgID, db.request.ID, "", tc.userID)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.Equal(t, tc.status, db.request.Status)
+				require.Equal(t, tc.currentStep, db.request.CurrentStep)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStatus, detail.Status)
+			require.Equal(t, tc.expectedStep, detail.CurrentStep)
+
+			decided := detail.Steps[tc.currentStep]
+			require.Equal(t, decision, decided.Status)
+			require.Equal(t, pgtype.UUID{Bytes: tc.userID, Valid: true}, decided.DecidedBy)
+		})
+	}
+}
+
+func TestService_Withdraw(t *testing.T) {
+	t.Parallel()
+
+	c := newChain()
+
+	type testCase struct {
+		name        string
+		status      finance.BudgetRequestStatus
+		userID      uuid.UUID
+		unknown     bool
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{name: "Requester withdraws a pending request", status: finance.BudgetRequestStatusPending, userID: c.requester},
+		{name: "Member of the requesting unit withdraws", status: finance.BudgetRequestStatusPending, userID: c.member},
+		{name: "Approver cannot withdraw", status: finance.BudgetRequestStatusPending, userID: c.treasurerUser, expectedErr: internal.ErrPermissionDenied},
+		{name: "Approved request", status: finance.BudgetRequestStatusApproved, userID: c.requester, expectedErr: internal.ErrBudgetRequestClosed},
+		{name: "Rejected request", status: finance.BudgetRequestStatusRejected, userID: c.requester, expectedErr: internal.ErrBudgetRequestClosed},
+		{name: "Withdrawn request", status: finance.BudgetRequestStatusWithdrawn, userID: c.requester, expectedErr: internal.ErrBudgetRequestClosed},
+		{name: "Unknown request", status: finance.BudgetRequestStatusPending, userID: c.requester, unknown: true, expectedErr: internal.ErrBudgetRequestNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := c.db(tc.status, 0)
+			service := finance.NewService(zap.NewNop(), db, nil)
+
+			id := db.request.ID
+			if tc.unknown {
+				id = uuid.New()
+			}
+
+			detail, err := service.Withdraw(context.Background(), c.orgID, id, tc.userID)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.Equal(t, tc.status, db.request.Status)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, finance.BudgetRequestStatusWithdrawn, detail.Status)
+
+			// A withdrawn request can no longer be decided
+			_, err = service.Approve(context.Background(), c.orgID, id, "", c.treasurerUser)
+			require.ErrorIs(t, err, internal.ErrBudgetRequestClosed)
+		})
+	}
+}
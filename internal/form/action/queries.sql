@@ -0,0 +1,23 @@
+-- name: CreateRun :one
+INSERT INTO form_action_runs (form_id, user_id, node_id)
+VALUES (@form_id, @user_id, @node_id)
+ON CONFLICT (form_id, user_id, node_id) DO NOTHING
+RETURNING *;
+
+-- name: UpdateRunStatus :exec
+UPDATE form_action_runs
+SET status = @status,
+    error = @error,
+    updated_at = now()
+WHERE id = @id;
+
+-- name: ListApprovalDecisions :many
+SELECT a.node_id, a.status
+FROM form_approvals AS a
+JOIN form_responses AS r ON r.id = a.response_id
+WHERE r.form_id = @form_id AND r.submitted_by = @user_id;
+
+-- name: GetFormUnit :one
+SELECT u.id, u.org_id FROM forms f
+JOIN units u ON u.id = f.unit_id
+WHERE f.id = @form_id;
\ No newline at end of file
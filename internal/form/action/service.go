@@ -0,0 +1,316 @@
+package action
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/egress"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const webhookTimeout = 10 * time.Second
+
+type Querier interface {
+	CreateRun(ctx context.Context, arg CreateRunParams) (FormActionRun, error)
+	UpdateRunStatus(ctx context.Context, arg UpdateRunStatusParams) error
+	ListApprovalDecisions(ctx context.Context, arg ListApprovalDecisionsParams) ([]ListApprovalDecisionsRow, error)
+	GetFormUnit(ctx context.Context, formID uuid.UUID) (GetFormUnitRow, error)
+}
+
+type WorkflowStore interface {
+	ActiveRuntime(ctx context.Context, formID uuid.UUID) (*workflow.Runtime, error)
+}
+
+type AnswerStore interface {
+	GetAnswersByFormIDAndSubmittedBy(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]response.Answer, error)
+}
+
+// Signer signs webhook deliveries with the keys of the organization of the form, so
+// receivers can tell them from forged ones
+type Signer interface {
+	Sign(ctx context.Context, orgID uuid.UUID, timestamp time.Time, body []byte) (string, error)
+}
+
+// Event is an internal event fired by an action node with actionType "event"
+type Event struct {
+	Name    string
+	FormID  uuid.UUID
+	UserID  uuid.UUID
+	NodeID  string
+	Payload map[string]string
+}
+
+// EventHandler handles an internal event. Returned errors mark the action run as failed.
+type EventHandler func(ctx context.Context, event Event) error
+
+type Service struct {
+	logger        *zap.Logger
+	queries       Querier
+	tracer        trace.Tracer
+	httpClient    *http.Client
+	workflowStore WorkflowStore
+	answerStore   AnswerStore
+	signer        Signer
+
+	handlersMu sync.RWMutex
+	handlers   map[string][]EventHandler
+}
+
+func NewService(logger *zap.Logger, db DBTX, workflowStore WorkflowStore, answerStore AnswerStore, signer Signer) *Service {
+	return &Service{
+		logger:        logger,
+		queries:       New(db),
+		tracer:        otel.Tracer("action/service"),
+		httpClient:    egress.NewClient(webhookTimeout),
+		workflowStore: workflowStore,
+		answerStore:   answerStore,
+		signer:        signer,
+		handlers:      make(map[string][]EventHandler),
+	}
+}
+
+// Subscribe registers a handler for an internal event name
+func (s *Service) Subscribe(name string, handler EventHandler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+
+	s.handlers[name] = append(s.handlers[name], handler)
+}
+
+// Run replays the whole active workflow of a form for a respondent, with their saved
+// answers and approval decisions, and dispatches the action nodes on the path.
+// Forms without an active workflow have nothing to run.
+func (s *Service) Run(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Run")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	runtime, err := s.workflowStore.ActiveRuntime(ctx, formID)
+	if err != nil {
+		if errors.Is(err, handlerutil.ErrNotFound) {
+			return nil
+		}
+		span.RecordError(err)
+		return err
+	}
+
+	saved, err := s.answerStore.GetAnswersByFormIDAndSubmittedBy(ctx, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	answers := make(workflow.Answers, len(saved))
+	for _, answer := range saved {
+		answers[answer.QuestionID.String()] = answer.Value
+	}
+
+	decisions, err := s.queries.ListApprovalDecisions(ctx, ListApprovalDecisionsParams{FormID: formID, UserID: userID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_approvals", "form_id", formID.String(), logger, "list approval decisions")
+		span.RecordError(err)
+		return err
+	}
+
+	approvals := make(workflow.Approvals, len(decisions))
+	for _, decision := range decisions {
+		approvals[decision.NodeID] = workflow.ApprovalDecision(decision.Status)
+	}
+
+	traversal, err := runtime.Traverse(answers, approvals, time.Now(), nil)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.Dispatch(ctx, formID, userID, traversal, answers)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Dispatch fires every action node on the traversal path that has not run for the
+// respondent yet. Each action runs at most once per respondent; a failing webhook or
+// event handler is recorded on the run and logged, but does not fail the caller.
+func (s *Service) Dispatch(ctx context.Context, formID uuid.UUID, userID uuid.UUID, traversal workflow.Traversal, answers workflow.Answers) error {
+	ctx, span := s.tracer.Start(ctx, "Dispatch")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	for _, step := range traversal.Steps {
+		if step.Action == nil {
+			continue
+		}
+
+		run, err := s.queries.CreateRun(ctx, CreateRunParams{FormID: formID, UserID: userID, NodeID: step.NodeID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// Already fired for this respondent
+				continue
+			}
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_action_runs", "form_id", formID.String(), logger, "create action run")
+			span.RecordError(err)
+			return err
+		}
+
+		payload := resolvePayload(step, formID, userID, answers)
+
+		status := ActionRunStatusSucceeded
+		var runError pgtype.Text
+		fireErr := s.fire(ctx, formID, userID, step, payload)
+		if fireErr != nil {
+			status = ActionRunStatusFailed
+			runError = pgtype.Text{String: fireErr.Error(), Valid: true}
+			logger.Warn("Workflow action failed",
+				zap.Error(fireErr),
+				zap.String("form_id", formID.String()),
+				zap.String("user_id", userID.String()),
+				zap.String("node_id", step.NodeID))
+		}
+
+		err = s.queries.UpdateRunStatus(ctx, UpdateRunStatusParams{Status: status, Error: runError, ID: run.ID})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_action_runs", "id", run.ID.String(), logger, "update action run status")
+			span.RecordError(err)
+			return err
+		}
+
+		logger.Info("Dispatched workflow action",
+			zap.String("form_id", formID.String()),
+			zap.String("user_id", userID.String()),
+			zap.String("node_id", step.NodeID),
+			zap.String("action_type", string(step.Action.ActionType)),
+			zap.String("status", string(status)))
+	}
+
+	return nil
+}
+
+func (s *Service) fire(ctx context.Context, formID uuid.UUID, userID uuid.UUID, step workflow.Step, payload map[string]string) error {
+	switch step.Action.ActionType {
+	case node.ActionTypeWebhook:
+		return s.postWebhook(ctx, formID, step.Action.URL, payload)
+	case node.ActionTypeEvent:
+		return s.publish(ctx, Event{
+			Name:    step.Action.Event,
+			FormID:  formID,
+			UserID:  userID,
+			NodeID:  step.NodeID,
+			Payload: payload,
+		})
+	default:
+		return fmt.Errorf("unsupported action type: %s", step.Action.ActionType)
+	}
+}
+
+func (s *Service) postWebhook(ctx context.Context, formID uuid.UUID, url string, payload map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	unit, err := s.queries.GetFormUnit(ctx, formID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization of form: %w", err)
+	}
+	orgID := unit.ID
+	if unit.OrgID.Valid {
+		orgID = unit.OrgID.Bytes
+	}
+
+	signature, err := s.signer.Sign(ctx, orgID, time.Now(), body)
+	if err != nil {
+		return fmt.Errorf("failed to sign webhook: %w", err)
+	}
+	if signature != "" {
+		req.Header.Set(webhook.SignatureHeader, signature)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *Service) publish(ctx context.Context, event Event) error {
+	s.handlersMu.RLock()
+	handlers := s.handlers[event.Name]
+	s.handlersMu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		err := handler(ctx, event)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// resolvePayload builds the payload of an action from its mapping. Actions without
+// a mapping send the form, respondent and node IDs.
+func resolvePayload(step workflow.Step, formID uuid.UUID, userID uuid.UUID, answers workflow.Answers) map[string]string {
+	if step.Action.Payload == nil {
+		return map[string]string{
+			"formId": formID.String(),
+			"userId": userID.String(),
+			"nodeId": step.NodeID,
+		}
+	}
+
+	payload := make(map[string]string, len(step.Action.Payload))
+	for key, source := range step.Action.Payload {
+		switch source {
+		case node.PayloadSourceFormID:
+			payload[key] = formID.String()
+		case node.PayloadSourceUserID:
+			payload[key] = userID.String()
+		case node.PayloadSourceNodeID:
+			payload[key] = step.NodeID
+		default:
+			// Unanswered questions map to an empty string
+			questionID, _ := strings.CutPrefix(source, node.PayloadSourceQuestionPrefix)
+			payload[key] = answers[questionID]
+		}
+	}
+
+	return payload
+}
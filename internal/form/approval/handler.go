@@ -0,0 +1,164 @@
+package approval
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	ListQueue(ctx context.Context, userID uuid.UUID) ([]FormApproval, error)
+	Approve(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) (FormApproval, error)
+	Reject(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) (FormApproval, error)
+}
+
+type DecideRequest struct {
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+type Response struct {
+	ID             string     `json:"id"`
+	FormID         string     `json:"formId"`
+	ResponseID     string     `json:"responseId"`
+	NodeID         string     `json:"nodeId"`
+	ApproverUnitID string     `json:"approverUnitId"`
+	Status         string     `json:"status"`
+	Comment        *string    `json:"comment,omitempty"`
+	DecidedBy      *string    `json:"decidedBy,omitempty"`
+	DecidedAt      *time.Time `json:"decidedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func ToResponse(approval FormApproval) Response {
+	response := Response{
+		ID:             approval.ID.String(),
+		FormID:         approval.FormID.String(),
+		ResponseID:     approval.ResponseID.String(),
+		NodeID:         approval.NodeID,
+		ApproverUnitID: approval.ApproverUnitID.String(),
+		Status:         strings.ToUpper(string(approval.Status)),
+		CreatedAt:      approval.CreatedAt.Time,
+		UpdatedAt:      approval.UpdatedAt.Time,
+	}
+	if approval.Comment.Valid {
+		response.Comment = &approval.Comment.String
+	}
+	if approval.DecidedBy.Valid {
+		decidedBy := uuid.UUID(approval.DecidedBy.Bytes).String()
+		response.DecidedBy = &decidedBy
+	}
+	if approval.DecidedAt.Valid {
+		response.DecidedAt = &approval.DecidedAt.Time
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("approval/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+// ListQueueHandler returns the pending approvals the current user can decide on
+func (h *Handler) ListQueueHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListQueueHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	approvals, err := h.store.ListQueue(traceCtx, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]Response, len(approvals))
+	for i, approval := range approvals {
+		responses[i] = ToResponse(approval)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+func (h *Handler) ApproveHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, "ApproveHandler", h.store.Approve)
+}
+
+func (h *Handler) RejectHandler(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, "RejectHandler", h.store.Reject)
+}
+
+func (h *Handler) decide(
+	w http.ResponseWriter,
+	r *http.Request,
+	spanName string,
+	decide func(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) (FormApproval, error),
+) {
+	traceCtx, span := h.tracer.Start(r.Context(), spanName)
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := handlerutil.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req DecideRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	approval, err := decide(traceCtx, id, currentUser.ID, req.Comment)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(approval))
+}
@@ -0,0 +1,50 @@
+-- name: Create :one
+INSERT INTO form_approvals (form_id, response_id, node_id, approver_unit_id)
+VALUES (@form_id, @response_id, @node_id, @approver_unit_id)
+ON CONFLICT (response_id, node_id) DO UPDATE
+SET updated_at = form_approvals.updated_at
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM form_approvals
+WHERE id = @id;
+
+-- name: ListByResponseID :many
+SELECT * FROM form_approvals
+WHERE response_id = @response_id
+ORDER BY created_at ASC;
+
+-- name: ListByFormIDAndSubmittedBy :many
+SELECT * FROM form_approvals
+WHERE response_id IN (
+    SELECT id FROM form_responses
+    WHERE form_id = @form_id AND submitted_by = @submitted_by
+)
+ORDER BY created_at ASC;
+
+-- name: ListPendingByMemberID :many
+SELECT * FROM form_approvals
+WHERE status = 'pending'
+  AND approver_unit_id IN (SELECT unit_id FROM unit_members WHERE member_id = @member_id)
+ORDER BY created_at ASC;
+
+-- name: Decide :one
+UPDATE form_approvals
+SET status = @status,
+    comment = @comment,
+    decided_by = @decided_by,
+    decided_at = now(),
+    updated_at = now()
+WHERE id = @id AND status = 'pending'
+RETURNING *;
+
+-- name: GetRespondentID :one
+SELECT submitted_by FROM form_responses
+WHERE id = @response_id;
+
+-- name: IsUnitMember :one
+SELECT EXISTS(SELECT 1 FROM unit_members WHERE unit_id = @unit_id AND member_id = @member_id);
+
+-- name: ListUnitMemberIDs :many
+SELECT member_id FROM unit_members
+WHERE unit_id = @unit_id;
\ No newline at end of file
@@ -0,0 +1,12 @@
+package approval
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the approval queue and its decisions
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /approvals", route.Authenticated, route.PermissionNone, h.ListQueueHandler)
+	r.Handle("POST /approvals/{id}/approve", route.Authenticated, route.PermissionUnitMember, h.ApproveHandler)
+	r.Handle("POST /approvals/{id}/reject", route.Authenticated, route.PermissionUnitMember, h.RejectHandler)
+}
@@ -0,0 +1,301 @@
+package approval
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (FormApproval, error)
+	GetByID(ctx context.Context, id uuid.UUID) (FormApproval, error)
+	ListByResponseID(ctx context.Context, responseID uuid.UUID) ([]FormApproval, error)
+	ListByFormIDAndSubmittedBy(ctx context.Context, arg ListByFormIDAndSubmittedByParams) ([]FormApproval, error)
+	ListPendingByMemberID(ctx context.Context, memberID uuid.UUID) ([]FormApproval, error)
+	Decide(ctx context.Context, arg DecideParams) (FormApproval, error)
+	GetRespondentID(ctx context.Context, responseID uuid.UUID) (uuid.UUID, error)
+	IsUnitMember(ctx context.Context, arg IsUnitMemberParams) (bool, error)
+	ListUnitMemberIDs(ctx context.Context, unitID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type WorkflowStore interface {
+	ActiveRuntime(ctx context.Context, formID uuid.UUID) (*workflow.Runtime, error)
+}
+
+type AnswerStore interface {
+	GetAnswersByFormIDAndSubmittedBy(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]response.Answer, error)
+}
+
+type InboxStore interface {
+	Create(ctx context.Context, contentType inbox.ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error)
+}
+
+type ActionStore interface {
+	Run(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+}
+
+type Service struct {
+	logger        *zap.Logger
+	queries       Querier
+	tracer        trace.Tracer
+	workflowStore WorkflowStore
+	answerStore   AnswerStore
+	inboxStore    InboxStore
+	actionStore   ActionStore
+}
+
+func NewService(logger *zap.Logger, db DBTX, workflowStore WorkflowStore, answerStore AnswerStore, inboxStore InboxStore, actionStore ActionStore) *Service {
+	return &Service{
+		logger:        logger,
+		queries:       New(db),
+		tracer:        otel.Tracer("approval/service"),
+		workflowStore: workflowStore,
+		answerStore:   answerStore,
+		inboxStore:    inboxStore,
+		actionStore:   actionStore,
+	}
+}
+
+// Request replays the active workflow of a form for a response and opens an approval
+// for the first gate the respondent is waiting on. Members of the gate's approver unit
+// are notified through their inbox. Gates that already have an approval record, and
+// forms without an active workflow, are left untouched.
+func (s *Service) Request(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Request")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	runtime, err := s.workflowStore.ActiveRuntime(ctx, formID)
+	if err != nil {
+		if errors.Is(err, handlerutil.ErrNotFound) {
+			return nil
+		}
+		span.RecordError(err)
+		return err
+	}
+
+	saved, err := s.answerStore.GetAnswersByFormIDAndSubmittedBy(ctx, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	answers := make(workflow.Answers, len(saved))
+	for _, answer := range saved {
+		answers[answer.QuestionID.String()] = answer.Value
+	}
+
+	existing, err := s.queries.ListByResponseID(ctx, responseID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_approvals", "response_id", responseID.String(), logger, "list approvals by response id")
+		span.RecordError(err)
+		return err
+	}
+
+	traversal, err := runtime.Traverse(answers, decisions(existing), time.Now(), nil)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return err
+	}
+
+	gate, ok := traversal.AwaitingApproval()
+	if !ok {
+		return nil
+	}
+	for _, approval := range existing {
+		if approval.NodeID == gate.NodeID {
+			return nil
+		}
+	}
+
+	approverUnitID, err := uuid.Parse(gate.Approval.ApproverUnitID)
+	if err != nil {
+		err = fmt.Errorf("%w: approval node '%s' has invalid approverUnitId: %w", internal.ErrWorkflowValidationFailed, gate.NodeID, err)
+		span.RecordError(err)
+		return err
+	}
+
+	approval, err := s.queries.Create(ctx, CreateParams{
+		FormID:         formID,
+		ResponseID:     responseID,
+		NodeID:         gate.NodeID,
+		ApproverUnitID: approverUnitID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_approvals", "response_id", responseID.String(), logger, "create approval")
+		span.RecordError(err)
+		return err
+	}
+
+	approverIDs, err := s.queries.ListUnitMemberIDs(ctx, approverUnitID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", approverUnitID.String(), logger, "list approver unit members")
+		span.RecordError(err)
+		return err
+	}
+
+	if len(approverIDs) > 0 {
+		_, err = s.inboxStore.Create(ctx, inbox.ContentTypeForm, formID, approverIDs, approverUnitID)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	logger.Info("Requested approval",
+		zap.String("approval_id", approval.ID.String()),
+		zap.String("response_id", responseID.String()),
+		zap.String("node_id", gate.NodeID),
+		zap.Int("approver_count", len(approverIDs)))
+
+	return nil
+}
+
+// Decisions returns the approval decisions made on a respondent's response to a form, keyed by approval node ID
+func (s *Service) Decisions(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (workflow.Approvals, error) {
+	ctx, span := s.tracer.Start(ctx, "Decisions")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	approvals, err := s.queries.ListByFormIDAndSubmittedBy(ctx, ListByFormIDAndSubmittedByParams{
+		FormID:      formID,
+		SubmittedBy: userID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_approvals", "form_id", formID.String(), logger, "list approvals by form id and submitted by")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return decisions(approvals), nil
+}
+
+// ListQueue returns the pending approvals of every unit the user is a member of, oldest first
+func (s *Service) ListQueue(ctx context.Context, userID uuid.UUID) ([]FormApproval, error) {
+	ctx, span := s.tracer.Start(ctx, "ListQueue")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	approvals, err := s.queries.ListPendingByMemberID(ctx, userID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_approvals", "member_id", userID.String(), logger, "list pending approvals by member id")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return approvals, nil
+}
+
+// Approve lets the respondent pass the approval gate. Action nodes behind the gate
+// are dispatched, and if the workflow reaches another gate afterward, an approval
+// is requested for it.
+func (s *Service) Approve(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) (FormApproval, error) {
+	ctx, span := s.tracer.Start(ctx, "Approve")
+	defer span.End()
+
+	approval, err := s.decide(ctx, id, userID, ApprovalStatusApproved, comment)
+	if err != nil {
+		span.RecordError(err)
+		return FormApproval{}, err
+	}
+
+	respondentID, err := s.queries.GetRespondentID(ctx, approval.ResponseID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", approval.ResponseID.String(), logutil.WithContext(ctx, s.logger), "get respondent id")
+		span.RecordError(err)
+		return FormApproval{}, err
+	}
+
+	err = s.actionStore.Run(ctx, approval.FormID, respondentID)
+	if err != nil {
+		span.RecordError(err)
+		return FormApproval{}, err
+	}
+
+	err = s.Request(ctx, approval.FormID, approval.ResponseID, respondentID)
+	if err != nil {
+		span.RecordError(err)
+		return FormApproval{}, err
+	}
+
+	return approval, nil
+}
+
+// Reject stops the respondent at the approval gate, so the submission never finalizes
+func (s *Service) Reject(ctx context.Context, id uuid.UUID, userID uuid.UUID, comment string) (FormApproval, error) {
+	ctx, span := s.tracer.Start(ctx, "Reject")
+	defer span.End()
+
+	approval, err := s.decide(ctx, id, userID, ApprovalStatusRejected, comment)
+	if err != nil {
+		span.RecordError(err)
+		return FormApproval{}, err
+	}
+
+	return approval, nil
+}
+
+// decide records the decision of a member of the approver unit on a pending approval
+func (s *Service) decide(ctx context.Context, id uuid.UUID, userID uuid.UUID, status ApprovalStatus, comment string) (FormApproval, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	approval, err := s.queries.GetByID(ctx, id)
+	if err != nil {
+		return FormApproval{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_approvals", "id", id.String(), logger, "get approval by id")
+	}
+
+	isMember, err := s.queries.IsUnitMember(ctx, IsUnitMemberParams{
+		UnitID:   approval.ApproverUnitID,
+		MemberID: userID,
+	})
+	if err != nil {
+		return FormApproval{}, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", approval.ApproverUnitID.String(), logger, "check approver unit membership")
+	}
+	if !isMember {
+		return FormApproval{}, fmt.Errorf("%w: user is not a member of approver unit %s", internal.ErrPermissionDenied, approval.ApproverUnitID)
+	}
+
+	decided, err := s.queries.Decide(ctx, DecideParams{
+		Status:    status,
+		Comment:   pgtype.Text{String: comment, Valid: comment != ""},
+		DecidedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		ID:        id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FormApproval{}, fmt.Errorf("%w: approval %s is %s", internal.ErrApprovalAlreadyDecided, id, approval.Status)
+		}
+		return FormApproval{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_approvals", "id", id.String(), logger, "decide approval")
+	}
+
+	logger.Info("Decided approval",
+		zap.String("approval_id", id.String()),
+		zap.String("status", string(status)),
+		zap.String("decided_by", userID.String()))
+
+	return decided, nil
+}
+
+func decisions(approvals []FormApproval) workflow.Approvals {
+	result := make(workflow.Approvals, len(approvals))
+	for _, approval := range approvals {
+		result[approval.NodeID] = workflow.ApprovalDecision(approval.Status)
+	}
+	return result
+}
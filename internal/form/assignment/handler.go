@@ -0,0 +1,291 @@
+package assignment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	GetSettings(ctx context.Context, formID uuid.UUID) (Settings, error)
+	UpdateSettings(ctx context.Context, settings Settings, userID uuid.UUID) (Settings, error)
+	DeleteSettings(ctx context.Context, formID uuid.UUID) error
+	Distribute(ctx context.Context, formID uuid.UUID) (int, error)
+	ListWorkload(ctx context.Context, formID uuid.UUID) ([]Workload, error)
+	ListAssignedTo(ctx context.Context, formID uuid.UUID, reviewerID uuid.UUID) ([]ListAssignedToRow, error)
+}
+
+type RuleRequest struct {
+	QuestionID uuid.UUID `json:"questionId" validate:"required"`
+	Value      string    `json:"value" validate:"required"`
+	ReviewerID uuid.UUID `json:"reviewerId" validate:"required"`
+}
+
+// Request configures assignment; rules are tried in order before the strategy
+type Request struct {
+	Strategy    string        `json:"strategy" validate:"required,oneof=round_robin least_loaded"`
+	ReviewerIDs []uuid.UUID   `json:"reviewerIds"`
+	Rules       []RuleRequest `json:"rules" validate:"dive"`
+}
+
+type RuleResponse struct {
+	QuestionID string `json:"questionId"`
+	Value      string `json:"value"`
+	ReviewerID string `json:"reviewerId"`
+}
+
+type Response struct {
+	FormID      string         `json:"formId"`
+	Strategy    string         `json:"strategy"`
+	ReviewerIDs []string       `json:"reviewerIds"`
+	Rules       []RuleResponse `json:"rules"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+}
+
+type WorkloadResponse struct {
+	ReviewerID string `json:"reviewerId"`
+	Assigned   int64  `json:"assigned"`
+}
+
+type DistributeResponse struct {
+	Assigned int `json:"assigned"`
+}
+
+type AssignedResponse struct {
+	ID          string    `json:"id"`
+	SubmittedBy string    `json:"submittedBy"`
+	AssignedAt  time.Time `json:"assignedAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func ToResponse(settings Settings) Response {
+	response := Response{
+		FormID:      settings.FormID.String(),
+		Strategy:    string(settings.Strategy),
+		ReviewerIDs: make([]string, len(settings.ReviewerIDs)),
+		Rules:       make([]RuleResponse, len(settings.Rules)),
+		UpdatedAt:   settings.UpdatedAt.Time,
+	}
+	for i, reviewerID := range settings.ReviewerIDs {
+		response.ReviewerIDs[i] = reviewerID.String()
+	}
+	for i, rule := range settings.Rules {
+		response.Rules[i] = RuleResponse{
+			QuestionID: rule.QuestionID.String(),
+			Value:      rule.Value,
+			ReviewerID: rule.ReviewerID.String(),
+		}
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("assignment/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	settings, err := h.store.GetSettings(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(settings))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	rules := make([]Rule, len(req.Rules))
+	for i, rule := range req.Rules {
+		rules[i] = Rule{
+			QuestionID: rule.QuestionID,
+			Value:      rule.Value,
+			ReviewerID: rule.ReviewerID,
+		}
+	}
+
+	settings, err := h.store.UpdateSettings(traceCtx, Settings{
+		FormID:      formID,
+		Strategy:    AssignmentStrategy(req.Strategy),
+		ReviewerIDs: req.ReviewerIDs,
+		Rules:       rules,
+	}, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(settings))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.DeleteSettings(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// DistributeHandler assigns the responses submitted before assignment was configured
+func (h *Handler) DistributeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DistributeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	assigned, err := h.store.Distribute(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, DistributeResponse{Assigned: assigned})
+}
+
+func (h *Handler) WorkloadHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "WorkloadHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	workload, err := h.store.ListWorkload(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]WorkloadResponse, len(workload))
+	for i, item := range workload {
+		responses[i] = WorkloadResponse{
+			ReviewerID: item.ReviewerID.String(),
+			Assigned:   item.Assigned,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// AssignedToMeHandler lists the responses of the form the current user is to review
+func (h *Handler) AssignedToMeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "AssignedToMeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	rows, err := h.store.ListAssignedTo(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]AssignedResponse, len(rows))
+	for i, row := range rows {
+		responses[i] = AssignedResponse{
+			ID:          row.ID.String(),
+			SubmittedBy: row.SubmittedBy.String(),
+			AssignedAt:  row.AssignedAt.Time,
+			CreatedAt:   row.CreatedAt.Time,
+			UpdatedAt:   row.UpdatedAt.Time,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
@@ -0,0 +1,59 @@
+-- name: GetSettings :one
+SELECT * FROM form_assignment_settings
+WHERE form_id = @form_id;
+
+-- name: UpsertSettings :one
+INSERT INTO form_assignment_settings (form_id, strategy, reviewer_ids, rules, updated_by)
+VALUES (@form_id, @strategy, @reviewer_ids, @rules, @updated_by)
+ON CONFLICT (form_id) DO UPDATE
+SET strategy = EXCLUDED.strategy, reviewer_ids = EXCLUDED.reviewer_ids, rules = EXCLUDED.rules,
+    updated_by = EXCLUDED.updated_by, updated_at = now()
+RETURNING *;
+
+-- name: DeleteSettings :execrows
+DELETE FROM form_assignment_settings
+WHERE form_id = @form_id;
+
+-- name: AdvanceCursor :one
+UPDATE form_assignment_settings
+SET next_index = next_index + 1
+WHERE form_id = @form_id
+RETURNING (next_index - 1)::int AS previous_index;
+
+-- name: GetFormUnit :one
+SELECT unit_id FROM forms
+WHERE id = @form_id;
+
+-- name: ListUnitMembers :many
+SELECT member_id FROM unit_members
+WHERE unit_id = @unit_id AND (valid_until IS NULL OR valid_until > now())
+ORDER BY member_id;
+
+-- name: ListWorkload :many
+SELECT reviewer_id, COUNT(*)::bigint AS assigned
+FROM response_assignments
+WHERE form_id = @form_id
+GROUP BY reviewer_id;
+
+-- name: ListAnswers :many
+SELECT question_id, value FROM answers
+WHERE response_id = @response_id;
+
+-- name: Assign :one
+INSERT INTO response_assignments (response_id, form_id, reviewer_id)
+VALUES (@response_id, @form_id, @reviewer_id)
+ON CONFLICT (response_id) DO NOTHING
+RETURNING *;
+
+-- name: ListUnassigned :many
+SELECT r.id FROM form_responses r
+WHERE r.form_id = @form_id AND NOT r.is_test
+    AND NOT EXISTS (SELECT 1 FROM response_assignments a WHERE a.response_id = r.id)
+ORDER BY r.created_at ASC;
+
+-- name: ListAssignedTo :many
+SELECT r.id, r.submitted_by, r.created_at, r.updated_at, a.assigned_at
+FROM response_assignments a
+JOIN form_responses r ON r.id = a.response_id
+WHERE a.form_id = @form_id AND a.reviewer_id = @reviewer_id
+ORDER BY a.assigned_at ASC;
\ No newline at end of file
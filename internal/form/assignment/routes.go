@@ -0,0 +1,15 @@
+package assignment
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the reviewer assignment of a form and the responses assigned to the current user
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/assignment", route.Authenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /forms/{id}/assignment", route.Authenticated, route.PermissionNone, h.UpdateHandler)
+	r.Handle("DELETE /forms/{id}/assignment", route.Authenticated, route.PermissionNone, h.DeleteHandler)
+	r.Handle("POST /forms/{id}/assignment/distribute", route.Authenticated, route.PermissionNone, h.DistributeHandler)
+	r.Handle("GET /forms/{id}/assignment/workload", route.Authenticated, route.PermissionNone, h.WorkloadHandler)
+	r.Handle("GET /forms/{formId}/responses/assigned-to-me", route.Authenticated, route.PermissionReviewer, h.AssignedToMeHandler)
+}
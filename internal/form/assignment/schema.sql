@@ -0,0 +1,26 @@
+CREATE TYPE assignment_strategy AS ENUM (
+    'round_robin',
+    'least_loaded'
+);
+
+-- How the responses of a form are shared out among its reviewers. An empty
+-- reviewer_ids means every member of the unit owning the form.
+CREATE TABLE IF NOT EXISTS form_assignment_settings (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    strategy assignment_strategy NOT NULL DEFAULT 'round_robin',
+    reviewer_ids UUID[] NOT NULL DEFAULT '{}',
+    rules JSONB NOT NULL DEFAULT '[]',
+    next_index INT NOT NULL DEFAULT 0,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS response_assignments (
+    response_id UUID PRIMARY KEY REFERENCES form_responses(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_response_assignments_reviewer ON response_assignments(form_id, reviewer_id);
\ No newline at end of file
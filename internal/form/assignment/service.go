@@ -0,0 +1,473 @@
+package assignment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	GetSettings(ctx context.Context, formID uuid.UUID) (FormAssignmentSetting, error)
+	UpsertSettings(ctx context.Context, arg UpsertSettingsParams) (FormAssignmentSetting, error)
+	DeleteSettings(ctx context.Context, formID uuid.UUID) (int64, error)
+	AdvanceCursor(ctx context.Context, formID uuid.UUID) (int32, error)
+	GetFormUnit(ctx context.Context, formID uuid.UUID) (pgtype.UUID, error)
+	ListUnitMembers(ctx context.Context, unitID uuid.UUID) ([]uuid.UUID, error)
+	ListWorkload(ctx context.Context, formID uuid.UUID) ([]ListWorkloadRow, error)
+	ListAnswers(ctx context.Context, responseID uuid.UUID) ([]ListAnswersRow, error)
+	Assign(ctx context.Context, arg AssignParams) (ResponseAssignment, error)
+	ListUnassigned(ctx context.Context, formID uuid.UUID) ([]uuid.UUID, error)
+	ListAssignedTo(ctx context.Context, arg ListAssignedToParams) ([]ListAssignedToRow, error)
+}
+
+// Rule sends a response to a reviewer when its answer to the question matches Value.
+// Answers holding several values, such as the choice IDs of a multiple choice
+// question, match when any of them does. Values are compared case insensitively.
+type Rule struct {
+	QuestionID uuid.UUID `json:"questionId"`
+	Value      string    `json:"value"`
+	ReviewerID uuid.UUID `json:"reviewerId"`
+}
+
+func (r Rule) matches(answers map[uuid.UUID]string) bool {
+	answer, ok := answers[r.QuestionID]
+	if !ok {
+		return false
+	}
+	for _, value := range strings.Split(answer, ";") {
+		if strings.EqualFold(strings.TrimSpace(value), strings.TrimSpace(r.Value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Settings is how the responses of a form are assigned. No ReviewerIDs means every
+// current member of the unit owning the form.
+type Settings struct {
+	FormID      uuid.UUID
+	Strategy    AssignmentStrategy
+	ReviewerIDs []uuid.UUID
+	Rules       []Rule
+	UpdatedAt   pgtype.Timestamptz
+}
+
+// Workload is the number of responses of a form assigned to a reviewer
+type Workload struct {
+	ReviewerID uuid.UUID
+	Assigned   int64
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("assignment/service"),
+	}
+}
+
+func toSettings(row FormAssignmentSetting) (Settings, error) {
+	rules := []Rule{}
+	err := json.Unmarshal(row.Rules, &rules)
+	if err != nil {
+		return Settings{}, fmt.Errorf("failed to decode assignment rules: %w", err)
+	}
+
+	return Settings{
+		FormID:      row.FormID,
+		Strategy:    row.Strategy,
+		ReviewerIDs: row.ReviewerIds,
+		Rules:       rules,
+		UpdatedAt:   row.UpdatedAt,
+	}, nil
+}
+
+func (s *Service) GetSettings(ctx context.Context, formID uuid.UUID) (Settings, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetSettings")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	row, err := s.queries.GetSettings(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrAssignmentNotConfigured
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_assignment_settings", "form_id", formID.String(), logger, "get assignment settings")
+		}
+		span.RecordError(err)
+		return Settings{}, err
+	}
+
+	settings, err := toSettings(row)
+	if err != nil {
+		span.RecordError(err)
+		return Settings{}, err
+	}
+
+	return settings, nil
+}
+
+// UpdateSettings replaces the assignment settings of a form. Every reviewer, listed or
+// named by a rule, must be a current member of the unit owning the form.
+func (s *Service) UpdateSettings(ctx context.Context, settings Settings, userID uuid.UUID) (Settings, error) {
+	traceCtx, span := s.tracer.Start(ctx, "UpdateSettings")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	members, err := s.unitMembers(traceCtx, logger, settings.FormID)
+	if err != nil {
+		span.RecordError(err)
+		return Settings{}, err
+	}
+
+	reviewers := slices.Clone(settings.ReviewerIDs)
+	for _, rule := range settings.Rules {
+		reviewers = append(reviewers, rule.ReviewerID)
+	}
+	for _, reviewerID := range reviewers {
+		if !slices.Contains(members, reviewerID) {
+			err = fmt.Errorf("%w: %s", internal.ErrReviewerNotInUnit, reviewerID)
+			span.RecordError(err)
+			return Settings{}, err
+		}
+	}
+
+	if settings.ReviewerIDs == nil {
+		settings.ReviewerIDs = []uuid.UUID{}
+	}
+	if settings.Rules == nil {
+		settings.Rules = []Rule{}
+	}
+	rules, err := json.Marshal(settings.Rules)
+	if err != nil {
+		span.RecordError(err)
+		return Settings{}, err
+	}
+
+	row, err := s.queries.UpsertSettings(traceCtx, UpsertSettingsParams{
+		FormID:      settings.FormID,
+		Strategy:    settings.Strategy,
+		ReviewerIds: settings.ReviewerIDs,
+		Rules:       rules,
+		UpdatedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_assignment_settings", "form_id", settings.FormID.String(), logger, "upsert assignment settings")
+		span.RecordError(err)
+		return Settings{}, err
+	}
+
+	updated, err := toSettings(row)
+	if err != nil {
+		span.RecordError(err)
+		return Settings{}, err
+	}
+
+	return updated, nil
+}
+
+// DeleteSettings turns automatic assignment off; existing assignments are kept
+func (s *Service) DeleteSettings(ctx context.Context, formID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "DeleteSettings")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	deleted, err := s.queries.DeleteSettings(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_assignment_settings", "form_id", formID.String(), logger, "delete assignment settings")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		err = internal.ErrAssignmentNotConfigured
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Assign gives a response of the form to a reviewer. The first matching rule decides;
+// otherwise the strategy picks from the reviewers: round_robin takes them in turn and
+// least_loaded the one with the fewest responses of the form so far. Forms without
+// settings are left alone, and a response keeps the reviewer it already has.
+func (s *Service) Assign(ctx context.Context, formID uuid.UUID, responseID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Assign")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	settings, err := s.GetSettings(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, internal.ErrAssignmentNotConfigured) {
+			return nil
+		}
+		span.RecordError(err)
+		return err
+	}
+
+	reviewers, err := s.reviewers(traceCtx, logger, settings)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	_, err = s.assign(traceCtx, logger, settings, reviewers, responseID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Distribute assigns every response of the form that has no reviewer yet, for example
+// those submitted before assignment was configured, and returns how many it assigned
+func (s *Service) Distribute(ctx context.Context, formID uuid.UUID) (int, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Distribute")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	settings, err := s.GetSettings(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	reviewers, err := s.reviewers(traceCtx, logger, settings)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+	if len(reviewers) == 0 {
+		err = internal.ErrNoReviewers
+		span.RecordError(err)
+		return 0, err
+	}
+
+	responseIDs, err := s.queries.ListUnassigned(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_assignments", "form_id", formID.String(), logger, "list unassigned responses")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	assigned := 0
+	for _, responseID := range responseIDs {
+		ok, err := s.assign(traceCtx, logger, settings, reviewers, responseID)
+		if err != nil {
+			span.RecordError(err)
+			return assigned, err
+		}
+		if ok {
+			assigned++
+		}
+	}
+
+	logger.Info("Distributed responses to reviewers", zap.String("form_id", formID.String()), zap.Int("assigned", assigned))
+
+	return assigned, nil
+}
+
+// assign reports whether the response was assigned by this call
+func (s *Service) assign(ctx context.Context, logger *zap.Logger, settings Settings, reviewers []uuid.UUID, responseID uuid.UUID) (bool, error) {
+	reviewerID, err := s.pick(ctx, logger, settings, reviewers, responseID)
+	if err != nil {
+		return false, err
+	}
+	if reviewerID == uuid.Nil {
+		logger.Warn("No reviewer to assign the response to", zap.String("form_id", settings.FormID.String()), zap.String("response_id", responseID.String()))
+		return false, nil
+	}
+
+	_, err = s.queries.Assign(ctx, AssignParams{
+		ResponseID: responseID,
+		FormID:     settings.FormID,
+		ReviewerID: reviewerID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Already assigned
+			return false, nil
+		}
+		return false, databaseutil.WrapDBErrorWithKeyValue(err, "response_assignments", "response_id", responseID.String(), logger, "assign response")
+	}
+
+	return true, nil
+}
+
+func (s *Service) pick(ctx context.Context, logger *zap.Logger, settings Settings, reviewers []uuid.UUID, responseID uuid.UUID) (uuid.UUID, error) {
+	if len(settings.Rules) > 0 {
+		rows, err := s.queries.ListAnswers(ctx, responseID)
+		if err != nil {
+			return uuid.Nil, databaseutil.WrapDBErrorWithKeyValue(err, "answers", "response_id", responseID.String(), logger, "list answers")
+		}
+		answers := make(map[uuid.UUID]string, len(rows))
+		for _, row := range rows {
+			answers[row.QuestionID] = row.Value
+		}
+		for _, rule := range settings.Rules {
+			if rule.matches(answers) {
+				return rule.ReviewerID, nil
+			}
+		}
+	}
+
+	if len(reviewers) == 0 {
+		return uuid.Nil, nil
+	}
+
+	switch settings.Strategy {
+	case AssignmentStrategyLeastLoaded:
+		workload, err := s.workload(ctx, logger, settings.FormID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		// reviewers is sorted, so ties go to the same reviewer every time
+		picked := reviewers[0]
+		for _, reviewerID := range reviewers[1:] {
+			if workload[reviewerID] < workload[picked] {
+				picked = reviewerID
+			}
+		}
+		return picked, nil
+	default:
+		index, err := s.queries.AdvanceCursor(ctx, settings.FormID)
+		if err != nil {
+			return uuid.Nil, databaseutil.WrapDBErrorWithKeyValue(err, "form_assignment_settings", "form_id", settings.FormID.String(), logger, "advance assignment cursor")
+		}
+		return reviewers[int(index)%len(reviewers)], nil
+	}
+}
+
+// reviewers lists the reviewers the strategy picks from, sorted by ID. Listed reviewers
+// who have since left the unit are skipped.
+func (s *Service) reviewers(ctx context.Context, logger *zap.Logger, settings Settings) ([]uuid.UUID, error) {
+	members, err := s.unitMembers(ctx, logger, settings.FormID)
+	if err != nil {
+		return nil, err
+	}
+	if len(settings.ReviewerIDs) == 0 {
+		return members, nil
+	}
+
+	reviewers := make([]uuid.UUID, 0, len(settings.ReviewerIDs))
+	for _, reviewerID := range settings.ReviewerIDs {
+		if slices.Contains(members, reviewerID) && !slices.Contains(reviewers, reviewerID) {
+			reviewers = append(reviewers, reviewerID)
+		}
+	}
+	slices.SortFunc(reviewers, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return reviewers, nil
+}
+
+func (s *Service) unitMembers(ctx context.Context, logger *zap.Logger, formID uuid.UUID) ([]uuid.UUID, error) {
+	unitID, err := s.queries.GetFormUnit(ctx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, internal.ErrFormNotFound
+		}
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "get form unit")
+	}
+	if !unitID.Valid {
+		return []uuid.UUID{}, nil
+	}
+
+	members, err := s.queries.ListUnitMembers(ctx, unitID.Bytes)
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", uuid.UUID(unitID.Bytes).String(), logger, "list unit members")
+	}
+	return members, nil
+}
+
+func (s *Service) workload(ctx context.Context, logger *zap.Logger, formID uuid.UUID) (map[uuid.UUID]int64, error) {
+	rows, err := s.queries.ListWorkload(ctx, formID)
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "response_assignments", "form_id", formID.String(), logger, "list workload")
+	}
+
+	workload := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		workload[row.ReviewerID] = row.Assigned
+	}
+	return workload, nil
+}
+
+// ListWorkload returns how many responses of the form each reviewer has, including
+// reviewers with none
+func (s *Service) ListWorkload(ctx context.Context, formID uuid.UUID) ([]Workload, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListWorkload")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	settings, err := s.GetSettings(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	reviewers, err := s.reviewers(traceCtx, logger, settings)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	workload, err := s.workload(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// Reviewers removed from the settings keep the responses they were given
+	for reviewerID := range workload {
+		if !slices.Contains(reviewers, reviewerID) {
+			reviewers = append(reviewers, reviewerID)
+		}
+	}
+
+	result := make([]Workload, len(reviewers))
+	for i, reviewerID := range reviewers {
+		result[i] = Workload{ReviewerID: reviewerID, Assigned: workload[reviewerID]}
+	}
+	return result, nil
+}
+
+// ListAssignedTo returns the responses of the form assigned to the reviewer, oldest
+// assignment first
+func (s *Service) ListAssignedTo(ctx context.Context, formID uuid.UUID, reviewerID uuid.UUID) ([]ListAssignedToRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListAssignedTo")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	rows, err := s.queries.ListAssignedTo(traceCtx, ListAssignedToParams{
+		FormID:     formID,
+		ReviewerID: reviewerID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_assignments", "form_id", formID.String(), logger, "list assigned responses")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return rows, nil
+}
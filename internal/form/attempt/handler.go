@@ -0,0 +1,144 @@
+package attempt
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Start(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Attempt, error)
+	Get(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Attempt, error)
+	Reset(ctx context.Context, formID uuid.UUID, userID uuid.UUID, resetBy uuid.UUID) error
+}
+
+// Response carries the server clock with the attempt, so the countdown of the client
+// does not depend on its own clock being right
+type Response struct {
+	FormID           string    `json:"formId"`
+	StartedAt        time.Time `json:"startedAt"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	TimeLimitSeconds int64     `json:"timeLimitSeconds"`
+	RemainingSeconds int64     `json:"remainingSeconds"`
+	ServerTime       time.Time `json:"serverTime"`
+}
+
+func ToResponse(attempt Attempt, now time.Time) Response {
+	remaining := attempt.ExpiresAt.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Response{
+		FormID:           attempt.FormID.String(),
+		StartedAt:        attempt.StartedAt,
+		ExpiresAt:        attempt.ExpiresAt,
+		TimeLimitSeconds: int64(attempt.TimeLimit / time.Second),
+		RemainingSeconds: int64(remaining / time.Second),
+		ServerTime:       now,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("attempt/handler"),
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) StartHandler(w http.ResponseWriter, r *http.Request) {
+	h.attempt(w, r, "StartHandler", h.store.Start)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	h.attempt(w, r, "GetHandler", h.store.Get)
+}
+
+func (h *Handler) attempt(
+	w http.ResponseWriter,
+	r *http.Request,
+	spanName string,
+	load func(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Attempt, error),
+) {
+	traceCtx, span := h.tracer.Start(r.Context(), spanName)
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	attempt, err := load(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(attempt, time.Now()))
+}
+
+// ResetHandler lets an org admin give a respondent the full time limit again
+func (h *Handler) ResetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ResetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	userID, err := internal.ParseUUID(r.PathValue("userId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Reset(traceCtx, formID, userID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
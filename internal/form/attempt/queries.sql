@@ -0,0 +1,25 @@
+-- name: GetTimeLimit :one
+SELECT time_limit_seconds FROM forms
+WHERE id = @form_id;
+
+-- name: Start :one
+INSERT INTO form_attempts (form_id, user_id)
+VALUES (@form_id, @user_id)
+ON CONFLICT (form_id, user_id) DO UPDATE SET started_at = form_attempts.started_at
+RETURNING *;
+
+-- name: Get :one
+SELECT * FROM form_attempts
+WHERE form_id = @form_id AND user_id = @user_id;
+
+-- name: Delete :execrows
+DELETE FROM form_attempts
+WHERE form_id = @form_id AND user_id = @user_id;
+
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
\ No newline at end of file
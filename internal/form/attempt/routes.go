@@ -0,0 +1,12 @@
+package attempt
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the timed attempts of a form
+func Routes(r route.Router, h *Handler) {
+	r.Handle("POST /forms/{id}/attempt", route.Respondent, route.PermissionNone, h.StartHandler)
+	r.Handle("GET /forms/{id}/attempt", route.Respondent, route.PermissionNone, h.GetHandler)
+	r.Handle("DELETE /forms/{id}/attempts/{userId}", route.Authenticated, route.PermissionOrgAdmin, h.ResetHandler)
+}
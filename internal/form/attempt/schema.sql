@@ -0,0 +1,8 @@
+-- When a respondent began a timed form. There is one clock per respondent, so
+-- reloading or switching devices does not restart it.
+CREATE TABLE IF NOT EXISTS form_attempts (
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (form_id, user_id)
+);
\ No newline at end of file
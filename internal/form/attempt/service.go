@@ -0,0 +1,212 @@
+package attempt
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// GracePeriod is how long after the time limit a submission is still accepted, for the
+// request in flight when the countdown of the respondent reaches zero
+const GracePeriod = 30 * time.Second
+
+type Querier interface {
+	GetTimeLimit(ctx context.Context, formID uuid.UUID) (pgtype.Int4, error)
+	Start(ctx context.Context, arg StartParams) (FormAttempt, error)
+	Get(ctx context.Context, arg GetParams) (FormAttempt, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+}
+
+// Attempt is the clock of a respondent on a timed form
+type Attempt struct {
+	FormID    uuid.UUID
+	UserID    uuid.UUID
+	StartedAt time.Time
+	ExpiresAt time.Time
+	TimeLimit time.Duration
+}
+
+func toAttempt(row FormAttempt, timeLimit time.Duration) Attempt {
+	return Attempt{
+		FormID:    row.FormID,
+		UserID:    row.UserID,
+		StartedAt: row.StartedAt.Time,
+		ExpiresAt: row.StartedAt.Time.Add(timeLimit),
+		TimeLimit: timeLimit,
+	}
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("attempt/service"),
+	}
+}
+
+// timeLimit returns the time limit of the form, 0 if it has none
+func (s *Service) timeLimit(ctx context.Context, logger *zap.Logger, formID uuid.UUID) (time.Duration, error) {
+	seconds, err := s.queries.GetTimeLimit(ctx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, internal.ErrFormNotFound
+		}
+		return 0, databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "get form time limit")
+	}
+	if !seconds.Valid {
+		return 0, nil
+	}
+	return time.Duration(seconds.Int32) * time.Second, nil
+}
+
+// Start starts the clock of the respondent on a timed form. Starting again returns the
+// attempt already running, so the clock cannot be reset by the respondent.
+func (s *Service) Start(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Attempt, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Start")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	timeLimit, err := s.timeLimit(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Attempt{}, err
+	}
+	if timeLimit == 0 {
+		err = internal.ErrFormNotTimed
+		span.RecordError(err)
+		return Attempt{}, err
+	}
+
+	row, err := s.queries.Start(traceCtx, StartParams{FormID: formID, UserID: userID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_attempts", "form_id", formID.String(), logger, "start attempt")
+		span.RecordError(err)
+		return Attempt{}, err
+	}
+
+	return toAttempt(row, timeLimit), nil
+}
+
+func (s *Service) Get(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Attempt, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	timeLimit, err := s.timeLimit(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Attempt{}, err
+	}
+	if timeLimit == 0 {
+		err = internal.ErrFormNotTimed
+		span.RecordError(err)
+		return Attempt{}, err
+	}
+
+	row, err := s.queries.Get(traceCtx, GetParams{FormID: formID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrAttemptNotStarted
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_attempts", "form_id", formID.String(), logger, "get attempt")
+		}
+		span.RecordError(err)
+		return Attempt{}, err
+	}
+
+	return toAttempt(row, timeLimit), nil
+}
+
+// Reset removes the attempt of a respondent, giving them the full time limit again. Only
+// admins of the organization of the form may, or respondents could restart their own
+// clock at will.
+func (s *Service) Reset(ctx context.Context, formID uuid.UUID, userID uuid.UUID, resetBy uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Reset")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	isAdmin, err := s.queries.IsFormOrgAdmin(traceCtx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: resetBy, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+		span.RecordError(err)
+		return err
+	}
+	if !isAdmin {
+		err = internal.ErrNotOrgAdmin
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.queries.Delete(traceCtx, DeleteParams{FormID: formID, UserID: userID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_attempts", "form_id", formID.String(), logger, "delete attempt")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		err = internal.ErrAttemptNotStarted
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Check reports whether the respondent may still submit the form at now: forms without
+// a time limit always pass, timed ones need an attempt whose time limit, plus the
+// grace period, has not yet passed
+func (s *Service) Check(ctx context.Context, formID uuid.UUID, userID uuid.UUID, now time.Time) error {
+	traceCtx, span := s.tracer.Start(ctx, "Check")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	timeLimit, err := s.timeLimit(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if timeLimit == 0 {
+		return nil
+	}
+
+	row, err := s.queries.Get(traceCtx, GetParams{FormID: formID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrAttemptNotStarted
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_attempts", "form_id", formID.String(), logger, "get attempt")
+		}
+		span.RecordError(err)
+		return err
+	}
+
+	attempt := toAttempt(row, timeLimit)
+	if now.After(attempt.ExpiresAt.Add(GracePeriod)) {
+		logger.Info("Rejected submission after the time limit", zap.String("form_id", formID.String()), zap.String("user_id", userID.String()), zap.Duration("late_by", now.Sub(attempt.ExpiresAt)))
+		err = internal.ErrTimeLimitExceeded
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package ballot
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ballots are held until ballotBatchSize of them are pending or the first of them waited
+// ballotBatchWindow, then written together in a random order in one transaction. Whoever
+// reads the table can tell the batch a ballot went into, no more, so the batch window is
+// kept long enough for voters casting around the same time to share one.
+const (
+	ballotBatchSize   = 16
+	ballotBatchWindow = 3 * time.Second
+)
+
+// pendingBallot is a ballot waiting for its batch; it holds nothing of its voter
+type pendingBallot struct {
+	formID      uuid.UUID
+	receiptHash []byte
+	answers     []CreateAnswerParams
+	done        chan error
+}
+
+// batcher writes ballots in shuffled batches, apart from the requests that cast them
+type batcher struct {
+	size   int
+	window time.Duration
+	store  func(ctx context.Context, ballots []*pendingBallot) error
+
+	mu      sync.Mutex
+	pending []*pendingBallot
+	timer   *time.Timer
+}
+
+func newBatcher(size int, window time.Duration, store func(ctx context.Context, ballots []*pendingBallot) error) *batcher {
+	return &batcher{
+		size:   size,
+		window: window,
+		store:  store,
+	}
+}
+
+// add queues the ballot and waits until the batch it went into is written, returning
+// the error of the batch
+func (b *batcher) add(ballot *pendingBallot) error {
+	ballot.done = make(chan error, 1)
+
+	b.mu.Lock()
+	b.pending = append(b.pending, ballot)
+	var batch []*pendingBallot
+	if len(b.pending) >= b.size {
+		batch = b.take()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.window, b.flush)
+	}
+	b.mu.Unlock()
+
+	if batch != nil {
+		b.write(batch)
+	}
+	return <-ballot.done
+}
+
+// take empties the pending ballots; b.mu must be held
+func (b *batcher) take() []*pendingBallot {
+	batch := b.pending
+	b.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return batch
+}
+
+func (b *batcher) flush() {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+
+	if len(batch) > 0 {
+		b.write(batch)
+	}
+}
+
+// write stores the batch in a random order and hands every ballot of it the outcome.
+// It runs on a context of its own: the context of the request that closed the batch
+// would tie its trace to every ballot of it.
+func (b *batcher) write(batch []*pendingBallot) {
+	rand.Shuffle(len(batch), func(i, j int) {
+		batch[i], batch[j] = batch[j], batch[i]
+	})
+
+	err := b.store(context.Background(), batch)
+	for _, ballot := range batch {
+		ballot.done <- err
+	}
+}
@@ -0,0 +1,90 @@
+package ballot
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatcher_Add(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name            string
+		size            int
+		ballots         int
+		expectedBatches []int
+	}
+
+	testCases := []testCase{
+		{name: "Full batch is written at once", size: 3, ballots: 3, expectedBatches: []int{3}},
+		{name: "Lone ballot is written after the window", size: 3, ballots: 1, expectedBatches: []int{1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := &fakeBallotStore{}
+			b := newBatcher(tc.size, 20*time.Millisecond, store.store)
+
+			var wg sync.WaitGroup
+			for range tc.ballots {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					require.NoError(t, b.add(&pendingBallot{formID: uuid.New()}))
+				}()
+			}
+			wg.Wait()
+
+			sizes := make([]int, len(store.batches))
+			for i, batch := range store.batches {
+				sizes[i] = len(batch)
+			}
+			require.Equal(t, tc.expectedBatches, sizes)
+		})
+	}
+}
+
+func TestBatcher_WriteShuffles(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeBallotStore{}
+	b := newBatcher(64, time.Hour, store.store)
+
+	batch := make([]*pendingBallot, 64)
+	for i := range batch {
+		batch[i] = &pendingBallot{formID: uuid.New(), done: make(chan error, 1)}
+	}
+	submitted := slices.Clone(batch)
+
+	b.write(batch)
+
+	require.Len(t, store.batches, 1)
+	require.ElementsMatch(t, submitted, store.batches[0])
+	// The chance of 64 ballots keeping their order is 1 in 64!
+	require.NotEqual(t, submitted, store.batches[0])
+}
+
+func TestBatcher_WriteReportsToEveryBallot(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeBallotStore{err: context.DeadlineExceeded}
+	b := newBatcher(2, time.Hour, store.store)
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.ErrorIs(t, b.add(&pendingBallot{formID: uuid.New()}), context.DeadlineExceeded)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,322 @@
+package ballot
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Get(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (FormBallot, error)
+	Enable(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (FormBallot, error)
+	Disable(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+	HasVoted(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (bool, error)
+	Cast(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam) (string, error)
+	Verify(ctx context.Context, formID uuid.UUID, receipt string) (bool, error)
+	Turnout(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Turnout, error)
+}
+
+type CastRequest struct {
+	Answers []AnswerRequest `json:"answers" validate:"required,dive"`
+}
+
+type AnswerRequest struct {
+	QuestionID string `json:"questionId" validate:"required,uuid"`
+	Value      string `json:"value" validate:"required"`
+}
+
+type VerifyRequest struct {
+	Receipt string `json:"receipt" validate:"required,max=64"`
+}
+
+type Response struct {
+	FormID    string    `json:"formId"`
+	EnabledBy *string   `json:"enabledBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type StatusResponse struct {
+	Voted bool `json:"voted"`
+}
+
+// CastResponse holds the receipt of the ballot, shown to the voter once to check later
+// that their ballot was counted
+type CastResponse struct {
+	Receipt string `json:"receipt,omitempty"`
+}
+
+type VerifyResponse struct {
+	Counted bool `json:"counted"`
+}
+
+type DayTurnoutResponse struct {
+	Day    time.Time `json:"day"`
+	Voters int64     `json:"voters"`
+}
+
+type TurnoutResponse struct {
+	Voters     int64                `json:"voters"`
+	Ballots    int64                `json:"ballots"`
+	Electorate int64                `json:"electorate"`
+	Rate       float64              `json:"rate"`
+	ByDay      []DayTurnoutResponse `json:"byDay"`
+}
+
+func ToResponse(ballot FormBallot) Response {
+	response := Response{
+		FormID:    ballot.FormID.String(),
+		CreatedAt: ballot.CreatedAt.Time,
+	}
+	if ballot.EnabledBy.Valid {
+		enabledBy := uuid.UUID(ballot.EnabledBy.Bytes).String()
+		response.EnabledBy = &enabledBy
+	}
+	return response
+}
+
+func ToTurnoutResponse(turnout Turnout) TurnoutResponse {
+	days := make([]DayTurnoutResponse, len(turnout.ByDay))
+	for i, day := range turnout.ByDay {
+		days[i] = DayTurnoutResponse{Day: day.Day, Voters: day.Voters}
+	}
+	return TurnoutResponse{
+		Voters:     turnout.Voters,
+		Ballots:    turnout.Ballots,
+		Electorate: turnout.Electorate,
+		Rate:       turnout.Rate(),
+		ByDay:      days,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("ballot/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	ballot, err := h.store.Get(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(ballot))
+}
+
+func (h *Handler) EnableHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "EnableHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	ballot, err := h.store.Enable(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(ballot))
+}
+
+func (h *Handler) DisableHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DisableHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Disable(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "StatusHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	voted, err := h.store.HasVoted(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, StatusResponse{Voted: voted})
+}
+
+func (h *Handler) CastHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CastHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req CastRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	answers := make([]shared.AnswerParam, len(req.Answers))
+	for i, answer := range req.Answers {
+		answers[i] = shared.AnswerParam{QuestionID: answer.QuestionID, Value: answer.Value}
+	}
+
+	receipt, err := h.store.Cast(traceCtx, formID, currentUser.ID, answers)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, CastResponse{Receipt: receipt})
+}
+
+// VerifyHandler takes the receipt in the body, keeping it out of access logs
+func (h *Handler) VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "VerifyHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req VerifyRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	counted, err := h.store.Verify(traceCtx, formID, req.Receipt)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, VerifyResponse{Counted: counted})
+}
+
+func (h *Handler) TurnoutHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "TurnoutHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	turnout, err := h.store.Turnout(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToTurnoutResponse(turnout))
+}
@@ -0,0 +1,85 @@
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
+
+-- name: Get :one
+SELECT * FROM form_ballots
+WHERE form_id = @form_id;
+
+-- name: Exists :one
+SELECT EXISTS(SELECT 1 FROM form_ballots WHERE form_id = @form_id);
+
+-- name: Create :one
+-- Returns no row when the form is in ballot mode already
+INSERT INTO form_ballots (form_id, enabled_by)
+VALUES (@form_id, @enabled_by)
+ON CONFLICT (form_id) DO NOTHING
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM form_ballots
+WHERE form_id = @form_id;
+
+-- name: HasAnonymousAccess :one
+SELECT EXISTS(SELECT 1 FROM form_public_access WHERE form_id = @form_id);
+
+-- name: HasResponses :one
+SELECT EXISTS(SELECT 1 FROM form_responses WHERE form_id = @form_id AND NOT is_test);
+
+-- name: CreateVoter :execrows
+INSERT INTO ballot_voters (form_id, user_id)
+VALUES (@form_id, @user_id)
+ON CONFLICT (form_id, user_id) DO NOTHING;
+
+-- name: DeleteVoter :exec
+-- Releases a voter whose ballot could not be stored, so they can vote again
+DELETE FROM ballot_voters
+WHERE form_id = @form_id AND user_id = @user_id;
+
+-- name: HasVoted :one
+SELECT EXISTS(SELECT 1 FROM ballot_voters WHERE form_id = @form_id AND user_id = @user_id);
+
+-- name: CreateBallot :one
+INSERT INTO ballots (form_id, receipt_hash)
+VALUES (@form_id, @receipt_hash)
+RETURNING id;
+
+-- name: CreateAnswer :exec
+INSERT INTO ballot_answers (ballot_id, question_id, value)
+VALUES (@ballot_id, @question_id, @value);
+
+-- name: BallotExists :one
+SELECT EXISTS(SELECT 1 FROM ballots WHERE form_id = @form_id AND receipt_hash = @receipt_hash);
+
+-- name: CountVoters :one
+SELECT COUNT(*) FROM ballot_voters
+WHERE form_id = @form_id;
+
+-- name: CountBallots :one
+SELECT COUNT(*) FROM ballots
+WHERE form_id = @form_id;
+
+-- name: CountMembers :one
+-- The current members of the unit of the form, who make up the electorate. The unit
+-- is the organization itself for forms of an organization.
+SELECT COUNT(*) FROM unit_members m
+JOIN forms f ON f.unit_id = m.unit_id
+WHERE f.id = @form_id AND (m.valid_until IS NULL OR m.valid_until > now());
+
+-- name: IsMember :one
+-- Whether the user is in the electorate CountMembers counts
+SELECT EXISTS(
+    SELECT 1 FROM unit_members m
+    JOIN forms f ON f.unit_id = m.unit_id
+    WHERE f.id = @form_id AND m.member_id = @user_id AND (m.valid_until IS NULL OR m.valid_until > now())
+);
+
+-- name: ListTurnoutByDay :many
+SELECT voted_on::timestamptz AS day, COUNT(*) AS voters FROM ballot_voters
+WHERE form_id = @form_id
+GROUP BY day
+ORDER BY day ASC;
\ No newline at end of file
@@ -0,0 +1,17 @@
+package ballot
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the ballot mode of forms, the casting and verifying of ballots and
+// the turnout of a vote
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/ballot", route.Authenticated, route.PermissionOrgAdmin, h.GetHandler)
+	r.Handle("PUT /forms/{id}/ballot", route.Authenticated, route.PermissionOrgAdmin, h.EnableHandler)
+	r.Handle("DELETE /forms/{id}/ballot", route.Authenticated, route.PermissionOrgAdmin, h.DisableHandler)
+	r.Handle("GET /forms/{id}/ballot/turnout", route.Authenticated, route.PermissionOrgAdmin, h.TurnoutHandler)
+	r.Handle("GET /forms/{id}/ballot/status", route.Respondent, route.PermissionSelf, h.StatusHandler)
+	r.Handle("POST /forms/{id}/ballot/cast", route.Respondent, route.PermissionNone, h.CastHandler)
+	r.Handle("POST /forms/{id}/ballot/verify", route.Authenticated, route.PermissionNone, h.VerifyHandler)
+}
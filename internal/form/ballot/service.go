@@ -0,0 +1,576 @@
+package ballot
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/eligibility"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+	Get(ctx context.Context, formID uuid.UUID) (FormBallot, error)
+	Exists(ctx context.Context, formID uuid.UUID) (bool, error)
+	Create(ctx context.Context, arg CreateParams) (FormBallot, error)
+	Delete(ctx context.Context, formID uuid.UUID) (int64, error)
+	HasAnonymousAccess(ctx context.Context, formID uuid.UUID) (bool, error)
+	HasResponses(ctx context.Context, formID uuid.UUID) (bool, error)
+	CreateVoter(ctx context.Context, arg CreateVoterParams) (int64, error)
+	DeleteVoter(ctx context.Context, arg DeleteVoterParams) error
+	HasVoted(ctx context.Context, arg HasVotedParams) (bool, error)
+	CreateBallot(ctx context.Context, arg CreateBallotParams) (uuid.UUID, error)
+	CreateAnswer(ctx context.Context, arg CreateAnswerParams) error
+	BallotExists(ctx context.Context, arg BallotExistsParams) (bool, error)
+	CountVoters(ctx context.Context, formID uuid.UUID) (int64, error)
+	CountBallots(ctx context.Context, formID uuid.UUID) (int64, error)
+	CountMembers(ctx context.Context, formID uuid.UUID) (int64, error)
+	IsMember(ctx context.Context, arg IsMemberParams) (bool, error)
+	ListTurnoutByDay(ctx context.Context, formID uuid.UUID) ([]ListTurnoutByDayRow, error)
+}
+
+// DB is the connection the service runs on; each batch of ballots is written in one
+// transaction begun on it, with their answers
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type FormStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (form.GetByIDRow, error)
+}
+
+type QuestionStore interface {
+	ListByFormID(ctx context.Context, formID uuid.UUID) ([]question.SectionWithQuestions, error)
+}
+
+type EligibilityStore interface {
+	Check(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (eligibility.Result, error)
+}
+
+// Turnout counts who voted. Electorate is the current members of the unit of the form.
+// Ballots trail Voters while a batch is pending, and stay short of it when the server
+// stopped before writing the batch of a voter who was counted.
+type Turnout struct {
+	Voters     int64
+	Ballots    int64
+	Electorate int64
+	ByDay      []DayTurnout
+}
+
+type DayTurnout struct {
+	Day    time.Time
+	Voters int64
+}
+
+// Rate is the share of the electorate that voted, 0 without an electorate
+func (t Turnout) Rate() float64 {
+	if t.Electorate == 0 {
+		return 0
+	}
+	return float64(t.Voters) / float64(t.Electorate)
+}
+
+type Service struct {
+	logger  *zap.Logger
+	db      DB
+	queries Querier
+	tracer  trace.Tracer
+	ballots *batcher
+
+	formStore        FormStore
+	questionStore    QuestionStore
+	eligibilityStore EligibilityStore
+}
+
+func NewService(logger *zap.Logger, db DB, formStore FormStore, questionStore QuestionStore, eligibilityStore EligibilityStore) *Service {
+	s := &Service{
+		logger:           logger,
+		db:               db,
+		queries:          New(db),
+		tracer:           otel.Tracer("ballot/service"),
+		formStore:        formStore,
+		questionStore:    questionStore,
+		eligibilityStore: eligibilityStore,
+	}
+	s.ballots = newBatcher(ballotBatchSize, ballotBatchWindow, s.storeBallots)
+	return s
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+// requireAdmin allows the owner of the organization of the form only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func (s *Service) requireBallot(ctx context.Context, logger *zap.Logger, formID uuid.UUID) error {
+	exists, err := s.queries.Exists(ctx, formID)
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "form_ballots", "form_id", formID.String(), logger, "check ballot mode")
+	}
+	if !exists {
+		return internal.ErrBallotNotFound
+	}
+	return nil
+}
+
+// hashReceipt is what a ballot keeps of its receipt, which is never stored
+func hashReceipt(receipt string) []byte {
+	sum := sha256.Sum256([]byte(receipt))
+	return sum[:]
+}
+
+func newReceipt() string {
+	receipt := make([]byte, 24)
+	_, _ = rand.Read(receipt)
+	return base64.RawURLEncoding.EncodeToString(receipt)
+}
+
+func (s *Service) Get(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (FormBallot, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return FormBallot{}, err
+	}
+
+	ballot, err := s.queries.Get(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrBallotNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_ballots", "form_id", formID.String(), logger, "get ballot mode")
+		}
+		span.RecordError(err)
+		return FormBallot{}, err
+	}
+	return ballot, nil
+}
+
+// Enable puts the form in ballot mode. A form that has responses already cannot be,
+// as they are tied to their respondents, nor can a form anyone may answer without an
+// account, as its voters are no electorate.
+func (s *Service) Enable(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (FormBallot, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Enable")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return FormBallot{}, err
+	}
+
+	hasResponses, err := s.queries.HasResponses(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "check responses")
+		span.RecordError(err)
+		return FormBallot{}, err
+	}
+	if hasResponses {
+		err = internal.ErrBallotFormHasResponses
+		span.RecordError(err)
+		return FormBallot{}, err
+	}
+
+	anonymous, err := s.queries.HasAnonymousAccess(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_public_access", "form_id", formID.String(), logger, "check anonymous responses")
+		span.RecordError(err)
+		return FormBallot{}, err
+	}
+	if anonymous {
+		err = internal.ErrBallotAnonymousAccess
+		span.RecordError(err)
+		return FormBallot{}, err
+	}
+
+	ballot, err := s.queries.Create(traceCtx, CreateParams{
+		FormID:    formID,
+		EnabledBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		ballot, err = s.queries.Get(traceCtx, formID)
+	}
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_ballots", "form_id", formID.String(), logger, "enable ballot mode")
+		span.RecordError(err)
+		return FormBallot{}, err
+	}
+
+	logger.Info("Enabled ballot mode", zap.String("form_id", formID.String()))
+	return ballot, nil
+}
+
+// Disable takes the form out of ballot mode, as long as nobody voted
+func (s *Service) Disable(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Disable")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	voters, err := s.queries.CountVoters(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "ballot_voters", "form_id", formID.String(), logger, "count voters")
+		span.RecordError(err)
+		return err
+	}
+	if voters > 0 {
+		err = internal.ErrBallotsCast
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.queries.Delete(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_ballots", "form_id", formID.String(), logger, "disable ballot mode")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		err = internal.ErrBallotNotFound
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// IsBallot tells whether the form takes ballots, which it then takes instead of responses
+func (s *Service) IsBallot(ctx context.Context, formID uuid.UUID) (bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "IsBallot")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	exists, err := s.queries.Exists(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_ballots", "form_id", formID.String(), logger, "check ballot mode")
+		span.RecordError(err)
+		return false, err
+	}
+	return exists, nil
+}
+
+// HasVoted tells the user whether they voted on the form
+func (s *Service) HasVoted(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "HasVoted")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireBallot(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	voted, err := s.queries.HasVoted(traceCtx, HasVotedParams{FormID: formID, UserID: userID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "ballot_voters", "form_id", formID.String(), logger, "check voter")
+		span.RecordError(err)
+		return false, err
+	}
+	return voted, nil
+}
+
+// validateAnswers checks the answers against the questions of the form, the way a
+// submitted response is
+func validateAnswers(sections []question.SectionWithQuestions, answers []shared.AnswerParam) ([]CreateAnswerParams, error) {
+	questions := make(map[string]question.Answerable)
+	for _, section := range sections {
+		for _, q := range section.Questions {
+			questions[q.Question().ID.String()] = q
+		}
+	}
+
+	var problems []string
+	params := make([]CreateAnswerParams, 0, len(answers))
+	answered := make(map[string]bool, len(answers))
+	for _, answer := range answers {
+		q, found := questions[answer.QuestionID]
+		if !found {
+			problems = append(problems, fmt.Sprintf("question with ID %s not found", answer.QuestionID))
+			continue
+		}
+		if answered[answer.QuestionID] {
+			problems = append(problems, fmt.Sprintf("question ID %s is answered twice", answer.QuestionID))
+			continue
+		}
+		answered[answer.QuestionID] = true
+
+		err := q.Validate(answer.Value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("validation error for question ID %s: %v", answer.QuestionID, err))
+			continue
+		}
+		params = append(params, CreateAnswerParams{QuestionID: q.Question().ID, Value: answer.Value})
+	}
+
+	for _, section := range sections {
+		for _, q := range section.Questions {
+			if q.Question().Required && !answered[q.Question().ID.String()] {
+				problems = append(problems, fmt.Sprintf("question ID %s is required but not answered", q.Question().ID))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", internal.ErrValidationFailed, strings.Join(problems, "; "))
+	}
+	return params, nil
+}
+
+// Cast records the vote of the user and returns the receipt of their ballot. Only the
+// current members of the unit of the form may vote. The user is marked as voted first,
+// so a second vote is refused, and the ballot is then handed to the batch writer, which
+// stores it without them in another transaction, shuffled among other ballots. A ballot
+// that cannot be stored releases its voter to vote again. A cast made with a preview
+// token is validated only, and returns no receipt.
+func (s *Service) Cast(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Cast")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireBallot(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	preview := internal.IsPreview(traceCtx)
+
+	if !preview {
+		formDetails, err := s.formStore.GetByID(traceCtx, formID)
+		if err != nil {
+			span.RecordError(err)
+			return "", err
+		}
+		if formDetails.Deadline.Valid && formDetails.Deadline.Time.Before(time.Now()) {
+			err = internal.ErrFormDeadlinePassed
+			span.RecordError(err)
+			return "", err
+		}
+
+		isMember, err := s.queries.IsMember(traceCtx, IsMemberParams{FormID: formID, UserID: userID})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "form_id", formID.String(), logger, "check voter membership")
+			span.RecordError(err)
+			return "", err
+		}
+		if !isMember {
+			err = internal.ErrBallotNotMember
+			span.RecordError(err)
+			return "", err
+		}
+
+		eligibilityResult, err := s.eligibilityStore.Check(traceCtx, formID, userID)
+		if err != nil {
+			span.RecordError(err)
+			return "", err
+		}
+		if !eligibilityResult.Eligible {
+			messages := make([]string, 0, len(eligibilityResult.Reasons))
+			for _, reason := range eligibilityResult.Reasons {
+				messages = append(messages, reason.Message)
+			}
+			err = fmt.Errorf("%w: %s", internal.ErrFormNotEligible, strings.Join(messages, "; "))
+			span.RecordError(err)
+			return "", err
+		}
+	}
+
+	sections, err := s.questionStore.ListByFormID(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	params, err := validateAnswers(sections, answers)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	if preview {
+		return "", nil
+	}
+
+	created, err := s.queries.CreateVoter(traceCtx, CreateVoterParams{FormID: formID, UserID: userID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "ballot_voters", "form_id", formID.String(), logger, "record voter")
+		span.RecordError(err)
+		return "", err
+	}
+	if created == 0 {
+		err = internal.ErrBallotAlreadyCast
+		span.RecordError(err)
+		return "", err
+	}
+
+	receipt := newReceipt()
+	err = s.ballots.add(&pendingBallot{formID: formID, receiptHash: hashReceipt(receipt), answers: params})
+	if err != nil {
+		releaseErr := s.queries.DeleteVoter(context.WithoutCancel(traceCtx), DeleteVoterParams{FormID: formID, UserID: userID})
+		if releaseErr != nil {
+			logger.Error("Failed to release the voter of an unstored ballot", zap.String("form_id", formID.String()), zap.Error(releaseErr))
+		}
+		span.RecordError(err)
+		return "", err
+	}
+
+	// Neither the user nor the ballot is logged, to keep them apart in the logs too
+	logger.Info("Cast ballot", zap.String("form_id", formID.String()))
+	return receipt, nil
+}
+
+// storeBallots writes a batch of ballots with their answers, in the order given
+func (s *Service) storeBallots(ctx context.Context, ballots []*pendingBallot) error {
+	return s.inTx(ctx, s.logger, func(queries Querier) error {
+		for _, ballot := range ballots {
+			ballotID, err := queries.CreateBallot(ctx, CreateBallotParams{FormID: ballot.formID, ReceiptHash: ballot.receiptHash})
+			if err != nil {
+				return databaseutil.WrapDBErrorWithKeyValue(err, "ballots", "form_id", ballot.formID.String(), s.logger, "cast ballot")
+			}
+
+			for _, param := range ballot.answers {
+				param.BallotID = ballotID
+				err = queries.CreateAnswer(ctx, param)
+				if err != nil {
+					return databaseutil.WrapDBErrorWithKeyValue(err, "ballot_answers", "question_id", param.QuestionID.String(), s.logger, "record ballot answer")
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Verify tells whether a ballot with the receipt was counted for the form
+func (s *Service) Verify(ctx context.Context, formID uuid.UUID, receipt string) (bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Verify")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireBallot(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return false, err
+	}
+
+	exists, err := s.queries.BallotExists(traceCtx, BallotExistsParams{FormID: formID, ReceiptHash: hashReceipt(receipt)})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "ballots", "form_id", formID.String(), logger, "verify ballot")
+		span.RecordError(err)
+		return false, err
+	}
+	return exists, nil
+}
+
+// Turnout returns how many voted; the ballots themselves are tallied by the results of
+// the form
+func (s *Service) Turnout(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Turnout, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Turnout")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Turnout{}, err
+	}
+
+	err = s.requireBallot(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Turnout{}, err
+	}
+
+	var turnout Turnout
+	turnout.Voters, err = s.queries.CountVoters(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "ballot_voters", "form_id", formID.String(), logger, "count voters")
+		span.RecordError(err)
+		return Turnout{}, err
+	}
+
+	turnout.Ballots, err = s.queries.CountBallots(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "ballots", "form_id", formID.String(), logger, "count ballots")
+		span.RecordError(err)
+		return Turnout{}, err
+	}
+
+	turnout.Electorate, err = s.queries.CountMembers(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "form_id", formID.String(), logger, "count electorate")
+		span.RecordError(err)
+		return Turnout{}, err
+	}
+
+	days, err := s.queries.ListTurnoutByDay(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "ballot_voters", "form_id", formID.String(), logger, "list turnout")
+		span.RecordError(err)
+		return Turnout{}, err
+	}
+	turnout.ByDay = make([]DayTurnout, len(days))
+	for i, day := range days {
+		turnout.ByDay[i] = DayTurnout{Day: day.Day.Time, Voters: day.Voters}
+	}
+
+	return turnout, nil
+}
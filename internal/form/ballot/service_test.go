@@ -0,0 +1,270 @@
+package ballot
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/eligibility"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+// fakeQuerier keeps the voters of one form in ballot mode
+type fakeQuerier struct {
+	Querier
+	member    bool
+	anonymous bool
+
+	mu       sync.Mutex
+	voters   map[uuid.UUID]bool
+	released []uuid.UUID
+}
+
+func (q *fakeQuerier) IsFormOrgAdmin(context.Context, IsFormOrgAdminParams) (bool, error) {
+	return true, nil
+}
+
+func (q *fakeQuerier) Exists(context.Context, uuid.UUID) (bool, error) {
+	return true, nil
+}
+
+func (q *fakeQuerier) HasResponses(context.Context, uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (q *fakeQuerier) HasAnonymousAccess(context.Context, uuid.UUID) (bool, error) {
+	return q.anonymous, nil
+}
+
+func (q *fakeQuerier) Create(_ context.Context, arg CreateParams) (FormBallot, error) {
+	return FormBallot{FormID: arg.FormID, EnabledBy: arg.EnabledBy}, nil
+}
+
+func (q *fakeQuerier) IsMember(context.Context, IsMemberParams) (bool, error) {
+	return q.member, nil
+}
+
+func (q *fakeQuerier) CreateVoter(_ context.Context, arg CreateVoterParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.voters[arg.UserID] {
+		return 0, nil
+	}
+	q.voters[arg.UserID] = true
+	return 1, nil
+}
+
+func (q *fakeQuerier) DeleteVoter(_ context.Context, arg DeleteVoterParams) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.voters[arg.UserID] = false
+	q.released = append(q.released, arg.UserID)
+	return nil
+}
+
+type fakeFormStore struct{}
+
+func (fakeFormStore) GetByID(context.Context, uuid.UUID) (form.GetByIDRow, error) {
+	return form.GetByIDRow{}, nil
+}
+
+type fakeQuestionStore struct{}
+
+func (fakeQuestionStore) ListByFormID(context.Context, uuid.UUID) ([]question.SectionWithQuestions, error) {
+	return nil, nil
+}
+
+type fakeEligibilityStore struct{}
+
+func (fakeEligibilityStore) Check(context.Context, uuid.UUID, uuid.UUID) (eligibility.Result, error) {
+	return eligibility.Result{Eligible: true}, nil
+}
+
+// fakeBallotStore records the batches written, failing them with err
+type fakeBallotStore struct {
+	err error
+
+	mu      sync.Mutex
+	batches [][]*pendingBallot
+}
+
+func (s *fakeBallotStore) store(_ context.Context, ballots []*pendingBallot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]*pendingBallot(nil), ballots...))
+	return s.err
+}
+
+func newTestService(queries *fakeQuerier, store *fakeBallotStore, batchSize int) *Service {
+	if queries.voters == nil {
+		queries.voters = make(map[uuid.UUID]bool)
+	}
+	return &Service{
+		logger:           zap.NewNop(),
+		queries:          queries,
+		tracer:           otel.Tracer("test"),
+		ballots:          newBatcher(batchSize, 10*time.Millisecond, store.store),
+		formStore:        fakeFormStore{},
+		questionStore:    fakeQuestionStore{},
+		eligibilityStore: fakeEligibilityStore{},
+	}
+}
+
+func TestService_Cast(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name             string
+		member           bool
+		votedBefore      bool
+		storeErr         error
+		expectedErr      error
+		expectedBatches  int
+		expectedVoter    bool
+		expectedReleased bool
+	}
+
+	testCases := []testCase{
+		{name: "Member casts a ballot", member: true, expectedBatches: 1, expectedVoter: true},
+		{name: "Non-member is refused", member: false, expectedErr: internal.ErrBallotNotMember},
+		{name: "Second ballot is refused", member: true, votedBefore: true, expectedErr: internal.ErrBallotAlreadyCast, expectedVoter: true},
+		{
+			name:             "Unstored ballot releases its voter",
+			member:           true,
+			storeErr:         errors.New("connection reset"),
+			expectedErr:      errors.New("connection reset"),
+			expectedBatches:  1,
+			expectedReleased: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			formID := uuid.New()
+			userID := uuid.New()
+			queries := &fakeQuerier{member: tc.member, voters: map[uuid.UUID]bool{userID: tc.votedBefore}}
+			store := &fakeBallotStore{err: tc.storeErr}
+			service := newTestService(queries, store, 1)
+
+			receipt, err := service.Cast(context.Background(), formID, userID, nil)
+			require.Len(t, store.batches, tc.expectedBatches)
+			require.Equal(t, tc.expectedVoter, queries.voters[userID])
+			if tc.expectedReleased {
+				require.Equal(t, []uuid.UUID{userID}, queries.released)
+			} else {
+				require.Empty(t, queries.released)
+			}
+			if tc.expectedErr != nil {
+				require.ErrorContains(t, err, tc.expectedErr.Error())
+				require.Empty(t, receipt)
+				return
+			}
+			require.NoError(t, err)
+
+			ballot := store.batches[0][0]
+			require.Equal(t, formID, ballot.formID)
+			require.Equal(t, hashReceipt(receipt), ballot.receiptHash)
+		})
+	}
+}
+
+// TestService_CastSharesBatches casts the ballots of concurrent voters, which are written
+// together in one batch, after each voter was recorded on their own
+func TestService_CastSharesBatches(t *testing.T) {
+	t.Parallel()
+
+	const voters = 4
+
+	queries := &fakeQuerier{member: true}
+	store := &fakeBallotStore{}
+	service := newTestService(queries, store, voters)
+	formID := uuid.New()
+
+	var wg sync.WaitGroup
+	receipts := make([]string, voters)
+	for i := range voters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			receipt, err := service.Cast(context.Background(), formID, uuid.New(), nil)
+			require.NoError(t, err)
+			receipts[i] = receipt
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, queries.voters, voters)
+	require.Len(t, store.batches, 1)
+	require.Len(t, store.batches[0], voters)
+
+	stored := make(map[string]bool, voters)
+	for _, ballot := range store.batches[0] {
+		stored[string(ballot.receiptHash)] = true
+	}
+	for _, receipt := range receipts {
+		require.True(t, stored[string(hashReceipt(receipt))])
+	}
+}
+
+// TestPendingBallot_HoldsNothingOfItsVoter guards what reaches the batch writer: a new
+// field is a deliberate change to what a ballot could be linked with
+func TestPendingBallot_HoldsNothingOfItsVoter(t *testing.T) {
+	t.Parallel()
+
+	ballotType := reflect.TypeOf(pendingBallot{})
+	fields := make([]string, ballotType.NumField())
+	for i := range fields {
+		fields[i] = ballotType.Field(i).Name
+	}
+	require.Equal(t, []string{"formID", "receiptHash", "answers", "done"}, fields)
+
+	answerType := reflect.TypeOf(CreateAnswerParams{})
+	for i := range answerType.NumField() {
+		require.NotContains(t, []string{"UserID", "RespondentID", "CreatedAt"}, answerType.Field(i).Name)
+	}
+}
+
+func TestService_Enable(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		anonymous   bool
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{name: "Form for members only", anonymous: false},
+		{name: "Form with anonymous access", anonymous: true, expectedErr: internal.ErrBallotAnonymousAccess},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := newTestService(&fakeQuerier{anonymous: tc.anonymous}, &fakeBallotStore{}, 1)
+			formID := uuid.New()
+			userID := uuid.New()
+
+			ballot, err := service.Enable(context.Background(), formID, userID)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, FormBallot{FormID: formID, EnabledBy: pgtype.UUID{Bytes: userID, Valid: true}}, ballot)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package checkin
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/qrcode"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	FormQRCode(ctx context.Context, formID uuid.UUID, scale int) ([]byte, error)
+	ReceiptQRCode(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID, scale int) ([]byte, error)
+	CheckIn(ctx context.Context, formID uuid.UUID, code string, userID uuid.UUID) (Result, error)
+	List(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]ListByFormRow, error)
+}
+
+// Request holds the receipt code read from the QR code a respondent shows
+type Request struct {
+	Code string `json:"code" validate:"required,max=128"`
+}
+
+type Response struct {
+	ResponseID       string    `json:"responseId"`
+	SubmittedBy      string    `json:"submittedBy"`
+	CheckedInBy      *string   `json:"checkedInBy,omitempty"`
+	CheckedInAt      time.Time `json:"checkedInAt"`
+	AlreadyCheckedIn bool      `json:"alreadyCheckedIn"`
+}
+
+type ListResponse struct {
+	ResponseID  string    `json:"responseId"`
+	SubmittedBy string    `json:"submittedBy"`
+	Name        string    `json:"name"`
+	Username    string    `json:"username"`
+	CheckedInBy *string   `json:"checkedInBy,omitempty"`
+	CheckedInAt time.Time `json:"checkedInAt"`
+}
+
+func toOptionalString(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	value := uuid.UUID(id.Bytes).String()
+	return &value
+}
+
+func ToResponse(result Result) Response {
+	return Response{
+		ResponseID:       result.ResponseID.String(),
+		SubmittedBy:      result.SubmittedBy.String(),
+		CheckedInBy:      toOptionalString(result.CheckedInBy),
+		CheckedInAt:      result.CheckedInAt.Time,
+		AlreadyCheckedIn: result.AlreadyCheckedIn,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("checkin/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+// parseScale reads the optional scale query parameter, the side of a module in pixels
+func parseScale(r *http.Request) (int, error) {
+	scaleParam := r.URL.Query().Get("scale")
+	if scaleParam == "" {
+		return qrcode.DefaultScale, nil
+	}
+	scale, err := strconv.Atoi(scaleParam)
+	if err != nil || scale < 1 || scale > qrcode.MaxScale {
+		return 0, fmt.Errorf("%w: scale must be between 1 and %d", internal.ErrInvalidQueryParameter, qrcode.MaxScale)
+	}
+	return scale, nil
+}
+
+func (h *Handler) writePNG(w http.ResponseWriter, image []byte, logger *zap.Logger) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(image)
+	if err != nil {
+		logger.Error("failed to write QR code", zap.Error(err))
+	}
+}
+
+func (h *Handler) FormQRCodeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "FormQRCodeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	scale, err := parseScale(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	image, err := h.store.FormQRCode(traceCtx, formID, scale)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	h.writePNG(w, image, logger)
+}
+
+func (h *Handler) ReceiptQRCodeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ReceiptQRCodeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responseID, err := internal.ParseUUID(r.PathValue("responseId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	scale, err := parseScale(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	image, err := h.store.ReceiptQRCode(traceCtx, formID, responseID, currentUser.ID, scale)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	h.writePNG(w, image, logger)
+}
+
+// CheckInHandler answers 201 for a new check-in and 200 for a receipt scanned again
+func (h *Handler) CheckInHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CheckInHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	result, err := h.store.CheckIn(traceCtx, formID, req.Code, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	status := http.StatusCreated
+	if result.AlreadyCheckedIn {
+		status = http.StatusOK
+	}
+	handlerutil.WriteJSONResponse(w, status, ToResponse(result))
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	checkins, err := h.store.List(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]ListResponse, len(checkins))
+	for i, checkin := range checkins {
+		responses[i] = ListResponse{
+			ResponseID:  checkin.ResponseID.String(),
+			SubmittedBy: checkin.SubmittedBy.String(),
+			Name:        checkin.Name.String,
+			Username:    checkin.Username.String,
+			CheckedInBy: toOptionalString(checkin.CheckedInBy),
+			CheckedInAt: checkin.CheckedInAt.Time,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
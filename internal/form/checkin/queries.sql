@@ -0,0 +1,32 @@
+-- name: FormExists :one
+SELECT EXISTS(SELECT 1 FROM forms WHERE id = @form_id);
+
+-- name: GetResponse :one
+SELECT id, submitted_by FROM form_responses
+WHERE id = @response_id AND form_id = @form_id;
+
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
+
+-- name: Create :one
+-- Returns no row when the response already checked in
+INSERT INTO form_checkins (response_id, form_id, checked_in_by)
+VALUES (@response_id, @form_id, @checked_in_by)
+ON CONFLICT (response_id) DO NOTHING
+RETURNING *;
+
+-- name: Get :one
+SELECT * FROM form_checkins
+WHERE response_id = @response_id;
+
+-- name: ListByForm :many
+SELECT c.response_id, c.checked_in_by, c.checked_in_at, r.submitted_by, u.name, u.username FROM form_checkins c
+JOIN form_responses r ON r.id = c.response_id
+LEFT JOIN users u ON u.id = r.submitted_by
+WHERE c.form_id = @form_id
+ORDER BY c.checked_in_at DESC;
\ No newline at end of file
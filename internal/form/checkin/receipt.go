@@ -0,0 +1,47 @@
+package checkin
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// signatureLength is the bytes of the HMAC kept in a receipt code, short enough for a
+// small QR code and still out of reach of guessing
+const signatureLength = 16
+
+// sign binds the response to its form, so that a code scanned at another event is rejected
+func sign(secret []byte, formID uuid.UUID, responseID uuid.UUID) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("checkin\n" + formID.String() + "\n" + responseID.String()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLength])
+}
+
+// receiptCode is what the check-in QR code of a response holds, written
+// "<responseId>.<signature>"
+func receiptCode(secret []byte, formID uuid.UUID, responseID uuid.UUID) string {
+	return responseID.String() + "." + sign(secret, formID, responseID)
+}
+
+// parseReceiptCode returns the response a receipt code of the form was issued for
+func parseReceiptCode(secret []byte, formID uuid.UUID, code string) (uuid.UUID, error) {
+	id, signature, ok := strings.Cut(strings.TrimSpace(code), ".")
+	if !ok {
+		return uuid.Nil, internal.ErrCheckinCodeInvalid
+	}
+
+	responseID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, internal.ErrCheckinCodeInvalid
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(secret, formID, responseID))) {
+		return uuid.Nil, internal.ErrCheckinCodeInvalid
+	}
+
+	return responseID, nil
+}
@@ -0,0 +1,14 @@
+package checkin
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the QR codes of a form and of its receipts, and the check-ins
+// taken by scanning them, which paired kiosks may take as well
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/qrcode.png", route.Authenticated, route.PermissionNone, h.FormQRCodeHandler)
+	r.Handle("GET /forms/{formId}/responses/{responseId}/checkin.png", route.Authenticated, route.PermissionSelf, h.ReceiptQRCodeHandler)
+	r.Handle("POST /forms/{id}/checkin", route.Kiosk, route.PermissionOrgAdmin, h.CheckInHandler)
+	r.Handle("GET /forms/{id}/checkins", route.Kiosk, route.PermissionOrgAdmin, h.ListHandler)
+}
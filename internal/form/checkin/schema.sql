@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS form_checkins (
+    response_id UUID PRIMARY KEY REFERENCES form_responses(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    checked_in_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    checked_in_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_checkins_form_id ON form_checkins(form_id, checked_in_at);
\ No newline at end of file
@@ -0,0 +1,212 @@
+package checkin
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/qrcode"
+	"context"
+	"errors"
+	"strings"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	FormExists(ctx context.Context, formID uuid.UUID) (bool, error)
+	GetResponse(ctx context.Context, arg GetResponseParams) (GetResponseRow, error)
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+	Create(ctx context.Context, arg CreateParams) (FormCheckin, error)
+	Get(ctx context.Context, responseID uuid.UUID) (FormCheckin, error)
+	ListByForm(ctx context.Context, formID uuid.UUID) ([]ListByFormRow, error)
+}
+
+// Result is the check-in of a scanned receipt. AlreadyCheckedIn is set when the response
+// had checked in before, the check-in being the first one.
+type Result struct {
+	FormCheckin
+	SubmittedBy      uuid.UUID
+	AlreadyCheckedIn bool
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+
+	secret  []byte
+	baseURL string
+}
+
+func NewService(logger *zap.Logger, db DBTX, secret string, baseURL string) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("checkin/service"),
+		secret:  []byte(secret),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// FormLink is the address respondents open the form at
+func (s *Service) FormLink(formID uuid.UUID) string {
+	return s.baseURL + "/forms/" + formID.String()
+}
+
+// requireAdmin allows the owner of the organization of the form only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func (s *Service) getResponse(ctx context.Context, logger *zap.Logger, formID uuid.UUID, responseID uuid.UUID) (GetResponseRow, error) {
+	response, err := s.queries.GetResponse(ctx, GetResponseParams{ResponseID: responseID, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return GetResponseRow{}, internal.ErrResponseNotFound
+		}
+		return GetResponseRow{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "get response")
+	}
+	return response, nil
+}
+
+// FormQRCode renders the link to the form as a PNG QR code, for posters and slides
+func (s *Service) FormQRCode(ctx context.Context, formID uuid.UUID, scale int) ([]byte, error) {
+	traceCtx, span := s.tracer.Start(ctx, "FormQRCode")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	exists, err := s.queries.FormExists(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check form")
+		span.RecordError(err)
+		return nil, err
+	}
+	if !exists {
+		err = internal.ErrFormNotFound
+		span.RecordError(err)
+		return nil, err
+	}
+
+	image, err := qrcode.EncodePNG(s.FormLink(formID), scale)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return image, nil
+}
+
+// ReceiptQRCode renders the receipt code of the response as a PNG QR code, which the
+// respondent shows to be checked in. Only the respondent and the organization admins
+// may get it.
+func (s *Service) ReceiptQRCode(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID, scale int) ([]byte, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ReceiptQRCode")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	response, err := s.getResponse(traceCtx, logger, formID, responseID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if response.SubmittedBy != userID {
+		err = s.requireAdmin(traceCtx, logger, formID, userID)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	image, err := qrcode.EncodePNG(receiptCode(s.secret, formID, response.ID), scale)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return image, nil
+}
+
+// CheckIn records the attendance of the response a scanned receipt code was issued
+// for. Scanning a receipt again is not an error; the first check-in is returned.
+func (s *Service) CheckIn(ctx context.Context, formID uuid.UUID, code string, userID uuid.UUID) (Result, error) {
+	traceCtx, span := s.tracer.Start(ctx, "CheckIn")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	responseID, err := parseReceiptCode(s.secret, formID, code)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	response, err := s.getResponse(traceCtx, logger, formID, responseID)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	checkin, err := s.queries.Create(traceCtx, CreateParams{
+		ResponseID:  response.ID,
+		FormID:      formID,
+		CheckedInBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err == nil {
+		logger.Info("Checked in response", zap.String("form_id", formID.String()), zap.String("response_id", response.ID.String()))
+		return Result{FormCheckin: checkin, SubmittedBy: response.SubmittedBy}, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_checkins", "response_id", response.ID.String(), logger, "create check-in")
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	checkin, err = s.queries.Get(traceCtx, response.ID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_checkins", "response_id", response.ID.String(), logger, "get check-in")
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	return Result{FormCheckin: checkin, SubmittedBy: response.SubmittedBy, AlreadyCheckedIn: true}, nil
+}
+
+// List returns the check-ins of the form, latest first
+func (s *Service) List(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]ListByFormRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	checkins, err := s.queries.ListByForm(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_checkins", "form_id", formID.String(), logger, "list check-ins")
+		span.RecordError(err)
+		return nil, err
+	}
+	return checkins, nil
+}
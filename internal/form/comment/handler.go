@@ -0,0 +1,210 @@
+package comment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Create(ctx context.Context, input CreateInput) (AnswerComment, error)
+	List(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, questionID uuid.UUID, userID uuid.UUID) ([]AnswerComment, error)
+}
+
+type CreateRequest struct {
+	ParentID            string `json:"parentId" validate:"omitempty,uuid"`
+	Content             string `json:"content" validate:"required,max=2000"`
+	VisibleToRespondent bool   `json:"visibleToRespondent"`
+}
+
+type Response struct {
+	ID                  string     `json:"id"`
+	ParentID            *string    `json:"parentId,omitempty"`
+	AuthorID            *string    `json:"authorId,omitempty"`
+	Content             string     `json:"content"`
+	VisibleToRespondent bool       `json:"visibleToRespondent"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	Replies             []Response `json:"replies"`
+}
+
+func ToResponse(comment AnswerComment) Response {
+	response := Response{
+		ID:                  comment.ID.String(),
+		Content:             comment.Content,
+		VisibleToRespondent: comment.VisibleToRespondent,
+		CreatedAt:           comment.CreatedAt.Time,
+		Replies:             []Response{},
+	}
+	if comment.ParentID.Valid {
+		parentID := uuid.UUID(comment.ParentID.Bytes).String()
+		response.ParentID = &parentID
+	}
+	if comment.AuthorID.Valid {
+		authorID := uuid.UUID(comment.AuthorID.Bytes).String()
+		response.AuthorID = &authorID
+	}
+	return response
+}
+
+// ToThreads nests replies under the comment they reply to. Comments are expected oldest
+// first; replies whose parent is not in the list are dropped.
+func ToThreads(comments []AnswerComment) []Response {
+	children := make(map[uuid.UUID][]AnswerComment)
+	var roots []AnswerComment
+	for _, comment := range comments {
+		if comment.ParentID.Valid {
+			parentID := uuid.UUID(comment.ParentID.Bytes)
+			children[parentID] = append(children[parentID], comment)
+			continue
+		}
+		roots = append(roots, comment)
+	}
+
+	var build func(comment AnswerComment) Response
+	build = func(comment AnswerComment) Response {
+		response := ToResponse(comment)
+		for _, reply := range children[comment.ID] {
+			response.Replies = append(response.Replies, build(reply))
+		}
+		return response
+	}
+
+	threads := make([]Response, len(roots))
+	for i, root := range roots {
+		threads[i] = build(root)
+	}
+	return threads
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("comment/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+// CreateHandler adds a reviewer comment to an answer of a response
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, responseID, questionID, err := parseAnswerPath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req CreateRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	parentID := uuid.Nil
+	if req.ParentID != "" {
+		parentID, err = internal.ParseUUID(req.ParentID)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	comment, err := h.store.Create(traceCtx, CreateInput{
+		FormID:              formID,
+		ResponseID:          responseID,
+		QuestionID:          questionID,
+		ParentID:            parentID,
+		AuthorID:            currentUser.ID,
+		Content:             req.Content,
+		VisibleToRespondent: req.VisibleToRespondent,
+	})
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(comment))
+}
+
+// ListHandler returns the comment threads on an answer that the current user can see
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, responseID, questionID, err := parseAnswerPath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	comments, err := h.store.List(traceCtx, formID, responseID, questionID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToThreads(comments))
+}
+
+func parseAnswerPath(r *http.Request) (uuid.UUID, uuid.UUID, uuid.UUID, error) {
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, err
+	}
+
+	responseID, err := internal.ParseUUID(r.PathValue("responseId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, err
+	}
+
+	questionID, err := internal.ParseUUID(r.PathValue("questionId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, err
+	}
+
+	return formID, responseID, questionID, nil
+}
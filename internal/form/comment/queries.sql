@@ -0,0 +1,24 @@
+-- name: Create :one
+INSERT INTO answer_comments (response_id, question_id, parent_id, author_id, content, visible_to_respondent)
+VALUES (@response_id, @question_id, @parent_id, @author_id, @content, @visible_to_respondent)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM answer_comments
+WHERE id = @id;
+
+-- name: ListByAnswer :many
+SELECT * FROM answer_comments
+WHERE response_id = @response_id AND question_id = @question_id
+ORDER BY created_at ASC;
+
+-- name: GetResponseOwner :one
+SELECT r.submitted_by, f.unit_id FROM form_responses r
+JOIN forms f ON f.id = r.form_id
+WHERE r.id = @response_id AND r.form_id = @form_id;
+
+-- name: AnswerExists :one
+SELECT EXISTS(SELECT 1 FROM answers WHERE response_id = @response_id AND question_id = @question_id);
+
+-- name: IsUnitMember :one
+SELECT EXISTS(SELECT 1 FROM unit_members WHERE unit_id = @unit_id AND member_id = @member_id);
\ No newline at end of file
@@ -0,0 +1,11 @@
+package comment
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the reviewer comments on an answer
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{formId}/responses/{responseId}/answers/{questionId}/comments", route.Authenticated, route.PermissionReviewer, h.ListHandler)
+	r.Handle("POST /forms/{formId}/responses/{responseId}/answers/{questionId}/comments", route.Authenticated, route.PermissionReviewer, h.CreateHandler)
+}
@@ -0,0 +1,185 @@
+package comment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"fmt"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (AnswerComment, error)
+	GetByID(ctx context.Context, id uuid.UUID) (AnswerComment, error)
+	ListByAnswer(ctx context.Context, arg ListByAnswerParams) ([]AnswerComment, error)
+	GetResponseOwner(ctx context.Context, arg GetResponseOwnerParams) (GetResponseOwnerRow, error)
+	AnswerExists(ctx context.Context, arg AnswerExistsParams) (bool, error)
+	IsUnitMember(ctx context.Context, arg IsUnitMemberParams) (bool, error)
+}
+
+// CreateInput is a comment to attach to the answer of a question in a response.
+// ParentID is uuid.Nil for a comment that starts a new thread.
+type CreateInput struct {
+	FormID              uuid.UUID
+	ResponseID          uuid.UUID
+	QuestionID          uuid.UUID
+	ParentID            uuid.UUID
+	AuthorID            uuid.UUID
+	Content             string
+	VisibleToRespondent bool
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("comment/service"),
+	}
+}
+
+// Create adds a comment to an answer. Only reviewers, the members of the unit owning
+// the form, can comment. A reply is only visible to the respondent when the comment
+// it replies to is visible as well.
+func (s *Service) Create(ctx context.Context, input CreateInput) (AnswerComment, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	isReviewer, _, err := s.access(ctx, input.FormID, input.ResponseID, input.AuthorID)
+	if err != nil {
+		span.RecordError(err)
+		return AnswerComment{}, err
+	}
+	if !isReviewer {
+		err = fmt.Errorf("%w: only reviewers can comment on answers", internal.ErrPermissionDenied)
+		span.RecordError(err)
+		return AnswerComment{}, err
+	}
+
+	exists, err := s.queries.AnswerExists(ctx, AnswerExistsParams{
+		ResponseID: input.ResponseID,
+		QuestionID: input.QuestionID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answers", "response_id", input.ResponseID.String(), logger, "check if answer exists")
+		span.RecordError(err)
+		return AnswerComment{}, err
+	}
+	if !exists {
+		err = fmt.Errorf("%w: response %s has no answer to question %s", internal.ErrAnswerNotFound, input.ResponseID, input.QuestionID)
+		span.RecordError(err)
+		return AnswerComment{}, err
+	}
+
+	visible := input.VisibleToRespondent
+	if input.ParentID != uuid.Nil {
+		parent, err := s.queries.GetByID(ctx, input.ParentID)
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "answer_comments", "id", input.ParentID.String(), logger, "get parent comment")
+			span.RecordError(err)
+			return AnswerComment{}, err
+		}
+		if parent.ResponseID != input.ResponseID || parent.QuestionID != input.QuestionID {
+			err = fmt.Errorf("%w: comment %s", internal.ErrCommentParentMismatch, input.ParentID)
+			span.RecordError(err)
+			return AnswerComment{}, err
+		}
+		visible = visible && parent.VisibleToRespondent
+	}
+
+	comment, err := s.queries.Create(ctx, CreateParams{
+		ResponseID:          input.ResponseID,
+		QuestionID:          input.QuestionID,
+		ParentID:            pgtype.UUID{Bytes: input.ParentID, Valid: input.ParentID != uuid.Nil},
+		AuthorID:            pgtype.UUID{Bytes: input.AuthorID, Valid: true},
+		Content:             input.Content,
+		VisibleToRespondent: visible,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answer_comments", "response_id", input.ResponseID.String(), logger, "create comment")
+		span.RecordError(err)
+		return AnswerComment{}, err
+	}
+
+	return comment, nil
+}
+
+// List returns the comments on an answer, oldest first. Reviewers see every comment,
+// the respondent only the ones made visible to them.
+func (s *Service) List(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, questionID uuid.UUID, userID uuid.UUID) ([]AnswerComment, error) {
+	ctx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	isReviewer, isRespondent, err := s.access(ctx, formID, responseID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if !isReviewer && !isRespondent {
+		err = fmt.Errorf("%w: user cannot view comments on response %s", internal.ErrPermissionDenied, responseID)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	comments, err := s.queries.ListByAnswer(ctx, ListByAnswerParams{
+		ResponseID: responseID,
+		QuestionID: questionID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answer_comments", "response_id", responseID.String(), logger, "list comments by answer")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if isReviewer {
+		return comments, nil
+	}
+
+	visible := make([]AnswerComment, 0, len(comments))
+	for _, comment := range comments {
+		if comment.VisibleToRespondent {
+			visible = append(visible, comment)
+		}
+	}
+	return visible, nil
+}
+
+// access reports whether the user reviews the response, as a member of the unit owning
+// the form, and whether they submitted it
+func (s *Service) access(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID) (bool, bool, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	owner, err := s.queries.GetResponseOwner(ctx, GetResponseOwnerParams{
+		ResponseID: responseID,
+		FormID:     formID,
+	})
+	if err != nil {
+		return false, false, databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "get response owner")
+	}
+
+	isReviewer := false
+	if owner.UnitID.Valid {
+		isReviewer, err = s.queries.IsUnitMember(ctx, IsUnitMemberParams{
+			UnitID:   owner.UnitID.Bytes,
+			MemberID: userID,
+		})
+		if err != nil {
+			return false, false, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", uuid.UUID(owner.UnitID.Bytes).String(), logger, "check reviewer unit membership")
+		}
+	}
+
+	return isReviewer, owner.SubmittedBy == userID, nil
+}
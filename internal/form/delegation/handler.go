@@ -0,0 +1,251 @@
+package delegation
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	pagutil "github.com/NYCU-SDC/summer/pkg/pagination"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Issue(ctx context.Context, formID uuid.UUID, req IssueRequest, userID uuid.UUID) (Issued, error)
+	List(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]FormDelegation, error)
+	Revoke(ctx context.Context, formID uuid.UUID, delegationID uuid.UUID, userID uuid.UUID) error
+	Activity(ctx context.Context, formID uuid.UUID, delegationID uuid.UUID, userID uuid.UUID, page int, size int) ([]audit.AuditLog, int64, error)
+}
+
+type Request struct {
+	Name      string    `json:"name" validate:"required,max=255"`
+	Email     string    `json:"email" validate:"omitempty,email,max=255"`
+	ExpiresAt time.Time `json:"expiresAt" validate:"required"`
+}
+
+type Response struct {
+	ID         string     `json:"id"`
+	FormID     string     `json:"formId"`
+	UserID     string     `json:"userId"`
+	Name       string     `json:"name"`
+	Email      string     `json:"email,omitempty"`
+	CreatedBy  *string    `json:"createdBy,omitempty"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// IssueResponse holds the management token, to hand over to the delegate; it cannot be
+// shown again
+type IssueResponse struct {
+	Response
+	Token string `json:"token"`
+}
+
+func ToResponse(delegation FormDelegation) Response {
+	response := Response{
+		ID:        delegation.ID.String(),
+		FormID:    delegation.FormID.String(),
+		UserID:    delegation.UserID.String(),
+		Name:      delegation.Name,
+		Email:     delegation.Email,
+		ExpiresAt: delegation.ExpiresAt.Time,
+		Active:    !delegation.RevokedAt.Valid && delegation.ExpiresAt.Time.After(time.Now()),
+		CreatedAt: delegation.CreatedAt.Time,
+	}
+	if delegation.CreatedBy.Valid {
+		createdBy := uuid.UUID(delegation.CreatedBy.Bytes).String()
+		response.CreatedBy = &createdBy
+	}
+	if delegation.RevokedAt.Valid {
+		response.RevokedAt = &delegation.RevokedAt.Time
+	}
+	if delegation.LastUsedAt.Valid {
+		response.LastUsedAt = &delegation.LastUsedAt.Time
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("delegation/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) IssueHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "IssueHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	issued, err := h.store.Issue(traceCtx, formID, IssueRequest{
+		Name:      req.Name,
+		Email:     req.Email,
+		ExpiresAt: req.ExpiresAt,
+	}, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, IssueResponse{
+		Response: ToResponse(issued.FormDelegation),
+		Token:    issued.Token,
+	})
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	delegations, err := h.store.List(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]Response, len(delegations))
+	for i, delegation := range delegations {
+		responses[i] = ToResponse(delegation)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+func (h *Handler) RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RevokeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	delegationID, err := internal.ParseUUID(r.PathValue("delegationId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Revoke(traceCtx, formID, delegationID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// ActivityHandler lists what the delegate did with the form, newest first
+func (h *Handler) ActivityHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ActivityHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	factory := pagutil.NewFactory[audit.ActivityResponse](200, []string{"CreatedAt"})
+	request, err := factory.GetRequest(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	delegationID, err := internal.ParseUUID(r.PathValue("delegationId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	logs, total, err := h.store.Activity(traceCtx, formID, delegationID, currentUser.ID, request.Page, request.Size)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	items := make([]audit.ActivityResponse, len(logs))
+	for i, log := range logs {
+		items[i] = audit.ToActivityResponse(log)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, factory.NewResponse(items, int(total), request.Page, request.Size))
+}
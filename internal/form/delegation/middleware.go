@@ -0,0 +1,140 @@
+package delegation
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type TokenParser interface {
+	ParseManageToken(ctx context.Context, tokenString string) (user.User, uuid.UUID, uuid.UUID, error)
+}
+
+// Authenticator handles every token that is not a management token
+type Authenticator interface {
+	AuthenticateMiddleware(handler http.HandlerFunc) http.HandlerFunc
+	RespondentMiddleware(handler http.HandlerFunc) http.HandlerFunc
+}
+
+type Middleware struct {
+	logger        *zap.Logger
+	problemWriter *problem.HttpWriter
+	service       *Service
+	tokenParser   TokenParser
+	authenticator Authenticator
+	tracer        trace.Tracer
+}
+
+func NewMiddleware(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	service *Service,
+	tokenParser TokenParser,
+	authenticator Authenticator,
+) *Middleware {
+	return &Middleware{
+		logger:        logger,
+		problemWriter: problemWriter,
+		service:       service,
+		tokenParser:   tokenParser,
+		authenticator: authenticator,
+		tracer:        otel.Tracer("delegation/middleware"),
+	}
+}
+
+// ManageMiddleware is the authentication of the routes a delegate may use. It takes
+// management tokens of the form in the path as long as their delegation is active,
+// and leaves any other token to AuthenticateMiddleware.
+func (m *Middleware) ManageMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	fallback := m.authenticator.AuthenticateMiddleware(handler)
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.serve(w, r, handler, fallback)
+	}
+}
+
+// ViewMiddleware lets delegates read the routes meant for respondents, such as the form
+// and its sections, without answering the form. Any other token is left to
+// RespondentMiddleware.
+func (m *Middleware) ViewMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	fallback := m.authenticator.RespondentMiddleware(handler)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			fallback(w, r)
+			return
+		}
+		m.serve(w, r, handler, fallback)
+	}
+}
+
+func (m *Middleware) serve(w http.ResponseWriter, r *http.Request, handler http.HandlerFunc, fallback http.HandlerFunc) {
+	tokenString, err := jwt.TokenFromRequest(r)
+	if err != nil {
+		fallback(w, r)
+		return
+	}
+	delegate, tokenFormID, delegationID, err := m.tokenParser.ParseManageToken(r.Context(), tokenString)
+	if err != nil {
+		fallback(w, r)
+		return
+	}
+
+	traceCtx, span := m.tracer.Start(r.Context(), "DelegationMiddleware")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, m.logger)
+
+	formID, err := m.formFromPath(traceCtx, r)
+	if err != nil {
+		m.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+	if formID != tokenFormID {
+		m.problemWriter.WriteError(traceCtx, w, internal.ErrRestrictedToken, logger)
+		return
+	}
+
+	err = m.service.Use(traceCtx, delegationID, formID)
+	if err != nil {
+		if !errors.Is(err, internal.ErrDelegationInactive) {
+			logger.Error("Failed to check delegation", zap.String("delegation_id", delegationID.String()), zap.Error(err))
+		}
+		m.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	ctx := context.WithValue(traceCtx, internal.UserContextKey, &delegate)
+	ctx = context.WithValue(ctx, internal.DelegationContextKey, delegationID)
+	handler(w, r.WithContext(ctx))
+}
+
+// formFromPath finds the form a request is about. Question routes name their section
+// rather than their form, as {sectionId} or as {id} under /sections.
+func (m *Middleware) formFromPath(ctx context.Context, r *http.Request) (uuid.UUID, error) {
+	if formID := r.PathValue("formId"); formID != "" {
+		return internal.ParseUUID(formID)
+	}
+
+	sectionID := r.PathValue("sectionId")
+	if sectionID == "" && strings.Contains(r.Pattern, "/sections/{id}") {
+		sectionID = r.PathValue("id")
+	}
+	if sectionID != "" {
+		id, err := internal.ParseUUID(sectionID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return m.service.SectionFormID(ctx, id)
+	}
+
+	return internal.ParseUUID(r.PathValue("id"))
+}
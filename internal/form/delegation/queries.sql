@@ -0,0 +1,42 @@
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
+
+-- name: CreateDelegate :one
+INSERT INTO users (name, role, is_onboarded)
+VALUES (@name, '{"delegate"}', true)
+RETURNING id;
+
+-- name: Create :one
+INSERT INTO form_delegations (form_id, user_id, name, email, created_by, expires_at)
+VALUES (@form_id, @user_id, @name, @email, @created_by, @expires_at)
+RETURNING *;
+
+-- name: Get :one
+SELECT * FROM form_delegations
+WHERE id = @id AND form_id = @form_id;
+
+-- name: ListByForm :many
+SELECT * FROM form_delegations
+WHERE form_id = @form_id
+ORDER BY created_at DESC;
+
+-- name: Revoke :execrows
+UPDATE form_delegations
+SET revoked_at = now()
+WHERE id = @id AND form_id = @form_id AND revoked_at IS NULL;
+
+-- name: Use :one
+-- Returns no row when the delegation is revoked, expired or for another form
+UPDATE form_delegations
+SET last_used_at = now()
+WHERE id = @id AND form_id = @form_id AND revoked_at IS NULL AND expires_at > now()
+RETURNING *;
+
+-- name: GetSectionFormID :one
+SELECT form_id FROM sections
+WHERE id = @id;
\ No newline at end of file
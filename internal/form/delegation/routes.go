@@ -0,0 +1,13 @@
+package delegation
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the delegations of a form to people outside its organization
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/delegations", route.Authenticated, route.PermissionOrgAdmin, h.ListHandler)
+	r.Handle("POST /forms/{id}/delegations", route.Authenticated, route.PermissionOrgAdmin, h.IssueHandler)
+	r.Handle("DELETE /forms/{formId}/delegations/{delegationId}", route.Authenticated, route.PermissionOrgAdmin, h.RevokeHandler)
+	r.Handle("GET /forms/{formId}/delegations/{delegationId}/activity", route.Authenticated, route.PermissionOrgAdmin, h.ActivityHandler)
+}
@@ -0,0 +1,312 @@
+package delegation
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// MaxLifetime caps how long a delegation lasts; one for a longer collaboration is issued again
+const MaxLifetime = 90 * 24 * time.Hour
+
+type Querier interface {
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+	CreateDelegate(ctx context.Context, name pgtype.Text) (uuid.UUID, error)
+	Create(ctx context.Context, arg CreateParams) (FormDelegation, error)
+	Get(ctx context.Context, arg GetParams) (FormDelegation, error)
+	ListByForm(ctx context.Context, formID uuid.UUID) ([]FormDelegation, error)
+	Revoke(ctx context.Context, arg RevokeParams) (int64, error)
+	Use(ctx context.Context, arg UseParams) (FormDelegation, error)
+	GetSectionFormID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+}
+
+// DB is the connection the service runs on; the delegate and their delegation are
+// created in one transaction begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type TokenIssuer interface {
+	NewManageToken(ctx context.Context, delegateID uuid.UUID, name string, formID uuid.UUID, delegationID uuid.UUID, expiresAt time.Time) (string, error)
+}
+
+// ActivityStore is the audit log the actions of delegates are read from
+type ActivityStore interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, action audit.NullAuditAction, page int, size int) ([]audit.AuditLog, error)
+	CountByUser(ctx context.Context, userID uuid.UUID, action audit.NullAuditAction) (int64, error)
+}
+
+// IssueRequest names the external person a form is delegated to
+type IssueRequest struct {
+	Name      string
+	Email     string
+	ExpiresAt time.Time
+}
+
+// Issued is a new delegation with its management token, which is only ever returned here
+type Issued struct {
+	FormDelegation
+	Token string
+}
+
+type Service struct {
+	logger  *zap.Logger
+	db      DB
+	queries Querier
+	tracer  trace.Tracer
+
+	tokenIssuer   TokenIssuer
+	activityStore ActivityStore
+}
+
+func NewService(logger *zap.Logger, db DB, tokenIssuer TokenIssuer, activityStore ActivityStore) *Service {
+	return &Service{
+		logger:        logger,
+		db:            db,
+		queries:       New(db),
+		tracer:        otel.Tracer("delegation/service"),
+		tokenIssuer:   tokenIssuer,
+		activityStore: activityStore,
+	}
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+// requireAdmin allows the owner of the organization of the form only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func (s *Service) get(ctx context.Context, logger *zap.Logger, formID uuid.UUID, delegationID uuid.UUID) (FormDelegation, error) {
+	delegation, err := s.queries.Get(ctx, GetParams{ID: delegationID, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FormDelegation{}, internal.ErrDelegationNotFound
+		}
+		return FormDelegation{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_delegations", "id", delegationID.String(), logger, "get delegation")
+	}
+	return delegation, nil
+}
+
+// Issue delegates the management of the form to an external person until the given
+// time. The delegate gets a user of their own, so their actions show in the audit log.
+func (s *Service) Issue(ctx context.Context, formID uuid.UUID, req IssueRequest, userID uuid.UUID) (Issued, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Issue")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Issued{}, err
+	}
+
+	now := time.Now()
+	if !req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(MaxLifetime)) {
+		err = fmt.Errorf("%w: must be in the future and within %d days", internal.ErrDelegationExpiryInvalid, int(MaxLifetime.Hours()/24))
+		span.RecordError(err)
+		return Issued{}, err
+	}
+
+	var delegation FormDelegation
+	err = s.inTx(traceCtx, logger, func(queries Querier) error {
+		delegateID, err := queries.CreateDelegate(traceCtx, pgtype.Text{String: req.Name, Valid: true})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "users", "name", req.Name, logger, "create delegate")
+		}
+
+		delegation, err = queries.Create(traceCtx, CreateParams{
+			FormID:    formID,
+			UserID:    delegateID,
+			Name:      req.Name,
+			Email:     req.Email,
+			CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+			ExpiresAt: pgtype.Timestamptz{Time: req.ExpiresAt, Valid: true},
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "form_delegations", "form_id", formID.String(), logger, "create delegation")
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Issued{}, err
+	}
+
+	token, err := s.tokenIssuer.NewManageToken(traceCtx, delegation.UserID, delegation.Name, formID, delegation.ID, delegation.ExpiresAt.Time)
+	if err != nil {
+		span.RecordError(err)
+		return Issued{}, err
+	}
+
+	logger.Info("Delegated form management", zap.String("form_id", formID.String()), zap.String("delegation_id", delegation.ID.String()), zap.Time("expires_at", delegation.ExpiresAt.Time))
+	return Issued{FormDelegation: delegation, Token: token}, nil
+}
+
+// List returns the delegations of the form, revoked and expired ones included, latest first
+func (s *Service) List(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]FormDelegation, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	delegations, err := s.queries.ListByForm(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_delegations", "form_id", formID.String(), logger, "list delegations")
+		span.RecordError(err)
+		return nil, err
+	}
+	return delegations, nil
+}
+
+// Revoke ends a delegation before it expires. The delegation is kept, so its activity
+// can still be read; revoking it again does nothing.
+func (s *Service) Revoke(ctx context.Context, formID uuid.UUID, delegationID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Revoke")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Revoke(traceCtx, RevokeParams{ID: delegationID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_delegations", "id", delegationID.String(), logger, "revoke delegation")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		_, err = s.get(traceCtx, logger, formID, delegationID)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+		return nil
+	}
+
+	logger.Info("Revoked delegation", zap.String("form_id", formID.String()), zap.String("delegation_id", delegationID.String()))
+	return nil
+}
+
+// Activity lists what the delegate did with the form, newest first, with the total
+func (s *Service) Activity(ctx context.Context, formID uuid.UUID, delegationID uuid.UUID, userID uuid.UUID, page int, size int) ([]audit.AuditLog, int64, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Activity")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	delegation, err := s.get(traceCtx, logger, formID, delegationID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	total, err := s.activityStore.CountByUser(traceCtx, delegation.UserID, audit.NullAuditAction{})
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	logs, err := s.activityStore.ListByUser(traceCtx, delegation.UserID, audit.NullAuditAction{}, page, size)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// Use checks that the delegation is active and for the given form, and records its use
+func (s *Service) Use(ctx context.Context, delegationID uuid.UUID, formID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Use")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	_, err := s.queries.Use(traceCtx, UseParams{ID: delegationID, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrDelegationInactive
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_delegations", "id", delegationID.String(), logger, "use delegation")
+		}
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// SectionFormID returns the form a section belongs to. A delegate asking for a section
+// that does not exist is refused like one asking for a section of another form.
+func (s *Service) SectionFormID(ctx context.Context, sectionID uuid.UUID) (uuid.UUID, error) {
+	traceCtx, span := s.tracer.Start(ctx, "SectionFormID")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	formID, err := s.queries.GetSectionFormID(traceCtx, sectionID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrRestrictedToken
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "sections", "id", sectionID.String(), logger, "get section form")
+		}
+		span.RecordError(err)
+		return uuid.Nil, err
+	}
+	return formID, nil
+}
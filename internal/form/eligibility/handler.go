@@ -0,0 +1,232 @@
+package eligibility
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	ListRules(ctx context.Context, formID uuid.UUID) ([]FormEligibilityRule, error)
+	ReplaceRules(ctx context.Context, formID uuid.UUID, params []RuleParam) ([]FormEligibilityRule, error)
+	Check(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Result, error)
+}
+
+type RuleRequest struct {
+	Type         string `json:"type" validate:"required,oneof=UNIT_MEMBER EMAIL_DOMAIN ATTRIBUTE GROUP_MEMBER"`
+	UnitID       string `json:"unitId" validate:"omitempty,uuid"`
+	GroupID      string `json:"groupId" validate:"omitempty,uuid"`
+	AttributeKey string `json:"attributeKey"`
+	Value        string `json:"value"`
+}
+
+type UpdateRulesRequest struct {
+	Rules []RuleRequest `json:"rules" validate:"dive"`
+}
+
+type RuleResponse struct {
+	ID           string    `json:"id"`
+	FormID       string    `json:"formId"`
+	Type         string    `json:"type"`
+	UnitID       *string   `json:"unitId,omitempty"`
+	GroupID      *string   `json:"groupId,omitempty"`
+	AttributeKey *string   `json:"attributeKey,omitempty"`
+	Value        *string   `json:"value,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type ReasonResponse struct {
+	RuleID  string `json:"ruleId"` // empty for a gate
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type CheckResponse struct {
+	Eligible bool             `json:"eligible"`
+	Reasons  []ReasonResponse `json:"reasons"`
+}
+
+func (r RuleRequest) ToRuleParam() RuleParam {
+	param := RuleParam{
+		// Convert uppercase request value to lowercase for database storage
+		Type:         EligibilityRuleType(strings.ToLower(r.Type)),
+		AttributeKey: r.AttributeKey,
+		Value:        r.Value,
+	}
+	if r.UnitID != "" {
+		// The validator guarantees a well-formed UUID here
+		param.UnitID = uuid.MustParse(r.UnitID)
+	}
+	if r.GroupID != "" {
+		param.GroupID = uuid.MustParse(r.GroupID)
+	}
+	return param
+}
+
+func ToRuleResponse(rule FormEligibilityRule) RuleResponse {
+	response := RuleResponse{
+		ID:        rule.ID.String(),
+		FormID:    rule.FormID.String(),
+		Type:      strings.ToUpper(string(rule.Type)),
+		CreatedAt: rule.CreatedAt.Time,
+		UpdatedAt: rule.UpdatedAt.Time,
+	}
+	if rule.UnitID.Valid {
+		unitID := uuid.UUID(rule.UnitID.Bytes).String()
+		response.UnitID = &unitID
+	}
+	if rule.GroupID.Valid {
+		groupID := uuid.UUID(rule.GroupID.Bytes).String()
+		response.GroupID = &groupID
+	}
+	if rule.AttributeKey.Valid {
+		response.AttributeKey = &rule.AttributeKey.String
+	}
+	if rule.Value.Valid {
+		response.Value = &rule.Value.String
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("eligibility/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) ListRulesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListRulesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	rules, err := h.store.ListRules(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]RuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = ToRuleResponse(rule)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+func (h *Handler) UpdateRulesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateRulesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req UpdateRulesRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	params := make([]RuleParam, len(req.Rules))
+	for i, rule := range req.Rules {
+		params[i] = rule.ToRuleParam()
+	}
+
+	rules, err := h.store.ReplaceRules(traceCtx, formID, params)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]RuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = ToRuleResponse(rule)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+// CheckHandler reports whether the current user may submit the form, with the reasons if not
+func (h *Handler) CheckHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CheckHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	result, err := h.store.Check(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	reasons := make([]ReasonResponse, len(result.Reasons))
+	for i, reason := range result.Reasons {
+		ruleID := ""
+		if reason.RuleID != uuid.Nil {
+			ruleID = reason.RuleID.String()
+		}
+		reasons[i] = ReasonResponse{
+			RuleID:  ruleID,
+			Type:    strings.ToUpper(string(reason.Type)),
+			Message: reason.Message,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, CheckResponse{
+		Eligible: result.Eligible,
+		Reasons:  reasons,
+	})
+}
@@ -0,0 +1,26 @@
+-- name: Create :one
+INSERT INTO form_eligibility_rules (form_id, type, unit_id, group_id, attribute_key, value)
+VALUES (@form_id, @type, @unit_id, @group_id, @attribute_key, @value)
+RETURNING *;
+
+-- name: ListByFormID :many
+SELECT * FROM form_eligibility_rules
+WHERE form_id = @form_id
+ORDER BY created_at ASC;
+
+-- name: DeleteByFormID :exec
+DELETE FROM form_eligibility_rules
+WHERE form_id = @form_id;
+
+-- name: IsUnitMember :one
+SELECT EXISTS(SELECT 1 FROM unit_members WHERE unit_id = @unit_id AND member_id = @member_id);
+
+-- name: IsGroupMember :one
+SELECT EXISTS(SELECT 1 FROM recipient_group_members WHERE group_id = @group_id AND user_id = @user_id);
+
+-- name: GetProfileValue :one
+SELECT v.value FROM profile_values v
+JOIN profile_fields pf ON pf.id = v.field_id
+JOIN units u ON COALESCE(u.org_id, u.id) = pf.org_id
+JOIN forms f ON f.unit_id = u.id
+WHERE f.id = @form_id AND pf.key = @key AND v.user_id = @user_id;
\ No newline at end of file
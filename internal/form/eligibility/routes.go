@@ -0,0 +1,12 @@
+package eligibility
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the eligibility check of a form and its rules
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/eligibility", route.Authenticated, route.PermissionNone, h.CheckHandler)
+	r.Handle("GET /forms/{id}/eligibility/rules", route.Authenticated, route.PermissionNone, h.ListRulesHandler)
+	r.Handle("PUT /forms/{id}/eligibility/rules", route.Authenticated, route.PermissionNone, h.UpdateRulesHandler)
+}
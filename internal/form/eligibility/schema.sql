@@ -0,0 +1,20 @@
+CREATE TYPE eligibility_rule_type AS ENUM(
+    'unit_member',
+    'email_domain',
+    'attribute',
+    'group_member'
+);
+
+CREATE TABLE IF NOT EXISTS form_eligibility_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    type eligibility_rule_type NOT NULL,
+    unit_id UUID REFERENCES units(id) ON DELETE CASCADE,
+    group_id UUID REFERENCES recipient_groups(id) ON DELETE CASCADE,
+    attribute_key TEXT DEFAULT NULL,
+    value TEXT DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_form_eligibility_rules_form_id ON form_eligibility_rules(form_id);
\ No newline at end of file
@@ -0,0 +1,337 @@
+package eligibility
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (FormEligibilityRule, error)
+	ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormEligibilityRule, error)
+	DeleteByFormID(ctx context.Context, formID uuid.UUID) error
+	IsUnitMember(ctx context.Context, arg IsUnitMemberParams) (bool, error)
+	IsGroupMember(ctx context.Context, arg IsGroupMemberParams) (bool, error)
+	GetProfileValue(ctx context.Context, arg GetProfileValueParams) (string, error)
+}
+
+type UserStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (user.UsersWithEmail, error)
+}
+
+// Gate admits users to a form on what they did before it, such as the stage before in
+// a pipeline; a failed gate is reported as a Reason of type ReasonTypeGate without a rule
+type Gate interface {
+	CheckGate(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (bool, string, error)
+}
+
+// ReasonTypeGate is the type of the Reason a failed Gate gives
+const ReasonTypeGate EligibilityRuleType = "gate"
+
+// Attribute keys that can be referenced by an attribute rule
+const (
+	AttributeRole     = "role"
+	AttributeUsername = "username"
+	AttributeName     = "name"
+)
+
+var supportedAttributes = []string{AttributeName, AttributeRole, AttributeUsername}
+
+// AttributeProfilePrefix marks an attribute key as a profile field of the organization
+// of the form, such as profile.department
+const AttributeProfilePrefix = "profile."
+
+type RuleParam struct {
+	Type         EligibilityRuleType
+	UnitID       uuid.UUID
+	GroupID      uuid.UUID
+	AttributeKey string
+	Value        string
+}
+
+// Reason describes a single rule the user failed to satisfy
+type Reason struct {
+	RuleID  uuid.UUID
+	Type    EligibilityRuleType
+	Message string
+}
+
+// Result is the outcome of evaluating all eligibility rules of a form.
+// A form without rules or a gate is open to every authenticated user.
+type Result struct {
+	Eligible bool
+	Reasons  []Reason
+}
+
+type Service struct {
+	logger    *zap.Logger
+	queries   Querier
+	tracer    trace.Tracer
+	userStore UserStore
+	gate      Gate
+}
+
+// NewService creates the eligibility service; gate may be nil, forms are then only
+// checked against their rules
+func NewService(logger *zap.Logger, db DBTX, userStore UserStore, gate Gate) *Service {
+	return &Service{
+		logger:    logger,
+		queries:   New(db),
+		tracer:    otel.Tracer("eligibility/service"),
+		userStore: userStore,
+		gate:      gate,
+	}
+}
+
+// ListRules returns the eligibility rules declared on a form
+func (s *Service) ListRules(ctx context.Context, formID uuid.UUID) ([]FormEligibilityRule, error) {
+	ctx, span := s.tracer.Start(ctx, "ListRules")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	rules, err := s.queries.ListByFormID(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_eligibility_rules", "form_id", formID.String(), logger, "list eligibility rules")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if rules == nil {
+		rules = []FormEligibilityRule{}
+	}
+
+	return rules, nil
+}
+
+// ReplaceRules validates the given rules and replaces all existing rules of the form with them
+func (s *Service) ReplaceRules(ctx context.Context, formID uuid.UUID, params []RuleParam) ([]FormEligibilityRule, error) {
+	ctx, span := s.tracer.Start(ctx, "ReplaceRules")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	createParams := make([]CreateParams, 0, len(params))
+	for i, param := range params {
+		createParam, err := toCreateParams(formID, param)
+		if err != nil {
+			err = fmt.Errorf("%w: rule %d: %w", internal.ErrInvalidEligibilityRule, i, err)
+			span.RecordError(err)
+			return nil, err
+		}
+		createParams = append(createParams, createParam)
+	}
+
+	err := s.queries.DeleteByFormID(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_eligibility_rules", "form_id", formID.String(), logger, "delete eligibility rules")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	rules := make([]FormEligibilityRule, 0, len(createParams))
+	for _, createParam := range createParams {
+		rule, err := s.queries.Create(ctx, createParam)
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_eligibility_rules", "form_id", formID.String(), logger, "create eligibility rule")
+			span.RecordError(err)
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	logger.Info("Replaced form eligibility rules", zap.String("form_id", formID.String()), zap.Int("count", len(rules)))
+
+	return rules, nil
+}
+
+// Check evaluates every rule of the form and the gate against the given user.
+// All of them must be satisfied; each failed one is reported as a Reason.
+func (s *Service) Check(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Result, error) {
+	ctx, span := s.tracer.Start(ctx, "Check")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	rules, err := s.queries.ListByFormID(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_eligibility_rules", "form_id", formID.String(), logger, "list eligibility rules")
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	result := Result{Eligible: true, Reasons: []Reason{}}
+
+	if s.gate != nil {
+		admitted, message, err := s.gate.CheckGate(ctx, formID, userID)
+		if err != nil {
+			span.RecordError(err)
+			return Result{}, err
+		}
+		if !admitted {
+			result.Eligible = false
+			result.Reasons = append(result.Reasons, Reason{Type: ReasonTypeGate, Message: message})
+		}
+	}
+
+	if len(rules) == 0 {
+		return result, nil
+	}
+
+	currentUser, err := s.userStore.GetByID(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	for _, rule := range rules {
+		satisfied, message, err := s.evaluate(ctx, rule, currentUser)
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_eligibility_rules", "id", rule.ID.String(), logger, "evaluate eligibility rule")
+			span.RecordError(err)
+			return Result{}, err
+		}
+
+		if !satisfied {
+			result.Eligible = false
+			result.Reasons = append(result.Reasons, Reason{
+				RuleID:  rule.ID,
+				Type:    rule.Type,
+				Message: message,
+			})
+		}
+	}
+
+	if !result.Eligible {
+		logger.Info("User is not eligible for form",
+			zap.String("form_id", formID.String()),
+			zap.String("user_id", userID.String()),
+			zap.Int("failed_rules", len(result.Reasons)))
+	}
+
+	return result, nil
+}
+
+func (s *Service) evaluate(ctx context.Context, rule FormEligibilityRule, currentUser user.UsersWithEmail) (bool, string, error) {
+	switch rule.Type {
+	case EligibilityRuleTypeUnitMember:
+		isMember, err := s.queries.IsUnitMember(ctx, IsUnitMemberParams{
+			UnitID:   rule.UnitID.Bytes,
+			MemberID: currentUser.ID,
+		})
+		if err != nil {
+			return false, "", err
+		}
+		return isMember, "user is not a member of the required unit", nil
+
+	case EligibilityRuleTypeGroupMember:
+		isMember, err := s.queries.IsGroupMember(ctx, IsGroupMemberParams{
+			GroupID: rule.GroupID.Bytes,
+			UserID:  currentUser.ID,
+		})
+		if err != nil {
+			return false, "", err
+		}
+		return isMember, "user is not a member of the required group", nil
+
+	case EligibilityRuleTypeEmailDomain:
+		domain := rule.Value.String
+		for _, email := range user.ConvertEmailsToSlice(currentUser.Emails) {
+			if strings.HasSuffix(strings.ToLower(email), "@"+domain) {
+				return true, "", nil
+			}
+		}
+		return false, fmt.Sprintf("user has no email address under domain %s", domain), nil
+
+	case EligibilityRuleTypeAttribute:
+		key := rule.AttributeKey.String
+		values := userAttribute(currentUser, key)
+		if fieldKey, ok := strings.CutPrefix(key, AttributeProfilePrefix); ok {
+			value, err := s.queries.GetProfileValue(ctx, GetProfileValueParams{
+				FormID: rule.FormID,
+				Key:    fieldKey,
+				UserID: currentUser.ID,
+			})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return false, "", err
+			}
+			values = []string{value}
+		}
+		if slices.Contains(values, rule.Value.String) {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("user attribute %s does not match %s", key, rule.Value.String), nil
+	}
+
+	return false, fmt.Sprintf("unsupported rule type %s", rule.Type), nil
+}
+
+// userAttribute returns the values of a user attribute that attribute rules can match against
+func userAttribute(u user.UsersWithEmail, key string) []string {
+	switch key {
+	case AttributeRole:
+		return u.Role
+	case AttributeUsername:
+		return []string{u.Username.String}
+	case AttributeName:
+		return []string{u.Name.String}
+	}
+	return nil
+}
+
+func toCreateParams(formID uuid.UUID, param RuleParam) (CreateParams, error) {
+	createParams := CreateParams{
+		FormID: formID,
+		Type:   param.Type,
+	}
+
+	switch param.Type {
+	case EligibilityRuleTypeUnitMember:
+		if param.UnitID == uuid.Nil {
+			return CreateParams{}, fmt.Errorf("unit member rule requires a unit id")
+		}
+		createParams.UnitID = pgtype.UUID{Bytes: param.UnitID, Valid: true}
+
+	case EligibilityRuleTypeGroupMember:
+		if param.GroupID == uuid.Nil {
+			return CreateParams{}, fmt.Errorf("group member rule requires a group id")
+		}
+		createParams.GroupID = pgtype.UUID{Bytes: param.GroupID, Valid: true}
+
+	case EligibilityRuleTypeEmailDomain:
+		domain := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(param.Value)), "@")
+		if domain == "" || strings.Contains(domain, "@") {
+			return CreateParams{}, fmt.Errorf("invalid email domain: %q", param.Value)
+		}
+		createParams.Value = pgtype.Text{String: domain, Valid: true}
+
+	case EligibilityRuleTypeAttribute:
+		fieldKey, isProfile := strings.CutPrefix(param.AttributeKey, AttributeProfilePrefix)
+		if isProfile && fieldKey == "" {
+			return CreateParams{}, fmt.Errorf("profile attribute requires a field key")
+		}
+		if !isProfile && !slices.Contains(supportedAttributes, param.AttributeKey) {
+			return CreateParams{}, fmt.Errorf("unsupported attribute %q, supported attributes are: %s and %s<field key>", param.AttributeKey, strings.Join(supportedAttributes, ", "), AttributeProfilePrefix)
+		}
+		if strings.TrimSpace(param.Value) == "" {
+			return CreateParams{}, fmt.Errorf("attribute rule requires a value")
+		}
+		createParams.AttributeKey = pgtype.Text{String: param.AttributeKey, Valid: true}
+		createParams.Value = pgtype.Text{String: strings.TrimSpace(param.Value), Valid: true}
+
+	default:
+		return CreateParams{}, fmt.Errorf("unsupported rule type: %s", param.Type)
+	}
+
+	return createParams, nil
+}
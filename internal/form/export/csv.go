@@ -0,0 +1,63 @@
+package export
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/pii"
+	"bytes"
+	"encoding/csv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// buildCSV writes one row per response, with the response metadata followed by one
+// column per question of the form. Unanswered questions are left empty, and the answers
+// to PII questions go through the mask.
+func buildCSV(questions []ListQuestionsByFormIDRow, responses []FormResponse, answers []ListAnswersByResponseIDsRow, mask pii.Mask) ([]byte, error) {
+	values := make(map[uuid.UUID]map[uuid.UUID]string, len(responses))
+	for _, answer := range answers {
+		if values[answer.ResponseID] == nil {
+			values[answer.ResponseID] = make(map[uuid.UUID]string)
+		}
+		values[answer.ResponseID][answer.QuestionID] = mask.Apply(answer.QuestionID, answer.Value)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"response_id", "submitted_by", "created_at", "updated_at"}
+	for _, q := range questions {
+		title := q.Title.String
+		if title == "" {
+			title = q.ID.String()
+		}
+		header = append(header, title)
+	}
+	err := writer.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, response := range responses {
+		record := []string{
+			response.ID.String(),
+			response.SubmittedBy.String(),
+			response.CreatedAt.Time.UTC().Format(time.RFC3339),
+			response.UpdatedAt.Time.UTC().Format(time.RFC3339),
+		}
+		for _, q := range questions {
+			record = append(record, values[response.ID][q.ID])
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
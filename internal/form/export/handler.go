@@ -0,0 +1,298 @@
+package export
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Create(ctx context.Context, formID uuid.UUID, input ScheduleInput, userID uuid.UUID) (FormExportSchedule, error)
+	ListByFormID(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]FormExportSchedule, error)
+	GetByID(ctx context.Context, formID uuid.UUID, id uuid.UUID, userID uuid.UUID) (FormExportSchedule, error)
+	Update(ctx context.Context, formID uuid.UUID, id uuid.UUID, input ScheduleInput, userID uuid.UUID) (FormExportSchedule, error)
+	Delete(ctx context.Context, formID uuid.UUID, id uuid.UUID, userID uuid.UUID) error
+}
+
+type ScheduleRequest struct {
+	Frequency   string     `json:"frequency" validate:"required,oneof=DAILY WEEKLY"`
+	Destination string     `json:"destination" validate:"required,oneof=WEBHOOK S3"`
+	Target      string     `json:"target" validate:"required,max=2048"`
+	Enabled     *bool      `json:"enabled"`
+	Masked      bool       `json:"masked"`
+	StartAt     *time.Time `json:"startAt"`
+}
+
+func (r ScheduleRequest) toInput() ScheduleInput {
+	input := ScheduleInput{
+		Frequency:   ExportFrequency(strings.ToLower(r.Frequency)),
+		Destination: ExportDestination(strings.ToLower(r.Destination)),
+		Target:      r.Target,
+		Enabled:     r.Enabled == nil || *r.Enabled,
+		Masked:      r.Masked,
+	}
+	if r.StartAt != nil {
+		input.StartAt = *r.StartAt
+	}
+	return input
+}
+
+// Response shows the target of webhook schedules redacted to its scheme and host, as the
+// rest of the URL often carries the token of the receiving service
+type Response struct {
+	ID          string     `json:"id"`
+	FormID      string     `json:"formId"`
+	Frequency   string     `json:"frequency"`
+	Destination string     `json:"destination"`
+	Target      string     `json:"target"`
+	Enabled     bool       `json:"enabled"`
+	Masked      bool       `json:"masked"`
+	NextRunAt   time.Time  `json:"nextRunAt"`
+	LastRunAt   *time.Time `json:"lastRunAt,omitempty"`
+	LastError   *string    `json:"lastError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func ToResponse(schedule FormExportSchedule) Response {
+	response := Response{
+		ID:          schedule.ID.String(),
+		FormID:      schedule.FormID.String(),
+		Frequency:   strings.ToUpper(string(schedule.Frequency)),
+		Destination: strings.ToUpper(string(schedule.Destination)),
+		Target:      schedule.Target,
+		Enabled:     schedule.Enabled,
+		Masked:      schedule.Masked,
+		NextRunAt:   schedule.NextRunAt.Time,
+		CreatedAt:   schedule.CreatedAt.Time,
+		UpdatedAt:   schedule.UpdatedAt.Time,
+	}
+	if schedule.LastRunAt.Valid {
+		response.LastRunAt = &schedule.LastRunAt.Time
+	}
+	if schedule.LastError.Valid {
+		response.LastError = &schedule.LastError.String
+	}
+	if schedule.Destination == ExportDestinationWebhook {
+		response.Target = redactTarget(schedule.Target)
+	}
+	return response
+}
+
+const redacted = "[redacted]"
+
+// redactTarget keeps the scheme and host of a webhook URL, hiding its credentials, path
+// and query
+func redactTarget(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Host == "" {
+		return redacted
+	}
+
+	origin := parsed.Scheme + "://" + parsed.Host
+	if parsed.User != nil || strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return origin + "/" + redacted
+	}
+	return origin
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("export/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ScheduleRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	schedule, err := h.store.Create(traceCtx, formID, req.toInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(schedule))
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	schedules, err := h.store.ListByFormID(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]Response, len(schedules))
+	for i, schedule := range schedules {
+		responses[i] = ToResponse(schedule)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, id, err := parseSchedulePath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	schedule, err := h.store.GetByID(traceCtx, formID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(schedule))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, id, err := parseSchedulePath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ScheduleRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	schedule, err := h.store.Update(traceCtx, formID, id, req.toInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(schedule))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, id, err := parseSchedulePath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, formID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func parseSchedulePath(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("scheduleId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return formID, id, nil
+}
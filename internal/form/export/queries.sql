@@ -0,0 +1,90 @@
+-- name: Create :one
+INSERT INTO form_export_schedules (form_id, frequency, destination, target, enabled, masked, next_run_at, created_by)
+VALUES (@form_id, @frequency, @destination, @target, @enabled, @masked, @next_run_at, @created_by)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM form_export_schedules
+WHERE id = @id AND form_id = @form_id;
+
+-- name: ListByFormID :many
+SELECT * FROM form_export_schedules
+WHERE form_id = @form_id
+ORDER BY created_at ASC;
+
+-- name: Update :one
+UPDATE form_export_schedules
+SET frequency = @frequency,
+    destination = @destination,
+    target = @target,
+    enabled = @enabled,
+    masked = @masked,
+    next_run_at = @next_run_at,
+    updated_at = now()
+WHERE id = @id AND form_id = @form_id
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM form_export_schedules
+WHERE id = @id AND form_id = @form_id;
+
+-- name: ListDue :many
+SELECT * FROM form_export_schedules
+WHERE enabled AND next_run_at <= @now
+ORDER BY next_run_at ASC;
+
+-- name: Claim :execrows
+UPDATE form_export_schedules
+SET next_run_at = @next_run_at,
+    updated_at = now()
+WHERE id = @id AND next_run_at = @previous_run_at;
+
+-- name: RecordSuccess :exec
+UPDATE form_export_schedules
+SET last_run_at = @last_run_at,
+    last_error = NULL,
+    updated_at = now()
+WHERE id = @id;
+
+-- name: RecordFailure :exec
+UPDATE form_export_schedules
+SET last_error = @last_error,
+    updated_at = now()
+WHERE id = @id;
+
+-- name: ListResponsesCreatedBetween :many
+SELECT * FROM form_responses
+WHERE form_id = @form_id AND created_at >= @since AND created_at < @until AND NOT is_test
+ORDER BY created_at ASC;
+
+-- name: ListAnswersByResponseIDs :many
+SELECT response_id, question_id, value FROM answers
+WHERE response_id = ANY(@response_ids::UUID[]);
+
+-- name: ListQuestionsByFormID :many
+SELECT q.id, q.title FROM questions q
+JOIN sections s ON s.id = q.section_id
+WHERE s.form_id = @form_id
+ORDER BY s.created_at ASC, q."order" ASC;
+
+-- name: ListWebhookTargets :many
+SELECT id, target FROM form_export_schedules
+WHERE destination = 'webhook';
+
+-- name: UpdateTarget :exec
+UPDATE form_export_schedules
+SET target = @target
+WHERE id = @id;
+
+-- name: GetFormUnit :one
+SELECT u.id, u.org_id FROM forms f
+JOIN units u ON u.id = f.unit_id
+WHERE f.id = @form_id;
+
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
\ No newline at end of file
@@ -0,0 +1,14 @@
+package export
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the scheduled response exports of a form
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/exports/schedules", route.Authenticated, route.PermissionOrgAdmin, h.ListHandler)
+	r.Handle("POST /forms/{id}/exports/schedules", route.Authenticated, route.PermissionOrgAdmin, h.CreateHandler)
+	r.Handle("GET /forms/{id}/exports/schedules/{scheduleId}", route.Authenticated, route.PermissionOrgAdmin, h.GetHandler)
+	r.Handle("PUT /forms/{id}/exports/schedules/{scheduleId}", route.Authenticated, route.PermissionOrgAdmin, h.UpdateHandler)
+	r.Handle("DELETE /forms/{id}/exports/schedules/{scheduleId}", route.Authenticated, route.PermissionOrgAdmin, h.DeleteHandler)
+}
@@ -0,0 +1,27 @@
+CREATE TYPE export_frequency AS ENUM(
+    'daily',
+    'weekly'
+);
+
+CREATE TYPE export_destination AS ENUM(
+    'webhook',
+    's3'
+);
+
+CREATE TABLE IF NOT EXISTS form_export_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    frequency export_frequency NOT NULL,
+    destination export_destination NOT NULL,
+    target TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ DEFAULT NULL,
+    last_error TEXT DEFAULT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    masked BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE INDEX idx_form_export_schedules_due ON form_export_schedules(next_run_at) WHERE enabled;
\ No newline at end of file
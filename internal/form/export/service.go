@@ -0,0 +1,602 @@
+package export
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/egress"
+	"NYCU-SDC/core-system-backend/internal/form/pii"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	deliveryTimeout    = 30 * time.Second
+	defaultRunInterval = 5 * time.Minute
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (FormExportSchedule, error)
+	GetByID(ctx context.Context, arg GetByIDParams) (FormExportSchedule, error)
+	ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormExportSchedule, error)
+	Update(ctx context.Context, arg UpdateParams) (FormExportSchedule, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	ListDue(ctx context.Context, now pgtype.Timestamptz) ([]FormExportSchedule, error)
+	Claim(ctx context.Context, arg ClaimParams) (int64, error)
+	RecordSuccess(ctx context.Context, arg RecordSuccessParams) error
+	RecordFailure(ctx context.Context, arg RecordFailureParams) error
+	ListResponsesCreatedBetween(ctx context.Context, arg ListResponsesCreatedBetweenParams) ([]FormResponse, error)
+	ListAnswersByResponseIDs(ctx context.Context, responseIds []uuid.UUID) ([]ListAnswersByResponseIDsRow, error)
+	ListQuestionsByFormID(ctx context.Context, formID uuid.UUID) ([]ListQuestionsByFormIDRow, error)
+	ListWebhookTargets(ctx context.Context) ([]ListWebhookTargetsRow, error)
+	UpdateTarget(ctx context.Context, arg UpdateTargetParams) error
+	GetFormUnit(ctx context.Context, formID uuid.UUID) (GetFormUnitRow, error)
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+}
+
+// FileStore receives exports pushed to the s3 destination
+type FileStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+}
+
+// MaskStore masks the answers to PII questions. Exports run without a caller, so they
+// are masked for the user who created the schedule.
+type MaskStore interface {
+	Mask(ctx context.Context, formID uuid.UUID, userID uuid.UUID, masked bool) (pii.Mask, error)
+}
+
+// SecretBox encrypts the webhook targets at rest, since their URLs often carry the
+// token of the service they post to
+type SecretBox interface {
+	Seal(value string) (string, error)
+	Open(value string) (string, error)
+	Current(value string) bool
+}
+
+// Signer signs webhook deliveries with the keys of the organization of the form, so
+// receivers can tell them from forged ones
+type Signer interface {
+	Sign(ctx context.Context, orgID uuid.UUID, timestamp time.Time, body []byte) (string, error)
+}
+
+// ScheduleInput configures an export schedule. A zero StartAt runs the first export one
+// period from now. Target is the webhook URL, or the key prefix of the exported files
+// in storage for the s3 destination. Masked masks the PII answers even when the creator
+// of the schedule may see them.
+type ScheduleInput struct {
+	Frequency   ExportFrequency
+	Destination ExportDestination
+	Target      string
+	Enabled     bool
+	Masked      bool
+	StartAt     time.Time
+}
+
+type Service struct {
+	logger     *zap.Logger
+	queries    Querier
+	tracer     trace.Tracer
+	httpClient *http.Client
+	fileStore  FileStore
+	maskStore  MaskStore
+	secretBox  SecretBox
+	signer     Signer
+}
+
+func NewService(logger *zap.Logger, db DBTX, fileStore FileStore, maskStore MaskStore, secretBox SecretBox, signer Signer) *Service {
+	return &Service{
+		logger:     logger,
+		queries:    New(db),
+		tracer:     otel.Tracer("export/service"),
+		httpClient: egress.NewClient(deliveryTimeout),
+		fileStore:  fileStore,
+		maskStore:  maskStore,
+		secretBox:  secretBox,
+		signer:     signer,
+	}
+}
+
+// period is the time between two runs of a schedule
+func period(frequency ExportFrequency) time.Duration {
+	if frequency == ExportFrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func validateTarget(input ScheduleInput) error {
+	if input.Destination != ExportDestinationWebhook {
+		return nil
+	}
+
+	target, err := url.ParseRequestURI(input.Target)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: webhook target must be an http(s) URL", internal.ErrInvalidExportTarget)
+	}
+	if egress.CheckHost(target.Hostname()) != nil {
+		return fmt.Errorf("%w: webhook target must not point to a private, loopback or link-local address", internal.ErrInvalidExportTarget)
+	}
+	return nil
+}
+
+// sealTarget encrypts the target of webhook schedules for storage
+func (s *Service) sealTarget(input ScheduleInput) (string, error) {
+	if input.Destination != ExportDestinationWebhook {
+		return input.Target, nil
+	}
+
+	sealed, err := s.secretBox.Seal(input.Target)
+	if err != nil {
+		return "", fmt.Errorf("failed to seal webhook target: %w", err)
+	}
+	return sealed, nil
+}
+
+// openTarget decrypts the stored target of a schedule
+func (s *Service) openTarget(schedule FormExportSchedule) (FormExportSchedule, error) {
+	if schedule.Destination != ExportDestinationWebhook {
+		return schedule, nil
+	}
+
+	target, err := s.secretBox.Open(schedule.Target)
+	if err != nil {
+		return FormExportSchedule{}, fmt.Errorf("failed to open webhook target of export schedule %s: %w", schedule.ID, err)
+	}
+	schedule.Target = target
+	return schedule, nil
+}
+
+// requireAdmin allows the owner of the organization of the form only. Schedules deliver
+// every new response to their target.
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrPermissionDenied
+	}
+	return nil
+}
+
+func firstRunAt(input ScheduleInput, now time.Time) time.Time {
+	if input.StartAt.IsZero() {
+		return now.Add(period(input.Frequency))
+	}
+	return input.StartAt
+}
+
+func (s *Service) Create(ctx context.Context, formID uuid.UUID, input ScheduleInput, userID uuid.UUID) (FormExportSchedule, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireAdmin(ctx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	err = validateTarget(input)
+	if err != nil {
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	target, err := s.sealTarget(input)
+	if err != nil {
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	schedule, err := s.queries.Create(ctx, CreateParams{
+		FormID:      formID,
+		Frequency:   input.Frequency,
+		Destination: input.Destination,
+		Target:      target,
+		Enabled:     input.Enabled,
+		Masked:      input.Masked,
+		NextRunAt:   pgtype.Timestamptz{Time: firstRunAt(input, time.Now()), Valid: true},
+		CreatedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "form_id", formID.String(), logger, "create export schedule")
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	schedule.Target = input.Target
+	return schedule, nil
+}
+
+func (s *Service) ListByFormID(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]FormExportSchedule, error) {
+	ctx, span := s.tracer.Start(ctx, "ListByFormID")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireAdmin(ctx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	schedules, err := s.queries.ListByFormID(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "form_id", formID.String(), logger, "list export schedules by form id")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	for i, schedule := range schedules {
+		schedules[i], err = s.openTarget(schedule)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	return schedules, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, formID uuid.UUID, id uuid.UUID, userID uuid.UUID) (FormExportSchedule, error) {
+	ctx, span := s.tracer.Start(ctx, "GetByID")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireAdmin(ctx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	schedule, err := s.queries.GetByID(ctx, GetByIDParams{ID: id, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "id", id.String(), logger, "get export schedule by id")
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	schedule, err = s.openTarget(schedule)
+	if err != nil {
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	return schedule, nil
+}
+
+// Update replaces the configuration of a schedule. The next run is rescheduled from
+// now, or to StartAt when given.
+func (s *Service) Update(ctx context.Context, formID uuid.UUID, id uuid.UUID, input ScheduleInput, userID uuid.UUID) (FormExportSchedule, error) {
+	ctx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireAdmin(ctx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	err = validateTarget(input)
+	if err != nil {
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	target, err := s.sealTarget(input)
+	if err != nil {
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	schedule, err := s.queries.Update(ctx, UpdateParams{
+		Frequency:   input.Frequency,
+		Destination: input.Destination,
+		Target:      target,
+		Enabled:     input.Enabled,
+		Masked:      input.Masked,
+		NextRunAt:   pgtype.Timestamptz{Time: firstRunAt(input, time.Now()), Valid: true},
+		ID:          id,
+		FormID:      formID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "id", id.String(), logger, "update export schedule")
+		span.RecordError(err)
+		return FormExportSchedule{}, err
+	}
+
+	schedule.Target = input.Target
+	return schedule, nil
+}
+
+func (s *Service) Delete(ctx context.Context, formID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireAdmin(ctx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Delete(ctx, DeleteParams{ID: id, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "id", id.String(), logger, "delete export schedule")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = fmt.Errorf("%w: %s", internal.ErrExportScheduleNotFound, id)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Start runs the due exports every interval until the context is done
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRunInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			err := s.RunDue(ctx, now)
+			if err != nil {
+				s.logger.Error("Failed to run due exports", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RunDue exports the responses created since the last successful run of every due
+// schedule. A schedule is claimed by moving its next run forward before exporting,
+// so concurrent runners never export the same window twice. Failed deliveries keep
+// the window open, and the next run exports those responses again.
+func (s *Service) RunDue(ctx context.Context, now time.Time) error {
+	ctx, span := s.tracer.Start(ctx, "RunDue")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	due, err := s.queries.ListDue(ctx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list due export schedules")
+		span.RecordError(err)
+		return err
+	}
+
+	for _, schedule := range due {
+		windowEnd := schedule.NextRunAt.Time
+		claimed, err := s.queries.Claim(ctx, ClaimParams{
+			NextRunAt:     pgtype.Timestamptz{Time: windowEnd.Add(period(schedule.Frequency)), Valid: true},
+			ID:            schedule.ID,
+			PreviousRunAt: schedule.NextRunAt,
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "id", schedule.ID.String(), logger, "claim export schedule")
+			span.RecordError(err)
+			return err
+		}
+		if claimed == 0 {
+			// Picked up by another runner
+			continue
+		}
+
+		windowStart := schedule.CreatedAt.Time
+		if schedule.LastRunAt.Valid {
+			windowStart = schedule.LastRunAt.Time
+		}
+
+		opened, runErr := s.openTarget(schedule)
+		count := 0
+		if runErr == nil {
+			count, runErr = s.run(ctx, opened, windowStart, windowEnd)
+		}
+		if runErr != nil {
+			logger.Warn("Export schedule failed",
+				zap.Error(runErr),
+				zap.String("schedule_id", schedule.ID.String()),
+				zap.String("form_id", schedule.FormID.String()))
+
+			err = s.queries.RecordFailure(ctx, RecordFailureParams{
+				LastError: pgtype.Text{String: runErr.Error(), Valid: true},
+				ID:        schedule.ID,
+			})
+			if err != nil {
+				err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "id", schedule.ID.String(), logger, "record export failure")
+				span.RecordError(err)
+				return err
+			}
+			continue
+		}
+
+		err = s.queries.RecordSuccess(ctx, RecordSuccessParams{
+			LastRunAt: pgtype.Timestamptz{Time: windowEnd, Valid: true},
+			ID:        schedule.ID,
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "id", schedule.ID.String(), logger, "record export success")
+			span.RecordError(err)
+			return err
+		}
+
+		logger.Info("Exported responses",
+			zap.String("schedule_id", schedule.ID.String()),
+			zap.String("form_id", schedule.FormID.String()),
+			zap.String("destination", string(schedule.Destination)),
+			zap.Int("response_count", count))
+	}
+
+	return nil
+}
+
+// run exports the responses created in [since, until) and returns how many were sent
+func (s *Service) run(ctx context.Context, schedule FormExportSchedule, since time.Time, until time.Time) (int, error) {
+	questions, err := s.queries.ListQuestionsByFormID(ctx, schedule.FormID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list questions: %w", err)
+	}
+
+	responses, err := s.queries.ListResponsesCreatedBetween(ctx, ListResponsesCreatedBetweenParams{
+		FormID: schedule.FormID,
+		Since:  pgtype.Timestamptz{Time: since, Valid: true},
+		Until:  pgtype.Timestamptz{Time: until, Valid: true},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list responses: %w", err)
+	}
+
+	responseIDs := make([]uuid.UUID, len(responses))
+	for i, response := range responses {
+		responseIDs[i] = response.ID
+	}
+
+	answers, err := s.queries.ListAnswersByResponseIDs(ctx, responseIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list answers: %w", err)
+	}
+
+	createdBy := uuid.Nil
+	if schedule.CreatedBy.Valid {
+		createdBy = schedule.CreatedBy.Bytes
+	}
+	mask, err := s.maskStore.Mask(ctx, schedule.FormID, createdBy, schedule.Masked)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mask answers: %w", err)
+	}
+
+	body, err := buildCSV(questions, responses, answers, mask)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build csv: %w", err)
+	}
+
+	filename := fmt.Sprintf("%s_%s.csv", schedule.FormID, until.UTC().Format("20060102T150405Z"))
+	err = s.deliver(ctx, schedule, filename, body)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(responses), nil
+}
+
+func (s *Service) deliver(ctx context.Context, schedule FormExportSchedule, filename string, body []byte) error {
+	switch schedule.Destination {
+	case ExportDestinationWebhook:
+		return s.postWebhook(ctx, schedule, filename, body)
+	case ExportDestinationS3:
+		err := s.fileStore.Put(ctx, path.Join(schedule.Target, filename), bytes.NewReader(body), int64(len(body)), "text/csv")
+		if err != nil {
+			return fmt.Errorf("failed to upload export: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported export destination: %s", schedule.Destination)
+	}
+}
+
+func (s *Service) postWebhook(ctx context.Context, schedule FormExportSchedule, filename string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, schedule.Target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	unit, err := s.queries.GetFormUnit(ctx, schedule.FormID)
+	if err != nil {
+		return fmt.Errorf("failed to get organization of form: %w", err)
+	}
+	orgID := unit.ID
+	if unit.OrgID.Valid {
+		orgID = unit.OrgID.Bytes
+	}
+
+	signature, err := s.signer.Sign(ctx, orgID, time.Now(), body)
+	if err != nil {
+		return fmt.Errorf("failed to sign webhook: %w", err)
+	}
+	if signature != "" {
+		req.Header.Set(webhook.SignatureHeader, signature)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// RotateSecrets seals the webhook targets stored in the clear or with a key of before a
+// rotation with the current key
+func (s *Service) RotateSecrets(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "RotateSecrets")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	targets, err := s.queries.ListWebhookTargets(ctx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list webhook targets")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	count := 0
+	for _, row := range targets {
+		if s.secretBox.Current(row.Target) {
+			continue
+		}
+
+		target, err := s.secretBox.Open(row.Target)
+		if err != nil {
+			err = fmt.Errorf("failed to open webhook target of export schedule %s: %w", row.ID, err)
+			span.RecordError(err)
+			return count, err
+		}
+		sealed, err := s.secretBox.Seal(target)
+		if err != nil {
+			span.RecordError(err)
+			return count, err
+		}
+
+		err = s.queries.UpdateTarget(ctx, UpdateTargetParams{Target: sealed, ID: row.ID})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_export_schedules", "id", row.ID.String(), logger, "seal webhook target")
+			span.RecordError(err)
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
@@ -0,0 +1,180 @@
+package favorite
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Star(ctx context.Context, userID uuid.UUID, formID uuid.UUID) error
+	Unstar(ctx context.Context, userID uuid.UUID, formID uuid.UUID) error
+	ListStarred(ctx context.Context, userID uuid.UUID) ([]ListStarredRow, error)
+	ListRecent(ctx context.Context, userID uuid.UUID) ([]ListRecentRow, error)
+}
+
+type StarredResponse struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Status    string     `json:"status"`
+	UnitName  string     `json:"unitName"`
+	Deadline  *time.Time `json:"deadline"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	StarredAt time.Time  `json:"starredAt"`
+}
+
+type RecentResponse struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Status    string     `json:"status"`
+	UnitName  string     `json:"unitName"`
+	Deadline  *time.Time `json:"deadline"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	ViewedAt  time.Time  `json:"viewedAt"`
+	Starred   bool       `json:"starred"`
+}
+
+func deadlineOf(deadline pgtype.Timestamptz) *time.Time {
+	if !deadline.Valid {
+		return nil
+	}
+	return &deadline.Time
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("favorite/handler"),
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) ListStarredHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListStarredHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	forms, err := h.store.ListStarred(traceCtx, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]StarredResponse, len(forms))
+	for i, form := range forms {
+		response[i] = StarredResponse{
+			ID:        form.ID.String(),
+			Title:     form.Title,
+			Status:    string(form.Status),
+			UnitName:  form.UnitName.String,
+			Deadline:  deadlineOf(form.Deadline),
+			UpdatedAt: form.UpdatedAt.Time,
+			StarredAt: form.StarredAt.Time,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) ListRecentHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListRecentHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	forms, err := h.store.ListRecent(traceCtx, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]RecentResponse, len(forms))
+	for i, form := range forms {
+		response[i] = RecentResponse{
+			ID:        form.ID.String(),
+			Title:     form.Title,
+			Status:    string(form.Status),
+			UnitName:  form.UnitName.String,
+			Deadline:  deadlineOf(form.Deadline),
+			UpdatedAt: form.UpdatedAt.Time,
+			ViewedAt:  form.ViewedAt.Time,
+			Starred:   form.Starred,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) StarHandler(w http.ResponseWriter, r *http.Request) {
+	h.setStar(w, r, true)
+}
+
+func (h *Handler) UnstarHandler(w http.ResponseWriter, r *http.Request) {
+	h.setStar(w, r, false)
+}
+
+func (h *Handler) setStar(w http.ResponseWriter, r *http.Request, starred bool) {
+	traceCtx, span := h.tracer.Start(r.Context(), "setStar")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	if starred {
+		err = h.store.Star(traceCtx, currentUser.ID, formID)
+	} else {
+		err = h.store.Unstar(traceCtx, currentUser.ID, formID)
+	}
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
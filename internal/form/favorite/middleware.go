@@ -0,0 +1,71 @@
+package favorite
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ViewRecorder interface {
+	RecordView(ctx context.Context, userID uuid.UUID, formID uuid.UUID) error
+}
+
+type Middleware struct {
+	logger   *zap.Logger
+	recorder ViewRecorder
+}
+
+func NewMiddleware(logger *zap.Logger, recorder ViewRecorder) *Middleware {
+	return &Middleware{
+		logger:   logger,
+		recorder: recorder,
+	}
+}
+
+// statusRecorder keeps the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// RecordViewMiddleware adds the form in the path to the caller's recently viewed list
+// once the handler served it. Anonymous respondents have no list to keep.
+func (m *Middleware) RecordViewMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+
+		if recorder.status != http.StatusOK {
+			return
+		}
+
+		currentUser, ok := user.GetFromContext(r.Context())
+		if !ok || slices.Contains(currentUser.Role, "respondent") {
+			return
+		}
+
+		formID, err := internal.ParseUUID(r.PathValue("id"))
+		if err != nil {
+			return
+		}
+
+		err = m.recorder.RecordView(r.Context(), currentUser.ID, formID)
+		if err != nil {
+			m.logger.Error("Failed to record form view", zap.String("form_id", formID.String()), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+-- name: Star :exec
+INSERT INTO form_stars (user_id, form_id)
+VALUES (@user_id, @form_id)
+ON CONFLICT (user_id, form_id) DO NOTHING;
+
+-- name: Unstar :exec
+DELETE FROM form_stars
+WHERE user_id = @user_id AND form_id = @form_id;
+
+-- name: ListStarred :many
+SELECT f.id, f.title, f.status, f.deadline, f.updated_at, u.name AS unit_name, s.created_at AS starred_at
+FROM form_stars s
+JOIN forms f ON f.id = s.form_id
+LEFT JOIN units u ON u.id = f.unit_id
+WHERE s.user_id = @user_id
+ORDER BY s.created_at DESC;
+
+-- name: RecordView :exec
+INSERT INTO form_views (user_id, form_id)
+VALUES (@user_id, @form_id)
+ON CONFLICT (user_id, form_id) DO UPDATE SET viewed_at = now();
+
+-- name: TrimViews :exec
+DELETE FROM form_views
+WHERE user_id = @user_id
+  AND form_id NOT IN (
+      SELECT v.form_id FROM form_views v
+      WHERE v.user_id = @user_id
+      ORDER BY v.viewed_at DESC
+      LIMIT @keep
+  );
+
+-- name: ListRecent :many
+SELECT f.id, f.title, f.status, f.deadline, f.updated_at, u.name AS unit_name, v.viewed_at,
+    EXISTS(SELECT 1 FROM form_stars s WHERE s.user_id = v.user_id AND s.form_id = v.form_id) AS starred
+FROM form_views v
+JOIN forms f ON f.id = v.form_id
+LEFT JOIN units u ON u.id = f.unit_id
+WHERE v.user_id = @user_id
+ORDER BY v.viewed_at DESC
+LIMIT @row_limit;
\ No newline at end of file
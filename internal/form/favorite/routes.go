@@ -0,0 +1,13 @@
+package favorite
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the starred and recently viewed forms of the current user
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/starred", route.Authenticated, route.PermissionSelf, h.ListStarredHandler)
+	r.Handle("GET /forms/recent", route.Authenticated, route.PermissionSelf, h.ListRecentHandler)
+	r.Handle("PUT /forms/{id}/star", route.Authenticated, route.PermissionSelf, h.StarHandler)
+	r.Handle("DELETE /forms/{id}/star", route.Authenticated, route.PermissionSelf, h.UnstarHandler)
+}
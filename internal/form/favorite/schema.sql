@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS form_stars (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, form_id)
+);
+
+CREATE TABLE IF NOT EXISTS form_views (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, form_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_views_user_viewed_at ON form_views(user_id, viewed_at DESC);
\ No newline at end of file
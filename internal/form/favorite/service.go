@@ -0,0 +1,127 @@
+package favorite
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// RecentLimit is how many recently viewed forms are kept per user
+const RecentLimit = 20
+
+type Querier interface {
+	Star(ctx context.Context, arg StarParams) error
+	Unstar(ctx context.Context, arg UnstarParams) error
+	ListStarred(ctx context.Context, userID uuid.UUID) ([]ListStarredRow, error)
+	RecordView(ctx context.Context, arg RecordViewParams) error
+	TrimViews(ctx context.Context, arg TrimViewsParams) error
+	ListRecent(ctx context.Context, arg ListRecentParams) ([]ListRecentRow, error)
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("favorite/service"),
+	}
+}
+
+func (s *Service) Star(ctx context.Context, userID uuid.UUID, formID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Star")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.queries.Star(traceCtx, StarParams{UserID: userID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_stars", "form_id", formID.String(), logger, "star form")
+		if errors.Is(err, databaseutil.ErrForeignKeyViolation) {
+			err = internal.ErrFormNotFound
+		}
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) Unstar(ctx context.Context, userID uuid.UUID, formID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Unstar")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.queries.Unstar(traceCtx, UnstarParams{UserID: userID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_stars", "form_id", formID.String(), logger, "unstar form")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) ListStarred(ctx context.Context, userID uuid.UUID) ([]ListStarredRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListStarred")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	forms, err := s.queries.ListStarred(traceCtx, userID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_stars", "user_id", userID.String(), logger, "list starred forms")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return forms, nil
+}
+
+// RecordView moves the form to the top of the user's recently viewed list and drops
+// the entries beyond RecentLimit
+func (s *Service) RecordView(ctx context.Context, userID uuid.UUID, formID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "RecordView")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.queries.RecordView(traceCtx, RecordViewParams{UserID: userID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_views", "form_id", formID.String(), logger, "record form view")
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.queries.TrimViews(traceCtx, TrimViewsParams{UserID: userID, Keep: RecentLimit})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_views", "user_id", userID.String(), logger, "trim form views")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) ListRecent(ctx context.Context, userID uuid.UUID) ([]ListRecentRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListRecent")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	forms, err := s.queries.ListRecent(traceCtx, ListRecentParams{UserID: userID, RowLimit: RecentLimit})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_views", "user_id", userID.String(), logger, "list recent forms")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return forms, nil
+}
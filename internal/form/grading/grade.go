@@ -0,0 +1,144 @@
+package grading
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuestionGrade is the score of one answer. Graded is false while a question without
+// correct choices waits for a score by hand.
+type QuestionGrade struct {
+	QuestionID uuid.UUID
+	Points     int32
+	Score      int32
+	Graded     bool
+	Automatic  bool
+	Comment    string
+}
+
+// Grade is the score of a response, totalled over the scored questions of the form
+type Grade struct {
+	ResponseID  uuid.UUID
+	SubmittedBy uuid.UUID
+	CreatedAt   time.Time
+	Score       int32
+	MaxScore    int32
+	Pending     int
+	Questions   []QuestionGrade
+}
+
+// grade scores the answers of a response against the rubric. A score given by hand
+// wins; otherwise a choice question earns its points when exactly the correct choices
+// are selected, and nothing for any other selection or no answer at all.
+func grade(rubric []ListPointsRow, answers map[uuid.UUID]string, scores map[uuid.UUID]AnswerScore) Grade {
+	result := Grade{
+		Questions: make([]QuestionGrade, len(rubric)),
+	}
+
+	for i, item := range rubric {
+		questionGrade := QuestionGrade{
+			QuestionID: item.QuestionID,
+			Points:     item.Points,
+		}
+
+		if score, ok := scores[item.QuestionID]; ok {
+			questionGrade.Score = score.Score
+			questionGrade.Graded = true
+			questionGrade.Comment = score.Comment.String
+		} else if len(item.CorrectChoiceIds) > 0 {
+			questionGrade.Graded = true
+			questionGrade.Automatic = true
+			if isCorrect(answers[item.QuestionID], item.CorrectChoiceIds) {
+				questionGrade.Score = item.Points
+			}
+		}
+
+		result.MaxScore += item.Points
+		if questionGrade.Graded {
+			result.Score += questionGrade.Score
+		} else {
+			result.Pending++
+		}
+		result.Questions[i] = questionGrade
+	}
+
+	return result
+}
+
+// isCorrect reports whether the selected choice IDs, separated by ";" as choice
+// answers are stored, are exactly the correct ones
+func isCorrect(answer string, correct []uuid.UUID) bool {
+	var selected []string
+	for _, id := range strings.Split(answer, ";") {
+		id = strings.TrimSpace(id)
+		if id != "" && !slices.Contains(selected, id) {
+			selected = append(selected, id)
+		}
+	}
+	if len(selected) != len(correct) {
+		return false
+	}
+
+	for _, id := range correct {
+		if !slices.Contains(selected, id.String()) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildCSV writes one row per response with its total, followed by one column per
+// scored question. Questions still waiting for a score by hand are left empty.
+func buildCSV(rubric []ListPointsRow, grades []Grade) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"response_id", "submitted_by", "created_at", "score", "max_score", "pending"}
+	for _, item := range rubric {
+		title := item.Title.String
+		if title == "" {
+			title = item.QuestionID.String()
+		}
+		header = append(header, title)
+	}
+	err := writer.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, g := range grades {
+		record := []string{
+			g.ResponseID.String(),
+			g.SubmittedBy.String(),
+			g.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.Itoa(int(g.Score)),
+			strconv.Itoa(int(g.MaxScore)),
+			strconv.Itoa(g.Pending),
+		}
+		for _, questionGrade := range g.Questions {
+			if !questionGrade.Graded {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, strconv.Itoa(int(questionGrade.Score)))
+		}
+		err = writer.Write(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
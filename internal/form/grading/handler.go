@@ -0,0 +1,384 @@
+package grading
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	ListRubric(ctx context.Context, formID uuid.UUID) ([]ListPointsRow, error)
+	SetPoints(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, points int32, correctChoiceIDs []uuid.UUID) (QuestionPoint, error)
+	DeletePoints(ctx context.Context, formID uuid.UUID, questionID uuid.UUID) error
+	SetScore(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, questionID uuid.UUID, score int32, comment string, userID uuid.UUID) (Grade, error)
+	ClearScore(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, questionID uuid.UUID) (Grade, error)
+	GetGrade(ctx context.Context, formID uuid.UUID, responseID uuid.UUID) (Grade, error)
+	ListGrades(ctx context.Context, formID uuid.UUID) ([]ListPointsRow, []Grade, error)
+	ExportGrades(ctx context.Context, formID uuid.UUID) ([]byte, error)
+}
+
+// PointsRequest sets what a question is worth. Leaving out correctChoiceIds makes the
+// question scored by hand.
+type PointsRequest struct {
+	Points           int32       `json:"points" validate:"min=0,max=10000"`
+	CorrectChoiceIDs []uuid.UUID `json:"correctChoiceIds"`
+}
+
+type ScoreRequest struct {
+	Score   *int32 `json:"score" validate:"required,min=0"`
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+type PointsResponse struct {
+	QuestionID       string    `json:"questionId"`
+	Title            string    `json:"title,omitempty"`
+	Points           int32     `json:"points"`
+	CorrectChoiceIDs []string  `json:"correctChoiceIds"`
+	Automatic        bool      `json:"automatic"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+type QuestionGradeResponse struct {
+	QuestionID string  `json:"questionId"`
+	Points     int32   `json:"points"`
+	Score      *int32  `json:"score"`
+	Automatic  bool    `json:"automatic"`
+	Comment    *string `json:"comment,omitempty"`
+}
+
+type GradeResponse struct {
+	ResponseID  string                  `json:"responseId"`
+	SubmittedBy string                  `json:"submittedBy"`
+	CreatedAt   time.Time               `json:"createdAt"`
+	Score       int32                   `json:"score"`
+	MaxScore    int32                   `json:"maxScore"`
+	Pending     int                     `json:"pending"`
+	Questions   []QuestionGradeResponse `json:"questions"`
+}
+
+type GradesResponse struct {
+	Rubric []PointsResponse `json:"rubric"`
+	Grades []GradeResponse  `json:"grades"`
+}
+
+func ToPointsResponse(questionID uuid.UUID, title string, points int32, correctChoiceIDs []uuid.UUID, updatedAt time.Time) PointsResponse {
+	response := PointsResponse{
+		QuestionID:       questionID.String(),
+		Title:            title,
+		Points:           points,
+		CorrectChoiceIDs: make([]string, len(correctChoiceIDs)),
+		Automatic:        len(correctChoiceIDs) > 0,
+		UpdatedAt:        updatedAt,
+	}
+	for i, id := range correctChoiceIDs {
+		response.CorrectChoiceIDs[i] = id.String()
+	}
+	return response
+}
+
+func ToGradeResponse(grade Grade) GradeResponse {
+	response := GradeResponse{
+		ResponseID:  grade.ResponseID.String(),
+		SubmittedBy: grade.SubmittedBy.String(),
+		CreatedAt:   grade.CreatedAt,
+		Score:       grade.Score,
+		MaxScore:    grade.MaxScore,
+		Pending:     grade.Pending,
+		Questions:   make([]QuestionGradeResponse, len(grade.Questions)),
+	}
+	for i, questionGrade := range grade.Questions {
+		item := QuestionGradeResponse{
+			QuestionID: questionGrade.QuestionID.String(),
+			Points:     questionGrade.Points,
+			Automatic:  questionGrade.Automatic,
+		}
+		if questionGrade.Graded {
+			score := questionGrade.Score
+			item.Score = &score
+		}
+		if questionGrade.Comment != "" {
+			comment := questionGrade.Comment
+			item.Comment = &comment
+		}
+		response.Questions[i] = item
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("grading/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) ListRubricHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListRubricHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	rubric, err := h.store.ListRubric(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, toRubricResponse(rubric))
+}
+
+func toRubricResponse(rubric []ListPointsRow) []PointsResponse {
+	responses := make([]PointsResponse, len(rubric))
+	for i, item := range rubric {
+		responses[i] = ToPointsResponse(item.QuestionID, item.Title.String, item.Points, item.CorrectChoiceIds, item.UpdatedAt.Time)
+	}
+	return responses
+}
+
+func (h *Handler) SetPointsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetPointsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	questionID, err := internal.ParseUUID(r.PathValue("questionId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req PointsRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	points, err := h.store.SetPoints(traceCtx, formID, questionID, req.Points, req.CorrectChoiceIDs)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToPointsResponse(points.QuestionID, "", points.Points, points.CorrectChoiceIds, points.UpdatedAt.Time))
+}
+
+func (h *Handler) DeletePointsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeletePointsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	questionID, err := internal.ParseUUID(r.PathValue("questionId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.DeletePoints(traceCtx, formID, questionID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) GetGradeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetGradeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, responseID, err := parseResponsePath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	grade, err := h.store.GetGrade(traceCtx, formID, responseID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToGradeResponse(grade))
+}
+
+func (h *Handler) SetScoreHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetScoreHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, responseID, err := parseResponsePath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	questionID, err := internal.ParseUUID(r.PathValue("questionId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ScoreRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	grade, err := h.store.SetScore(traceCtx, formID, responseID, questionID, *req.Score, req.Comment, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToGradeResponse(grade))
+}
+
+func (h *Handler) ClearScoreHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ClearScoreHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, responseID, err := parseResponsePath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	questionID, err := internal.ParseUUID(r.PathValue("questionId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	grade, err := h.store.ClearScore(traceCtx, formID, responseID, questionID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToGradeResponse(grade))
+}
+
+// ListGradesHandler returns the rubric and the grade of every response of the form
+func (h *Handler) ListGradesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListGradesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	rubric, grades, err := h.store.ListGrades(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := GradesResponse{
+		Rubric: toRubricResponse(rubric),
+		Grades: make([]GradeResponse, len(grades)),
+	}
+	for i, grade := range grades {
+		response.Grades[i] = ToGradeResponse(grade)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) ExportGradesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ExportGradesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	content, err := h.store.ExportGrades(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "grades-"+formID.String()+".csv"))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(content)
+	if err != nil {
+		logger.Error("failed to write grades export", zap.Error(err))
+	}
+}
+
+func parseResponsePath(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	responseID, err := internal.ParseUUID(r.PathValue("responseId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return formID, responseID, nil
+}
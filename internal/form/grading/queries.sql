@@ -0,0 +1,54 @@
+-- name: GetQuestion :one
+SELECT q.id, q.type, q.metadata FROM questions q
+JOIN sections s ON s.id = q.section_id
+WHERE q.id = @question_id AND s.form_id = @form_id;
+
+-- name: ListPoints :many
+SELECT p.question_id, p.points, p.correct_choice_ids, q.title, p.updated_at FROM question_points p
+JOIN questions q ON q.id = p.question_id
+JOIN sections s ON s.id = q.section_id
+WHERE p.form_id = @form_id
+ORDER BY s.created_at ASC, q."order" ASC;
+
+-- name: GetPoints :one
+SELECT * FROM question_points
+WHERE question_id = @question_id AND form_id = @form_id;
+
+-- name: UpsertPoints :one
+INSERT INTO question_points (question_id, form_id, points, correct_choice_ids)
+VALUES (@question_id, @form_id, @points, @correct_choice_ids)
+ON CONFLICT (question_id) DO UPDATE
+SET points = EXCLUDED.points, correct_choice_ids = EXCLUDED.correct_choice_ids, updated_at = now()
+RETURNING *;
+
+-- name: DeletePoints :execrows
+DELETE FROM question_points
+WHERE question_id = @question_id AND form_id = @form_id;
+
+-- name: GetResponse :one
+SELECT id, submitted_by, created_at FROM form_responses
+WHERE id = @response_id AND form_id = @form_id;
+
+-- name: ListResponses :many
+SELECT id, submitted_by, created_at FROM form_responses
+WHERE form_id = @form_id AND NOT is_test
+ORDER BY created_at ASC;
+
+-- name: ListAnswers :many
+SELECT response_id, question_id, value FROM answers
+WHERE response_id = ANY(@response_ids::UUID[]);
+
+-- name: ListScores :many
+SELECT * FROM answer_scores
+WHERE response_id = ANY(@response_ids::UUID[]);
+
+-- name: UpsertScore :one
+INSERT INTO answer_scores (response_id, question_id, score, comment, graded_by)
+VALUES (@response_id, @question_id, @score, @comment, @graded_by)
+ON CONFLICT (response_id, question_id) DO UPDATE
+SET score = EXCLUDED.score, comment = EXCLUDED.comment, graded_by = EXCLUDED.graded_by, updated_at = now()
+RETURNING *;
+
+-- name: DeleteScore :execrows
+DELETE FROM answer_scores
+WHERE response_id = @response_id AND question_id = @question_id;
\ No newline at end of file
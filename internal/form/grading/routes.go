@@ -0,0 +1,17 @@
+package grading
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the rubric of a form, its grades and the scores of a response
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/rubric", route.Authenticated, route.PermissionNone, h.ListRubricHandler)
+	r.Handle("PUT /forms/{formId}/questions/{questionId}/points", route.Authenticated, route.PermissionNone, h.SetPointsHandler)
+	r.Handle("DELETE /forms/{formId}/questions/{questionId}/points", route.Authenticated, route.PermissionNone, h.DeletePointsHandler)
+	r.Handle("GET /forms/{id}/grades", route.Authenticated, route.PermissionNone, h.ListGradesHandler)
+	r.Handle("GET /forms/{id}/grades/export", route.Authenticated, route.PermissionNone, h.ExportGradesHandler)
+	r.Handle("GET /forms/{formId}/responses/{responseId}/grade", route.Authenticated, route.PermissionReviewer, h.GetGradeHandler)
+	r.Handle("PUT /forms/{formId}/responses/{responseId}/scores/{questionId}", route.Authenticated, route.PermissionReviewer, h.SetScoreHandler)
+	r.Handle("DELETE /forms/{formId}/responses/{responseId}/scores/{questionId}", route.Authenticated, route.PermissionReviewer, h.ClearScoreHandler)
+}
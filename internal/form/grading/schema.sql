@@ -0,0 +1,24 @@
+-- Points a question is worth. A choice question with correct choices is scored
+-- automatically; any other scored question is graded by hand.
+CREATE TABLE IF NOT EXISTS question_points (
+    question_id UUID PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    points INT NOT NULL CHECK (points >= 0),
+    correct_choice_ids UUID[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_points_form_id ON question_points(form_id);
+
+-- Scores given by hand. They also override the automatic score of a choice question.
+CREATE TABLE IF NOT EXISTS answer_scores (
+    response_id UUID NOT NULL REFERENCES form_responses(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    score INT NOT NULL CHECK (score >= 0),
+    comment TEXT,
+    graded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (response_id, question_id)
+);
\ No newline at end of file
@@ -0,0 +1,364 @@
+package grading
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	GetQuestion(ctx context.Context, arg GetQuestionParams) (GetQuestionRow, error)
+	ListPoints(ctx context.Context, formID uuid.UUID) ([]ListPointsRow, error)
+	GetPoints(ctx context.Context, arg GetPointsParams) (QuestionPoint, error)
+	UpsertPoints(ctx context.Context, arg UpsertPointsParams) (QuestionPoint, error)
+	DeletePoints(ctx context.Context, arg DeletePointsParams) (int64, error)
+	GetResponse(ctx context.Context, arg GetResponseParams) (GetResponseRow, error)
+	ListResponses(ctx context.Context, formID uuid.UUID) ([]ListResponsesRow, error)
+	ListAnswers(ctx context.Context, responseIds []uuid.UUID) ([]ListAnswersRow, error)
+	ListScores(ctx context.Context, responseIds []uuid.UUID) ([]AnswerScore, error)
+	UpsertScore(ctx context.Context, arg UpsertScoreParams) (AnswerScore, error)
+	DeleteScore(ctx context.Context, arg DeleteScoreParams) (int64, error)
+}
+
+// singleChoiceTypes accept one correct choice, multipleChoiceTypes any number of them
+var (
+	singleChoiceTypes   = []QuestionType{QuestionTypeSingleChoice, QuestionTypeDropdown}
+	multipleChoiceTypes = []QuestionType{QuestionTypeMultipleChoice, QuestionTypeDetailedMultipleChoice}
+)
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("grading/service"),
+	}
+}
+
+// ListRubric returns the scored questions of a form in form order
+func (s *Service) ListRubric(ctx context.Context, formID uuid.UUID) ([]ListPointsRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListRubric")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	rubric, err := s.queries.ListPoints(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "question_points", "form_id", formID.String(), logger, "list question points")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return rubric, nil
+}
+
+// SetPoints makes a question of the form worth points. Correct choices, if any, make
+// it scored automatically and must be choices of the question; a single choice or
+// dropdown question has at most one.
+func (s *Service) SetPoints(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, points int32, correctChoiceIDs []uuid.UUID) (QuestionPoint, error) {
+	traceCtx, span := s.tracer.Start(ctx, "SetPoints")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	q, err := s.queries.GetQuestion(traceCtx, GetQuestionParams{QuestionID: questionID, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrQuestionNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "questions", "id", questionID.String(), logger, "get question")
+		}
+		span.RecordError(err)
+		return QuestionPoint{}, err
+	}
+
+	if correctChoiceIDs == nil {
+		correctChoiceIDs = []uuid.UUID{}
+	}
+	if len(correctChoiceIDs) > 0 {
+		err = validateCorrectChoices(q, correctChoiceIDs)
+		if err != nil {
+			span.RecordError(err)
+			return QuestionPoint{}, err
+		}
+	}
+
+	row, err := s.queries.UpsertPoints(traceCtx, UpsertPointsParams{
+		QuestionID:       questionID,
+		FormID:           formID,
+		Points:           points,
+		CorrectChoiceIds: correctChoiceIDs,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "question_points", "question_id", questionID.String(), logger, "upsert question points")
+		span.RecordError(err)
+		return QuestionPoint{}, err
+	}
+
+	return row, nil
+}
+
+func validateCorrectChoices(q GetQuestionRow, correctChoiceIDs []uuid.UUID) error {
+	switch {
+	case slices.Contains(singleChoiceTypes, q.Type):
+		if len(correctChoiceIDs) > 1 {
+			return fmt.Errorf("%w: %s questions have one correct choice", internal.ErrCorrectChoiceNotValid, q.Type)
+		}
+	case slices.Contains(multipleChoiceTypes, q.Type):
+	default:
+		return fmt.Errorf("%w: %s questions are scored by hand", internal.ErrCorrectChoiceNotValid, q.Type)
+	}
+
+	choices, err := question.ExtractChoices(q.Metadata)
+	if err != nil {
+		return err
+	}
+	for _, id := range correctChoiceIDs {
+		if !slices.ContainsFunc(choices, func(choice question.Choice) bool { return choice.ID == id }) {
+			return fmt.Errorf("%w: %s is not a choice of the question", internal.ErrCorrectChoiceNotValid, id)
+		}
+	}
+	return nil
+}
+
+// DeletePoints takes a question out of the rubric; scores given for it are kept and
+// count again if the question is scored again
+func (s *Service) DeletePoints(ctx context.Context, formID uuid.UUID, questionID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "DeletePoints")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	deleted, err := s.queries.DeletePoints(traceCtx, DeletePointsParams{QuestionID: questionID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "question_points", "question_id", questionID.String(), logger, "delete question points")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		err = internal.ErrQuestionNotScored
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// SetScore scores an answer by hand, within the points of the question. It is how text
+// answers are graded and also overrides the automatic score of a choice question.
+func (s *Service) SetScore(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, questionID uuid.UUID, score int32, comment string, userID uuid.UUID) (Grade, error) {
+	traceCtx, span := s.tracer.Start(ctx, "SetScore")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkResponse(traceCtx, logger, formID, responseID)
+	if err != nil {
+		span.RecordError(err)
+		return Grade{}, err
+	}
+
+	points, err := s.queries.GetPoints(traceCtx, GetPointsParams{QuestionID: questionID, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrQuestionNotScored
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "question_points", "question_id", questionID.String(), logger, "get question points")
+		}
+		span.RecordError(err)
+		return Grade{}, err
+	}
+	if score < 0 || score > points.Points {
+		err = fmt.Errorf("%w: %d of %d", internal.ErrScoreOutOfRange, score, points.Points)
+		span.RecordError(err)
+		return Grade{}, err
+	}
+
+	_, err = s.queries.UpsertScore(traceCtx, UpsertScoreParams{
+		ResponseID: responseID,
+		QuestionID: questionID,
+		Score:      score,
+		Comment:    pgtype.Text{String: comment, Valid: comment != ""},
+		GradedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answer_scores", "response_id", responseID.String(), logger, "upsert answer score")
+		span.RecordError(err)
+		return Grade{}, err
+	}
+
+	return s.GetGrade(traceCtx, formID, responseID)
+}
+
+// ClearScore removes the score given by hand, so a choice question falls back to its
+// automatic score and any other question waits for grading again
+func (s *Service) ClearScore(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, questionID uuid.UUID) (Grade, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ClearScore")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkResponse(traceCtx, logger, formID, responseID)
+	if err != nil {
+		span.RecordError(err)
+		return Grade{}, err
+	}
+
+	_, err = s.queries.DeleteScore(traceCtx, DeleteScoreParams{ResponseID: responseID, QuestionID: questionID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answer_scores", "response_id", responseID.String(), logger, "delete answer score")
+		span.RecordError(err)
+		return Grade{}, err
+	}
+
+	return s.GetGrade(traceCtx, formID, responseID)
+}
+
+func (s *Service) checkResponse(ctx context.Context, logger *zap.Logger, formID uuid.UUID, responseID uuid.UUID) error {
+	_, err := s.queries.GetResponse(ctx, GetResponseParams{ResponseID: responseID, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return internal.ErrResponseNotFound
+		}
+		return databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "get response")
+	}
+	return nil
+}
+
+func (s *Service) GetGrade(ctx context.Context, formID uuid.UUID, responseID uuid.UUID) (Grade, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetGrade")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	response, err := s.queries.GetResponse(traceCtx, GetResponseParams{ResponseID: responseID, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrResponseNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "get response")
+		}
+		span.RecordError(err)
+		return Grade{}, err
+	}
+
+	rubric, err := s.ListRubric(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Grade{}, err
+	}
+
+	grades, err := s.grade(traceCtx, logger, rubric, []ListResponsesRow{{
+		ID:          response.ID,
+		SubmittedBy: response.SubmittedBy,
+		CreatedAt:   response.CreatedAt,
+	}})
+	if err != nil {
+		span.RecordError(err)
+		return Grade{}, err
+	}
+
+	return grades[0], nil
+}
+
+// ListGrades grades every response of the form, test responses excluded, and returns
+// the rubric the grades were calculated with
+func (s *Service) ListGrades(ctx context.Context, formID uuid.UUID) ([]ListPointsRow, []Grade, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListGrades")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	rubric, err := s.ListRubric(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	responses, err := s.queries.ListResponses(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "list responses")
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	grades, err := s.grade(traceCtx, logger, rubric, responses)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	return rubric, grades, nil
+}
+
+// ExportGrades returns the grades of the form as a CSV file
+func (s *Service) ExportGrades(ctx context.Context, formID uuid.UUID) ([]byte, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ExportGrades")
+	defer span.End()
+
+	rubric, grades, err := s.ListGrades(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	content, err := buildCSV(rubric, grades)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return content, nil
+}
+
+func (s *Service) grade(ctx context.Context, logger *zap.Logger, rubric []ListPointsRow, responses []ListResponsesRow) ([]Grade, error) {
+	responseIDs := make([]uuid.UUID, len(responses))
+	for i, response := range responses {
+		responseIDs[i] = response.ID
+	}
+
+	answerRows, err := s.queries.ListAnswers(ctx, responseIDs)
+	if err != nil {
+		return nil, databaseutil.WrapDBError(err, logger, "list answers")
+	}
+	answers := make(map[uuid.UUID]map[uuid.UUID]string, len(responses))
+	for _, row := range answerRows {
+		if answers[row.ResponseID] == nil {
+			answers[row.ResponseID] = make(map[uuid.UUID]string)
+		}
+		answers[row.ResponseID][row.QuestionID] = row.Value
+	}
+
+	scoreRows, err := s.queries.ListScores(ctx, responseIDs)
+	if err != nil {
+		return nil, databaseutil.WrapDBError(err, logger, "list answer scores")
+	}
+	scores := make(map[uuid.UUID]map[uuid.UUID]AnswerScore, len(responses))
+	for _, row := range scoreRows {
+		if scores[row.ResponseID] == nil {
+			scores[row.ResponseID] = make(map[uuid.UUID]AnswerScore)
+		}
+		scores[row.ResponseID][row.QuestionID] = row
+	}
+
+	grades := make([]Grade, len(responses))
+	for i, response := range responses {
+		g := grade(rubric, answers[response.ID], scores[response.ID])
+		g.ResponseID = response.ID
+		g.SubmittedBy = response.SubmittedBy
+		g.CreatedAt = response.CreatedAt.Time
+		grades[i] = g
+	}
+
+	return grades, nil
+}
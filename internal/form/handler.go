@@ -2,14 +2,19 @@ package form
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/conditional"
 	"NYCU-SDC/core-system-backend/internal/user"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	pagutil "github.com/NYCU-SDC/summer/pkg/pagination"
 	"github.com/NYCU-SDC/summer/pkg/problem"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -23,24 +28,35 @@ type Request struct {
 	Description    string     `json:"description"`
 	PreviewMessage string     `json:"previewMessage"`
 	Deadline       *time.Time `json:"deadline"`
+
+	// RandomizeQuestions shuffles the questions within each section and ShuffleChoices
+	// the choices of each choice question, per respondent
+	RandomizeQuestions bool `json:"randomizeQuestions"`
+	ShuffleChoices     bool `json:"shuffleChoices"`
+
+	// TimeLimitSeconds gives each respondent that long from starting the form to submit it
+	TimeLimitSeconds *int32 `json:"timeLimitSeconds" validate:"omitempty,min=1"`
 }
 
 type Response struct {
-	ID             string               `json:"id"`
-	Title          string               `json:"title"`
-	Description    string               `json:"description"`
-	PreviewMessage string               `json:"previewMessage"`
-	Status         string               `json:"status"`
-	UnitID         string               `json:"unitId"`
-	OrgID          string               `json:"orgId"`
-	LastEditor     user.ProfileResponse `json:"lastEditor"`
-	Deadline       *time.Time           `json:"deadline"`
-	CreatedAt      time.Time            `json:"createdAt"`
-	UpdatedAt      time.Time            `json:"updatedAt"`
+	ID                 string               `json:"id"`
+	Title              string               `json:"title"`
+	Description        string               `json:"description"`
+	PreviewMessage     string               `json:"previewMessage"`
+	Status             string               `json:"status"`
+	UnitID             string               `json:"unitId"`
+	OrgID              string               `json:"orgId"`
+	LastEditor         user.ProfileResponse `json:"lastEditor"`
+	Deadline           *time.Time           `json:"deadline"`
+	RandomizeQuestions bool                 `json:"randomizeQuestions"`
+	ShuffleChoices     bool                 `json:"shuffleChoices"`
+	TimeLimitSeconds   *int32               `json:"timeLimitSeconds"`
+	CreatedAt          time.Time            `json:"createdAt"`
+	UpdatedAt          time.Time            `json:"updatedAt"`
 }
 
 // ToResponse converts a Form storage model into an API Response.
-// Ensures deadline is null when empty/invalid.
+// Ensures deadline and time limit are null when empty/invalid.
 func ToResponse(form Form, unitName string, orgName string, editor user.User, emails []string) Response {
 	var deadline *time.Time
 
@@ -50,6 +66,11 @@ func ToResponse(form Form, unitName string, orgName string, editor user.User, em
 		deadline = nil
 	}
 
+	var timeLimit *int32
+	if form.TimeLimitSeconds.Valid {
+		timeLimit = &form.TimeLimitSeconds.Int32
+	}
+
 	return Response{
 		ID:             form.ID.String(),
 		Title:          form.Title,
@@ -65,10 +86,107 @@ func ToResponse(form Form, unitName string, orgName string, editor user.User, em
 			Emails:    emails,
 			AvatarURL: editor.AvatarUrl.String,
 		},
-		Deadline:  deadline,
-		CreatedAt: form.CreatedAt.Time,
-		UpdatedAt: form.UpdatedAt.Time,
+		Deadline:           deadline,
+		RandomizeQuestions: form.RandomizeQuestions,
+		ShuffleChoices:     form.ShuffleChoices,
+		TimeLimitSeconds:   timeLimit,
+		CreatedAt:          form.CreatedAt.Time,
+		UpdatedAt:          form.UpdatedAt.Time,
+	}
+}
+
+// ListItemResponse is a form in GET /api/forms
+type ListItemResponse struct {
+	Response
+	ResponseCount int64    `json:"responseCount"`
+	TagIDs        []string `json:"tagIds"`
+}
+
+// ListFields are the keys a fields= projection of the form list may select
+var ListFields = []string{
+	"id", "title", "description", "previewMessage", "status", "unitId", "orgId",
+	"lastEditor", "deadline", "randomizeQuestions", "shuffleChoices", "timeLimitSeconds", "createdAt", "updatedAt", "responseCount", "tagIds",
+}
+
+// queryList collects a repeatable, comma separated query parameter
+func queryList(r *http.Request, name string) []string {
+	var values []string
+	for _, value := range r.URL.Query()[name] {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
+
+// parseListFilter reads ?status=draft,published&unitId=...&tagId=...&sort=-responseCount,title
+func parseListFilter(r *http.Request) (ListFilter, error) {
+	var filter ListFilter
+
+	for _, value := range queryList(r, "status") {
+		status := Status(value)
+		if status != StatusDraft && status != StatusPublished {
+			return ListFilter{}, fmt.Errorf("%w: unknown status %q", internal.ErrInvalidQueryParameter, value)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	for _, value := range queryList(r, "unitId") {
+		unitID, err := uuid.Parse(value)
+		if err != nil {
+			return ListFilter{}, fmt.Errorf("%w: unitId %q is not a UUID", internal.ErrInvalidQueryParameter, value)
+		}
+		filter.UnitIDs = append(filter.UnitIDs, unitID)
+	}
+
+	for _, value := range queryList(r, "tagId") {
+		tagID, err := uuid.Parse(value)
+		if err != nil {
+			return ListFilter{}, fmt.Errorf("%w: tagId %q is not a UUID", internal.ErrInvalidQueryParameter, value)
+		}
+		filter.TagIDs = append(filter.TagIDs, tagID)
+	}
+
+	for _, value := range queryList(r, "sort") {
+		key := SortKey{Field: strings.TrimPrefix(value, "-"), Descending: strings.HasPrefix(value, "-")}
+		if !slices.Contains(SortableFields, key.Field) {
+			return ListFilter{}, fmt.Errorf("%w: %s, valid: %v", pagutil.ErrInvalidSortingField, key.Field, SortableFields)
+		}
+		filter.Sort = append(filter.Sort, key)
 	}
+
+	return filter, nil
+}
+
+func tagIDStrings(ids []uuid.UUID) []string {
+	tagIDs := make([]string, len(ids))
+	for i, id := range ids {
+		tagIDs[i] = id.String()
+	}
+	return tagIDs
+}
+
+// project keeps only the requested keys of a list item
+func project(item ListItemResponse, fields []string) (map[string]json.RawMessage, error) {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(body, &all)
+	if err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]json.RawMessage, len(fields))
+	for _, field := range fields {
+		projected[field] = all[field]
+	}
+	return projected, nil
 }
 
 type Store interface {
@@ -76,7 +194,7 @@ type Store interface {
 	Update(ctx context.Context, id uuid.UUID, request Request, userID uuid.UUID) (UpdateRow, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByID(ctx context.Context, id uuid.UUID) (GetByIDRow, error)
-	List(ctx context.Context) ([]ListRow, error)
+	List(ctx context.Context, filter ListFilter) ([]ListRow, error)
 	ListByUnit(ctx context.Context, unitID uuid.UUID) ([]ListByUnitRow, error)
 	SetStatus(ctx context.Context, id uuid.UUID, status Status, userID uuid.UUID) (Form, error)
 }
@@ -85,6 +203,12 @@ type tenantStore interface {
 	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
 }
 
+// quotaStore holds an organization to its quota of forms
+type quotaStore interface {
+	Check(ctx context.Context, orgID uuid.UUID) error
+	Observe(ctx context.Context, orgID uuid.UUID)
+}
+
 type Handler struct {
 	logger *zap.Logger
 	tracer trace.Tracer
@@ -94,6 +218,7 @@ type Handler struct {
 
 	store       Store
 	tenantStore tenantStore
+	quotaStore  quotaStore
 }
 
 func NewHandler(
@@ -102,6 +227,7 @@ func NewHandler(
 	problemWriter *problem.HttpWriter,
 	store Store,
 	tenantStore tenantStore,
+	quotaStore quotaStore,
 ) *Handler {
 	return &Handler{
 		logger:        logger,
@@ -110,6 +236,7 @@ func NewHandler(
 		problemWriter: problemWriter,
 		store:         store,
 		tenantStore:   tenantStore,
+		quotaStore:    quotaStore,
 	}
 }
 
@@ -126,7 +253,7 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req Request
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
@@ -144,16 +271,19 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := ToResponse(Form{
-		ID:             currentForm.ID,
-		Title:          currentForm.Title,
-		Description:    currentForm.Description,
-		PreviewMessage: currentForm.PreviewMessage,
-		Status:         currentForm.Status,
-		UnitID:         currentForm.UnitID,
-		LastEditor:     currentForm.LastEditor,
-		Deadline:       currentForm.Deadline,
-		CreatedAt:      currentForm.CreatedAt,
-		UpdatedAt:      currentForm.UpdatedAt,
+		ID:                 currentForm.ID,
+		Title:              currentForm.Title,
+		Description:        currentForm.Description,
+		PreviewMessage:     currentForm.PreviewMessage,
+		Status:             currentForm.Status,
+		UnitID:             currentForm.UnitID,
+		LastEditor:         currentForm.LastEditor,
+		Deadline:           currentForm.Deadline,
+		RandomizeQuestions: currentForm.RandomizeQuestions,
+		ShuffleChoices:     currentForm.ShuffleChoices,
+		TimeLimitSeconds:   currentForm.TimeLimitSeconds,
+		CreatedAt:          currentForm.CreatedAt,
+		UpdatedAt:          currentForm.UpdatedAt,
 	},
 		currentForm.UnitName.String,
 		currentForm.OrgName.String,
@@ -207,16 +337,19 @@ func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response := ToResponse(Form{
-		ID:             currentForm.ID,
-		Title:          currentForm.Title,
-		Description:    currentForm.Description,
-		PreviewMessage: currentForm.PreviewMessage,
-		Status:         currentForm.Status,
-		UnitID:         currentForm.UnitID,
-		LastEditor:     currentForm.LastEditor,
-		Deadline:       currentForm.Deadline,
-		CreatedAt:      currentForm.CreatedAt,
-		UpdatedAt:      currentForm.UpdatedAt,
+		ID:                 currentForm.ID,
+		Title:              currentForm.Title,
+		Description:        currentForm.Description,
+		PreviewMessage:     currentForm.PreviewMessage,
+		Status:             currentForm.Status,
+		UnitID:             currentForm.UnitID,
+		LastEditor:         currentForm.LastEditor,
+		Deadline:           currentForm.Deadline,
+		RandomizeQuestions: currentForm.RandomizeQuestions,
+		ShuffleChoices:     currentForm.ShuffleChoices,
+		TimeLimitSeconds:   currentForm.TimeLimitSeconds,
+		CreatedAt:          currentForm.CreatedAt,
+		UpdatedAt:          currentForm.UpdatedAt,
 	},
 		currentForm.UnitName.String,
 		currentForm.OrgName.String,
@@ -227,6 +360,7 @@ func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 			AvatarUrl: currentForm.LastEditorAvatarUrl,
 		},
 		user.ConvertEmailsToSlice(currentForm.LastEditorEmail))
+	conditional.SetLastModified(w, currentForm.UpdatedAt.Time)
 	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
 }
 
@@ -235,32 +369,73 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, h.logger)
 
-	forms, err := h.store.List(traceCtx)
+	filter, err := parseListFilter(r)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 
-	responses := make([]Response, 0, len(forms))
+	fields := queryList(r, "fields")
+	for _, field := range fields {
+		if !slices.Contains(ListFields, field) {
+			h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: unknown field %q", internal.ErrInvalidQueryParameter, field), logger)
+			return
+		}
+	}
+
+	forms, err := h.store.List(traceCtx, filter)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	items := make([]ListItemResponse, 0, len(forms))
 	for _, form := range forms {
-		responses = append(responses, ToResponse(Form{
-			ID:             form.ID,
-			Title:          form.Title,
-			Description:    form.Description,
-			PreviewMessage: form.PreviewMessage,
-			Status:         form.Status,
-		},
-			form.UnitName.String,
-			form.OrgName.String,
-			user.User{
-				ID:        form.LastEditor,
-				Name:      form.LastEditorName,
-				Username:  form.LastEditorUsername,
-				AvatarUrl: form.LastEditorAvatarUrl,
+		items = append(items, ListItemResponse{
+			Response: ToResponse(Form{
+				ID:                 form.ID,
+				Title:              form.Title,
+				Description:        form.Description,
+				PreviewMessage:     form.PreviewMessage,
+				Status:             form.Status,
+				UnitID:             form.UnitID,
+				LastEditor:         form.LastEditor,
+				Deadline:           form.Deadline,
+				RandomizeQuestions: form.RandomizeQuestions,
+				ShuffleChoices:     form.ShuffleChoices,
+				TimeLimitSeconds:   form.TimeLimitSeconds,
+				CreatedAt:          form.CreatedAt,
+				UpdatedAt:          form.UpdatedAt,
 			},
-			user.ConvertEmailsToSlice(form.LastEditorEmail)))
+				form.UnitName.String,
+				form.OrgName.String,
+				user.User{
+					ID:        form.LastEditor,
+					Name:      form.LastEditorName,
+					Username:  form.LastEditorUsername,
+					AvatarUrl: form.LastEditorAvatarUrl,
+				},
+				user.ConvertEmailsToSlice(form.LastEditorEmail)),
+			ResponseCount: form.ResponseCount,
+			TagIDs:        tagIDStrings(form.TagIds),
+		})
 	}
-	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+
+	if len(fields) == 0 {
+		handlerutil.WriteJSONResponse(w, http.StatusOK, items)
+		return
+	}
+
+	projected := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		fieldsOfItem, err := project(item, fields)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		projected = append(projected, fieldsOfItem)
+	}
+	handlerutil.WriteJSONResponse(w, http.StatusOK, projected)
 }
 
 func (h *Handler) CreateUnderOrgHandler(w http.ResponseWriter, r *http.Request) {
@@ -269,7 +444,7 @@ func (h *Handler) CreateUnderOrgHandler(w http.ResponseWriter, r *http.Request)
 	logger := logutil.WithContext(traceCtx, h.logger)
 
 	var req Request
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
@@ -292,23 +467,33 @@ func (h *Handler) CreateUnderOrgHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	err = h.quotaStore.Check(traceCtx, orgID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
 	newForm, err := h.store.Create(traceCtx, req, orgID, currentUser.ID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
+	h.quotaStore.Observe(traceCtx, orgID)
 
 	response := ToResponse(Form{
-		ID:             newForm.ID,
-		Title:          newForm.Title,
-		Description:    newForm.Description,
-		PreviewMessage: newForm.PreviewMessage,
-		Status:         newForm.Status,
-		UnitID:         newForm.UnitID,
-		LastEditor:     newForm.LastEditor,
-		Deadline:       newForm.Deadline,
-		CreatedAt:      newForm.CreatedAt,
-		UpdatedAt:      newForm.UpdatedAt,
+		ID:                 newForm.ID,
+		Title:              newForm.Title,
+		Description:        newForm.Description,
+		PreviewMessage:     newForm.PreviewMessage,
+		Status:             newForm.Status,
+		UnitID:             newForm.UnitID,
+		LastEditor:         newForm.LastEditor,
+		Deadline:           newForm.Deadline,
+		RandomizeQuestions: newForm.RandomizeQuestions,
+		ShuffleChoices:     newForm.ShuffleChoices,
+		TimeLimitSeconds:   newForm.TimeLimitSeconds,
+		CreatedAt:          newForm.CreatedAt,
+		UpdatedAt:          newForm.UpdatedAt,
 	},
 		newForm.UnitName.String,
 		newForm.OrgName.String,
@@ -348,16 +533,19 @@ func (h *Handler) ListByOrgHandler(w http.ResponseWriter, r *http.Request) {
 	responses := make([]Response, len(forms))
 	for i, currentForm := range forms {
 		responses[i] = ToResponse(Form{
-			ID:             currentForm.ID,
-			Title:          currentForm.Title,
-			Description:    currentForm.Description,
-			PreviewMessage: currentForm.PreviewMessage,
-			Status:         currentForm.Status,
-			UnitID:         currentForm.UnitID,
-			LastEditor:     currentForm.LastEditor,
-			Deadline:       currentForm.Deadline,
-			CreatedAt:      currentForm.CreatedAt,
-			UpdatedAt:      currentForm.UpdatedAt,
+			ID:                 currentForm.ID,
+			Title:              currentForm.Title,
+			Description:        currentForm.Description,
+			PreviewMessage:     currentForm.PreviewMessage,
+			Status:             currentForm.Status,
+			UnitID:             currentForm.UnitID,
+			LastEditor:         currentForm.LastEditor,
+			Deadline:           currentForm.Deadline,
+			RandomizeQuestions: currentForm.RandomizeQuestions,
+			ShuffleChoices:     currentForm.ShuffleChoices,
+			TimeLimitSeconds:   currentForm.TimeLimitSeconds,
+			CreatedAt:          currentForm.CreatedAt,
+			UpdatedAt:          currentForm.UpdatedAt,
 		}, currentForm.UnitName.String, currentForm.OrgName.String, user.User{
 			ID:        currentForm.LastEditor,
 			Name:      currentForm.LastEditorName,
@@ -0,0 +1,269 @@
+package importer
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxRows is the largest number of questions a single import may create
+const MaxRows = 500
+
+// listSeparator splits the list cells of a CSV row, e.g. "Yes|No|Maybe"
+const listSeparator = "|"
+
+// Definition is a form and its questions in spreadsheet order. Consecutive or not,
+// questions naming the same section end up in one section, and sections keep the
+// order in which they are first named.
+type Definition struct {
+	Title          string     `json:"title" validate:"required"`
+	Description    string     `json:"description"`
+	PreviewMessage string     `json:"previewMessage"`
+	Deadline       *time.Time `json:"deadline"`
+	Questions      []Row      `json:"questions"`
+}
+
+// Row is one question of a definition. The options follow the question API, so a
+// LINEAR_SCALE row sets MinVal and MaxVal and an UPLOAD_FILE row the file options.
+type Row struct {
+	Section          string                  `json:"section"`
+	Type             string                  `json:"type"`
+	Title            string                  `json:"title"`
+	Description      string                  `json:"description"`
+	Required         bool                    `json:"required"`
+	Choices          []question.ChoiceOption `json:"choices"`
+	MinVal           int                     `json:"minVal"`
+	MaxVal           int                     `json:"maxVal"`
+	MinValueLabel    string                  `json:"minValueLabel"`
+	MaxValueLabel    string                  `json:"maxValueLabel"`
+	Icon             string                  `json:"icon"`
+	AllowedFileTypes []string                `json:"allowedFileTypes"`
+	MaxFileAmount    int32                   `json:"maxFileAmount"`
+	MaxFileSizeLimit string                  `json:"maxFileSizeLimit"`
+	OauthConnect     string                  `json:"oauthConnect"`
+
+	// Line is the CSV line the row was read from
+	Line int `json:"-"`
+}
+
+// RowError reports why a row of the definition cannot be imported. Row counts the
+// questions of a JSON definition from 1, and is the line number for a CSV file,
+// whose header is line 1; 0 means the definition as a whole.
+type RowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every row error of a definition
+type ValidationError struct {
+	Rows []RowError
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("form definition has %d invalid rows", len(e.Rows))
+}
+
+// CSVColumns are the header names a CSV definition may use, matched case
+// insensitively; type and title are required
+var CSVColumns = []string{
+	"section", "type", "title", "description", "required", "choices", "choiceDescriptions",
+	"minVal", "maxVal", "minValueLabel", "maxValueLabel", "icon",
+	"allowedFileTypes", "maxFileAmount", "maxFileSizeLimit", "oauthConnect",
+}
+
+// ParseCSV reads the questions of a CSV definition, one question per line after the
+// header. List cells such as choices are separated by "|".
+func ParseCSV(reader io.Reader) ([]Row, []RowError) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, []RowError{{Row: 1, Message: "missing header"}}
+		}
+		return nil, []RowError{{Row: 1, Message: err.Error()}}
+	}
+
+	columns := make(map[string]int, len(header))
+	var rowErrors []RowError
+	for i, name := range header {
+		column, ok := csvColumn(name)
+		if !ok {
+			rowErrors = append(rowErrors, RowError{Row: 1, Field: name, Message: "unknown column"})
+			continue
+		}
+		if _, duplicate := columns[column]; duplicate {
+			rowErrors = append(rowErrors, RowError{Row: 1, Field: name, Message: "duplicate column"})
+			continue
+		}
+		columns[column] = i
+	}
+	for _, column := range []string{"type", "title"} {
+		if _, ok := columns[column]; !ok {
+			rowErrors = append(rowErrors, RowError{Row: 1, Field: column, Message: "missing column"})
+		}
+	}
+	if len(rowErrors) > 0 {
+		return nil, rowErrors
+	}
+
+	var rows []Row
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseError *csv.ParseError
+			if !errors.As(err, &parseError) {
+				rowErrors = append(rowErrors, RowError{Message: err.Error()})
+				break
+			}
+			rowErrors = append(rowErrors, RowError{Row: parseError.StartLine, Message: parseError.Err.Error()})
+			// A row with the wrong number of cells does not keep the reader from the next one
+			if errors.Is(parseError.Err, csv.ErrFieldCount) {
+				continue
+			}
+			break
+		}
+		line, _ := csvReader.FieldPos(0)
+
+		cell := func(column string) string {
+			i, ok := columns[column]
+			if !ok {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		row, errs := parseCSVRow(line, cell)
+		rowErrors = append(rowErrors, errs...)
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors
+}
+
+func csvColumn(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	for _, column := range CSVColumns {
+		if strings.EqualFold(column, name) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+func parseCSVRow(line int, cell func(string) string) (Row, []RowError) {
+	row := Row{
+		Line:             line,
+		Section:          cell("section"),
+		Type:             cell("type"),
+		Title:            cell("title"),
+		Description:      cell("description"),
+		MinValueLabel:    cell("minValueLabel"),
+		MaxValueLabel:    cell("maxValueLabel"),
+		Icon:             cell("icon"),
+		AllowedFileTypes: splitList(cell("allowedFileTypes")),
+		MaxFileSizeLimit: cell("maxFileSizeLimit"),
+		OauthConnect:     cell("oauthConnect"),
+	}
+	var rowErrors []RowError
+
+	if value := cell("required"); value != "" {
+		required, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Field: "required", Message: fmt.Sprintf("%q is not a boolean", value)})
+		}
+		row.Required = required
+	}
+
+	descriptions := splitList(cell("choiceDescriptions"))
+	for i, name := range splitList(cell("choices")) {
+		option := question.ChoiceOption{Name: name}
+		if i < len(descriptions) {
+			option.Description = descriptions[i]
+		}
+		row.Choices = append(row.Choices, option)
+	}
+	if len(descriptions) > len(row.Choices) {
+		rowErrors = append(rowErrors, RowError{Row: line, Field: "choiceDescriptions", Message: "more descriptions than choices"})
+	}
+
+	for _, field := range []struct {
+		name   string
+		target *int
+	}{
+		{"minVal", &row.MinVal},
+		{"maxVal", &row.MaxVal},
+	} {
+		value := cell(field.name)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Field: field.name, Message: fmt.Sprintf("%q is not an integer", value)})
+			continue
+		}
+		*field.target = number
+	}
+
+	if value := cell("maxFileAmount"); value != "" {
+		amount, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Field: "maxFileAmount", Message: fmt.Sprintf("%q is not an integer", value)})
+		}
+		row.MaxFileAmount = int32(amount)
+	}
+
+	return row, rowErrors
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, listSeparator) {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ToQuestionRequest converts the row to a request of the question API, with its order
+// within the section
+func (r Row) ToQuestionRequest(order int32) question.Request {
+	required := r.Required
+	return question.Request{
+		Required:    &required,
+		Type:        strings.ToLower(strings.TrimSpace(r.Type)),
+		Title:       strings.TrimSpace(r.Title),
+		Description: r.Description,
+		Order:       order,
+		Choices:     r.Choices,
+		Scale: question.ScaleOption{
+			Icon:          r.Icon,
+			MinVal:        r.MinVal,
+			MaxVal:        r.MaxVal,
+			MinValueLabel: r.MinValueLabel,
+			MaxValueLabel: r.MaxValueLabel,
+		},
+		UploadFile: question.UploadFileOption{
+			AllowedFileTypes: r.AllowedFileTypes,
+			MaxFileAmount:    r.MaxFileAmount,
+			MaxFileSizeLimit: r.MaxFileSizeLimit,
+		},
+		OauthConnect: r.OauthConnect,
+	}
+}
@@ -0,0 +1,202 @@
+package importer
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DocumentVersion is the version of the Document layout written by Export. Import
+// rejects documents of any other version.
+const DocumentVersion = 1
+
+// Document is a complete form definition for backup and for promoting a form between
+// environments. The IDs in it are those of the exporting form; an import gives every
+// section, question and workflow node a new ID and rewrites the references to them.
+type Document struct {
+	Version    int               `json:"version" validate:"required"`
+	ExportedAt time.Time         `json:"exportedAt"`
+	Form       DocumentForm      `json:"form" validate:"required"`
+	Sections   []DocumentSection `json:"sections" validate:"dive"`
+	Workflow   json.RawMessage   `json:"workflow" validate:"required"`
+}
+
+type DocumentForm struct {
+	Title              string     `json:"title" validate:"required"`
+	Description        string     `json:"description"`
+	PreviewMessage     string     `json:"previewMessage"`
+	Deadline           *time.Time `json:"deadline"`
+	RandomizeQuestions bool       `json:"randomizeQuestions"`
+	ShuffleChoices     bool       `json:"shuffleChoices"`
+	TimeLimitSeconds   *int32     `json:"timeLimitSeconds,omitempty"`
+}
+
+type DocumentSection struct {
+	ID          uuid.UUID          `json:"id" validate:"required"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Questions   []DocumentQuestion `json:"questions" validate:"dive"`
+}
+
+// DocumentQuestion keeps the stored metadata of a question as is, so choice IDs
+// survive a round trip and conditions on them stay valid
+type DocumentQuestion struct {
+	ID          uuid.UUID       `json:"id" validate:"required"`
+	Type        string          `json:"type" validate:"required"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Required    bool            `json:"required"`
+	Order       int32           `json:"order"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+	SourceID    *uuid.UUID      `json:"sourceId,omitempty"`
+}
+
+func toDocumentSection(section question.SectionWithQuestions) DocumentSection {
+	documentSection := DocumentSection{
+		ID:          section.Section.ID,
+		Title:       section.Section.Title.String,
+		Description: section.Section.Description.String,
+		Questions:   make([]DocumentQuestion, len(section.Questions)),
+	}
+
+	for i, answerable := range section.Questions {
+		q := answerable.Question()
+		documentQuestion := DocumentQuestion{
+			ID:          q.ID,
+			Type:        string(q.Type),
+			Title:       q.Title.String,
+			Description: q.Description.String,
+			Required:    q.Required,
+			Order:       q.Order,
+			Metadata:    q.Metadata,
+		}
+		if q.SourceID.Valid {
+			sourceID := uuid.UUID(q.SourceID.Bytes)
+			documentQuestion.SourceID = &sourceID
+		}
+		documentSection.Questions[i] = documentQuestion
+	}
+
+	return documentSection
+}
+
+// validateDocument checks a document before anything is imported. Row counts the
+// questions of the document from 1, in section order.
+func validateDocument(document Document) ([]map[string]any, error) {
+	if document.Version != DocumentVersion {
+		return nil, ValidationError{Rows: []RowError{{Field: "version", Message: fmt.Sprintf("unsupported document version %d, expected %d", document.Version, DocumentVersion)}}}
+	}
+
+	var nodes []map[string]any
+	err := json.Unmarshal(document.Workflow, &nodes)
+	if err != nil {
+		return nil, ValidationError{Rows: []RowError{{Field: "workflow", Message: "workflow must be an array of nodes"}}}
+	}
+
+	var rowErrors []RowError
+	nodeTypes := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		id, _ := n["id"].(string)
+		nodeType, _ := n["type"].(string)
+		if id == "" || nodeType == "" {
+			rowErrors = append(rowErrors, RowError{Field: "workflow", Message: "every workflow node needs an id and a type"})
+			continue
+		}
+		nodeTypes[id] = nodeType
+	}
+	for _, nodeType := range []workflow.NodeType{workflow.NodeTypeStart, workflow.NodeTypeEnd} {
+		count := 0
+		for _, t := range nodeTypes {
+			if t == string(nodeType) {
+				count++
+			}
+		}
+		if count != 1 {
+			rowErrors = append(rowErrors, RowError{Field: "workflow", Message: fmt.Sprintf("workflow must have exactly one %s node", nodeType)})
+		}
+	}
+
+	questionIDs := make(map[uuid.UUID]bool)
+	for _, section := range document.Sections {
+		for _, q := range section.Questions {
+			questionIDs[q.ID] = true
+		}
+	}
+
+	row := 0
+	for _, section := range document.Sections {
+		if nodeTypes[section.ID.String()] != string(workflow.NodeTypeSection) {
+			rowErrors = append(rowErrors, RowError{Field: "sections", Message: fmt.Sprintf("section %s has no section node in the workflow", section.ID)})
+		}
+
+		for _, q := range section.Questions {
+			row++
+			_, err := question.NewAnswerable(q.toQuestion(section.ID), uuid.Nil)
+			if err != nil {
+				rowErrors = append(rowErrors, RowError{Row: row, Field: "metadata", Message: err.Error()})
+			}
+			if q.SourceID != nil && !questionIDs[*q.SourceID] {
+				rowErrors = append(rowErrors, RowError{Row: row, Field: "sourceId", Message: "source question is not part of the document"})
+			}
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, ValidationError{Rows: rowErrors}
+	}
+
+	return nodes, nil
+}
+
+func (q DocumentQuestion) toQuestion(sectionID uuid.UUID) question.Question {
+	converted := question.Question{
+		ID:          q.ID,
+		SectionID:   sectionID,
+		Required:    q.Required,
+		Type:        question.QuestionType(strings.ToLower(q.Type)),
+		Title:       pgtype.Text{String: q.Title, Valid: true},
+		Description: pgtype.Text{String: q.Description, Valid: true},
+		Metadata:    q.Metadata,
+		Order:       q.Order,
+	}
+	if q.SourceID != nil {
+		converted.SourceID = pgtype.UUID{Bytes: *q.SourceID, Valid: true}
+	}
+	return converted
+}
+
+// remapIDs replaces every string in the workflow that is an old ID, or a payload
+// source naming an old question, with its new ID
+func remapIDs(value any, ids map[string]string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = remapIDs(item, ids)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = remapIDs(item, ids)
+		}
+		return v
+	case string:
+		if id, ok := ids[v]; ok {
+			return id
+		}
+		if questionID, ok := strings.CutPrefix(v, node.PayloadSourceQuestionPrefix); ok {
+			if id, ok := ids[questionID]; ok {
+				return node.PayloadSourceQuestionPrefix + id
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
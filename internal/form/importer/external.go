@@ -0,0 +1,312 @@
+package importer
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalFormat names a form tool whose exported definitions can be imported
+type ExternalFormat string
+
+const (
+	// FormatGoogleForms is a form as returned by forms.get of the Google Forms API
+	FormatGoogleForms ExternalFormat = "google-forms"
+	// FormatTypeform is a form as returned by the Typeform Create API
+	FormatTypeform ExternalFormat = "typeform"
+)
+
+// The labels of the start and end nodes, as a new form gets them
+const (
+	startLabel = "開始表單"
+	endLabel   = "確認/送出"
+)
+
+// ConversionNote reports a feature of an external definition the import left out or
+// changed. Item is the title of the question or section it concerns, if any.
+type ConversionNote struct {
+	Item    string `json:"item,omitempty"`
+	Feature string `json:"feature"`
+	Message string `json:"message"`
+}
+
+// ConversionSummary tells what an import of an external definition created, and what
+// it could not carry over
+type ConversionSummary struct {
+	Format      ExternalFormat   `json:"format"`
+	Sections    int              `json:"sections"`
+	Questions   int              `json:"questions"`
+	Branches    int              `json:"branches"`
+	Unsupported []ConversionNote `json:"unsupported"`
+}
+
+// ConvertExternal converts an exported definition of the format into a Document, ready
+// for ImportDocument. Anything the form model has no equivalent for is skipped and
+// reported in the summary; only a definition that is not of the format, or that leaves
+// no question to import, is rejected.
+func ConvertExternal(format ExternalFormat, data []byte) (Document, ConversionSummary, error) {
+	switch format {
+	case FormatGoogleForms:
+		return convertGoogleForm(data)
+	case FormatTypeform:
+		return convertTypeform(data)
+	default:
+		return Document{}, ConversionSummary{}, fmt.Errorf("%w: format must be %s or %s", internal.ErrInvalidQueryParameter, FormatGoogleForms, FormatTypeform)
+	}
+}
+
+// jumpTarget is where a branch leads, a section by the key the source gave it or the end
+type jumpTarget struct {
+	section string
+	end     bool
+}
+
+// branch leads the respondent to target when the choice is selected
+type branch struct {
+	question DocumentQuestion
+	choice   question.Choice
+	target   jumpTarget
+}
+
+type convertedSection struct {
+	section  DocumentSection
+	branches []branch
+	// next replaces the following section as where the section leads by default
+	next *jumpTarget
+}
+
+// conversion collects the sections, questions and branches of an external definition
+// while it is read, then lays them out as a Document
+type conversion struct {
+	summary  ConversionSummary
+	sections []*convertedSection
+	keys     map[string]int
+}
+
+func newConversion(format ExternalFormat) *conversion {
+	return &conversion{
+		summary: ConversionSummary{Format: format, Unsupported: []ConversionNote{}},
+		keys:    make(map[string]int),
+	}
+}
+
+func (c *conversion) note(item string, feature string, message string) {
+	c.summary.Unsupported = append(c.summary.Unsupported, ConversionNote{Item: item, Feature: feature, Message: message})
+}
+
+// addSection starts a new section, which the following questions are added to. An
+// empty title is replaced like in a spreadsheet import.
+func (c *conversion) addSection(key string, title string, description string) *convertedSection {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = fmt.Sprintf("Section %d", len(c.sections)+1)
+	}
+
+	section := &convertedSection{
+		section: DocumentSection{
+			ID:          uuid.New(),
+			Title:       title,
+			Description: strings.TrimSpace(description),
+		},
+	}
+	if key != "" {
+		c.keys[key] = len(c.sections)
+	}
+	c.sections = append(c.sections, section)
+	return section
+}
+
+// alias lets branches target the section by another key, such as its first question
+func (c *conversion) alias(key string, section *convertedSection) {
+	for i, candidate := range c.sections {
+		if candidate == section {
+			c.keys[key] = i
+			return
+		}
+	}
+}
+
+// current returns the section questions are added to, starting one if there is none yet
+func (c *conversion) current() *convertedSection {
+	if len(c.sections) == 0 {
+		return c.addSection("", "", "")
+	}
+	return c.sections[len(c.sections)-1]
+}
+
+// appendText adds a block of text of the source to the description of the current section
+func (c *conversion) appendText(title string, description string) {
+	section := c.current()
+	text := strings.TrimSpace(strings.Join([]string{strings.TrimSpace(title), strings.TrimSpace(description)}, "\n"))
+	if text == "" {
+		return
+	}
+	if section.section.Description != "" {
+		text = section.section.Description + "\n\n" + text
+	}
+	section.section.Description = text
+}
+
+// addQuestion validates the request like the question API and adds the question to the
+// current section. A question the form model rejects is reported and skipped.
+func (c *conversion) addQuestion(request question.Request) (DocumentQuestion, bool) {
+	section := c.current()
+	request.Type = strings.ToLower(request.Type)
+	request.Title = strings.TrimSpace(request.Title)
+	if request.Title == "" {
+		request.Title = fmt.Sprintf("Question %d", c.summary.Questions+1)
+	}
+	if request.Required == nil {
+		required := false
+		request.Required = &required
+	}
+
+	metadata, err := question.GenerateMetadata(request)
+	if err != nil {
+		c.note(request.Title, "question", fmt.Sprintf("skipped, the %s question cannot be converted: %s", request.Type, err))
+		return DocumentQuestion{}, false
+	}
+
+	converted := DocumentQuestion{
+		ID:          uuid.New(),
+		Type:        request.Type,
+		Title:       request.Title,
+		Description: request.Description,
+		Required:    request.IsRequired(),
+		Order:       int32(len(section.section.Questions) + 1),
+		Metadata:    metadata,
+	}
+	section.section.Questions = append(section.section.Questions, converted)
+	c.summary.Questions++
+	return converted, true
+}
+
+// addBranch leads the respondent of the section to target when the choice with the given
+// index of the question is selected
+func (c *conversion) addBranch(section *convertedSection, q DocumentQuestion, choiceIndex int, target jumpTarget) {
+	choices, err := question.ExtractChoices(q.Metadata)
+	if err != nil || choiceIndex < 0 || choiceIndex >= len(choices) {
+		c.note(q.Title, "branching", "skipped a branch on a choice that was not imported")
+		return
+	}
+
+	section.branches = append(section.branches, branch{question: q, choice: choices[choiceIndex], target: target})
+}
+
+// resolve returns the ID of the node a target of the section at index leads to. Only
+// jumps forward are kept; the workflow of a form has no loops.
+func (c *conversion) resolve(index int, target jumpTarget, endID string, item string) (string, bool) {
+	if target.end {
+		return endID, true
+	}
+
+	to, ok := c.keys[target.section]
+	if !ok {
+		c.note(item, "branching", "skipped a jump to a section or question that was not imported")
+		return "", false
+	}
+	if to <= index {
+		c.note(item, "branching", fmt.Sprintf("skipped a jump back to %q, only jumps to a later section are supported", c.sections[to].section.Title))
+		return "", false
+	}
+	return c.sections[to].section.ID.String(), true
+}
+
+// document lays out the sections between the start and end nodes, in order, with a
+// chain of condition nodes after each section that branches
+func (c *conversion) document(title string, description string) (Document, ConversionSummary, error) {
+	if c.summary.Questions == 0 {
+		return Document{}, c.summary, ValidationError{Rows: []RowError{{Message: "definition has no questions that can be imported"}}}
+	}
+
+	startID, endID := uuid.NewString(), uuid.NewString()
+	nodes := []map[string]any{{"id": startID, "type": string(workflow.NodeTypeStart), "label": startLabel, "next": c.sections[0].section.ID.String()}}
+	sections := make([]DocumentSection, len(c.sections))
+
+	for i, section := range c.sections {
+		sections[i] = section.section
+		sectionID := section.section.ID.String()
+
+		defaultNext := endID
+		if i+1 < len(c.sections) {
+			defaultNext = c.sections[i+1].section.ID.String()
+		}
+		if section.next != nil {
+			if id, ok := c.resolve(i, *section.next, endID, section.section.Title); ok {
+				defaultNext = id
+			}
+		}
+
+		var conditions []map[string]any
+		for _, b := range section.branches {
+			target, ok := c.resolve(i, b.target, endID, b.question.Title)
+			if !ok {
+				continue
+			}
+			conditions = append(conditions, map[string]any{
+				"id":       uuid.NewString(),
+				"type":     string(workflow.NodeTypeCondition),
+				"label":    fmt.Sprintf("%s: %s", b.question.Title, b.choice.Name),
+				"nextTrue": target,
+				"conditionRule": node.ConditionRule{
+					Source:         node.ConditionSourceChoice,
+					NodeID:         sectionID,
+					Key:            b.question.ID.String(),
+					ChoiceOptionID: b.choice.ID.String(),
+					Pattern:        "^" + regexp.QuoteMeta(b.choice.ID.String()) + "$",
+				},
+			})
+		}
+
+		// Each condition falls through to the next one, the last to the default
+		next := defaultNext
+		for k := len(conditions) - 1; k >= 0; k-- {
+			conditions[k]["nextFalse"] = next
+			next = conditions[k]["id"].(string)
+		}
+		nodes = append(nodes, map[string]any{"id": sectionID, "type": string(workflow.NodeTypeSection), "label": section.section.Title, "next": next})
+		nodes = append(nodes, conditions...)
+		c.summary.Branches += len(conditions)
+	}
+
+	nodes = append(nodes, map[string]any{"id": endID, "type": string(workflow.NodeTypeEnd), "label": endLabel})
+	c.summary.Sections = len(sections)
+
+	graph, err := json.Marshal(nodes)
+	if err != nil {
+		return Document{}, c.summary, fmt.Errorf("failed to encode workflow: %w", err)
+	}
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = "Imported form"
+	}
+
+	return Document{
+		Version:    DocumentVersion,
+		ExportedAt: time.Now().UTC(),
+		Form:       DocumentForm{Title: title, Description: strings.TrimSpace(description)},
+		Sections:   sections,
+		Workflow:   graph,
+	}, c.summary, nil
+}
+
+// scaleRange fits a scale of the source into the 1 to 7 of a linear scale question
+func (c *conversion) scaleRange(item string, low int, high int) (int, int) {
+	minVal, maxVal := min(max(low, 1), 6), min(high, 7)
+	if maxVal <= minVal {
+		maxVal = minVal + 1
+	}
+	if minVal != low || maxVal != high {
+		c.note(item, "scale", fmt.Sprintf("scale from %d to %d converted to %d to %d", low, high, minVal, maxVal))
+	}
+	return minVal, maxVal
+}
@@ -0,0 +1,295 @@
+package importer
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// googleForm is the part of a Form resource of the Google Forms API the import reads
+type googleForm struct {
+	Info struct {
+		Title         string `json:"title"`
+		DocumentTitle string `json:"documentTitle"`
+		Description   string `json:"description"`
+	} `json:"info"`
+	Settings struct {
+		QuizSettings struct {
+			IsQuiz bool `json:"isQuiz"`
+		} `json:"quizSettings"`
+		EmailCollectionType string `json:"emailCollectionType"`
+	} `json:"settings"`
+	Items []googleItem `json:"items"`
+}
+
+type googleItem struct {
+	ItemID       string `json:"itemId"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	QuestionItem *struct {
+		Question googleQuestion  `json:"question"`
+		Image    json.RawMessage `json:"image"`
+	} `json:"questionItem"`
+	QuestionGroupItem json.RawMessage `json:"questionGroupItem"`
+	PageBreakItem     json.RawMessage `json:"pageBreakItem"`
+	TextItem          json.RawMessage `json:"textItem"`
+	ImageItem         json.RawMessage `json:"imageItem"`
+	VideoItem         json.RawMessage `json:"videoItem"`
+}
+
+type googleQuestion struct {
+	Required       bool `json:"required"`
+	ChoiceQuestion *struct {
+		Type    string         `json:"type"`
+		Options []googleOption `json:"options"`
+		Shuffle bool           `json:"shuffle"`
+	} `json:"choiceQuestion"`
+	TextQuestion *struct {
+		Paragraph bool `json:"paragraph"`
+	} `json:"textQuestion"`
+	ScaleQuestion *struct {
+		Low       int    `json:"low"`
+		High      int    `json:"high"`
+		LowLabel  string `json:"lowLabel"`
+		HighLabel string `json:"highLabel"`
+	} `json:"scaleQuestion"`
+	DateQuestion *struct {
+		IncludeTime bool `json:"includeTime"`
+	} `json:"dateQuestion"`
+	TimeQuestion       json.RawMessage `json:"timeQuestion"`
+	FileUploadQuestion *struct {
+		Types       []string `json:"types"`
+		MaxFiles    int32    `json:"maxFiles"`
+		MaxFileSize string   `json:"maxFileSize"`
+	} `json:"fileUploadQuestion"`
+	RatingQuestion *struct {
+		RatingScaleLevel int    `json:"ratingScaleLevel"`
+		IconType         string `json:"iconType"`
+	} `json:"ratingQuestion"`
+}
+
+type googleOption struct {
+	Value         string          `json:"value"`
+	IsOther       bool            `json:"isOther"`
+	GoToAction    string          `json:"goToAction"`
+	GoToSectionID string          `json:"goToSectionId"`
+	Image         json.RawMessage `json:"image"`
+}
+
+// googleFileCategories are the categories of files a Google Forms upload can accept
+var googleFileCategories = []string{"DOCUMENT", "PRESENTATION", "SPREADSHEET", "DRAWING", "PDF", "IMAGE", "VIDEO", "AUDIO"}
+
+// googleFileTypes are the file types accepted for each category
+var googleFileTypes = map[string][]string{
+	"DOCUMENT":     {"doc", "docx", "odt", "rtf", "txt", "md"},
+	"PRESENTATION": {"ppt", "pptx", "odp"},
+	"SPREADSHEET":  {"xls", "xlsx", "ods", "csv"},
+	"DRAWING":      {"svg", "ai", "eps"},
+	"PDF":          {"pdf"},
+	"IMAGE":        {"jpg", "jpeg", "png", "webp", "gif", "tiff", "bmp", "heic"},
+	"VIDEO":        {"mp4", "webm", "mov", "mkv", "avi"},
+	"AUDIO":        {"mp3", "wav", "m4a", "aac", "ogg", "flac"},
+}
+
+var googleRatingIcons = map[string]string{
+	"STAR":     "star",
+	"HEART":    "heart",
+	"THUMB_UP": "thumbs-up",
+}
+
+// convertGoogleForm converts a form of the Google Forms API. Page breaks start sections,
+// and the choices of a radio or drop-down question that go to a section become branches.
+func convertGoogleForm(data []byte) (Document, ConversionSummary, error) {
+	var source googleForm
+	err := json.Unmarshal(data, &source)
+	if err != nil || (source.Info.Title == "" && source.Info.DocumentTitle == "" && source.Items == nil) {
+		return Document{}, ConversionSummary{}, ValidationError{Rows: []RowError{{Message: "body is not a form of the Google Forms API"}}}
+	}
+
+	c := newConversion(FormatGoogleForms)
+	if source.Settings.QuizSettings.IsQuiz {
+		c.note("", "quiz", "answer keys, points and feedback are not imported")
+	}
+	if source.Settings.EmailCollectionType != "" && source.Settings.EmailCollectionType != "DO_NOT_COLLECT" {
+		c.note("", "email collection", "respondent emails are not collected by the imported form")
+	}
+
+	for _, item := range source.Items {
+		switch {
+		case item.PageBreakItem != nil:
+			c.addSection(item.ItemID, item.Title, item.Description)
+		case item.QuestionItem != nil:
+			if item.QuestionItem.Image != nil {
+				c.note(item.Title, "image", "the image of the question is not imported")
+			}
+			c.addGoogleQuestion(item, item.QuestionItem.Question)
+		case item.TextItem != nil:
+			c.appendText(item.Title, item.Description)
+		case item.QuestionGroupItem != nil:
+			c.note(item.Title, "grid", "skipped, grid questions are not supported")
+		case item.ImageItem != nil, item.VideoItem != nil:
+			c.note(item.Title, "media", "skipped, images and videos between questions are not supported")
+		default:
+			c.note(item.Title, "item", "skipped an item of an unknown kind")
+		}
+	}
+
+	title := source.Info.Title
+	if title == "" {
+		title = source.Info.DocumentTitle
+	}
+	return c.document(title, source.Info.Description)
+}
+
+func (c *conversion) addGoogleQuestion(item googleItem, source googleQuestion) {
+	required := source.Required
+	request := question.Request{
+		Required:    &required,
+		Title:       item.Title,
+		Description: item.Description,
+	}
+
+	switch {
+	case source.ChoiceQuestion != nil:
+		c.addGoogleChoiceQuestion(item, request, source)
+		return
+	case source.TextQuestion != nil:
+		request.Type = string(question.QuestionTypeShortText)
+		if source.TextQuestion.Paragraph {
+			request.Type = string(question.QuestionTypeLongText)
+		}
+	case source.ScaleQuestion != nil:
+		request.Type = string(question.QuestionTypeLinearScale)
+		request.Scale.MinVal, request.Scale.MaxVal = c.scaleRange(item.Title, source.ScaleQuestion.Low, source.ScaleQuestion.High)
+		request.Scale.MinValueLabel = source.ScaleQuestion.LowLabel
+		request.Scale.MaxValueLabel = source.ScaleQuestion.HighLabel
+	case source.RatingQuestion != nil:
+		request.Type = string(question.QuestionTypeRating)
+		request.Scale.MinVal, request.Scale.MaxVal = 1, source.RatingQuestion.RatingScaleLevel
+		request.Scale.Icon = googleRatingIcons[source.RatingQuestion.IconType]
+		if request.Scale.Icon == "" {
+			request.Scale.Icon = "star"
+		}
+	case source.DateQuestion != nil:
+		request.Type = string(question.QuestionTypeDate)
+		if source.DateQuestion.IncludeTime {
+			c.note(item.Title, "date", "the time of day is not asked, only the date")
+		}
+	case source.TimeQuestion != nil:
+		request.Type = string(question.QuestionTypeShortText)
+		c.note(item.Title, "time", "converted to a short text question")
+	case source.FileUploadQuestion != nil:
+		request.Type = string(question.QuestionTypeUploadFile)
+		request.UploadFile = c.uploadOption(item.Title, source.FileUploadQuestion.Types, source.FileUploadQuestion.MaxFiles, source.FileUploadQuestion.MaxFileSize)
+	default:
+		c.note(item.Title, "question", "skipped a question of an unsupported kind")
+		return
+	}
+
+	c.addQuestion(request)
+}
+
+func (c *conversion) addGoogleChoiceQuestion(item googleItem, request question.Request, source googleQuestion) {
+	choice := source.ChoiceQuestion
+
+	type jump struct {
+		index  int
+		target jumpTarget
+	}
+	var jumps []jump
+	for _, option := range choice.Options {
+		if option.IsOther {
+			c.note(item.Title, "other option", "the \"Other\" option with its own answer is not imported")
+			continue
+		}
+		if option.Image != nil {
+			c.note(item.Title, "image", fmt.Sprintf("the image of choice %q is not imported", option.Value))
+		}
+
+		index := len(request.Choices)
+		request.Choices = append(request.Choices, question.ChoiceOption{Name: option.Value})
+		switch {
+		case option.GoToSectionID != "":
+			jumps = append(jumps, jump{index: index, target: jumpTarget{section: option.GoToSectionID}})
+		case option.GoToAction == "SUBMIT_FORM":
+			jumps = append(jumps, jump{index: index, target: jumpTarget{end: true}})
+		case option.GoToAction == "RESTART_FORM":
+			c.note(item.Title, "branching", fmt.Sprintf("choice %q restarts the form, which is not supported", option.Value))
+		}
+	}
+	if choice.Shuffle {
+		c.note(item.Title, "shuffle", "choices are not shuffled for this question alone, use the shuffle choices setting of the form")
+	}
+
+	switch choice.Type {
+	case "CHECKBOX":
+		request.Type = string(question.QuestionTypeMultipleChoice)
+	case "DROP_DOWN":
+		request.Type = string(question.QuestionTypeDropdown)
+		// Conditions only read single and multiple choice questions
+		if len(jumps) > 0 {
+			request.Type = string(question.QuestionTypeSingleChoice)
+			c.note(item.Title, "dropdown", "converted to a single choice question so it can branch")
+		}
+	default:
+		request.Type = string(question.QuestionTypeSingleChoice)
+	}
+
+	converted, ok := c.addQuestion(request)
+	if !ok {
+		return
+	}
+	section := c.current()
+	if len(jumps) > 0 && len(section.branches) > 0 {
+		c.note(item.Title, "branching", "skipped, only the first question of a section that goes to another section branches")
+		return
+	}
+	for _, j := range jumps {
+		c.addBranch(section, converted, j.index, j.target)
+	}
+}
+
+// uploadOption converts the upload settings of Google Forms, rounding the size limit up to one offered
+func (c *conversion) uploadOption(item string, categories []string, maxFiles int32, maxFileSize string) question.UploadFileOption {
+	option := question.UploadFileOption{MaxFileAmount: min(max(maxFiles, 1), 10)}
+
+	if len(categories) == 0 || slices.Contains(categories, "ANY") {
+		categories = googleFileCategories
+		option.AllowedFileTypes = append(option.AllowedFileTypes, "zip")
+	}
+	for _, category := range categories {
+		option.AllowedFileTypes = append(option.AllowedFileTypes, googleFileTypes[category]...)
+	}
+
+	// Google Forms limits each file to 10MB unless told otherwise
+	size, err := strconv.ParseInt(maxFileSize, 10, 64)
+	if err != nil || size <= 0 {
+		size = 10 << 20
+	}
+	option.MaxFileSizeLimit = uploadSizeLimit(size)
+	if size > 1<<30 {
+		c.note(item, "file size", "the size limit is lowered to 1GB")
+	}
+
+	return option
+}
+
+// uploadSizeLimit returns the smallest size limit of an upload question holding size bytes
+func uploadSizeLimit(size int64) string {
+	for _, limit := range []struct {
+		bytes int64
+		name  question.FileSizeLimit
+	}{
+		{1 << 20, question.FileSizeLimit1MB},
+		{5 << 20, question.FileSizeLimit5MB},
+		{10 << 20, question.FileSizeLimit10MB},
+		{100 << 20, question.FileSizeLimit100MB},
+	} {
+		if size <= limit.bytes {
+			return string(limit.name)
+		}
+	}
+	return string(question.FileSizeLimit1GB)
+}
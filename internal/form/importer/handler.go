@@ -0,0 +1,281 @@
+package importer
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Import(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID, definition Definition) (Result, error)
+	ImportDocument(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID, document Document) (Result, error)
+	ImportExternal(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID, format ExternalFormat, data []byte) (Result, error)
+	Export(ctx context.Context, formID uuid.UUID) (Document, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+type SectionResponse struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	QuestionCount int    `json:"questionCount"`
+}
+
+type Response struct {
+	form.Response
+	Sections   []SectionResponse  `json:"sections"`
+	Conversion *ConversionSummary `json:"conversion,omitempty"`
+}
+
+// ValidationProblem is the body of a rejected import, listing every invalid row
+type ValidationProblem struct {
+	problem.Problem
+	Errors []RowError `json:"errors"`
+}
+
+func ToResponse(result Result) Response {
+	newForm := result.Form
+	response := Response{
+		Response: form.ToResponse(form.Form{
+			ID:                 newForm.ID,
+			Title:              newForm.Title,
+			Description:        newForm.Description,
+			PreviewMessage:     newForm.PreviewMessage,
+			Status:             newForm.Status,
+			UnitID:             newForm.UnitID,
+			LastEditor:         newForm.LastEditor,
+			Deadline:           newForm.Deadline,
+			RandomizeQuestions: newForm.RandomizeQuestions,
+			ShuffleChoices:     newForm.ShuffleChoices,
+			TimeLimitSeconds:   newForm.TimeLimitSeconds,
+			CreatedAt:          newForm.CreatedAt,
+			UpdatedAt:          newForm.UpdatedAt,
+		},
+			newForm.UnitName.String,
+			newForm.OrgName.String,
+			user.User{
+				ID:        newForm.LastEditor,
+				Name:      newForm.LastEditorName,
+				Username:  newForm.LastEditorUsername,
+				AvatarUrl: newForm.LastEditorAvatarUrl,
+			},
+			user.ConvertEmailsToSlice(newForm.LastEditorEmail)),
+		Sections:   make([]SectionResponse, len(result.Sections)),
+		Conversion: result.Conversion,
+	}
+
+	for i, section := range result.Sections {
+		response.Sections[i] = SectionResponse{
+			ID:            section.ID.String(),
+			Title:         section.Title,
+			QuestionCount: section.QuestionCount,
+		}
+	}
+
+	return response
+}
+
+type Handler struct {
+	logger    *zap.Logger
+	validator *validator.Validate
+	tracer    trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		validator:     validator,
+		tracer:        otel.Tracer("importer/handler"),
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// parseCSVDefinition reads a text/csv body; the form itself is described by the
+// query, ?title=...&description=...&previewMessage=...&deadline=<RFC 3339>
+func parseCSVDefinition(r *http.Request) (Definition, error) {
+	query := r.URL.Query()
+	definition := Definition{
+		Title:          strings.TrimSpace(query.Get("title")),
+		Description:    query.Get("description"),
+		PreviewMessage: query.Get("previewMessage"),
+	}
+	if definition.Title == "" {
+		return Definition{}, fmt.Errorf("%w: title is required", internal.ErrInvalidQueryParameter)
+	}
+	if deadlineParam := query.Get("deadline"); deadlineParam != "" {
+		deadline, err := time.Parse(time.RFC3339, deadlineParam)
+		if err != nil {
+			return Definition{}, fmt.Errorf("%w: deadline must be an RFC 3339 time", internal.ErrInvalidQueryParameter)
+		}
+		definition.Deadline = &deadline
+	}
+
+	rows, rowErrors := ParseCSV(r.Body)
+	if len(rowErrors) > 0 {
+		return Definition{}, ValidationError{Rows: rowErrors}
+	}
+	definition.Questions = rows
+
+	return definition, nil
+}
+
+// isDocument reports whether the JSON body is an exported Document, which unlike a
+// Definition carries a version. The body is buffered so it can still be decoded; if
+// reading fails, the body is left as is and decoding reports the error.
+func isDocument(r *http.Request) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var probe struct {
+		Version *int `json:"version"`
+	}
+	_ = json.Unmarshal(body, &probe)
+	return probe.Version != nil
+}
+
+func (h *Handler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ExportHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	document, err := h.store.Export(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "form-"+formID.String()+".json"))
+	handlerutil.WriteJSONResponse(w, http.StatusOK, document)
+}
+
+func (h *Handler) ImportHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ImportHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	slug, err := internal.GetSlugFromContext(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org slug from context: %w", err), logger)
+		return
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(traceCtx, slug)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org ID by slug: %w", err), logger)
+		return
+	}
+
+	// ?format=google-forms or ?format=typeform imports a definition exported by that tool
+	format := ExternalFormat(r.URL.Query().Get("format"))
+
+	var result Result
+	switch {
+	case format != "":
+		var data []byte
+		data, err = io.ReadAll(r.Body)
+		if err != nil {
+			h.writeError(traceCtx, w, fmt.Errorf("failed to read request body: %w", err), logger)
+			return
+		}
+		result, err = h.store.ImportExternal(traceCtx, orgID, unitID, currentUser.ID, format, data)
+	case strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv"):
+		var definition Definition
+		definition, err = parseCSVDefinition(r)
+		if err != nil {
+			h.writeError(traceCtx, w, err, logger)
+			return
+		}
+		result, err = h.store.Import(traceCtx, orgID, unitID, currentUser.ID, definition)
+	case isDocument(r):
+		var document Document
+		err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &document)
+		if err != nil {
+			h.writeError(traceCtx, w, err, logger)
+			return
+		}
+		result, err = h.store.ImportDocument(traceCtx, orgID, unitID, currentUser.ID, document)
+	default:
+		var definition Definition
+		err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &definition)
+		if err != nil {
+			h.writeError(traceCtx, w, err, logger)
+			return
+		}
+		result, err = h.store.Import(traceCtx, orgID, unitID, currentUser.ID, definition)
+	}
+	if err != nil {
+		h.writeError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(result))
+}
+
+// writeError answers a ValidationError with the invalid rows and anything else
+// through the problem writer
+func (h *Handler) writeError(ctx context.Context, w http.ResponseWriter, err error, logger *zap.Logger) {
+	var validationError ValidationError
+	if errors.As(err, &validationError) {
+		handlerutil.WriteJSONResponse(w, http.StatusBadRequest, ValidationProblem{
+			Problem: problem.NewValidateProblem(err.Error()),
+			Errors:  validationError.Rows,
+		})
+		return
+	}
+
+	h.problemWriter.WriteError(ctx, w, err, logger)
+}
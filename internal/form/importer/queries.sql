@@ -0,0 +1,13 @@
+-- name: IsUnitInOrg :one
+SELECT EXISTS (
+    SELECT 1
+    FROM units
+    WHERE id = @unit_id AND (id = @org_id OR org_id = @org_id)
+);
+
+-- name: UpdateSection :exec
+UPDATE sections
+SET title = @title,
+    description = @description,
+    updated_at = now()
+WHERE id = @id;
\ No newline at end of file
@@ -0,0 +1,11 @@
+package importer
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the export of a form definition and its import into a unit
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/export", route.Authenticated, route.PermissionNone, h.ExportHandler)
+	r.Handle("POST /orgs/{slug}/units/{id}/forms/import", route.TenantAuthenticated, route.PermissionNone, h.ImportHandler)
+}
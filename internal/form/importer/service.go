@@ -0,0 +1,595 @@
+package importer
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	IsUnitInOrg(ctx context.Context, arg IsUnitInOrgParams) (bool, error)
+	UpdateSection(ctx context.Context, arg UpdateSectionParams) error
+}
+
+type FormStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (form.GetByIDRow, error)
+	Create(ctx context.Context, request form.Request, unitID uuid.UUID, userID uuid.UUID) (form.CreateRow, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type WorkflowStore interface {
+	Get(ctx context.Context, formID uuid.UUID) (workflow.GetRow, error)
+	CreateNode(ctx context.Context, formID uuid.UUID, nodeType workflow.NodeType, userID uuid.UUID) (workflow.CreateNodeRow, error)
+	Update(ctx context.Context, formID uuid.UUID, workflow []byte, userID uuid.UUID) (workflow.UpdateRow, []workflow.ValidationInfo, error)
+}
+
+type QuestionStore interface {
+	Create(ctx context.Context, input question.CreateParams) (question.Answerable, error)
+	ListByFormID(ctx context.Context, formID uuid.UUID) ([]question.SectionWithQuestions, error)
+}
+
+// ImportedSection is a section created by an import
+type ImportedSection struct {
+	ID            uuid.UUID
+	Title         string
+	QuestionCount int
+}
+
+// Result is the form created by an import. Conversion is set for an import of a
+// definition exported from another form tool.
+type Result struct {
+	Form       form.CreateRow
+	Sections   []ImportedSection
+	Conversion *ConversionSummary
+}
+
+// plannedSection holds the validated questions of one section before anything is written
+type plannedSection struct {
+	title     string
+	questions []question.CreateParams
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+
+	formStore     FormStore
+	workflowStore WorkflowStore
+	questionStore QuestionStore
+}
+
+func NewService(logger *zap.Logger, db DBTX, formStore FormStore, workflowStore WorkflowStore, questionStore QuestionStore) *Service {
+	return &Service{
+		logger:        logger,
+		queries:       New(db),
+		tracer:        otel.Tracer("importer/service"),
+		formStore:     formStore,
+		workflowStore: workflowStore,
+		questionStore: questionStore,
+	}
+}
+
+// plan validates every row of the definition and groups the questions into sections
+func plan(definition Definition) ([]plannedSection, error) {
+	if len(definition.Questions) == 0 {
+		return nil, ValidationError{Rows: []RowError{{Message: "definition has no questions"}}}
+	}
+	if len(definition.Questions) > MaxRows {
+		return nil, ValidationError{Rows: []RowError{{Message: fmt.Sprintf("definition has %d questions, at most %d are allowed", len(definition.Questions), MaxRows)}}}
+	}
+
+	var sections []plannedSection
+	sectionIndex := make(map[string]int)
+	var rowErrors []RowError
+
+	for i, row := range definition.Questions {
+		number := i + 1
+		if row.Line > 0 {
+			number = row.Line
+		}
+
+		name := strings.TrimSpace(row.Section)
+		index, ok := sectionIndex[name]
+		if !ok {
+			index = len(sections)
+			sectionIndex[name] = index
+			title := name
+			if title == "" {
+				title = fmt.Sprintf("Section %d", index+1)
+			}
+			sections = append(sections, plannedSection{title: title})
+		}
+
+		request := row.ToQuestionRequest(int32(len(sections[index].questions) + 1))
+		if request.Title == "" {
+			rowErrors = append(rowErrors, RowError{Row: number, Field: "title", Message: "title is required"})
+			continue
+		}
+		if request.Type == "" {
+			rowErrors = append(rowErrors, RowError{Row: number, Field: "type", Message: "type is required"})
+			continue
+		}
+
+		metadata, err := question.GenerateMetadata(request)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: number, Field: "type", Message: err.Error()})
+			continue
+		}
+
+		sections[index].questions = append(sections[index].questions, question.CreateParams{
+			Required:    request.IsRequired(),
+			Type:        question.QuestionType(request.Type),
+			Title:       pgtype.Text{String: request.Title, Valid: true},
+			Description: pgtype.Text{String: request.Description, Valid: true},
+			Order:       request.Order,
+			Metadata:    metadata,
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		return nil, ValidationError{Rows: rowErrors}
+	}
+
+	return sections, nil
+}
+
+// Import creates a draft form in the unit from the definition. Every row is validated
+// before anything is written, and a form that fails halfway is deleted again.
+func (s *Service) Import(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID, definition Definition) (Result, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Import")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkUnit(traceCtx, logger, orgID, unitID)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	sections, err := plan(definition)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	newForm, err := s.formStore.Create(traceCtx, form.Request{
+		Title:          definition.Title,
+		Description:    definition.Description,
+		PreviewMessage: definition.PreviewMessage,
+		Deadline:       definition.Deadline,
+	}, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	result, err := s.populate(traceCtx, logger, newForm, userID, sections)
+	if err != nil {
+		span.RecordError(err)
+		deleteErr := s.formStore.Delete(traceCtx, newForm.ID)
+		if deleteErr != nil {
+			logger.Error("Failed to delete partially imported form", zap.String("form_id", newForm.ID.String()), zap.Error(deleteErr))
+		}
+		return Result{}, err
+	}
+
+	logger.Info("Imported form",
+		zap.String("form_id", newForm.ID.String()),
+		zap.String("unit_id", unitID.String()),
+		zap.Int("sections", len(result.Sections)),
+		zap.Int("questions", len(definition.Questions)))
+
+	return result, nil
+}
+
+func (s *Service) checkUnit(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, unitID uuid.UUID) error {
+	inOrg, err := s.queries.IsUnitInOrg(ctx, IsUnitInOrgParams{UnitID: unitID, OrgID: orgID})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "units", "id", unitID.String(), logger, "check unit organization")
+	}
+	if !inOrg {
+		return internal.ErrUnitNotFound
+	}
+	return nil
+}
+
+// populate adds the sections to the workflow of the new form, chained between its start
+// and end nodes, and creates their questions
+func (s *Service) populate(ctx context.Context, logger *zap.Logger, newForm form.CreateRow, userID uuid.UUID, sections []plannedSection) (Result, error) {
+	result := Result{Form: newForm, Sections: make([]ImportedSection, len(sections))}
+
+	var graph []byte
+	for i, section := range sections {
+		node, err := s.workflowStore.CreateNode(ctx, newForm.ID, workflow.NodeTypeSection, userID)
+		if err != nil {
+			return Result{}, err
+		}
+		graph = node.Workflow
+
+		err = s.queries.UpdateSection(ctx, UpdateSectionParams{
+			ID:    node.NodeID,
+			Title: pgtype.Text{String: section.title, Valid: true},
+		})
+		if err != nil {
+			return Result{}, databaseutil.WrapDBErrorWithKeyValue(err, "sections", "id", node.NodeID.String(), logger, "update imported section")
+		}
+
+		result.Sections[i] = ImportedSection{ID: node.NodeID, Title: section.title, QuestionCount: len(section.questions)}
+	}
+
+	graph, err := chainSections(graph, result.Sections)
+	if err != nil {
+		return Result{}, err
+	}
+	_, _, err = s.workflowStore.Update(ctx, newForm.ID, graph, userID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	for i, section := range sections {
+		for _, params := range section.questions {
+			params.SectionID = result.Sections[i].ID
+			_, err := s.questionStore.Create(ctx, params)
+			if err != nil {
+				return Result{}, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// chainSections links start -> sections in order -> end and labels the section nodes
+// with their titles
+func chainSections(graph []byte, sections []ImportedSection) ([]byte, error) {
+	var nodes []map[string]any
+	err := json.Unmarshal(graph, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode workflow: %w", err)
+	}
+
+	titles := make(map[string]string, len(sections))
+	for _, section := range sections {
+		titles[section.ID.String()] = section.Title
+	}
+
+	var start, end map[string]any
+	for _, node := range nodes {
+		switch node["type"] {
+		case string(workflow.NodeTypeStart):
+			start = node
+		case string(workflow.NodeTypeEnd):
+			end = node
+		}
+	}
+	if start == nil || end == nil {
+		return nil, fmt.Errorf("workflow of the new form has no start or end node")
+	}
+
+	previous := start
+	for _, section := range sections {
+		id := section.ID.String()
+		for _, node := range nodes {
+			if node["id"] == id {
+				node["label"] = titles[id]
+				previous["next"] = id
+				previous = node
+				break
+			}
+		}
+	}
+	previous["next"] = end["id"]
+
+	return json.Marshal(nodes)
+}
+
+// Export returns the settings, questions and workflow of a form as a Document, with the
+// sections in the order their nodes appear in the workflow
+func (s *Service) Export(ctx context.Context, formID uuid.UUID) (Document, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Export")
+	defer span.End()
+
+	currentForm, err := s.formStore.GetByID(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Document{}, err
+	}
+
+	currentWorkflow, err := s.workflowStore.Get(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Document{}, err
+	}
+
+	sections, err := s.questionStore.ListByFormID(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Document{}, err
+	}
+
+	var nodes []struct {
+		ID string `json:"id"`
+	}
+	err = json.Unmarshal(currentWorkflow.Workflow, &nodes)
+	if err != nil {
+		err = fmt.Errorf("failed to decode workflow: %w", err)
+		span.RecordError(err)
+		return Document{}, err
+	}
+	position := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		position[node.ID] = i
+	}
+	slices.SortStableFunc(sections, func(a, b question.SectionWithQuestions) int {
+		return cmp.Compare(position[a.Section.ID.String()], position[b.Section.ID.String()])
+	})
+
+	document := Document{
+		Version:    DocumentVersion,
+		ExportedAt: time.Now().UTC(),
+		Form: DocumentForm{
+			Title:              currentForm.Title,
+			Description:        currentForm.Description.String,
+			PreviewMessage:     currentForm.PreviewMessage.String,
+			RandomizeQuestions: currentForm.RandomizeQuestions,
+			ShuffleChoices:     currentForm.ShuffleChoices,
+		},
+		Sections: make([]DocumentSection, len(sections)),
+		Workflow: currentWorkflow.Workflow,
+	}
+	if currentForm.TimeLimitSeconds.Valid {
+		document.Form.TimeLimitSeconds = &currentForm.TimeLimitSeconds.Int32
+	}
+	if currentForm.Deadline.Valid {
+		document.Form.Deadline = &currentForm.Deadline.Time
+	}
+	for i, section := range sections {
+		document.Sections[i] = toDocumentSection(section)
+	}
+
+	return document, nil
+}
+
+// ImportDocument creates a draft form in the unit from an exported Document. Sections,
+// questions and workflow nodes get new IDs, and the workflow is rewritten to use them.
+func (s *Service) ImportDocument(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID, document Document) (Result, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ImportDocument")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkUnit(traceCtx, logger, orgID, unitID)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	nodes, err := validateDocument(document)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	newForm, err := s.formStore.Create(traceCtx, form.Request{
+		Title:              document.Form.Title,
+		Description:        document.Form.Description,
+		PreviewMessage:     document.Form.PreviewMessage,
+		Deadline:           document.Form.Deadline,
+		RandomizeQuestions: document.Form.RandomizeQuestions,
+		ShuffleChoices:     document.Form.ShuffleChoices,
+		TimeLimitSeconds:   document.Form.TimeLimitSeconds,
+	}, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	result, err := s.populateDocument(traceCtx, logger, newForm, userID, document, nodes)
+	if err != nil {
+		span.RecordError(err)
+		deleteErr := s.formStore.Delete(traceCtx, newForm.ID)
+		if deleteErr != nil {
+			logger.Error("Failed to delete partially imported form", zap.String("form_id", newForm.ID.String()), zap.Error(deleteErr))
+		}
+		return Result{}, err
+	}
+
+	logger.Info("Imported form document",
+		zap.String("form_id", newForm.ID.String()),
+		zap.String("unit_id", unitID.String()),
+		zap.Int("version", document.Version),
+		zap.Int("sections", len(result.Sections)))
+
+	return result, nil
+}
+
+// ImportExternal creates a draft form in the unit from a definition exported by another
+// form tool, converted into a Document first. The result reports what the conversion
+// left out.
+func (s *Service) ImportExternal(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID, format ExternalFormat, data []byte) (Result, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ImportExternal")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	document, summary, err := ConvertExternal(format, data)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+
+	result, err := s.ImportDocument(traceCtx, orgID, unitID, userID, document)
+	if err != nil {
+		span.RecordError(err)
+		return Result{}, err
+	}
+	result.Conversion = &summary
+
+	logger.Info("Imported external form",
+		zap.String("form_id", result.Form.ID.String()),
+		zap.String("format", string(format)),
+		zap.Int("questions", summary.Questions),
+		zap.Int("branches", summary.Branches),
+		zap.Int("unsupported", len(summary.Unsupported)))
+
+	return result, nil
+}
+
+// populateDocument recreates the workflow nodes and questions of the document in the new
+// form. The start and end nodes map onto those the form was created with.
+func (s *Service) populateDocument(ctx context.Context, logger *zap.Logger, newForm form.CreateRow, userID uuid.UUID, document Document, nodes []map[string]any) (Result, error) {
+	result := Result{Form: newForm}
+
+	initial, err := s.workflowStore.Get(ctx, newForm.ID)
+	if err != nil {
+		return Result{}, err
+	}
+	var initialNodes []map[string]any
+	err = json.Unmarshal(initial.Workflow, &initialNodes)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to decode workflow: %w", err)
+	}
+	initialIDs := make(map[string]string, len(initialNodes))
+	for _, node := range initialNodes {
+		nodeType, _ := node["type"].(string)
+		initialIDs[nodeType], _ = node["id"].(string)
+	}
+
+	sections := make(map[string]DocumentSection, len(document.Sections))
+	for _, section := range document.Sections {
+		sections[section.ID.String()] = section
+	}
+
+	ids := make(map[string]string)
+	for _, node := range nodes {
+		oldID := node["id"].(string)
+		nodeType := node["type"].(string)
+
+		switch workflow.NodeType(nodeType) {
+		case workflow.NodeTypeStart, workflow.NodeTypeEnd:
+			ids[oldID] = initialIDs[nodeType]
+			continue
+		}
+
+		created, err := s.workflowStore.CreateNode(ctx, newForm.ID, workflow.NodeType(nodeType), userID)
+		if err != nil {
+			return Result{}, err
+		}
+		ids[oldID] = created.NodeID.String()
+
+		if workflow.NodeType(nodeType) != workflow.NodeTypeSection {
+			continue
+		}
+
+		section, ok := sections[oldID]
+		if !ok {
+			section.Title, _ = node["label"].(string)
+		}
+		err = s.queries.UpdateSection(ctx, UpdateSectionParams{
+			ID:          created.NodeID,
+			Title:       pgtype.Text{String: section.Title, Valid: true},
+			Description: pgtype.Text{String: section.Description, Valid: section.Description != ""},
+		})
+		if err != nil {
+			return Result{}, databaseutil.WrapDBErrorWithKeyValue(err, "sections", "id", created.NodeID.String(), logger, "update imported section")
+		}
+		result.Sections = append(result.Sections, ImportedSection{ID: created.NodeID, Title: section.Title, QuestionCount: len(section.Questions)})
+	}
+
+	err = s.createDocumentQuestions(ctx, document, ids)
+	if err != nil {
+		return Result{}, err
+	}
+
+	graph, err := json.Marshal(remapIDs(anySlice(nodes), ids))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to encode workflow: %w", err)
+	}
+	_, _, err = s.workflowStore.Update(ctx, newForm.ID, graph, userID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
+
+// createDocumentQuestions creates the questions of the document in their new sections and
+// records their new IDs. A question whose choices come from another question is created
+// once its source has been.
+func (s *Service) createDocumentQuestions(ctx context.Context, document Document, ids map[string]string) error {
+	type pendingQuestion struct {
+		sectionID uuid.UUID
+		question  DocumentQuestion
+	}
+
+	var pending []pendingQuestion
+	for _, section := range document.Sections {
+		sectionID, err := uuid.Parse(ids[section.ID.String()])
+		if err != nil {
+			return fmt.Errorf("section %s was not created: %w", section.ID, err)
+		}
+		for _, q := range section.Questions {
+			pending = append(pending, pendingQuestion{sectionID: sectionID, question: q})
+		}
+	}
+
+	for len(pending) > 0 {
+		var waiting []pendingQuestion
+		for _, p := range pending {
+			converted := p.question.toQuestion(p.sectionID)
+			if p.question.SourceID != nil {
+				sourceID, ok := ids[p.question.SourceID.String()]
+				if !ok {
+					waiting = append(waiting, p)
+					continue
+				}
+				converted.SourceID = pgtype.UUID{Bytes: uuid.MustParse(sourceID), Valid: true}
+			}
+
+			created, err := s.questionStore.Create(ctx, question.CreateParams{
+				SectionID:   converted.SectionID,
+				Required:    converted.Required,
+				Type:        converted.Type,
+				Title:       converted.Title,
+				Description: converted.Description,
+				Order:       converted.Order,
+				Metadata:    converted.Metadata,
+				SourceID:    converted.SourceID,
+			})
+			if err != nil {
+				return err
+			}
+			ids[p.question.ID.String()] = created.Question().ID.String()
+		}
+
+		if len(waiting) == len(pending) {
+			return fmt.Errorf("%w: the source questions of %d questions form a cycle", internal.ErrInvalidRequestBody, len(waiting))
+		}
+		pending = waiting
+	}
+
+	return nil
+}
+
+func anySlice(nodes []map[string]any) []any {
+	items := make([]any, len(nodes))
+	for i, node := range nodes {
+		items[i] = node
+	}
+	return items
+}
@@ -0,0 +1,340 @@
+package importer
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"encoding/json"
+	"fmt"
+)
+
+// typeform is the part of a form of the Typeform Create API the import reads
+type typeform struct {
+	Title          string `json:"title"`
+	WelcomeScreens []struct {
+		Properties struct {
+			Description string `json:"description"`
+		} `json:"properties"`
+	} `json:"welcome_screens"`
+	Fields    []typeformField `json:"fields"`
+	Logic     []typeformLogic `json:"logic"`
+	Hidden    []string        `json:"hidden"`
+	Variables json.RawMessage `json:"variables"`
+}
+
+type typeformField struct {
+	Ref        string `json:"ref"`
+	Title      string `json:"title"`
+	Type       string `json:"type"`
+	Properties struct {
+		Description string `json:"description"`
+		Choices     []struct {
+			Ref   string `json:"ref"`
+			Label string `json:"label"`
+		} `json:"choices"`
+		AllowMultipleSelection bool   `json:"allow_multiple_selection"`
+		AllowOtherChoice       bool   `json:"allow_other_choice"`
+		Steps                  int    `json:"steps"`
+		StartAtOne             bool   `json:"start_at_one"`
+		Shape                  string `json:"shape"`
+		Labels                 struct {
+			Left  string `json:"left"`
+			Right string `json:"right"`
+		} `json:"labels"`
+		Fields []typeformField `json:"fields"`
+	} `json:"properties"`
+	Validations struct {
+		Required bool `json:"required"`
+	} `json:"validations"`
+}
+
+type typeformLogic struct {
+	Type    string `json:"type"`
+	Ref     string `json:"ref"`
+	Actions []struct {
+		Action  string `json:"action"`
+		Details struct {
+			To struct {
+				Type  string `json:"type"`
+				Value string `json:"value"`
+			} `json:"to"`
+		} `json:"details"`
+		Condition struct {
+			Op   string `json:"op"`
+			Vars []struct {
+				Type  string          `json:"type"`
+				Value json.RawMessage `json:"value"`
+			} `json:"vars"`
+		} `json:"condition"`
+	} `json:"actions"`
+}
+
+// typeformRatingIcons maps the shapes of a Typeform rating onto icons of a rating question
+var typeformRatingIcons = map[string]string{
+	"star":        "star",
+	"heart":       "heart",
+	"user":        "user",
+	"up":          "thumbs-up",
+	"crown":       "crown",
+	"cat":         "cat",
+	"dog":         "dog",
+	"circle":      "circle",
+	"flag":        "flag",
+	"droplet":     "droplet",
+	"tick":        "check",
+	"lightbulb":   "lightbulb",
+	"trophy":      "trophy",
+	"cloud":       "cloud",
+	"thunderbolt": "zap",
+	"pencil":      "pencil",
+	"skull":       "skull",
+}
+
+// typeformQuestion keeps a converted field for the logic, which is resolved once every
+// field has been read
+type typeformQuestion struct {
+	question DocumentQuestion
+	section  *convertedSection
+	// choices maps the ref of each choice, or "true" and "false" for a yes/no field, to
+	// the index of the choice
+	choices map[string]int
+}
+
+// last reports whether the question ends its section, where the jumps of a section apply
+func (q *typeformQuestion) last() bool {
+	questions := q.section.section.Questions
+	return questions[len(questions)-1].ID == q.question.ID
+}
+
+// convertTypeform converts a form of the Typeform Create API. Typeform shows a question
+// at a time, so each group becomes a section, and the other fields are split into
+// sections after a field with logic and before a field logic jumps to. Jumps on a
+// selected choice become branches.
+func convertTypeform(data []byte) (Document, ConversionSummary, error) {
+	var source typeform
+	err := json.Unmarshal(data, &source)
+	if err != nil || (source.Title == "" && source.Fields == nil) {
+		return Document{}, ConversionSummary{}, ValidationError{Rows: []RowError{{Message: "body is not a form of the Typeform Create API"}}}
+	}
+
+	c := newConversion(FormatTypeform)
+	if len(source.Hidden) > 0 {
+		c.note("", "hidden fields", "hidden fields are not imported")
+	}
+	if source.Variables != nil && string(source.Variables) != "null" {
+		c.note("", "variables", "scores and other variables are not imported")
+	}
+
+	targets := make(map[string]bool)
+	branching := make(map[string]bool)
+	for _, logic := range source.Logic {
+		for _, action := range logic.Actions {
+			if action.Action == "jump" && action.Details.To.Type == "field" {
+				targets[action.Details.To.Value] = true
+			}
+		}
+		branching[logic.Ref] = true
+	}
+
+	questions := make(map[string]*typeformQuestion)
+	split := true
+	for _, field := range source.Fields {
+		if field.Type == "group" {
+			section := c.addSection(field.Ref, field.Title, field.Properties.Description)
+			for i, child := range field.Properties.Fields {
+				if i > 0 && targets[child.Ref] {
+					c.note(child.Title, "branching", "a jump into a question group lands on the start of the group")
+				}
+				c.alias(child.Ref, section)
+				c.addTypeformField(child, branching, questions)
+			}
+			split = true
+			continue
+		}
+
+		if split || targets[field.Ref] {
+			c.addSection(field.Ref, "", "")
+		}
+		c.addTypeformField(field, branching, questions)
+		split = branching[field.Ref]
+	}
+
+	for _, logic := range source.Logic {
+		c.addTypeformLogic(logic, questions)
+	}
+
+	description := ""
+	if len(source.WelcomeScreens) > 0 {
+		description = source.WelcomeScreens[0].Properties.Description
+	}
+	return c.document(source.Title, description)
+}
+
+func (c *conversion) addTypeformField(field typeformField, branching map[string]bool, questions map[string]*typeformQuestion) {
+	required := field.Validations.Required
+	request := question.Request{
+		Required:    &required,
+		Title:       field.Title,
+		Description: field.Properties.Description,
+	}
+	choices := make(map[string]int)
+
+	switch field.Type {
+	case "short_text":
+		request.Type = string(question.QuestionTypeShortText)
+	case "email", "phone_number", "website", "number":
+		request.Type = string(question.QuestionTypeShortText)
+		c.note(field.Title, field.Type, "converted to a short text question, the answer format is not checked")
+	case "long_text":
+		request.Type = string(question.QuestionTypeLongText)
+	case "multiple_choice", "picture_choice", "dropdown", "ranking":
+		if field.Type == "picture_choice" {
+			c.note(field.Title, "pictures", "converted to a choice question without the pictures")
+		}
+		if field.Properties.AllowOtherChoice {
+			c.note(field.Title, "other option", "the \"Other\" option with its own answer is not imported")
+		}
+		for i, choice := range field.Properties.Choices {
+			choices[choice.Ref] = i
+			request.Choices = append(request.Choices, question.ChoiceOption{Name: choice.Label})
+		}
+
+		switch {
+		case field.Type == "ranking":
+			request.Type = string(question.QuestionTypeRanking)
+		case field.Properties.AllowMultipleSelection:
+			request.Type = string(question.QuestionTypeMultipleChoice)
+		case field.Type == "dropdown" && !branching[field.Ref]:
+			request.Type = string(question.QuestionTypeDropdown)
+		case field.Type == "dropdown":
+			// Conditions only read single and multiple choice questions
+			request.Type = string(question.QuestionTypeSingleChoice)
+			c.note(field.Title, "dropdown", "converted to a single choice question so it can branch")
+		default:
+			request.Type = string(question.QuestionTypeSingleChoice)
+		}
+	case "yes_no":
+		request.Type = string(question.QuestionTypeSingleChoice)
+		request.Choices = []question.ChoiceOption{{Name: "Yes"}, {Name: "No"}}
+		choices["true"], choices["false"] = 0, 1
+	case "date":
+		request.Type = string(question.QuestionTypeDate)
+	case "rating":
+		request.Type = string(question.QuestionTypeRating)
+		request.Scale.MinVal, request.Scale.MaxVal = 1, field.Properties.Steps
+		if request.Scale.MaxVal == 0 {
+			request.Scale.MaxVal = 5
+		}
+		request.Scale.Icon = typeformRatingIcons[field.Properties.Shape]
+		if request.Scale.Icon == "" {
+			request.Scale.Icon = "star"
+		}
+	case "opinion_scale", "nps":
+		steps := field.Properties.Steps
+		if steps == 0 {
+			steps = 11
+		}
+		low := 0
+		if field.Properties.StartAtOne {
+			low = 1
+		}
+		request.Type = string(question.QuestionTypeLinearScale)
+		request.Scale.MinVal, request.Scale.MaxVal = c.scaleRange(field.Title, low, low+steps-1)
+		request.Scale.MinValueLabel = field.Properties.Labels.Left
+		request.Scale.MaxValueLabel = field.Properties.Labels.Right
+	case "file_upload":
+		// Typeform takes a single file of up to 10MB of any type
+		request.Type = string(question.QuestionTypeUploadFile)
+		request.UploadFile = c.uploadOption(field.Title, nil, 1, "")
+	case "legal":
+		request.Type = string(question.QuestionTypeConsent)
+		text := field.Properties.Description
+		if text == "" {
+			text = field.Title
+		}
+		request.Consent = &question.ConsentOption{Text: text, Version: "1"}
+	case "statement":
+		c.appendText(field.Title, field.Properties.Description)
+		return
+	default:
+		c.note(field.Title, field.Type, fmt.Sprintf("skipped, %s fields are not supported", field.Type))
+		return
+	}
+
+	converted, ok := c.addQuestion(request)
+	if !ok {
+		return
+	}
+	questions[field.Ref] = &typeformQuestion{question: converted, section: c.current(), choices: choices}
+}
+
+// addTypeformLogic turns the jumps of a field or group into branches and default jumps
+// of its section. A jump is kept when it always applies, or when it tests that a choice
+// of a single choice question of the section is selected.
+func (c *conversion) addTypeformLogic(logic typeformLogic, questions map[string]*typeformQuestion) {
+	var section *convertedSection
+	switch owner, ok := questions[logic.Ref]; {
+	case logic.Type == "group":
+		index, found := c.keys[logic.Ref]
+		if !found {
+			c.note(logic.Ref, "logic", "skipped the logic of a group that was not imported")
+			return
+		}
+		section = c.sections[index]
+	case !ok:
+		c.note(logic.Ref, "logic", "skipped the logic of a field that was not imported")
+		return
+	default:
+		section = owner.section
+		if !owner.last() {
+			c.note(owner.question.Title, "logic", "the jumps of the question apply once its whole group is answered")
+		}
+	}
+
+	for _, action := range logic.Actions {
+		if action.Action != "jump" {
+			c.note(logic.Ref, "logic", fmt.Sprintf("skipped the %s action, only jumps are supported", action.Action))
+			continue
+		}
+
+		var target jumpTarget
+		switch action.Details.To.Type {
+		case "field":
+			target = jumpTarget{section: action.Details.To.Value}
+		case "thankyou":
+			target = jumpTarget{end: true}
+		default:
+			c.note(logic.Ref, "logic", fmt.Sprintf("skipped a jump to a %s", action.Details.To.Type))
+			continue
+		}
+
+		condition := action.Condition
+		if condition.Op == "always" {
+			section.next = &target
+			continue
+		}
+		if (condition.Op != "is" && condition.Op != "equal") || len(condition.Vars) != 2 || condition.Vars[0].Type != "field" {
+			c.note(logic.Ref, "logic", fmt.Sprintf("skipped a jump on the %q condition, only jumps on a selected choice are supported", condition.Op))
+			continue
+		}
+
+		var ref, key string
+		_ = json.Unmarshal(condition.Vars[0].Value, &ref)
+		switch condition.Vars[1].Type {
+		case "choice":
+			_ = json.Unmarshal(condition.Vars[1].Value, &key)
+		case "constant":
+			key = string(condition.Vars[1].Value)
+		}
+
+		tested, ok := questions[ref]
+		if !ok || tested.section != section || tested.question.Type != string(question.QuestionTypeSingleChoice) {
+			c.note(logic.Ref, "branching", "skipped a jump on a question that is not a single choice question of the same section")
+			continue
+		}
+		index, ok := tested.choices[key]
+		if !ok {
+			c.note(tested.question.Title, "branching", "skipped a jump on a choice that was not imported")
+			continue
+		}
+		c.addBranch(section, tested.question, index, target)
+	}
+}
@@ -0,0 +1,269 @@
+package ingest
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]OrgIngestSource, error)
+	Rotate(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, source string) (OrgIngestSource, string, error)
+	Delete(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, source string) error
+	Authenticate(ctx context.Context, formID uuid.UUID, header string, body []byte) (string, error)
+	Ingest(ctx context.Context, formID uuid.UUID, submission Submission) (FormResponseSource, bool, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+// SourceRequest names the source to create or replace the credential of
+type SourceRequest struct {
+	Source string `json:"source" validate:"required,max=64"`
+}
+
+// SourceResponse describes a source; its id is the key its signatures carry
+type SourceResponse struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RotateResponse holds the secret of the credential, which cannot be read again
+type RotateResponse struct {
+	SourceResponse
+	Secret string `json:"secret"`
+}
+
+func ToSourceResponse(source OrgIngestSource) SourceResponse {
+	return SourceResponse{
+		ID:        source.ID.String(),
+		Source:    source.Source,
+		CreatedAt: source.CreatedAt.Time,
+	}
+}
+
+// Request is a submission posted by an external form tool; each answer names its
+// question by ID or by title
+type Request struct {
+	Source      string          `json:"source" validate:"required,max=64"`
+	ExternalID  string          `json:"externalId" validate:"required,max=255"`
+	SubmittedAt *time.Time      `json:"submittedAt"`
+	Answers     []AnswerRequest `json:"answers" validate:"dive"`
+}
+
+type AnswerRequest struct {
+	QuestionID string `json:"questionId" validate:"required_without=Question,omitempty,uuid"`
+	Question   string `json:"question" validate:"required_without=QuestionID,max=255"`
+	Value      string `json:"value" validate:"required"`
+}
+
+type Response struct {
+	ResponseID string `json:"responseId"`
+	Source     string `json:"source"`
+	ExternalID string `json:"externalId"`
+	Duplicate  bool   `json:"duplicate"`
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store, tenantStore tenantStore) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("ingest/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+func (h *Handler) ListSourcesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListSourcesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, orgID, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	sources, err := h.store.List(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]SourceResponse, len(sources))
+	for i, source := range sources {
+		response[i] = ToSourceResponse(source)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) RotateSourceHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RotateSourceHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	var req SourceRequest
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, orgID, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	source, secret, err := h.store.Rotate(traceCtx, orgID, currentUser.ID, req.Source)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, RotateResponse{SourceResponse: ToSourceResponse(source), Secret: secret})
+}
+
+func (h *Handler) DeleteSourceHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteSourceHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, orgID, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, orgID, currentUser.ID, r.PathValue("source"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IngestHandler saves a submission of an external form tool as a response of the form.
+// The request carries no session; it is signed with the credential of the source, in the
+// format of the webhook.SignatureHeader header, and the source it names must be the one
+// that signed. A submission delivered again answers 200 with the response saved the first
+// time.
+func (h *Handler) IngestHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "IngestHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	// The signature covers the raw body, so it is read before being parsed
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to read request body: %w", err), logger)
+		return
+	}
+
+	signedBy, err := h.store.Authenticate(traceCtx, formID, r.Header.Get(webhook.SignatureHeader), body)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+	if req.Source != signedBy {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: signed by another source", internal.ErrWebhookSignatureInvalid), logger)
+		return
+	}
+
+	answers := make([]SubmittedAnswer, len(req.Answers))
+	for i, answer := range req.Answers {
+		answers[i] = SubmittedAnswer{
+			QuestionID: answer.QuestionID,
+			Question:   answer.Question,
+			Value:      answer.Value,
+		}
+	}
+
+	source, duplicate, err := h.store.Ingest(traceCtx, formID, Submission{
+		Source:      req.Source,
+		ExternalID:  req.ExternalID,
+		SubmittedAt: req.SubmittedAt,
+		Answers:     answers,
+	})
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	status := http.StatusCreated
+	if duplicate {
+		status = http.StatusOK
+	}
+
+	handlerutil.WriteJSONResponse(w, status, Response{
+		ResponseID: uuid.UUID(source.ResponseID.Bytes).String(),
+		Source:     source.Source,
+		ExternalID: source.ExternalID,
+		Duplicate:  duplicate,
+	})
+}
+
+// caller returns the current user and the organization of the path
+func (h *Handler) caller(ctx context.Context) (*user.User, uuid.UUID, error) {
+	currentUser, ok := user.GetFromContext(ctx)
+	if !ok {
+		return nil, uuid.Nil, internal.ErrNoUserInContext
+	}
+
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return currentUser, orgID, nil
+}
@@ -0,0 +1,66 @@
+package ingest_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/ingest"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeStore authenticates every request as signed by the source signedBy
+type fakeStore struct {
+	ingest.Store
+	signedBy string
+}
+
+func (s fakeStore) Authenticate(context.Context, uuid.UUID, string, []byte) (string, error) {
+	return s.signedBy, nil
+}
+
+func (s fakeStore) Ingest(_ context.Context, formID uuid.UUID, submission ingest.Submission) (ingest.FormResponseSource, bool, error) {
+	return ingest.FormResponseSource{
+		FormID:     formID,
+		ResponseID: pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Source:     submission.Source,
+		ExternalID: submission.ExternalID,
+	}, false, nil
+}
+
+func TestHandler_IngestBindsSource(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name           string
+		signedBy       string
+		expectedStatus int
+	}
+
+	testCases := []testCase{
+		{name: "Signed by the source it names", signedBy: "google-forms", expectedStatus: http.StatusCreated},
+		{name: "Signed by another source", signedBy: "typeform", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := ingest.NewHandler(zap.NewNop(), internal.NewValidator(), internal.NewProblemWriter(), fakeStore{signedBy: tc.signedBy}, nil)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/forms/"+uuid.NewString()+"/ingest", strings.NewReader(`{"source":"google-forms","externalId":"1","answers":[]}`))
+			req.SetPathValue("id", uuid.NewString())
+			req.Header.Set(webhook.SignatureHeader, "t=0,v1=key:00")
+			rec := httptest.NewRecorder()
+
+			handler.IngestHandler(rec, req)
+			require.Equal(t, tc.expectedStatus, rec.Code)
+		})
+	}
+}
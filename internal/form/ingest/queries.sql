@@ -0,0 +1,62 @@
+-- name: GetFormOrg :one
+SELECT u.id, u.org_id
+FROM forms f
+JOIN units u ON u.id = f.unit_id
+WHERE f.id = @form_id;
+
+-- name: CreateRespondent :one
+INSERT INTO users (name, role, is_onboarded)
+VALUES ('External respondent', '{"respondent"}', true)
+RETURNING id;
+
+-- name: Claim :one
+INSERT INTO form_response_sources (form_id, source, external_id, submitted_at)
+VALUES (@form_id, @source, @external_id, @submitted_at)
+ON CONFLICT (form_id, source, external_id) DO NOTHING
+RETURNING *;
+
+-- name: GetBySource :one
+SELECT * FROM form_response_sources
+WHERE form_id = @form_id AND source = @source AND external_id = @external_id;
+
+-- name: Attach :exec
+UPDATE form_response_sources
+SET response_id = @response_id
+WHERE id = @id;
+
+-- name: Release :exec
+DELETE FROM form_response_sources
+WHERE id = @id AND response_id IS NULL;
+
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: UpsertSource :one
+INSERT INTO org_ingest_sources (org_id, source, secret, created_by)
+VALUES (@org_id, @source, @secret, @created_by)
+ON CONFLICT (org_id, source) DO UPDATE
+SET secret = EXCLUDED.secret,
+    created_by = EXCLUDED.created_by,
+    created_at = now()
+RETURNING *;
+
+-- name: ListSources :many
+SELECT * FROM org_ingest_sources
+WHERE org_id = @org_id
+ORDER BY source;
+
+-- name: GetSource :one
+SELECT * FROM org_ingest_sources
+WHERE id = @id AND org_id = @org_id;
+
+-- name: DeleteSource :execrows
+DELETE FROM org_ingest_sources
+WHERE org_id = @org_id AND source = @source;
+
+-- name: ListSourceSecrets :many
+SELECT id, secret FROM org_ingest_sources;
+
+-- name: UpdateSourceSecret :exec
+UPDATE org_ingest_sources
+SET secret = @secret
+WHERE id = @id;
\ No newline at end of file
@@ -0,0 +1,16 @@
+package ingest
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the endpoint external form tools post their submissions to, signed
+// with the credential of the source instead of a session, and the sources of an
+// organization
+func Routes(r route.Router, h *Handler) {
+	r.Handle("POST /forms/{id}/ingest", route.Public, route.PermissionNone, h.IngestHandler)
+
+	r.Handle("GET /orgs/{slug}/ingest-sources", route.TenantAuthenticated, route.PermissionOrgAdmin, h.ListSourcesHandler)
+	r.Handle("POST /orgs/{slug}/ingest-sources", route.TenantAuthenticated, route.PermissionOrgAdmin, h.RotateSourceHandler)
+	r.Handle("DELETE /orgs/{slug}/ingest-sources/{source}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.DeleteSourceHandler)
+}
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS form_response_sources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    response_id UUID UNIQUE REFERENCES form_responses(id) ON DELETE CASCADE,
+    source VARCHAR(64) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    submitted_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (form_id, source, external_id)
+);
+
+CREATE TABLE IF NOT EXISTS org_ingest_sources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    source VARCHAR(64) NOT NULL,
+    secret TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (org_id, source)
+);
\ No newline at end of file
@@ -0,0 +1,533 @@
+package ingest
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// sourcePattern keeps the names of the sources short identifiers, e.g. "google-forms"
+var sourcePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+const secretLength = 32
+
+// SecretBox encrypts the credentials of the sources at rest
+type SecretBox interface {
+	Seal(value string) (string, error)
+	Open(value string) (string, error)
+	Current(value string) bool
+}
+
+type FormStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (form.GetByIDRow, error)
+}
+
+type QuestionStore interface {
+	ListByFormID(ctx context.Context, formID uuid.UUID) ([]question.SectionWithQuestions, error)
+}
+
+type ResponseStore interface {
+	Create(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam, questionType []response.QuestionType, isTest bool) (response.FormResponse, error)
+}
+
+type BallotStore interface {
+	IsBallot(ctx context.Context, formID uuid.UUID) (bool, error)
+}
+
+type AssignmentStore interface {
+	Assign(ctx context.Context, formID uuid.UUID, responseID uuid.UUID) error
+}
+
+// SubmittedAnswer is an answer of an external submission, for the question of the ID or, when
+// the source does not know the IDs, the question of the title
+type SubmittedAnswer struct {
+	QuestionID string
+	Question   string
+	Value      string
+}
+
+// Submission is a response collected by an external form tool
+type Submission struct {
+	Source      string
+	ExternalID  string
+	SubmittedAt *time.Time
+	Answers     []SubmittedAnswer
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries *Queries
+	tracer  trace.Tracer
+
+	secretBox       SecretBox
+	formStore       FormStore
+	questionStore   QuestionStore
+	responseStore   ResponseStore
+	ballotStore     BallotStore
+	assignmentStore AssignmentStore
+}
+
+func NewService(logger *zap.Logger, db DBTX, secretBox SecretBox, formStore FormStore, questionStore QuestionStore, responseStore ResponseStore, ballotStore BallotStore, assignmentStore AssignmentStore) *Service {
+	return &Service{
+		logger:          logger,
+		queries:         New(db),
+		tracer:          otel.Tracer("ingest/service"),
+		secretBox:       secretBox,
+		formStore:       formStore,
+		questionStore:   questionStore,
+		responseStore:   responseStore,
+		ballotStore:     ballotStore,
+		assignmentStore: assignmentStore,
+	}
+}
+
+// List returns the sources allowed to post submissions to the forms of the organization.
+// Only org admins may read them, and never their credentials.
+func (s *Service) List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]OrgIngestSource, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkOrgAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	sources, err := s.queries.ListSources(traceCtx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_ingest_sources", "org_id", orgID.String(), logger, "list ingest sources")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// Rotate creates the credential of a source, or replaces the one it had, and returns it
+// with its secret, which is shown only this once. A replaced credential stops being
+// accepted at once; a source signs with a single credential, unlike the deliveries.
+func (s *Service) Rotate(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, source string) (OrgIngestSource, string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Rotate")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkOrgAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return OrgIngestSource{}, "", err
+	}
+
+	if !sourcePattern.MatchString(source) {
+		err = fmt.Errorf("%w: source must be lowercase letters, digits, '.', '_' or '-'", internal.ErrInvalidIngestSubmission)
+		span.RecordError(err)
+		return OrgIngestSource{}, "", err
+	}
+
+	secret, err := randomSecret()
+	if err != nil {
+		span.RecordError(err)
+		return OrgIngestSource{}, "", err
+	}
+	sealed, err := s.secretBox.Seal(secret)
+	if err != nil {
+		err = fmt.Errorf("failed to seal ingest secret: %w", err)
+		span.RecordError(err)
+		return OrgIngestSource{}, "", err
+	}
+
+	credential, err := s.queries.UpsertSource(traceCtx, UpsertSourceParams{
+		OrgID:     orgID,
+		Source:    source,
+		Secret:    sealed,
+		CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_ingest_sources", "source", source, logger, "upsert ingest source")
+		span.RecordError(err)
+		return OrgIngestSource{}, "", err
+	}
+
+	logger.Info("Rotated ingest source credential",
+		zap.String("org_id", orgID.String()),
+		zap.String("source", source),
+		zap.String("credential_id", credential.ID.String()),
+	)
+	return credential, secret, nil
+}
+
+// Delete stops accepting the submissions of a source
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, source string) error {
+	traceCtx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkOrgAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.DeleteSource(traceCtx, DeleteSourceParams{OrgID: orgID, Source: source})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_ingest_sources", "source", source, logger, "delete ingest source")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = fmt.Errorf("%w: %s", internal.ErrIngestSourceNotFound, source)
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted ingest source", zap.String("org_id", orgID.String()), zap.String("source", source))
+	return nil
+}
+
+// Authenticate checks that the body was signed with the credential of a source of the
+// organization owning the form, in the format of webhook.SignatureHeader with the ID of
+// the credential as the key, and returns the name of that source
+func (s *Service) Authenticate(ctx context.Context, formID uuid.UUID, header string, body []byte) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Authenticate")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	unit, err := s.queries.GetFormOrg(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "get form organization")
+		span.RecordError(err)
+		return "", err
+	}
+
+	// A unit without a parent is the organization itself
+	orgID := unit.ID
+	if unit.OrgID.Valid {
+		orgID = unit.OrgID.Bytes
+	}
+
+	signature, err := webhook.ParseSignature(header, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	for _, keyID := range signature.KeyIDs() {
+		id, err := uuid.Parse(keyID)
+		if err != nil {
+			continue
+		}
+
+		credential, err := s.queries.GetSource(traceCtx, GetSourceParams{ID: id, OrgID: orgID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "org_ingest_sources", "id", keyID, logger, "get ingest source")
+			span.RecordError(err)
+			return "", err
+		}
+
+		secret, err := s.secretBox.Open(credential.Secret)
+		if err != nil {
+			err = fmt.Errorf("failed to open ingest secret %s: %w", credential.ID, err)
+			span.RecordError(err)
+			return "", err
+		}
+
+		if signature.Matches(keyID, secret, body) {
+			return credential.Source, nil
+		}
+	}
+
+	err = fmt.Errorf("%w: no credential of the organization matches", internal.ErrWebhookSignatureInvalid)
+	span.RecordError(err)
+	return "", err
+}
+
+// RotateSecrets seals the credentials of the sources again with the current key of the
+// keyring
+func (s *Service) RotateSecrets(ctx context.Context) (int, error) {
+	traceCtx, span := s.tracer.Start(ctx, "RotateSecrets")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	rows, err := s.queries.ListSourceSecrets(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list ingest secrets")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	count := 0
+	for _, row := range rows {
+		if s.secretBox.Current(row.Secret) {
+			continue
+		}
+
+		secret, err := s.secretBox.Open(row.Secret)
+		if err != nil {
+			err = fmt.Errorf("failed to open ingest secret %s: %w", row.ID, err)
+			span.RecordError(err)
+			return count, err
+		}
+		sealed, err := s.secretBox.Seal(secret)
+		if err != nil {
+			span.RecordError(err)
+			return count, err
+		}
+
+		err = s.queries.UpdateSourceSecret(traceCtx, UpdateSourceSecretParams{Secret: sealed, ID: row.ID})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "org_ingest_sources", "id", row.ID.String(), logger, "seal ingest secret")
+			span.RecordError(err)
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Ingest saves an external submission as a response of the form, answered by a guest
+// respondent standing for the person who filled the external form. A submission already
+// ingested is not saved again; its source is returned with duplicate set, so the source
+// can retry a delivery safely.
+//
+// Like the respondents, a source cannot answer a form that is not published or whose
+// deadline has passed. The response does not run the workflow actions nor request
+// approval, which belong to the submissions made in the form itself, but is assigned to
+// a reviewer like them.
+func (s *Service) Ingest(ctx context.Context, formID uuid.UUID, submission Submission) (FormResponseSource, bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Ingest")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if !sourcePattern.MatchString(submission.Source) {
+		err := fmt.Errorf("%w: source must be lowercase letters, digits, '.', '_' or '-'", internal.ErrInvalidIngestSubmission)
+		span.RecordError(err)
+		return FormResponseSource{}, false, err
+	}
+
+	currentForm, err := s.formStore.GetByID(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return FormResponseSource{}, false, err
+	}
+	err = form.CheckOpen(currentForm.Status, currentForm.Deadline, time.Now())
+	if err != nil {
+		span.RecordError(err)
+		return FormResponseSource{}, false, err
+	}
+
+	isBallot, err := s.ballotStore.IsBallot(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return FormResponseSource{}, false, err
+	}
+	if isBallot {
+		span.RecordError(internal.ErrBallotMode)
+		return FormResponseSource{}, false, internal.ErrBallotMode
+	}
+
+	answers, questionTypes, err := s.resolve(traceCtx, formID, submission.Answers)
+	if err != nil {
+		span.RecordError(err)
+		return FormResponseSource{}, false, err
+	}
+
+	submittedAt := pgtype.Timestamptz{}
+	if submission.SubmittedAt != nil {
+		submittedAt = pgtype.Timestamptz{Time: *submission.SubmittedAt, Valid: true}
+	}
+
+	// The claim is taken before the response is saved, so concurrent deliveries of the
+	// same submission save it once
+	claim, err := s.queries.Claim(traceCtx, ClaimParams{
+		FormID:      formID,
+		Source:      submission.Source,
+		ExternalID:  submission.ExternalID,
+		SubmittedAt: submittedAt,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s.existing(traceCtx, formID, submission)
+	}
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_response_sources", "external_id", submission.ExternalID, logger, "claim ingested submission")
+		span.RecordError(err)
+		return FormResponseSource{}, false, err
+	}
+
+	result, err := s.save(traceCtx, formID, answers, questionTypes)
+	if err != nil {
+		// Releasing the claim lets the source retry the delivery
+		releaseErr := s.queries.Release(traceCtx, claim.ID)
+		if releaseErr != nil {
+			logger.Error("failed to release ingested submission claim", zap.Error(releaseErr), zap.String("claimID", claim.ID.String()))
+		}
+		span.RecordError(err)
+		return FormResponseSource{}, false, err
+	}
+
+	claim.ResponseID = pgtype.UUID{Bytes: result.ID, Valid: true}
+	err = s.queries.Attach(traceCtx, AttachParams{ID: claim.ID, ResponseID: claim.ResponseID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_response_sources", "id", claim.ID.String(), logger, "attach ingested response")
+		span.RecordError(err)
+		return FormResponseSource{}, false, err
+	}
+
+	// The response is saved by now; one left unassigned can still be distributed later
+	err = s.assignmentStore.Assign(traceCtx, formID, result.ID)
+	if err != nil {
+		logger.Error("failed to assign ingested response to a reviewer", zap.Error(err), zap.String("formID", formID.String()), zap.String("responseID", result.ID.String()))
+		span.RecordError(err)
+	}
+
+	return claim, false, nil
+}
+
+// resolve matches the answers to the questions of the form and validates them like a
+// submission, reporting every invalid answer at once
+func (s *Service) resolve(ctx context.Context, formID uuid.UUID, answers []SubmittedAnswer) ([]shared.AnswerParam, []response.QuestionType, error) {
+	list, err := s.questionStore.ListByFormID(ctx, formID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[string]question.Answerable)
+	byTitle := make(map[string][]question.Answerable)
+	for _, section := range list {
+		for _, q := range section.Questions {
+			byID[q.Question().ID.String()] = q
+			title := strings.ToLower(strings.TrimSpace(q.Question().Title.String))
+			byTitle[title] = append(byTitle[title], q)
+		}
+	}
+
+	params := make([]shared.AnswerParam, 0, len(answers))
+	questionTypes := make([]response.QuestionType, 0, len(answers))
+	answered := make(map[uuid.UUID]bool, len(answers))
+	var messages []string
+
+	for _, answer := range answers {
+		var q question.Answerable
+		switch {
+		case answer.QuestionID != "":
+			q = byID[answer.QuestionID]
+			if q == nil {
+				messages = append(messages, fmt.Sprintf("question with ID %s not found in form %s", answer.QuestionID, formID))
+				continue
+			}
+		default:
+			matches := byTitle[strings.ToLower(strings.TrimSpace(answer.Question))]
+			if len(matches) != 1 {
+				messages = append(messages, fmt.Sprintf("question %q matches %d questions of form %s", answer.Question, len(matches), formID))
+				continue
+			}
+			q = matches[0]
+		}
+
+		id := q.Question().ID
+		if answered[id] {
+			messages = append(messages, fmt.Sprintf("question ID %s is answered more than once", id))
+			continue
+		}
+		answered[id] = true
+
+		err := q.Validate(answer.Value)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("validation error for question ID %s: %s", id, err))
+			continue
+		}
+
+		params = append(params, shared.AnswerParam{QuestionID: id.String(), Value: answer.Value})
+		questionTypes = append(questionTypes, response.QuestionType(q.Question().Type))
+	}
+
+	for _, section := range list {
+		for _, q := range section.Questions {
+			if q.Question().Required && !answered[q.Question().ID] {
+				messages = append(messages, fmt.Sprintf("question ID %s is required but not answered", q.Question().ID))
+			}
+		}
+	}
+
+	if len(messages) > 0 {
+		return nil, nil, fmt.Errorf("%w: [%s]", internal.ErrInvalidIngestSubmission, strings.Join(messages, "; "))
+	}
+
+	return params, questionTypes, nil
+}
+
+// save creates the guest respondent of the submission and its response
+func (s *Service) save(ctx context.Context, formID uuid.UUID, answers []shared.AnswerParam, questionTypes []response.QuestionType) (response.FormResponse, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	respondentID, err := s.queries.CreateRespondent(ctx)
+	if err != nil {
+		return response.FormResponse{}, databaseutil.WrapDBError(err, logger, "create external respondent")
+	}
+
+	return s.responseStore.Create(ctx, formID, respondentID, answers, questionTypes, false)
+}
+
+// existing returns the source of a submission ingested before
+func (s *Service) existing(ctx context.Context, formID uuid.UUID, submission Submission) (FormResponseSource, bool, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	source, err := s.queries.GetBySource(ctx, GetBySourceParams{
+		FormID:     formID,
+		Source:     submission.Source,
+		ExternalID: submission.ExternalID,
+	})
+	if err != nil {
+		return FormResponseSource{}, false, databaseutil.WrapDBErrorWithKeyValue(err, "form_response_sources", "external_id", submission.ExternalID, logger, "get ingested submission")
+	}
+
+	// Another delivery of the submission is saving it right now
+	if !source.ResponseID.Valid {
+		return FormResponseSource{}, false, internal.ErrIngestInProgress
+	}
+
+	return source, true, nil
+}
+
+func (s *Service) checkOrgAdmin(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsOrgAdmin(ctx, IsOrgAdminParams{OrgID: orgID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check org admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func randomSecret() (string, error) {
+	b := make([]byte, secretLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ingest secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
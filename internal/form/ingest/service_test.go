@@ -0,0 +1,219 @@
+package ingest_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/ingest"
+	"NYCU-SDC/core-system-backend/internal/secrets"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// sourcesDB keeps the ingest sources of the tests in memory, with every form in a unit
+// of the organization orgID
+type sourcesDB struct {
+	orgID   uuid.UUID
+	sources []ingest.OrgIngestSource
+}
+
+func (db *sourcesDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("unexpected exec")
+}
+
+func (db *sourcesDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (db *sourcesDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	switch {
+	case strings.Contains(sql, "name: GetFormOrg"):
+		return scanRow(func(dest ...any) error {
+			*dest[0].(*uuid.UUID) = uuid.New()
+			*dest[1].(*pgtype.UUID) = pgtype.UUID{Bytes: db.orgID, Valid: true}
+			return nil
+		})
+	case strings.Contains(sql, "name: IsOrgAdmin"):
+		return scanRow(func(dest ...any) error {
+			*dest[0].(*bool) = true
+			return nil
+		})
+	case strings.Contains(sql, "name: UpsertSource"):
+		orgID, name := args[0].(uuid.UUID), args[1].(string)
+		for i, source := range db.sources {
+			if source.OrgID == orgID && source.Source == name {
+				db.sources[i].Secret = args[2].(string)
+				return sourceRow(db.sources[i])
+			}
+		}
+		source := ingest.OrgIngestSource{
+			ID:        uuid.New(),
+			OrgID:     orgID,
+			Source:    name,
+			Secret:    args[2].(string),
+			CreatedBy: args[3].(pgtype.UUID),
+			CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		}
+		db.sources = append(db.sources, source)
+		return sourceRow(source)
+	case strings.Contains(sql, "name: GetSource"):
+		id, orgID := args[0].(uuid.UUID), args[1].(uuid.UUID)
+		for _, source := range db.sources {
+			if source.ID == id && source.OrgID == orgID {
+				return sourceRow(source)
+			}
+		}
+		return scanRow(func(...any) error { return pgx.ErrNoRows })
+	}
+	return scanRow(func(...any) error { return errors.New("unexpected query") })
+}
+
+type scanRow func(dest ...any) error
+
+func (r scanRow) Scan(dest ...any) error { return r(dest...) }
+
+func sourceRow(source ingest.OrgIngestSource) scanRow {
+	return func(dest ...any) error {
+		*dest[0].(*uuid.UUID) = source.ID
+		*dest[1].(*uuid.UUID) = source.OrgID
+		*dest[2].(*string) = source.Source
+		*dest[3].(*string) = source.Secret
+		*dest[4].(*pgtype.UUID) = source.CreatedBy
+		*dest[5].(*pgtype.Timestamptz) = source.CreatedAt
+		return nil
+	}
+}
+
+type formStore struct {
+	row form.GetByIDRow
+}
+
+func (s formStore) GetByID(context.Context, uuid.UUID) (form.GetByIDRow, error) {
+	return s.row, nil
+}
+
+// header signs the body with the credential like an external source
+func header(keyID string, secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix + "."))
+	mac.Write(body)
+	return "t=" + unix + ",v1=" + keyID + ":" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestService_Authenticate(t *testing.T) {
+	t.Parallel()
+
+	db := &sourcesDB{orgID: uuid.New()}
+	service := ingest.NewService(zap.NewNop(), db, secrets.NewDevKeyring("test-secret"), formStore{}, nil, nil, nil, nil)
+	userID := uuid.New()
+	body := []byte(`{"source":"google-forms","externalId":"1","answers":[]}`)
+
+	credential, secret, err := service.Rotate(context.Background(), db.orgID, userID, "google-forms")
+	require.NoError(t, err)
+	replaced, replacedSecret, err := service.Rotate(context.Background(), db.orgID, userID, "typeform")
+	require.NoError(t, err)
+	_, _, err = service.Rotate(context.Background(), db.orgID, userID, "typeform")
+	require.NoError(t, err)
+	other, otherSecret, err := service.Rotate(context.Background(), uuid.New(), userID, "google-forms")
+	require.NoError(t, err)
+
+	type testCase struct {
+		name           string
+		header         string
+		expectedSource string
+		expectedErr    error
+	}
+
+	now := time.Now()
+	testCases := []testCase{
+		{name: "Signed now", header: header(credential.ID.String(), secret, now, body), expectedSource: "google-forms"},
+		{name: "Signed within the tolerance", header: header(credential.ID.String(), secret, now.Add(-webhook.SignatureTolerance+time.Minute), body), expectedSource: "google-forms"},
+		{name: "Clock of the source ahead within the tolerance", header: header(credential.ID.String(), secret, now.Add(webhook.SignatureTolerance-time.Minute), body), expectedSource: "google-forms"},
+		{name: "Signed before the tolerance", header: header(credential.ID.String(), secret, now.Add(-webhook.SignatureTolerance-time.Minute), body), expectedErr: internal.ErrWebhookSignatureInvalid},
+		{name: "Signed after the tolerance", header: header(credential.ID.String(), secret, now.Add(webhook.SignatureTolerance+time.Minute), body), expectedErr: internal.ErrWebhookSignatureInvalid},
+		{name: "Wrong secret", header: header(credential.ID.String(), otherSecret, now, body), expectedErr: internal.ErrWebhookSignatureInvalid},
+		{name: "Credential replaced by a rotation", header: header(replaced.ID.String(), replacedSecret, now, body), expectedErr: internal.ErrWebhookSignatureInvalid},
+		{name: "Credential of another organization", header: header(other.ID.String(), otherSecret, now, body), expectedErr: internal.ErrWebhookSignatureInvalid},
+		{name: "Key not a credential ID", header: header("key", secret, now, body), expectedErr: internal.ErrWebhookSignatureInvalid},
+		{name: "Unsigned", header: "", expectedErr: internal.ErrWebhookSignatureInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			source, err := service.Authenticate(context.Background(), uuid.New(), tc.header, body)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedSource, source)
+		})
+	}
+}
+
+func TestService_Rotate(t *testing.T) {
+	t.Parallel()
+
+	db := &sourcesDB{orgID: uuid.New()}
+	service := ingest.NewService(zap.NewNop(), db, secrets.NewDevKeyring("test-secret"), formStore{}, nil, nil, nil, nil)
+
+	first, firstSecret, err := service.Rotate(context.Background(), db.orgID, uuid.New(), "google-forms")
+	require.NoError(t, err)
+	require.NotContains(t, db.sources[0].Secret, firstSecret, "the credential is sealed at rest")
+
+	// Rotating again keeps the source and its ID, with a new secret
+	second, secondSecret, err := service.Rotate(context.Background(), db.orgID, uuid.New(), "google-forms")
+	require.NoError(t, err)
+	require.Equal(t, first.ID, second.ID)
+	require.NotEqual(t, firstSecret, secondSecret)
+	require.Len(t, db.sources, 1)
+
+	_, _, err = service.Rotate(context.Background(), db.orgID, uuid.New(), "Google Forms")
+	require.ErrorIs(t, err, internal.ErrInvalidIngestSubmission)
+}
+
+func TestService_IngestRefusesClosedForms(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		form        form.GetByIDRow
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{name: "Draft form", form: form.GetByIDRow{Status: form.StatusDraft}, expectedErr: internal.ErrFormNotPublished},
+		{
+			name:        "Deadline passed",
+			form:        form.GetByIDRow{Status: form.StatusPublished, Deadline: pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}},
+			expectedErr: internal.ErrFormDeadlinePassed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := ingest.NewService(zap.NewNop(), &sourcesDB{}, secrets.NewDevKeyring("test-secret"), formStore{row: tc.form}, nil, nil, nil, nil)
+			_, _, err := service.Ingest(context.Background(), uuid.New(), ingest.Submission{Source: "google-forms", ExternalID: "1"})
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package payment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// taipei is the time zone of the timestamps both providers send
+var taipei = time.FixedZone("Asia/Taipei", 8*60*60)
+
+// ecpayEscaper undoes the differences between url.QueryEscape and the .NET URL encoding
+// ECPay signs with
+var ecpayEscaper = strings.NewReplacer("%21", "!", "%2a", "*", "%28", "(", "%29", ")", "~", "%7e")
+
+// ECPay reads the ReturnURL callbacks of ECPay (綠界), form posts signed with CheckMacValue
+type ECPay struct {
+	merchantID string
+	hashKey    string
+	hashIV     string
+}
+
+func NewECPay(merchantID, hashKey, hashIV string) *ECPay {
+	return &ECPay{merchantID: merchantID, hashKey: hashKey, hashIV: hashIV}
+}
+
+func (p *ECPay) Name() string {
+	return "ecpay"
+}
+
+// checkMacValue signs the fields sorted by name, between the hash key and IV, URL
+// encoded and lowercased, with SHA-256
+func (p *ECPay) checkMacValue(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "CheckMacValue" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return strings.ToLower(keys[i]) < strings.ToLower(keys[j])
+	})
+
+	var b strings.Builder
+	b.WriteString("HashKey=" + p.hashKey)
+	for _, key := range keys {
+		b.WriteString("&" + key + "=" + values.Get(key))
+	}
+	b.WriteString("&HashIV=" + p.hashIV)
+
+	encoded := ecpayEscaper.Replace(strings.ToLower(url.QueryEscape(b.String())))
+	sum := sha256.Sum256([]byte(encoded))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func (p *ECPay) Parse(r *http.Request) (Notification, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return Notification{}, fmt.Errorf("%w: %v", internal.ErrPaymentNotificationInvalid, err)
+	}
+	values := r.PostForm
+
+	signature := strings.ToUpper(values.Get("CheckMacValue"))
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(p.checkMacValue(values))) != 1 {
+		return Notification{}, fmt.Errorf("%w: CheckMacValue does not match", internal.ErrPaymentNotificationInvalid)
+	}
+	if values.Get("MerchantID") != p.merchantID {
+		return Notification{}, fmt.Errorf("%w: unknown merchant", internal.ErrPaymentNotificationInvalid)
+	}
+
+	amount, err := strconv.ParseInt(values.Get("TradeAmt"), 10, 32)
+	if err != nil {
+		return Notification{}, fmt.Errorf("%w: invalid TradeAmt", internal.ErrPaymentNotificationInvalid)
+	}
+
+	notification := Notification{
+		Reference:         values.Get("MerchantTradeNo"),
+		ExternalReference: values.Get("TradeNo"),
+		Status:            PaymentStatusFailed,
+		Amount:            int32(amount),
+		Simulated:         values.Get("SimulatePaid") == "1",
+	}
+	if values.Get("RtnCode") == "1" {
+		notification.Status = PaymentStatusPaid
+		notification.PaidAt, err = time.ParseInLocation("2006/01/02 15:04:05", values.Get("PaymentDate"), taipei)
+		if err != nil {
+			notification.PaidAt = time.Now()
+		}
+	}
+	return notification, nil
+}
+
+func (p *ECPay) Acknowledge(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write([]byte("1|OK"))
+	return err
+}
@@ -0,0 +1,389 @@
+package payment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	GetSettings(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (FormPaymentSetting, error)
+	SetSettings(ctx context.Context, formID uuid.UUID, amountDue int32, currency string, userID uuid.UUID) (FormPaymentSetting, error)
+	DeleteSettings(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+	Get(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID) (ResponsePayment, error)
+	Update(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, update Update, userID uuid.UUID) (ResponsePayment, error)
+	Notify(ctx context.Context, provider string, notification Notification) error
+	Report(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Report, error)
+}
+
+type SettingsRequest struct {
+	AmountDue int32  `json:"amountDue" validate:"min=0"`
+	Currency  string `json:"currency" validate:"omitempty,len=3,uppercase"`
+}
+
+type SettingsResponse struct {
+	FormID    string    `json:"formId"`
+	AmountDue int32     `json:"amountDue"`
+	Currency  string    `json:"currency"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type UpdateRequest struct {
+	Status            string  `json:"status" validate:"required,oneof=unpaid pending paid failed refunded waived"`
+	AmountDue         *int32  `json:"amountDue" validate:"omitempty,min=0"`
+	AmountPaid        *int32  `json:"amountPaid" validate:"omitempty,min=0"`
+	ExternalReference *string `json:"externalReference" validate:"omitempty,max=100"`
+}
+
+type Response struct {
+	ResponseID        string     `json:"responseId"`
+	Reference         string     `json:"reference"`
+	AmountDue         int32      `json:"amountDue"`
+	AmountPaid        int32      `json:"amountPaid"`
+	Status            string     `json:"status"`
+	Provider          string     `json:"provider"`
+	ExternalReference string     `json:"externalReference"`
+	PaidAt            *time.Time `json:"paidAt,omitempty"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+}
+
+type ReportRowResponse struct {
+	ResponseID        string     `json:"responseId"`
+	SubmittedBy       string     `json:"submittedBy"`
+	Name              string     `json:"name"`
+	Username          string     `json:"username"`
+	Reference         string     `json:"reference"`
+	AmountDue         int32      `json:"amountDue"`
+	AmountPaid        int32      `json:"amountPaid"`
+	Status            string     `json:"status"`
+	Provider          string     `json:"provider"`
+	ExternalReference string     `json:"externalReference"`
+	PaidAt            *time.Time `json:"paidAt,omitempty"`
+	Discrepancy       string     `json:"discrepancy,omitempty"`
+}
+
+type ReportResponse struct {
+	Currency    string                `json:"currency"`
+	AmountDue   int64                 `json:"amountDue"`
+	AmountPaid  int64                 `json:"amountPaid"`
+	Outstanding int64                 `json:"outstanding"`
+	Counts      map[PaymentStatus]int `json:"counts"`
+	Rows        []ReportRowResponse   `json:"rows"`
+}
+
+func ToSettingsResponse(settings FormPaymentSetting) SettingsResponse {
+	return SettingsResponse{
+		FormID:    settings.FormID.String(),
+		AmountDue: settings.AmountDue,
+		Currency:  settings.Currency,
+		UpdatedAt: settings.UpdatedAt.Time,
+	}
+}
+
+func ToResponse(payment ResponsePayment) Response {
+	response := Response{
+		ResponseID:        payment.ResponseID.String(),
+		Reference:         payment.Reference,
+		AmountDue:         payment.AmountDue,
+		AmountPaid:        payment.AmountPaid,
+		Status:            string(payment.Status),
+		Provider:          payment.Provider,
+		ExternalReference: payment.ExternalReference,
+		UpdatedAt:         payment.UpdatedAt.Time,
+	}
+	if payment.PaidAt.Valid {
+		response.PaidAt = &payment.PaidAt.Time
+	}
+	return response
+}
+
+func ToReportResponse(report Report) ReportResponse {
+	rows := make([]ReportRowResponse, len(report.Rows))
+	for i, row := range report.Rows {
+		rows[i] = ReportRowResponse{
+			ResponseID:        row.ResponseID.String(),
+			SubmittedBy:       row.SubmittedBy.String(),
+			Name:              row.Name,
+			Username:          row.Username,
+			Reference:         row.Reference,
+			AmountDue:         row.AmountDue,
+			AmountPaid:        row.AmountPaid,
+			Status:            string(row.Status),
+			Provider:          row.Provider,
+			ExternalReference: row.ExternalReference,
+			PaidAt:            row.PaidAt,
+			Discrepancy:       row.Discrepancy,
+		}
+	}
+	return ReportResponse{
+		Currency:    report.Currency,
+		AmountDue:   report.AmountDue,
+		AmountPaid:  report.AmountPaid,
+		Outstanding: report.Outstanding,
+		Counts:      report.Counts,
+		Rows:        rows,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store     Store
+	providers map[string]Provider
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	providers []Provider,
+) *Handler {
+	byName := make(map[string]Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("payment/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		providers:     byName,
+	}
+}
+
+func (h *Handler) GetSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetSettingsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	settings, err := h.store.GetSettings(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToSettingsResponse(settings))
+}
+
+func (h *Handler) SetSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetSettingsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req SettingsRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	settings, err := h.store.SetSettings(traceCtx, formID, req.AmountDue, req.Currency, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToSettingsResponse(settings))
+}
+
+func (h *Handler) DeleteSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteSettingsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.DeleteSettings(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responseID, err := internal.ParseUUID(r.PathValue("responseId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	payment, err := h.store.Get(traceCtx, formID, responseID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(payment))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responseID, err := internal.ParseUUID(r.PathValue("responseId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req UpdateRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	payment, err := h.store.Update(traceCtx, formID, responseID, Update{
+		Status:            PaymentStatus(req.Status),
+		AmountDue:         req.AmountDue,
+		AmountPaid:        req.AmountPaid,
+		ExternalReference: req.ExternalReference,
+	}, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(payment))
+}
+
+func (h *Handler) ReportHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ReportHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	report, err := h.store.Report(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToReportResponse(report))
+}
+
+// WebhookHandler receives the payment results of a provider; the callback is
+// authenticated by its signature. Failing to record it answers 500, so the provider
+// retries later.
+func (h *Handler) WebhookHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "WebhookHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	provider, ok := h.providers[r.PathValue("provider")]
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrPaymentProviderNotFound, logger)
+		return
+	}
+
+	notification, err := provider.Parse(r)
+	if err != nil {
+		logger.Warn("Rejected payment notification", zap.String("provider", provider.Name()), zap.Error(err))
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Notify(traceCtx, provider.Name(), notification)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = provider.Acknowledge(w)
+	if err != nil {
+		logger.Error("failed to acknowledge payment notification", zap.Error(err))
+	}
+}
@@ -0,0 +1,122 @@
+package payment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// NewebPay reads the NotifyURL callbacks of NewebPay (藍新), whose TradeInfo is AES
+// encrypted and signed by TradeSha
+type NewebPay struct {
+	merchantID string
+	hashKey    string
+	hashIV     string
+}
+
+func NewNewebPay(merchantID, hashKey, hashIV string) *NewebPay {
+	return &NewebPay{merchantID: merchantID, hashKey: hashKey, hashIV: hashIV}
+}
+
+func (p *NewebPay) Name() string {
+	return "newebpay"
+}
+
+func (p *NewebPay) tradeSha(tradeInfo string) string {
+	sum := sha256.Sum256([]byte("HashKey=" + p.hashKey + "&" + tradeInfo + "&HashIV=" + p.hashIV))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// decrypt opens the hex encoded, PKCS#7 padded AES-256-CBC TradeInfo
+func (p *NewebPay) decrypt(tradeInfo string) ([]byte, error) {
+	data, err := hex.DecodeString(tradeInfo)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher([]byte(p.hashKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("TradeInfo is not a whole number of blocks")
+	}
+
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, []byte(p.hashIV)).CryptBlocks(plain, data)
+
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("invalid padding")
+	}
+	return plain[:len(plain)-padding], nil
+}
+
+type newebPayTradeInfo struct {
+	Status  string `json:"Status"`
+	Message string `json:"Message"`
+	Result  struct {
+		MerchantID      string `json:"MerchantID"`
+		Amt             int32  `json:"Amt"`
+		TradeNo         string `json:"TradeNo"`
+		MerchantOrderNo string `json:"MerchantOrderNo"`
+		PayTime         string `json:"PayTime"`
+	} `json:"Result"`
+}
+
+func (p *NewebPay) Parse(r *http.Request) (Notification, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return Notification{}, fmt.Errorf("%w: %v", internal.ErrPaymentNotificationInvalid, err)
+	}
+	tradeInfo := r.PostForm.Get("TradeInfo")
+
+	signature := strings.ToUpper(r.PostForm.Get("TradeSha"))
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(p.tradeSha(tradeInfo))) != 1 {
+		return Notification{}, fmt.Errorf("%w: TradeSha does not match", internal.ErrPaymentNotificationInvalid)
+	}
+
+	plain, err := p.decrypt(tradeInfo)
+	if err != nil {
+		return Notification{}, fmt.Errorf("%w: %v", internal.ErrPaymentNotificationInvalid, err)
+	}
+
+	var info newebPayTradeInfo
+	err = json.Unmarshal(plain, &info)
+	if err != nil {
+		return Notification{}, fmt.Errorf("%w: %v", internal.ErrPaymentNotificationInvalid, err)
+	}
+	if info.Result.MerchantID != p.merchantID {
+		return Notification{}, fmt.Errorf("%w: unknown merchant", internal.ErrPaymentNotificationInvalid)
+	}
+
+	notification := Notification{
+		Reference:         info.Result.MerchantOrderNo,
+		ExternalReference: info.Result.TradeNo,
+		Status:            PaymentStatusFailed,
+		Amount:            info.Result.Amt,
+	}
+	if info.Status == "SUCCESS" {
+		notification.Status = PaymentStatusPaid
+		notification.PaidAt, err = time.ParseInLocation("2006-01-02 15:04:05", info.Result.PayTime, taipei)
+		if err != nil {
+			notification.PaidAt = time.Now()
+		}
+	}
+	return notification, nil
+}
+
+// Acknowledge answers 200; NewebPay only looks at the status code
+func (p *NewebPay) Acknowledge(w http.ResponseWriter) error {
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
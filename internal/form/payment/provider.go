@@ -0,0 +1,77 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Notification is a payment result reported by a provider, whose signature was verified
+type Notification struct {
+	// Reference is the order number the payment was started with
+	Reference         string
+	ExternalReference string
+	Status            PaymentStatus
+	Amount            int32
+	PaidAt            time.Time
+	// Simulated is set for the test payments a merchant triggers from the provider's
+	// dashboard; they are recorded but never change a payment
+	Simulated bool
+}
+
+// Provider reads the callbacks of a payment provider
+type Provider interface {
+	Name() string
+	// Parse verifies the signature of a callback and reads the result it reports,
+	// failing with internal.ErrPaymentNotificationInvalid
+	Parse(r *http.Request) (Notification, error)
+	// Acknowledge answers a callback the way the provider expects, so it stops retrying
+	Acknowledge(w http.ResponseWriter) error
+}
+
+// Config holds the merchant credentials of the payment providers, found in their
+// merchant dashboards. A provider is disabled when left empty.
+type Config struct {
+	ECPayMerchantID    string `yaml:"ecpay_merchant_id"    envconfig:"PAYMENT_ECPAY_MERCHANT_ID"`
+	ECPayHashKey       string `yaml:"ecpay_hash_key"       envconfig:"PAYMENT_ECPAY_HASH_KEY"`
+	ECPayHashIV        string `yaml:"ecpay_hash_iv"        envconfig:"PAYMENT_ECPAY_HASH_IV"`
+	NewebPayMerchantID string `yaml:"newebpay_merchant_id" envconfig:"PAYMENT_NEWEBPAY_MERCHANT_ID"`
+	NewebPayHashKey    string `yaml:"newebpay_hash_key"    envconfig:"PAYMENT_NEWEBPAY_HASH_KEY"`
+	NewebPayHashIV     string `yaml:"newebpay_hash_iv"     envconfig:"PAYMENT_NEWEBPAY_HASH_IV"`
+}
+
+func (c *Config) Validate() error {
+	if !allOrNone(c.ECPayMerchantID, c.ECPayHashKey, c.ECPayHashIV) {
+		return fmt.Errorf("payment ecpay_merchant_id, ecpay_hash_key and ecpay_hash_iv must be set together")
+	}
+	if !allOrNone(c.NewebPayMerchantID, c.NewebPayHashKey, c.NewebPayHashIV) {
+		return fmt.Errorf("payment newebpay_merchant_id, newebpay_hash_key and newebpay_hash_iv must be set together")
+	}
+	// NewebPay encrypts its callbacks with AES-256-CBC, keyed by the hash key and IV
+	if c.NewebPayHashKey != "" && (len(c.NewebPayHashKey) != 32 || len(c.NewebPayHashIV) != 16) {
+		return fmt.Errorf("payment newebpay_hash_key must be 32 characters and newebpay_hash_iv 16")
+	}
+	return nil
+}
+
+func allOrNone(values ...string) bool {
+	set := 0
+	for _, value := range values {
+		if value != "" {
+			set++
+		}
+	}
+	return set == 0 || set == len(values)
+}
+
+// NewProviders returns the providers the config has credentials for
+func NewProviders(c Config) []Provider {
+	var providers []Provider
+	if c.ECPayMerchantID != "" {
+		providers = append(providers, NewECPay(c.ECPayMerchantID, c.ECPayHashKey, c.ECPayHashIV))
+	}
+	if c.NewebPayMerchantID != "" {
+		providers = append(providers, NewNewebPay(c.NewebPayMerchantID, c.NewebPayHashKey, c.NewebPayHashIV))
+	}
+	return providers
+}
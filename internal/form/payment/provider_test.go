@@ -0,0 +1,155 @@
+package payment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func callback(values url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/payments/webhooks/test", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestECPay_Parse(t *testing.T) {
+	t.Parallel()
+
+	provider := NewECPay("3002607", "pwFHCqoQZGmho4w6", "EkRm7iFT261dpevs")
+	other := NewECPay("3002607", "5294y06JbISpM5x9", "v77hoKGq4kWxNNIS")
+
+	paid := func() url.Values {
+		return url.Values{
+			"MerchantID":      {"3002607"},
+			"MerchantTradeNo": {"REF123"},
+			"TradeNo":         {"2410141200001"},
+			"TradeAmt":        {"500"},
+			"RtnCode":         {"1"},
+			"PaymentDate":     {"2024/10/14 12:00:00"},
+			"SimulatePaid":    {"0"},
+		}
+	}
+
+	type testCase struct {
+		name        string
+		values      func() url.Values
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{
+			name: "Signed by the merchant",
+			values: func() url.Values {
+				values := paid()
+				values.Set("CheckMacValue", provider.checkMacValue(values))
+				return values
+			},
+		},
+		{
+			name: "Signed with another hash key",
+			values: func() url.Values {
+				values := paid()
+				values.Set("CheckMacValue", other.checkMacValue(values))
+				return values
+			},
+			expectedErr: internal.ErrPaymentNotificationInvalid,
+		},
+		{
+			name: "Amount changed after signing",
+			values: func() url.Values {
+				values := paid()
+				values.Set("CheckMacValue", provider.checkMacValue(values))
+				values.Set("TradeAmt", "1")
+				return values
+			},
+			expectedErr: internal.ErrPaymentNotificationInvalid,
+		},
+		{
+			name:        "Unsigned",
+			values:      paid,
+			expectedErr: internal.ErrPaymentNotificationInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			notification, err := provider.Parse(callback(tc.values()))
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "REF123", notification.Reference)
+			require.Equal(t, PaymentStatusPaid, notification.Status)
+			require.Equal(t, int32(500), notification.Amount)
+		})
+	}
+}
+
+// encrypt seals the TradeInfo of a NewebPay callback the way NewebPay does
+func encrypt(t *testing.T, key string, iv string, plain string) string {
+	block, err := aes.NewCipher([]byte(key))
+	require.NoError(t, err)
+
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	data := append([]byte(plain), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, []byte(iv)).CryptBlocks(data, data)
+	return hex.EncodeToString(data)
+}
+
+func TestNewebPay_Parse(t *testing.T) {
+	t.Parallel()
+
+	provider := NewNewebPay("MS127874575", "Fs5cX1TGqYM2PpdbE14a9H83YQSQF5jn", "C6AcmfqJILwgnhIP")
+	other := NewNewebPay("MS127874575", "9hAAiYcC3BvsP16T4fPZGCxW7rhcJFEX", "PuBfsh4T7AbjG8jM")
+
+	tradeInfo := encrypt(t, provider.hashKey, provider.hashIV,
+		`{"Status":"SUCCESS","Result":{"MerchantID":"MS127874575","Amt":500,"TradeNo":"24101412000012345","MerchantOrderNo":"REF123","PayTime":"2024-10-14 12:00:00"}}`)
+	tampered := encrypt(t, provider.hashKey, provider.hashIV,
+		`{"Status":"SUCCESS","Result":{"MerchantID":"MS127874575","Amt":1,"TradeNo":"24101412000012345","MerchantOrderNo":"REF123","PayTime":"2024-10-14 12:00:00"}}`)
+
+	type testCase struct {
+		name        string
+		tradeInfo   string
+		tradeSha    string
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{name: "Signed by the merchant", tradeInfo: tradeInfo, tradeSha: provider.tradeSha(tradeInfo)},
+		{name: "Signed with another hash key", tradeInfo: tradeInfo, tradeSha: other.tradeSha(tradeInfo), expectedErr: internal.ErrPaymentNotificationInvalid},
+		{name: "TradeInfo replaced after signing", tradeInfo: tampered, tradeSha: provider.tradeSha(tradeInfo), expectedErr: internal.ErrPaymentNotificationInvalid},
+		{name: "Unsigned", tradeInfo: tradeInfo, expectedErr: internal.ErrPaymentNotificationInvalid},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			notification, err := provider.Parse(callback(url.Values{
+				"MerchantID": {"MS127874575"},
+				"TradeInfo":  {tc.tradeInfo},
+				"TradeSha":   {tc.tradeSha},
+			}))
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "REF123", notification.Reference)
+			require.Equal(t, PaymentStatusPaid, notification.Status)
+			require.Equal(t, int32(500), notification.Amount)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
+
+-- name: GetSettings :one
+SELECT * FROM form_payment_settings
+WHERE form_id = @form_id;
+
+-- name: UpsertSettings :one
+INSERT INTO form_payment_settings (form_id, amount_due, currency, updated_by)
+VALUES (@form_id, @amount_due, @currency, @updated_by)
+ON CONFLICT (form_id) DO UPDATE
+SET amount_due = EXCLUDED.amount_due,
+    currency = EXCLUDED.currency,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteSettings :execrows
+DELETE FROM form_payment_settings
+WHERE form_id = @form_id;
+
+-- name: GetResponse :one
+SELECT id, submitted_by FROM form_responses
+WHERE id = @response_id AND form_id = @form_id AND NOT is_test;
+
+-- name: Get :one
+SELECT * FROM response_payments
+WHERE response_id = @response_id;
+
+-- name: GetByReference :one
+SELECT * FROM response_payments
+WHERE reference = @reference;
+
+-- name: Create :one
+-- Returns no row when the response already has a payment
+INSERT INTO response_payments (response_id, form_id, reference, amount_due)
+VALUES (@response_id, @form_id, @reference, @amount_due)
+ON CONFLICT (response_id) DO NOTHING
+RETURNING *;
+
+-- name: Update :one
+UPDATE response_payments
+SET amount_due = @amount_due,
+    amount_paid = @amount_paid,
+    status = @status,
+    provider = @provider,
+    external_reference = @external_reference,
+    paid_at = @paid_at,
+    updated_by = @updated_by,
+    updated_at = now()
+WHERE response_id = @response_id
+RETURNING *;
+
+-- name: CreateNotification :one
+INSERT INTO payment_notifications (provider, reference, response_id, status, amount, external_reference, simulated)
+VALUES (@provider, @reference, @response_id, @status, @amount, @external_reference, @simulated)
+RETURNING *;
+
+-- name: ListReport :many
+SELECT r.id AS response_id, r.submitted_by, u.name, u.username, p.reference, p.amount_due, p.amount_paid, p.status, p.provider, p.external_reference, p.paid_at FROM form_responses r
+LEFT JOIN users u ON u.id = r.submitted_by
+LEFT JOIN response_payments p ON p.response_id = r.id
+WHERE r.form_id = @form_id AND NOT r.is_test
+ORDER BY r.created_at ASC;
\ No newline at end of file
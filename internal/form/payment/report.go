@@ -0,0 +1,121 @@
+package payment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// statuses lists every payment status, in the order the counts show them
+var statuses = []PaymentStatus{
+	PaymentStatusUnpaid,
+	PaymentStatusPending,
+	PaymentStatusPaid,
+	PaymentStatusFailed,
+	PaymentStatusRefunded,
+	PaymentStatusWaived,
+}
+
+// Discrepancies flagged on the rows of a report
+const (
+	DiscrepancyAmountMismatch = "amount_mismatch"
+	DiscrepancyUnsettled      = "paid_not_settled"
+)
+
+// ReportRow is the payment of one response. Responses that never opened their payment
+// are listed as unpaid with the amount the form asks for, without a reference.
+type ReportRow struct {
+	ResponseID        uuid.UUID
+	SubmittedBy       uuid.UUID
+	Name              string
+	Username          string
+	Reference         string
+	AmountDue         int32
+	AmountPaid        int32
+	Status            PaymentStatus
+	Provider          string
+	ExternalReference string
+	PaidAt            *time.Time
+	Discrepancy       string
+}
+
+// Report totals the payments of a form, refunded and waived ones left out. Outstanding is what is still owed by the
+// payments not settled.
+type Report struct {
+	Currency    string
+	AmountDue   int64
+	AmountPaid  int64
+	Outstanding int64
+	Counts      map[PaymentStatus]int
+	Rows        []ReportRow
+}
+
+// settled tells whether a payment is done with, whatever was paid
+func settled(status PaymentStatus) bool {
+	return status == PaymentStatusPaid || status == PaymentStatusRefunded || status == PaymentStatusWaived
+}
+
+// discrepancy flags a paid payment whose amount differs from the amount due, and money
+// received on a payment not marked paid
+func discrepancy(row ReportRow) string {
+	switch {
+	case row.Status == PaymentStatusPaid && row.AmountPaid != row.AmountDue:
+		return DiscrepancyAmountMismatch
+	case !settled(row.Status) && row.AmountPaid > 0:
+		return DiscrepancyUnsettled
+	}
+	return ""
+}
+
+// buildReport sums up the rows; responses without a payment are left out when the form
+// does not ask for one, amountDue being nil
+func buildReport(currency string, amountDue *int32, rows []ListReportRow) Report {
+	report := Report{
+		Currency: currency,
+		Counts:   make(map[PaymentStatus]int, len(statuses)),
+		Rows:     make([]ReportRow, 0, len(rows)),
+	}
+	for _, status := range statuses {
+		report.Counts[status] = 0
+	}
+
+	for _, row := range rows {
+		if !row.Reference.Valid && amountDue == nil {
+			continue
+		}
+
+		reportRow := ReportRow{
+			ResponseID:  row.ResponseID,
+			SubmittedBy: row.SubmittedBy,
+			Name:        row.Name.String,
+			Username:    row.Username.String,
+			Status:      PaymentStatusUnpaid,
+		}
+		if row.Reference.Valid {
+			reportRow.Reference = row.Reference.String
+			reportRow.AmountDue = row.AmountDue.Int32
+			reportRow.AmountPaid = row.AmountPaid.Int32
+			reportRow.Status = row.Status.PaymentStatus
+			reportRow.Provider = row.Provider.String
+			reportRow.ExternalReference = row.ExternalReference.String
+			if row.PaidAt.Valid {
+				reportRow.PaidAt = &row.PaidAt.Time
+			}
+		} else {
+			reportRow.AmountDue = *amountDue
+		}
+		reportRow.Discrepancy = discrepancy(reportRow)
+
+		report.Counts[reportRow.Status]++
+		// Refunded money was given back, and waived payments were never owed
+		if reportRow.Status != PaymentStatusRefunded && reportRow.Status != PaymentStatusWaived {
+			report.AmountDue += int64(reportRow.AmountDue)
+			report.AmountPaid += int64(reportRow.AmountPaid)
+		}
+		if !settled(reportRow.Status) && reportRow.AmountDue > reportRow.AmountPaid {
+			report.Outstanding += int64(reportRow.AmountDue - reportRow.AmountPaid)
+		}
+		report.Rows = append(report.Rows, reportRow)
+	}
+	return report
+}
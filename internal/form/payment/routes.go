@@ -0,0 +1,17 @@
+package payment
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the payment settings of forms, the payments of their responses, the
+// reconciliation report and the callbacks of the payment providers
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/payment", route.Authenticated, route.PermissionOrgAdmin, h.GetSettingsHandler)
+	r.Handle("PUT /forms/{id}/payment", route.Authenticated, route.PermissionOrgAdmin, h.SetSettingsHandler)
+	r.Handle("DELETE /forms/{id}/payment", route.Authenticated, route.PermissionOrgAdmin, h.DeleteSettingsHandler)
+	r.Handle("GET /forms/{id}/payments/report", route.Authenticated, route.PermissionOrgAdmin, h.ReportHandler)
+	r.Handle("GET /forms/{formId}/responses/{responseId}/payment", route.Authenticated, route.PermissionSelf, h.GetHandler)
+	r.Handle("PUT /forms/{formId}/responses/{responseId}/payment", route.Authenticated, route.PermissionOrgAdmin, h.UpdateHandler)
+	r.Handle("POST /payments/webhooks/{provider}", route.Public, route.PermissionNone, h.WebhookHandler)
+}
@@ -0,0 +1,53 @@
+CREATE TYPE payment_status AS ENUM(
+    'unpaid',
+    'pending',
+    'paid',
+    'failed',
+    'refunded',
+    'waived'
+);
+
+CREATE TABLE IF NOT EXISTS form_payment_settings (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    amount_due INT NOT NULL CHECK (amount_due >= 0),
+    currency TEXT NOT NULL DEFAULT 'TWD',
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- The reference is the order number given to the payment provider, which its
+-- callbacks are matched on; the external reference is the provider's own trade number
+-- or a receipt number entered by hand.
+CREATE TABLE IF NOT EXISTS response_payments (
+    response_id UUID PRIMARY KEY REFERENCES form_responses(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    reference TEXT NOT NULL UNIQUE,
+    amount_due INT NOT NULL CHECK (amount_due >= 0),
+    amount_paid INT NOT NULL DEFAULT 0 CHECK (amount_paid >= 0),
+    status payment_status NOT NULL DEFAULT 'unpaid',
+    provider TEXT NOT NULL DEFAULT '',
+    external_reference TEXT NOT NULL DEFAULT '',
+    paid_at TIMESTAMPTZ DEFAULT NULL,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_response_payments_form_id ON response_payments(form_id);
+
+-- Every callback received, kept for reconciliation; response_id is null when the
+-- reference matched no payment
+CREATE TABLE IF NOT EXISTS payment_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider TEXT NOT NULL,
+    reference TEXT NOT NULL,
+    response_id UUID REFERENCES form_responses(id) ON DELETE SET NULL,
+    status payment_status NOT NULL,
+    amount INT NOT NULL,
+    external_reference TEXT NOT NULL DEFAULT '',
+    simulated BOOLEAN NOT NULL DEFAULT false,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_notifications_reference ON payment_notifications(reference);
\ No newline at end of file
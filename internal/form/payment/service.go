@@ -0,0 +1,424 @@
+package payment
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"crypto/rand"
+	"errors"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DefaultCurrency is the currency of the amounts of a form unless set otherwise
+const DefaultCurrency = "TWD"
+
+// referenceAlphabet and referenceLength fit the order numbers of both providers, at most
+// 20 letters and digits for ECPay
+const (
+	referenceAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	referenceLength   = 20
+)
+
+type Querier interface {
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+	GetSettings(ctx context.Context, formID uuid.UUID) (FormPaymentSetting, error)
+	UpsertSettings(ctx context.Context, arg UpsertSettingsParams) (FormPaymentSetting, error)
+	DeleteSettings(ctx context.Context, formID uuid.UUID) (int64, error)
+	GetResponse(ctx context.Context, arg GetResponseParams) (GetResponseRow, error)
+	Get(ctx context.Context, responseID uuid.UUID) (ResponsePayment, error)
+	GetByReference(ctx context.Context, reference string) (ResponsePayment, error)
+	Create(ctx context.Context, arg CreateParams) (ResponsePayment, error)
+	Update(ctx context.Context, arg UpdateParams) (ResponsePayment, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (PaymentNotification, error)
+	ListReport(ctx context.Context, formID uuid.UUID) ([]ListReportRow, error)
+}
+
+// Update is a payment status set by hand, e.g. for cash paid at the door. AmountDue and
+// AmountPaid are left as they are when nil, except that marking a payment paid without
+// an amount takes the amount due as paid.
+type Update struct {
+	Status            PaymentStatus
+	AmountDue         *int32
+	AmountPaid        *int32
+	ExternalReference *string
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("payment/service"),
+	}
+}
+
+// newReference generates the order number a payment is started and matched with
+func newReference() string {
+	random := make([]byte, referenceLength)
+	_, _ = rand.Read(random)
+	for i, b := range random {
+		random[i] = referenceAlphabet[int(b)%len(referenceAlphabet)]
+	}
+	return string(random)
+}
+
+// requireAdmin allows the owner of the organization of the form only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func (s *Service) getSettings(ctx context.Context, logger *zap.Logger, formID uuid.UUID) (FormPaymentSetting, error) {
+	settings, err := s.queries.GetSettings(ctx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return FormPaymentSetting{}, internal.ErrPaymentSettingsNotFound
+		}
+		return FormPaymentSetting{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_payment_settings", "form_id", formID.String(), logger, "get payment settings")
+	}
+	return settings, nil
+}
+
+func (s *Service) GetSettings(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (FormPaymentSetting, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetSettings")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return FormPaymentSetting{}, err
+	}
+
+	settings, err := s.getSettings(traceCtx, logger, formID)
+	if err != nil {
+		span.RecordError(err)
+		return FormPaymentSetting{}, err
+	}
+	return settings, nil
+}
+
+// SetSettings makes the respondents of the form owe the amount. Payments created before
+// keep the amount they were created with.
+func (s *Service) SetSettings(ctx context.Context, formID uuid.UUID, amountDue int32, currency string, userID uuid.UUID) (FormPaymentSetting, error) {
+	traceCtx, span := s.tracer.Start(ctx, "SetSettings")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return FormPaymentSetting{}, err
+	}
+
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	settings, err := s.queries.UpsertSettings(traceCtx, UpsertSettingsParams{
+		FormID:    formID,
+		AmountDue: amountDue,
+		Currency:  currency,
+		UpdatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_payment_settings", "form_id", formID.String(), logger, "set payment settings")
+		span.RecordError(err)
+		return FormPaymentSetting{}, err
+	}
+	return settings, nil
+}
+
+// DeleteSettings stops asking new respondents for payment; existing payments are kept
+func (s *Service) DeleteSettings(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "DeleteSettings")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.queries.DeleteSettings(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_payment_settings", "form_id", formID.String(), logger, "delete payment settings")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		err = internal.ErrPaymentSettingsNotFound
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// payment returns the payment of the response, creating it with the amount due of the
+// form the first time; the form must ask for payment then
+func (s *Service) payment(ctx context.Context, logger *zap.Logger, formID uuid.UUID, responseID uuid.UUID) (ResponsePayment, error) {
+	payment, err := s.queries.Get(ctx, responseID)
+	if err == nil {
+		return payment, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return ResponsePayment{}, databaseutil.WrapDBErrorWithKeyValue(err, "response_payments", "response_id", responseID.String(), logger, "get payment")
+	}
+
+	settings, err := s.queries.GetSettings(ctx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ResponsePayment{}, internal.ErrPaymentNotFound
+		}
+		return ResponsePayment{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_payment_settings", "form_id", formID.String(), logger, "get payment settings")
+	}
+
+	payment, err = s.queries.Create(ctx, CreateParams{
+		ResponseID: responseID,
+		FormID:     formID,
+		Reference:  newReference(),
+		AmountDue:  settings.AmountDue,
+	})
+	if err == nil {
+		return payment, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return ResponsePayment{}, databaseutil.WrapDBErrorWithKeyValue(err, "response_payments", "response_id", responseID.String(), logger, "create payment")
+	}
+
+	// Created by a concurrent request
+	payment, err = s.queries.Get(ctx, responseID)
+	if err != nil {
+		return ResponsePayment{}, databaseutil.WrapDBErrorWithKeyValue(err, "response_payments", "response_id", responseID.String(), logger, "get payment")
+	}
+	return payment, nil
+}
+
+func (s *Service) getResponse(ctx context.Context, logger *zap.Logger, formID uuid.UUID, responseID uuid.UUID) (GetResponseRow, error) {
+	response, err := s.queries.GetResponse(ctx, GetResponseParams{ResponseID: responseID, FormID: formID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return GetResponseRow{}, internal.ErrResponseNotFound
+		}
+		return GetResponseRow{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "get response")
+	}
+	return response, nil
+}
+
+// Get returns the payment of a response, with the reference to start paying with. Only
+// the respondent and the organization admins may get it.
+func (s *Service) Get(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID) (ResponsePayment, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	response, err := s.getResponse(traceCtx, logger, formID, responseID)
+	if err != nil {
+		span.RecordError(err)
+		return ResponsePayment{}, err
+	}
+
+	if response.SubmittedBy != userID {
+		err = s.requireAdmin(traceCtx, logger, formID, userID)
+		if err != nil {
+			span.RecordError(err)
+			return ResponsePayment{}, err
+		}
+	}
+
+	payment, err := s.payment(traceCtx, logger, formID, response.ID)
+	if err != nil {
+		span.RecordError(err)
+		return ResponsePayment{}, err
+	}
+	return payment, nil
+}
+
+// Update sets the status of a payment by hand. A manual update is recorded with the
+// provider left empty.
+func (s *Service) Update(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, update Update, userID uuid.UUID) (ResponsePayment, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return ResponsePayment{}, err
+	}
+
+	response, err := s.getResponse(traceCtx, logger, formID, responseID)
+	if err != nil {
+		span.RecordError(err)
+		return ResponsePayment{}, err
+	}
+
+	payment, err := s.payment(traceCtx, logger, formID, response.ID)
+	if err != nil {
+		span.RecordError(err)
+		return ResponsePayment{}, err
+	}
+
+	params := UpdateParams{
+		ResponseID:        payment.ResponseID,
+		AmountDue:         payment.AmountDue,
+		AmountPaid:        payment.AmountPaid,
+		Status:            update.Status,
+		Provider:          "",
+		ExternalReference: payment.ExternalReference,
+		PaidAt:            payment.PaidAt,
+		UpdatedBy:         pgtype.UUID{Bytes: userID, Valid: true},
+	}
+	if update.AmountDue != nil {
+		params.AmountDue = *update.AmountDue
+	}
+	if update.ExternalReference != nil {
+		params.ExternalReference = *update.ExternalReference
+	}
+	switch {
+	case update.AmountPaid != nil:
+		params.AmountPaid = *update.AmountPaid
+	case update.Status == PaymentStatusPaid:
+		params.AmountPaid = params.AmountDue
+	}
+	if update.Status == PaymentStatusPaid && !params.PaidAt.Valid {
+		params.PaidAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	}
+
+	payment, err = s.queries.Update(traceCtx, params)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_payments", "response_id", response.ID.String(), logger, "update payment")
+		span.RecordError(err)
+		return ResponsePayment{}, err
+	}
+
+	logger.Info("Updated payment by hand", zap.String("response_id", response.ID.String()), zap.String("status", string(payment.Status)))
+	return payment, nil
+}
+
+// Notify applies a verified provider callback to the payment it references. Every
+// callback is logged, including the ones matching no payment, which are then ignored
+// so the provider stops retrying them. Once paid, a payment is not set back by a late
+// callback of an earlier failed attempt.
+func (s *Service) Notify(ctx context.Context, provider string, notification Notification) error {
+	traceCtx, span := s.tracer.Start(ctx, "Notify")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	payment, err := s.queries.GetByReference(traceCtx, notification.Reference)
+	found := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_payments", "reference", notification.Reference, logger, "get payment")
+		span.RecordError(err)
+		return err
+	}
+
+	responseID := pgtype.UUID{}
+	if found {
+		responseID = pgtype.UUID{Bytes: payment.ResponseID, Valid: true}
+	}
+	_, err = s.queries.CreateNotification(traceCtx, CreateNotificationParams{
+		Provider:          provider,
+		Reference:         notification.Reference,
+		ResponseID:        responseID,
+		Status:            notification.Status,
+		Amount:            notification.Amount,
+		ExternalReference: notification.ExternalReference,
+		Simulated:         notification.Simulated,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "payment_notifications", "reference", notification.Reference, logger, "record payment notification")
+		span.RecordError(err)
+		return err
+	}
+
+	if !found {
+		logger.Warn("Received payment notification for unknown reference", zap.String("provider", provider), zap.String("reference", notification.Reference))
+		return nil
+	}
+	if notification.Simulated || (payment.Status == PaymentStatusPaid && notification.Status != PaymentStatusPaid) {
+		return nil
+	}
+
+	params := UpdateParams{
+		ResponseID:        payment.ResponseID,
+		AmountDue:         payment.AmountDue,
+		AmountPaid:        payment.AmountPaid,
+		Status:            notification.Status,
+		Provider:          provider,
+		ExternalReference: notification.ExternalReference,
+		PaidAt:            payment.PaidAt,
+	}
+	if notification.Status == PaymentStatusPaid {
+		params.AmountPaid = notification.Amount
+		params.PaidAt = pgtype.Timestamptz{Time: notification.PaidAt, Valid: true}
+	}
+
+	payment, err = s.queries.Update(traceCtx, params)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_payments", "response_id", payment.ResponseID.String(), logger, "update payment")
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Updated payment from provider", zap.String("provider", provider), zap.String("response_id", payment.ResponseID.String()), zap.String("status", string(payment.Status)))
+	return nil
+}
+
+// Report reconciles the payments of the form for its treasurer
+func (s *Service) Report(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Report, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Report")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Report{}, err
+	}
+
+	settings, err := s.queries.GetSettings(traceCtx, formID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_payment_settings", "form_id", formID.String(), logger, "get payment settings")
+		span.RecordError(err)
+		return Report{}, err
+	}
+	var amountDue *int32
+	currency := DefaultCurrency
+	if err == nil {
+		amountDue = &settings.AmountDue
+		currency = settings.Currency
+	}
+
+	rows, err := s.queries.ListReport(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response_payments", "form_id", formID.String(), logger, "list payments")
+		span.RecordError(err)
+		return Report{}, err
+	}
+
+	return buildReport(currency, amountDue, rows), nil
+}
@@ -0,0 +1,196 @@
+package payment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+// fakeQuerier keeps one payment in memory and records the notifications it receives
+type fakeQuerier struct {
+	Querier
+	payment       *ResponsePayment
+	notifications []CreateNotificationParams
+	settings      *FormPaymentSetting
+	report        []ListReportRow
+}
+
+func (q *fakeQuerier) GetByReference(_ context.Context, reference string) (ResponsePayment, error) {
+	if q.payment == nil || q.payment.Reference != reference {
+		return ResponsePayment{}, pgx.ErrNoRows
+	}
+	return *q.payment, nil
+}
+
+func (q *fakeQuerier) CreateNotification(_ context.Context, arg CreateNotificationParams) (PaymentNotification, error) {
+	q.notifications = append(q.notifications, arg)
+	return PaymentNotification{ID: uuid.New()}, nil
+}
+
+func (q *fakeQuerier) Update(_ context.Context, arg UpdateParams) (ResponsePayment, error) {
+	q.payment.AmountDue = arg.AmountDue
+	q.payment.AmountPaid = arg.AmountPaid
+	q.payment.Status = arg.Status
+	q.payment.Provider = arg.Provider
+	q.payment.ExternalReference = arg.ExternalReference
+	q.payment.PaidAt = arg.PaidAt
+	return *q.payment, nil
+}
+
+func (q *fakeQuerier) IsFormOrgAdmin(context.Context, IsFormOrgAdminParams) (bool, error) {
+	return true, nil
+}
+
+func (q *fakeQuerier) GetSettings(context.Context, uuid.UUID) (FormPaymentSetting, error) {
+	if q.settings == nil {
+		return FormPaymentSetting{}, pgx.ErrNoRows
+	}
+	return *q.settings, nil
+}
+
+func (q *fakeQuerier) ListReport(context.Context, uuid.UUID) ([]ListReportRow, error) {
+	return q.report, nil
+}
+
+func TestService_NotifyReplayed(t *testing.T) {
+	t.Parallel()
+
+	paidAt := time.Date(2024, 10, 14, 12, 0, 0, 0, taipei)
+	paid := Notification{Reference: "REF123", ExternalReference: "T1", Status: PaymentStatusPaid, Amount: 500, PaidAt: paidAt}
+	failed := Notification{Reference: "REF123", ExternalReference: "T0", Status: PaymentStatusFailed, Amount: 500}
+
+	type testCase struct {
+		name               string
+		notifications      []Notification
+		expectedStatus     PaymentStatus
+		expectedAmountPaid int32
+		expectedReference  string
+	}
+
+	testCases := []testCase{
+		{
+			name:               "Paid callback delivered twice",
+			notifications:      []Notification{paid, paid},
+			expectedStatus:     PaymentStatusPaid,
+			expectedAmountPaid: 500,
+			expectedReference:  "T1",
+		},
+		{
+			name:               "Failed attempt replayed after the payment",
+			notifications:      []Notification{paid, failed},
+			expectedStatus:     PaymentStatusPaid,
+			expectedAmountPaid: 500,
+			expectedReference:  "T1",
+		},
+		{
+			name:           "Failed attempt delivered twice",
+			notifications:  []Notification{failed, failed},
+			expectedStatus: PaymentStatusFailed,
+			// Each attempt of the provider has its own trade number
+			expectedReference: "T0",
+		},
+		{
+			name: "Simulated payment",
+			notifications: []Notification{
+				{Reference: "REF123", Status: PaymentStatusPaid, Amount: 500, PaidAt: paidAt, Simulated: true},
+			},
+			expectedStatus: PaymentStatusPending,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			queries := &fakeQuerier{payment: &ResponsePayment{
+				ResponseID: uuid.New(),
+				Reference:  "REF123",
+				AmountDue:  500,
+				Status:     PaymentStatusPending,
+			}}
+			service := &Service{logger: zap.NewNop(), queries: queries, tracer: otel.Tracer("test")}
+
+			for _, notification := range tc.notifications {
+				err := service.Notify(context.Background(), "ecpay", notification)
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.expectedStatus, queries.payment.Status)
+			require.Equal(t, tc.expectedAmountPaid, queries.payment.AmountPaid)
+			require.Equal(t, tc.expectedReference, queries.payment.ExternalReference)
+			if tc.expectedStatus == PaymentStatusPaid {
+				require.True(t, queries.payment.PaidAt.Time.Equal(paidAt))
+			}
+			// Every delivery is kept, replays included
+			require.Len(t, queries.notifications, len(tc.notifications))
+		})
+	}
+}
+
+func TestService_NotifyUnknownReference(t *testing.T) {
+	t.Parallel()
+
+	queries := &fakeQuerier{}
+	service := &Service{logger: zap.NewNop(), queries: queries, tracer: otel.Tracer("test")}
+
+	err := service.Notify(context.Background(), "newebpay", Notification{Reference: "NOPE", Status: PaymentStatusPaid, Amount: 500})
+	require.NoError(t, err)
+	require.Len(t, queries.notifications, 1)
+	require.False(t, queries.notifications[0].ResponseID.Valid)
+}
+
+func TestService_ReportMismatch(t *testing.T) {
+	t.Parallel()
+
+	row := func(status PaymentStatus, amountPaid int32) ListReportRow {
+		return ListReportRow{
+			ResponseID:  uuid.New(),
+			SubmittedBy: uuid.New(),
+			Reference:   pgtype.Text{String: uuid.NewString(), Valid: true},
+			AmountDue:   pgtype.Int4{Int32: 500, Valid: true},
+			AmountPaid:  pgtype.Int4{Int32: amountPaid, Valid: true},
+			Status:      NullPaymentStatus{PaymentStatus: status, Valid: true},
+		}
+	}
+
+	queries := &fakeQuerier{
+		settings: &FormPaymentSetting{AmountDue: 500, Currency: "TWD"},
+		report: []ListReportRow{
+			row(PaymentStatusPaid, 500),
+			row(PaymentStatusPaid, 300),
+			row(PaymentStatusFailed, 200),
+			row(PaymentStatusWaived, 0),
+			// A response that never opened its payment
+			{ResponseID: uuid.New(), SubmittedBy: uuid.New()},
+		},
+	}
+	service := &Service{logger: zap.NewNop(), queries: queries, tracer: otel.Tracer("test")}
+
+	report, err := service.Report(context.Background(), uuid.New(), uuid.New())
+	require.NoError(t, err)
+
+	discrepancies := make([]string, len(report.Rows))
+	for i, row := range report.Rows {
+		discrepancies[i] = row.Discrepancy
+	}
+	require.Equal(t, []string{"", DiscrepancyAmountMismatch, DiscrepancyUnsettled, "", ""}, discrepancies)
+
+	require.Equal(t, PaymentStatusUnpaid, report.Rows[4].Status)
+	require.Equal(t, int32(500), report.Rows[4].AmountDue)
+	require.Equal(t, int64(2000), report.AmountDue)
+	require.Equal(t, int64(1000), report.AmountPaid)
+	// The failed payment still owes 300 and the unopened one 500; the short paid one is
+	// settled and only flagged
+	require.Equal(t, int64(800), report.Outstanding)
+	require.Equal(t, 2, report.Counts[PaymentStatusPaid])
+	require.Equal(t, 1, report.Counts[PaymentStatusFailed])
+	require.Equal(t, 1, report.Counts[PaymentStatusWaived])
+	require.Equal(t, 1, report.Counts[PaymentStatusUnpaid])
+}
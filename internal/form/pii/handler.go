@@ -0,0 +1,191 @@
+package pii
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	List(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]ListByFormIDRow, error)
+	Mark(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, masking PiiMasking, userID uuid.UUID) (PiiQuestion, error)
+	Unmark(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, userID uuid.UUID) error
+}
+
+type Request struct {
+	Masking string `json:"masking" validate:"required,oneof=REDACT HASH"`
+}
+
+type Response struct {
+	QuestionID string    `json:"questionId"`
+	Title      string    `json:"title,omitempty"`
+	Masking    string    `json:"masking"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("pii/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	rows, err := h.store.List(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]Response, len(rows))
+	for i, row := range rows {
+		responses[i] = Response{
+			QuestionID: row.QuestionID.String(),
+			Title:      row.Title.String,
+			Masking:    strings.ToUpper(string(row.Masking)),
+			UpdatedAt:  row.UpdatedAt.Time,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+func (h *Handler) MarkHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "MarkHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, questionID, err := parseQuestionPath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	row, err := h.store.Mark(traceCtx, formID, questionID, PiiMasking(strings.ToLower(req.Masking)), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, Response{
+		QuestionID: row.QuestionID.String(),
+		Masking:    strings.ToUpper(string(row.Masking)),
+		UpdatedAt:  row.UpdatedAt.Time,
+	})
+}
+
+func (h *Handler) UnmarkHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UnmarkHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, questionID, err := parseQuestionPath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Unmark(traceCtx, formID, questionID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func parseQuestionPath(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	questionID, err := internal.ParseUUID(r.PathValue("questionId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return formID, questionID, nil
+}
+
+// ParseMaskedParam reads ?masked=true, with which admins ask for the masked answers
+// everyone else gets anyway
+func ParseMaskedParam(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("masked")
+	if value == "" {
+		return false, nil
+	}
+
+	masked, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: masked must be a boolean", internal.ErrInvalidQueryParameter)
+	}
+	return masked, nil
+}
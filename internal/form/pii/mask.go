@@ -0,0 +1,40 @@
+package pii
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+)
+
+// Redacted replaces the answers of redacted questions
+const Redacted = "[REDACTED]"
+
+// Mask masks the answers of the PII questions of one form. The zero Mask leaves every
+// answer as it is.
+type Mask struct {
+	formID    uuid.UUID
+	questions map[uuid.UUID]PiiMasking
+}
+
+// Masks reports whether the answers of the question are masked
+func (m Mask) Masks(questionID uuid.UUID) bool {
+	_, ok := m.questions[questionID]
+	return ok
+}
+
+// Apply returns the answer to the question as the caller may see it. Hashed answers are
+// salted with the form, so equal answers can still be matched within one form but not
+// across forms. Empty answers stay empty.
+func (m Mask) Apply(questionID uuid.UUID, value string) string {
+	masking, ok := m.questions[questionID]
+	if !ok || value == "" {
+		return value
+	}
+
+	if masking == PiiMaskingHash {
+		sum := sha256.Sum256(append(m.formID[:], value...))
+		return hex.EncodeToString(sum[:])
+	}
+	return Redacted
+}
@@ -0,0 +1,33 @@
+-- name: QuestionExists :one
+SELECT EXISTS(
+    SELECT 1 FROM questions q
+    JOIN sections s ON s.id = q.section_id
+    WHERE q.id = @question_id AND s.form_id = @form_id
+);
+
+-- name: ListByFormID :many
+SELECT p.question_id, p.masking, q.title, p.updated_at FROM pii_questions p
+JOIN questions q ON q.id = p.question_id
+JOIN sections s ON s.id = q.section_id
+WHERE p.form_id = @form_id
+ORDER BY s.created_at ASC, q."order" ASC;
+
+-- name: Upsert :one
+INSERT INTO pii_questions (question_id, form_id, masking)
+VALUES (@question_id, @form_id, @masking)
+ON CONFLICT (question_id) DO UPDATE
+SET masking = EXCLUDED.masking, updated_at = now()
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM pii_questions
+WHERE question_id = @question_id AND form_id = @form_id;
+
+-- name: IsFormOrgAdmin :one
+-- Owners of the organization the form belongs to may see the raw answers
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
\ No newline at end of file
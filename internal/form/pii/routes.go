@@ -0,0 +1,12 @@
+package pii
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the questions of a form marked as personal data
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/pii", route.Authenticated, route.PermissionOrgAdmin, h.ListHandler)
+	r.Handle("PUT /forms/{formId}/questions/{questionId}/pii", route.Authenticated, route.PermissionOrgAdmin, h.MarkHandler)
+	r.Handle("DELETE /forms/{formId}/questions/{questionId}/pii", route.Authenticated, route.PermissionOrgAdmin, h.UnmarkHandler)
+}
@@ -0,0 +1,14 @@
+CREATE TYPE pii_masking AS ENUM(
+    'redact',
+    'hash'
+);
+
+CREATE TABLE IF NOT EXISTS pii_questions (
+    question_id UUID PRIMARY KEY REFERENCES questions(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    masking pii_masking NOT NULL DEFAULT 'redact',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pii_questions_form_id ON pii_questions(form_id);
\ No newline at end of file
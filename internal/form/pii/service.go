@@ -0,0 +1,176 @@
+package pii
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"fmt"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	QuestionExists(ctx context.Context, arg QuestionExistsParams) (bool, error)
+	ListByFormID(ctx context.Context, formID uuid.UUID) ([]ListByFormIDRow, error)
+	Upsert(ctx context.Context, arg UpsertParams) (PiiQuestion, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("pii/service"),
+	}
+}
+
+// requireAdmin allows the owner of the organization of the form only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+// List returns the questions of the form marked as PII. Only org admins may manage
+// them, as unmarking a question shows its answers to everyone reading the form.
+func (s *Service) List(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]ListByFormIDRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	rows, err := s.queries.ListByFormID(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "pii_questions", "form_id", formID.String(), logger, "list pii questions")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// Mark classifies the question as PII, or changes how its answers are masked
+func (s *Service) Mark(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, masking PiiMasking, userID uuid.UUID) (PiiQuestion, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Mark")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return PiiQuestion{}, err
+	}
+
+	exists, err := s.queries.QuestionExists(traceCtx, QuestionExistsParams{QuestionID: questionID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "questions", "id", questionID.String(), logger, "check question")
+		span.RecordError(err)
+		return PiiQuestion{}, err
+	}
+	if !exists {
+		err = fmt.Errorf("%w: %s", internal.ErrQuestionNotFound, questionID)
+		span.RecordError(err)
+		return PiiQuestion{}, err
+	}
+
+	row, err := s.queries.Upsert(traceCtx, UpsertParams{
+		QuestionID: questionID,
+		FormID:     formID,
+		Masking:    masking,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "pii_questions", "question_id", questionID.String(), logger, "upsert pii question")
+		span.RecordError(err)
+		return PiiQuestion{}, err
+	}
+
+	return row, nil
+}
+
+func (s *Service) Unmark(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Unmark")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.queries.Delete(traceCtx, DeleteParams{QuestionID: questionID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "pii_questions", "question_id", questionID.String(), logger, "delete pii question")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		err = internal.ErrQuestionNotPII
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Mask returns the mask the user sees the answers of the form through. Admins of the
+// organization of the form see the raw answers unless they ask for masked ones;
+// everyone else, including a uuid.Nil user, always gets them masked.
+func (s *Service) Mask(ctx context.Context, formID uuid.UUID, userID uuid.UUID, masked bool) (Mask, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Mask")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if !masked && userID != uuid.Nil {
+		isAdmin, err := s.queries.IsFormOrgAdmin(traceCtx, IsFormOrgAdminParams{
+			FormID: formID,
+			UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+			span.RecordError(err)
+			return Mask{}, err
+		}
+		if isAdmin {
+			return Mask{}, nil
+		}
+	}
+
+	rows, err := s.queries.ListByFormID(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "pii_questions", "form_id", formID.String(), logger, "list pii questions")
+		span.RecordError(err)
+		return Mask{}, err
+	}
+
+	mask := Mask{formID: formID, questions: make(map[uuid.UUID]PiiMasking, len(rows))}
+	for _, row := range rows {
+		mask.questions[row.QuestionID] = row.Masking
+	}
+	return mask, nil
+}
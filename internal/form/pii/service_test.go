@@ -0,0 +1,126 @@
+package pii_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/pii"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// piiDB answers the queries of the service for a form whose organization admin check
+// returns admin, counting the writes that went through
+type piiDB struct {
+	admin  bool
+	writes int
+}
+
+func (db *piiDB) Exec(_ context.Context, sql string, _ ...interface{}) (pgconn.CommandTag, error) {
+	if !strings.Contains(sql, "name: Delete") {
+		return pgconn.CommandTag{}, errors.New("unexpected exec")
+	}
+	db.writes++
+	return pgconn.NewCommandTag("DELETE 1"), nil
+}
+
+func (db *piiDB) Query(_ context.Context, sql string, _ ...interface{}) (pgx.Rows, error) {
+	if !strings.Contains(sql, "name: ListByFormID") {
+		return nil, errors.New("unexpected query")
+	}
+	return emptyRows{}, nil
+}
+
+func (db *piiDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	switch {
+	case strings.Contains(sql, "name: IsFormOrgAdmin"):
+		return boolRow(db.admin)
+	case strings.Contains(sql, "name: QuestionExists"):
+		return boolRow(true)
+	case strings.Contains(sql, "name: Upsert"):
+		db.writes++
+		return scanRow(func(dest ...any) error {
+			*dest[0].(*uuid.UUID) = args[0].(uuid.UUID)
+			*dest[1].(*uuid.UUID) = args[1].(uuid.UUID)
+			*dest[2].(*pii.PiiMasking) = args[2].(pii.PiiMasking)
+			return nil
+		})
+	}
+	return scanRow(func(...any) error { return errors.New("unexpected query") })
+}
+
+type scanRow func(dest ...any) error
+
+func (r scanRow) Scan(dest ...any) error { return r(dest...) }
+
+func boolRow(value bool) scanRow {
+	return func(dest ...any) error {
+		*dest[0].(*bool) = value
+		return nil
+	}
+}
+
+type emptyRows struct {
+	pgx.Rows
+}
+
+func (emptyRows) Next() bool { return false }
+func (emptyRows) Close()     {}
+func (emptyRows) Err() error { return nil }
+
+func TestService_RequiresOrgAdmin(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		call        func(service *pii.Service, formID uuid.UUID, userID uuid.UUID) error
+		expectWrite bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "List",
+			call: func(service *pii.Service, formID uuid.UUID, userID uuid.UUID) error {
+				_, err := service.List(context.Background(), formID, userID)
+				return err
+			},
+		},
+		{
+			name: "Mark",
+			call: func(service *pii.Service, formID uuid.UUID, userID uuid.UUID) error {
+				_, err := service.Mark(context.Background(), formID, uuid.New(), pii.PiiMaskingRedact, userID)
+				return err
+			},
+			expectWrite: true,
+		},
+		{
+			name: "Unmark",
+			call: func(service *pii.Service, formID uuid.UUID, userID uuid.UUID) error {
+				return service.Unmark(context.Background(), formID, uuid.New(), userID)
+			},
+			expectWrite: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			admin := &piiDB{admin: true}
+			require.NoError(t, tc.call(pii.NewService(zap.NewNop(), admin), uuid.New(), uuid.New()))
+			require.Equal(t, tc.expectWrite, admin.writes == 1)
+
+			// Unmarking would otherwise let anyone read the answers the mask hides
+			member := &piiDB{}
+			err := tc.call(pii.NewService(zap.NewNop(), member), uuid.New(), uuid.New())
+			require.ErrorIs(t, err, internal.ErrNotOrgAdmin)
+			require.Zero(t, member.writes)
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package pipeline
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Create(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, input Input) (Detail, error)
+	List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]Pipeline, error)
+	Get(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID) (Detail, error)
+	Update(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID, input Input) (Detail, error)
+	Delete(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID) error
+	Dashboard(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID) (Dashboard, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+// StageRequest is one stage of a pipeline. Gate is what candidates need on the stage
+// before, approved when empty; it has no effect on the first stage.
+type StageRequest struct {
+	FormID uuid.UUID `json:"formId" validate:"required"`
+	Name   string    `json:"name" validate:"max=128"`
+	Gate   string    `json:"gate" validate:"omitempty,oneof=submitted approved"`
+}
+
+// Request declares a pipeline and its stages in the order candidates go through them
+type Request struct {
+	Name        string         `json:"name" validate:"required,max=128"`
+	Description string         `json:"description"`
+	Stages      []StageRequest `json:"stages" validate:"required,min=1,dive"`
+}
+
+func (r Request) ToInput() Input {
+	stages := make([]StageInput, len(r.Stages))
+	for i, stage := range r.Stages {
+		gate := PipelineGate(stage.Gate)
+		if gate == "" {
+			gate = PipelineGateApproved
+		}
+		stages[i] = StageInput{
+			FormID: stage.FormID,
+			Name:   strings.TrimSpace(stage.Name),
+			Gate:   gate,
+		}
+	}
+
+	return Input{
+		Name:        strings.TrimSpace(r.Name),
+		Description: r.Description,
+		Stages:      stages,
+	}
+}
+
+type StageResponse struct {
+	Position  int32  `json:"position"`
+	FormID    string `json:"formId"`
+	FormTitle string `json:"formTitle"`
+	Name      string `json:"name"`
+	Gate      string `json:"gate"`
+}
+
+type Response struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Stages      []StageResponse `json:"stages,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func ToResponse(pipeline Pipeline) Response {
+	return Response{
+		ID:          pipeline.ID.String(),
+		Name:        pipeline.Name,
+		Description: pipeline.Description,
+		CreatedAt:   pipeline.CreatedAt.Time,
+		UpdatedAt:   pipeline.UpdatedAt.Time,
+	}
+}
+
+func ToStageResponse(stage ListStagesRow) StageResponse {
+	return StageResponse{
+		Position:  stage.Position,
+		FormID:    stage.FormID.String(),
+		FormTitle: stage.FormTitle,
+		Name:      stage.Name,
+		Gate:      string(stage.Gate),
+	}
+}
+
+func ToDetailResponse(detail Detail) Response {
+	response := ToResponse(detail.Pipeline)
+	response.Stages = make([]StageResponse, len(detail.Stages))
+	for i, stage := range detail.Stages {
+		response.Stages[i] = ToStageResponse(stage)
+	}
+	return response
+}
+
+type StageSummaryResponse struct {
+	StageResponse
+	Counts map[StageStatus]int `json:"counts"`
+}
+
+type CandidateStageResponse struct {
+	Position    int32     `json:"position"`
+	Status      string    `json:"status"`
+	RespondedAt time.Time `json:"respondedAt"`
+}
+
+type CandidateResponse struct {
+	UserID   string                   `json:"userId"`
+	Name     string                   `json:"name"`
+	Username string                   `json:"username"`
+	Stage    int32                    `json:"stage"`
+	Status   string                   `json:"status"`
+	Stages   []CandidateStageResponse `json:"stages"`
+}
+
+type DashboardResponse struct {
+	Pipeline   Response               `json:"pipeline"`
+	Stages     []StageSummaryResponse `json:"stages"`
+	Candidates []CandidateResponse    `json:"candidates"`
+}
+
+func ToDashboardResponse(dashboard Dashboard) DashboardResponse {
+	stages := make([]StageSummaryResponse, len(dashboard.Summaries))
+	for i, summary := range dashboard.Summaries {
+		stages[i] = StageSummaryResponse{
+			StageResponse: ToStageResponse(summary.Stage),
+			Counts:        summary.Counts,
+		}
+	}
+
+	candidates := make([]CandidateResponse, len(dashboard.Candidates))
+	for i, candidate := range dashboard.Candidates {
+		candidateStages := make([]CandidateStageResponse, len(candidate.Stages))
+		for j, stage := range candidate.Stages {
+			candidateStages[j] = CandidateStageResponse{
+				Position:    stage.Position,
+				Status:      string(stage.Status),
+				RespondedAt: stage.RespondedAt,
+			}
+		}
+		candidates[i] = CandidateResponse{
+			UserID:   candidate.UserID.String(),
+			Name:     candidate.Name,
+			Username: candidate.Username,
+			Stage:    candidate.Stage,
+			Status:   string(candidate.Status),
+			Stages:   candidateStages,
+		}
+	}
+
+	return DashboardResponse{
+		Pipeline:   ToResponse(dashboard.Pipeline),
+		Stages:     stages,
+		Candidates: candidates,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("pipeline/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// caller returns the organization in the path and the current user
+func (h *Handler) caller(ctx context.Context) (uuid.UUID, *user.User, error) {
+	currentUser, ok := user.GetFromContext(ctx)
+	if !ok {
+		return uuid.Nil, nil, internal.ErrNoUserInContext
+	}
+
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return orgID, currentUser, nil
+}
+
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, currentUser, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	detail, err := h.store.Create(traceCtx, orgID, currentUser.ID, req.ToInput())
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToDetailResponse(detail))
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, currentUser, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	pipelines, err := h.store.List(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]Response, len(pipelines))
+	for i, pipeline := range pipelines {
+		response[i] = ToResponse(pipeline)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, currentUser, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	detail, err := h.store.Get(traceCtx, orgID, currentUser.ID, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToDetailResponse(detail))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, currentUser, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	detail, err := h.store.Update(traceCtx, orgID, currentUser.ID, id, req.ToInput())
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToDetailResponse(detail))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, currentUser, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, orgID, currentUser.ID, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DashboardHandler shows where every candidate of the pipeline stands
+func (h *Handler) DashboardHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DashboardHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, currentUser, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	dashboard, err := h.store.Dashboard(traceCtx, orgID, currentUser.ID, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToDashboardResponse(dashboard))
+}
@@ -0,0 +1,80 @@
+-- name: Create :one
+INSERT INTO pipelines (org_id, name, description)
+VALUES (@org_id, @name, @description)
+RETURNING *;
+
+-- name: Get :one
+SELECT * FROM pipelines
+WHERE id = @id AND org_id = @org_id;
+
+-- name: ListByOrg :many
+SELECT * FROM pipelines
+WHERE org_id = @org_id
+ORDER BY created_at ASC;
+
+-- name: Update :one
+UPDATE pipelines
+SET name = @name, description = @description, updated_at = now()
+WHERE id = @id AND org_id = @org_id
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM pipelines
+WHERE id = @id AND org_id = @org_id;
+
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: IsFormInOrg :one
+SELECT EXISTS (
+    SELECT 1
+    FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    WHERE f.id = @form_id AND (u.id = @org_id OR u.org_id = @org_id)
+);
+
+-- name: ListStages :many
+SELECT s.pipeline_id, s.position, s.form_id, s.name, s.gate, f.title AS form_title FROM pipeline_stages s
+JOIN forms f ON f.id = s.form_id
+WHERE s.pipeline_id = @pipeline_id
+ORDER BY s.position ASC;
+
+-- name: DeleteStages :exec
+DELETE FROM pipeline_stages
+WHERE pipeline_id = @pipeline_id;
+
+-- name: CreateStage :one
+INSERT INTO pipeline_stages (pipeline_id, position, form_id, name, gate)
+VALUES (@pipeline_id, @position, @form_id, @name, @gate)
+RETURNING *;
+
+-- name: GetPreviousStage :one
+-- The stage the form follows in its pipeline, with the gate of the form's own stage
+SELECT s.pipeline_id, s.gate, p.form_id AS previous_form_id, p.name AS previous_name FROM pipeline_stages s
+JOIN pipeline_stages p ON p.pipeline_id = s.pipeline_id AND p.position = s.position - 1
+WHERE s.form_id = @form_id;
+
+-- name: GetReviewCounts :one
+-- How the approvals of the user's response to the form were decided; no row without a response
+SELECT
+    COUNT(a.id) FILTER (WHERE a.status = 'approved') AS approved,
+    COUNT(a.id) FILTER (WHERE a.status = 'rejected') AS rejected,
+    COUNT(a.id) FILTER (WHERE a.status = 'pending') AS pending
+FROM form_responses r
+LEFT JOIN form_approvals a ON a.response_id = r.id
+WHERE r.form_id = @form_id AND r.submitted_by = @user_id AND NOT r.is_test
+GROUP BY r.id;
+
+-- name: ListCandidates :many
+-- One row per candidate and stage they responded to, with how their approvals were decided
+SELECT s.position, r.submitted_by, u.name, u.username, r.created_at,
+    COUNT(a.id) FILTER (WHERE a.status = 'approved') AS approved,
+    COUNT(a.id) FILTER (WHERE a.status = 'rejected') AS rejected,
+    COUNT(a.id) FILTER (WHERE a.status = 'pending') AS pending
+FROM pipeline_stages s
+JOIN form_responses r ON r.form_id = s.form_id AND NOT r.is_test
+JOIN users u ON u.id = r.submitted_by
+LEFT JOIN form_approvals a ON a.response_id = r.id
+WHERE s.pipeline_id = @pipeline_id
+GROUP BY s.position, r.id, u.id
+ORDER BY r.submitted_by, s.position;
\ No newline at end of file
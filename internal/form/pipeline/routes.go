@@ -0,0 +1,15 @@
+package pipeline
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the pipelines of an organization and their dashboards
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/pipelines", route.TenantAuthenticated, route.PermissionOrgAdmin, h.ListHandler)
+	r.Handle("POST /orgs/{slug}/pipelines", route.TenantAuthenticated, route.PermissionOrgAdmin, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/pipelines/{id}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.GetHandler)
+	r.Handle("PUT /orgs/{slug}/pipelines/{id}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.UpdateHandler)
+	r.Handle("DELETE /orgs/{slug}/pipelines/{id}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.DeleteHandler)
+	r.Handle("GET /orgs/{slug}/pipelines/{id}/dashboard", route.TenantAuthenticated, route.PermissionOrgAdmin, h.DashboardHandler)
+}
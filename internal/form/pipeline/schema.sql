@@ -0,0 +1,27 @@
+CREATE TYPE pipeline_gate AS ENUM(
+    'submitted',
+    'approved'
+);
+
+CREATE TABLE IF NOT EXISTS pipelines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pipelines_org_id ON pipelines(org_id);
+
+-- Stages are numbered from 0 in the order candidates go through them. A form is a
+-- stage of one pipeline at most; the gate is what candidates need on the stage before
+-- to take part in this one, the first stage being open to everyone the form is.
+CREATE TABLE IF NOT EXISTS pipeline_stages (
+    pipeline_id UUID NOT NULL REFERENCES pipelines(id) ON DELETE CASCADE,
+    position INT NOT NULL CHECK (position >= 0),
+    form_id UUID NOT NULL UNIQUE REFERENCES forms(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    gate pipeline_gate NOT NULL DEFAULT 'approved',
+    PRIMARY KEY (pipeline_id, position)
+);
\ No newline at end of file
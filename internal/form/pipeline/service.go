@@ -0,0 +1,536 @@
+package pipeline
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (Pipeline, error)
+	Get(ctx context.Context, arg GetParams) (Pipeline, error)
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]Pipeline, error)
+	Update(ctx context.Context, arg UpdateParams) (Pipeline, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	IsFormInOrg(ctx context.Context, arg IsFormInOrgParams) (bool, error)
+	ListStages(ctx context.Context, pipelineID uuid.UUID) ([]ListStagesRow, error)
+	DeleteStages(ctx context.Context, pipelineID uuid.UUID) error
+	CreateStage(ctx context.Context, arg CreateStageParams) (PipelineStage, error)
+	GetPreviousStage(ctx context.Context, formID uuid.UUID) (GetPreviousStageRow, error)
+	GetReviewCounts(ctx context.Context, arg GetReviewCountsParams) (GetReviewCountsRow, error)
+	ListCandidates(ctx context.Context, pipelineID uuid.UUID) ([]ListCandidatesRow, error)
+}
+
+// DB is the connection the service runs on; a pipeline and its stages are written in
+// a transaction begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// StageStatus is where a candidate stands on one stage, from the approvals of their response
+// to its form. A response to a form without approval steps is only submitted.
+type StageStatus string
+
+const (
+	StatusSubmitted StageStatus = "submitted"
+	StatusPending   StageStatus = "pending"
+	StatusApproved  StageStatus = "approved"
+	StatusRejected  StageStatus = "rejected"
+)
+
+var statuses = []StageStatus{StatusSubmitted, StatusPending, StatusApproved, StatusRejected}
+
+// statusOf gives a rejection precedence over the approvals still pending, and those
+// over the ones already given
+func statusOf(approved, rejected, pending int64) StageStatus {
+	switch {
+	case rejected > 0:
+		return StatusRejected
+	case pending > 0:
+		return StatusPending
+	case approved > 0:
+		return StatusApproved
+	default:
+		return StatusSubmitted
+	}
+}
+
+// admits reports whether a candidate with status on the stage before may go on: the
+// submitted gate lets everyone not rejected through, the approved one only the approved
+func (g PipelineGate) admits(status StageStatus) bool {
+	switch g {
+	case PipelineGateSubmitted:
+		return status != StatusRejected
+	case PipelineGateApproved:
+		return status == StatusApproved
+	}
+	return false
+}
+
+// StageInput is one form of a pipeline, in the order candidates take them
+type StageInput struct {
+	FormID uuid.UUID
+	Name   string
+	Gate   PipelineGate
+}
+
+type Input struct {
+	Name        string
+	Description string
+	Stages      []StageInput
+}
+
+// Detail is a pipeline with its stages in order
+type Detail struct {
+	Pipeline Pipeline
+	Stages   []ListStagesRow
+}
+
+// StageSummary counts the candidates of a stage by status
+type StageSummary struct {
+	Stage  ListStagesRow
+	Counts map[StageStatus]int
+}
+
+type CandidateStage struct {
+	Position    int32
+	Status      StageStatus
+	RespondedAt time.Time
+}
+
+// Candidate is a user who responded to a stage of the pipeline. Stage and Status are
+// those of the furthest stage they responded to.
+type Candidate struct {
+	UserID   uuid.UUID
+	Name     string
+	Username string
+	Stage    int32
+	Status   StageStatus
+	Stages   []CandidateStage
+}
+
+// Dashboard shows how the candidates progressed through a pipeline
+type Dashboard struct {
+	Detail
+	Summaries  []StageSummary
+	Candidates []Candidate
+}
+
+type Service struct {
+	logger  *zap.Logger
+	db      DB
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DB) *Service {
+	return &Service{
+		logger:  logger,
+		db:      db,
+		queries: New(db),
+		tracer:  otel.Tracer("pipeline/service"),
+	}
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+// requireAdmin allows the owner of the organization only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsOrgAdmin(ctx, IsOrgAdminParams{
+		OrgID:  orgID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+// validateStages checks the stages name distinct forms of the organization
+func (s *Service) validateStages(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, stages []StageInput) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("%w: a pipeline needs at least one stage", internal.ErrPipelineStagesInvalid)
+	}
+
+	seen := make(map[uuid.UUID]struct{}, len(stages))
+	for i, stage := range stages {
+		if _, ok := seen[stage.FormID]; ok {
+			return fmt.Errorf("%w: stage %d repeats form %s", internal.ErrPipelineStagesInvalid, i, stage.FormID)
+		}
+		seen[stage.FormID] = struct{}{}
+
+		if stage.Gate != PipelineGateSubmitted && stage.Gate != PipelineGateApproved {
+			return fmt.Errorf("%w: stage %d: gate must be submitted or approved", internal.ErrPipelineStagesInvalid, i)
+		}
+
+		ok, err := s.queries.IsFormInOrg(ctx, IsFormInOrgParams{FormID: stage.FormID, OrgID: orgID})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", stage.FormID.String(), logger, "check form organization")
+		}
+		if !ok {
+			return fmt.Errorf("%w: stage %d: %w", internal.ErrPipelineStagesInvalid, i, internal.ErrFormNotFound)
+		}
+	}
+
+	return nil
+}
+
+// replaceStages writes the stages of the pipeline in place of its current ones
+func replaceStages(ctx context.Context, logger *zap.Logger, queries Querier, pipelineID uuid.UUID, stages []StageInput) error {
+	err := queries.DeleteStages(ctx, pipelineID)
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "pipeline_stages", "pipeline_id", pipelineID.String(), logger, "delete pipeline stages")
+	}
+
+	for i, stage := range stages {
+		_, err := queries.CreateStage(ctx, CreateStageParams{
+			PipelineID: pipelineID,
+			Position:   int32(i),
+			FormID:     stage.FormID,
+			Name:       stage.Name,
+			Gate:       stage.Gate,
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "pipeline_stages", "form_id", stage.FormID.String(), logger, "create pipeline stage")
+			if errors.Is(err, databaseutil.ErrUniqueViolation) {
+				err = internal.ErrPipelineFormTaken
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) detail(ctx context.Context, logger *zap.Logger, pipeline Pipeline) (Detail, error) {
+	stages, err := s.queries.ListStages(ctx, pipeline.ID)
+	if err != nil {
+		return Detail{}, databaseutil.WrapDBErrorWithKeyValue(err, "pipeline_stages", "pipeline_id", pipeline.ID.String(), logger, "list pipeline stages")
+	}
+	if stages == nil {
+		stages = []ListStagesRow{}
+	}
+	return Detail{Pipeline: pipeline, Stages: stages}, nil
+}
+
+func (s *Service) get(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, id uuid.UUID) (Pipeline, error) {
+	pipeline, err := s.queries.Get(ctx, GetParams{ID: id, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Pipeline{}, internal.ErrPipelineNotFound
+		}
+		return Pipeline{}, databaseutil.WrapDBErrorWithKeyValue(err, "pipelines", "id", id.String(), logger, "get pipeline")
+	}
+	return pipeline, nil
+}
+
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, input Input) (Detail, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	err = s.validateStages(traceCtx, logger, orgID, input.Stages)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	var pipeline Pipeline
+	err = s.inTx(traceCtx, logger, func(queries Querier) error {
+		pipeline, err = queries.Create(traceCtx, CreateParams{
+			OrgID:       orgID,
+			Name:        input.Name,
+			Description: input.Description,
+		})
+		if err != nil {
+			return databaseutil.WrapDBError(err, logger, "create pipeline")
+		}
+		return replaceStages(traceCtx, logger, queries, pipeline.ID, input.Stages)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	logger.Info("Created pipeline", zap.String("pipeline_id", pipeline.ID.String()), zap.String("org_id", orgID.String()), zap.Int("stages", len(input.Stages)))
+
+	detail, err := s.detail(traceCtx, logger, pipeline)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+	return detail, nil
+}
+
+func (s *Service) List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]Pipeline, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	pipelines, err := s.queries.ListByOrg(traceCtx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "pipelines", "org_id", orgID.String(), logger, "list pipelines")
+		span.RecordError(err)
+		return nil, err
+	}
+	if pipelines == nil {
+		pipelines = []Pipeline{}
+	}
+
+	return pipelines, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID) (Detail, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	pipeline, err := s.get(traceCtx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	detail, err := s.detail(traceCtx, logger, pipeline)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+	return detail, nil
+}
+
+// Update replaces the name, the description and the stages of the pipeline
+func (s *Service) Update(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID, input Input) (Detail, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	err = s.validateStages(traceCtx, logger, orgID, input.Stages)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	var pipeline Pipeline
+	err = s.inTx(traceCtx, logger, func(queries Querier) error {
+		pipeline, err = queries.Update(traceCtx, UpdateParams{
+			ID:          id,
+			OrgID:       orgID,
+			Name:        input.Name,
+			Description: input.Description,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return internal.ErrPipelineNotFound
+			}
+			return databaseutil.WrapDBErrorWithKeyValue(err, "pipelines", "id", id.String(), logger, "update pipeline")
+		}
+		return replaceStages(traceCtx, logger, queries, pipeline.ID, input.Stages)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	logger.Info("Updated pipeline", zap.String("pipeline_id", id.String()), zap.Int("stages", len(input.Stages)))
+
+	detail, err := s.detail(traceCtx, logger, pipeline)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+	return detail, nil
+}
+
+// Delete removes the pipeline and its stages; the forms stay, without gates
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Delete(traceCtx, DeleteParams{ID: id, OrgID: orgID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "pipelines", "id", id.String(), logger, "delete pipeline")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrPipelineNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted pipeline", zap.String("pipeline_id", id.String()))
+	return nil
+}
+
+// Dashboard counts the candidates of every stage by status and lists the candidates,
+// those who got the furthest first
+func (s *Service) Dashboard(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID) (Dashboard, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Dashboard")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	detail, err := s.Get(traceCtx, orgID, userID, id)
+	if err != nil {
+		span.RecordError(err)
+		return Dashboard{}, err
+	}
+
+	rows, err := s.queries.ListCandidates(traceCtx, id)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "pipeline_stages", "pipeline_id", id.String(), logger, "list pipeline candidates")
+		span.RecordError(err)
+		return Dashboard{}, err
+	}
+
+	summaries := make([]StageSummary, len(detail.Stages))
+	for i, stage := range detail.Stages {
+		counts := make(map[StageStatus]int, len(statuses))
+		for _, status := range statuses {
+			counts[status] = 0
+		}
+		summaries[i] = StageSummary{Stage: stage, Counts: counts}
+	}
+
+	// Rows come ordered by candidate, then by stage
+	candidates := []Candidate{}
+	for _, row := range rows {
+		status := statusOf(row.Approved, row.Rejected, row.Pending)
+		if int(row.Position) < len(summaries) {
+			summaries[row.Position].Counts[status]++
+		}
+
+		if len(candidates) == 0 || candidates[len(candidates)-1].UserID != row.SubmittedBy {
+			candidates = append(candidates, Candidate{
+				UserID:   row.SubmittedBy,
+				Name:     row.Name.String,
+				Username: row.Username.String,
+			})
+		}
+		candidate := &candidates[len(candidates)-1]
+		candidate.Stage = row.Position
+		candidate.Status = status
+		candidate.Stages = append(candidate.Stages, CandidateStage{
+			Position:    row.Position,
+			Status:      status,
+			RespondedAt: row.CreatedAt.Time,
+		})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b Candidate) int {
+		return cmp.Or(cmp.Compare(b.Stage, a.Stage), cmp.Compare(a.Username, b.Username))
+	})
+
+	return Dashboard{Detail: detail, Summaries: summaries, Candidates: candidates}, nil
+}
+
+// CheckGate admits the user to a form that is a stage of a pipeline only when their
+// response to the stage before passes the gate. Forms outside pipelines and first
+// stages admit everyone.
+func (s *Service) CheckGate(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (bool, string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "CheckGate")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	previous, err := s.queries.GetPreviousStage(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return true, "", nil
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "pipeline_stages", "form_id", formID.String(), logger, "get previous pipeline stage")
+		span.RecordError(err)
+		return false, "", err
+	}
+
+	stageName := previous.PreviousName
+	if stageName == "" {
+		stageName = "the previous stage"
+	}
+
+	counts, err := s.queries.GetReviewCounts(traceCtx, GetReviewCountsParams{FormID: previous.PreviousFormID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, fmt.Sprintf("user has not responded to %s", stageName), nil
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", previous.PreviousFormID.String(), logger, "get review status")
+		span.RecordError(err)
+		return false, "", err
+	}
+
+	status := statusOf(counts.Approved, counts.Rejected, counts.Pending)
+	if !previous.Gate.admits(status) {
+		return false, fmt.Sprintf("response to %s is %s, %s is required", stageName, status, previous.Gate), nil
+	}
+
+	return true, "", nil
+}
@@ -0,0 +1,179 @@
+package progress
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Get(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Progress, error)
+	Update(ctx context.Context, formID uuid.UUID, userID uuid.UUID, sectionID uuid.UUID) (Progress, error)
+	Reset(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+}
+
+type UpdateRequest struct {
+	CurrentSectionID string `json:"currentSectionId" validate:"required,uuid"`
+}
+
+type Response struct {
+	FormID           string          `json:"formId"`
+	CurrentSectionID *string         `json:"currentSectionId"`
+	VisitedNodes     []string        `json:"visitedNodes"`
+	Path             []workflow.Step `json:"path"`
+	Completed        bool            `json:"completed"`
+	AwaitingApproval *string         `json:"awaitingApproval"`
+	ReleaseAt        *time.Time      `json:"releaseAt"`
+	UpdatedAt        *time.Time      `json:"updatedAt"`
+}
+
+func ToResponse(progress Progress) Response {
+	response := Response{
+		FormID:       progress.FormID.String(),
+		VisitedNodes: progress.VisitedNodes,
+		Path:         progress.Traversal.Steps,
+		Completed:    progress.Traversal.Completed,
+	}
+	if progress.CurrentSectionID != uuid.Nil {
+		currentSectionID := progress.CurrentSectionID.String()
+		response.CurrentSectionID = &currentSectionID
+	}
+	if gate, ok := progress.Traversal.AwaitingApproval(); ok {
+		response.AwaitingApproval = &gate.NodeID
+	}
+	if delay, ok := progress.Traversal.AwaitingRelease(); ok {
+		response.ReleaseAt = &delay.Delay.ReleaseAt
+	}
+	if progress.UpdatedAt.Valid {
+		response.UpdatedAt = &progress.UpdatedAt.Time
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("progress/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	progress, err := h.store.Get(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(progress))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req UpdateRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	sectionID, err := handlerutil.ParseUUID(req.CurrentSectionID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	progress, err := h.store.Update(traceCtx, formID, currentUser.ID, sectionID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(progress))
+}
+
+func (h *Handler) ResetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ResetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	err = h.store.Reset(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
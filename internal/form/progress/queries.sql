@@ -0,0 +1,16 @@
+-- name: Get :one
+SELECT * FROM form_progress
+WHERE form_id = @form_id AND user_id = @user_id;
+
+-- name: Upsert :one
+INSERT INTO form_progress (form_id, user_id, current_section_id, visited_nodes)
+VALUES (@form_id, @user_id, @current_section_id, @visited_nodes)
+ON CONFLICT (form_id, user_id) DO UPDATE
+SET current_section_id = EXCLUDED.current_section_id,
+    visited_nodes = EXCLUDED.visited_nodes,
+    updated_at = now()
+RETURNING *;
+
+-- name: Delete :exec
+DELETE FROM form_progress
+WHERE form_id = @form_id AND user_id = @user_id;
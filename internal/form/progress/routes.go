@@ -0,0 +1,12 @@
+package progress
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the saved progress of the current user on a form
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{formId}/progress", route.Authenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /forms/{formId}/progress", route.Authenticated, route.PermissionNone, h.UpdateHandler)
+	r.Handle("DELETE /forms/{formId}/progress", route.Authenticated, route.PermissionNone, h.ResetHandler)
+}
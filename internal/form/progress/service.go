@@ -0,0 +1,267 @@
+package progress
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	Get(ctx context.Context, arg GetParams) (FormProgress, error)
+	Upsert(ctx context.Context, arg UpsertParams) (FormProgress, error)
+	Delete(ctx context.Context, arg DeleteParams) error
+}
+
+type WorkflowStore interface {
+	ActiveRuntime(ctx context.Context, formID uuid.UUID) (*workflow.Runtime, error)
+}
+
+type AnswerStore interface {
+	GetAnswersByFormIDAndSubmittedBy(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]response.Answer, error)
+}
+
+type ApprovalStore interface {
+	Decisions(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (workflow.Approvals, error)
+}
+
+type ActionStore interface {
+	Dispatch(ctx context.Context, formID uuid.UUID, userID uuid.UUID, traversal workflow.Traversal, answers workflow.Answers) error
+}
+
+// Progress is a respondent's position in the active workflow of a form.
+// CurrentSectionID is uuid.Nil once the traversal reaches the end node or while
+// the respondent is waiting at an approval gate or a delay node.
+type Progress struct {
+	FormID           uuid.UUID
+	CurrentSectionID uuid.UUID
+	VisitedNodes     []string
+	Traversal        workflow.Traversal
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type Service struct {
+	logger        *zap.Logger
+	queries       Querier
+	tracer        trace.Tracer
+	workflowStore WorkflowStore
+	answerStore   AnswerStore
+	approvalStore ApprovalStore
+	actionStore   ActionStore
+}
+
+func NewService(logger *zap.Logger, db DBTX, workflowStore WorkflowStore, answerStore AnswerStore, approvalStore ApprovalStore, actionStore ActionStore) *Service {
+	return &Service{
+		logger:        logger,
+		queries:       New(db),
+		tracer:        otel.Tracer("progress/service"),
+		workflowStore: workflowStore,
+		answerStore:   answerStore,
+		approvalStore: approvalStore,
+		actionStore:   actionStore,
+	}
+}
+
+// Get resumes a respondent's progress. The active workflow is replayed with the
+// respondent's saved answers and stops at the stored current section, or at the
+// first section on the path that was never visited if the answers changed the branch.
+// Respondents without stored progress start at the first section.
+func (s *Service) Get(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Progress, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	runtime, answers, approvals, err := s.load(ctx, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	stored, err := s.queries.Get(ctx, GetParams{FormID: formID, UserID: userID})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_progress", "form_id", formID.String(), logger, "get form progress")
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	// No stored progress, stop at the first section
+	stopAt := func(string) bool { return true }
+	if err == nil {
+		visited := make(map[string]bool, len(stored.VisitedNodes))
+		for _, nodeID := range stored.VisitedNodes {
+			visited[nodeID] = true
+		}
+
+		var currentSectionID string
+		if stored.CurrentSectionID.Valid {
+			currentSectionID = uuid.UUID(stored.CurrentSectionID.Bytes).String()
+		}
+
+		stopAt = func(sectionID string) bool {
+			return sectionID == currentSectionID || !visited[sectionID]
+		}
+	}
+
+	traversal, err := runtime.Traverse(answers, approvals, time.Now(), stopAt)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	return newProgress(formID, traversal, stored.UpdatedAt), nil
+}
+
+// Update moves a respondent to the given section. The section must lie on the
+// path the active workflow takes with the respondent's saved answers, and must not
+// be behind an approval gate that is still waiting for a decision or a delay node
+// that has not been released; every node on that path up to the section is
+// recorded as visited and the action nodes passed on the way are dispatched.
+func (s *Service) Update(ctx context.Context, formID uuid.UUID, userID uuid.UUID, sectionID uuid.UUID) (Progress, error) {
+	ctx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	runtime, answers, approvals, err := s.load(ctx, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	if !runtime.HasSection(sectionID.String()) {
+		err = fmt.Errorf("%w: section %s is not part of the active workflow", internal.ErrSectionNotReachable, sectionID)
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	traversal, err := runtime.Traverse(answers, approvals, time.Now(), func(id string) bool { return id == sectionID.String() })
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	if gate, ok := traversal.AwaitingApproval(); ok {
+		err = fmt.Errorf("%w: section %s is behind approval node '%s' which is %s", internal.ErrSectionNotReachable, sectionID, gate.NodeID, gate.Approval.Decision)
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	if delay, ok := traversal.AwaitingRelease(); ok {
+		err = fmt.Errorf("%w: section %s is behind delay node '%s' until %s", internal.ErrSectionNotReachable, sectionID, delay.NodeID, delay.Delay.ReleaseAt.Format(time.RFC3339))
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	if traversal.Completed {
+		err = fmt.Errorf("%w: section %s is skipped by the current answers", internal.ErrSectionNotReachable, sectionID)
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	progress := newProgress(formID, traversal, pgtype.Timestamptz{})
+
+	stored, err := s.queries.Upsert(ctx, UpsertParams{
+		FormID:           formID,
+		UserID:           userID,
+		CurrentSectionID: pgtype.UUID{Bytes: sectionID, Valid: true},
+		VisitedNodes:     progress.VisitedNodes,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_progress", "form_id", formID.String(), logger, "upsert form progress")
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	progress.UpdatedAt = stored.UpdatedAt
+
+	err = s.actionStore.Dispatch(ctx, formID, userID, traversal, answers)
+	if err != nil {
+		span.RecordError(err)
+		return Progress{}, err
+	}
+
+	logger.Info("Updated form progress",
+		zap.String("form_id", formID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("section_id", sectionID.String()))
+
+	return progress, nil
+}
+
+// Reset discards a respondent's stored progress so the form starts over from the first section
+func (s *Service) Reset(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Reset")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.queries.Delete(ctx, DeleteParams{FormID: formID, UserID: userID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_progress", "form_id", formID.String(), logger, "delete form progress")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// load builds the runtime for the active workflow of the form along with the
+// respondent's saved answers and the approval decisions made on their response
+func (s *Service) load(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (*workflow.Runtime, workflow.Answers, workflow.Approvals, error) {
+	runtime, err := s.workflowStore.ActiveRuntime(ctx, formID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	saved, err := s.answerStore.GetAnswersByFormIDAndSubmittedBy(ctx, formID, userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	answers := make(workflow.Answers, len(saved))
+	for _, answer := range saved {
+		answers[answer.QuestionID.String()] = answer.Value
+	}
+
+	approvals, err := s.approvalStore.Decisions(ctx, formID, userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return runtime, answers, approvals, nil
+}
+
+func newProgress(formID uuid.UUID, traversal workflow.Traversal, updatedAt pgtype.Timestamptz) Progress {
+	visited := make([]string, len(traversal.Steps))
+	for i, step := range traversal.Steps {
+		visited[i] = step.NodeID
+	}
+
+	progress := Progress{
+		FormID:       formID,
+		VisitedNodes: visited,
+		Traversal:    traversal,
+		UpdatedAt:    updatedAt,
+	}
+	if len(traversal.Steps) > 0 {
+		last := traversal.Steps[len(traversal.Steps)-1]
+		if last.Type == string(workflow.NodeTypeSection) {
+			// Section node IDs are the IDs of their sections
+			progress.CurrentSectionID, _ = uuid.Parse(last.NodeID)
+		}
+	}
+
+	return progress
+}
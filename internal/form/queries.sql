@@ -1,7 +1,7 @@
 -- name: Create :one
 WITH created AS (
-    INSERT INTO forms (title, description, preview_message, unit_id, last_editor, deadline)
-    VALUES ($1, $2, $3, $4, $5, $6)
+    INSERT INTO forms (title, description, preview_message, unit_id, last_editor, deadline, randomize_questions, shuffle_choices, time_limit_seconds)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
     RETURNING *
 ),
 workflow_created AS (
@@ -42,7 +42,8 @@ LEFT JOIN users_with_emails usr ON f.last_editor = usr.id;
 -- name: Update :one
 WITH updated AS (
     UPDATE forms
-    SET title = $2, description = $3, preview_message = $4, last_editor = $5, deadline = $6, updated_at = now()
+    SET title = $2, description = $3, preview_message = $4, last_editor = $5, deadline = $6,
+        randomize_questions = $7, shuffle_choices = $8, time_limit_seconds = $9, updated_at = now()
     WHERE forms.id = $1
     RETURNING *
 )
@@ -85,11 +86,18 @@ SELECT
     usr.name as last_editor_name,
     usr.username as last_editor_username,
     usr.avatar_url as last_editor_avatar_url,
-    usr.emails as last_editor_email
+    usr.emails as last_editor_email,
+    (SELECT COUNT(*) FROM form_responses fr WHERE fr.form_id = f.id AND NOT fr.is_test)::bigint as response_count,
+    ARRAY(SELECT ft.tag_id FROM form_tags ft WHERE ft.form_id = f.id)::uuid[] as tag_ids
 FROM forms f
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 LEFT JOIN users_with_emails usr ON f.last_editor = usr.id
+WHERE (cardinality(@statuses::text[]) = 0 OR f.status::text = ANY(@statuses::text[]))
+  AND (cardinality(@unit_ids::uuid[]) = 0 OR f.unit_id = ANY(@unit_ids::uuid[]))
+  AND (cardinality(@tag_ids::uuid[]) = 0 OR EXISTS (
+    SELECT 1 FROM form_tags ft WHERE ft.form_id = f.id AND ft.tag_id = ANY(@tag_ids::uuid[])
+  ))
 ORDER BY f.updated_at DESC;
 
 -- name: ListByUnit :many
@@ -112,4 +120,8 @@ ORDER BY f.updated_at DESC;
 UPDATE forms
 SET status = $2, last_editor = $3, updated_at = now()
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: IsHeld :one
+-- Any active legal hold on the form or one of its responses blocks deleting the form
+SELECT EXISTS(SELECT 1 FROM legal_holds WHERE form_id = $1 AND released_at IS NULL);
\ No newline at end of file
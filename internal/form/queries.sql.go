@@ -14,9 +14,9 @@ import (
 
 const create = `-- name: Create :one
 WITH created AS (
-    INSERT INTO forms (title, description, preview_message, unit_id, last_editor, deadline)
-    VALUES ($1, $2, $3, $4, $5, $6)
-    RETURNING id, title, description, preview_message, status, unit_id, last_editor, deadline, created_at, updated_at
+    INSERT INTO forms (title, description, preview_message, unit_id, last_editor, deadline, randomize_questions, shuffle_choices, time_limit_seconds)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    RETURNING id, title, description, preview_message, status, unit_id, last_editor, deadline, created_at, updated_at, randomize_questions, shuffle_choices, time_limit_seconds
 ),
 workflow_created AS (
     INSERT INTO workflow_versions (form_id, last_editor, workflow)
@@ -41,7 +41,7 @@ workflow_created AS (
     ) AS node_ids
 )
 SELECT 
-    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at,
+    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at, f.randomize_questions, f.shuffle_choices, f.time_limit_seconds,
     u.name as unit_name,
     o.name as org_name,
     usr.name as last_editor_name,
@@ -55,12 +55,15 @@ LEFT JOIN users_with_emails usr ON f.last_editor = usr.id
 `
 
 type CreateParams struct {
-	Title          string
-	Description    pgtype.Text
-	PreviewMessage pgtype.Text
-	UnitID         pgtype.UUID
-	LastEditor     uuid.UUID
-	Deadline       pgtype.Timestamptz
+	Title              string
+	Description        pgtype.Text
+	PreviewMessage     pgtype.Text
+	UnitID             pgtype.UUID
+	LastEditor         uuid.UUID
+	Deadline           pgtype.Timestamptz
+	RandomizeQuestions bool
+	ShuffleChoices     bool
+	TimeLimitSeconds   pgtype.Int4
 }
 
 type CreateRow struct {
@@ -74,6 +77,9 @@ type CreateRow struct {
 	Deadline            pgtype.Timestamptz
 	CreatedAt           pgtype.Timestamptz
 	UpdatedAt           pgtype.Timestamptz
+	RandomizeQuestions  bool
+	ShuffleChoices      bool
+	TimeLimitSeconds    pgtype.Int4
 	UnitName            pgtype.Text
 	OrgName             pgtype.Text
 	LastEditorName      pgtype.Text
@@ -90,6 +96,9 @@ func (q *Queries) Create(ctx context.Context, arg CreateParams) (CreateRow, erro
 		arg.UnitID,
 		arg.LastEditor,
 		arg.Deadline,
+		arg.RandomizeQuestions,
+		arg.ShuffleChoices,
+		arg.TimeLimitSeconds,
 	)
 	var i CreateRow
 	err := row.Scan(
@@ -103,6 +112,9 @@ func (q *Queries) Create(ctx context.Context, arg CreateParams) (CreateRow, erro
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RandomizeQuestions,
+		&i.ShuffleChoices,
+		&i.TimeLimitSeconds,
 		&i.UnitName,
 		&i.OrgName,
 		&i.LastEditorName,
@@ -124,7 +136,7 @@ func (q *Queries) Delete(ctx context.Context, id uuid.UUID) error {
 
 const getByID = `-- name: GetByID :one
 SELECT 
-    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at,
+    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at, f.randomize_questions, f.shuffle_choices, f.time_limit_seconds,
     u.name as unit_name,
     o.name as org_name,
     usr.name as last_editor_name,
@@ -149,6 +161,9 @@ type GetByIDRow struct {
 	Deadline            pgtype.Timestamptz
 	CreatedAt           pgtype.Timestamptz
 	UpdatedAt           pgtype.Timestamptz
+	RandomizeQuestions  bool
+	ShuffleChoices      bool
+	TimeLimitSeconds    pgtype.Int4
 	UnitName            pgtype.Text
 	OrgName             pgtype.Text
 	LastEditorName      pgtype.Text
@@ -171,6 +186,9 @@ func (q *Queries) GetByID(ctx context.Context, id uuid.UUID) (GetByIDRow, error)
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RandomizeQuestions,
+		&i.ShuffleChoices,
+		&i.TimeLimitSeconds,
 		&i.UnitName,
 		&i.OrgName,
 		&i.LastEditorName,
@@ -181,22 +199,47 @@ func (q *Queries) GetByID(ctx context.Context, id uuid.UUID) (GetByIDRow, error)
 	return i, err
 }
 
+const isHeld = `-- name: IsHeld :one
+SELECT EXISTS(SELECT 1 FROM legal_holds WHERE form_id = $1 AND released_at IS NULL)
+`
+
+// Any active legal hold on the form or one of its responses blocks deleting the form
+func (q *Queries) IsHeld(ctx context.Context, formID uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isHeld, formID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const list = `-- name: List :many
 SELECT 
-    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at,
+    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at, f.randomize_questions, f.shuffle_choices, f.time_limit_seconds,
     u.name as unit_name,
     o.name as org_name,
     usr.name as last_editor_name,
     usr.username as last_editor_username,
     usr.avatar_url as last_editor_avatar_url,
-    usr.emails as last_editor_email
+    usr.emails as last_editor_email,
+    (SELECT COUNT(*) FROM form_responses fr WHERE fr.form_id = f.id AND NOT fr.is_test)::bigint as response_count,
+    ARRAY(SELECT ft.tag_id FROM form_tags ft WHERE ft.form_id = f.id)::uuid[] as tag_ids
 FROM forms f
 LEFT JOIN units u ON f.unit_id = u.id
 LEFT JOIN units o ON u.org_id = o.id
 LEFT JOIN users_with_emails usr ON f.last_editor = usr.id
+WHERE (cardinality($1::text[]) = 0 OR f.status::text = ANY($1::text[]))
+  AND (cardinality($2::uuid[]) = 0 OR f.unit_id = ANY($2::uuid[]))
+  AND (cardinality($3::uuid[]) = 0 OR EXISTS (
+    SELECT 1 FROM form_tags ft WHERE ft.form_id = f.id AND ft.tag_id = ANY($3::uuid[])
+  ))
 ORDER BY f.updated_at DESC
 `
 
+type ListParams struct {
+	Statuses []string
+	UnitIds  []uuid.UUID
+	TagIds   []uuid.UUID
+}
+
 type ListRow struct {
 	ID                  uuid.UUID
 	Title               string
@@ -208,16 +251,21 @@ type ListRow struct {
 	Deadline            pgtype.Timestamptz
 	CreatedAt           pgtype.Timestamptz
 	UpdatedAt           pgtype.Timestamptz
+	RandomizeQuestions  bool
+	ShuffleChoices      bool
+	TimeLimitSeconds    pgtype.Int4
 	UnitName            pgtype.Text
 	OrgName             pgtype.Text
 	LastEditorName      pgtype.Text
 	LastEditorUsername  pgtype.Text
 	LastEditorAvatarUrl pgtype.Text
 	LastEditorEmail     interface{}
+	ResponseCount       int64
+	TagIds              []uuid.UUID
 }
 
-func (q *Queries) List(ctx context.Context) ([]ListRow, error) {
-	rows, err := q.db.Query(ctx, list)
+func (q *Queries) List(ctx context.Context, arg ListParams) ([]ListRow, error) {
+	rows, err := q.db.Query(ctx, list, arg.Statuses, arg.UnitIds, arg.TagIds)
 	if err != nil {
 		return nil, err
 	}
@@ -236,12 +284,17 @@ func (q *Queries) List(ctx context.Context) ([]ListRow, error) {
 			&i.Deadline,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RandomizeQuestions,
+			&i.ShuffleChoices,
+			&i.TimeLimitSeconds,
 			&i.UnitName,
 			&i.OrgName,
 			&i.LastEditorName,
 			&i.LastEditorUsername,
 			&i.LastEditorAvatarUrl,
 			&i.LastEditorEmail,
+			&i.ResponseCount,
+			&i.TagIds,
 		); err != nil {
 			return nil, err
 		}
@@ -255,7 +308,7 @@ func (q *Queries) List(ctx context.Context) ([]ListRow, error) {
 
 const listByUnit = `-- name: ListByUnit :many
 SELECT 
-    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at,
+    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at, f.randomize_questions, f.shuffle_choices, f.time_limit_seconds,
     u.name as unit_name,
     o.name as org_name,
     usr.name as last_editor_name,
@@ -281,6 +334,9 @@ type ListByUnitRow struct {
 	Deadline            pgtype.Timestamptz
 	CreatedAt           pgtype.Timestamptz
 	UpdatedAt           pgtype.Timestamptz
+	RandomizeQuestions  bool
+	ShuffleChoices      bool
+	TimeLimitSeconds    pgtype.Int4
 	UnitName            pgtype.Text
 	OrgName             pgtype.Text
 	LastEditorName      pgtype.Text
@@ -309,6 +365,9 @@ func (q *Queries) ListByUnit(ctx context.Context, unitID pgtype.UUID) ([]ListByU
 			&i.Deadline,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RandomizeQuestions,
+			&i.ShuffleChoices,
+			&i.TimeLimitSeconds,
 			&i.UnitName,
 			&i.OrgName,
 			&i.LastEditorName,
@@ -330,7 +389,7 @@ const setStatus = `-- name: SetStatus :one
 UPDATE forms
 SET status = $2, last_editor = $3, updated_at = now()
 WHERE id = $1
-RETURNING id, title, description, preview_message, status, unit_id, last_editor, deadline, created_at, updated_at
+RETURNING id, title, description, preview_message, status, unit_id, last_editor, deadline, created_at, updated_at, randomize_questions, shuffle_choices, time_limit_seconds
 `
 
 type SetStatusParams struct {
@@ -353,6 +412,9 @@ func (q *Queries) SetStatus(ctx context.Context, arg SetStatusParams) (Form, err
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RandomizeQuestions,
+		&i.ShuffleChoices,
+		&i.TimeLimitSeconds,
 	)
 	return i, err
 }
@@ -360,12 +422,13 @@ func (q *Queries) SetStatus(ctx context.Context, arg SetStatusParams) (Form, err
 const update = `-- name: Update :one
 WITH updated AS (
     UPDATE forms
-    SET title = $2, description = $3, preview_message = $4, last_editor = $5, deadline = $6, updated_at = now()
+    SET title = $2, description = $3, preview_message = $4, last_editor = $5, deadline = $6,
+        randomize_questions = $7, shuffle_choices = $8, time_limit_seconds = $9, updated_at = now()
     WHERE forms.id = $1
-    RETURNING id, title, description, preview_message, status, unit_id, last_editor, deadline, created_at, updated_at
+    RETURNING id, title, description, preview_message, status, unit_id, last_editor, deadline, created_at, updated_at, randomize_questions, shuffle_choices, time_limit_seconds
 )
 SELECT 
-    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at,
+    f.id, f.title, f.description, f.preview_message, f.status, f.unit_id, f.last_editor, f.deadline, f.created_at, f.updated_at, f.randomize_questions, f.shuffle_choices, f.time_limit_seconds,
     u.name as unit_name,
     o.name as org_name,
     usr.name as last_editor_name,
@@ -379,12 +442,15 @@ LEFT JOIN users_with_emails usr ON f.last_editor = usr.id
 `
 
 type UpdateParams struct {
-	ID             uuid.UUID
-	Title          string
-	Description    pgtype.Text
-	PreviewMessage pgtype.Text
-	LastEditor     uuid.UUID
-	Deadline       pgtype.Timestamptz
+	ID                 uuid.UUID
+	Title              string
+	Description        pgtype.Text
+	PreviewMessage     pgtype.Text
+	LastEditor         uuid.UUID
+	Deadline           pgtype.Timestamptz
+	RandomizeQuestions bool
+	ShuffleChoices     bool
+	TimeLimitSeconds   pgtype.Int4
 }
 
 type UpdateRow struct {
@@ -398,6 +464,9 @@ type UpdateRow struct {
 	Deadline            pgtype.Timestamptz
 	CreatedAt           pgtype.Timestamptz
 	UpdatedAt           pgtype.Timestamptz
+	RandomizeQuestions  bool
+	ShuffleChoices      bool
+	TimeLimitSeconds    pgtype.Int4
 	UnitName            pgtype.Text
 	OrgName             pgtype.Text
 	LastEditorName      pgtype.Text
@@ -414,6 +483,9 @@ func (q *Queries) Update(ctx context.Context, arg UpdateParams) (UpdateRow, erro
 		arg.PreviewMessage,
 		arg.LastEditor,
 		arg.Deadline,
+		arg.RandomizeQuestions,
+		arg.ShuffleChoices,
+		arg.TimeLimitSeconds,
 	)
 	var i UpdateRow
 	err := row.Scan(
@@ -427,6 +499,9 @@ func (q *Queries) Update(ctx context.Context, arg UpdateParams) (UpdateRow, erro
 		&i.Deadline,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RandomizeQuestions,
+		&i.ShuffleChoices,
+		&i.TimeLimitSeconds,
 		&i.UnitName,
 		&i.OrgName,
 		&i.LastEditorName,
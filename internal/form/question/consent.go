@@ -0,0 +1,102 @@
+package question
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ConsentOption is a consent block: terms in markdown the respondent has to accept.
+// Raising the version when the terms change makes earlier acceptances stale.
+type ConsentOption struct {
+	Text    string `json:"text" validate:"required,max=20000"`
+	Version string `json:"version" validate:"required,max=100"`
+}
+
+// Consent is answered with the version of the terms the respondent accepted; when it
+// was accepted is the time the answer was saved. Consent questions are always required.
+type Consent struct {
+	question Question
+	formID   uuid.UUID
+	Text     string
+	Version  string
+}
+
+func (c Consent) Question() Question {
+	return c.question
+}
+
+func (c Consent) FormID() uuid.UUID {
+	return c.formID
+}
+
+func (c Consent) Validate(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	if value != c.Version {
+		return ErrConsentVersionMismatch{
+			QuestionID: c.question.ID.String(),
+			Expected:   c.Version,
+			Given:      value,
+		}
+	}
+
+	return nil
+}
+
+func NewConsent(q Question, formID uuid.UUID) (Consent, error) {
+	if q.Metadata == nil {
+		return Consent{}, ErrMetadataBroken{QuestionID: q.ID.String(), RawData: q.Metadata, Message: "metadata is nil"}
+	}
+
+	consent, err := ExtractConsent(q.Metadata)
+	if err != nil {
+		return Consent{}, ErrMetadataBroken{QuestionID: q.ID.String(), RawData: q.Metadata, Message: "could not extract consent"}
+	}
+
+	if consent.Text == "" || consent.Version == "" {
+		return Consent{}, ErrMetadataBroken{QuestionID: q.ID.String(), RawData: q.Metadata, Message: "consent needs a text and a version"}
+	}
+
+	return Consent{
+		question: q,
+		formID:   formID,
+		Text:     consent.Text,
+		Version:  consent.Version,
+	}, nil
+}
+
+func GenerateConsentMetadata(option ConsentOption) ([]byte, error) {
+	if option.Text == "" || option.Version == "" {
+		return nil, ErrMetadataValidate{
+			QuestionID: "consent",
+			RawData:    []byte(fmt.Sprintf("%v", option)),
+			Message:    "consent needs a text and a version",
+		}
+	}
+
+	metadata := map[string]any{
+		"consent": option,
+	}
+
+	return json.Marshal(metadata)
+}
+
+func ExtractConsent(data []byte) (ConsentOption, error) {
+	var partial map[string]json.RawMessage
+	if err := json.Unmarshal(data, &partial); err != nil {
+		return ConsentOption{}, fmt.Errorf("could not parse partial json: %w", err)
+	}
+
+	var consent ConsentOption
+	if raw, ok := partial["consent"]; ok {
+		if err := json.Unmarshal(raw, &consent); err != nil {
+			return ConsentOption{}, fmt.Errorf("could not parse consent: %w", err)
+		}
+	}
+
+	return consent, nil
+}
@@ -49,6 +49,18 @@ func (e ErrInvalidDateFormat) Error() string {
 	return fmt.Sprintf("invalid date format for question %s: %s, raw value: %s", e.QuestionID, e.Message, e.RawValue)
 }
 
+// ErrConsentVersionMismatch is returned when a respondent accepted terms other than
+// the current ones, for example because the form was edited after they loaded it.
+type ErrConsentVersionMismatch struct {
+	QuestionID string
+	Expected   string
+	Given      string
+}
+
+func (e ErrConsentVersionMismatch) Error() string {
+	return fmt.Sprintf("consent for question %s accepted version %s, current version is %s", e.QuestionID, e.Given, e.Expected)
+}
+
 // ErrMetadataBroken is returned when stored metadata is corrupted and cannot be recovered.
 type ErrMetadataBroken struct {
 	QuestionID string
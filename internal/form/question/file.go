@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/google/uuid"
@@ -81,6 +82,35 @@ const (
 	FileSizeLimit1GB   FileSizeLimit = "1GB"
 )
 
+// Bytes returns the size limit in bytes, or 0 for an unknown limit
+func (l FileSizeLimit) Bytes() int64 {
+	switch l {
+	case FileSizeLimit1MB:
+		return 1 << 20
+	case FileSizeLimit5MB:
+		return 5 << 20
+	case FileSizeLimit10MB:
+		return 10 << 20
+	case FileSizeLimit100MB:
+		return 100 << 20
+	case FileSizeLimit1GB:
+		return 1 << 30
+	default:
+		return 0
+	}
+}
+
+// Allows reports whether a file name has one of the allowed file type extensions
+func (u UploadFile) Allows(filename string) bool {
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	for _, allowed := range u.AllowedFileTypes {
+		if FileType(extension) == allowed {
+			return true
+		}
+	}
+	return false
+}
+
 // UploadFileOption represents the request from frontend
 type UploadFileOption struct {
 	AllowedFileTypes []string `json:"allowedFileTypes" validate:"required"`
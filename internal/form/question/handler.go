@@ -2,9 +2,11 @@ package question
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,7 +23,7 @@ import (
 
 type Request struct {
 	Required     *bool            `json:"required" validate:"required"`
-	Type         string           `json:"type" validate:"required,oneof=SHORT_TEXT LONG_TEXT SINGLE_CHOICE MULTIPLE_CHOICE DATE DROPDOWN DETAILED_MULTIPLE_CHOICE UPLOAD_FILE LINEAR_SCALE RATING RANKING OAUTH_CONNECT HYPERLINK"`
+	Type         string           `json:"type" validate:"required,oneof=SHORT_TEXT LONG_TEXT SINGLE_CHOICE MULTIPLE_CHOICE DATE DROPDOWN DETAILED_MULTIPLE_CHOICE UPLOAD_FILE LINEAR_SCALE RATING RANKING OAUTH_CONNECT HYPERLINK CONSENT"`
 	Title        string           `json:"title" validate:"required"`
 	Description  string           `json:"description"`
 	Order        int32            `json:"order" validate:"required,min=1"`
@@ -29,7 +31,10 @@ type Request struct {
 	Scale        ScaleOption      `json:"scale,omitempty" validate:"omitempty,required_if=Type LINEAR_SCALE,required_if=Type RATING"`
 	UploadFile   UploadFileOption `json:"uploadFile,omitempty" validate:"omitempty,required_if=Type UPLOAD_FILE"`
 	OauthConnect string           `json:"oauthConnect,omitempty" validate:"required_if=Type OAUTH_CONNECT"`
+	Consent      *ConsentOption   `json:"consent,omitempty" validate:"required_if=Type CONSENT"`
 	SourceID     uuid.UUID        `json:"sourceId,omitempty"`
+	// AnswerMigration is only used on update, when the question type changes
+	AnswerMigration string `json:"answerMigration,omitempty" validate:"omitempty,oneof=PRESERVE CLEAR CONVERT"`
 }
 
 type Response struct {
@@ -43,6 +48,7 @@ type Response struct {
 	Scale        *ScaleOption      `json:"scale,omitempty"`
 	UploadFile   *UploadFileOption `json:"uploadFile,omitempty"`
 	OauthConnect string            `json:"oauthConnect,omitempty"`
+	Consent      *ConsentOption    `json:"consent,omitempty"`
 	SourceID     string            `json:"sourceId,omitempty"`
 	CreatedAt    time.Time         `json:"createdAt"`
 	UpdatedAt    time.Time         `json:"updatedAt"`
@@ -142,6 +148,16 @@ func ToResponse(answerable Answerable) (Response, error) {
 			}
 		}
 		response.OauthConnect = string(provider)
+	case QuestionTypeConsent:
+		consent, err := ExtractConsent(q.Metadata)
+		if err != nil {
+			return response, ErrInvalidMetadata{
+				QuestionID: q.ID.String(),
+				RawData:    q.Metadata,
+				Message:    err.Error(),
+			}
+		}
+		response.Consent = &consent
 	}
 
 	return response, nil
@@ -149,10 +165,11 @@ func ToResponse(answerable Answerable) (Response, error) {
 
 type Store interface {
 	Create(ctx context.Context, input CreateParams) (Answerable, error)
-	Update(ctx context.Context, input UpdateParams) (Answerable, error)
+	Update(ctx context.Context, input UpdateParams, migration AnswerMigration) (Answerable, error)
 	UpdateOrder(ctx context.Context, input UpdateOrderParams) (Answerable, error)
 	DeleteAndReorder(ctx context.Context, sectionID uuid.UUID, id uuid.UUID) error
 	ListByFormID(ctx context.Context, formID uuid.UUID) ([]SectionWithQuestions, error)
+	GetOrderOptions(ctx context.Context, formID uuid.UUID) (OrderOptions, error)
 }
 
 type Handler struct {
@@ -193,14 +210,14 @@ func (h *Handler) AddHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req Request
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 	req.Type = strings.ToLower(req.Type)
 
 	// Generate and validate metadata (returns nil if source_id provided)
-	metadata, err := getGenerateMetadata(req)
+	metadata, err := GenerateMetadata(req)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to generate metadata: %w", err), logger)
 		return
@@ -208,7 +225,7 @@ func (h *Handler) AddHandler(w http.ResponseWriter, r *http.Request) {
 
 	request := CreateParams{
 		SectionID:   sectionID,
-		Required:    *req.Required,
+		Required:    req.IsRequired(),
 		Type:        QuestionType(req.Type),
 		Title:       pgtype.Text{String: req.Title, Valid: true},
 		Description: pgtype.Text{String: req.Description, Valid: true},
@@ -252,14 +269,14 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req Request
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 	req.Type = strings.ToLower(req.Type)
 
 	// Generate and validate metadata
-	metadata, err := getGenerateMetadata(req)
+	metadata, err := GenerateMetadata(req)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to update metadata: %w", err), logger)
 		return
@@ -268,7 +285,7 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	request := UpdateParams{
 		ID:          id,
 		SectionID:   sectionID,
-		Required:    *req.Required,
+		Required:    req.IsRequired(),
 		Type:        QuestionType(req.Type),
 		Title:       pgtype.Text{String: req.Title, Valid: true},
 		Description: pgtype.Text{String: req.Description, Valid: true},
@@ -276,7 +293,8 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 		SourceID:    pgtype.UUID{Bytes: req.SourceID, Valid: req.SourceID != uuid.Nil},
 	}
 
-	updatedQuestion, err := h.store.Update(traceCtx, request)
+	migration := AnswerMigration(strings.ToLower(req.AnswerMigration))
+	updatedQuestion, err := h.store.Update(traceCtx, request, migration)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -345,6 +363,18 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	shuffle, err := parseShuffleParam(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if shuffle && !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
 	sectionWithQuestions, err := h.store.ListByFormID(traceCtx, formID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
@@ -364,10 +394,42 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if shuffle {
+		options, err := h.store.GetOrderOptions(traceCtx, formID)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		Shuffle(responses, options, formID, currentUser.ID)
+	}
+
 	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
 }
 
-func getGenerateMetadata(req Request) ([]byte, error) {
+// parseShuffleParam reads ?shuffle=true, which respondents pass to see the form in the
+// order its randomization options give them; editors leave it out for the authored order
+func parseShuffleParam(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("shuffle")
+	if value == "" {
+		return false, nil
+	}
+	shuffle, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: shuffle must be a boolean", internal.ErrInvalidQueryParameter)
+	}
+	return shuffle, nil
+}
+
+// IsRequired reports whether the question of the request, whose Type is already lower
+// case, is required; a consent block always is, since the form may not be submitted
+// without it
+func (r Request) IsRequired() bool {
+	return *r.Required || r.Type == string(QuestionTypeConsent)
+}
+
+// GenerateMetadata validates the type specific options of a question request, whose Type
+// is already lower case, and encodes them as the question metadata
+func GenerateMetadata(req Request) ([]byte, error) {
 	// If source_id is provided, don't generate metadata
 	if req.SourceID != uuid.Nil {
 		switch req.Type {
@@ -392,6 +454,11 @@ func getGenerateMetadata(req Request) ([]byte, error) {
 		return GenerateRatingMetadata(req.Scale)
 	case "oauth_connect":
 		return GenerateOauthConnectMetadata(req.OauthConnect)
+	case "consent":
+		if req.Consent == nil {
+			return GenerateConsentMetadata(ConsentOption{})
+		}
+		return GenerateConsentMetadata(*req.Consent)
 	case "upload_file":
 		return GenerateUploadFileMetadata(req.UploadFile)
 	default:
@@ -0,0 +1,133 @@
+package question
+
+import (
+	"strings"
+)
+
+// AnswerMigration is the strategy applied to existing answers when a question changes type
+type AnswerMigration string
+
+const (
+	// AnswerMigrationPreserve keeps answers untouched; they keep the type they were given for
+	AnswerMigrationPreserve AnswerMigration = "preserve"
+	// AnswerMigrationClear deletes every answer to the question
+	AnswerMigrationClear AnswerMigration = "clear"
+	// AnswerMigrationConvert rewrites answers for the new type and deletes the ones that cannot be converted
+	AnswerMigrationConvert AnswerMigration = "convert"
+)
+
+// Workflow condition rule sources. They mirror the workflow node package, which
+// depends on this package and cannot be imported here.
+const (
+	conditionSourceChoice    = "choice"
+	conditionSourceNonChoice = "nonChoice"
+)
+
+// SupportsConditionSource reports whether a workflow condition rule with the given
+// source can reference a question of the given type.
+func SupportsConditionSource(source string, questionType QuestionType) bool {
+	switch source {
+	case conditionSourceChoice:
+		return questionType == QuestionTypeSingleChoice || questionType == QuestionTypeMultipleChoice
+	case conditionSourceNonChoice:
+		return questionType == QuestionTypeShortText || questionType == QuestionTypeLongText || questionType == QuestionTypeDate
+	default:
+		return false
+	}
+}
+
+// convertAnswer converts an answer given for the from question into a value for the
+// to question. Choice answers are carried over by option name, so a selected option
+// becomes its name in a text question and a text answer matching an option name
+// selects that option. Returns false when the result is not valid for the new type.
+func convertAnswer(from Answerable, to Answerable, value string) (string, bool) {
+	if strings.TrimSpace(value) == "" {
+		return value, true
+	}
+
+	parts := []string{value}
+	if fromChoices, ok := choicesOf(from); ok {
+		names, ok := choiceNames(fromChoices, value)
+		if !ok {
+			return "", false
+		}
+		parts = names
+	}
+
+	var converted string
+	if toChoices, ok := choicesOf(to); ok {
+		ids, ok := choiceIDs(toChoices, parts)
+		if !ok {
+			return "", false
+		}
+		converted = strings.Join(ids, ";")
+	} else {
+		converted = strings.Join(parts, ", ")
+	}
+
+	err := to.Validate(converted)
+	if err != nil {
+		return "", false
+	}
+
+	return converted, true
+}
+
+// choicesOf returns the options of choice-based questions
+func choicesOf(answerable Answerable) ([]Choice, bool) {
+	switch q := answerable.(type) {
+	case SingleChoice:
+		return q.Choices, true
+	case MultiChoice:
+		return q.Choices, true
+	case DetailedMultiChoice:
+		return q.Choices, true
+	case Ranking:
+		return q.Rank, true
+	default:
+		return nil, false
+	}
+}
+
+// choiceNames maps a ";"-separated list of choice IDs to the option names
+func choiceNames(choices []Choice, value string) ([]string, bool) {
+	var names []string
+	for _, id := range strings.Split(value, ";") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+
+		found := false
+		for _, choice := range choices {
+			if choice.ID.String() == id {
+				names = append(names, choice.Name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return names, true
+}
+
+// choiceIDs maps option names to choice IDs, ignoring case and surrounding spaces
+func choiceIDs(choices []Choice, names []string) ([]string, bool) {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, choice := range choices {
+			if strings.EqualFold(strings.TrimSpace(choice.Name), strings.TrimSpace(name)) {
+				ids = append(ids, choice.ID.String())
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return ids, true
+}
@@ -139,4 +139,39 @@ SELECT
     s.form_id
 FROM questions q
 JOIN sections s ON q.section_id = s.id
-WHERE q.id = $1;
\ No newline at end of file
+WHERE q.id = $1;
+
+-- name: ListConditionSourcesByQuestionID :many
+SELECT DISTINCT (node->'conditionRule'->>'source')::TEXT AS source
+FROM workflow_versions wv
+CROSS JOIN LATERAL jsonb_array_elements(wv.workflow) AS node
+WHERE wv.form_id = @form_id
+  AND (wv.is_active OR wv.id = (
+      SELECT latest.id FROM workflow_versions latest
+      WHERE latest.form_id = @form_id
+      ORDER BY latest.updated_at DESC
+      LIMIT 1
+  ))
+  AND node->>'type' = 'condition'
+  AND node->'conditionRule'->>'key' = @question_id::TEXT;
+
+-- name: ListAnswersByQuestionID :many
+SELECT id, value FROM answers
+WHERE question_id = $1;
+
+-- name: UpdateAnswerValue :exec
+UPDATE answers
+SET type = @type, value = @value, updated_at = now()
+WHERE id = @id;
+
+-- name: DeleteAnswer :exec
+DELETE FROM answers
+WHERE id = $1;
+
+-- name: DeleteAnswersByQuestionID :exec
+DELETE FROM answers
+WHERE question_id = $1;
+
+-- name: GetOrderOptions :one
+SELECT randomize_questions, shuffle_choices FROM forms
+WHERE id = $1;
\ No newline at end of file
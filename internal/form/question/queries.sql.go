@@ -113,6 +113,26 @@ func (q *Queries) DeleteAndReorder(ctx context.Context, arg DeleteAndReorderPara
 	return err
 }
 
+const deleteAnswer = `-- name: DeleteAnswer :exec
+DELETE FROM answers
+WHERE id = $1
+`
+
+func (q *Queries) DeleteAnswer(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteAnswer, id)
+	return err
+}
+
+const deleteAnswersByQuestionID = `-- name: DeleteAnswersByQuestionID :exec
+DELETE FROM answers
+WHERE question_id = $1
+`
+
+func (q *Queries) DeleteAnswersByQuestionID(ctx context.Context, questionID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteAnswersByQuestionID, questionID)
+	return err
+}
+
 const getByID = `-- name: GetByID :one
 SELECT 
     q.id,
@@ -167,6 +187,53 @@ func (q *Queries) GetByID(ctx context.Context, id uuid.UUID) (GetByIDRow, error)
 	return i, err
 }
 
+const getOrderOptions = `-- name: GetOrderOptions :one
+SELECT randomize_questions, shuffle_choices FROM forms
+WHERE id = $1
+`
+
+type GetOrderOptionsRow struct {
+	RandomizeQuestions bool
+	ShuffleChoices     bool
+}
+
+func (q *Queries) GetOrderOptions(ctx context.Context, id uuid.UUID) (GetOrderOptionsRow, error) {
+	row := q.db.QueryRow(ctx, getOrderOptions, id)
+	var i GetOrderOptionsRow
+	err := row.Scan(&i.RandomizeQuestions, &i.ShuffleChoices)
+	return i, err
+}
+
+const listAnswersByQuestionID = `-- name: ListAnswersByQuestionID :many
+SELECT id, value FROM answers
+WHERE question_id = $1
+`
+
+type ListAnswersByQuestionIDRow struct {
+	ID    uuid.UUID
+	Value string
+}
+
+func (q *Queries) ListAnswersByQuestionID(ctx context.Context, questionID uuid.UUID) ([]ListAnswersByQuestionIDRow, error) {
+	rows, err := q.db.Query(ctx, listAnswersByQuestionID, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAnswersByQuestionIDRow
+	for rows.Next() {
+		var i ListAnswersByQuestionIDRow
+		if err := rows.Scan(&i.ID, &i.Value); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listByFormID = `-- name: ListByFormID :many
 SELECT
     s.id as section_id,
@@ -252,6 +319,46 @@ func (q *Queries) ListByFormID(ctx context.Context, formID uuid.UUID) ([]ListByF
 	return items, nil
 }
 
+const listConditionSourcesByQuestionID = `-- name: ListConditionSourcesByQuestionID :many
+SELECT DISTINCT (node->'conditionRule'->>'source')::TEXT AS source
+FROM workflow_versions wv
+CROSS JOIN LATERAL jsonb_array_elements(wv.workflow) AS node
+WHERE wv.form_id = $1
+  AND (wv.is_active OR wv.id = (
+      SELECT latest.id FROM workflow_versions latest
+      WHERE latest.form_id = $1
+      ORDER BY latest.updated_at DESC
+      LIMIT 1
+  ))
+  AND node->>'type' = 'condition'
+  AND node->'conditionRule'->>'key' = $2::TEXT
+`
+
+type ListConditionSourcesByQuestionIDParams struct {
+	FormID     uuid.UUID
+	QuestionID string
+}
+
+func (q *Queries) ListConditionSourcesByQuestionID(ctx context.Context, arg ListConditionSourcesByQuestionIDParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, listConditionSourcesByQuestionID, arg.FormID, arg.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var source string
+		if err := rows.Scan(&source); err != nil {
+			return nil, err
+		}
+		items = append(items, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const update = `-- name: Update :one
 WITH updated AS (
     UPDATE questions
@@ -331,6 +438,23 @@ func (q *Queries) Update(ctx context.Context, arg UpdateParams) (UpdateRow, erro
 	return i, err
 }
 
+const updateAnswerValue = `-- name: UpdateAnswerValue :exec
+UPDATE answers
+SET type = $1, value = $2, updated_at = now()
+WHERE id = $3
+`
+
+type UpdateAnswerValueParams struct {
+	Type  QuestionType
+	Value string
+	ID    uuid.UUID
+}
+
+func (q *Queries) UpdateAnswerValue(ctx context.Context, arg UpdateAnswerValueParams) error {
+	_, err := q.db.Exec(ctx, updateAnswerValue, arg.Type, arg.Value, arg.ID)
+	return err
+}
+
 const updateOrder = `-- name: UpdateOrder :one
 WITH shifted AS (
     UPDATE questions
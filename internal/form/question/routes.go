@@ -0,0 +1,14 @@
+package question
+
+import (
+	"NYCU-SDC/core-system-backend/internal/conditional"
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the sections of a form and their questions
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/sections", route.Respondent, route.PermissionNone, conditional.Middleware(h.ListHandler))
+	r.Handle("POST /sections/{id}/questions", route.Delegate, route.PermissionNone, h.AddHandler)
+	r.Handle("PUT /sections/{sectionId}/questions/{questionId}", route.Delegate, route.PermissionNone, h.UpdateHandler)
+	r.Handle("DELETE /sections/{sectionId}/questions/{questionId}", route.Delegate, route.PermissionNone, h.DeleteHandler)
+}
@@ -11,7 +11,8 @@ CREATE TYPE question_type AS ENUM(
     'rating',
     'ranking',
     'oauth_connect',
-    'hyperlink'
+    'hyperlink',
+    'consent'
 );
 
 CREATE TABLE IF NOT EXISTS questions(
@@ -1,13 +1,17 @@
 package question
 
 import (
+	"NYCU-SDC/core-system-backend/internal"
 	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"slices"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -20,6 +24,12 @@ type Querier interface {
 	DeleteAndReorder(ctx context.Context, arg DeleteAndReorderParams) error
 	ListByFormID(ctx context.Context, formID uuid.UUID) ([]ListByFormIDRow, error)
 	GetByID(ctx context.Context, id uuid.UUID) (GetByIDRow, error)
+	ListConditionSourcesByQuestionID(ctx context.Context, arg ListConditionSourcesByQuestionIDParams) ([]string, error)
+	ListAnswersByQuestionID(ctx context.Context, questionID uuid.UUID) ([]ListAnswersByQuestionIDRow, error)
+	UpdateAnswerValue(ctx context.Context, arg UpdateAnswerValueParams) error
+	DeleteAnswer(ctx context.Context, id uuid.UUID) error
+	DeleteAnswersByQuestionID(ctx context.Context, questionID uuid.UUID) error
+	GetOrderOptions(ctx context.Context, id uuid.UUID) (GetOrderOptionsRow, error)
 }
 
 type Answerable interface {
@@ -62,11 +72,40 @@ func (s *Service) Create(ctx context.Context, input CreateParams) (Answerable, e
 	return NewAnswerable(row.ToQuestion(), row.FormID)
 }
 
-func (s *Service) Update(ctx context.Context, input UpdateParams) (Answerable, error) {
+// Update updates a question. When the question type changes, the new type must still
+// satisfy every workflow condition rule referencing the question, in both the latest
+// and the active workflow version, and the existing answers are migrated with the
+// given strategy. An empty strategy preserves the answers.
+func (s *Service) Update(ctx context.Context, input UpdateParams, migration AnswerMigration) (Answerable, error) {
 	ctx, span := s.tracer.Start(ctx, "Update")
 	defer span.End()
 	logger := logutil.WithContext(ctx, s.logger)
 
+	current, err := s.queries.GetByID(ctx, input.ID)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "get question by id")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	typeChanged := current.Type != input.Type
+	if typeChanged {
+		err = s.checkConditionCompatibility(ctx, current.FormID, input.ID, input.Type)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	var previous Answerable
+	if typeChanged && migration == AnswerMigrationConvert {
+		previous, err = NewAnswerable(current.ToQuestion(), current.FormID)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
 	row, err := s.queries.Update(ctx, input)
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "update question")
@@ -74,7 +113,99 @@ func (s *Service) Update(ctx context.Context, input UpdateParams) (Answerable, e
 		return nil, err
 	}
 
-	return NewAnswerable(row.ToQuestion(), row.FormID)
+	updated, err := NewAnswerable(row.ToQuestion(), row.FormID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if typeChanged {
+		err = s.migrateAnswers(ctx, previous, updated, migration)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+// checkConditionCompatibility rejects a question type that a workflow condition rule referencing the question cannot evaluate
+func (s *Service) checkConditionCompatibility(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, questionType QuestionType) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	sources, err := s.queries.ListConditionSourcesByQuestionID(ctx, ListConditionSourcesByQuestionIDParams{
+		FormID:     formID,
+		QuestionID: questionID.String(),
+	})
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "list workflow condition sources by question id")
+	}
+
+	for _, source := range sources {
+		if !SupportsConditionSource(source, questionType) {
+			return fmt.Errorf("%w: workflow condition with source '%s' references question %s, which cannot be of type '%s'", internal.ErrQuestionTypeIncompatible, source, questionID, questionType)
+		}
+	}
+
+	return nil
+}
+
+// migrateAnswers applies the answer migration strategy after a question changed type.
+// from is only needed, and only set, for AnswerMigrationConvert.
+func (s *Service) migrateAnswers(ctx context.Context, from Answerable, to Answerable, migration AnswerMigration) error {
+	logger := logutil.WithContext(ctx, s.logger)
+	q := to.Question()
+
+	switch migration {
+	case AnswerMigrationClear:
+		err := s.queries.DeleteAnswersByQuestionID(ctx, q.ID)
+		if err != nil {
+			return databaseutil.WrapDBError(err, logger, "delete answers by question id")
+		}
+
+		logger.Info("Cleared answers after question type change",
+			zap.String("question_id", q.ID.String()),
+			zap.String("type", string(q.Type)))
+	case AnswerMigrationConvert:
+		answers, err := s.queries.ListAnswersByQuestionID(ctx, q.ID)
+		if err != nil {
+			return databaseutil.WrapDBError(err, logger, "list answers by question id")
+		}
+
+		converted, cleared := 0, 0
+		for _, answer := range answers {
+			value, ok := convertAnswer(from, to, answer.Value)
+			if !ok {
+				err = s.queries.DeleteAnswer(ctx, answer.ID)
+				if err != nil {
+					return databaseutil.WrapDBError(err, logger, "delete unconvertible answer")
+				}
+				cleared++
+				continue
+			}
+
+			err = s.queries.UpdateAnswerValue(ctx, UpdateAnswerValueParams{
+				Type:  q.Type,
+				Value: value,
+				ID:    answer.ID,
+			})
+			if err != nil {
+				return databaseutil.WrapDBError(err, logger, "update converted answer")
+			}
+			converted++
+		}
+
+		logger.Info("Converted answers after question type change",
+			zap.String("question_id", q.ID.String()),
+			zap.String("type", string(q.Type)),
+			zap.Int("converted", converted),
+			zap.Int("cleared", cleared))
+	default:
+		// Preserved answers keep the type they were given for
+	}
+
+	return nil
 }
 
 func (s *Service) UpdateOrder(ctx context.Context, input UpdateOrderParams) (Answerable, error) {
@@ -110,6 +241,29 @@ func (s *Service) DeleteAndReorder(ctx context.Context, sectionID uuid.UUID, id
 	return nil
 }
 
+// GetOrderOptions returns how the form shuffles questions and choices for respondents
+func (s *Service) GetOrderOptions(ctx context.Context, formID uuid.UUID) (OrderOptions, error) {
+	ctx, span := s.tracer.Start(ctx, "GetOrderOptions")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	row, err := s.queries.GetOrderOptions(ctx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrFormNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "get form order options")
+		}
+		span.RecordError(err)
+		return OrderOptions{}, err
+	}
+
+	return OrderOptions{
+		RandomizeQuestions: row.RandomizeQuestions,
+		ShuffleChoices:     row.ShuffleChoices,
+	}, nil
+}
+
 func (s *Service) ListByFormID(ctx context.Context, formID uuid.UUID) ([]SectionWithQuestions, error) {
 	ctx, span := s.tracer.Start(ctx, "ListByFormID")
 	defer span.End()
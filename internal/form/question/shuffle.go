@@ -0,0 +1,48 @@
+package question
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand/v2"
+
+	"github.com/google/uuid"
+)
+
+// OrderOptions are the per form options for shuffling what a respondent sees
+type OrderOptions struct {
+	RandomizeQuestions bool
+	ShuffleChoices     bool
+}
+
+// Shuffle reorders the questions of each section and the choices of each question as
+// the options ask. Every section and question gets its own generator, seeded by the
+// form, the respondent and its own ID, so one respondent always sees the same order
+// and editing one question leaves the order of the others alone.
+func Shuffle(sections []SectionResponse, options OrderOptions, formID uuid.UUID, respondentID uuid.UUID) {
+	for _, section := range sections {
+		if options.RandomizeQuestions {
+			random := newRandom(formID, respondentID, section.Section.ID)
+			random.Shuffle(len(section.Questions), func(i, j int) {
+				section.Questions[i], section.Questions[j] = section.Questions[j], section.Questions[i]
+			})
+		}
+
+		if options.ShuffleChoices {
+			for _, q := range section.Questions {
+				random := newRandom(formID, respondentID, q.ID)
+				random.Shuffle(len(q.Choices), func(i, j int) {
+					q.Choices[i], q.Choices[j] = q.Choices[j], q.Choices[i]
+				})
+			}
+		}
+	}
+}
+
+func newRandom(formID uuid.UUID, respondentID uuid.UUID, id uuid.UUID) *rand.Rand {
+	hash := sha256.New()
+	hash.Write(formID[:])
+	hash.Write(respondentID[:])
+	hash.Write(id[:])
+	sum := hash.Sum(nil)
+	return rand.New(rand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16])))
+}
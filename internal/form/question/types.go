@@ -30,6 +30,8 @@ func NewAnswerable(q Question, formID uuid.UUID) (Answerable, error) {
 		return NewUploadFile(q, formID)
 	case QuestionTypeHyperlink:
 		return NewHyperlink(q, formID), nil
+	case QuestionTypeConsent:
+		return NewConsent(q, formID)
 	}
 
 	return nil, ErrUnsupportedQuestionType{
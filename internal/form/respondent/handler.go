@@ -0,0 +1,214 @@
+package respondent
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/audit"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Enable(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+	Disable(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+	IsPublic(ctx context.Context, formID uuid.UUID) (bool, error)
+	Issue(ctx context.Context, formID uuid.UUID, respondentID uuid.UUID, ipAddress string) (Token, error)
+	IssuePreview(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Token, error)
+}
+
+type TokenParser interface {
+	ParseRespondentToken(ctx context.Context, tokenString string) (user.User, uuid.UUID, error)
+}
+
+type TokenResponse struct {
+	AccessToken  string    `json:"accessToken"`
+	TokenType    string    `json:"tokenType"`
+	RespondentID string    `json:"respondentId"`
+	FormID       string    `json:"formId"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+type PublicResponse struct {
+	Public bool `json:"public"`
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tokenParser TokenParser
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tokenParser TokenParser,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("respondent/handler"),
+		problemWriter: problemWriter,
+		store:         store,
+		tokenParser:   tokenParser,
+	}
+}
+
+// previousRespondent returns the respondent of a still valid token for the same form
+func (h *Handler) previousRespondent(ctx context.Context, r *http.Request, formID uuid.UUID) uuid.UUID {
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		return uuid.Nil
+	}
+
+	respondent, tokenFormID, err := h.tokenParser.ParseRespondentToken(ctx, fields[1])
+	if err != nil || tokenFormID != formID {
+		return uuid.Nil
+	}
+	return respondent.ID
+}
+
+// IssueHandler gives an anonymous visitor a token that can only answer this form
+func (h *Handler) IssueHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "IssueHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	token, err := h.store.Issue(traceCtx, formID, h.previousRespondent(traceCtx, r, formID), audit.ClientIP(r))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	writeToken(w, token)
+}
+
+// IssuePreviewHandler gives an org admin a token to try the form out; what is
+// submitted with it is test data
+func (h *Handler) IssuePreviewHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "IssuePreviewHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	token, err := h.store.IssuePreview(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	writeToken(w, token)
+}
+
+func writeToken(w http.ResponseWriter, token Token) {
+	w.Header().Set("Cache-Control", "no-store")
+	handlerutil.WriteJSONResponse(w, http.StatusOK, TokenResponse{
+		AccessToken:  token.AccessToken,
+		TokenType:    "Bearer",
+		RespondentID: token.RespondentID.String(),
+		FormID:       token.FormID.String(),
+		ExpiresAt:    token.ExpiresAt,
+	})
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	public, err := h.store.IsPublic(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, PublicResponse{Public: public})
+}
+
+func (h *Handler) EnableHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "EnableHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	err = h.store.Enable(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) DisableHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DisableHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	err = h.store.Disable(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
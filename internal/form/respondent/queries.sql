@@ -0,0 +1,44 @@
+-- name: Enable :exec
+INSERT INTO form_public_access (form_id, enabled_by)
+VALUES (@form_id, @enabled_by)
+ON CONFLICT (form_id) DO NOTHING;
+
+-- name: Disable :execrows
+DELETE FROM form_public_access
+WHERE form_id = @form_id;
+
+-- name: IsPublic :one
+SELECT EXISTS(SELECT 1 FROM form_public_access WHERE form_id = @form_id);
+
+-- name: IsBallot :one
+SELECT EXISTS(SELECT 1 FROM form_ballots WHERE form_id = @form_id);
+
+-- name: GetPublicForm :one
+SELECT f.id, f.status, f.deadline
+FROM forms f
+JOIN form_public_access p ON p.form_id = f.id
+WHERE f.id = @form_id;
+
+-- name: FormExists :one
+SELECT EXISTS(SELECT 1 FROM forms WHERE id = @form_id);
+
+-- name: CreateGuest :one
+INSERT INTO users (name, role, is_onboarded)
+VALUES ('Anonymous respondent', '{"respondent"}', true)
+RETURNING id;
+
+-- name: RecordGuest :exec
+INSERT INTO form_respondent_guests (respondent_id, form_id, ip_address)
+VALUES (@respondent_id, @form_id, @ip_address);
+
+-- name: CountGuestsSince :one
+SELECT COUNT(*) FROM form_respondent_guests
+WHERE ip_address = @ip_address AND created_at > @since;
+
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
\ No newline at end of file
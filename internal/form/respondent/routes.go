@@ -0,0 +1,14 @@
+package respondent
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the anonymous respondent tokens and the public link of a form
+func Routes(r route.Router, h *Handler) {
+	r.Handle("POST /forms/{id}/respondent-token", route.Public, route.PermissionNone, h.IssueHandler)
+	r.Handle("POST /forms/{id}/preview-token", route.Authenticated, route.PermissionOrgAdmin, h.IssuePreviewHandler)
+	r.Handle("GET /forms/{id}/public", route.Authenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /forms/{id}/public", route.Authenticated, route.PermissionOrgAdmin, h.EnableHandler)
+	r.Handle("DELETE /forms/{id}/public", route.Authenticated, route.PermissionOrgAdmin, h.DisableHandler)
+}
@@ -0,0 +1,289 @@
+package respondent
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	Enable(ctx context.Context, arg EnableParams) error
+	Disable(ctx context.Context, formID uuid.UUID) (int64, error)
+	IsPublic(ctx context.Context, formID uuid.UUID) (bool, error)
+	IsBallot(ctx context.Context, formID uuid.UUID) (bool, error)
+	GetPublicForm(ctx context.Context, formID uuid.UUID) (GetPublicFormRow, error)
+	FormExists(ctx context.Context, formID uuid.UUID) (bool, error)
+	CreateGuest(ctx context.Context) (uuid.UUID, error)
+	RecordGuest(ctx context.Context, arg RecordGuestParams) error
+	CountGuestsSince(ctx context.Context, arg CountGuestsSinceParams) (int64, error)
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+}
+
+// New anonymous respondents are counted per client over guestRateWindow. Tokens for
+// the respondent of an earlier token are not counted, as they create no one.
+const (
+	guestRateWindow       = 15 * time.Minute
+	maxGuestsPerIPAddress = 30
+)
+
+type TokenIssuer interface {
+	NewRespondentToken(ctx context.Context, respondentID uuid.UUID, formID uuid.UUID) (string, time.Time, error)
+	NewPreviewToken(ctx context.Context, respondentID uuid.UUID, formID uuid.UUID) (string, time.Time, error)
+}
+
+// Token lets an anonymous respondent answer one form
+type Token struct {
+	AccessToken  string
+	RespondentID uuid.UUID
+	FormID       uuid.UUID
+	ExpiresAt    time.Time
+}
+
+type Service struct {
+	logger      *zap.Logger
+	queries     Querier
+	tracer      trace.Tracer
+	tokenIssuer TokenIssuer
+}
+
+func NewService(logger *zap.Logger, db DBTX, tokenIssuer TokenIssuer) *Service {
+	return &Service{
+		logger:      logger,
+		queries:     New(db),
+		tracer:      otel.Tracer("respondent/service"),
+		tokenIssuer: tokenIssuer,
+	}
+}
+
+// requireAdmin allows the owner of the organization of the form only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{FormID: formID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+// Enable lets anyone answer the form without an account. Only org admins may open a
+// form this way, and forms in ballot mode cannot be, as only their electorate may vote.
+func (s *Service) Enable(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Enable")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	isBallot, err := s.queries.IsBallot(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_ballots", "form_id", formID.String(), logger, "check ballot mode")
+		span.RecordError(err)
+		return err
+	}
+	if isBallot {
+		err = internal.ErrBallotAnonymousAccess
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.queries.Enable(traceCtx, EnableParams{
+		FormID:    formID,
+		EnabledBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_public_access", "form_id", formID.String(), logger, "enable anonymous responses")
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Enabled anonymous responses", zap.String("form_id", formID.String()), zap.String("user_id", userID.String()))
+
+	return nil
+}
+
+// Disable stops issuing respondent tokens; tokens already issued expire on their own
+func (s *Service) Disable(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Disable")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	_, err = s.queries.Disable(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_public_access", "form_id", formID.String(), logger, "disable anonymous responses")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) IsPublic(ctx context.Context, formID uuid.UUID) (bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "IsPublic")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	public, err := s.queries.IsPublic(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_public_access", "form_id", formID.String(), logger, "check anonymous responses")
+		span.RecordError(err)
+		return false, err
+	}
+
+	return public, nil
+}
+
+// Issue hands out a respondent token for a public, published and open form. Passing
+// the respondent of an earlier token keeps the same identity, so drafts and rate
+// limits follow the session rather than each token. New respondents are limited per
+// client IP address.
+func (s *Service) Issue(ctx context.Context, formID uuid.UUID, respondentID uuid.UUID, ipAddress string) (Token, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Issue")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	publicForm, err := s.queries.GetPublicForm(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrFormNotPublic
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_public_access", "form_id", formID.String(), logger, "get public form")
+		}
+		span.RecordError(err)
+		return Token{}, err
+	}
+
+	err = form.CheckOpen(form.Status(publicForm.Status), publicForm.Deadline, time.Now())
+	if errors.Is(err, internal.ErrFormNotPublished) {
+		err = internal.ErrFormNotPublic
+	}
+	if err != nil {
+		span.RecordError(err)
+		return Token{}, err
+	}
+
+	if respondentID == uuid.Nil {
+		respondentID, err = s.createGuest(traceCtx, logger, formID, ipAddress)
+		if err != nil {
+			span.RecordError(err)
+			return Token{}, err
+		}
+	}
+
+	accessToken, expiresAt, err := s.tokenIssuer.NewRespondentToken(traceCtx, respondentID, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Token{}, err
+	}
+
+	logger.Debug("Issued respondent token", zap.String("form_id", formID.String()), zap.String("respondent_id", respondentID.String()))
+
+	return Token{
+		AccessToken:  accessToken,
+		RespondentID: respondentID,
+		FormID:       formID,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// createGuest creates an anonymous respondent for the client, unless it created too
+// many already
+func (s *Service) createGuest(ctx context.Context, logger *zap.Logger, formID uuid.UUID, ipAddress string) (uuid.UUID, error) {
+	count, err := s.queries.CountGuestsSince(ctx, CountGuestsSinceParams{
+		IpAddress: ipAddress,
+		Since:     pgtype.Timestamptz{Time: time.Now().Add(-guestRateWindow), Valid: true},
+	})
+	if err != nil {
+		return uuid.Nil, databaseutil.WrapDBError(err, logger, "count anonymous respondents by ip address")
+	}
+	if count >= maxGuestsPerIPAddress {
+		logger.Warn("Refused an anonymous respondent over the rate limit", zap.String("form_id", formID.String()), zap.String("ip_address", ipAddress))
+		return uuid.Nil, fmt.Errorf("%w: at most %d every %s", internal.ErrRespondentRateLimited, maxGuestsPerIPAddress, guestRateWindow)
+	}
+
+	respondentID, err := s.queries.CreateGuest(ctx)
+	if err != nil {
+		return uuid.Nil, databaseutil.WrapDBErrorWithKeyValue(err, "users", "form_id", formID.String(), logger, "create anonymous respondent")
+	}
+
+	err = s.queries.RecordGuest(ctx, RecordGuestParams{RespondentID: respondentID, FormID: formID, IpAddress: ipAddress})
+	if err != nil {
+		return uuid.Nil, databaseutil.WrapDBErrorWithKeyValue(err, "form_respondent_guests", "form_id", formID.String(), logger, "record anonymous respondent")
+	}
+	return respondentID, nil
+}
+
+// IssuePreview hands an org admin a preview token, which answers the form as a
+// fresh respondent whatever its status, deadline and eligibility rules. Every call gets
+// a new respondent, so each walk through the form starts from a blank response.
+func (s *Service) IssuePreview(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Token, error) {
+	traceCtx, span := s.tracer.Start(ctx, "IssuePreview")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	exists, err := s.queries.FormExists(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check form exists")
+		span.RecordError(err)
+		return Token{}, err
+	}
+	if !exists {
+		err = internal.ErrFormNotFound
+		span.RecordError(err)
+		return Token{}, err
+	}
+
+	err = s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Token{}, err
+	}
+
+	respondentID, err := s.queries.CreateGuest(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "users", "form_id", formID.String(), logger, "create preview respondent")
+		span.RecordError(err)
+		return Token{}, err
+	}
+
+	accessToken, expiresAt, err := s.tokenIssuer.NewPreviewToken(traceCtx, respondentID, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Token{}, err
+	}
+
+	logger.Info("Issued preview token",
+		zap.String("form_id", formID.String()),
+		zap.String("user_id", userID.String()),
+		zap.String("respondent_id", respondentID.String()))
+
+	return Token{
+		AccessToken:  accessToken,
+		RespondentID: respondentID,
+		FormID:       formID,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
@@ -0,0 +1,158 @@
+package respondent
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+// fakeQuerier serves one form and counts the guests it creates
+type fakeQuerier struct {
+	Querier
+	form        *GetPublicFormRow
+	ballot      bool
+	recentCount int64
+	created     int
+	recorded    []RecordGuestParams
+	enabled     bool
+	notAdmin    bool
+}
+
+func (q *fakeQuerier) IsFormOrgAdmin(context.Context, IsFormOrgAdminParams) (bool, error) {
+	return !q.notAdmin, nil
+}
+
+func (q *fakeQuerier) IsBallot(context.Context, uuid.UUID) (bool, error) {
+	return q.ballot, nil
+}
+
+func (q *fakeQuerier) Enable(context.Context, EnableParams) error {
+	q.enabled = true
+	return nil
+}
+
+func (q *fakeQuerier) GetPublicForm(context.Context, uuid.UUID) (GetPublicFormRow, error) {
+	if q.form == nil {
+		return GetPublicFormRow{}, pgx.ErrNoRows
+	}
+	return *q.form, nil
+}
+
+func (q *fakeQuerier) CountGuestsSince(context.Context, CountGuestsSinceParams) (int64, error) {
+	return q.recentCount, nil
+}
+
+func (q *fakeQuerier) CreateGuest(context.Context) (uuid.UUID, error) {
+	q.created++
+	return uuid.New(), nil
+}
+
+func (q *fakeQuerier) RecordGuest(_ context.Context, arg RecordGuestParams) error {
+	q.recorded = append(q.recorded, arg)
+	return nil
+}
+
+type fakeTokenIssuer struct {
+	TokenIssuer
+}
+
+func (fakeTokenIssuer) NewRespondentToken(context.Context, uuid.UUID, uuid.UUID) (string, time.Time, error) {
+	return "token", time.Now().Add(time.Hour), nil
+}
+
+func TestService_Issue(t *testing.T) {
+	t.Parallel()
+
+	formID := uuid.New()
+	published := &GetPublicFormRow{ID: formID, Status: StatusPublished}
+
+	type testCase struct {
+		name            string
+		form            *GetPublicFormRow
+		recentCount     int64
+		respondentID    uuid.UUID
+		expectedErr     error
+		expectedCreated int
+	}
+
+	testCases := []testCase{
+		{name: "Public form creates a guest", form: published, expectedCreated: 1},
+		{name: "Earlier respondent is reused", form: published, recentCount: maxGuestsPerIPAddress, respondentID: uuid.New(), expectedCreated: 0},
+		{name: "Form without anonymous access", form: nil, expectedErr: internal.ErrFormNotPublic},
+		{name: "Draft form", form: &GetPublicFormRow{ID: formID, Status: StatusDraft}, expectedErr: internal.ErrFormNotPublic},
+		{
+			name:        "Deadline passed",
+			form:        &GetPublicFormRow{ID: formID, Status: StatusPublished, Deadline: pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true}},
+			expectedErr: internal.ErrFormDeadlinePassed,
+		},
+		{name: "Below the rate limit", form: published, recentCount: maxGuestsPerIPAddress - 1, expectedCreated: 1},
+		{name: "Over the rate limit", form: published, recentCount: maxGuestsPerIPAddress, expectedErr: internal.ErrRespondentRateLimited},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			queries := &fakeQuerier{form: tc.form, recentCount: tc.recentCount}
+			service := &Service{logger: zap.NewNop(), queries: queries, tracer: otel.Tracer("test"), tokenIssuer: fakeTokenIssuer{}}
+
+			token, err := service.Issue(context.Background(), formID, tc.respondentID, "203.0.113.7")
+			require.Equal(t, tc.expectedCreated, queries.created)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.respondentID != uuid.Nil {
+				require.Equal(t, tc.respondentID, token.RespondentID)
+				require.Empty(t, queries.recorded)
+				return
+			}
+			require.Equal(t, []RecordGuestParams{{RespondentID: token.RespondentID, FormID: formID, IpAddress: "203.0.113.7"}}, queries.recorded)
+		})
+	}
+}
+
+func TestService_Enable(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		ballot      bool
+		notAdmin    bool
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{name: "Form taking responses", ballot: false},
+		{name: "Form in ballot mode", ballot: true, expectedErr: internal.ErrBallotAnonymousAccess},
+		{name: "Not an org admin", notAdmin: true, expectedErr: internal.ErrNotOrgAdmin},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			queries := &fakeQuerier{ballot: tc.ballot, notAdmin: tc.notAdmin}
+			service := &Service{logger: zap.NewNop(), queries: queries, tracer: otel.Tracer("test")}
+
+			err := service.Enable(context.Background(), uuid.New(), uuid.New())
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.False(t, queries.enabled)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, queries.enabled)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package response
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+)
+
+// DefaultSimilarityThreshold is the trigram similarity from which two answers are
+// reported as near-identical
+const DefaultSimilarityThreshold = 0.8
+
+type DuplicateKeyKind string
+
+const (
+	// DuplicateKeyUser groups the responses submitted by the same user
+	DuplicateKeyUser DuplicateKeyKind = "user"
+	// DuplicateKeyAnswer groups the responses giving the same answer to a question,
+	// such as an email address, ignoring case and surrounding spaces
+	DuplicateKeyAnswer DuplicateKeyKind = "answer"
+	// DuplicateKeySimilar groups the responses giving near-identical answers to a question
+	DuplicateKeySimilar DuplicateKeyKind = "similar"
+)
+
+// DuplicateKey is what responses are compared on, written "user", "answer:<questionId>"
+// or "similar:<questionId>"
+type DuplicateKey struct {
+	Kind       DuplicateKeyKind
+	QuestionID uuid.UUID
+}
+
+func ParseDuplicateKey(value string) (DuplicateKey, error) {
+	kind, questionID, hasQuestion := strings.Cut(value, ":")
+	switch DuplicateKeyKind(kind) {
+	case DuplicateKeyUser:
+		if hasQuestion {
+			return DuplicateKey{}, fmt.Errorf("%w: key user takes no question", internal.ErrInvalidQueryParameter)
+		}
+		return DuplicateKey{Kind: DuplicateKeyUser}, nil
+	case DuplicateKeyAnswer, DuplicateKeySimilar:
+		id, err := uuid.Parse(questionID)
+		if err != nil {
+			return DuplicateKey{}, fmt.Errorf("%w: key %s needs a question id, as in %s:<questionId>", internal.ErrInvalidQueryParameter, kind, kind)
+		}
+		return DuplicateKey{Kind: DuplicateKeyKind(kind), QuestionID: id}, nil
+	default:
+		return DuplicateKey{}, fmt.Errorf("%w: key must be user, answer:<questionId> or similar:<questionId>", internal.ErrInvalidQueryParameter)
+	}
+}
+
+func (k DuplicateKey) String() string {
+	if k.Kind == DuplicateKeyUser {
+		return string(k.Kind)
+	}
+	return string(k.Kind) + ":" + k.QuestionID.String()
+}
+
+// DuplicateMember is one response of a group, with the value it shares with the
+// others: the user who submitted it or its answer to the question of the key
+type DuplicateMember struct {
+	ResponseID  uuid.UUID
+	SubmittedBy uuid.UUID
+	CreatedAt   time.Time
+	Value       string
+}
+
+// DuplicateGroup holds the responses linked to each other on one key. Score is the
+// lowest similarity of the pairs linking them, 1 for the exact keys.
+type DuplicateGroup struct {
+	Key     DuplicateKey
+	Score   float64
+	Members []DuplicateMember
+}
+
+// duplicatePair is two responses found alike on a key
+type duplicatePair struct {
+	responseID, otherResponseID uuid.UUID
+	value, otherValue           string
+	score                       float64
+}
+
+// groupPairs links the pairs into groups, two responses being in the same group when
+// a chain of pairs joins them
+func groupPairs(key DuplicateKey, pairs []duplicatePair, responses map[uuid.UUID]FormResponse) []DuplicateGroup {
+	parent := make(map[uuid.UUID]uuid.UUID)
+	var find func(id uuid.UUID) uuid.UUID
+	find = func(id uuid.UUID) uuid.UUID {
+		p, ok := parent[id]
+		if !ok || p == id {
+			parent[id] = id
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+
+	values := make(map[uuid.UUID]string)
+	for _, pair := range pairs {
+		values[pair.responseID] = pair.value
+		values[pair.otherResponseID] = pair.otherValue
+		parent[find(pair.otherResponseID)] = find(pair.responseID)
+	}
+
+	byRoot := make(map[uuid.UUID]*DuplicateGroup)
+	var roots []uuid.UUID
+	for _, pair := range pairs {
+		root := find(pair.responseID)
+		group, ok := byRoot[root]
+		if !ok {
+			group = &DuplicateGroup{Key: key, Score: pair.score}
+			byRoot[root] = group
+			roots = append(roots, root)
+		}
+		group.Score = min(group.Score, pair.score)
+	}
+
+	for id, value := range values {
+		current := responses[id]
+		group := byRoot[find(id)]
+		group.Members = append(group.Members, DuplicateMember{
+			ResponseID:  id,
+			SubmittedBy: current.SubmittedBy,
+			CreatedAt:   current.CreatedAt.Time,
+			Value:       value,
+		})
+	}
+
+	groups := make([]DuplicateGroup, len(roots))
+	for i, root := range roots {
+		group := byRoot[root]
+		slices.SortFunc(group.Members, func(a, b DuplicateMember) int {
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c
+			}
+			return strings.Compare(a.ResponseID.String(), b.ResponseID.String())
+		})
+		groups[i] = *group
+	}
+	slices.SortFunc(groups, func(a, b DuplicateGroup) int {
+		return a.Members[0].CreatedAt.Compare(b.Members[0].CreatedAt)
+	})
+
+	return groups
+}
+
+// ListDuplicates reports the responses to the form that look like the same submission
+// on each of the keys, test responses left out; threshold is the similarity the
+// similar keys need at least, in (0, 1]. Groups come in the order of the keys, then of
+// their earliest response.
+func (s Service) ListDuplicates(ctx context.Context, formID uuid.UUID, keys []DuplicateKey, threshold float64) ([]DuplicateGroup, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListDuplicates")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if threshold <= 0 || threshold > 1 {
+		err := fmt.Errorf("%w: threshold must be greater than 0 and at most 1", internal.ErrInvalidQueryParameter)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	formResponses, err := s.queries.ListByFormID(traceCtx, ListByFormIDParams{FormID: formID, IsTest: false})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list responses by form id")
+		span.RecordError(err)
+		return nil, err
+	}
+	responses := make(map[uuid.UUID]FormResponse, len(formResponses))
+	for _, current := range formResponses {
+		responses[current.ID] = current
+	}
+
+	groups := []DuplicateGroup{}
+	for _, key := range keys {
+		var pairs []duplicatePair
+		switch key.Kind {
+		case DuplicateKeyUser:
+			rows, err := s.queries.ListSameUserPairs(traceCtx, formID)
+			if err != nil {
+				err = databaseutil.WrapDBError(err, logger, "list responses of the same user")
+				span.RecordError(err)
+				return nil, err
+			}
+			for _, row := range rows {
+				value := row.SubmittedBy.String()
+				pairs = append(pairs, duplicatePair{row.ResponseID, row.OtherResponseID, value, value, 1})
+			}
+		case DuplicateKeyAnswer:
+			rows, err := s.queries.ListMatchingAnswerPairs(traceCtx, ListMatchingAnswerPairsParams{QuestionID: key.QuestionID, FormID: formID})
+			if err != nil {
+				err = databaseutil.WrapDBError(err, logger, "list responses with matching answers")
+				span.RecordError(err)
+				return nil, err
+			}
+			for _, row := range rows {
+				pairs = append(pairs, duplicatePair{row.ResponseID, row.OtherResponseID, row.Value, row.OtherValue, 1})
+			}
+		case DuplicateKeySimilar:
+			rows, err := s.queries.ListSimilarAnswerPairs(traceCtx, ListSimilarAnswerPairsParams{QuestionID: key.QuestionID, FormID: formID, Threshold: threshold})
+			if err != nil {
+				err = databaseutil.WrapDBError(err, logger, "list responses with similar answers")
+				span.RecordError(err)
+				return nil, err
+			}
+			for _, row := range rows {
+				pairs = append(pairs, duplicatePair{row.ResponseID, row.OtherResponseID, row.Value, row.OtherValue, row.Score})
+			}
+		}
+
+		groups = append(groups, groupPairs(key, pairs, responses)...)
+	}
+
+	return groups, nil
+}
@@ -2,11 +2,16 @@ package response
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/conditional"
+	"NYCU-SDC/core-system-backend/internal/form/pii"
 	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/user"
 
 	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -35,10 +40,15 @@ type AnswerForQuestionResponse struct {
 type Response struct {
 	ID          string    `json:"id" validate:"required,uuid"`
 	SubmittedBy string    `json:"submittedBy" validate:"required,uuid"`
+	IsTest      bool      `json:"isTest"`
 	CreatedAt   time.Time `json:"createdAt" validate:"required,datetime"`
 	UpdatedAt   time.Time `json:"updatedAt" validate:"required,datetime"`
 }
 
+type DeleteTestResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
 type ListResponse struct {
 	FormID        string     `json:"formId" validate:"required,uuid"`
 	ResponseJSONs []Response `json:"responses" validate:"required,dive"`
@@ -58,38 +68,97 @@ type AnswersForQuestionResponse struct {
 	Answers  []AnswerForQuestionResponse `json:"answers" validate:"required,dive"`
 }
 
+type AnswerRevisionResponse struct {
+	ID            string    `json:"id" validate:"required,uuid"`
+	QuestionID    string    `json:"questionId" validate:"required,uuid"`
+	PreviousValue string    `json:"previousValue"`
+	Value         string    `json:"value"`
+	EditedBy      string    `json:"editedBy,omitempty"` // empty when the editor was deleted
+	EditedAt      time.Time `json:"editedAt" validate:"required,datetime"`
+}
+
+type HistoryResponse struct {
+	ResponseID string                   `json:"responseId" validate:"required,uuid"`
+	Revisions  []AnswerRevisionResponse `json:"revisions" validate:"required,dive"`
+}
+
+type DuplicateMemberResponse struct {
+	ResponseID  string    `json:"responseId" validate:"required,uuid"`
+	SubmittedBy string    `json:"submittedBy" validate:"required,uuid"`
+	CreatedAt   time.Time `json:"createdAt" validate:"required,datetime"`
+	Value       string    `json:"value"`
+}
+
+type DuplicateGroupResponse struct {
+	Key       string                    `json:"key"`
+	Score     float64                   `json:"score"`
+	Responses []DuplicateMemberResponse `json:"responses" validate:"required,dive"`
+}
+
+type DuplicatesResponse struct {
+	FormID string                   `json:"formId" validate:"required,uuid"`
+	Groups []DuplicateGroupResponse `json:"groups" validate:"required,dive"`
+}
+
 type Store interface {
 	Get(ctx context.Context, formID uuid.UUID, responseID uuid.UUID) (FormResponse, []Answer, error)
-	ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormResponse, error)
+	ListByFormID(ctx context.Context, formID uuid.UUID, isTest bool) ([]FormResponse, error)
 	Delete(ctx context.Context, responseID uuid.UUID) error
+	DeleteTest(ctx context.Context, formID uuid.UUID) (int64, error)
 	GetAnswersByQuestionID(ctx context.Context, questionID uuid.UUID, formID uuid.UUID) ([]GetAnswersByQuestionIDRow, error)
+	ListHistory(ctx context.Context, formID uuid.UUID, responseID uuid.UUID) ([]AnswerRevision, error)
+	ListDuplicates(ctx context.Context, formID uuid.UUID, keys []DuplicateKey, threshold float64) ([]DuplicateGroup, error)
 }
 
 type QuestionStore interface {
 	GetByID(ctx context.Context, id uuid.UUID) (question.Answerable, error)
 }
 
+// PIIStore masks the answers to PII questions for callers who may not see them
+type PIIStore interface {
+	Mask(ctx context.Context, formID uuid.UUID, userID uuid.UUID, masked bool) (pii.Mask, error)
+}
+
 type Handler struct {
 	logger        *zap.Logger
 	validator     *validator.Validate
 	problemWriter *problem.HttpWriter
 	store         Store
 	questionStore QuestionStore
+	piiStore      PIIStore
 	tracer        trace.Tracer
 }
 
-func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store, questionStore QuestionStore) *Handler {
+func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store, questionStore QuestionStore, piiStore PIIStore) *Handler {
 	return &Handler{
 		logger:        logger,
 		validator:     validator,
 		problemWriter: problemWriter,
 		store:         store,
 		questionStore: questionStore,
+		piiStore:      piiStore,
 		tracer:        otel.Tracer("response/handler"),
 	}
 }
 
-// ListHandler lists all responses for a form
+// mask returns the mask the current user sees the answers of the form through;
+// ?masked=true masks them even for admins
+func (h *Handler) mask(ctx context.Context, r *http.Request, formID uuid.UUID) (pii.Mask, error) {
+	currentUser, ok := user.GetFromContext(ctx)
+	if !ok {
+		return pii.Mask{}, internal.ErrNoUserInContext
+	}
+
+	masked, err := pii.ParseMaskedParam(r)
+	if err != nil {
+		return pii.Mask{}, err
+	}
+
+	return h.piiStore.Mask(ctx, formID, currentUser.ID, masked)
+}
+
+// ListHandler lists the responses of a form; ?test=true lists the test responses
+// submitted in preview mode instead
 func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
 	defer span.End()
@@ -102,7 +171,13 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	responses, err := h.store.ListByFormID(traceCtx, formID)
+	isTest, err := parseTestParam(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses, err := h.store.ListByFormID(traceCtx, formID, isTest)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -112,14 +187,20 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 		FormID:        formID.String(),
 		ResponseJSONs: make([]Response, len(responses)),
 	}
+	var lastModified time.Time
 	for i, currentResponse := range responses {
 		listResponse.ResponseJSONs[i] = Response{
 			ID:          currentResponse.ID.String(),
 			SubmittedBy: currentResponse.SubmittedBy.String(),
+			IsTest:      currentResponse.IsTest,
 			CreatedAt:   currentResponse.CreatedAt.Time,
 			UpdatedAt:   currentResponse.UpdatedAt.Time,
 		}
+		if currentResponse.UpdatedAt.Time.After(lastModified) {
+			lastModified = currentResponse.UpdatedAt.Time
+		}
 	}
+	conditional.SetLastModified(w, lastModified)
 	handlerutil.WriteJSONResponse(w, http.StatusOK, listResponse)
 }
 
@@ -149,6 +230,12 @@ func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	mask, err := h.mask(traceCtx, r, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
 	questionAnswerResponses := make([]QuestionAnswerForGetResponse, len(answers))
 	for i, answer := range answers {
 		q, err := h.questionStore.GetByID(traceCtx, answer.QuestionID)
@@ -159,7 +246,7 @@ func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 
 		questionAnswerResponses[i] = QuestionAnswerForGetResponse{
 			QuestionID: q.Question().ID.String(),
-			Answer:     answer.Value,
+			Answer:     mask.Apply(answer.QuestionID, answer.Value),
 		}
 	}
 
@@ -173,6 +260,60 @@ func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// HistoryHandler lists what changed in the answers of a response after they were first saved
+func (h *Handler) HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "HistoryHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formIDStr := r.PathValue("formId")
+	formID, err := internal.ParseUUID(formIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	idStr := r.PathValue("responseId")
+	id, err := internal.ParseUUID(idStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	revisions, err := h.store.ListHistory(traceCtx, formID, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	mask, err := h.mask(traceCtx, r, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	historyResponse := HistoryResponse{
+		ResponseID: id.String(),
+		Revisions:  make([]AnswerRevisionResponse, len(revisions)),
+	}
+	for i, revision := range revisions {
+		editedBy := ""
+		if revision.EditedBy.Valid {
+			editedBy = uuid.UUID(revision.EditedBy.Bytes).String()
+		}
+
+		historyResponse.Revisions[i] = AnswerRevisionResponse{
+			ID:            revision.ID.String(),
+			QuestionID:    revision.QuestionID.String(),
+			PreviousValue: mask.Apply(revision.QuestionID, revision.PreviousValue),
+			Value:         mask.Apply(revision.QuestionID, revision.Value),
+			EditedBy:      editedBy,
+			EditedAt:      revision.CreatedAt.Time,
+		}
+	}
+	handlerutil.WriteJSONResponse(w, http.StatusOK, historyResponse)
+}
+
 // DeleteHandler deletes a response by id
 func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
@@ -195,6 +336,126 @@ func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
 }
 
+// DeleteTestHandler purges the test responses of a form
+func (h *Handler) DeleteTestHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteTestHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	deleted, err := h.store.DeleteTest(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, DeleteTestResponse{Deleted: deleted})
+}
+
+// DuplicatesHandler groups the responses that may be the same submission made twice.
+// Each ?key= compares on the submitting user (user), an exact answer to a question
+// (answer:<questionId>) or a near-identical one (similar:<questionId>), the user when
+// none is given; ?threshold= sets the similarity the near-identical answers need.
+func (h *Handler) DuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DuplicatesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	keys, threshold, err := parseDuplicateParams(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	groups, err := h.store.ListDuplicates(traceCtx, formID, keys, threshold)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	mask, err := h.mask(traceCtx, r, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	duplicatesResponse := DuplicatesResponse{
+		FormID: formID.String(),
+		Groups: make([]DuplicateGroupResponse, len(groups)),
+	}
+	for i, group := range groups {
+		members := make([]DuplicateMemberResponse, len(group.Members))
+		for j, member := range group.Members {
+			value := member.Value
+			if group.Key.Kind != DuplicateKeyUser {
+				value = mask.Apply(group.Key.QuestionID, value)
+			}
+			members[j] = DuplicateMemberResponse{
+				ResponseID:  member.ResponseID.String(),
+				SubmittedBy: member.SubmittedBy.String(),
+				CreatedAt:   member.CreatedAt,
+				Value:       value,
+			}
+		}
+		duplicatesResponse.Groups[i] = DuplicateGroupResponse{
+			Key:       group.Key.String(),
+			Score:     group.Score,
+			Responses: members,
+		}
+	}
+	handlerutil.WriteJSONResponse(w, http.StatusOK, duplicatesResponse)
+}
+
+func parseDuplicateParams(r *http.Request) ([]DuplicateKey, float64, error) {
+	query := r.URL.Query()
+
+	keys := []DuplicateKey{{Kind: DuplicateKeyUser}}
+	if values := query["key"]; len(values) > 0 {
+		keys = make([]DuplicateKey, len(values))
+		for i, value := range values {
+			key, err := ParseDuplicateKey(value)
+			if err != nil {
+				return nil, 0, err
+			}
+			keys[i] = key
+		}
+	}
+
+	threshold := DefaultSimilarityThreshold
+	if value := query.Get("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: threshold must be a number", internal.ErrInvalidQueryParameter)
+		}
+		threshold = parsed
+	}
+
+	return keys, threshold, nil
+}
+
+func parseTestParam(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("test")
+	if value == "" {
+		return false, nil
+	}
+	isTest, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: test must be a boolean", internal.ErrInvalidQueryParameter)
+	}
+	return isTest, nil
+}
+
 // GetAnswersByQuestionIDHandler gets answers by question id
 func (h *Handler) GetAnswersByQuestionIDHandler(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "GetAnswersByQuestionIDHandler")
@@ -227,6 +488,12 @@ func (h *Handler) GetAnswersByQuestionIDHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	mask, err := h.mask(traceCtx, r, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
 	questionAnswerResponse := AnswersForQuestionResponse{
 		Question: currentQuestion.Question(),
 		Answers:  make([]AnswerForQuestionResponse, len(answers)),
@@ -236,7 +503,7 @@ func (h *Handler) GetAnswersByQuestionIDHandler(w http.ResponseWriter, r *http.R
 			ID:          answer.ID.String(),
 			ResponseID:  answer.ResponseID.String(),
 			SubmittedBy: answer.SubmittedBy.String(),
-			Value:       answer.Value,
+			Value:       mask.Apply(questionID, answer.Value),
 			CreatedAt:   answer.CreatedAt.Time,
 			UpdatedAt:   answer.UpdatedAt.Time,
 		}
@@ -1,6 +1,6 @@
 -- name: Create :one
-INSERT INTO form_responses (form_id, submitted_by)
-VALUES ($1, $2)
+INSERT INTO form_responses (form_id, submitted_by, is_test)
+VALUES ($1, $2, $3)
 RETURNING *;
 
 -- name: Get :one
@@ -13,7 +13,7 @@ WHERE form_id = $1 AND submitted_by = $2;
 
 -- name: ListByFormID :many
 SELECT * FROM form_responses
-WHERE form_id = $1
+WHERE form_id = $1 AND is_test = $2
 ORDER BY created_at ASC;
 
 -- name: ListBySubmittedBy :many
@@ -30,6 +30,28 @@ WHERE id = $1;
 DELETE FROM form_responses
 WHERE id = $1;
 
+-- name: DeleteTestByFormID :execrows
+DELETE FROM form_responses r
+WHERE r.form_id = $1 AND r.is_test
+  AND NOT EXISTS (
+    SELECT 1 FROM legal_holds h
+    WHERE h.response_id = r.id AND h.released_at IS NULL
+  );
+
+-- name: IsHeld :one
+-- A response is held by a hold on it or on its whole form
+SELECT EXISTS(
+    SELECT 1 FROM legal_holds h
+    JOIN form_responses r ON r.form_id = h.form_id
+    WHERE r.id = $1 AND h.released_at IS NULL AND (h.response_id IS NULL OR h.response_id = r.id)
+);
+
+-- name: IsFormHeld :one
+SELECT EXISTS(
+    SELECT 1 FROM legal_holds
+    WHERE form_id = $1 AND response_id IS NULL AND released_at IS NULL
+);
+
 -- name: Exists :one
 SELECT EXISTS(SELECT 1 FROM form_responses WHERE form_id = $1 AND submitted_by = $2);
 
@@ -38,6 +60,10 @@ INSERT INTO answers (response_id, question_id, type, value)
 VALUES ($1, $2, $3, $4)
 RETURNING *;
 
+-- name: CreateAnswers :exec
+INSERT INTO answers (response_id, question_id, type, value)
+SELECT @response_id::uuid, unnest(@question_ids::uuid[]), unnest(@types::text[])::question_type, unnest(@values::text[]);
+
 -- name: GetAnswersByResponseID :many
 SELECT * FROM answers 
 WHERE response_id = $1
@@ -46,7 +72,7 @@ ORDER BY created_at ASC;
 -- name: GetAnswersByQuestionID :many
 SELECT a.*, r.form_id, r.submitted_by FROM answers a
 JOIN form_responses r ON a.response_id = r.id
-WHERE a.question_id = $1 AND r.form_id = $2
+WHERE a.question_id = $1 AND r.form_id = $2 AND NOT r.is_test
 ORDER BY a.created_at ASC;
 
 -- name: DeleteAnswersByResponseID :exec
@@ -59,6 +85,12 @@ SET value = $2, updated_at = now()
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateAnswers :exec
+UPDATE answers a
+SET value = u.value, updated_at = now()
+FROM unnest(@ids::uuid[], @values::text[]) AS u(id, value)
+WHERE a.id = u.id;
+
 -- name: CheckAnswerContent :one
 SELECT EXISTS(SELECT 1 FROM answers WHERE response_id = $1 AND question_id = $2 AND value = $3);
 
@@ -66,4 +98,48 @@ SELECT EXISTS(SELECT 1 FROM answers WHERE response_id = $1 AND question_id = $2
 SELECT EXISTS(SELECT 1 FROM answers WHERE response_id = $1 AND question_id = $2);
 
 -- name: GetAnswerID :one
-SELECT id FROM answers WHERE response_id = $1 AND question_id = $2;
\ No newline at end of file
+SELECT id FROM answers WHERE response_id = $1 AND question_id = $2;
+
+-- name: GetAnswer :one
+SELECT * FROM answers WHERE response_id = $1 AND question_id = $2;
+
+-- name: CreateAnswerRevision :one
+INSERT INTO answer_revisions (answer_id, response_id, question_id, previous_value, value, edited_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: CreateAnswerRevisions :exec
+INSERT INTO answer_revisions (answer_id, response_id, question_id, previous_value, value, edited_by)
+SELECT unnest(@answer_ids::uuid[]), @response_id::uuid, unnest(@question_ids::uuid[]), unnest(@previous_values::text[]), unnest(@values::text[]), @edited_by::uuid;
+
+-- name: ListAnswerRevisionsByResponseID :many
+SELECT * FROM answer_revisions
+WHERE response_id = $1
+ORDER BY created_at ASC;
+
+-- name: ListSameUserPairs :many
+-- Pairs of responses to the form submitted by the same user, each pair once
+SELECT a.id AS response_id, b.id AS other_response_id, a.submitted_by FROM form_responses a
+JOIN form_responses b ON b.form_id = a.form_id AND b.submitted_by = a.submitted_by AND b.id > a.id
+WHERE a.form_id = @form_id AND NOT a.is_test AND NOT b.is_test;
+
+-- name: ListMatchingAnswerPairs :many
+-- Pairs of responses giving the same answer to the question, ignoring case and surrounding spaces
+SELECT a.response_id, b.response_id AS other_response_id, a.value, b.value AS other_value FROM answers a
+JOIN form_responses ra ON ra.id = a.response_id
+JOIN answers b ON b.question_id = a.question_id AND b.response_id > a.response_id
+    AND lower(btrim(b.value)) = lower(btrim(a.value))
+JOIN form_responses rb ON rb.id = b.response_id
+WHERE a.question_id = @question_id AND ra.form_id = @form_id
+  AND NOT ra.is_test AND NOT rb.is_test AND btrim(a.value) <> '';
+
+-- name: ListSimilarAnswerPairs :many
+-- Pairs of responses whose answers to the question have a trigram similarity of at least the threshold
+SELECT a.response_id, b.response_id AS other_response_id, a.value, b.value AS other_value,
+    similarity(a.value, b.value)::float8 AS score FROM answers a
+JOIN form_responses ra ON ra.id = a.response_id
+JOIN answers b ON b.question_id = a.question_id AND b.response_id > a.response_id
+JOIN form_responses rb ON rb.id = b.response_id
+WHERE a.question_id = @question_id AND ra.form_id = @form_id
+  AND NOT ra.is_test AND NOT rb.is_test AND btrim(a.value) <> ''
+  AND similarity(a.value, b.value) >= @threshold::float8;
\ No newline at end of file
@@ -46,18 +46,19 @@ func (q *Queries) CheckAnswerContent(ctx context.Context, arg CheckAnswerContent
 }
 
 const create = `-- name: Create :one
-INSERT INTO form_responses (form_id, submitted_by)
-VALUES ($1, $2)
-RETURNING id, form_id, submitted_by, submitted_at, created_at, updated_at
+INSERT INTO form_responses (form_id, submitted_by, is_test)
+VALUES ($1, $2, $3)
+RETURNING id, form_id, submitted_by, submitted_at, created_at, updated_at, is_test, anonymized_at
 `
 
 type CreateParams struct {
 	FormID      uuid.UUID
 	SubmittedBy uuid.UUID
+	IsTest      bool
 }
 
 func (q *Queries) Create(ctx context.Context, arg CreateParams) (FormResponse, error) {
-	row := q.db.QueryRow(ctx, create, arg.FormID, arg.SubmittedBy)
+	row := q.db.QueryRow(ctx, create, arg.FormID, arg.SubmittedBy, arg.IsTest)
 	var i FormResponse
 	err := row.Scan(
 		&i.ID,
@@ -66,6 +67,8 @@ func (q *Queries) Create(ctx context.Context, arg CreateParams) (FormResponse, e
 		&i.SubmittedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsTest,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
@@ -103,6 +106,92 @@ func (q *Queries) CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Ans
 	return i, err
 }
 
+const createAnswerRevision = `-- name: CreateAnswerRevision :one
+INSERT INTO answer_revisions (answer_id, response_id, question_id, previous_value, value, edited_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, answer_id, response_id, question_id, previous_value, value, edited_by, created_at
+`
+
+type CreateAnswerRevisionParams struct {
+	AnswerID      uuid.UUID
+	ResponseID    uuid.UUID
+	QuestionID    uuid.UUID
+	PreviousValue string
+	Value         string
+	EditedBy      pgtype.UUID
+}
+
+func (q *Queries) CreateAnswerRevision(ctx context.Context, arg CreateAnswerRevisionParams) (AnswerRevision, error) {
+	row := q.db.QueryRow(ctx, createAnswerRevision,
+		arg.AnswerID,
+		arg.ResponseID,
+		arg.QuestionID,
+		arg.PreviousValue,
+		arg.Value,
+		arg.EditedBy,
+	)
+	var i AnswerRevision
+	err := row.Scan(
+		&i.ID,
+		&i.AnswerID,
+		&i.ResponseID,
+		&i.QuestionID,
+		&i.PreviousValue,
+		&i.Value,
+		&i.EditedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAnswerRevisions = `-- name: CreateAnswerRevisions :exec
+INSERT INTO answer_revisions (answer_id, response_id, question_id, previous_value, value, edited_by)
+SELECT unnest($1::uuid[]), $2::uuid, unnest($3::uuid[]), unnest($4::text[]), unnest($5::text[]), $6::uuid
+`
+
+type CreateAnswerRevisionsParams struct {
+	AnswerIds      []uuid.UUID
+	ResponseID     uuid.UUID
+	QuestionIds    []uuid.UUID
+	PreviousValues []string
+	Values         []string
+	EditedBy       uuid.UUID
+}
+
+func (q *Queries) CreateAnswerRevisions(ctx context.Context, arg CreateAnswerRevisionsParams) error {
+	_, err := q.db.Exec(ctx, createAnswerRevisions,
+		arg.AnswerIds,
+		arg.ResponseID,
+		arg.QuestionIds,
+		arg.PreviousValues,
+		arg.Values,
+		arg.EditedBy,
+	)
+	return err
+}
+
+const createAnswers = `-- name: CreateAnswers :exec
+INSERT INTO answers (response_id, question_id, type, value)
+SELECT $1::uuid, unnest($2::uuid[]), unnest($3::text[])::question_type, unnest($4::text[])
+`
+
+type CreateAnswersParams struct {
+	ResponseID  uuid.UUID
+	QuestionIds []uuid.UUID
+	Types       []string
+	Values      []string
+}
+
+func (q *Queries) CreateAnswers(ctx context.Context, arg CreateAnswersParams) error {
+	_, err := q.db.Exec(ctx, createAnswers,
+		arg.ResponseID,
+		arg.QuestionIds,
+		arg.Types,
+		arg.Values,
+	)
+	return err
+}
+
 const delete = `-- name: Delete :exec
 DELETE FROM form_responses
 WHERE id = $1
@@ -123,6 +212,23 @@ func (q *Queries) DeleteAnswersByResponseID(ctx context.Context, responseID uuid
 	return err
 }
 
+const deleteTestByFormID = `-- name: DeleteTestByFormID :execrows
+DELETE FROM form_responses r
+WHERE r.form_id = $1 AND r.is_test
+  AND NOT EXISTS (
+    SELECT 1 FROM legal_holds h
+    WHERE h.response_id = r.id AND h.released_at IS NULL
+  )
+`
+
+func (q *Queries) DeleteTestByFormID(ctx context.Context, formID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTestByFormID, formID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const exists = `-- name: Exists :one
 SELECT EXISTS(SELECT 1 FROM form_responses WHERE form_id = $1 AND submitted_by = $2)
 `
@@ -140,7 +246,7 @@ func (q *Queries) Exists(ctx context.Context, arg ExistsParams) (bool, error) {
 }
 
 const get = `-- name: Get :one
-SELECT id, form_id, submitted_by, submitted_at, created_at, updated_at FROM form_responses
+SELECT id, form_id, submitted_by, submitted_at, created_at, updated_at, is_test, anonymized_at FROM form_responses
 WHERE id = $1 AND form_id = $2
 `
 
@@ -159,6 +265,32 @@ func (q *Queries) Get(ctx context.Context, arg GetParams) (FormResponse, error)
 		&i.SubmittedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsTest,
+		&i.AnonymizedAt,
+	)
+	return i, err
+}
+
+const getAnswer = `-- name: GetAnswer :one
+SELECT id, response_id, question_id, type, value, created_at, updated_at FROM answers WHERE response_id = $1 AND question_id = $2
+`
+
+type GetAnswerParams struct {
+	ResponseID uuid.UUID
+	QuestionID uuid.UUID
+}
+
+func (q *Queries) GetAnswer(ctx context.Context, arg GetAnswerParams) (Answer, error) {
+	row := q.db.QueryRow(ctx, getAnswer, arg.ResponseID, arg.QuestionID)
+	var i Answer
+	err := row.Scan(
+		&i.ID,
+		&i.ResponseID,
+		&i.QuestionID,
+		&i.Type,
+		&i.Value,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -182,7 +314,7 @@ func (q *Queries) GetAnswerID(ctx context.Context, arg GetAnswerIDParams) (uuid.
 const getAnswersByQuestionID = `-- name: GetAnswersByQuestionID :many
 SELECT a.id, a.response_id, a.question_id, a.type, a.value, a.created_at, a.updated_at, r.form_id, r.submitted_by FROM answers a
 JOIN form_responses r ON a.response_id = r.id
-WHERE a.question_id = $1 AND r.form_id = $2
+WHERE a.question_id = $1 AND r.form_id = $2 AND NOT r.is_test
 ORDER BY a.created_at ASC
 `
 
@@ -268,7 +400,7 @@ func (q *Queries) GetAnswersByResponseID(ctx context.Context, responseID uuid.UU
 }
 
 const getByFormIDAndSubmittedBy = `-- name: GetByFormIDAndSubmittedBy :one
-SELECT id, form_id, submitted_by, submitted_at, created_at, updated_at FROM form_responses
+SELECT id, form_id, submitted_by, submitted_at, created_at, updated_at, is_test, anonymized_at FROM form_responses
 WHERE form_id = $1 AND submitted_by = $2
 `
 
@@ -287,18 +419,90 @@ func (q *Queries) GetByFormIDAndSubmittedBy(ctx context.Context, arg GetByFormID
 		&i.SubmittedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IsTest,
+		&i.AnonymizedAt,
 	)
 	return i, err
 }
 
+const isFormHeld = `-- name: IsFormHeld :one
+SELECT EXISTS(
+    SELECT 1 FROM legal_holds
+    WHERE form_id = $1 AND response_id IS NULL AND released_at IS NULL
+)
+`
+
+func (q *Queries) IsFormHeld(ctx context.Context, formID uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isFormHeld, formID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const isHeld = `-- name: IsHeld :one
+SELECT EXISTS(
+    SELECT 1 FROM legal_holds h
+    JOIN form_responses r ON r.form_id = h.form_id
+    WHERE r.id = $1 AND h.released_at IS NULL AND (h.response_id IS NULL OR h.response_id = r.id)
+)
+`
+
+// A response is held by a hold on it or on its whole form
+func (q *Queries) IsHeld(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isHeld, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listAnswerRevisionsByResponseID = `-- name: ListAnswerRevisionsByResponseID :many
+SELECT id, answer_id, response_id, question_id, previous_value, value, edited_by, created_at FROM answer_revisions
+WHERE response_id = $1
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListAnswerRevisionsByResponseID(ctx context.Context, responseID uuid.UUID) ([]AnswerRevision, error) {
+	rows, err := q.db.Query(ctx, listAnswerRevisionsByResponseID, responseID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AnswerRevision
+	for rows.Next() {
+		var i AnswerRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.AnswerID,
+			&i.ResponseID,
+			&i.QuestionID,
+			&i.PreviousValue,
+			&i.Value,
+			&i.EditedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listByFormID = `-- name: ListByFormID :many
-SELECT id, form_id, submitted_by, submitted_at, created_at, updated_at FROM form_responses
-WHERE form_id = $1
+SELECT id, form_id, submitted_by, submitted_at, created_at, updated_at, is_test, anonymized_at FROM form_responses
+WHERE form_id = $1 AND is_test = $2
 ORDER BY created_at ASC
 `
 
-func (q *Queries) ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormResponse, error) {
-	rows, err := q.db.Query(ctx, listByFormID, formID)
+type ListByFormIDParams struct {
+	FormID uuid.UUID
+	IsTest bool
+}
+
+func (q *Queries) ListByFormID(ctx context.Context, arg ListByFormIDParams) ([]FormResponse, error) {
+	rows, err := q.db.Query(ctx, listByFormID, arg.FormID, arg.IsTest)
 	if err != nil {
 		return nil, err
 	}
@@ -313,6 +517,8 @@ func (q *Queries) ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormRes
 			&i.SubmittedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsTest,
+			&i.AnonymizedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -325,7 +531,7 @@ func (q *Queries) ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormRes
 }
 
 const listBySubmittedBy = `-- name: ListBySubmittedBy :many
-SELECT id, form_id, submitted_by, submitted_at, created_at, updated_at FROM form_responses
+SELECT id, form_id, submitted_by, submitted_at, created_at, updated_at, is_test, anonymized_at FROM form_responses
 WHERE submitted_by = $1
 ORDER BY submitted_at DESC NULLS LAST
 `
@@ -346,6 +552,141 @@ func (q *Queries) ListBySubmittedBy(ctx context.Context, submittedBy uuid.UUID)
 			&i.SubmittedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IsTest,
+			&i.AnonymizedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMatchingAnswerPairs = `-- name: ListMatchingAnswerPairs :many
+SELECT a.response_id, b.response_id AS other_response_id, a.value, b.value AS other_value FROM answers a
+JOIN form_responses ra ON ra.id = a.response_id
+JOIN answers b ON b.question_id = a.question_id AND b.response_id > a.response_id
+    AND lower(btrim(b.value)) = lower(btrim(a.value))
+JOIN form_responses rb ON rb.id = b.response_id
+WHERE a.question_id = $1 AND ra.form_id = $2
+  AND NOT ra.is_test AND NOT rb.is_test AND btrim(a.value) <> ''
+`
+
+type ListMatchingAnswerPairsParams struct {
+	QuestionID uuid.UUID
+	FormID     uuid.UUID
+}
+
+type ListMatchingAnswerPairsRow struct {
+	ResponseID      uuid.UUID
+	OtherResponseID uuid.UUID
+	Value           string
+	OtherValue      string
+}
+
+// Pairs of responses giving the same answer to the question, ignoring case and surrounding spaces
+func (q *Queries) ListMatchingAnswerPairs(ctx context.Context, arg ListMatchingAnswerPairsParams) ([]ListMatchingAnswerPairsRow, error) {
+	rows, err := q.db.Query(ctx, listMatchingAnswerPairs, arg.QuestionID, arg.FormID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMatchingAnswerPairsRow
+	for rows.Next() {
+		var i ListMatchingAnswerPairsRow
+		if err := rows.Scan(
+			&i.ResponseID,
+			&i.OtherResponseID,
+			&i.Value,
+			&i.OtherValue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSameUserPairs = `-- name: ListSameUserPairs :many
+SELECT a.id AS response_id, b.id AS other_response_id, a.submitted_by FROM form_responses a
+JOIN form_responses b ON b.form_id = a.form_id AND b.submitted_by = a.submitted_by AND b.id > a.id
+WHERE a.form_id = $1 AND NOT a.is_test AND NOT b.is_test
+`
+
+type ListSameUserPairsRow struct {
+	ResponseID      uuid.UUID
+	OtherResponseID uuid.UUID
+	SubmittedBy     uuid.UUID
+}
+
+// Pairs of responses to the form submitted by the same user, each pair once
+func (q *Queries) ListSameUserPairs(ctx context.Context, formID uuid.UUID) ([]ListSameUserPairsRow, error) {
+	rows, err := q.db.Query(ctx, listSameUserPairs, formID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSameUserPairsRow
+	for rows.Next() {
+		var i ListSameUserPairsRow
+		if err := rows.Scan(&i.ResponseID, &i.OtherResponseID, &i.SubmittedBy); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSimilarAnswerPairs = `-- name: ListSimilarAnswerPairs :many
+SELECT a.response_id, b.response_id AS other_response_id, a.value, b.value AS other_value,
+    similarity(a.value, b.value)::float8 AS score FROM answers a
+JOIN form_responses ra ON ra.id = a.response_id
+JOIN answers b ON b.question_id = a.question_id AND b.response_id > a.response_id
+JOIN form_responses rb ON rb.id = b.response_id
+WHERE a.question_id = $1 AND ra.form_id = $2
+  AND NOT ra.is_test AND NOT rb.is_test AND btrim(a.value) <> ''
+  AND similarity(a.value, b.value) >= $3::float8
+`
+
+type ListSimilarAnswerPairsParams struct {
+	QuestionID uuid.UUID
+	FormID     uuid.UUID
+	Threshold  float64
+}
+
+type ListSimilarAnswerPairsRow struct {
+	ResponseID      uuid.UUID
+	OtherResponseID uuid.UUID
+	Value           string
+	OtherValue      string
+	Score           float64
+}
+
+// Pairs of responses whose answers to the question have a trigram similarity of at least the threshold
+func (q *Queries) ListSimilarAnswerPairs(ctx context.Context, arg ListSimilarAnswerPairsParams) ([]ListSimilarAnswerPairsRow, error) {
+	rows, err := q.db.Query(ctx, listSimilarAnswerPairs, arg.QuestionID, arg.FormID, arg.Threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListSimilarAnswerPairsRow
+	for rows.Next() {
+		var i ListSimilarAnswerPairsRow
+		if err := rows.Scan(
+			&i.ResponseID,
+			&i.OtherResponseID,
+			&i.Value,
+			&i.OtherValue,
+			&i.Score,
 		); err != nil {
 			return nil, err
 		}
@@ -394,3 +735,20 @@ func (q *Queries) UpdateAnswer(ctx context.Context, arg UpdateAnswerParams) (Ans
 	)
 	return i, err
 }
+
+const updateAnswers = `-- name: UpdateAnswers :exec
+UPDATE answers a
+SET value = u.value, updated_at = now()
+FROM unnest($1::uuid[], $2::text[]) AS u(id, value)
+WHERE a.id = u.id
+`
+
+type UpdateAnswersParams struct {
+	Ids    []uuid.UUID
+	Values []string
+}
+
+func (q *Queries) UpdateAnswers(ctx context.Context, arg UpdateAnswersParams) error {
+	_, err := q.db.Exec(ctx, updateAnswers, arg.Ids, arg.Values)
+	return err
+}
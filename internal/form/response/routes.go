@@ -0,0 +1,18 @@
+package response
+
+import (
+	"NYCU-SDC/core-system-backend/internal/conditional"
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the responses of a form, their history, their duplicates and the
+// answers to a question
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{formId}/responses", route.Delegate, route.PermissionNone, conditional.Middleware(h.ListHandler))
+	r.Handle("GET /forms/{formId}/responses/duplicates", route.Delegate, route.PermissionNone, h.DuplicatesHandler)
+	r.Handle("GET /forms/{formId}/responses/{responseId}", route.Delegate, route.PermissionNone, h.GetHandler)
+	r.Handle("DELETE /forms/{formId}/responses/{responseId}", route.Authenticated, route.PermissionNone, h.DeleteHandler)
+	r.Handle("DELETE /forms/{formId}/responses/test", route.Authenticated, route.PermissionNone, h.DeleteTestHandler)
+	r.Handle("GET /forms/{formId}/responses/{responseId}/history", route.Delegate, route.PermissionNone, h.HistoryHandler)
+	r.Handle("GET /forms/{formId}/questions/{questionId}", route.Delegate, route.PermissionNone, h.GetAnswersByQuestionIDHandler)
+}
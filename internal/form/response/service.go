@@ -3,6 +3,7 @@ package response
 import (
 	"NYCU-SDC/core-system-backend/internal/form/shared"
 	"context"
+	"errors"
 	"fmt"
 
 	"NYCU-SDC/core-system-backend/internal"
@@ -10,6 +11,7 @@ import (
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -20,34 +22,82 @@ type Querier interface {
 	Get(ctx context.Context, arg GetParams) (FormResponse, error)
 	GetByFormIDAndSubmittedBy(ctx context.Context, arg GetByFormIDAndSubmittedByParams) (FormResponse, error)
 	Exists(ctx context.Context, arg ExistsParams) (bool, error)
-	ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormResponse, error)
+	ListByFormID(ctx context.Context, arg ListByFormIDParams) ([]FormResponse, error)
 	Update(ctx context.Context, id uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteTestByFormID(ctx context.Context, formID uuid.UUID) (int64, error)
 	CreateAnswer(ctx context.Context, arg CreateAnswerParams) (Answer, error)
+	CreateAnswers(ctx context.Context, arg CreateAnswersParams) error
 	GetAnswersByQuestionID(ctx context.Context, arg GetAnswersByQuestionIDParams) ([]GetAnswersByQuestionIDRow, error)
 	GetAnswersByResponseID(ctx context.Context, responseID uuid.UUID) ([]Answer, error)
 	UpdateAnswer(ctx context.Context, arg UpdateAnswerParams) (Answer, error)
+	UpdateAnswers(ctx context.Context, arg UpdateAnswersParams) error
 	AnswerExists(ctx context.Context, arg AnswerExistsParams) (bool, error)
 	CheckAnswerContent(ctx context.Context, arg CheckAnswerContentParams) (bool, error)
 	GetAnswerID(ctx context.Context, arg GetAnswerIDParams) (uuid.UUID, error)
+	GetAnswer(ctx context.Context, arg GetAnswerParams) (Answer, error)
+	CreateAnswerRevision(ctx context.Context, arg CreateAnswerRevisionParams) (AnswerRevision, error)
+	CreateAnswerRevisions(ctx context.Context, arg CreateAnswerRevisionsParams) error
+	ListAnswerRevisionsByResponseID(ctx context.Context, responseID uuid.UUID) ([]AnswerRevision, error)
 	ListBySubmittedBy(ctx context.Context, submittedBy uuid.UUID) ([]FormResponse, error)
+	IsHeld(ctx context.Context, id uuid.UUID) (bool, error)
+	IsFormHeld(ctx context.Context, formID uuid.UUID) (bool, error)
+	ListSameUserPairs(ctx context.Context, formID uuid.UUID) ([]ListSameUserPairsRow, error)
+	ListMatchingAnswerPairs(ctx context.Context, arg ListMatchingAnswerPairsParams) ([]ListMatchingAnswerPairsRow, error)
+	ListSimilarAnswerPairs(ctx context.Context, arg ListSimilarAnswerPairsParams) ([]ListSimilarAnswerPairsRow, error)
+}
+
+// DB is the connection the service runs on, a pool or a transaction; answers are
+// written in a transaction begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
 type Service struct {
 	logger  *zap.Logger
+	db      DB
 	queries Querier
 	tracer  trace.Tracer
 }
 
-func NewService(logger *zap.Logger, db DBTX) *Service {
+func NewService(logger *zap.Logger, db DB) *Service {
 	return &Service{
 		logger:  logger,
+		db:      db,
 		queries: New(db),
 		tracer:  otel.Tracer("response/service"),
 	}
 }
 
-func (s Service) CreateOrUpdate(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam, questionType []QuestionType) (FormResponse, error) {
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+// CreateOrUpdate saves the answers of a user to a form. isTest flags a new response as
+// test data; it has no effect on an existing response. The response and all its answers
+// are written in one transaction, with one query per kind of change whatever the number
+// of answers.
+func (s Service) CreateOrUpdate(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam, questionType []QuestionType, isTest bool) (FormResponse, error) {
 	traceCtx, span := s.tracer.Start(ctx, "CreateOrUpdate")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
@@ -59,57 +109,82 @@ func (s Service) CreateOrUpdate(ctx context.Context, formID uuid.UUID, userID uu
 		return FormResponse{}, err
 	}
 
-	exists, err := s.queries.Exists(traceCtx, ExistsParams{
-		FormID:      formID,
-		SubmittedBy: userID,
+	var result FormResponse
+	err := s.inTx(traceCtx, logger, func(queries Querier) error {
+		currentResponse, err := queries.GetByFormIDAndSubmittedBy(traceCtx, GetByFormIDAndSubmittedByParams{
+			FormID:      formID,
+			SubmittedBy: userID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			result, err = s.create(traceCtx, logger, queries, formID, userID, answers, questionType, isTest)
+			return err
+		}
+		if err != nil {
+			return databaseutil.WrapDBError(err, logger, "get response by form id and submitted by")
+		}
+
+		result, err = s.update(traceCtx, logger, queries, currentResponse, userID, answers, questionType)
+		return err
 	})
 	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "check if response exists")
 		span.RecordError(err)
 		return FormResponse{}, err
 	}
 
-	if exists {
-		return s.Update(traceCtx, formID, userID, answers, questionType)
-	} else {
-		return s.Create(traceCtx, formID, userID, answers, questionType)
-	}
+	return result, nil
 }
 
 // Create creates a new response and answers for a given form and user
-func (s Service) Create(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam, questionType []QuestionType) (FormResponse, error) {
+func (s Service) Create(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam, questionType []QuestionType, isTest bool) (FormResponse, error) {
 	traceCtx, span := s.tracer.Start(ctx, "Create")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	newResponse, err := s.queries.Create(traceCtx, CreateParams{
-		FormID:      formID,
-		SubmittedBy: userID,
+	var newResponse FormResponse
+	err := s.inTx(traceCtx, logger, func(queries Querier) error {
+		var err error
+		newResponse, err = s.create(traceCtx, logger, queries, formID, userID, answers, questionType, isTest)
+		return err
 	})
 	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "create response")
 		span.RecordError(err)
 		return FormResponse{}, err
 	}
 
+	return newResponse, nil
+}
+
+func (s Service) create(ctx context.Context, logger *zap.Logger, queries Querier, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam, questionType []QuestionType, isTest bool) (FormResponse, error) {
+	newResponse, err := queries.Create(ctx, CreateParams{
+		FormID:      formID,
+		SubmittedBy: userID,
+		IsTest:      isTest,
+	})
+	if err != nil {
+		return FormResponse{}, databaseutil.WrapDBError(err, logger, "create response")
+	}
+
+	params := CreateAnswersParams{
+		ResponseID:  newResponse.ID,
+		QuestionIds: make([]uuid.UUID, 0, len(answers)),
+		Types:       make([]string, 0, len(answers)),
+		Values:      make([]string, 0, len(answers)),
+	}
 	for i, answer := range answers {
 		questionID, err := internal.ParseUUID(answer.QuestionID)
 		if err != nil {
-			err = databaseutil.WrapDBError(err, logger, "parse question id")
-			span.RecordError(err)
-			return FormResponse{}, err
+			return FormResponse{}, databaseutil.WrapDBError(err, logger, "parse question id")
 		}
 
-		_, err = s.queries.CreateAnswer(traceCtx, CreateAnswerParams{
-			ResponseID: newResponse.ID,
-			QuestionID: questionID,
-			Type:       questionType[i],
-			Value:      answer.Value,
-		})
+		params.QuestionIds = append(params.QuestionIds, questionID)
+		params.Types = append(params.Types, string(questionType[i]))
+		params.Values = append(params.Values, answer.Value)
+	}
+
+	if len(params.QuestionIds) > 0 {
+		err = queries.CreateAnswers(ctx, params)
 		if err != nil {
-			err = databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", newResponse.ID.String(), logger, "create answer")
-			span.RecordError(err)
-			return FormResponse{}, err
+			return FormResponse{}, databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", newResponse.ID.String(), logger, "create answers")
 		}
 	}
 
@@ -121,90 +196,105 @@ func (s Service) Update(ctx context.Context, formID uuid.UUID, userID uuid.UUID,
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	currentResponse, err := s.queries.GetByFormIDAndSubmittedBy(traceCtx, GetByFormIDAndSubmittedByParams{
-		FormID:      formID,
-		SubmittedBy: userID,
+	var currentResponse FormResponse
+	err := s.inTx(traceCtx, logger, func(queries Querier) error {
+		var err error
+		currentResponse, err = queries.GetByFormIDAndSubmittedBy(traceCtx, GetByFormIDAndSubmittedByParams{
+			FormID:      formID,
+			SubmittedBy: userID,
+		})
+		if err != nil {
+			return databaseutil.WrapDBError(err, logger, "get response by form id and submitted by")
+		}
+
+		currentResponse, err = s.update(traceCtx, logger, queries, currentResponse, userID, answers, questionType)
+		return err
 	})
 	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "get response by form id and submitted by")
 		span.RecordError(err)
 		return FormResponse{}, err
 	}
 
+	return currentResponse, nil
+}
+
+// update saves the answers to an existing response: new answers are created, changed
+// ones are updated keeping their previous value as a revision, and unchanged ones are
+// left alone. When a question is answered more than once, the last answer wins.
+func (s Service) update(ctx context.Context, logger *zap.Logger, queries Querier, currentResponse FormResponse, userID uuid.UUID, answers []shared.AnswerParam, questionType []QuestionType) (FormResponse, error) {
+	existing, err := queries.GetAnswersByResponseID(ctx, currentResponse.ID)
+	if err != nil {
+		return FormResponse{}, databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", currentResponse.ID.String(), logger, "get answers by response id")
+	}
+
+	existingByQuestion := make(map[uuid.UUID]Answer, len(existing))
+	for _, answer := range existing {
+		existingByQuestion[answer.QuestionID] = answer
+	}
+
+	// Keep the last answer of each question, in the order the questions were first answered
+	order := make([]uuid.UUID, 0, len(answers))
+	latest := make(map[uuid.UUID]int, len(answers))
 	for i, answer := range answers {
-		// check if answer exists
 		questionID, err := internal.ParseUUID(answer.QuestionID)
 		if err != nil {
-			err = databaseutil.WrapDBError(err, logger, "parse question id")
-			span.RecordError(err)
-			return FormResponse{}, err
+			return FormResponse{}, databaseutil.WrapDBError(err, logger, "parse question id")
 		}
-		answerExists, err := s.queries.AnswerExists(traceCtx, AnswerExistsParams{
-			ResponseID: currentResponse.ID,
-			QuestionID: questionID,
-		})
-		if err != nil {
-			err = databaseutil.WrapDBError(err, logger, "check if answer exists")
-			span.RecordError(err)
-			return FormResponse{}, err
+		if _, seen := latest[questionID]; !seen {
+			order = append(order, questionID)
 		}
+		latest[questionID] = i
+	}
 
-		// if answer does not exist, create it
-		if !answerExists {
-			_, err = s.queries.CreateAnswer(traceCtx, CreateAnswerParams{
-				ResponseID: currentResponse.ID,
-				QuestionID: questionID,
-				Type:       questionType[i],
-				Value:      answer.Value,
-			})
-			if err != nil {
-				err = databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", currentResponse.ID.String(), logger, "create answer")
-				span.RecordError(err)
-				return FormResponse{}, err
-			}
+	created := CreateAnswersParams{ResponseID: currentResponse.ID}
+	updated := UpdateAnswersParams{}
+	revisions := CreateAnswerRevisionsParams{ResponseID: currentResponse.ID, EditedBy: userID}
+	for _, questionID := range order {
+		i := latest[questionID]
+		value := answers[i].Value
+
+		previous, ok := existingByQuestion[questionID]
+		if !ok {
+			created.QuestionIds = append(created.QuestionIds, questionID)
+			created.Types = append(created.Types, string(questionType[i]))
+			created.Values = append(created.Values, value)
+			continue
+		}
+		if previous.Value == value {
+			continue
 		}
 
-		// if answer exists, check if it is the same as the new answer
-		sameAnswer, err := s.queries.CheckAnswerContent(traceCtx, CheckAnswerContentParams{
-			ResponseID: currentResponse.ID,
-			QuestionID: questionID,
-			Value:      answer.Value,
-		})
+		updated.Ids = append(updated.Ids, previous.ID)
+		updated.Values = append(updated.Values, value)
+		revisions.AnswerIds = append(revisions.AnswerIds, previous.ID)
+		revisions.QuestionIds = append(revisions.QuestionIds, questionID)
+		revisions.PreviousValues = append(revisions.PreviousValues, previous.Value)
+		revisions.Values = append(revisions.Values, value)
+	}
+
+	if len(created.QuestionIds) > 0 {
+		err = queries.CreateAnswers(ctx, created)
 		if err != nil {
-			err = databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", currentResponse.ID.String(), logger, "check answer content")
-			span.RecordError(err)
-			return FormResponse{}, err
+			return FormResponse{}, databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", currentResponse.ID.String(), logger, "create answers")
 		}
+	}
 
-		// if answer is different, update it
-		if !sameAnswer {
-			answerID, err := s.queries.GetAnswerID(traceCtx, GetAnswerIDParams{
-				ResponseID: currentResponse.ID,
-				QuestionID: questionID,
-			})
-			if err != nil {
-				err = databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", currentResponse.ID.String(), logger, "get answer id")
-				span.RecordError(err)
-				return FormResponse{}, err
-			}
-			_, err = s.queries.UpdateAnswer(traceCtx, UpdateAnswerParams{
-				ID:    answerID,
-				Value: answer.Value,
-			})
-			if err != nil {
-				err = databaseutil.WrapDBErrorWithKeyValue(err, "answer", "id", answerID.String(), logger, "update answer")
-				span.RecordError(err)
-				return FormResponse{}, err
-			}
+	if len(updated.Ids) > 0 {
+		err = queries.UpdateAnswers(ctx, updated)
+		if err != nil {
+			return FormResponse{}, databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", currentResponse.ID.String(), logger, "update answers")
+		}
+
+		err = queries.CreateAnswerRevisions(ctx, revisions)
+		if err != nil {
+			return FormResponse{}, databaseutil.WrapDBErrorWithKeyValue(err, "answer_revisions", "response_id", currentResponse.ID.String(), logger, "create answer revisions")
 		}
 	}
 
 	// update the value of updated_at of response
-	err = s.queries.Update(traceCtx, currentResponse.ID)
+	err = queries.Update(ctx, currentResponse.ID)
 	if err != nil {
-		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "id", currentResponse.ID.String(), logger, "update response")
-		span.RecordError(err)
-		return FormResponse{}, err
+		return FormResponse{}, databaseutil.WrapDBErrorWithKeyValue(err, "response", "id", currentResponse.ID.String(), logger, "update response")
 	}
 	return currentResponse, nil
 }
@@ -235,13 +325,84 @@ func (s Service) Get(ctx context.Context, formID uuid.UUID, id uuid.UUID) (FormR
 	return currentResponse, answers, nil
 }
 
-// ListByFormID retrieves all responses for a given form
-func (s Service) ListByFormID(ctx context.Context, formID uuid.UUID) ([]FormResponse, error) {
+// ListHistory retrieves the revisions of every edited answer of a response, oldest first.
+// Answers that were never changed after they were first saved have no revisions.
+func (s Service) ListHistory(ctx context.Context, formID uuid.UUID, id uuid.UUID) ([]AnswerRevision, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListHistory")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	_, err := s.queries.Get(traceCtx, GetParams{
+		ID:     id,
+		FormID: formID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "id", id.String(), logger, "get response by id")
+		span.RecordError(err)
+		return []AnswerRevision{}, err
+	}
+
+	revisions, err := s.queries.ListAnswerRevisionsByResponseID(traceCtx, id)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answer_revisions", "response_id", id.String(), logger, "list answer revisions by response id")
+		span.RecordError(err)
+		return []AnswerRevision{}, err
+	}
+
+	return revisions, nil
+}
+
+// GetAnswersByFormIDAndSubmittedBy retrieves the answers a user has saved for a form.
+// Returns an empty slice if the user has no response for the form yet.
+func (s Service) GetAnswersByFormIDAndSubmittedBy(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]Answer, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetAnswersByFormIDAndSubmittedBy")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	exists, err := s.queries.Exists(traceCtx, ExistsParams{
+		FormID:      formID,
+		SubmittedBy: userID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "check if response exists")
+		span.RecordError(err)
+		return []Answer{}, err
+	}
+	if !exists {
+		return []Answer{}, nil
+	}
+
+	currentResponse, err := s.queries.GetByFormIDAndSubmittedBy(traceCtx, GetByFormIDAndSubmittedByParams{
+		FormID:      formID,
+		SubmittedBy: userID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "form_id", formID.String(), logger, "get response by form id and submitted by")
+		span.RecordError(err)
+		return []Answer{}, err
+	}
+
+	answers, err := s.queries.GetAnswersByResponseID(traceCtx, currentResponse.ID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answer", "response_id", currentResponse.ID.String(), logger, "get answers by response id")
+		span.RecordError(err)
+		return []Answer{}, err
+	}
+
+	return answers, nil
+}
+
+// ListByFormID retrieves the responses for a given form, either the real ones or the
+// test responses submitted in preview mode
+func (s Service) ListByFormID(ctx context.Context, formID uuid.UUID, isTest bool) ([]FormResponse, error) {
 	traceCtx, span := s.tracer.Start(ctx, "ListByFormID")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	responses, err := s.queries.ListByFormID(traceCtx, formID)
+	responses, err := s.queries.ListByFormID(traceCtx, ListByFormIDParams{
+		FormID: formID,
+		IsTest: isTest,
+	})
 	if err != nil {
 		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "form_id", formID.String(), logger, "list responses by form id")
 		span.RecordError(err)
@@ -257,7 +418,20 @@ func (s Service) Delete(ctx context.Context, id uuid.UUID) error {
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	err := s.queries.Delete(traceCtx, id)
+	held, err := s.queries.IsHeld(traceCtx, id)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "legal_holds", "response_id", id.String(), logger, "check legal hold")
+		span.RecordError(err)
+		return err
+	}
+	if held {
+		logger.Info("Refused to delete held response", zap.String("response_id", id.String()))
+		err = internal.ErrLegalHold
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.queries.Delete(traceCtx, id)
 	if err != nil {
 		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "id", id.String(), logger, "delete response")
 		span.RecordError(err)
@@ -267,6 +441,37 @@ func (s Service) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// DeleteTest deletes every test response of a form and returns how many were deleted.
+// Test responses held on their own are kept; a hold on the form refuses the purge.
+func (s Service) DeleteTest(ctx context.Context, formID uuid.UUID) (int64, error) {
+	traceCtx, span := s.tracer.Start(ctx, "DeleteTest")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	held, err := s.queries.IsFormHeld(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "legal_holds", "form_id", formID.String(), logger, "check legal hold")
+		span.RecordError(err)
+		return 0, err
+	}
+	if held {
+		err = internal.ErrLegalHold
+		span.RecordError(err)
+		return 0, err
+	}
+
+	deleted, err := s.queries.DeleteTestByFormID(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "response", "form_id", formID.String(), logger, "delete test responses")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	logger.Info("Deleted test responses", zap.String("form_id", formID.String()), zap.Int64("count", deleted))
+
+	return deleted, nil
+}
+
 // GetAnswersByQuestionID retrieves all answers for a given question
 func (s Service) GetAnswersByQuestionID(ctx context.Context, questionID uuid.UUID, formID uuid.UUID) ([]GetAnswersByQuestionIDRow, error) {
 	traceCtx, span := s.tracer.Start(ctx, "GetAnswersByQuestionID")
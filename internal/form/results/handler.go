@@ -0,0 +1,270 @@
+package results
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	GetShare(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Share, error)
+	Share(ctx context.Context, formID uuid.UUID, expiresAt *time.Time, userID uuid.UUID) (Share, error)
+	Unshare(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+	Summary(ctx context.Context, formID uuid.UUID, signature string) (Summary, error)
+}
+
+// ShareRequest leaves the results shared until revoked when ExpiresAt is omitted
+type ShareRequest struct {
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type ShareResponse struct {
+	FormID    string     `json:"formId"`
+	JSONLink  string     `json:"jsonLink"`
+	HTMLLink  string     `json:"htmlLink"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type OptionResponse struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Count       int      `json:"count"`
+	AverageRank *float64 `json:"averageRank,omitempty"`
+}
+
+type BucketResponse struct {
+	Value int `json:"value"`
+	Count int `json:"count"`
+}
+
+type QuestionResponse struct {
+	QuestionID string           `json:"questionId"`
+	Title      string           `json:"title"`
+	Type       string           `json:"type"`
+	Answered   int              `json:"answered"`
+	Options    []OptionResponse `json:"options,omitempty"`
+	Buckets    []BucketResponse `json:"buckets,omitempty"`
+	Average    *float64         `json:"average,omitempty"`
+}
+
+type SummaryResponse struct {
+	FormID      string             `json:"formId"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Responses   int64              `json:"responses"`
+	Questions   []QuestionResponse `json:"questions"`
+	GeneratedAt time.Time          `json:"generatedAt"`
+}
+
+func ToShareResponse(share Share) ShareResponse {
+	response := ShareResponse{
+		FormID:    share.FormID.String(),
+		JSONLink:  share.JSONLink,
+		HTMLLink:  share.HTMLLink,
+		CreatedAt: share.CreatedAt.Time,
+	}
+	if share.ExpiresAt.Valid {
+		response.ExpiresAt = &share.ExpiresAt.Time
+	}
+	return response
+}
+
+func ToSummaryResponse(summary Summary) SummaryResponse {
+	questions := make([]QuestionResponse, len(summary.Questions))
+	for i, q := range summary.Questions {
+		questions[i] = QuestionResponse{
+			QuestionID: q.QuestionID.String(),
+			Title:      q.Title,
+			Type:       string(q.Type),
+			Answered:   q.Answered,
+		}
+		for _, option := range q.Options {
+			response := OptionResponse{ID: option.ID.String(), Name: option.Name, Count: option.Count}
+			if q.Type == question.QuestionTypeRanking && option.Count > 0 {
+				response.AverageRank = &option.AverageRank
+			}
+			questions[i].Options = append(questions[i].Options, response)
+		}
+		for _, bucket := range q.Buckets {
+			questions[i].Buckets = append(questions[i].Buckets, BucketResponse{Value: bucket.Value, Count: bucket.Count})
+		}
+		if q.Buckets != nil {
+			average := q.Average
+			questions[i].Average = &average
+		}
+	}
+	return SummaryResponse{
+		FormID:      summary.FormID.String(),
+		Title:       summary.Title,
+		Description: summary.Description,
+		Responses:   summary.Responses,
+		Questions:   questions,
+		GeneratedAt: summary.GeneratedAt,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("results/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) GetShareHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetShareHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	share, err := h.store.GetShare(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToShareResponse(share))
+}
+
+func (h *Handler) ShareHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ShareHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ShareRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	share, err := h.store.Share(traceCtx, formID, req.ExpiresAt, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToShareResponse(share))
+}
+
+func (h *Handler) UnshareHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UnshareHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Unshare(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// summary reads the form and the signature of a results link
+func (h *Handler) summary(ctx context.Context, r *http.Request) (Summary, error) {
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		return Summary{}, err
+	}
+	return h.store.Summary(ctx, formID, r.URL.Query().Get("sig"))
+}
+
+func (h *Handler) SummaryHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SummaryHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	summary, err := h.summary(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToSummaryResponse(summary))
+}
+
+// SummaryPageHandler renders the results as a page of bar charts, for links shared
+// where there is no frontend
+func (h *Handler) SummaryPageHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SummaryPageHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	summary, err := h.summary(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err = resultsPage.Execute(w, summary)
+	if err != nil {
+		logger.Error("Failed to render results page", zap.Error(err))
+	}
+}
@@ -0,0 +1,27 @@
+package results
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/google/uuid"
+)
+
+// signatureLength is the bytes of the HMAC kept in a results link
+const signatureLength = 16
+
+// newNonce picks the value a new share of the results is signed with
+func newNonce() string {
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	return base64.RawURLEncoding.EncodeToString(nonce)
+}
+
+// sign binds a results link to the form and to the share it was given out with
+func sign(secret []byte, formID uuid.UUID, nonce string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("results\n" + formID.String() + "\n" + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLength])
+}
@@ -0,0 +1,45 @@
+package results
+
+import (
+	"html/template"
+)
+
+var resultsPage = template.Must(template.New("results").Funcs(template.FuncMap{
+	"percent": func(count int, total int) int {
+		if total == 0 {
+			return 0
+		}
+		return count * 100 / total
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}} · Results</title>
+<style>
+body { font-family: sans-serif; max-width: 40rem; margin: 2rem auto; padding: 0 1rem; color: #222 }
+section { margin: 2rem 0 }
+.row { display: flex; align-items: center; gap: .5rem; margin: .25rem 0 }
+.label { flex: 0 0 12rem; overflow-wrap: anywhere }
+.bar { flex: 1; background: #eee; height: 1.25rem }
+.fill { background: #3b82f6; height: 100% }
+.count { flex: 0 0 5rem; text-align: right; font-variant-numeric: tabular-nums }
+small { color: #666 }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<p><small>{{.Responses}} responses · updated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}</small></p>
+{{range .Questions}}{{$answered := .Answered}}<section>
+<h2>{{.Title}}</h2>
+<p><small>{{.Answered}} answered{{if .Buckets}} · average {{printf "%.2f" .Average}}{{end}}</small></p>
+{{range .Options}}<div class="row"><span class="label">{{.Name}}</span><span class="bar"><span class="fill" style="display: block; width: {{percent .Count $answered}}%"></span></span><span class="count">{{.Count}}{{if .AverageRank}} · #{{printf "%.1f" .AverageRank}}{{end}}</span></div>
+{{end}}{{range .Buckets}}<div class="row"><span class="label">{{.Value}}</span><span class="bar"><span class="fill" style="display: block; width: {{percent .Count $answered}}%"></span></span><span class="count">{{.Count}}</span></div>
+{{end}}</section>
+{{else}}<p>No results to show.</p>
+{{end}}</body>
+</html>
+`))
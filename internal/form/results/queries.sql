@@ -0,0 +1,43 @@
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
+
+-- name: GetForm :one
+SELECT id, title, description FROM forms
+WHERE id = @form_id;
+
+-- name: GetShare :one
+SELECT * FROM form_result_shares
+WHERE form_id = @form_id;
+
+-- name: UpsertShare :one
+INSERT INTO form_result_shares (form_id, nonce, expires_at, created_by)
+VALUES (@form_id, @nonce, sqlc.narg(expires_at)::timestamptz, @created_by)
+ON CONFLICT (form_id) DO UPDATE
+SET nonce = EXCLUDED.nonce,
+    expires_at = EXCLUDED.expires_at,
+    created_by = EXCLUDED.created_by,
+    created_at = now()
+RETURNING *;
+
+-- name: DeleteShare :execrows
+DELETE FROM form_result_shares
+WHERE form_id = @form_id;
+
+-- name: CountResponses :one
+-- Ballots count as responses of a form in ballot mode
+SELECT ((SELECT COUNT(*) FROM form_responses r WHERE r.form_id = @form_id AND r.submitted_at IS NOT NULL AND NOT r.is_test)
+    + (SELECT COUNT(*) FROM ballots b WHERE b.form_id = @form_id))::bigint AS count;
+
+-- name: ListAnswers :many
+SELECT a.question_id, a.value FROM answers a
+JOIN form_responses r ON r.id = a.response_id
+WHERE r.form_id = @form_id AND r.submitted_at IS NOT NULL AND NOT r.is_test
+UNION ALL
+SELECT ba.question_id, ba.value FROM ballot_answers ba
+JOIN ballots b ON b.id = ba.ballot_id
+WHERE b.form_id = @form_id;
\ No newline at end of file
@@ -0,0 +1,15 @@
+package results
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the sharing of form results and the public results pages, which are
+// authorized by the signature of their link
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/results/share", route.Authenticated, route.PermissionOrgAdmin, h.GetShareHandler)
+	r.Handle("PUT /forms/{id}/results/share", route.Authenticated, route.PermissionOrgAdmin, h.ShareHandler)
+	r.Handle("DELETE /forms/{id}/results/share", route.Authenticated, route.PermissionOrgAdmin, h.UnshareHandler)
+	r.Handle("GET /forms/{id}/results", route.Public, route.PermissionNone, h.SummaryHandler)
+	r.Handle("GET /forms/{id}/results.html", route.Public, route.PermissionNone, h.SummaryPageHandler)
+}
@@ -0,0 +1,240 @@
+package results
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"context"
+	"crypto/hmac"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+	GetForm(ctx context.Context, formID uuid.UUID) (GetFormRow, error)
+	GetShare(ctx context.Context, formID uuid.UUID) (FormResultShare, error)
+	UpsertShare(ctx context.Context, arg UpsertShareParams) (FormResultShare, error)
+	DeleteShare(ctx context.Context, formID uuid.UUID) (int64, error)
+	CountResponses(ctx context.Context, formID uuid.UUID) (int64, error)
+	ListAnswers(ctx context.Context, formID uuid.UUID) ([]ListAnswersRow, error)
+}
+
+type QuestionStore interface {
+	ListByFormID(ctx context.Context, formID uuid.UUID) ([]question.SectionWithQuestions, error)
+}
+
+// Share is a shared results page with the links to it, as JSON for the frontend and as
+// a static HTML page
+type Share struct {
+	FormResultShare
+	JSONLink string
+	HTMLLink string
+}
+
+type Service struct {
+	logger        *zap.Logger
+	queries       Querier
+	tracer        trace.Tracer
+	questionStore QuestionStore
+
+	secret  []byte
+	baseURL string
+}
+
+func NewService(logger *zap.Logger, db DBTX, questionStore QuestionStore, secret string, baseURL string) *Service {
+	return &Service{
+		logger:        logger,
+		queries:       New(db),
+		tracer:        otel.Tracer("results/service"),
+		questionStore: questionStore,
+		secret:        []byte(secret),
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+func (s *Service) toShare(share FormResultShare) Share {
+	query := "?" + url.Values{"sig": {sign(s.secret, share.FormID, share.Nonce)}}.Encode()
+	base := s.baseURL + route.V1 + "/forms/" + share.FormID.String()
+	return Share{
+		FormResultShare: share,
+		JSONLink:        base + "/results" + query,
+		HTMLLink:        base + "/results.html" + query,
+	}
+}
+
+// requireAdmin allows the owner of the organization of the form only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func (s *Service) GetShare(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (Share, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetShare")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Share{}, err
+	}
+
+	share, err := s.queries.GetShare(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrResultShareNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_result_shares", "form_id", formID.String(), logger, "get result share")
+		}
+		span.RecordError(err)
+		return Share{}, err
+	}
+	return s.toShare(share), nil
+}
+
+// Share publishes the results of the form until expiresAt, or for good when nil. Sharing
+// again gives out a new link; the former one stops working.
+func (s *Service) Share(ctx context.Context, formID uuid.UUID, expiresAt *time.Time, userID uuid.UUID) (Share, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Share")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Share{}, err
+	}
+
+	expires := pgtype.Timestamptz{}
+	if expiresAt != nil {
+		expires = pgtype.Timestamptz{Time: *expiresAt, Valid: true}
+	}
+
+	share, err := s.queries.UpsertShare(traceCtx, UpsertShareParams{
+		FormID:    formID,
+		Nonce:     newNonce(),
+		ExpiresAt: expires,
+		CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_result_shares", "form_id", formID.String(), logger, "share results")
+		span.RecordError(err)
+		return Share{}, err
+	}
+
+	logger.Info("Shared form results", zap.String("form_id", formID.String()))
+	return s.toShare(share), nil
+}
+
+// Unshare revokes the results link of the form
+func (s *Service) Unshare(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Unshare")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.queries.DeleteShare(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_result_shares", "form_id", formID.String(), logger, "unshare results")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		err = internal.ErrResultShareNotFound
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// Summary returns the results of the form to the holder of a valid link. A link of a
+// form not shared, revoked or expired is rejected alike.
+func (s *Service) Summary(ctx context.Context, formID uuid.UUID, signature string) (Summary, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Summary")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	share, err := s.queries.GetShare(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrResultLinkInvalid
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_result_shares", "form_id", formID.String(), logger, "get result share")
+		}
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(s.secret, formID, share.Nonce))) ||
+		(share.ExpiresAt.Valid && time.Now().After(share.ExpiresAt.Time)) {
+		err = internal.ErrResultLinkInvalid
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	form, err := s.queries.GetForm(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "get form")
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	responses, err := s.queries.CountResponses(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "count responses")
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	sections, err := s.questionStore.ListByFormID(traceCtx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return Summary{}, err
+	}
+
+	rows, err := s.queries.ListAnswers(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "answers", "form_id", formID.String(), logger, "list answers")
+		span.RecordError(err)
+		return Summary{}, err
+	}
+	answers := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		answers[row.QuestionID] = append(answers[row.QuestionID], row.Value)
+	}
+
+	return Summary{
+		FormID:      form.ID,
+		Title:       form.Title,
+		Description: form.Description.String,
+		Responses:   responses,
+		Questions:   summarize(sections, answers),
+		GeneratedAt: time.Now(),
+	}, nil
+}
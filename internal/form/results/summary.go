@@ -0,0 +1,181 @@
+package results
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Option is how often a choice was picked. AverageRank is set for ranking questions only,
+// 1 being the top.
+type Option struct {
+	ID          uuid.UUID
+	Name        string
+	Count       int
+	AverageRank float64
+}
+
+// Bucket is how often a value of a scale was picked
+type Bucket struct {
+	Value int
+	Count int
+}
+
+// QuestionSummary aggregates the answers to one question. Choice questions fill Options
+// and scales fill Buckets and Average.
+type QuestionSummary struct {
+	QuestionID uuid.UUID
+	Title      string
+	Type       question.QuestionType
+	Answered   int
+	Options    []Option
+	Buckets    []Bucket
+	Average    float64
+}
+
+// Summary is what a results page shows. Only questions with answers that chart well are
+// summarized; free text, dates, files and the like could identify a respondent.
+type Summary struct {
+	FormID      uuid.UUID
+	Title       string
+	Description string
+	Responses   int64
+	Questions   []QuestionSummary
+	GeneratedAt time.Time
+}
+
+// splitIDs reads the ";"-separated choice IDs of an answer
+func splitIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ";") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func summarizeChoices(summary *QuestionSummary, choices []question.Choice, values []string) {
+	index := make(map[string]int, len(choices))
+	summary.Options = make([]Option, len(choices))
+	for i, choice := range choices {
+		index[choice.ID.String()] = i
+		summary.Options[i] = Option{ID: choice.ID, Name: choice.Name}
+	}
+
+	for _, value := range values {
+		ids := splitIDs(value)
+		if len(ids) == 0 {
+			continue
+		}
+		summary.Answered++
+		for _, id := range ids {
+			i, ok := index[id]
+			if ok {
+				summary.Options[i].Count++
+			}
+		}
+	}
+}
+
+func summarizeRanking(summary *QuestionSummary, choices []question.Choice, values []string) {
+	index := make(map[string]int, len(choices))
+	summary.Options = make([]Option, len(choices))
+	positions := make([]int, len(choices))
+	for i, choice := range choices {
+		index[choice.ID.String()] = i
+		summary.Options[i] = Option{ID: choice.ID, Name: choice.Name}
+	}
+
+	for _, value := range values {
+		ids := splitIDs(value)
+		if len(ids) == 0 {
+			continue
+		}
+		summary.Answered++
+		for position, id := range ids {
+			i, ok := index[id]
+			if ok {
+				summary.Options[i].Count++
+				positions[i] += position + 1
+			}
+		}
+	}
+
+	for i := range summary.Options {
+		if summary.Options[i].Count > 0 {
+			summary.Options[i].AverageRank = float64(positions[i]) / float64(summary.Options[i].Count)
+		}
+	}
+}
+
+func summarizeScale(summary *QuestionSummary, minVal, maxVal int, values []string) {
+	if maxVal < minVal {
+		return
+	}
+	summary.Buckets = make([]Bucket, maxVal-minVal+1)
+	for i := range summary.Buckets {
+		summary.Buckets[i].Value = minVal + i
+	}
+
+	total := 0
+	for _, value := range values {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || n < minVal || n > maxVal {
+			continue
+		}
+		summary.Answered++
+		summary.Buckets[n-minVal].Count++
+		total += n
+	}
+	if summary.Answered > 0 {
+		summary.Average = float64(total) / float64(summary.Answered)
+	}
+}
+
+// summarize aggregates the answers of every chartable question, in the order of the
+// questions within their sections. answers holds the values by question.
+func summarize(sections []question.SectionWithQuestions, answers map[uuid.UUID][]string) []QuestionSummary {
+	var summaries []QuestionSummary
+	for _, section := range sections {
+		questions := slices.Clone(section.Questions)
+		slices.SortFunc(questions, func(a, b question.Answerable) int {
+			return cmp.Compare(a.Question().Order, b.Question().Order)
+		})
+
+		for _, answerable := range questions {
+			q := answerable.Question()
+			summary := QuestionSummary{
+				QuestionID: q.ID,
+				Title:      q.Title.String,
+				Type:       q.Type,
+			}
+			values := answers[q.ID]
+
+			switch typed := answerable.(type) {
+			case question.SingleChoice:
+				summarizeChoices(&summary, typed.Choices, values)
+			case question.MultiChoice:
+				summarizeChoices(&summary, typed.Choices, values)
+			case question.DetailedMultiChoice:
+				summarizeChoices(&summary, typed.Choices, values)
+			case question.Ranking:
+				summarizeRanking(&summary, typed.Rank, values)
+			case question.LinearScale:
+				summarizeScale(&summary, typed.MinVal, typed.MaxVal, values)
+			case question.Rating:
+				summarizeScale(&summary, typed.MinVal, typed.MaxVal, values)
+			default:
+				continue
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
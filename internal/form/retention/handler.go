@@ -0,0 +1,352 @@
+package retention
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Get(ctx context.Context, formID uuid.UUID) (FormRetentionPolicy, error)
+	Update(ctx context.Context, formID uuid.UUID, policy Policy, userID uuid.UUID) (FormRetentionPolicy, error)
+	Delete(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+	ListPurges(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]RetentionPurge, error)
+	PlaceHold(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, reason string, userID uuid.UUID) (LegalHold, error)
+	ReleaseHold(ctx context.Context, formID uuid.UUID, id uuid.UUID, userID uuid.UUID) (LegalHold, error)
+	ListHolds(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]LegalHold, error)
+}
+
+// Request sets how many days after the deadline of the form its responses are
+// anonymized and deleted; leaving one out skips that step
+type Request struct {
+	DeleteAfterDays    *int32 `json:"deleteAfterDays" validate:"omitempty,min=1,max=36500"`
+	AnonymizeAfterDays *int32 `json:"anonymizeAfterDays" validate:"omitempty,min=1,max=36500"`
+}
+
+type Response struct {
+	FormID             string    `json:"formId"`
+	DeleteAfterDays    *int32    `json:"deleteAfterDays"`
+	AnonymizeAfterDays *int32    `json:"anonymizeAfterDays"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+type PurgeResponse struct {
+	ID            string    `json:"id"`
+	Action        string    `json:"action"`
+	AfterDays     int32     `json:"afterDays"`
+	ResponseCount int32     `json:"responseCount"`
+	PurgedAt      time.Time `json:"purgedAt"`
+}
+
+// HoldRequest holds the whole form, or only the response when responseId is given
+type HoldRequest struct {
+	ResponseID *uuid.UUID `json:"responseId"`
+	Reason     string     `json:"reason" validate:"required,max=1000"`
+}
+
+type HoldResponse struct {
+	ID         string     `json:"id"`
+	FormID     string     `json:"formId"`
+	ResponseID *string    `json:"responseId,omitempty"`
+	Reason     string     `json:"reason"`
+	PlacedBy   *string    `json:"placedBy,omitempty"`
+	PlacedAt   time.Time  `json:"placedAt"`
+	ReleasedBy *string    `json:"releasedBy,omitempty"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+func toOptionalString(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	value := uuid.UUID(id.Bytes).String()
+	return &value
+}
+
+func ToHoldResponse(hold LegalHold) HoldResponse {
+	response := HoldResponse{
+		ID:         hold.ID.String(),
+		FormID:     hold.FormID.String(),
+		ResponseID: toOptionalString(hold.ResponseID),
+		Reason:     hold.Reason,
+		PlacedBy:   toOptionalString(hold.PlacedBy),
+		PlacedAt:   hold.PlacedAt.Time,
+		ReleasedBy: toOptionalString(hold.ReleasedBy),
+	}
+	if hold.ReleasedAt.Valid {
+		response.ReleasedAt = &hold.ReleasedAt.Time
+	}
+	return response
+}
+
+func ToResponse(policy FormRetentionPolicy) Response {
+	response := Response{
+		FormID:    policy.FormID.String(),
+		UpdatedAt: policy.UpdatedAt.Time,
+	}
+	if policy.DeleteAfterDays.Valid {
+		response.DeleteAfterDays = &policy.DeleteAfterDays.Int32
+	}
+	if policy.AnonymizeAfterDays.Valid {
+		response.AnonymizeAfterDays = &policy.AnonymizeAfterDays.Int32
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("retention/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	policy, err := h.store.Get(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(policy))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	policy, err := h.store.Update(traceCtx, formID, Policy{
+		DeleteAfterDays:    req.DeleteAfterDays,
+		AnonymizeAfterDays: req.AnonymizeAfterDays,
+	}, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(policy))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) ListPurgesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListPurgesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	purges, err := h.store.ListPurges(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]PurgeResponse, len(purges))
+	for i, purge := range purges {
+		responses[i] = PurgeResponse{
+			ID:            purge.ID.String(),
+			Action:        strings.ToUpper(string(purge.Action)),
+			AfterDays:     purge.AfterDays,
+			ResponseCount: purge.ResponseCount,
+			PurgedAt:      purge.PurgedAt.Time,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
+
+func (h *Handler) PlaceHoldHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "PlaceHoldHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req HoldRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responseID := uuid.Nil
+	if req.ResponseID != nil {
+		responseID = *req.ResponseID
+	}
+
+	hold, err := h.store.PlaceHold(traceCtx, formID, responseID, req.Reason, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToHoldResponse(hold))
+}
+
+func (h *Handler) ReleaseHoldHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ReleaseHoldHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	holdID, err := internal.ParseUUID(r.PathValue("holdId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	hold, err := h.store.ReleaseHold(traceCtx, formID, holdID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToHoldResponse(hold))
+}
+
+func (h *Handler) ListHoldsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHoldsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	holds, err := h.store.ListHolds(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	responses := make([]HoldResponse, len(holds))
+	for i, hold := range holds {
+		responses[i] = ToHoldResponse(hold)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, responses)
+}
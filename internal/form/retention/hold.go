@@ -0,0 +1,149 @@
+package retention
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+// requireAdmin allows the owner of the organization of the form only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsFormOrgAdmin(ctx, IsFormOrgAdminParams{
+		FormID: formID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+// PlaceHold puts the form, or only the response when responseID is not uuid.Nil, under
+// a legal hold. Held items are skipped by the retention job and cannot be deleted.
+func (s *Service) PlaceHold(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, reason string, userID uuid.UUID) (LegalHold, error) {
+	traceCtx, span := s.tracer.Start(ctx, "PlaceHold")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return LegalHold{}, err
+	}
+
+	target := pgtype.UUID{}
+	if responseID != uuid.Nil {
+		exists, err := s.queries.ResponseExists(traceCtx, ResponseExistsParams{ResponseID: responseID, FormID: formID})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "check response")
+			span.RecordError(err)
+			return LegalHold{}, err
+		}
+		if !exists {
+			err = fmt.Errorf("%w: %s", internal.ErrResponseNotFound, responseID)
+			span.RecordError(err)
+			return LegalHold{}, err
+		}
+		target = pgtype.UUID{Bytes: responseID, Valid: true}
+	}
+
+	held, err := s.queries.IsHeld(traceCtx, IsHeldParams{FormID: formID, ResponseID: target})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "legal_holds", "form_id", formID.String(), logger, "check legal hold")
+		span.RecordError(err)
+		return LegalHold{}, err
+	}
+	if held {
+		err = internal.ErrLegalHoldExists
+		span.RecordError(err)
+		return LegalHold{}, err
+	}
+
+	hold, err := s.queries.CreateHold(traceCtx, CreateHoldParams{
+		FormID:     formID,
+		ResponseID: target,
+		Reason:     reason,
+		PlacedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "legal_holds", "form_id", formID.String(), logger, "create legal hold")
+		span.RecordError(err)
+		return LegalHold{}, err
+	}
+
+	logger.Info("Placed legal hold",
+		zap.String("hold_id", hold.ID.String()),
+		zap.String("form_id", formID.String()),
+		zap.String("response_id", responseID.String()),
+		zap.String("user_id", userID.String()))
+
+	return hold, nil
+}
+
+// ReleaseHold ends the hold; the retention job picks the items up again on its next run
+func (s *Service) ReleaseHold(ctx context.Context, formID uuid.UUID, id uuid.UUID, userID uuid.UUID) (LegalHold, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ReleaseHold")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return LegalHold{}, err
+	}
+
+	hold, err := s.queries.ReleaseHold(traceCtx, ReleaseHoldParams{
+		ReleasedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		ID:         id,
+		FormID:     formID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrLegalHoldNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "legal_holds", "id", id.String(), logger, "release legal hold")
+		}
+		span.RecordError(err)
+		return LegalHold{}, err
+	}
+
+	logger.Info("Released legal hold",
+		zap.String("hold_id", id.String()),
+		zap.String("form_id", formID.String()),
+		zap.String("user_id", userID.String()))
+
+	return hold, nil
+}
+
+// ListHolds lists the active and released holds of the form, most recent first
+func (s *Service) ListHolds(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]LegalHold, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListHolds")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	holds, err := s.queries.ListHolds(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "legal_holds", "form_id", formID.String(), logger, "list legal holds")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return holds, nil
+}
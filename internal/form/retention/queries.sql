@@ -0,0 +1,107 @@
+-- name: Get :one
+SELECT * FROM form_retention_policies
+WHERE form_id = @form_id;
+
+-- name: Upsert :one
+INSERT INTO form_retention_policies (form_id, delete_after_days, anonymize_after_days, updated_by)
+VALUES (@form_id, @delete_after_days, @anonymize_after_days, @updated_by)
+ON CONFLICT (form_id) DO UPDATE
+SET delete_after_days = EXCLUDED.delete_after_days,
+    anonymize_after_days = EXCLUDED.anonymize_after_days,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = now()
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM form_retention_policies
+WHERE form_id = @form_id;
+
+-- name: ListDue :many
+-- Policies of closed forms with a step whose time has come. A step that already ran
+-- keeps matching, but finds no responses left to purge. Forms under a legal hold are
+-- left out until it is released.
+SELECT p.form_id, p.delete_after_days, p.anonymize_after_days, f.deadline FROM form_retention_policies p
+JOIN forms f ON f.id = p.form_id
+WHERE f.deadline IS NOT NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM legal_holds h
+    WHERE h.form_id = p.form_id AND h.response_id IS NULL AND h.released_at IS NULL
+  )
+  AND ((p.delete_after_days IS NOT NULL AND f.deadline + make_interval(days => p.delete_after_days) <= @now::timestamptz)
+    OR (p.anonymize_after_days IS NOT NULL AND f.deadline + make_interval(days => p.anonymize_after_days) <= @now::timestamptz));
+
+-- name: DeleteResponses :execrows
+DELETE FROM form_responses r
+WHERE r.form_id = @form_id
+  AND NOT EXISTS (
+    SELECT 1 FROM legal_holds h
+    WHERE h.response_id = r.id AND h.released_at IS NULL
+  );
+
+-- name: ListIdentifiedResponses :many
+SELECT r.id, r.submitted_by FROM form_responses r
+WHERE r.form_id = @form_id AND r.anonymized_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM legal_holds h
+    WHERE h.response_id = r.id AND h.released_at IS NULL
+  );
+
+-- name: Anonymize :execrows
+-- Moves the response to a new guest user; a response anonymized concurrently is left alone
+WITH guest AS (
+    INSERT INTO users (name, role, is_onboarded)
+    VALUES ('Anonymous respondent', '{"respondent"}', true)
+    RETURNING id
+)
+UPDATE form_responses
+SET submitted_by = (SELECT id FROM guest), anonymized_at = now()
+WHERE form_responses.id = @response_id AND anonymized_at IS NULL;
+
+-- name: ClearRevisionEditor :exec
+UPDATE answer_revisions
+SET edited_by = NULL
+WHERE response_id = @response_id AND edited_by = @user_id;
+
+-- name: RecordPurge :one
+INSERT INTO retention_purges (form_id, action, after_days, response_count)
+VALUES (@form_id, @action, @after_days, @response_count)
+RETURNING *;
+
+-- name: ListPurges :many
+SELECT * FROM retention_purges
+WHERE form_id = @form_id
+ORDER BY purged_at DESC;
+
+-- name: IsFormOrgAdmin :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    JOIN tenants t ON t.id = COALESCE(u.org_id, u.id)
+    WHERE f.id = @form_id AND t.owner_id = @user_id
+);
+
+-- name: ResponseExists :one
+SELECT EXISTS(SELECT 1 FROM form_responses WHERE id = @response_id AND form_id = @form_id);
+
+-- name: IsHeld :one
+-- Whether the form, or with a response ID that one response, already has an active hold
+SELECT EXISTS(
+    SELECT 1 FROM legal_holds
+    WHERE form_id = @form_id AND response_id IS NOT DISTINCT FROM sqlc.narg(response_id)::uuid AND released_at IS NULL
+);
+
+-- name: CreateHold :one
+INSERT INTO legal_holds (form_id, response_id, reason, placed_by)
+VALUES (@form_id, @response_id, @reason, @placed_by)
+RETURNING *;
+
+-- name: ReleaseHold :one
+UPDATE legal_holds
+SET released_by = @released_by, released_at = now()
+WHERE id = @id AND form_id = @form_id AND released_at IS NULL
+RETURNING *;
+
+-- name: ListHolds :many
+SELECT * FROM legal_holds
+WHERE form_id = @form_id
+ORDER BY placed_at DESC;
\ No newline at end of file
@@ -0,0 +1,16 @@
+package retention
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the retention policy of a form, its purges and its legal holds
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/retention", route.Authenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /forms/{id}/retention", route.Authenticated, route.PermissionOrgAdmin, h.UpdateHandler)
+	r.Handle("DELETE /forms/{id}/retention", route.Authenticated, route.PermissionOrgAdmin, h.DeleteHandler)
+	r.Handle("GET /forms/{id}/retention/purges", route.Authenticated, route.PermissionOrgAdmin, h.ListPurgesHandler)
+	r.Handle("GET /forms/{id}/legal-holds", route.Authenticated, route.PermissionOrgAdmin, h.ListHoldsHandler)
+	r.Handle("POST /forms/{id}/legal-holds", route.Authenticated, route.PermissionOrgAdmin, h.PlaceHoldHandler)
+	r.Handle("POST /forms/{formId}/legal-holds/{holdId}/release", route.Authenticated, route.PermissionOrgAdmin, h.ReleaseHoldHandler)
+}
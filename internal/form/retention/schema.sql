@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS form_retention_policies (
+    form_id UUID PRIMARY KEY REFERENCES forms(id) ON DELETE CASCADE,
+    delete_after_days INT DEFAULT NULL CHECK (delete_after_days > 0),
+    anonymize_after_days INT DEFAULT NULL CHECK (anonymize_after_days > 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TYPE retention_action AS ENUM(
+    'delete',
+    'anonymize'
+);
+
+CREATE TABLE IF NOT EXISTS retention_purges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL,
+    action retention_action NOT NULL,
+    after_days INT NOT NULL,
+    response_count INT NOT NULL,
+    purged_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_retention_purges_form_id ON retention_purges(form_id, purged_at);
+
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL,
+    response_id UUID DEFAULT NULL,
+    reason TEXT NOT NULL,
+    placed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    placed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    released_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    released_at TIMESTAMPTZ DEFAULT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_legal_holds_active ON legal_holds(form_id, response_id) WHERE released_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,296 @@
+package retention
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// DefaultRunInterval is how often the retention policies are enforced
+const DefaultRunInterval = time.Hour
+
+type Querier interface {
+	Get(ctx context.Context, formID uuid.UUID) (FormRetentionPolicy, error)
+	Upsert(ctx context.Context, arg UpsertParams) (FormRetentionPolicy, error)
+	Delete(ctx context.Context, formID uuid.UUID) (int64, error)
+	ListDue(ctx context.Context, now pgtype.Timestamptz) ([]ListDueRow, error)
+	DeleteResponses(ctx context.Context, formID uuid.UUID) (int64, error)
+	ListIdentifiedResponses(ctx context.Context, formID uuid.UUID) ([]ListIdentifiedResponsesRow, error)
+	Anonymize(ctx context.Context, responseID uuid.UUID) (int64, error)
+	ClearRevisionEditor(ctx context.Context, arg ClearRevisionEditorParams) error
+	RecordPurge(ctx context.Context, arg RecordPurgeParams) (RetentionPurge, error)
+	ListPurges(ctx context.Context, formID uuid.UUID) ([]RetentionPurge, error)
+	IsFormOrgAdmin(ctx context.Context, arg IsFormOrgAdminParams) (bool, error)
+	ResponseExists(ctx context.Context, arg ResponseExistsParams) (bool, error)
+	IsHeld(ctx context.Context, arg IsHeldParams) (bool, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (LegalHold, error)
+	ReleaseHold(ctx context.Context, arg ReleaseHoldParams) (LegalHold, error)
+	ListHolds(ctx context.Context, formID uuid.UUID) ([]LegalHold, error)
+}
+
+// Policy is counted in days from the deadline of the form; forms without a deadline
+// never close, so their responses are kept. A nil step is never run.
+type Policy struct {
+	DeleteAfterDays    *int32
+	AnonymizeAfterDays *int32
+}
+
+func toInt4(days *int32) pgtype.Int4 {
+	if days == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *days, Valid: true}
+}
+
+func validatePolicy(policy Policy) error {
+	if policy.DeleteAfterDays == nil && policy.AnonymizeAfterDays == nil {
+		return fmt.Errorf("%w: set deleteAfterDays, anonymizeAfterDays or both", internal.ErrRetentionPolicyInvalid)
+	}
+	if policy.DeleteAfterDays != nil && policy.AnonymizeAfterDays != nil && *policy.AnonymizeAfterDays >= *policy.DeleteAfterDays {
+		return fmt.Errorf("%w: responses must be anonymized before they are deleted", internal.ErrRetentionPolicyInvalid)
+	}
+	return nil
+}
+
+// due reports whether the step set days after the deadline has to run at now
+func due(deadline time.Time, days pgtype.Int4, now time.Time) bool {
+	return days.Valid && !deadline.AddDate(0, 0, int(days.Int32)).After(now)
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("retention/service"),
+	}
+}
+
+func (s *Service) Get(ctx context.Context, formID uuid.UUID) (FormRetentionPolicy, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	policy, err := s.queries.Get(traceCtx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrRetentionPolicyNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_retention_policies", "form_id", formID.String(), logger, "get retention policy")
+		}
+		span.RecordError(err)
+		return FormRetentionPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+// Update sets the retention policy of the form. Only org admins may, as the purge job
+// deletes the responses once the policy is due.
+func (s *Service) Update(ctx context.Context, formID uuid.UUID, policy Policy, userID uuid.UUID) (FormRetentionPolicy, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return FormRetentionPolicy{}, err
+	}
+
+	err = validatePolicy(policy)
+	if err != nil {
+		span.RecordError(err)
+		return FormRetentionPolicy{}, err
+	}
+
+	row, err := s.queries.Upsert(traceCtx, UpsertParams{
+		FormID:             formID,
+		DeleteAfterDays:    toInt4(policy.DeleteAfterDays),
+		AnonymizeAfterDays: toInt4(policy.AnonymizeAfterDays),
+		UpdatedBy:          pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_retention_policies", "form_id", formID.String(), logger, "upsert retention policy")
+		span.RecordError(err)
+		return FormRetentionPolicy{}, err
+	}
+
+	logger.Info("Updated retention policy", zap.String("form_id", formID.String()), zap.String("user_id", userID.String()))
+
+	return row, nil
+}
+
+func (s *Service) Delete(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	deleted, err := s.queries.Delete(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_retention_policies", "form_id", formID.String(), logger, "delete retention policy")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		err = internal.ErrRetentionPolicyNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListPurges lists what the retention job removed from the form, most recent first
+func (s *Service) ListPurges(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]RetentionPurge, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListPurges")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.requireAdmin(traceCtx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	purges, err := s.queries.ListPurges(traceCtx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "retention_purges", "form_id", formID.String(), logger, "list retention purges")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return purges, nil
+}
+
+// Start enforces the retention policies every interval until the context is done
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			err := s.RunDue(ctx, now)
+			if err != nil {
+				s.logger.Error("Failed to enforce retention policies", zap.Error(err))
+			}
+		}
+	}
+}
+
+// RunDue runs the steps of every policy whose time has come. Deleting makes anonymizing
+// moot, so a form past both only has its responses deleted. Forms and responses under a
+// legal hold are skipped. Each purge that touched a response is recorded.
+func (s *Service) RunDue(ctx context.Context, now time.Time) error {
+	traceCtx, span := s.tracer.Start(ctx, "RunDue")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	policies, err := s.queries.ListDue(traceCtx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list due retention policies")
+		span.RecordError(err)
+		return err
+	}
+
+	for _, policy := range policies {
+		var (
+			action    RetentionAction
+			afterDays int32
+			count     int64
+		)
+
+		switch {
+		case due(policy.Deadline.Time, policy.DeleteAfterDays, now):
+			action, afterDays = RetentionActionDelete, policy.DeleteAfterDays.Int32
+			count, err = s.queries.DeleteResponses(traceCtx, policy.FormID)
+			if err != nil {
+				err = databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", policy.FormID.String(), logger, "delete expired responses")
+			}
+		case due(policy.Deadline.Time, policy.AnonymizeAfterDays, now):
+			action, afterDays = RetentionActionAnonymize, policy.AnonymizeAfterDays.Int32
+			count, err = s.anonymize(traceCtx, logger, policy.FormID)
+		default:
+			continue
+		}
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+
+		_, err = s.queries.RecordPurge(traceCtx, RecordPurgeParams{
+			FormID:        policy.FormID,
+			Action:        action,
+			AfterDays:     afterDays,
+			ResponseCount: int32(count),
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "retention_purges", "form_id", policy.FormID.String(), logger, "record retention purge")
+			span.RecordError(err)
+			return err
+		}
+
+		logger.Info("Purged responses",
+			zap.String("form_id", policy.FormID.String()),
+			zap.String("action", string(action)),
+			zap.Int64("response_count", count))
+	}
+
+	return nil
+}
+
+// anonymize moves every identified response of the form to its own guest user, and
+// drops the respondent from the edit history of the response
+func (s *Service) anonymize(ctx context.Context, logger *zap.Logger, formID uuid.UUID) (int64, error) {
+	responses, err := s.queries.ListIdentifiedResponses(ctx, formID)
+	if err != nil {
+		return 0, databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "form_id", formID.String(), logger, "list identified responses")
+	}
+
+	var count int64
+	for _, response := range responses {
+		err = s.queries.ClearRevisionEditor(ctx, ClearRevisionEditorParams{
+			ResponseID: response.ID,
+			UserID:     pgtype.UUID{Bytes: response.SubmittedBy, Valid: true},
+		})
+		if err != nil {
+			return count, databaseutil.WrapDBErrorWithKeyValue(err, "answer_revisions", "response_id", response.ID.String(), logger, "clear revision editor")
+		}
+
+		anonymized, err := s.queries.Anonymize(ctx, response.ID)
+		if err != nil {
+			return count, databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", response.ID.String(), logger, "anonymize response")
+		}
+		count += anonymized
+	}
+
+	return count, nil
+}
@@ -0,0 +1,17 @@
+package form
+
+import (
+	"NYCU-SDC/core-system-backend/internal/conditional"
+	"NYCU-SDC/core-system-backend/internal/form/favorite"
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the forms; viewing a form records it among the recently viewed ones
+func Routes(r route.Router, h *Handler, favorites *favorite.Middleware) {
+	r.Handle("GET /forms", route.Authenticated, route.PermissionNone, h.ListHandler)
+	r.Handle("GET /forms/{id}", route.Respondent, route.PermissionNone, conditional.Middleware(favorites.RecordViewMiddleware(h.GetHandler)))
+	r.Handle("PUT /forms/{id}", route.Delegate, route.PermissionNone, h.UpdateHandler)
+	r.Handle("DELETE /forms/{id}", route.Authenticated, route.PermissionNone, h.DeleteHandler)
+	r.Handle("POST /orgs/{slug}/forms", route.TenantAuthenticated, route.PermissionNone, h.CreateUnderOrgHandler)
+	r.Handle("GET /orgs/{slug}/forms", route.TenantPublic, route.PermissionNone, h.ListByOrgHandler)
+}
@@ -13,7 +13,10 @@ CREATE TABLE IF NOT EXISTS forms (
     last_editor UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
     deadline TIMESTAMPTZ DEFAULT NULL,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
-    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    randomize_questions BOOLEAN NOT NULL DEFAULT false,
+    shuffle_choices BOOLEAN NOT NULL DEFAULT false,
+    time_limit_seconds INTEGER DEFAULT NULL CHECK (time_limit_seconds > 0)
 );
 
 -- Section progress enum (for form completion tracking)
@@ -1,9 +1,13 @@
 package form
 
 import (
+	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/form/response"
+	"cmp"
 	"context"
 	"slices"
+	"strings"
+	"time"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -19,15 +23,39 @@ type Querier interface {
 	Update(ctx context.Context, params UpdateParams) (UpdateRow, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByID(ctx context.Context, id uuid.UUID) (GetByIDRow, error)
-	List(ctx context.Context) ([]ListRow, error)
+	List(ctx context.Context, arg ListParams) ([]ListRow, error)
 	ListByUnit(ctx context.Context, unitID pgtype.UUID) ([]ListByUnitRow, error)
 	SetStatus(ctx context.Context, arg SetStatusParams) (Form, error)
+	IsHeld(ctx context.Context, formID uuid.UUID) (bool, error)
 }
 
 type ResponseStore interface {
 	ListBySubmittedBy(ctx context.Context, submittedBy uuid.UUID) ([]response.FormResponse, error)
 }
 
+const (
+	SortUpdatedAt     = "updatedAt"
+	SortTitle         = "title"
+	SortResponseCount = "responseCount"
+)
+
+var SortableFields = []string{SortUpdatedAt, SortTitle, SortResponseCount}
+
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// ListFilter narrows the form list to the given statuses and units, and to forms carrying
+// any of the tags, and orders it by the sort keys in turn; the zero value lists every
+// form, most recently updated first
+type ListFilter struct {
+	Statuses []Status
+	UnitIDs  []uuid.UUID
+	TagIDs   []uuid.UUID
+	Sort     []SortKey
+}
+
 type UserFormStatus string
 
 const (
@@ -59,6 +87,13 @@ func NewService(logger *zap.Logger, db DBTX, responseStore ResponseStore) *Servi
 	}
 }
 
+func toTimeLimit(seconds *int32) pgtype.Int4 {
+	if seconds == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *seconds, Valid: true}
+}
+
 func (s *Service) Create(ctx context.Context, req Request, unitID uuid.UUID, userID uuid.UUID) (CreateRow, error) {
 	ctx, span := s.tracer.Start(ctx, "Create")
 	defer span.End()
@@ -72,12 +107,15 @@ func (s *Service) Create(ctx context.Context, req Request, unitID uuid.UUID, use
 	}
 
 	newForm, err := s.queries.Create(ctx, CreateParams{
-		Title:          req.Title,
-		Description:    pgtype.Text{String: req.Description, Valid: true},
-		PreviewMessage: pgtype.Text{String: req.PreviewMessage, Valid: req.PreviewMessage != ""},
-		UnitID:         pgtype.UUID{Bytes: unitID, Valid: true},
-		LastEditor:     userID,
-		Deadline:       deadline,
+		Title:              req.Title,
+		Description:        pgtype.Text{String: req.Description, Valid: true},
+		PreviewMessage:     pgtype.Text{String: req.PreviewMessage, Valid: req.PreviewMessage != ""},
+		UnitID:             pgtype.UUID{Bytes: unitID, Valid: true},
+		LastEditor:         userID,
+		Deadline:           deadline,
+		RandomizeQuestions: req.RandomizeQuestions,
+		ShuffleChoices:     req.ShuffleChoices,
+		TimeLimitSeconds:   toTimeLimit(req.TimeLimitSeconds),
 	})
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "create form")
@@ -101,12 +139,15 @@ func (s *Service) Update(ctx context.Context, id uuid.UUID, request Request, use
 	}
 
 	updatedForm, err := s.queries.Update(ctx, UpdateParams{
-		ID:             id,
-		Title:          request.Title,
-		Description:    pgtype.Text{String: request.Description, Valid: true},
-		PreviewMessage: pgtype.Text{String: request.PreviewMessage, Valid: request.PreviewMessage != ""},
-		LastEditor:     userID,
-		Deadline:       deadline,
+		ID:                 id,
+		Title:              request.Title,
+		Description:        pgtype.Text{String: request.Description, Valid: true},
+		PreviewMessage:     pgtype.Text{String: request.PreviewMessage, Valid: request.PreviewMessage != ""},
+		LastEditor:         userID,
+		Deadline:           deadline,
+		RandomizeQuestions: request.RandomizeQuestions,
+		ShuffleChoices:     request.ShuffleChoices,
+		TimeLimitSeconds:   toTimeLimit(request.TimeLimitSeconds),
 	})
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "update form")
@@ -122,7 +163,20 @@ func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
 	defer span.End()
 	logger := logutil.WithContext(ctx, s.logger)
 
-	err := s.queries.Delete(ctx, id)
+	held, err := s.queries.IsHeld(ctx, id)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "legal_holds", "form_id", id.String(), logger, "check legal hold")
+		span.RecordError(err)
+		return err
+	}
+	if held {
+		logger.Info("Refused to delete held form", zap.String("form_id", id.String()))
+		err = internal.ErrLegalHold
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.queries.Delete(ctx, id)
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "delete form")
 		span.RecordError(err)
@@ -147,21 +201,65 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (GetByIDRow, error)
 	return currentForm, nil
 }
 
-func (s *Service) List(ctx context.Context) ([]ListRow, error) {
+func (s *Service) List(ctx context.Context, filter ListFilter) ([]ListRow, error) {
 	ctx, span := s.tracer.Start(ctx, "ListForms")
 	defer span.End()
 	logger := logutil.WithContext(ctx, s.logger)
 
-	forms, err := s.queries.List(ctx)
+	statuses := make([]string, len(filter.Statuses))
+	for i, status := range filter.Statuses {
+		statuses[i] = string(status)
+	}
+	unitIDs := filter.UnitIDs
+	if unitIDs == nil {
+		unitIDs = []uuid.UUID{}
+	}
+	tagIDs := filter.TagIDs
+	if tagIDs == nil {
+		tagIDs = []uuid.UUID{}
+	}
+
+	forms, err := s.queries.List(ctx, ListParams{
+		Statuses: statuses,
+		UnitIds:  unitIDs,
+		TagIds:   tagIDs,
+	})
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "list forms")
 		span.RecordError(err)
 		return []ListRow{}, err
 	}
 
+	if len(filter.Sort) > 0 {
+		slices.SortStableFunc(forms, func(a, b ListRow) int {
+			return compareListRows(a, b, filter.Sort)
+		})
+	}
+
 	return forms, nil
 }
 
+func compareListRows(a, b ListRow, keys []SortKey) int {
+	for _, key := range keys {
+		var result int
+		switch key.Field {
+		case SortUpdatedAt:
+			result = a.UpdatedAt.Time.Compare(b.UpdatedAt.Time)
+		case SortTitle:
+			result = cmp.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		case SortResponseCount:
+			result = cmp.Compare(a.ResponseCount, b.ResponseCount)
+		}
+		if key.Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
 func (s *Service) ListByUnit(ctx context.Context, unitID uuid.UUID) ([]ListByUnitRow, error) {
 	ctx, span := s.tracer.Start(ctx, "ListByUnit")
 	defer span.End()
@@ -272,3 +370,15 @@ func (s *Service) ListFormsOfUser(ctx context.Context, unitIDs []uuid.UUID, user
 
 	return userForms, nil
 }
+
+// CheckOpen refuses responses to a form that is not published, or whose deadline has
+// passed at now, as respondents are refused before answering the form
+func CheckOpen(status Status, deadline pgtype.Timestamptz, now time.Time) error {
+	if status != StatusPublished {
+		return internal.ErrFormNotPublished
+	}
+	if deadline.Valid && deadline.Time.Before(now) {
+		return internal.ErrFormDeadlinePassed
+	}
+	return nil
+}
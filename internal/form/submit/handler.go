@@ -80,7 +80,7 @@ func (h *Handler) SubmitHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var request Request
-	err = handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &request)
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &request)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -99,6 +99,12 @@ func (h *Handler) SubmitHandler(w http.ResponseWriter, r *http.Request) {
 
 	newResponse, errs := h.operator.Submit(traceCtx, formID, currentUser.ID, answerParams)
 	if errs != nil {
+		// Ineligible users get a forbidden response instead of a validation failure
+		if len(errs) == 1 && errors.Is(errs[0], internal.ErrFormNotEligible) {
+			h.problemWriter.WriteError(traceCtx, w, errs[0], logger)
+			return
+		}
+
 		// Convert errors to strings and join them for better error handling
 		errorStrings := make([]string, len(errs))
 		for i, err := range errs {
@@ -0,0 +1,11 @@
+package submit
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the submission of a response, by its owner or by a respondent
+func Routes(r route.Router, h *Handler) {
+	r.Handle("POST /responses/{id}/submit", route.Authenticated, route.PermissionNone, h.SubmitHandler)
+	r.Handle("POST /forms/{formId}/submit", route.Respondent, route.PermissionNone, h.SubmitHandler)
+}
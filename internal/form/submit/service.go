@@ -3,11 +3,13 @@ package submit
 import (
 	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/eligibility"
 	"NYCU-SDC/core-system-backend/internal/form/question"
 	"NYCU-SDC/core-system-backend/internal/form/response"
 	"NYCU-SDC/core-system-backend/internal/form/shared"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -26,37 +28,89 @@ type FormStore interface {
 }
 
 type FormResponseStore interface {
-	CreateOrUpdate(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam, questionType []response.QuestionType) (response.FormResponse, error)
+	CreateOrUpdate(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam, questionType []response.QuestionType, isTest bool) (response.FormResponse, error)
+}
+
+type EligibilityStore interface {
+	Check(ctx context.Context, formID uuid.UUID, userID uuid.UUID) (eligibility.Result, error)
+}
+
+type ApprovalStore interface {
+	Request(ctx context.Context, formID uuid.UUID, responseID uuid.UUID, userID uuid.UUID) error
+}
+
+type ActionStore interface {
+	Run(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
+}
+
+type AttemptStore interface {
+	Check(ctx context.Context, formID uuid.UUID, userID uuid.UUID, now time.Time) error
+}
+
+type AssignmentStore interface {
+	Assign(ctx context.Context, formID uuid.UUID, responseID uuid.UUID) error
+}
+
+type BallotStore interface {
+	IsBallot(ctx context.Context, formID uuid.UUID) (bool, error)
+}
+
+// EventStore refuses registrations to an event that has no place left
+type EventStore interface {
+	CheckCapacity(ctx context.Context, formID uuid.UUID, userID uuid.UUID) error
 }
 
 type Service struct {
 	logger *zap.Logger
 	tracer trace.Tracer
 
-	formStore     FormStore
-	questionStore QuestionStore
-	responseStore FormResponseStore
+	formStore        FormStore
+	questionStore    QuestionStore
+	responseStore    FormResponseStore
+	eligibilityStore EligibilityStore
+	approvalStore    ApprovalStore
+	actionStore      ActionStore
+	attemptStore     AttemptStore
+	assignmentStore  AssignmentStore
+	ballotStore      BallotStore
+	eventStore       EventStore
 }
 
-func NewService(logger *zap.Logger, formStore FormStore, questionStore QuestionStore, formResponseStore FormResponseStore) *Service {
+func NewService(logger *zap.Logger, formStore FormStore, questionStore QuestionStore, formResponseStore FormResponseStore, eligibilityStore EligibilityStore, approvalStore ApprovalStore, actionStore ActionStore, attemptStore AttemptStore, assignmentStore AssignmentStore, ballotStore BallotStore, eventStore EventStore) *Service {
 	return &Service{
-		logger:        logger,
-		tracer:        otel.Tracer("submit/service"),
-		formStore:     formStore,
-		questionStore: questionStore,
-		responseStore: formResponseStore,
+		logger:           logger,
+		tracer:           otel.Tracer("submit/service"),
+		formStore:        formStore,
+		questionStore:    questionStore,
+		responseStore:    formResponseStore,
+		eligibilityStore: eligibilityStore,
+		approvalStore:    approvalStore,
+		actionStore:      actionStore,
+		attemptStore:     attemptStore,
+		assignmentStore:  assignmentStore,
+		ballotStore:      ballotStore,
+		eventStore:       eventStore,
 	}
 }
 
 // Submit handles a user's submission for a specific form.
 // It performs the following steps:
-// 1. Retrieves all questions associated with the form.
-// 2. Validates the submitted answers against the corresponding questions.
-//   - If any validation fails or if an answer references a nonexistent question, it accumulates the errors.
-//   - Validates that all required questions have been answered.
+//  1. Checks the form deadline, ballot mode, the time limit of a timed form, the user's eligibility for the form
+//     and the places left in the event the form registers for.
+//  2. Retrieves all questions associated with the form.
+//  3. Validates the submitted answers against the corresponding questions.
+//     - If any validation fails or if an answer references a nonexistent question, it accumulates the errors.
+//     - Validates that all required questions have been answered.
 //
-// 3. If there are validation errors, returns them without saving.
-// 4. If validation passes, creates or updates the response record using the answer values and question types.
+// 4. If there are validation errors, returns them without saving.
+// 5. If validation passes, creates or updates the response record using the answer values and question types.
+// 6. Dispatches the workflow action nodes on the respondent's path.
+// 7. Requests approval if the workflow stops the response at an approval gate.
+// 8. Assigns the response to a reviewer if the form has assignment configured.
+//
+// A submission made with a preview token (see internal.IsPreview) skips the deadline,
+// time limit, eligibility and capacity checks, is saved as a test response and triggers no actions,
+// approval requests or assignment, so trying out a form has no effect outside the test data.
 //
 // Returns the saved form response if successful, or a list of validation/database errors otherwise.
 func (s *Service) Submit(ctx context.Context, formID uuid.UUID, userID uuid.UUID, answers []shared.AnswerParam) (response.FormResponse, []error) {
@@ -70,9 +124,47 @@ func (s *Service) Submit(ctx context.Context, formID uuid.UUID, userID uuid.UUID
 		return response.FormResponse{}, []error{err}
 	}
 
-	// Validate form deadline
-	if formDetails.Deadline.Valid && formDetails.Deadline.Time.Before(time.Now()) {
-		return response.FormResponse{}, []error{internal.ErrFormDeadlinePassed}
+	preview := internal.IsPreview(traceCtx)
+
+	if !preview {
+		// Validate form deadline
+		if formDetails.Deadline.Valid && formDetails.Deadline.Time.Before(time.Now()) {
+			return response.FormResponse{}, []error{internal.ErrFormDeadlinePassed}
+		}
+
+		// Ballots are cast anonymously, never submitted as a response tied to the voter
+		isBallot, err := s.ballotStore.IsBallot(traceCtx, formID)
+		if err != nil {
+			return response.FormResponse{}, []error{err}
+		}
+		if isBallot {
+			return response.FormResponse{}, []error{internal.ErrBallotMode}
+		}
+
+		// Validate the time limit, for forms that have one
+		err = s.attemptStore.Check(traceCtx, formID, userID, time.Now())
+		if err != nil {
+			return response.FormResponse{}, []error{err}
+		}
+
+		// Check eligibility rules before validating any answers
+		eligibilityResult, err := s.eligibilityStore.Check(traceCtx, formID, userID)
+		if err != nil {
+			return response.FormResponse{}, []error{err}
+		}
+		if !eligibilityResult.Eligible {
+			messages := make([]string, 0, len(eligibilityResult.Reasons))
+			for _, reason := range eligibilityResult.Reasons {
+				messages = append(messages, reason.Message)
+			}
+			return response.FormResponse{}, []error{fmt.Errorf("%w: %s", internal.ErrFormNotEligible, strings.Join(messages, "; "))}
+		}
+
+		// Registrations to a full event are turned away
+		err = s.eventStore.CheckCapacity(traceCtx, formID, userID)
+		if err != nil {
+			return response.FormResponse{}, []error{err}
+		}
 	}
 
 	list, err := s.questionStore.ListByFormID(traceCtx, formID)
@@ -80,35 +172,33 @@ func (s *Service) Submit(ctx context.Context, formID uuid.UUID, userID uuid.UUID
 		return response.FormResponse{}, []error{err}
 	}
 
-	// Validate answers against questions
-	var questionTypes []response.QuestionType
+	// Validate answers against questions, looked up by ID from the single question list
+	questions := make(map[string]question.Answerable)
+	for _, section := range list {
+		for _, q := range section.Questions {
+			questions[q.Question().ID.String()] = q
+		}
+	}
+
+	questionTypes := make([]response.QuestionType, 0, len(answers))
 	validationErrors := make([]error, 0)
-	answeredQuestionIDs := make(map[string]bool)
+	answeredQuestionIDs := make(map[string]bool, len(answers))
 
 	for _, ans := range answers {
-		var found bool
-		for _, section := range list {
-			for _, q := range section.Questions {
-				if q.Question().ID.String() == ans.QuestionID {
-					found = true
-					answeredQuestionIDs[ans.QuestionID] = true
-
-					// Validate answer value
-					err := q.Validate(ans.Value)
-					if err != nil {
-						validationErrors = append(validationErrors, fmt.Errorf("validation error for question ID %s: %w", ans.QuestionID, err))
-					}
-
-					questionTypes = append(questionTypes, response.QuestionType(q.Question().Type))
-
-					break
-				}
-			}
-		}
-
+		q, found := questions[ans.QuestionID]
 		if !found {
 			validationErrors = append(validationErrors, fmt.Errorf("question with ID %s not found in form %s", ans.QuestionID, formID))
+			continue
+		}
+		answeredQuestionIDs[ans.QuestionID] = true
+
+		// Validate answer value
+		err := q.Validate(ans.Value)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("validation error for question ID %s: %w", ans.QuestionID, err))
 		}
+
+		questionTypes = append(questionTypes, response.QuestionType(q.Question().Type))
 	}
 
 	// Check for required questions that were not answered
@@ -127,12 +217,37 @@ func (s *Service) Submit(ctx context.Context, formID uuid.UUID, userID uuid.UUID
 		return response.FormResponse{}, validationErrors
 	}
 
-	result, err := s.responseStore.CreateOrUpdate(traceCtx, formID, userID, answers, questionTypes)
+	result, err := s.responseStore.CreateOrUpdate(traceCtx, formID, userID, answers, questionTypes, preview)
 	if err != nil {
 		logger.Error("failed to create or update form response", zap.Error(err), zap.String("formID", formID.String()), zap.String("userID", userID.String()))
 		span.RecordError(err)
 		return response.FormResponse{}, []error{err}
 	}
 
+	if preview {
+		return result, nil
+	}
+
+	err = s.actionStore.Run(traceCtx, formID, userID)
+	if err != nil {
+		logger.Error("failed to run workflow actions for form response", zap.Error(err), zap.String("formID", formID.String()), zap.String("responseID", result.ID.String()))
+		span.RecordError(err)
+		return response.FormResponse{}, []error{err}
+	}
+
+	err = s.approvalStore.Request(traceCtx, formID, result.ID, userID)
+	if err != nil {
+		logger.Error("failed to request approval for form response", zap.Error(err), zap.String("formID", formID.String()), zap.String("responseID", result.ID.String()))
+		span.RecordError(err)
+		return response.FormResponse{}, []error{err}
+	}
+
+	// The response is saved by now; one left unassigned can still be distributed later
+	err = s.assignmentStore.Assign(traceCtx, formID, result.ID)
+	if err != nil {
+		logger.Error("failed to assign form response to a reviewer", zap.Error(err), zap.String("formID", formID.String()), zap.String("responseID", result.ID.String()))
+		span.RecordError(err)
+	}
+
 	return result, nil
 }
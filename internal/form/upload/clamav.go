@@ -0,0 +1,98 @@
+package upload
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const clamAVChunkSize = 64 << 10
+
+// Scanner inspects a file for malware. Infected files return a non-empty signature.
+type Scanner interface {
+	Scan(ctx context.Context, file io.Reader) (infected bool, signature string, err error)
+}
+
+// ClamAV scans files with a clamd daemon over TCP using the INSTREAM command
+type ClamAV struct {
+	address string
+	timeout time.Duration
+}
+
+func NewClamAV(address string, timeout time.Duration) *ClamAV {
+	return &ClamAV{
+		address: address,
+		timeout: timeout,
+	}
+}
+
+func (c *ClamAV) Scan(ctx context.Context, file io.Reader) (bool, string, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+
+	_, err = conn.Write([]byte("zINSTREAM\x00"))
+	if err != nil {
+		return false, "", fmt.Errorf("failed to start clamd stream: %w", err)
+	}
+
+	// Each chunk is prefixed by its length; a zero length ends the stream
+	chunk := make([]byte, clamAVChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, readErr := file.Read(chunk)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			_, err = conn.Write(size)
+			if err == nil {
+				_, err = conn.Write(chunk[:n])
+			}
+			if err != nil {
+				return false, "", fmt.Errorf("failed to stream file to clamd: %w", err)
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return false, "", fmt.Errorf("failed to read file: %w", readErr)
+		}
+	}
+
+	binary.BigEndian.PutUint32(size, 0)
+	_, err = conn.Write(size)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to end clamd stream: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, "", fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+
+	return parseClamAVReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamAVReply reads replies such as "stream: OK" and "stream: Eicar-Signature FOUND"
+func parseClamAVReply(reply string) (bool, string, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return false, "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return true, strings.TrimSuffix(result, " FOUND"), nil
+	default:
+		return false, "", fmt.Errorf("unexpected clamd reply: %s", reply)
+	}
+}
@@ -0,0 +1,181 @@
+package upload
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/imaging"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// multipartMemory is how much of a request is kept in memory before spilling to disk
+	multipartMemory = 32 << 20
+
+	// multipartOverhead leaves room for the multipart boundaries and headers
+	multipartOverhead = 1 << 20
+)
+
+type Store interface {
+	Upload(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, userID uuid.UUID, file File) (FormUpload, error)
+	Get(ctx context.Context, id uuid.UUID, userID uuid.UUID, size imaging.Size) (FormUpload, string, error)
+}
+
+type Response struct {
+	ID          string     `json:"id"`
+	FormID      string     `json:"formId"`
+	QuestionID  string     `json:"questionId"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`
+	Status      string     `json:"status"`
+	ScannedAt   *time.Time `json:"scannedAt,omitempty"`
+	HasVariants bool       `json:"hasVariants"`
+	DownloadURL string     `json:"downloadUrl,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func ToResponse(upload FormUpload, downloadURL string) Response {
+	response := Response{
+		ID:          upload.ID.String(),
+		FormID:      upload.FormID.String(),
+		QuestionID:  upload.QuestionID.String(),
+		Filename:    upload.Filename,
+		ContentType: upload.ContentType,
+		Size:        upload.Size,
+		Status:      strings.ToUpper(string(upload.Status)),
+		HasVariants: upload.HasVariants,
+		DownloadURL: downloadURL,
+		CreatedAt:   upload.CreatedAt.Time,
+	}
+	if upload.ScannedAt.Valid {
+		response.ScannedAt = &upload.ScannedAt.Time
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("upload/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) UploadHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UploadHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := internal.ParseUUID(r.PathValue("formId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	questionID, err := internal.ParseUUID(r.PathValue("questionId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, question.FileSizeLimit1GB.Bytes()+multipartOverhead)
+	err = r.ParseMultipartForm(multipartMemory)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %w", internal.ErrInvalidUpload, err), logger)
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %w", internal.ErrInvalidUpload, err), logger)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	upload, err := h.store.Upload(traceCtx, formID, questionID, currentUser.ID, File{
+		Name:        header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		Body:        file,
+	})
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(upload, ""))
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	var size imaging.Size
+	if sizeParam := r.URL.Query().Get("size"); sizeParam != "" {
+		var ok bool
+		size, ok = imaging.ParseSize(sizeParam)
+		if !ok {
+			h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %s", internal.ErrInvalidImageSize, sizeParam), logger)
+			return
+		}
+	}
+
+	upload, downloadURL, err := h.store.Get(traceCtx, id, currentUser.ID, size)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(upload, downloadURL))
+}
@@ -0,0 +1,40 @@
+-- name: Create :one
+INSERT INTO form_uploads (form_id, question_id, uploaded_by, filename, content_type, size)
+VALUES (@form_id, @question_id, @uploaded_by, @filename, @content_type, @size)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM form_uploads
+WHERE id = @id;
+
+-- name: ListPending :many
+SELECT * FROM form_uploads
+WHERE status = 'pending'
+ORDER BY created_at ASC
+LIMIT @max_count;
+
+-- name: MarkScanned :one
+UPDATE form_uploads
+SET status = @status,
+    scan_result = @scan_result,
+    size = @size,
+    has_variants = @has_variants,
+    scanned_at = now(),
+    updated_at = now()
+WHERE id = @id AND status = 'pending'
+RETURNING *;
+
+-- name: GetFormUnitID :one
+SELECT unit_id FROM forms
+WHERE id = @form_id;
+
+-- name: IsUnitMember :one
+SELECT EXISTS(SELECT 1 FROM unit_members WHERE unit_id = @unit_id AND member_id = @member_id);
+
+-- name: ListUnitMemberIDs :many
+SELECT member_id FROM unit_members
+WHERE unit_id = @unit_id;
+
+-- name: CountPending :one
+SELECT count(*) FROM form_uploads
+WHERE status = 'pending';
\ No newline at end of file
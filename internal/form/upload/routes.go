@@ -0,0 +1,11 @@
+package upload
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the file uploads answering a question
+func Routes(r route.Router, h *Handler, limits route.BodyLimits) {
+	r.Handle("POST /forms/{formId}/questions/{questionId}/uploads", route.Authenticated, route.PermissionNone, h.UploadHandler).WithBodyLimit(limits.Upload)
+	r.Handle("GET /uploads/{id}", route.Authenticated, route.PermissionSelf, h.GetHandler)
+}
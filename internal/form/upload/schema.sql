@@ -0,0 +1,23 @@
+CREATE TYPE upload_status AS ENUM(
+    'pending',
+    'clean',
+    'infected'
+);
+
+CREATE TABLE IF NOT EXISTS form_uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    form_id UUID NOT NULL REFERENCES forms(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    status upload_status NOT NULL DEFAULT 'pending',
+    scan_result TEXT DEFAULT NULL,
+    scanned_at TIMESTAMPTZ DEFAULT NULL,
+    has_variants BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_form_uploads_pending ON form_uploads(created_at) WHERE status = 'pending';
\ No newline at end of file
@@ -0,0 +1,441 @@
+package upload
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/imaging"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultScanInterval is how often quarantined uploads are picked up for scanning
+	DefaultScanInterval = 10 * time.Second
+
+	scanBatchSize = 20
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (FormUpload, error)
+	GetByID(ctx context.Context, id uuid.UUID) (FormUpload, error)
+	ListPending(ctx context.Context, maxCount int32) ([]FormUpload, error)
+	MarkScanned(ctx context.Context, arg MarkScannedParams) (FormUpload, error)
+	GetFormUnitID(ctx context.Context, formID uuid.UUID) (pgtype.UUID, error)
+	IsUnitMember(ctx context.Context, arg IsUnitMemberParams) (bool, error)
+	ListUnitMemberIDs(ctx context.Context, unitID uuid.UUID) ([]uuid.UUID, error)
+	CountPending(ctx context.Context) (int64, error)
+}
+
+type QuestionStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (question.Answerable, error)
+}
+
+type FileStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	PresignGet(ctx context.Context, key string) (string, error)
+}
+
+type InboxStore interface {
+	Create(ctx context.Context, contentType inbox.ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error)
+}
+
+// File is an uploaded file as received from the client
+type File struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
+
+type Service struct {
+	logger        *zap.Logger
+	queries       Querier
+	tracer        trace.Tracer
+	questionStore QuestionStore
+	fileStore     FileStore
+	inboxStore    InboxStore
+	scanner       Scanner
+}
+
+// NewService creates the upload service. A nil scanner disables virus scanning, and
+// uploads are released as soon as they are stored.
+func NewService(logger *zap.Logger, db DBTX, questionStore QuestionStore, fileStore FileStore, inboxStore InboxStore, scanner Scanner) *Service {
+	return &Service{
+		logger:        logger,
+		queries:       New(db),
+		tracer:        otel.Tracer("upload/service"),
+		questionStore: questionStore,
+		fileStore:     fileStore,
+		inboxStore:    inboxStore,
+		scanner:       scanner,
+	}
+}
+
+func quarantineKey(id uuid.UUID) string {
+	return "quarantine/" + id.String()
+}
+
+func releasedKey(id uuid.UUID) string {
+	return "uploads/" + id.String()
+}
+
+func variantKey(id uuid.UUID, size imaging.Size) string {
+	return "uploads/" + id.String() + "_" + string(size)
+}
+
+// Upload stores a file for an upload question of a form. The file is checked against
+// the allowed file types and size limit of the question, and stays in quarantine until
+// it is scanned.
+func (s *Service) Upload(ctx context.Context, formID uuid.UUID, questionID uuid.UUID, userID uuid.UUID, file File) (FormUpload, error) {
+	ctx, span := s.tracer.Start(ctx, "Upload")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	answerable, err := s.questionStore.GetByID(ctx, questionID)
+	if err != nil {
+		span.RecordError(err)
+		return FormUpload{}, err
+	}
+
+	uploadQuestion, ok := answerable.(question.UploadFile)
+	if !ok || uploadQuestion.FormID() != formID {
+		err = fmt.Errorf("%w: question %s does not accept file uploads", internal.ErrInvalidUpload, questionID)
+		span.RecordError(err)
+		return FormUpload{}, err
+	}
+	if !uploadQuestion.Allows(file.Name) {
+		err = fmt.Errorf("%w: file type of '%s' is not allowed", internal.ErrInvalidUpload, file.Name)
+		span.RecordError(err)
+		return FormUpload{}, err
+	}
+	if file.Size > uploadQuestion.MaxFileSizeLimit.Bytes() {
+		err = fmt.Errorf("%w: file exceeds the %s size limit", internal.ErrInvalidUpload, uploadQuestion.MaxFileSizeLimit)
+		span.RecordError(err)
+		return FormUpload{}, err
+	}
+
+	upload, err := s.queries.Create(ctx, CreateParams{
+		FormID:      formID,
+		QuestionID:  questionID,
+		UploadedBy:  pgtype.UUID{Bytes: userID, Valid: true},
+		Filename:    file.Name,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_uploads", "question_id", questionID.String(), logger, "create upload")
+		span.RecordError(err)
+		return FormUpload{}, err
+	}
+
+	err = s.fileStore.Put(ctx, quarantineKey(upload.ID), file.Body, file.Size, file.ContentType)
+	if err != nil {
+		span.RecordError(err)
+		return FormUpload{}, err
+	}
+
+	if s.scanner == nil {
+		upload, err = s.release(ctx, upload, "")
+		if err != nil {
+			span.RecordError(err)
+			return FormUpload{}, err
+		}
+	}
+
+	return upload, nil
+}
+
+// Get returns an upload with a presigned download URL once it passed the scan. A size
+// selects a resized variant of an image upload; the original is returned when it is
+// empty. Only the uploader and the members of the unit owning the form can access it.
+func (s *Service) Get(ctx context.Context, id uuid.UUID, userID uuid.UUID, size imaging.Size) (FormUpload, string, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	upload, err := s.queries.GetByID(ctx, id)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_uploads", "id", id.String(), logger, "get upload by id")
+		span.RecordError(err)
+		return FormUpload{}, "", err
+	}
+
+	isUploader := upload.UploadedBy.Valid && upload.UploadedBy.Bytes == userID
+	if !isUploader {
+		unitID, err := s.queries.GetFormUnitID(ctx, upload.FormID)
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", upload.FormID.String(), logger, "get form unit id")
+			span.RecordError(err)
+			return FormUpload{}, "", err
+		}
+
+		isMember := false
+		if unitID.Valid {
+			isMember, err = s.queries.IsUnitMember(ctx, IsUnitMemberParams{UnitID: unitID.Bytes, MemberID: userID})
+			if err != nil {
+				err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", uuid.UUID(unitID.Bytes).String(), logger, "check form unit membership")
+				span.RecordError(err)
+				return FormUpload{}, "", err
+			}
+		}
+		if !isMember {
+			err = fmt.Errorf("%w: user cannot access upload %s", internal.ErrPermissionDenied, id)
+			span.RecordError(err)
+			return FormUpload{}, "", err
+		}
+	}
+
+	if size != "" && !upload.HasVariants {
+		err = fmt.Errorf("%w: upload %s has no image variants", internal.ErrInvalidImageSize, id)
+		span.RecordError(err)
+		return FormUpload{}, "", err
+	}
+
+	if upload.Status != UploadStatusClean {
+		return upload, "", nil
+	}
+
+	key := releasedKey(upload.ID)
+	if size != "" {
+		key = variantKey(upload.ID, size)
+	}
+
+	downloadURL, err := s.fileStore.PresignGet(ctx, key)
+	if err != nil {
+		span.RecordError(err)
+		return FormUpload{}, "", err
+	}
+
+	return upload, downloadURL, nil
+}
+
+// Start scans the quarantined uploads every interval until the context is done
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	if s.scanner == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.ScanPending(ctx)
+			if err != nil {
+				s.logger.Error("Failed to scan pending uploads", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ScanPending scans the oldest quarantined uploads. Clean files are released; infected
+// files are deleted and the members of the unit owning the form are notified. Uploads
+// that could not be scanned stay in quarantine and are retried on the next run.
+func (s *Service) ScanPending(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "ScanPending")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	pending, err := s.queries.ListPending(ctx, scanBatchSize)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list pending uploads")
+		span.RecordError(err)
+		return err
+	}
+
+	for _, upload := range pending {
+		infected, signature, scanErr := s.scan(ctx, upload)
+		if scanErr != nil {
+			logger.Warn("Failed to scan upload", zap.Error(scanErr), zap.String("upload_id", upload.ID.String()))
+			continue
+		}
+
+		if infected {
+			err = s.reject(ctx, upload, signature)
+		} else {
+			_, err = s.release(ctx, upload, "")
+		}
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) scan(ctx context.Context, upload FormUpload) (bool, string, error) {
+	file, err := s.fileStore.Get(ctx, quarantineKey(upload.ID))
+	if err != nil {
+		return false, "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	return s.scanner.Scan(ctx, file)
+}
+
+// release moves a clean upload out of quarantine. Images are re-encoded without their
+// EXIF metadata and get a resized variant for each imaging size.
+func (s *Service) release(ctx context.Context, upload FormUpload, scanResult string) (FormUpload, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	size, hasVariants, err := s.store(ctx, upload)
+	if err != nil {
+		return FormUpload{}, err
+	}
+
+	err = s.fileStore.Delete(ctx, quarantineKey(upload.ID))
+	if err != nil {
+		return FormUpload{}, err
+	}
+
+	released, err := s.queries.MarkScanned(ctx, MarkScannedParams{
+		Status:      UploadStatusClean,
+		ScanResult:  pgtype.Text{String: scanResult, Valid: scanResult != ""},
+		Size:        size,
+		HasVariants: hasVariants,
+		ID:          upload.ID,
+	})
+	if err != nil {
+		return FormUpload{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_uploads", "id", upload.ID.String(), logger, "mark upload clean")
+	}
+
+	return released, nil
+}
+
+// store copies the quarantined file to its released key, returning the stored size
+// and whether image variants were generated
+func (s *Service) store(ctx context.Context, upload FormUpload) (int64, bool, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	if !imaging.Supported(upload.ContentType) || upload.Size > imaging.MaxFileSize {
+		return upload.Size, false, s.copyQuarantined(ctx, upload)
+	}
+
+	img, err := s.decodeQuarantined(ctx, upload)
+	if err != nil {
+		// Not a valid image despite its content type; keep the file as it was uploaded
+		logger.Warn("Failed to decode uploaded image, storing it unprocessed", zap.Error(err), zap.String("upload_id", upload.ID.String()))
+		return upload.Size, false, s.copyQuarantined(ctx, upload)
+	}
+
+	size, err := s.putImage(ctx, releasedKey(upload.ID), img)
+	if err != nil {
+		return 0, false, err
+	}
+
+	for _, variant := range imaging.Sizes {
+		_, err = s.putImage(ctx, variantKey(upload.ID, variant), imaging.Resize(img, variant.MaxDimension()))
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	return size, true, nil
+}
+
+func (s *Service) copyQuarantined(ctx context.Context, upload FormUpload) error {
+	file, err := s.fileStore.Get(ctx, quarantineKey(upload.ID))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	return s.fileStore.Put(ctx, releasedKey(upload.ID), file, upload.Size, upload.ContentType)
+}
+
+func (s *Service) decodeQuarantined(ctx context.Context, upload FormUpload) (imaging.Image, error) {
+	file, err := s.fileStore.Get(ctx, quarantineKey(upload.ID))
+	if err != nil {
+		return imaging.Image{}, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return imaging.Decode(file)
+}
+
+func (s *Service) putImage(ctx context.Context, key string, img imaging.Image) (int64, error) {
+	var buf bytes.Buffer
+	contentType, err := imaging.Encode(&buf, img)
+	if err != nil {
+		return 0, err
+	}
+
+	size := int64(buf.Len())
+	err = s.fileStore.Put(ctx, key, &buf, size, contentType)
+	if err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// reject deletes an infected upload and notifies the form owners through their inbox
+func (s *Service) reject(ctx context.Context, upload FormUpload, signature string) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.fileStore.Delete(ctx, quarantineKey(upload.ID))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.queries.MarkScanned(ctx, MarkScannedParams{
+		Status:     UploadStatusInfected,
+		ScanResult: pgtype.Text{String: signature, Valid: true},
+		Size:       upload.Size,
+		ID:         upload.ID,
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "form_uploads", "id", upload.ID.String(), logger, "mark upload infected")
+	}
+
+	logger.Warn("Rejected infected upload",
+		zap.String("upload_id", upload.ID.String()),
+		zap.String("form_id", upload.FormID.String()),
+		zap.String("signature", signature))
+
+	unitID, err := s.queries.GetFormUnitID(ctx, upload.FormID)
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", upload.FormID.String(), logger, "get form unit id")
+	}
+	if !unitID.Valid {
+		return nil
+	}
+
+	ownerIDs, err := s.queries.ListUnitMemberIDs(ctx, unitID.Bytes)
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", uuid.UUID(unitID.Bytes).String(), logger, "list form unit members")
+	}
+	if len(ownerIDs) == 0 {
+		return nil
+	}
+
+	_, err = s.inboxStore.Create(ctx, inbox.ContentTypeForm, upload.FormID, ownerIDs, unitID.Bytes)
+	return err
+}
+
+// PendingScans counts the uploads still in quarantine waiting for a scan
+func (s *Service) PendingScans(ctx context.Context) (int64, error) {
+	count, err := s.queries.CountPending(ctx)
+	if err != nil {
+		return 0, databaseutil.WrapDBError(err, s.logger, "count pending uploads")
+	}
+	return count, nil
+}
@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+)
+
+// DefaultActiveCacheTTL bounds how long an active workflow is served from memory.
+// Writes through the service invalidate it at once; the TTL covers activations made
+// by other instances of the backend.
+const DefaultActiveCacheTTL = 30 * time.Second
+
+// activeCache keeps the active workflow version of each form along with its runtime,
+// parsed once per version, so respondents going through a form do not re-read and
+// re-parse the workflow at every step
+type activeCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uuid.UUID]*activeEntry
+	// generation changes on every invalidation, so a version read before it is not cached
+	generation uint64
+
+	hits   metric.Int64Counter
+	misses metric.Int64Counter
+}
+
+type activeEntry struct {
+	row       GetActiveRow
+	runtime   *Runtime
+	expiresAt time.Time
+}
+
+func newActiveCache(logger *zap.Logger, ttl time.Duration) *activeCache {
+	meter := otel.Meter("internal/form/workflow")
+
+	hits, err := meter.Int64Counter("workflow.cache.hits",
+		metric.WithDescription("Active workflow lookups served from memory"),
+		metric.WithUnit("{lookup}"))
+	if err != nil {
+		logger.Warn("Failed to create workflow cache hits counter", zap.Error(err))
+		hits = noop.Int64Counter{}
+	}
+
+	misses, err := meter.Int64Counter("workflow.cache.misses",
+		metric.WithDescription("Active workflow lookups read from the database"),
+		metric.WithUnit("{lookup}"))
+	if err != nil {
+		logger.Warn("Failed to create workflow cache misses counter", zap.Error(err))
+		misses = noop.Int64Counter{}
+	}
+
+	return &activeCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]*activeEntry),
+		hits:    hits,
+		misses:  misses,
+	}
+}
+
+// get returns the cached entry of the form when it has not expired. On a miss it
+// returns the generation to hand to put along with the version read from the database.
+func (c *activeCache) get(ctx context.Context, formID uuid.UUID, now time.Time) (*activeEntry, uint64, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[formID]
+	generation := c.generation
+	c.mu.Unlock()
+
+	if !ok || now.After(entry.expiresAt) {
+		c.misses.Add(ctx, 1)
+		return nil, generation, false
+	}
+
+	c.hits.Add(ctx, 1)
+	return entry, generation, true
+}
+
+// put caches the active version read from the database, unless the cache was
+// invalidated since the read. The runtime of the entry it replaces is kept when the
+// version is the same.
+func (c *activeCache) put(formID uuid.UUID, row GetActiveRow, generation uint64, now time.Time) *activeEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &activeEntry{row: row, expiresAt: now.Add(c.ttl)}
+	previous, ok := c.entries[formID]
+	if ok && previous.row.ID == row.ID {
+		entry.runtime = previous.runtime
+	}
+	if generation == c.generation {
+		c.entries[formID] = entry
+	}
+
+	return entry
+}
+
+// runtime returns the runtime of the entry, parsing the workflow on first use
+func (c *activeCache) runtime(entry *activeEntry) (*Runtime, error) {
+	c.mu.Lock()
+	runtime := entry.runtime
+	c.mu.Unlock()
+	if runtime != nil {
+		return runtime, nil
+	}
+
+	runtime, err := NewRuntime(entry.row.Workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	entry.runtime = runtime
+	c.mu.Unlock()
+
+	return runtime, nil
+}
+
+// invalidate drops the cached version of the form, after its workflow was written
+func (c *activeCache) invalidate(formID uuid.UUID) {
+	c.mu.Lock()
+	delete(c.entries, formID)
+	c.generation++
+	c.mu.Unlock()
+}
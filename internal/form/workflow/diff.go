@@ -0,0 +1,188 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// edgeFields are the node fields that point to a successor node
+var edgeFields = []string{"next", "nextTrue", "nextFalse"}
+
+// NodeSummary identifies a node in a workflow diff
+type NodeSummary struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+}
+
+// FieldChange is a single non-edge field that differs between two versions of a node.
+// Before or After is nil when the field is absent in that version.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// NodeChange is a node present in both versions whose fields changed
+type NodeChange struct {
+	NodeSummary
+	Changes []FieldChange `json:"changes"`
+}
+
+// Edge is a connection from a node to its successor through one of its edge fields
+type Edge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Field string `json:"field"`
+}
+
+// Diff describes what changed from one workflow version to another.
+// Edge changes are reported separately from node field changes.
+type Diff struct {
+	FromVersionID string        `json:"fromVersionId"`
+	ToVersionID   string        `json:"toVersionId"`
+	AddedNodes    []NodeSummary `json:"addedNodes"`
+	RemovedNodes  []NodeSummary `json:"removedNodes"`
+	ChangedNodes  []NodeChange  `json:"changedNodes"`
+	AddedEdges    []Edge        `json:"addedEdges"`
+	RemovedEdges  []Edge        `json:"removedEdges"`
+}
+
+// DiffWorkflows compares two workflow JSON documents by node ID. Added nodes follow
+// their order in the new version, removed nodes their order in the old one.
+func DiffWorkflows(from []byte, to []byte) (Diff, error) {
+	fromNodes, err := parseDiffNodes(from)
+	if err != nil {
+		return Diff{}, fmt.Errorf("invalid source workflow: %w", err)
+	}
+	toNodes, err := parseDiffNodes(to)
+	if err != nil {
+		return Diff{}, fmt.Errorf("invalid target workflow: %w", err)
+	}
+
+	fromByID := indexNodes(fromNodes)
+	toByID := indexNodes(toNodes)
+
+	diff := Diff{
+		AddedNodes:   []NodeSummary{},
+		RemovedNodes: []NodeSummary{},
+		ChangedNodes: []NodeChange{},
+		AddedEdges:   []Edge{},
+		RemovedEdges: []Edge{},
+	}
+
+	for _, n := range toNodes {
+		nodeID, _ := n["id"].(string)
+		previous, ok := fromByID[nodeID]
+		if !ok {
+			diff.AddedNodes = append(diff.AddedNodes, summarize(n))
+			continue
+		}
+
+		changes := diffFields(previous, n)
+		if len(changes) > 0 {
+			diff.ChangedNodes = append(diff.ChangedNodes, NodeChange{NodeSummary: summarize(n), Changes: changes})
+		}
+	}
+
+	for _, n := range fromNodes {
+		nodeID, _ := n["id"].(string)
+		if _, ok := toByID[nodeID]; !ok {
+			diff.RemovedNodes = append(diff.RemovedNodes, summarize(n))
+		}
+	}
+
+	fromEdges := collectEdges(fromNodes)
+	toEdges := collectEdges(toNodes)
+	diff.AddedEdges = subtractEdges(toEdges, fromEdges)
+	diff.RemovedEdges = subtractEdges(fromEdges, toEdges)
+
+	return diff, nil
+}
+
+func parseDiffNodes(workflow []byte) ([]map[string]interface{}, error) {
+	var nodes []map[string]interface{}
+	err := json.Unmarshal(workflow, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON format: %w", err)
+	}
+	return nodes, nil
+}
+
+func indexNodes(nodes []map[string]interface{}) map[string]map[string]interface{} {
+	byID := make(map[string]map[string]interface{}, len(nodes))
+	for _, n := range nodes {
+		nodeID, _ := n["id"].(string)
+		byID[nodeID] = n
+	}
+	return byID
+}
+
+func summarize(n map[string]interface{}) NodeSummary {
+	summary := NodeSummary{}
+	summary.ID, _ = n["id"].(string)
+	summary.Type, _ = n["type"].(string)
+	summary.Label, _ = n["label"].(string)
+	return summary
+}
+
+// diffFields compares every field except the ID and edge fields, in alphabetical order
+func diffFields(before map[string]interface{}, after map[string]interface{}) []FieldChange {
+	fields := make(map[string]bool, len(before)+len(after))
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+	delete(fields, "id")
+	for _, field := range edgeFields {
+		delete(fields, field)
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	var changes []FieldChange
+	for _, field := range names {
+		if !reflect.DeepEqual(before[field], after[field]) {
+			changes = append(changes, FieldChange{Field: field, Before: before[field], After: after[field]})
+		}
+	}
+	return changes
+}
+
+func collectEdges(nodes []map[string]interface{}) []Edge {
+	var edges []Edge
+	for _, n := range nodes {
+		nodeID, _ := n["id"].(string)
+		for _, field := range edgeFields {
+			target, ok := n[field].(string)
+			if ok && target != "" {
+				edges = append(edges, Edge{From: nodeID, To: target, Field: field})
+			}
+		}
+	}
+	return edges
+}
+
+// subtractEdges returns the edges of a that are not in b, keeping the order of a
+func subtractEdges(a []Edge, b []Edge) []Edge {
+	existing := make(map[Edge]bool, len(b))
+	for _, edge := range b {
+		existing[edge] = true
+	}
+
+	result := []Edge{}
+	for _, edge := range a {
+		if !existing[edge] {
+			result = append(result, edge)
+		}
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+package workflow_test
+
+import (
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffWorkflows(t *testing.T) {
+	t.Parallel()
+
+	startID := uuid.New().String()
+	sectionID := uuid.New().String()
+	newSectionID := uuid.New().String()
+	endID := uuid.New().String()
+
+	from := createWorkflowJSON(t, []map[string]interface{}{
+		{"id": startID, "type": "start", "label": "Start", "next": sectionID},
+		{"id": sectionID, "type": "section", "label": "Basic Info", "next": endID},
+		{"id": endID, "type": "end", "label": "End"},
+	})
+
+	type testCase struct {
+		name             string
+		to               []byte
+		wantAdded        []string
+		wantRemoved      []string
+		wantChanged      map[string][]string
+		wantAddedEdges   []workflow.Edge
+		wantRemovedEdges []workflow.Edge
+	}
+
+	testCases := []testCase{
+		{
+			name:             "identical workflows have no changes",
+			to:               from,
+			wantAdded:        []string{},
+			wantRemoved:      []string{},
+			wantChanged:      map[string][]string{},
+			wantAddedEdges:   []workflow.Edge{},
+			wantRemovedEdges: []workflow.Edge{},
+		},
+		{
+			name: "inserted section adds the node and rewires edges",
+			to: createWorkflowJSON(t, []map[string]interface{}{
+				{"id": startID, "type": "start", "label": "Start", "next": sectionID},
+				{"id": sectionID, "type": "section", "label": "Basic Info", "next": newSectionID},
+				{"id": newSectionID, "type": "section", "label": "Details", "next": endID},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			wantAdded:   []string{newSectionID},
+			wantRemoved: []string{},
+			wantChanged: map[string][]string{},
+			wantAddedEdges: []workflow.Edge{
+				{From: sectionID, To: newSectionID, Field: "next"},
+				{From: newSectionID, To: endID, Field: "next"},
+			},
+			wantRemovedEdges: []workflow.Edge{
+				{From: sectionID, To: endID, Field: "next"},
+			},
+		},
+		{
+			name: "removed section and relabeled node",
+			to: createWorkflowJSON(t, []map[string]interface{}{
+				{"id": startID, "type": "start", "label": "Begin", "next": endID},
+				{"id": endID, "type": "end", "label": "End"},
+			}),
+			wantAdded:   []string{},
+			wantRemoved: []string{sectionID},
+			wantChanged: map[string][]string{startID: {"label"}},
+			wantAddedEdges: []workflow.Edge{
+				{From: startID, To: endID, Field: "next"},
+			},
+			wantRemovedEdges: []workflow.Edge{
+				{From: startID, To: sectionID, Field: "next"},
+				{From: sectionID, To: endID, Field: "next"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			diff, err := workflow.DiffWorkflows(from, tc.to)
+			require.NoError(t, err)
+
+			added := make([]string, len(diff.AddedNodes))
+			for i, n := range diff.AddedNodes {
+				added[i] = n.ID
+			}
+			require.Equal(t, tc.wantAdded, added)
+
+			removed := make([]string, len(diff.RemovedNodes))
+			for i, n := range diff.RemovedNodes {
+				removed[i] = n.ID
+			}
+			require.Equal(t, tc.wantRemoved, removed)
+
+			changed := make(map[string][]string, len(diff.ChangedNodes))
+			for _, n := range diff.ChangedNodes {
+				for _, change := range n.Changes {
+					changed[n.ID] = append(changed[n.ID], change.Field)
+				}
+			}
+			require.Equal(t, tc.wantChanged, changed)
+
+			require.Equal(t, tc.wantAddedEdges, diff.AddedEdges)
+			require.Equal(t, tc.wantRemovedEdges, diff.RemovedEdges)
+		})
+	}
+}
+
+func TestDiffWorkflows_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	_, err := workflow.DiffWorkflows([]byte("{"), []byte("[]"))
+	require.Error(t, err)
+}
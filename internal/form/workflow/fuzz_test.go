@@ -0,0 +1,135 @@
+package workflow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fuzzTimeout bounds a single input; workflows come straight from clients, so no input
+// may make parsing or validation hang
+const fuzzTimeout = 5 * time.Second
+
+var fuzzSeeds = []string{
+	``,
+	`null`,
+	`{}`,
+	`[]`,
+	`[null]`,
+	`[{}]`,
+	`[{"type":"start"}]`,
+	`[{"id":"s","type":"start","label":"Start","next":"e"},{"id":"e","type":"end","label":"End"}]`,
+	`[{"id":"s","type":"start","label":"Start","next":"a"},{"id":"a","type":"section","label":"A","next":"a"},{"id":"e","type":"end","label":"End"}]`,
+	`[{"id":"s","type":"start","label":"Start","next":"c"},{"id":"c","type":"condition","label":"C","nextTrue":"c","nextFalse":"e","conditionRule":{"source":"nonChoice","nodeId":"s","key":"q","pattern":"("}},{"id":"e","type":"end","label":"End"}]`,
+	`[{"id":"s","type":"start","label":"Start","next":"a"},{"id":"a","type":"section","label":"A","next":"c"},{"id":"c","type":"condition","label":"C","nextTrue":"b","nextFalse":"e","conditionRule":{"source":"choice","nodeId":"a","key":"q","choiceOptionId":"o","pattern":".*"}},{"id":"b","type":"approval","label":"B","next":"d","approverUnitId":"u"},{"id":"d","type":"delay","label":"D","next":"x","releaseAt":"2000-01-01T00:00:00Z"},{"id":"x","type":"action","label":"X","next":"e","actionType":"webhook","url":"https://example.com","payload":{"a":"question:q"}},{"id":"e","type":"end","label":"End"}]`,
+	`[{"id":1,"type":["start"],"label":{},"next":null}]`,
+	`[{"id":"s","type":"start","label":"Start","next":"s"},{"id":"e","type":"end","label":"End","next":"s"}]`,
+}
+
+// fuzzQuestionStore answers every lookup with a short text question, so the condition
+// checks get past the lookup and into the type rules
+type fuzzQuestionStore struct{}
+
+func (fuzzQuestionStore) GetByID(_ context.Context, id uuid.UUID) (question.Answerable, error) {
+	return question.NewAnswerable(question.Question{
+		ID:    id,
+		Type:  question.QuestionTypeShortText,
+		Title: pgtype.Text{String: "Fuzz", Valid: true},
+	}, uuid.Nil)
+}
+
+// withinTimeout fails the input when fn has not returned in time; a panic in fn fails
+// it through the fuzzing engine as usual
+func withinTimeout(t *testing.T, data []byte, fn func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(fuzzTimeout):
+		t.Fatalf("did not return within %s for input %q", fuzzTimeout, data)
+	}
+}
+
+func addSeeds(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+}
+
+func FuzzValidate(f *testing.F) {
+	addSeeds(f)
+	validator := workflow.NewValidator()
+	formID := uuid.New()
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		withinTimeout(t, data, func() {
+			_ = validator.Validate(context.Background(), formID, data, nil)
+			_ = validator.Validate(context.Background(), formID, data, fuzzQuestionStore{})
+			_ = validator.Warnings(context.Background(), data)
+		})
+	})
+}
+
+func FuzzActivate(f *testing.F) {
+	addSeeds(f)
+	validator := workflow.NewValidator()
+	formID := uuid.New()
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		withinTimeout(t, data, func() {
+			_ = validator.Activate(context.Background(), formID, data, fuzzQuestionStore{})
+		})
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	addSeeds(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		withinTimeout(t, data, func() {
+			nodes, err := node.Parse(data)
+			if err != nil {
+				return
+			}
+			for _, n := range nodes {
+				_ = n.NodeID()
+				_ = n.Successors()
+			}
+		})
+	})
+}
+
+// FuzzTraverse runs the respondent traversal on every workflow the validator accepts
+// for activation, the only ones a respondent can meet
+func FuzzTraverse(f *testing.F) {
+	addSeeds(f)
+	validator := workflow.NewValidator()
+	formID := uuid.New()
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		withinTimeout(t, data, func() {
+			err := validator.Activate(context.Background(), formID, data, fuzzQuestionStore{})
+			if err != nil {
+				return
+			}
+			runtime, err := workflow.NewRuntime(data)
+			if err != nil {
+				return
+			}
+			_, _ = runtime.Traverse(workflow.Answers{"q": "fuzz"}, workflow.Approvals{}, time.Now(), func(string) bool { return false })
+		})
+	})
+}
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
 	"NYCU-SDC/core-system-backend/internal/user"
 	"context"
 	"encoding/json"
@@ -9,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -22,11 +24,14 @@ import (
 
 type Store interface {
 	Get(ctx context.Context, formID uuid.UUID) (GetRow, error)
-	Update(ctx context.Context, formID uuid.UUID, workflow []byte, userID uuid.UUID) (UpdateRow, error)
+	Update(ctx context.Context, formID uuid.UUID, workflow []byte, userID uuid.UUID) (UpdateRow, []ValidationInfo, error)
 	CreateNode(ctx context.Context, formID uuid.UUID, nodeType NodeType, userID uuid.UUID) (CreateNodeRow, error)
 	DeleteNode(ctx context.Context, formID uuid.UUID, nodeID uuid.UUID, userID uuid.UUID) ([]byte, error)
 	Activate(ctx context.Context, formID uuid.UUID, userID uuid.UUID, workflow []byte) (ActivateRow, error)
 	GetValidationInfo(ctx context.Context, formID uuid.UUID, workflow []byte) ([]ValidationInfo, error)
+	Simulate(ctx context.Context, formID uuid.UUID, answers []shared.AnswerParam, approvals Approvals, at time.Time) (Traversal, error)
+	ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error)
+	Diff(ctx context.Context, formID uuid.UUID, fromVersionID uuid.UUID, toVersionID uuid.UUID) (Diff, error)
 }
 
 type Handler struct {
@@ -55,7 +60,7 @@ func NewHandler(
 }
 
 type createNodeRequest struct {
-	Type string `json:"type" validate:"required,oneof=SECTION CONDITION"`
+	Type string `json:"type" validate:"required,oneof=SECTION CONDITION APPROVAL DELAY ACTION"`
 }
 
 type createNodeResponse struct {
@@ -65,9 +70,35 @@ type createNodeResponse struct {
 }
 
 type ValidationInfo struct {
-	Type    ValidationInfoType `json:"type"`
-	NodeID  *string            `json:"nodeId,omitempty"`
-	Message string             `json:"message"`
+	Type     ValidationInfoType `json:"type"`
+	Severity ValidationSeverity `json:"severity"`
+	NodeID   *string            `json:"nodeId,omitempty"`
+	Message  string             `json:"message"`
+}
+
+type simulateAnswerRequest struct {
+	QuestionID string `json:"questionId" validate:"required,uuid"`
+	Value      string `json:"value"`
+}
+
+type simulateRequest struct {
+	Answers   []simulateAnswerRequest `json:"answers" validate:"dive"`
+	Approvals map[string]string       `json:"approvals" validate:"dive,keys,uuid,endkeys,oneof=PENDING APPROVED REJECTED"`
+	At        *time.Time              `json:"at"`
+}
+
+type SimulateResponse struct {
+	Path      []Step `json:"path"`
+	EndNodeID string `json:"endNodeId"`
+	Completed bool   `json:"completed"`
+}
+
+type VersionResponse struct {
+	ID         string    `json:"id"`
+	LastEditor string    `json:"lastEditor"`
+	IsActive   bool      `json:"isActive"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
 type GetWorkflowResponse struct {
@@ -131,34 +162,37 @@ func (h *Handler) UpdateWorkflow(w http.ResponseWriter, r *http.Request) {
 	// json.RawMessage doesn't need struct validation, so read body directly
 	var req json.RawMessage
 	if r.Body == nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("request body is nil"), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: body is empty", internal.ErrInvalidRequestBody), logger)
 		return
 	}
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to read request body: %w", err), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %w", internal.ErrInvalidRequestBody, err), logger)
 		return
 	}
 	if len(bodyBytes) == 0 {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("request body is empty"), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: body is empty", internal.ErrInvalidRequestBody), logger)
 		return
 	}
 
 	var unmarshalTest interface{}
 	err = json.Unmarshal(bodyBytes, &unmarshalTest)
 	if err != nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid JSON in request body: %w", err), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: malformed JSON: %w", internal.ErrInvalidRequestBody, err), logger)
 		return
 	}
 	req = json.RawMessage(bodyBytes)
 
-	row, err := h.store.Update(traceCtx, formID, []byte(req), currentUser.ID)
+	row, warnings, err := h.store.Update(traceCtx, formID, []byte(req), currentUser.ID)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusOK, json.RawMessage(row.Workflow))
+	handlerutil.WriteJSONResponse(w, http.StatusOK, GetWorkflowResponse{
+		Workflow: json.RawMessage(row.Workflow),
+		Info:     warnings,
+	})
 }
 
 func (h *Handler) CreateNode(w http.ResponseWriter, r *http.Request) {
@@ -174,7 +208,7 @@ func (h *Handler) CreateNode(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req createNodeRequest
-	err = handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -251,23 +285,23 @@ func (h *Handler) ActivateWorkflow(w http.ResponseWriter, r *http.Request) {
 	// json.RawMessage doesn't need struct validation, so read body directly
 	var req json.RawMessage
 	if r.Body == nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("request body is nil"), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: body is empty", internal.ErrInvalidRequestBody), logger)
 		return
 	}
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to read request body: %w", err), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %w", internal.ErrInvalidRequestBody, err), logger)
 		return
 	}
 	if len(bodyBytes) == 0 {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("request body is empty"), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: body is empty", internal.ErrInvalidRequestBody), logger)
 		return
 	}
 
 	var unmarshalTest interface{}
 	err = json.Unmarshal(bodyBytes, &unmarshalTest)
 	if err != nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid JSON in request body: %w", err), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: malformed JSON: %w", internal.ErrInvalidRequestBody, err), logger)
 		return
 	}
 	req = json.RawMessage(bodyBytes)
@@ -296,3 +330,121 @@ func (h *Handler) ActivateWorkflow(w http.ResponseWriter, r *http.Request) {
 
 	handlerutil.WriteJSONResponse(w, http.StatusOK, nil)
 }
+
+// SimulateWorkflow evaluates the latest workflow against hypothetical answers without saving a response
+func (h *Handler) SimulateWorkflow(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SimulateWorkflow")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formIDStr := r.PathValue("formId")
+	formID, err := handlerutil.ParseUUID(formIDStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req simulateRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	answers := make([]shared.AnswerParam, len(req.Answers))
+	for i, answer := range req.Answers {
+		answers[i] = shared.AnswerParam{
+			QuestionID: answer.QuestionID,
+			Value:      answer.Value,
+		}
+	}
+
+	// Convert uppercase request values to lowercase decisions
+	approvals := make(Approvals, len(req.Approvals))
+	for nodeID, decision := range req.Approvals {
+		approvals[nodeID] = ApprovalDecision(strings.ToLower(decision))
+	}
+
+	// Delay nodes are evaluated at the current time unless the author picks another one
+	at := time.Now()
+	if req.At != nil {
+		at = *req.At
+	}
+
+	traversal, err := h.store.Simulate(traceCtx, formID, answers, approvals, at)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, SimulateResponse{
+		Path:      traversal.Steps,
+		EndNodeID: traversal.CurrentNodeID,
+		Completed: traversal.Completed,
+	})
+}
+
+// ListVersions lists the workflow versions of a form so reviewers can pick two of them to diff
+func (h *Handler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListVersions")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	versions, err := h.store.ListVersions(traceCtx, formID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]VersionResponse, len(versions))
+	for i, version := range versions {
+		response[i] = VersionResponse{
+			ID:         version.ID.String(),
+			LastEditor: version.LastEditor.String(),
+			IsActive:   version.IsActive,
+			CreatedAt:  version.CreatedAt.Time,
+			UpdatedAt:  version.UpdatedAt.Time,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// DiffVersions returns the node and edge changes going from version a to version b
+func (h *Handler) DiffVersions(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DiffVersions")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	formID, err := handlerutil.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	fromVersionID, err := handlerutil.ParseUUID(r.PathValue("a"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	toVersionID, err := handlerutil.ParseUUID(r.PathValue("b"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	diff, err := h.store.Diff(traceCtx, formID, fromVersionID, toVersionID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, diff)
+}
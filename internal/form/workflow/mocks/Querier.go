@@ -305,6 +305,268 @@ func (_c *MockQuerier_Get_Call) RunAndReturn(run func(ctx context.Context, formI
 	return _c
 }
 
+// GetActive provides a mock function for the type MockQuerier
+func (_mock *MockQuerier) GetActive(ctx context.Context, formID uuid.UUID) (workflow.GetActiveRow, error) {
+	ret := _mock.Called(ctx, formID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActive")
+	}
+
+	var r0 workflow.GetActiveRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) (workflow.GetActiveRow, error)); ok {
+		return returnFunc(ctx, formID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) workflow.GetActiveRow); ok {
+		r0 = returnFunc(ctx, formID)
+	} else {
+		r0 = ret.Get(0).(workflow.GetActiveRow)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, formID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockQuerier_GetActive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetActive'
+type MockQuerier_GetActive_Call struct {
+	*mock.Call
+}
+
+// GetActive is a helper method to define mock.On call
+//   - ctx context.Context
+//   - formID uuid.UUID
+func (_e *MockQuerier_Expecter) GetActive(ctx interface{}, formID interface{}) *MockQuerier_GetActive_Call {
+	return &MockQuerier_GetActive_Call{Call: _e.mock.On("GetActive", ctx, formID)}
+}
+
+func (_c *MockQuerier_GetActive_Call) Run(run func(ctx context.Context, formID uuid.UUID)) *MockQuerier_GetActive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockQuerier_GetActive_Call) Return(getActiveRow workflow.GetActiveRow, err error) *MockQuerier_GetActive_Call {
+	_c.Call.Return(getActiveRow, err)
+	return _c
+}
+
+func (_c *MockQuerier_GetActive_Call) RunAndReturn(run func(ctx context.Context, formID uuid.UUID) (workflow.GetActiveRow, error)) *MockQuerier_GetActive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetVersion provides a mock function for the type MockQuerier
+func (_mock *MockQuerier) GetVersion(ctx context.Context, arg workflow.GetVersionParams) (workflow.WorkflowVersion, error) {
+	ret := _mock.Called(ctx, arg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVersion")
+	}
+
+	var r0 workflow.WorkflowVersion
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, workflow.GetVersionParams) (workflow.WorkflowVersion, error)); ok {
+		return returnFunc(ctx, arg)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, workflow.GetVersionParams) workflow.WorkflowVersion); ok {
+		r0 = returnFunc(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(workflow.WorkflowVersion)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, workflow.GetVersionParams) error); ok {
+		r1 = returnFunc(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockQuerier_GetVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVersion'
+type MockQuerier_GetVersion_Call struct {
+	*mock.Call
+}
+
+// GetVersion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - arg workflow.GetVersionParams
+func (_e *MockQuerier_Expecter) GetVersion(ctx interface{}, arg interface{}) *MockQuerier_GetVersion_Call {
+	return &MockQuerier_GetVersion_Call{Call: _e.mock.On("GetVersion", ctx, arg)}
+}
+
+func (_c *MockQuerier_GetVersion_Call) Run(run func(ctx context.Context, arg workflow.GetVersionParams)) *MockQuerier_GetVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 workflow.GetVersionParams
+		if args[1] != nil {
+			arg1 = args[1].(workflow.GetVersionParams)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockQuerier_GetVersion_Call) Return(workflowVersion workflow.WorkflowVersion, err error) *MockQuerier_GetVersion_Call {
+	_c.Call.Return(workflowVersion, err)
+	return _c
+}
+
+func (_c *MockQuerier_GetVersion_Call) RunAndReturn(run func(ctx context.Context, arg workflow.GetVersionParams) (workflow.WorkflowVersion, error)) *MockQuerier_GetVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListVersions provides a mock function for the type MockQuerier
+func (_mock *MockQuerier) ListVersions(ctx context.Context, formID uuid.UUID) ([]workflow.ListVersionsRow, error) {
+	ret := _mock.Called(ctx, formID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListVersions")
+	}
+
+	var r0 []workflow.ListVersionsRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]workflow.ListVersionsRow, error)); ok {
+		return returnFunc(ctx, formID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID) []workflow.ListVersionsRow); ok {
+		r0 = returnFunc(ctx, formID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]workflow.ListVersionsRow)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, formID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockQuerier_ListVersions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListVersions'
+type MockQuerier_ListVersions_Call struct {
+	*mock.Call
+}
+
+// ListVersions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - formID uuid.UUID
+func (_e *MockQuerier_Expecter) ListVersions(ctx interface{}, formID interface{}) *MockQuerier_ListVersions_Call {
+	return &MockQuerier_ListVersions_Call{Call: _e.mock.On("ListVersions", ctx, formID)}
+}
+
+func (_c *MockQuerier_ListVersions_Call) Run(run func(ctx context.Context, formID uuid.UUID)) *MockQuerier_ListVersions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockQuerier_ListVersions_Call) Return(listVersionsRows []workflow.ListVersionsRow, err error) *MockQuerier_ListVersions_Call {
+	_c.Call.Return(listVersionsRows, err)
+	return _c
+}
+
+func (_c *MockQuerier_ListVersions_Call) RunAndReturn(run func(ctx context.Context, formID uuid.UUID) ([]workflow.ListVersionsRow, error)) *MockQuerier_ListVersions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebhookWorkflows provides a mock function for the type MockQuerier
+func (_mock *MockQuerier) ListWebhookWorkflows(ctx context.Context) ([]workflow.ListWebhookWorkflowsRow, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebhookWorkflows")
+	}
+
+	var r0 []workflow.ListWebhookWorkflowsRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]workflow.ListWebhookWorkflowsRow, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []workflow.ListWebhookWorkflowsRow); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]workflow.ListWebhookWorkflowsRow)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockQuerier_ListWebhookWorkflows_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebhookWorkflows'
+type MockQuerier_ListWebhookWorkflows_Call struct {
+	*mock.Call
+}
+
+// ListWebhookWorkflows is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockQuerier_Expecter) ListWebhookWorkflows(ctx interface{}) *MockQuerier_ListWebhookWorkflows_Call {
+	return &MockQuerier_ListWebhookWorkflows_Call{Call: _e.mock.On("ListWebhookWorkflows", ctx)}
+}
+
+func (_c *MockQuerier_ListWebhookWorkflows_Call) Run(run func(ctx context.Context)) *MockQuerier_ListWebhookWorkflows_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockQuerier_ListWebhookWorkflows_Call) Return(listWebhookWorkflowsRows []workflow.ListWebhookWorkflowsRow, err error) *MockQuerier_ListWebhookWorkflows_Call {
+	_c.Call.Return(listWebhookWorkflowsRows, err)
+	return _c
+}
+
+func (_c *MockQuerier_ListWebhookWorkflows_Call) RunAndReturn(run func(ctx context.Context) ([]workflow.ListWebhookWorkflowsRow, error)) *MockQuerier_ListWebhookWorkflows_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Update provides a mock function for the type MockQuerier
 func (_mock *MockQuerier) Update(ctx context.Context, arg workflow.UpdateParams) (workflow.UpdateRow, error) {
 	ret := _mock.Called(ctx, arg)
@@ -370,3 +632,60 @@ func (_c *MockQuerier_Update_Call) RunAndReturn(run func(ctx context.Context, ar
 	_c.Call.Return(run)
 	return _c
 }
+
+// UpdateVersionWorkflow provides a mock function for the type MockQuerier
+func (_mock *MockQuerier) UpdateVersionWorkflow(ctx context.Context, arg workflow.UpdateVersionWorkflowParams) error {
+	ret := _mock.Called(ctx, arg)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateVersionWorkflow")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, workflow.UpdateVersionWorkflowParams) error); ok {
+		r0 = returnFunc(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockQuerier_UpdateVersionWorkflow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateVersionWorkflow'
+type MockQuerier_UpdateVersionWorkflow_Call struct {
+	*mock.Call
+}
+
+// UpdateVersionWorkflow is a helper method to define mock.On call
+//   - ctx context.Context
+//   - arg workflow.UpdateVersionWorkflowParams
+func (_e *MockQuerier_Expecter) UpdateVersionWorkflow(ctx interface{}, arg interface{}) *MockQuerier_UpdateVersionWorkflow_Call {
+	return &MockQuerier_UpdateVersionWorkflow_Call{Call: _e.mock.On("UpdateVersionWorkflow", ctx, arg)}
+}
+
+func (_c *MockQuerier_UpdateVersionWorkflow_Call) Run(run func(ctx context.Context, arg workflow.UpdateVersionWorkflowParams)) *MockQuerier_UpdateVersionWorkflow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 workflow.UpdateVersionWorkflowParams
+		if args[1] != nil {
+			arg1 = args[1].(workflow.UpdateVersionWorkflowParams)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockQuerier_UpdateVersionWorkflow_Call) Return(err error) *MockQuerier_UpdateVersionWorkflow_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockQuerier_UpdateVersionWorkflow_Call) RunAndReturn(run func(ctx context.Context, arg workflow.UpdateVersionWorkflowParams) error) *MockQuerier_UpdateVersionWorkflow_Call {
+	_c.Call.Return(run)
+	return _c
+}
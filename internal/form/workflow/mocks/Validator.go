@@ -302,3 +302,62 @@ func (_c *MockValidator_ValidateUpdateNodeIDs_Call) RunAndReturn(run func(ctx co
 	_c.Call.Return(run)
 	return _c
 }
+
+// Warnings provides a mock function for the type MockValidator
+func (_mock *MockValidator) Warnings(ctx context.Context, workflow1 []byte) []workflow.ValidationInfo {
+	ret := _mock.Called(ctx, workflow1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Warnings")
+	}
+
+	var r0 []workflow.ValidationInfo
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []byte) []workflow.ValidationInfo); ok {
+		r0 = returnFunc(ctx, workflow1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]workflow.ValidationInfo)
+		}
+	}
+	return r0
+}
+
+// MockValidator_Warnings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Warnings'
+type MockValidator_Warnings_Call struct {
+	*mock.Call
+}
+
+// Warnings is a helper method to define mock.On call
+//   - ctx context.Context
+//   - workflow1 []byte
+func (_e *MockValidator_Expecter) Warnings(ctx interface{}, workflow1 interface{}) *MockValidator_Warnings_Call {
+	return &MockValidator_Warnings_Call{Call: _e.mock.On("Warnings", ctx, workflow1)}
+}
+
+func (_c *MockValidator_Warnings_Call) Run(run func(ctx context.Context, workflow1 []byte)) *MockValidator_Warnings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []byte
+		if args[1] != nil {
+			arg1 = args[1].([]byte)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockValidator_Warnings_Call) Return(validationInfos []workflow.ValidationInfo) *MockValidator_Warnings_Call {
+	_c.Call.Return(validationInfos)
+	return _c
+}
+
+func (_c *MockValidator_Warnings_Call) RunAndReturn(run func(ctx context.Context, workflow1 []byte) []workflow.ValidationInfo) *MockValidator_Warnings_Call {
+	_c.Call.Return(run)
+	return _c
+}
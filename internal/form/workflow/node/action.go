@@ -0,0 +1,199 @@
+package node
+
+import (
+	"NYCU-SDC/core-system-backend/internal/egress"
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ActionType is the kind of side effect an action node triggers
+type ActionType string
+
+const (
+	ActionTypeWebhook ActionType = "webhook"
+	ActionTypeEvent   ActionType = "event"
+)
+
+// Payload mapping sources. A payload value is either one of the fixed sources
+// or PayloadSourceQuestionPrefix followed by the ID of a question in the form.
+const (
+	PayloadSourceFormID         = "form.id"
+	PayloadSourceUserID         = "user.id"
+	PayloadSourceNodeID         = "node.id"
+	PayloadSourceQuestionPrefix = "question:"
+)
+
+var eventNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// ActionNode represents a side effect fired once when a respondent passes through
+// it: a webhook POSTed to an external URL or an internal event.
+type ActionNode struct {
+	Base
+	Next       string            `json:"next"`
+	ActionType ActionType        `json:"actionType"`
+	URL        string            `json:"url"`
+	Event      string            `json:"event"`
+	Payload    map[string]string `json:"payload"`
+}
+
+func NewActionNode(raw Raw) (Node, error) {
+	n := &ActionNode{}
+	n.decode(raw, n)
+	return n, nil
+}
+
+func (n *ActionNode) Successors() []string {
+	return successors(n.Next)
+}
+
+func (n *ActionNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]Node, questionStore QuestionStore) error {
+	nodeID := n.ID
+
+	// Validate field names (check for typos and invalid fields)
+	err := n.validateFieldNames(nodeID)
+	if err != nil {
+		return err
+	}
+
+	err = n.decoded()
+	if err != nil {
+		return err
+	}
+
+	// Action node must have a next field
+	next := n.Next
+	if next == "" {
+		return fmt.Errorf("action node '%s' must have a 'next' field", nodeID)
+	}
+
+	// Validate that next node exists
+	_, exists := nodeMap[next]
+	if !exists {
+		return fmt.Errorf("action node '%s' references non-existent node '%s' in next", nodeID, next)
+	}
+
+	switch n.ActionType {
+	case ActionTypeWebhook:
+		err = n.validateWebhook(nodeID)
+	case ActionTypeEvent:
+		err = n.validateEvent(nodeID)
+	default:
+		err = fmt.Errorf("action node '%s' has invalid actionType: '%s'", nodeID, n.ActionType)
+	}
+	if err != nil {
+		return err
+	}
+
+	return n.validatePayload(ctx, formID, nodeID, questionStore)
+}
+
+// validateWebhook validates that the webhook URL is an absolute http(s) URL outside the
+// server's network
+func (n *ActionNode) validateWebhook(nodeID string) error {
+	if n.has("event") {
+		return fmt.Errorf("action node '%s' with actionType 'webhook' cannot have an 'event' field", nodeID)
+	}
+
+	rawURL := n.URL
+	if rawURL == "" {
+		return fmt.Errorf("action node '%s' with actionType 'webhook' must have a 'url' field", nodeID)
+	}
+
+	webhookURL, err := url.Parse(rawURL)
+	if err != nil || webhookURL.Host == "" || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") {
+		return fmt.Errorf("action node '%s' url '%s' must be an absolute http or https URL", nodeID, rawURL)
+	}
+	if egress.CheckHost(webhookURL.Hostname()) != nil {
+		return fmt.Errorf("action node '%s' url '%s' must not point to a private, loopback or link-local address", nodeID, rawURL)
+	}
+
+	return nil
+}
+
+// validateEvent validates the internal event name, e.g. "application.accepted"
+func (n *ActionNode) validateEvent(nodeID string) error {
+	if n.has("url") {
+		return fmt.Errorf("action node '%s' with actionType 'event' cannot have a 'url' field", nodeID)
+	}
+
+	event := n.Event
+	if event == "" {
+		return fmt.Errorf("action node '%s' with actionType 'event' must have an 'event' field", nodeID)
+	}
+
+	if !eventNamePattern.MatchString(event) {
+		return fmt.Errorf("action node '%s' event '%s' must be lowercase words separated by dots", nodeID, event)
+	}
+
+	return nil
+}
+
+// validatePayload validates that every payload value maps to a known source.
+// Question sources must reference questions of the same form.
+func (n *ActionNode) validatePayload(ctx context.Context, formID uuid.UUID, nodeID string, questionStore QuestionStore) error {
+	for key, source := range n.Payload {
+		if key == "" {
+			return fmt.Errorf("action node '%s' payload contains an empty key", nodeID)
+		}
+
+		switch source {
+		case PayloadSourceFormID, PayloadSourceUserID, PayloadSourceNodeID:
+			continue
+		}
+
+		rawQuestionID, found := strings.CutPrefix(source, PayloadSourceQuestionPrefix)
+		if !found {
+			return fmt.Errorf("action node '%s' payload.%s has unknown source '%s'", nodeID, key, source)
+		}
+
+		questionID, err := uuid.Parse(rawQuestionID)
+		if err != nil {
+			return fmt.Errorf("action node '%s' payload.%s question '%s' is not a valid UUID", nodeID, key, rawQuestionID)
+		}
+
+		if questionStore != nil {
+			answerable, err := questionStore.GetByID(ctx, questionID)
+			if err != nil {
+				return fmt.Errorf("action node '%s' payload.%s references non-existent question '%s'", nodeID, key, rawQuestionID)
+			}
+
+			if answerable.FormID() != formID {
+				return fmt.Errorf("action node '%s' payload.%s references question '%s' that belongs to a different form", nodeID, key, rawQuestionID)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateFieldNames validates that the node only contains valid field names
+func (n *ActionNode) validateFieldNames(nodeID string) error {
+	validFields := map[string]bool{
+		"id":         true,
+		"type":       true,
+		"label":      true,
+		"next":       true,
+		"actionType": true,
+		"url":        true,
+		"event":      true,
+		"payload":    true,
+	}
+
+	var invalidFields []string
+	for fieldName := range n.raw {
+		if !validFields[fieldName] {
+			invalidFields = append(invalidFields, fieldName)
+		}
+	}
+
+	if len(invalidFields) > 0 {
+		return fmt.Errorf("action node '%s' contains invalid field(s): %v. Valid fields are: actionType, event, id, label, next, payload, type, url", nodeID, invalidFields)
+	}
+
+	return nil
+}
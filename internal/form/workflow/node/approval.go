@@ -0,0 +1,90 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ApprovalNode represents an approval gate. Respondents cannot pass the gate
+// until a member of the approver unit approves their response.
+type ApprovalNode struct {
+	Base
+	Next           string `json:"next"`
+	ApproverUnitID string `json:"approverUnitId"`
+}
+
+func NewApprovalNode(raw Raw) (Node, error) {
+	n := &ApprovalNode{}
+	n.decode(raw, n)
+	return n, nil
+}
+
+func (n *ApprovalNode) Successors() []string {
+	return successors(n.Next)
+}
+
+func (n *ApprovalNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]Node, questionStore QuestionStore) error {
+	nodeID := n.ID
+
+	// Validate field names (check for typos and invalid fields)
+	err := n.validateFieldNames(nodeID)
+	if err != nil {
+		return err
+	}
+
+	err = n.decoded()
+	if err != nil {
+		return err
+	}
+
+	// Approval node must have a next field for approved responses
+	next := n.Next
+	if next == "" {
+		return fmt.Errorf("approval node '%s' must have a 'next' field", nodeID)
+	}
+
+	// Validate that next node exists
+	_, exists := nodeMap[next]
+	if !exists {
+		return fmt.Errorf("approval node '%s' references non-existent node '%s' in next", nodeID, next)
+	}
+
+	// Validate the designated approver unit
+	approverUnitID := n.ApproverUnitID
+	if approverUnitID == "" {
+		return fmt.Errorf("approval node '%s' must have an 'approverUnitId' field", nodeID)
+	}
+
+	_, err = uuid.Parse(approverUnitID)
+	if err != nil {
+		return fmt.Errorf("approval node '%s' approverUnitId '%s' is not a valid UUID", nodeID, approverUnitID)
+	}
+
+	return nil
+}
+
+// validateFieldNames validates that the node only contains valid field names
+func (n *ApprovalNode) validateFieldNames(nodeID string) error {
+	validFields := map[string]bool{
+		"id":             true,
+		"type":           true,
+		"label":          true,
+		"next":           true,
+		"approverUnitId": true,
+	}
+
+	var invalidFields []string
+	for fieldName := range n.raw {
+		if !validFields[fieldName] {
+			invalidFields = append(invalidFields, fieldName)
+		}
+	}
+
+	if len(invalidFields) > 0 {
+		return fmt.Errorf("approval node '%s' contains invalid field(s): %v. Valid fields are: approverUnitId, id, label, next, type", nodeID, invalidFields)
+	}
+
+	return nil
+}
@@ -4,22 +4,77 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
 
 	"github.com/google/uuid"
 )
 
 // ConditionNode represents a condition node
 type ConditionNode struct {
-	node map[string]interface{}
+	Base
+	NextTrue  string `json:"nextTrue"`
+	NextFalse string `json:"nextFalse"`
+	// ConditionRule is nil when the node has no conditionRule or it could not be decoded
+	ConditionRule *ConditionRule `json:"-"`
+
+	ruleErr error
 }
 
-func NewConditionNode(node map[string]interface{}) (Validatable, error) {
-	return &ConditionNode{node: node}, nil
+func NewConditionNode(raw Raw) (Node, error) {
+	n := &ConditionNode{}
+
+	// The rule is kept raw while decoding the node and decoded on its own, so a
+	// malformed rule keeps its specific error
+	wire := struct {
+		*ConditionNode
+		Rule json.RawMessage `json:"conditionRule"`
+	}{ConditionNode: n}
+	n.decode(raw, &wire)
+
+	rawRule, ok := raw.Fields["conditionRule"]
+	if !ok {
+		return n, nil
+	}
+	if wire.Rule == nil {
+		// Decoding stopped before the rule, on an invalid field
+		n.ConditionRule, n.ruleErr = decodeConditionRule(rawRule)
+		return n, nil
+	}
+
+	var conditionRule ConditionRule
+	n.ruleErr = json.Unmarshal(wire.Rule, &conditionRule)
+	if n.ruleErr == nil {
+		n.ConditionRule = &conditionRule
+	}
+
+	return n, nil
+}
+
+// RuleError returns the error that prevented the conditionRule from being decoded, if any
+func (n *ConditionNode) RuleError() error {
+	return n.ruleErr
 }
 
-func (n *ConditionNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]map[string]interface{}, questionStore QuestionStore) error {
-	nodeID, _ := n.node["id"].(string)
+func decodeConditionRule(rawRule interface{}) (*ConditionRule, error) {
+	conditionRuleBytes, err := json.Marshal(rawRule)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditionRule ConditionRule
+	err = json.Unmarshal(conditionRuleBytes, &conditionRule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conditionRule, nil
+}
+
+func (n *ConditionNode) Successors() []string {
+	return successors(n.NextTrue, n.NextFalse)
+}
+
+func (n *ConditionNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]Node, questionStore QuestionStore) error {
+	nodeID := n.ID
 
 	// Validate field names (check for typos and invalid fields)
 	err := n.validateFieldNames(nodeID)
@@ -27,14 +82,19 @@ func (n *ConditionNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap
 		return err
 	}
 
+	err = n.decoded()
+	if err != nil {
+		return err
+	}
+
 	// Condition node must have nextTrue and nextFalse
-	nextTrue, ok := n.node["nextTrue"].(string)
-	if !ok || nextTrue == "" {
+	nextTrue := n.NextTrue
+	if nextTrue == "" {
 		return fmt.Errorf("condition node '%s' must have a 'nextTrue' field", nodeID)
 	}
 
-	nextFalse, ok := n.node["nextFalse"].(string)
-	if !ok || nextFalse == "" {
+	nextFalse := n.NextFalse
+	if nextFalse == "" {
 		return fmt.Errorf("condition node '%s' must have a 'nextFalse' field", nodeID)
 	}
 
@@ -48,24 +108,16 @@ func (n *ConditionNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap
 	}
 
 	// Validate conditionRule
-	conditionRuleRaw, ok := n.node["conditionRule"]
-	if !ok {
+	if !n.has("conditionRule") {
 		return fmt.Errorf("condition node '%s' must have a 'conditionRule' field", nodeID)
 	}
 
-	// Parse conditionRule
-	conditionRuleBytes, err := json.Marshal(conditionRuleRaw)
-	if err != nil {
-		return fmt.Errorf("condition node '%s' has invalid conditionRule format: %w", nodeID, err)
-	}
-
-	var conditionRule ConditionRule
-	if err := json.Unmarshal(conditionRuleBytes, &conditionRule); err != nil {
-		return fmt.Errorf("condition node '%s' has invalid conditionRule format: %w", nodeID, err)
+	if n.ruleErr != nil {
+		return fmt.Errorf("condition node '%s' has invalid conditionRule format: %w", nodeID, n.ruleErr)
 	}
 
 	// Validate conditionRule fields
-	err = n.validateConditionRule(ctx, formID, nodeID, conditionRule, nodeMap, questionStore)
+	err = n.validateConditionRule(ctx, formID, nodeID, *n.ConditionRule, nodeMap, questionStore)
 	if err != nil {
 		return err
 	}
@@ -85,7 +137,7 @@ func (n *ConditionNode) validateFieldNames(nodeID string) error {
 	}
 
 	var invalidFields []string
-	for fieldName := range n.node {
+	for fieldName := range n.raw {
 		if !validFields[fieldName] {
 			invalidFields = append(invalidFields, fieldName)
 		}
@@ -98,7 +150,7 @@ func (n *ConditionNode) validateFieldNames(nodeID string) error {
 	return nil
 }
 
-func (n *ConditionNode) validateConditionRule(ctx context.Context, formID uuid.UUID, nodeID string, rule ConditionRule, nodeMap map[string]map[string]interface{}, questionStore QuestionStore) error {
+func (n *ConditionNode) validateConditionRule(ctx context.Context, formID uuid.UUID, nodeID string, rule ConditionRule, nodeMap map[string]Node, questionStore QuestionStore) error {
 	// Validate source
 	if rule.Source != ConditionSourceChoice && rule.Source != ConditionSourceNonChoice {
 		return fmt.Errorf("condition node '%s' has invalid conditionRule.source: '%s'", nodeID, rule.Source)
@@ -121,9 +173,9 @@ func (n *ConditionNode) validateConditionRule(ctx context.Context, formID uuid.U
 	}
 
 	// Validate pattern is a valid regex
-	_, err := regexp.Compile(rule.Pattern)
+	_, err := n.Pattern()
 	if err != nil {
-		return fmt.Errorf("condition node '%s' conditionRule.pattern is not a valid regex: %w", nodeID, err)
+		return err
 	}
 
 	// Validate question ID exists and type matches condition source
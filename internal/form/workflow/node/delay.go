@@ -0,0 +1,91 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DelayNode represents a scheduled pause. Respondents cannot pass the node
+// before its releaseAt time, e.g. until results are announced.
+type DelayNode struct {
+	Base
+	Next      string `json:"next"`
+	ReleaseAt string `json:"releaseAt"`
+}
+
+func NewDelayNode(raw Raw) (Node, error) {
+	n := &DelayNode{}
+	n.decode(raw, n)
+	return n, nil
+}
+
+func (n *DelayNode) Successors() []string {
+	return successors(n.Next)
+}
+
+func (n *DelayNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]Node, questionStore QuestionStore) error {
+	nodeID := n.ID
+
+	// Validate field names (check for typos and invalid fields)
+	err := n.validateFieldNames(nodeID)
+	if err != nil {
+		return err
+	}
+
+	err = n.decoded()
+	if err != nil {
+		return err
+	}
+
+	// Delay node must have a next field for when it is released
+	next := n.Next
+	if next == "" {
+		return fmt.Errorf("delay node '%s' must have a 'next' field", nodeID)
+	}
+
+	// Validate that next node exists
+	_, exists := nodeMap[next]
+	if !exists {
+		return fmt.Errorf("delay node '%s' references non-existent node '%s' in next", nodeID, next)
+	}
+
+	// Validate the release time
+	releaseAt := n.ReleaseAt
+	if releaseAt == "" {
+		return fmt.Errorf("delay node '%s' must have a 'releaseAt' field", nodeID)
+	}
+
+	_, err = time.Parse(time.RFC3339, releaseAt)
+	if err != nil {
+		return fmt.Errorf("delay node '%s' releaseAt '%s' is not a valid RFC 3339 timestamp", nodeID, releaseAt)
+	}
+
+	return nil
+}
+
+// validateFieldNames validates that the node only contains valid field names
+func (n *DelayNode) validateFieldNames(nodeID string) error {
+	validFields := map[string]bool{
+		"id":        true,
+		"type":      true,
+		"label":     true,
+		"next":      true,
+		"releaseAt": true,
+	}
+
+	var invalidFields []string
+	for fieldName := range n.raw {
+		if !validFields[fieldName] {
+			invalidFields = append(invalidFields, fieldName)
+		}
+	}
+
+	if len(invalidFields) > 0 {
+		return fmt.Errorf("delay node '%s' contains invalid field(s): %v. Valid fields are: id, label, next, releaseAt, type", nodeID, invalidFields)
+	}
+
+	return nil
+}
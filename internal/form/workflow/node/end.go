@@ -9,15 +9,21 @@ import (
 
 // EndNode represents an end node
 type EndNode struct {
-	node map[string]interface{}
+	Base
 }
 
-func NewEndNode(node map[string]interface{}) (Validatable, error) {
-	return &EndNode{node: node}, nil
+func NewEndNode(raw Raw) (Node, error) {
+	n := &EndNode{}
+	n.decode(raw, n)
+	return n, nil
 }
 
-func (n *EndNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]map[string]interface{}, questionStore QuestionStore) error {
-	nodeID, _ := n.node["id"].(string)
+func (n *EndNode) Successors() []string {
+	return nil
+}
+
+func (n *EndNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]Node, questionStore QuestionStore) error {
+	nodeID := n.ID
 
 	// Validate field names (check for typos and invalid fields)
 	err := n.validateFieldNames(nodeID)
@@ -25,6 +31,11 @@ func (n *EndNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[st
 		return err
 	}
 
+	err = n.decoded()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -37,7 +48,7 @@ func (n *EndNode) validateFieldNames(nodeID string) error {
 	}
 
 	var invalidFields []string
-	for fieldName := range n.node {
+	for fieldName := range n.raw {
 		if !validFields[fieldName] {
 			invalidFields = append(invalidFields, fieldName)
 		}
@@ -0,0 +1,87 @@
+package node
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// patternCacheSize bounds the number of compiled condition patterns kept in memory
+const patternCacheSize = 1024
+
+// patternCache is an LRU cache of compiled condition patterns keyed by the pattern.
+// Workflows are validated and traversed on every save and submit, while their
+// patterns rarely change, so compiling each one once saves most of the regex work.
+type patternCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type patternEntry struct {
+	pattern string
+	re      *regexp.Regexp
+	err     error
+}
+
+var patterns = newPatternCache(patternCacheSize)
+
+func newPatternCache(size int) *patternCache {
+	return &patternCache{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// compile returns the compiled pattern, compiling it only when it is not cached.
+// Invalid patterns are cached with their error.
+func (c *patternCache) compile(pattern string) (*regexp.Regexp, error) {
+	c.mu.Lock()
+	element, ok := c.entries[pattern]
+	if ok {
+		c.order.MoveToFront(element)
+		entry := element.Value.(*patternEntry)
+		c.mu.Unlock()
+		return entry.re, entry.err
+	}
+	c.mu.Unlock()
+
+	// Compile outside the lock; a pattern compiled twice concurrently is stored once
+	re, err := regexp.Compile(pattern)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok = c.entries[pattern]
+	if ok {
+		c.order.MoveToFront(element)
+		return re, err
+	}
+
+	c.entries[pattern] = c.order.PushFront(&patternEntry{pattern: pattern, re: re, err: err})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*patternEntry).pattern)
+	}
+
+	return re, err
+}
+
+// Pattern returns the compiled conditionRule.pattern of the node. An invalid pattern
+// is reported with the ID of the node.
+func (n *ConditionNode) Pattern() (*regexp.Regexp, error) {
+	if n.ConditionRule == nil {
+		return nil, fmt.Errorf("condition node '%s' must have a 'conditionRule' field", n.ID)
+	}
+
+	re, err := patterns.compile(n.ConditionRule.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("condition node '%s' conditionRule.pattern is not a valid regex: %w", n.ID, err)
+	}
+
+	return re, nil
+}
@@ -0,0 +1,141 @@
+package node
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternCache_Compile(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name string
+		// compiles are run in order on a cache of size 2
+		compiles []string
+		// cached are the patterns expected to be kept, most recently used first
+		cached []string
+		// invalid are the patterns expected to fail to compile
+		invalid []string
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Repeated pattern is compiled once",
+			compiles: []string{"^a$", "^a$", "^a$"},
+			cached:   []string{"^a$"},
+		},
+		{
+			name:     "Least recently used pattern is evicted",
+			compiles: []string{"^a$", "^b$", "^c$"},
+			cached:   []string{"^c$", "^b$"},
+		},
+		{
+			name:     "Hit keeps a pattern from being evicted",
+			compiles: []string{"^a$", "^b$", "^a$", "^c$"},
+			cached:   []string{"^c$", "^a$"},
+		},
+		{
+			name:     "Invalid pattern is cached with its error",
+			compiles: []string{"(", "("},
+			cached:   []string{"("},
+			invalid:  []string{"("},
+		},
+		{
+			name:     "Invalid pattern is evicted like any other",
+			compiles: []string{"(", "^a$", "^b$"},
+			cached:   []string{"^b$", "^a$"},
+			invalid:  []string{"("},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cache := newPatternCache(2)
+			for _, pattern := range tc.compiles {
+				re, err := cache.compile(pattern)
+				if slices.Contains(tc.invalid, pattern) {
+					require.Error(t, err, pattern)
+					require.Nil(t, re, pattern)
+					continue
+				}
+				require.NoError(t, err, pattern)
+				require.Equal(t, pattern, re.String())
+			}
+
+			var cached []string
+			for element := cache.order.Front(); element != nil; element = element.Next() {
+				cached = append(cached, element.Value.(*patternEntry).pattern)
+			}
+			require.Equal(t, tc.cached, cached)
+			require.Len(t, cache.entries, len(tc.cached))
+		})
+	}
+}
+
+func TestPatternCache_CompileReturnsCachedRegexp(t *testing.T) {
+	t.Parallel()
+
+	cache := newPatternCache(2)
+
+	first, err := cache.compile("^a$")
+	require.NoError(t, err)
+	second, err := cache.compile("^a$")
+	require.NoError(t, err)
+	require.Same(t, first, second)
+
+	_, err = cache.compile("^b$")
+	require.NoError(t, err)
+	_, err = cache.compile("^c$")
+	require.NoError(t, err)
+
+	// Evicted, so compiled again
+	third, err := cache.compile("^a$")
+	require.NoError(t, err)
+	require.NotSame(t, first, third)
+}
+
+func TestConditionNode_Pattern(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		node        ConditionNode
+		expectedErr string
+	}
+
+	testCases := []testCase{
+		{
+			name: "Valid pattern",
+			node: ConditionNode{Base: Base{ID: "n1"}, ConditionRule: &ConditionRule{Pattern: "^yes$"}},
+		},
+		{
+			name:        "Missing condition rule",
+			node:        ConditionNode{Base: Base{ID: "n1"}},
+			expectedErr: "condition node 'n1' must have a 'conditionRule' field",
+		},
+		{
+			name:        "Invalid pattern",
+			node:        ConditionNode{Base: Base{ID: "n1"}, ConditionRule: &ConditionRule{Pattern: "[a-"}},
+			expectedErr: "condition node 'n1' conditionRule.pattern is not a valid regex",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			re, err := tc.node.Pattern()
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				require.Nil(t, re)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, re.MatchString("yes"))
+		})
+	}
+}
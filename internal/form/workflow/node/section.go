@@ -9,15 +9,22 @@ import (
 
 // SectionNode represents a section node
 type SectionNode struct {
-	node map[string]interface{}
+	Base
+	Next string `json:"next"`
 }
 
-func NewSectionNode(node map[string]interface{}) (Validatable, error) {
-	return &SectionNode{node: node}, nil
+func NewSectionNode(raw Raw) (Node, error) {
+	n := &SectionNode{}
+	n.decode(raw, n)
+	return n, nil
 }
 
-func (n *SectionNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]map[string]interface{}, questionStore QuestionStore) error {
-	nodeID, _ := n.node["id"].(string)
+func (n *SectionNode) Successors() []string {
+	return successors(n.Next)
+}
+
+func (n *SectionNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]Node, questionStore QuestionStore) error {
+	nodeID := n.ID
 
 	// Validate field names (check for typos and invalid fields)
 	err := n.validateFieldNames(nodeID)
@@ -25,9 +32,14 @@ func (n *SectionNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap ma
 		return err
 	}
 
+	err = n.decoded()
+	if err != nil {
+		return err
+	}
+
 	// Section node must have a next field (unless it's the last node before end)
-	next, ok := n.node["next"].(string)
-	if !ok || next == "" {
+	next := n.Next
+	if next == "" {
 		return fmt.Errorf("section node '%s' must have a 'next' field", nodeID)
 	}
 
@@ -50,7 +62,7 @@ func (n *SectionNode) validateFieldNames(nodeID string) error {
 	}
 
 	var invalidFields []string
-	for fieldName := range n.node {
+	for fieldName := range n.raw {
 		if !validFields[fieldName] {
 			invalidFields = append(invalidFields, fieldName)
 		}
@@ -9,15 +9,22 @@ import (
 
 // StartNode represents a start node
 type StartNode struct {
-	node map[string]interface{}
+	Base
+	Next string `json:"next"`
 }
 
-func NewStartNode(node map[string]interface{}) (Validatable, error) {
-	return &StartNode{node: node}, nil
+func NewStartNode(raw Raw) (Node, error) {
+	n := &StartNode{}
+	n.decode(raw, n)
+	return n, nil
 }
 
-func (n *StartNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]map[string]interface{}, questionStore QuestionStore) error {
-	nodeID, _ := n.node["id"].(string)
+func (n *StartNode) Successors() []string {
+	return successors(n.Next)
+}
+
+func (n *StartNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]Node, questionStore QuestionStore) error {
+	nodeID := n.ID
 
 	// Validate field names (check for typos and invalid fields)
 	err := n.validateFieldNames(nodeID)
@@ -25,9 +32,14 @@ func (n *StartNode) Validate(ctx context.Context, formID uuid.UUID, nodeMap map[
 		return err
 	}
 
+	err = n.decoded()
+	if err != nil {
+		return err
+	}
+
 	// Start node must have a next field
-	next, ok := n.node["next"].(string)
-	if !ok || next == "" {
+	next := n.Next
+	if next == "" {
 		return fmt.Errorf("start node '%s' must have a 'next' field", nodeID)
 	}
 
@@ -50,7 +62,7 @@ func (n *StartNode) validateFieldNames(nodeID string) error {
 	}
 
 	var invalidFields []string
-	for fieldName := range n.node {
+	for fieldName := range n.raw {
 		if !validFields[fieldName] {
 			invalidFields = append(invalidFields, fieldName)
 		}
@@ -2,6 +2,8 @@ package node
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"NYCU-SDC/core-system-backend/internal/form/question"
@@ -17,7 +19,100 @@ type QuestionStore interface {
 // Validatable defines the interface for node validation
 // Similar to Answerable in question package
 type Validatable interface {
-	Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]map[string]interface{}, questionStore QuestionStore) error
+	Validate(ctx context.Context, formID uuid.UUID, nodeMap map[string]Node, questionStore QuestionStore) error
+}
+
+// Node is a workflow node decoded into the typed struct selected by its "type"
+// discriminator, e.g. *SectionNode or *ConditionNode. Callers reach the
+// type-specific fields with a type switch.
+type Node interface {
+	Validatable
+	NodeID() string
+	NodeType() string
+	NodeLabel() string
+	// Successors returns the IDs of the nodes this node links to, skipping empty links
+	Successors() []string
+}
+
+// Base holds the fields shared by every node type, along with the raw JSON object
+// the node was decoded from so validation can still detect unknown fields.
+type Base struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+
+	raw       map[string]interface{}
+	decodeErr error
+}
+
+func (b *Base) NodeID() string {
+	return b.ID
+}
+
+func (b *Base) NodeType() string {
+	return b.Type
+}
+
+func (b *Base) NodeLabel() string {
+	return b.Label
+}
+
+// Raw is a node as found in the workflow JSON: the object, kept so validation can
+// detect unknown fields, and the bytes it was parsed from. Data may be nil, the object
+// is then encoded again to decode the typed node.
+type Raw struct {
+	Fields map[string]interface{}
+	Data   []byte
+}
+
+// decode fills target, the typed node embedding b, from the raw JSON object.
+// A field with the wrong JSON type is left at its zero value and remembered, so
+// Validate reports it on activation while draft checks keep working on the rest.
+func (b *Base) decode(raw Raw, target interface{}) {
+	b.raw = raw.Fields
+
+	data := raw.Data
+	if data == nil {
+		var err error
+		data, err = json.Marshal(raw.Fields)
+		if err != nil {
+			b.decodeErr = err
+			return
+		}
+	}
+
+	err := json.Unmarshal(data, target)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		b.decodeErr = fmt.Errorf("field '%s' must be of type %s", typeErr.Field, typeErr.Type)
+		return
+	}
+	b.decodeErr = err
+}
+
+// decoded returns the error recorded while decoding the node, if any
+func (b *Base) decoded() error {
+	if b.decodeErr == nil {
+		return nil
+	}
+	return fmt.Errorf("%s node '%s' %w", b.Type, b.ID, b.decodeErr)
+}
+
+// has reports whether the raw JSON object contains the field, even with an empty value
+func (b *Base) has(field string) bool {
+	_, ok := b.raw[field]
+	return ok
+}
+
+// successors collects the non-empty node IDs among links
+func successors(links ...string) []string {
+	var result []string
+	for _, link := range links {
+		if link != "" {
+			result = append(result, link)
+		}
+	}
+	return result
 }
 
 // ConditionSource represents the source type for condition rules
@@ -43,30 +138,83 @@ const (
 	TypeSection   = "section"
 	TypeCondition = "condition"
 	TypeEnd       = "end"
+	TypeApproval  = "approval"
+	TypeDelay     = "delay"
+	TypeAction    = "action"
 )
 
-// NewNode creates a Validatable instance based on node type.
-// Returns the node type as a string to avoid circular dependency.
-func New(node map[string]interface{}) (Validatable, string, error) {
-	nodeType, ok := node["type"].(string)
+// New decodes a raw JSON node into the typed node matching its "type" field.
+func New(node map[string]interface{}) (Node, error) {
+	return Decode(Raw{Fields: node})
+}
+
+// Decode is New for a node parsed by ParseRaw, decoding the typed node from the bytes
+// of the node instead of encoding its object again
+func Decode(raw Raw) (Node, error) {
+	nodeType, ok := raw.Fields["type"].(string)
 	if !ok || nodeType == "" {
-		return nil, "", fmt.Errorf("node missing required field 'type'")
+		return nil, fmt.Errorf("node missing required field 'type'")
 	}
 
 	switch nodeType {
 	case TypeStart:
-		validatable, err := NewStartNode(node)
-		return validatable, nodeType, err
+		return NewStartNode(raw)
 	case TypeSection:
-		validatable, err := NewSectionNode(node)
-		return validatable, nodeType, err
+		return NewSectionNode(raw)
 	case TypeCondition:
-		validatable, err := NewConditionNode(node)
-		return validatable, nodeType, err
+		return NewConditionNode(raw)
 	case TypeEnd:
-		validatable, err := NewEndNode(node)
-		return validatable, nodeType, err
+		return NewEndNode(raw)
+	case TypeApproval:
+		return NewApprovalNode(raw)
+	case TypeDelay:
+		return NewDelayNode(raw)
+	case TypeAction:
+		return NewActionNode(raw)
 	default:
-		return nil, "", fmt.Errorf("unsupported node type: %s", nodeType)
+		return nil, fmt.Errorf("unsupported node type: %s", nodeType)
+	}
+}
+
+// ParseRaw splits a workflow JSON array into its nodes, parsing the workflow once.
+// A null entry has no fields.
+func ParseRaw(workflow []byte) ([]Raw, error) {
+	var items []json.RawMessage
+	err := json.Unmarshal(workflow, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]Raw, len(items))
+	for i, item := range items {
+		var fields map[string]interface{}
+		err = json.Unmarshal(item, &fields)
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = Raw{Fields: fields, Data: item}
+	}
+
+	return nodes, nil
+}
+
+// Parse decodes a workflow JSON array into typed nodes, keeping the workflow order.
+// It only checks what is needed to pick each node type; the workflow validator
+// enforces the remaining rules.
+func Parse(workflow []byte) ([]Node, error) {
+	rawNodes, err := ParseRaw(workflow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON format: %w", err)
 	}
+
+	nodes := make([]Node, 0, len(rawNodes))
+	for i, raw := range rawNodes {
+		n, err := Decode(raw)
+		if err != nil {
+			return nil, fmt.Errorf("node at index %d: %w", i, err)
+		}
+		nodes = append(nodes, n)
+	}
+
+	return nodes, nil
 }
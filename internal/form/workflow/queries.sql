@@ -5,6 +5,24 @@ WHERE form_id = $1
 ORDER BY updated_at DESC
 LIMIT 1;
 
+-- name: GetActive :one
+SELECT workflow, id, form_id, last_editor, is_active, created_at, updated_at
+FROM workflow_versions
+WHERE form_id = $1
+  AND is_active = true
+ORDER BY updated_at DESC
+LIMIT 1;
+
+-- name: GetVersion :one
+SELECT * FROM workflow_versions
+WHERE form_id = @form_id AND id = @id;
+
+-- name: ListVersions :many
+SELECT id, form_id, last_editor, is_active, created_at, updated_at
+FROM workflow_versions
+WHERE form_id = $1
+ORDER BY updated_at DESC;
+
 -- name: Update :one
 WITH latest_workflow AS (
     SELECT wv.id, wv.is_active, wv.form_id
@@ -330,4 +348,13 @@ unchanged AS (
 -- Return the activated version or unchanged version
 SELECT * FROM activated
 UNION ALL
-SELECT * FROM unchanged;
\ No newline at end of file
+SELECT * FROM unchanged;
+
+-- name: ListWebhookWorkflows :many
+SELECT id, workflow FROM workflow_versions
+WHERE workflow @> '[{"type": "action", "actionType": "webhook"}]'::JSONB;
+
+-- name: UpdateVersionWorkflow :exec
+UPDATE workflow_versions
+SET workflow = @workflow
+WHERE id = @id;
\ No newline at end of file
@@ -416,6 +416,138 @@ func (q *Queries) Get(ctx context.Context, formID uuid.UUID) (GetRow, error) {
 	return i, err
 }
 
+const getActive = `-- name: GetActive :one
+SELECT workflow, id, form_id, last_editor, is_active, created_at, updated_at
+FROM workflow_versions
+WHERE form_id = $1
+  AND is_active = true
+ORDER BY updated_at DESC
+LIMIT 1
+`
+
+type GetActiveRow struct {
+	Workflow   []byte
+	ID         uuid.UUID
+	FormID     uuid.UUID
+	LastEditor uuid.UUID
+	IsActive   bool
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+func (q *Queries) GetActive(ctx context.Context, formID uuid.UUID) (GetActiveRow, error) {
+	row := q.db.QueryRow(ctx, getActive, formID)
+	var i GetActiveRow
+	err := row.Scan(
+		&i.Workflow,
+		&i.ID,
+		&i.FormID,
+		&i.LastEditor,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getVersion = `-- name: GetVersion :one
+SELECT id, form_id, last_editor, is_active, workflow, created_at, updated_at FROM workflow_versions
+WHERE form_id = $1 AND id = $2
+`
+
+type GetVersionParams struct {
+	FormID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) GetVersion(ctx context.Context, arg GetVersionParams) (WorkflowVersion, error) {
+	row := q.db.QueryRow(ctx, getVersion, arg.FormID, arg.ID)
+	var i WorkflowVersion
+	err := row.Scan(
+		&i.ID,
+		&i.FormID,
+		&i.LastEditor,
+		&i.IsActive,
+		&i.Workflow,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listVersions = `-- name: ListVersions :many
+SELECT id, form_id, last_editor, is_active, created_at, updated_at
+FROM workflow_versions
+WHERE form_id = $1
+ORDER BY updated_at DESC
+`
+
+type ListVersionsRow struct {
+	ID         uuid.UUID
+	FormID     uuid.UUID
+	LastEditor uuid.UUID
+	IsActive   bool
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+func (q *Queries) ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error) {
+	rows, err := q.db.Query(ctx, listVersions, formID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListVersionsRow
+	for rows.Next() {
+		var i ListVersionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FormID,
+			&i.LastEditor,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhookWorkflows = `-- name: ListWebhookWorkflows :many
+SELECT id, workflow FROM workflow_versions
+WHERE workflow @> '[{"type": "action", "actionType": "webhook"}]'::JSONB
+`
+
+type ListWebhookWorkflowsRow struct {
+	ID       uuid.UUID
+	Workflow []byte
+}
+
+func (q *Queries) ListWebhookWorkflows(ctx context.Context) ([]ListWebhookWorkflowsRow, error) {
+	rows, err := q.db.Query(ctx, listWebhookWorkflows)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWebhookWorkflowsRow
+	for rows.Next() {
+		var i ListWebhookWorkflowsRow
+		if err := rows.Scan(&i.ID, &i.Workflow); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const update = `-- name: Update :one
 WITH latest_workflow AS (
     SELECT wv.id, wv.is_active, wv.form_id
@@ -475,3 +607,19 @@ func (q *Queries) Update(ctx context.Context, arg UpdateParams) (UpdateRow, erro
 	)
 	return i, err
 }
+
+const updateVersionWorkflow = `-- name: UpdateVersionWorkflow :exec
+UPDATE workflow_versions
+SET workflow = $1
+WHERE id = $2
+`
+
+type UpdateVersionWorkflowParams struct {
+	Workflow []byte
+	ID       uuid.UUID
+}
+
+func (q *Queries) UpdateVersionWorkflow(ctx context.Context, arg UpdateVersionWorkflowParams) error {
+	_, err := q.db.Exec(ctx, updateVersionWorkflow, arg.Workflow, arg.ID)
+	return err
+}
@@ -0,0 +1,17 @@
+package workflow
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the workflow of a form, its versions and its nodes
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /forms/{id}/workflow", route.Authenticated, route.PermissionNone, h.GetWorkflow)
+	r.Handle("PUT /forms/{id}/workflow", route.Authenticated, route.PermissionNone, h.UpdateWorkflow)
+	r.Handle("POST /forms/{id}/workflow/activate", route.Authenticated, route.PermissionNone, h.ActivateWorkflow)
+	r.Handle("GET /forms/{id}/workflow/versions", route.Authenticated, route.PermissionNone, h.ListVersions)
+	r.Handle("GET /forms/{id}/workflow/versions/{a}/diff/{b}", route.Authenticated, route.PermissionNone, h.DiffVersions)
+	r.Handle("POST /forms/{formId}/workflow/nodes", route.Authenticated, route.PermissionNone, h.CreateNode)
+	r.Handle("DELETE /forms/{formId}/workflow/nodes/{nodeId}", route.Authenticated, route.PermissionNone, h.DeleteNode)
+	r.Handle("POST /forms/{formId}/workflow/simulate", route.Authenticated, route.PermissionNone, h.SimulateWorkflow)
+}
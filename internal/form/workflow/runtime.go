@@ -0,0 +1,297 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
+)
+
+// Answers maps question IDs to the raw answer values given by a respondent
+type Answers map[string]string
+
+// ApprovalDecision is the state of an approval gate for a single response
+type ApprovalDecision string
+
+const (
+	ApprovalDecisionPending  ApprovalDecision = "pending"
+	ApprovalDecisionApproved ApprovalDecision = "approved"
+	ApprovalDecisionRejected ApprovalDecision = "rejected"
+)
+
+// Approvals maps approval node IDs to the decision made for a response.
+// Gates without an entry are treated as pending.
+type Approvals map[string]ApprovalDecision
+
+// ApprovalEvaluation records the state of an approval gate reached during traversal
+type ApprovalEvaluation struct {
+	ApproverUnitID string           `json:"approverUnitId"`
+	Decision       ApprovalDecision `json:"decision"`
+}
+
+// DelayEvaluation records whether a delay node had been released at traversal time
+type DelayEvaluation struct {
+	ReleaseAt time.Time `json:"releaseAt"`
+	Released  bool      `json:"released"`
+}
+
+// ActionEvaluation describes an action node passed during traversal. The webhook
+// URL and payload mapping are kept out of JSON so they are not exposed to respondents.
+type ActionEvaluation struct {
+	ActionType node.ActionType   `json:"actionType"`
+	Event      string            `json:"event,omitempty"`
+	URL        string            `json:"-"`
+	Payload    map[string]string `json:"-"`
+}
+
+// ConditionEvaluation records how a condition node was resolved during traversal
+type ConditionEvaluation struct {
+	Source         node.ConditionSource `json:"source"`
+	QuestionID     string               `json:"questionId"`
+	ChoiceOptionID string               `json:"choiceOptionId,omitempty"`
+	Pattern        string               `json:"pattern"`
+	Value          string               `json:"value"`
+	Answered       bool                 `json:"answered"`
+	Matched        bool                 `json:"matched"`
+	Next           string               `json:"next"`
+}
+
+// Step is a single node visited during traversal
+type Step struct {
+	NodeID    string               `json:"nodeId"`
+	Type      string               `json:"type"`
+	Label     string               `json:"label"`
+	Condition *ConditionEvaluation `json:"condition,omitempty"`
+	Approval  *ApprovalEvaluation  `json:"approval,omitempty"`
+	Delay     *DelayEvaluation     `json:"delay,omitempty"`
+	Action    *ActionEvaluation    `json:"action,omitempty"`
+}
+
+// Traversal is the path taken through a workflow.
+// CurrentNodeID is the node the traversal stopped at: a section waiting for the
+// respondent, an approval gate that has not been approved, a delay node that has
+// not been released yet, or the end node when Completed is true.
+type Traversal struct {
+	Steps         []Step `json:"steps"`
+	CurrentNodeID string `json:"currentNodeId"`
+	Completed     bool   `json:"completed"`
+}
+
+// AwaitingApproval returns the approval gate the traversal stopped at, if any
+func (t Traversal) AwaitingApproval() (Step, bool) {
+	if t.Completed || len(t.Steps) == 0 {
+		return Step{}, false
+	}
+	last := t.Steps[len(t.Steps)-1]
+	if last.Approval == nil {
+		return Step{}, false
+	}
+	return last, true
+}
+
+// AwaitingRelease returns the delay node the traversal stopped at, if any
+func (t Traversal) AwaitingRelease() (Step, bool) {
+	if t.Completed || len(t.Steps) == 0 {
+		return Step{}, false
+	}
+	last := t.Steps[len(t.Steps)-1]
+	if last.Delay == nil || last.Delay.Released {
+		return Step{}, false
+	}
+	return last, true
+}
+
+// Runtime walks a workflow graph from its start node, resolving condition nodes
+// against a set of answers. It does not validate the workflow; callers are
+// expected to run it on workflows that already passed activation validation.
+type Runtime struct {
+	nodes   map[string]node.Node
+	startID string
+}
+
+func NewRuntime(workflow []byte) (*Runtime, error) {
+	nodes, err := node.Parse(workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime := &Runtime{nodes: make(map[string]node.Node, len(nodes))}
+	for _, n := range nodes {
+		nodeID := n.NodeID()
+		if nodeID == "" {
+			return nil, fmt.Errorf("workflow contains a node without an id")
+		}
+		runtime.nodes[nodeID] = n
+
+		if n.NodeType() == node.TypeStart {
+			runtime.startID = nodeID
+		}
+	}
+
+	if runtime.startID == "" {
+		return nil, fmt.Errorf("workflow has no start node")
+	}
+
+	return runtime, nil
+}
+
+// HasSection reports whether the workflow contains a section node with the given id
+func (r *Runtime) HasSection(sectionID string) bool {
+	_, ok := r.nodes[sectionID].(*node.SectionNode)
+	return ok
+}
+
+// Traverse follows the workflow from the start node. Condition nodes are resolved
+// with the given answers; unanswered questions evaluate to false. The traversal
+// stops at the first section for which stopAt returns true, at the first approval
+// gate that is not approved, at the first delay node whose releaseAt is after now,
+// or at the end node. A nil stopAt walks the whole path.
+func (r *Runtime) Traverse(answers Answers, approvals Approvals, now time.Time, stopAt func(sectionID string) bool) (Traversal, error) {
+	traversal := Traversal{Steps: []Step{}}
+
+	// Every node can be visited at most once on a valid (acyclic) workflow
+	maxSteps := len(r.nodes)
+	currentID := r.startID
+	for range maxSteps {
+		current, ok := r.nodes[currentID]
+		if !ok {
+			return Traversal{}, fmt.Errorf("node '%s' does not exist in workflow", currentID)
+		}
+
+		nodeType := current.NodeType()
+		step := Step{NodeID: currentID, Type: nodeType, Label: current.NodeLabel()}
+
+		var next string
+		switch current := current.(type) {
+		case *node.StartNode:
+			next = current.Next
+		case *node.SectionNode:
+			next = current.Next
+		case *node.ConditionNode:
+			evaluation, err := evaluateCondition(current, answers)
+			if err != nil {
+				return Traversal{}, err
+			}
+			step.Condition = &evaluation
+			next = evaluation.Next
+		case *node.ApprovalNode:
+			decision, ok := approvals[currentID]
+			if !ok {
+				decision = ApprovalDecisionPending
+			}
+			step.Approval = &ApprovalEvaluation{ApproverUnitID: current.ApproverUnitID, Decision: decision}
+
+			if decision != ApprovalDecisionApproved {
+				traversal.Steps = append(traversal.Steps, step)
+				traversal.CurrentNodeID = currentID
+				return traversal, nil
+			}
+			next = current.Next
+		case *node.DelayNode:
+			releaseAt, err := time.Parse(time.RFC3339, current.ReleaseAt)
+			if err != nil {
+				return Traversal{}, fmt.Errorf("delay node '%s' has invalid releaseAt: %w", currentID, err)
+			}
+			step.Delay = &DelayEvaluation{ReleaseAt: releaseAt, Released: !now.Before(releaseAt)}
+
+			if !step.Delay.Released {
+				traversal.Steps = append(traversal.Steps, step)
+				traversal.CurrentNodeID = currentID
+				return traversal, nil
+			}
+			next = current.Next
+		case *node.ActionNode:
+			step.Action = &ActionEvaluation{
+				ActionType: current.ActionType,
+				Event:      current.Event,
+				URL:        current.URL,
+				Payload:    current.Payload,
+			}
+			next = current.Next
+		case *node.EndNode:
+			traversal.Steps = append(traversal.Steps, step)
+			traversal.CurrentNodeID = currentID
+			traversal.Completed = true
+			return traversal, nil
+		default:
+			return Traversal{}, fmt.Errorf("unsupported node type: %s", nodeType)
+		}
+
+		traversal.Steps = append(traversal.Steps, step)
+
+		if nodeType == node.TypeSection && stopAt != nil && stopAt(currentID) {
+			traversal.CurrentNodeID = currentID
+			return traversal, nil
+		}
+
+		if next == "" {
+			return Traversal{}, fmt.Errorf("%s node '%s' has no next node", nodeType, currentID)
+		}
+		currentID = next
+	}
+
+	return Traversal{}, fmt.Errorf("workflow traversal exceeded %d steps, the workflow may contain a cycle", maxSteps)
+}
+
+// evaluateCondition resolves a condition node against the answers.
+// Choice conditions match when the selected option is chosen (or, without a
+// choiceOptionId, when any selected option matches the pattern); non-choice
+// conditions match the pattern against the raw answer value.
+func evaluateCondition(n *node.ConditionNode, answers Answers) (ConditionEvaluation, error) {
+	nodeID := n.ID
+	if n.RuleError() != nil {
+		return ConditionEvaluation{}, fmt.Errorf("condition node '%s' has invalid conditionRule format: %w", nodeID, n.RuleError())
+	}
+	if n.ConditionRule == nil {
+		return ConditionEvaluation{}, fmt.Errorf("condition node '%s' must have a 'conditionRule' field", nodeID)
+	}
+	rule := *n.ConditionRule
+
+	value, answered := answers[rule.Key]
+	evaluation := ConditionEvaluation{
+		Source:         rule.Source,
+		QuestionID:     rule.Key,
+		ChoiceOptionID: rule.ChoiceOptionID,
+		Pattern:        rule.Pattern,
+		Value:          value,
+		Answered:       answered && strings.TrimSpace(value) != "",
+	}
+
+	if evaluation.Answered {
+		pattern, err := n.Pattern()
+		if err != nil {
+			return ConditionEvaluation{}, err
+		}
+
+		switch rule.Source {
+		case node.ConditionSourceChoice:
+			for _, selected := range strings.Split(value, ";") {
+				selected = strings.TrimSpace(selected)
+				if selected == "" {
+					continue
+				}
+				if rule.ChoiceOptionID != "" && selected == rule.ChoiceOptionID {
+					evaluation.Matched = true
+					break
+				}
+				if rule.ChoiceOptionID == "" && pattern.MatchString(selected) {
+					evaluation.Matched = true
+					break
+				}
+			}
+		case node.ConditionSourceNonChoice:
+			evaluation.Matched = pattern.MatchString(value)
+		default:
+			return ConditionEvaluation{}, fmt.Errorf("condition node '%s' has invalid conditionRule.source: '%s'", nodeID, rule.Source)
+		}
+	}
+
+	if evaluation.Matched {
+		evaluation.Next = n.NextTrue
+	} else {
+		evaluation.Next = n.NextFalse
+	}
+
+	return evaluation, nil
+}
@@ -0,0 +1,252 @@
+package workflow_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
+
+	"pgregory.net/rapid"
+)
+
+var (
+	propertyQuestionKeys = []string{"q1", "q2", "q3"}
+	propertyOptionIDs    = []string{"o1", "o2", "o3"}
+	propertyPatterns     = []string{".*", "^$", "^yes$", "o1", "^o[12]$", "[0-9]+", "(a|b)+"}
+	propertyReleaseTimes = []time.Time{
+		time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+)
+
+// generatedWorkflow is an acyclic workflow: every link of the node at index i points to
+// a node after it, the last one being the end node
+type generatedWorkflow struct {
+	json      []byte
+	nodeCount int
+	endID     string
+	types     map[string]string
+	approvals []string
+}
+
+func drawWorkflow(t *rapid.T) generatedWorkflow {
+	middle := rapid.IntRange(0, 8).Draw(t, "nodes")
+	ids := make([]string, middle+2)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("n%d", i)
+	}
+	startID, endID := ids[0], ids[len(ids)-1]
+
+	// later draws a link from the node at index i to any node after it
+	later := func(i int, label string) string {
+		return ids[rapid.IntRange(i+1, len(ids)-1).Draw(t, label)]
+	}
+
+	generated := generatedWorkflow{nodeCount: len(ids), endID: endID, types: map[string]string{startID: node.TypeStart, endID: node.TypeEnd}}
+	nodes := []map[string]interface{}{{"id": startID, "type": node.TypeStart, "label": "Start", "next": later(0, "start.next")}}
+
+	for i := 1; i <= middle; i++ {
+		id := ids[i]
+		nodeType := rapid.SampledFrom([]string{node.TypeSection, node.TypeCondition, node.TypeApproval, node.TypeDelay, node.TypeAction}).Draw(t, id+".type")
+		generated.types[id] = nodeType
+		n := map[string]interface{}{"id": id, "type": nodeType, "label": id}
+
+		switch nodeType {
+		case node.TypeCondition:
+			rule := map[string]interface{}{
+				"source":  rapid.SampledFrom([]string{string(node.ConditionSourceChoice), string(node.ConditionSourceNonChoice)}).Draw(t, id+".source"),
+				"nodeId":  startID,
+				"key":     rapid.SampledFrom(propertyQuestionKeys).Draw(t, id+".key"),
+				"pattern": rapid.SampledFrom(propertyPatterns).Draw(t, id+".pattern"),
+			}
+			if rapid.Bool().Draw(t, id+".byOption") {
+				rule["choiceOptionId"] = rapid.SampledFrom(propertyOptionIDs).Draw(t, id+".option")
+			}
+			n["conditionRule"] = rule
+			n["nextTrue"] = later(i, id+".nextTrue")
+			n["nextFalse"] = later(i, id+".nextFalse")
+		case node.TypeApproval:
+			n["approverUnitId"] = "unit"
+			n["next"] = later(i, id+".next")
+			generated.approvals = append(generated.approvals, id)
+		case node.TypeDelay:
+			n["releaseAt"] = rapid.SampledFrom(propertyReleaseTimes).Draw(t, id+".releaseAt").Format(time.RFC3339)
+			n["next"] = later(i, id+".next")
+		case node.TypeAction:
+			n["actionType"] = string(node.ActionTypeEvent)
+			n["event"] = "submitted"
+			n["next"] = later(i, id+".next")
+		default:
+			n["next"] = later(i, id+".next")
+		}
+		nodes = append(nodes, n)
+	}
+	nodes = append(nodes, map[string]interface{}{"id": endID, "type": node.TypeEnd, "label": "End"})
+
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		t.Fatalf("failed to encode workflow: %v", err)
+	}
+	generated.json = data
+	return generated
+}
+
+func drawAnswers(t *rapid.T) workflow.Answers {
+	answers := workflow.Answers{}
+	for _, key := range propertyQuestionKeys {
+		if !rapid.Bool().Draw(t, key+".answered") {
+			continue
+		}
+		answers[key] = rapid.OneOf(
+			rapid.SampledFrom([]string{"", " ", "yes", "no", "42", "abba"}),
+			rapid.Custom(func(t *rapid.T) string {
+				return strings.Join(rapid.SliceOfDistinct(rapid.SampledFrom(propertyOptionIDs), rapid.ID[string]).Draw(t, "options"), ";")
+			}),
+			rapid.String(),
+		).Draw(t, key+".value")
+	}
+	return answers
+}
+
+func drawApprovals(t *rapid.T, gates []string) workflow.Approvals {
+	approvals := workflow.Approvals{}
+	for _, gate := range gates {
+		approvals[gate] = rapid.SampledFrom([]workflow.ApprovalDecision{
+			workflow.ApprovalDecisionPending,
+			workflow.ApprovalDecisionApproved,
+			workflow.ApprovalDecisionRejected,
+		}).Draw(t, gate+".decision")
+	}
+	return approvals
+}
+
+func newPropertyRuntime(t *rapid.T, generated generatedWorkflow) *workflow.Runtime {
+	runtime, err := workflow.NewRuntime(generated.json)
+	if err != nil {
+		t.Fatalf("failed to create runtime for %s: %v", generated.json, err)
+	}
+	return runtime
+}
+
+func TestRuntimeProperty_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	rapid.Check(t, func(t *rapid.T) {
+		generated := drawWorkflow(t)
+		answers := drawAnswers(t)
+		approvals := drawApprovals(t, generated.approvals)
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		first, firstErr := newPropertyRuntime(t, generated).Traverse(answers, approvals, now, nil)
+		second, secondErr := newPropertyRuntime(t, generated).Traverse(answers, approvals, now, nil)
+
+		if (firstErr == nil) != (secondErr == nil) {
+			t.Fatalf("errors differ between runs: %v, %v", firstErr, secondErr)
+		}
+		firstJSON, _ := json.Marshal(first)
+		secondJSON, _ := json.Marshal(second)
+		if string(firstJSON) != string(secondJSON) {
+			t.Fatalf("traversals differ between runs:\n%s\n%s", firstJSON, secondJSON)
+		}
+	})
+}
+
+// With every gate approved and every delay released nothing can hold the respondent, so
+// each traversal has to reach the end node, visiting no node twice
+func TestRuntimeProperty_TerminatesAtEnd(t *testing.T) {
+	t.Parallel()
+
+	rapid.Check(t, func(t *rapid.T) {
+		generated := drawWorkflow(t)
+		answers := drawAnswers(t)
+		approvals := workflow.Approvals{}
+		for _, gate := range generated.approvals {
+			approvals[gate] = workflow.ApprovalDecisionApproved
+		}
+		now := propertyReleaseTimes[len(propertyReleaseTimes)-1]
+
+		traversal, err := newPropertyRuntime(t, generated).Traverse(answers, approvals, now, nil)
+		if err != nil {
+			t.Fatalf("traversal failed on %s: %v", generated.json, err)
+		}
+		if !traversal.Completed || traversal.CurrentNodeID != generated.endID {
+			t.Fatalf("traversal stopped at %q, not at the end node, on %s", traversal.CurrentNodeID, generated.json)
+		}
+		if len(traversal.Steps) > generated.nodeCount {
+			t.Fatalf("traversal took %d steps through %d nodes", len(traversal.Steps), generated.nodeCount)
+		}
+
+		visited := make(map[string]bool, len(traversal.Steps))
+		for _, step := range traversal.Steps {
+			if visited[step.NodeID] {
+				t.Fatalf("node %q visited twice on %s", step.NodeID, generated.json)
+			}
+			visited[step.NodeID] = true
+		}
+	})
+}
+
+// Every traversal follows the links of the workflow: a condition step continues on the
+// branch it evaluated to, and a traversal stopping early stops at a node able to hold
+// the respondent
+func TestRuntimeProperty_FollowsEvaluation(t *testing.T) {
+	t.Parallel()
+
+	rapid.Check(t, func(t *rapid.T) {
+		generated := drawWorkflow(t)
+		answers := drawAnswers(t)
+		approvals := drawApprovals(t, generated.approvals)
+		now := rapid.SampledFrom(propertyReleaseTimes).Draw(t, "now")
+		stopAtSections := rapid.Bool().Draw(t, "stopAtSections")
+
+		var stopAt func(string) bool
+		if stopAtSections {
+			stopAt = func(string) bool { return true }
+		}
+
+		traversal, err := newPropertyRuntime(t, generated).Traverse(answers, approvals, now, stopAt)
+		if err != nil {
+			t.Fatalf("traversal failed on %s: %v", generated.json, err)
+		}
+
+		for i, step := range traversal.Steps {
+			if step.Condition == nil || i == len(traversal.Steps)-1 {
+				continue
+			}
+			if traversal.Steps[i+1].NodeID != step.Condition.Next {
+				t.Fatalf("condition %q evaluated to %q but the traversal went to %q", step.NodeID, step.Condition.Next, traversal.Steps[i+1].NodeID)
+			}
+			if step.Condition.Matched && !step.Condition.Answered {
+				t.Fatalf("condition %q matched an unanswered question", step.NodeID)
+			}
+		}
+
+		last := traversal.Steps[len(traversal.Steps)-1]
+		if last.NodeID != traversal.CurrentNodeID {
+			t.Fatalf("traversal reports %q as current but its last step is %q", traversal.CurrentNodeID, last.NodeID)
+		}
+		if traversal.Completed {
+			return
+		}
+		switch generated.types[last.NodeID] {
+		case node.TypeSection:
+			if !stopAtSections {
+				t.Fatalf("traversal stopped at section %q without being asked to", last.NodeID)
+			}
+		case node.TypeApproval:
+			if last.Approval == nil || last.Approval.Decision == workflow.ApprovalDecisionApproved {
+				t.Fatalf("traversal stopped at approved gate %q", last.NodeID)
+			}
+		case node.TypeDelay:
+			if last.Delay == nil || last.Delay.Released {
+				t.Fatalf("traversal stopped at released delay %q", last.NodeID)
+			}
+		default:
+			t.Fatalf("traversal stopped at %s node %q", generated.types[last.NodeID], last.NodeID)
+		}
+	})
+}
@@ -0,0 +1,393 @@
+package workflow_test
+
+import (
+	"testing"
+	"time"
+
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// branchingWorkflow contains start -> section A -> condition -> (section B | section C) -> end
+type branchingWorkflow struct {
+	startID     string
+	sectionAID  string
+	conditionID string
+	sectionBID  string
+	sectionCID  string
+	endID       string
+	questionID  string
+	json        []byte
+}
+
+func createBranchingWorkflow(t *testing.T, source string, pattern string, choiceOptionID string) branchingWorkflow {
+	t.Helper()
+	w := branchingWorkflow{
+		startID:     uuid.New().String(),
+		sectionAID:  uuid.New().String(),
+		conditionID: uuid.New().String(),
+		sectionBID:  uuid.New().String(),
+		sectionCID:  uuid.New().String(),
+		endID:       uuid.New().String(),
+		questionID:  uuid.New().String(),
+	}
+
+	conditionRule := map[string]interface{}{
+		"source":  source,
+		"nodeId":  w.sectionAID,
+		"key":     w.questionID,
+		"pattern": pattern,
+	}
+	if choiceOptionID != "" {
+		conditionRule["choiceOptionId"] = choiceOptionID
+	}
+
+	w.json = createWorkflowJSON(t, []map[string]interface{}{
+		{"id": w.startID, "type": "start", "label": "Start", "next": w.sectionAID},
+		{"id": w.sectionAID, "type": "section", "label": "A", "next": w.conditionID},
+		{
+			"id":            w.conditionID,
+			"type":          "condition",
+			"label":         "Check",
+			"nextTrue":      w.sectionBID,
+			"nextFalse":     w.sectionCID,
+			"conditionRule": conditionRule,
+		},
+		{"id": w.sectionBID, "type": "section", "label": "B", "next": w.endID},
+		{"id": w.sectionCID, "type": "section", "label": "C", "next": w.endID},
+		{"id": w.endID, "type": "end", "label": "End"},
+	})
+	return w
+}
+
+func stepIDs(traversal workflow.Traversal) []string {
+	ids := make([]string, len(traversal.Steps))
+	for i, step := range traversal.Steps {
+		ids[i] = step.NodeID
+	}
+	return ids
+}
+
+func TestRuntime_Traverse(t *testing.T) {
+	t.Parallel()
+
+	optionID := uuid.New().String()
+
+	type testCase struct {
+		name        string
+		workflow    func(t *testing.T) branchingWorkflow
+		answers     func(w branchingWorkflow) workflow.Answers
+		wantBranch  func(w branchingWorkflow) string
+		wantMatched bool
+	}
+
+	testCases := []testCase{
+		{
+			name:        "non-choice answer matching pattern takes nextTrue",
+			workflow:    func(t *testing.T) branchingWorkflow { return createBranchingWorkflow(t, "nonChoice", "^yes$", "") },
+			answers:     func(w branchingWorkflow) workflow.Answers { return workflow.Answers{w.questionID: "yes"} },
+			wantBranch:  func(w branchingWorkflow) string { return w.sectionBID },
+			wantMatched: true,
+		},
+		{
+			name:        "non-choice answer not matching pattern takes nextFalse",
+			workflow:    func(t *testing.T) branchingWorkflow { return createBranchingWorkflow(t, "nonChoice", "^yes$", "") },
+			answers:     func(w branchingWorkflow) workflow.Answers { return workflow.Answers{w.questionID: "no"} },
+			wantBranch:  func(w branchingWorkflow) string { return w.sectionCID },
+			wantMatched: false,
+		},
+		{
+			name:        "unanswered question takes nextFalse",
+			workflow:    func(t *testing.T) branchingWorkflow { return createBranchingWorkflow(t, "nonChoice", ".*", "") },
+			answers:     func(w branchingWorkflow) workflow.Answers { return workflow.Answers{} },
+			wantBranch:  func(w branchingWorkflow) string { return w.sectionCID },
+			wantMatched: false,
+		},
+		{
+			name:     "choice answer containing the option takes nextTrue",
+			workflow: func(t *testing.T) branchingWorkflow { return createBranchingWorkflow(t, "choice", ".*", optionID) },
+			answers: func(w branchingWorkflow) workflow.Answers {
+				return workflow.Answers{w.questionID: uuid.New().String() + ";" + optionID}
+			},
+			wantBranch:  func(w branchingWorkflow) string { return w.sectionBID },
+			wantMatched: true,
+		},
+		{
+			name:     "choice answer without the option takes nextFalse",
+			workflow: func(t *testing.T) branchingWorkflow { return createBranchingWorkflow(t, "choice", ".*", optionID) },
+			answers: func(w branchingWorkflow) workflow.Answers {
+				return workflow.Answers{w.questionID: uuid.New().String()}
+			},
+			wantBranch:  func(w branchingWorkflow) string { return w.sectionCID },
+			wantMatched: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := tc.workflow(t)
+			runtime, err := workflow.NewRuntime(w.json)
+			require.NoError(t, err)
+
+			traversal, err := runtime.Traverse(tc.answers(w), nil, time.Now(), nil)
+			require.NoError(t, err)
+
+			require.True(t, traversal.Completed)
+			require.Equal(t, w.endID, traversal.CurrentNodeID)
+			require.Equal(t, []string{w.startID, w.sectionAID, w.conditionID, tc.wantBranch(w), w.endID}, stepIDs(traversal))
+
+			condition := traversal.Steps[2].Condition
+			require.NotNil(t, condition)
+			require.Equal(t, tc.wantMatched, condition.Matched)
+			require.Equal(t, tc.wantBranch(w), condition.Next)
+		})
+	}
+}
+
+func TestRuntime_TraverseStopsAtSection(t *testing.T) {
+	t.Parallel()
+
+	w := createBranchingWorkflow(t, "nonChoice", "^yes$", "")
+	runtime, err := workflow.NewRuntime(w.json)
+	require.NoError(t, err)
+
+	traversal, err := runtime.Traverse(workflow.Answers{}, nil, time.Now(), func(sectionID string) bool { return true })
+	require.NoError(t, err)
+
+	require.False(t, traversal.Completed)
+	require.Equal(t, w.sectionAID, traversal.CurrentNodeID)
+	require.Equal(t, []string{w.startID, w.sectionAID}, stepIDs(traversal))
+}
+
+func TestRuntime_TraverseApprovalGate(t *testing.T) {
+	t.Parallel()
+
+	startID := uuid.New().String()
+	approvalID := uuid.New().String()
+	sectionID := uuid.New().String()
+	endID := uuid.New().String()
+	approverUnitID := uuid.New().String()
+
+	workflowJSON := createWorkflowJSON(t, []map[string]interface{}{
+		{"id": startID, "type": "start", "label": "Start", "next": approvalID},
+		{"id": approvalID, "type": "approval", "label": "Review", "next": sectionID, "approverUnitId": approverUnitID},
+		{"id": sectionID, "type": "section", "label": "Details", "next": endID},
+		{"id": endID, "type": "end", "label": "End"},
+	})
+
+	type testCase struct {
+		name          string
+		approvals     workflow.Approvals
+		wantSteps     []string
+		wantDecision  workflow.ApprovalDecision
+		wantCompleted bool
+		wantAwaiting  bool
+	}
+
+	testCases := []testCase{
+		{
+			name:         "gate without a decision is pending",
+			approvals:    nil,
+			wantSteps:    []string{startID, approvalID},
+			wantDecision: workflow.ApprovalDecisionPending,
+			wantAwaiting: true,
+		},
+		{
+			name:         "rejected gate stops the traversal",
+			approvals:    workflow.Approvals{approvalID: workflow.ApprovalDecisionRejected},
+			wantSteps:    []string{startID, approvalID},
+			wantDecision: workflow.ApprovalDecisionRejected,
+			wantAwaiting: true,
+		},
+		{
+			name:          "approved gate continues to the end",
+			approvals:     workflow.Approvals{approvalID: workflow.ApprovalDecisionApproved},
+			wantSteps:     []string{startID, approvalID, sectionID, endID},
+			wantDecision:  workflow.ApprovalDecisionApproved,
+			wantCompleted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime, err := workflow.NewRuntime(workflowJSON)
+			require.NoError(t, err)
+
+			traversal, err := runtime.Traverse(workflow.Answers{}, tc.approvals, time.Now(), nil)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantSteps, stepIDs(traversal))
+			require.Equal(t, tc.wantCompleted, traversal.Completed)
+
+			approval := traversal.Steps[1].Approval
+			require.NotNil(t, approval)
+			require.Equal(t, approverUnitID, approval.ApproverUnitID)
+			require.Equal(t, tc.wantDecision, approval.Decision)
+
+			gate, ok := traversal.AwaitingApproval()
+			require.Equal(t, tc.wantAwaiting, ok)
+			if ok {
+				require.Equal(t, approvalID, gate.NodeID)
+				require.Equal(t, approvalID, traversal.CurrentNodeID)
+			}
+		})
+	}
+}
+
+func TestRuntime_TraverseDelay(t *testing.T) {
+	t.Parallel()
+
+	startID := uuid.New().String()
+	delayID := uuid.New().String()
+	sectionID := uuid.New().String()
+	endID := uuid.New().String()
+	releaseAt := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+	workflowJSON := createWorkflowJSON(t, []map[string]interface{}{
+		{"id": startID, "type": "start", "label": "Start", "next": delayID},
+		{"id": delayID, "type": "delay", "label": "Review period", "next": sectionID, "releaseAt": releaseAt.Format(time.RFC3339)},
+		{"id": sectionID, "type": "section", "label": "Results", "next": endID},
+		{"id": endID, "type": "end", "label": "End"},
+	})
+
+	type testCase struct {
+		name          string
+		now           time.Time
+		wantSteps     []string
+		wantReleased  bool
+		wantCompleted bool
+	}
+
+	testCases := []testCase{
+		{
+			name:      "before releaseAt stops at the delay node",
+			now:       releaseAt.Add(-time.Minute),
+			wantSteps: []string{startID, delayID},
+		},
+		{
+			name:          "at releaseAt continues to the end",
+			now:           releaseAt,
+			wantSteps:     []string{startID, delayID, sectionID, endID},
+			wantReleased:  true,
+			wantCompleted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime, err := workflow.NewRuntime(workflowJSON)
+			require.NoError(t, err)
+
+			traversal, err := runtime.Traverse(workflow.Answers{}, nil, tc.now, nil)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantSteps, stepIDs(traversal))
+			require.Equal(t, tc.wantCompleted, traversal.Completed)
+
+			delay := traversal.Steps[1].Delay
+			require.NotNil(t, delay)
+			require.True(t, releaseAt.Equal(delay.ReleaseAt))
+			require.Equal(t, tc.wantReleased, delay.Released)
+
+			step, ok := traversal.AwaitingRelease()
+			require.Equal(t, !tc.wantReleased, ok)
+			if ok {
+				require.Equal(t, delayID, step.NodeID)
+				require.Equal(t, delayID, traversal.CurrentNodeID)
+			}
+		})
+	}
+}
+
+func TestRuntime_TraverseAction(t *testing.T) {
+	t.Parallel()
+
+	startID := uuid.New().String()
+	actionID := uuid.New().String()
+	endID := uuid.New().String()
+	questionID := uuid.New().String()
+
+	workflowJSON := createWorkflowJSON(t, []map[string]interface{}{
+		{"id": startID, "type": "start", "label": "Start", "next": actionID},
+		{
+			"id":         actionID,
+			"type":       "action",
+			"label":      "Notify",
+			"next":       endID,
+			"actionType": "webhook",
+			"url":        "https://example.com/hooks/form",
+			"payload":    map[string]interface{}{"answer": "question:" + questionID},
+		},
+		{"id": endID, "type": "end", "label": "End"},
+	})
+
+	runtime, err := workflow.NewRuntime(workflowJSON)
+	require.NoError(t, err)
+
+	traversal, err := runtime.Traverse(workflow.Answers{}, nil, time.Now(), nil)
+	require.NoError(t, err)
+
+	require.True(t, traversal.Completed)
+	require.Equal(t, []string{startID, actionID, endID}, stepIDs(traversal))
+
+	action := traversal.Steps[1].Action
+	require.NotNil(t, action)
+	require.Equal(t, node.ActionTypeWebhook, action.ActionType)
+	require.Equal(t, "https://example.com/hooks/form", action.URL)
+	require.Equal(t, map[string]string{"answer": "question:" + questionID}, action.Payload)
+}
+
+func TestRuntime_InvalidWorkflow(t *testing.T) {
+	t.Parallel()
+
+	startID := uuid.New().String()
+	sectionID := uuid.New().String()
+
+	type testCase struct {
+		name     string
+		workflow []byte
+	}
+
+	testCases := []testCase{
+		{
+			name:     "invalid JSON",
+			workflow: []byte("{"),
+		},
+		{
+			name: "missing start node",
+			workflow: createWorkflowJSON(t, []map[string]interface{}{
+				{"id": uuid.New().String(), "type": "end", "label": "End"},
+			}),
+		},
+		{
+			name: "cycle between nodes",
+			workflow: createWorkflowJSON(t, []map[string]interface{}{
+				{"id": startID, "type": "start", "label": "Start", "next": sectionID},
+				{"id": sectionID, "type": "section", "label": "Loop", "next": sectionID},
+			}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			runtime, err := workflow.NewRuntime(tc.workflow)
+			if err != nil {
+				return
+			}
+
+			_, err = runtime.Traverse(workflow.Answers{}, nil, time.Now(), nil)
+			require.Error(t, err)
+		})
+	}
+}
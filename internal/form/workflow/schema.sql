@@ -3,7 +3,10 @@ CREATE TYPE node_type AS ENUM(
     'section',
     'end',
     'start',
-    'condition'
+    'condition',
+    'approval',
+    'delay',
+    'action'
 );
 
 CREATE TABLE IF NOT EXISTS workflow_versions (
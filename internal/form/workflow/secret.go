@@ -0,0 +1,186 @@
+package workflow
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+)
+
+// SecretBox encrypts the URLs of webhook action nodes at rest, since they often carry
+// the token of the service they post to
+type SecretBox interface {
+	Seal(value string) (string, error)
+	Open(value string) (string, error)
+	Current(value string) bool
+}
+
+// webhookURLs calls transform on the URL of every webhook action node of the workflow
+// and returns the workflow with the URLs it returned, the other nodes and fields as
+// they were. The workflow is returned as is when no URL changed.
+func webhookURLs(workflow []byte, transform func(nodeID string, url string) (string, error)) ([]byte, error) {
+	var nodes []map[string]json.RawMessage
+	err := json.Unmarshal(workflow, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+
+	changed := false
+	for _, n := range nodes {
+		stored := stringField(n, "url")
+		if NodeType(stringField(n, "type")) != NodeTypeAction || node.ActionType(stringField(n, "actionType")) != node.ActionTypeWebhook || stored == "" {
+			continue
+		}
+
+		url, err := transform(stringField(n, "id"), stored)
+		if err != nil {
+			return nil, err
+		}
+		if url == stored {
+			continue
+		}
+
+		n["url"], err = json.Marshal(url)
+		if err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+
+	if !changed {
+		return workflow, nil
+	}
+	return json.Marshal(nodes)
+}
+
+// stringField decodes a string field of a node, empty when it is missing or of another
+// type; nodes of the wrong shape are for the validator to report
+func stringField(n map[string]json.RawMessage, name string) string {
+	var value string
+	_ = json.Unmarshal(n[name], &value)
+	return value
+}
+
+// sealURLs encrypts the webhook URLs of a workflow for storage. A URL left as it was in
+// the stored workflow keeps the ciphertext stored there, so an unchanged workflow is
+// stored unchanged and activating it again is noticed as a no-op. The stored workflow
+// is only read for workflows with webhooks.
+func (s *Service) sealURLs(workflow []byte, stored func() ([]byte, error)) ([]byte, error) {
+	var sealedByNode map[string]map[string]string
+	return webhookURLs(workflow, func(nodeID string, url string) (string, error) {
+		if sealedByNode == nil {
+			previous, err := stored()
+			if err != nil {
+				return "", err
+			}
+			sealedByNode, err = s.sealedURLs(previous)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		sealed, ok := sealedByNode[nodeID][url]
+		if ok {
+			return sealed, nil
+		}
+
+		sealed, err := s.secretBox.Seal(url)
+		if err != nil {
+			return "", fmt.Errorf("failed to seal webhook url of node %s: %w", nodeID, err)
+		}
+		return sealed, nil
+	})
+}
+
+// sealedURLs maps the webhook URLs of a stored workflow to their ciphertext, by node.
+// URLs stored in the clear or with a key of before a rotation are left out, to be sealed
+// with the current key.
+func (s *Service) sealedURLs(workflow []byte) (map[string]map[string]string, error) {
+	sealedByNode := make(map[string]map[string]string)
+	if len(workflow) == 0 {
+		return sealedByNode, nil
+	}
+
+	_, err := webhookURLs(workflow, func(nodeID string, sealed string) (string, error) {
+		if !s.secretBox.Current(sealed) {
+			return sealed, nil
+		}
+		url, err := s.secretBox.Open(sealed)
+		if err != nil {
+			return sealed, nil
+		}
+
+		if sealedByNode[nodeID] == nil {
+			sealedByNode[nodeID] = make(map[string]string)
+		}
+		sealedByNode[nodeID][url] = sealed
+		return sealed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sealedByNode, nil
+}
+
+// openURLs decrypts the stored webhook URLs of a workflow
+func (s *Service) openURLs(workflow []byte) ([]byte, error) {
+	return webhookURLs(workflow, func(nodeID string, sealed string) (string, error) {
+		url, err := s.secretBox.Open(sealed)
+		if err != nil {
+			return "", fmt.Errorf("failed to open webhook url of node %s: %w", nodeID, err)
+		}
+		return url, nil
+	})
+}
+
+// RotateSecrets seals the webhook URLs stored in the clear or with a key of before a
+// rotation with the current key, in every workflow version
+func (s *Service) RotateSecrets(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "RotateSecrets")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	versions, err := s.queries.ListWebhookWorkflows(ctx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list webhook workflows")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	count := 0
+	for _, version := range versions {
+		sealedCount := 0
+		workflow, err := webhookURLs(version.Workflow, func(nodeID string, stored string) (string, error) {
+			if s.secretBox.Current(stored) {
+				return stored, nil
+			}
+
+			url, err := s.secretBox.Open(stored)
+			if err != nil {
+				return "", fmt.Errorf("failed to open webhook url of node %s in workflow version %s: %w", nodeID, version.ID, err)
+			}
+			sealedCount++
+			return s.secretBox.Seal(url)
+		})
+		if err != nil {
+			span.RecordError(err)
+			return count, err
+		}
+		if sealedCount == 0 {
+			continue
+		}
+
+		err = s.queries.UpdateVersionWorkflow(ctx, UpdateVersionWorkflowParams{Workflow: workflow, ID: version.ID})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow_versions", "id", version.ID.String(), logger, "seal webhook urls")
+			span.RecordError(err)
+			return count, err
+		}
+		count += sealedCount
+	}
+
+	return count, nil
+}
@@ -0,0 +1,307 @@
+package workflow_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/secrets"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/zap"
+)
+
+const webhookURL = "https://hooks.example.com/services/T000/B000/secret-token"
+
+// writeSecretsKey writes a fresh secrets key file
+func writeSecretsKey(t *testing.T) string {
+	t.Helper()
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "secrets.key")
+	require.NoError(t, os.WriteFile(file, []byte(base64.StdEncoding.EncodeToString(key)), 0o600))
+	return file
+}
+
+func newKeyring(t *testing.T, keyFiles ...string) *secrets.Keyring {
+	t.Helper()
+
+	keyring, err := secrets.NewKeyring(secrets.Config{KeyFiles: keyFiles})
+	require.NoError(t, err)
+	return keyring
+}
+
+// createWebhookWorkflow creates a workflow posting to the URL from its webhook node
+func createWebhookWorkflow(t *testing.T, webhookID uuid.UUID, url string) []byte {
+	t.Helper()
+	startID := uuid.New()
+	endID := uuid.New()
+	return createWorkflowJSON(t, []map[string]interface{}{
+		{"id": startID.String(), "type": "start", "label": "Start", "next": webhookID.String()},
+		{"id": webhookID.String(), "type": "action", "actionType": "webhook", "label": "Notify", "url": url, "next": endID.String()},
+		{"id": endID.String(), "type": "end", "label": "End"},
+	})
+}
+
+// storedURL reads the URL of the webhook node as stored
+func storedURL(t *testing.T, workflowJSON []byte, webhookID uuid.UUID) string {
+	t.Helper()
+
+	var nodes []map[string]interface{}
+	require.NoError(t, json.Unmarshal(workflowJSON, &nodes))
+	for _, n := range nodes {
+		if n["id"] == webhookID.String() {
+			url, _ := n["url"].(string)
+			return url
+		}
+	}
+	t.Fatalf("webhook node %s not found", webhookID)
+	return ""
+}
+
+func TestService_UpdateSealsWebhookURLs(t *testing.T) {
+	t.Parallel()
+
+	keyring := newKeyring(t, writeSecretsKey(t))
+	webhookID := uuid.New()
+	previouslySealed, err := keyring.Seal(webhookURL)
+	require.NoError(t, err)
+	otherSealed, err := keyring.Seal("https://hooks.example.com/services/T000/B000/old-token")
+	require.NoError(t, err)
+
+	type testCase struct {
+		name    string
+		current []byte
+		// expectSameCiphertext expects the ciphertext of the current workflow to be kept
+		expectSameCiphertext bool
+	}
+
+	testCases := []testCase{
+		{name: "First save"},
+		{name: "URL of a draft stored in the clear", current: createWebhookWorkflow(t, webhookID, webhookURL)},
+		{name: "Unchanged URL", current: createWebhookWorkflow(t, webhookID, previouslySealed), expectSameCiphertext: true},
+		{name: "Changed URL", current: createWebhookWorkflow(t, webhookID, otherSealed)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			formID := uuid.New()
+			workflowJSON := createWebhookWorkflow(t, webhookID, webhookURL)
+
+			mockQuerier := new(mockQuerier)
+			mockValidator := new(mockValidator)
+			mockValidator.On("Validate", mock.Anything, formID, workflowJSON, mock.Anything).Return(nil)
+			mockValidator.On("ValidateUpdateNodeIDs", mock.Anything, mock.Anything, workflowJSON).Return(nil)
+			mockValidator.On("Warnings", mock.Anything, workflowJSON).Return([]workflow.ValidationInfo{})
+			if tc.current == nil {
+				mockQuerier.On("Get", mock.Anything, formID).Return(workflow.GetRow{}, pgx.ErrNoRows)
+			} else {
+				mockQuerier.On("Get", mock.Anything, formID).Return(workflow.GetRow{FormID: formID, Workflow: tc.current}, nil)
+			}
+
+			var stored []byte
+			mockQuerier.On("Update", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(workflow.UpdateParams).Workflow
+			}).Return(workflow.UpdateRow{FormID: formID}, nil)
+
+			service := workflow.NewServiceForTesting(zap.NewNop(), noop.NewTracerProvider().Tracer("test"), mockQuerier, mockValidator, nil, keyring)
+			updated, _, err := service.Update(context.Background(), formID, workflowJSON, uuid.New())
+			require.NoError(t, err)
+
+			sealed := storedURL(t, stored, webhookID)
+			require.NotContains(t, string(stored), "secret-token")
+			require.True(t, keyring.Current(sealed))
+			opened, err := keyring.Open(sealed)
+			require.NoError(t, err)
+			require.Equal(t, webhookURL, opened)
+			if tc.expectSameCiphertext {
+				require.Equal(t, previouslySealed, sealed)
+			} else {
+				require.NotEqual(t, previouslySealed, sealed)
+			}
+
+			require.Equal(t, webhookURL, storedURL(t, updated.Workflow, webhookID))
+		})
+	}
+}
+
+func TestService_UpdateKeepsWorkflowsWithoutWebhooks(t *testing.T) {
+	t.Parallel()
+
+	formID := uuid.New()
+	workflowJSON := createSimpleValidWorkflow(t)
+
+	mockQuerier := new(mockQuerier)
+	mockValidator := new(mockValidator)
+	mockValidator.On("Validate", mock.Anything, formID, workflowJSON, mock.Anything).Return(nil)
+	mockValidator.On("ValidateUpdateNodeIDs", mock.Anything, mock.Anything, workflowJSON).Return(nil)
+	mockValidator.On("Warnings", mock.Anything, workflowJSON).Return([]workflow.ValidationInfo{})
+	mockQuerier.On("Get", mock.Anything, formID).Return(workflow.GetRow{}, pgx.ErrNoRows)
+	mockQuerier.On("Update", mock.Anything, workflow.UpdateParams{FormID: formID, LastEditor: uuid.Nil, Workflow: workflowJSON}).
+		Return(workflow.UpdateRow{FormID: formID, Workflow: workflowJSON}, nil)
+
+	service := workflow.NewServiceForTesting(zap.NewNop(), noop.NewTracerProvider().Tracer("test"), mockQuerier, mockValidator, nil, newKeyring(t, writeSecretsKey(t)))
+	_, _, err := service.Update(context.Background(), formID, workflowJSON, uuid.Nil)
+	require.NoError(t, err)
+	mockQuerier.AssertExpectations(t)
+}
+
+func TestService_GetOpensWebhookURLs(t *testing.T) {
+	t.Parallel()
+
+	keyring := newKeyring(t, writeSecretsKey(t))
+	sealed, err := keyring.Seal(webhookURL)
+	require.NoError(t, err)
+
+	type testCase struct {
+		name        string
+		stored      string
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{name: "Sealed URL", stored: sealed},
+		{name: "URL stored before encryption", stored: webhookURL},
+		{name: "URL sealed with a removed key", stored: "enc:v1:00000000:AAAA", expectedErr: secrets.ErrUnknownKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			formID := uuid.New()
+			webhookID := uuid.New()
+			mockQuerier := new(mockQuerier)
+			mockQuerier.On("Get", mock.Anything, formID).Return(workflow.GetRow{FormID: formID, Workflow: createWebhookWorkflow(t, webhookID, tc.stored)}, nil)
+
+			service := workflow.NewServiceForTesting(zap.NewNop(), noop.NewTracerProvider().Tracer("test"), mockQuerier, new(mockValidator), nil, keyring)
+			row, err := service.Get(context.Background(), formID)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, webhookURL, storedURL(t, row.Workflow, webhookID))
+		})
+	}
+}
+
+// TestService_ActivateKeepsSealedURLs activates the active workflow again, which has to
+// be stored byte for byte the same for the query to notice nothing changed
+func TestService_ActivateKeepsSealedURLs(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := writeSecretsKey(t), writeSecretsKey(t)
+	sealedWithOldKey, err := newKeyring(t, oldKey).Seal(webhookURL)
+	require.NoError(t, err)
+	keyring := newKeyring(t, newKey, oldKey)
+	sealed, err := keyring.Seal(webhookURL)
+	require.NoError(t, err)
+
+	type testCase struct {
+		name                 string
+		activeURL            string
+		noActive             bool
+		expectSameCiphertext bool
+	}
+
+	testCases := []testCase{
+		{name: "No active version", noActive: true},
+		{name: "Active with the same URL", activeURL: sealed, expectSameCiphertext: true},
+		{name: "Active with the URL sealed before a rotation", activeURL: sealedWithOldKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			formID := uuid.New()
+			webhookID := uuid.New()
+			workflowJSON := createWebhookWorkflow(t, webhookID, webhookURL)
+
+			mockQuerier := new(mockQuerier)
+			mockValidator := new(mockValidator)
+			mockValidator.On("Activate", mock.Anything, formID, workflowJSON, mock.Anything).Return(nil)
+			if tc.noActive {
+				mockQuerier.On("GetActive", mock.Anything, formID).Return(workflow.GetActiveRow{}, pgx.ErrNoRows)
+			} else {
+				mockQuerier.On("GetActive", mock.Anything, formID).Return(workflow.GetActiveRow{FormID: formID, Workflow: createWebhookWorkflow(t, webhookID, tc.activeURL)}, nil)
+			}
+
+			var stored []byte
+			mockQuerier.On("Activate", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(1).(workflow.ActivateParams).Workflow
+			}).Return(workflow.ActivateRow{FormID: formID, Workflow: createWebhookWorkflow(t, webhookID, sealed)}, nil)
+
+			service := workflow.NewServiceForTesting(zap.NewNop(), noop.NewTracerProvider().Tracer("test"), mockQuerier, mockValidator, nil, keyring)
+			activated, err := service.Activate(context.Background(), formID, uuid.New(), workflowJSON)
+			require.NoError(t, err)
+			require.Equal(t, webhookURL, storedURL(t, activated.Workflow, webhookID))
+
+			storedSealed := storedURL(t, stored, webhookID)
+			require.True(t, keyring.Current(storedSealed))
+			if tc.expectSameCiphertext {
+				require.Equal(t, sealed, storedSealed)
+			} else {
+				require.NotEqual(t, sealed, storedSealed)
+			}
+		})
+	}
+}
+
+func TestService_RotateSecrets(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := writeSecretsKey(t), writeSecretsKey(t)
+	sealedWithOldKey, err := newKeyring(t, oldKey).Seal(webhookURL)
+	require.NoError(t, err)
+	keyring := newKeyring(t, newKey, oldKey)
+	sealed, err := keyring.Seal(webhookURL)
+	require.NoError(t, err)
+
+	webhookID := uuid.New()
+	oldVersion := workflow.ListWebhookWorkflowsRow{ID: uuid.New(), Workflow: createWebhookWorkflow(t, webhookID, sealedWithOldKey)}
+	legacyVersion := workflow.ListWebhookWorkflowsRow{ID: uuid.New(), Workflow: createWebhookWorkflow(t, webhookID, webhookURL)}
+	currentVersion := workflow.ListWebhookWorkflowsRow{ID: uuid.New(), Workflow: createWebhookWorkflow(t, webhookID, sealed)}
+
+	mockQuerier := new(mockQuerier)
+	mockQuerier.On("ListWebhookWorkflows", mock.Anything).Return([]workflow.ListWebhookWorkflowsRow{oldVersion, legacyVersion, currentVersion}, nil)
+	updated := make(map[uuid.UUID][]byte)
+	mockQuerier.On("UpdateVersionWorkflow", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		params := args.Get(1).(workflow.UpdateVersionWorkflowParams)
+		updated[params.ID] = params.Workflow
+	}).Return(nil)
+
+	service := workflow.NewServiceForTesting(zap.NewNop(), noop.NewTracerProvider().Tracer("test"), mockQuerier, new(mockValidator), nil, keyring)
+	count, err := service.RotateSecrets(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	require.Len(t, updated, 2)
+	require.NotContains(t, updated, currentVersion.ID)
+
+	// The old key can go once the versions are sealed again
+	retired := newKeyring(t, newKey)
+	for _, id := range []uuid.UUID{oldVersion.ID, legacyVersion.ID} {
+		require.Contains(t, updated, id)
+		resealed := storedURL(t, updated[id], webhookID)
+		require.True(t, retired.Current(resealed))
+		opened, err := retired.Open(resealed)
+		require.NoError(t, err)
+		require.Equal(t, webhookURL, opened)
+	}
+}
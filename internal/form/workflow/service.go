@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -18,10 +20,15 @@ import (
 
 type Querier interface {
 	Get(ctx context.Context, formID uuid.UUID) (GetRow, error)
+	GetActive(ctx context.Context, formID uuid.UUID) (GetActiveRow, error)
+	GetVersion(ctx context.Context, arg GetVersionParams) (WorkflowVersion, error)
+	ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error)
 	Update(ctx context.Context, arg UpdateParams) (UpdateRow, error)
 	CreateNode(ctx context.Context, arg CreateNodeParams) (CreateNodeRow, error)
 	DeleteNode(ctx context.Context, arg DeleteNodeParams) ([]byte, error)
 	Activate(ctx context.Context, arg ActivateParams) (ActivateRow, error)
+	ListWebhookWorkflows(ctx context.Context) ([]ListWebhookWorkflowsRow, error)
+	UpdateVersionWorkflow(ctx context.Context, arg UpdateVersionWorkflowParams) error
 }
 
 type Validator interface {
@@ -29,6 +36,7 @@ type Validator interface {
 	Validate(ctx context.Context, formID uuid.UUID, workflow []byte, questionStore QuestionStore) error
 	ValidateNodeIDsUnchanged(ctx context.Context, currentWorkflow, newWorkflow []byte) error
 	ValidateUpdateNodeIDs(ctx context.Context, currentWorkflow []byte, newWorkflow []byte) error
+	Warnings(ctx context.Context, workflow []byte) []ValidationInfo
 }
 
 type Service struct {
@@ -37,27 +45,33 @@ type Service struct {
 	tracer        trace.Tracer
 	validator     Validator
 	questionStore QuestionStore
+	secretBox     SecretBox
+	active        *activeCache
 }
 
-func NewService(logger *zap.Logger, db DBTX, questionService QuestionStore) *Service {
+func NewService(logger *zap.Logger, db DBTX, questionService QuestionStore, secretBox SecretBox) *Service {
 	return &Service{
 		logger:        logger,
 		queries:       New(db),
 		tracer:        otel.Tracer("workflow/service"),
 		validator:     NewValidator(),
 		questionStore: questionService,
+		secretBox:     secretBox,
+		active:        newActiveCache(logger, DefaultActiveCacheTTL),
 	}
 }
 
 // NewServiceForTesting creates a Service with injected dependencies for testing.
 // This allows unit tests to mock the Querier and Validator interfaces.
-func NewServiceForTesting(logger *zap.Logger, tracer trace.Tracer, queries Querier, validator Validator, questionStore QuestionStore) *Service {
+func NewServiceForTesting(logger *zap.Logger, tracer trace.Tracer, queries Querier, validator Validator, questionStore QuestionStore, secretBox SecretBox) *Service {
 	return &Service{
 		logger:        logger,
 		queries:       queries,
 		tracer:        tracer,
 		validator:     validator,
 		questionStore: questionStore,
+		secretBox:     secretBox,
+		active:        newActiveCache(logger, DefaultActiveCacheTTL),
 	}
 }
 
@@ -75,13 +89,147 @@ func (s *Service) Get(ctx context.Context, formID uuid.UUID) (GetRow, error) {
 		return GetRow{}, err
 	}
 
+	workflow.Workflow, err = s.openURLs(workflow.Workflow)
+	if err != nil {
+		span.RecordError(err)
+		return GetRow{}, err
+	}
+
 	return workflow, nil
 }
 
+// GetActive retrieves the active workflow version for a form, which is the one respondents go through.
+// The version is served from memory for up to DefaultActiveCacheTTL, or until the
+// workflow of the form is written through the service.
+func (s *Service) GetActive(ctx context.Context, formID uuid.UUID) (GetActiveRow, error) {
+	methodName := "GetActive"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+
+	entry, err := s.activeEntry(ctx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return GetActiveRow{}, err
+	}
+
+	return entry.row, nil
+}
+
+// ActiveRuntime returns the runtime of the active workflow of a form. The workflow is
+// parsed once per active version.
+func (s *Service) ActiveRuntime(ctx context.Context, formID uuid.UUID) (*Runtime, error) {
+	methodName := "ActiveRuntime"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+
+	entry, err := s.activeEntry(ctx, formID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	runtime, err := s.active.runtime(entry)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return runtime, nil
+}
+
+func (s *Service) activeEntry(ctx context.Context, formID uuid.UUID) (*activeEntry, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	now := time.Now()
+	entry, generation, ok := s.active.get(ctx, formID, now)
+	if ok {
+		return entry, nil
+	}
+
+	workflow, err := s.queries.GetActive(ctx, formID)
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "get active workflow by form id")
+	}
+
+	// Cached with the webhook URLs opened, for the actions to post to
+	workflow.Workflow, err = s.openURLs(workflow.Workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.active.put(formID, workflow, generation, now), nil
+}
+
+// ListVersions lists the workflow versions of a form, newest first, without their workflow content
+func (s *Service) ListVersions(ctx context.Context, formID uuid.UUID) ([]ListVersionsRow, error) {
+	methodName := "ListVersions"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	versions, err := s.queries.ListVersions(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "list workflow versions by form id")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// Diff compares two workflow versions of a form, reporting the nodes and edges
+// that were added, removed or changed going from one version to the other
+func (s *Service) Diff(ctx context.Context, formID uuid.UUID, fromVersionID uuid.UUID, toVersionID uuid.UUID) (Diff, error) {
+	methodName := "Diff"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	from, err := s.queries.GetVersion(ctx, GetVersionParams{FormID: formID, ID: fromVersionID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "versionId", fromVersionID.String(), logger, "get workflow version")
+		span.RecordError(err)
+		return Diff{}, err
+	}
+
+	to, err := s.queries.GetVersion(ctx, GetVersionParams{FormID: formID, ID: toVersionID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "versionId", toVersionID.String(), logger, "get workflow version")
+		span.RecordError(err)
+		return Diff{}, err
+	}
+
+	fromWorkflow, err := s.openURLs(from.Workflow)
+	if err != nil {
+		span.RecordError(err)
+		return Diff{}, err
+	}
+	toWorkflow, err := s.openURLs(to.Workflow)
+	if err != nil {
+		span.RecordError(err)
+		return Diff{}, err
+	}
+
+	diff, err := DiffWorkflows(fromWorkflow, toWorkflow)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return Diff{}, err
+	}
+
+	diff.FromVersionID = fromVersionID.String()
+	diff.ToVersionID = toVersionID.String()
+
+	return diff, nil
+}
+
 // Update updates a workflow version conditionally:
 // - If latest workflow is active: creates a new workflow version
 // - If latest workflow is draft: updates the existing workflow version
-func (s *Service) Update(ctx context.Context, formID uuid.UUID, workflow []byte, userID uuid.UUID) (UpdateRow, error) {
+// Only errors reject the update. The warnings of the saved workflow are returned
+// alongside it so the editor can show them.
+func (s *Service) Update(ctx context.Context, formID uuid.UUID, workflow []byte, userID uuid.UUID) (UpdateRow, []ValidationInfo, error) {
 	methodName := "Update"
 	ctx, span := s.tracer.Start(ctx, methodName)
 	defer span.End()
@@ -97,7 +245,7 @@ func (s *Service) Update(ctx context.Context, formID uuid.UUID, workflow []byte,
 		// Wrap validation error to return 400 instead of 500
 		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
 		span.RecordError(err)
-		return UpdateRow{}, err
+		return UpdateRow{}, nil, err
 	}
 
 	// Get current workflow to validate node IDs haven't changed
@@ -107,7 +255,7 @@ func (s *Service) Update(ctx context.Context, formID uuid.UUID, workflow []byte,
 		if !errors.Is(err, pgx.ErrNoRows) {
 			err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "get current workflow")
 			span.RecordError(err)
-			return UpdateRow{}, err
+			return UpdateRow{}, nil, err
 		}
 		// First update scenario: no existing workflow to compare against
 	}
@@ -122,21 +270,30 @@ func (s *Service) Update(ctx context.Context, formID uuid.UUID, workflow []byte,
 	if err := s.validator.ValidateUpdateNodeIDs(ctx, currentWorkflowBytes, workflow); err != nil {
 		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
 		span.RecordError(err)
-		return UpdateRow{}, err
+		return UpdateRow{}, nil, err
+	}
+
+	sealed, err := s.sealURLs(workflow, func() ([]byte, error) { return currentWorkflowBytes, nil })
+	if err != nil {
+		span.RecordError(err)
+		return UpdateRow{}, nil, err
 	}
 
 	updated, err := s.queries.Update(ctx, UpdateParams{
 		FormID:     formID,
 		LastEditor: userID,
-		Workflow:   workflow,
+		Workflow:   sealed,
 	})
 	if err != nil {
 		err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "update workflow")
 		span.RecordError(err)
-		return UpdateRow{}, err
+		return UpdateRow{}, nil, err
 	}
+	s.active.invalidate(formID)
+
+	updated.Workflow = workflow
 
-	return updated, nil
+	return updated, s.validator.Warnings(ctx, workflow), nil
 }
 
 func (s *Service) CreateNode(ctx context.Context, formID uuid.UUID, nodeType NodeType, userID uuid.UUID) (CreateNodeRow, error) {
@@ -149,6 +306,9 @@ func (s *Service) CreateNode(ctx context.Context, formID uuid.UUID, nodeType Nod
 	switch nodeType {
 	case NodeTypeSection:
 	case NodeTypeCondition:
+	case NodeTypeApproval:
+	case NodeTypeDelay:
+	case NodeTypeAction:
 		break
 	default:
 		err := fmt.Errorf("invalid node type: %s", nodeType)
@@ -166,6 +326,13 @@ func (s *Service) CreateNode(ctx context.Context, formID uuid.UUID, nodeType Nod
 		span.RecordError(err)
 		return CreateNodeRow{}, err
 	}
+	s.active.invalidate(formID)
+
+	createdRow.Workflow, err = s.openURLs(createdRow.Workflow)
+	if err != nil {
+		span.RecordError(err)
+		return CreateNodeRow{}, err
+	}
 
 	// Validate created workflow (relaxed draft validation)
 	if err := s.validator.Validate(ctx, formID, createdRow.Workflow, s.questionStore); err != nil {
@@ -193,6 +360,13 @@ func (s *Service) DeleteNode(ctx context.Context, formID uuid.UUID, nodeID uuid.
 		span.RecordError(err)
 		return []byte{}, err
 	}
+	s.active.invalidate(formID)
+
+	deleted, err = s.openURLs(deleted)
+	if err != nil {
+		span.RecordError(err)
+		return []byte{}, err
+	}
 
 	// Validate deleted workflow (relaxed draft validation)
 	if err := s.validator.Validate(ctx, formID, deleted, s.questionStore); err != nil {
@@ -219,35 +393,117 @@ func (s *Service) Activate(ctx context.Context, formID uuid.UUID, userID uuid.UU
 		return ActivateRow{}, err
 	}
 
+	// The active version is compared against to skip activating it again, which needs
+	// its unchanged webhook URLs sealed the same
+	sealed, err := s.sealURLs(workflow, func() ([]byte, error) {
+		active, err := s.queries.GetActive(ctx, formID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "get active workflow by form id")
+		}
+		return active.Workflow, nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return ActivateRow{}, err
+	}
+
 	activatedVersion, err := s.queries.Activate(ctx, ActivateParams{
 		FormID:     formID,
 		LastEditor: userID,
-		Workflow:   workflow,
+		Workflow:   sealed,
 	})
 	if err != nil {
 		err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "activate workflow")
 		span.RecordError(err)
 		return ActivateRow{}, err
 	}
+	s.active.invalidate(formID)
+
+	activatedVersion.Workflow, err = s.openURLs(activatedVersion.Workflow)
+	if err != nil {
+		span.RecordError(err)
+		return ActivateRow{}, err
+	}
 
 	return activatedVersion, nil
 }
 
-// GetValidationInfo checks if a workflow can be activated and returns detailed validation errors.
-// Returns an empty slice if validation passes, or an array of ValidationInfo with node-specific errors.
+// GetValidationInfo checks if a workflow can be activated and returns detailed validation findings.
+// Errors that block activation come first, followed by the draft warnings and infos.
+// A finding that blocks activation is only reported once, as an error.
+// Returns an empty slice if the workflow has no findings.
 func (s *Service) GetValidationInfo(ctx context.Context, formID uuid.UUID, workflow []byte) ([]ValidationInfo, error) {
 	methodName := "GetValidationInfo"
 	ctx, span := s.tracer.Start(ctx, methodName)
 	defer span.End()
 
+	validationInfos := []ValidationInfo{}
+
 	// Call the validator's Activate method
 	err := s.validator.Activate(ctx, formID, workflow, s.questionStore)
-	if err == nil {
-		// Validation passed
-		return []ValidationInfo{}, nil
+	if err != nil {
+		// Parse the validation errors
+		validationInfos = append(validationInfos, parseValidationErrors(err)...)
+	}
+
+	reported := make(map[string]bool, len(validationInfos))
+	for _, info := range validationInfos {
+		reported[info.Message] = true
+	}
+	for _, warning := range s.validator.Warnings(ctx, workflow) {
+		if !reported[warning.Message] {
+			validationInfos = append(validationInfos, warning)
+		}
 	}
 
-	// Parse the validation errors
-	validationInfos := parseValidationErrors(err)
 	return validationInfos, nil
 }
+
+// Simulate runs the latest workflow version of a form against hypothetical answers
+// and returns the full traversal path from the start node to the end node, or to
+// the first approval gate without an approved decision, or to the first delay node
+// not yet released at the given time.
+// Nothing is persisted, so authors can test branching on a draft workflow.
+func (s *Service) Simulate(ctx context.Context, formID uuid.UUID, answers []shared.AnswerParam, approvals Approvals, at time.Time) (Traversal, error) {
+	methodName := "Simulate"
+	ctx, span := s.tracer.Start(ctx, methodName)
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	latest, err := s.queries.Get(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "workflow", "formId", formID.String(), logger, "get workflow by form id")
+		span.RecordError(err)
+		return Traversal{}, err
+	}
+
+	latest.Workflow, err = s.openURLs(latest.Workflow)
+	if err != nil {
+		span.RecordError(err)
+		return Traversal{}, err
+	}
+
+	runtime, err := NewRuntime(latest.Workflow)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return Traversal{}, err
+	}
+
+	hypothetical := make(Answers, len(answers))
+	for _, answer := range answers {
+		hypothetical[answer.QuestionID] = answer.Value
+	}
+
+	traversal, err := runtime.Traverse(hypothetical, approvals, at, nil)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", internal.ErrWorkflowValidationFailed, err)
+		span.RecordError(err)
+		return Traversal{}, err
+	}
+
+	return traversal, nil
+}
@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
+	"NYCU-SDC/core-system-backend/internal/form/shared"
 	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/secrets"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
@@ -27,6 +30,21 @@ func (m *mockQuerier) Get(ctx context.Context, formID uuid.UUID) (workflow.GetRo
 	return args.Get(0).(workflow.GetRow), args.Error(1)
 }
 
+func (m *mockQuerier) GetActive(ctx context.Context, formID uuid.UUID) (workflow.GetActiveRow, error) {
+	args := m.Called(ctx, formID)
+	return args.Get(0).(workflow.GetActiveRow), args.Error(1)
+}
+
+func (m *mockQuerier) GetVersion(ctx context.Context, arg workflow.GetVersionParams) (workflow.WorkflowVersion, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(workflow.WorkflowVersion), args.Error(1)
+}
+
+func (m *mockQuerier) ListVersions(ctx context.Context, formID uuid.UUID) ([]workflow.ListVersionsRow, error) {
+	args := m.Called(ctx, formID)
+	return args.Get(0).([]workflow.ListVersionsRow), args.Error(1)
+}
+
 func (m *mockQuerier) Update(ctx context.Context, arg workflow.UpdateParams) (workflow.UpdateRow, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(workflow.UpdateRow), args.Error(1)
@@ -47,6 +65,16 @@ func (m *mockQuerier) Activate(ctx context.Context, arg workflow.ActivateParams)
 	return args.Get(0).(workflow.ActivateRow), args.Error(1)
 }
 
+func (m *mockQuerier) ListWebhookWorkflows(ctx context.Context) ([]workflow.ListWebhookWorkflowsRow, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]workflow.ListWebhookWorkflowsRow), args.Error(1)
+}
+
+func (m *mockQuerier) UpdateVersionWorkflow(ctx context.Context, arg workflow.UpdateVersionWorkflowParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 // mockValidator is a mock implementation of workflow.Validator interface
 type mockValidator struct {
 	mock.Mock
@@ -72,10 +100,15 @@ func (m *mockValidator) ValidateUpdateNodeIDs(ctx context.Context, currentWorkfl
 	return args.Error(0)
 }
 
+func (m *mockValidator) Warnings(ctx context.Context, workflowJSON []byte) []workflow.ValidationInfo {
+	args := m.Called(ctx, workflowJSON)
+	return args.Get(0).([]workflow.ValidationInfo)
+}
+
 // createTestService creates a workflow.Service with mocked dependencies
 func createTestService(t *testing.T, logger *zap.Logger, tracer trace.Tracer, mockQuerier *mockQuerier, mockValidator *mockValidator, questionStore workflow.QuestionStore) *workflow.Service {
 	t.Helper()
-	return workflow.NewServiceForTesting(logger, tracer, mockQuerier, mockValidator, questionStore)
+	return workflow.NewServiceForTesting(logger, tracer, mockQuerier, mockValidator, questionStore, secrets.NewDevKeyring("test-secret"))
 }
 
 func TestService_Activate(t *testing.T) {
@@ -156,9 +189,10 @@ func TestService_Update(t *testing.T) {
 	}
 
 	type testCase struct {
-		name      string
-		params    Params
-		expectErr bool
+		name           string
+		params         Params
+		expectErr      bool
+		expectWarnings int
 	}
 
 	testCases := []testCase{
@@ -169,6 +203,14 @@ func TestService_Update(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "unreachable node is saved with a warning",
+			params: Params{
+				workflowJSON: createWorkflowWithUnreachableNode(t),
+			},
+			expectErr:      false,
+			expectWarnings: 1,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -183,7 +225,7 @@ func TestService_Update(t *testing.T) {
 
 			mockQuerier := new(mockQuerier)
 			realValidator := workflow.NewValidator()
-			service := workflow.NewServiceForTesting(logger, tracer, mockQuerier, realValidator, nil)
+			service := workflow.NewServiceForTesting(logger, tracer, mockQuerier, realValidator, nil, secrets.NewDevKeyring("test-secret"))
 
 			workflowJSON := tc.params.workflowJSON
 			expectedRow := workflow.UpdateRow{
@@ -207,7 +249,7 @@ func TestService_Update(t *testing.T) {
 				Workflow:   workflowJSON,
 			}).Return(expectedRow, nil).Once()
 
-			result, err := service.Update(ctx, formID, workflowJSON, userID)
+			result, warnings, err := service.Update(ctx, formID, workflowJSON, userID)
 
 			if tc.expectErr {
 				require.Error(t, err, "expected error but got nil")
@@ -215,6 +257,10 @@ func TestService_Update(t *testing.T) {
 			} else {
 				require.NoError(t, err, "unexpected error: %v", err)
 				require.Equal(t, expectedRow, result)
+				require.Len(t, warnings, tc.expectWarnings)
+				for _, warning := range warnings {
+					require.Equal(t, workflow.ValidationSeverityWarning, warning.Severity)
+				}
 				mockQuerier.AssertExpectations(t)
 			}
 		})
@@ -306,7 +352,7 @@ func TestService_CreateNode(t *testing.T) {
 			mockQuerier := new(mockQuerier)
 			realValidator := workflow.NewValidator()
 
-			service := workflow.NewServiceForTesting(logger, tracer, mockQuerier, realValidator, tc.params.questionStore)
+			service := workflow.NewServiceForTesting(logger, tracer, mockQuerier, realValidator, tc.params.questionStore, secrets.NewDevKeyring("test-secret"))
 
 			// Only set up mock if node type is valid (service will call querier)
 			// Note: CreateNode calls the querier BEFORE validation, so we need to set up the mock
@@ -444,7 +490,7 @@ func TestService_DeleteNode(t *testing.T) {
 			mockQuerier := new(mockQuerier)
 			realValidator := workflow.NewValidator()
 
-			service := workflow.NewServiceForTesting(logger, tracer, mockQuerier, realValidator, tc.params.questionStore)
+			service := workflow.NewServiceForTesting(logger, tracer, mockQuerier, realValidator, tc.params.questionStore, secrets.NewDevKeyring("test-secret"))
 
 			workflowJSON := tc.params.workflowJSON
 
@@ -480,6 +526,8 @@ func TestService_GetWorkflow_ValidationErrors(t *testing.T) {
 		setupMock       func(*mockValidator, uuid.UUID, []byte)
 		expectedInfoLen int
 		expectedErr     bool
+		// expectedSeverities, when set, is the severity of each returned info in order
+		expectedSeverities []workflow.ValidationSeverity
 	}
 
 	testCases := []testCase{
@@ -487,8 +535,9 @@ func TestService_GetWorkflow_ValidationErrors(t *testing.T) {
 			name:         "validation passes - returns empty info array",
 			formID:       uuid.New(),
 			workflowJSON: createSimpleValidWorkflow(t),
-			setupMock: func(mv *mockValidator, formID uuid.UUID, workflow []byte) {
-				mv.On("Activate", mock.Anything, formID, workflow, mock.Anything).Return(nil).Once()
+			setupMock: func(mv *mockValidator, formID uuid.UUID, workflowJSON []byte) {
+				mv.On("Activate", mock.Anything, formID, workflowJSON, mock.Anything).Return(nil).Once()
+				mv.On("Warnings", mock.Anything, workflowJSON).Return([]workflow.ValidationInfo{}).Once()
 			},
 			expectedInfoLen: 0,
 			expectedErr:     false,
@@ -497,14 +546,15 @@ func TestService_GetWorkflow_ValidationErrors(t *testing.T) {
 			name:         "parsing - nested joined errors",
 			formID:       uuid.New(),
 			workflowJSON: createSimpleValidWorkflow(t),
-			setupMock: func(mv *mockValidator, formID uuid.UUID, workflow []byte) {
+			setupMock: func(mv *mockValidator, formID uuid.UUID, workflowJSON []byte) {
 				startID := uuid.New()
 				err1 := fmt.Errorf("start node '%s' must have a 'next' field", startID.String())
 				err2 := fmt.Errorf("workflow must contain exactly one start node, found 0")
 				err3 := fmt.Errorf("workflow must contain exactly one end node, found 0")
 				innerErr := errors.Join(err2, err3)
 				outerErr := fmt.Errorf("workflow validation failed: %w", errors.Join(err1, innerErr))
-				mv.On("Activate", mock.Anything, formID, workflow, mock.Anything).Return(outerErr).Once()
+				mv.On("Activate", mock.Anything, formID, workflowJSON, mock.Anything).Return(outerErr).Once()
+				mv.On("Warnings", mock.Anything, workflowJSON).Return([]workflow.ValidationInfo{}).Once()
 			},
 			expectedInfoLen: 3, // 3 lines: 1 with node ID, 2 without
 			expectedErr:     false,
@@ -513,18 +563,38 @@ func TestService_GetWorkflow_ValidationErrors(t *testing.T) {
 			name:         "parsing - multiple unreachable nodes with individual node IDs",
 			formID:       uuid.New(),
 			workflowJSON: createSimpleValidWorkflow(t),
-			setupMock: func(mv *mockValidator, formID uuid.UUID, workflow []byte) {
+			setupMock: func(mv *mockValidator, formID uuid.UUID, workflowJSON []byte) {
 				unreachableID1 := uuid.New()
 				unreachableID2 := uuid.New()
 				err1 := fmt.Errorf("node '%s' is unreachable from the start node", unreachableID1.String())
 				err2 := fmt.Errorf("node '%s' is unreachable from the start node", unreachableID2.String())
 				graphErr := fmt.Errorf("graph validation failed: %w", errors.Join(err1, err2))
 				outerErr := fmt.Errorf("workflow validation failed: %w", graphErr)
-				mv.On("Activate", mock.Anything, formID, workflow, mock.Anything).Return(outerErr).Once()
+				mv.On("Activate", mock.Anything, formID, workflowJSON, mock.Anything).Return(outerErr).Once()
+				mv.On("Warnings", mock.Anything, workflowJSON).Return([]workflow.ValidationInfo{}).Once()
 			},
 			expectedInfoLen: 2, // 2 unique node IDs, each gets its own ValidationInfo with the same full message
 			expectedErr:     false,
 		},
+		{
+			name:         "warnings - blocking finding reported once as error, infos appended",
+			formID:       uuid.New(),
+			workflowJSON: createSimpleValidWorkflow(t),
+			setupMock: func(mv *mockValidator, formID uuid.UUID, workflowJSON []byte) {
+				unreachableID := uuid.New().String()
+				delayID := uuid.New().String()
+				message := fmt.Sprintf("node '%s' is unreachable from the start node", unreachableID)
+				graphErr := fmt.Errorf("graph validation failed: %w", errors.New(message))
+				mv.On("Activate", mock.Anything, formID, workflowJSON, mock.Anything).Return(fmt.Errorf("workflow validation failed: %w", graphErr)).Once()
+				mv.On("Warnings", mock.Anything, workflowJSON).Return([]workflow.ValidationInfo{
+					{Type: workflow.ValidationTypeGraph, Severity: workflow.ValidationSeverityWarning, NodeID: &unreachableID, Message: message},
+					{Type: workflow.ValidationTypeNode, Severity: workflow.ValidationSeverityInfo, NodeID: &delayID, Message: fmt.Sprintf("delay node '%s' releaseAt has already passed", delayID)},
+				}).Once()
+			},
+			expectedInfoLen:    2,
+			expectedErr:        false,
+			expectedSeverities: []workflow.ValidationSeverity{workflow.ValidationSeverityError, workflow.ValidationSeverityInfo},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -558,6 +628,14 @@ func TestService_GetWorkflow_ValidationErrors(t *testing.T) {
 					}
 					require.NotEmpty(t, info.Message)
 				}
+
+				if tc.expectedSeverities != nil {
+					severities := make([]workflow.ValidationSeverity, len(validationInfos))
+					for i, info := range validationInfos {
+						severities[i] = info.Severity
+					}
+					require.Equal(t, tc.expectedSeverities, severities)
+				}
 			}
 
 			mockValidator.AssertExpectations(t)
@@ -565,6 +643,125 @@ func TestService_GetWorkflow_ValidationErrors(t *testing.T) {
 	}
 }
 
+func TestService_Simulate(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name       string
+		answer     string
+		wantBranch func(w branchingWorkflow) string
+	}
+
+	testCases := []testCase{
+		{
+			name:       "matching answer follows nextTrue",
+			answer:     "yes",
+			wantBranch: func(w branchingWorkflow) string { return w.sectionBID },
+		},
+		{
+			name:       "non-matching answer follows nextFalse",
+			answer:     "no",
+			wantBranch: func(w branchingWorkflow) string { return w.sectionCID },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			logger := zap.NewNop()
+			tracer := noop.NewTracerProvider().Tracer("test")
+			formID := uuid.New()
+			w := createBranchingWorkflow(t, "nonChoice", "^yes$", "")
+
+			mockQuerier := new(mockQuerier)
+			mockValidator := new(mockValidator)
+			service := createTestService(t, logger, tracer, mockQuerier, mockValidator, nil)
+
+			mockQuerier.On("Get", mock.Anything, formID).Return(workflow.GetRow{
+				ID:       uuid.New(),
+				FormID:   formID,
+				Workflow: w.json,
+			}, nil).Once()
+
+			traversal, err := service.Simulate(ctx, formID, []shared.AnswerParam{
+				{QuestionID: w.questionID, Value: tc.answer},
+			}, nil, time.Now())
+
+			require.NoError(t, err)
+			require.True(t, traversal.Completed)
+			require.Equal(t, []string{w.startID, w.sectionAID, w.conditionID, tc.wantBranch(w), w.endID}, stepIDs(traversal))
+
+			mockQuerier.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_ActiveRuntime(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	logger := zap.NewNop()
+	tracer := noop.NewTracerProvider().Tracer("test")
+	formID := uuid.New()
+	userID := uuid.New()
+	first := createSimpleValidWorkflow(t)
+	second := createComplexValidWorkflow(t)
+
+	mockQuerier := new(mockQuerier)
+	mockValidator := new(mockValidator)
+	service := createTestService(t, logger, tracer, mockQuerier, mockValidator, nil)
+
+	// The active version is read and parsed once while it stays active
+	mockQuerier.On("GetActive", mock.Anything, formID).Return(workflow.GetActiveRow{
+		ID:       uuid.New(),
+		FormID:   formID,
+		IsActive: true,
+		Workflow: first,
+	}, nil).Once()
+
+	runtime, err := service.ActiveRuntime(ctx, formID)
+	require.NoError(t, err)
+	cached, err := service.ActiveRuntime(ctx, formID)
+	require.NoError(t, err)
+	require.Same(t, runtime, cached, "runtime was parsed again")
+
+	active, err := service.GetActive(ctx, formID)
+	require.NoError(t, err)
+	require.Equal(t, first, active.Workflow)
+	mockQuerier.AssertExpectations(t)
+
+	// Activating another version invalidates the cached one
+	mockValidator.On("Activate", mock.Anything, formID, second, mock.Anything).Return(nil).Once()
+	mockQuerier.On("Activate", mock.Anything, mock.Anything).Return(workflow.ActivateRow{
+		ID:       uuid.New(),
+		FormID:   formID,
+		IsActive: true,
+		Workflow: second,
+	}, nil).Once()
+	mockQuerier.On("GetActive", mock.Anything, formID).Return(workflow.GetActiveRow{
+		ID:       uuid.New(),
+		FormID:   formID,
+		IsActive: true,
+		Workflow: second,
+	}, nil).Once()
+
+	_, err = service.Activate(ctx, formID, userID, second)
+	require.NoError(t, err)
+
+	reloaded, err := service.ActiveRuntime(ctx, formID)
+	require.NoError(t, err)
+	require.NotSame(t, runtime, reloaded, "runtime of the previous version was served")
+
+	active, err = service.GetActive(ctx, formID)
+	require.NoError(t, err)
+	require.Equal(t, second, active.Workflow)
+
+	mockValidator.AssertExpectations(t)
+	mockQuerier.AssertExpectations(t)
+}
+
 // Helper functions to create test workflows
 
 func createSimpleValidWorkflow(t *testing.T) []byte {
@@ -586,6 +783,32 @@ func createSimpleValidWorkflow(t *testing.T) []byte {
 	})
 }
 
+func createWorkflowWithUnreachableNode(t *testing.T) []byte {
+	t.Helper()
+	startID := uuid.New()
+	endID := uuid.New()
+	orphanID := uuid.New()
+	return createWorkflowJSON(t, []map[string]interface{}{
+		{
+			"id":    startID.String(),
+			"type":  "start",
+			"label": "Start",
+			"next":  endID.String(),
+		},
+		{
+			"id":    endID.String(),
+			"type":  "end",
+			"label": "End",
+		},
+		{
+			"id":    orphanID.String(),
+			"type":  "section",
+			"label": "Orphan",
+			"next":  endID.String(),
+		},
+	})
+}
+
 func createComplexValidWorkflow(t *testing.T) []byte {
 	t.Helper()
 	startID := uuid.New()
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"NYCU-SDC/core-system-backend/internal/form/question"
 	"NYCU-SDC/core-system-backend/internal/form/workflow/node"
@@ -26,6 +27,9 @@ var nodeIDPatternTemplates = []string{
 	node.TypeSection + " node '%s'",
 	node.TypeCondition + " node '%s'",
 	node.TypeEnd + " node '%s'",
+	node.TypeApproval + " node '%s'",
+	node.TypeDelay + " node '%s'",
+	node.TypeAction + " node '%s'",
 	// Generic node pattern: "node 'uuid' is unreachable"
 	"node '%s'",
 	// Duplicate node ID pattern: "duplicate node id 'uuid'"
@@ -52,6 +56,16 @@ const (
 	ValidationTypeUnknown  ValidationInfoType = "unknown"
 )
 
+// ValidationSeverity represents how strongly a validation finding blocks the workflow.
+// Errors block saving and activation, warnings and infos are only surfaced to the editor.
+type ValidationSeverity string
+
+const (
+	ValidationSeverityError   ValidationSeverity = "error"
+	ValidationSeverityWarning ValidationSeverity = "warning"
+	ValidationSeverityInfo    ValidationSeverity = "info"
+)
+
 // QuestionStore defines the interface for querying form questions
 // This allows the validator to check if condition rule question IDs exist and match expected types
 type QuestionStore interface {
@@ -93,7 +107,8 @@ func NewValidator() Validator {
 // - Valid node types
 // - Graph connectivity (all nodes are reachable)
 // - Condition rule question IDs exist and types match
-// Returns all validation errors if validation fails
+// Returns all validation errors if validation fails. Findings reported by Warnings
+// with info severity never block activation.
 func (v workflowValidator) Activate(ctx context.Context, formID uuid.UUID, workflow []byte, questionStore QuestionStore) error {
 	nodes, nodeMap, validationErrors, err := runCommonWorkflowValidation(ctx, formID, workflow, questionStore, true)
 	if err != nil {
@@ -118,6 +133,7 @@ func (v workflowValidator) Activate(ctx context.Context, formID uuid.UUID, workf
 }
 
 // Validate performs validation for workflows (used by Update).
+// Only errors are returned; unreachable nodes are allowed in a draft and reported by Warnings.
 func (v workflowValidator) Validate(ctx context.Context, formID uuid.UUID, workflow []byte, questionStore QuestionStore) error {
 	nodes, nodeMap, validationErrors, err := runCommonWorkflowValidation(ctx, formID, workflow, questionStore, false)
 	if err != nil {
@@ -142,16 +158,11 @@ func (v workflowValidator) Validate(ctx context.Context, formID uuid.UUID, workf
 
 		if questionStore != nil {
 			for _, n := range nodes {
-				nodeType, _ := n["type"].(string)
-				if nodeType != string(NodeTypeCondition) {
+				condition, ok := n.(*node.ConditionNode)
+				if !ok || (condition.ConditionRule == nil && condition.RuleError() == nil) {
 					continue
 				}
-				rawRule, ok := n["conditionRule"]
-				if !ok {
-					continue
-				}
-				nodeID, _ := n["id"].(string)
-				err := validateDraftConditionQuestion(ctx, formID, nodeID, rawRule, questionStore)
+				err := validateDraftConditionQuestion(ctx, formID, condition, questionStore)
 				if err != nil {
 					validationErrors = append(validationErrors, err)
 				}
@@ -166,6 +177,61 @@ func (v workflowValidator) Validate(ctx context.Context, formID uuid.UUID, workf
 	return nil
 }
 
+// Warnings reports the findings that do not block saving a draft workflow:
+// - Nodes unreachable from the start node (warning, they block activation)
+// - Delay nodes whose releaseAt has already passed (info)
+// Workflows that cannot be parsed have no warnings, Validate reports them as errors.
+func (v workflowValidator) Warnings(ctx context.Context, workflow []byte) []ValidationInfo {
+	warnings := []ValidationInfo{}
+
+	rawNodes, err := validateWorkflowJSON(workflow)
+	if err != nil {
+		return warnings
+	}
+
+	// Nodes that cannot be decoded are reported as errors by Validate
+	nodes := make([]node.Node, 0, len(rawNodes))
+	nodeMap := make(map[string]node.Node, len(rawNodes))
+	for _, raw := range rawNodes {
+		n, err := node.Decode(raw)
+		if err != nil || n.NodeID() == "" {
+			continue
+		}
+		nodes = append(nodes, n)
+		nodeMap[n.NodeID()] = n
+	}
+
+	for _, nodeID := range findUnreachableNodes(nodes, nodeMap) {
+		warnings = append(warnings, ValidationInfo{
+			Type:     ValidationTypeGraph,
+			Severity: ValidationSeverityWarning,
+			NodeID:   &nodeID,
+			Message:  unreachableNodeMessage(nodeID),
+		})
+	}
+
+	now := time.Now()
+	for _, n := range nodes {
+		delay, ok := n.(*node.DelayNode)
+		if !ok {
+			continue
+		}
+		nodeID := delay.ID
+		releaseAt, err := time.Parse(time.RFC3339, delay.ReleaseAt)
+		if err != nil || releaseAt.After(now) {
+			continue
+		}
+		warnings = append(warnings, ValidationInfo{
+			Type:     ValidationTypeNode,
+			Severity: ValidationSeverityInfo,
+			NodeID:   &nodeID,
+			Message:  fmt.Sprintf("delay node '%s' releaseAt '%s' has already passed, respondents will not be held", nodeID, delay.ReleaseAt),
+		})
+	}
+
+	return warnings
+}
+
 // formatWorkflowValidationErrors joins and wraps workflow validation errors in a consistent way.
 func formatWorkflowValidationErrors(validationErrors []error) error {
 	if len(validationErrors) == 0 {
@@ -182,24 +248,25 @@ func validateWorkflowLength(workflow []byte) error {
 	return nil
 }
 
-// validateWorkflowJSON validates and parses the workflow JSON format
-func validateWorkflowJSON(workflow []byte) ([]map[string]interface{}, error) {
-	var nodes []map[string]interface{}
-	err := json.Unmarshal(workflow, &nodes)
+// validateWorkflowJSON validates and parses the workflow JSON format. The nodes keep
+// the bytes they were parsed from, so each one is decoded without encoding it again.
+func validateWorkflowJSON(workflow []byte) ([]node.Raw, error) {
+	nodes, err := node.ParseRaw(workflow)
 	if err != nil {
 		return nil, fmt.Errorf("invalid JSON format: %w", err)
 	}
 	return nodes, nil
 }
 
-// runCommonWorkflowValidation performs shared validation: workflow length, JSON parse, node validation, and required node types. Returns (nodes, nodeMap, validationErrors, err).
+// runCommonWorkflowValidation performs shared validation: workflow length, JSON parse, node validation, and required node types.
+// Returns (nodes, nodeMap, validationErrors, err) where nodes are the typed nodes that could be decoded, in workflow order.
 func runCommonWorkflowValidation(
 	ctx context.Context,
 	formID uuid.UUID,
 	workflow []byte,
 	questionStore QuestionStore,
 	isActivate bool,
-) (nodes []map[string]interface{}, nodeMap map[string]map[string]interface{}, validationErrors []error, err error) {
+) (nodes []node.Node, nodeMap map[string]node.Node, validationErrors []error, err error) {
 	var errs []error
 
 	err = validateWorkflowLength(workflow)
@@ -219,8 +286,7 @@ func runCommonWorkflowValidation(
 		return nil, nil, errs, fmt.Errorf("workflow validation failed: unable to parse workflow: %w", err)
 	}
 
-	nodes = parsed
-	nodeMap, startCount, endCount, nodeErrs := validateNodes(ctx, formID, nodes, questionStore, isActivate)
+	nodes, nodeMap, startCount, endCount, nodeErrs := validateNodes(ctx, formID, parsed, questionStore, isActivate)
 	if len(nodeErrs) > 0 {
 		errs = append(errs, nodeErrs...)
 	}
@@ -272,30 +338,31 @@ func validateNodeID(node map[string]interface{}, index int) (string, error) {
 	return id, nil
 }
 
-// validateNodes validates all nodes in the workflow and returns:
-// - nodeMap: map of node ID to node
+// validateNodes validates all nodes in the workflow, decoding each one into its typed node, and returns:
+// - typedNodes: the nodes that could be decoded, in workflow order
+// - nodeMap: map of node ID to typed node
 // - startNodeCount: number of start nodes found
 // - endNodeCount: number of end nodes found
 // - errors: all validation errors collected
 // When isActivate is true, this performs full node-specific validation (used by Activate).
 // When false, it performs a relaxed validation suitable for draft Update.
-func validateNodes(ctx context.Context, formID uuid.UUID, nodes []map[string]interface{}, questionStore QuestionStore, isActivate bool) (map[string]map[string]interface{}, int, int, []error) {
-	nodeMap := make(map[string]map[string]interface{})
-	nodeIDs := make(map[string]bool)
-	validatedNodes := make([]node.Validatable, 0, len(nodes))
+func validateNodes(ctx context.Context, formID uuid.UUID, nodes []node.Raw, questionStore QuestionStore, isActivate bool) ([]node.Node, map[string]node.Node, int, int, []error) {
+	nodeMap := make(map[string]node.Node, len(nodes))
+	nodeIDs := make(map[string]bool, len(nodes))
+	typedNodes := make([]node.Node, 0, len(nodes))
 	startNodeCount := 0
 	endNodeCount := 0
 	var validationErrors []error
 
 	for i, nodeData := range nodes {
 		// Validate required fields (id, type, label)
-		if err := validateRequiredFields(nodeData, i); err != nil {
+		if err := validateRequiredFields(nodeData.Fields, i); err != nil {
 			validationErrors = append(validationErrors, err)
 			continue // Skip this node but continue validating others
 		}
 
 		// Validate node ID
-		id, err := validateNodeID(nodeData, i)
+		id, err := validateNodeID(nodeData.Fields, i)
 		if err != nil {
 			validationErrors = append(validationErrors, err)
 			continue // Skip this node but continue validating others
@@ -307,23 +374,21 @@ func validateNodes(ctx context.Context, formID uuid.UUID, nodes []map[string]int
 			continue // Skip this node but continue validating others
 		}
 		nodeIDs[id] = true
-		nodeMap[id] = nodeData
 
-		// Validate node type and build Validatable instance
-		validatedNode, nodeTypeStr, err := node.New(nodeData)
+		// Validate node type and decode the typed node
+		typedNode, err := node.Decode(nodeData)
 		if err != nil {
 			validationErrors = append(validationErrors, fmt.Errorf("node at index %d: %w", i, err))
 			continue // Skip this node but continue validating others
 		}
-		if isActivate {
-			validatedNodes = append(validatedNodes, validatedNode)
-		}
+		typedNodes = append(typedNodes, typedNode)
+		nodeMap[id] = typedNode
 
 		// Count start and end nodes
-		if nodeTypeStr == string(NodeTypeStart) {
+		if typedNode.NodeType() == string(NodeTypeStart) {
 			startNodeCount++
 		}
-		if nodeTypeStr == string(NodeTypeEnd) {
+		if typedNode.NodeType() == string(NodeTypeEnd) {
 			endNodeCount++
 		}
 	}
@@ -332,16 +397,16 @@ func validateNodes(ctx context.Context, formID uuid.UUID, nodes []map[string]int
 	// This validates node-specific rules (e.g., condition nodes must have conditionRule)
 	// Only validate nodes that were successfully created
 	if isActivate {
-		for i, validatedNode := range validatedNodes {
+		for i, typedNode := range typedNodes {
 			// Pass context, formID, and questionStore for condition rule validation
-			err := validatedNode.Validate(ctx, formID, nodeMap, questionStore)
+			err := typedNode.Validate(ctx, formID, nodeMap, questionStore)
 			if err != nil {
 				validationErrors = append(validationErrors, fmt.Errorf("node at index %d: %w", i, err))
 			}
 		}
 	}
 
-	return nodeMap, startNodeCount, endNodeCount, validationErrors
+	return typedNodes, nodeMap, startNodeCount, endNodeCount, validationErrors
 }
 
 // validateRequiredNodeTypes validates that the workflow contains exactly one start node and exactly one end node
@@ -366,122 +431,114 @@ func validateRequiredNodeTypes(startNodeCount, endNodeCount int) []error {
 // - All node references (next, nextTrue, nextFalse) point to valid nodes
 // - All nodes can be reached from entry points (start nodes or first node)
 // - No orphaned nodes exist
-func validateGraphConnectivity(nodes []map[string]interface{}, nodeMap map[string]map[string]interface{}) error {
+func validateGraphConnectivity(nodes []node.Node, nodeMap map[string]node.Node) error {
 	// First validate references
 	err := validateGraphReferences(nodes, nodeMap)
 	if err != nil {
 		return err
 	}
 
-	// Build graph for reachability validation
-	graph := make(map[string][]string)
+	var unreachableErrors []error
+	for _, nodeID := range findUnreachableNodes(nodes, nodeMap) {
+		unreachableErrors = append(unreachableErrors, errors.New(unreachableNodeMessage(nodeID)))
+	}
 
-	// Build adjacency list and validate references
-	for _, node := range nodes {
-		nodeID, _ := node["id"].(string)
-		nodeType, _ := node["type"].(string)
+	if len(unreachableErrors) > 0 {
+		return errors.Join(unreachableErrors...)
+	}
 
-		var nextNodes []string
+	return nil
+}
 
-		// Handle different node types and their connection fields
-		if nodeType == string(NodeTypeCondition) {
-			// Condition nodes have nextTrue and nextFalse
-			nextTrue, ok := node["nextTrue"].(string)
-			if ok && nextTrue != "" {
-				nextNodes = append(nextNodes, nextTrue)
-			}
-			nextFalse, ok := node["nextFalse"].(string)
-			if ok && nextFalse != "" {
-				nextNodes = append(nextNodes, nextFalse)
-			}
-		} else {
-			// Other nodes have next field
-			next, ok := node["next"].(string)
-			if ok && next != "" {
-				nextNodes = append(nextNodes, next)
-			}
-		}
+// findUnreachableNodes returns the IDs of the nodes in nodeMap that cannot be reached
+// from the start node, in workflow order.
+func findUnreachableNodes(nodes []node.Node, nodeMap map[string]node.Node) []string {
+	startNodeID, graph := buildGraph(nodes)
+	visitOrder := traverseGraph(startNodeID, graph)
 
-		graph[nodeID] = nextNodes
+	var unreachable []string
+	for _, n := range nodes {
+		nodeID := n.NodeID()
+		if _, ok := nodeMap[nodeID]; !ok {
+			continue
+		}
+		if _, visited := visitOrder[nodeID]; visited {
+			continue
+		}
+		// Mark as visited so duplicate IDs are reported once
+		visitOrder[nodeID] = -1
+		unreachable = append(unreachable, nodeID)
 	}
 
-	// Find the start node (there should be exactly one)
+	return unreachable
+}
+
+// buildGraph returns the ID of the first start node and the adjacency list of the workflow
+func buildGraph(nodes []node.Node) (string, map[string][]string) {
 	startNodeID := ""
-	for _, node := range nodes {
-		nodeID, _ := node["id"].(string)
-		nodeType, _ := node["type"].(string)
+	graph := make(map[string][]string, len(nodes))
+	for _, n := range nodes {
+		graph[n.NodeID()] = n.Successors()
 
 		// The start node is the only entry point
-		if nodeType == string(NodeTypeStart) {
-			startNodeID = nodeID
-			break
+		if startNodeID == "" && n.NodeType() == string(NodeTypeStart) {
+			startNodeID = n.NodeID()
 		}
 	}
+	return startNodeID, graph
+}
 
-	// BFS traversal from the start node to ensure all nodes are reachable
-	visited := make(map[string]bool)
-	queue := make([]string, 0)
+// traverseGraph runs a BFS from startNodeID and returns the order in which each
+// reachable node is first visited, the start node being 0. Each node and link is
+// visited once.
+func traverseGraph(startNodeID string, graph map[string][]string) map[string]int {
+	visitOrder := make(map[string]int, len(graph))
+	queue := make([]string, 0, len(graph))
 
-	// Start from the start node
+	visitOrder[startNodeID] = 0
 	queue = append(queue, startNodeID)
-	visited[startNodeID] = true
-
-	// Traverse the graph
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
 
-		// Visit all connected nodes
-		for _, nextNodeID := range graph[current] {
-			if !visited[nextNodeID] {
-				visited[nextNodeID] = true
+	for head := 0; head < len(queue); head++ {
+		for _, nextNodeID := range graph[queue[head]] {
+			_, visited := visitOrder[nextNodeID]
+			if !visited {
+				visitOrder[nextNodeID] = len(queue)
 				queue = append(queue, nextNodeID)
 			}
 		}
 	}
 
-	// Check if all nodes are reachable
-	var unreachableErrors []error
-	for nodeID := range nodeMap {
-		if !visited[nodeID] {
-			unreachableErrors = append(unreachableErrors, fmt.Errorf("node '%s' is unreachable from the start node", nodeID))
-		}
-	}
-
-	if len(unreachableErrors) > 0 {
-		return errors.Join(unreachableErrors...)
-	}
+	return visitOrder
+}
 
-	return nil
+func unreachableNodeMessage(nodeID string) string {
+	return fmt.Sprintf("node '%s' is unreachable from the start node", nodeID)
 }
 
 // validateGraphReferences validates that any explicit reference fields point to nodes that exist.
-func validateGraphReferences(nodes []map[string]interface{}, nodeMap map[string]map[string]interface{}) error {
+func validateGraphReferences(nodes []node.Node, nodeMap map[string]node.Node) error {
 	var referenceErrors []error
 
-	for _, node := range nodes {
-		nodeID, _ := node["id"].(string)
-		nodeType, _ := node["type"].(string)
+	for _, n := range nodes {
+		nodeID := n.NodeID()
 
-		if nodeType == string(NodeTypeCondition) {
-			nextTrue, ok := node["nextTrue"].(string)
-			if ok && nextTrue != "" {
-				_, exists := nodeMap[nextTrue]
+		switch typed := n.(type) {
+		case *node.ConditionNode:
+			if typed.NextTrue != "" {
+				_, exists := nodeMap[typed.NextTrue]
 				if !exists {
-					referenceErrors = append(referenceErrors, fmt.Errorf("condition node '%s' references non-existent node '%s' in nextTrue", nodeID, nextTrue))
+					referenceErrors = append(referenceErrors, fmt.Errorf("condition node '%s' references non-existent node '%s' in nextTrue", nodeID, typed.NextTrue))
 				}
 			}
 
-			nextFalse, ok := node["nextFalse"].(string)
-			if ok && nextFalse != "" {
-				_, exists := nodeMap[nextFalse]
+			if typed.NextFalse != "" {
+				_, exists := nodeMap[typed.NextFalse]
 				if !exists {
-					referenceErrors = append(referenceErrors, fmt.Errorf("condition node '%s' references non-existent node '%s' in nextFalse", nodeID, nextFalse))
+					referenceErrors = append(referenceErrors, fmt.Errorf("condition node '%s' references non-existent node '%s' in nextFalse", nodeID, typed.NextFalse))
 				}
 			}
-		} else {
-			next, ok := node["next"].(string)
-			if ok && next != "" {
+		default:
+			for _, next := range n.Successors() {
 				_, exists := nodeMap[next]
 				if !exists {
 					referenceErrors = append(referenceErrors, fmt.Errorf("node '%s' references non-existent node '%s' in next", nodeID, next))
@@ -501,51 +558,13 @@ func validateGraphReferences(nodes []map[string]interface{}, nodeMap map[string]
 // If a condition references a section that comes after it in the graph,
 // the condition will always evaluate to false (section not yet visited).
 // Returns error if any condition references a section that comes after it.
-func validateConditionSectionOrder(nodes []map[string]interface{}) error {
-	// Find the start node
-	var startNodeID string
-	for _, n := range nodes {
-		nodeType, _ := n["type"].(string)
-		if nodeType == string(NodeTypeStart) {
-			startNodeID, _ = n["id"].(string)
-			break
-		}
-	}
-
+func validateConditionSectionOrder(nodes []node.Node) error {
+	startNodeID, graph := buildGraph(nodes)
 	if startNodeID == "" {
 		// No start node found, skip this validation (other validations will catch this)
 		return nil
 	}
 
-	// Build adjacency list for graph traversal
-	graph := make(map[string][]string)
-	for _, n := range nodes {
-		nodeID, _ := n["id"].(string)
-		nodeType, _ := n["type"].(string)
-
-		var nextNodes []string
-		switch nodeType {
-		case string(NodeTypeCondition):
-			nextTrue, ok := n["nextTrue"].(string)
-			if !ok || nextTrue == "" {
-				continue
-			}
-			nextNodes = append(nextNodes, nextTrue)
-			nextFalse, ok := n["nextFalse"].(string)
-			if !ok || nextFalse == "" {
-				continue
-			}
-			nextNodes = append(nextNodes, nextFalse)
-		default:
-			next, ok := n["next"].(string)
-			if !ok || next == "" {
-				continue
-			}
-			nextNodes = append(nextNodes, next)
-		}
-		graph[nodeID] = nextNodes
-	}
-
 	// Collect all condition nodes with their conditionRule.nodeId
 	type conditionInfo struct {
 		conditionNodeID  string
@@ -553,32 +572,15 @@ func validateConditionSectionOrder(nodes []map[string]interface{}) error {
 	}
 	var conditionsToCheck []conditionInfo
 
-	for _, node := range nodes {
-		nodeType, _ := node["type"].(string)
-		if nodeType != string(NodeTypeCondition) {
-			continue
-		}
-
-		nodeID, _ := node["id"].(string)
-		rawRule, ok := node["conditionRule"]
-		if !ok {
-			continue
-		}
-
-		// Parse conditionRule to get nodeId
-		ruleMap, ok := rawRule.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		referencedNodeID, ok := ruleMap["nodeId"].(string)
-		if !ok || referencedNodeID == "" {
+	for _, n := range nodes {
+		condition, ok := n.(*node.ConditionNode)
+		if !ok || condition.ConditionRule == nil || condition.ConditionRule.NodeID == "" {
 			continue
 		}
 
 		conditionsToCheck = append(conditionsToCheck, conditionInfo{
-			conditionNodeID:  nodeID,
-			referencedNodeID: referencedNodeID,
+			conditionNodeID:  condition.ID,
+			referencedNodeID: condition.ConditionRule.NodeID,
 		})
 	}
 
@@ -586,26 +588,8 @@ func validateConditionSectionOrder(nodes []map[string]interface{}) error {
 		return nil
 	}
 
-	// BFS traversal to determine visit order
 	// Track when each node is first visited (order number)
-	visitOrder := make(map[string]int)
-	queue := []string{startNodeID}
-	order := 0
-	visitOrder[startNodeID] = order
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
-		for _, nextNodeID := range graph[current] {
-			_, visited := visitOrder[nextNodeID]
-			if !visited {
-				order++
-				visitOrder[nextNodeID] = order
-				queue = append(queue, nextNodeID)
-			}
-		}
-	}
+	visitOrder := traverseGraph(startNodeID, graph)
 
 	// Check each condition: referenced section must be visited before the condition
 	var orderErrors []error
@@ -640,21 +624,14 @@ func validateConditionSectionOrder(nodes []map[string]interface{}) error {
 func validateDraftConditionQuestion(
 	ctx context.Context,
 	formID uuid.UUID,
-	nodeID string,
-	rawRule interface{},
+	condition *node.ConditionNode,
 	questionStore QuestionStore,
 ) error {
-	// Marshal-then-unmarshal into ConditionRule to reuse the shared struct definition.
-	conditionRuleBytes, err := json.Marshal(rawRule)
-	if err != nil {
-		return fmt.Errorf("condition node '%s' has invalid conditionRule format: %w", nodeID, err)
-	}
-
-	var rule node.ConditionRule
-	err = json.Unmarshal(conditionRuleBytes, &rule)
-	if err != nil {
-		return fmt.Errorf("condition node '%s' has invalid conditionRule format: %w", nodeID, err)
+	nodeID := condition.ID
+	if condition.RuleError() != nil {
+		return fmt.Errorf("condition node '%s' has invalid conditionRule format: %w", nodeID, condition.RuleError())
 	}
+	rule := *condition.ConditionRule
 
 	// Only validate question existence and type compatibility in draft mode.
 	if rule.Key == "" {
@@ -785,9 +762,10 @@ func parseValidationErrors(err error) []ValidationInfo {
 			nodeID := extractNodeID(line)
 
 			validationInfos = append(validationInfos, ValidationInfo{
-				NodeID:  nodeID,
-				Type:    errType,
-				Message: line, // Message without prefix
+				NodeID:   nodeID,
+				Type:     errType,
+				Severity: ValidationSeverityError,
+				Message:  line, // Message without prefix
 			})
 		}
 	}
@@ -0,0 +1,106 @@
+package workflow_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// benchQuestionStore answers every lookup with a short text question of the form
+type benchQuestionStore struct {
+	formID uuid.UUID
+}
+
+func (s benchQuestionStore) GetByID(_ context.Context, id uuid.UUID) (question.Answerable, error) {
+	return question.NewAnswerable(question.Question{
+		ID:    id,
+		Type:  question.QuestionTypeShortText,
+		Title: pgtype.Text{String: "Bench", Valid: true},
+	}, s.formID)
+}
+
+// largeWorkflow builds a valid workflow of about size nodes: a chain of sections where
+// every fourth node is a condition on the section before it, branching to the next
+// section or skipping it
+func largeWorkflow(b *testing.B, size int) []byte {
+	b.Helper()
+
+	ids := make([]string, size)
+	for i := range ids {
+		ids[i] = uuid.NewString()
+	}
+
+	nodes := make([]map[string]interface{}, 0, size)
+	nodes = append(nodes, map[string]interface{}{"id": ids[0], "type": "start", "label": "Start", "next": ids[1]})
+	for i := 1; i < size-1; i++ {
+		if i%4 == 0 && i+2 < size {
+			nodes = append(nodes, map[string]interface{}{
+				"id":        ids[i],
+				"type":      "condition",
+				"label":     fmt.Sprintf("Condition %d", i),
+				"nextTrue":  ids[i+1],
+				"nextFalse": ids[i+2],
+				"conditionRule": map[string]interface{}{
+					"source":  "nonChoice",
+					"nodeId":  ids[i-1],
+					"key":     uuid.NewString(),
+					"pattern": "^yes$",
+				},
+			})
+			continue
+		}
+		nodes = append(nodes, map[string]interface{}{"id": ids[i], "type": "section", "label": fmt.Sprintf("Section %d", i), "next": ids[i+1]})
+	}
+	nodes = append(nodes, map[string]interface{}{"id": ids[size-1], "type": "end", "label": "End"})
+
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		b.Fatalf("failed to encode workflow: %v", err)
+	}
+	return data
+}
+
+func BenchmarkValidator(b *testing.B) {
+	validator := workflow.NewValidator()
+	formID := uuid.New()
+	store := benchQuestionStore{formID: formID}
+	ctx := context.Background()
+
+	for _, size := range []int{10, 100, 500, 1000} {
+		data := largeWorkflow(b, size)
+
+		b.Run(fmt.Sprintf("Validate/nodes=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				err := validator.Validate(ctx, formID, data, store)
+				if err != nil {
+					b.Fatalf("validate failed: %v", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Activate/nodes=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				err := validator.Activate(ctx, formID, data, store)
+				if err != nil {
+					b.Fatalf("activate failed: %v", err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Warnings/nodes=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = validator.Warnings(ctx, data)
+			}
+		})
+	}
+}
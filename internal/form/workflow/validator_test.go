@@ -746,6 +746,13 @@ func TestValidate(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "valid workflow - approval gate",
+			setup: func() ([]byte, workflow.QuestionStore) {
+				return createWorkflowWithApproval(t, uuid.New().String()), &mockQuestionStore{questions: make(map[uuid.UUID]question.Answerable)}
+			},
+			expectedErr: false,
+		},
 	}
 
 	validator := workflow.NewValidator()
@@ -767,6 +774,443 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+// TestActivate_ApprovalNodeValidation tests that approval nodes must designate a valid approver unit
+func TestActivate_ApprovalNodeValidation(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		workflow    []byte
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			name:        "valid approval node",
+			workflow:    createWorkflowWithApproval(t, uuid.New().String()),
+			expectedErr: false,
+		},
+		{
+			name:        "approval node without approverUnitId",
+			workflow:    createWorkflowWithApproval(t, ""),
+			expectedErr: true,
+		},
+		{
+			name:        "approval node with invalid approverUnitId",
+			workflow:    createWorkflowWithApproval(t, "not-a-uuid"),
+			expectedErr: true,
+		},
+	}
+
+	validator := workflow.NewValidator()
+	ctx := context.Background()
+	formID := uuid.New()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validator.Activate(ctx, formID, tc.workflow, nil)
+
+			if tc.expectedErr {
+				require.Error(t, err, "expected validation error")
+			} else {
+				require.NoError(t, err, "expected validation to pass but got error: %v", err)
+			}
+		})
+	}
+}
+
+func createWorkflowWithApproval(t *testing.T, approverUnitID string) []byte {
+	t.Helper()
+	startID := uuid.New()
+	approvalID := uuid.New()
+	endID := uuid.New()
+
+	approval := map[string]interface{}{
+		"id":    approvalID.String(),
+		"type":  "approval",
+		"label": "Approval",
+		"next":  endID.String(),
+	}
+	if approverUnitID != "" {
+		approval["approverUnitId"] = approverUnitID
+	}
+
+	return createWorkflowJSON(t, []map[string]interface{}{
+		{
+			"id":    startID.String(),
+			"type":  "start",
+			"label": "Start",
+			"next":  approvalID.String(),
+		},
+		approval,
+		{
+			"id":    endID.String(),
+			"type":  "end",
+			"label": "End",
+		},
+	})
+}
+
+// TestActivate_DelayNodeValidation tests that delay nodes must have a valid release time
+func TestActivate_DelayNodeValidation(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		workflow    []byte
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			name:        "valid delay node",
+			workflow:    createWorkflowWithDelay(t, "2026-03-01T09:00:00+08:00"),
+			expectedErr: false,
+		},
+		{
+			name:        "delay node without releaseAt",
+			workflow:    createWorkflowWithDelay(t, ""),
+			expectedErr: true,
+		},
+		{
+			name:        "delay node with releaseAt that is not RFC 3339",
+			workflow:    createWorkflowWithDelay(t, "2026-03-01"),
+			expectedErr: true,
+		},
+	}
+
+	validator := workflow.NewValidator()
+	ctx := context.Background()
+	formID := uuid.New()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validator.Activate(ctx, formID, tc.workflow, nil)
+
+			if tc.expectedErr {
+				require.Error(t, err, "expected validation error")
+			} else {
+				require.NoError(t, err, "expected validation to pass but got error: %v", err)
+			}
+		})
+	}
+}
+
+func createWorkflowWithDelay(t *testing.T, releaseAt string) []byte {
+	t.Helper()
+	startID := uuid.New()
+	delayID := uuid.New()
+	endID := uuid.New()
+
+	delay := map[string]interface{}{
+		"id":    delayID.String(),
+		"type":  "delay",
+		"label": "Delay",
+		"next":  endID.String(),
+	}
+	if releaseAt != "" {
+		delay["releaseAt"] = releaseAt
+	}
+
+	return createWorkflowJSON(t, []map[string]interface{}{
+		{
+			"id":    startID.String(),
+			"type":  "start",
+			"label": "Start",
+			"next":  delayID.String(),
+		},
+		delay,
+		{
+			"id":    endID.String(),
+			"type":  "end",
+			"label": "End",
+		},
+	})
+}
+
+// TestActivate_NodeFieldTypes tests that fields decoded with the wrong JSON type fail activation
+// but still let draft validation run on the rest of the workflow
+func TestActivate_NodeFieldTypes(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name string
+		node map[string]interface{}
+	}
+
+	testCases := []testCase{
+		{
+			name: "non-string releaseAt",
+			node: map[string]interface{}{"type": "delay", "label": "Delay", "releaseAt": 1700000000},
+		},
+		{
+			name: "non-string approverUnitId",
+			node: map[string]interface{}{"type": "approval", "label": "Approval", "approverUnitId": true},
+		},
+		{
+			name: "non-string payload value",
+			node: map[string]interface{}{"type": "action", "label": "Action", "actionType": "event", "event": "application.accepted", "payload": map[string]interface{}{"score": 1}},
+		},
+	}
+
+	validator := workflow.NewValidator()
+	ctx := context.Background()
+	formID := uuid.New()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			startID := uuid.New().String()
+			nodeID := uuid.New().String()
+			endID := uuid.New().String()
+
+			n := map[string]interface{}{"id": nodeID, "next": endID}
+			for key, value := range tc.node {
+				n[key] = value
+			}
+			workflowJSON := createWorkflowJSON(t, []map[string]interface{}{
+				{"id": startID, "type": "start", "label": "Start", "next": nodeID},
+				n,
+				{"id": endID, "type": "end", "label": "End"},
+			})
+
+			err := validator.Activate(ctx, formID, workflowJSON, nil)
+			require.Error(t, err, "expected validation error")
+			require.Contains(t, err.Error(), nodeID)
+
+			err = validator.Validate(ctx, formID, workflowJSON, nil)
+			require.NoError(t, err, "draft validation should not check node field types")
+		})
+	}
+}
+
+// TestWarnings tests the non-blocking findings reported for draft workflows
+func TestWarnings(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name               string
+		workflow           []byte
+		expectedSeverities []workflow.ValidationSeverity
+	}
+
+	testCases := []testCase{
+		{
+			name:               "valid workflow has no warnings",
+			workflow:           createSimpleWorkflowForNodeIDTest(t),
+			expectedSeverities: []workflow.ValidationSeverity{},
+		},
+		{
+			name:               "unreachable node is a warning",
+			workflow:           createWorkflowWithUnreachableNode(t),
+			expectedSeverities: []workflow.ValidationSeverity{workflow.ValidationSeverityWarning},
+		},
+		{
+			name:               "past delay release is an info",
+			workflow:           createWorkflowWithDelay(t, "2000-01-01T00:00:00Z"),
+			expectedSeverities: []workflow.ValidationSeverity{workflow.ValidationSeverityInfo},
+		},
+		{
+			name:               "future delay release has no warnings",
+			workflow:           createWorkflowWithDelay(t, "2999-01-01T00:00:00Z"),
+			expectedSeverities: []workflow.ValidationSeverity{},
+		},
+		{
+			name:               "invalid JSON has no warnings",
+			workflow:           []byte("{"),
+			expectedSeverities: []workflow.ValidationSeverity{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			validator := workflow.NewValidator()
+			warnings := validator.Warnings(context.Background(), tc.workflow)
+
+			severities := make([]workflow.ValidationSeverity, len(warnings))
+			for i, warning := range warnings {
+				severities[i] = warning.Severity
+				require.NotNil(t, warning.NodeID)
+				require.NotEmpty(t, warning.Message)
+			}
+			require.Equal(t, tc.expectedSeverities, severities)
+
+			// Warnings never block activation on their own
+			if len(warnings) > 0 && tc.expectedSeverities[0] == workflow.ValidationSeverityInfo {
+				require.NoError(t, validator.Activate(context.Background(), uuid.New(), tc.workflow, nil))
+			}
+		})
+	}
+}
+
+// TestActivate_ActionNodeValidation tests webhook URL, event name and payload mapping validation of action nodes
+func TestActivate_ActionNodeValidation(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		action      map[string]interface{}
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			name: "valid webhook action with payload mapping",
+			action: map[string]interface{}{
+				"actionType": "webhook",
+				"url":        "https://example.com/hooks/form",
+				"payload": map[string]interface{}{
+					"form":   "form.id",
+					"user":   "user.id",
+					"answer": "question:" + uuid.New().String(),
+				},
+			},
+			expectedErr: false,
+		},
+		{
+			name: "valid event action",
+			action: map[string]interface{}{
+				"actionType": "event",
+				"event":      "application.submitted",
+			},
+			expectedErr: false,
+		},
+		{
+			name: "missing actionType",
+			action: map[string]interface{}{
+				"url": "https://example.com/hooks/form",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "webhook without url",
+			action: map[string]interface{}{
+				"actionType": "webhook",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "webhook with relative url",
+			action: map[string]interface{}{
+				"actionType": "webhook",
+				"url":        "/hooks/form",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "webhook with non-http url",
+			action: map[string]interface{}{
+				"actionType": "webhook",
+				"url":        "ftp://example.com/hooks/form",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "webhook with loopback url",
+			action: map[string]interface{}{
+				"actionType": "webhook",
+				"url":        "http://127.0.0.1:8080/hooks/form",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "webhook with metadata url",
+			action: map[string]interface{}{
+				"actionType": "webhook",
+				"url":        "http://169.254.169.254/latest/meta-data",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "event with invalid name",
+			action: map[string]interface{}{
+				"actionType": "event",
+				"event":      "Application Submitted",
+			},
+			expectedErr: true,
+		},
+		{
+			name: "payload with unknown source",
+			action: map[string]interface{}{
+				"actionType": "event",
+				"event":      "application.submitted",
+				"payload": map[string]interface{}{
+					"email": "user.email",
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "payload with invalid question ID",
+			action: map[string]interface{}{
+				"actionType": "webhook",
+				"url":        "https://example.com/hooks/form",
+				"payload": map[string]interface{}{
+					"answer": "question:not-a-uuid",
+				},
+			},
+			expectedErr: true,
+		},
+	}
+
+	validator := workflow.NewValidator()
+	ctx := context.Background()
+	formID := uuid.New()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validator.Activate(ctx, formID, createWorkflowWithAction(t, tc.action), nil)
+
+			if tc.expectedErr {
+				require.Error(t, err, "expected validation error")
+			} else {
+				require.NoError(t, err, "expected validation to pass but got error: %v", err)
+			}
+		})
+	}
+}
+
+func createWorkflowWithAction(t *testing.T, config map[string]interface{}) []byte {
+	t.Helper()
+	startID := uuid.New()
+	actionID := uuid.New()
+	endID := uuid.New()
+
+	action := map[string]interface{}{
+		"id":    actionID.String(),
+		"type":  "action",
+		"label": "Action",
+		"next":  endID.String(),
+	}
+	for key, value := range config {
+		action[key] = value
+	}
+
+	return createWorkflowJSON(t, []map[string]interface{}{
+		{
+			"id":    startID.String(),
+			"type":  "start",
+			"label": "Start",
+			"next":  actionID.String(),
+		},
+		action,
+		{
+			"id":    endID.String(),
+			"type":  "end",
+			"label": "End",
+		},
+	})
+}
+
 // Helper functions for ValidateUpdateNodeIDs tests
 
 func createSimpleWorkflowForNodeIDTest(t *testing.T) []byte {
@@ -0,0 +1,60 @@
+-- name: Create :one
+INSERT INTO recipient_groups (org_id, name, description)
+VALUES (@org_id, @name, @description)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM recipient_groups
+WHERE id = @id AND org_id = @org_id;
+
+-- name: ListByOrg :many
+SELECT g.*, COUNT(m.user_id) AS member_count
+FROM recipient_groups g
+LEFT JOIN recipient_group_members m ON m.group_id = g.id
+WHERE g.org_id = @org_id
+GROUP BY g.id
+ORDER BY g.name;
+
+-- name: Update :one
+UPDATE recipient_groups
+SET name = @name,
+    description = @description,
+    updated_at = now()
+WHERE id = @id AND org_id = @org_id
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM recipient_groups
+WHERE id = @id AND org_id = @org_id;
+
+-- name: ListMembers :many
+SELECT u.id,
+       u.name,
+       u.username,
+       u.avatar_url,
+       u.emails
+FROM recipient_group_members m
+JOIN users_with_emails u ON u.id = m.user_id
+WHERE m.group_id = @group_id
+ORDER BY u.name, u.id;
+
+-- name: DeleteMembers :exec
+DELETE FROM recipient_group_members
+WHERE group_id = @group_id;
+
+-- name: CountOrgMembers :one
+SELECT COUNT(DISTINCT um.member_id) AS total
+FROM unit_members um
+JOIN units u ON u.id = um.unit_id
+WHERE um.member_id = ANY(@user_ids::uuid[])
+  AND (u.id = @org_id OR u.org_id = @org_id);
+
+-- name: AddMembers :exec
+INSERT INTO recipient_group_members (group_id, user_id)
+SELECT @group_id::uuid, unnest(@user_ids::uuid[])
+ON CONFLICT (group_id, user_id) DO NOTHING;
+
+-- name: ListMemberIDs :many
+SELECT DISTINCT m.user_id
+FROM recipient_group_members m
+WHERE m.group_id = ANY(@group_ids::uuid[]);
\ No newline at end of file
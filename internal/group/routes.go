@@ -0,0 +1,14 @@
+package group
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the recipient groups of an organization
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/groups", route.TenantAuthenticated, route.PermissionNone, h.ListHandler)
+	r.Handle("POST /orgs/{slug}/groups", route.TenantAuthenticated, route.PermissionNone, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/groups/{id}", route.TenantAuthenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /orgs/{slug}/groups/{id}", route.TenantAuthenticated, route.PermissionNone, h.UpdateHandler)
+	r.Handle("DELETE /orgs/{slug}/groups/{id}", route.TenantAuthenticated, route.PermissionNone, h.DeleteHandler)
+}
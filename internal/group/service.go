@@ -0,0 +1,285 @@
+package group
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"errors"
+	"fmt"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (RecipientGroup, error)
+	GetByID(ctx context.Context, arg GetByIDParams) (RecipientGroup, error)
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]ListByOrgRow, error)
+	Update(ctx context.Context, arg UpdateParams) (RecipientGroup, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	ListMembers(ctx context.Context, groupID uuid.UUID) ([]ListMembersRow, error)
+	DeleteMembers(ctx context.Context, groupID uuid.UUID) error
+	CountOrgMembers(ctx context.Context, arg CountOrgMembersParams) (int64, error)
+	AddMembers(ctx context.Context, arg AddMembersParams) error
+	ListMemberIDs(ctx context.Context, groupIds []uuid.UUID) ([]uuid.UUID, error)
+}
+
+// Input describes a recipient group. MemberIDs replaces the whole member set and
+// may only contain members of the organization or one of its units.
+type Input struct {
+	Name        string
+	Description string
+	MemberIDs   []uuid.UUID
+}
+
+// Group is a recipient group together with its members
+type Group struct {
+	RecipientGroup
+	Members []user.Profile
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("group/service"),
+	}
+}
+
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, input Input) (Group, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	memberIDs, err := s.validateMembers(ctx, logger, orgID, input.MemberIDs)
+	if err != nil {
+		span.RecordError(err)
+		return Group{}, err
+	}
+
+	group, err := s.queries.Create(ctx, CreateParams{
+		OrgID:       orgID,
+		Name:        input.Name,
+		Description: input.Description,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "recipient_groups", "org_id", orgID.String(), logger, "create recipient group")
+		span.RecordError(err)
+		return Group{}, err
+	}
+
+	members, err := s.replaceMembers(ctx, logger, group.ID, memberIDs)
+	if err != nil {
+		span.RecordError(err)
+		return Group{}, err
+	}
+
+	logger.Info("Created recipient group",
+		zap.String("group_id", group.ID.String()),
+		zap.String("org_id", orgID.String()),
+		zap.Int("members", len(members)))
+
+	return Group{RecipientGroup: group, Members: members}, nil
+}
+
+func (s *Service) List(ctx context.Context, orgID uuid.UUID) ([]ListByOrgRow, error) {
+	ctx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	groups, err := s.queries.ListByOrg(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "recipient_groups", "org_id", orgID.String(), logger, "list recipient groups")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (Group, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	group, err := s.queries.GetByID(ctx, GetByIDParams{ID: id, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrGroupNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "recipient_groups", "id", id.String(), logger, "get recipient group")
+		}
+		span.RecordError(err)
+		return Group{}, err
+	}
+
+	members, err := s.listMembers(ctx, logger, id)
+	if err != nil {
+		span.RecordError(err)
+		return Group{}, err
+	}
+
+	return Group{RecipientGroup: group, Members: members}, nil
+}
+
+func (s *Service) Update(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input Input) (Group, error) {
+	ctx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	memberIDs, err := s.validateMembers(ctx, logger, orgID, input.MemberIDs)
+	if err != nil {
+		span.RecordError(err)
+		return Group{}, err
+	}
+
+	group, err := s.queries.Update(ctx, UpdateParams{
+		Name:        input.Name,
+		Description: input.Description,
+		ID:          id,
+		OrgID:       orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrGroupNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "recipient_groups", "id", id.String(), logger, "update recipient group")
+		}
+		span.RecordError(err)
+		return Group{}, err
+	}
+
+	members, err := s.replaceMembers(ctx, logger, group.ID, memberIDs)
+	if err != nil {
+		span.RecordError(err)
+		return Group{}, err
+	}
+
+	logger.Info("Updated recipient group", zap.String("group_id", id.String()), zap.Int("members", len(members)))
+
+	return Group{RecipientGroup: group, Members: members}, nil
+}
+
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	rows, err := s.queries.Delete(ctx, DeleteParams{ID: id, OrgID: orgID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "recipient_groups", "id", id.String(), logger, "delete recipient group")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrGroupNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted recipient group", zap.String("group_id", id.String()))
+
+	return nil
+}
+
+// ListMemberIDs resolves the distinct members of the given groups, used as the
+// recipients of a publication
+func (s *Service) ListMemberIDs(ctx context.Context, groupIDs []uuid.UUID) ([]uuid.UUID, error) {
+	ctx, span := s.tracer.Start(ctx, "ListMemberIDs")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	if len(groupIDs) == 0 {
+		return []uuid.UUID{}, nil
+	}
+
+	ids, err := s.queries.ListMemberIDs(ctx, groupIDs)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list recipient group member ids")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// validateMembers deduplicates the member IDs and checks that each of them is a
+// member of the organization or one of its units
+func (s *Service) validateMembers(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, memberIDs []uuid.UUID) ([]uuid.UUID, error) {
+	unique := make([]uuid.UUID, 0, len(memberIDs))
+	seen := make(map[uuid.UUID]struct{}, len(memberIDs))
+	for _, id := range memberIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	count, err := s.queries.CountOrgMembers(ctx, CountOrgMembersParams{
+		UserIds: unique,
+		OrgID:   orgID,
+	})
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "org_id", orgID.String(), logger, "count organization members")
+	}
+	if count != int64(len(unique)) {
+		return nil, fmt.Errorf("%w: %d of %d users are not members", internal.ErrGroupMemberNotInOrg, int64(len(unique))-count, len(unique))
+	}
+
+	return unique, nil
+}
+
+func (s *Service) replaceMembers(ctx context.Context, logger *zap.Logger, groupID uuid.UUID, memberIDs []uuid.UUID) ([]user.Profile, error) {
+	err := s.queries.DeleteMembers(ctx, groupID)
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "recipient_group_members", "group_id", groupID.String(), logger, "delete recipient group members")
+	}
+
+	if len(memberIDs) > 0 {
+		err = s.queries.AddMembers(ctx, AddMembersParams{
+			GroupID: groupID,
+			UserIds: memberIDs,
+		})
+		if err != nil {
+			return nil, databaseutil.WrapDBErrorWithKeyValue(err, "recipient_group_members", "group_id", groupID.String(), logger, "add recipient group members")
+		}
+	}
+
+	return s.listMembers(ctx, logger, groupID)
+}
+
+func (s *Service) listMembers(ctx context.Context, logger *zap.Logger, groupID uuid.UUID) ([]user.Profile, error) {
+	rows, err := s.queries.ListMembers(ctx, groupID)
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "recipient_group_members", "group_id", groupID.String(), logger, "list recipient group members")
+	}
+
+	members := make([]user.Profile, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, user.Profile{
+			ID:        row.ID,
+			Name:      row.Name.String,
+			Username:  row.Username.String,
+			AvatarURL: row.AvatarUrl.String,
+			Emails:    user.ConvertEmailsToSlice(row.Emails),
+		})
+	}
+
+	return members, nil
+}
@@ -2,20 +2,30 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
 )
 
 type contextKey string
 
 var (
-	UserContextKey    contextKey = "user"
-	OrgIDContextKey   contextKey = "org-id"
-	OrgSlugContextKey contextKey = "org-slug"
-	DBConnectionKey   contextKey = "database-connection"
+	UserContextKey       contextKey = "user"
+	OrgIDContextKey      contextKey = "org-id"
+	OrgSlugContextKey    contextKey = "org-slug"
+	DBConnectionKey      contextKey = "database-connection"
+	PreviewContextKey    contextKey = "preview"
+	DelegationContextKey contextKey = "delegation"
+	ClientIPContextKey   contextKey = "client-ip"
 )
 
 type DBTX interface {
@@ -41,6 +51,20 @@ func GetDBTXFromContext(ctx context.Context) (DBTX, error) {
 	return conn, nil
 }
 
+// IsPreview reports whether the request was made with a preview token, whose
+// submissions are test data
+func IsPreview(ctx context.Context) bool {
+	preview, _ := ctx.Value(PreviewContextKey).(bool)
+	return preview
+}
+
+// DelegationFromContext returns the delegation a request was made on behalf of, set
+// for requests made with a management token only
+func DelegationFromContext(ctx context.Context) (uuid.UUID, bool) {
+	delegationID, ok := ctx.Value(DelegationContextKey).(uuid.UUID)
+	return delegationID, ok
+}
+
 func GetSlugFromContext(ctx context.Context) (string, error) {
 	orgSlug, ok := ctx.Value(OrgSlugContextKey).(string)
 	if !ok {
@@ -48,3 +72,61 @@ func GetSlugFromContext(ctx context.Context) (string, error) {
 	}
 	return orgSlug, nil
 }
+
+// ParseAndValidateRequestBody decodes exactly one JSON value into s and validates it.
+// Unknown fields and trailing data are rejected so typos in a payload surface as errors
+// instead of being silently dropped.
+func ParseAndValidateRequestBody(ctx context.Context, v *validator.Validate, r *http.Request, s interface{}) error {
+	_, span := otel.Tracer("internal/handler").Start(ctx, "ParseAndValidateRequestBody")
+	defer span.End()
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(s)
+	if err == nil {
+		_, err = decoder.Token()
+		if err == nil {
+			err = fmt.Errorf("%w: unexpected data after the JSON value", ErrInvalidRequestBody)
+		} else if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	}
+	if err != nil {
+		err = requestBodyError(err)
+		span.RecordError(err)
+		return err
+	}
+
+	err = v.Struct(s)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+func requestBodyError(err error) error {
+	var maxBytesError *http.MaxBytesError
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, ErrInvalidRequestBody):
+		return err
+	case errors.As(err, &maxBytesError):
+		return fmt.Errorf("%w: limit is %d bytes", ErrRequestBodyTooLarge, maxBytesError.Limit)
+	case errors.Is(err, io.EOF):
+		return fmt.Errorf("%w: body is empty", ErrInvalidRequestBody)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%w: body is truncated", ErrInvalidRequestBody)
+	case errors.As(err, &syntaxError):
+		return fmt.Errorf("%w: malformed JSON at offset %d", ErrInvalidRequestBody, syntaxError.Offset)
+	case errors.As(err, &typeError):
+		return fmt.Errorf("%w: field %q must be %s", ErrInvalidRequestBody, typeError.Field, typeError.Type)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return fmt.Errorf("%w: unknown field %s", ErrInvalidRequestBody, strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return fmt.Errorf("%w: %w", ErrInvalidRequestBody, err)
+	}
+}
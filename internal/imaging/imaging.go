@@ -0,0 +1,196 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+const (
+	// MaxFileSize is the largest file that is decoded for processing
+	MaxFileSize = 50 << 20
+
+	// MaxPixels guards against decompression bombs with small files but huge dimensions
+	MaxPixels = 40_000_000
+
+	jpegQuality = 85
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrImageTooLarge     = errors.New("image is too large")
+)
+
+// Size is a named size variant of a processed image
+type Size string
+
+const (
+	SizeThumbnail Size = "thumbnail"
+	SizeMedium    Size = "medium"
+	SizeLarge     Size = "large"
+)
+
+// Sizes lists every variant generated for a processed image
+var Sizes = []Size{SizeThumbnail, SizeMedium, SizeLarge}
+
+// MaxDimension is the longest side of the variant in pixels
+func (s Size) MaxDimension() int {
+	switch s {
+	case SizeThumbnail:
+		return 160
+	case SizeMedium:
+		return 640
+	case SizeLarge:
+		return 1280
+	default:
+		return 0
+	}
+}
+
+func ParseSize(s string) (Size, bool) {
+	size := Size(s)
+	return size, size.MaxDimension() > 0
+}
+
+// Supported reports whether files of the content type can be processed
+func Supported(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	default:
+		return false
+	}
+}
+
+// Image is a decoded image with its EXIF orientation already applied
+type Image struct {
+	image.Image
+	Format string
+}
+
+// Decode reads a JPEG, PNG or GIF image. Metadata is not kept, so encoding the image
+// again strips EXIF data such as GPS coordinates; the EXIF orientation of JPEG images
+// is applied to the pixels first so photos keep their rotation.
+func Decode(r io.Reader) (Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > MaxFileSize {
+		return Image{}, fmt.Errorf("%w: exceeds %d bytes", ErrImageTooLarge, MaxFileSize)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+	if config.Width*config.Height > MaxPixels {
+		return Image{}, fmt.Errorf("%w: %dx%d pixels", ErrImageTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("%w: %w", ErrUnsupportedFormat, err)
+	}
+
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	return Image{Image: img, Format: format}, nil
+}
+
+// Encode writes the image as JPEG if it was decoded from a JPEG and as PNG otherwise,
+// returning the content type of the output
+func Encode(w io.Writer, img Image) (string, error) {
+	if img.Format == "jpeg" {
+		err := jpeg.Encode(w, img.Image, &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+		return "image/jpeg", nil
+	}
+
+	err := png.Encode(w, img.Image)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode png: %w", err)
+	}
+	return "image/png", nil
+}
+
+// Resize scales the image down so its longest side is at most maxDimension. Smaller
+// images are returned unchanged.
+func Resize(img Image, maxDimension int) Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxDimension && height <= maxDimension {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*maxDimension/width)
+		width = maxDimension
+	} else {
+		width = max(1, width*maxDimension/height)
+		height = maxDimension
+	}
+
+	return Image{Image: scale(img.Image, width, height), Format: img.Format}
+}
+
+// CropSquare cuts the largest centered square out of the image
+func CropSquare(img Image) Image {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	for dy := 0; dy < side; dy++ {
+		for dx := 0; dx < side; dx++ {
+			dst.Set(dx, dy, img.At(x+dx, y+dy))
+		}
+	}
+
+	return Image{Image: dst, Format: img.Format}
+}
+
+// scale downsamples by averaging the source pixels covered by each destination pixel
+func scale(src image.Image, width int, height int) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					count++
+				}
+			}
+
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / count >> 8)
+			dst.Pix[offset+1] = uint8(g / count >> 8)
+			dst.Pix[offset+2] = uint8(b / count >> 8)
+			dst.Pix[offset+3] = uint8(a / count >> 8)
+		}
+	}
+
+	return dst
+}
@@ -38,6 +38,8 @@ func (b *Bool) Validate() (bool, error) {
 			return false, internal.ErrInvalidIsStarredParameter
 		case "isArchived":
 			return false, internal.ErrInvalidIsArchivedParameter
+		case "isSnoozed":
+			return false, internal.ErrInvalidIsSnoozedParameter
 		}
 		return false, err
 	}
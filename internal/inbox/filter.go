@@ -1,7 +1,12 @@
 package inbox
 
 import (
+	"NYCU-SDC/core-system-backend/internal"
+	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
 )
 
 // FilterRequest represents the filter parameters for inbox messages
@@ -9,7 +14,10 @@ type FilterRequest struct {
 	IsRead     *bool  `json:"isRead,omitempty"`
 	IsStarred  *bool  `json:"isStarred,omitempty"`
 	IsArchived *bool  `json:"isArchived,omitempty"`
+	IsSnoozed  *bool  `json:"isSnoozed,omitempty"`
 	Search     string `json:"search,omitempty"`
+	// TagIDs keeps the messages carrying any of the tags
+	TagIDs []uuid.UUID `json:"tagIds,omitempty"`
 }
 
 // ParseFilterRequest parses filter parameters from HTTP request query parameters
@@ -19,6 +27,7 @@ func ParseFilterRequest(r *http.Request) (*FilterRequest, error) {
 	isReadStr := query.Get("isRead")
 	isStarredStr := query.Get("isStarred")
 	isArchivedStr := query.Get("isArchived")
+	isSnoozedStr := query.Get("isSnoozed")
 	searchStr := query.Get("search")
 
 	isRead, err := NewBool("isRead", isReadStr)
@@ -36,6 +45,11 @@ func ParseFilterRequest(r *http.Request) (*FilterRequest, error) {
 		return nil, err
 	}
 
+	isSnoozed, err := NewBool("isSnoozed", isSnoozedStr)
+	if err != nil {
+		return nil, err
+	}
+
 	search, err := NewSearch("search", searchStr)
 	if err != nil {
 		return nil, err
@@ -54,7 +68,59 @@ func ParseFilterRequest(r *http.Request) (*FilterRequest, error) {
 	if isArchivedStr != "" {
 		filter.IsArchived = &isArchived
 	}
+	if isSnoozedStr != "" {
+		filter.IsSnoozed = &isSnoozed
+	}
 	filter.Search = *search
 
+	for _, value := range query["tagId"] {
+		for _, part := range strings.Split(value, ",") {
+			tagID, err := uuid.Parse(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("%w: tagId %q is not a UUID", internal.ErrInvalidQueryParameter, part)
+			}
+			filter.TagIDs = append(filter.TagIDs, tagID)
+		}
+	}
+
+	return filter, nil
+}
+
+// ParseUnitInboxFilter parses the filter of a shared unit mailbox. The assignee query
+// parameter accepts "me", "unassigned" or a user ID.
+func ParseUnitInboxFilter(r *http.Request, currentUserID uuid.UUID) (UnitInboxFilter, error) {
+	query := r.URL.Query()
+	filter := UnitInboxFilter{}
+
+	if isReadStr := query.Get("isRead"); isReadStr != "" {
+		isRead, err := NewBool("isRead", isReadStr)
+		if err != nil {
+			return UnitInboxFilter{}, err
+		}
+		filter.IsRead = &isRead
+	}
+
+	if isArchivedStr := query.Get("isArchived"); isArchivedStr != "" {
+		isArchived, err := NewBool("isArchived", isArchivedStr)
+		if err != nil {
+			return UnitInboxFilter{}, err
+		}
+		filter.IsArchived = &isArchived
+	}
+
+	switch assignee := query.Get("assignee"); assignee {
+	case "":
+	case "me":
+		filter.AssigneeID = &currentUserID
+	case "unassigned":
+		filter.Unassigned = true
+	default:
+		assigneeID, err := internal.ParseUUID(assignee)
+		if err != nil {
+			return UnitInboxFilter{}, err
+		}
+		filter.AssigneeID = &assigneeID
+	}
+
 	return filter, nil
 }
@@ -17,6 +17,7 @@ import (
 	"github.com/NYCU-SDC/summer/pkg/problem"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -26,14 +27,26 @@ import (
 type Store interface {
 	List(ctx context.Context, userID uuid.UUID, filter *FilterRequest, page int, size int) ([]ListRow, error)
 	Count(ctx context.Context, userID uuid.UUID, filter *FilterRequest) (int64, error)
+	ListWithCount(ctx context.Context, userID uuid.UUID, filter *FilterRequest, page int, size int) ([]ListRow, int64, error)
 	GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (GetByIDRow, error)
 	UpdateByID(ctx context.Context, id uuid.UUID, userID uuid.UUID, arg UserInboxMessageFilter) (UpdateByIDRow, error)
+	Reply(ctx context.Context, id uuid.UUID, userID uuid.UUID, body string) (ListThreadRow, error)
+	ListThread(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]ListThreadRow, error)
+	ListUnitInbox(ctx context.Context, unitID uuid.UUID, userID uuid.UUID, filter UnitInboxFilter, page int, size int) ([]ListUnitInboxRow, int64, error)
+	UpdateUnitInbox(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, update UnitInboxUpdate) (GetUnitInboxByIDRow, error)
+	ReplyFromUnitInbox(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, body string) (ListThreadRow, error)
+	ListUnitInboxThread(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID) ([]ListThreadRow, error)
 }
 
+// UserInboxMessageFilter is the state of a message in the inbox of a user. Updates
+// replace the whole state, so a message is marked unread by sending isRead false and
+// un-snoozed by leaving snoozedUntil out. Snoozed messages are hidden from the default
+// list until snoozedUntil.
 type UserInboxMessageFilter struct {
-	IsRead     bool `json:"isRead"`
-	IsStarred  bool `json:"isStarred"`
-	IsArchived bool `json:"isArchived"`
+	IsRead       bool       `json:"isRead"`
+	IsStarred    bool       `json:"isStarred"`
+	IsArchived   bool       `json:"isArchived"`
+	SnoozedUntil *time.Time `json:"snoozedUntil,omitempty"`
 }
 
 type FormMessageResponse struct {
@@ -45,10 +58,119 @@ type FormMessageResponse struct {
 	Type           ContentType `json:"type"`
 	PreviewMessage string      `json:"previewMessage"`
 	ContentID      string      `json:"contentId"`
+	ReplyTo        string      `json:"replyTo,omitempty"`
+	ThreadID       string      `json:"threadId,omitempty"`
+	SenderID       string      `json:"senderId,omitempty"`
+	Body           string      `json:"body,omitempty"`
+	TagIDs         []string    `json:"tagIds,omitempty"`
 	CreatedAt      string      `json:"createdAt"`
 	UpdatedAt      string      `json:"updatedAt"`
 }
 
+type ReplyRequest struct {
+	Body string `json:"body" validate:"required,max=4000"`
+}
+
+// ThreadMessageResponse is a message of a thread. InboxID is the inbox entry of the
+// current user and is empty for the message starting the thread when it was not
+// delivered to the user.
+type ThreadMessageResponse struct {
+	ID         string      `json:"id"`
+	InboxID    string      `json:"inboxId,omitempty"`
+	PostedBy   string      `json:"postedBy"`
+	SenderID   string      `json:"senderId,omitempty"`
+	SenderName string      `json:"senderName,omitempty"`
+	Type       ContentType `json:"type"`
+	ContentID  string      `json:"contentId"`
+	ReplyTo    string      `json:"replyTo,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	IsRead     bool        `json:"isRead"`
+	CreatedAt  string      `json:"createdAt"`
+}
+
+func optionalUUID(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return uuid.UUID(id.Bytes).String()
+}
+
+func tagIDStrings(ids []uuid.UUID) []string {
+	tagIDs := make([]string, len(ids))
+	for i, id := range ids {
+		tagIDs[i] = id.String()
+	}
+	return tagIDs
+}
+
+func optionalTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func ToThreadMessageResponse(message ListThreadRow) ThreadMessageResponse {
+	return ThreadMessageResponse{
+		ID:         message.ID.String(),
+		InboxID:    optionalUUID(message.UserInboxMessageID),
+		PostedBy:   message.PostedBy.String(),
+		SenderID:   optionalUUID(message.SenderID),
+		SenderName: message.SenderName.String,
+		Type:       message.Type,
+		ContentID:  message.ContentID.String(),
+		ReplyTo:    optionalUUID(message.ReplyTo),
+		Body:       message.Body.String,
+		IsRead:     message.IsRead.Bool,
+		CreatedAt:  message.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
+type UnitInboxUpdateRequest struct {
+	IsRead     bool   `json:"isRead"`
+	IsArchived bool   `json:"isArchived"`
+	AssigneeID string `json:"assigneeId" validate:"omitempty,uuid"`
+}
+
+// UnitInboxResponse is a message of the shared mailbox of a unit
+type UnitInboxResponse struct {
+	ID           string      `json:"id"`
+	UnitID       string      `json:"unitId"`
+	MessageID    string      `json:"messageId"`
+	Type         ContentType `json:"type"`
+	ContentID    string      `json:"contentId"`
+	ReplyTo      string      `json:"replyTo,omitempty"`
+	ThreadID     string      `json:"threadId,omitempty"`
+	SenderID     string      `json:"senderId,omitempty"`
+	SenderName   string      `json:"senderName,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	AssigneeID   string      `json:"assigneeId,omitempty"`
+	AssigneeName string      `json:"assigneeName,omitempty"`
+	IsRead       bool        `json:"isRead"`
+	IsArchived   bool        `json:"isArchived"`
+	CreatedAt    string      `json:"createdAt"`
+}
+
+func ToUnitInboxResponse(message ListUnitInboxRow) UnitInboxResponse {
+	return UnitInboxResponse{
+		ID:           message.ID.String(),
+		UnitID:       message.UnitID.String(),
+		MessageID:    message.MessageID.String(),
+		Type:         message.Type,
+		ContentID:    message.ContentID.String(),
+		ReplyTo:      optionalUUID(message.ReplyTo),
+		ThreadID:     optionalUUID(message.ThreadID),
+		SenderID:     optionalUUID(message.SenderID),
+		SenderName:   message.SenderName.String,
+		Body:         message.Body.String,
+		AssigneeID:   optionalUUID(message.AssigneeID),
+		AssigneeName: message.AssigneeName.String,
+		IsRead:       message.IsRead,
+		IsArchived:   message.IsArchived,
+		CreatedAt:    message.CreatedAt.Time.Format(time.RFC3339),
+	}
+}
+
 type Response struct {
 	ID      string              `json:"id"`
 	Message FormMessageResponse `json:"message"`
@@ -112,13 +234,19 @@ func (h *Handler) mapToResponse(ctx context.Context, message ListRow) (Response,
 			Type:           message.Type,
 			PreviewMessage: previewMessage,
 			ContentID:      message.ContentID.String(),
+			ReplyTo:        optionalUUID(message.ReplyTo),
+			ThreadID:       optionalUUID(message.ThreadID),
+			SenderID:       optionalUUID(message.SenderID),
+			Body:           message.Body.String,
+			TagIDs:         tagIDStrings(message.TagIds),
 			CreatedAt:      message.CreatedAt.Time.Format(time.RFC3339),
 			UpdatedAt:      message.UpdatedAt.Time.Format(time.RFC3339),
 		},
 		UserInboxMessageFilter: UserInboxMessageFilter{
-			IsRead:     message.IsRead,
-			IsStarred:  message.IsStarred,
-			IsArchived: message.IsArchived,
+			IsRead:       message.IsRead,
+			IsStarred:    message.IsStarred,
+			IsArchived:   message.IsArchived,
+			SnoozedUntil: optionalTime(message.SnoozedUntil),
 		},
 	}, nil
 }
@@ -161,16 +289,19 @@ func (h *Handler) GetMessageContent(ctx context.Context, contentType ContentType
 			return form.Response{}, err
 		}
 		response := form.ToResponse(form.Form{
-			ID:             currentForm.ID,
-			Title:          currentForm.Title,
-			Description:    currentForm.Description,
-			PreviewMessage: currentForm.PreviewMessage,
-			Status:         currentForm.Status,
-			UnitID:         currentForm.UnitID,
-			LastEditor:     currentForm.LastEditor,
-			Deadline:       currentForm.Deadline,
-			CreatedAt:      currentForm.CreatedAt,
-			UpdatedAt:      currentForm.UpdatedAt,
+			ID:                 currentForm.ID,
+			Title:              currentForm.Title,
+			Description:        currentForm.Description,
+			PreviewMessage:     currentForm.PreviewMessage,
+			Status:             currentForm.Status,
+			UnitID:             currentForm.UnitID,
+			LastEditor:         currentForm.LastEditor,
+			Deadline:           currentForm.Deadline,
+			RandomizeQuestions: currentForm.RandomizeQuestions,
+			ShuffleChoices:     currentForm.ShuffleChoices,
+			TimeLimitSeconds:   currentForm.TimeLimitSeconds,
+			CreatedAt:          currentForm.CreatedAt,
+			UpdatedAt:          currentForm.UpdatedAt,
 		}, currentForm.UnitName.String, currentForm.OrgName.String, user.User{
 			ID:        currentForm.LastEditor,
 			Name:      currentForm.LastEditorName,
@@ -178,7 +309,7 @@ func (h *Handler) GetMessageContent(ctx context.Context, contentType ContentType
 			AvatarUrl: currentForm.LastEditorAvatarUrl,
 		}, user.ConvertEmailsToSlice(currentForm.LastEditorEmail))
 		return response, nil
-	case ContentTypeText:
+	case ContentTypeText, ContentTypeTask, ContentTypeQuota:
 		return nil, nil
 	}
 
@@ -210,14 +341,8 @@ func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get total count for pagination
-	total, err := h.store.Count(traceCtx, currentUser.ID, filter)
-	if err != nil {
-		h.problemWriter.WriteError(traceCtx, w, err, logger)
-		return
-	}
-
-	messages, err := h.store.List(traceCtx, currentUser.ID, filter, request.Page, request.Size)
+	// The page comes with the total count for pagination
+	messages, total, err := h.store.ListWithCount(traceCtx, currentUser.ID, filter, request.Page, request.Size)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -288,14 +413,20 @@ func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
 			Type:           message.Type,
 			PreviewMessage: previewMessage,
 			ContentID:      message.ContentID.String(),
+			ReplyTo:        optionalUUID(message.ReplyTo),
+			ThreadID:       optionalUUID(message.ThreadID),
+			SenderID:       optionalUUID(message.SenderID),
+			Body:           message.Body.String,
+			TagIDs:         tagIDStrings(message.TagIds),
 			CreatedAt:      message.CreatedAt.Time.Format(time.RFC3339),
 			UpdatedAt:      message.UpdatedAt.Time.Format(time.RFC3339),
 		},
 		Content: messageContent,
 		UserInboxMessageFilter: UserInboxMessageFilter{
-			IsRead:     message.IsRead,
-			IsStarred:  message.IsStarred,
-			IsArchived: message.IsArchived,
+			IsRead:       message.IsRead,
+			IsStarred:    message.IsStarred,
+			IsArchived:   message.IsArchived,
+			SnoozedUntil: optionalTime(message.SnoozedUntil),
 		},
 	}
 
@@ -315,7 +446,7 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req UserInboxMessageFilter
-	err = handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
@@ -328,9 +459,10 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	message, err := h.store.UpdateByID(traceCtx, id, currentUser.ID, UserInboxMessageFilter{
-		IsRead:     req.IsRead,
-		IsStarred:  req.IsStarred,
-		IsArchived: req.IsArchived,
+		IsRead:       req.IsRead,
+		IsStarred:    req.IsStarred,
+		IsArchived:   req.IsArchived,
+		SnoozedUntil: req.SnoozedUntil,
 	})
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, err, logger)
@@ -353,15 +485,258 @@ func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
 			Type:           message.Type,
 			PreviewMessage: previewMessage,
 			ContentID:      message.ContentID.String(),
+			ReplyTo:        optionalUUID(message.ReplyTo),
+			ThreadID:       optionalUUID(message.ThreadID),
+			SenderID:       optionalUUID(message.SenderID),
+			Body:           message.Body.String,
 			CreatedAt:      message.CreatedAt.Time.Format(time.RFC3339),
 			UpdatedAt:      message.UpdatedAt.Time.Format(time.RFC3339),
 		},
 		UserInboxMessageFilter: UserInboxMessageFilter{
-			IsRead:     message.IsRead,
-			IsStarred:  message.IsStarred,
-			IsArchived: message.IsArchived,
+			IsRead:       message.IsRead,
+			IsStarred:    message.IsStarred,
+			IsArchived:   message.IsArchived,
+			SnoozedUntil: optionalTime(message.SnoozedUntil),
 		},
 	}
 
 	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
 }
+
+func (h *Handler) ReplyHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ReplyHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ReplyRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	reply, err := h.store.Reply(traceCtx, id, currentUser.ID, req.Body)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := ToThreadMessageResponse(reply)
+	response.SenderName = currentUser.Name.String
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, response)
+}
+
+func (h *Handler) ThreadHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ThreadHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	thread, err := h.store.ListThread(traceCtx, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]ThreadMessageResponse, len(thread))
+	for i, message := range thread {
+		response[i] = ToThreadMessageResponse(message)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func parseUnitInboxPath(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("messageId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return unitID, id, nil
+}
+
+func (h *Handler) ListUnitInboxHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListUnitInboxHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	factory := pagutil.NewFactory[UnitInboxResponse](200, []string{"CreatedAt"})
+	request, err := factory.GetRequest(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	filter, err := ParseUnitInboxFilter(r, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	messages, total, err := h.store.ListUnitInbox(traceCtx, unitID, currentUser.ID, filter, request.Page, request.Size)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	mappedMessages := make([]UnitInboxResponse, len(messages))
+	for i, message := range messages {
+		mappedMessages[i] = ToUnitInboxResponse(message)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, factory.NewResponse(mappedMessages, int(total), request.Page, request.Size))
+}
+
+func (h *Handler) UpdateUnitInboxHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateUnitInboxHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	unitID, id, err := parseUnitInboxPath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req UnitInboxUpdateRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	update := UnitInboxUpdate{
+		IsRead:     req.IsRead,
+		IsArchived: req.IsArchived,
+	}
+	if req.AssigneeID != "" {
+		assigneeID, err := internal.ParseUUID(req.AssigneeID)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+		update.AssigneeID = &assigneeID
+	}
+
+	message, err := h.store.UpdateUnitInbox(traceCtx, unitID, id, currentUser.ID, update)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToUnitInboxResponse(ListUnitInboxRow(message)))
+}
+
+func (h *Handler) UnitInboxReplyHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UnitInboxReplyHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	unitID, id, err := parseUnitInboxPath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ReplyRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	reply, err := h.store.ReplyFromUnitInbox(traceCtx, unitID, id, currentUser.ID, req.Body)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := ToThreadMessageResponse(reply)
+	response.SenderName = currentUser.Name.String
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, response)
+}
+
+func (h *Handler) UnitInboxThreadHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UnitInboxThreadHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	unitID, id, err := parseUnitInboxPath(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	thread, err := h.store.ListUnitInboxThread(traceCtx, unitID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]ThreadMessageResponse, len(thread))
+	for i, message := range thread {
+		response[i] = ToThreadMessageResponse(message)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
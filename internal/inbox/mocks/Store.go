@@ -267,6 +267,512 @@ func (_c *MockStore_List_Call) RunAndReturn(run func(ctx context.Context, userID
 	return _c
 }
 
+// ListThread provides a mock function for the type MockStore
+func (_mock *MockStore) ListThread(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]inbox.ListThreadRow, error) {
+	ret := _mock.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListThread")
+	}
+
+	var r0 []inbox.ListThreadRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) ([]inbox.ListThreadRow, error)); ok {
+		return returnFunc(ctx, id, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID) []inbox.ListThreadRow); ok {
+		r0 = returnFunc(ctx, id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]inbox.ListThreadRow)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_ListThread_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListThread'
+type MockStore_ListThread_Call struct {
+	*mock.Call
+}
+
+// ListThread is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockStore_Expecter) ListThread(ctx interface{}, id interface{}, userID interface{}) *MockStore_ListThread_Call {
+	return &MockStore_ListThread_Call{Call: _e.mock.On("ListThread", ctx, id, userID)}
+}
+
+func (_c *MockStore_ListThread_Call) Run(run func(ctx context.Context, id uuid.UUID, userID uuid.UUID)) *MockStore_ListThread_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_ListThread_Call) Return(listThreadRows []inbox.ListThreadRow, err error) *MockStore_ListThread_Call {
+	_c.Call.Return(listThreadRows, err)
+	return _c
+}
+
+func (_c *MockStore_ListThread_Call) RunAndReturn(run func(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]inbox.ListThreadRow, error)) *MockStore_ListThread_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUnitInbox provides a mock function for the type MockStore
+func (_mock *MockStore) ListUnitInbox(ctx context.Context, unitID uuid.UUID, userID uuid.UUID, filter inbox.UnitInboxFilter, page int, size int) ([]inbox.ListUnitInboxRow, int64, error) {
+	ret := _mock.Called(ctx, unitID, userID, filter, page, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUnitInbox")
+	}
+
+	var r0 []inbox.ListUnitInboxRow
+	var r1 int64
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, inbox.UnitInboxFilter, int, int) ([]inbox.ListUnitInboxRow, int64, error)); ok {
+		return returnFunc(ctx, unitID, userID, filter, page, size)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, inbox.UnitInboxFilter, int, int) []inbox.ListUnitInboxRow); ok {
+		r0 = returnFunc(ctx, unitID, userID, filter, page, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]inbox.ListUnitInboxRow)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, inbox.UnitInboxFilter, int, int) int64); ok {
+		r1 = returnFunc(ctx, unitID, userID, filter, page, size)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, uuid.UUID, uuid.UUID, inbox.UnitInboxFilter, int, int) error); ok {
+		r2 = returnFunc(ctx, unitID, userID, filter, page, size)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockStore_ListUnitInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUnitInbox'
+type MockStore_ListUnitInbox_Call struct {
+	*mock.Call
+}
+
+// ListUnitInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - unitID uuid.UUID
+//   - userID uuid.UUID
+//   - filter inbox.UnitInboxFilter
+//   - page int
+//   - size int
+func (_e *MockStore_Expecter) ListUnitInbox(ctx interface{}, unitID interface{}, userID interface{}, filter interface{}, page interface{}, size interface{}) *MockStore_ListUnitInbox_Call {
+	return &MockStore_ListUnitInbox_Call{Call: _e.mock.On("ListUnitInbox", ctx, unitID, userID, filter, page, size)}
+}
+
+func (_c *MockStore_ListUnitInbox_Call) Run(run func(ctx context.Context, unitID uuid.UUID, userID uuid.UUID, filter inbox.UnitInboxFilter, page int, size int)) *MockStore_ListUnitInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 inbox.UnitInboxFilter
+		if args[3] != nil {
+			arg3 = args[3].(inbox.UnitInboxFilter)
+		}
+		var arg4 int
+		if args[4] != nil {
+			arg4 = args[4].(int)
+		}
+		var arg5 int
+		if args[5] != nil {
+			arg5 = args[5].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+			arg5,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_ListUnitInbox_Call) Return(listUnitInboxRows []inbox.ListUnitInboxRow, n int64, err error) *MockStore_ListUnitInbox_Call {
+	_c.Call.Return(listUnitInboxRows, n, err)
+	return _c
+}
+
+func (_c *MockStore_ListUnitInbox_Call) RunAndReturn(run func(ctx context.Context, unitID uuid.UUID, userID uuid.UUID, filter inbox.UnitInboxFilter, page int, size int) ([]inbox.ListUnitInboxRow, int64, error)) *MockStore_ListUnitInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUnitInboxThread provides a mock function for the type MockStore
+func (_mock *MockStore) ListUnitInboxThread(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID) ([]inbox.ListThreadRow, error) {
+	ret := _mock.Called(ctx, unitID, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUnitInboxThread")
+	}
+
+	var r0 []inbox.ListThreadRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) ([]inbox.ListThreadRow, error)); ok {
+		return returnFunc(ctx, unitID, id, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) []inbox.ListThreadRow); ok {
+		r0 = returnFunc(ctx, unitID, id, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]inbox.ListThreadRow)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r1 = returnFunc(ctx, unitID, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_ListUnitInboxThread_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUnitInboxThread'
+type MockStore_ListUnitInboxThread_Call struct {
+	*mock.Call
+}
+
+// ListUnitInboxThread is a helper method to define mock.On call
+//   - ctx context.Context
+//   - unitID uuid.UUID
+//   - id uuid.UUID
+//   - userID uuid.UUID
+func (_e *MockStore_Expecter) ListUnitInboxThread(ctx interface{}, unitID interface{}, id interface{}, userID interface{}) *MockStore_ListUnitInboxThread_Call {
+	return &MockStore_ListUnitInboxThread_Call{Call: _e.mock.On("ListUnitInboxThread", ctx, unitID, id, userID)}
+}
+
+func (_c *MockStore_ListUnitInboxThread_Call) Run(run func(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID)) *MockStore_ListUnitInboxThread_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 uuid.UUID
+		if args[3] != nil {
+			arg3 = args[3].(uuid.UUID)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_ListUnitInboxThread_Call) Return(listThreadRows []inbox.ListThreadRow, err error) *MockStore_ListUnitInboxThread_Call {
+	_c.Call.Return(listThreadRows, err)
+	return _c
+}
+
+func (_c *MockStore_ListUnitInboxThread_Call) RunAndReturn(run func(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID) ([]inbox.ListThreadRow, error)) *MockStore_ListUnitInboxThread_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWithCount provides a mock function for the type MockStore
+func (_mock *MockStore) ListWithCount(ctx context.Context, userID uuid.UUID, filter *inbox.FilterRequest, page int, size int) ([]inbox.ListRow, int64, error) {
+	ret := _mock.Called(ctx, userID, filter, page, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWithCount")
+	}
+
+	var r0 []inbox.ListRow
+	var r1 int64
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *inbox.FilterRequest, int, int) ([]inbox.ListRow, int64, error)); ok {
+		return returnFunc(ctx, userID, filter, page, size)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, *inbox.FilterRequest, int, int) []inbox.ListRow); ok {
+		r0 = returnFunc(ctx, userID, filter, page, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]inbox.ListRow)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, *inbox.FilterRequest, int, int) int64); ok {
+		r1 = returnFunc(ctx, userID, filter, page, size)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, uuid.UUID, *inbox.FilterRequest, int, int) error); ok {
+		r2 = returnFunc(ctx, userID, filter, page, size)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockStore_ListWithCount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWithCount'
+type MockStore_ListWithCount_Call struct {
+	*mock.Call
+}
+
+// ListWithCount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID uuid.UUID
+//   - filter *inbox.FilterRequest
+//   - page int
+//   - size int
+func (_e *MockStore_Expecter) ListWithCount(ctx interface{}, userID interface{}, filter interface{}, page interface{}, size interface{}) *MockStore_ListWithCount_Call {
+	return &MockStore_ListWithCount_Call{Call: _e.mock.On("ListWithCount", ctx, userID, filter, page, size)}
+}
+
+func (_c *MockStore_ListWithCount_Call) Run(run func(ctx context.Context, userID uuid.UUID, filter *inbox.FilterRequest, page int, size int)) *MockStore_ListWithCount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 *inbox.FilterRequest
+		if args[2] != nil {
+			arg2 = args[2].(*inbox.FilterRequest)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		var arg4 int
+		if args[4] != nil {
+			arg4 = args[4].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_ListWithCount_Call) Return(listRows []inbox.ListRow, n int64, err error) *MockStore_ListWithCount_Call {
+	_c.Call.Return(listRows, n, err)
+	return _c
+}
+
+func (_c *MockStore_ListWithCount_Call) RunAndReturn(run func(ctx context.Context, userID uuid.UUID, filter *inbox.FilterRequest, page int, size int) ([]inbox.ListRow, int64, error)) *MockStore_ListWithCount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reply provides a mock function for the type MockStore
+func (_mock *MockStore) Reply(ctx context.Context, id uuid.UUID, userID uuid.UUID, body string) (inbox.ListThreadRow, error) {
+	ret := _mock.Called(ctx, id, userID, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reply")
+	}
+
+	var r0 inbox.ListThreadRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) (inbox.ListThreadRow, error)); ok {
+		return returnFunc(ctx, id, userID, body)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, string) inbox.ListThreadRow); ok {
+		r0 = returnFunc(ctx, id, userID, body)
+	} else {
+		r0 = ret.Get(0).(inbox.ListThreadRow)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(ctx, id, userID, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_Reply_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reply'
+type MockStore_Reply_Call struct {
+	*mock.Call
+}
+
+// Reply is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uuid.UUID
+//   - userID uuid.UUID
+//   - body string
+func (_e *MockStore_Expecter) Reply(ctx interface{}, id interface{}, userID interface{}, body interface{}) *MockStore_Reply_Call {
+	return &MockStore_Reply_Call{Call: _e.mock.On("Reply", ctx, id, userID, body)}
+}
+
+func (_c *MockStore_Reply_Call) Run(run func(ctx context.Context, id uuid.UUID, userID uuid.UUID, body string)) *MockStore_Reply_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_Reply_Call) Return(listThreadRow inbox.ListThreadRow, err error) *MockStore_Reply_Call {
+	_c.Call.Return(listThreadRow, err)
+	return _c
+}
+
+func (_c *MockStore_Reply_Call) RunAndReturn(run func(ctx context.Context, id uuid.UUID, userID uuid.UUID, body string) (inbox.ListThreadRow, error)) *MockStore_Reply_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReplyFromUnitInbox provides a mock function for the type MockStore
+func (_mock *MockStore) ReplyFromUnitInbox(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, body string) (inbox.ListThreadRow, error) {
+	ret := _mock.Called(ctx, unitID, id, userID, body)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplyFromUnitInbox")
+	}
+
+	var r0 inbox.ListThreadRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string) (inbox.ListThreadRow, error)); ok {
+		return returnFunc(ctx, unitID, id, userID, body)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string) inbox.ListThreadRow); ok {
+		r0 = returnFunc(ctx, unitID, id, userID, body)
+	} else {
+		r0 = ret.Get(0).(inbox.ListThreadRow)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string) error); ok {
+		r1 = returnFunc(ctx, unitID, id, userID, body)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_ReplyFromUnitInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplyFromUnitInbox'
+type MockStore_ReplyFromUnitInbox_Call struct {
+	*mock.Call
+}
+
+// ReplyFromUnitInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - unitID uuid.UUID
+//   - id uuid.UUID
+//   - userID uuid.UUID
+//   - body string
+func (_e *MockStore_Expecter) ReplyFromUnitInbox(ctx interface{}, unitID interface{}, id interface{}, userID interface{}, body interface{}) *MockStore_ReplyFromUnitInbox_Call {
+	return &MockStore_ReplyFromUnitInbox_Call{Call: _e.mock.On("ReplyFromUnitInbox", ctx, unitID, id, userID, body)}
+}
+
+func (_c *MockStore_ReplyFromUnitInbox_Call) Run(run func(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, body string)) *MockStore_ReplyFromUnitInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 uuid.UUID
+		if args[3] != nil {
+			arg3 = args[3].(uuid.UUID)
+		}
+		var arg4 string
+		if args[4] != nil {
+			arg4 = args[4].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_ReplyFromUnitInbox_Call) Return(listThreadRow inbox.ListThreadRow, err error) *MockStore_ReplyFromUnitInbox_Call {
+	_c.Call.Return(listThreadRow, err)
+	return _c
+}
+
+func (_c *MockStore_ReplyFromUnitInbox_Call) RunAndReturn(run func(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, body string) (inbox.ListThreadRow, error)) *MockStore_ReplyFromUnitInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateByID provides a mock function for the type MockStore
 func (_mock *MockStore) UpdateByID(ctx context.Context, id uuid.UUID, userID uuid.UUID, arg inbox.UserInboxMessageFilter) (inbox.UpdateByIDRow, error) {
 	ret := _mock.Called(ctx, id, userID, arg)
@@ -344,3 +850,87 @@ func (_c *MockStore_UpdateByID_Call) RunAndReturn(run func(ctx context.Context,
 	_c.Call.Return(run)
 	return _c
 }
+
+// UpdateUnitInbox provides a mock function for the type MockStore
+func (_mock *MockStore) UpdateUnitInbox(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, update inbox.UnitInboxUpdate) (inbox.GetUnitInboxByIDRow, error) {
+	ret := _mock.Called(ctx, unitID, id, userID, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUnitInbox")
+	}
+
+	var r0 inbox.GetUnitInboxByIDRow
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, inbox.UnitInboxUpdate) (inbox.GetUnitInboxByIDRow, error)); ok {
+		return returnFunc(ctx, unitID, id, userID, update)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, inbox.UnitInboxUpdate) inbox.GetUnitInboxByIDRow); ok {
+		r0 = returnFunc(ctx, unitID, id, userID, update)
+	} else {
+		r0 = ret.Get(0).(inbox.GetUnitInboxByIDRow)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, inbox.UnitInboxUpdate) error); ok {
+		r1 = returnFunc(ctx, unitID, id, userID, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_UpdateUnitInbox_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUnitInbox'
+type MockStore_UpdateUnitInbox_Call struct {
+	*mock.Call
+}
+
+// UpdateUnitInbox is a helper method to define mock.On call
+//   - ctx context.Context
+//   - unitID uuid.UUID
+//   - id uuid.UUID
+//   - userID uuid.UUID
+//   - update inbox.UnitInboxUpdate
+func (_e *MockStore_Expecter) UpdateUnitInbox(ctx interface{}, unitID interface{}, id interface{}, userID interface{}, update interface{}) *MockStore_UpdateUnitInbox_Call {
+	return &MockStore_UpdateUnitInbox_Call{Call: _e.mock.On("UpdateUnitInbox", ctx, unitID, id, userID, update)}
+}
+
+func (_c *MockStore_UpdateUnitInbox_Call) Run(run func(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, update inbox.UnitInboxUpdate)) *MockStore_UpdateUnitInbox_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uuid.UUID
+		if args[1] != nil {
+			arg1 = args[1].(uuid.UUID)
+		}
+		var arg2 uuid.UUID
+		if args[2] != nil {
+			arg2 = args[2].(uuid.UUID)
+		}
+		var arg3 uuid.UUID
+		if args[3] != nil {
+			arg3 = args[3].(uuid.UUID)
+		}
+		var arg4 inbox.UnitInboxUpdate
+		if args[4] != nil {
+			arg4 = args[4].(inbox.UnitInboxUpdate)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_UpdateUnitInbox_Call) Return(getUnitInboxByIDRow inbox.GetUnitInboxByIDRow, err error) *MockStore_UpdateUnitInbox_Call {
+	_c.Call.Return(getUnitInboxByIDRow, err)
+	return _c
+}
+
+func (_c *MockStore_UpdateUnitInbox_Call) RunAndReturn(run func(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, update inbox.UnitInboxUpdate) (inbox.GetUnitInboxByIDRow, error)) *MockStore_UpdateUnitInbox_Call {
+	_c.Call.Return(run)
+	return _c
+}
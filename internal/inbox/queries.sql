@@ -12,14 +12,20 @@ RETURNING *;
 SELECT 
     uim.*,
     im.*,
-    CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) END AS preview_message,
-    CASE WHEN im.type = 'form' THEN f.title END AS title,
-    CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-    CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+    CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25))
+         WHEN im.type = 'task' THEN LEFT(t.description, 25)
+         WHEN im.type = 'quota' THEN qw.usage || ' of ' || qw.quota_limit || ' used' END AS preview_message,
+    CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title
+         WHEN im.type = 'quota' THEN 'Quota of ' || qw.resource::text || ' at ' || qw.threshold || '%' END AS title,
+    CASE WHEN im.type IN ('form', 'task', 'quota') THEN COALESCE(o.name, u.name) END AS org_name,
+    CASE WHEN im.type IN ('form', 'task') AND u.type = 'unit' THEN u.name END AS unit_name,
+    ARRAY(SELECT mt.tag_id FROM inbox_message_tags mt WHERE mt.message_id = im.id)::uuid[] AS tag_ids
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
 LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
-LEFT JOIN units u ON f.unit_id = u.id
+LEFT JOIN tasks t ON im.type = 'task' AND im.content_id = t.id
+LEFT JOIN quota_warnings qw ON im.type = 'quota' AND im.content_id = qw.id
+LEFT JOIN units u ON u.id = COALESCE(f.unit_id, t.unit_id, qw.org_id)
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.id = @user_inbox_message_id AND uim.user_id = @user_id;
 
@@ -27,24 +33,34 @@ WHERE uim.id = @user_inbox_message_id AND uim.user_id = @user_id;
 SELECT 
     uim.*,
     im.*,
-    CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) END AS preview_message,
-    CASE WHEN im.type = 'form' THEN f.title END AS title,
-    CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-    CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+    CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25))
+         WHEN im.type = 'task' THEN LEFT(t.description, 25)
+         WHEN im.type = 'quota' THEN qw.usage || ' of ' || qw.quota_limit || ' used' END AS preview_message,
+    CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title
+         WHEN im.type = 'quota' THEN 'Quota of ' || qw.resource::text || ' at ' || qw.threshold || '%' END AS title,
+    CASE WHEN im.type IN ('form', 'task', 'quota') THEN COALESCE(o.name, u.name) END AS org_name,
+    CASE WHEN im.type IN ('form', 'task') AND u.type = 'unit' THEN u.name END AS unit_name,
+    ARRAY(SELECT mt.tag_id FROM inbox_message_tags mt WHERE mt.message_id = im.id)::uuid[] AS tag_ids
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
 LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
-LEFT JOIN units u ON f.unit_id = u.id
+LEFT JOIN tasks t ON im.type = 'task' AND im.content_id = t.id
+LEFT JOIN quota_warnings qw ON im.type = 'quota' AND im.content_id = qw.id
+LEFT JOIN units u ON u.id = COALESCE(f.unit_id, t.unit_id, qw.org_id)
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.user_id = @user_id
   AND (sqlc.narg(is_read)::boolean IS NULL OR uim.is_read = sqlc.narg(is_read))
   AND (sqlc.narg(is_starred)::boolean IS NULL OR uim.is_starred = sqlc.narg(is_starred))
   AND (uim.is_archived = COALESCE(sqlc.narg(is_archived)::boolean, false))
+  AND ((uim.snoozed_until IS NOT NULL AND uim.snoozed_until > now()) = COALESCE(sqlc.narg(is_snoozed)::boolean, false))
   AND (@search::text = '' OR @search::text IS NULL OR (
-    CASE WHEN im.type = 'form' THEN f.title ELSE '' END ILIKE '%' || @search::text || '%'
+    CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title ELSE '' END ILIKE '%' || @search::text || '%'
     OR CASE WHEN im.type = 'form' THEN f.description ELSE '' END ILIKE '%' || @search::text || '%'
     OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) ELSE '' END ILIKE '%' || @search::text || '%'
   ))
+  AND (cardinality(@tag_ids::uuid[]) = 0 OR EXISTS (
+    SELECT 1 FROM inbox_message_tags mt WHERE mt.message_id = im.id AND mt.tag_id = ANY(@tag_ids::uuid[])
+  ))
 LIMIT COALESCE(@page_limit::int, 10)
 OFFSET COALESCE(@page_offset::int, 0);
 
@@ -54,28 +70,129 @@ SELECT
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
 LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
-LEFT JOIN units u ON f.unit_id = u.id
+LEFT JOIN tasks t ON im.type = 'task' AND im.content_id = t.id
+LEFT JOIN quota_warnings qw ON im.type = 'quota' AND im.content_id = qw.id
+LEFT JOIN units u ON u.id = COALESCE(f.unit_id, t.unit_id, qw.org_id)
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.user_id = @user_id
   AND (sqlc.narg(is_read)::boolean IS NULL OR uim.is_read = sqlc.narg(is_read))
   AND (sqlc.narg(is_starred)::boolean IS NULL OR uim.is_starred = sqlc.narg(is_starred))
   AND (uim.is_archived = COALESCE(sqlc.narg(is_archived)::boolean, false))
+  AND ((uim.snoozed_until IS NOT NULL AND uim.snoozed_until > now()) = COALESCE(sqlc.narg(is_snoozed)::boolean, false))
   AND (@search::text = '' OR @search::text IS NULL OR (
-    CASE WHEN im.type = 'form' THEN f.title ELSE '' END ILIKE '%' || @search::text || '%'
+    CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title ELSE '' END ILIKE '%' || @search::text || '%'
     OR CASE WHEN im.type = 'form' THEN f.description ELSE '' END ILIKE '%' || @search::text || '%'
     OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) ELSE '' END ILIKE '%' || @search::text || '%'
+  ))
+  AND (cardinality(@tag_ids::uuid[]) = 0 OR EXISTS (
+    SELECT 1 FROM inbox_message_tags mt WHERE mt.message_id = im.id AND mt.tag_id = ANY(@tag_ids::uuid[])
   ));
 
 -- name: UpdateByID :one
 UPDATE user_inbox_messages AS uim
-SET is_read = @is_read, is_starred = @is_starred, is_archived = @is_archived
+SET is_read = @is_read, is_starred = @is_starred, is_archived = @is_archived, snoozed_until = sqlc.narg(snoozed_until)
 FROM inbox_message AS im
 LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
-LEFT JOIN units u ON f.unit_id = u.id
+LEFT JOIN tasks t ON im.type = 'task' AND im.content_id = t.id
+LEFT JOIN quota_warnings qw ON im.type = 'quota' AND im.content_id = qw.id
+LEFT JOIN units u ON u.id = COALESCE(f.unit_id, t.unit_id, qw.org_id)
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.message_id = im.id AND uim.id = @id AND uim.user_id = @user_id
 RETURNING uim.*, im.*,
-CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) END AS preview_message,
-CASE WHEN im.type = 'form' THEN f.title END AS title,
-CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name;
+CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25))
+     WHEN im.type = 'task' THEN LEFT(t.description, 25)
+     WHEN im.type = 'quota' THEN qw.usage || ' of ' || qw.quota_limit || ' used' END AS preview_message,
+CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title
+     WHEN im.type = 'quota' THEN 'Quota of ' || qw.resource::text || ' at ' || qw.threshold || '%' END AS title,
+CASE WHEN im.type IN ('form', 'task', 'quota') THEN COALESCE(o.name, u.name) END AS org_name,
+CASE WHEN im.type IN ('form', 'task') AND u.type = 'unit' THEN u.name END AS unit_name;
+
+
+-- name: CreateReply :one
+INSERT INTO inbox_message (posted_by, type, content_id, reply_to, thread_id, sender_id, body)
+VALUES (@posted_by, 'text', @content_id, @reply_to, @thread_id, @sender_id, @body)
+RETURNING *;
+
+-- name: ListUnitMemberIDs :many
+SELECT member_id FROM unit_members
+WHERE unit_id = @unit_id;
+
+-- name: ListThread :many
+SELECT
+    im.*,
+    uim.id AS user_inbox_message_id,
+    uim.is_read,
+    su.name AS sender_name
+FROM inbox_message im
+LEFT JOIN user_inbox_messages uim ON uim.message_id = im.id AND uim.user_id = @user_id
+LEFT JOIN users su ON im.sender_id = su.id
+WHERE (im.id = @thread_id OR im.thread_id = @thread_id)
+  AND (
+    uim.id IS NOT NULL
+    OR im.id = @thread_id
+    OR EXISTS(
+        SELECT 1 FROM unit_inbox_messages uni
+        JOIN unit_members um ON um.unit_id = uni.unit_id
+        WHERE uni.message_id = im.id AND um.member_id = @user_id
+    )
+  )
+ORDER BY im.created_at ASC;
+
+-- name: IsUnitMember :one
+SELECT EXISTS(SELECT 1 FROM unit_members WHERE unit_id = @unit_id AND member_id = @member_id);
+
+-- name: CreateUnitInbox :one
+INSERT INTO unit_inbox_messages (unit_id, message_id, is_read)
+VALUES (@unit_id, @message_id, @is_read)
+RETURNING *;
+
+-- name: GetUnitInboxByID :one
+SELECT
+    uni.id, uni.unit_id, uni.message_id, uni.assignee_id, uni.is_read, uni.is_archived,
+    im.posted_by, im.type, im.content_id, im.reply_to, im.thread_id, im.sender_id, im.body, im.created_at,
+    su.name AS sender_name,
+    au.name AS assignee_name
+FROM unit_inbox_messages uni
+JOIN inbox_message im ON uni.message_id = im.id
+LEFT JOIN users su ON im.sender_id = su.id
+LEFT JOIN users au ON uni.assignee_id = au.id
+WHERE uni.id = @id AND uni.unit_id = @unit_id;
+
+-- name: ListUnitInbox :many
+SELECT
+    uni.id, uni.unit_id, uni.message_id, uni.assignee_id, uni.is_read, uni.is_archived,
+    im.posted_by, im.type, im.content_id, im.reply_to, im.thread_id, im.sender_id, im.body, im.created_at,
+    su.name AS sender_name,
+    au.name AS assignee_name
+FROM unit_inbox_messages uni
+JOIN inbox_message im ON uni.message_id = im.id
+LEFT JOIN users su ON im.sender_id = su.id
+LEFT JOIN users au ON uni.assignee_id = au.id
+WHERE uni.unit_id = @unit_id
+  AND (sqlc.narg(is_read)::boolean IS NULL OR uni.is_read = sqlc.narg(is_read))
+  AND (uni.is_archived = COALESCE(sqlc.narg(is_archived)::boolean, false))
+  AND (sqlc.narg(assignee_id)::uuid IS NULL OR uni.assignee_id = sqlc.narg(assignee_id))
+  AND (NOT @unassigned::boolean OR uni.assignee_id IS NULL)
+ORDER BY im.created_at DESC
+LIMIT COALESCE(@page_limit::int, 10)
+OFFSET COALESCE(@page_offset::int, 0);
+
+-- name: CountUnitInbox :one
+SELECT COUNT(*) AS total
+FROM unit_inbox_messages uni
+WHERE uni.unit_id = @unit_id
+  AND (sqlc.narg(is_read)::boolean IS NULL OR uni.is_read = sqlc.narg(is_read))
+  AND (uni.is_archived = COALESCE(sqlc.narg(is_archived)::boolean, false))
+  AND (sqlc.narg(assignee_id)::uuid IS NULL OR uni.assignee_id = sqlc.narg(assignee_id))
+  AND (NOT @unassigned::boolean OR uni.assignee_id IS NULL);
+
+-- name: UpdateUnitInbox :execrows
+UPDATE unit_inbox_messages
+SET is_read = @is_read, is_archived = @is_archived, assignee_id = @assignee_id, updated_at = now()
+WHERE id = @id AND unit_id = @unit_id;
+
+-- name: ResurfaceSnoozed :many
+UPDATE user_inbox_messages
+SET is_read = false, snoozed_until = NULL
+WHERE snoozed_until IS NOT NULL AND snoozed_until <= now()
+RETURNING *;
\ No newline at end of file
@@ -12,10 +12,41 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countUnitInbox = `-- name: CountUnitInbox :one
+SELECT COUNT(*) AS total
+FROM unit_inbox_messages uni
+WHERE uni.unit_id = $1
+  AND ($2::boolean IS NULL OR uni.is_read = $2)
+  AND (uni.is_archived = COALESCE($3::boolean, false))
+  AND ($4::uuid IS NULL OR uni.assignee_id = $4)
+  AND (NOT $5::boolean OR uni.assignee_id IS NULL)
+`
+
+type CountUnitInboxParams struct {
+	UnitID     uuid.UUID
+	IsRead     pgtype.Bool
+	IsArchived pgtype.Bool
+	AssigneeID pgtype.UUID
+	Unassigned bool
+}
+
+func (q *Queries) CountUnitInbox(ctx context.Context, arg CountUnitInboxParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUnitInbox,
+		arg.UnitID,
+		arg.IsRead,
+		arg.IsArchived,
+		arg.AssigneeID,
+		arg.Unassigned,
+	)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const createMessage = `-- name: CreateMessage :one
 INSERT INTO inbox_message (posted_by, type, content_id)
 VALUES ($1, $2, $3)
-RETURNING id, posted_by, type, content_id, created_at, updated_at
+RETURNING id, posted_by, type, content_id, created_at, updated_at, reply_to, thread_id, sender_id, body
 `
 
 type CreateMessageParams struct {
@@ -34,6 +65,78 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (I
 		&i.ContentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReplyTo,
+		&i.ThreadID,
+		&i.SenderID,
+		&i.Body,
+	)
+	return i, err
+}
+
+const createReply = `-- name: CreateReply :one
+INSERT INTO inbox_message (posted_by, type, content_id, reply_to, thread_id, sender_id, body)
+VALUES ($1, 'text', $2, $3, $4, $5, $6)
+RETURNING id, posted_by, type, content_id, created_at, updated_at, reply_to, thread_id, sender_id, body
+`
+
+type CreateReplyParams struct {
+	PostedBy  uuid.UUID
+	ContentID uuid.UUID
+	ReplyTo   pgtype.UUID
+	ThreadID  pgtype.UUID
+	SenderID  pgtype.UUID
+	Body      pgtype.Text
+}
+
+func (q *Queries) CreateReply(ctx context.Context, arg CreateReplyParams) (InboxMessage, error) {
+	row := q.db.QueryRow(ctx, createReply,
+		arg.PostedBy,
+		arg.ContentID,
+		arg.ReplyTo,
+		arg.ThreadID,
+		arg.SenderID,
+		arg.Body,
+	)
+	var i InboxMessage
+	err := row.Scan(
+		&i.ID,
+		&i.PostedBy,
+		&i.Type,
+		&i.ContentID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReplyTo,
+		&i.ThreadID,
+		&i.SenderID,
+		&i.Body,
+	)
+	return i, err
+}
+
+const createUnitInbox = `-- name: CreateUnitInbox :one
+INSERT INTO unit_inbox_messages (unit_id, message_id, is_read)
+VALUES ($1, $2, $3)
+RETURNING id, unit_id, message_id, assignee_id, is_read, is_archived, created_at, updated_at
+`
+
+type CreateUnitInboxParams struct {
+	UnitID    uuid.UUID
+	MessageID uuid.UUID
+	IsRead    bool
+}
+
+func (q *Queries) CreateUnitInbox(ctx context.Context, arg CreateUnitInboxParams) (UnitInboxMessage, error) {
+	row := q.db.QueryRow(ctx, createUnitInbox, arg.UnitID, arg.MessageID, arg.IsRead)
+	var i UnitInboxMessage
+	err := row.Scan(
+		&i.ID,
+		&i.UnitID,
+		&i.MessageID,
+		&i.AssigneeID,
+		&i.IsRead,
+		&i.IsArchived,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -41,7 +144,7 @@ func (q *Queries) CreateMessage(ctx context.Context, arg CreateMessageParams) (I
 const createUserInboxBulk = `-- name: CreateUserInboxBulk :many
 INSERT INTO user_inbox_messages (user_id, message_id)
 SELECT unnest($1::uuid[]), $2::uuid
-RETURNING id, user_id, message_id, is_read, is_starred, is_archived
+RETURNING id, user_id, message_id, is_read, is_starred, is_archived, snoozed_until
 `
 
 type CreateUserInboxBulkParams struct {
@@ -65,6 +168,7 @@ func (q *Queries) CreateUserInboxBulk(ctx context.Context, arg CreateUserInboxBu
 			&i.IsRead,
 			&i.IsStarred,
 			&i.IsArchived,
+			&i.SnoozedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -78,16 +182,22 @@ func (q *Queries) CreateUserInboxBulk(ctx context.Context, arg CreateUserInboxBu
 
 const getByID = `-- name: GetByID :one
 SELECT 
-    uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived,
-    im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at,
-    CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) END AS preview_message,
-    CASE WHEN im.type = 'form' THEN f.title END AS title,
-    CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-    CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+    uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived, uim.snoozed_until,
+    im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at, im.reply_to, im.thread_id, im.sender_id, im.body,
+    CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25))
+         WHEN im.type = 'task' THEN LEFT(t.description, 25)
+         WHEN im.type = 'quota' THEN qw.usage || ' of ' || qw.quota_limit || ' used' END AS preview_message,
+    CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title
+         WHEN im.type = 'quota' THEN 'Quota of ' || qw.resource::text || ' at ' || qw.threshold || '%' END AS title,
+    CASE WHEN im.type IN ('form', 'task', 'quota') THEN COALESCE(o.name, u.name) END AS org_name,
+    CASE WHEN im.type IN ('form', 'task') AND u.type = 'unit' THEN u.name END AS unit_name,
+    ARRAY(SELECT mt.tag_id FROM inbox_message_tags mt WHERE mt.message_id = im.id)::uuid[] AS tag_ids
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
 LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
-LEFT JOIN units u ON f.unit_id = u.id
+LEFT JOIN tasks t ON im.type = 'task' AND im.content_id = t.id
+LEFT JOIN quota_warnings qw ON im.type = 'quota' AND im.content_id = qw.id
+LEFT JOIN units u ON u.id = COALESCE(f.unit_id, t.unit_id, qw.org_id)
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.id = $1 AND uim.user_id = $2
 `
@@ -104,16 +214,22 @@ type GetByIDRow struct {
 	IsRead         bool
 	IsStarred      bool
 	IsArchived     bool
+	SnoozedUntil   pgtype.Timestamptz
 	ID_2           uuid.UUID
 	PostedBy       uuid.UUID
 	Type           ContentType
 	ContentID      uuid.UUID
 	CreatedAt      pgtype.Timestamp
 	UpdatedAt      pgtype.Timestamp
+	ReplyTo        pgtype.UUID
+	ThreadID       pgtype.UUID
+	SenderID       pgtype.UUID
+	Body           pgtype.Text
 	PreviewMessage interface{}
 	Title          interface{}
 	OrgName        interface{}
 	UnitName       interface{}
+	TagIds         []uuid.UUID
 }
 
 func (q *Queries) GetByID(ctx context.Context, arg GetByIDParams) (GetByIDRow, error) {
@@ -126,44 +242,137 @@ func (q *Queries) GetByID(ctx context.Context, arg GetByIDParams) (GetByIDRow, e
 		&i.IsRead,
 		&i.IsStarred,
 		&i.IsArchived,
+		&i.SnoozedUntil,
 		&i.ID_2,
 		&i.PostedBy,
 		&i.Type,
 		&i.ContentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReplyTo,
+		&i.ThreadID,
+		&i.SenderID,
+		&i.Body,
 		&i.PreviewMessage,
 		&i.Title,
 		&i.OrgName,
 		&i.UnitName,
+		&i.TagIds,
 	)
 	return i, err
 }
 
+const getUnitInboxByID = `-- name: GetUnitInboxByID :one
+SELECT
+    uni.id, uni.unit_id, uni.message_id, uni.assignee_id, uni.is_read, uni.is_archived,
+    im.posted_by, im.type, im.content_id, im.reply_to, im.thread_id, im.sender_id, im.body, im.created_at,
+    su.name AS sender_name,
+    au.name AS assignee_name
+FROM unit_inbox_messages uni
+JOIN inbox_message im ON uni.message_id = im.id
+LEFT JOIN users su ON im.sender_id = su.id
+LEFT JOIN users au ON uni.assignee_id = au.id
+WHERE uni.id = $1 AND uni.unit_id = $2
+`
+
+type GetUnitInboxByIDParams struct {
+	ID     uuid.UUID
+	UnitID uuid.UUID
+}
+
+type GetUnitInboxByIDRow struct {
+	ID           uuid.UUID
+	UnitID       uuid.UUID
+	MessageID    uuid.UUID
+	AssigneeID   pgtype.UUID
+	IsRead       bool
+	IsArchived   bool
+	PostedBy     uuid.UUID
+	Type         ContentType
+	ContentID    uuid.UUID
+	ReplyTo      pgtype.UUID
+	ThreadID     pgtype.UUID
+	SenderID     pgtype.UUID
+	Body         pgtype.Text
+	CreatedAt    pgtype.Timestamp
+	SenderName   pgtype.Text
+	AssigneeName pgtype.Text
+}
+
+func (q *Queries) GetUnitInboxByID(ctx context.Context, arg GetUnitInboxByIDParams) (GetUnitInboxByIDRow, error) {
+	row := q.db.QueryRow(ctx, getUnitInboxByID, arg.ID, arg.UnitID)
+	var i GetUnitInboxByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.UnitID,
+		&i.MessageID,
+		&i.AssigneeID,
+		&i.IsRead,
+		&i.IsArchived,
+		&i.PostedBy,
+		&i.Type,
+		&i.ContentID,
+		&i.ReplyTo,
+		&i.ThreadID,
+		&i.SenderID,
+		&i.Body,
+		&i.CreatedAt,
+		&i.SenderName,
+		&i.AssigneeName,
+	)
+	return i, err
+}
+
+const isUnitMember = `-- name: IsUnitMember :one
+SELECT EXISTS(SELECT 1 FROM unit_members WHERE unit_id = $1 AND member_id = $2)
+`
+
+type IsUnitMemberParams struct {
+	UnitID   uuid.UUID
+	MemberID uuid.UUID
+}
+
+func (q *Queries) IsUnitMember(ctx context.Context, arg IsUnitMemberParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isUnitMember, arg.UnitID, arg.MemberID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const list = `-- name: List :many
 SELECT 
-    uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived,
-    im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at,
-    CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) END AS preview_message,
-    CASE WHEN im.type = 'form' THEN f.title END AS title,
-    CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-    CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+    uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived, uim.snoozed_until,
+    im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at, im.reply_to, im.thread_id, im.sender_id, im.body,
+    CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25))
+         WHEN im.type = 'task' THEN LEFT(t.description, 25)
+         WHEN im.type = 'quota' THEN qw.usage || ' of ' || qw.quota_limit || ' used' END AS preview_message,
+    CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title
+         WHEN im.type = 'quota' THEN 'Quota of ' || qw.resource::text || ' at ' || qw.threshold || '%' END AS title,
+    CASE WHEN im.type IN ('form', 'task', 'quota') THEN COALESCE(o.name, u.name) END AS org_name,
+    CASE WHEN im.type IN ('form', 'task') AND u.type = 'unit' THEN u.name END AS unit_name,
+    ARRAY(SELECT mt.tag_id FROM inbox_message_tags mt WHERE mt.message_id = im.id)::uuid[] AS tag_ids
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
 LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
-LEFT JOIN units u ON f.unit_id = u.id
+LEFT JOIN tasks t ON im.type = 'task' AND im.content_id = t.id
+LEFT JOIN quota_warnings qw ON im.type = 'quota' AND im.content_id = qw.id
+LEFT JOIN units u ON u.id = COALESCE(f.unit_id, t.unit_id, qw.org_id)
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.user_id = $1
   AND ($2::boolean IS NULL OR uim.is_read = $2)
   AND ($3::boolean IS NULL OR uim.is_starred = $3)
   AND (uim.is_archived = COALESCE($4::boolean, false))
-  AND ($5::text = '' OR $5::text IS NULL OR (
-    CASE WHEN im.type = 'form' THEN f.title ELSE '' END ILIKE '%' || $5::text || '%'
-    OR CASE WHEN im.type = 'form' THEN f.description ELSE '' END ILIKE '%' || $5::text || '%'
-    OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) ELSE '' END ILIKE '%' || $5::text || '%'
+  AND ((uim.snoozed_until IS NOT NULL AND uim.snoozed_until > now()) = COALESCE($5::boolean, false))
+  AND ($6::text = '' OR $6::text IS NULL OR (
+    CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title ELSE '' END ILIKE '%' || $6::text || '%'
+    OR CASE WHEN im.type = 'form' THEN f.description ELSE '' END ILIKE '%' || $6::text || '%'
+    OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) ELSE '' END ILIKE '%' || $6::text || '%'
   ))
-LIMIT COALESCE($7::int, 10)
-OFFSET COALESCE($6::int, 0)
+  AND (cardinality($7::uuid[]) = 0 OR EXISTS (
+    SELECT 1 FROM inbox_message_tags mt WHERE mt.message_id = im.id AND mt.tag_id = ANY($7::uuid[])
+  ))
+LIMIT COALESCE($9::int, 10)
+OFFSET COALESCE($8::int, 0)
 `
 
 type ListParams struct {
@@ -171,7 +380,9 @@ type ListParams struct {
 	IsRead     pgtype.Bool
 	IsStarred  pgtype.Bool
 	IsArchived pgtype.Bool
+	IsSnoozed  pgtype.Bool
 	Search     string
+	TagIds     []uuid.UUID
 	PageOffset int32
 	PageLimit  int32
 }
@@ -183,16 +394,22 @@ type ListRow struct {
 	IsRead         bool
 	IsStarred      bool
 	IsArchived     bool
+	SnoozedUntil   pgtype.Timestamptz
 	ID_2           uuid.UUID
 	PostedBy       uuid.UUID
 	Type           ContentType
 	ContentID      uuid.UUID
 	CreatedAt      pgtype.Timestamp
 	UpdatedAt      pgtype.Timestamp
+	ReplyTo        pgtype.UUID
+	ThreadID       pgtype.UUID
+	SenderID       pgtype.UUID
+	Body           pgtype.Text
 	PreviewMessage interface{}
 	Title          interface{}
 	OrgName        interface{}
 	UnitName       interface{}
+	TagIds         []uuid.UUID
 }
 
 func (q *Queries) List(ctx context.Context, arg ListParams) ([]ListRow, error) {
@@ -201,7 +418,9 @@ func (q *Queries) List(ctx context.Context, arg ListParams) ([]ListRow, error) {
 		arg.IsRead,
 		arg.IsStarred,
 		arg.IsArchived,
+		arg.IsSnoozed,
 		arg.Search,
+		arg.TagIds,
 		arg.PageOffset,
 		arg.PageLimit,
 	)
@@ -219,16 +438,22 @@ func (q *Queries) List(ctx context.Context, arg ListParams) ([]ListRow, error) {
 			&i.IsRead,
 			&i.IsStarred,
 			&i.IsArchived,
+			&i.SnoozedUntil,
 			&i.ID_2,
 			&i.PostedBy,
 			&i.Type,
 			&i.ContentID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ReplyTo,
+			&i.ThreadID,
+			&i.SenderID,
+			&i.Body,
 			&i.PreviewMessage,
 			&i.Title,
 			&i.OrgName,
 			&i.UnitName,
+			&i.TagIds,
 		); err != nil {
 			return nil, err
 		}
@@ -246,16 +471,22 @@ SELECT
 FROM user_inbox_messages uim
 JOIN inbox_message im ON uim.message_id = im.id
 LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
-LEFT JOIN units u ON f.unit_id = u.id
+LEFT JOIN tasks t ON im.type = 'task' AND im.content_id = t.id
+LEFT JOIN quota_warnings qw ON im.type = 'quota' AND im.content_id = qw.id
+LEFT JOIN units u ON u.id = COALESCE(f.unit_id, t.unit_id, qw.org_id)
 LEFT JOIN units o ON u.org_id = o.id
 WHERE uim.user_id = $1
   AND ($2::boolean IS NULL OR uim.is_read = $2)
   AND ($3::boolean IS NULL OR uim.is_starred = $3)
   AND (uim.is_archived = COALESCE($4::boolean, false))
-  AND ($5::text = '' OR $5::text IS NULL OR (
-    CASE WHEN im.type = 'form' THEN f.title ELSE '' END ILIKE '%' || $5::text || '%'
-    OR CASE WHEN im.type = 'form' THEN f.description ELSE '' END ILIKE '%' || $5::text || '%'
-    OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) ELSE '' END ILIKE '%' || $5::text || '%'
+  AND ((uim.snoozed_until IS NOT NULL AND uim.snoozed_until > now()) = COALESCE($5::boolean, false))
+  AND ($6::text = '' OR $6::text IS NULL OR (
+    CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title ELSE '' END ILIKE '%' || $6::text || '%'
+    OR CASE WHEN im.type = 'form' THEN f.description ELSE '' END ILIKE '%' || $6::text || '%'
+    OR CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) ELSE '' END ILIKE '%' || $6::text || '%'
+  ))
+  AND (cardinality($7::uuid[]) = 0 OR EXISTS (
+    SELECT 1 FROM inbox_message_tags mt WHERE mt.message_id = im.id AND mt.tag_id = ANY($7::uuid[])
   ))
 `
 
@@ -264,7 +495,9 @@ type ListCountParams struct {
 	IsRead     pgtype.Bool
 	IsStarred  pgtype.Bool
 	IsArchived pgtype.Bool
+	IsSnoozed  pgtype.Bool
 	Search     string
+	TagIds     []uuid.UUID
 }
 
 func (q *Queries) ListCount(ctx context.Context, arg ListCountParams) (int64, error) {
@@ -273,34 +506,273 @@ func (q *Queries) ListCount(ctx context.Context, arg ListCountParams) (int64, er
 		arg.IsRead,
 		arg.IsStarred,
 		arg.IsArchived,
+		arg.IsSnoozed,
 		arg.Search,
+		arg.TagIds,
 	)
 	var total int64
 	err := row.Scan(&total)
 	return total, err
 }
 
+const listThread = `-- name: ListThread :many
+SELECT
+    im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at, im.reply_to, im.thread_id, im.sender_id, im.body,
+    uim.id AS user_inbox_message_id,
+    uim.is_read,
+    su.name AS sender_name
+FROM inbox_message im
+LEFT JOIN user_inbox_messages uim ON uim.message_id = im.id AND uim.user_id = $1
+LEFT JOIN users su ON im.sender_id = su.id
+WHERE (im.id = $2 OR im.thread_id = $2)
+  AND (
+    uim.id IS NOT NULL
+    OR im.id = $2
+    OR EXISTS(
+        SELECT 1 FROM unit_inbox_messages uni
+        JOIN unit_members um ON um.unit_id = uni.unit_id
+        WHERE uni.message_id = im.id AND um.member_id = $1
+    )
+  )
+ORDER BY im.created_at ASC
+`
+
+type ListThreadParams struct {
+	UserID   uuid.UUID
+	ThreadID uuid.UUID
+}
+
+type ListThreadRow struct {
+	ID                 uuid.UUID
+	PostedBy           uuid.UUID
+	Type               ContentType
+	ContentID          uuid.UUID
+	CreatedAt          pgtype.Timestamp
+	UpdatedAt          pgtype.Timestamp
+	ReplyTo            pgtype.UUID
+	ThreadID           pgtype.UUID
+	SenderID           pgtype.UUID
+	Body               pgtype.Text
+	UserInboxMessageID pgtype.UUID
+	IsRead             pgtype.Bool
+	SenderName         pgtype.Text
+}
+
+func (q *Queries) ListThread(ctx context.Context, arg ListThreadParams) ([]ListThreadRow, error) {
+	rows, err := q.db.Query(ctx, listThread, arg.UserID, arg.ThreadID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListThreadRow
+	for rows.Next() {
+		var i ListThreadRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostedBy,
+			&i.Type,
+			&i.ContentID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReplyTo,
+			&i.ThreadID,
+			&i.SenderID,
+			&i.Body,
+			&i.UserInboxMessageID,
+			&i.IsRead,
+			&i.SenderName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnitInbox = `-- name: ListUnitInbox :many
+SELECT
+    uni.id, uni.unit_id, uni.message_id, uni.assignee_id, uni.is_read, uni.is_archived,
+    im.posted_by, im.type, im.content_id, im.reply_to, im.thread_id, im.sender_id, im.body, im.created_at,
+    su.name AS sender_name,
+    au.name AS assignee_name
+FROM unit_inbox_messages uni
+JOIN inbox_message im ON uni.message_id = im.id
+LEFT JOIN users su ON im.sender_id = su.id
+LEFT JOIN users au ON uni.assignee_id = au.id
+WHERE uni.unit_id = $1
+  AND ($2::boolean IS NULL OR uni.is_read = $2)
+  AND (uni.is_archived = COALESCE($3::boolean, false))
+  AND ($4::uuid IS NULL OR uni.assignee_id = $4)
+  AND (NOT $5::boolean OR uni.assignee_id IS NULL)
+ORDER BY im.created_at DESC
+LIMIT COALESCE($7::int, 10)
+OFFSET COALESCE($6::int, 0)
+`
+
+type ListUnitInboxParams struct {
+	UnitID     uuid.UUID
+	IsRead     pgtype.Bool
+	IsArchived pgtype.Bool
+	AssigneeID pgtype.UUID
+	Unassigned bool
+	PageOffset int32
+	PageLimit  int32
+}
+
+type ListUnitInboxRow struct {
+	ID           uuid.UUID
+	UnitID       uuid.UUID
+	MessageID    uuid.UUID
+	AssigneeID   pgtype.UUID
+	IsRead       bool
+	IsArchived   bool
+	PostedBy     uuid.UUID
+	Type         ContentType
+	ContentID    uuid.UUID
+	ReplyTo      pgtype.UUID
+	ThreadID     pgtype.UUID
+	SenderID     pgtype.UUID
+	Body         pgtype.Text
+	CreatedAt    pgtype.Timestamp
+	SenderName   pgtype.Text
+	AssigneeName pgtype.Text
+}
+
+func (q *Queries) ListUnitInbox(ctx context.Context, arg ListUnitInboxParams) ([]ListUnitInboxRow, error) {
+	rows, err := q.db.Query(ctx, listUnitInbox,
+		arg.UnitID,
+		arg.IsRead,
+		arg.IsArchived,
+		arg.AssigneeID,
+		arg.Unassigned,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnitInboxRow
+	for rows.Next() {
+		var i ListUnitInboxRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UnitID,
+			&i.MessageID,
+			&i.AssigneeID,
+			&i.IsRead,
+			&i.IsArchived,
+			&i.PostedBy,
+			&i.Type,
+			&i.ContentID,
+			&i.ReplyTo,
+			&i.ThreadID,
+			&i.SenderID,
+			&i.Body,
+			&i.CreatedAt,
+			&i.SenderName,
+			&i.AssigneeName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnitMemberIDs = `-- name: ListUnitMemberIDs :many
+SELECT member_id FROM unit_members
+WHERE unit_id = $1
+`
+
+func (q *Queries) ListUnitMemberIDs(ctx context.Context, unitID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listUnitMemberIDs, unitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var member_id uuid.UUID
+		if err := rows.Scan(&member_id); err != nil {
+			return nil, err
+		}
+		items = append(items, member_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resurfaceSnoozed = `-- name: ResurfaceSnoozed :many
+UPDATE user_inbox_messages
+SET is_read = false, snoozed_until = NULL
+WHERE snoozed_until IS NOT NULL AND snoozed_until <= now()
+RETURNING id, user_id, message_id, is_read, is_starred, is_archived, snoozed_until
+`
+
+func (q *Queries) ResurfaceSnoozed(ctx context.Context) ([]UserInboxMessage, error) {
+	rows, err := q.db.Query(ctx, resurfaceSnoozed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserInboxMessage
+	for rows.Next() {
+		var i UserInboxMessage
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.MessageID,
+			&i.IsRead,
+			&i.IsStarred,
+			&i.IsArchived,
+			&i.SnoozedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateByID = `-- name: UpdateByID :one
 UPDATE user_inbox_messages AS uim
-SET is_read = $1, is_starred = $2, is_archived = $3
+SET is_read = $1, is_starred = $2, is_archived = $3, snoozed_until = $4
 FROM inbox_message AS im
 LEFT JOIN forms f ON im.type = 'form' AND im.content_id = f.id
-LEFT JOIN units u ON f.unit_id = u.id
+LEFT JOIN tasks t ON im.type = 'task' AND im.content_id = t.id
+LEFT JOIN quota_warnings qw ON im.type = 'quota' AND im.content_id = qw.id
+LEFT JOIN units u ON u.id = COALESCE(f.unit_id, t.unit_id, qw.org_id)
 LEFT JOIN units o ON u.org_id = o.id
-WHERE uim.message_id = im.id AND uim.id = $4 AND uim.user_id = $5
-RETURNING uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived, im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at,
-CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25)) END AS preview_message,
-CASE WHEN im.type = 'form' THEN f.title END AS title,
-CASE WHEN im.type = 'form' THEN COALESCE(o.name, u.name) END AS org_name,
-CASE WHEN im.type = 'form' AND u.type = 'unit' THEN u.name END AS unit_name
+WHERE uim.message_id = im.id AND uim.id = $5 AND uim.user_id = $6
+RETURNING uim.id, uim.user_id, uim.message_id, uim.is_read, uim.is_starred, uim.is_archived, uim.snoozed_until, im.id, im.posted_by, im.type, im.content_id, im.created_at, im.updated_at, im.reply_to, im.thread_id, im.sender_id, im.body,
+CASE WHEN im.type = 'form' THEN COALESCE(f.preview_message, LEFT(f.description, 25))
+     WHEN im.type = 'task' THEN LEFT(t.description, 25)
+     WHEN im.type = 'quota' THEN qw.usage || ' of ' || qw.quota_limit || ' used' END AS preview_message,
+CASE WHEN im.type = 'form' THEN f.title WHEN im.type = 'task' THEN t.title
+     WHEN im.type = 'quota' THEN 'Quota of ' || qw.resource::text || ' at ' || qw.threshold || '%' END AS title,
+CASE WHEN im.type IN ('form', 'task', 'quota') THEN COALESCE(o.name, u.name) END AS org_name,
+CASE WHEN im.type IN ('form', 'task') AND u.type = 'unit' THEN u.name END AS unit_name
 `
 
 type UpdateByIDParams struct {
-	IsRead     bool
-	IsStarred  bool
-	IsArchived bool
-	ID         uuid.UUID
-	UserID     uuid.UUID
+	IsRead       bool
+	IsStarred    bool
+	IsArchived   bool
+	SnoozedUntil pgtype.Timestamptz
+	ID           uuid.UUID
+	UserID       uuid.UUID
 }
 
 type UpdateByIDRow struct {
@@ -310,12 +782,17 @@ type UpdateByIDRow struct {
 	IsRead         bool
 	IsStarred      bool
 	IsArchived     bool
+	SnoozedUntil   pgtype.Timestamptz
 	ID_2           uuid.UUID
 	PostedBy       uuid.UUID
 	Type           ContentType
 	ContentID      uuid.UUID
 	CreatedAt      pgtype.Timestamp
 	UpdatedAt      pgtype.Timestamp
+	ReplyTo        pgtype.UUID
+	ThreadID       pgtype.UUID
+	SenderID       pgtype.UUID
+	Body           pgtype.Text
 	PreviewMessage interface{}
 	Title          interface{}
 	OrgName        interface{}
@@ -327,6 +804,7 @@ func (q *Queries) UpdateByID(ctx context.Context, arg UpdateByIDParams) (UpdateB
 		arg.IsRead,
 		arg.IsStarred,
 		arg.IsArchived,
+		arg.SnoozedUntil,
 		arg.ID,
 		arg.UserID,
 	)
@@ -338,12 +816,17 @@ func (q *Queries) UpdateByID(ctx context.Context, arg UpdateByIDParams) (UpdateB
 		&i.IsRead,
 		&i.IsStarred,
 		&i.IsArchived,
+		&i.SnoozedUntil,
 		&i.ID_2,
 		&i.PostedBy,
 		&i.Type,
 		&i.ContentID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ReplyTo,
+		&i.ThreadID,
+		&i.SenderID,
+		&i.Body,
 		&i.PreviewMessage,
 		&i.Title,
 		&i.OrgName,
@@ -351,3 +834,31 @@ func (q *Queries) UpdateByID(ctx context.Context, arg UpdateByIDParams) (UpdateB
 	)
 	return i, err
 }
+
+const updateUnitInbox = `-- name: UpdateUnitInbox :execrows
+UPDATE unit_inbox_messages
+SET is_read = $1, is_archived = $2, assignee_id = $3, updated_at = now()
+WHERE id = $4 AND unit_id = $5
+`
+
+type UpdateUnitInboxParams struct {
+	IsRead     bool
+	IsArchived bool
+	AssigneeID pgtype.UUID
+	ID         uuid.UUID
+	UnitID     uuid.UUID
+}
+
+func (q *Queries) UpdateUnitInbox(ctx context.Context, arg UpdateUnitInboxParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUnitInbox,
+		arg.IsRead,
+		arg.IsArchived,
+		arg.AssigneeID,
+		arg.ID,
+		arg.UnitID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,21 @@
+package inbox
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the inbox of the current user, its event stream and the inboxes of units
+func Routes(r route.Router, h *Handler, stream *StreamHandler) {
+	r.Handle("GET /inbox", route.Authenticated, route.PermissionSelf, h.ListHandler)
+	r.Handle("GET /inbox/stream", route.Authenticated, route.PermissionSelf, stream.StreamHandler)
+	r.Handle("GET /inbox/{id}", route.Authenticated, route.PermissionSelf, h.GetHandler)
+	r.Handle("PUT /inbox/{id}", route.Authenticated, route.PermissionSelf, h.UpdateHandler)
+	r.Handle("GET /inbox/{id}/thread", route.Authenticated, route.PermissionSelf, h.ThreadHandler)
+	r.Handle("POST /inbox/{id}/replies", route.Authenticated, route.PermissionSelf, h.ReplyHandler)
+
+	// Unit inbox routes
+	r.Handle("GET /orgs/{slug}/units/{id}/inbox", route.TenantAuthenticated, route.PermissionUnitMember, h.ListUnitInboxHandler)
+	r.Handle("PUT /orgs/{slug}/units/{id}/inbox/{messageId}", route.TenantAuthenticated, route.PermissionUnitMember, h.UpdateUnitInboxHandler)
+	r.Handle("GET /orgs/{slug}/units/{id}/inbox/{messageId}/thread", route.TenantAuthenticated, route.PermissionUnitMember, h.UnitInboxThreadHandler)
+	r.Handle("POST /orgs/{slug}/units/{id}/inbox/{messageId}/replies", route.TenantAuthenticated, route.PermissionUnitMember, h.UnitInboxReplyHandler)
+}
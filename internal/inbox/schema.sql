@@ -1,6 +1,8 @@
 CREATE TYPE content_type AS ENUM(
     'text',
-    'form'
+    'form',
+    'task',
+    'quota'
 );
 
 CREATE TABLE IF NOT EXISTS inbox_message(
@@ -9,14 +11,37 @@ CREATE TABLE IF NOT EXISTS inbox_message(
     type content_type NOT NULL,
     content_id UUID NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT now(),
-    updated_at TIMESTAMP NOT NULL DEFAULT now()
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    reply_to UUID DEFAULT NULL references inbox_message(id) ON DELETE CASCADE,
+    thread_id UUID DEFAULT NULL references inbox_message(id) ON DELETE CASCADE,
+    sender_id UUID DEFAULT NULL references users(id) ON DELETE SET NULL,
+    body TEXT DEFAULT NULL
 );
 
+CREATE INDEX IF NOT EXISTS idx_inbox_message_thread_id ON inbox_message(thread_id);
+
 CREATE TABLE IF NOT EXISTS user_inbox_messages (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     user_id UUID NOT NULL references users(id) ON DELETE CASCADE,
     message_id UUID NOT NULL references inbox_message(id) ON DELETE CASCADE,
     is_read boolean NOT NULL DEFAULT false,
     is_starred boolean NOT NULL DEFAULT false,
-    is_archived boolean NOT NULL DEFAULT false
+    is_archived boolean NOT NULL DEFAULT false,
+    snoozed_until TIMESTAMPTZ DEFAULT NULL
 );
+
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_snoozed_until ON user_inbox_messages(snoozed_until) WHERE snoozed_until IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_user_id ON user_inbox_messages(user_id, is_archived, message_id);
+CREATE INDEX IF NOT EXISTS idx_user_inbox_messages_message_id ON user_inbox_messages(message_id, user_id);
+
+CREATE TABLE IF NOT EXISTS unit_inbox_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    unit_id UUID NOT NULL references units(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL references inbox_message(id) ON DELETE CASCADE,
+    assignee_id UUID DEFAULT NULL references users(id) ON DELETE SET NULL,
+    is_read boolean NOT NULL DEFAULT false,
+    is_archived boolean NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (unit_id, message_id)
+);
\ No newline at end of file
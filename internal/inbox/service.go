@@ -1,11 +1,15 @@
 package inbox
 
 import (
+	"NYCU-SDC/core-system-backend/internal"
 	"context"
+	"fmt"
+	"time"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
@@ -19,19 +23,97 @@ type Querier interface {
 	ListCount(ctx context.Context, arg ListCountParams) (int64, error)
 	GetByID(ctx context.Context, arg GetByIDParams) (GetByIDRow, error)
 	UpdateByID(ctx context.Context, arg UpdateByIDParams) (UpdateByIDRow, error)
+	CreateReply(ctx context.Context, arg CreateReplyParams) (InboxMessage, error)
+	ListUnitMemberIDs(ctx context.Context, unitID uuid.UUID) ([]uuid.UUID, error)
+	ListThread(ctx context.Context, arg ListThreadParams) ([]ListThreadRow, error)
+	IsUnitMember(ctx context.Context, arg IsUnitMemberParams) (bool, error)
+	CreateUnitInbox(ctx context.Context, arg CreateUnitInboxParams) (UnitInboxMessage, error)
+	GetUnitInboxByID(ctx context.Context, arg GetUnitInboxByIDParams) (GetUnitInboxByIDRow, error)
+	ListUnitInbox(ctx context.Context, arg ListUnitInboxParams) ([]ListUnitInboxRow, error)
+	CountUnitInbox(ctx context.Context, arg CountUnitInboxParams) (int64, error)
+	UpdateUnitInbox(ctx context.Context, arg UpdateUnitInboxParams) (int64, error)
+	ResurfaceSnoozed(ctx context.Context) ([]UserInboxMessage, error)
+}
+
+// DefaultResurfaceInterval is how often snoozed messages are checked for resurfacing
+const DefaultResurfaceInterval = time.Minute
+
+// Notifier is told about messages delivered to personal inboxes, e.g. to send push
+// notifications to the devices of the recipients
+type Notifier interface {
+	Notify(ctx context.Context, messageID uuid.UUID, userIDs []uuid.UUID) error
+}
+
+// DB is the connection the service runs on, a pool or a transaction; lookups made
+// together are sent to it as one batch
+type DB interface {
+	DBTX
+	SendBatch(ctx context.Context, batch *pgx.Batch) pgx.BatchResults
 }
 
 type Service struct {
-	logger  *zap.Logger
-	queries Querier
-	tracer  trace.Tracer
+	logger   *zap.Logger
+	db       DB
+	queries  Querier
+	tracer   trace.Tracer
+	notifier Notifier
+}
+
+// UnitInboxFilter narrows the shared mailbox of a unit. Unassigned takes precedence
+// over AssigneeID.
+type UnitInboxFilter struct {
+	IsRead     *bool
+	IsArchived *bool
+	AssigneeID *uuid.UUID
+	Unassigned bool
+}
+
+// UnitInboxUpdate is the state a member sets on a message of the shared mailbox
+type UnitInboxUpdate struct {
+	IsRead     bool
+	IsArchived bool
+	AssigneeID *uuid.UUID
+}
+
+// replyTarget is the message being answered
+type replyTarget struct {
+	MessageID uuid.UUID
+	PostedBy  uuid.UUID
+	ContentID uuid.UUID
+	ThreadID  pgtype.UUID
+	SenderID  pgtype.UUID
 }
 
-func NewService(logger *zap.Logger, db DBTX) *Service {
+// threadID returns the ID of the message starting the thread of the target
+func (t replyTarget) threadID() uuid.UUID {
+	if t.ThreadID.Valid {
+		return t.ThreadID.Bytes
+	}
+	return t.MessageID
+}
+
+// NewService creates the inbox service. notifier may be nil when no notifications
+// are sent besides the inbox itself.
+func NewService(logger *zap.Logger, db DB, notifier Notifier) *Service {
 	return &Service{
-		logger:  logger,
-		queries: New(db),
-		tracer:  otel.Tracer("inbox/service"),
+		logger:   logger,
+		db:       db,
+		queries:  New(db),
+		tracer:   otel.Tracer("inbox/service"),
+		notifier: notifier,
+	}
+}
+
+// notify hands delivered messages to the notifier. The messages are already in the
+// inbox, so failures are logged instead of failing the delivery.
+func (s *Service) notify(ctx context.Context, messageID uuid.UUID, userIDs []uuid.UUID) {
+	if s.notifier == nil || len(userIDs) == 0 {
+		return
+	}
+
+	err := s.notifier.Notify(ctx, messageID, userIDs)
+	if err != nil {
+		logutil.WithContext(ctx, s.logger).Warn("Failed to notify inbox recipients", zap.Error(err), zap.String("message_id", messageID.String()))
 	}
 }
 
@@ -71,6 +153,8 @@ func (s *Service) Create(ctx context.Context, contentType ContentType, contentID
 		zap.Int("recipients", len(userIDs)),
 	)
 
+	s.notify(traceCtx, message.ID, userIDs)
+
 	return message.ID, nil
 }
 
@@ -79,43 +163,12 @@ func (s *Service) List(ctx context.Context, userID uuid.UUID, filter *FilterRequ
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	// Prepare filter parameters
-	params := ListParams{
-		UserID:     userID,
-		IsRead:     pgtype.Bool{Valid: false},
-		IsStarred:  pgtype.Bool{Valid: false},
-		IsArchived: pgtype.Bool{Valid: false},
-		Search:     "",
-	}
-
-	if filter != nil {
-		if filter.IsRead != nil {
-			params.IsRead = pgtype.Bool{Bool: *filter.IsRead, Valid: true}
-		}
-		if filter.IsStarred != nil {
-			params.IsStarred = pgtype.Bool{Bool: *filter.IsStarred, Valid: true}
-		}
-		if filter.IsArchived != nil {
-			params.IsArchived = pgtype.Bool{Bool: *filter.IsArchived, Valid: true}
-		}
-		if filter.Search != "" {
-			params.Search = filter.Search
-		}
-	}
+	params := listParams(userID, filter, page, size)
 
 	logger.Info("List params", zap.Any("params", params))
 	logger.Info("Page", zap.Int("page", page))
 	logger.Info("Size", zap.Int("size", size))
 
-	// Apply pagination
-	if size > 0 {
-		params.PageLimit = int32(size)
-	}
-	if page > 0 && size > 0 {
-		offset := (page - 1) * size
-		params.PageOffset = int32(offset)
-	}
-
 	messages, err := s.queries.List(traceCtx, params)
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "list all user inbox messages")
@@ -135,12 +188,86 @@ func (s *Service) Count(ctx context.Context, userID uuid.UUID, filter *FilterReq
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	params := ListCountParams{
+	params := listCountParams(listParams(userID, filter, 0, 0))
+
+	total, err := s.queries.ListCount(traceCtx, params)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "count user inbox messages")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// ListWithCount returns a page of the inbox of a user along with the number of messages
+// matching the filter. Both queries are sent as one batch, so the page costs a single
+// round trip to the database.
+func (s *Service) ListWithCount(ctx context.Context, userID uuid.UUID, filter *FilterRequest, page int, size int) ([]ListRow, int64, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListWithCount")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	params := listParams(userID, filter, page, size)
+	countParams := listCountParams(params)
+
+	var total int64
+	var messages []ListRow
+
+	// The arguments follow the order of the generated List and ListCount queries
+	batch := &pgx.Batch{}
+	batch.Queue(listCount,
+		countParams.UserID,
+		countParams.IsRead,
+		countParams.IsStarred,
+		countParams.IsArchived,
+		countParams.IsSnoozed,
+		countParams.Search,
+		countParams.TagIds,
+	).QueryRow(func(row pgx.Row) error {
+		return row.Scan(&total)
+	})
+	batch.Queue(list,
+		params.UserID,
+		params.IsRead,
+		params.IsStarred,
+		params.IsArchived,
+		params.IsSnoozed,
+		params.Search,
+		params.TagIds,
+		params.PageOffset,
+		params.PageLimit,
+	).Query(func(rows pgx.Rows) error {
+		var err error
+		messages, err = pgx.CollectRows(rows, pgx.RowToStructByPos[ListRow])
+		return err
+	})
+
+	err := s.db.SendBatch(traceCtx, batch).Close()
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list and count user inbox messages")
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	if messages == nil {
+		return []ListRow{}, total, nil
+	}
+
+	return messages, total, nil
+}
+
+// listParams builds the List parameters from the filter and the page; a page or size
+// of 0 leaves the query defaults
+func listParams(userID uuid.UUID, filter *FilterRequest, page int, size int) ListParams {
+	params := ListParams{
 		UserID:     userID,
 		IsRead:     pgtype.Bool{Valid: false},
 		IsStarred:  pgtype.Bool{Valid: false},
 		IsArchived: pgtype.Bool{Valid: false},
+		IsSnoozed:  pgtype.Bool{Valid: false},
 		Search:     "",
+		TagIds:     []uuid.UUID{},
 	}
 
 	if filter != nil {
@@ -153,19 +280,39 @@ func (s *Service) Count(ctx context.Context, userID uuid.UUID, filter *FilterReq
 		if filter.IsArchived != nil {
 			params.IsArchived = pgtype.Bool{Bool: *filter.IsArchived, Valid: true}
 		}
+		if filter.IsSnoozed != nil {
+			params.IsSnoozed = pgtype.Bool{Bool: *filter.IsSnoozed, Valid: true}
+		}
 		if filter.Search != "" {
 			params.Search = filter.Search
 		}
+		if len(filter.TagIDs) > 0 {
+			params.TagIds = filter.TagIDs
+		}
 	}
 
-	total, err := s.queries.ListCount(traceCtx, params)
-	if err != nil {
-		err = databaseutil.WrapDBError(err, logger, "count user inbox messages")
-		span.RecordError(err)
-		return 0, err
+	// Apply pagination
+	if size > 0 {
+		params.PageLimit = int32(size)
+	}
+	if page > 0 && size > 0 {
+		offset := (page - 1) * size
+		params.PageOffset = int32(offset)
 	}
 
-	return total, nil
+	return params
+}
+
+func listCountParams(params ListParams) ListCountParams {
+	return ListCountParams{
+		UserID:     params.UserID,
+		IsRead:     params.IsRead,
+		IsStarred:  params.IsStarred,
+		IsArchived: params.IsArchived,
+		IsSnoozed:  params.IsSnoozed,
+		Search:     params.Search,
+		TagIds:     params.TagIds,
+	}
 }
 
 func (s *Service) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (GetByIDRow, error) {
@@ -191,12 +338,22 @@ func (s *Service) UpdateByID(ctx context.Context, id uuid.UUID, userID uuid.UUID
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
+	snoozedUntil := pgtype.Timestamptz{}
+	if arg.SnoozedUntil != nil {
+		if !arg.SnoozedUntil.After(time.Now()) {
+			span.RecordError(internal.ErrSnoozeInPast)
+			return UpdateByIDRow{}, internal.ErrSnoozeInPast
+		}
+		snoozedUntil = pgtype.Timestamptz{Time: *arg.SnoozedUntil, Valid: true}
+	}
+
 	message, err := s.queries.UpdateByID(traceCtx, UpdateByIDParams{
-		ID:         id,
-		UserID:     userID,
-		IsRead:     arg.IsRead,
-		IsArchived: arg.IsArchived,
-		IsStarred:  arg.IsStarred,
+		ID:           id,
+		UserID:       userID,
+		IsRead:       arg.IsRead,
+		IsArchived:   arg.IsArchived,
+		IsStarred:    arg.IsStarred,
+		SnoozedUntil: snoozedUntil,
 	})
 	if err != nil {
 		err = databaseutil.WrapDBError(err, logger, "update user_inbox_message by id")
@@ -206,3 +363,424 @@ func (s *Service) UpdateByID(ctx context.Context, id uuid.UUID, userID uuid.UUID
 
 	return message, err
 }
+
+// Reply answers a message in the inbox of the user
+func (s *Service) Reply(ctx context.Context, id uuid.UUID, userID uuid.UUID, body string) (ListThreadRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Reply")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	parent, err := s.queries.GetByID(traceCtx, GetByIDParams{
+		UserInboxMessageID: id,
+		UserID:             userID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "user_inbox_messages", "id", id.String(), logger, "get replied inbox message")
+		span.RecordError(err)
+		return ListThreadRow{}, err
+	}
+
+	reply, err := s.reply(traceCtx, replyTarget{
+		MessageID: parent.MessageID,
+		PostedBy:  parent.PostedBy,
+		ContentID: parent.ContentID,
+		ThreadID:  parent.ThreadID,
+		SenderID:  parent.SenderID,
+	}, userID, body)
+	if err != nil {
+		span.RecordError(err)
+		return ListThreadRow{}, err
+	}
+
+	return reply, nil
+}
+
+// reply creates a reply in the thread of the target. The reply goes to the shared mailbox
+// of the unit that posted the thread, and to the personal inboxes of the author of the
+// target and of the user, so the other recipients of an announcement never see each
+// other's replies.
+func (s *Service) reply(ctx context.Context, target replyTarget, userID uuid.UUID, body string) (ListThreadRow, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	message, err := s.queries.CreateReply(ctx, CreateReplyParams{
+		PostedBy:  target.PostedBy,
+		ContentID: target.ContentID,
+		ReplyTo:   pgtype.UUID{Bytes: target.MessageID, Valid: true},
+		ThreadID:  pgtype.UUID{Bytes: target.threadID(), Valid: true},
+		SenderID:  pgtype.UUID{Bytes: userID, Valid: true},
+		Body:      pgtype.Text{String: body, Valid: true},
+	})
+	if err != nil {
+		return ListThreadRow{}, databaseutil.WrapDBError(err, logger, "create inbox reply")
+	}
+
+	isMember, err := s.queries.IsUnitMember(ctx, IsUnitMemberParams{
+		UnitID:   target.PostedBy,
+		MemberID: userID,
+	})
+	if err != nil {
+		return ListThreadRow{}, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", target.PostedBy.String(), logger, "check unit membership")
+	}
+
+	// Replies written by members are already handled from the unit's point of view
+	_, err = s.queries.CreateUnitInbox(ctx, CreateUnitInboxParams{
+		UnitID:    target.PostedBy,
+		MessageID: message.ID,
+		IsRead:    isMember,
+	})
+	if err != nil {
+		return ListThreadRow{}, databaseutil.WrapDBErrorWithKeyValue(err, "unit_inbox_messages", "unit_id", target.PostedBy.String(), logger, "deliver reply to unit inbox")
+	}
+
+	userIDs := []uuid.UUID{userID}
+	if target.SenderID.Valid && target.SenderID.Bytes != userID {
+		userIDs = append(userIDs, target.SenderID.Bytes)
+	}
+
+	inboxMessages, err := s.queries.CreateUserInboxBulk(ctx, CreateUserInboxBulkParams{
+		UserIds:   userIDs,
+		MessageID: message.ID,
+	})
+	if err != nil {
+		return ListThreadRow{}, databaseutil.WrapDBError(err, logger, "create user inbox messages in bulk")
+	}
+
+	reply := ListThreadRow{
+		ID:        message.ID,
+		PostedBy:  message.PostedBy,
+		Type:      message.Type,
+		ContentID: message.ContentID,
+		CreatedAt: message.CreatedAt,
+		UpdatedAt: message.UpdatedAt,
+		ReplyTo:   message.ReplyTo,
+		ThreadID:  message.ThreadID,
+		SenderID:  message.SenderID,
+		Body:      message.Body,
+	}
+
+	// The copy in the inbox of the sender is already read
+	for _, inboxMessage := range inboxMessages {
+		if inboxMessage.UserID != userID {
+			continue
+		}
+
+		_, err = s.queries.UpdateByID(ctx, UpdateByIDParams{
+			ID:     inboxMessage.ID,
+			UserID: userID,
+			IsRead: true,
+		})
+		if err != nil {
+			return ListThreadRow{}, databaseutil.WrapDBError(err, logger, "mark own reply as read")
+		}
+
+		reply.UserInboxMessageID = pgtype.UUID{Bytes: inboxMessage.ID, Valid: true}
+		reply.IsRead = pgtype.Bool{Bool: true, Valid: true}
+	}
+
+	logger.Info("Created inbox reply",
+		zap.String("message_id", message.ID.String()),
+		zap.String("reply_to", target.MessageID.String()),
+		zap.Int("recipients", len(userIDs)),
+	)
+
+	// The sender wrote the reply, so only the author of the target hears about it
+	s.notify(ctx, message.ID, userIDs[1:])
+
+	return reply, nil
+}
+
+// ListThread returns the conversation a message in the inbox of the user belongs to,
+// oldest first
+func (s *Service) ListThread(ctx context.Context, id uuid.UUID, userID uuid.UUID) ([]ListThreadRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListThread")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	message, err := s.queries.GetByID(traceCtx, GetByIDParams{
+		UserInboxMessageID: id,
+		UserID:             userID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "user_inbox_messages", "id", id.String(), logger, "get inbox message")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	thread, err := s.listThread(traceCtx, userID, replyTarget{MessageID: message.MessageID, ThreadID: message.ThreadID})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return thread, nil
+}
+
+// listThread returns the message starting the thread and the replies the user can see,
+// either in the personal inbox or in the shared mailbox of a unit the user belongs to
+func (s *Service) listThread(ctx context.Context, userID uuid.UUID, target replyTarget) ([]ListThreadRow, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	thread, err := s.queries.ListThread(ctx, ListThreadParams{
+		UserID:   userID,
+		ThreadID: target.threadID(),
+	})
+	if err != nil {
+		return nil, databaseutil.WrapDBError(err, logger, "list inbox thread")
+	}
+
+	if thread == nil {
+		return []ListThreadRow{}, nil
+	}
+
+	return thread, nil
+}
+
+// checkUnitMember makes sure the user may read and act on the shared mailbox of the unit
+func (s *Service) checkUnitMember(ctx context.Context, unitID uuid.UUID, userID uuid.UUID) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	isMember, err := s.queries.IsUnitMember(ctx, IsUnitMemberParams{
+		UnitID:   unitID,
+		MemberID: userID,
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", unitID.String(), logger, "check unit membership")
+	}
+	if !isMember {
+		return fmt.Errorf("%w: user %s is not a member of unit %s", internal.ErrPermissionDenied, userID, unitID)
+	}
+
+	return nil
+}
+
+// ListUnitInbox lists the shared mailbox of a unit, newest first
+func (s *Service) ListUnitInbox(ctx context.Context, unitID uuid.UUID, userID uuid.UUID, filter UnitInboxFilter, page int, size int) ([]ListUnitInboxRow, int64, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListUnitInbox")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkUnitMember(traceCtx, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	params := ListUnitInboxParams{
+		UnitID:     unitID,
+		Unassigned: filter.Unassigned,
+	}
+	if filter.IsRead != nil {
+		params.IsRead = pgtype.Bool{Bool: *filter.IsRead, Valid: true}
+	}
+	if filter.IsArchived != nil {
+		params.IsArchived = pgtype.Bool{Bool: *filter.IsArchived, Valid: true}
+	}
+	if filter.AssigneeID != nil && !filter.Unassigned {
+		params.AssigneeID = pgtype.UUID{Bytes: *filter.AssigneeID, Valid: true}
+	}
+
+	total, err := s.queries.CountUnitInbox(traceCtx, CountUnitInboxParams{
+		UnitID:     params.UnitID,
+		IsRead:     params.IsRead,
+		IsArchived: params.IsArchived,
+		AssigneeID: params.AssigneeID,
+		Unassigned: params.Unassigned,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_inbox_messages", "unit_id", unitID.String(), logger, "count unit inbox messages")
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	if size > 0 {
+		params.PageLimit = int32(size)
+	}
+	if page > 0 && size > 0 {
+		params.PageOffset = int32((page - 1) * size)
+	}
+
+	messages, err := s.queries.ListUnitInbox(traceCtx, params)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_inbox_messages", "unit_id", unitID.String(), logger, "list unit inbox messages")
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	if messages == nil {
+		return []ListUnitInboxRow{}, total, nil
+	}
+
+	return messages, total, nil
+}
+
+// UpdateUnitInbox marks a message of the shared mailbox and assigns it to a member of
+// the unit handling it
+func (s *Service) UpdateUnitInbox(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, update UnitInboxUpdate) (GetUnitInboxByIDRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "UpdateUnitInbox")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkUnitMember(traceCtx, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return GetUnitInboxByIDRow{}, err
+	}
+
+	var assigneeID pgtype.UUID
+	if update.AssigneeID != nil {
+		isMember, err := s.queries.IsUnitMember(traceCtx, IsUnitMemberParams{
+			UnitID:   unitID,
+			MemberID: *update.AssigneeID,
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", unitID.String(), logger, "check assignee membership")
+			span.RecordError(err)
+			return GetUnitInboxByIDRow{}, err
+		}
+		if !isMember {
+			err = fmt.Errorf("%w: %s", internal.ErrAssigneeNotUnitMember, update.AssigneeID)
+			span.RecordError(err)
+			return GetUnitInboxByIDRow{}, err
+		}
+		assigneeID = pgtype.UUID{Bytes: *update.AssigneeID, Valid: true}
+	}
+
+	rows, err := s.queries.UpdateUnitInbox(traceCtx, UpdateUnitInboxParams{
+		IsRead:     update.IsRead,
+		IsArchived: update.IsArchived,
+		AssigneeID: assigneeID,
+		ID:         id,
+		UnitID:     unitID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_inbox_messages", "id", id.String(), logger, "update unit inbox message")
+		span.RecordError(err)
+		return GetUnitInboxByIDRow{}, err
+	}
+	if rows == 0 {
+		err = fmt.Errorf("%w: unit inbox message %s", internal.ErrInboxMessageNotFound, id)
+		span.RecordError(err)
+		return GetUnitInboxByIDRow{}, err
+	}
+
+	return s.getUnitInbox(traceCtx, unitID, id)
+}
+
+// ReplyFromUnitInbox answers a message of the shared mailbox on behalf of the unit
+func (s *Service) ReplyFromUnitInbox(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID, body string) (ListThreadRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ReplyFromUnitInbox")
+	defer span.End()
+
+	err := s.checkUnitMember(traceCtx, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return ListThreadRow{}, err
+	}
+
+	parent, err := s.getUnitInbox(traceCtx, unitID, id)
+	if err != nil {
+		span.RecordError(err)
+		return ListThreadRow{}, err
+	}
+
+	reply, err := s.reply(traceCtx, replyTarget{
+		MessageID: parent.MessageID,
+		PostedBy:  parent.PostedBy,
+		ContentID: parent.ContentID,
+		ThreadID:  parent.ThreadID,
+		SenderID:  parent.SenderID,
+	}, userID, body)
+	if err != nil {
+		span.RecordError(err)
+		return ListThreadRow{}, err
+	}
+
+	return reply, nil
+}
+
+// ListUnitInboxThread returns the conversation a message of the shared mailbox belongs to
+func (s *Service) ListUnitInboxThread(ctx context.Context, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID) ([]ListThreadRow, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListUnitInboxThread")
+	defer span.End()
+
+	err := s.checkUnitMember(traceCtx, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	message, err := s.getUnitInbox(traceCtx, unitID, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	thread, err := s.listThread(traceCtx, userID, replyTarget{MessageID: message.MessageID, ThreadID: message.ThreadID})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return thread, nil
+}
+
+func (s *Service) getUnitInbox(ctx context.Context, unitID uuid.UUID, id uuid.UUID) (GetUnitInboxByIDRow, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	message, err := s.queries.GetUnitInboxByID(ctx, GetUnitInboxByIDParams{
+		ID:     id,
+		UnitID: unitID,
+	})
+	if err != nil {
+		return GetUnitInboxByIDRow{}, databaseutil.WrapDBErrorWithKeyValue(err, "unit_inbox_messages", "id", id.String(), logger, "get unit inbox message")
+	}
+
+	return message, nil
+}
+
+// Start resurfaces snoozed messages every interval until the context is canceled
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.ResurfaceSnoozed(ctx)
+			if err != nil {
+				s.logger.Error("Failed to resurface snoozed inbox messages", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ResurfaceSnoozed returns messages whose snooze has passed to the default inbox list.
+// They are marked unread again and the notifier is told, as if they had just arrived.
+func (s *Service) ResurfaceSnoozed(ctx context.Context) error {
+	traceCtx, span := s.tracer.Start(ctx, "ResurfaceSnoozed")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	messages, err := s.queries.ResurfaceSnoozed(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "resurface snoozed user inbox messages")
+		span.RecordError(err)
+		return err
+	}
+
+	if len(messages) > 0 {
+		logger.Info("Resurfaced snoozed inbox messages", zap.Int("count", len(messages)))
+	}
+
+	recipients := make(map[uuid.UUID][]uuid.UUID)
+	for _, message := range messages {
+		recipients[message.MessageID] = append(recipients[message.MessageID], message.UserID)
+	}
+	for messageID, userIDs := range recipients {
+		s.notify(traceCtx, messageID, userIDs)
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package inbox
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/realtime"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// streamHeartbeat keeps idle streams from being closed by proxies between the client
+// and the backend
+const streamHeartbeat = 30 * time.Second
+
+// EventMessage is sent on the stream of a user when a message arrives in their inbox
+const EventMessage = "message"
+
+// StreamTopic is the realtime topic of the inbox of a user
+func StreamTopic(userID uuid.UUID) string {
+	return "inbox:" + userID.String()
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, topic string, eventType string, data any) error
+}
+
+type Subscriber interface {
+	Subscribe(topic string) (<-chan realtime.Event, func())
+}
+
+// Notifiers tells every notifier in turn
+type Notifiers []Notifier
+
+func (n Notifiers) Notify(ctx context.Context, messageID uuid.UUID, userIDs []uuid.UUID) error {
+	var errs []error
+	for _, notifier := range n {
+		err := notifier.Notify(ctx, messageID, userIDs)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StreamNotifier sends delivered messages to the inbox streams of the recipients
+type StreamNotifier struct {
+	publisher Publisher
+}
+
+func NewStreamNotifier(publisher Publisher) StreamNotifier {
+	return StreamNotifier{publisher: publisher}
+}
+
+func (n StreamNotifier) Notify(ctx context.Context, messageID uuid.UUID, userIDs []uuid.UUID) error {
+	var errs []error
+	for _, userID := range userIDs {
+		err := n.publisher.Publish(ctx, StreamTopic(userID), EventMessage, map[string]string{"messageId": messageID.String()})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type StreamHandler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	subscriber Subscriber
+}
+
+func NewStreamHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	subscriber Subscriber,
+) *StreamHandler {
+	return &StreamHandler{
+		logger:        logger,
+		tracer:        otel.Tracer("inbox/stream"),
+		problemWriter: problemWriter,
+		subscriber:    subscriber,
+	}
+}
+
+// StreamHandler sends the events of the inbox of the current user as server-sent
+// events until the client disconnects. Events only carry the message ID; the client
+// loads the message, and reloads the inbox when it reconnects.
+func (h *StreamHandler) StreamHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "StreamHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	events, unsubscribe := h.subscriber.Subscribe(StreamTopic(currentUser.ID))
+	defer unsubscribe()
+
+	controller := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Streams outlive any write deadline of the server
+	_ = controller.SetWriteDeadline(time.Time{})
+	err := controller.Flush()
+	if err != nil {
+		logger.Warn("Response does not support streaming", zap.Error(err))
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		case event := <-events:
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+		}
+		if err == nil {
+			err = controller.Flush()
+		}
+		if err != nil {
+			logger.Debug("Closed inbox stream", zap.Error(err))
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"net/http"
+	"strings"
+)
+
+const maxUserAgentLength = 512
+
+// Fingerprint is the client a refresh token is issued to. Only the platform and the
+// browser family are compared, so browser updates keep the token valid while a cookie
+// replayed from another kind of device does not.
+type Fingerprint struct {
+	UserAgent string
+	Platform  string
+	Browser   string
+}
+
+// NewFingerprint reads the fingerprint of the client from the request, preferring the
+// Sec-CH-UA-Platform client hint over the user agent for the platform
+func NewFingerprint(r *http.Request) Fingerprint {
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	platform := strings.ToLower(strings.Trim(r.Header.Get("Sec-CH-UA-Platform"), `" `))
+	if platform == "" {
+		platform = platformOf(userAgent)
+	}
+
+	return Fingerprint{
+		UserAgent: userAgent,
+		Platform:  platform,
+		Browser:   browserOf(userAgent),
+	}
+}
+
+// Hash is what the token stores of the fingerprint and later compares against
+func (f Fingerprint) Hash() []byte {
+	sum := sha256.Sum256([]byte(f.Platform + "\n" + f.Browser))
+	return sum[:]
+}
+
+// Matches reports whether the client is the one the token was issued to. Tokens issued
+// before fingerprints were recorded have no hash and match any client.
+func (f Fingerprint) Matches(hash []byte) bool {
+	return len(hash) == 0 || bytes.Equal(f.Hash(), hash)
+}
+
+func platformOf(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "android"):
+		return "android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		return "ios"
+	case strings.Contains(ua, "windows"):
+		return "windows"
+	case strings.Contains(ua, "cros"):
+		return "chrome os"
+	case strings.Contains(ua, "mac os"), strings.Contains(ua, "macintosh"):
+		return "macos"
+	case strings.Contains(ua, "linux"):
+		return "linux"
+	}
+	return ""
+}
+
+// browserOf names the browser family; the order matters since most user agents also
+// claim to be Safari or Chrome for compatibility
+func browserOf(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "edg/"), strings.Contains(ua, "edga/"), strings.Contains(ua, "edgios/"):
+		return "edge"
+	case strings.Contains(ua, "opr/"), strings.Contains(ua, "opera"):
+		return "opera"
+	case strings.Contains(ua, "firefox/"), strings.Contains(ua, "fxios/"):
+		return "firefox"
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"), strings.Contains(ua, "chromium/"):
+		return "chrome"
+	case strings.Contains(ua, "safari/"):
+		return "safari"
+	case ua == "":
+		return ""
+	}
+	// Native apps and scripts come under the product name their user agent starts with
+	product, _, _ := strings.Cut(ua, "/")
+	return strings.TrimSpace(product)
+}
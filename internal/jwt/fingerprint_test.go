@@ -0,0 +1,109 @@
+package jwt_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chromeWindows  = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	chromeWindows2 = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36"
+	edgeWindows    = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.0.0"
+	firefoxLinux   = "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0"
+	safariIPhone   = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	chromeAndroid  = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+	safariMac      = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_4) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15"
+)
+
+// newFingerprint reads the fingerprint of a request with the user agent and, when not
+// empty, the platform client hint
+func newFingerprint(userAgent string, platformHint string) jwt.Fingerprint {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", nil)
+	r.Header.Set("User-Agent", userAgent)
+	if platformHint != "" {
+		r.Header.Set("Sec-CH-UA-Platform", platformHint)
+	}
+	return jwt.NewFingerprint(r)
+}
+
+func TestNewFingerprint(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name             string
+		userAgent        string
+		platformHint     string
+		expectedPlatform string
+		expectedBrowser  string
+	}
+
+	testCases := []testCase{
+		{name: "Chrome on Windows", userAgent: chromeWindows, expectedPlatform: "windows", expectedBrowser: "chrome"},
+		{name: "Edge claiming to be Chrome", userAgent: edgeWindows, expectedPlatform: "windows", expectedBrowser: "edge"},
+		{name: "Firefox on Linux", userAgent: firefoxLinux, expectedPlatform: "linux", expectedBrowser: "firefox"},
+		{name: "Safari on an iPhone claiming to be macOS", userAgent: safariIPhone, expectedPlatform: "ios", expectedBrowser: "safari"},
+		{name: "Chrome on Android claiming to be Linux", userAgent: chromeAndroid, expectedPlatform: "android", expectedBrowser: "chrome"},
+		{name: "Safari on macOS", userAgent: safariMac, expectedPlatform: "macos", expectedBrowser: "safari"},
+		{name: "Client hint over the user agent", userAgent: chromeWindows, platformHint: `"Android"`, expectedPlatform: "android", expectedBrowser: "chrome"},
+		{name: "Native app", userAgent: "CoreSystemApp/2.3", expectedPlatform: "", expectedBrowser: "coresystemapp"},
+		{name: "No user agent", userAgent: "", expectedPlatform: "", expectedBrowser: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fingerprint := newFingerprint(tc.userAgent, tc.platformHint)
+			require.Equal(t, tc.userAgent, fingerprint.UserAgent)
+			require.Equal(t, tc.expectedPlatform, fingerprint.Platform)
+			require.Equal(t, tc.expectedBrowser, fingerprint.Browser)
+		})
+	}
+}
+
+func TestNewFingerprint_TruncatesUserAgent(t *testing.T) {
+	t.Parallel()
+
+	fingerprint := newFingerprint(chromeWindows+strings.Repeat("x", 1024), "")
+	require.Len(t, fingerprint.UserAgent, 512)
+	require.Equal(t, "chrome", fingerprint.Browser)
+}
+
+func TestFingerprint_Matches(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name      string
+		issued    jwt.Fingerprint
+		presented jwt.Fingerprint
+		legacy    bool
+		expected  bool
+	}
+
+	testCases := []testCase{
+		{name: "Same client", issued: newFingerprint(chromeWindows, ""), presented: newFingerprint(chromeWindows, ""), expected: true},
+		{name: "Updated browser", issued: newFingerprint(chromeWindows, ""), presented: newFingerprint(chromeWindows2, ""), expected: true},
+		{name: "Client hint agreeing with the user agent", issued: newFingerprint(chromeWindows, ""), presented: newFingerprint(chromeWindows, `"Windows"`), expected: true},
+		{name: "Another browser on the platform", issued: newFingerprint(chromeWindows, ""), presented: newFingerprint(edgeWindows, ""), expected: false},
+		{name: "Another platform", issued: newFingerprint(chromeWindows, ""), presented: newFingerprint(chromeAndroid, ""), expected: false},
+		{name: "Another platform by client hint", issued: newFingerprint(chromeWindows, ""), presented: newFingerprint(chromeWindows, `"Linux"`), expected: false},
+		{name: "Token issued before fingerprints", legacy: true, presented: newFingerprint(firefoxLinux, ""), expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var hash []byte
+			if !tc.legacy {
+				hash = tc.issued.Hash()
+			}
+			require.Equal(t, tc.expected, tc.presented.Matches(hash))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package jwt
+
+import (
+	"net/http"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type KeyStore interface {
+	JWKS() JWKS
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	keyStore KeyStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	keyStore KeyStore,
+) *Handler {
+	return &Handler{
+		logger:   logger,
+		tracer:   otel.Tracer("jwt/handler"),
+		keyStore: keyStore,
+	}
+}
+
+// JWKSHandler publishes the public signing keys at /.well-known/jwks.json
+func (h *Handler) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	_, span := h.tracer.Start(r.Context(), "JWKSHandler")
+	defer span.End()
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	handlerutil.WriteJSONResponse(w, http.StatusOK, h.keyStore.JWKS())
+}
@@ -0,0 +1,195 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrNoSigningKey = errors.New("the first jwt key file must hold a private key")
+
+// Config lists the PEM files of the asymmetric signing keys. The first key signs new
+// tokens; the others are only used to verify tokens issued before a rotation and may
+// hold public keys. Without any key file, tokens are signed with the HMAC secret.
+// HMACUntil is the end of the migration to the keys: HMAC tokens issued before it are
+// accepted until then, and refused once key files are configured without it.
+type Config struct {
+	KeyFiles     []string `yaml:"key_files"  envconfig:"JWT_KEY_FILES"`
+	HMACUntilStr string   `yaml:"hmac_until" envconfig:"JWT_HMAC_UNTIL"`
+
+	HMACUntil time.Time `yaml:"-"`
+}
+
+// Validate parses the end of the HMAC migration window, an RFC 3339 timestamp
+func (c *Config) Validate() error {
+	if c.HMACUntilStr == "" {
+		return nil
+	}
+
+	until, err := time.Parse(time.RFC3339, c.HMACUntilStr)
+	if err != nil {
+		return fmt.Errorf("invalid jwt hmac_until: %w", err)
+	}
+	c.HMACUntil = until
+	return nil
+}
+
+// Key is an RSA or Ed25519 key identified by the thumbprint of its public key
+type Key struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Public  crypto.PublicKey
+	Private crypto.Signer
+}
+
+type KeySet struct {
+	keys      []Key
+	hmacUntil time.Time
+}
+
+// LoadKeySet reads the configured key files, it returns nil when none is configured
+func LoadKeySet(cfg Config) (*KeySet, error) {
+	if len(cfg.KeyFiles) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]Key, 0, len(cfg.KeyFiles))
+	for _, file := range cfg.KeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt key file %s: %w", file, err)
+		}
+
+		key, err := parseKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jwt key file %s: %w", file, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if keys[0].Private == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	return &KeySet{keys: keys, hmacUntil: cfg.HMACUntil}, nil
+}
+
+func parseKey(data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("unsupported PEM block type %s", block.Type)
+	}
+	if err != nil {
+		return Key{}, err
+	}
+
+	var key Key
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key = Key{Method: jwt.SigningMethodRS256, Public: &k.PublicKey, Private: k}
+	case *rsa.PublicKey:
+		key = Key{Method: jwt.SigningMethodRS256, Public: k}
+	case ed25519.PrivateKey:
+		key = Key{Method: jwt.SigningMethodEdDSA, Public: k.Public(), Private: k}
+	case ed25519.PublicKey:
+		key = Key{Method: jwt.SigningMethodEdDSA, Public: k}
+	default:
+		return Key{}, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", parsed)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		return Key{}, err
+	}
+	sum := sha256.Sum256(der)
+	key.ID = base64.RawURLEncoding.EncodeToString(sum[:12])
+
+	return key, nil
+}
+
+// Signing returns the key used for new tokens
+func (s *KeySet) Signing() Key {
+	return s.keys[0]
+}
+
+// AcceptsHMAC reports whether HMAC tokens are still accepted during the migration to
+// the keys of the set
+func (s *KeySet) AcceptsHMAC(now time.Time) bool {
+	return now.Before(s.hmacUntil)
+}
+
+func (s *KeySet) Lookup(id string) (Key, bool) {
+	for _, key := range s.keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// JWK is the public part of a key in the RFC 7517 format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists every configured key, so tokens signed before a rotation can still be verified
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwk := JWK{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Method.Alg(),
+		}
+
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
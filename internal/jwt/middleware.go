@@ -3,6 +3,7 @@ package jwt
 import (
 	"NYCU-SDC/core-system-backend/internal"
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -11,6 +12,7 @@ import (
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/NYCU-SDC/summer/pkg/problem"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -46,29 +48,18 @@ func (m *Middleware) AuthenticateMiddleware(handler http.HandlerFunc) http.Handl
 		defer span.End()
 		logger := logutil.WithContext(traceCtx, m.logger)
 
-		var tokenString string
-
-		// Extract access token from cookie
-		if accessTokenCookie, err := r.Cookie("access_token"); err == nil && accessTokenCookie.Value != "" {
-			tokenString = accessTokenCookie.Value
-		} else {
-			// Fallback to Authorization header
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				m.problemWriter.WriteError(traceCtx, w, internal.ErrMissingAuthHeader, logger)
-				return
-			}
-
-			fields := strings.Fields(authHeader)
-			if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
-				m.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidAuthHeaderFormat, logger)
-				return
-			}
-			tokenString = fields[1]
+		tokenString, err := TokenFromRequest(r)
+		if err != nil {
+			m.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
 		}
 
 		// Parse and validate JWT token
 		authenticatedUser, err := m.service.Parse(r.Context(), tokenString)
+		if errors.Is(err, internal.ErrRestrictedToken) {
+			m.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
 		if err != nil {
 			m.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidAuthUser, logger)
 			return
@@ -81,3 +72,102 @@ func (m *Middleware) AuthenticateMiddleware(handler http.HandlerFunc) http.Handl
 		handler(w, r.WithContext(ctxWithUser))
 	}
 }
+
+// RespondentMiddleware is the authentication of the routes needed to answer a form. It
+// takes full access tokens like AuthenticateMiddleware, and respondent and preview tokens
+// as long as the form in the path is the one they were issued for. Requests with a
+// preview token are marked in the context, see internal.IsPreview.
+func (m *Middleware) RespondentMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		traceCtx, span := m.tracer.Start(r.Context(), "RespondentMiddleware")
+		defer span.End()
+		logger := logutil.WithContext(traceCtx, m.logger)
+
+		tokenString, err := TokenFromRequest(r)
+		if err != nil {
+			m.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+
+		authenticatedUser, err := m.service.Parse(traceCtx, tokenString)
+		var scope string
+		if errors.Is(err, internal.ErrRestrictedToken) {
+			var formID uuid.UUID
+			authenticatedUser, formID, scope, err = m.service.ParseFormToken(traceCtx, tokenString)
+			if err == nil && formID.String() != formIDFromPath(r) {
+				m.problemWriter.WriteError(traceCtx, w, internal.ErrRestrictedToken, logger)
+				return
+			}
+		}
+		if err != nil {
+			m.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidAuthUser, logger)
+			return
+		}
+
+		ctxWithUser := context.WithValue(traceCtx, internal.UserContextKey, &authenticatedUser)
+		if scope == ScopePreview {
+			ctxWithUser = context.WithValue(ctxWithUser, internal.PreviewContextKey, true)
+		}
+		handler(w, r.WithContext(ctxWithUser))
+	}
+}
+
+// KioskMiddleware is the authentication of the check-in routes. It takes full access
+// tokens like AuthenticateMiddleware, and the tokens of kiosks paired through the device
+// flow, which no other route takes.
+func (m *Middleware) KioskMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		traceCtx, span := m.tracer.Start(r.Context(), "KioskMiddleware")
+		defer span.End()
+		logger := logutil.WithContext(traceCtx, m.logger)
+
+		tokenString, err := TokenFromRequest(r)
+		if err != nil {
+			m.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+
+		authenticatedUser, err := m.service.Parse(traceCtx, tokenString)
+		if errors.Is(err, internal.ErrRestrictedToken) {
+			authenticatedUser, err = m.service.ParseKioskToken(traceCtx, tokenString)
+			if errors.Is(err, internal.ErrRestrictedToken) {
+				m.problemWriter.WriteError(traceCtx, w, err, logger)
+				return
+			}
+		}
+		if err != nil {
+			m.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidAuthUser, logger)
+			return
+		}
+
+		ctxWithUser := context.WithValue(traceCtx, internal.UserContextKey, &authenticatedUser)
+		handler(w, r.WithContext(ctxWithUser))
+	}
+}
+
+// formIDFromPath supports both spellings of the form path parameter used by the routes
+func formIDFromPath(r *http.Request) string {
+	formID := r.PathValue("formId")
+	if formID == "" {
+		formID = r.PathValue("id")
+	}
+	return formID
+}
+
+// TokenFromRequest reads the access token from its cookie or the Authorization header
+func TokenFromRequest(r *http.Request) (string, error) {
+	if accessTokenCookie, err := r.Cookie("access_token"); err == nil && accessTokenCookie.Value != "" {
+		return accessTokenCookie.Value, nil
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", internal.ErrMissingAuthHeader
+	}
+
+	fields := strings.Fields(authHeader)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		return "", internal.ErrInvalidAuthHeaderFormat
+	}
+	return fields[1], nil
+}
@@ -0,0 +1,72 @@
+package jwt_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMiddleware_KioskMiddleware(t *testing.T) {
+	t.Parallel()
+
+	service := newService(t, jwt.Config{})
+	middleware := jwt.NewMiddleware(zap.NewNop(), validator.New(), internal.NewProblemWriter(), service)
+	approver := user.User{ID: uuid.New(), Role: []string{"user"}}
+
+	fullToken, err := service.New(context.Background(), approver)
+	require.NoError(t, err)
+	kioskToken, _, err := service.NewKioskToken(context.Background(), approver)
+	require.NoError(t, err)
+	respondentToken, _, err := service.NewRespondentToken(context.Background(), uuid.New(), uuid.New())
+	require.NoError(t, err)
+
+	type testCase struct {
+		name               string
+		token              string
+		middleware         func(http.HandlerFunc) http.HandlerFunc
+		expectedStatusCode int
+	}
+
+	testCases := []testCase{
+		{name: "Kiosk route takes a full access token", token: fullToken, middleware: middleware.KioskMiddleware, expectedStatusCode: http.StatusOK},
+		{name: "Kiosk route takes a kiosk token", token: kioskToken, middleware: middleware.KioskMiddleware, expectedStatusCode: http.StatusOK},
+		{name: "Kiosk route refuses a respondent token", token: respondentToken, middleware: middleware.KioskMiddleware, expectedStatusCode: http.StatusForbidden},
+		{name: "Kiosk route refuses a missing token", token: "", middleware: middleware.KioskMiddleware, expectedStatusCode: http.StatusUnauthorized},
+		{name: "Authenticated route refuses a kiosk token", token: kioskToken, middleware: middleware.AuthenticateMiddleware, expectedStatusCode: http.StatusForbidden},
+		{name: "Respondent route refuses a kiosk token", token: kioskToken, middleware: middleware.RespondentMiddleware, expectedStatusCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got *user.User
+			handler := tc.middleware(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = r.Context().Value(internal.UserContextKey).(*user.User)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/forms/"+uuid.NewString()+"/checkin", nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			require.Equal(t, tc.expectedStatusCode, rec.Code, rec.Body.String())
+			if tc.expectedStatusCode == http.StatusOK {
+				require.NotNil(t, got)
+				require.Equal(t, approver.ID, got.ID)
+			}
+		})
+	}
+}
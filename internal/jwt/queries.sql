@@ -1,8 +1,8 @@
--- name: GetUserIDByTokenID :one
-SELECT user_id FROM refresh_tokens WHERE id = $1 AND is_active = TRUE AND expiration_date > NOW();
+-- name: GetActiveByID :one
+SELECT * FROM refresh_tokens WHERE id = $1 AND is_active = TRUE AND expiration_date > NOW();
 
 -- name: Create :one
-INSERT INTO refresh_tokens (user_id, expiration_date) VALUES ($1, $2) RETURNING *;
+INSERT INTO refresh_tokens (user_id, expiration_date, user_agent, platform_hash) VALUES ($1, $2, $3, $4) RETURNING *;
 
 -- name: Inactivate :execrows
 UPDATE refresh_tokens SET is_active = FALSE WHERE id = $1 RETURNING *;
@@ -11,4 +11,11 @@ UPDATE refresh_tokens SET is_active = FALSE WHERE id = $1 RETURNING *;
 DELETE FROM refresh_tokens WHERE expiration_date < NOW() OR is_active = FALSE;
 
 -- name: GetRefreshTokenByID :one
-SELECT * FROM refresh_tokens WHERE id = $1;
\ No newline at end of file
+SELECT * FROM refresh_tokens WHERE id = $1;
+
+-- name: IsDelegationActive :one
+-- Same condition as the Use query of delegations, without recording the use
+SELECT EXISTS(
+    SELECT 1 FROM form_delegations
+    WHERE id = @id AND form_id = @form_id AND revoked_at IS NULL AND expires_at > now()
+);
\ No newline at end of file
@@ -13,22 +13,31 @@ import (
 )
 
 const create = `-- name: Create :one
-INSERT INTO refresh_tokens (user_id, expiration_date) VALUES ($1, $2) RETURNING id, user_id, is_active, expiration_date
+INSERT INTO refresh_tokens (user_id, expiration_date, user_agent, platform_hash) VALUES ($1, $2, $3, $4) RETURNING id, user_id, is_active, expiration_date, user_agent, platform_hash
 `
 
 type CreateParams struct {
 	UserID         uuid.UUID
 	ExpirationDate pgtype.Timestamptz
+	UserAgent      string
+	PlatformHash   []byte
 }
 
 func (q *Queries) Create(ctx context.Context, arg CreateParams) (RefreshToken, error) {
-	row := q.db.QueryRow(ctx, create, arg.UserID, arg.ExpirationDate)
+	row := q.db.QueryRow(ctx, create,
+		arg.UserID,
+		arg.ExpirationDate,
+		arg.UserAgent,
+		arg.PlatformHash,
+	)
 	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.IsActive,
 		&i.ExpirationDate,
+		&i.UserAgent,
+		&i.PlatformHash,
 	)
 	return i, err
 }
@@ -45,35 +54,44 @@ func (q *Queries) Delete(ctx context.Context) (int64, error) {
 	return result.RowsAffected(), nil
 }
 
-const getRefreshTokenByID = `-- name: GetRefreshTokenByID :one
-SELECT id, user_id, is_active, expiration_date FROM refresh_tokens WHERE id = $1
+const getActiveByID = `-- name: GetActiveByID :one
+SELECT id, user_id, is_active, expiration_date, user_agent, platform_hash FROM refresh_tokens WHERE id = $1 AND is_active = TRUE AND expiration_date > NOW()
 `
 
-func (q *Queries) GetRefreshTokenByID(ctx context.Context, id uuid.UUID) (RefreshToken, error) {
-	row := q.db.QueryRow(ctx, getRefreshTokenByID, id)
+func (q *Queries) GetActiveByID(ctx context.Context, id uuid.UUID) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, getActiveByID, id)
 	var i RefreshToken
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.IsActive,
 		&i.ExpirationDate,
+		&i.UserAgent,
+		&i.PlatformHash,
 	)
 	return i, err
 }
 
-const getUserIDByTokenID = `-- name: GetUserIDByTokenID :one
-SELECT user_id FROM refresh_tokens WHERE id = $1 AND is_active = TRUE AND expiration_date > NOW()
+const getRefreshTokenByID = `-- name: GetRefreshTokenByID :one
+SELECT id, user_id, is_active, expiration_date, user_agent, platform_hash FROM refresh_tokens WHERE id = $1
 `
 
-func (q *Queries) GetUserIDByTokenID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, getUserIDByTokenID, id)
-	var user_id uuid.UUID
-	err := row.Scan(&user_id)
-	return user_id, err
+func (q *Queries) GetRefreshTokenByID(ctx context.Context, id uuid.UUID) (RefreshToken, error) {
+	row := q.db.QueryRow(ctx, getRefreshTokenByID, id)
+	var i RefreshToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.IsActive,
+		&i.ExpirationDate,
+		&i.UserAgent,
+		&i.PlatformHash,
+	)
+	return i, err
 }
 
 const inactivate = `-- name: Inactivate :execrows
-UPDATE refresh_tokens SET is_active = FALSE WHERE id = $1 RETURNING id, user_id, is_active, expiration_date
+UPDATE refresh_tokens SET is_active = FALSE WHERE id = $1 RETURNING id, user_id, is_active, expiration_date, user_agent, platform_hash
 `
 
 func (q *Queries) Inactivate(ctx context.Context, id uuid.UUID) (int64, error) {
@@ -83,3 +101,23 @@ func (q *Queries) Inactivate(ctx context.Context, id uuid.UUID) (int64, error) {
 	}
 	return result.RowsAffected(), nil
 }
+
+const isDelegationActive = `-- name: IsDelegationActive :one
+SELECT EXISTS(
+    SELECT 1 FROM form_delegations
+    WHERE id = $1 AND form_id = $2 AND revoked_at IS NULL AND expires_at > now()
+)
+`
+
+type IsDelegationActiveParams struct {
+	ID     uuid.UUID
+	FormID uuid.UUID
+}
+
+// Same condition as the Use query of delegations, without recording the use
+func (q *Queries) IsDelegationActive(ctx context.Context, arg IsDelegationActiveParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isDelegationActive, arg.ID, arg.FormID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,10 @@
+package jwt
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// WellKnownRoutes declares the public keys other services verify our access tokens with
+func WellKnownRoutes(r route.Router, h *Handler) {
+	r.Handle("GET /.well-known/jwks.json", route.Public, route.PermissionNone, h.JWKSHandler)
+}
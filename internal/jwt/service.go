@@ -1,10 +1,12 @@
 package jwt
 
 import (
+	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/user"
 
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -21,18 +23,43 @@ import (
 
 const Issuer = "core-system"
 
+const (
+	// ScopeRespond restricts a token to answering the single form named in its claims
+	ScopeRespond = "respond"
+	// ScopePreview is ScopeRespond for a form author trying out a form; what it submits
+	// is test data
+	ScopePreview = "preview"
+	// ScopeManage restricts a token to managing the single form named in its claims on
+	// behalf of a delegation, whose ID is the ID of the token
+	ScopeManage = "manage"
+	// ScopeUserInfo restricts a token to the OpenID Connect userinfo endpoint; the client
+	// it was issued to is its audience and the scopes the user granted are in its claims
+	ScopeUserInfo = "userinfo"
+	// ScopeKiosk restricts a token to the check-in routes of a kiosk paired through the
+	// device flow, acting for the user who approved it
+	ScopeKiosk = "kiosk"
+
+	RespondentTokenExpiration = 2 * time.Hour
+	PreviewTokenExpiration    = 2 * time.Hour
+	// KioskTokenExpiration covers the check-in of an event; there is no refresh token,
+	// the kiosk is paired again after that
+	KioskTokenExpiration = 4 * time.Hour
+)
+
 type Querier interface {
-	GetUserIDByTokenID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	GetActiveByID(ctx context.Context, id uuid.UUID) (RefreshToken, error)
 	Create(ctx context.Context, arg CreateParams) (RefreshToken, error)
 	Inactivate(ctx context.Context, id uuid.UUID) (int64, error)
 	Delete(ctx context.Context) (int64, error)
 	GetRefreshTokenByID(ctx context.Context, id uuid.UUID) (RefreshToken, error)
+	IsDelegationActive(ctx context.Context, arg IsDelegationActiveParams) (bool, error)
 }
 
 type Service struct {
 	logger                 *zap.Logger
 	secret                 string
 	oauthProxySecret       string
+	keys                   *KeySet
 	accessTokenExpiration  time.Duration
 	refreshTokenExpiration time.Duration
 	queries                Querier
@@ -44,6 +71,7 @@ func NewService(
 	db DBTX,
 	secret string,
 	oauthProxySecret string,
+	keys *KeySet,
 	accessTokenExpiration time.Duration,
 	refreshTokenExpiration time.Duration,
 ) *Service {
@@ -53,6 +81,7 @@ func NewService(
 		tracer:                 otel.Tracer("jwt/service"),
 		secret:                 secret,
 		oauthProxySecret:       oauthProxySecret,
+		keys:                   keys,
 		accessTokenExpiration:  accessTokenExpiration,
 		refreshTokenExpiration: refreshTokenExpiration,
 	}
@@ -64,6 +93,11 @@ type claims struct {
 	Name      string
 	AvatarUrl string
 	Role      []string
+	// Scope is empty for full access tokens, restricted tokens are refused by Parse
+	Scope  string `json:",omitempty"`
+	FormID string `json:",omitempty"`
+	// OIDCScope lists the scopes granted to the client of a userinfo token
+	OIDCScope string `json:",omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -113,8 +147,7 @@ func (s Service) New(ctx context.Context, user user.User) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.secret))
+	tokenString, err := s.sign(claims)
 	if err != nil {
 		logger.Error("failed to sign token", zap.Error(err), zap.String("user_id", id.String()), zap.String("username", username), zap.String("role", strings.Join(user.Role, ",")))
 		return "", err
@@ -124,6 +157,426 @@ func (s Service) New(ctx context.Context, user user.User) (string, error) {
 	return tokenString, nil
 }
 
+// NewRespondentToken issues a short-lived token that can only answer the given form,
+// for respondents of public forms who have no account
+func (s Service) NewRespondentToken(ctx context.Context, respondentID uuid.UUID, formID uuid.UUID) (string, time.Time, error) {
+	traceCtx, span := s.tracer.Start(ctx, "NewRespondentToken")
+	defer span.End()
+
+	return s.newFormToken(traceCtx, respondentID, formID, ScopeRespond, RespondentTokenExpiration)
+}
+
+// NewPreviewToken issues a token that answers the given form like a respondent token,
+// with its submissions flagged as test data
+func (s Service) NewPreviewToken(ctx context.Context, respondentID uuid.UUID, formID uuid.UUID) (string, time.Time, error) {
+	traceCtx, span := s.tracer.Start(ctx, "NewPreviewToken")
+	defer span.End()
+
+	return s.newFormToken(traceCtx, respondentID, formID, ScopePreview, PreviewTokenExpiration)
+}
+
+func (s Service) newFormToken(ctx context.Context, respondentID uuid.UUID, formID uuid.UUID, scope string, expiration time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(expiration)
+	claims := &claims{
+		Role:   []string{"respondent"},
+		Scope:  scope,
+		FormID: formID.String(),
+	}
+
+	tokenString, err := s.signFormToken(ctx, uuid.New(), respondentID, claims, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenString, expiresAt, nil
+}
+
+// NewManageToken issues a token that manages the given form on behalf of a delegation.
+// It lasts as long as the delegation; revoking the delegation is checked on every use,
+// see ParseManageToken.
+func (s Service) NewManageToken(ctx context.Context, delegateID uuid.UUID, name string, formID uuid.UUID, delegationID uuid.UUID, expiresAt time.Time) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "NewManageToken")
+	defer span.End()
+
+	claims := &claims{
+		Name:   name,
+		Role:   []string{"delegate"},
+		Scope:  ScopeManage,
+		FormID: formID.String(),
+	}
+	return s.signFormToken(traceCtx, delegationID, delegateID, claims, expiresAt)
+}
+
+// NewKioskToken issues the token of a check-in kiosk approved by the given user. Parse
+// refuses it, only the routes of the Kiosk access level take it, see ParseKioskToken.
+func (s Service) NewKioskToken(ctx context.Context, approver user.User) (string, time.Time, error) {
+	traceCtx, span := s.tracer.Start(ctx, "NewKioskToken")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	jwtID := uuid.New()
+	now := time.Now()
+	expiresAt := now.Add(KioskTokenExpiration)
+	claims := &claims{
+		ID:        jwtID,
+		Username:  approver.Username.String,
+		Name:      approver.Name.String,
+		AvatarUrl: approver.AvatarUrl.String,
+		Role:      approver.Role,
+		Scope:     ScopeKiosk,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   approver.ID.String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        jwtID.String(),
+		},
+	}
+
+	tokenString, err := s.sign(claims)
+	if err != nil {
+		logger.Error("failed to sign kiosk token", zap.Error(err), zap.String("user_id", approver.ID.String()))
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// ParseKioskToken validates a token issued by NewKioskToken and returns the user who
+// approved the kiosk
+func (s Service) ParseKioskToken(ctx context.Context, tokenString string) (user.User, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ParseKioskToken")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	tokenClaims := &claims{}
+	_, err := jwt.ParseWithClaims(strings.TrimPrefix(tokenString, "Bearer "), tokenClaims, s.verificationKey, jwt.WithIssuer(Issuer))
+	if err != nil {
+		logger.Debug("Failed to parse kiosk token", zap.Error(err))
+		return user.User{}, err
+	}
+	if tokenClaims.Scope != ScopeKiosk || len(tokenClaims.Audience) > 0 {
+		return user.User{}, internal.ErrRestrictedToken
+	}
+
+	approverID, err := uuid.Parse(tokenClaims.Subject)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return user.User{
+		ID:        approverID,
+		Username:  pgtype.Text{String: tokenClaims.Username, Valid: true},
+		Name:      pgtype.Text{String: tokenClaims.Name, Valid: true},
+		AvatarUrl: pgtype.Text{String: tokenClaims.AvatarUrl, Valid: true},
+		Role:      tokenClaims.Role,
+	}, nil
+}
+
+// UserInfoGrant is the user and client a userinfo token was issued for, with the
+// scopes the user granted to the client
+type UserInfoGrant struct {
+	UserID   uuid.UUID
+	ClientID string
+	Scope    string
+}
+
+// NewUserInfoToken issues the access token of an OpenID Connect client. It only reads
+// the claims of the user at the userinfo endpoint, within the granted scopes; Parse
+// refuses it like every token issued for an audience.
+func (s Service) NewUserInfoToken(ctx context.Context, userID uuid.UUID, clientID string, grantedScope string) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "NewUserInfoToken")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	jwtID := uuid.New()
+	now := time.Now()
+	claims := &claims{
+		ID:        jwtID,
+		Scope:     ScopeUserInfo,
+		OIDCScope: grantedScope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        jwtID.String(),
+		},
+	}
+
+	tokenString, err := s.sign(claims)
+	if err != nil {
+		logger.Error("failed to sign userinfo token", zap.Error(err), zap.String("client_id", clientID), zap.String("user_id", userID.String()))
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// ParseUserInfoToken validates a token issued by NewUserInfoToken
+func (s Service) ParseUserInfoToken(ctx context.Context, tokenString string) (UserInfoGrant, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ParseUserInfoToken")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	tokenClaims := &claims{}
+	_, err := jwt.ParseWithClaims(strings.TrimPrefix(tokenString, "Bearer "), tokenClaims, s.verificationKey, jwt.WithIssuer(Issuer))
+	if err != nil {
+		logger.Debug("Failed to parse userinfo token", zap.Error(err))
+		return UserInfoGrant{}, err
+	}
+	if tokenClaims.Scope != ScopeUserInfo || len(tokenClaims.Audience) != 1 {
+		return UserInfoGrant{}, internal.ErrRestrictedToken
+	}
+
+	userID, err := uuid.Parse(tokenClaims.Subject)
+	if err != nil {
+		return UserInfoGrant{}, err
+	}
+
+	return UserInfoGrant{
+		UserID:   userID,
+		ClientID: tokenClaims.Audience[0],
+		Scope:    tokenClaims.OIDCScope,
+	}, nil
+}
+
+// signFormToken completes the claims of a restricted token and signs it
+func (s Service) signFormToken(ctx context.Context, jwtID uuid.UUID, subjectID uuid.UUID, claims *claims, expiresAt time.Time) (string, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	now := time.Now()
+	claims.ID = jwtID
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    Issuer,
+		Subject:   subjectID.String(),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ID:        jwtID.String(),
+	}
+
+	tokenString, err := s.sign(claims)
+	if err != nil {
+		logger.Error("failed to sign restricted token", zap.Error(err), zap.String("form_id", claims.FormID), zap.String("scope", claims.Scope))
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// ParseRespondentToken validates a token issued by NewRespondentToken and returns the
+// respondent and the form it may answer
+func (s Service) ParseRespondentToken(ctx context.Context, tokenString string) (user.User, uuid.UUID, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ParseRespondentToken")
+	defer span.End()
+
+	respondent, formID, scope, err := s.ParseFormToken(traceCtx, tokenString)
+	if err != nil {
+		return user.User{}, uuid.Nil, err
+	}
+	if scope != ScopeRespond {
+		return user.User{}, uuid.Nil, internal.ErrRestrictedToken
+	}
+
+	return respondent, formID, nil
+}
+
+// ParseFormToken validates a respondent or preview token and returns the respondent,
+// the form it may answer and the scope of the token
+func (s Service) ParseFormToken(ctx context.Context, tokenString string) (user.User, uuid.UUID, string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ParseFormToken")
+	defer span.End()
+
+	tokenClaims, formID, err := s.parseFormClaims(traceCtx, tokenString)
+	if err != nil {
+		return user.User{}, uuid.Nil, "", err
+	}
+	if tokenClaims.Scope != ScopeRespond && tokenClaims.Scope != ScopePreview {
+		return user.User{}, uuid.Nil, "", internal.ErrRestrictedToken
+	}
+
+	respondentID, err := uuid.Parse(tokenClaims.Subject)
+	if err != nil {
+		return user.User{}, uuid.Nil, "", err
+	}
+
+	return user.User{
+		ID:   respondentID,
+		Role: tokenClaims.Role,
+	}, formID, tokenClaims.Scope, nil
+}
+
+// ParseManageToken validates a token issued by NewManageToken and returns the delegate,
+// the form it may manage and the delegation it was issued for. Whether the delegation
+// is still active is for the caller to check.
+func (s Service) ParseManageToken(ctx context.Context, tokenString string) (user.User, uuid.UUID, uuid.UUID, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ParseManageToken")
+	defer span.End()
+
+	tokenClaims, formID, err := s.parseFormClaims(traceCtx, tokenString)
+	if err != nil {
+		return user.User{}, uuid.Nil, uuid.Nil, err
+	}
+	if tokenClaims.Scope != ScopeManage {
+		return user.User{}, uuid.Nil, uuid.Nil, internal.ErrRestrictedToken
+	}
+
+	delegateID, err := uuid.Parse(tokenClaims.Subject)
+	if err != nil {
+		return user.User{}, uuid.Nil, uuid.Nil, err
+	}
+	delegationID, err := uuid.Parse(tokenClaims.RegisteredClaims.ID)
+	if err != nil {
+		return user.User{}, uuid.Nil, uuid.Nil, err
+	}
+
+	return user.User{
+		ID:   delegateID,
+		Name: pgtype.Text{String: tokenClaims.Name, Valid: true},
+		Role: tokenClaims.Role,
+	}, formID, delegationID, nil
+}
+
+// parseFormClaims validates a token restricted to a form and returns its claims and form
+func (s Service) parseFormClaims(ctx context.Context, tokenString string) (*claims, uuid.UUID, error) {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	tokenClaims := &claims{}
+	_, err := jwt.ParseWithClaims(strings.TrimPrefix(tokenString, "Bearer "), tokenClaims, s.verificationKey, jwt.WithIssuer(Issuer))
+	if err != nil {
+		logger.Debug("Failed to parse restricted token", zap.Error(err))
+		return nil, uuid.Nil, err
+	}
+	if tokenClaims.Scope == "" {
+		return nil, uuid.Nil, internal.ErrRestrictedToken
+	}
+
+	formID, err := uuid.Parse(tokenClaims.FormID)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	return tokenClaims, formID, nil
+}
+
+// sign uses the active asymmetric key when one is configured and the HMAC secret otherwise
+func (s Service) sign(claims jwt.Claims) (string, error) {
+	if s.keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.secret))
+	}
+
+	key := s.keys.Signing()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// verificationKey picks the key by the algorithm and key ID of the token. HMAC tokens
+// are only accepted without asymmetric keys, or during the configured migration window
+// so sessions issued before switching to the keys stay valid until it ends.
+func (s Service) verificationKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if s.keys != nil && !s.keys.AcceptsHMAC(time.Now()) {
+			return nil, fmt.Errorf("signing method %s is no longer accepted", token.Method.Alg())
+		}
+		return []byte(s.secret), nil
+	}
+
+	if s.keys == nil {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+
+	id, _ := token.Header["kid"].(string)
+	key, ok := s.keys.Lookup(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", id)
+	}
+	if key.Method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("signing method %s does not match key %q", token.Method.Alg(), id)
+	}
+
+	return key.Public, nil
+}
+
+// IDToken holds the OpenID Connect claims about the authenticated user for one client
+type IDToken struct {
+	Issuer            string
+	Audience          string
+	Subject           string
+	Nonce             string
+	Name              string
+	PreferredUsername string
+	Picture           string
+	Email             string
+}
+
+type idTokenClaims struct {
+	Nonce             string `json:"nonce,omitempty"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	Email             string `json:"email,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewIDToken signs an OpenID Connect ID token. Without asymmetric keys it falls back to
+// HS256 with the client secret, as OpenID Connect Core section 10.1 allows, so clients
+// never learn our own secret.
+func (s Service) NewIDToken(ctx context.Context, idToken IDToken, clientSecret string) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "NewIDToken")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	now := time.Now()
+	claims := &idTokenClaims{
+		Nonce:             idToken.Nonce,
+		Name:              idToken.Name,
+		PreferredUsername: idToken.PreferredUsername,
+		Picture:           idToken.Picture,
+		Email:             idToken.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    idToken.Issuer,
+			Subject:   idToken.Subject,
+			Audience:  jwt.ClaimStrings{idToken.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenExpiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
+	}
+
+	var tokenString string
+	var err error
+	if s.keys == nil {
+		tokenString, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(clientSecret))
+	} else {
+		tokenString, err = s.sign(claims)
+	}
+	if err != nil {
+		logger.Error("failed to sign id token", zap.Error(err), zap.String("client_id", idToken.Audience), zap.String("user_id", idToken.Subject))
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// SigningAlgorithm is the algorithm of new access and ID tokens, advertised in the OpenID discovery document
+func (s Service) SigningAlgorithm() string {
+	if s.keys == nil {
+		return jwt.SigningMethodHS256.Alg()
+	}
+	return s.keys.Signing().Method.Alg()
+}
+
+// JWKS returns the public keys other services use to verify our access tokens
+func (s Service) JWKS() JWKS {
+	if s.keys == nil {
+		return JWKS{Keys: []JWK{}}
+	}
+	return s.keys.JWKS()
+}
+
 func (s Service) NewState(ctx context.Context, service, environment, callbackURL, redirectURL string) (string, error) {
 	traceCtx, span := s.tracer.Start(ctx, "NewState")
 	defer span.End()
@@ -163,14 +616,13 @@ func (s Service) Parse(ctx context.Context, tokenString string) (user.User, erro
 
 	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
-	secret := func(token *jwt.Token) (interface{}, error) {
-		return []byte(s.secret), nil
-	}
-
 	tokenClaims := &claims{}
-	token, err := jwt.ParseWithClaims(tokenString, tokenClaims, secret)
+	token, err := jwt.ParseWithClaims(tokenString, tokenClaims, s.verificationKey, jwt.WithIssuer(Issuer))
 	if err != nil {
 		switch {
+		case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+			logger.Warn("Failed to parse JWT token issued by another issuer, such as an ID token", zap.String("error", err.Error()))
+			return user.User{}, err
 		case errors.Is(err, jwt.ErrTokenMalformed):
 			logger.Warn("Failed to parse JWT token due to malformed structure, this is not a JWT token", zap.String("token", tokenString), zap.String("error", err.Error()))
 			return user.User{}, err
@@ -199,6 +651,16 @@ func (s Service) Parse(ctx context.Context, tokenString string) (user.User, erro
 		}
 	}
 
+	// Tokens issued for an audience are meant for a single client and endpoint
+	if len(tokenClaims.Audience) > 0 {
+		logger.Warn("Refused a token issued for an audience on a full access route", zap.Strings("audience", tokenClaims.Audience))
+		return user.User{}, internal.ErrRestrictedToken
+	}
+	if tokenClaims.Scope != "" {
+		logger.Warn("Refused a restricted token on a full access route", zap.String("scope", tokenClaims.Scope))
+		return user.User{}, internal.ErrRestrictedToken
+	}
+
 	// Parse user ID from subject
 	userID, err := uuid.Parse(tokenClaims.Subject)
 	if err != nil {
@@ -215,6 +677,88 @@ func (s Service) Parse(ctx context.Context, tokenString string) (user.User, erro
 	}, nil
 }
 
+// Introspection describes an access token in the terms of RFC 7662
+type Introspection struct {
+	Active    bool
+	Subject   string
+	Username  string
+	Name      string
+	Scope     string
+	FormID    string
+	Issuer    string
+	ID        string
+	ExpiresAt time.Time
+	IssuedAt  time.Time
+	NotBefore time.Time
+}
+
+// Introspect reports whether the token is a valid access token issued by us. Malformed,
+// expired or forged tokens are inactive rather than an error, and so are management
+// tokens whose delegation was revoked or expired, as the delegation middleware would
+// refuse them.
+func (s Service) Introspect(ctx context.Context, tokenString string) Introspection {
+	traceCtx, span := s.tracer.Start(ctx, "Introspect")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	tokenClaims := &claims{}
+	_, err := jwt.ParseWithClaims(tokenString, tokenClaims, s.verificationKey, jwt.WithIssuer(Issuer))
+	if err != nil {
+		logger.Debug("Introspected an inactive token", zap.Error(err))
+		return Introspection{}
+	}
+
+	if tokenClaims.Scope == ScopeManage {
+		active, err := s.delegationActive(traceCtx, tokenClaims)
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_delegations", "id", tokenClaims.RegisteredClaims.ID, logger, "check delegation of introspected token")
+			span.RecordError(err)
+			return Introspection{}
+		}
+		if !active {
+			logger.Debug("Introspected a management token of an inactive delegation", zap.String("delegation_id", tokenClaims.RegisteredClaims.ID))
+			return Introspection{}
+		}
+	}
+
+	introspection := Introspection{
+		Active:   true,
+		Subject:  tokenClaims.Subject,
+		Username: tokenClaims.Username,
+		Name:     tokenClaims.Name,
+		Scope:    tokenClaims.Scope,
+		FormID:   tokenClaims.FormID,
+		Issuer:   tokenClaims.Issuer,
+		ID:       tokenClaims.RegisteredClaims.ID,
+	}
+	if tokenClaims.ExpiresAt != nil {
+		introspection.ExpiresAt = tokenClaims.ExpiresAt.Time
+	}
+	if tokenClaims.IssuedAt != nil {
+		introspection.IssuedAt = tokenClaims.IssuedAt.Time
+	}
+	if tokenClaims.NotBefore != nil {
+		introspection.NotBefore = tokenClaims.NotBefore.Time
+	}
+
+	return introspection
+}
+
+// delegationActive checks the delegation a management token was issued for, whose ID
+// is the ID of the token
+func (s Service) delegationActive(ctx context.Context, tokenClaims *claims) (bool, error) {
+	delegationID, err := uuid.Parse(tokenClaims.RegisteredClaims.ID)
+	if err != nil {
+		return false, nil
+	}
+	formID, err := uuid.Parse(tokenClaims.FormID)
+	if err != nil {
+		return false, nil
+	}
+
+	return s.queries.IsDelegationActive(ctx, IsDelegationActiveParams{ID: delegationID, FormID: formID})
+}
+
 // ParseState parses the state jwt payload to get redirect URL
 func (s Service) ParseState(ctx context.Context, tokenString string) (string, error) {
 	traceCtx, span := s.tracer.Start(ctx, "ParseState")
@@ -262,21 +806,44 @@ func (s Service) ParseState(ctx context.Context, tokenString string) (string, er
 	return tokenClaims.RedirectURL, nil
 }
 
-func (s Service) GetUserIDByRefreshToken(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+// GetUserIDByRefreshToken returns the owner of an active refresh token presented by the
+// client. A token presented by another platform or browser than the one it was issued
+// to is inactivated, as its cookie was likely stolen; the owner is still returned along
+// with ErrRefreshTokenMismatch so the mismatch can be reported to them.
+func (s Service) GetUserIDByRefreshToken(ctx context.Context, id uuid.UUID, fingerprint Fingerprint) (uuid.UUID, error) {
 	traceCtx, span := s.tracer.Start(ctx, "GetUserIDByRefreshToken")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
 
-	userID, err := s.queries.GetUserIDByTokenID(ctx, id)
+	refreshToken, err := s.queries.GetActiveByID(traceCtx, id)
 	if err != nil {
 		logger.Error("failed to get user id by refresh token", zap.Error(err))
 		return uuid.UUID{}, err
 	}
 
-	return userID, nil
+	if !fingerprint.Matches(refreshToken.PlatformHash) {
+		_, err = s.queries.Inactivate(traceCtx, id)
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "refresh_token", "id", id.String(), logger, "inactivate mismatched refresh token")
+			span.RecordError(err)
+			return uuid.UUID{}, err
+		}
+
+		logger.Warn("Inactivated refresh token presented by another device",
+			zap.String("refresh_token_id", id.String()),
+			zap.String("user_id", refreshToken.UserID.String()),
+			zap.String("issued_user_agent", refreshToken.UserAgent),
+			zap.String("user_agent", fingerprint.UserAgent),
+		)
+		span.RecordError(internal.ErrRefreshTokenMismatch)
+		return refreshToken.UserID, internal.ErrRefreshTokenMismatch
+	}
+
+	return refreshToken.UserID, nil
 }
 
-func (s Service) GenerateRefreshToken(ctx context.Context, userID uuid.UUID) (RefreshToken, error) {
+// GenerateRefreshToken issues a refresh token bound to the fingerprint of the client
+func (s Service) GenerateRefreshToken(ctx context.Context, userID uuid.UUID, fingerprint Fingerprint) (RefreshToken, error) {
 	traceCtx, span := s.tracer.Start(ctx, "GenerateRefreshToken")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, s.logger)
@@ -298,6 +865,8 @@ func (s Service) GenerateRefreshToken(ctx context.Context, userID uuid.UUID) (Re
 			Time:  nextRefreshDate,
 			Valid: true,
 		},
+		UserAgent:    fingerprint.UserAgent,
+		PlatformHash: fingerprint.Hash(),
 	}
 	refreshToken, err := s.queries.Create(traceCtx, params)
 	if err != nil {
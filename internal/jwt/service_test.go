@@ -0,0 +1,538 @@
+package jwt_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testSecret = "test-secret"
+
+// writeKey writes a new Ed25519 key pair and returns the paths of its private and
+// public PEM files
+func writeKey(t *testing.T) (string, string) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "key.pem")
+	publicPath := filepath.Join(dir, "key.pub.pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600))
+	return privatePath, publicPath
+}
+
+// fakeDB answers every query with a single boolean, the only kind of row the queries
+// of the tests scan
+type fakeDB struct {
+	exists bool
+	err    error
+}
+
+func (db fakeDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, db.err
+}
+
+func (db fakeDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, db.err
+}
+
+func (db fakeDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return db
+}
+
+func (db fakeDB) Scan(dest ...any) error {
+	if db.err != nil {
+		return db.err
+	}
+	*dest[0].(*bool) = db.exists
+	return nil
+}
+
+// newService returns a service signing with the given key files, or the HMAC secret
+// when there are none
+func newService(t *testing.T, cfg jwt.Config) *jwt.Service {
+	t.Helper()
+	return newServiceWithDB(t, cfg, fakeDB{})
+}
+
+func newServiceWithDB(t *testing.T, cfg jwt.Config, db jwt.DBTX) *jwt.Service {
+	t.Helper()
+
+	keys, err := jwt.LoadKeySet(cfg)
+	require.NoError(t, err)
+	return jwt.NewService(zap.NewNop(), db, testSecret, testSecret, keys, 15*time.Minute, 24*time.Hour)
+}
+
+func newToken(t *testing.T, service *jwt.Service) (string, uuid.UUID) {
+	t.Helper()
+
+	userID := uuid.New()
+	token, err := service.New(context.Background(), user.User{ID: userID, Role: []string{"user"}})
+	require.NoError(t, err)
+	return token, userID
+}
+
+func TestService_KeyRotation(t *testing.T) {
+	t.Parallel()
+
+	oldPrivate, oldPublic := writeKey(t)
+	newPrivate, _ := writeKey(t)
+
+	type testCase struct {
+		name        string
+		verifier    jwt.Config
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "Same key",
+			verifier: jwt.Config{KeyFiles: []string{oldPrivate}},
+		},
+		{
+			name:     "Rotated key keeps the old public key",
+			verifier: jwt.Config{KeyFiles: []string{newPrivate, oldPublic}},
+		},
+		{
+			name:        "Rotated key drops the old key",
+			verifier:    jwt.Config{KeyFiles: []string{newPrivate}},
+			expectedErr: true,
+		},
+		{
+			name:        "No keys",
+			verifier:    jwt.Config{},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			token, userID := newToken(t, newService(t, jwt.Config{KeyFiles: []string{oldPrivate}}))
+
+			parsed, err := newService(t, tc.verifier).Parse(context.Background(), token)
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, userID, parsed.ID)
+		})
+	}
+}
+
+func TestService_HMACMigrationWindow(t *testing.T) {
+	t.Parallel()
+
+	private, _ := writeKey(t)
+
+	type testCase struct {
+		name        string
+		verifier    jwt.Config
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{
+			name:     "No key set loaded",
+			verifier: jwt.Config{},
+		},
+		{
+			name:     "Window still open",
+			verifier: jwt.Config{KeyFiles: []string{private}, HMACUntil: time.Now().Add(time.Hour)},
+		},
+		{
+			name:        "Window expired",
+			verifier:    jwt.Config{KeyFiles: []string{private}, HMACUntil: time.Now().Add(-time.Second)},
+			expectedErr: true,
+		},
+		{
+			name:        "No window configured",
+			verifier:    jwt.Config{KeyFiles: []string{private}},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			token, userID := newToken(t, newService(t, jwt.Config{}))
+
+			verifier := newService(t, tc.verifier)
+			parsed, err := verifier.Parse(context.Background(), token)
+			if tc.expectedErr {
+				require.Error(t, err)
+				require.False(t, verifier.Introspect(context.Background(), token).Active)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, userID, parsed.ID)
+		})
+	}
+}
+
+func TestService_IntrospectManageToken(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name     string
+		db       fakeDB
+		expected bool
+	}
+
+	testCases := []testCase{
+		{name: "Active delegation", db: fakeDB{exists: true}, expected: true},
+		{name: "Revoked or expired delegation", db: fakeDB{exists: false}, expected: false},
+		{name: "Database error", db: fakeDB{err: errors.New("connection refused")}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := newServiceWithDB(t, jwt.Config{}, tc.db)
+			delegationID := uuid.New()
+			formID := uuid.New()
+			token, err := service.NewManageToken(context.Background(), uuid.New(), "Delegate", formID, delegationID, time.Now().Add(time.Hour))
+			require.NoError(t, err)
+
+			introspection := service.Introspect(context.Background(), token)
+			require.Equal(t, tc.expected, introspection.Active)
+			if tc.expected {
+				require.Equal(t, jwt.ScopeManage, introspection.Scope)
+				require.Equal(t, delegationID.String(), introspection.ID)
+				require.Equal(t, formID.String(), introspection.FormID)
+			}
+		})
+	}
+}
+
+func TestService_IntrospectIgnoresDelegationsForOtherScopes(t *testing.T) {
+	t.Parallel()
+
+	// A revoked delegation in the database would make any checked token inactive
+	service := newServiceWithDB(t, jwt.Config{}, fakeDB{exists: false})
+
+	token, _ := newToken(t, service)
+	require.True(t, service.Introspect(context.Background(), token).Active)
+
+	respondentToken, _, err := service.NewRespondentToken(context.Background(), uuid.New(), uuid.New())
+	require.NoError(t, err)
+	require.True(t, service.Introspect(context.Background(), respondentToken).Active)
+}
+
+func TestService_ParseRefusesTokensForOtherAudiences(t *testing.T) {
+	t.Parallel()
+
+	private, _ := writeKey(t)
+	service := newService(t, jwt.Config{KeyFiles: []string{private}})
+	userID := uuid.New()
+
+	userInfoToken, err := service.NewUserInfoToken(context.Background(), userID, "club-tool", "openid email")
+	require.NoError(t, err)
+	idToken, err := service.NewIDToken(context.Background(), jwt.IDToken{
+		Issuer:   "https://core-system.example.com",
+		Audience: "club-tool",
+		Subject:  userID.String(),
+	}, "client-secret")
+	require.NoError(t, err)
+	fullToken, _ := newToken(t, service)
+
+	type testCase struct {
+		name             string
+		token            string
+		expectedParseErr error
+		expectedUserInfo bool
+	}
+
+	testCases := []testCase{
+		{name: "Full access token", token: fullToken, expectedUserInfo: false},
+		{name: "Userinfo token", token: userInfoToken, expectedParseErr: internal.ErrRestrictedToken, expectedUserInfo: true},
+		{name: "ID token of a foreign issuer", token: idToken, expectedParseErr: gojwt.ErrTokenInvalidIssuer, expectedUserInfo: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := service.Parse(context.Background(), tc.token)
+			if tc.expectedParseErr != nil {
+				require.ErrorIs(t, err, tc.expectedParseErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			grant, err := service.ParseUserInfoToken(context.Background(), tc.token)
+			if !tc.expectedUserInfo {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, jwt.UserInfoGrant{UserID: userID, ClientID: "club-tool", Scope: "openid email"}, grant)
+		})
+	}
+}
+
+func TestService_RestrictedScopes(t *testing.T) {
+	t.Parallel()
+
+	service := newService(t, jwt.Config{})
+	approver := user.User{ID: uuid.New(), Role: []string{"user"}}
+
+	fullToken, _ := newToken(t, service)
+	kioskToken, expiresAt, err := service.NewKioskToken(context.Background(), approver)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(jwt.KioskTokenExpiration), expiresAt, time.Minute)
+	respondentToken, _, err := service.NewRespondentToken(context.Background(), uuid.New(), uuid.New())
+	require.NoError(t, err)
+	manageToken, err := service.NewManageToken(context.Background(), uuid.New(), "Delegate", uuid.New(), uuid.New(), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	userInfoToken, err := service.NewUserInfoToken(context.Background(), uuid.New(), "club-tool", "openid")
+	require.NoError(t, err)
+
+	type testCase struct {
+		name          string
+		token         string
+		expectedFull  bool
+		expectedKiosk bool
+		expectedForm  bool
+	}
+
+	testCases := []testCase{
+		{name: "Full access token", token: fullToken, expectedFull: true},
+		{name: "Kiosk token", token: kioskToken, expectedKiosk: true},
+		{name: "Respondent token", token: respondentToken, expectedForm: true},
+		{name: "Manage token", token: manageToken},
+		{name: "Userinfo token", token: userInfoToken},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := service.Parse(context.Background(), tc.token)
+			if tc.expectedFull {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, internal.ErrRestrictedToken)
+			}
+
+			kiosk, err := service.ParseKioskToken(context.Background(), tc.token)
+			if tc.expectedKiosk {
+				require.NoError(t, err)
+				require.Equal(t, approver.ID, kiosk.ID)
+			} else {
+				require.Error(t, err)
+			}
+
+			_, _, _, err = service.ParseFormToken(context.Background(), tc.token)
+			if tc.expectedForm {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		hmacUntil   string
+		expected    time.Time
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{name: "Empty", hmacUntil: ""},
+		{name: "RFC 3339", hmacUntil: "2026-12-31T00:00:00Z", expected: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{name: "Not a timestamp", hmacUntil: "next week", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := jwt.Config{HMACUntilStr: tc.hmacUntil}
+			err := cfg.Validate()
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tc.expected.Equal(cfg.HMACUntil))
+		})
+	}
+}
+
+// refreshTokenDB keeps the refresh tokens of the tests in memory, answering the queries
+// of issuing, reading and inactivating them
+type refreshTokenDB struct {
+	tokens map[uuid.UUID]*jwt.RefreshToken
+}
+
+func (db *refreshTokenDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if !strings.Contains(sql, "name: Inactivate") {
+		return pgconn.NewCommandTag("DELETE 0"), nil
+	}
+
+	token, ok := db.tokens[args[0].(uuid.UUID)]
+	if !ok || !token.IsActive.Bool {
+		return pgconn.NewCommandTag("UPDATE 0"), nil
+	}
+	token.IsActive.Bool = false
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (db *refreshTokenDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (db *refreshTokenDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	switch {
+	case strings.Contains(sql, "name: Create"):
+		token := &jwt.RefreshToken{
+			ID:             uuid.New(),
+			UserID:         args[0].(uuid.UUID),
+			IsActive:       pgtype.Bool{Bool: true, Valid: true},
+			ExpirationDate: args[1].(pgtype.Timestamptz),
+			UserAgent:      args[2].(string),
+			PlatformHash:   args[3].([]byte),
+		}
+		db.tokens[token.ID] = token
+		return refreshTokenRow{token: token}
+	case strings.Contains(sql, "name: GetActiveByID"):
+		token, ok := db.tokens[args[0].(uuid.UUID)]
+		if !ok || !token.IsActive.Bool {
+			return refreshTokenRow{err: pgx.ErrNoRows}
+		}
+		return refreshTokenRow{token: token}
+	}
+	return refreshTokenRow{err: errors.New("unexpected query")}
+}
+
+type refreshTokenRow struct {
+	token *jwt.RefreshToken
+	err   error
+}
+
+func (r refreshTokenRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*uuid.UUID) = r.token.ID
+	*dest[1].(*uuid.UUID) = r.token.UserID
+	*dest[2].(*pgtype.Bool) = r.token.IsActive
+	*dest[3].(*pgtype.Timestamptz) = r.token.ExpirationDate
+	*dest[4].(*string) = r.token.UserAgent
+	*dest[5].(*[]byte) = r.token.PlatformHash
+	return nil
+}
+
+func TestService_GetUserIDByRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name           string
+		issued         jwt.Fingerprint
+		legacy         bool
+		presented      jwt.Fingerprint
+		expectedErr    error
+		expectedActive bool
+	}
+
+	testCases := []testCase{
+		{
+			name:           "Device it was issued to",
+			issued:         newFingerprint(chromeWindows, ""),
+			presented:      newFingerprint(chromeWindows, ""),
+			expectedActive: true,
+		},
+		{
+			name:           "Updated browser",
+			issued:         newFingerprint(chromeWindows, ""),
+			presented:      newFingerprint(chromeWindows2, `"Windows"`),
+			expectedActive: true,
+		},
+		{
+			name:        "Another browser",
+			issued:      newFingerprint(chromeWindows, ""),
+			presented:   newFingerprint(firefoxLinux, ""),
+			expectedErr: internal.ErrRefreshTokenMismatch,
+		},
+		{
+			name:        "Another platform",
+			issued:      newFingerprint(safariIPhone, ""),
+			presented:   newFingerprint(safariMac, ""),
+			expectedErr: internal.ErrRefreshTokenMismatch,
+		},
+		{
+			name:           "Token issued before fingerprints",
+			legacy:         true,
+			presented:      newFingerprint(chromeAndroid, ""),
+			expectedActive: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &refreshTokenDB{tokens: make(map[uuid.UUID]*jwt.RefreshToken)}
+			service := newServiceWithDB(t, jwt.Config{}, db)
+			userID := uuid.New()
+
+			refreshToken, err := service.GenerateRefreshToken(context.Background(), userID, tc.issued)
+			require.NoError(t, err)
+			require.Equal(t, tc.issued.UserAgent, refreshToken.UserAgent)
+			if tc.legacy {
+				db.tokens[refreshToken.ID].PlatformHash = nil
+			}
+
+			owner, err := service.GetUserIDByRefreshToken(context.Background(), refreshToken.ID, tc.presented)
+			// The owner comes back either way, for a mismatch to be reported to them
+			require.Equal(t, userID, owner)
+			require.Equal(t, tc.expectedActive, db.tokens[refreshToken.ID].IsActive.Bool)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+
+				// The inactivated token no longer refreshes, not even on its own device
+				_, err = service.GetUserIDByRefreshToken(context.Background(), refreshToken.ID, tc.issued)
+				require.ErrorIs(t, err, pgx.ErrNoRows)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package leader
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// DefaultRetryInterval is how often a replica tries to take over a job it does not lead,
+// and how often the leader checks it still holds the lock
+const DefaultRetryInterval = 30 * time.Second
+
+// Elector runs each scheduled job on one replica at a time. The replica leading a job
+// holds a Postgres advisory lock named after it on a connection of its own; when the
+// replica dies the connection and the lock go with it, and another replica takes over
+// on its next try.
+type Elector struct {
+	logger        *zap.Logger
+	databaseURL   string
+	retryInterval time.Duration
+}
+
+func NewElector(logger *zap.Logger, databaseURL string, retryInterval time.Duration) *Elector {
+	return &Elector{
+		logger:        logger,
+		databaseURL:   databaseURL,
+		retryInterval: retryInterval,
+	}
+}
+
+// Run calls job whenever this replica leads the job named name, until the context is
+// done. The context passed to job is canceled when the lead is lost. A job returning on
+// its own, because it has nothing to do on this deployment, ends Run.
+func (e *Elector) Run(ctx context.Context, name string, job func(ctx context.Context)) {
+	logger := e.logger.With(zap.String("job", name))
+
+	for {
+		finished, err := e.lead(ctx, logger, name, job)
+		if err != nil {
+			logger.Warn("Lost or failed to take the lead of scheduled job", zap.Error(err))
+		}
+		if finished {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// lead runs job if the lock of the job is free, and reports whether Run is done
+func (e *Elector) lead(ctx context.Context, logger *zap.Logger, name string, job func(ctx context.Context)) (bool, error) {
+	conn, err := pgx.Connect(ctx, e.databaseURL)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		err := conn.Close(context.Background())
+		if err != nil {
+			logger.Warn("Failed to close scheduled job lock connection", zap.Error(err))
+		}
+	}()
+
+	var acquired bool
+	err = conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtextextended($1, 0))", "job:"+name).Scan(&acquired)
+	if err != nil {
+		return false, err
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	logger.Info("Leading scheduled job")
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job(jobCtx)
+	}()
+
+	ticker := time.NewTicker(e.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			// Closing the connection releases the lock
+			return ctx.Err() == nil, nil
+		case <-ticker.C:
+			err = conn.Ping(ctx)
+			if err != nil && ctx.Err() == nil {
+				// The lock may already be held by another replica; stop before it runs twice
+				cancel()
+				<-done
+				return false, err
+			}
+		}
+	}
+}
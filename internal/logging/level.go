@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Level switches the level of the running logger. It is changed by an admin through
+// the API or by reloading the config on SIGHUP; neither survives a restart, which
+// starts from the configured level again.
+type Level struct {
+	logger *zap.Logger
+	level  zap.AtomicLevel
+}
+
+func NewLevel(logger *zap.Logger, level zap.AtomicLevel) *Level {
+	return &Level{
+		logger: logger,
+		level:  level,
+	}
+}
+
+// Get returns the current level, e.g. "info"
+func (l *Level) Get() string {
+	return l.level.Level().String()
+}
+
+// Set switches to the named level
+func (l *Level) Set(name string) error {
+	level, err := zapcore.ParseLevel(name)
+	if err != nil {
+		return fmt.Errorf("%w: %s", internal.ErrInvalidLogLevel, name)
+	}
+
+	previous := l.level.Level()
+	l.level.SetLevel(level)
+	if previous != level {
+		l.logger.Info("Changed log level", zap.String("from", previous.String()), zap.String("to", level.String()))
+	}
+
+	return nil
+}
+
+// Watch sets the level returned by load every time the process receives SIGHUP, until
+// the context is done
+func (l *Level) Watch(ctx context.Context, load func() (string, error)) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			name, err := load()
+			if err != nil {
+				l.logger.Warn("Failed to reload config for the log level", zap.Error(err))
+				continue
+			}
+			if name == "" {
+				continue
+			}
+
+			err = l.Set(name)
+			if err != nil {
+				l.logger.Warn("Ignored invalid log level in the reloaded config", zap.String("log_level", name))
+			}
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package logging
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the runtime log level for admins
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /admin/log-level", route.Authenticated, route.PermissionAdmin, h.GetLevelHandler)
+	r.Handle("PUT /admin/log-level", route.Authenticated, route.PermissionAdmin, h.SetLevelHandler)
+}
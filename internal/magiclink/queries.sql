@@ -0,0 +1,23 @@
+-- name: Create :one
+INSERT INTO magic_links (email, redirect_url, device_hash, ip_address, user_agent, expires_at)
+VALUES (@email, @redirect_url, @device_hash, @ip_address, @user_agent, @expires_at)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM magic_links WHERE id = @id;
+
+-- name: Consume :one
+UPDATE magic_links SET used_at = now()
+WHERE id = @id AND used_at IS NULL AND expires_at > now()
+RETURNING *;
+
+-- name: CountSinceByEmail :one
+SELECT COUNT(*) FROM magic_links
+WHERE email = @email AND created_at > @since;
+
+-- name: CountSinceByIPAddress :one
+SELECT COUNT(*) FROM magic_links
+WHERE ip_address = @ip_address AND created_at > @since;
+
+-- name: DeleteExpired :execrows
+DELETE FROM magic_links WHERE expires_at < @before;
\ No newline at end of file
@@ -0,0 +1,403 @@
+package magiclink
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/mail"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultCleanupInterval = time.Hour
+
+	defaultTTL = 15 * time.Minute
+	maxTTL     = time.Hour
+
+	maxUserAgentLength = 512
+
+	// Requests are counted over rateWindow, per address and per client IP, and links
+	// are kept a day for the counting before they are deleted
+	rateWindow      = 15 * time.Minute
+	maxPerEmail     = 5
+	maxPerIPAddress = 20
+	retention       = 24 * time.Hour
+
+	// verifyPath is where the links in the mail point to, under the versioned API
+	verifyPath = "/auth/login/magic-link/verify"
+)
+
+// Config enables signing in with a link sent by mail, for people without an account
+// of an OAuth provider. AllowedDomains limits the addresses that may ask for a link,
+// every address may when empty.
+type Config struct {
+	Enabled        bool     `yaml:"enabled"         envconfig:"MAGIC_LINK_ENABLED"`
+	TTLStr         string   `yaml:"ttl"             envconfig:"MAGIC_LINK_TTL"`
+	AllowedDomains []string `yaml:"allowed_domains" envconfig:"MAGIC_LINK_ALLOWED_DOMAINS"`
+
+	TTL time.Duration `yaml:"-"`
+}
+
+// Validate parses the lifetime of the links, at most an hour, and normalizes the domains
+func (c *Config) Validate() error {
+	c.TTL = defaultTTL
+	if c.TTLStr != "" {
+		ttl, err := time.ParseDuration(c.TTLStr)
+		if err != nil {
+			return fmt.Errorf("invalid magic_link ttl: %w", err)
+		}
+		if ttl <= 0 || ttl > maxTTL {
+			return fmt.Errorf("magic_link ttl must be greater than zero and at most %s", maxTTL)
+		}
+		c.TTL = ttl
+	}
+
+	for i, domain := range c.AllowedDomains {
+		c.AllowedDomains[i] = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+	}
+	return nil
+}
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (MagicLink, error)
+	GetByID(ctx context.Context, id uuid.UUID) (MagicLink, error)
+	Consume(ctx context.Context, id uuid.UUID) (MagicLink, error)
+	CountSinceByEmail(ctx context.Context, arg CountSinceByEmailParams) (int64, error)
+	CountSinceByIPAddress(ctx context.Context, arg CountSinceByIPAddressParams) (int64, error)
+	DeleteExpired(ctx context.Context, before pgtype.Timestamptz) (int64, error)
+}
+
+// Device is the client a link is requested from, reported in the mail and on the
+// confirmation of another device
+type Device struct {
+	IPAddress string
+	UserAgent string
+}
+
+type Service struct {
+	logger     *zap.Logger
+	queries    Querier
+	tracer     trace.Tracer
+	config     Config
+	secret     []byte
+	baseURL    string
+	mailSender mail.Sender
+}
+
+// NewService creates the service; secret signs the links, which point to baseURL
+func NewService(logger *zap.Logger, db DBTX, config Config, secret string, baseURL string, mailSender mail.Sender) *Service {
+	return &Service{
+		logger:     logger,
+		queries:    New(db),
+		tracer:     otel.Tracer("magiclink/service"),
+		config:     config,
+		secret:     []byte(secret),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		mailSender: mailSender,
+	}
+}
+
+// TTL is how long a link can be used once sent
+func (s *Service) TTL() time.Duration {
+	return s.config.TTL
+}
+
+// Request mails a sign in link to the address and returns the device nonce the
+// requesting client keeps, so the link signs in without confirmation when opened
+// there. No account is needed; one is created when the link is first used.
+// redirectURL is where to go after signing in and must be a path of this site.
+func (s *Service) Request(ctx context.Context, email string, redirectURL string, device Device) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Request")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	if !s.config.Enabled {
+		span.RecordError(internal.ErrMagicLinkDisabled)
+		return "", internal.ErrMagicLinkDisabled
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	err := s.validate(email, redirectURL)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	err = s.checkRate(traceCtx, logger, email, device.IPAddress)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	userAgent := device.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	nonce, err := randomToken()
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	link, err := s.queries.Create(traceCtx, CreateParams{
+		Email:       email,
+		RedirectUrl: redirectURL,
+		DeviceHash:  hashNonce(nonce),
+		IpAddress:   device.IPAddress,
+		UserAgent:   userAgent,
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(s.config.TTL), Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "create magic link")
+		span.RecordError(err)
+		return "", err
+	}
+
+	err = s.mailSender.SendMail(traceCtx, s.message(link))
+	if err != nil {
+		err = fmt.Errorf("failed to send magic link: %w", err)
+		span.RecordError(err)
+		return "", err
+	}
+
+	logger.Info("Sent magic link", zap.String("link_id", link.ID.String()))
+	return nonce, nil
+}
+
+// Inspect returns the unused link of the token, and whether it was requested by the
+// device holding the nonce; links opened elsewhere are used only after confirmation
+func (s *Service) Inspect(ctx context.Context, token string, nonce string) (MagicLink, bool, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Inspect")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	id, err := s.parseToken(token)
+	if err != nil {
+		span.RecordError(err)
+		return MagicLink{}, false, err
+	}
+
+	link, err := s.queries.GetByID(traceCtx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.RecordError(internal.ErrMagicLinkInvalid)
+			return MagicLink{}, false, internal.ErrMagicLinkInvalid
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "magic_links", "id", id.String(), logger, "get magic link")
+		span.RecordError(err)
+		return MagicLink{}, false, err
+	}
+	if link.UsedAt.Valid || !link.ExpiresAt.Time.After(time.Now()) {
+		span.RecordError(internal.ErrMagicLinkInvalid)
+		return MagicLink{}, false, internal.ErrMagicLinkInvalid
+	}
+
+	sameDevice := nonce != "" && subtle.ConstantTimeCompare(hashNonce(nonce), link.DeviceHash) == 1
+	return link, sameDevice, nil
+}
+
+// Consume uses up the link of the token, which signs in once
+func (s *Service) Consume(ctx context.Context, token string) (MagicLink, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Consume")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	id, err := s.parseToken(token)
+	if err != nil {
+		span.RecordError(err)
+		return MagicLink{}, err
+	}
+
+	link, err := s.queries.Consume(traceCtx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.RecordError(internal.ErrMagicLinkInvalid)
+			return MagicLink{}, internal.ErrMagicLinkInvalid
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "magic_links", "id", id.String(), logger, "consume magic link")
+		span.RecordError(err)
+		return MagicLink{}, err
+	}
+
+	return link, nil
+}
+
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.DeleteExpired(ctx)
+			if err != nil {
+				s.logger.Error("Failed to delete expired magic links", zap.Error(err))
+			}
+		}
+	}
+}
+
+// DeleteExpired removes the links expired for longer than requests are counted
+func (s *Service) DeleteExpired(ctx context.Context) error {
+	traceCtx, span := s.tracer.Start(ctx, "DeleteExpired")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	rows, err := s.queries.DeleteExpired(traceCtx, pgtype.Timestamptz{Time: time.Now().Add(-retention), Valid: true})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "delete expired magic links")
+		span.RecordError(err)
+		return err
+	}
+
+	if rows > 0 {
+		logger.Debug("Deleted expired magic links", zap.Int64("count", rows))
+	}
+	return nil
+}
+
+func (s *Service) validate(email string, redirectURL string) error {
+	_, domain, found := strings.Cut(email, "@")
+	if !found || domain == "" {
+		return fmt.Errorf("%w: invalid email address", internal.ErrMagicLinkRequestInvalid)
+	}
+	if len(s.config.AllowedDomains) > 0 && !containsDomain(s.config.AllowedDomains, domain) {
+		return fmt.Errorf("%w: %s addresses cannot sign in with a magic link", internal.ErrMagicLinkRequestInvalid, domain)
+	}
+
+	// Only paths of this site, so the links cannot send people elsewhere
+	if redirectURL != "" && (!strings.HasPrefix(redirectURL, "/") || strings.HasPrefix(redirectURL, "//") || strings.Contains(redirectURL, "\\")) {
+		return fmt.Errorf("%w: redirect must be a path of this site", internal.ErrMagicLinkRequestInvalid)
+	}
+	return nil
+}
+
+func containsDomain(domains []string, domain string) bool {
+	for _, allowed := range domains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) checkRate(ctx context.Context, logger *zap.Logger, email string, ipAddress string) error {
+	since := pgtype.Timestamptz{Time: time.Now().Add(-rateWindow), Valid: true}
+
+	count, err := s.queries.CountSinceByEmail(ctx, CountSinceByEmailParams{Email: email, Since: since})
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "count magic links by email")
+	}
+	if count >= maxPerEmail {
+		return fmt.Errorf("%w: at most %d links per address every %s", internal.ErrMagicLinkRateLimited, maxPerEmail, rateWindow)
+	}
+
+	if ipAddress == "" {
+		return nil
+	}
+	count, err = s.queries.CountSinceByIPAddress(ctx, CountSinceByIPAddressParams{IpAddress: ipAddress, Since: since})
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "count magic links by ip address")
+	}
+	if count >= maxPerIPAddress {
+		return fmt.Errorf("%w: at most %d links per client every %s", internal.ErrMagicLinkRateLimited, maxPerIPAddress, rateWindow)
+	}
+	return nil
+}
+
+func (s *Service) message(link MagicLink) mail.Message {
+	verifyURL := s.baseURL + route.V1 + verifyPath + "?token=" + url.QueryEscape(s.signToken(link))
+
+	var body strings.Builder
+	body.WriteString("Open the link below to sign in to Core System. It works once, for the next ")
+	body.WriteString(s.config.TTL.String() + ".\n\n")
+	body.WriteString(verifyURL + "\n\n")
+	body.WriteString("It was requested from " + link.IpAddress)
+	if link.UserAgent != "" {
+		body.WriteString(" (" + link.UserAgent + ")")
+	}
+	body.WriteString(". Opened on another device, the link asks to confirm signing in there.\n")
+	body.WriteString("If you did not ask to sign in, ignore this mail.\n")
+
+	return mail.Message{
+		To:      link.Email,
+		Subject: "Sign in to Core System",
+		Body:    body.String(),
+	}
+}
+
+// signToken signs the id and the expiry of the link, so forged and expired tokens are
+// refused before the database is asked
+func (s *Service) signToken(link MagicLink) string {
+	payload := link.ID.String() + "." + strconv.FormatInt(link.ExpiresAt.Time.Unix(), 10)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+func (s *Service) parseToken(token string) (uuid.UUID, error) {
+	index := strings.LastIndex(token, ".")
+	if index < 0 {
+		return uuid.Nil, internal.ErrMagicLinkInvalid
+	}
+	payload, signature := token[:index], token[index+1:]
+
+	expected := base64.RawURLEncoding.EncodeToString(s.mac(payload))
+	if subtle.ConstantTimeCompare([]byte(signature), []byte(expected)) != 1 {
+		return uuid.Nil, internal.ErrMagicLinkInvalid
+	}
+
+	idStr, expiresStr, found := strings.Cut(payload, ".")
+	if !found {
+		return uuid.Nil, internal.ErrMagicLinkInvalid
+	}
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil || time.Now().Unix() >= expires {
+		return uuid.Nil, internal.ErrMagicLinkInvalid
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return uuid.Nil, internal.ErrMagicLinkInvalid
+	}
+	return id, nil
+}
+
+func (s *Service) mac(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("magic-link:" + payload))
+	return mac.Sum(nil)
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate device nonce: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashNonce(nonce string) []byte {
+	sum := sha256.Sum256([]byte(nonce))
+	return sum[:]
+}
@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Config is the SMTP server mail is sent through; sending mail is disabled when Host
+// is empty
+type Config struct {
+	Host     string `yaml:"host"     envconfig:"MAIL_HOST"`
+	Port     string `yaml:"port"     envconfig:"MAIL_PORT"`
+	Username string `yaml:"username" envconfig:"MAIL_USERNAME"`
+	Password string `yaml:"password" envconfig:"MAIL_PASSWORD"`
+	From     string `yaml:"from"     envconfig:"MAIL_FROM"`
+}
+
+func (c *Config) Validate() error {
+	if c.Host == "" {
+		return nil
+	}
+	if c.From == "" {
+		return fmt.Errorf("mail from is required when mail host is set")
+	}
+	if c.Port == "" {
+		c.Port = "587"
+	}
+	return nil
+}
+
+// Message is a plain text mail to a single address
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers mail to the users, such as their sign in links
+type Sender interface {
+	SendMail(ctx context.Context, message Message) error
+}
+
+// SMTP sends mail through an SMTP server, upgrading to TLS when the server offers it
+type SMTP struct {
+	address string
+	from    string
+	auth    smtp.Auth
+}
+
+func NewSMTP(config Config) *SMTP {
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+
+	return &SMTP{
+		address: net.JoinHostPort(config.Host, config.Port),
+		from:    config.From,
+		auth:    auth,
+	}
+}
+
+// SendMail implements Sender. net/smtp does not take a context, so a cancelled request
+// only stops mail not yet handed to the server.
+func (s *SMTP) SendMail(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Line breaks in the headers would let a caller add headers of its own
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return fmt.Errorf("mail headers cannot contain line breaks")
+	}
+
+	var builder strings.Builder
+	builder.WriteString("From: " + s.from + "\r\n")
+	builder.WriteString("To: " + message.To + "\r\n")
+	builder.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n")
+	builder.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	err := smtp.SendMail(s.address, s.auth, s.from, []string{message.To}, []byte(builder.String()))
+	if err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// Middleware records the requests served and observes the depth of the background
+// queues. Instruments go to the global meter provider, exported to the collector when
+// one is configured.
+type Middleware struct {
+	logger *zap.Logger
+	meter  metric.Meter
+
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func NewMiddleware(logger *zap.Logger) (*Middleware, error) {
+	meter := otel.Meter("internal/metrics")
+
+	requests, err := meter.Int64Counter("http.server.request.count",
+		metric.WithDescription("Requests served"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Time taken to serve requests"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Middleware{
+		logger:   logger,
+		meter:    meter,
+		requests: requests,
+		duration: duration,
+	}, nil
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// RecordMiddleware counts the request and its duration by route pattern, so paths with
+// IDs in them do not each get a series of their own
+func (m *Middleware) RecordMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+
+		next(recorder, r)
+
+		status := recorder.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		attributes := metric.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("http.route", r.Pattern),
+			attribute.String("http.response.status_code", strconv.Itoa(status)),
+		)
+
+		// The request may be canceled by the client; record it regardless
+		ctx := context.WithoutCancel(r.Context())
+		m.requests.Add(ctx, 1, attributes)
+		m.duration.Record(ctx, time.Since(start).Seconds(), attributes)
+	}
+}
+
+// ObserveQueue reports the depth of the named queue whenever metrics are collected
+func (m *Middleware) ObserveQueue(name string, depth func(ctx context.Context) (int64, error)) error {
+	_, err := m.meter.Int64ObservableGauge("queue.depth",
+		metric.WithDescription("Items waiting in a background queue"),
+		metric.WithUnit("{item}"),
+		metric.WithInt64Callback(func(ctx context.Context, observer metric.Int64Observer) error {
+			count, err := depth(ctx)
+			if err != nil {
+				m.logger.Warn("Failed to observe queue depth", zap.String("queue", name), zap.Error(err))
+				return nil
+			}
+			observer.Observe(count, metric.WithAttributes(attribute.String("queue", name)))
+			return nil
+		}))
+	return err
+}
@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Status(ctx context.Context) (Status, error)
+}
+
+type MigrationResponse struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+}
+
+type StatusResponse struct {
+	Version uint                `json:"version"`
+	Dirty   bool                `json:"dirty"`
+	Unknown bool                `json:"unknown"`
+	Drift   bool                `json:"drift"`
+	Applied []MigrationResponse `json:"applied"`
+	Pending []MigrationResponse `json:"pending"`
+}
+
+func toMigrationResponses(migrations []Migration) []MigrationResponse {
+	responses := make([]MigrationResponse, len(migrations))
+	for i, migration := range migrations {
+		responses[i] = MigrationResponse{Version: migration.Version, Name: migration.Name}
+	}
+	return responses
+}
+
+func ToStatusResponse(status Status) StatusResponse {
+	return StatusResponse{
+		Version: status.Version,
+		Dirty:   status.Dirty,
+		Unknown: status.Unknown,
+		Drift:   status.Drift(),
+		Applied: toMigrationResponses(status.Applied),
+		Pending: toMigrationResponses(status.Pending),
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("migration/handler"),
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "StatusHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+	if !user.IsAdmin(currentUser) {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrPermissionDenied, logger)
+		return
+	}
+
+	status, err := h.store.Status(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToStatusResponse(status))
+}
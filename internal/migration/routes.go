@@ -0,0 +1,10 @@
+package migration
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the migration status for admins
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /admin/migrations", route.Authenticated, route.PermissionAdmin, h.StatusHandler)
+}
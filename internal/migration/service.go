@@ -0,0 +1,217 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// Status compares the migrations of the source with the version recorded in the
+// database. Unknown means the database is at a version the source does not have, for
+// example after a rollback of the deployment without one of the schema.
+type Status struct {
+	Version uint
+	Dirty   bool
+	Unknown bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Drift reports whether the database differs from what the source expects
+func (s Status) Drift() bool {
+	return s.Dirty || s.Unknown || len(s.Pending) > 0
+}
+
+type Service struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	sourceURL   string
+	databaseURL string
+}
+
+func NewService(logger *zap.Logger, sourceURL string, databaseURL string) *Service {
+	return &Service{
+		logger:      logger,
+		tracer:      otel.Tracer("migration/service"),
+		sourceURL:   sourceURL,
+		databaseURL: databaseURL,
+	}
+}
+
+// Status reads the migration state without applying anything
+func (s *Service) Status(ctx context.Context) (Status, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Status")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	migrations, err := s.list()
+	if err != nil {
+		err = fmt.Errorf("failed to read migration source: %w", err)
+		span.RecordError(err)
+		return Status{}, err
+	}
+
+	m, err := migrate.New(s.sourceURL, s.databaseURL)
+	if err != nil {
+		err = fmt.Errorf("failed to open migrations: %w", err)
+		span.RecordError(err)
+		return Status{}, err
+	}
+	defer func() {
+		sourceErr, databaseErr := m.Close()
+		if sourceErr != nil || databaseErr != nil {
+			logger.Warn("Failed to close migrations", zap.NamedError("source_error", sourceErr), zap.NamedError("database_error", databaseErr))
+		}
+	}()
+
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		err = fmt.Errorf("failed to read database version: %w", err)
+		span.RecordError(err)
+		return Status{}, err
+	}
+
+	status := Status{
+		Version: version,
+		Dirty:   dirty,
+		Unknown: version != 0,
+		Applied: []Migration{},
+		Pending: []Migration{},
+	}
+	for _, migration := range migrations {
+		if migration.Version == version {
+			status.Unknown = false
+		}
+		if migration.Version <= version {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+
+	return status, nil
+}
+
+// list returns the up migrations of the source in order
+func (s *Service) list() ([]Migration, error) {
+	driver, err := source.Open(s.sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = driver.Close()
+	}()
+
+	var migrations []Migration
+	version, err := driver.First()
+	for err == nil {
+		var reader io.ReadCloser
+		var identifier string
+		reader, identifier, err = driver.ReadUp(version)
+		if err != nil {
+			return nil, err
+		}
+		_ = reader.Close()
+		migrations = append(migrations, Migration{Version: version, Name: identifier})
+
+		version, err = driver.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return migrations, nil
+}
+
+// Report renders the status for operators reading the output of the check on startup
+func Report(status Status) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "database version: %d", status.Version)
+	if status.Dirty {
+		b.WriteString(" (dirty, a migration failed halfway)")
+	}
+	if status.Unknown {
+		b.WriteString(" (not in the migration source)")
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "applied: %d, pending: %d\n", len(status.Applied), len(status.Pending))
+	for _, migration := range status.Pending {
+		fmt.Fprintf(&b, "  pending %d_%s\n", migration.Version, migration.Name)
+	}
+
+	if status.Drift() {
+		b.WriteString("drift detected, the schema does not match the migration source\n")
+	} else {
+		b.WriteString("schema is up to date\n")
+	}
+
+	return b.String()
+}
+
+// lockKey is the advisory lock replicas hold while migrating, so only one of them
+// applies migrations and the others wait for it instead of racing on the version table
+const lockKey int64 = 0x636f72652d6d6967
+
+// Up applies the pending migrations while holding the advisory lock. Replicas starting
+// together queue on the lock; the ones after the first find no change and move on.
+func (s *Service) Up(ctx context.Context) error {
+	traceCtx, span := s.tracer.Start(ctx, "Up")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	conn, err := pgx.Connect(traceCtx, s.databaseURL)
+	if err != nil {
+		err = fmt.Errorf("failed to connect for the migration lock: %w", err)
+		span.RecordError(err)
+		return err
+	}
+	defer func() {
+		err := conn.Close(context.Background())
+		if err != nil {
+			logger.Warn("Failed to close migration lock connection", zap.Error(err))
+		}
+	}()
+
+	logger.Info("Waiting for the migration lock")
+	_, err = conn.Exec(traceCtx, "SELECT pg_advisory_lock($1)", lockKey)
+	if err != nil {
+		err = fmt.Errorf("failed to acquire the migration lock: %w", err)
+		span.RecordError(err)
+		return err
+	}
+	defer func() {
+		_, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+		if err != nil {
+			logger.Warn("Failed to release the migration lock", zap.Error(err))
+		}
+	}()
+
+	err = databaseutil.MigrationUp(s.sourceURL, s.databaseURL, logger)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package oidc
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+)
+
+const (
+	// DeviceCodeLifetime is how long a user has to enter the code shown on the device
+	DeviceCodeLifetime = 10 * time.Minute
+
+	// DevicePollInterval is the minimum time between two token requests of a device
+	DevicePollInterval = 5 * time.Second
+
+	// userCodeAlphabet has no vowels or look-alike characters, see RFC 8628 section 6.1
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+)
+
+// DeviceCode is handed to the device, which shows UserCode and polls with DeviceCode
+type DeviceCode struct {
+	DeviceCode string
+	UserCode   string
+	ExpiresAt  time.Time
+	Interval   time.Duration
+}
+
+func newUserCode() (string, error) {
+	var code strings.Builder
+	for range userCodeLength {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(userCodeAlphabet[n.Int64()])
+	}
+	return code.String(), nil
+}
+
+// FormatUserCode splits the user code in two halves, e.g. "BCDF-GHJK", for display
+func FormatUserCode(code string) string {
+	if len(code) != userCodeLength {
+		return code
+	}
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+// normalizeUserCode accepts the code as typed, in any case and with or without the dash
+func normalizeUserCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// StartDeviceAuthorization begins the device authorization grant (RFC 8628) for a client
+// allowed to use it
+func (s *Service) StartDeviceAuthorization(ctx context.Context, clientID string, scope string) (DeviceCode, error) {
+	traceCtx, span := s.tracer.Start(ctx, "StartDeviceAuthorization")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	client, ok := s.client(clientID)
+	if !ok {
+		err := internal.ErrOIDCUnknownClient
+		span.RecordError(err)
+		return DeviceCode{}, err
+	}
+	if !client.DeviceFlow {
+		err := internal.ErrOIDCDeviceFlowNotAllowed
+		span.RecordError(err)
+		return DeviceCode{}, err
+	}
+
+	deviceCode, err := randomCode(32)
+	if err != nil {
+		span.RecordError(err)
+		return DeviceCode{}, err
+	}
+	expiresAt := time.Now().Add(DeviceCodeLifetime)
+
+	// User codes are short, so retry the rare collision with a pending authorization
+	for attempt := 0; ; attempt++ {
+		userCode, err := newUserCode()
+		if err != nil {
+			span.RecordError(err)
+			return DeviceCode{}, err
+		}
+
+		err = s.queries.CreateDeviceAuthorization(traceCtx, CreateDeviceAuthorizationParams{
+			DeviceCodeHash: hashCode(deviceCode),
+			UserCode:       userCode,
+			ClientID:       client.ID,
+			Scope:          scope,
+			ExpiresAt:      pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "device_authorizations", "client_id", client.ID, logger, "create device authorization")
+			if errors.Is(err, databaseutil.ErrUniqueViolation) && attempt < 3 {
+				continue
+			}
+			span.RecordError(err)
+			return DeviceCode{}, err
+		}
+
+		logger.Info("Started device authorization", zap.String("client_id", client.ID))
+
+		return DeviceCode{
+			DeviceCode: deviceCode,
+			UserCode:   userCode,
+			ExpiresAt:  expiresAt,
+			Interval:   DevicePollInterval,
+		}, nil
+	}
+}
+
+// GetPendingDeviceAuthorization looks up the authorization a user is about to approve
+func (s *Service) GetPendingDeviceAuthorization(ctx context.Context, userCode string) (DeviceAuthorization, Client, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetPendingDeviceAuthorization")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	authorization, err := s.queries.GetPendingDeviceAuthorization(traceCtx, normalizeUserCode(userCode))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrDeviceUserCodeNotFound
+		} else {
+			err = databaseutil.WrapDBError(err, logger, "get pending device authorization")
+		}
+		span.RecordError(err)
+		return DeviceAuthorization{}, Client{}, err
+	}
+
+	client, _ := s.client(authorization.ClientID)
+
+	return authorization, client, nil
+}
+
+// DecideDeviceAuthorization records whether the signed-in user approves the device
+func (s *Service) DecideDeviceAuthorization(ctx context.Context, userCode string, userID uuid.UUID, approve bool) (DeviceAuthorization, error) {
+	traceCtx, span := s.tracer.Start(ctx, "DecideDeviceAuthorization")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	status := DeviceAuthorizationStatusDenied
+	if approve {
+		status = DeviceAuthorizationStatusApproved
+	}
+
+	authorization, err := s.queries.DecideDeviceAuthorization(traceCtx, DecideDeviceAuthorizationParams{
+		Status:   status,
+		UserID:   pgtype.UUID{Bytes: userID, Valid: true},
+		UserCode: normalizeUserCode(userCode),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrDeviceUserCodeNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "device_authorizations", "user_id", userID.String(), logger, "decide device authorization")
+		}
+		span.RecordError(err)
+		return DeviceAuthorization{}, err
+	}
+
+	logger.Info("Decided device authorization",
+		zap.String("client_id", authorization.ClientID),
+		zap.String("user_id", userID.String()),
+		zap.String("status", string(status)))
+
+	return authorization, nil
+}
+
+// PollDeviceAuthorization answers a token request of the device. It returns the
+// authorization once approved and removes it, so the grant can be redeemed only once.
+func (s *Service) PollDeviceAuthorization(ctx context.Context, clientID string, deviceCode string) (DeviceAuthorization, error) {
+	traceCtx, span := s.tracer.Start(ctx, "PollDeviceAuthorization")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	codeHash := hashCode(deviceCode)
+	authorization, err := s.queries.GetDeviceAuthorization(traceCtx, codeHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrOIDCInvalidGrant
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "device_authorizations", "client_id", clientID, logger, "get device authorization")
+		}
+		span.RecordError(err)
+		return DeviceAuthorization{}, err
+	}
+
+	if authorization.ClientID != clientID {
+		err = internal.ErrOIDCInvalidGrant
+		span.RecordError(err)
+		return DeviceAuthorization{}, err
+	}
+
+	err = s.pollResult(traceCtx, authorization)
+	if err != nil {
+		if !errors.Is(err, internal.ErrDeviceAuthorizationPending) && !errors.Is(err, internal.ErrDeviceSlowDown) {
+			deleteErr := s.queries.DeleteDeviceAuthorization(traceCtx, codeHash)
+			if deleteErr != nil {
+				logger.Warn("Failed to delete finished device authorization", zap.Error(deleteErr))
+			}
+		}
+		span.RecordError(err)
+		return DeviceAuthorization{}, err
+	}
+
+	err = s.queries.DeleteDeviceAuthorization(traceCtx, codeHash)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "device_authorizations", "client_id", clientID, logger, "delete device authorization")
+		span.RecordError(err)
+		return DeviceAuthorization{}, err
+	}
+
+	return authorization, nil
+}
+
+// pollResult maps the state of the authorization to the RFC 8628 token errors and
+// records the poll for the slow_down check
+func (s *Service) pollResult(ctx context.Context, authorization DeviceAuthorization) error {
+	if time.Now().After(authorization.ExpiresAt.Time) {
+		return internal.ErrDeviceCodeExpired
+	}
+
+	switch authorization.Status {
+	case DeviceAuthorizationStatusApproved:
+		return nil
+	case DeviceAuthorizationStatusDenied:
+		return internal.ErrDeviceAccessDenied
+	}
+
+	err := s.queries.TouchDeviceAuthorization(ctx, authorization.DeviceCodeHash)
+	if err != nil {
+		return databaseutil.WrapDBError(err, s.logger, "touch device authorization")
+	}
+
+	if authorization.LastPolledAt.Valid && time.Since(authorization.LastPolledAt.Time) < DevicePollInterval {
+		return internal.ErrDeviceSlowDown
+	}
+	return internal.ErrDeviceAuthorizationPending
+}
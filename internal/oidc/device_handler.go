@@ -0,0 +1,180 @@
+package oidc
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"net/http"
+	"net/url"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"go.uber.org/zap"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceTokenResponse carries a kiosk token, which only the check-in routes take. There
+// is no refresh token: the kiosk is paired again once the token expired.
+type DeviceTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+type PendingDeviceResponse struct {
+	UserCode   string    `json:"userCode"`
+	ClientID   string    `json:"clientId"`
+	ClientName string    `json:"clientName"`
+	Scope      string    `json:"scope"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+func ToPendingDeviceResponse(authorization DeviceAuthorization, client Client) PendingDeviceResponse {
+	return PendingDeviceResponse{
+		UserCode:   FormatUserCode(authorization.UserCode),
+		ClientID:   authorization.ClientID,
+		ClientName: client.Name,
+		Scope:      authorization.Scope,
+		ExpiresAt:  authorization.ExpiresAt.Time,
+	}
+}
+
+// DeviceCodeHandler starts the device flow: the device shows the user code and polls the
+// token endpoint while the user approves it on another screen
+func (h *Handler) DeviceCodeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeviceCodeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	err := r.ParseForm()
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrOIDCUnknownClient, logger)
+		return
+	}
+
+	deviceCode, err := h.store.StartDeviceAuthorization(traceCtx, r.PostForm.Get("client_id"), r.PostForm.Get("scope"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	verificationURI := h.baseURL + "/device"
+	userCode := FormatUserCode(deviceCode.UserCode)
+
+	w.Header().Set("Cache-Control", "no-store")
+	handlerutil.WriteJSONResponse(w, http.StatusOK, DeviceCodeResponse{
+		DeviceCode:              deviceCode.DeviceCode,
+		UserCode:                userCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(userCode),
+		ExpiresIn:               int64(time.Until(deviceCode.ExpiresAt).Seconds()),
+		Interval:                int64(deviceCode.Interval.Seconds()),
+	})
+}
+
+// DeviceTokenHandler is polled by the device until the user approved or denied it
+func (h *Handler) DeviceTokenHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeviceTokenHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	err := r.ParseForm()
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrOIDCInvalidGrant, logger)
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != deviceCodeGrantType {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrOIDCUnsupportedGrantType, logger)
+		return
+	}
+
+	authorization, err := h.store.PollDeviceAuthorization(traceCtx, r.PostForm.Get("client_id"), r.PostForm.Get("device_code"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	u, err := h.userStore.GetByID(traceCtx, authorization.UserID.Bytes)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	accessToken, expiresAt, err := h.tokenIssuer.NewKioskToken(traceCtx, user.User{
+		ID:        u.ID,
+		Name:      u.Name,
+		Username:  u.Username,
+		AvatarUrl: u.AvatarUrl,
+		Role:      u.Role,
+	})
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	logger.Info("Issued kiosk token", zap.String("client_id", authorization.ClientID), zap.String("user_id", u.ID.String()))
+
+	w.Header().Set("Cache-Control", "no-store")
+	handlerutil.WriteJSONResponse(w, http.StatusOK, DeviceTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(expiresAt).Seconds()),
+		Scope:       jwt.ScopeKiosk,
+	})
+}
+
+// GetDeviceHandler shows the signed-in user which client asks for access before they decide
+func (h *Handler) GetDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetDeviceHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	authorization, client, err := h.store.GetPendingDeviceAuthorization(traceCtx, r.PathValue("user_code"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToPendingDeviceResponse(authorization, client))
+}
+
+func (h *Handler) ApproveDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	h.decideDevice(w, r, true)
+}
+
+func (h *Handler) DenyDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	h.decideDevice(w, r, false)
+}
+
+func (h *Handler) decideDevice(w http.ResponseWriter, r *http.Request, approve bool) {
+	traceCtx, span := h.tracer.Start(r.Context(), "decideDevice")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, err := h.store.DecideDeviceAuthorization(traceCtx, r.PathValue("user_code"), currentUser.ID, approve)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
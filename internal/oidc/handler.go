@@ -0,0 +1,386 @@
+package oidc
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	ValidateRedirect(clientID string, redirectURI string) (Client, error)
+	Authenticate(clientID string, secret string) (Client, error)
+	CreateCode(ctx context.Context, input AuthorizeInput) (string, error)
+	Exchange(ctx context.Context, client Client, code string, redirectURI string, codeVerifier string) (OidcAuthorizationCode, error)
+	StartDeviceAuthorization(ctx context.Context, clientID string, scope string) (DeviceCode, error)
+	GetPendingDeviceAuthorization(ctx context.Context, userCode string) (DeviceAuthorization, Client, error)
+	DecideDeviceAuthorization(ctx context.Context, userCode string, userID uuid.UUID, approve bool) (DeviceAuthorization, error)
+	PollDeviceAuthorization(ctx context.Context, clientID string, deviceCode string) (DeviceAuthorization, error)
+}
+
+type TokenIssuer interface {
+	Parse(ctx context.Context, tokenString string) (user.User, error)
+	NewUserInfoToken(ctx context.Context, userID uuid.UUID, clientID string, grantedScope string) (string, error)
+	ParseUserInfoToken(ctx context.Context, tokenString string) (jwt.UserInfoGrant, error)
+	NewKioskToken(ctx context.Context, approver user.User) (string, time.Time, error)
+	NewIDToken(ctx context.Context, idToken jwt.IDToken, clientSecret string) (string, error)
+	SigningAlgorithm() string
+}
+
+type UserStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (user.UsersWithEmail, error)
+}
+
+type DiscoveryResponse struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	DeviceAuthorizationEndpoint       string   `json:"device_authorization_endpoint"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+// UserInfoResponse holds the claims of the scopes granted to the client, see OpenID
+// Connect Core section 5.4
+type UserInfoResponse struct {
+	Subject           string `json:"sub"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	Email             string `json:"email,omitempty"`
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	baseURL               string
+	accessTokenExpiration time.Duration
+
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tokenIssuer TokenIssuer
+	userStore   UserStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tokenIssuer TokenIssuer,
+	userStore UserStore,
+	baseURL string,
+	accessTokenExpiration time.Duration,
+) *Handler {
+	return &Handler{
+		logger:                logger,
+		tracer:                otel.Tracer("oidc/handler"),
+		baseURL:               strings.TrimSuffix(baseURL, "/"),
+		accessTokenExpiration: accessTokenExpiration,
+		problemWriter:         problemWriter,
+		store:                 store,
+		tokenIssuer:           tokenIssuer,
+		userStore:             userStore,
+	}
+}
+
+func hasScope(scope string, name string) bool {
+	return slices.Contains(strings.Fields(scope), name)
+}
+
+func primaryEmail(u user.UsersWithEmail) string {
+	emails := user.ConvertEmailsToSlice(u.Emails)
+	if len(emails) == 0 {
+		return ""
+	}
+	return emails[0]
+}
+
+// DiscoveryHandler serves /.well-known/openid-configuration
+func (h *Handler) DiscoveryHandler(w http.ResponseWriter, r *http.Request) {
+	_, span := h.tracer.Start(r.Context(), "DiscoveryHandler")
+	defer span.End()
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, DiscoveryResponse{
+		Issuer:                            h.baseURL,
+		AuthorizationEndpoint:             h.baseURL + route.V1 + "/oidc/authorize",
+		TokenEndpoint:                     h.baseURL + route.V1 + "/oidc/token",
+		UserInfoEndpoint:                  h.baseURL + route.V1 + "/oidc/userinfo",
+		JWKSURI:                           h.baseURL + "/.well-known/jwks.json",
+		DeviceAuthorizationEndpoint:       h.baseURL + route.V1 + "/auth/device/code",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", deviceCodeGrantType},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{h.tokenIssuer.SigningAlgorithm()},
+		ScopesSupported:                   []string{"openid", "profile", "email"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		ClaimsSupported:                   []string{"sub", "name", "preferred_username", "picture", "email"},
+	})
+}
+
+// redirectError reports an authorization error back to the client, see RFC 6749 section 4.1.2.1
+func redirectError(w http.ResponseWriter, r *http.Request, redirectURI string, state string, code string, description string) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, description, http.StatusBadRequest)
+		return
+	}
+
+	query := target.Query()
+	query.Set("error", code)
+	query.Set("error_description", description)
+	if state != "" {
+		query.Set("state", state)
+	}
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// currentUser reads the session cookie; the authorize endpoint redirects to the login
+// page instead of answering 401, so it cannot sit behind the auth middleware
+func (h *Handler) currentUser(ctx context.Context, r *http.Request) (user.User, bool) {
+	cookie, err := r.Cookie("access_token")
+	if err != nil || cookie.Value == "" {
+		return user.User{}, false
+	}
+
+	u, err := h.tokenIssuer.Parse(ctx, cookie.Value)
+	if err != nil {
+		return user.User{}, false
+	}
+	return u, true
+}
+
+// AuthorizeHandler runs the authorization code flow for a registered client. Users who
+// are not signed in are sent through the Google login first and come back here after.
+// Clients are registered by the operators, so there is no separate consent screen.
+func (h *Handler) AuthorizeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "AuthorizeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	query := r.URL.Query()
+	clientID := query.Get("client_id")
+	redirectURI := query.Get("redirect_uri")
+	state := query.Get("state")
+
+	// Never redirect to an unverified URI, errors before this point are shown to the user
+	_, err := h.store.ValidateRedirect(clientID, redirectURI)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	if query.Get("response_type") != "code" {
+		redirectError(w, r, redirectURI, state, "unsupported_response_type", "only the authorization code flow is supported")
+		return
+	}
+
+	scope := query.Get("scope")
+	if !hasScope(scope, "openid") {
+		redirectError(w, r, redirectURI, state, "invalid_scope", "the openid scope is required")
+		return
+	}
+
+	codeChallenge := query.Get("code_challenge")
+	method := query.Get("code_challenge_method")
+	if (codeChallenge != "" || method != "") && method != "S256" {
+		redirectError(w, r, redirectURI, state, "invalid_request", "code_challenge_method must be S256")
+		return
+	}
+
+	currentUser, ok := h.currentUser(traceCtx, r)
+	if !ok {
+		http.Redirect(w, r, route.V1+"/auth/login/oauth/google?r="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+		return
+	}
+
+	code, err := h.store.CreateCode(traceCtx, AuthorizeInput{
+		ClientID:      clientID,
+		UserID:        currentUser.ID,
+		RedirectURI:   redirectURI,
+		Scope:         scope,
+		Nonce:         query.Get("nonce"),
+		CodeChallenge: codeChallenge,
+	})
+	if err != nil {
+		redirectError(w, r, redirectURI, state, "server_error", "failed to issue an authorization code")
+		return
+	}
+
+	target, _ := url.Parse(redirectURI)
+	targetQuery := target.Query()
+	targetQuery.Set("code", code)
+	if state != "" {
+		targetQuery.Set("state", state)
+	}
+	target.RawQuery = targetQuery.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// TokenHandler exchanges an authorization code for an access token and an ID token
+func (h *Handler) TokenHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "TokenHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	err := r.ParseForm()
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrOIDCInvalidGrant, logger)
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	client, err := h.store.Authenticate(clientID, clientSecret)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oidc"`)
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrOIDCUnsupportedGrantType, logger)
+		return
+	}
+
+	authorization, err := h.store.Exchange(traceCtx, client, r.PostForm.Get("code"), r.PostForm.Get("redirect_uri"), r.PostForm.Get("code_verifier"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	u, err := h.userStore.GetByID(traceCtx, authorization.UserID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	// The access token only reads the userinfo endpoint, the client never gets a token
+	// of the API itself
+	accessToken, err := h.tokenIssuer.NewUserInfoToken(traceCtx, u.ID, client.ID, authorization.Scope)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	idToken := jwt.IDToken{
+		Issuer:   h.baseURL,
+		Audience: client.ID,
+		Subject:  u.ID.String(),
+		Nonce:    authorization.Nonce,
+	}
+	if hasScope(authorization.Scope, "profile") {
+		idToken.Name = u.Name.String
+		idToken.PreferredUsername = u.Username.String
+		idToken.Picture = u.AvatarUrl.String
+	}
+	if hasScope(authorization.Scope, "email") {
+		idToken.Email = primaryEmail(u)
+	}
+
+	signedIDToken, err := h.tokenIssuer.NewIDToken(traceCtx, idToken, client.Secret)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	logger.Info("Issued OIDC tokens", zap.String("client_id", client.ID), zap.String("user_id", u.ID.String()))
+
+	w.Header().Set("Cache-Control", "no-store")
+	handlerutil.WriteJSONResponse(w, http.StatusOK, TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.accessTokenExpiration.Seconds()),
+		IDToken:     signedIDToken,
+		Scope:       authorization.Scope,
+	})
+}
+
+// bearerToken reads the access token of a client from the Authorization header. The
+// session cookie is ignored: it is not a token of the client.
+func bearerToken(r *http.Request) (string, bool) {
+	fields := strings.Fields(r.Header.Get("Authorization"))
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+		return "", false
+	}
+	return fields[1], true
+}
+
+// UserInfoHandler returns the claims of the user owning the bearer access token, limited
+// to the scopes the user granted to the client it was issued to. Only the access tokens
+// of the token endpoint are accepted, see RFC 6750 section 3 for the errors.
+func (h *Handler) UserInfoHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UserInfoHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	tokenString, ok := bearerToken(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oidc"`)
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrMissingAuthHeader, logger)
+		return
+	}
+
+	grant, err := h.tokenIssuer.ParseUserInfoToken(traceCtx, tokenString)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oidc", error="invalid_token"`)
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrInvalidAuthUser, logger)
+		return
+	}
+
+	u, err := h.userStore.GetByID(traceCtx, grant.UserID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := UserInfoResponse{Subject: u.ID.String()}
+	if hasScope(grant.Scope, "profile") {
+		response.Name = u.Name.String
+		response.PreferredUsername = u.Username.String
+		response.Picture = u.AvatarUrl.String
+	}
+	if hasScope(grant.Scope, "email") {
+		response.Email = primaryEmail(u)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
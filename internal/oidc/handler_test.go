@@ -0,0 +1,271 @@
+package oidc_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/jwt"
+	"NYCU-SDC/core-system-backend/internal/oidc"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testClientSecret = "client-secret"
+
+var testClient = oidc.Client{ID: "club-tool", Secret: testClientSecret, Name: "Club Tool"}
+
+// fakeStore hands out one authorization for any code, granted with scope
+type fakeStore struct {
+	oidc.Store
+	userID uuid.UUID
+	scope  string
+}
+
+func (s fakeStore) Authenticate(clientID string, secret string) (oidc.Client, error) {
+	if clientID != testClient.ID || secret != testClient.Secret {
+		return oidc.Client{}, internal.ErrOIDCUnknownClient
+	}
+	return testClient, nil
+}
+
+func (s fakeStore) Exchange(context.Context, oidc.Client, string, string, string) (oidc.OidcAuthorizationCode, error) {
+	return oidc.OidcAuthorizationCode{ClientID: testClient.ID, UserID: s.userID, Scope: s.scope}, nil
+}
+
+func (s fakeStore) PollDeviceAuthorization(context.Context, string, string) (oidc.DeviceAuthorization, error) {
+	return oidc.DeviceAuthorization{
+		ClientID: testClient.ID,
+		Scope:    s.scope,
+		Status:   oidc.DeviceAuthorizationStatusApproved,
+		UserID:   pgtype.UUID{Bytes: s.userID, Valid: true},
+	}, nil
+}
+
+type fakeUserStore struct {
+	user user.UsersWithEmail
+}
+
+func (s fakeUserStore) GetByID(context.Context, uuid.UUID) (user.UsersWithEmail, error) {
+	return s.user, nil
+}
+
+func newTestUser() user.UsersWithEmail {
+	return user.UsersWithEmail{
+		ID:        uuid.New(),
+		Name:      pgtype.Text{String: "Alex Chen", Valid: true},
+		Username:  pgtype.Text{String: "alex", Valid: true},
+		AvatarUrl: pgtype.Text{String: "https://example.com/alex.png", Valid: true},
+		Role:      []string{"user"},
+		Emails:    []string{"alex@example.com"},
+	}
+}
+
+func newHandler(store oidc.Store, tokens *jwt.Service, u user.UsersWithEmail) *oidc.Handler {
+	return oidc.NewHandler(zap.NewNop(), internal.NewProblemWriter(), store, tokens, fakeUserStore{user: u}, "https://core-system.example.com", 15*time.Minute)
+}
+
+func newTokens() *jwt.Service {
+	return jwt.NewService(zap.NewNop(), nil, "test-secret", "test-secret", nil, 15*time.Minute, 24*time.Hour)
+}
+
+// exchange runs the token endpoint and returns the access token it issued
+func exchange(t *testing.T, handler *oidc.Handler) oidc.TokenResponse {
+	t.Helper()
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {"code"},
+		"redirect_uri": {"https://club-tool.example.com/auth/callback"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/oidc/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(testClient.ID, testClient.Secret)
+
+	rec := httptest.NewRecorder()
+	handler.TokenHandler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var response oidc.TokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return response
+}
+
+func userInfo(handler *oidc.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/oidc/userinfo", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.UserInfoHandler(rec, req)
+	return rec
+}
+
+func TestHandler_TokenHandlerIssuesUserInfoToken(t *testing.T) {
+	t.Parallel()
+
+	tokens := newTokens()
+	u := newTestUser()
+	handler := newHandler(fakeStore{userID: u.ID, scope: "openid profile email"}, tokens, u)
+
+	response := exchange(t, handler)
+	require.Equal(t, "Bearer", response.TokenType)
+	require.NotEmpty(t, response.IDToken)
+
+	// Not a token of the API: the auth middleware refuses it
+	_, err := tokens.Parse(context.Background(), response.AccessToken)
+	require.ErrorIs(t, err, internal.ErrRestrictedToken)
+
+	grant, err := tokens.ParseUserInfoToken(context.Background(), response.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, jwt.UserInfoGrant{UserID: u.ID, ClientID: testClient.ID, Scope: "openid profile email"}, grant)
+}
+
+func TestHandler_UserInfoHandler(t *testing.T) {
+	t.Parallel()
+
+	u := newTestUser()
+
+	type testCase struct {
+		name         string
+		scope        string
+		expectedInfo oidc.UserInfoResponse
+	}
+
+	testCases := []testCase{
+		{
+			name:         "Openid only",
+			scope:        "openid",
+			expectedInfo: oidc.UserInfoResponse{Subject: u.ID.String()},
+		},
+		{
+			name:         "Email scope",
+			scope:        "openid email",
+			expectedInfo: oidc.UserInfoResponse{Subject: u.ID.String(), Email: "alex@example.com"},
+		},
+		{
+			name:  "Profile scope",
+			scope: "openid profile",
+			expectedInfo: oidc.UserInfoResponse{
+				Subject:           u.ID.String(),
+				Name:              "Alex Chen",
+				PreferredUsername: "alex",
+				Picture:           "https://example.com/alex.png",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newHandler(fakeStore{userID: u.ID, scope: tc.scope}, newTokens(), u)
+			token := exchange(t, handler).AccessToken
+
+			rec := userInfo(handler, token)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var info oidc.UserInfoResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+			require.Equal(t, tc.expectedInfo, info)
+		})
+	}
+}
+
+func TestHandler_UserInfoHandlerRefusesOtherTokens(t *testing.T) {
+	t.Parallel()
+
+	tokens := newTokens()
+	u := newTestUser()
+	handler := newHandler(fakeStore{userID: u.ID, scope: "openid email"}, tokens, u)
+
+	fullToken, err := tokens.New(context.Background(), user.User{ID: u.ID, Role: u.Role})
+	require.NoError(t, err)
+	respondentToken, _, err := tokens.NewRespondentToken(context.Background(), u.ID, uuid.New())
+	require.NoError(t, err)
+	kioskToken, _, err := tokens.NewKioskToken(context.Background(), user.User{ID: u.ID, Role: u.Role})
+	require.NoError(t, err)
+
+	type testCase struct {
+		name  string
+		token string
+	}
+
+	testCases := []testCase{
+		{name: "No token", token: ""},
+		{name: "Full access token", token: fullToken},
+		{name: "Respondent token", token: respondentToken},
+		{name: "Kiosk token", token: kioskToken},
+		{name: "ID token", token: exchange(t, handler).IDToken},
+		{name: "Garbage", token: "not-a-token"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := userInfo(handler, tc.token)
+			require.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+			require.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestHandler_UserInfoHandlerIgnoresSessionCookie(t *testing.T) {
+	t.Parallel()
+
+	tokens := newTokens()
+	u := newTestUser()
+	handler := newHandler(fakeStore{userID: u.ID, scope: "openid email"}, tokens, u)
+
+	fullToken, err := tokens.New(context.Background(), user.User{ID: u.ID, Role: u.Role})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/oidc/userinfo", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: fullToken})
+	rec := httptest.NewRecorder()
+	handler.UserInfoHandler(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestHandler_DeviceTokenHandlerIssuesKioskToken(t *testing.T) {
+	t.Parallel()
+
+	tokens := newTokens()
+	u := newTestUser()
+	handler := newHandler(fakeStore{userID: u.ID, scope: "openid"}, tokens, u)
+
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"client_id":   {testClient.ID},
+		"device_code": {"device-code"},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/device/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.DeviceTokenHandler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.NotContains(t, body, "refresh_token")
+	require.Equal(t, jwt.ScopeKiosk, body["scope"])
+	require.InDelta(t, jwt.KioskTokenExpiration.Seconds(), body["expires_in"], 5)
+
+	accessToken, _ := body["access_token"].(string)
+	_, err := tokens.Parse(context.Background(), accessToken)
+	require.ErrorIs(t, err, internal.ErrRestrictedToken)
+
+	kiosk, err := tokens.ParseKioskToken(context.Background(), accessToken)
+	require.NoError(t, err)
+	require.Equal(t, u.ID, kiosk.ID)
+}
@@ -0,0 +1,43 @@
+-- name: CreateCode :exec
+INSERT INTO oidc_authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, expires_at)
+VALUES (@code_hash, @client_id, @user_id, @redirect_uri, @scope, @nonce, @code_challenge, @expires_at);
+
+-- name: ConsumeCode :one
+DELETE FROM oidc_authorization_codes
+WHERE code_hash = @code_hash AND expires_at > now()
+RETURNING *;
+
+-- name: DeleteExpiredCodes :execrows
+DELETE FROM oidc_authorization_codes
+WHERE expires_at <= now();
+
+-- name: CreateDeviceAuthorization :exec
+INSERT INTO device_authorizations (device_code_hash, user_code, client_id, scope, expires_at)
+VALUES (@device_code_hash, @user_code, @client_id, @scope, @expires_at);
+
+-- name: GetPendingDeviceAuthorization :one
+SELECT * FROM device_authorizations
+WHERE user_code = @user_code AND status = 'pending' AND expires_at > now();
+
+-- name: DecideDeviceAuthorization :one
+UPDATE device_authorizations
+SET status = @status, user_id = @user_id
+WHERE user_code = @user_code AND status = 'pending' AND expires_at > now()
+RETURNING *;
+
+-- name: GetDeviceAuthorization :one
+SELECT * FROM device_authorizations
+WHERE device_code_hash = @device_code_hash;
+
+-- name: TouchDeviceAuthorization :exec
+UPDATE device_authorizations
+SET last_polled_at = now()
+WHERE device_code_hash = @device_code_hash;
+
+-- name: DeleteDeviceAuthorization :exec
+DELETE FROM device_authorizations
+WHERE device_code_hash = @device_code_hash;
+
+-- name: DeleteExpiredDeviceAuthorizations :execrows
+DELETE FROM device_authorizations
+WHERE expires_at <= now();
\ No newline at end of file
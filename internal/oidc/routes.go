@@ -0,0 +1,25 @@
+package oidc
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the OpenID Connect provider and device authorization endpoints
+func Routes(r route.Router, h *Handler, limits route.BodyLimits) {
+	r.Handle("GET /oidc/authorize", route.Public, route.PermissionNone, h.AuthorizeHandler)
+	r.Handle("POST /oidc/token", route.Public, route.PermissionClient, h.TokenHandler).WithBodyLimit(limits.Auth)
+	// Authenticated by the userinfo token itself, which the auth middleware refuses
+	r.Handle("GET /oidc/userinfo", route.Public, route.PermissionSelf, h.UserInfoHandler)
+
+	// Device authorization flow for check-in kiosks (RFC 8628)
+	r.Handle("POST /auth/device/code", route.Public, route.PermissionClient, h.DeviceCodeHandler).WithBodyLimit(limits.Auth)
+	r.Handle("POST /auth/device/token", route.Public, route.PermissionClient, h.DeviceTokenHandler).WithBodyLimit(limits.Auth)
+	r.Handle("GET /auth/device/{user_code}", route.Authenticated, route.PermissionNone, h.GetDeviceHandler)
+	r.Handle("POST /auth/device/{user_code}/approve", route.Authenticated, route.PermissionNone, h.ApproveDeviceHandler)
+	r.Handle("POST /auth/device/{user_code}/deny", route.Authenticated, route.PermissionNone, h.DenyDeviceHandler)
+}
+
+// WellKnownRoutes declares the discovery document, at the root where clients look it up
+func WellKnownRoutes(r route.Router, h *Handler) {
+	r.Handle("GET /.well-known/openid-configuration", route.Public, route.PermissionNone, h.DiscoveryHandler)
+}
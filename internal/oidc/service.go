@@ -0,0 +1,256 @@
+package oidc
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// CodeLifetime is how long an authorization code can be exchanged for tokens
+	CodeLifetime = time.Minute
+
+	DefaultCleanupInterval = 10 * time.Minute
+)
+
+// Client is a sub-application registered to sign in with this backend
+type Client struct {
+	ID           string   `yaml:"client_id"`
+	Secret       string   `yaml:"client_secret"`
+	Name         string   `yaml:"name"`
+	RedirectURIs []string `yaml:"redirect_uris"`
+	// DeviceFlow lets the check-in kiosks of this client pair through the device
+	// authorization grant, for a token limited to the check-in routes
+	DeviceFlow bool `yaml:"device_flow"`
+}
+
+type Config struct {
+	Clients []Client `yaml:"clients"`
+}
+
+func (c *Config) Validate() error {
+	for _, client := range c.Clients {
+		if client.ID == "" || client.Secret == "" {
+			return fmt.Errorf("oidc clients need both client_id and client_secret")
+		}
+		if len(client.RedirectURIs) == 0 && !client.DeviceFlow {
+			return fmt.Errorf("oidc client %s needs at least one redirect_uri", client.ID)
+		}
+	}
+	return nil
+}
+
+type Querier interface {
+	CreateCode(ctx context.Context, arg CreateCodeParams) error
+	ConsumeCode(ctx context.Context, codeHash string) (OidcAuthorizationCode, error)
+	DeleteExpiredCodes(ctx context.Context) (int64, error)
+	CreateDeviceAuthorization(ctx context.Context, arg CreateDeviceAuthorizationParams) error
+	GetPendingDeviceAuthorization(ctx context.Context, userCode string) (DeviceAuthorization, error)
+	DecideDeviceAuthorization(ctx context.Context, arg DecideDeviceAuthorizationParams) (DeviceAuthorization, error)
+	GetDeviceAuthorization(ctx context.Context, deviceCodeHash string) (DeviceAuthorization, error)
+	TouchDeviceAuthorization(ctx context.Context, deviceCodeHash string) error
+	DeleteDeviceAuthorization(ctx context.Context, deviceCodeHash string) error
+	DeleteExpiredDeviceAuthorizations(ctx context.Context) (int64, error)
+}
+
+// AuthorizeInput is an authorization request already granted by the signed-in user
+type AuthorizeInput struct {
+	ClientID      string
+	UserID        uuid.UUID
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+	clients []Client
+}
+
+func NewService(logger *zap.Logger, db DBTX, clients []Client) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("oidc/service"),
+		clients: clients,
+	}
+}
+
+// randomCode returns a URL-safe random code of n bytes of entropy
+func randomCode(n int) (string, error) {
+	raw := make([]byte, n)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Service) client(clientID string) (Client, bool) {
+	for _, client := range s.clients {
+		if client.ID == clientID {
+			return client, true
+		}
+	}
+	return Client{}, false
+}
+
+// ValidateRedirect checks the redirect URI is one registered for the client, exactly
+func (s *Service) ValidateRedirect(clientID string, redirectURI string) (Client, error) {
+	client, ok := s.client(clientID)
+	if !ok {
+		return Client{}, internal.ErrOIDCUnknownClient
+	}
+	if !slices.Contains(client.RedirectURIs, redirectURI) {
+		return Client{}, internal.ErrOIDCInvalidRedirectURI
+	}
+	return client, nil
+}
+
+func (s *Service) Authenticate(clientID string, secret string) (Client, error) {
+	client, ok := s.client(clientID)
+	if !ok || subtle.ConstantTimeCompare([]byte(client.Secret), []byte(secret)) != 1 {
+		return Client{}, internal.ErrInvalidClient
+	}
+	return client, nil
+}
+
+// CreateCode issues a single-use authorization code, only its hash is stored
+func (s *Service) CreateCode(ctx context.Context, input AuthorizeInput) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "CreateCode")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	code, err := randomCode(32)
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	err = s.queries.CreateCode(traceCtx, CreateCodeParams{
+		CodeHash:      hashCode(code),
+		ClientID:      input.ClientID,
+		UserID:        input.UserID,
+		RedirectUri:   input.RedirectURI,
+		Scope:         input.Scope,
+		Nonce:         input.Nonce,
+		CodeChallenge: input.CodeChallenge,
+		ExpiresAt:     pgtype.Timestamptz{Time: time.Now().Add(CodeLifetime), Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "oidc_authorization_codes", "client_id", input.ClientID, logger, "create authorization code")
+		span.RecordError(err)
+		return "", err
+	}
+
+	logger.Info("Issued OIDC authorization code", zap.String("client_id", input.ClientID), zap.String("user_id", input.UserID.String()))
+
+	return code, nil
+}
+
+// Exchange redeems an authorization code. The code is consumed before it is checked,
+// so a code sent with the wrong client or redirect URI cannot be retried either.
+func (s *Service) Exchange(ctx context.Context, client Client, code string, redirectURI string, codeVerifier string) (OidcAuthorizationCode, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Exchange")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	authorization, err := s.queries.ConsumeCode(traceCtx, hashCode(code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrOIDCInvalidGrant
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "oidc_authorization_codes", "client_id", client.ID, logger, "consume authorization code")
+		}
+		span.RecordError(err)
+		return OidcAuthorizationCode{}, err
+	}
+
+	if authorization.ClientID != client.ID || authorization.RedirectUri != redirectURI {
+		err = internal.ErrOIDCInvalidGrant
+		span.RecordError(err)
+		return OidcAuthorizationCode{}, err
+	}
+
+	// PKCE with S256 (RFC 7636), required only when the client sent a challenge
+	if authorization.CodeChallenge != "" {
+		sum := sha256.Sum256([]byte(codeVerifier))
+		challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+		if subtle.ConstantTimeCompare([]byte(challenge), []byte(authorization.CodeChallenge)) != 1 {
+			err = internal.ErrOIDCInvalidCodeVerifier
+			span.RecordError(err)
+			return OidcAuthorizationCode{}, err
+		}
+	}
+
+	return authorization, nil
+}
+
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.DeleteExpiredCodes(ctx)
+			if err != nil {
+				s.logger.Error("Failed to delete expired authorization codes", zap.Error(err))
+			}
+		}
+	}
+}
+
+// DeleteExpiredCodes removes expired authorization codes and device authorizations
+func (s *Service) DeleteExpiredCodes(ctx context.Context) error {
+	traceCtx, span := s.tracer.Start(ctx, "DeleteExpiredCodes")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	rows, err := s.queries.DeleteExpiredCodes(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "delete expired authorization codes")
+		span.RecordError(err)
+		return err
+	}
+
+	deviceRows, err := s.queries.DeleteExpiredDeviceAuthorizations(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "delete expired device authorizations")
+		span.RecordError(err)
+		return err
+	}
+
+	if rows+deviceRows > 0 {
+		logger.Debug("Deleted expired authorization codes", zap.Int64("count", rows), zap.Int64("device_count", deviceRows))
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package profile
+
+import (
+	"NYCU-SDC/core-system-backend/internal/user"
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func formatTime(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+// buildCSV writes a row for each member, the profile fields after the member columns
+// in the order the fields are shown
+func buildCSV(fields []ProfileField, members []ListMembersRow, values []ProfileValue) ([]byte, error) {
+	byMember := make(map[uuid.UUID]map[uuid.UUID]string)
+	for _, value := range values {
+		if byMember[value.UserID] == nil {
+			byMember[value.UserID] = make(map[uuid.UUID]string)
+		}
+		byMember[value.UserID][value.FieldID] = value.Value
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"member_id", "name", "username", "emails", "valid_until"}
+	for _, field := range fields {
+		header = append(header, field.Key)
+	}
+	err := writer.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		row := []string{
+			member.ID.String(),
+			member.Name.String,
+			member.Username.String,
+			strings.Join(user.ConvertEmailsToSlice(member.Emails), ";"),
+			formatTime(member.ValidUntil),
+		}
+		for _, field := range fields {
+			row = append(row, byMember[member.ID][field.ID])
+		}
+
+		err = writer.Write(row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	err = writer.Error()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
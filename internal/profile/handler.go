@@ -0,0 +1,577 @@
+package profile
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	ListFields(ctx context.Context, orgID uuid.UUID) ([]ProfileField, error)
+	CreateField(ctx context.Context, orgID uuid.UUID, input FieldInput, userID uuid.UUID) (ProfileField, error)
+	UpdateField(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input FieldInput, userID uuid.UUID) (ProfileField, error)
+	DeleteField(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error
+	GetOwn(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) (Profile, error)
+	SetOwn(ctx context.Context, orgID uuid.UUID, values map[string]string, userID uuid.UUID) (Profile, error)
+	GetMember(ctx context.Context, orgID uuid.UUID, memberID uuid.UUID, userID uuid.UUID) (Profile, error)
+	SetMember(ctx context.Context, orgID uuid.UUID, memberID uuid.UUID, values map[string]string, userID uuid.UUID) (Profile, error)
+	ExportMembers(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]byte, error)
+	ListPrefills(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]ProfilePrefill, error)
+	SetPrefills(ctx context.Context, formID uuid.UUID, prefills map[uuid.UUID]uuid.UUID, userID uuid.UUID) ([]ProfilePrefill, error)
+	Prefill(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]ListPrefillValuesRow, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+type FieldRequest struct {
+	Key      string   `json:"key" validate:"required,max=50"`
+	Label    string   `json:"label" validate:"required,max=100"`
+	Type     string   `json:"type" validate:"required,oneof=text number select"`
+	Options  []string `json:"options" validate:"max=100,dive,max=100"`
+	Required bool     `json:"required"`
+	Position int32    `json:"position" validate:"min=0"`
+}
+
+type ProfileRequest struct {
+	Values map[string]string `json:"values" validate:"max=100"`
+}
+
+type PrefillsRequest struct {
+	Prefills map[string]string `json:"prefills" validate:"max=500,dive,keys,uuid,endkeys,uuid"`
+}
+
+type FieldResponse struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"key"`
+	Label     string    `json:"label"`
+	Type      string    `json:"type"`
+	Options   []string  `json:"options"`
+	Required  bool      `json:"required"`
+	Position  int32     `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ProfileResponse struct {
+	Values   map[string]string `json:"values"`
+	Missing  []string          `json:"missing"`
+	Complete bool              `json:"complete"`
+}
+
+type PrefillResponse struct {
+	QuestionID string `json:"questionId"`
+	FieldID    string `json:"fieldId"`
+}
+
+type PrefillValueResponse struct {
+	QuestionID string `json:"questionId"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+}
+
+func ToFieldResponse(field ProfileField) FieldResponse {
+	options := field.Options
+	if options == nil {
+		options = []string{}
+	}
+
+	return FieldResponse{
+		ID:        field.ID.String(),
+		Key:       field.Key,
+		Label:     field.Label,
+		Type:      string(field.Type),
+		Options:   options,
+		Required:  field.Required,
+		Position:  field.Position,
+		CreatedAt: field.CreatedAt.Time,
+		UpdatedAt: field.UpdatedAt.Time,
+	}
+}
+
+func ToProfileResponse(profile Profile) ProfileResponse {
+	return ProfileResponse{
+		Values:   profile.Values,
+		Missing:  profile.Missing,
+		Complete: len(profile.Missing) == 0,
+	}
+}
+
+func ToPrefillResponses(prefills []ProfilePrefill) []PrefillResponse {
+	response := make([]PrefillResponse, len(prefills))
+	for i, prefill := range prefills {
+		response[i] = PrefillResponse{
+			QuestionID: prefill.QuestionID.String(),
+			FieldID:    prefill.FieldID.String(),
+		}
+	}
+	return response
+}
+
+func (r FieldRequest) ToInput() FieldInput {
+	return FieldInput{
+		Key:      r.Key,
+		Label:    r.Label,
+		Type:     ProfileFieldType(r.Type),
+		Options:  r.Options,
+		Required: r.Required,
+		Position: r.Position,
+	}
+}
+
+// ToPrefills converts the request; the IDs are expected to have passed validation
+func (r PrefillsRequest) ToPrefills() map[uuid.UUID]uuid.UUID {
+	prefills := make(map[uuid.UUID]uuid.UUID, len(r.Prefills))
+	for questionID, fieldID := range r.Prefills {
+		prefills[uuid.MustParse(questionID)] = uuid.MustParse(fieldID)
+	}
+	return prefills
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("profile/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// org resolves the slug and the organization it names
+func (h *Handler) org(ctx context.Context) (string, uuid.UUID, error) {
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return slug, orgID, nil
+}
+
+func (h *Handler) ListFieldsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListFieldsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	fields, err := h.store.ListFields(traceCtx, orgID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]FieldResponse, len(fields))
+	for i, field := range fields {
+		response[i] = ToFieldResponse(field)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) CreateFieldHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateFieldHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req FieldRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	field, err := h.store.CreateField(traceCtx, orgID, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToFieldResponse(field))
+}
+
+// UpdateFieldHandler changes a profile field; the key in the request is ignored as
+// fields keep the key they were created with
+func (h *Handler) UpdateFieldHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateFieldHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("fieldId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req FieldRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	field, err := h.store.UpdateField(traceCtx, orgID, id, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToFieldResponse(field))
+}
+
+func (h *Handler) DeleteFieldHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteFieldHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("fieldId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.DeleteField(traceCtx, orgID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) GetOwnHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetOwnHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	profile, err := h.store.GetOwn(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToProfileResponse(profile))
+}
+
+// SetOwnHandler saves the given values in the profile of the current user, an empty
+// value clearing its field and the fields not given staying as they are
+func (h *Handler) SetOwnHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetOwnHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ProfileRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	profile, err := h.store.SetOwn(traceCtx, orgID, req.Values, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToProfileResponse(profile))
+}
+
+func (h *Handler) GetMemberHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetMemberHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	memberID, err := internal.ParseUUID(r.PathValue("member_id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	profile, err := h.store.GetMember(traceCtx, orgID, memberID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToProfileResponse(profile))
+}
+
+func (h *Handler) SetMemberHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetMemberHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	_, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	memberID, err := internal.ParseUUID(r.PathValue("member_id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req ProfileRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	profile, err := h.store.SetMember(traceCtx, orgID, memberID, req.Values, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToProfileResponse(profile))
+}
+
+// ExportHandler serves the members of the organization and their profiles as a CSV file
+func (h *Handler) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ExportHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	slug, orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	content, err := h.store.ExportMembers(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "members-"+slug+".csv"))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(content)
+	if err != nil {
+		logger.Error("failed to write member export", zap.Error(err))
+	}
+}
+
+func (h *Handler) ListPrefillsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListPrefillsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	prefills, err := h.store.ListPrefills(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToPrefillResponses(prefills))
+}
+
+func (h *Handler) SetPrefillsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetPrefillsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req PrefillsRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	prefills, err := h.store.SetPrefills(traceCtx, formID, req.ToPrefills(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToPrefillResponses(prefills))
+}
+
+// PrefillHandler returns the answers the current user's profile suggests for the
+// questions of the form
+func (h *Handler) PrefillHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "PrefillHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	formID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	values, err := h.store.Prefill(traceCtx, formID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]PrefillValueResponse, len(values))
+	for i, value := range values {
+		response[i] = PrefillValueResponse{
+			QuestionID: value.QuestionID.String(),
+			Key:        value.Key,
+			Value:      value.Value,
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,93 @@
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: IsOrgMember :one
+SELECT EXISTS(SELECT 1 FROM unit_members WHERE unit_id = @org_id AND member_id = @user_id);
+
+-- name: GetFormOrg :one
+SELECT COALESCE(u.org_id, u.id)::uuid AS org_id
+FROM forms f
+JOIN units u ON u.id = f.unit_id
+WHERE f.id = @form_id;
+
+-- name: CreateField :one
+INSERT INTO profile_fields (org_id, key, label, type, options, required, position)
+VALUES (@org_id, @key, @label, @type, @options, @required, @position)
+RETURNING *;
+
+-- name: ListFields :many
+SELECT * FROM profile_fields
+WHERE org_id = @org_id
+ORDER BY position ASC, created_at ASC;
+
+-- name: UpdateField :one
+UPDATE profile_fields
+SET label = @label, type = @type, options = @options, required = @required, position = @position, updated_at = now()
+WHERE id = @id AND org_id = @org_id
+RETURNING *;
+
+-- name: DeleteField :execrows
+DELETE FROM profile_fields
+WHERE id = @id AND org_id = @org_id;
+
+-- name: ListValues :many
+SELECT v.* FROM profile_values v
+JOIN profile_fields f ON f.id = v.field_id
+WHERE f.org_id = @org_id AND v.user_id = @user_id;
+
+-- name: ListOrgValues :many
+SELECT v.* FROM profile_values v
+JOIN profile_fields f ON f.id = v.field_id
+WHERE f.org_id = @org_id;
+
+-- name: UpsertValue :exec
+INSERT INTO profile_values (field_id, user_id, value)
+VALUES (@field_id, @user_id, @value)
+ON CONFLICT (field_id, user_id) DO UPDATE
+    SET value = EXCLUDED.value, updated_at = now();
+
+-- name: DeleteValue :exec
+DELETE FROM profile_values
+WHERE field_id = @field_id AND user_id = @user_id;
+
+-- name: ListMembers :many
+SELECT u.id, u.name, u.username, u.emails, m.valid_until
+FROM unit_members m
+JOIN users_with_emails u ON u.id = m.member_id
+WHERE m.unit_id = @org_id
+ORDER BY u.name ASC, u.id ASC;
+
+-- name: IsQuestionInForm :one
+SELECT EXISTS(
+    SELECT 1 FROM questions q
+    JOIN sections s ON s.id = q.section_id
+    WHERE q.id = @question_id AND s.form_id = @form_id
+);
+
+-- name: ListPrefills :many
+SELECT p.* FROM profile_prefills p
+JOIN questions q ON q.id = p.question_id
+JOIN sections s ON s.id = q.section_id
+WHERE s.form_id = @form_id;
+
+-- name: DeletePrefills :exec
+DELETE FROM profile_prefills
+WHERE question_id IN (
+    SELECT q.id FROM questions q
+    JOIN sections s ON s.id = q.section_id
+    WHERE s.form_id = @form_id
+);
+
+-- name: CreatePrefill :one
+INSERT INTO profile_prefills (question_id, field_id)
+VALUES (@question_id, @field_id)
+RETURNING *;
+
+-- name: ListPrefillValues :many
+SELECT p.question_id, f.key, v.value
+FROM profile_prefills p
+JOIN questions q ON q.id = p.question_id
+JOIN sections s ON s.id = q.section_id
+JOIN profile_fields f ON f.id = p.field_id
+JOIN profile_values v ON v.field_id = p.field_id AND v.user_id = @user_id
+WHERE s.form_id = @form_id;
\ No newline at end of file
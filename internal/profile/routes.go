@@ -0,0 +1,23 @@
+package profile
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the profile fields of an organization, the profiles of its members
+// and the form questions prefilled from them
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/profile-fields", route.TenantAuthenticated, route.PermissionNone, h.ListFieldsHandler)
+	r.Handle("POST /orgs/{slug}/profile-fields", route.TenantAuthenticated, route.PermissionOrgAdmin, h.CreateFieldHandler)
+	r.Handle("PUT /orgs/{slug}/profile-fields/{fieldId}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.UpdateFieldHandler)
+	r.Handle("DELETE /orgs/{slug}/profile-fields/{fieldId}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.DeleteFieldHandler)
+	r.Handle("GET /orgs/{slug}/profile", route.TenantAuthenticated, route.PermissionSelf, h.GetOwnHandler)
+	r.Handle("PUT /orgs/{slug}/profile", route.TenantAuthenticated, route.PermissionSelf, h.SetOwnHandler)
+	r.Handle("GET /orgs/{slug}/members/export", route.TenantAuthenticated, route.PermissionOrgAdmin, h.ExportHandler)
+	r.Handle("GET /orgs/{slug}/members/{member_id}/profile", route.TenantAuthenticated, route.PermissionOrgAdmin, h.GetMemberHandler)
+	r.Handle("PUT /orgs/{slug}/members/{member_id}/profile", route.TenantAuthenticated, route.PermissionOrgAdmin, h.SetMemberHandler)
+
+	r.Handle("GET /forms/{id}/profile-prefills", route.Authenticated, route.PermissionOrgAdmin, h.ListPrefillsHandler)
+	r.Handle("PUT /forms/{id}/profile-prefills", route.Authenticated, route.PermissionOrgAdmin, h.SetPrefillsHandler)
+	r.Handle("GET /forms/{id}/prefill", route.Authenticated, route.PermissionSelf, h.PrefillHandler)
+}
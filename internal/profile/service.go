@@ -0,0 +1,723 @@
+package profile
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// MaxValueLength is the longest value, in characters, a member can give for a field
+const MaxValueLength = 500
+
+// keyPattern is the form of field keys, which attribute eligibility rules reference as
+// profile.<key>
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+type Querier interface {
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	IsOrgMember(ctx context.Context, arg IsOrgMemberParams) (bool, error)
+	GetFormOrg(ctx context.Context, formID uuid.UUID) (uuid.UUID, error)
+	CreateField(ctx context.Context, arg CreateFieldParams) (ProfileField, error)
+	ListFields(ctx context.Context, orgID uuid.UUID) ([]ProfileField, error)
+	UpdateField(ctx context.Context, arg UpdateFieldParams) (ProfileField, error)
+	DeleteField(ctx context.Context, arg DeleteFieldParams) (int64, error)
+	ListValues(ctx context.Context, arg ListValuesParams) ([]ProfileValue, error)
+	ListOrgValues(ctx context.Context, orgID uuid.UUID) ([]ProfileValue, error)
+	UpsertValue(ctx context.Context, arg UpsertValueParams) error
+	DeleteValue(ctx context.Context, arg DeleteValueParams) error
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]ListMembersRow, error)
+	IsQuestionInForm(ctx context.Context, arg IsQuestionInFormParams) (bool, error)
+	ListPrefills(ctx context.Context, formID uuid.UUID) ([]ProfilePrefill, error)
+	DeletePrefills(ctx context.Context, formID uuid.UUID) error
+	CreatePrefill(ctx context.Context, arg CreatePrefillParams) (ProfilePrefill, error)
+	ListPrefillValues(ctx context.Context, arg ListPrefillValuesParams) ([]ListPrefillValuesRow, error)
+}
+
+// DB is the connection the service runs on; the values of a profile and the prefills
+// of a form are replaced in transactions begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// FieldInput describes a profile field. Options are the allowed values of a select
+// field and must be empty for the other types.
+type FieldInput struct {
+	Key      string
+	Label    string
+	Type     ProfileFieldType
+	Options  []string
+	Required bool
+	Position int32
+}
+
+// Profile is what a member gave for the fields of an organization, keyed by field key,
+// and the keys of the required fields still without a value
+type Profile struct {
+	Values  map[string]string
+	Missing []string
+}
+
+type Service struct {
+	logger  *zap.Logger
+	db      DB
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DB) *Service {
+	return &Service{
+		logger:  logger,
+		db:      db,
+		queries: New(db),
+		tracer:  otel.Tracer("profile/service"),
+	}
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+func (s *Service) requireOrgAdmin(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsOrgAdmin(ctx, IsOrgAdminParams{OrgID: orgID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func (s *Service) isOrgMember(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) (bool, error) {
+	isMember, err := s.queries.IsOrgMember(ctx, IsOrgMemberParams{OrgID: orgID, UserID: userID})
+	if err != nil {
+		return false, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", orgID.String(), logger, "check organization membership")
+	}
+	return isMember, nil
+}
+
+// requireFormAdmin allows the owner of the organization of the form only and returns
+// that organization
+func (s *Service) requireFormAdmin(ctx context.Context, logger *zap.Logger, formID uuid.UUID, userID uuid.UUID) (uuid.UUID, error) {
+	orgID, err := s.queries.GetFormOrg(ctx, formID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, internal.ErrFormNotFound
+		}
+		return uuid.Nil, databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "get form organization")
+	}
+
+	err = s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return orgID, nil
+}
+
+func (s *Service) listFields(ctx context.Context, logger *zap.Logger, orgID uuid.UUID) ([]ProfileField, error) {
+	fields, err := s.queries.ListFields(ctx, orgID)
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "profile_fields", "org_id", orgID.String(), logger, "list profile fields")
+	}
+	if fields == nil {
+		fields = []ProfileField{}
+	}
+	return fields, nil
+}
+
+// checkField normalizes the input of a field
+func checkField(input FieldInput) (FieldInput, error) {
+	input.Key = strings.TrimSpace(input.Key)
+	if !keyPattern.MatchString(input.Key) {
+		return FieldInput{}, fmt.Errorf("%w: key must start with a lowercase letter and contain only lowercase letters, digits and underscores", internal.ErrProfileFieldInvalid)
+	}
+
+	options := make([]string, 0, len(input.Options))
+	for _, option := range input.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || slices.Contains(options, option) {
+			return FieldInput{}, fmt.Errorf("%w: options must be distinct and not empty", internal.ErrProfileFieldInvalid)
+		}
+		options = append(options, option)
+	}
+
+	switch input.Type {
+	case ProfileFieldTypeSelect:
+		if len(options) == 0 {
+			return FieldInput{}, fmt.Errorf("%w: select field requires options", internal.ErrProfileFieldInvalid)
+		}
+	case ProfileFieldTypeText, ProfileFieldTypeNumber:
+		if len(options) != 0 {
+			return FieldInput{}, fmt.Errorf("%w: only select fields have options", internal.ErrProfileFieldInvalid)
+		}
+	default:
+		return FieldInput{}, fmt.Errorf("%w: unsupported type %s", internal.ErrProfileFieldInvalid, input.Type)
+	}
+
+	input.Label = strings.TrimSpace(input.Label)
+	input.Options = options
+	return input, nil
+}
+
+// resolve checks the values, keyed by field key, against the fields of the organization
+// and returns them keyed by field ID; an empty value clears the field
+func resolve(fields []ProfileField, values map[string]string) (map[uuid.UUID]string, error) {
+	byKey := make(map[string]ProfileField, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = field
+	}
+
+	resolved := make(map[uuid.UUID]string, len(values))
+	for key, value := range values {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %s", internal.ErrProfileValueInvalid, key)
+		}
+
+		value = strings.TrimSpace(value)
+		if value != "" {
+			if utf8.RuneCountInString(value) > MaxValueLength {
+				return nil, fmt.Errorf("%w: %s is longer than %d characters", internal.ErrProfileValueInvalid, key, MaxValueLength)
+			}
+
+			switch field.Type {
+			case ProfileFieldTypeNumber:
+				_, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: %s must be a number", internal.ErrProfileValueInvalid, key)
+				}
+			case ProfileFieldTypeSelect:
+				if !slices.Contains(field.Options, value) {
+					return nil, fmt.Errorf("%w: %s must be one of %s", internal.ErrProfileValueInvalid, key, strings.Join(field.Options, ", "))
+				}
+			}
+		}
+
+		resolved[field.ID] = value
+	}
+
+	return resolved, nil
+}
+
+func buildProfile(fields []ProfileField, values []ProfileValue) Profile {
+	byField := make(map[uuid.UUID]string, len(values))
+	for _, value := range values {
+		byField[value.FieldID] = value.Value
+	}
+
+	profile := Profile{Values: make(map[string]string, len(values)), Missing: []string{}}
+	for _, field := range fields {
+		value, ok := byField[field.ID]
+		if ok {
+			profile.Values[field.Key] = value
+		} else if field.Required {
+			profile.Missing = append(profile.Missing, field.Key)
+		}
+	}
+	return profile
+}
+
+// ListFields returns the profile fields of the organization in the order they are shown
+func (s *Service) ListFields(ctx context.Context, orgID uuid.UUID) ([]ProfileField, error) {
+	ctx, span := s.tracer.Start(ctx, "ListFields")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	fields, err := s.listFields(ctx, logger, orgID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return fields, nil
+}
+
+// CreateField adds a profile field to the organization; the organization admin only
+func (s *Service) CreateField(ctx context.Context, orgID uuid.UUID, input FieldInput, userID uuid.UUID) (ProfileField, error) {
+	ctx, span := s.tracer.Start(ctx, "CreateField")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return ProfileField{}, err
+	}
+
+	input, err = checkField(input)
+	if err != nil {
+		span.RecordError(err)
+		return ProfileField{}, err
+	}
+
+	field, err := s.queries.CreateField(ctx, CreateFieldParams{
+		OrgID:    orgID,
+		Key:      input.Key,
+		Label:    input.Label,
+		Type:     input.Type,
+		Options:  input.Options,
+		Required: input.Required,
+		Position: input.Position,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "profile_fields", "org_id", orgID.String(), logger, "create profile field")
+		if errors.Is(err, databaseutil.ErrUniqueViolation) {
+			err = internal.ErrProfileFieldKeyTaken
+		}
+		span.RecordError(err)
+		return ProfileField{}, err
+	}
+
+	logger.Info("Created profile field", zap.String("org_id", orgID.String()), zap.String("key", field.Key))
+
+	return field, nil
+}
+
+// UpdateField changes a profile field of the organization; its key stays as created so
+// that the eligibility rules referencing it keep working. Values no longer fitting the
+// field are kept until the member edits them.
+func (s *Service) UpdateField(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input FieldInput, userID uuid.UUID) (ProfileField, error) {
+	ctx, span := s.tracer.Start(ctx, "UpdateField")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return ProfileField{}, err
+	}
+
+	input, err = checkField(input)
+	if err != nil {
+		span.RecordError(err)
+		return ProfileField{}, err
+	}
+
+	field, err := s.queries.UpdateField(ctx, UpdateFieldParams{
+		ID:       id,
+		OrgID:    orgID,
+		Label:    input.Label,
+		Type:     input.Type,
+		Options:  input.Options,
+		Required: input.Required,
+		Position: input.Position,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrProfileFieldNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "profile_fields", "id", id.String(), logger, "update profile field")
+		}
+		span.RecordError(err)
+		return ProfileField{}, err
+	}
+
+	return field, nil
+}
+
+// DeleteField removes a profile field with the values given for it and the questions
+// it prefills; the organization admin only
+func (s *Service) DeleteField(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "DeleteField")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.DeleteField(ctx, DeleteFieldParams{ID: id, OrgID: orgID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "profile_fields", "id", id.String(), logger, "delete profile field")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		span.RecordError(internal.ErrProfileFieldNotFound)
+		return internal.ErrProfileFieldNotFound
+	}
+
+	logger.Info("Deleted profile field", zap.String("org_id", orgID.String()), zap.String("field_id", id.String()))
+
+	return nil
+}
+
+// Validate checks the values, keyed by field key, against the fields of the
+// organization without storing them
+func (s *Service) Validate(ctx context.Context, orgID uuid.UUID, values map[string]string) error {
+	ctx, span := s.tracer.Start(ctx, "Validate")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	fields, err := s.listFields(ctx, logger, orgID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	_, err = resolve(fields, values)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
+
+// Save stores the values, keyed by field key, in the profile of the user in the
+// organization, leaving the fields not given as they are. It does not check who is
+// saving; it backs the collection of the profile when a member is added.
+func (s *Service) Save(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, values map[string]string) (Profile, error) {
+	ctx, span := s.tracer.Start(ctx, "Save")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	profile, err := s.save(ctx, logger, orgID, userID, values)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+func (s *Service) save(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID, values map[string]string) (Profile, error) {
+	fields, err := s.listFields(ctx, logger, orgID)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	resolved, err := resolve(fields, values)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	var stored []ProfileValue
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		for fieldID, value := range resolved {
+			if value == "" {
+				err := queries.DeleteValue(ctx, DeleteValueParams{FieldID: fieldID, UserID: userID})
+				if err != nil {
+					return databaseutil.WrapDBErrorWithKeyValue(err, "profile_values", "field_id", fieldID.String(), logger, "clear profile value")
+				}
+				continue
+			}
+
+			err := queries.UpsertValue(ctx, UpsertValueParams{FieldID: fieldID, UserID: userID, Value: value})
+			if err != nil {
+				return databaseutil.WrapDBErrorWithKeyValue(err, "profile_values", "field_id", fieldID.String(), logger, "save profile value")
+			}
+		}
+
+		var err error
+		stored, err = queries.ListValues(ctx, ListValuesParams{OrgID: orgID, UserID: userID})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "profile_values", "user_id", userID.String(), logger, "list profile values")
+		}
+		return nil
+	})
+	if err != nil {
+		return Profile{}, err
+	}
+
+	return buildProfile(fields, stored), nil
+}
+
+func (s *Service) get(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) (Profile, error) {
+	fields, err := s.listFields(ctx, logger, orgID)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	values, err := s.queries.ListValues(ctx, ListValuesParams{OrgID: orgID, UserID: userID})
+	if err != nil {
+		return Profile{}, databaseutil.WrapDBErrorWithKeyValue(err, "profile_values", "user_id", userID.String(), logger, "list profile values")
+	}
+
+	return buildProfile(fields, values), nil
+}
+
+func (s *Service) requireOrgMember(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isMember, err := s.isOrgMember(ctx, logger, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("%w: user is not a member of organization %s", internal.ErrPermissionDenied, orgID)
+	}
+	return nil
+}
+
+// GetOwn returns the profile of the user in the organization they are a member of
+func (s *Service) GetOwn(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) (Profile, error) {
+	ctx, span := s.tracer.Start(ctx, "GetOwn")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgMember(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+
+	profile, err := s.get(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// SetOwn lets a member edit their profile in the organization
+func (s *Service) SetOwn(ctx context.Context, orgID uuid.UUID, values map[string]string, userID uuid.UUID) (Profile, error) {
+	ctx, span := s.tracer.Start(ctx, "SetOwn")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgMember(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+
+	profile, err := s.save(ctx, logger, orgID, userID, values)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// requireMemberOf allows the organization admin to manage a member of the organization
+func (s *Service) requireMemberOf(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, memberID uuid.UUID, userID uuid.UUID) error {
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := s.isOrgMember(ctx, logger, orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return internal.ErrMemberNotFound
+	}
+	return nil
+}
+
+// GetMember returns the profile of a member of the organization to its admin
+func (s *Service) GetMember(ctx context.Context, orgID uuid.UUID, memberID uuid.UUID, userID uuid.UUID) (Profile, error) {
+	ctx, span := s.tracer.Start(ctx, "GetMember")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMemberOf(ctx, logger, orgID, memberID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+
+	profile, err := s.get(ctx, logger, orgID, memberID)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// SetMember lets the organization admin edit the profile of a member
+func (s *Service) SetMember(ctx context.Context, orgID uuid.UUID, memberID uuid.UUID, values map[string]string, userID uuid.UUID) (Profile, error) {
+	ctx, span := s.tracer.Start(ctx, "SetMember")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMemberOf(ctx, logger, orgID, memberID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+
+	profile, err := s.save(ctx, logger, orgID, memberID, values)
+	if err != nil {
+		span.RecordError(err)
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// ExportMembers builds a CSV of the members of the organization with a column for each
+// of its profile fields; the organization admin only
+func (s *Service) ExportMembers(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]byte, error) {
+	ctx, span := s.tracer.Start(ctx, "ExportMembers")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireOrgAdmin(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	fields, err := s.listFields(ctx, logger, orgID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	members, err := s.queries.ListMembers(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", orgID.String(), logger, "list organization members")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	values, err := s.queries.ListOrgValues(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "profile_values", "org_id", orgID.String(), logger, "list profile values")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	content, err := buildCSV(fields, members, values)
+	if err != nil {
+		err = fmt.Errorf("failed to build member export: %w", err)
+		span.RecordError(err)
+		return nil, err
+	}
+	return content, nil
+}
+
+// ListPrefills returns which questions of the form are prefilled from which profile
+// field; the admin of the organization of the form only
+func (s *Service) ListPrefills(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]ProfilePrefill, error) {
+	ctx, span := s.tracer.Start(ctx, "ListPrefills")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	_, err := s.requireFormAdmin(ctx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	prefills, err := s.queries.ListPrefills(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "profile_prefills", "form_id", formID.String(), logger, "list profile prefills")
+		span.RecordError(err)
+		return nil, err
+	}
+	if prefills == nil {
+		prefills = []ProfilePrefill{}
+	}
+	return prefills, nil
+}
+
+// SetPrefills replaces the prefills of the form with the given profile field for each
+// question; the fields must be of the organization of the form
+func (s *Service) SetPrefills(ctx context.Context, formID uuid.UUID, prefills map[uuid.UUID]uuid.UUID, userID uuid.UUID) ([]ProfilePrefill, error) {
+	ctx, span := s.tracer.Start(ctx, "SetPrefills")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	orgID, err := s.requireFormAdmin(ctx, logger, formID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	fields, err := s.listFields(ctx, logger, orgID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	created := make([]ProfilePrefill, 0, len(prefills))
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		err := queries.DeletePrefills(ctx, formID)
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "profile_prefills", "form_id", formID.String(), logger, "delete profile prefills")
+		}
+
+		for questionID, fieldID := range prefills {
+			if !slices.ContainsFunc(fields, func(field ProfileField) bool { return field.ID == fieldID }) {
+				return fmt.Errorf("%w: field %s is not a profile field of the organization", internal.ErrProfilePrefillInvalid, fieldID)
+			}
+
+			inForm, err := queries.IsQuestionInForm(ctx, IsQuestionInFormParams{QuestionID: questionID, FormID: formID})
+			if err != nil {
+				return databaseutil.WrapDBErrorWithKeyValue(err, "questions", "id", questionID.String(), logger, "check question of form")
+			}
+			if !inForm {
+				return fmt.Errorf("%w: question %s is not a question of the form", internal.ErrProfilePrefillInvalid, questionID)
+			}
+
+			prefill, err := queries.CreatePrefill(ctx, CreatePrefillParams{QuestionID: questionID, FieldID: fieldID})
+			if err != nil {
+				return databaseutil.WrapDBErrorWithKeyValue(err, "profile_prefills", "question_id", questionID.String(), logger, "create profile prefill")
+			}
+			created = append(created, prefill)
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	logger.Info("Replaced profile prefills", zap.String("form_id", formID.String()), zap.Int("count", len(created)))
+
+	return created, nil
+}
+
+// Prefill returns the values of the profile of the user for the questions of the form
+// prefilled from it; questions whose field the user left empty are omitted
+func (s *Service) Prefill(ctx context.Context, formID uuid.UUID, userID uuid.UUID) ([]ListPrefillValuesRow, error) {
+	ctx, span := s.tracer.Start(ctx, "Prefill")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	values, err := s.queries.ListPrefillValues(ctx, ListPrefillValuesParams{UserID: userID, FormID: formID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "profile_prefills", "form_id", formID.String(), logger, "list prefill values")
+		span.RecordError(err)
+		return nil, err
+	}
+	if values == nil {
+		values = []ListPrefillValuesRow{}
+	}
+	return values, nil
+}
@@ -21,8 +21,9 @@ type PreviewResponse struct {
 }
 
 type Request struct {
-	OrgID   uuid.UUID   `json:"orgId"`
-	UnitIDs []uuid.UUID `json:"unitIds"`
+	OrgID    uuid.UUID   `json:"orgId"`
+	UnitIDs  []uuid.UUID `json:"unitIds"`
+	GroupIDs []uuid.UUID `json:"groupIds"`
 }
 
 type Handler struct {
@@ -55,7 +56,7 @@ func (h *Handler) PreviewForm(w http.ResponseWriter, r *http.Request) {
 	logger := logutil.WithContext(ctx, h.logger)
 
 	var req Request
-	if err := handlerutil.ParseAndValidateRequestBody(ctx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(ctx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(ctx, w, err, logger)
 		return
 	}
@@ -82,7 +83,7 @@ func (h *Handler) PublishForm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req Request
-	if err := handlerutil.ParseAndValidateRequestBody(ctx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(ctx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(ctx, w, err, logger)
 		return
 	}
@@ -93,7 +94,7 @@ func (h *Handler) PublishForm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.PublishForm(ctx, formID, req.UnitIDs, currentUser.ID); err != nil {
+	if err := h.service.PublishForm(ctx, formID, req.UnitIDs, req.GroupIDs, currentUser.ID); err != nil {
 		h.problemWriter.WriteError(ctx, w, err, logger)
 		return
 	}
@@ -0,0 +1,11 @@
+package publish
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the recipient preview and the publishing of a form
+func Routes(r route.Router, h *Handler) {
+	r.Handle("POST /forms/recipients/preview", route.Authenticated, route.PermissionNone, h.PreviewForm)
+	r.Handle("POST /forms/{id}/publish", route.Authenticated, route.PermissionNone, h.PublishForm)
+}
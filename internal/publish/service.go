@@ -19,6 +19,7 @@ import (
 type Distributor interface {
 	GetOrgRecipients(ctx context.Context, orgID uuid.UUID) ([]uuid.UUID, error)
 	GetRecipients(ctx context.Context, unitIDs []uuid.UUID) ([]uuid.UUID, error)
+	GetGroupRecipients(ctx context.Context, groupIDs []uuid.UUID) ([]uuid.UUID, error)
 }
 
 type FormStore interface {
@@ -30,9 +31,12 @@ type InboxPort interface {
 	Create(ctx context.Context, contentType inbox.ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error)
 }
 
+// Selection picks the recipients of a publication: the whole organization or a set of
+// units, plus the members of any recipient groups
 type Selection struct {
-	OrgID   uuid.UUID
-	UnitIDs []uuid.UUID
+	OrgID    uuid.UUID
+	UnitIDs  []uuid.UUID
+	GroupIDs []uuid.UUID
 }
 
 type Service struct {
@@ -82,13 +86,33 @@ func (s *Service) GetRecipients(ctx context.Context, selection Selection) ([]uui
 		users = append(users, unitUsers...)
 	}
 
+	if len(selection.GroupIDs) > 0 {
+		groupUsers, err := s.distributor.GetGroupRecipients(ctx, selection.GroupIDs)
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "getting group recipients")
+			span.RecordError(err)
+			return nil, err
+		}
+		users = append(users, groupUsers...)
+	}
+
 	// can add some verify method here
 
-	return users, nil
+	seen := make(map[uuid.UUID]struct{}, len(users))
+	uniq := make([]uuid.UUID, 0, len(users))
+	for _, id := range users {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		uniq = append(uniq, id)
+	}
+
+	return uniq, nil
 }
 
 // PublishForm not Publish is because maybe we will publish something else in future
-func (s *Service) PublishForm(ctx context.Context, formID uuid.UUID, unitIDs []uuid.UUID, editor uuid.UUID) error {
+func (s *Service) PublishForm(ctx context.Context, formID uuid.UUID, unitIDs []uuid.UUID, groupIDs []uuid.UUID, editor uuid.UUID) error {
 	ctx, span := s.tracer.Start(ctx, "PublishForm")
 	defer span.End()
 	logger := logutil.WithContext(ctx, s.logger)
@@ -122,7 +146,8 @@ func (s *Service) PublishForm(ctx context.Context, formID uuid.UUID, unitIDs []u
 	}
 
 	recipientIDs, err := s.GetRecipients(ctx, Selection{
-		UnitIDs: unitIDs,
+		UnitIDs:  unitIDs,
+		GroupIDs: groupIDs,
 	})
 	if err != nil {
 		span.RecordError(err)
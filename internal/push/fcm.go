@@ -0,0 +1,121 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCM sends notifications through the Firebase Cloud Messaging HTTP v1 API
+type FCM struct {
+	client    *http.Client
+	projectID string
+}
+
+// NewFCM authenticates with the service account file of a Firebase project
+func NewFCM(ctx context.Context, credentialsFile string) (*FCM, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fcm credentials: %w", err)
+	}
+
+	credentials, err := google.CredentialsFromJSON(ctx, data, fcmScope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fcm credentials: %w", err)
+	}
+	if credentials.ProjectID == "" {
+		return nil, fmt.Errorf("fcm credentials have no project_id")
+	}
+
+	return &FCM{
+		client:    oauth2.NewClient(ctx, credentials.TokenSource),
+		projectID: credentials.ProjectID,
+	}, nil
+}
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmError struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send delivers the notification to the FCM registration token stored as the endpoint of the device
+func (f *FCM) Send(ctx context.Context, device PushDevice, notification Notification) error {
+	body, err := json.Marshal(fcmRequest{
+		Message: fcmMessage{
+			Token: device.Endpoint,
+			Notification: fcmNotification{
+				Title: notification.Title,
+				Body:  notification.Body,
+			},
+			Data: map[string]string{
+				"url":       notification.URL,
+				"messageId": notification.MessageID,
+				"inboxId":   notification.InboxID,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode fcm message: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", f.projectID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create fcm request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send fcm message: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var response fcmError
+	if json.Unmarshal(data, &response) == nil {
+		for _, detail := range response.Error.Details {
+			if detail.ErrorCode == "UNREGISTERED" {
+				return ErrDeviceGone
+			}
+		}
+		if response.Error.Status == "NOT_FOUND" {
+			return ErrDeviceGone
+		}
+	}
+
+	return fmt.Errorf("fcm returned %s: %s", resp.Status, data)
+}
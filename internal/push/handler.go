@@ -0,0 +1,279 @@
+package push
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	RegisterDevice(ctx context.Context, userID uuid.UUID, input DeviceInput) (PushDevice, error)
+	ListDevices(ctx context.Context, userID uuid.UUID) ([]PushDevice, error)
+	DeleteDevice(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	GetPreferences(ctx context.Context, userID uuid.UUID) (PushPreference, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, input PreferencesInput) (PushPreference, error)
+}
+
+type SubscriptionKeys struct {
+	P256dh string `json:"p256dh" validate:"required"`
+	Auth   string `json:"auth"   validate:"required"`
+}
+
+// DeviceRequest registers a device. Web clients send the PushSubscription of the
+// browser as endpoint and keys; FCM clients send their registration token.
+type DeviceRequest struct {
+	Platform string            `json:"platform" validate:"required,oneof=web fcm"`
+	Endpoint string            `json:"endpoint" validate:"required_if=Platform web,omitempty,url,max=2048"`
+	Keys     *SubscriptionKeys `json:"keys"     validate:"required_if=Platform web,omitempty"`
+	Token    string            `json:"token"    validate:"required_if=Platform fcm,max=4096"`
+}
+
+type DeviceResponse struct {
+	ID         string     `json:"id"`
+	Platform   string     `json:"platform"`
+	UserAgent  string     `json:"userAgent"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func ToDeviceResponse(device PushDevice) DeviceResponse {
+	response := DeviceResponse{
+		ID:        device.ID.String(),
+		Platform:  string(device.Platform),
+		UserAgent: device.UserAgent,
+		CreatedAt: device.CreatedAt.Time,
+	}
+	if device.LastUsedAt.Valid {
+		response.LastUsedAt = &device.LastUsedAt.Time
+	}
+	return response
+}
+
+// PreferencesRequest replaces the push preferences of the user. Digest batches the
+// notifications of an hour or a day into a single summary.
+type PreferencesRequest struct {
+	Enabled      bool   `json:"enabled"`
+	FormMessages bool   `json:"formMessages"`
+	TextMessages bool   `json:"textMessages"`
+	Digest       string `json:"digest" validate:"omitempty,oneof=immediate hourly daily"`
+}
+
+type PreferencesResponse struct {
+	Enabled      bool   `json:"enabled"`
+	FormMessages bool   `json:"formMessages"`
+	TextMessages bool   `json:"textMessages"`
+	Digest       string `json:"digest"`
+}
+
+func ToPreferencesResponse(preferences PushPreference) PreferencesResponse {
+	return PreferencesResponse{
+		Enabled:      preferences.Enabled,
+		FormMessages: preferences.FormMessages,
+		TextMessages: preferences.TextMessages,
+		Digest:       string(preferences.Digest),
+	}
+}
+
+type VAPIDKeyResponse struct {
+	PublicKey string `json:"publicKey"`
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store          Store
+	vapidPublicKey string
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	vapidPublicKey string,
+) *Handler {
+	return &Handler{
+		logger:         logger,
+		tracer:         otel.Tracer("push/handler"),
+		validator:      validator,
+		problemWriter:  problemWriter,
+		store:          store,
+		vapidPublicKey: vapidPublicKey,
+	}
+}
+
+// VAPIDKeyHandler returns the application server key browsers subscribe with
+func (h *Handler) VAPIDKeyHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "VAPIDKeyHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	if h.vapidPublicKey == "" {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrPushPlatformUnavailable, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, VAPIDKeyResponse{PublicKey: h.vapidPublicKey})
+}
+
+func (h *Handler) RegisterDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RegisterDeviceHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	var req DeviceRequest
+	err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	input := DeviceInput{
+		Platform:  PushPlatform(req.Platform),
+		Endpoint:  req.Endpoint,
+		UserAgent: r.UserAgent(),
+	}
+	if input.Platform == PushPlatformFcm {
+		input.Endpoint = req.Token
+	} else {
+		input.P256dh = req.Keys.P256dh
+		input.Auth = req.Keys.Auth
+	}
+
+	device, err := h.store.RegisterDevice(traceCtx, currentUser.ID, input)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToDeviceResponse(device))
+}
+
+func (h *Handler) ListDevicesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListDevicesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	devices, err := h.store.ListDevices(traceCtx, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]DeviceResponse, len(devices))
+	for i, device := range devices {
+		response[i] = ToDeviceResponse(device)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) DeleteDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteDeviceHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	err = h.store.DeleteDevice(traceCtx, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) GetPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetPreferencesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	preferences, err := h.store.GetPreferences(traceCtx, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToPreferencesResponse(preferences))
+}
+
+func (h *Handler) UpdatePreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdatePreferencesHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	var req PreferencesRequest
+	err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	digest := PushDigestImmediate
+	if req.Digest != "" {
+		digest = PushDigest(req.Digest)
+	}
+
+	preferences, err := h.store.UpdatePreferences(traceCtx, currentUser.ID, PreferencesInput{
+		Enabled:      req.Enabled,
+		FormMessages: req.FormMessages,
+		TextMessages: req.TextMessages,
+		Digest:       digest,
+	})
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToPreferencesResponse(preferences))
+}
@@ -0,0 +1,45 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDeviceGone is returned by a Sender when the push service no longer accepts
+// notifications for the device, so its registration can be removed
+var ErrDeviceGone = errors.New("push device is no longer registered")
+
+// Notification is what a device shows for a new inbox message
+type Notification struct {
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	URL       string `json:"url"`
+	MessageID string `json:"messageId"`
+	InboxID   string `json:"inboxId,omitempty"`
+}
+
+// Sender delivers notifications to the devices of one platform
+type Sender interface {
+	Send(ctx context.Context, device PushDevice, notification Notification) error
+}
+
+// Config holds the credentials of the push services. Web Push needs a VAPID key pair,
+// generated once with e.g. `npx web-push generate-vapid-keys`; FCM needs the service
+// account file of the Firebase project. Either platform is disabled when left empty.
+type Config struct {
+	VAPIDPublicKey     string `yaml:"vapid_public_key"     envconfig:"PUSH_VAPID_PUBLIC_KEY"`
+	VAPIDPrivateKey    string `yaml:"vapid_private_key"    envconfig:"PUSH_VAPID_PRIVATE_KEY"`
+	VAPIDSubject       string `yaml:"vapid_subject"        envconfig:"PUSH_VAPID_SUBJECT"`
+	FCMCredentialsFile string `yaml:"fcm_credentials_file" envconfig:"PUSH_FCM_CREDENTIALS_FILE"`
+}
+
+func (c *Config) Validate() error {
+	if (c.VAPIDPublicKey == "") != (c.VAPIDPrivateKey == "") {
+		return fmt.Errorf("push vapid_public_key and vapid_private_key must be set together")
+	}
+	if c.VAPIDPrivateKey != "" && c.VAPIDSubject == "" {
+		return fmt.Errorf("push vapid_subject is required for web push, e.g. mailto:admin@example.com")
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+-- name: UpsertDevice :one
+INSERT INTO push_devices (user_id, platform, endpoint, p256dh, auth, user_agent)
+VALUES (@user_id, @platform, @endpoint, @p256dh, @auth, @user_agent)
+ON CONFLICT (endpoint) DO UPDATE
+SET user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    p256dh = EXCLUDED.p256dh,
+    auth = EXCLUDED.auth,
+    user_agent = EXCLUDED.user_agent,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListDevicesByUserID :many
+SELECT * FROM push_devices
+WHERE user_id = @user_id
+ORDER BY created_at ASC;
+
+-- name: DeleteDevice :execrows
+DELETE FROM push_devices
+WHERE id = @id AND user_id = @user_id;
+
+-- name: DeleteDeviceByID :exec
+DELETE FROM push_devices
+WHERE id = @id;
+
+-- name: TouchDevice :exec
+UPDATE push_devices
+SET last_used_at = now()
+WHERE id = @id;
+
+-- name: GetPreferences :one
+SELECT * FROM push_preferences
+WHERE user_id = @user_id;
+
+-- name: UpsertPreferences :one
+INSERT INTO push_preferences (user_id, enabled, form_messages, text_messages, digest)
+VALUES (@user_id, @enabled, @form_messages, @text_messages, @digest)
+ON CONFLICT (user_id) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    form_messages = EXCLUDED.form_messages,
+    text_messages = EXCLUDED.text_messages,
+    digest = EXCLUDED.digest,
+    last_digest_at = CASE WHEN push_preferences.digest = EXCLUDED.digest THEN push_preferences.last_digest_at ELSE now() END,
+    updated_at = now()
+RETURNING *;
+
+-- name: EnqueueJobs :execrows
+INSERT INTO push_jobs (user_id, message_id)
+SELECT d.user_id, im.id
+FROM inbox_message im
+JOIN (SELECT DISTINCT user_id FROM push_devices WHERE user_id = ANY(@user_ids::UUID[])) d ON true
+LEFT JOIN push_preferences p ON p.user_id = d.user_id
+WHERE im.id = @message_id
+  AND (p.user_id IS NULL OR (p.enabled AND CASE WHEN im.type = 'form' THEN p.form_messages ELSE p.text_messages END));
+
+-- name: ClaimJobs :many
+UPDATE push_jobs
+SET attempts = attempts + 1,
+    next_attempt_at = @lease_until,
+    updated_at = now()
+WHERE id IN (
+    SELECT id FROM push_jobs
+    WHERE status = 'pending' AND next_attempt_at <= now()
+      AND NOT EXISTS (
+        SELECT 1 FROM push_preferences p
+        WHERE p.user_id = push_jobs.user_id AND p.digest <> 'immediate'
+      )
+    ORDER BY next_attempt_at ASC
+    LIMIT @max_count
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: GetPayload :one
+SELECT
+    im.id,
+    im.type,
+    im.body,
+    uim.id AS inbox_id,
+    f.title AS form_title,
+    u.name AS unit_name,
+    su.name AS sender_name
+FROM inbox_message im
+LEFT JOIN user_inbox_messages uim ON uim.message_id = im.id AND uim.user_id = @user_id
+LEFT JOIN forms f ON im.type = 'form' AND f.id = im.content_id
+LEFT JOIN units u ON u.id = im.posted_by
+LEFT JOIN users su ON su.id = im.sender_id
+WHERE im.id = @message_id;
+
+-- name: MarkJobSent :exec
+UPDATE push_jobs
+SET status = 'sent',
+    last_error = NULL,
+    updated_at = now()
+WHERE id = @id;
+
+-- name: MarkJobFailed :exec
+UPDATE push_jobs
+SET status = @status,
+    last_error = @last_error,
+    next_attempt_at = @next_attempt_at,
+    updated_at = now()
+WHERE id = @id;
+
+-- name: ListDueDigests :many
+SELECT * FROM push_preferences p
+WHERE p.digest <> 'immediate'
+  AND p.last_digest_at <= now() - CASE WHEN p.digest = 'hourly' THEN interval '1 hour' ELSE interval '1 day' END
+  AND EXISTS (
+    SELECT 1 FROM push_jobs j
+    WHERE j.user_id = p.user_id AND j.status = 'pending'
+  )
+LIMIT @max_count;
+
+-- name: ClaimDigest :execrows
+UPDATE push_preferences
+SET last_digest_at = now()
+WHERE user_id = @user_id AND last_digest_at = @previous_digest_at;
+
+-- name: ListPendingJobsByUserID :many
+SELECT * FROM push_jobs
+WHERE user_id = @user_id AND status = 'pending'
+ORDER BY created_at ASC;
+
+-- name: MarkJobsSent :exec
+UPDATE push_jobs
+SET status = 'sent',
+    last_error = NULL,
+    updated_at = now()
+WHERE id = ANY(@ids::UUID[]);
+
+-- name: RecordDigestFailure :exec
+UPDATE push_jobs
+SET last_error = @last_error,
+    updated_at = now()
+WHERE id = ANY(@ids::UUID[]);
+
+-- name: CountPendingJobs :one
+SELECT count(*) FROM push_jobs
+WHERE status = 'pending';
\ No newline at end of file
@@ -0,0 +1,15 @@
+package push
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the VAPID key and the push devices and preferences of the current user
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /push/vapid-public-key", route.Public, route.PermissionNone, h.VAPIDKeyHandler)
+	r.Handle("GET /users/me/push-devices", route.Authenticated, route.PermissionSelf, h.ListDevicesHandler)
+	r.Handle("POST /users/me/push-devices", route.Authenticated, route.PermissionSelf, h.RegisterDeviceHandler)
+	r.Handle("DELETE /users/me/push-devices/{id}", route.Authenticated, route.PermissionSelf, h.DeleteDeviceHandler)
+	r.Handle("GET /users/me/push-preferences", route.Authenticated, route.PermissionSelf, h.GetPreferencesHandler)
+	r.Handle("PUT /users/me/push-preferences", route.Authenticated, route.PermissionSelf, h.UpdatePreferencesHandler)
+}
@@ -0,0 +1,55 @@
+CREATE TYPE push_platform AS ENUM(
+    'web',
+    'fcm'
+);
+
+CREATE TYPE push_job_status AS ENUM(
+    'pending',
+    'sent',
+    'failed'
+);
+
+CREATE TYPE push_digest AS ENUM(
+    'immediate',
+    'hourly',
+    'daily'
+);
+
+CREATE TABLE IF NOT EXISTS push_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform push_platform NOT NULL,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT DEFAULT NULL,
+    auth TEXT DEFAULT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    last_used_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices(user_id);
+
+CREATE TABLE IF NOT EXISTS push_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    form_messages BOOLEAN NOT NULL DEFAULT true,
+    text_messages BOOLEAN NOT NULL DEFAULT true,
+    digest push_digest NOT NULL DEFAULT 'immediate',
+    last_digest_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS push_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL REFERENCES inbox_message(id) ON DELETE CASCADE,
+    status push_job_status NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_jobs_pending ON push_jobs(next_attempt_at) WHERE status = 'pending';
\ No newline at end of file
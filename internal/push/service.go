@@ -0,0 +1,560 @@
+package push
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/egress"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultDispatchInterval is how often queued push jobs are sent
+	DefaultDispatchInterval = 5 * time.Second
+
+	dispatchBatchSize = 50
+	digestBatchSize   = 50
+	maxAttempts       = 5
+
+	// digestPreviewCount is how many messages a digest lists before summarizing the rest
+	digestPreviewCount = 3
+
+	// jobLease keeps a claimed job from being picked up again while it is being sent;
+	// jobs of a crashed dispatcher are retried once their lease expires
+	jobLease = 5 * time.Minute
+
+	maxBodyLength = 200
+)
+
+type Querier interface {
+	UpsertDevice(ctx context.Context, arg UpsertDeviceParams) (PushDevice, error)
+	ListDevicesByUserID(ctx context.Context, userID uuid.UUID) ([]PushDevice, error)
+	DeleteDevice(ctx context.Context, arg DeleteDeviceParams) (int64, error)
+	DeleteDeviceByID(ctx context.Context, id uuid.UUID) error
+	TouchDevice(ctx context.Context, id uuid.UUID) error
+	GetPreferences(ctx context.Context, userID uuid.UUID) (PushPreference, error)
+	UpsertPreferences(ctx context.Context, arg UpsertPreferencesParams) (PushPreference, error)
+	EnqueueJobs(ctx context.Context, arg EnqueueJobsParams) (int64, error)
+	ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]PushJob, error)
+	GetPayload(ctx context.Context, arg GetPayloadParams) (GetPayloadRow, error)
+	MarkJobSent(ctx context.Context, id uuid.UUID) error
+	MarkJobFailed(ctx context.Context, arg MarkJobFailedParams) error
+	ListDueDigests(ctx context.Context, maxCount int32) ([]PushPreference, error)
+	ClaimDigest(ctx context.Context, arg ClaimDigestParams) (int64, error)
+	ListPendingJobsByUserID(ctx context.Context, userID uuid.UUID) ([]PushJob, error)
+	MarkJobsSent(ctx context.Context, ids []uuid.UUID) error
+	RecordDigestFailure(ctx context.Context, arg RecordDigestFailureParams) error
+	CountPendingJobs(ctx context.Context) (int64, error)
+}
+
+// DeviceInput registers a device. For web, Endpoint, P256dh and Auth come from the
+// PushSubscription of the browser; for fcm, Endpoint is the registration token.
+type DeviceInput struct {
+	Platform  PushPlatform
+	Endpoint  string
+	P256dh    string
+	Auth      string
+	UserAgent string
+}
+
+// PreferencesInput selects which inbox messages are pushed to the devices of a user.
+// With an hourly or daily digest the messages are held back and summarized in a single
+// notification per period.
+type PreferencesInput struct {
+	Enabled      bool
+	FormMessages bool
+	TextMessages bool
+	Digest       PushDigest
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+	senders map[PushPlatform]Sender
+}
+
+// NewService creates the push service. A nil sender disables its platform:
+// registration is rejected and no notifications are sent to existing devices.
+func NewService(logger *zap.Logger, db DBTX, webPush Sender, fcm Sender) *Service {
+	senders := make(map[PushPlatform]Sender)
+	if webPush != nil {
+		senders[PushPlatformWeb] = webPush
+	}
+	if fcm != nil {
+		senders[PushPlatformFcm] = fcm
+	}
+
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("push/service"),
+		senders: senders,
+	}
+}
+
+func validateDevice(input DeviceInput) error {
+	if input.Platform != PushPlatformWeb {
+		return nil
+	}
+
+	endpoint, err := url.Parse(input.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", internal.ErrInvalidPushSubscription)
+	}
+	if egress.CheckHost(endpoint.Hostname()) != nil {
+		return fmt.Errorf("%w: endpoint must not point to a private, loopback or link-local address", internal.ErrInvalidPushSubscription)
+	}
+
+	p256dh, err := base64.RawURLEncoding.DecodeString(input.P256dh)
+	if err != nil || len(p256dh) != 65 {
+		return fmt.Errorf("%w: invalid p256dh key", internal.ErrInvalidPushSubscription)
+	}
+
+	auth, err := base64.RawURLEncoding.DecodeString(input.Auth)
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("%w: invalid auth secret", internal.ErrInvalidPushSubscription)
+	}
+
+	return nil
+}
+
+// RegisterDevice adds a device of the user, or moves an already registered device to them
+func (s *Service) RegisterDevice(ctx context.Context, userID uuid.UUID, input DeviceInput) (PushDevice, error) {
+	ctx, span := s.tracer.Start(ctx, "RegisterDevice")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	if _, ok := s.senders[input.Platform]; !ok {
+		err := fmt.Errorf("%w: %s", internal.ErrPushPlatformUnavailable, input.Platform)
+		span.RecordError(err)
+		return PushDevice{}, err
+	}
+
+	// Browsers hand out base64url keys with padding in some implementations
+	input.P256dh = strings.TrimRight(input.P256dh, "=")
+	input.Auth = strings.TrimRight(input.Auth, "=")
+
+	err := validateDevice(input)
+	if err != nil {
+		span.RecordError(err)
+		return PushDevice{}, err
+	}
+
+	params := UpsertDeviceParams{
+		UserID:    userID,
+		Platform:  input.Platform,
+		Endpoint:  input.Endpoint,
+		UserAgent: input.UserAgent,
+	}
+	if input.Platform == PushPlatformWeb {
+		params.P256dh = pgtype.Text{String: input.P256dh, Valid: true}
+		params.Auth = pgtype.Text{String: input.Auth, Valid: true}
+	}
+
+	device, err := s.queries.UpsertDevice(ctx, params)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "push_devices", "user_id", userID.String(), logger, "register push device")
+		span.RecordError(err)
+		return PushDevice{}, err
+	}
+
+	return device, nil
+}
+
+func (s *Service) ListDevices(ctx context.Context, userID uuid.UUID) ([]PushDevice, error) {
+	ctx, span := s.tracer.Start(ctx, "ListDevices")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	devices, err := s.queries.ListDevicesByUserID(ctx, userID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "push_devices", "user_id", userID.String(), logger, "list push devices")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return devices, nil
+}
+
+func (s *Service) DeleteDevice(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "DeleteDevice")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	deleted, err := s.queries.DeleteDevice(ctx, DeleteDeviceParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "push_devices", "id", id.String(), logger, "delete push device")
+		span.RecordError(err)
+		return err
+	}
+	if deleted == 0 {
+		span.RecordError(internal.ErrPushDeviceNotFound)
+		return internal.ErrPushDeviceNotFound
+	}
+
+	return nil
+}
+
+// GetPreferences returns the push preferences of the user; users who never changed
+// them get every notification
+func (s *Service) GetPreferences(ctx context.Context, userID uuid.UUID) (PushPreference, error) {
+	ctx, span := s.tracer.Start(ctx, "GetPreferences")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	preferences, err := s.queries.GetPreferences(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return PushPreference{
+				UserID:       userID,
+				Enabled:      true,
+				FormMessages: true,
+				TextMessages: true,
+				Digest:       PushDigestImmediate,
+			}, nil
+		}
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "push_preferences", "user_id", userID.String(), logger, "get push preferences")
+		span.RecordError(err)
+		return PushPreference{}, err
+	}
+
+	return preferences, nil
+}
+
+func (s *Service) UpdatePreferences(ctx context.Context, userID uuid.UUID, input PreferencesInput) (PushPreference, error) {
+	ctx, span := s.tracer.Start(ctx, "UpdatePreferences")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	preferences, err := s.queries.UpsertPreferences(ctx, UpsertPreferencesParams{
+		UserID:       userID,
+		Enabled:      input.Enabled,
+		FormMessages: input.FormMessages,
+		TextMessages: input.TextMessages,
+		Digest:       input.Digest,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "push_preferences", "user_id", userID.String(), logger, "update push preferences")
+		span.RecordError(err)
+		return PushPreference{}, err
+	}
+
+	return preferences, nil
+}
+
+// Notify queues a push job for every user who has a device and whose preferences
+// allow the type of the message. It is called when a message reaches personal inboxes.
+func (s *Service) Notify(ctx context.Context, messageID uuid.UUID, userIDs []uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Notify")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	if len(userIDs) == 0 || len(s.senders) == 0 {
+		return nil
+	}
+
+	queued, err := s.queries.EnqueueJobs(ctx, EnqueueJobsParams{
+		UserIds:   userIDs,
+		MessageID: messageID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "push_jobs", "message_id", messageID.String(), logger, "enqueue push jobs")
+		span.RecordError(err)
+		return err
+	}
+
+	if queued > 0 {
+		logger.Debug("Queued push notifications", zap.String("message_id", messageID.String()), zap.Int64("jobs", queued))
+	}
+
+	return nil
+}
+
+// Start sends queued push jobs and due digests every interval until the context is canceled
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	if len(s.senders) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.Dispatch(ctx)
+			if err != nil {
+				s.logger.Error("Failed to dispatch push notifications", zap.Error(err))
+			}
+
+			err = s.DispatchDigests(ctx)
+			if err != nil {
+				s.logger.Error("Failed to dispatch push digests", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Dispatch claims a batch of due push jobs of users without a digest and sends them to
+// the devices of their users. Failed jobs are retried with exponential backoff up to
+// maxAttempts times.
+func (s *Service) Dispatch(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "Dispatch")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	jobs, err := s.queries.ClaimJobs(ctx, ClaimJobsParams{
+		LeaseUntil: pgtype.Timestamptz{Time: time.Now().Add(jobLease), Valid: true},
+		MaxCount:   dispatchBatchSize,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "claim push jobs")
+		span.RecordError(err)
+		return err
+	}
+
+	for _, job := range jobs {
+		sendErr := s.send(ctx, job)
+		if sendErr == nil {
+			err = s.queries.MarkJobSent(ctx, job.ID)
+		} else {
+			logger.Warn("Failed to send push notification", zap.Error(sendErr), zap.String("job_id", job.ID.String()), zap.Int32("attempt", job.Attempts))
+			err = s.queries.MarkJobFailed(ctx, failure(job, sendErr, time.Now()))
+		}
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "push_jobs", "id", job.ID.String(), logger, "record push job result")
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// failure schedules the retry of a failed job, giving up after maxAttempts
+func failure(job PushJob, err error, now time.Time) MarkJobFailedParams {
+	params := MarkJobFailedParams{
+		ID:            job.ID,
+		Status:        PushJobStatusPending,
+		LastError:     pgtype.Text{String: err.Error(), Valid: true},
+		NextAttemptAt: pgtype.Timestamptz{Time: now.Add(time.Duration(1<<job.Attempts) * time.Minute), Valid: true},
+	}
+	if job.Attempts >= maxAttempts {
+		params.Status = PushJobStatusFailed
+		params.NextAttemptAt = pgtype.Timestamptz{Time: now, Valid: true}
+	}
+	return params
+}
+
+// DispatchDigests sends one summary notification to every user whose digest period has
+// passed and who has pending push jobs. Jobs of a failed digest stay pending and are
+// included in the next one.
+func (s *Service) DispatchDigests(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "DispatchDigests")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	due, err := s.queries.ListDueDigests(ctx, digestBatchSize)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list due push digests")
+		span.RecordError(err)
+		return err
+	}
+
+	for _, preferences := range due {
+		// Another instance may have sent the digest since it was listed
+		claimed, err := s.queries.ClaimDigest(ctx, ClaimDigestParams{
+			UserID:           preferences.UserID,
+			PreviousDigestAt: preferences.LastDigestAt,
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "push_preferences", "user_id", preferences.UserID.String(), logger, "claim push digest")
+			span.RecordError(err)
+			return err
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		err = s.sendDigest(ctx, preferences.UserID)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) sendDigest(ctx context.Context, userID uuid.UUID) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	jobs, err := s.queries.ListPendingJobsByUserID(ctx, userID)
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "push_jobs", "user_id", userID.String(), logger, "list pending push jobs")
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(jobs))
+	notifications := make([]Notification, 0, len(jobs))
+	for i, job := range jobs {
+		ids[i] = job.ID
+
+		payload, err := s.queries.GetPayload(ctx, GetPayloadParams{
+			UserID:    userID,
+			MessageID: job.MessageID,
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "inbox_message", "id", job.MessageID.String(), logger, "load push payload")
+		}
+		notifications = append(notifications, buildNotification(payload))
+	}
+
+	sendErr := s.deliver(ctx, userID, buildDigest(notifications))
+	if sendErr != nil {
+		logger.Warn("Failed to send push digest", zap.Error(sendErr), zap.String("user_id", userID.String()))
+		err = s.queries.RecordDigestFailure(ctx, RecordDigestFailureParams{
+			LastError: pgtype.Text{String: sendErr.Error(), Valid: true},
+			Ids:       ids,
+		})
+	} else {
+		err = s.queries.MarkJobsSent(ctx, ids)
+	}
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "push_jobs", "user_id", userID.String(), logger, "record push digest result")
+	}
+
+	return nil
+}
+
+// buildDigest summarizes the notifications of a digest period. A single notification
+// is sent as it is.
+func buildDigest(notifications []Notification) Notification {
+	if len(notifications) == 1 {
+		return notifications[0]
+	}
+
+	lines := make([]string, 0, digestPreviewCount+1)
+	for i, notification := range notifications {
+		if i == digestPreviewCount {
+			lines = append(lines, fmt.Sprintf("and %d more", len(notifications)-digestPreviewCount))
+			break
+		}
+		lines = append(lines, notification.Title+": "+notification.Body)
+	}
+
+	return Notification{
+		Title: fmt.Sprintf("%d new inbox messages", len(notifications)),
+		Body:  strings.Join(lines, "\n"),
+		URL:   "/inbox",
+	}
+}
+
+// send delivers a job to the devices of its user
+func (s *Service) send(ctx context.Context, job PushJob) error {
+	payload, err := s.queries.GetPayload(ctx, GetPayloadParams{
+		UserID:    job.UserID,
+		MessageID: job.MessageID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load push payload: %w", err)
+	}
+
+	return s.deliver(ctx, job.UserID, buildNotification(payload))
+}
+
+// deliver sends a notification to every device of the user. It succeeds when at least
+// one device received the notification or when the user has no device left.
+func (s *Service) deliver(ctx context.Context, userID uuid.UUID, notification Notification) error {
+	logger := logutil.WithContext(ctx, s.logger)
+
+	devices, err := s.queries.ListDevicesByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list push devices: %w", err)
+	}
+
+	var errs []error
+	delivered := false
+	for _, device := range devices {
+		sender, ok := s.senders[device.Platform]
+		if !ok {
+			continue
+		}
+
+		err = sender.Send(ctx, device, notification)
+		if errors.Is(err, ErrDeviceGone) {
+			logger.Info("Removing expired push device", zap.String("device_id", device.ID.String()))
+			err = s.queries.DeleteDeviceByID(ctx, device.ID)
+		} else if err == nil {
+			delivered = true
+			err = s.queries.TouchDevice(ctx, device.ID)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if delivered {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+func buildNotification(payload GetPayloadRow) Notification {
+	notification := Notification{
+		MessageID: payload.ID.String(),
+		URL:       "/inbox",
+	}
+	if payload.InboxID.Valid {
+		notification.InboxID = uuid.UUID(payload.InboxID.Bytes).String()
+		notification.URL = "/inbox/" + notification.InboxID
+	}
+
+	switch payload.Type {
+	case ContentTypeForm:
+		notification.Title = payload.UnitName.String
+		notification.Body = payload.FormTitle.String
+	default:
+		notification.Title = payload.SenderName.String
+		if notification.Title == "" {
+			notification.Title = payload.UnitName.String
+		}
+		notification.Body = payload.Body.String
+	}
+
+	if len([]rune(notification.Body)) > maxBodyLength {
+		notification.Body = string([]rune(notification.Body)[:maxBodyLength-1]) + "…"
+	}
+
+	return notification
+}
+
+// PendingJobs counts the push jobs waiting to be sent, retries included
+func (s *Service) PendingJobs(ctx context.Context) (int64, error) {
+	count, err := s.queries.CountPendingJobs(ctx)
+	if err != nil {
+		return 0, databaseutil.WrapDBError(err, s.logger, "count pending push jobs")
+	}
+	return count, nil
+}
@@ -0,0 +1,226 @@
+package push
+
+import (
+	"NYCU-SDC/core-system-backend/internal/egress"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// webPushTTL is how long the push service keeps a notification for an offline device
+	webPushTTL = 24 * time.Hour
+
+	vapidTokenExpiration = 12 * time.Hour
+
+	// sendTimeout bounds a delivery to the push service of a browser
+	sendTimeout = 30 * time.Second
+
+	// recordSize is the aes128gcm record size; payloads always fit in a single record
+	recordSize = 4096
+)
+
+// WebPush sends notifications through the push service of a browser subscription,
+// authenticated with VAPID (RFC 8292) and encrypted with aes128gcm (RFC 8291)
+type WebPush struct {
+	client     *http.Client
+	subject    string
+	publicKey  string
+	privateKey *ecdsa.PrivateKey
+}
+
+// NewWebPush parses a VAPID key pair given as unpadded base64url strings, the
+// format used by browsers and the web-push tooling
+func NewWebPush(publicKey string, privateKey string, subject string) (*WebPush, error) {
+	rawPrivate, err := base64.RawURLEncoding.DecodeString(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vapid private key: %w", err)
+	}
+
+	derivedPublic := key.PublicKey().Bytes()
+	if base64.RawURLEncoding.EncodeToString(derivedPublic) != publicKey {
+		return nil, fmt.Errorf("vapid public key does not match the private key")
+	}
+
+	// The uncompressed point is 0x04 || X || Y
+	signingKey := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(derivedPublic[1:33]),
+			Y:     new(big.Int).SetBytes(derivedPublic[33:]),
+		},
+		D: new(big.Int).SetBytes(rawPrivate),
+	}
+
+	return &WebPush{
+		client:     egress.NewClient(sendTimeout),
+		subject:    subject,
+		publicKey:  publicKey,
+		privateKey: signingKey,
+	}, nil
+}
+
+func (w *WebPush) Send(ctx context.Context, device PushDevice, notification Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	body, err := encryptPayload(payload, device.P256dh.String, device.Auth.String)
+	if err != nil {
+		return err
+	}
+
+	token, err := w.vapidToken(device.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, device.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create web push request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, w.publicKey))
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", fmt.Sprintf("%d", int(webPushTTL.Seconds())))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send web push: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrDeviceGone
+	case resp.StatusCode >= 300:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("web push service returned %s: %s", resp.Status, message)
+	}
+
+	return nil
+}
+
+// vapidToken signs the JWT identifying this server to the push service of the endpoint
+func (w *WebPush) vapidToken(endpoint string) (string, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid web push endpoint: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpointURL.Scheme + "://" + endpointURL.Host,
+		"exp": time.Now().Add(vapidTokenExpiration).Unix(),
+		"sub": w.subject,
+	})
+
+	signed, err := token.SignedString(w.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign vapid token: %w", err)
+	}
+	return signed, nil
+}
+
+// encryptPayload encrypts the payload for the subscription keys of a browser as a
+// single aes128gcm record
+func encryptPayload(payload []byte, p256dh string, authSecret string) ([]byte, error) {
+	rawSubscriber, err := base64.RawURLEncoding.DecodeString(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	subscriberKey, err := ecdh.P256().NewPublicKey(rawSubscriber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	auth, err := base64.RawURLEncoding.DecodeString(authSecret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	sharedSecret, err := serverKey.ECDH(subscriberKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+	}
+	serverPublic := serverKey.PublicKey().Bytes()
+
+	salt := make([]byte, 16)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	// RFC 8291 section 3.4
+	keyInfo := "WebPush: info\x00" + string(rawSubscriber) + string(serverPublic)
+	prkKey, err := hkdf.Extract(sha256.New, sharedSecret, auth)
+	if err != nil {
+		return nil, err
+	}
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 is the padding delimiter of the last record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	if len(plaintext)+gcm.Overhead() > recordSize {
+		return nil, fmt.Errorf("notification payload of %d bytes is too large", len(payload))
+	}
+
+	header := make([]byte, 0, 16+4+1+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
@@ -0,0 +1,54 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const (
+	// DefaultScale is the side of a module in pixels
+	DefaultScale = 8
+
+	// MaxScale bounds the scale a caller may ask for
+	MaxScale = 32
+
+	// quietZone is the light border, in modules, scanners need around the code
+	quietZone = 4
+)
+
+// PNG renders the code with the quiet zone, each module scale pixels wide
+func (c *Code) PNG(scale int) ([]byte, error) {
+	side := (c.Size + 2*quietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := range c.Size {
+		for x := range c.Size {
+			if !c.Dark(x, y) {
+				continue
+			}
+			for dy := range scale {
+				row := (quietZone+y)*scale + dy
+				for dx := range scale {
+					img.SetColorIndex((quietZone+x)*scale+dx, row, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodePNG renders text as a PNG QR code
+func EncodePNG(text string, scale int) ([]byte, error) {
+	code, err := Encode(text)
+	if err != nil {
+		return nil, err
+	}
+	return code.PNG(scale)
+}
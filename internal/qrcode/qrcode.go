@@ -0,0 +1,457 @@
+// Package qrcode encodes text as a QR code in byte mode with error correction level M,
+// the level most scanners are tuned for, and renders it as a PNG image.
+package qrcode
+
+import (
+	"errors"
+)
+
+// ErrTooLong is returned for text that does not fit in the largest supported version
+var ErrTooLong = errors.New("text is too long for a QR code")
+
+// levelM is the two bits the format information gives error correction level M
+const levelM = 0b00
+
+// version holds the block structure of one QR version at level M
+type version struct {
+	ecPerBlock int
+	// blocks lists the data codewords of each block; the longer blocks come last
+	blocks    []int
+	alignment []int
+}
+
+// versions covers version 1 to 10, up to 213 bytes, which fits any link this service
+// hands out
+var versions = []version{
+	1:  {10, []int{16}, nil},
+	2:  {16, []int{28}, []int{6, 18}},
+	3:  {26, []int{44}, []int{6, 22}},
+	4:  {18, []int{32, 32}, []int{6, 26}},
+	5:  {24, []int{43, 43}, []int{6, 30}},
+	6:  {16, []int{27, 27, 27, 27}, []int{6, 34}},
+	7:  {18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	8:  {22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	9:  {22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	10: {26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+func (v version) dataCodewords() int {
+	total := 0
+	for _, n := range v.blocks {
+		total += n
+	}
+	return total
+}
+
+// Code is the module matrix of a QR code, without the quiet zone
+type Code struct {
+	Size    int
+	modules [][]bool
+	// reserved marks the function patterns, which data and masks leave alone
+	reserved [][]bool
+}
+
+// Dark reports whether the module in column x of row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.reserved[y][x] = true
+}
+
+// Encode builds the QR code of text in the smallest version it fits
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	number := 0
+	for n := 1; n < len(versions); n++ {
+		countBits := 8
+		if n >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= 8*versions[n].dataCodewords() {
+			number = n
+			break
+		}
+	}
+	if number == 0 {
+		return nil, ErrTooLong
+	}
+	v := versions[number]
+
+	size := 17 + 4*number
+	code := &Code{Size: size, modules: make([][]bool, size), reserved: make([][]bool, size)}
+	for y := range size {
+		code.modules[y] = make([]bool, size)
+		code.reserved[y] = make([]bool, size)
+	}
+
+	code.drawFunctionPatterns(number, v)
+	code.drawCodewords(interleave(v, encodeData(number, v, data)))
+
+	best, bestPenalty := 0, -1
+	for mask := range 8 {
+		code.applyMask(mask)
+		code.drawFormat(mask)
+		penalty := code.penalty()
+		if bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		code.applyMask(mask)
+	}
+	code.applyMask(best)
+	code.drawFormat(best)
+
+	return code, nil
+}
+
+// encodeData writes the byte mode segment with its terminator and padding
+func encodeData(number int, v version, data []byte) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	if number >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := 8 * v.dataCodewords()
+	bits.append(0, min(4, capacity-bits.len()))
+	bits.append(0, (8-bits.len()%8)%8)
+	for pad := 0xEC; bits.len() < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	return bits.bytes()
+}
+
+// interleave splits the data into blocks, adds the error correction of each and
+// interleaves the codewords of the blocks
+func interleave(v version, data []byte) []byte {
+	generator := rsGenerator(v.ecPerBlock)
+
+	var blocks, ecBlocks [][]byte
+	longest := 0
+	for _, n := range v.blocks {
+		block := data[:n]
+		data = data[n:]
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, rsRemainder(block, generator))
+		longest = max(longest, n)
+	}
+
+	var result []byte
+	for i := range longest {
+		for _, block := range blocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := range v.ecPerBlock {
+		for _, ec := range ecBlocks {
+			result = append(result, ec[i])
+		}
+	}
+	return result
+}
+
+func (c *Code) drawFunctionPatterns(number int, v version) {
+	for i := range c.Size {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	for _, corner := range [][2]int{{3, 3}, {c.Size - 4, 3}, {3, c.Size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x < 0 || x >= c.Size || y < 0 || y >= c.Size {
+					continue
+				}
+				distance := max(abs(dx), abs(dy))
+				c.set(x, y, distance != 2 && distance != 4)
+			}
+		}
+	}
+
+	last := len(v.alignment) - 1
+	for i, y := range v.alignment {
+		for j, x := range v.alignment {
+			// the corners taken by the finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// reserved until the mask is chosen
+	c.drawFormat(0)
+
+	if number >= 7 {
+		remainder := number
+		for range 12 {
+			remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+		}
+		bits := number<<12 | remainder
+		for i := range 18 {
+			dark := (bits>>i)&1 != 0
+			a, b := c.Size-11+i%3, i/3
+			c.set(a, b, dark)
+			c.set(b, a, dark)
+		}
+	}
+}
+
+// drawFormat writes both copies of the format information for level M and the mask
+func (c *Code) drawFormat(mask int) {
+	data := levelM<<3 | mask
+	remainder := data
+	for range 10 {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	for i := range 6 {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true)
+}
+
+// drawCodewords places the codewords in the zigzag order of the standard, two columns
+// at a time from the bottom right; the remainder bits are left light
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vertical := range c.Size {
+			y := vertical
+			if upward {
+				y = c.Size - 1 - vertical
+			}
+			for j := range 2 {
+				x := right - j
+				if c.reserved[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y][x] = (codewords[i>>3]>>(7-i&7))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by the mask; applying it twice undoes it
+func (c *Code) applyMask(mask int) {
+	for y := range c.Size {
+		for x := range c.Size {
+			if c.reserved[y][x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to scan, following the four rules of the
+// standard: long runs, 2x2 blocks, finder-like patterns and an unbalanced share of
+// dark modules
+func (c *Code) penalty() int {
+	penalty := 0
+	finderLike := []bool{true, false, true, true, true, false, true}
+
+	line := make([]bool, c.Size)
+	for _, vertical := range []bool{false, true} {
+		for a := range c.Size {
+			for b := range c.Size {
+				if vertical {
+					line[b] = c.modules[b][a]
+				} else {
+					line[b] = c.modules[a][b]
+				}
+			}
+
+			run := 1
+			for b := 1; b <= c.Size; b++ {
+				if b < c.Size && line[b] == line[b-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += 3 + run - 5
+				}
+				run = 1
+			}
+
+			for b := 0; b+7 <= c.Size; b++ {
+				match := true
+				for k, dark := range finderLike {
+					if line[b+k] != dark {
+						match = false
+						break
+					}
+				}
+				if match && (lightRun(line, b-4, b) || lightRun(line, b+7, b+11)) {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := range c.Size {
+		for x := range c.Size {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				color := c.modules[y][x]
+				if c.modules[y][x+1] == color && c.modules[y+1][x] == color && c.modules[y+1][x+1] == color {
+					penalty += 3
+				}
+			}
+		}
+	}
+	total := c.Size * c.Size
+	penalty += 10 * (abs(dark*20-total*10) / total)
+
+	return penalty
+}
+
+// lightRun reports whether the modules from start to end are all light, those outside
+// the code counting as light
+func lightRun(line []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+type bitBuffer struct {
+	bits []bool
+}
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		b.bits = append(b.bits, (value>>i)&1 != 0)
+	}
+}
+
+func (b *bitBuffer) len() int {
+	return len(b.bits)
+}
+
+func (b *bitBuffer) bytes() []byte {
+	result := make([]byte, len(b.bits)/8)
+	for i, bit := range b.bits {
+		if bit {
+			result[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(256) modulo the polynomial 0x11D of the standard
+func gfMultiply(a, b byte) byte {
+	var product byte
+	for i := 7; i >= 0; i-- {
+		carry := product & 0x80
+		product <<= 1
+		if carry != 0 {
+			product ^= 0x1D
+		}
+		if (b>>i)&1 != 0 {
+			product ^= a
+		}
+	}
+	return product
+}
+
+// rsGenerator returns the coefficients of the Reed-Solomon generator polynomial of
+// the degree, highest first, the leading 1 left out
+func rsGenerator(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range degree {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the error correction codewords of data
+func rsRemainder(data []byte, generator []byte) []byte {
+	result := make([]byte, len(generator))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range generator {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
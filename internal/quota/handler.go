@@ -0,0 +1,129 @@
+package quota
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]Usage, []QuotaWarning, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+// UsageResponse reports a quota; limit is omitted for an unlimited resource
+type UsageResponse struct {
+	Resource string `json:"resource"`
+	Used     int32  `json:"used"`
+	Limit    *int32 `json:"limit,omitempty"`
+	Percent  int32  `json:"percent"`
+}
+
+type WarningResponse struct {
+	ID        string    `json:"id"`
+	Resource  string    `json:"resource"`
+	Threshold int32     `json:"threshold"`
+	Usage     int32     `json:"usage"`
+	Limit     int32     `json:"limit"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Response struct {
+	Quotas   []UsageResponse   `json:"quotas"`
+	Warnings []WarningResponse `json:"warnings"`
+}
+
+func ToResponse(usages []Usage, warnings []QuotaWarning) Response {
+	response := Response{
+		Quotas:   make([]UsageResponse, len(usages)),
+		Warnings: make([]WarningResponse, len(warnings)),
+	}
+	for i, usage := range usages {
+		response.Quotas[i] = UsageResponse{
+			Resource: string(usage.Resource),
+			Used:     usage.Used,
+			Percent:  usage.Percent(),
+		}
+		if usage.Limit > 0 {
+			limit := usage.Limit
+			response.Quotas[i].Limit = &limit
+		}
+	}
+	for i, warning := range warnings {
+		response.Warnings[i] = WarningResponse{
+			ID:        warning.ID.String(),
+			Resource:  string(warning.Resource),
+			Threshold: warning.Threshold,
+			Usage:     warning.Usage,
+			Limit:     warning.QuotaLimit,
+			CreatedAt: warning.CreatedAt.Time,
+		}
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(logger *zap.Logger, problemWriter *problem.HttpWriter, store Store, tenantStore tenantStore) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("quota/handler"),
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	slug, err := internal.GetSlugFromContext(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org slug from context: %w", err), logger)
+		return
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(traceCtx, slug)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org ID by slug: %w", err), logger)
+		return
+	}
+
+	usages, warnings, err := h.store.List(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(usages, warnings))
+}
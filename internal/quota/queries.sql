@@ -0,0 +1,33 @@
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: GetOrgOwner :one
+SELECT owner_id FROM tenants WHERE id = @org_id;
+
+-- name: GetLimit :one
+SELECT quota_limit FROM org_quotas
+WHERE org_id = @org_id AND resource = @resource;
+
+-- name: CountForms :one
+SELECT COUNT(*) FROM forms f
+JOIN units u ON u.id = f.unit_id
+WHERE u.id = @org_id OR u.org_id = @org_id;
+
+-- name: CountMembers :one
+SELECT COUNT(*) FROM unit_members
+WHERE unit_id = @org_id AND (valid_until IS NULL OR valid_until > now());
+
+-- name: CreateWarning :one
+INSERT INTO quota_warnings (org_id, resource, threshold, usage, quota_limit)
+VALUES (@org_id, @resource, @threshold, @usage, @quota_limit)
+ON CONFLICT (org_id, resource, threshold) WHERE resolved_at IS NULL DO NOTHING
+RETURNING *;
+
+-- name: ResolveWarnings :exec
+UPDATE quota_warnings SET resolved_at = now()
+WHERE org_id = @org_id AND resource = @resource AND threshold > @usage_percent AND resolved_at IS NULL;
+
+-- name: ListWarnings :many
+SELECT * FROM quota_warnings
+WHERE org_id = @org_id AND resolved_at IS NULL
+ORDER BY resource ASC, threshold ASC;
\ No newline at end of file
@@ -0,0 +1,10 @@
+package quota
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the quotas of an organization as their admins see them
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/quotas", route.TenantAuthenticated, route.PermissionOrgAdmin, h.ListHandler)
+}
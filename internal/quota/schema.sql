@@ -0,0 +1,22 @@
+CREATE TYPE quota_resource AS ENUM ('forms', 'members');
+
+CREATE TABLE IF NOT EXISTS org_quotas (
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    resource quota_resource NOT NULL,
+    quota_limit INTEGER NOT NULL CHECK (quota_limit >= 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (org_id, resource)
+);
+
+CREATE TABLE IF NOT EXISTS quota_warnings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id UUID NOT NULL REFERENCES units(id) ON DELETE CASCADE,
+    resource quota_resource NOT NULL,
+    threshold INTEGER NOT NULL,
+    usage INTEGER NOT NULL,
+    quota_limit INTEGER NOT NULL,
+    resolved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quota_warnings_active ON quota_warnings(org_id, resource, threshold) WHERE resolved_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,282 @@
+package quota
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"context"
+	"errors"
+	"fmt"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Resources are the resources under quota, in the order they are reported
+var Resources = []QuotaResource{QuotaResourceForms, QuotaResourceMembers}
+
+// Thresholds are the shares of a quota, in percent, at which the org admins are warned
+var Thresholds = []int32{80, 90, 100}
+
+// Config holds the quotas of organizations without one of their own in org_quotas;
+// zero leaves the resource unlimited
+type Config struct {
+	Forms   int32 `yaml:"forms"   envconfig:"QUOTA_FORMS"`
+	Members int32 `yaml:"members" envconfig:"QUOTA_MEMBERS"`
+}
+
+func (c *Config) Validate() error {
+	if c.Forms < 0 || c.Members < 0 {
+		return fmt.Errorf("quotas cannot be negative")
+	}
+	return nil
+}
+
+func (c *Config) limit(resource QuotaResource) int32 {
+	switch resource {
+	case QuotaResourceForms:
+		return c.Forms
+	case QuotaResourceMembers:
+		return c.Members
+	}
+	return 0
+}
+
+type Querier interface {
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	GetOrgOwner(ctx context.Context, orgID uuid.UUID) (pgtype.UUID, error)
+	GetLimit(ctx context.Context, arg GetLimitParams) (int32, error)
+	CountForms(ctx context.Context, orgID uuid.UUID) (int64, error)
+	CountMembers(ctx context.Context, orgID uuid.UUID) (int64, error)
+	CreateWarning(ctx context.Context, arg CreateWarningParams) (QuotaWarning, error)
+	ResolveWarnings(ctx context.Context, arg ResolveWarningsParams) error
+	ListWarnings(ctx context.Context, orgID uuid.UUID) ([]QuotaWarning, error)
+}
+
+type InboxStore interface {
+	Create(ctx context.Context, contentType inbox.ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error)
+}
+
+// Usage is how much of a quota an organization uses; Limit is zero for an unlimited
+// resource
+type Usage struct {
+	Resource QuotaResource
+	Used     int32
+	Limit    int32
+}
+
+// Percent is the share of the quota in use, rounded down
+func (u Usage) Percent() int32 {
+	if u.Limit == 0 {
+		return 0
+	}
+	return int32(int64(u.Used) * 100 / int64(u.Limit))
+}
+
+type Service struct {
+	logger     *zap.Logger
+	queries    Querier
+	tracer     trace.Tracer
+	defaults   Config
+	inboxStore InboxStore
+}
+
+func NewService(logger *zap.Logger, db DBTX, defaults Config, inboxStore InboxStore) *Service {
+	return &Service{
+		logger:     logger,
+		queries:    New(db),
+		tracer:     otel.Tracer("quota/service"),
+		defaults:   defaults,
+		inboxStore: inboxStore,
+	}
+}
+
+// Guard binds the service to one resource, for the stores that create it
+func (s *Service) Guard(resource QuotaResource) Guard {
+	return Guard{service: s, resource: resource}
+}
+
+// List reports the usage of every quota of the organization and its unresolved
+// warnings. Only org admins may read them.
+func (s *Service) List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]Usage, []QuotaWarning, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	isAdmin, err := s.queries.IsOrgAdmin(traceCtx, IsOrgAdminParams{OrgID: orgID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check org admin")
+		span.RecordError(err)
+		return nil, nil, err
+	}
+	if !isAdmin {
+		span.RecordError(internal.ErrNotOrgAdmin)
+		return nil, nil, internal.ErrNotOrgAdmin
+	}
+
+	usages := make([]Usage, len(Resources))
+	for i, resource := range Resources {
+		usages[i], err = s.usage(traceCtx, logger, orgID, resource)
+		if err != nil {
+			span.RecordError(err)
+			return nil, nil, err
+		}
+	}
+
+	warnings, err := s.queries.ListWarnings(traceCtx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "quota_warnings", "org_id", orgID.String(), logger, "list quota warnings")
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	return usages, warnings, nil
+}
+
+// Check refuses to create another of the resource once the organization uses its
+// whole quota. Concurrent creations may pass it together, so the quota is a soft one
+// that can be overshot by a few.
+func (s *Service) Check(ctx context.Context, orgID uuid.UUID, resource QuotaResource) error {
+	traceCtx, span := s.tracer.Start(ctx, "Check")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	usage, err := s.usage(traceCtx, logger, orgID, resource)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	if usage.Limit > 0 && usage.Used >= usage.Limit {
+		err = fmt.Errorf("%w: %d of %d %s used", internal.ErrQuotaExceeded, usage.Used, usage.Limit, resource)
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// Observe warns the org admins through their inbox of each threshold the usage has
+// passed, once until the usage drops below it again. It runs after the resource is
+// created or removed, so failures are logged rather than returned.
+func (s *Service) Observe(ctx context.Context, orgID uuid.UUID, resource QuotaResource) {
+	traceCtx, span := s.tracer.Start(ctx, "Observe")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	usage, err := s.usage(traceCtx, logger, orgID, resource)
+	if err != nil {
+		span.RecordError(err)
+		logger.Warn("Failed to observe quota usage", zap.String("org_id", orgID.String()), zap.String("resource", string(resource)), zap.Error(err))
+		return
+	}
+	if usage.Limit == 0 {
+		return
+	}
+
+	percent := usage.Percent()
+	err = s.queries.ResolveWarnings(traceCtx, ResolveWarningsParams{OrgID: orgID, Resource: resource, UsagePercent: percent})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "quota_warnings", "org_id", orgID.String(), logger, "resolve quota warnings")
+		span.RecordError(err)
+		return
+	}
+
+	// Every threshold passed is recorded, but only the highest new one is announced,
+	// a jump from under 80 to 100 percent makes a single message
+	var latest *QuotaWarning
+	for _, threshold := range Thresholds {
+		if percent < threshold {
+			break
+		}
+
+		warning, err := s.queries.CreateWarning(traceCtx, CreateWarningParams{
+			OrgID:      orgID,
+			Resource:   resource,
+			Threshold:  threshold,
+			Usage:      usage.Used,
+			QuotaLimit: usage.Limit,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "quota_warnings", "org_id", orgID.String(), logger, "create quota warning")
+			span.RecordError(err)
+			return
+		}
+		latest = &warning
+	}
+	if latest == nil {
+		return
+	}
+
+	s.notifyAdmins(traceCtx, logger, *latest)
+}
+
+func (s *Service) notifyAdmins(ctx context.Context, logger *zap.Logger, warning QuotaWarning) {
+	ownerID, err := s.queries.GetOrgOwner(ctx, warning.OrgID)
+	if err != nil {
+		logger.Warn("Failed to get org owner for quota warning", zap.String("org_id", warning.OrgID.String()), zap.Error(err))
+		return
+	}
+	if !ownerID.Valid {
+		return
+	}
+
+	_, err = s.inboxStore.Create(ctx, inbox.ContentTypeQuota, warning.ID, []uuid.UUID{ownerID.Bytes}, warning.OrgID)
+	if err != nil {
+		logger.Warn("Failed to notify org admins of quota warning", zap.String("warning_id", warning.ID.String()), zap.Error(err))
+		return
+	}
+
+	logger.Info("Warned org admins of quota usage",
+		zap.String("org_id", warning.OrgID.String()),
+		zap.String("resource", string(warning.Resource)),
+		zap.Int32("threshold", warning.Threshold),
+	)
+}
+
+func (s *Service) usage(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, resource QuotaResource) (Usage, error) {
+	limit, err := s.queries.GetLimit(ctx, GetLimitParams{OrgID: orgID, Resource: resource})
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return Usage{}, databaseutil.WrapDBErrorWithKeyValue(err, "org_quotas", "org_id", orgID.String(), logger, "get org quota")
+		}
+		limit = s.defaults.limit(resource)
+	}
+
+	var used int64
+	switch resource {
+	case QuotaResourceForms:
+		used, err = s.queries.CountForms(ctx, orgID)
+	case QuotaResourceMembers:
+		used, err = s.queries.CountMembers(ctx, orgID)
+	default:
+		return Usage{}, fmt.Errorf("unknown quota resource %s", resource)
+	}
+	if err != nil {
+		return Usage{}, databaseutil.WrapDBErrorWithKeyValue(err, "org_quotas", "org_id", orgID.String(), logger, "count quota usage")
+	}
+
+	return Usage{Resource: resource, Used: int32(used), Limit: limit}, nil
+}
+
+// Guard checks and observes the quota of a single resource
+type Guard struct {
+	service  *Service
+	resource QuotaResource
+}
+
+func (g Guard) Check(ctx context.Context, orgID uuid.UUID) error {
+	return g.service.Check(ctx, orgID, g.resource)
+}
+
+func (g Guard) Observe(ctx context.Context, orgID uuid.UUID) {
+	g.service.Observe(ctx, orgID, g.resource)
+}
@@ -0,0 +1,173 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// channel is the Postgres channel every replica listens on
+const channel = "realtime_events"
+
+// maxPayload is the limit of Postgres on the payload of a notification, less some room
+const maxPayload = 7900
+
+// subscriberBuffer is how many events a slow subscriber may have pending before newer
+// ones are dropped for it
+const subscriberBuffer = 16
+
+// reconnectDelay is how long the listener waits before reconnecting after losing its connection
+const reconnectDelay = 5 * time.Second
+
+// Event is delivered to the subscribers of Topic. Data is small, a reference the client
+// loads through the API, as notifications are capped at 8000 bytes.
+type Event struct {
+	Topic string          `json:"topic"`
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type Execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// Hub fans events out to the subscribers of a topic on every replica. Publishing sends
+// a Postgres notification; each replica listens on a connection of its own and hands the
+// events to its local subscribers, so a stream gets the event whichever replica it is
+// connected to.
+type Hub struct {
+	logger      *zap.Logger
+	db          Execer
+	databaseURL string
+
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+func NewHub(logger *zap.Logger, db Execer, databaseURL string) *Hub {
+	return &Hub{
+		logger:      logger,
+		db:          db,
+		databaseURL: databaseURL,
+		subscribers: make(map[string]map[chan Event]struct{}),
+	}
+}
+
+// Publish sends the event to the subscribers of the topic on every replica
+func (h *Hub) Publish(ctx context.Context, topic string, eventType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode realtime event: %w", err)
+	}
+
+	payload, err := json.Marshal(Event{Topic: topic, Type: eventType, Data: raw})
+	if err != nil {
+		return fmt.Errorf("failed to encode realtime event: %w", err)
+	}
+	if len(payload) > maxPayload {
+		return fmt.Errorf("realtime event of %d bytes exceeds the limit of %d", len(payload), maxPayload)
+	}
+
+	_, err = h.db.Exec(ctx, "SELECT pg_notify($1, $2)", channel, string(payload))
+	if err != nil {
+		return fmt.Errorf("failed to publish realtime event: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe returns the events of the topic published from now on, and the function to
+// call once the subscriber is gone
+func (h *Hub) Subscribe(topic string) (<-chan Event, func()) {
+	events := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[topic] == nil {
+		h.subscribers[topic] = make(map[chan Event]struct{})
+	}
+	h.subscribers[topic][events] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[topic], events)
+			if len(h.subscribers[topic]) == 0 {
+				delete(h.subscribers, topic)
+			}
+			h.mu.Unlock()
+		})
+	}
+
+	return events, unsubscribe
+}
+
+// Start listens for events until the context is done, reconnecting when the connection
+// is lost. Events published while this replica is reconnecting are missed; streams are
+// expected to reload their state when they reconnect.
+func (h *Hub) Start(ctx context.Context) {
+	for {
+		err := h.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		h.logger.Warn("Lost the realtime listener connection, reconnecting", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (h *Hub) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, h.databaseURL)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+
+	_, err = conn.Exec(ctx, "LISTEN "+channel)
+	if err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event Event
+		err = json.Unmarshal([]byte(notification.Payload), &event)
+		if err != nil {
+			h.logger.Warn("Dropped malformed realtime event", zap.Error(err))
+			continue
+		}
+
+		h.dispatch(event)
+	}
+}
+
+func (h *Hub) dispatch(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for events := range h.subscribers[event.Topic] {
+		select {
+		case events <- event:
+		default:
+			h.logger.Debug("Dropped realtime event for a slow subscriber", zap.String("topic", event.Topic), zap.String("type", event.Type))
+		}
+	}
+}
@@ -0,0 +1,515 @@
+package resource
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Create(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, input Input, userID uuid.UUID) (Resource, error)
+	List(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID) ([]Resource, error)
+	Get(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (Resource, error)
+	Update(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input Input, userID uuid.UUID) (Resource, error)
+	Delete(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error
+	Availability(ctx context.Context, orgID uuid.UUID, id uuid.UUID, period Range) ([]Booking, error)
+	Book(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input BookingInput, userID uuid.UUID) (Booking, error)
+	GetBooking(ctx context.Context, orgID uuid.UUID, resourceID uuid.UUID, bookingID uuid.UUID, userID uuid.UUID) (Booking, error)
+	CancelBooking(ctx context.Context, orgID uuid.UUID, resourceID uuid.UUID, bookingID uuid.UUID, userID uuid.UUID) (Booking, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+type Request struct {
+	Name          string `json:"name" validate:"required,max=255"`
+	Kind          string `json:"kind" validate:"required,oneof=room equipment"`
+	Description   string `json:"description" validate:"max=10000"`
+	Location      string `json:"location" validate:"max=255"`
+	BookingFormID string `json:"bookingFormId" validate:"omitempty,uuid"`
+}
+
+type Response struct {
+	ID            string    `json:"id"`
+	UnitID        string    `json:"unitId"`
+	Name          string    `json:"name"`
+	Kind          string    `json:"kind"`
+	Description   string    `json:"description"`
+	Location      string    `json:"location"`
+	BookingFormID *string   `json:"bookingFormId,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+type BookingRequest struct {
+	StartsAt   time.Time `json:"startsAt" validate:"required"`
+	EndsAt     time.Time `json:"endsAt" validate:"required"`
+	Purpose    string    `json:"purpose" validate:"max=2000"`
+	ResponseID string    `json:"responseId" validate:"omitempty,uuid"`
+}
+
+type BookingResponse struct {
+	ID          string     `json:"id"`
+	ResourceID  string     `json:"resourceId"`
+	BookedBy    string     `json:"bookedBy"`
+	StartsAt    time.Time  `json:"startsAt"`
+	EndsAt      time.Time  `json:"endsAt"`
+	Purpose     string     `json:"purpose"`
+	ResponseID  *string    `json:"responseId,omitempty"`
+	Status      string     `json:"status"`
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// SlotResponse is a booking as shown on the availability calendar, without who took
+// it or why
+type SlotResponse struct {
+	ID       string    `json:"id"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	Status   string    `json:"status"`
+}
+
+func ToResponse(resource Resource) Response {
+	response := Response{
+		ID:          resource.ID.String(),
+		UnitID:      resource.UnitID.String(),
+		Name:        resource.Name,
+		Kind:        string(resource.Kind),
+		Description: resource.Description,
+		Location:    resource.Location,
+		CreatedAt:   resource.CreatedAt.Time,
+		UpdatedAt:   resource.UpdatedAt.Time,
+	}
+	if resource.BookingFormID.Valid {
+		formID := uuid.UUID(resource.BookingFormID.Bytes).String()
+		response.BookingFormID = &formID
+	}
+	return response
+}
+
+func ToBookingResponse(booking Booking) BookingResponse {
+	response := BookingResponse{
+		ID:         booking.ID.String(),
+		ResourceID: booking.ResourceID.String(),
+		BookedBy:   booking.BookedBy.String(),
+		StartsAt:   booking.StartsAt.Time,
+		EndsAt:     booking.EndsAt.Time,
+		Purpose:    booking.Purpose,
+		Status:     string(booking.Status),
+		CreatedAt:  booking.CreatedAt.Time,
+	}
+	if booking.ResponseID.Valid {
+		responseID := uuid.UUID(booking.ResponseID.Bytes).String()
+		response.ResponseID = &responseID
+	}
+	if booking.CancelledAt.Valid {
+		response.CancelledAt = &booking.CancelledAt.Time
+	}
+	return response
+}
+
+// ToInput converts the request; the form ID is expected to have passed validation
+func (r Request) ToInput() Input {
+	input := Input{
+		Name:        strings.TrimSpace(r.Name),
+		Kind:        ResourceKind(r.Kind),
+		Description: r.Description,
+		Location:    strings.TrimSpace(r.Location),
+	}
+	if r.BookingFormID != "" {
+		input.BookingFormID = uuid.MustParse(r.BookingFormID)
+	}
+	return input
+}
+
+// ToInput converts the request; the response ID is expected to have passed validation
+func (r BookingRequest) ToInput() BookingInput {
+	input := BookingInput{
+		StartsAt: r.StartsAt,
+		EndsAt:   r.EndsAt,
+		Purpose:  r.Purpose,
+	}
+	if r.ResponseID != "" {
+		input.ResponseID = uuid.MustParse(r.ResponseID)
+	}
+	return input
+}
+
+// parseRange reads the optional from and before query parameters, RFC 3339 times
+func parseRange(r *http.Request) (Range, error) {
+	var period Range
+	for name, target := range map[string]*time.Time{"from": &period.From, "before": &period.Before} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return Range{}, fmt.Errorf("%w: %s must be an RFC 3339 time", internal.ErrInvalidQueryParameter, name)
+		}
+		*target = parsed
+	}
+	return period, nil
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("resource/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// org resolves the organization of the slug
+func (h *Handler) org(ctx context.Context) (uuid.UUID, error) {
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return orgID, nil
+}
+
+// resource resolves the organization of the slug and the resource in the path
+func (h *Handler) resource(ctx context.Context, r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	orgID, err := h.org(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("resourceId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return orgID, id, nil
+}
+
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	resource, err := h.store.Create(traceCtx, orgID, unitID, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(resource))
+}
+
+// ListHandler lists the resources of the organization, of one unit when the unitId
+// query parameter is given
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, err := h.org(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var unitID uuid.UUID
+	if value := r.URL.Query().Get("unitId"); value != "" {
+		unitID, err = uuid.Parse(value)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: unitId must be a UUID", internal.ErrInvalidQueryParameter), logger)
+			return
+		}
+	}
+
+	resources, err := h.store.List(traceCtx, orgID, unitID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]Response, len(resources))
+	for i, resource := range resources {
+		response[i] = ToResponse(resource)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, id, err := h.resource(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	resource, err := h.store.Get(traceCtx, orgID, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(resource))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.resource(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	resource, err := h.store.Update(traceCtx, orgID, id, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(resource))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.resource(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, orgID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// AvailabilityHandler lists the slots taken on the resource between the from and before
+// query parameters
+func (h *Handler) AvailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "AvailabilityHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	orgID, id, err := h.resource(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	period, err := parseRange(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	bookings, err := h.store.Availability(traceCtx, orgID, id, period)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]SlotResponse, len(bookings))
+	for i, booking := range bookings {
+		response[i] = SlotResponse{
+			ID:       booking.ID.String(),
+			StartsAt: booking.StartsAt.Time,
+			EndsAt:   booking.EndsAt.Time,
+			Status:   string(booking.Status),
+		}
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) BookHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "BookHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.resource(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req BookingRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	booking, err := h.store.Book(traceCtx, orgID, id, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToBookingResponse(booking))
+}
+
+func (h *Handler) GetBookingHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetBookingHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.resource(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	bookingID, err := internal.ParseUUID(r.PathValue("bookingId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	booking, err := h.store.GetBooking(traceCtx, orgID, id, bookingID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToBookingResponse(booking))
+}
+
+func (h *Handler) CancelBookingHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CancelBookingHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, id, err := h.resource(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	bookingID, err := internal.ParseUUID(r.PathValue("bookingId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	booking, err := h.store.CancelBooking(traceCtx, orgID, id, bookingID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToBookingResponse(booking))
+}
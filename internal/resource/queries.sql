@@ -0,0 +1,111 @@
+-- name: GetMembership :one
+SELECT EXISTS(
+    SELECT 1 FROM unit_members
+    WHERE unit_id = u.id AND member_id = @user_id
+) AS is_member
+FROM units u
+WHERE u.id = @unit_id AND (u.id = @org_id OR u.org_id = @org_id);
+
+-- name: IsOrgMember :one
+SELECT EXISTS(
+    SELECT 1 FROM unit_members um
+    JOIN units u ON u.id = um.unit_id
+    WHERE um.member_id = @user_id AND (u.id = @org_id OR u.org_id = @org_id)
+);
+
+-- name: IsFormOfUnit :one
+SELECT EXISTS(SELECT 1 FROM forms WHERE id = @form_id AND unit_id = @unit_id);
+
+-- name: Create :one
+INSERT INTO resources (unit_id, name, kind, description, location, booking_form_id)
+VALUES (@unit_id, @name, @kind, @description, @location, @booking_form_id)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT r.* FROM resources r
+JOIN units u ON u.id = r.unit_id
+WHERE r.id = @id AND (u.id = @org_id OR u.org_id = @org_id);
+
+-- name: List :many
+-- Resources of every unit of the organization, narrowed to one unit when unit_id is given
+SELECT r.* FROM resources r
+JOIN units u ON u.id = r.unit_id
+WHERE (u.id = @org_id OR u.org_id = @org_id)
+  AND (sqlc.narg(unit_id)::uuid IS NULL OR r.unit_id = sqlc.narg(unit_id))
+ORDER BY r.name ASC;
+
+-- name: Update :one
+UPDATE resources
+SET name = @name,
+    kind = @kind,
+    description = @description,
+    location = @location,
+    booking_form_id = @booking_form_id,
+    updated_at = now()
+WHERE id = @id
+RETURNING *;
+
+-- name: Delete :exec
+DELETE FROM resources
+WHERE id = @id;
+
+-- name: Lock :exec
+-- Serializes the bookings of a resource so that two overlapping ones cannot both be taken
+SELECT id FROM resources
+WHERE id = @id
+FOR UPDATE;
+
+-- name: CountOverlapping :one
+-- Bookings holding part of the slot: neither cancelled nor rejected at an approval gate
+SELECT COUNT(*) FROM resource_bookings b
+WHERE b.resource_id = @resource_id
+  AND b.cancelled_at IS NULL
+  AND b.starts_at < @ends_at
+  AND b.ends_at > @starts_at
+  AND NOT EXISTS (
+      SELECT 1 FROM form_approvals a
+      WHERE a.response_id = b.response_id AND a.status = 'rejected'
+  );
+
+-- name: GetResponse :one
+SELECT form_id, submitted_by, submitted_at FROM form_responses
+WHERE id = @id AND NOT is_test;
+
+-- name: CreateBooking :one
+INSERT INTO resource_bookings (resource_id, booked_by, starts_at, ends_at, purpose, response_id)
+VALUES (@resource_id, @booked_by, @starts_at, @ends_at, @purpose, @response_id)
+RETURNING *;
+
+-- name: GetBooking :one
+SELECT b.*,
+       (CASE
+           WHEN b.cancelled_at IS NOT NULL THEN 'cancelled'
+           WHEN EXISTS (SELECT 1 FROM form_approvals a WHERE a.response_id = b.response_id AND a.status = 'rejected') THEN 'rejected'
+           WHEN EXISTS (SELECT 1 FROM form_approvals a WHERE a.response_id = b.response_id AND a.status = 'pending') THEN 'pending'
+           ELSE 'approved'
+       END)::text AS status
+FROM resource_bookings b
+WHERE b.id = @id AND b.resource_id = @resource_id;
+
+-- name: ListBookings :many
+-- The slots taken on the resource in the range, either end left open when null
+SELECT b.*,
+       (CASE
+           WHEN EXISTS (SELECT 1 FROM form_approvals a WHERE a.response_id = b.response_id AND a.status = 'pending') THEN 'pending'
+           ELSE 'approved'
+       END)::text AS status
+FROM resource_bookings b
+WHERE b.resource_id = @resource_id
+  AND b.cancelled_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM form_approvals a
+      WHERE a.response_id = b.response_id AND a.status = 'rejected'
+  )
+  AND (sqlc.narg(range_from)::timestamptz IS NULL OR b.ends_at > sqlc.narg(range_from))
+  AND (sqlc.narg(range_before)::timestamptz IS NULL OR b.starts_at < sqlc.narg(range_before))
+ORDER BY b.starts_at ASC;
+
+-- name: CancelBooking :exec
+UPDATE resource_bookings
+SET cancelled_at = now()
+WHERE id = @id AND resource_id = @resource_id AND cancelled_at IS NULL;
\ No newline at end of file
@@ -0,0 +1,18 @@
+package resource
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the rooms and equipment of the units of an organization and their bookings
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/resources", route.TenantAuthenticated, route.PermissionNone, h.ListHandler)
+	r.Handle("POST /orgs/{slug}/units/{id}/resources", route.TenantAuthenticated, route.PermissionUnitMember, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/resources/{resourceId}", route.TenantAuthenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /orgs/{slug}/resources/{resourceId}", route.TenantAuthenticated, route.PermissionUnitMember, h.UpdateHandler)
+	r.Handle("DELETE /orgs/{slug}/resources/{resourceId}", route.TenantAuthenticated, route.PermissionUnitMember, h.DeleteHandler)
+	r.Handle("GET /orgs/{slug}/resources/{resourceId}/bookings", route.TenantAuthenticated, route.PermissionNone, h.AvailabilityHandler)
+	r.Handle("POST /orgs/{slug}/resources/{resourceId}/bookings", route.TenantAuthenticated, route.PermissionUnitMember, h.BookHandler)
+	r.Handle("GET /orgs/{slug}/resources/{resourceId}/bookings/{bookingId}", route.TenantAuthenticated, route.PermissionSelf, h.GetBookingHandler)
+	r.Handle("POST /orgs/{slug}/resources/{resourceId}/bookings/{bookingId}/cancel", route.TenantAuthenticated, route.PermissionSelf, h.CancelBookingHandler)
+}
@@ -0,0 +1,597 @@
+package resource
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	GetMembership(ctx context.Context, arg GetMembershipParams) (bool, error)
+	IsOrgMember(ctx context.Context, arg IsOrgMemberParams) (bool, error)
+	IsFormOfUnit(ctx context.Context, arg IsFormOfUnitParams) (bool, error)
+	Create(ctx context.Context, arg CreateParams) (Resource, error)
+	GetByID(ctx context.Context, arg GetByIDParams) (Resource, error)
+	List(ctx context.Context, arg ListParams) ([]Resource, error)
+	Update(ctx context.Context, arg UpdateParams) (Resource, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	Lock(ctx context.Context, id uuid.UUID) error
+	CountOverlapping(ctx context.Context, arg CountOverlappingParams) (int64, error)
+	GetResponse(ctx context.Context, id uuid.UUID) (GetResponseRow, error)
+	CreateBooking(ctx context.Context, arg CreateBookingParams) (ResourceBooking, error)
+	GetBooking(ctx context.Context, arg GetBookingParams) (GetBookingRow, error)
+	ListBookings(ctx context.Context, arg ListBookingsParams) ([]ListBookingsRow, error)
+	CancelBooking(ctx context.Context, arg CancelBookingParams) error
+}
+
+// DB is the connection the service runs on; a booking is checked against the others
+// and taken in one transaction begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// BookingStatus is derived from the approvals of the response a booking points at.
+// Bookings of a resource without a booking form are approved as soon as they are taken.
+type BookingStatus string
+
+const (
+	BookingStatusPending   BookingStatus = "pending"
+	BookingStatusApproved  BookingStatus = "approved"
+	BookingStatusRejected  BookingStatus = "rejected"
+	BookingStatusCancelled BookingStatus = "cancelled"
+)
+
+// Input describes a resource. BookingFormID is uuid.Nil when bookings need no approval.
+type Input struct {
+	Name          string
+	Kind          ResourceKind
+	Description   string
+	Location      string
+	BookingFormID uuid.UUID
+}
+
+// BookingInput describes a booking request. ResponseID is the response the requester
+// submitted to the booking form of the resource, and is ignored when it has none.
+type BookingInput struct {
+	StartsAt   time.Time
+	EndsAt     time.Time
+	Purpose    string
+	ResponseID uuid.UUID
+}
+
+// Booking is a booking together with its current status
+type Booking struct {
+	ResourceBooking
+	Status BookingStatus
+}
+
+// Range bounds the bookings listed on the availability calendar of a resource; a zero
+// time leaves that end open
+type Range struct {
+	From   time.Time
+	Before time.Time
+}
+
+type Service struct {
+	logger  *zap.Logger
+	db      DB
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DB) *Service {
+	return &Service{
+		logger:  logger,
+		db:      db,
+		queries: New(db),
+		tracer:  otel.Tracer("resource/service"),
+	}
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+// isMember reports whether the user is a member of the unit. A unit outside the
+// organization is not found.
+func (s *Service) isMember(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID) (bool, error) {
+	isMember, err := s.queries.GetMembership(ctx, GetMembershipParams{
+		UserID: userID,
+		UnitID: unitID,
+		OrgID:  orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, internal.ErrUnitNotFound
+		}
+		return false, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", unitID.String(), logger, "check unit membership")
+	}
+	return isMember, nil
+}
+
+// requireMember allows the members of the unit owning the resources only
+func (s *Service) requireMember(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID) error {
+	isMember, err := s.isMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("%w: only members of unit %s can manage its resources", internal.ErrPermissionDenied, unitID)
+	}
+	return nil
+}
+
+// requireOrgMember allows the members of any unit of the organization, so clubs can
+// book the rooms of one another
+func (s *Service) requireOrgMember(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isMember, err := s.queries.IsOrgMember(ctx, IsOrgMemberParams{UserID: userID, OrgID: orgID})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "member_id", userID.String(), logger, "check organization member")
+	}
+	if !isMember {
+		return fmt.Errorf("%w: only members of organization %s can book its resources", internal.ErrPermissionDenied, orgID)
+	}
+	return nil
+}
+
+// validateForm checks that the booking form is a form of the unit owning the resource
+func (s *Service) validateForm(ctx context.Context, logger *zap.Logger, unitID uuid.UUID, formID uuid.UUID) (pgtype.UUID, error) {
+	if formID == uuid.Nil {
+		return pgtype.UUID{}, nil
+	}
+
+	ofUnit, err := s.queries.IsFormOfUnit(ctx, IsFormOfUnitParams{FormID: formID, UnitID: pgtype.UUID{Bytes: unitID, Valid: true}})
+	if err != nil {
+		return pgtype.UUID{}, databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check booking form unit")
+	}
+	if !ofUnit {
+		return pgtype.UUID{}, fmt.Errorf("%w: form %s", internal.ErrResourceFormInvalid, formID)
+	}
+
+	return pgtype.UUID{Bytes: formID, Valid: true}, nil
+}
+
+func (s *Service) get(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, id uuid.UUID) (Resource, error) {
+	resource, err := s.queries.GetByID(ctx, GetByIDParams{ID: id, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Resource{}, internal.ErrResourceNotFound
+		}
+		return Resource{}, databaseutil.WrapDBErrorWithKeyValue(err, "resources", "id", id.String(), logger, "get resource")
+	}
+	return resource, nil
+}
+
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, input Input, userID uuid.UUID) (Resource, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Resource{}, err
+	}
+
+	formID, err := s.validateForm(ctx, logger, unitID, input.BookingFormID)
+	if err != nil {
+		span.RecordError(err)
+		return Resource{}, err
+	}
+
+	resource, err := s.queries.Create(ctx, CreateParams{
+		UnitID:        unitID,
+		Name:          input.Name,
+		Kind:          input.Kind,
+		Description:   input.Description,
+		Location:      input.Location,
+		BookingFormID: formID,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "resources", "unit_id", unitID.String(), logger, "create resource")
+		span.RecordError(err)
+		return Resource{}, err
+	}
+
+	logger.Info("Created resource", zap.String("resource_id", resource.ID.String()), zap.String("unit_id", unitID.String()))
+
+	return resource, nil
+}
+
+// List returns the resources of the organization, of one of its units only when unitID
+// is not uuid.Nil
+func (s *Service) List(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID) ([]Resource, error) {
+	ctx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	resources, err := s.queries.List(ctx, ListParams{
+		OrgID:  orgID,
+		UnitID: pgtype.UUID{Bytes: unitID, Valid: unitID != uuid.Nil},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "resources", "org_id", orgID.String(), logger, "list resources")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (Resource, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	resource, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return Resource{}, err
+	}
+
+	return resource, nil
+}
+
+// Update replaces the resource. Bookings taken already keep the approvals they went
+// through when the booking form changes.
+func (s *Service) Update(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input Input, userID uuid.UUID) (Resource, error) {
+	ctx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	resource, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return Resource{}, err
+	}
+
+	err = s.requireMember(ctx, logger, orgID, resource.UnitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Resource{}, err
+	}
+
+	formID, err := s.validateForm(ctx, logger, resource.UnitID, input.BookingFormID)
+	if err != nil {
+		span.RecordError(err)
+		return Resource{}, err
+	}
+
+	resource, err = s.queries.Update(ctx, UpdateParams{
+		Name:          input.Name,
+		Kind:          input.Kind,
+		Description:   input.Description,
+		Location:      input.Location,
+		BookingFormID: formID,
+		ID:            id,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "resources", "id", id.String(), logger, "update resource")
+		span.RecordError(err)
+		return Resource{}, err
+	}
+
+	logger.Info("Updated resource", zap.String("resource_id", id.String()))
+
+	return resource, nil
+}
+
+// Delete removes the resource together with its bookings
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	resource, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.requireMember(ctx, logger, orgID, resource.UnitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	err = s.queries.Delete(ctx, id)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "resources", "id", id.String(), logger, "delete resource")
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted resource", zap.String("resource_id", id.String()))
+
+	return nil
+}
+
+// Availability returns the bookings holding a slot of the resource within the range,
+// the pending ones included, the earliest first
+func (s *Service) Availability(ctx context.Context, orgID uuid.UUID, id uuid.UUID, period Range) ([]Booking, error) {
+	ctx, span := s.tracer.Start(ctx, "Availability")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	_, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	rows, err := s.queries.ListBookings(ctx, ListBookingsParams{
+		ResourceID:  id,
+		RangeFrom:   pgtype.Timestamptz{Time: period.From, Valid: !period.From.IsZero()},
+		RangeBefore: pgtype.Timestamptz{Time: period.Before, Valid: !period.Before.IsZero()},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "resource_bookings", "resource_id", id.String(), logger, "list resource bookings")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	bookings := make([]Booking, len(rows))
+	for i, row := range rows {
+		bookings[i] = toBooking(GetBookingRow(row))
+	}
+
+	return bookings, nil
+}
+
+// Book requests the slot of the resource for the user. When the resource has a booking
+// form, the booking points at the response the user submitted to it and stays pending
+// until the approval gates of its workflow are passed; approvers decide through their
+// approval queue. A slot overlapping a booking that is neither cancelled nor rejected
+// cannot be taken. Only members of the organization may book.
+func (s *Service) Book(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input BookingInput, userID uuid.UUID) (Booking, error) {
+	ctx, span := s.tracer.Start(ctx, "Book")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	resource, err := s.get(ctx, logger, orgID, id)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	err = s.requireOrgMember(ctx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	if !input.EndsAt.After(input.StartsAt) || input.StartsAt.Before(time.Now()) {
+		err = internal.ErrBookingTimeInvalid
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	responseID, err := s.validateResponse(ctx, logger, resource, input.ResponseID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	var booking ResourceBooking
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		err := queries.Lock(ctx, id)
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "resources", "id", id.String(), logger, "lock resource")
+		}
+
+		overlapping, err := queries.CountOverlapping(ctx, CountOverlappingParams{
+			ResourceID: id,
+			StartsAt:   pgtype.Timestamptz{Time: input.StartsAt, Valid: true},
+			EndsAt:     pgtype.Timestamptz{Time: input.EndsAt, Valid: true},
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "resource_bookings", "resource_id", id.String(), logger, "count overlapping bookings")
+		}
+		if overlapping > 0 {
+			return internal.ErrBookingConflict
+		}
+
+		booking, err = queries.CreateBooking(ctx, CreateBookingParams{
+			ResourceID: id,
+			BookedBy:   userID,
+			StartsAt:   pgtype.Timestamptz{Time: input.StartsAt, Valid: true},
+			EndsAt:     pgtype.Timestamptz{Time: input.EndsAt, Valid: true},
+			Purpose:    input.Purpose,
+			ResponseID: responseID,
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "resource_bookings", "resource_id", id.String(), logger, "create resource booking")
+			if errors.Is(err, databaseutil.ErrUniqueViolation) {
+				return fmt.Errorf("%w: response %s is booked already", internal.ErrBookingResponseInvalid, input.ResponseID)
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	logger.Info("Booked resource",
+		zap.String("resource_id", id.String()),
+		zap.String("booking_id", booking.ID.String()),
+		zap.Time("starts_at", input.StartsAt))
+
+	result, err := s.getBooking(ctx, logger, id, booking.ID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	return result, nil
+}
+
+// validateResponse checks that the response is a submitted response of the user to the
+// booking form of the resource. Resources without a booking form take no response.
+func (s *Service) validateResponse(ctx context.Context, logger *zap.Logger, resource Resource, responseID uuid.UUID, userID uuid.UUID) (pgtype.UUID, error) {
+	if !resource.BookingFormID.Valid {
+		return pgtype.UUID{}, nil
+	}
+	if responseID == uuid.Nil {
+		return pgtype.UUID{}, fmt.Errorf("%w: resource %s is booked through form %s", internal.ErrBookingResponseInvalid, resource.ID, uuid.UUID(resource.BookingFormID.Bytes))
+	}
+
+	response, err := s.queries.GetResponse(ctx, responseID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, fmt.Errorf("%w: response %s not found", internal.ErrBookingResponseInvalid, responseID)
+		}
+		return pgtype.UUID{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "get booking response")
+	}
+	if response.FormID != resource.BookingFormID.Bytes || response.SubmittedBy != userID || !response.SubmittedAt.Valid {
+		return pgtype.UUID{}, fmt.Errorf("%w: response %s", internal.ErrBookingResponseInvalid, responseID)
+	}
+
+	return pgtype.UUID{Bytes: responseID, Valid: true}, nil
+}
+
+func (s *Service) getBooking(ctx context.Context, logger *zap.Logger, resourceID uuid.UUID, bookingID uuid.UUID) (Booking, error) {
+	row, err := s.queries.GetBooking(ctx, GetBookingParams{ID: bookingID, ResourceID: resourceID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Booking{}, internal.ErrBookingNotFound
+		}
+		return Booking{}, databaseutil.WrapDBErrorWithKeyValue(err, "resource_bookings", "id", bookingID.String(), logger, "get resource booking")
+	}
+
+	return toBooking(row), nil
+}
+
+// requireBooker allows the user who took the booking and the members of the unit
+// owning the resource
+func (s *Service) requireBooker(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, resource Resource, booking Booking, userID uuid.UUID) error {
+	if booking.BookedBy == userID {
+		return nil
+	}
+
+	isMember, err := s.isMember(ctx, logger, orgID, resource.UnitID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return fmt.Errorf("%w: booking %s belongs to another user", internal.ErrPermissionDenied, booking.ID)
+	}
+	return nil
+}
+
+func (s *Service) GetBooking(ctx context.Context, orgID uuid.UUID, resourceID uuid.UUID, bookingID uuid.UUID, userID uuid.UUID) (Booking, error) {
+	ctx, span := s.tracer.Start(ctx, "GetBooking")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	resource, err := s.get(ctx, logger, orgID, resourceID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	booking, err := s.getBooking(ctx, logger, resourceID, bookingID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	err = s.requireBooker(ctx, logger, orgID, resource, booking, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	return booking, nil
+}
+
+// CancelBooking frees the slot of the booking; the response it points at is kept
+func (s *Service) CancelBooking(ctx context.Context, orgID uuid.UUID, resourceID uuid.UUID, bookingID uuid.UUID, userID uuid.UUID) (Booking, error) {
+	ctx, span := s.tracer.Start(ctx, "CancelBooking")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	resource, err := s.get(ctx, logger, orgID, resourceID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	booking, err := s.getBooking(ctx, logger, resourceID, bookingID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	err = s.requireBooker(ctx, logger, orgID, resource, booking, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	err = s.queries.CancelBooking(ctx, CancelBookingParams{ID: bookingID, ResourceID: resourceID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "resource_bookings", "id", bookingID.String(), logger, "cancel resource booking")
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	logger.Info("Cancelled resource booking", zap.String("booking_id", bookingID.String()))
+
+	booking, err = s.getBooking(ctx, logger, resourceID, bookingID)
+	if err != nil {
+		span.RecordError(err)
+		return Booking{}, err
+	}
+
+	return booking, nil
+}
+
+func toBooking(row GetBookingRow) Booking {
+	return Booking{
+		ResourceBooking: ResourceBooking{
+			ID:          row.ID,
+			ResourceID:  row.ResourceID,
+			BookedBy:    row.BookedBy,
+			StartsAt:    row.StartsAt,
+			EndsAt:      row.EndsAt,
+			Purpose:     row.Purpose,
+			ResponseID:  row.ResponseID,
+			CancelledAt: row.CancelledAt,
+			CreatedAt:   row.CreatedAt,
+		},
+		Status: BookingStatus(row.Status),
+	}
+}
@@ -0,0 +1,334 @@
+package resource_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/resource"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// bookingsDB keeps one resource and its bookings in memory. approvals holds the status
+// of the approval gates of the responses bookings point at; a response without one has
+// passed its gates.
+type bookingsDB struct {
+	orgID     uuid.UUID
+	resource  resource.Resource
+	members   []uuid.UUID
+	responses map[uuid.UUID]resource.GetResponseRow
+	approvals map[uuid.UUID]resource.BookingStatus
+	bookings  []resource.ResourceBooking
+}
+
+func (db *bookingsDB) status(booking resource.ResourceBooking) resource.BookingStatus {
+	if booking.CancelledAt.Valid {
+		return resource.BookingStatusCancelled
+	}
+	if status, ok := db.approvals[booking.ResponseID.Bytes]; ok && booking.ResponseID.Valid {
+		return status
+	}
+	return resource.BookingStatusApproved
+}
+
+func (db *bookingsDB) Begin(context.Context) (pgx.Tx, error) {
+	return bookingsTx{db: db}, nil
+}
+
+func (db *bookingsDB) Exec(_ context.Context, sql string, _ ...interface{}) (pgconn.CommandTag, error) {
+	if strings.Contains(sql, "name: Lock") {
+		return pgconn.NewCommandTag("SELECT 1"), nil
+	}
+	return pgconn.CommandTag{}, errors.New("unexpected exec")
+}
+
+func (db *bookingsDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (db *bookingsDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	switch {
+	case strings.Contains(sql, "name: GetByID"):
+		if args[0].(uuid.UUID) != db.resource.ID || args[1].(uuid.UUID) != db.orgID {
+			return scanRow(func(...any) error { return pgx.ErrNoRows })
+		}
+		return resourceRow(db.resource)
+	case strings.Contains(sql, "name: IsOrgMember"):
+		userID := args[0].(uuid.UUID)
+		for _, member := range db.members {
+			if member == userID {
+				return boolRow(true)
+			}
+		}
+		return boolRow(false)
+	case strings.Contains(sql, "name: GetResponse"):
+		response, ok := db.responses[args[0].(uuid.UUID)]
+		if !ok {
+			return scanRow(func(...any) error { return pgx.ErrNoRows })
+		}
+		return scanRow(func(dest ...any) error {
+			*dest[0].(*uuid.UUID) = response.FormID
+			*dest[1].(*uuid.UUID) = response.SubmittedBy
+			*dest[2].(*pgtype.Timestamptz) = response.SubmittedAt
+			return nil
+		})
+	case strings.Contains(sql, "name: CountOverlapping"):
+		endsAt, startsAt := args[1].(pgtype.Timestamptz), args[2].(pgtype.Timestamptz)
+		var count int64
+		for _, booking := range db.bookings {
+			status := db.status(booking)
+			if status == resource.BookingStatusCancelled || status == resource.BookingStatusRejected {
+				continue
+			}
+			if booking.StartsAt.Time.Before(endsAt.Time) && booking.EndsAt.Time.After(startsAt.Time) {
+				count++
+			}
+		}
+		return scanRow(func(dest ...any) error {
+			*dest[0].(*int64) = count
+			return nil
+		})
+	case strings.Contains(sql, "name: CreateBooking"):
+		booking := resource.ResourceBooking{
+			ID:         uuid.New(),
+			ResourceID: args[0].(uuid.UUID),
+			BookedBy:   args[1].(uuid.UUID),
+			StartsAt:   args[2].(pgtype.Timestamptz),
+			EndsAt:     args[3].(pgtype.Timestamptz),
+			Purpose:    args[4].(string),
+			ResponseID: args[5].(pgtype.UUID),
+			CreatedAt:  pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		}
+		db.bookings = append(db.bookings, booking)
+		return bookingRow(booking, "")
+	case strings.Contains(sql, "name: GetBooking"):
+		for _, booking := range db.bookings {
+			if booking.ID == args[0].(uuid.UUID) && booking.ResourceID == args[1].(uuid.UUID) {
+				return bookingRow(booking, db.status(booking))
+			}
+		}
+		return scanRow(func(...any) error { return pgx.ErrNoRows })
+	}
+	return scanRow(func(...any) error { return errors.New("unexpected query") })
+}
+
+// bookingsTx runs the queries of a transaction straight on the bookings, the tests
+// never roll back a transaction that wrote
+type bookingsTx struct {
+	pgx.Tx
+	db *bookingsDB
+}
+
+func (tx bookingsTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx bookingsTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.db.Query(ctx, sql, args...)
+}
+
+func (tx bookingsTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.db.QueryRow(ctx, sql, args...)
+}
+
+func (tx bookingsTx) Commit(context.Context) error   { return nil }
+func (tx bookingsTx) Rollback(context.Context) error { return nil }
+
+type scanRow func(dest ...any) error
+
+func (r scanRow) Scan(dest ...any) error { return r(dest...) }
+
+func boolRow(value bool) scanRow {
+	return func(dest ...any) error {
+		*dest[0].(*bool) = value
+		return nil
+	}
+}
+
+func resourceRow(r resource.Resource) scanRow {
+	return func(dest ...any) error {
+		*dest[0].(*uuid.UUID) = r.ID
+		*dest[1].(*uuid.UUID) = r.UnitID
+		*dest[2].(*string) = r.Name
+		*dest[3].(*resource.ResourceKind) = r.Kind
+		*dest[4].(*string) = r.Description
+		*dest[5].(*string) = r.Location
+		*dest[6].(*pgtype.UUID) = r.BookingFormID
+		*dest[7].(*pgtype.Timestamptz) = r.CreatedAt
+		*dest[8].(*pgtype.Timestamptz) = r.UpdatedAt
+		return nil
+	}
+}
+
+// bookingRow scans a booking, followed by its status for GetBooking
+func bookingRow(booking resource.ResourceBooking, status resource.BookingStatus) scanRow {
+	return func(dest ...any) error {
+		*dest[0].(*uuid.UUID) = booking.ID
+		*dest[1].(*uuid.UUID) = booking.ResourceID
+		*dest[2].(*uuid.UUID) = booking.BookedBy
+		*dest[3].(*pgtype.Timestamptz) = booking.StartsAt
+		*dest[4].(*pgtype.Timestamptz) = booking.EndsAt
+		*dest[5].(*string) = booking.Purpose
+		*dest[6].(*pgtype.UUID) = booking.ResponseID
+		*dest[7].(*pgtype.Timestamptz) = booking.CancelledAt
+		*dest[8].(*pgtype.Timestamptz) = booking.CreatedAt
+		if len(dest) > 9 {
+			*dest[9].(*string) = string(status)
+		}
+		return nil
+	}
+}
+
+// slot runs tomorrow from the hour from to the hour to
+func slot(from int, to int) (time.Time, time.Time) {
+	day := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour)
+	return day.Add(time.Duration(from) * time.Hour), day.Add(time.Duration(to) * time.Hour)
+}
+
+func TestService_BookOverlap(t *testing.T) {
+	t.Parallel()
+
+	orgID, userID := uuid.New(), uuid.New()
+
+	type testCase struct {
+		name        string
+		from        int
+		to          int
+		cancelled   bool
+		rejected    bool
+		expectedErr error
+	}
+
+	// Every case books against an existing booking from 10:00 to 12:00
+	testCases := []testCase{
+		{name: "Same slot", from: 10, to: 12, expectedErr: internal.ErrBookingConflict},
+		{name: "Starts during the booking", from: 11, to: 13, expectedErr: internal.ErrBookingConflict},
+		{name: "Ends during the booking", from: 9, to: 11, expectedErr: internal.ErrBookingConflict},
+		{name: "Holds the booking", from: 9, to: 13, expectedErr: internal.ErrBookingConflict},
+		{name: "Right after the booking", from: 12, to: 13},
+		{name: "Right before the booking", from: 9, to: 10},
+		{name: "Same slot as a cancelled booking", from: 10, to: 12, cancelled: true},
+		{name: "Same slot as a rejected booking", from: 10, to: 12, rejected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			startsAt, endsAt := slot(10, 12)
+			existing := resource.ResourceBooking{
+				ID:          uuid.New(),
+				BookedBy:    uuid.New(),
+				StartsAt:    pgtype.Timestamptz{Time: startsAt, Valid: true},
+				EndsAt:      pgtype.Timestamptz{Time: endsAt, Valid: true},
+				CancelledAt: pgtype.Timestamptz{Time: time.Now(), Valid: tc.cancelled},
+				ResponseID:  pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			}
+			db := &bookingsDB{
+				orgID:     orgID,
+				resource:  resource.Resource{ID: uuid.New(), UnitID: uuid.New(), Name: "Club room"},
+				members:   []uuid.UUID{userID},
+				approvals: map[uuid.UUID]resource.BookingStatus{},
+			}
+			existing.ResourceID = db.resource.ID
+			if tc.rejected {
+				db.approvals[existing.ResponseID.Bytes] = resource.BookingStatusRejected
+			}
+			db.bookings = []resource.ResourceBooking{existing}
+			service := resource.NewService(zap.NewNop(), db)
+
+			startsAt, endsAt = slot(tc.from, tc.to)
+			booking, err := service.Book(context.Background(), orgID, db.resource.ID, resource.BookingInput{StartsAt: startsAt, EndsAt: endsAt}, userID)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.Len(t, db.bookings, 1)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, resource.BookingStatusApproved, booking.Status)
+			require.Len(t, db.bookings, 2)
+		})
+	}
+}
+
+func TestService_BookApprovalGate(t *testing.T) {
+	t.Parallel()
+
+	orgID, userID, formID := uuid.New(), uuid.New(), uuid.New()
+	submitted := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+
+	pendingResponse := uuid.New()
+	approvedResponse := uuid.New()
+	otherUsersResponse := uuid.New()
+	otherFormResponse := uuid.New()
+	draftResponse := uuid.New()
+	responses := map[uuid.UUID]resource.GetResponseRow{
+		pendingResponse:    {FormID: formID, SubmittedBy: userID, SubmittedAt: submitted},
+		approvedResponse:   {FormID: formID, SubmittedBy: userID, SubmittedAt: submitted},
+		otherUsersResponse: {FormID: formID, SubmittedBy: uuid.New(), SubmittedAt: submitted},
+		otherFormResponse:  {FormID: uuid.New(), SubmittedBy: userID, SubmittedAt: submitted},
+		draftResponse:      {FormID: formID, SubmittedBy: userID},
+	}
+
+	type testCase struct {
+		name           string
+		bookingForm    bool
+		responseID     uuid.UUID
+		userID         uuid.UUID
+		expectedErr    error
+		expectedStatus resource.BookingStatus
+	}
+
+	testCases := []testCase{
+		{name: "Resource without a booking form", userID: userID, expectedStatus: resource.BookingStatusApproved},
+		{name: "Response waiting for its approvers", bookingForm: true, responseID: pendingResponse, userID: userID, expectedStatus: resource.BookingStatusPending},
+		{name: "Response past its approval gates", bookingForm: true, responseID: approvedResponse, userID: userID, expectedStatus: resource.BookingStatusApproved},
+		{name: "No response", bookingForm: true, userID: userID, expectedErr: internal.ErrBookingResponseInvalid},
+		{name: "Unknown response", bookingForm: true, responseID: uuid.New(), userID: userID, expectedErr: internal.ErrBookingResponseInvalid},
+		{name: "Response of another user", bookingForm: true, responseID: otherUsersResponse, userID: userID, expectedErr: internal.ErrBookingResponseInvalid},
+		{name: "Response to another form", bookingForm: true, responseID: otherFormResponse, userID: userID, expectedErr: internal.ErrBookingResponseInvalid},
+		{name: "Response not submitted", bookingForm: true, responseID: draftResponse, userID: userID, expectedErr: internal.ErrBookingResponseInvalid},
+		{name: "Not a member of the organization", userID: uuid.New(), expectedErr: internal.ErrPermissionDenied},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &bookingsDB{
+				orgID:     orgID,
+				resource:  resource.Resource{ID: uuid.New(), UnitID: uuid.New(), Name: "Projector"},
+				members:   []uuid.UUID{userID},
+				responses: responses,
+				approvals: map[uuid.UUID]resource.BookingStatus{pendingResponse: resource.BookingStatusPending},
+			}
+			if tc.bookingForm {
+				db.resource.BookingFormID = pgtype.UUID{Bytes: formID, Valid: true}
+			}
+			service := resource.NewService(zap.NewNop(), db)
+
+			startsAt, endsAt := slot(10, 12)
+			booking, err := service.Book(context.Background(), orgID, db.resource.ID, resource.BookingInput{
+				StartsAt:   startsAt,
+				EndsAt:     endsAt,
+				ResponseID: tc.responseID,
+			}, tc.userID)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.Empty(t, db.bookings)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStatus, booking.Status)
+			require.Equal(t, tc.bookingForm, booking.ResponseID.Valid)
+		})
+	}
+}
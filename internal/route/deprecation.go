@@ -0,0 +1,93 @@
+package route
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Deprecation announces that a route or a response field is going away. Since is when
+// it was deprecated and is required; Sunset, when set, is when it stops working, and
+// Link points to the migration notes.
+type Deprecation struct {
+	Since  time.Time
+	Sunset time.Time
+	Link   string
+}
+
+func (d Deprecation) validate() error {
+	if d.Since.IsZero() {
+		return fmt.Errorf("deprecation requires a since date")
+	}
+	if !d.Sunset.IsZero() && !d.Sunset.After(d.Since) {
+		return fmt.Errorf("sunset must be after the deprecation")
+	}
+	return nil
+}
+
+// setHeaders writes the Deprecation header of RFC 9745 and the Sunset header of RFC 8594
+func (d Deprecation) setHeaders(header http.Header) {
+	header.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	if !d.Sunset.IsZero() {
+		header.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		header.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Link))
+	}
+}
+
+// Field is a response field clients should stop reading, named as clients see it,
+// e.g. unit.metadata
+type Field struct {
+	Name        string
+	Deprecation Deprecation
+}
+
+// deprecatedUsage counts the requests served through a deprecated surface. It goes to
+// the global meter provider and is created on first use, after the provider is set.
+var deprecatedUsage = sync.OnceValues(func() (metric.Int64Counter, error) {
+	return otel.Meter("internal/route").Int64Counter("http.server.deprecated.request.count",
+		metric.WithDescription("Requests served through deprecated routes and fields"),
+		metric.WithUnit("{request}"))
+})
+
+func recordDeprecatedUsage(ctx context.Context, surface string) {
+	counter, err := deprecatedUsage()
+	if err != nil {
+		return
+	}
+	counter.Add(context.WithoutCancel(ctx), 1, metric.WithAttributes(attribute.String("deprecated.surface", surface)))
+}
+
+// Deprecated announces the deprecation of this route on every response it serves
+func (r *Route) Deprecated(deprecation Deprecation) *Route {
+	r.Deprecation = &deprecation
+	return r
+}
+
+func deprecate(deprecation Deprecation, surface string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deprecation.setHeaders(w.Header())
+		recordDeprecatedUsage(r.Context(), surface)
+		next(w, r)
+	}
+}
+
+// DeprecateField announces on the response that it carries a deprecated field; call it
+// before writing the response. The names of the fields are listed in the
+// Deprecated-Fields header, and the headers of the first one win over later fields.
+func DeprecateField(w http.ResponseWriter, r *http.Request, field Field) {
+	header := w.Header()
+	if header.Get("Deprecation") == "" {
+		field.Deprecation.setHeaders(header)
+	}
+	header.Add("Deprecated-Fields", field.Name)
+	recordDeprecatedUsage(r.Context(), "field "+field.Name)
+}
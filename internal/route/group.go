@@ -0,0 +1,84 @@
+package route
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// APIPrefix is the root of the API; each version of it is mounted below, e.g. /api/v1
+	APIPrefix = "/api"
+	// V1 is the prefix of the first version of the API
+	V1 = APIPrefix + "/v1"
+)
+
+// Router declares routes. Modules declare their endpoints on a Router without knowing
+// the prefix they are mounted under, so several versions of the API can coexist.
+type Router interface {
+	Handle(pattern string, access Access, permission Permission, handler http.HandlerFunc) *Route
+}
+
+// Group declares its routes on the registry under a path prefix
+type Group struct {
+	registry *Registry
+	prefix   string
+}
+
+// Group returns a router declaring its routes under prefix, e.g. route.V1
+func (r *Registry) Group(prefix string) *Group {
+	return &Group{
+		registry: r,
+		prefix:   strings.TrimSuffix(prefix, "/"),
+	}
+}
+
+// Group returns a router declaring its routes under prefix, below the prefix of g
+func (g *Group) Group(prefix string) *Group {
+	return g.registry.Group(g.prefix + prefix)
+}
+
+// Handle declares a route; pattern is "METHOD /path" with the path relative to the group
+func (g *Group) Handle(pattern string, access Access, permission Permission, handler http.HandlerFunc) *Route {
+	method, path, _ := strings.Cut(pattern, " ")
+	if method == "" || !strings.HasPrefix(path, "/") {
+		// Left as is for Mux to report
+		return g.registry.Handle(pattern, access, permission, handler)
+	}
+	return g.registry.Handle(method+" "+g.prefix+path, access, permission, handler)
+}
+
+// alias serves the requests under legacy from the routes under version
+type alias struct {
+	legacy  string
+	version string
+}
+
+// Alias serves the paths under legacy that no route matches from the routes under
+// version, e.g. /api/forms from /api/v1/forms, for the clients written before the API
+// was versioned. Routes declared under legacy itself keep precedence.
+func (r *Registry) Alias(legacy, version string) {
+	r.aliases = append(r.aliases, alias{
+		legacy:  strings.TrimSuffix(legacy, "/"),
+		version: strings.TrimSuffix(version, "/"),
+	})
+}
+
+func (a alias) handler(mux *http.ServeMux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The rewritten path is under version; matching it again means no route exists
+		if r.URL.Path == a.version || strings.HasPrefix(r.URL.Path, a.version+"/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		rewritten := new(http.Request)
+		*rewritten = *r
+		u := *r.URL
+		rewritten.URL = &u
+		rewritten.URL.Path = a.version + strings.TrimPrefix(r.URL.Path, a.legacy)
+		if r.URL.RawPath != "" {
+			rewritten.URL.RawPath = a.version + strings.TrimPrefix(r.URL.RawPath, a.legacy)
+		}
+		mux.ServeHTTP(w, rewritten)
+	}
+}
@@ -0,0 +1,47 @@
+package route
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	DefaultBodyLimit int64 = 1 << 20
+	// DefaultAuthBodyLimit is enough for any token or client credential request
+	DefaultAuthBodyLimit int64 = 64 << 10
+	// DefaultUploadBodyLimit leaves room above the 1 GB file size limit for multipart framing
+	DefaultUploadBodyLimit int64 = 1<<30 + 1<<20
+)
+
+// BodyLimits caps request bodies in bytes; routes without an explicit limit get Default
+type BodyLimits struct {
+	Default int64 `yaml:"default"`
+	Auth    int64 `yaml:"auth"`
+	Upload  int64 `yaml:"upload"`
+}
+
+func (l *BodyLimits) Validate() error {
+	if l.Default < 0 || l.Auth < 0 || l.Upload < 0 {
+		return fmt.Errorf("body limits must not be negative")
+	}
+
+	if l.Default == 0 {
+		l.Default = DefaultBodyLimit
+	}
+	if l.Auth == 0 {
+		l.Auth = DefaultAuthBodyLimit
+	}
+	if l.Upload == 0 {
+		l.Upload = DefaultUploadBodyLimit
+	}
+	return nil
+}
+
+func limitBody(limit int64, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,190 @@
+package route
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/NYCU-SDC/summer/pkg/middleware"
+	"go.uber.org/zap"
+)
+
+// Access is the authentication a route requires; each access level maps to one middleware set
+type Access string
+
+const (
+	Public              Access = "public"
+	Authenticated       Access = "authenticated"
+	Respondent          Access = "respondent"
+	Delegate            Access = "delegate"
+	Kiosk               Access = "kiosk"
+	TenantPublic        Access = "tenant_public"
+	TenantAuthenticated Access = "tenant_authenticated"
+)
+
+// Permission names the authorization check the handler performs once the caller passed
+// the access level. It is declared here so the route table can be audited in one place.
+type Permission string
+
+const (
+	// PermissionNone means any caller passing the access level may use the route
+	PermissionNone Permission = "none"
+	// PermissionSelf means the route only reads or changes the caller's own resources
+	PermissionSelf Permission = "self"
+	// PermissionClient means the caller identifies as a registered client, with its secret where the flow requires one
+	PermissionClient Permission = "client"
+	// PermissionUnitMember means the caller must belong to the unit the resource is addressed to
+	PermissionUnitMember Permission = "unit_member"
+	// PermissionReviewer means the caller must review the form the resource belongs to
+	PermissionReviewer Permission = "reviewer"
+	// PermissionOrgAdmin means the caller must own the organization in the path, or the one the form in the path belongs to
+	PermissionOrgAdmin Permission = "org_admin"
+	// PermissionAdmin means the caller must hold the admin role of the deployment
+	PermissionAdmin Permission = "admin"
+)
+
+var permissions = []Permission{
+	PermissionNone,
+	PermissionSelf,
+	PermissionClient,
+	PermissionUnitMember,
+	PermissionReviewer,
+	PermissionOrgAdmin,
+	PermissionAdmin,
+}
+
+type Route struct {
+	Method     string
+	Path       string
+	Access     Access
+	Permission Permission
+	Handler    http.HandlerFunc
+	// BodyLimit overrides the registry default when set
+	BodyLimit int64
+	// Deprecation is announced on every response of the route when set
+	Deprecation *Deprecation
+}
+
+func (r Route) Pattern() string {
+	return r.Method + " " + r.Path
+}
+
+// WithBodyLimit caps the request body of this route instead of the registry default
+func (r *Route) WithBodyLimit(limit int64) *Route {
+	r.BodyLimit = limit
+	return r
+}
+
+// Registry collects every route with its access level and permission before building
+// the mux, so a route cannot be mounted without declaring both
+type Registry struct {
+	logger           *zap.Logger
+	middlewares      map[Access]*middleware.Set
+	defaultBodyLimit int64
+	routes           []*Route
+	aliases          []alias
+}
+
+func NewRegistry(logger *zap.Logger, middlewares map[Access]*middleware.Set, defaultBodyLimit int64) *Registry {
+	return &Registry{
+		logger:           logger,
+		middlewares:      middlewares,
+		defaultBodyLimit: defaultBodyLimit,
+	}
+}
+
+// Handle declares a route; pattern is "METHOD /path" as accepted by http.ServeMux
+func (r *Registry) Handle(pattern string, access Access, permission Permission, handler http.HandlerFunc) *Route {
+	method, path, _ := strings.Cut(pattern, " ")
+	route := &Route{
+		Method:     method,
+		Path:       path,
+		Access:     access,
+		Permission: permission,
+		Handler:    handler,
+	}
+	r.routes = append(r.routes, route)
+	return route
+}
+
+// Routes returns the declared routes in registration order
+func (r *Registry) Routes() []Route {
+	routes := make([]Route, len(r.routes))
+	for i, route := range r.routes {
+		routes[i] = *route
+	}
+	return routes
+}
+
+func (r *Registry) validate(route *Route, seen map[string]bool) error {
+	if route.Method == "" || !strings.HasPrefix(route.Path, "/") {
+		return fmt.Errorf("route %q: pattern must be \"METHOD /path\"", route.Pattern())
+	}
+	if _, ok := r.middlewares[route.Access]; !ok {
+		return fmt.Errorf("route %s: unknown access level %q", route.Pattern(), route.Access)
+	}
+	if !slices.Contains(permissions, route.Permission) {
+		return fmt.Errorf("route %s: unknown permission %q", route.Pattern(), route.Permission)
+	}
+	if route.Handler == nil {
+		return fmt.Errorf("route %s: missing handler", route.Pattern())
+	}
+	if route.BodyLimit < 0 {
+		return fmt.Errorf("route %s: negative body limit", route.Pattern())
+	}
+	if route.Deprecation != nil {
+		err := route.Deprecation.validate()
+		if err != nil {
+			return fmt.Errorf("route %s: %w", route.Pattern(), err)
+		}
+	}
+	if seen[route.Pattern()] {
+		return fmt.Errorf("route %s: registered twice", route.Pattern())
+	}
+	seen[route.Pattern()] = true
+	return nil
+}
+
+// Mux validates every declared route and mounts it behind the middleware set of its access
+// level, with its request body capped and its deprecation announced
+func (r *Registry) Mux() (*http.ServeMux, error) {
+	var errs []error
+	seen := make(map[string]bool, len(r.routes))
+	for _, route := range r.routes {
+		err := r.validate(route, seen)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range r.routes {
+		bodyLimit := route.BodyLimit
+		if bodyLimit == 0 {
+			bodyLimit = r.defaultBodyLimit
+		}
+		handler := r.middlewares[route.Access].HandlerFunc(limitBody(bodyLimit, route.Handler))
+		if route.Deprecation != nil {
+			// Outside the middlewares, so rejected requests are told as well
+			handler = deprecate(*route.Deprecation, "route "+route.Pattern(), handler)
+			r.logger.Info("Mounted deprecated route", zap.String("route", route.Pattern()), zap.Time("sunset", route.Deprecation.Sunset))
+		}
+		mux.Handle(route.Pattern(), handler)
+		if route.Access == Public || route.Access == TenantPublic {
+			r.logger.Debug("Mounted unauthenticated route", zap.String("route", route.Pattern()), zap.String("permission", string(route.Permission)))
+		}
+	}
+
+	for _, alias := range r.aliases {
+		mux.Handle(alias.legacy+"/", alias.handler(mux))
+	}
+
+	r.logger.Info("Mounted routes", zap.Int("count", len(r.routes)))
+
+	return mux, nil
+}
@@ -0,0 +1,193 @@
+package search
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Search(ctx context.Context, userID uuid.UUID, query Query) (Results, error)
+}
+
+type FormResult struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	UnitName  string    `json:"unitName"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type UnitResult struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	OrgSlug     string `json:"orgSlug"`
+}
+
+type AnnouncementResult struct {
+	ID         string    `json:"id"`
+	Body       string    `json:"body"`
+	SenderName string    `json:"senderName"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type MemberResult struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Username  string `json:"username"`
+	AvatarURL string `json:"avatarUrl"`
+	OrgSlug   string `json:"orgSlug"`
+}
+
+type Response struct {
+	Query         string               `json:"query"`
+	Facets        map[EntityType]int64 `json:"facets"`
+	Forms         []FormResult         `json:"forms"`
+	Units         []UnitResult         `json:"units"`
+	Announcements []AnnouncementResult `json:"announcements"`
+	Members       []MemberResult       `json:"members"`
+}
+
+func ToResponse(text string, results Results) Response {
+	response := Response{
+		Query:         text,
+		Facets:        results.Facets,
+		Forms:         make([]FormResult, len(results.Forms)),
+		Units:         make([]UnitResult, len(results.Units)),
+		Announcements: make([]AnnouncementResult, len(results.Announcements)),
+		Members:       make([]MemberResult, len(results.Members)),
+	}
+
+	for i, form := range results.Forms {
+		response.Forms[i] = FormResult{
+			ID:        form.ID.String(),
+			Title:     form.Title,
+			Status:    string(form.Status),
+			UnitName:  form.UnitName.String,
+			UpdatedAt: form.UpdatedAt.Time,
+		}
+	}
+	for i, unit := range results.Units {
+		response.Units[i] = UnitResult{
+			ID:          unit.ID.String(),
+			Type:        string(unit.Type),
+			Name:        unit.Name.String,
+			Description: unit.Description.String,
+			OrgSlug:     unit.OrgSlug.String,
+		}
+	}
+	for i, announcement := range results.Announcements {
+		response.Announcements[i] = AnnouncementResult{
+			ID:         announcement.ID.String(),
+			Body:       announcement.Body.String,
+			SenderName: announcement.SenderName.String,
+			CreatedAt:  announcement.CreatedAt.Time,
+		}
+	}
+	for i, member := range results.Members {
+		response.Members[i] = MemberResult{
+			ID:        member.ID.String(),
+			Name:      member.Name.String,
+			Username:  member.Username.String,
+			AvatarURL: member.AvatarUrl.String,
+			OrgSlug:   member.OrgSlug.String,
+		}
+	}
+
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("search/handler"),
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+// parseQuery reads ?q=...&type=form,unit&limit=10
+func parseQuery(r *http.Request) (Query, error) {
+	query := Query{Text: strings.TrimSpace(r.URL.Query().Get("q"))}
+	if query.Text == "" {
+		return Query{}, fmt.Errorf("%w: q is required", internal.ErrInvalidQueryParameter)
+	}
+	if len(query.Text) > 200 {
+		return Query{}, fmt.Errorf("%w: q is longer than 200 characters", internal.ErrInvalidQueryParameter)
+	}
+
+	for _, value := range r.URL.Query()["type"] {
+		for _, name := range strings.Split(value, ",") {
+			entityType := EntityType(strings.TrimSpace(name))
+			if !slices.Contains(EntityTypes, entityType) {
+				return Query{}, fmt.Errorf("%w: unknown type %q", internal.ErrInvalidQueryParameter, name)
+			}
+			query.Types = append(query.Types, entityType)
+		}
+	}
+
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return Query{}, fmt.Errorf("%w: limit must be between 1 and %d", internal.ErrInvalidQueryParameter, MaxLimit)
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+func (h *Handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SearchHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	query, err := parseQuery(r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	results, err := h.store.Search(traceCtx, currentUser.ID, query)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(query.Text, results))
+}
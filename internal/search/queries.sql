@@ -0,0 +1,66 @@
+-- name: SearchForms :many
+-- Forms of the units the user belongs to, and forms delivered to their inbox
+SELECT f.id, f.title, f.status, f.updated_at, un.name AS unit_name,
+    ts_rank(to_tsvector('simple', f.title || ' ' || coalesce(f.description, '')), to_tsquery('simple', @query::text))::float8 AS rank,
+    COUNT(*) OVER ()::bigint AS total
+FROM forms f
+LEFT JOIN units un ON un.id = f.unit_id
+WHERE to_tsvector('simple', f.title || ' ' || coalesce(f.description, '')) @@ to_tsquery('simple', @query::text)
+  AND (
+      EXISTS (
+          SELECT 1 FROM unit_members um
+          WHERE um.member_id = @user_id AND (um.unit_id = f.unit_id OR um.unit_id = un.org_id)
+      )
+      OR EXISTS (
+          SELECT 1 FROM user_inbox_messages uim
+          JOIN inbox_message im ON im.id = uim.message_id
+          WHERE uim.user_id = @user_id AND im.type = 'form' AND im.content_id = f.id
+      )
+  )
+ORDER BY rank DESC, f.updated_at DESC
+LIMIT @row_limit;
+
+-- name: SearchUnits :many
+SELECT u.id, u.type, u.name, u.description, sh.slug AS org_slug,
+    ts_rank(to_tsvector('simple', coalesce(u.name, '') || ' ' || coalesce(u.description, '')), to_tsquery('simple', @query::text))::float8 AS rank,
+    COUNT(*) OVER ()::bigint AS total
+FROM units u
+LEFT JOIN slug_history sh ON sh.org_id = COALESCE(u.org_id, u.id) AND sh.ended_at IS NULL
+WHERE to_tsvector('simple', coalesce(u.name, '') || ' ' || coalesce(u.description, '')) @@ to_tsquery('simple', @query::text)
+ORDER BY rank DESC, u.name
+LIMIT @row_limit;
+
+-- name: SearchAnnouncements :many
+-- Top-level text messages in the user's inbox
+SELECT uim.id, im.body, im.created_at, sender.name AS sender_name,
+    ts_rank(to_tsvector('simple', coalesce(im.body, '')), to_tsquery('simple', @query::text))::float8 AS rank,
+    COUNT(*) OVER ()::bigint AS total
+FROM user_inbox_messages uim
+JOIN inbox_message im ON im.id = uim.message_id
+LEFT JOIN users sender ON sender.id = im.sender_id
+WHERE uim.user_id = @user_id
+  AND im.type = 'text'
+  AND im.reply_to IS NULL
+  AND to_tsvector('simple', coalesce(im.body, '')) @@ to_tsquery('simple', @query::text)
+ORDER BY rank DESC, im.created_at DESC
+LIMIT @row_limit;
+
+-- name: IsAnyOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE owner_id = @user_id);
+
+-- name: SearchMembers :many
+-- Members of the organizations the user owns
+SELECT usr.id, usr.name, usr.username, usr.avatar_url, t.id AS org_id, sh.slug AS org_slug,
+    ts_rank(to_tsvector('simple', coalesce(usr.name, '') || ' ' || coalesce(usr.username, '')), to_tsquery('simple', @query::text))::float8 AS rank,
+    COUNT(*) OVER ()::bigint AS total
+FROM tenants t
+JOIN users usr ON EXISTS (
+    SELECT 1 FROM unit_members um
+    JOIN units u ON u.id = um.unit_id
+    WHERE um.member_id = usr.id AND (u.id = t.id OR u.org_id = t.id)
+)
+LEFT JOIN slug_history sh ON sh.org_id = t.id AND sh.ended_at IS NULL
+WHERE t.owner_id = @user_id
+  AND to_tsvector('simple', coalesce(usr.name, '') || ' ' || coalesce(usr.username, '')) @@ to_tsquery('simple', @query::text)
+ORDER BY rank DESC, usr.name
+LIMIT @row_limit;
\ No newline at end of file
@@ -0,0 +1,10 @@
+package search
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the search endpoint
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /search", route.Authenticated, route.PermissionNone, h.SearchHandler)
+}
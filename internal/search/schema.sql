@@ -0,0 +1,6 @@
+-- Full-text indexes for GET /api/search. The 'simple' configuration does no stemming,
+-- so names and Chinese titles match the way they were typed.
+CREATE INDEX IF NOT EXISTS idx_forms_search ON forms USING GIN (to_tsvector('simple', title || ' ' || coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_units_search ON units USING GIN (to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_inbox_message_search ON inbox_message USING GIN (to_tsvector('simple', coalesce(body, ''))) WHERE type = 'text';
+CREATE INDEX IF NOT EXISTS idx_users_search ON users USING GIN (to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(username, '')));
\ No newline at end of file
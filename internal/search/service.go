@@ -0,0 +1,194 @@
+package search
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type EntityType string
+
+const (
+	EntityForm         EntityType = "form"
+	EntityUnit         EntityType = "unit"
+	EntityAnnouncement EntityType = "announcement"
+	EntityMember       EntityType = "member"
+)
+
+var EntityTypes = []EntityType{EntityForm, EntityUnit, EntityAnnouncement, EntityMember}
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 50
+	// maxTerms keeps a pasted paragraph from turning into a huge tsquery
+	maxTerms = 8
+)
+
+type Querier interface {
+	SearchForms(ctx context.Context, arg SearchFormsParams) ([]SearchFormsRow, error)
+	SearchUnits(ctx context.Context, arg SearchUnitsParams) ([]SearchUnitsRow, error)
+	SearchAnnouncements(ctx context.Context, arg SearchAnnouncementsParams) ([]SearchAnnouncementsRow, error)
+	IsAnyOrgAdmin(ctx context.Context, userID pgtype.UUID) (bool, error)
+	SearchMembers(ctx context.Context, arg SearchMembersParams) ([]SearchMembersRow, error)
+}
+
+// Query is one search; an empty Types searches every entity type the user may see
+type Query struct {
+	Text  string
+	Types []EntityType
+	Limit int
+}
+
+// Results holds up to Limit hits per entity type, and in Facets the total number of
+// hits of every type that was searched
+type Results struct {
+	Forms         []SearchFormsRow
+	Units         []SearchUnitsRow
+	Announcements []SearchAnnouncementsRow
+	Members       []SearchMembersRow
+	Facets        map[EntityType]int64
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("search/service"),
+	}
+}
+
+// ToTSQuery turns free text into a prefix match of every word, dropping the
+// characters that have a meaning in tsquery syntax
+func ToTSQuery(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		word = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, word)
+		if word == "" {
+			continue
+		}
+		terms = append(terms, word+":*")
+		if len(terms) == maxTerms {
+			break
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+func (q Query) includes(entityType EntityType) bool {
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, t := range q.Types {
+		if t == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) Search(ctx context.Context, userID uuid.UUID, query Query) (Results, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Search")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	tsQuery := ToTSQuery(query.Text)
+	if tsQuery == "" {
+		err := fmt.Errorf("%w: q must contain a word", internal.ErrInvalidQueryParameter)
+		span.RecordError(err)
+		return Results{}, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	results := Results{Facets: map[EntityType]int64{}}
+	var err error
+
+	if query.includes(EntityForm) {
+		results.Forms, err = s.queries.SearchForms(traceCtx, SearchFormsParams{Query: tsQuery, UserID: userID, RowLimit: int32(limit)})
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "search forms")
+			span.RecordError(err)
+			return Results{}, err
+		}
+		results.Facets[EntityForm] = 0
+		if len(results.Forms) > 0 {
+			results.Facets[EntityForm] = results.Forms[0].Total
+		}
+	}
+
+	if query.includes(EntityUnit) {
+		results.Units, err = s.queries.SearchUnits(traceCtx, SearchUnitsParams{Query: tsQuery, RowLimit: int32(limit)})
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "search units")
+			span.RecordError(err)
+			return Results{}, err
+		}
+		results.Facets[EntityUnit] = 0
+		if len(results.Units) > 0 {
+			results.Facets[EntityUnit] = results.Units[0].Total
+		}
+	}
+
+	if query.includes(EntityAnnouncement) {
+		results.Announcements, err = s.queries.SearchAnnouncements(traceCtx, SearchAnnouncementsParams{Query: tsQuery, UserID: userID, RowLimit: int32(limit)})
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "search announcements")
+			span.RecordError(err)
+			return Results{}, err
+		}
+		results.Facets[EntityAnnouncement] = 0
+		if len(results.Announcements) > 0 {
+			results.Facets[EntityAnnouncement] = results.Announcements[0].Total
+		}
+	}
+
+	// Members are only searched, and only get a facet, for organization owners
+	if query.includes(EntityMember) {
+		admin, err := s.queries.IsAnyOrgAdmin(traceCtx, pgtype.UUID{Bytes: userID, Valid: true})
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "check organization owner")
+			span.RecordError(err)
+			return Results{}, err
+		}
+
+		if admin {
+			results.Members, err = s.queries.SearchMembers(traceCtx, SearchMembersParams{Query: tsQuery, UserID: pgtype.UUID{Bytes: userID, Valid: true}, RowLimit: int32(limit)})
+			if err != nil {
+				err = databaseutil.WrapDBError(err, logger, "search members")
+				span.RecordError(err)
+				return Results{}, err
+			}
+			results.Facets[EntityMember] = 0
+			if len(results.Members) > 0 {
+				results.Facets[EntityMember] = results.Members[0].Total
+			}
+		}
+	}
+
+	return results, nil
+}
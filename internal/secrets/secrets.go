@@ -0,0 +1,185 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// prefix marks sealed values, the ones without it are stored in the clear from before
+// they were encrypted
+const prefix = "enc:v1:"
+
+var (
+	ErrUnknownKey = errors.New("secret sealed with a key no longer configured")
+	ErrMalformed  = errors.New("malformed sealed secret")
+	ErrNoKeys     = errors.New("no secrets key files configured")
+)
+
+// Config lists the files of the AES-256 keys integration credentials are encrypted with,
+// each holding 32 bytes encoded in base64, e.g. from `openssl rand -base64 32` or mounted
+// from a KMS. The first key seals new values; the others only open values sealed before
+// a rotation until they are sealed again. At least one key file is required outside of
+// dev mode.
+type Config struct {
+	KeyFiles []string `yaml:"key_files" envconfig:"SECRETS_KEY_FILES"`
+}
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring seals and opens the secrets stored by the integrations: the URLs of export
+// and workflow webhooks, which carry the tokens of the services they post to, the
+// signing secrets of the organizations and the credentials of their ingest sources
+type Keyring struct {
+	keys []key
+}
+
+// NewKeyring loads the configured keys, failing with ErrNoKeys when there are none
+func NewKeyring(cfg Config) (*Keyring, error) {
+	if len(cfg.KeyFiles) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	var raws [][]byte
+	for _, file := range cfg.KeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secrets key file %s: %w", file, err)
+		}
+
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(raw) != 32 {
+			return nil, fmt.Errorf("secrets key file %s must hold 32 bytes in base64", file)
+		}
+		raws = append(raws, raw)
+	}
+
+	return newKeyring(raws)
+}
+
+// NewDevKeyring seals with a key derived from the application secret, for dev mode
+// only: anyone knowing the secret opens what it sealed. Values sealed with it open
+// under NewKeyring once the derived key is written to a key file, given after the
+// current one:
+//
+//	printf 'core-system secrets\n%s' "$SECRET" | openssl dgst -sha256 -binary | base64
+func NewDevKeyring(secret string) *Keyring {
+	derived := sha256.Sum256([]byte("core-system secrets\n" + secret))
+	keyring, err := newKeyring([][]byte{derived[:]})
+	if err != nil {
+		// A 32 byte key always makes an AES-256 cipher
+		panic(err)
+	}
+	return keyring
+}
+
+func newKeyring(raws [][]byte) (*Keyring, error) {
+	keys := make([]key, len(raws))
+	for i, raw := range raws {
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		// The ID only tells the keys apart, it reveals nothing of the key
+		thumbprint := sha256.Sum256(raw)
+		keys[i] = key{id: hex.EncodeToString(thumbprint[:4]), aead: aead}
+	}
+
+	return &Keyring{keys: keys}, nil
+}
+
+// Seal encrypts the value with the current key. The empty value stays empty.
+func (k *Keyring) Seal(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	current := k.keys[0]
+	nonce := make([]byte, current.aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := current.aead.Seal(nonce, nonce, []byte(value), []byte(current.id))
+	return prefix + current.id + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a sealed value with the key it was sealed with. Values stored before
+// encryption are returned as they are.
+func (k *Keyring) Open(value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return value, nil
+	}
+
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", ErrMalformed
+	}
+
+	for _, candidate := range k.keys {
+		if candidate.id != id {
+			continue
+		}
+
+		sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+		if err != nil || len(sealed) < candidate.aead.NonceSize() {
+			return "", ErrMalformed
+		}
+
+		nonce, ciphertext := sealed[:candidate.aead.NonceSize()], sealed[candidate.aead.NonceSize():]
+		plaintext, err := candidate.aead.Open(nil, nonce, ciphertext, []byte(id))
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrMalformed, err)
+		}
+		return string(plaintext), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownKey, id)
+}
+
+// Current reports whether the value is sealed with the current key, values stored in
+// the clear or sealed before a rotation are not
+func (k *Keyring) Current(value string) bool {
+	return value == "" || strings.HasPrefix(value, prefix+k.keys[0].id+":")
+}
+
+// Rotator seals again the secrets of an integration which are not sealed with the
+// current key and returns how many it sealed
+type Rotator interface {
+	RotateSecrets(ctx context.Context) (int, error)
+}
+
+// Rotate runs every rotator once, so the keys of before a rotation can be removed from
+// the configuration after the next start. A failing integration does not stop the others.
+func Rotate(ctx context.Context, logger *zap.Logger, rotators map[string]Rotator) {
+	for name, rotator := range rotators {
+		count, err := rotator.RotateSecrets(ctx)
+		if err != nil {
+			logger.Error("Failed to rotate secrets", zap.String("integration", name), zap.Error(err))
+			continue
+		}
+		if count > 0 {
+			logger.Info("Sealed secrets with the current key", zap.String("integration", name), zap.Int("count", count))
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package secrets_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal/secrets"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeKey writes a key file holding the raw key, or a fresh one when nil
+func writeKey(t *testing.T, raw []byte) string {
+	t.Helper()
+
+	if raw == nil {
+		raw = make([]byte, 32)
+		_, err := rand.Read(raw)
+		require.NoError(t, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "secrets.key")
+	require.NoError(t, os.WriteFile(file, []byte(base64.StdEncoding.EncodeToString(raw)+"\n"), 0o600))
+	return file
+}
+
+func newKeyring(t *testing.T, keyFiles ...string) *secrets.Keyring {
+	t.Helper()
+
+	keyring, err := secrets.NewKeyring(secrets.Config{KeyFiles: keyFiles})
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	t.Parallel()
+
+	keyring := newKeyring(t, writeKey(t, nil))
+	const url = "https://hooks.example.com/services/T000/B000/secret-token"
+
+	sealed, err := keyring.Seal(url)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(sealed, "enc:v1:"))
+	require.NotContains(t, sealed, "secret-token")
+	require.True(t, keyring.Current(sealed))
+
+	again, err := keyring.Seal(url)
+	require.NoError(t, err)
+	require.NotEqual(t, sealed, again, "every seal draws a fresh nonce")
+
+	opened, err := keyring.Open(sealed)
+	require.NoError(t, err)
+	require.Equal(t, url, opened)
+}
+
+func TestKeyring_OpenAfterRotation(t *testing.T) {
+	t.Parallel()
+
+	oldKey, newKey := writeKey(t, nil), writeKey(t, nil)
+	const url = "https://hooks.example.com/token"
+
+	sealedBefore, err := newKeyring(t, oldKey).Seal(url)
+	require.NoError(t, err)
+
+	// The new key goes first, the old one stays until everything is sealed again
+	rotated := newKeyring(t, newKey, oldKey)
+	require.False(t, rotated.Current(sealedBefore))
+
+	opened, err := rotated.Open(sealedBefore)
+	require.NoError(t, err)
+	require.Equal(t, url, opened)
+
+	sealedAfter, err := rotated.Seal(opened)
+	require.NoError(t, err)
+	require.True(t, rotated.Current(sealedAfter))
+
+	// Once the old key is removed, only the values sealed again still open
+	retired := newKeyring(t, newKey)
+	opened, err = retired.Open(sealedAfter)
+	require.NoError(t, err)
+	require.Equal(t, url, opened)
+
+	_, err = retired.Open(sealedBefore)
+	require.ErrorIs(t, err, secrets.ErrUnknownKey)
+}
+
+func TestKeyring_Open(t *testing.T) {
+	t.Parallel()
+
+	keyring := newKeyring(t, writeKey(t, nil))
+	sealed, err := keyring.Seal("https://hooks.example.com/token")
+	require.NoError(t, err)
+	id, _, _ := strings.Cut(strings.TrimPrefix(sealed, "enc:v1:"), ":")
+
+	type testCase struct {
+		name        string
+		value       string
+		expected    string
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{name: "Value stored in the clear", value: "https://hooks.example.com/legacy", expected: "https://hooks.example.com/legacy"},
+		{name: "Empty value", value: "", expected: ""},
+		{name: "Missing key id", value: "enc:v1:abc", expectedErr: secrets.ErrMalformed},
+		{name: "Invalid encoding", value: "enc:v1:" + id + ":***", expectedErr: secrets.ErrMalformed},
+		{name: "Shorter than a nonce", value: "enc:v1:" + id + ":AAAA", expectedErr: secrets.ErrMalformed},
+		{name: "Tampered ciphertext", value: sealed[:len(sealed)-2] + "AA", expectedErr: secrets.ErrMalformed},
+		{name: "Unknown key", value: "enc:v1:00000000:AAAA", expectedErr: secrets.ErrUnknownKey},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			opened, err := keyring.Open(tc.value)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, opened)
+		})
+	}
+}
+
+func TestKeyring_Current(t *testing.T) {
+	t.Parallel()
+
+	keyring := newKeyring(t, writeKey(t, nil))
+	require.True(t, keyring.Current(""))
+	require.False(t, keyring.Current("https://hooks.example.com/legacy"))
+}
+
+func TestNewKeyring(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	shortKey := filepath.Join(dir, "short.key")
+	require.NoError(t, os.WriteFile(shortKey, []byte(base64.StdEncoding.EncodeToString(make([]byte, 16))), 0o600))
+	notBase64 := filepath.Join(dir, "plain.key")
+	require.NoError(t, os.WriteFile(notBase64, []byte("not a key"), 0o600))
+
+	type testCase struct {
+		name        string
+		keyFiles    []string
+		expectedErr bool
+		expectedIs  error
+	}
+
+	testCases := []testCase{
+		{name: "One key", keyFiles: []string{writeKey(t, nil)}},
+		{name: "No keys", expectedErr: true, expectedIs: secrets.ErrNoKeys},
+		{name: "Missing file", keyFiles: []string{filepath.Join(dir, "missing.key")}, expectedErr: true, expectedIs: os.ErrNotExist},
+		{name: "Key of 16 bytes", keyFiles: []string{shortKey}, expectedErr: true},
+		{name: "Key not in base64", keyFiles: []string{notBase64}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := secrets.NewKeyring(secrets.Config{KeyFiles: tc.keyFiles})
+			if !tc.expectedErr {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			if tc.expectedIs != nil {
+				require.ErrorIs(t, err, tc.expectedIs)
+			}
+		})
+	}
+}
+
+// TestNewDevKeyring opens the values sealed in dev mode with a key file holding the
+// derived key, as the doc of NewDevKeyring describes for moving off dev mode
+func TestNewDevKeyring(t *testing.T) {
+	t.Parallel()
+
+	dev := secrets.NewDevKeyring("test-secret")
+	sealed, err := dev.Seal("https://hooks.example.com/token")
+	require.NoError(t, err)
+
+	other, err := secrets.NewDevKeyring("another-secret").Seal("https://hooks.example.com/token")
+	require.NoError(t, err)
+	_, err = dev.Open(other)
+	require.ErrorIs(t, err, secrets.ErrUnknownKey)
+
+	derived := sha256.Sum256([]byte("core-system secrets\ntest-secret"))
+	keyring := newKeyring(t, writeKey(t, nil), writeKey(t, derived[:]))
+	opened, err := keyring.Open(sealed)
+	require.NoError(t, err)
+	require.Equal(t, "https://hooks.example.com/token", opened)
+}
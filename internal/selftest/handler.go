@@ -0,0 +1,74 @@
+package selftest
+
+import (
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Last() (Result, bool)
+}
+
+// ReadyResponse leaves out the error of a failed run, the route being public; it is
+// logged instead
+type ReadyResponse struct {
+	Status     string     `json:"status"`
+	CheckedAt  *time.Time `json:"checkedAt,omitempty"`
+	DurationMs int64      `json:"durationMs,omitempty"`
+	FailedStep string     `json:"failedStep,omitempty"`
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+	store  Store
+}
+
+func NewHandler(logger *zap.Logger, store Store) *Handler {
+	return &Handler{
+		logger: logger,
+		tracer: otel.Tracer("selftest/handler"),
+		store:  store,
+	}
+}
+
+// LiveHandler answers 200 while the process serves requests, whatever the self-test reports
+func (h *Handler) LiveHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write([]byte("OK"))
+	if err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
+
+// ReadyHandler reports the replica ready once the last self-test passed. Until the
+// first run has finished, and while runs fail, it answers 503.
+func (h *Handler) ReadyHandler(w http.ResponseWriter, r *http.Request) {
+	_, span := h.tracer.Start(r.Context(), "ReadyHandler")
+	defer span.End()
+
+	result, ok := h.store.Last()
+	if !ok {
+		handlerutil.WriteJSONResponse(w, http.StatusServiceUnavailable, ReadyResponse{Status: "pending"})
+		return
+	}
+
+	resp := ReadyResponse{
+		Status:     "ok",
+		CheckedAt:  &result.CheckedAt,
+		DurationMs: result.Duration.Milliseconds(),
+	}
+	if !result.Healthy {
+		resp.Status = "failing"
+		resp.FailedStep = result.Step
+		handlerutil.WriteJSONResponse(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, resp)
+}
@@ -0,0 +1,14 @@
+-- name: CreateUser :one
+INSERT INTO users (name, role, is_onboarded)
+VALUES ('Self-test', '{"respondent"}', true)
+RETURNING id;
+
+-- name: CreateOrg :one
+INSERT INTO units (name, type)
+VALUES ('Self-test sandbox', 'organization')
+RETURNING id;
+
+-- name: CreateUnit :one
+INSERT INTO units (name, type, org_id, parent_id)
+VALUES ('Self-test', 'unit', @org_id, @org_id)
+RETURNING id;
\ No newline at end of file
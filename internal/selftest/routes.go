@@ -0,0 +1,11 @@
+package selftest
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the liveness and readiness probes
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /healthz", route.Public, route.PermissionNone, h.LiveHandler)
+	r.Handle("GET /readyz", route.Public, route.PermissionNone, h.ReadyHandler)
+}
@@ -0,0 +1,295 @@
+package selftest
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
+	"NYCU-SDC/core-system-backend/internal/form/submit"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const DefaultRunInterval = 5 * time.Minute
+
+// answerValue is what the self-test submits and expects to read back
+const answerValue = "self-test"
+
+// Steps of a run, reported as the step that failed
+const (
+	StepSandbox = "sandbox"
+	StepCreate  = "create"
+	StepSubmit  = "submit"
+	StepRead    = "read"
+	StepDelete  = "delete"
+)
+
+type Pool interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Result is the outcome of one run. Step is empty when the run passed.
+type Result struct {
+	Healthy   bool
+	CheckedAt time.Time
+	Duration  time.Duration
+	Step      string
+	Err       error
+}
+
+type stepError struct {
+	step string
+	err  error
+}
+
+func (e stepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.step, e.err)
+}
+
+func (e stepError) Unwrap() error {
+	return e.err
+}
+
+type Service struct {
+	logger    *zap.Logger
+	pool      Pool
+	tracer    trace.Tracer
+	secretBox workflow.SecretBox
+
+	duration metric.Float64Histogram
+
+	mu   sync.RWMutex
+	last Result
+	ran  bool
+}
+
+func NewService(logger *zap.Logger, pool Pool, secretBox workflow.SecretBox) (*Service, error) {
+	s := &Service{
+		logger:    logger,
+		pool:      pool,
+		tracer:    otel.Tracer("selftest/service"),
+		secretBox: secretBox,
+	}
+
+	meter := otel.Meter("internal/selftest")
+
+	duration, err := meter.Float64Histogram("selftest.duration",
+		metric.WithDescription("Time taken by the end-to-end self-test"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	s.duration = duration
+
+	_, err = meter.Int64ObservableGauge("selftest.healthy",
+		metric.WithDescription("Whether the last end-to-end self-test passed"),
+		metric.WithInt64Callback(func(ctx context.Context, observer metric.Int64Observer) error {
+			result, ok := s.Last()
+			if !ok {
+				return nil
+			}
+			healthy := int64(0)
+			if result.Healthy {
+				healthy = 1
+			}
+			observer.Observe(healthy)
+			return nil
+		}))
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Last returns the result of the latest run, false before the first one has finished
+func (s *Service) Last() (Result, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last, s.ran
+}
+
+// Start runs the self-test right away and then every interval. Every replica runs its
+// own, since each reports its own readiness.
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.Run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run creates a form in a sandbox organization, submits it, reads the response back
+// and deletes the form. Everything is done in one transaction that is always rolled
+// back, so a run leaves nothing behind.
+func (s *Service) Run(ctx context.Context) Result {
+	traceCtx, span := s.tracer.Start(ctx, "Run")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	start := time.Now()
+	err := s.run(traceCtx, logger)
+	result := Result{
+		Healthy:   err == nil,
+		CheckedAt: start,
+		Duration:  time.Since(start),
+	}
+	if err != nil {
+		span.RecordError(err)
+		result.Err = err
+
+		var failed stepError
+		if errors.As(err, &failed) {
+			result.Step = failed.step
+		}
+	}
+
+	s.duration.Record(traceCtx, result.Duration.Seconds())
+
+	s.mu.Lock()
+	wasHealthy := s.last.Healthy || !s.ran
+	s.last = result
+	s.ran = true
+	s.mu.Unlock()
+
+	if err != nil {
+		logger.Error("Self-test failed", zap.String("step", result.Step), zap.Duration("duration", result.Duration), zap.Error(err))
+	} else if !wasHealthy {
+		logger.Info("Self-test passed again", zap.Duration("duration", result.Duration))
+	}
+
+	return result
+}
+
+func (s *Service) run(ctx context.Context, logger *zap.Logger) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return stepError{step: StepSandbox, err: databaseutil.WrapDBError(err, logger, "begin self-test transaction")}
+	}
+	defer func() {
+		err := tx.Rollback(context.WithoutCancel(ctx))
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			logger.Error("Failed to roll back self-test transaction", zap.Error(err))
+		}
+	}()
+
+	queries := New(tx)
+	questionService := question.NewService(logger, tx)
+	responseService := response.NewService(logger, tx)
+	formService := form.NewService(logger, tx, responseService)
+	workflowService := workflow.NewService(logger, tx, questionService, s.secretBox)
+	importerService := importer.NewService(logger, tx, formService, workflowService, questionService)
+	submitService := submit.NewService(logger, formService, questionService, responseService, nil, nil, nil, nil, nil, nil, nil)
+
+	userID, err := queries.CreateUser(ctx)
+	if err != nil {
+		return stepError{step: StepSandbox, err: databaseutil.WrapDBError(err, logger, "create self-test user")}
+	}
+	orgID, err := queries.CreateOrg(ctx)
+	if err != nil {
+		return stepError{step: StepSandbox, err: databaseutil.WrapDBError(err, logger, "create self-test organization")}
+	}
+	unitID, err := queries.CreateUnit(ctx, pgtype.UUID{Bytes: orgID, Valid: true})
+	if err != nil {
+		return stepError{step: StepSandbox, err: databaseutil.WrapDBError(err, logger, "create self-test unit")}
+	}
+
+	document, err := sandboxDocument()
+	if err != nil {
+		return stepError{step: StepCreate, err: err}
+	}
+	imported, err := importerService.ImportDocument(ctx, orgID, unitID, userID, document)
+	if err != nil {
+		return stepError{step: StepCreate, err: err}
+	}
+	formID := imported.Form.ID
+
+	sections, err := questionService.ListByFormID(ctx, formID)
+	if err != nil {
+		return stepError{step: StepCreate, err: err}
+	}
+	if len(sections) != 1 || len(sections[0].Questions) != 1 {
+		return stepError{step: StepCreate, err: fmt.Errorf("form %s was created with an unexpected layout", formID)}
+	}
+	questionID := sections[0].Questions[0].Question().ID
+
+	// A preview submission is a test response and skips the checks and side effects the
+	// self-test has no stores for
+	previewCtx := context.WithValue(ctx, internal.PreviewContextKey, true)
+	submitted, errs := submitService.Submit(previewCtx, formID, userID, []shared.AnswerParam{
+		{QuestionID: questionID.String(), Value: answerValue},
+	})
+	if len(errs) > 0 {
+		return stepError{step: StepSubmit, err: errors.Join(errs...)}
+	}
+
+	_, answers, err := responseService.Get(ctx, formID, submitted.ID)
+	if err != nil {
+		return stepError{step: StepRead, err: err}
+	}
+	if len(answers) != 1 || answers[0].QuestionID != questionID {
+		return stepError{step: StepRead, err: fmt.Errorf("response %s did not read back the submitted answer", submitted.ID)}
+	}
+
+	err = formService.Delete(ctx, formID)
+	if err != nil {
+		return stepError{step: StepDelete, err: err}
+	}
+
+	return nil
+}
+
+// sandboxDocument is a form with one section holding one short text question
+func sandboxDocument() (importer.Document, error) {
+	startID, sectionID, endID, questionID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	graph, err := json.Marshal([]map[string]any{
+		{"id": startID.String(), "type": string(workflow.NodeTypeStart), "label": "Start", "next": sectionID.String()},
+		{"id": sectionID.String(), "type": string(workflow.NodeTypeSection), "label": "Self-test", "next": endID.String()},
+		{"id": endID.String(), "type": string(workflow.NodeTypeEnd), "label": "End"},
+	})
+	if err != nil {
+		return importer.Document{}, fmt.Errorf("failed to encode self-test workflow: %w", err)
+	}
+
+	return importer.Document{
+		Version: importer.DocumentVersion,
+		Form:    importer.DocumentForm{Title: "Self-test"},
+		Sections: []importer.DocumentSection{{
+			ID:    sectionID,
+			Title: "Self-test",
+			Questions: []importer.DocumentQuestion{{
+				ID:       questionID,
+				Type:     string(question.QuestionTypeShortText),
+				Title:    "Self-test",
+				Required: true,
+				Order:    1,
+			}},
+		}},
+		Workflow: graph,
+	}, nil
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LocalDownloadPath is the route prefix serving presigned downloads of the local backend
+const LocalDownloadPath = "/api/storage/"
+
+// Local stores objects as files under a directory. Presigned URLs point to
+// DownloadHandler and are signed with the server secret.
+type Local struct {
+	dir        string
+	secret     []byte
+	baseURL    string
+	presignTTL time.Duration
+}
+
+func NewLocal(dir string, secret string, baseURL string, presignTTL time.Duration) (*Local, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &Local{
+		dir:        dir,
+		secret:     []byte(secret),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		presignTTL: presignTTL,
+	}, nil
+}
+
+func (l *Local) Put(_ context.Context, key string, body io.Reader, _ int64, _ string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create object: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = io.Copy(tmp, body)
+	if err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("failed to store object: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	return file, nil
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+
+	return nil
+}
+
+func (l *Local) PresignGet(_ context.Context, key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(l.presignTTL).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", l.sign(key, expires))
+
+	return l.baseURL + LocalDownloadPath + escapePath(key) + "?" + query.Encode(), nil
+}
+
+// DownloadHandler serves an object by a presigned URL. Register it on
+// "GET /api/storage/{key...}".
+func (l *Local) DownloadHandler(logger *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("key")
+		expires := r.URL.Query().Get("expires")
+
+		expiresAt, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || time.Now().Unix() > expiresAt {
+			http.Error(w, "download link expired", http.StatusForbidden)
+			return
+		}
+		if !hmac.Equal([]byte(l.sign(key, expires)), []byte(r.URL.Query().Get("signature"))) {
+			http.Error(w, "invalid download signature", http.StatusForbidden)
+			return
+		}
+
+		path, err := l.path(key)
+		if err != nil {
+			http.Error(w, "invalid object key", http.StatusBadRequest)
+			return
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				http.NotFound(w, r)
+				return
+			}
+			logger.Error("Failed to open stored object", zap.Error(err), zap.String("key", key))
+			http.Error(w, "failed to open object", http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = file.Close() }()
+
+		info, err := file.Stat()
+		if err != nil {
+			logger.Error("Failed to stat stored object", zap.Error(err), zap.String("key", key))
+			http.Error(w, "failed to open object", http.StatusInternalServerError)
+			return
+		}
+
+		http.ServeContent(w, r, filepath.Base(path), info.ModTime(), file)
+	}
+}
+
+func (l *Local) sign(key string, expires string) string {
+	return hex.EncodeToString(hmacSHA256(l.secret, key+"\n"+expires))
+}
+
+func (l *Local) path(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	BackendS3    = "s3"
+	BackendLocal = "local"
+
+	defaultPresignTTL = 15 * time.Minute
+	defaultLocalDir   = "data/storage"
+)
+
+var (
+	ErrObjectNotFound = errors.New("object not found")
+	ErrInvalidKey     = errors.New("invalid object key")
+)
+
+// Storage stores objects such as uploaded files, exports and branding assets by key
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a URL that downloads the object without authentication until the presigned URL TTL expires
+	PresignGet(ctx context.Context, key string) (string, error)
+}
+
+// Config selects and configures the storage backend. The S3 backend works with any
+// S3-compatible service such as MinIO; the local backend keeps objects on disk and is
+// meant for development.
+type Config struct {
+	Backend         string `yaml:"backend"           envconfig:"STORAGE_BACKEND"`
+	Bucket          string `yaml:"bucket"            envconfig:"STORAGE_BUCKET"`
+	Endpoint        string `yaml:"endpoint"          envconfig:"STORAGE_ENDPOINT"`
+	Region          string `yaml:"region"            envconfig:"STORAGE_REGION"`
+	AccessKeyID     string `yaml:"access_key_id"     envconfig:"STORAGE_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secret_access_key" envconfig:"STORAGE_SECRET_ACCESS_KEY"`
+	UsePathStyle    bool   `yaml:"use_path_style"    envconfig:"STORAGE_USE_PATH_STYLE"`
+	PresignTTLStr   string `yaml:"presign_ttl"       envconfig:"STORAGE_PRESIGN_TTL"`
+	LocalDir        string `yaml:"local_dir"         envconfig:"STORAGE_LOCAL_DIR"`
+
+	PresignTTL time.Duration `yaml:"-"`
+}
+
+// Validate checks the settings required by the selected backend and parses the presigned URL TTL
+func (c *Config) Validate() error {
+	switch c.Backend {
+	case "", BackendLocal:
+		c.Backend = BackendLocal
+		if c.LocalDir == "" {
+			c.LocalDir = defaultLocalDir
+		}
+	case BackendS3:
+		if c.Bucket == "" || c.Endpoint == "" {
+			return fmt.Errorf("storage bucket and endpoint are required for the s3 backend")
+		}
+		if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+			return fmt.Errorf("storage access_key_id and secret_access_key are required for the s3 backend")
+		}
+		if c.Region == "" {
+			c.Region = "us-east-1"
+		}
+	default:
+		return fmt.Errorf("unsupported storage backend: %s", c.Backend)
+	}
+
+	c.PresignTTL = defaultPresignTTL
+	if c.PresignTTLStr != "" {
+		ttl, err := time.ParseDuration(c.PresignTTLStr)
+		if err != nil {
+			return fmt.Errorf("invalid storage presign_ttl: %w", err)
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("storage presign_ttl must be greater than zero")
+		}
+		c.PresignTTL = ttl
+	}
+
+	return nil
+}
+
+// New creates the storage backend selected by a validated config. secret signs the
+// download URLs of the local backend, which are served under baseURL.
+func New(cfg Config, secret string, baseURL string) (Storage, error) {
+	switch cfg.Backend {
+	case BackendS3:
+		return NewS3(cfg)
+	case BackendLocal:
+		return NewLocal(cfg.LocalDir, secret, baseURL, cfg.PresignTTL)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", cfg.Backend)
+	}
+}
+
+// cleanKey rejects empty keys and keys that escape their prefix
+func cleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("%w: %s", ErrInvalidKey, key)
+		}
+	}
+	return key, nil
+}
@@ -0,0 +1,343 @@
+package studentid
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Get(ctx context.Context, userID uuid.UUID) (StudentID, error)
+	Set(ctx context.Context, userID uuid.UUID, value string) (StudentID, error)
+	Delete(ctx context.Context, userID uuid.UUID) error
+	ListPending(ctx context.Context, orgID uuid.UUID, adminID uuid.UUID) ([]ListPendingByOrgRow, error)
+	Verify(ctx context.Context, orgID uuid.UUID, adminID uuid.UUID, userID uuid.UUID, value string) (StudentID, error)
+	Reject(ctx context.Context, orgID uuid.UUID, adminID uuid.UUID, userID uuid.UUID) error
+	Lookup(ctx context.Context, orgID uuid.UUID, adminID uuid.UUID, value string) (LookupVerifiedInOrgRow, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+type Request struct {
+	StudentID string `json:"studentId" validate:"required,alphanum,max=32"`
+}
+
+type Response struct {
+	StudentID  string     `json:"studentId"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verifiedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+type PendingResponse struct {
+	User      user.ProfileResponse `json:"user"`
+	StudentID string               `json:"studentId"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+type LookupResponse struct {
+	User       user.ProfileResponse `json:"user"`
+	StudentID  string               `json:"studentId"`
+	VerifiedAt time.Time            `json:"verifiedAt"`
+}
+
+func optionalTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func ToResponse(studentID StudentID) Response {
+	return Response{
+		StudentID:  studentID.Value,
+		Verified:   studentID.VerifiedAt.Valid,
+		VerifiedAt: optionalTime(studentID.VerifiedAt),
+		CreatedAt:  studentID.CreatedAt.Time,
+		UpdatedAt:  studentID.UpdatedAt.Time,
+	}
+}
+
+func ToPendingResponse(row ListPendingByOrgRow) PendingResponse {
+	return PendingResponse{
+		User: user.ProfileResponse{
+			ID:        row.UserID,
+			Name:      row.Name.String,
+			Username:  row.Username.String,
+			AvatarURL: row.AvatarUrl.String,
+			Emails:    user.ConvertEmailsToSlice(row.Emails),
+		},
+		StudentID: row.Value,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+}
+
+func ToLookupResponse(row LookupVerifiedInOrgRow) LookupResponse {
+	return LookupResponse{
+		User: user.ProfileResponse{
+			ID:        row.UserID,
+			Name:      row.Name.String,
+			Username:  row.Username.String,
+			AvatarURL: row.AvatarUrl.String,
+			Emails:    user.ConvertEmailsToSlice(row.Emails),
+		},
+		StudentID:  row.Value,
+		VerifiedAt: row.VerifiedAt.Time,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("studentid/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+func (h *Handler) orgID(ctx context.Context) (uuid.UUID, error) {
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return orgID, nil
+}
+
+func (h *Handler) GetMeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetMeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	studentID, err := h.store.Get(traceCtx, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(studentID))
+}
+
+func (h *Handler) SetMeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetMeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	var req Request
+	err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	studentID, err := h.store.Set(traceCtx, currentUser.ID, req.StudentID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(studentID))
+}
+
+func (h *Handler) DeleteMeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteMeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	err := h.store.Delete(traceCtx, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// ListPendingHandler lists the student IDs of the organization's members still waiting for verification
+func (h *Handler) ListPendingHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListPendingHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.orgID(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	pending, err := h.store.ListPending(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]PendingResponse, len(pending))
+	for i, row := range pending {
+		response[i] = ToPendingResponse(row)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "VerifyHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.orgID(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	memberID, err := internal.ParseUUID(r.PathValue("member_id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	studentID, err := h.store.Verify(traceCtx, orgID, currentUser.ID, memberID, req.StudentID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(studentID))
+}
+
+func (h *Handler) RejectHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RejectHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.orgID(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	memberID, err := internal.ParseUUID(r.PathValue("member_id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Reject(traceCtx, orgID, currentUser.ID, memberID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+// LookupHandler finds the member holding a verified student ID, used to reconcile
+// form responses against the registrar's lists
+func (h *Handler) LookupHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "LookupHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, err := h.orgID(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	row, err := h.store.Lookup(traceCtx, orgID, currentUser.ID, r.PathValue("studentId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToLookupResponse(row))
+}
@@ -0,0 +1,59 @@
+-- name: GetByUserID :one
+SELECT * FROM student_ids
+WHERE user_id = @user_id;
+
+-- name: Upsert :one
+INSERT INTO student_ids (user_id, value)
+VALUES (@user_id, @value)
+ON CONFLICT (user_id) DO UPDATE
+    SET value = EXCLUDED.value,
+        verified_at = CASE WHEN student_ids.value = EXCLUDED.value THEN student_ids.verified_at END,
+        verified_by = CASE WHEN student_ids.value = EXCLUDED.value THEN student_ids.verified_by END,
+        updated_at = now()
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM student_ids
+WHERE user_id = @user_id;
+
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: IsOrgMember :one
+SELECT EXISTS(
+    SELECT 1 FROM unit_members um
+    JOIN units u ON u.id = um.unit_id
+    WHERE um.member_id = @user_id AND (u.id = @org_id OR u.org_id = @org_id)
+);
+
+-- name: ListPendingByOrg :many
+SELECT s.user_id, s.value, s.created_at, s.updated_at, u.name, u.username, u.avatar_url, u.emails
+FROM student_ids s
+JOIN users_with_emails u ON u.id = s.user_id
+WHERE s.verified_at IS NULL
+  AND EXISTS(
+    SELECT 1 FROM unit_members um
+    JOIN units un ON un.id = um.unit_id
+    WHERE um.member_id = s.user_id AND (un.id = @org_id OR un.org_id = @org_id)
+  )
+ORDER BY s.updated_at;
+
+-- name: Verify :one
+UPDATE student_ids
+SET verified_at = now(),
+    verified_by = @verified_by,
+    updated_at = now()
+WHERE user_id = @user_id AND value = @value
+RETURNING *;
+
+-- name: LookupVerifiedInOrg :one
+SELECT s.user_id, s.value, s.verified_at, u.name, u.username, u.avatar_url, u.emails
+FROM student_ids s
+JOIN users_with_emails u ON u.id = s.user_id
+WHERE s.value = @value
+  AND s.verified_at IS NOT NULL
+  AND EXISTS(
+    SELECT 1 FROM unit_members um
+    JOIN units un ON un.id = um.unit_id
+    WHERE um.member_id = s.user_id AND (un.id = @org_id OR un.org_id = @org_id)
+  );
\ No newline at end of file
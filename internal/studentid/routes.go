@@ -0,0 +1,18 @@
+package studentid
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the student ID of the current user and its verification by organization admins
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /users/me/student-id", route.Authenticated, route.PermissionSelf, h.GetMeHandler)
+	r.Handle("PUT /users/me/student-id", route.Authenticated, route.PermissionSelf, h.SetMeHandler)
+	r.Handle("DELETE /users/me/student-id", route.Authenticated, route.PermissionSelf, h.DeleteMeHandler)
+
+	// Verification routes
+	r.Handle("GET /orgs/{slug}/student-ids/pending", route.TenantAuthenticated, route.PermissionOrgAdmin, h.ListPendingHandler)
+	r.Handle("GET /orgs/{slug}/student-ids/{studentId}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.LookupHandler)
+	r.Handle("POST /orgs/{slug}/members/{member_id}/student-id/verify", route.TenantAuthenticated, route.PermissionOrgAdmin, h.VerifyHandler)
+	r.Handle("DELETE /orgs/{slug}/members/{member_id}/student-id", route.TenantAuthenticated, route.PermissionOrgAdmin, h.RejectHandler)
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS student_ids (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    value VARCHAR(32) NOT NULL,
+    verified_at TIMESTAMPTZ DEFAULT NULL,
+    verified_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Anyone can claim an ID, but only one account can hold it verified
+CREATE UNIQUE INDEX idx_student_ids_verified_value ON student_ids(value) WHERE verified_at IS NOT NULL;
+CREATE INDEX idx_student_ids_value ON student_ids(value);
\ No newline at end of file
@@ -0,0 +1,265 @@
+package studentid
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"strings"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) (StudentID, error)
+	Upsert(ctx context.Context, arg UpsertParams) (StudentID, error)
+	Delete(ctx context.Context, userID uuid.UUID) (int64, error)
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	IsOrgMember(ctx context.Context, arg IsOrgMemberParams) (bool, error)
+	ListPendingByOrg(ctx context.Context, orgID uuid.UUID) ([]ListPendingByOrgRow, error)
+	Verify(ctx context.Context, arg VerifyParams) (StudentID, error)
+	LookupVerifiedInOrg(ctx context.Context, arg LookupVerifiedInOrgParams) (LookupVerifiedInOrgRow, error)
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("studentid/service"),
+	}
+}
+
+// normalize makes IDs typed by users comparable with the registrar's lists
+func normalize(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
+func (s *Service) Get(ctx context.Context, userID uuid.UUID) (StudentID, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	studentID, err := s.queries.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrStudentIDNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "student_ids", "user_id", userID.String(), logger, "get student id")
+		}
+		span.RecordError(err)
+		return StudentID{}, err
+	}
+
+	return studentID, nil
+}
+
+// Set claims a student ID for the user. Changing the value drops its verification.
+func (s *Service) Set(ctx context.Context, userID uuid.UUID, value string) (StudentID, error) {
+	ctx, span := s.tracer.Start(ctx, "Set")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	studentID, err := s.queries.Upsert(ctx, UpsertParams{
+		UserID: userID,
+		Value:  normalize(value),
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "student_ids", "user_id", userID.String(), logger, "set student id")
+		span.RecordError(err)
+		return StudentID{}, err
+	}
+
+	logger.Info("Set student id", zap.String("user_id", userID.String()), zap.Bool("verified", studentID.VerifiedAt.Valid))
+
+	return studentID, nil
+}
+
+func (s *Service) Delete(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	rows, err := s.queries.Delete(ctx, userID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "student_ids", "user_id", userID.String(), logger, "delete student id")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrStudentIDNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
+
+// ListPending lists the unverified student IDs of the members of an organization
+func (s *Service) ListPending(ctx context.Context, orgID uuid.UUID, adminID uuid.UUID) ([]ListPendingByOrgRow, error) {
+	ctx, span := s.tracer.Start(ctx, "ListPending")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireAdmin(ctx, logger, orgID, adminID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	pending, err := s.queries.ListPendingByOrg(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "student_ids", "org_id", orgID.String(), logger, "list pending student ids")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// Verify confirms the student ID claimed by a member of the organization. The value
+// the admin checked is compared with the claim, so a claim changed in the meantime
+// is not verified by accident.
+func (s *Service) Verify(ctx context.Context, orgID uuid.UUID, adminID uuid.UUID, userID uuid.UUID, value string) (StudentID, error) {
+	ctx, span := s.tracer.Start(ctx, "Verify")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, adminID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return StudentID{}, err
+	}
+
+	studentID, err := s.queries.Verify(ctx, VerifyParams{
+		VerifiedBy: pgtype.UUID{Bytes: adminID, Valid: true},
+		UserID:     userID,
+		Value:      normalize(value),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrStudentIDNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "student_ids", "user_id", userID.String(), logger, "verify student id")
+			if errors.Is(err, databaseutil.ErrUniqueViolation) {
+				err = internal.ErrStudentIDTaken
+			}
+		}
+		span.RecordError(err)
+		return StudentID{}, err
+	}
+
+	logger.Info("Verified student id",
+		zap.String("user_id", userID.String()),
+		zap.String("verified_by", adminID.String()),
+		zap.String("org_id", orgID.String()))
+
+	return studentID, nil
+}
+
+// Reject removes the student ID of a member so they can enter it again
+func (s *Service) Reject(ctx context.Context, orgID uuid.UUID, adminID uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Reject")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, adminID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Delete(ctx, userID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "student_ids", "user_id", userID.String(), logger, "reject student id")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrStudentIDNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Rejected student id",
+		zap.String("user_id", userID.String()),
+		zap.String("rejected_by", adminID.String()))
+
+	return nil
+}
+
+// Lookup finds the member of the organization holding a verified student ID
+func (s *Service) Lookup(ctx context.Context, orgID uuid.UUID, adminID uuid.UUID, value string) (LookupVerifiedInOrgRow, error) {
+	ctx, span := s.tracer.Start(ctx, "Lookup")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireAdmin(ctx, logger, orgID, adminID)
+	if err != nil {
+		span.RecordError(err)
+		return LookupVerifiedInOrgRow{}, err
+	}
+
+	row, err := s.queries.LookupVerifiedInOrg(ctx, LookupVerifiedInOrgParams{
+		Value: normalize(value),
+		OrgID: orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrStudentIDNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "student_ids", "org_id", orgID.String(), logger, "lookup student id")
+		}
+		span.RecordError(err)
+		return LookupVerifiedInOrgRow{}, err
+	}
+
+	return row, nil
+}
+
+// requireAdmin allows the owner of the organization only
+func (s *Service) requireAdmin(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsOrgAdmin(ctx, IsOrgAdminParams{
+		OrgID:  orgID,
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check organization admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+// requireMember checks the admin and that the user belongs to the organization, so
+// admins only act on the student IDs of their own members
+func (s *Service) requireMember(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, adminID uuid.UUID, userID uuid.UUID) error {
+	err := s.requireAdmin(ctx, logger, orgID, adminID)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := s.queries.IsOrgMember(ctx, IsOrgMemberParams{
+		UserID: userID,
+		OrgID:  orgID,
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "member_id", userID.String(), logger, "check organization member")
+	}
+	if !isMember {
+		return internal.ErrStudentIDNotFound
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+-- name: Create :one
+INSERT INTO tags (org_id, name, color)
+VALUES (@org_id, @name, @color)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM tags
+WHERE id = @id AND org_id = @org_id;
+
+-- name: ListByOrg :many
+SELECT t.*,
+       (SELECT COUNT(*) FROM form_tags ft WHERE ft.tag_id = t.id)::bigint AS form_count,
+       (SELECT COUNT(*) FROM inbox_message_tags mt WHERE mt.tag_id = t.id)::bigint AS message_count
+FROM tags t
+WHERE t.org_id = @org_id
+ORDER BY t.name;
+
+-- name: Update :one
+UPDATE tags
+SET name = @name,
+    color = @color,
+    updated_at = now()
+WHERE id = @id AND org_id = @org_id
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM tags
+WHERE id = @id AND org_id = @org_id;
+
+-- name: CountOrgTags :one
+SELECT COUNT(*) AS total
+FROM tags
+WHERE id = ANY(@tag_ids::uuid[]) AND org_id = @org_id;
+
+-- name: IsFormInOrg :one
+SELECT EXISTS (
+    SELECT 1
+    FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    WHERE f.id = @form_id AND (u.id = @org_id OR u.org_id = @org_id)
+);
+
+-- name: IsMessageInOrg :one
+SELECT EXISTS (
+    SELECT 1
+    FROM inbox_message m
+    JOIN units u ON u.id = m.posted_by
+    WHERE m.id = @message_id AND (u.id = @org_id OR u.org_id = @org_id)
+);
+
+-- name: ListFormTags :many
+SELECT t.*
+FROM form_tags ft
+JOIN tags t ON t.id = ft.tag_id
+WHERE ft.form_id = @form_id
+ORDER BY t.name;
+
+-- name: DeleteFormTags :exec
+DELETE FROM form_tags
+WHERE form_id = @form_id;
+
+-- name: AddFormTags :exec
+INSERT INTO form_tags (form_id, tag_id)
+SELECT @form_id::uuid, unnest(@tag_ids::uuid[])
+ON CONFLICT (form_id, tag_id) DO NOTHING;
+
+-- name: ListMessageTags :many
+SELECT t.*
+FROM inbox_message_tags mt
+JOIN tags t ON t.id = mt.tag_id
+WHERE mt.message_id = @message_id
+ORDER BY t.name;
+
+-- name: DeleteMessageTags :exec
+DELETE FROM inbox_message_tags
+WHERE message_id = @message_id;
+
+-- name: AddMessageTags :exec
+INSERT INTO inbox_message_tags (message_id, tag_id)
+SELECT @message_id::uuid, unnest(@tag_ids::uuid[])
+ON CONFLICT (message_id, tag_id) DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,18 @@
+package tag
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the tags of an organization and the tags set on its forms and messages
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/tags", route.TenantAuthenticated, route.PermissionNone, h.ListHandler)
+	r.Handle("POST /orgs/{slug}/tags", route.TenantAuthenticated, route.PermissionNone, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/tags/{id}", route.TenantAuthenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /orgs/{slug}/tags/{id}", route.TenantAuthenticated, route.PermissionNone, h.UpdateHandler)
+	r.Handle("DELETE /orgs/{slug}/tags/{id}", route.TenantAuthenticated, route.PermissionNone, h.DeleteHandler)
+	r.Handle("GET /orgs/{slug}/forms/{id}/tags", route.TenantAuthenticated, route.PermissionNone, h.ListFormTagsHandler)
+	r.Handle("PUT /orgs/{slug}/forms/{id}/tags", route.TenantAuthenticated, route.PermissionNone, h.SetFormTagsHandler)
+	r.Handle("GET /orgs/{slug}/messages/{id}/tags", route.TenantAuthenticated, route.PermissionNone, h.ListMessageTagsHandler)
+	r.Handle("PUT /orgs/{slug}/messages/{id}/tags", route.TenantAuthenticated, route.PermissionNone, h.SetMessageTagsHandler)
+}
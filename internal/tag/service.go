@@ -0,0 +1,348 @@
+package tag
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (Tag, error)
+	GetByID(ctx context.Context, arg GetByIDParams) (Tag, error)
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]ListByOrgRow, error)
+	Update(ctx context.Context, arg UpdateParams) (Tag, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	CountOrgTags(ctx context.Context, arg CountOrgTagsParams) (int64, error)
+	IsFormInOrg(ctx context.Context, arg IsFormInOrgParams) (bool, error)
+	IsMessageInOrg(ctx context.Context, arg IsMessageInOrgParams) (bool, error)
+	ListFormTags(ctx context.Context, formID uuid.UUID) ([]Tag, error)
+	DeleteFormTags(ctx context.Context, formID uuid.UUID) error
+	AddFormTags(ctx context.Context, arg AddFormTagsParams) error
+	ListMessageTags(ctx context.Context, messageID uuid.UUID) ([]Tag, error)
+	DeleteMessageTags(ctx context.Context, messageID uuid.UUID) error
+	AddMessageTags(ctx context.Context, arg AddMessageTagsParams) error
+}
+
+// Input describes an organization tag such as "recruitment" or "finance"
+type Input struct {
+	Name  string
+	Color string
+}
+
+type Service struct {
+	logger  *zap.Logger
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DBTX) *Service {
+	return &Service{
+		logger:  logger,
+		queries: New(db),
+		tracer:  otel.Tracer("tag/service"),
+	}
+}
+
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, input Input) (Tag, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	tag, err := s.queries.Create(ctx, CreateParams{
+		OrgID: orgID,
+		Name:  input.Name,
+		Color: input.Color,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "tags", "name", input.Name, logger, "create tag")
+		if errors.Is(err, databaseutil.ErrUniqueViolation) {
+			err = internal.ErrTagNameTaken
+		}
+		span.RecordError(err)
+		return Tag{}, err
+	}
+
+	logger.Info("Created tag", zap.String("tag_id", tag.ID.String()), zap.String("org_id", orgID.String()))
+
+	return tag, nil
+}
+
+func (s *Service) List(ctx context.Context, orgID uuid.UUID) ([]ListByOrgRow, error) {
+	ctx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	tags, err := s.queries.ListByOrg(ctx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "tags", "org_id", orgID.String(), logger, "list tags")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (Tag, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	tag, err := s.queries.GetByID(ctx, GetByIDParams{ID: id, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrTagNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "tags", "id", id.String(), logger, "get tag")
+		}
+		span.RecordError(err)
+		return Tag{}, err
+	}
+
+	return tag, nil
+}
+
+func (s *Service) Update(ctx context.Context, orgID uuid.UUID, id uuid.UUID, input Input) (Tag, error) {
+	ctx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	tag, err := s.queries.Update(ctx, UpdateParams{
+		Name:  input.Name,
+		Color: input.Color,
+		ID:    id,
+		OrgID: orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrTagNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "tags", "id", id.String(), logger, "update tag")
+			if errors.Is(err, databaseutil.ErrUniqueViolation) {
+				err = internal.ErrTagNameTaken
+			}
+		}
+		span.RecordError(err)
+		return Tag{}, err
+	}
+
+	logger.Info("Updated tag", zap.String("tag_id", id.String()))
+
+	return tag, nil
+}
+
+// Delete removes the tag, detaching it from every form and message carrying it
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	rows, err := s.queries.Delete(ctx, DeleteParams{ID: id, OrgID: orgID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "tags", "id", id.String(), logger, "delete tag")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrTagNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted tag", zap.String("tag_id", id.String()))
+
+	return nil
+}
+
+func (s *Service) ListFormTags(ctx context.Context, orgID uuid.UUID, formID uuid.UUID) ([]Tag, error) {
+	ctx, span := s.tracer.Start(ctx, "ListFormTags")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.checkForm(ctx, logger, orgID, formID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tags, err := s.queries.ListFormTags(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_tags", "form_id", formID.String(), logger, "list form tags")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// SetFormTags replaces the tags of a form of the organization
+func (s *Service) SetFormTags(ctx context.Context, orgID uuid.UUID, formID uuid.UUID, tagIDs []uuid.UUID) ([]Tag, error) {
+	ctx, span := s.tracer.Start(ctx, "SetFormTags")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.checkForm(ctx, logger, orgID, formID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tagIDs, err = s.validateTags(ctx, logger, orgID, tagIDs)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	err = s.queries.DeleteFormTags(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_tags", "form_id", formID.String(), logger, "delete form tags")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(tagIDs) > 0 {
+		err = s.queries.AddFormTags(ctx, AddFormTagsParams{FormID: formID, TagIds: tagIDs})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "form_tags", "form_id", formID.String(), logger, "add form tags")
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	tags, err := s.queries.ListFormTags(ctx, formID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "form_tags", "form_id", formID.String(), logger, "list form tags")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	logger.Info("Set form tags", zap.String("form_id", formID.String()), zap.Int("tags", len(tags)))
+
+	return tags, nil
+}
+
+func (s *Service) ListMessageTags(ctx context.Context, orgID uuid.UUID, messageID uuid.UUID) ([]Tag, error) {
+	ctx, span := s.tracer.Start(ctx, "ListMessageTags")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.checkMessage(ctx, logger, orgID, messageID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tags, err := s.queries.ListMessageTags(ctx, messageID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "inbox_message_tags", "message_id", messageID.String(), logger, "list message tags")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// SetMessageTags replaces the tags of an inbox message posted by the organization or
+// one of its units
+func (s *Service) SetMessageTags(ctx context.Context, orgID uuid.UUID, messageID uuid.UUID, tagIDs []uuid.UUID) ([]Tag, error) {
+	ctx, span := s.tracer.Start(ctx, "SetMessageTags")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.checkMessage(ctx, logger, orgID, messageID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tagIDs, err = s.validateTags(ctx, logger, orgID, tagIDs)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	err = s.queries.DeleteMessageTags(ctx, messageID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "inbox_message_tags", "message_id", messageID.String(), logger, "delete message tags")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(tagIDs) > 0 {
+		err = s.queries.AddMessageTags(ctx, AddMessageTagsParams{MessageID: messageID, TagIds: tagIDs})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "inbox_message_tags", "message_id", messageID.String(), logger, "add message tags")
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	tags, err := s.queries.ListMessageTags(ctx, messageID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "inbox_message_tags", "message_id", messageID.String(), logger, "list message tags")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	logger.Info("Set message tags", zap.String("message_id", messageID.String()), zap.Int("tags", len(tags)))
+
+	return tags, nil
+}
+
+func (s *Service) checkForm(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, formID uuid.UUID) error {
+	ok, err := s.queries.IsFormInOrg(ctx, IsFormInOrgParams{FormID: formID, OrgID: orgID})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check form organization")
+	}
+	if !ok {
+		return internal.ErrFormNotFound
+	}
+	return nil
+}
+
+func (s *Service) checkMessage(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, messageID uuid.UUID) error {
+	ok, err := s.queries.IsMessageInOrg(ctx, IsMessageInOrgParams{MessageID: messageID, OrgID: orgID})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "inbox_message", "id", messageID.String(), logger, "check message organization")
+	}
+	if !ok {
+		return internal.ErrInboxMessageNotFound
+	}
+	return nil
+}
+
+// validateTags deduplicates the tag IDs and checks that each of them is a tag of the
+// organization
+func (s *Service) validateTags(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, tagIDs []uuid.UUID) ([]uuid.UUID, error) {
+	unique := make([]uuid.UUID, 0, len(tagIDs))
+	seen := make(map[uuid.UUID]struct{}, len(tagIDs))
+	for _, id := range tagIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	count, err := s.queries.CountOrgTags(ctx, CountOrgTagsParams{TagIds: unique, OrgID: orgID})
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "tags", "org_id", orgID.String(), logger, "count organization tags")
+	}
+	if count != int64(len(unique)) {
+		return nil, fmt.Errorf("%w: %d of %d tags", internal.ErrTagNotInOrg, int64(len(unique))-count, len(unique))
+	}
+
+	return unique, nil
+}
@@ -0,0 +1,377 @@
+package task
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Create(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, input Input, userID uuid.UUID) (Detail, error)
+	List(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, filter Filter, userID uuid.UUID) ([]Detail, error)
+	Get(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Detail, error)
+	Update(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, id uuid.UUID, input Input, userID uuid.UUID) (Detail, error)
+	Delete(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID) error
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+type Request struct {
+	Title       string      `json:"title" validate:"required,max=255"`
+	Description string      `json:"description" validate:"max=10000"`
+	Status      string      `json:"status" validate:"omitempty,oneof=todo in_progress done cancelled"`
+	DueAt       *time.Time  `json:"dueAt"`
+	FormID      string      `json:"formId" validate:"omitempty,uuid"`
+	ResponseID  string      `json:"responseId" validate:"omitempty,uuid"`
+	AssigneeIDs []uuid.UUID `json:"assigneeIds"`
+}
+
+type Response struct {
+	ID          string                 `json:"id"`
+	UnitID      string                 `json:"unitId"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Status      string                 `json:"status"`
+	DueAt       *time.Time             `json:"dueAt,omitempty"`
+	FormID      *string                `json:"formId,omitempty"`
+	ResponseID  *string                `json:"responseId,omitempty"`
+	Assignees   []user.ProfileResponse `json:"assignees"`
+	CreatedBy   *string                `json:"createdBy,omitempty"`
+	CompletedAt *time.Time             `json:"completedAt,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
+func optionalID(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	value := uuid.UUID(id.Bytes).String()
+	return &value
+}
+
+func optionalTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func ToResponse(task Detail) Response {
+	assignees := make([]user.ProfileResponse, 0, len(task.Assignees))
+	for _, assignee := range task.Assignees {
+		assignees = append(assignees, user.ProfileResponse(assignee))
+	}
+
+	return Response{
+		ID:          task.ID.String(),
+		UnitID:      task.UnitID.String(),
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      string(task.Status),
+		DueAt:       optionalTime(task.DueAt),
+		FormID:      optionalID(task.FormID),
+		ResponseID:  optionalID(task.ResponseID),
+		Assignees:   assignees,
+		CreatedBy:   optionalID(task.CreatedBy),
+		CompletedAt: optionalTime(task.CompletedAt),
+		CreatedAt:   task.CreatedAt.Time,
+		UpdatedAt:   task.UpdatedAt.Time,
+	}
+}
+
+// ToInput converts the request; the IDs are expected to have passed validation
+func (r Request) ToInput() Input {
+	status := TaskStatusTodo
+	if r.Status != "" {
+		status = TaskStatus(r.Status)
+	}
+
+	input := Input{
+		Title:       strings.TrimSpace(r.Title),
+		Description: r.Description,
+		Status:      status,
+		DueAt:       r.DueAt,
+		AssigneeIDs: r.AssigneeIDs,
+	}
+	if r.FormID != "" {
+		input.FormID = uuid.MustParse(r.FormID)
+	}
+	if r.ResponseID != "" {
+		input.ResponseID = uuid.MustParse(r.ResponseID)
+	}
+	return input
+}
+
+// ParseFilter reads the status, assigneeId and formId query parameters. An assigneeId
+// of "me" stands for the current user.
+func ParseFilter(r *http.Request, currentUserID uuid.UUID) (Filter, error) {
+	query := r.URL.Query()
+	var filter Filter
+
+	if status := query.Get("status"); status != "" {
+		switch TaskStatus(status) {
+		case TaskStatusTodo, TaskStatusInProgress, TaskStatusDone, TaskStatusCancelled:
+			filter.Status = TaskStatus(status)
+		default:
+			return Filter{}, fmt.Errorf("%w: status must be one of todo, in_progress, done and cancelled", internal.ErrInvalidQueryParameter)
+		}
+	}
+
+	if assigneeID := query.Get("assigneeId"); assigneeID == "me" {
+		filter.AssigneeID = currentUserID
+	} else if assigneeID != "" {
+		id, err := uuid.Parse(assigneeID)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%w: assigneeId must be a UUID or me", internal.ErrInvalidQueryParameter)
+		}
+		filter.AssigneeID = id
+	}
+
+	if formID := query.Get("formId"); formID != "" {
+		id, err := uuid.Parse(formID)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%w: formId must be a UUID", internal.ErrInvalidQueryParameter)
+		}
+		filter.FormID = id
+	}
+
+	return filter, nil
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("task/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// unit resolves the organization of the slug and the unit in the path
+func (h *Handler) unit(ctx context.Context, r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+
+	return orgID, unitID, nil
+}
+
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, unitID, err := h.unit(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	task, err := h.store.Create(traceCtx, orgID, unitID, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(task))
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, unitID, err := h.unit(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	filter, err := ParseFilter(r, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	tasks, err := h.store.List(traceCtx, orgID, unitID, filter, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]Response, len(tasks))
+	for i, task := range tasks {
+		response[i] = ToResponse(task)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, unitID, err := h.unit(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("taskId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	task, err := h.store.Get(traceCtx, orgID, unitID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(task))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, unitID, err := h.unit(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("taskId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req Request
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	task, err := h.store.Update(traceCtx, orgID, unitID, id, req.ToInput(), currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(task))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+
+	orgID, unitID, err := h.unit(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	id, err := internal.ParseUUID(r.PathValue("taskId"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, orgID, unitID, id, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
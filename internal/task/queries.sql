@@ -0,0 +1,85 @@
+-- name: GetMembership :one
+SELECT EXISTS(
+    SELECT 1 FROM unit_members
+    WHERE unit_id = u.id AND member_id = @user_id
+) AS is_member
+FROM units u
+WHERE u.id = @unit_id AND (u.id = @org_id OR u.org_id = @org_id);
+
+-- name: CountUnitMembers :one
+SELECT COUNT(DISTINCT member_id) AS total
+FROM unit_members
+WHERE unit_id = @unit_id AND member_id = ANY(@user_ids::uuid[]);
+
+-- name: FormInOrg :one
+SELECT EXISTS(
+    SELECT 1 FROM forms f
+    JOIN units u ON u.id = f.unit_id
+    WHERE f.id = @form_id AND (u.id = @org_id OR u.org_id = @org_id)
+);
+
+-- name: GetResponseFormID :one
+SELECT form_id FROM form_responses
+WHERE id = @response_id;
+
+-- name: Create :one
+INSERT INTO tasks (unit_id, title, description, status, due_at, form_id, response_id, created_by, completed_at)
+VALUES (
+    @unit_id, @title, @description, @status, @due_at, @form_id, @response_id, @created_by,
+    CASE WHEN @status::task_status = 'done' THEN now() END
+)
+RETURNING *;
+
+-- name: GetByID :one
+SELECT * FROM tasks
+WHERE id = @id AND unit_id = @unit_id;
+
+-- name: List :many
+SELECT t.* FROM tasks t
+WHERE t.unit_id = @unit_id
+  AND (sqlc.narg(status)::task_status IS NULL OR t.status = sqlc.narg(status))
+  AND (sqlc.narg(form_id)::uuid IS NULL OR t.form_id = sqlc.narg(form_id))
+  AND (sqlc.narg(assignee_id)::uuid IS NULL OR EXISTS(
+      SELECT 1 FROM task_assignees a
+      WHERE a.task_id = t.id AND a.user_id = sqlc.narg(assignee_id)
+  ))
+ORDER BY t.due_at ASC NULLS LAST, t.created_at ASC;
+
+-- name: Update :one
+UPDATE tasks
+SET title = @title,
+    description = @description,
+    status = @status,
+    due_at = @due_at,
+    form_id = @form_id,
+    response_id = @response_id,
+    completed_at = CASE WHEN @status::task_status = 'done' THEN COALESCE(completed_at, now()) END,
+    updated_at = now()
+WHERE id = @id AND unit_id = @unit_id
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM tasks
+WHERE id = @id AND unit_id = @unit_id;
+
+-- name: ListAssignees :many
+SELECT a.task_id,
+       u.id,
+       u.name,
+       u.username,
+       u.avatar_url,
+       u.emails
+FROM task_assignees a
+JOIN users_with_emails u ON u.id = a.user_id
+WHERE a.task_id = ANY(@task_ids::uuid[])
+ORDER BY a.assigned_at, u.id;
+
+-- name: AddAssignees :many
+INSERT INTO task_assignees (task_id, user_id)
+SELECT @task_id::uuid, unnest(@user_ids::uuid[])
+ON CONFLICT (task_id, user_id) DO NOTHING
+RETURNING user_id;
+
+-- name: RemoveAssignees :exec
+DELETE FROM task_assignees
+WHERE task_id = @task_id AND NOT (user_id = ANY(@keep_ids::uuid[]));
\ No newline at end of file
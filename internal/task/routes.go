@@ -0,0 +1,14 @@
+package task
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the tasks of a unit
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/units/{id}/tasks", route.TenantAuthenticated, route.PermissionUnitMember, h.ListHandler)
+	r.Handle("POST /orgs/{slug}/units/{id}/tasks", route.TenantAuthenticated, route.PermissionUnitMember, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/units/{id}/tasks/{taskId}", route.TenantAuthenticated, route.PermissionUnitMember, h.GetHandler)
+	r.Handle("PUT /orgs/{slug}/units/{id}/tasks/{taskId}", route.TenantAuthenticated, route.PermissionUnitMember, h.UpdateHandler)
+	r.Handle("DELETE /orgs/{slug}/units/{id}/tasks/{taskId}", route.TenantAuthenticated, route.PermissionUnitMember, h.DeleteHandler)
+}
@@ -0,0 +1,497 @@
+package task
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Querier interface {
+	GetMembership(ctx context.Context, arg GetMembershipParams) (bool, error)
+	CountUnitMembers(ctx context.Context, arg CountUnitMembersParams) (int64, error)
+	FormInOrg(ctx context.Context, arg FormInOrgParams) (bool, error)
+	GetResponseFormID(ctx context.Context, responseID uuid.UUID) (uuid.UUID, error)
+	Create(ctx context.Context, arg CreateParams) (Task, error)
+	GetByID(ctx context.Context, arg GetByIDParams) (Task, error)
+	List(ctx context.Context, arg ListParams) ([]Task, error)
+	Update(ctx context.Context, arg UpdateParams) (Task, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	ListAssignees(ctx context.Context, taskIds []uuid.UUID) ([]ListAssigneesRow, error)
+	AddAssignees(ctx context.Context, arg AddAssigneesParams) ([]uuid.UUID, error)
+	RemoveAssignees(ctx context.Context, arg RemoveAssigneesParams) error
+}
+
+// DB is the connection the service runs on; a task and its assignees are written in
+// one transaction begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type InboxStore interface {
+	Create(ctx context.Context, contentType inbox.ContentType, contentID uuid.UUID, userIDs []uuid.UUID, postByUnitID uuid.UUID) (uuid.UUID, error)
+}
+
+// Input describes a task. FormID and ResponseID link it to what it is about and are
+// uuid.Nil when it is about neither; a response alone links its form as well.
+// AssigneeIDs replaces the whole set of assignees and may only contain members of the
+// unit.
+type Input struct {
+	Title       string
+	Description string
+	Status      TaskStatus
+	DueAt       *time.Time
+	FormID      uuid.UUID
+	ResponseID  uuid.UUID
+	AssigneeIDs []uuid.UUID
+}
+
+// Filter narrows the tasks of a unit; zero fields match every task
+type Filter struct {
+	Status     TaskStatus
+	AssigneeID uuid.UUID
+	FormID     uuid.UUID
+}
+
+// Detail is a task together with the people assigned to it
+type Detail struct {
+	Task
+	Assignees []user.Profile
+}
+
+type Service struct {
+	logger     *zap.Logger
+	db         DB
+	queries    Querier
+	tracer     trace.Tracer
+	inboxStore InboxStore
+}
+
+func NewService(logger *zap.Logger, db DB, inboxStore InboxStore) *Service {
+	return &Service{
+		logger:     logger,
+		db:         db,
+		queries:    New(db),
+		tracer:     otel.Tracer("task/service"),
+		inboxStore: inboxStore,
+	}
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+// requireMember allows the members of the unit only. A unit outside the organization
+// is not found.
+func (s *Service) requireMember(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID) error {
+	isMember, err := s.queries.GetMembership(ctx, GetMembershipParams{
+		UserID: userID,
+		UnitID: unitID,
+		OrgID:  orgID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return internal.ErrUnitNotFound
+		}
+		return databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", unitID.String(), logger, "check unit membership")
+	}
+	if !isMember {
+		return fmt.Errorf("%w: only members of unit %s can manage its tasks", internal.ErrPermissionDenied, unitID)
+	}
+	return nil
+}
+
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, input Input, userID uuid.UUID) (Detail, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	assigneeIDs, err := s.validateAssignees(ctx, logger, unitID, input.AssigneeIDs)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	formID, responseID, err := s.resolveLink(ctx, logger, orgID, input.FormID, input.ResponseID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	var (
+		task  Task
+		added []uuid.UUID
+	)
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		task, err = queries.Create(ctx, CreateParams{
+			UnitID:      unitID,
+			Title:       input.Title,
+			Description: input.Description,
+			Status:      input.Status,
+			DueAt:       dueAt(input.DueAt),
+			FormID:      formID,
+			ResponseID:  responseID,
+			CreatedBy:   pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "tasks", "unit_id", unitID.String(), logger, "create task")
+		}
+
+		added, err = s.assign(ctx, logger, queries, task.ID, assigneeIDs)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	s.notifyAssigned(ctx, logger, task, added, userID)
+
+	logger.Info("Created task",
+		zap.String("task_id", task.ID.String()),
+		zap.String("unit_id", unitID.String()),
+		zap.Int("assignees", len(assigneeIDs)))
+
+	return s.detail(ctx, logger, task)
+}
+
+// List returns the tasks of the unit matching the filter, the ones due soonest first
+// and the ones without a due date last
+func (s *Service) List(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, filter Filter, userID uuid.UUID) ([]Detail, error) {
+	ctx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tasks, err := s.queries.List(ctx, ListParams{
+		UnitID:     unitID,
+		Status:     NullTaskStatus{TaskStatus: filter.Status, Valid: filter.Status != ""},
+		FormID:     pgtype.UUID{Bytes: filter.FormID, Valid: filter.FormID != uuid.Nil},
+		AssigneeID: pgtype.UUID{Bytes: filter.AssigneeID, Valid: filter.AssigneeID != uuid.Nil},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "tasks", "unit_id", unitID.String(), logger, "list tasks")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	details, err := s.details(ctx, logger, tasks)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return details, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID) (Detail, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	task, err := s.queries.GetByID(ctx, GetByIDParams{ID: id, UnitID: unitID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrTaskNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "tasks", "id", id.String(), logger, "get task")
+		}
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	detail, err := s.detail(ctx, logger, task)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	return detail, nil
+}
+
+// Update replaces the task. Only the people it is newly assigned to are notified.
+func (s *Service) Update(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, id uuid.UUID, input Input, userID uuid.UUID) (Detail, error) {
+	ctx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	assigneeIDs, err := s.validateAssignees(ctx, logger, unitID, input.AssigneeIDs)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	formID, responseID, err := s.resolveLink(ctx, logger, orgID, input.FormID, input.ResponseID)
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	var (
+		task  Task
+		added []uuid.UUID
+	)
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		task, err = queries.Update(ctx, UpdateParams{
+			Title:       input.Title,
+			Description: input.Description,
+			Status:      input.Status,
+			DueAt:       dueAt(input.DueAt),
+			FormID:      formID,
+			ResponseID:  responseID,
+			ID:          id,
+			UnitID:      unitID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return internal.ErrTaskNotFound
+			}
+			return databaseutil.WrapDBErrorWithKeyValue(err, "tasks", "id", id.String(), logger, "update task")
+		}
+
+		err = queries.RemoveAssignees(ctx, RemoveAssigneesParams{TaskID: id, KeepIds: assigneeIDs})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "task_assignees", "task_id", id.String(), logger, "remove task assignees")
+		}
+
+		added, err = s.assign(ctx, logger, queries, id, assigneeIDs)
+		return err
+	})
+	if err != nil {
+		span.RecordError(err)
+		return Detail{}, err
+	}
+
+	s.notifyAssigned(ctx, logger, task, added, userID)
+
+	logger.Info("Updated task", zap.String("task_id", id.String()), zap.String("status", string(task.Status)))
+
+	return s.detail(ctx, logger, task)
+}
+
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, id uuid.UUID, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireMember(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Delete(ctx, DeleteParams{ID: id, UnitID: unitID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "tasks", "id", id.String(), logger, "delete task")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrTaskNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted task", zap.String("task_id", id.String()))
+
+	return nil
+}
+
+// validateAssignees deduplicates the assignee IDs and checks that each of them is a
+// member of the unit
+func (s *Service) validateAssignees(ctx context.Context, logger *zap.Logger, unitID uuid.UUID, assigneeIDs []uuid.UUID) ([]uuid.UUID, error) {
+	unique := make([]uuid.UUID, 0, len(assigneeIDs))
+	seen := make(map[uuid.UUID]struct{}, len(assigneeIDs))
+	for _, id := range assigneeIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	count, err := s.queries.CountUnitMembers(ctx, CountUnitMembersParams{
+		UnitID:  unitID,
+		UserIds: unique,
+	})
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", unitID.String(), logger, "count unit members")
+	}
+	if count != int64(len(unique)) {
+		return nil, fmt.Errorf("%w: %d of %d assignees", internal.ErrAssigneeNotUnitMember, int64(len(unique))-count, len(unique))
+	}
+
+	return unique, nil
+}
+
+// resolveLink checks that the linked form belongs to the organization and that the
+// linked response was made to it, taking the form of the response when none is given
+func (s *Service) resolveLink(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, formID uuid.UUID, responseID uuid.UUID) (pgtype.UUID, pgtype.UUID, error) {
+	if responseID != uuid.Nil {
+		responseFormID, err := s.queries.GetResponseFormID(ctx, responseID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return pgtype.UUID{}, pgtype.UUID{}, fmt.Errorf("%w: response %s not found", internal.ErrTaskLinkInvalid, responseID)
+			}
+			return pgtype.UUID{}, pgtype.UUID{}, databaseutil.WrapDBErrorWithKeyValue(err, "form_responses", "id", responseID.String(), logger, "get response form")
+		}
+		if formID != uuid.Nil && formID != responseFormID {
+			return pgtype.UUID{}, pgtype.UUID{}, fmt.Errorf("%w: response %s is not a response to form %s", internal.ErrTaskLinkInvalid, responseID, formID)
+		}
+		formID = responseFormID
+	}
+
+	if formID == uuid.Nil {
+		return pgtype.UUID{}, pgtype.UUID{}, nil
+	}
+
+	inOrg, err := s.queries.FormInOrg(ctx, FormInOrgParams{FormID: formID, OrgID: orgID})
+	if err != nil {
+		return pgtype.UUID{}, pgtype.UUID{}, databaseutil.WrapDBErrorWithKeyValue(err, "forms", "id", formID.String(), logger, "check form organization")
+	}
+	if !inOrg {
+		return pgtype.UUID{}, pgtype.UUID{}, fmt.Errorf("%w: form %s is not in the organization", internal.ErrTaskLinkInvalid, formID)
+	}
+
+	return pgtype.UUID{Bytes: formID, Valid: true}, pgtype.UUID{Bytes: responseID, Valid: responseID != uuid.Nil}, nil
+}
+
+// assign adds the assignees the task does not have yet and returns them
+func (s *Service) assign(ctx context.Context, logger *zap.Logger, queries Querier, taskID uuid.UUID, assigneeIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(assigneeIDs) == 0 {
+		return nil, nil
+	}
+
+	added, err := queries.AddAssignees(ctx, AddAssigneesParams{TaskID: taskID, UserIds: assigneeIDs})
+	if err != nil {
+		return nil, databaseutil.WrapDBErrorWithKeyValue(err, "task_assignees", "task_id", taskID.String(), logger, "add task assignees")
+	}
+	return added, nil
+}
+
+// notifyAssigned tells the new assignees of the task through their inbox, except the
+// one who assigned it. The task is saved by then, so a failure is logged rather than
+// returned.
+func (s *Service) notifyAssigned(ctx context.Context, logger *zap.Logger, task Task, added []uuid.UUID, assignerID uuid.UUID) {
+	recipients := make([]uuid.UUID, 0, len(added))
+	for _, id := range added {
+		if id != assignerID {
+			recipients = append(recipients, id)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	_, err := s.inboxStore.Create(ctx, inbox.ContentTypeTask, task.ID, recipients, task.UnitID)
+	if err != nil {
+		logger.Warn("Failed to notify task assignees", zap.String("task_id", task.ID.String()), zap.Error(err))
+	}
+}
+
+func (s *Service) detail(ctx context.Context, logger *zap.Logger, task Task) (Detail, error) {
+	details, err := s.details(ctx, logger, []Task{task})
+	if err != nil {
+		return Detail{}, err
+	}
+	return details[0], nil
+}
+
+// details attaches their assignees to the tasks, in one query for all of them
+func (s *Service) details(ctx context.Context, logger *zap.Logger, tasks []Task) ([]Detail, error) {
+	details := make([]Detail, len(tasks))
+	if len(tasks) == 0 {
+		return details, nil
+	}
+
+	ids := make([]uuid.UUID, len(tasks))
+	index := make(map[uuid.UUID]int, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+		index[task.ID] = i
+		details[i] = Detail{Task: task, Assignees: []user.Profile{}}
+	}
+
+	rows, err := s.queries.ListAssignees(ctx, ids)
+	if err != nil {
+		return nil, databaseutil.WrapDBError(err, logger, "list task assignees")
+	}
+	for _, row := range rows {
+		i := index[row.TaskID]
+		details[i].Assignees = append(details[i].Assignees, user.Profile{
+			ID:        row.ID,
+			Name:      row.Name.String,
+			Username:  row.Username.String,
+			AvatarURL: row.AvatarUrl.String,
+			Emails:    user.ConvertEmailsToSlice(row.Emails),
+		})
+	}
+
+	return details, nil
+}
+
+func dueAt(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
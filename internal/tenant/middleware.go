@@ -2,6 +2,7 @@ package tenant
 
 import (
 	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/route"
 	"context"
 	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
@@ -12,13 +13,19 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"net/http"
+	"strings"
 )
 
 type reader interface {
 	Get(ctx context.Context, id uuid.UUID) (Tenant, error)
 	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+	GetSlugByID(ctx context.Context, id uuid.UUID) (string, error)
 }
 
+// idSegment addresses an organization by its ID in place of its slug, as in
+// /api/v1/orgs/id/{id}/units
+const idSegment = "/orgs/id/"
+
 type Middleware struct {
 	tracer       trace.Tracer
 	logger       *zap.Logger
@@ -84,3 +91,52 @@ func (m *Middleware) Middleware(next http.HandlerFunc) http.HandlerFunc {
 		next(w, r.WithContext(ctx))
 	}
 }
+
+// ResolveID rewrites the paths addressing an organization by ID, under any version of
+// the API, to the paths of its current slug before they are routed, so automation that
+// stores IDs keeps working when the slug is renamed. Middleware then resolves the slug
+// as for any other request.
+func (m *Middleware) ResolveID(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefix, after, found := strings.Cut(r.URL.Path, idSegment)
+		if !found || (prefix != route.APIPrefix && prefix != route.V1) {
+			next(w, r)
+			return
+		}
+
+		rawID, rest, hasRest := strings.Cut(after, "/")
+		orgID, err := uuid.Parse(rawID)
+		if err != nil {
+			next(w, r)
+			return
+		}
+
+		traceCtx, span := m.tracer.Start(r.Context(), "ResolveOrgID")
+		logger := logutil.WithContext(traceCtx, m.logger)
+
+		slug, err := m.reader.GetSlugByID(traceCtx, orgID)
+		if err != nil {
+			span.RecordError(err)
+			span.End()
+			problem.New().WriteError(traceCtx, w, err, logger)
+			return
+		}
+		span.End()
+
+		resolved := prefix + "/orgs/" + slug
+		rewritten := new(http.Request)
+		*rewritten = *r
+		u := *r.URL
+		rewritten.URL = &u
+		rewritten.URL.Path = resolved
+		if hasRest {
+			rewritten.URL.Path += "/" + rest
+		}
+		if r.URL.RawPath != "" {
+			_, rawAfter, _ := strings.Cut(r.URL.RawPath, idSegment)
+			_, rawRest, _ := strings.Cut(rawAfter, "/")
+			rewritten.URL.RawPath = resolved + "/" + rawRest
+		}
+		next(w, rewritten)
+	}
+}
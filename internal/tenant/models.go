@@ -12,427 +12,2215 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type ContentType string
+type ActionRunStatus string
 
 const (
-	ContentTypeText ContentType = "text"
-	ContentTypeForm ContentType = "form"
+	ActionRunStatusPending   ActionRunStatus = "pending"
+	ActionRunStatusSucceeded ActionRunStatus = "succeeded"
+	ActionRunStatusFailed    ActionRunStatus = "failed"
 )
 
-func (e *ContentType) Scan(src interface{}) error {
+func (e *ActionRunStatus) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = ContentType(s)
+		*e = ActionRunStatus(s)
 	case string:
-		*e = ContentType(s)
+		*e = ActionRunStatus(s)
 	default:
-		return fmt.Errorf("unsupported scan type for ContentType: %T", src)
+		return fmt.Errorf("unsupported scan type for ActionRunStatus: %T", src)
 	}
 	return nil
 }
 
-type NullContentType struct {
-	ContentType ContentType
-	Valid       bool // Valid is true if ContentType is not NULL
+type NullActionRunStatus struct {
+	ActionRunStatus ActionRunStatus
+	Valid           bool // Valid is true if ActionRunStatus is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullContentType) Scan(value interface{}) error {
+func (ns *NullActionRunStatus) Scan(value interface{}) error {
 	if value == nil {
-		ns.ContentType, ns.Valid = "", false
+		ns.ActionRunStatus, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.ContentType.Scan(value)
+	return ns.ActionRunStatus.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullContentType) Value() (driver.Value, error) {
+func (ns NullActionRunStatus) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.ContentType), nil
+	return string(ns.ActionRunStatus), nil
 }
 
-type DbStrategy string
+type AnnouncementVisibility string
 
 const (
-	DbStrategyShared   DbStrategy = "shared"
-	DbStrategyIsolated DbStrategy = "isolated"
+	AnnouncementVisibilityOrganization AnnouncementVisibility = "organization"
+	AnnouncementVisibilityUnit         AnnouncementVisibility = "unit"
 )
 
-func (e *DbStrategy) Scan(src interface{}) error {
+func (e *AnnouncementVisibility) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = DbStrategy(s)
+		*e = AnnouncementVisibility(s)
 	case string:
-		*e = DbStrategy(s)
+		*e = AnnouncementVisibility(s)
 	default:
-		return fmt.Errorf("unsupported scan type for DbStrategy: %T", src)
+		return fmt.Errorf("unsupported scan type for AnnouncementVisibility: %T", src)
 	}
 	return nil
 }
 
-type NullDbStrategy struct {
-	DbStrategy DbStrategy
-	Valid      bool // Valid is true if DbStrategy is not NULL
+type NullAnnouncementVisibility struct {
+	AnnouncementVisibility AnnouncementVisibility
+	Valid                  bool // Valid is true if AnnouncementVisibility is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullDbStrategy) Scan(value interface{}) error {
+func (ns *NullAnnouncementVisibility) Scan(value interface{}) error {
 	if value == nil {
-		ns.DbStrategy, ns.Valid = "", false
+		ns.AnnouncementVisibility, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.DbStrategy.Scan(value)
+	return ns.AnnouncementVisibility.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullDbStrategy) Value() (driver.Value, error) {
+func (ns NullAnnouncementVisibility) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.DbStrategy), nil
+	return string(ns.AnnouncementVisibility), nil
 }
 
-type NodeType string
+type ApprovalStatus string
 
 const (
-	NodeTypeSection   NodeType = "section"
-	NodeTypeEnd       NodeType = "end"
-	NodeTypeStart     NodeType = "start"
-	NodeTypeCondition NodeType = "condition"
+	ApprovalStatusPending  ApprovalStatus = "pending"
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	ApprovalStatusRejected ApprovalStatus = "rejected"
 )
 
-func (e *NodeType) Scan(src interface{}) error {
+func (e *ApprovalStatus) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = NodeType(s)
+		*e = ApprovalStatus(s)
 	case string:
-		*e = NodeType(s)
+		*e = ApprovalStatus(s)
 	default:
-		return fmt.Errorf("unsupported scan type for NodeType: %T", src)
+		return fmt.Errorf("unsupported scan type for ApprovalStatus: %T", src)
 	}
 	return nil
 }
 
-type NullNodeType struct {
-	NodeType NodeType
-	Valid    bool // Valid is true if NodeType is not NULL
+type NullApprovalStatus struct {
+	ApprovalStatus ApprovalStatus
+	Valid          bool // Valid is true if ApprovalStatus is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullNodeType) Scan(value interface{}) error {
+func (ns *NullApprovalStatus) Scan(value interface{}) error {
 	if value == nil {
-		ns.NodeType, ns.Valid = "", false
+		ns.ApprovalStatus, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.NodeType.Scan(value)
+	return ns.ApprovalStatus.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullNodeType) Value() (driver.Value, error) {
+func (ns NullApprovalStatus) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.NodeType), nil
+	return string(ns.ApprovalStatus), nil
 }
 
-type QuestionType string
+type AssignmentStrategy string
 
 const (
-	QuestionTypeShortText              QuestionType = "short_text"
-	QuestionTypeLongText               QuestionType = "long_text"
-	QuestionTypeSingleChoice           QuestionType = "single_choice"
-	QuestionTypeMultipleChoice         QuestionType = "multiple_choice"
-	QuestionTypeDate                   QuestionType = "date"
-	QuestionTypeDropdown               QuestionType = "dropdown"
-	QuestionTypeDetailedMultipleChoice QuestionType = "detailed_multiple_choice"
-	QuestionTypeUploadFile             QuestionType = "upload_file"
-	QuestionTypeLinearScale            QuestionType = "linear_scale"
-	QuestionTypeRating                 QuestionType = "rating"
-	QuestionTypeRanking                QuestionType = "ranking"
-	QuestionTypeOauthConnect           QuestionType = "oauth_connect"
-	QuestionTypeHyperlink              QuestionType = "hyperlink"
+	AssignmentStrategyRoundRobin  AssignmentStrategy = "round_robin"
+	AssignmentStrategyLeastLoaded AssignmentStrategy = "least_loaded"
 )
 
-func (e *QuestionType) Scan(src interface{}) error {
+func (e *AssignmentStrategy) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = QuestionType(s)
+		*e = AssignmentStrategy(s)
 	case string:
-		*e = QuestionType(s)
+		*e = AssignmentStrategy(s)
 	default:
-		return fmt.Errorf("unsupported scan type for QuestionType: %T", src)
+		return fmt.Errorf("unsupported scan type for AssignmentStrategy: %T", src)
 	}
 	return nil
 }
 
-type NullQuestionType struct {
-	QuestionType QuestionType
-	Valid        bool // Valid is true if QuestionType is not NULL
+type NullAssignmentStrategy struct {
+	AssignmentStrategy AssignmentStrategy
+	Valid              bool // Valid is true if AssignmentStrategy is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullQuestionType) Scan(value interface{}) error {
+func (ns *NullAssignmentStrategy) Scan(value interface{}) error {
 	if value == nil {
-		ns.QuestionType, ns.Valid = "", false
+		ns.AssignmentStrategy, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.QuestionType.Scan(value)
+	return ns.AssignmentStrategy.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullQuestionType) Value() (driver.Value, error) {
+func (ns NullAssignmentStrategy) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.QuestionType), nil
+	return string(ns.AssignmentStrategy), nil
 }
 
-type SectionProgress string
+type AttendanceStatus string
 
 const (
-	SectionProgressDraft     SectionProgress = "draft"
-	SectionProgressSubmitted SectionProgress = "submitted"
+	AttendanceStatusPresent AttendanceStatus = "present"
+	AttendanceStatusLate    AttendanceStatus = "late"
+	AttendanceStatusAbsent  AttendanceStatus = "absent"
+	AttendanceStatusExcused AttendanceStatus = "excused"
 )
 
-func (e *SectionProgress) Scan(src interface{}) error {
+func (e *AttendanceStatus) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = SectionProgress(s)
+		*e = AttendanceStatus(s)
 	case string:
-		*e = SectionProgress(s)
+		*e = AttendanceStatus(s)
 	default:
-		return fmt.Errorf("unsupported scan type for SectionProgress: %T", src)
+		return fmt.Errorf("unsupported scan type for AttendanceStatus: %T", src)
 	}
 	return nil
 }
 
-type NullSectionProgress struct {
-	SectionProgress SectionProgress
-	Valid           bool // Valid is true if SectionProgress is not NULL
+type NullAttendanceStatus struct {
+	AttendanceStatus AttendanceStatus
+	Valid            bool // Valid is true if AttendanceStatus is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullSectionProgress) Scan(value interface{}) error {
+func (ns *NullAttendanceStatus) Scan(value interface{}) error {
 	if value == nil {
-		ns.SectionProgress, ns.Valid = "", false
+		ns.AttendanceStatus, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.SectionProgress.Scan(value)
+	return ns.AttendanceStatus.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullSectionProgress) Value() (driver.Value, error) {
+func (ns NullAttendanceStatus) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.SectionProgress), nil
+	return string(ns.AttendanceStatus), nil
 }
 
-type Status string
+type AuditAction string
 
 const (
-	StatusDraft     Status = "draft"
-	StatusPublished Status = "published"
+	AuditActionLogin  AuditAction = "login"
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionSubmit AuditAction = "submit"
+	AuditActionView   AuditAction = "view"
 )
 
-func (e *Status) Scan(src interface{}) error {
+func (e *AuditAction) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = Status(s)
+		*e = AuditAction(s)
 	case string:
-		*e = Status(s)
+		*e = AuditAction(s)
 	default:
-		return fmt.Errorf("unsupported scan type for Status: %T", src)
+		return fmt.Errorf("unsupported scan type for AuditAction: %T", src)
 	}
 	return nil
 }
 
-type NullStatus struct {
-	Status Status
-	Valid  bool // Valid is true if Status is not NULL
+type NullAuditAction struct {
+	AuditAction AuditAction
+	Valid       bool // Valid is true if AuditAction is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullStatus) Scan(value interface{}) error {
+func (ns *NullAuditAction) Scan(value interface{}) error {
 	if value == nil {
-		ns.Status, ns.Valid = "", false
+		ns.AuditAction, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.Status.Scan(value)
+	return ns.AuditAction.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullStatus) Value() (driver.Value, error) {
+func (ns NullAuditAction) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.Status), nil
+	return string(ns.AuditAction), nil
 }
 
-type UnitType string
+type BudgetRequestStatus string
 
 const (
-	UnitTypeOrganization UnitType = "organization"
-	UnitTypeUnit         UnitType = "unit"
+	BudgetRequestStatusPending   BudgetRequestStatus = "pending"
+	BudgetRequestStatusApproved  BudgetRequestStatus = "approved"
+	BudgetRequestStatusRejected  BudgetRequestStatus = "rejected"
+	BudgetRequestStatusWithdrawn BudgetRequestStatus = "withdrawn"
 )
 
-func (e *UnitType) Scan(src interface{}) error {
+func (e *BudgetRequestStatus) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = UnitType(s)
+		*e = BudgetRequestStatus(s)
 	case string:
-		*e = UnitType(s)
+		*e = BudgetRequestStatus(s)
 	default:
-		return fmt.Errorf("unsupported scan type for UnitType: %T", src)
+		return fmt.Errorf("unsupported scan type for BudgetRequestStatus: %T", src)
 	}
 	return nil
 }
 
-type NullUnitType struct {
-	UnitType UnitType
-	Valid    bool // Valid is true if UnitType is not NULL
+type NullBudgetRequestStatus struct {
+	BudgetRequestStatus BudgetRequestStatus
+	Valid               bool // Valid is true if BudgetRequestStatus is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullUnitType) Scan(value interface{}) error {
+func (ns *NullBudgetRequestStatus) Scan(value interface{}) error {
 	if value == nil {
-		ns.UnitType, ns.Valid = "", false
+		ns.BudgetRequestStatus, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.UnitType.Scan(value)
+	return ns.BudgetRequestStatus.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullUnitType) Value() (driver.Value, error) {
+func (ns NullBudgetRequestStatus) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.UnitType), nil
+	return string(ns.BudgetRequestStatus), nil
 }
 
-type Answer struct {
-	ID         uuid.UUID
-	ResponseID uuid.UUID
-	QuestionID uuid.UUID
-	Type       QuestionType
-	Value      string
-	CreatedAt  pgtype.Timestamptz
-	UpdatedAt  pgtype.Timestamptz
-}
+type ContentType string
 
-type Auth struct {
-	ID         uuid.UUID
-	UserID     uuid.UUID
-	Provider   string
-	ProviderID string
-	CreatedAt  pgtype.Timestamptz
-	UpdatedAt  pgtype.Timestamptz
-}
+const (
+	ContentTypeText  ContentType = "text"
+	ContentTypeForm  ContentType = "form"
+	ContentTypeTask  ContentType = "task"
+	ContentTypeQuota ContentType = "quota"
+)
 
-type Form struct {
-	ID             uuid.UUID
-	Title          string
-	Description    pgtype.Text
-	PreviewMessage pgtype.Text
-	Status         Status
-	UnitID         pgtype.UUID
-	LastEditor     uuid.UUID
-	Deadline       pgtype.Timestamptz
-	CreatedAt      pgtype.Timestamptz
-	UpdatedAt      pgtype.Timestamptz
+func (e *ContentType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ContentType(s)
+	case string:
+		*e = ContentType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ContentType: %T", src)
+	}
+	return nil
 }
 
-type FormResponse struct {
-	ID          uuid.UUID
-	FormID      uuid.UUID
-	SubmittedBy uuid.UUID
-	SubmittedAt pgtype.Timestamptz
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
+type NullContentType struct {
+	ContentType ContentType
+	Valid       bool // Valid is true if ContentType is not NULL
 }
 
-type InboxMessage struct {
-	ID        uuid.UUID
-	PostedBy  uuid.UUID
-	Type      ContentType
-	ContentID uuid.UUID
-	CreatedAt pgtype.Timestamp
-	UpdatedAt pgtype.Timestamp
+// Scan implements the Scanner interface.
+func (ns *NullContentType) Scan(value interface{}) error {
+	if value == nil {
+		ns.ContentType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ContentType.Scan(value)
 }
 
-type Question struct {
-	ID          uuid.UUID
-	SectionID   uuid.UUID
-	Required    bool
-	Type        QuestionType
-	Title       pgtype.Text
-	Description pgtype.Text
-	Metadata    []byte
-	Order       int32
-	SourceID    pgtype.UUID
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
+// Value implements the driver Valuer interface.
+func (ns NullContentType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ContentType), nil
 }
 
-type RefreshToken struct {
-	ID             uuid.UUID
-	UserID         uuid.UUID
-	IsActive       pgtype.Bool
-	ExpirationDate pgtype.Timestamptz
-}
+type DbStrategy string
 
-type Section struct {
-	ID          uuid.UUID
-	FormID      uuid.UUID
-	Title       pgtype.Text
-	Progress    SectionProgress
-	Description pgtype.Text
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
-}
+const (
+	DbStrategyShared   DbStrategy = "shared"
+	DbStrategyIsolated DbStrategy = "isolated"
+)
 
-type SlugHistory struct {
-	ID        int32
-	Slug      string
-	OrgID     pgtype.UUID
-	CreatedAt pgtype.Timestamptz
-	EndedAt   pgtype.Timestamptz
+func (e *DbStrategy) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DbStrategy(s)
+	case string:
+		*e = DbStrategy(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DbStrategy: %T", src)
+	}
+	return nil
 }
 
-type Tenant struct {
-	ID         uuid.UUID
+type NullDbStrategy struct {
 	DbStrategy DbStrategy
-	OwnerID    pgtype.UUID
-}
-
-type Unit struct {
-	ID          uuid.UUID
-	OrgID       pgtype.UUID
-	ParentID    pgtype.UUID
-	Type        UnitType
-	Name        pgtype.Text
-	Description pgtype.Text
-	Metadata    []byte
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
+	Valid      bool // Valid is true if DbStrategy is not NULL
 }
 
-type UnitMember struct {
-	UnitID   uuid.UUID
-	MemberID uuid.UUID
+// Scan implements the Scanner interface.
+func (ns *NullDbStrategy) Scan(value interface{}) error {
+	if value == nil {
+		ns.DbStrategy, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DbStrategy.Scan(value)
 }
 
-type User struct {
-	ID          uuid.UUID
+// Value implements the driver Valuer interface.
+func (ns NullDbStrategy) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DbStrategy), nil
+}
+
+type DeviceAuthorizationStatus string
+
+const (
+	DeviceAuthorizationStatusPending  DeviceAuthorizationStatus = "pending"
+	DeviceAuthorizationStatusApproved DeviceAuthorizationStatus = "approved"
+	DeviceAuthorizationStatusDenied   DeviceAuthorizationStatus = "denied"
+)
+
+func (e *DeviceAuthorizationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DeviceAuthorizationStatus(s)
+	case string:
+		*e = DeviceAuthorizationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DeviceAuthorizationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullDeviceAuthorizationStatus struct {
+	DeviceAuthorizationStatus DeviceAuthorizationStatus
+	Valid                     bool // Valid is true if DeviceAuthorizationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDeviceAuthorizationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.DeviceAuthorizationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DeviceAuthorizationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDeviceAuthorizationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DeviceAuthorizationStatus), nil
+}
+
+type EligibilityRuleType string
+
+const (
+	EligibilityRuleTypeUnitMember  EligibilityRuleType = "unit_member"
+	EligibilityRuleTypeEmailDomain EligibilityRuleType = "email_domain"
+	EligibilityRuleTypeAttribute   EligibilityRuleType = "attribute"
+	EligibilityRuleTypeGroupMember EligibilityRuleType = "group_member"
+)
+
+func (e *EligibilityRuleType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EligibilityRuleType(s)
+	case string:
+		*e = EligibilityRuleType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EligibilityRuleType: %T", src)
+	}
+	return nil
+}
+
+type NullEligibilityRuleType struct {
+	EligibilityRuleType EligibilityRuleType
+	Valid               bool // Valid is true if EligibilityRuleType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEligibilityRuleType) Scan(value interface{}) error {
+	if value == nil {
+		ns.EligibilityRuleType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EligibilityRuleType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEligibilityRuleType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EligibilityRuleType), nil
+}
+
+type ExportDestination string
+
+const (
+	ExportDestinationWebhook ExportDestination = "webhook"
+	ExportDestinationS3      ExportDestination = "s3"
+)
+
+func (e *ExportDestination) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExportDestination(s)
+	case string:
+		*e = ExportDestination(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExportDestination: %T", src)
+	}
+	return nil
+}
+
+type NullExportDestination struct {
+	ExportDestination ExportDestination
+	Valid             bool // Valid is true if ExportDestination is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExportDestination) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExportDestination, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExportDestination.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExportDestination) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExportDestination), nil
+}
+
+type ExportFrequency string
+
+const (
+	ExportFrequencyDaily  ExportFrequency = "daily"
+	ExportFrequencyWeekly ExportFrequency = "weekly"
+)
+
+func (e *ExportFrequency) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ExportFrequency(s)
+	case string:
+		*e = ExportFrequency(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ExportFrequency: %T", src)
+	}
+	return nil
+}
+
+type NullExportFrequency struct {
+	ExportFrequency ExportFrequency
+	Valid           bool // Valid is true if ExportFrequency is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullExportFrequency) Scan(value interface{}) error {
+	if value == nil {
+		ns.ExportFrequency, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ExportFrequency.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullExportFrequency) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ExportFrequency), nil
+}
+
+type NodeType string
+
+const (
+	NodeTypeSection   NodeType = "section"
+	NodeTypeEnd       NodeType = "end"
+	NodeTypeStart     NodeType = "start"
+	NodeTypeCondition NodeType = "condition"
+	NodeTypeApproval  NodeType = "approval"
+	NodeTypeDelay     NodeType = "delay"
+	NodeTypeAction    NodeType = "action"
+)
+
+func (e *NodeType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NodeType(s)
+	case string:
+		*e = NodeType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NodeType: %T", src)
+	}
+	return nil
+}
+
+type NullNodeType struct {
+	NodeType NodeType
+	Valid    bool // Valid is true if NodeType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNodeType) Scan(value interface{}) error {
+	if value == nil {
+		ns.NodeType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NodeType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNodeType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NodeType), nil
+}
+
+type PaymentStatus string
+
+const (
+	PaymentStatusUnpaid   PaymentStatus = "unpaid"
+	PaymentStatusPending  PaymentStatus = "pending"
+	PaymentStatusPaid     PaymentStatus = "paid"
+	PaymentStatusFailed   PaymentStatus = "failed"
+	PaymentStatusRefunded PaymentStatus = "refunded"
+	PaymentStatusWaived   PaymentStatus = "waived"
+)
+
+func (e *PaymentStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PaymentStatus(s)
+	case string:
+		*e = PaymentStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PaymentStatus: %T", src)
+	}
+	return nil
+}
+
+type NullPaymentStatus struct {
+	PaymentStatus PaymentStatus
+	Valid         bool // Valid is true if PaymentStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPaymentStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.PaymentStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PaymentStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPaymentStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PaymentStatus), nil
+}
+
+type PiiMasking string
+
+const (
+	PiiMaskingRedact PiiMasking = "redact"
+	PiiMaskingHash   PiiMasking = "hash"
+)
+
+func (e *PiiMasking) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PiiMasking(s)
+	case string:
+		*e = PiiMasking(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PiiMasking: %T", src)
+	}
+	return nil
+}
+
+type NullPiiMasking struct {
+	PiiMasking PiiMasking
+	Valid      bool // Valid is true if PiiMasking is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPiiMasking) Scan(value interface{}) error {
+	if value == nil {
+		ns.PiiMasking, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PiiMasking.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPiiMasking) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PiiMasking), nil
+}
+
+type PipelineGate string
+
+const (
+	PipelineGateSubmitted PipelineGate = "submitted"
+	PipelineGateApproved  PipelineGate = "approved"
+)
+
+func (e *PipelineGate) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PipelineGate(s)
+	case string:
+		*e = PipelineGate(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PipelineGate: %T", src)
+	}
+	return nil
+}
+
+type NullPipelineGate struct {
+	PipelineGate PipelineGate
+	Valid        bool // Valid is true if PipelineGate is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPipelineGate) Scan(value interface{}) error {
+	if value == nil {
+		ns.PipelineGate, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PipelineGate.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPipelineGate) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PipelineGate), nil
+}
+
+type ProfileFieldType string
+
+const (
+	ProfileFieldTypeText   ProfileFieldType = "text"
+	ProfileFieldTypeNumber ProfileFieldType = "number"
+	ProfileFieldTypeSelect ProfileFieldType = "select"
+)
+
+func (e *ProfileFieldType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProfileFieldType(s)
+	case string:
+		*e = ProfileFieldType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProfileFieldType: %T", src)
+	}
+	return nil
+}
+
+type NullProfileFieldType struct {
+	ProfileFieldType ProfileFieldType
+	Valid            bool // Valid is true if ProfileFieldType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProfileFieldType) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProfileFieldType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProfileFieldType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProfileFieldType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProfileFieldType), nil
+}
+
+type PushDigest string
+
+const (
+	PushDigestImmediate PushDigest = "immediate"
+	PushDigestHourly    PushDigest = "hourly"
+	PushDigestDaily     PushDigest = "daily"
+)
+
+func (e *PushDigest) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PushDigest(s)
+	case string:
+		*e = PushDigest(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PushDigest: %T", src)
+	}
+	return nil
+}
+
+type NullPushDigest struct {
+	PushDigest PushDigest
+	Valid      bool // Valid is true if PushDigest is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPushDigest) Scan(value interface{}) error {
+	if value == nil {
+		ns.PushDigest, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PushDigest.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPushDigest) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PushDigest), nil
+}
+
+type PushJobStatus string
+
+const (
+	PushJobStatusPending PushJobStatus = "pending"
+	PushJobStatusSent    PushJobStatus = "sent"
+	PushJobStatusFailed  PushJobStatus = "failed"
+)
+
+func (e *PushJobStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PushJobStatus(s)
+	case string:
+		*e = PushJobStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PushJobStatus: %T", src)
+	}
+	return nil
+}
+
+type NullPushJobStatus struct {
+	PushJobStatus PushJobStatus
+	Valid         bool // Valid is true if PushJobStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPushJobStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.PushJobStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PushJobStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPushJobStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PushJobStatus), nil
+}
+
+type PushPlatform string
+
+const (
+	PushPlatformWeb PushPlatform = "web"
+	PushPlatformFcm PushPlatform = "fcm"
+)
+
+func (e *PushPlatform) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PushPlatform(s)
+	case string:
+		*e = PushPlatform(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PushPlatform: %T", src)
+	}
+	return nil
+}
+
+type NullPushPlatform struct {
+	PushPlatform PushPlatform
+	Valid        bool // Valid is true if PushPlatform is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPushPlatform) Scan(value interface{}) error {
+	if value == nil {
+		ns.PushPlatform, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PushPlatform.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPushPlatform) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PushPlatform), nil
+}
+
+type QuestionType string
+
+const (
+	QuestionTypeShortText              QuestionType = "short_text"
+	QuestionTypeLongText               QuestionType = "long_text"
+	QuestionTypeSingleChoice           QuestionType = "single_choice"
+	QuestionTypeMultipleChoice         QuestionType = "multiple_choice"
+	QuestionTypeDate                   QuestionType = "date"
+	QuestionTypeDropdown               QuestionType = "dropdown"
+	QuestionTypeDetailedMultipleChoice QuestionType = "detailed_multiple_choice"
+	QuestionTypeUploadFile             QuestionType = "upload_file"
+	QuestionTypeLinearScale            QuestionType = "linear_scale"
+	QuestionTypeRating                 QuestionType = "rating"
+	QuestionTypeRanking                QuestionType = "ranking"
+	QuestionTypeOauthConnect           QuestionType = "oauth_connect"
+	QuestionTypeHyperlink              QuestionType = "hyperlink"
+	QuestionTypeConsent                QuestionType = "consent"
+)
+
+func (e *QuestionType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = QuestionType(s)
+	case string:
+		*e = QuestionType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for QuestionType: %T", src)
+	}
+	return nil
+}
+
+type NullQuestionType struct {
+	QuestionType QuestionType
+	Valid        bool // Valid is true if QuestionType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullQuestionType) Scan(value interface{}) error {
+	if value == nil {
+		ns.QuestionType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.QuestionType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullQuestionType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.QuestionType), nil
+}
+
+type QuotaResource string
+
+const (
+	QuotaResourceForms   QuotaResource = "forms"
+	QuotaResourceMembers QuotaResource = "members"
+)
+
+func (e *QuotaResource) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = QuotaResource(s)
+	case string:
+		*e = QuotaResource(s)
+	default:
+		return fmt.Errorf("unsupported scan type for QuotaResource: %T", src)
+	}
+	return nil
+}
+
+type NullQuotaResource struct {
+	QuotaResource QuotaResource
+	Valid         bool // Valid is true if QuotaResource is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullQuotaResource) Scan(value interface{}) error {
+	if value == nil {
+		ns.QuotaResource, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.QuotaResource.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullQuotaResource) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.QuotaResource), nil
+}
+
+type ResourceKind string
+
+const (
+	ResourceKindRoom      ResourceKind = "room"
+	ResourceKindEquipment ResourceKind = "equipment"
+)
+
+func (e *ResourceKind) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ResourceKind(s)
+	case string:
+		*e = ResourceKind(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ResourceKind: %T", src)
+	}
+	return nil
+}
+
+type NullResourceKind struct {
+	ResourceKind ResourceKind
+	Valid        bool // Valid is true if ResourceKind is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullResourceKind) Scan(value interface{}) error {
+	if value == nil {
+		ns.ResourceKind, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ResourceKind.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullResourceKind) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ResourceKind), nil
+}
+
+type RetentionAction string
+
+const (
+	RetentionActionDelete    RetentionAction = "delete"
+	RetentionActionAnonymize RetentionAction = "anonymize"
+)
+
+func (e *RetentionAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RetentionAction(s)
+	case string:
+		*e = RetentionAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RetentionAction: %T", src)
+	}
+	return nil
+}
+
+type NullRetentionAction struct {
+	RetentionAction RetentionAction
+	Valid           bool // Valid is true if RetentionAction is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRetentionAction) Scan(value interface{}) error {
+	if value == nil {
+		ns.RetentionAction, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RetentionAction.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRetentionAction) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RetentionAction), nil
+}
+
+type SectionProgress string
+
+const (
+	SectionProgressDraft     SectionProgress = "draft"
+	SectionProgressSubmitted SectionProgress = "submitted"
+)
+
+func (e *SectionProgress) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SectionProgress(s)
+	case string:
+		*e = SectionProgress(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SectionProgress: %T", src)
+	}
+	return nil
+}
+
+type NullSectionProgress struct {
+	SectionProgress SectionProgress
+	Valid           bool // Valid is true if SectionProgress is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSectionProgress) Scan(value interface{}) error {
+	if value == nil {
+		ns.SectionProgress, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SectionProgress.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSectionProgress) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SectionProgress), nil
+}
+
+type SecurityEventType string
+
+const (
+	SecurityEventTypeRefreshTokenMismatch SecurityEventType = "refresh_token_mismatch"
+)
+
+func (e *SecurityEventType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SecurityEventType(s)
+	case string:
+		*e = SecurityEventType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SecurityEventType: %T", src)
+	}
+	return nil
+}
+
+type NullSecurityEventType struct {
+	SecurityEventType SecurityEventType
+	Valid             bool // Valid is true if SecurityEventType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSecurityEventType) Scan(value interface{}) error {
+	if value == nil {
+		ns.SecurityEventType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SecurityEventType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSecurityEventType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SecurityEventType), nil
+}
+
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusPublished Status = "published"
+)
+
+func (e *Status) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = Status(s)
+	case string:
+		*e = Status(s)
+	default:
+		return fmt.Errorf("unsupported scan type for Status: %T", src)
+	}
+	return nil
+}
+
+type NullStatus struct {
+	Status Status
+	Valid  bool // Valid is true if Status is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.Status, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.Status.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.Status), nil
+}
+
+type TaskStatus string
+
+const (
+	TaskStatusTodo       TaskStatus = "todo"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusDone       TaskStatus = "done"
+	TaskStatusCancelled  TaskStatus = "cancelled"
+)
+
+func (e *TaskStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TaskStatus(s)
+	case string:
+		*e = TaskStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TaskStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTaskStatus struct {
+	TaskStatus TaskStatus
+	Valid      bool // Valid is true if TaskStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTaskStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TaskStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TaskStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTaskStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TaskStatus), nil
+}
+
+type UnitType string
+
+const (
+	UnitTypeOrganization UnitType = "organization"
+	UnitTypeUnit         UnitType = "unit"
+)
+
+func (e *UnitType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = UnitType(s)
+	case string:
+		*e = UnitType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for UnitType: %T", src)
+	}
+	return nil
+}
+
+type NullUnitType struct {
+	UnitType UnitType
+	Valid    bool // Valid is true if UnitType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullUnitType) Scan(value interface{}) error {
+	if value == nil {
+		ns.UnitType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.UnitType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullUnitType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.UnitType), nil
+}
+
+type UploadStatus string
+
+const (
+	UploadStatusPending  UploadStatus = "pending"
+	UploadStatusClean    UploadStatus = "clean"
+	UploadStatusInfected UploadStatus = "infected"
+)
+
+func (e *UploadStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = UploadStatus(s)
+	case string:
+		*e = UploadStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for UploadStatus: %T", src)
+	}
+	return nil
+}
+
+type NullUploadStatus struct {
+	UploadStatus UploadStatus
+	Valid        bool // Valid is true if UploadStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullUploadStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.UploadStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.UploadStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullUploadStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.UploadStatus), nil
+}
+
+type WikiVisibility string
+
+const (
+	WikiVisibilityUnit         WikiVisibility = "unit"
+	WikiVisibilityOrganization WikiVisibility = "organization"
+)
+
+func (e *WikiVisibility) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WikiVisibility(s)
+	case string:
+		*e = WikiVisibility(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WikiVisibility: %T", src)
+	}
+	return nil
+}
+
+type NullWikiVisibility struct {
+	WikiVisibility WikiVisibility
+	Valid          bool // Valid is true if WikiVisibility is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWikiVisibility) Scan(value interface{}) error {
+	if value == nil {
+		ns.WikiVisibility, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WikiVisibility.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWikiVisibility) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WikiVisibility), nil
+}
+
+type Announcement struct {
+	ID         uuid.UUID
+	OrgID      uuid.UUID
+	UnitID     pgtype.UUID
+	Title      string
+	Body       string
+	Visibility AnnouncementVisibility
+	PinnedAt   pgtype.Timestamptz
+	CreatedBy  pgtype.UUID
+	UpdatedBy  pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type AnnouncementReaction struct {
+	AnnouncementID uuid.UUID
+	UserID         uuid.UUID
+	Emoji          string
+	CreatedAt      pgtype.Timestamptz
+}
+
+type AnnouncementRead struct {
+	AnnouncementID uuid.UUID
+	UserID         uuid.UUID
+	ReadAt         pgtype.Timestamptz
+}
+
+type Answer struct {
+	ID         uuid.UUID
+	ResponseID uuid.UUID
+	QuestionID uuid.UUID
+	Type       QuestionType
+	Value      string
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type AnswerComment struct {
+	ID                  uuid.UUID
+	ResponseID          uuid.UUID
+	QuestionID          uuid.UUID
+	ParentID            pgtype.UUID
+	AuthorID            pgtype.UUID
+	Content             string
+	VisibleToRespondent bool
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
+}
+
+type AnswerRevision struct {
+	ID            uuid.UUID
+	AnswerID      uuid.UUID
+	ResponseID    uuid.UUID
+	QuestionID    uuid.UUID
+	PreviousValue string
+	Value         string
+	EditedBy      pgtype.UUID
+	CreatedAt     pgtype.Timestamptz
+}
+
+type AnswerScore struct {
+	ResponseID uuid.UUID
+	QuestionID uuid.UUID
+	Score      int32
+	Comment    pgtype.Text
+	GradedBy   pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type AttendanceEvent struct {
+	FormID           uuid.UUID
+	StartsAt         pgtype.Timestamptz
+	LateAfterMinutes int32
+	UpdatedBy        pgtype.UUID
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type AttendanceMark struct {
+	ResponseID uuid.UUID
+	FormID     uuid.UUID
+	Status     AttendanceStatus
+	MarkedBy   pgtype.UUID
+	MarkedAt   pgtype.Timestamptz
+}
+
+type AuditLog struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Action     AuditAction
+	Route      string
+	Path       string
+	StatusCode int32
+	IpAddress  string
+	UserAgent  string
+	CreatedAt  pgtype.Timestamptz
+}
+
+type Auth struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Provider   string
+	ProviderID string
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type Ballot struct {
+	ID          uuid.UUID
+	FormID      uuid.UUID
+	ReceiptHash []byte
+}
+
+type BallotAnswer struct {
+	BallotID   uuid.UUID
+	QuestionID uuid.UUID
+	Value      string
+}
+
+type BallotVoter struct {
+	FormID  uuid.UUID
+	UserID  uuid.UUID
+	VotedOn pgtype.Date
+}
+
+type BudgetApprovalStep struct {
+	OrgID          uuid.UUID
+	Position       int32
+	Name           string
+	ApproverUnitID pgtype.UUID
+}
+
+type BudgetRequest struct {
+	ID          uuid.UUID
+	OrgID       uuid.UUID
+	UnitID      uuid.UUID
+	Title       string
+	Description string
+	Category    string
+	Amount      int32
+	Currency    string
+	Status      BudgetRequestStatus
+	CurrentStep int32
+	RequestedBy pgtype.UUID
+	DecidedAt   pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type BudgetRequestAttachment struct {
+	ID          uuid.UUID
+	RequestID   uuid.UUID
+	Filename    string
+	ContentType string
+	Size        int64
+	UploadedBy  pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+}
+
+type BudgetRequestComment struct {
+	ID        uuid.UUID
+	RequestID uuid.UUID
+	AuthorID  pgtype.UUID
+	Body      string
+	CreatedAt pgtype.Timestamptz
+}
+
+type BudgetRequestStep struct {
+	RequestID      uuid.UUID
+	Position       int32
+	Name           string
+	ApproverUnitID uuid.UUID
+	Status         ApprovalStatus
+	Comment        pgtype.Text
+	DecidedBy      pgtype.UUID
+	DecidedAt      pgtype.Timestamptz
+}
+
+type DeviceAuthorization struct {
+	DeviceCodeHash string
+	UserCode       string
+	ClientID       string
+	Scope          string
+	Status         DeviceAuthorizationStatus
+	UserID         pgtype.UUID
+	LastPolledAt   pgtype.Timestamptz
+	ExpiresAt      pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+}
+
+type Form struct {
+	ID                 uuid.UUID
+	Title              string
+	Description        pgtype.Text
+	PreviewMessage     pgtype.Text
+	Status             Status
+	UnitID             pgtype.UUID
+	LastEditor         uuid.UUID
+	Deadline           pgtype.Timestamptz
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+	RandomizeQuestions bool
+	ShuffleChoices     bool
+	TimeLimitSeconds   pgtype.Int4
+}
+
+type FormActionRun struct {
+	ID        uuid.UUID
+	FormID    uuid.UUID
+	UserID    uuid.UUID
+	NodeID    string
+	Status    ActionRunStatus
+	Error     pgtype.Text
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type FormApproval struct {
+	ID             uuid.UUID
+	FormID         uuid.UUID
+	ResponseID     uuid.UUID
+	NodeID         string
+	ApproverUnitID uuid.UUID
+	Status         ApprovalStatus
+	Comment        pgtype.Text
+	DecidedBy      pgtype.UUID
+	DecidedAt      pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type FormAssignmentSetting struct {
+	FormID      uuid.UUID
+	Strategy    AssignmentStrategy
+	ReviewerIds []uuid.UUID
+	Rules       []byte
+	NextIndex   int32
+	UpdatedBy   pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type FormAttempt struct {
+	FormID    uuid.UUID
+	UserID    uuid.UUID
+	StartedAt pgtype.Timestamptz
+}
+
+type FormBallot struct {
+	FormID    uuid.UUID
+	EnabledBy pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type FormCheckin struct {
+	ResponseID  uuid.UUID
+	FormID      uuid.UUID
+	CheckedInBy pgtype.UUID
+	CheckedInAt pgtype.Timestamptz
+}
+
+type FormDelegation struct {
+	ID         uuid.UUID
+	FormID     uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	Email      string
+	CreatedBy  pgtype.UUID
+	ExpiresAt  pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+	LastUsedAt pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+}
+
+type FormEligibilityRule struct {
+	ID           uuid.UUID
+	FormID       uuid.UUID
+	Type         EligibilityRuleType
+	UnitID       pgtype.UUID
+	GroupID      pgtype.UUID
+	AttributeKey pgtype.Text
+	Value        pgtype.Text
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+}
+
+type FormExportSchedule struct {
+	ID          uuid.UUID
+	FormID      uuid.UUID
+	Frequency   ExportFrequency
+	Destination ExportDestination
+	Target      string
+	Enabled     bool
+	NextRunAt   pgtype.Timestamptz
+	LastRunAt   pgtype.Timestamptz
+	LastError   pgtype.Text
+	CreatedBy   pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	Masked      bool
+}
+
+type FormPaymentSetting struct {
+	FormID    uuid.UUID
+	AmountDue int32
+	Currency  string
+	UpdatedBy pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type FormProgress struct {
+	ID               uuid.UUID
+	FormID           uuid.UUID
+	UserID           uuid.UUID
+	CurrentSectionID pgtype.UUID
+	VisitedNodes     []string
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type FormPublicAccess struct {
+	FormID    uuid.UUID
+	EnabledBy pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type FormRespondentGuest struct {
+	RespondentID uuid.UUID
+	FormID       uuid.UUID
+	IpAddress    string
+	CreatedAt    pgtype.Timestamptz
+}
+
+type FormResponse struct {
+	ID           uuid.UUID
+	FormID       uuid.UUID
+	SubmittedBy  uuid.UUID
+	SubmittedAt  pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	IsTest       bool
+	AnonymizedAt pgtype.Timestamptz
+}
+
+type FormResponseSource struct {
+	ID          uuid.UUID
+	FormID      uuid.UUID
+	ResponseID  pgtype.UUID
+	Source      string
+	ExternalID  string
+	SubmittedAt pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+}
+
+type FormResultShare struct {
+	FormID    uuid.UUID
+	Nonce     string
+	ExpiresAt pgtype.Timestamptz
+	CreatedBy pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type FormRetentionPolicy struct {
+	FormID             uuid.UUID
+	DeleteAfterDays    pgtype.Int4
+	AnonymizeAfterDays pgtype.Int4
+	UpdatedBy          pgtype.UUID
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+}
+
+type FormStar struct {
+	UserID    uuid.UUID
+	FormID    uuid.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type FormTag struct {
+	FormID    uuid.UUID
+	TagID     uuid.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type FormUpload struct {
+	ID          uuid.UUID
+	FormID      uuid.UUID
+	QuestionID  uuid.UUID
+	UploadedBy  pgtype.UUID
+	Filename    string
+	ContentType string
+	Size        int64
+	Status      UploadStatus
+	ScanResult  pgtype.Text
+	ScannedAt   pgtype.Timestamptz
+	HasVariants bool
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type FormView struct {
+	UserID   uuid.UUID
+	FormID   uuid.UUID
+	ViewedAt pgtype.Timestamptz
+}
+
+type InboxMessage struct {
+	ID        uuid.UUID
+	PostedBy  uuid.UUID
+	Type      ContentType
+	ContentID uuid.UUID
+	CreatedAt pgtype.Timestamp
+	UpdatedAt pgtype.Timestamp
+	ReplyTo   pgtype.UUID
+	ThreadID  pgtype.UUID
+	SenderID  pgtype.UUID
+	Body      pgtype.Text
+}
+
+type InboxMessageTag struct {
+	MessageID uuid.UUID
+	TagID     uuid.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type LegalHold struct {
+	ID         uuid.UUID
+	FormID     uuid.UUID
+	ResponseID pgtype.UUID
+	Reason     string
+	PlacedBy   pgtype.UUID
+	PlacedAt   pgtype.Timestamptz
+	ReleasedBy pgtype.UUID
+	ReleasedAt pgtype.Timestamptz
+}
+
+type MagicLink struct {
+	ID          uuid.UUID
+	Email       string
+	RedirectUrl string
+	DeviceHash  []byte
+	IpAddress   string
+	UserAgent   string
+	ExpiresAt   pgtype.Timestamptz
+	UsedAt      pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+}
+
+type OidcAuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	UserID        uuid.UUID
+	RedirectUri   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string
+	ExpiresAt     pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+}
+
+type OrgEvent struct {
+	ID          uuid.UUID
+	OrgID       uuid.UUID
+	Title       string
+	Description string
+	Venue       string
+	StartsAt    pgtype.Timestamptz
+	EndsAt      pgtype.Timestamptz
+	Capacity    pgtype.Int4
+	FormID      pgtype.UUID
+	Published   bool
+	CreatedBy   pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type OrgIngestSource struct {
+	ID        uuid.UUID
+	OrgID     uuid.UUID
+	Source    string
+	Secret    string
+	CreatedBy pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type OrgQuota struct {
+	OrgID      uuid.UUID
+	Resource   QuotaResource
+	QuotaLimit int32
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type OrgWebhookKey struct {
+	ID        uuid.UUID
+	OrgID     uuid.UUID
+	Secret    string
+	CreatedBy pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+type PaymentNotification struct {
+	ID                uuid.UUID
+	Provider          string
+	Reference         string
+	ResponseID        pgtype.UUID
+	Status            PaymentStatus
+	Amount            int32
+	ExternalReference string
+	Simulated         bool
+	ReceivedAt        pgtype.Timestamptz
+}
+
+type PiiQuestion struct {
+	QuestionID uuid.UUID
+	FormID     uuid.UUID
+	Masking    PiiMasking
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type Pipeline struct {
+	ID          uuid.UUID
+	OrgID       uuid.UUID
+	Name        string
+	Description string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type PipelineStage struct {
+	PipelineID uuid.UUID
+	Position   int32
+	FormID     uuid.UUID
+	Name       string
+	Gate       PipelineGate
+}
+
+type ProfileField struct {
+	ID        uuid.UUID
+	OrgID     uuid.UUID
+	Key       string
+	Label     string
+	Type      ProfileFieldType
+	Options   []string
+	Required  bool
+	Position  int32
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type ProfilePrefill struct {
+	QuestionID uuid.UUID
+	FieldID    uuid.UUID
+}
+
+type ProfileValue struct {
+	FieldID   uuid.UUID
+	UserID    uuid.UUID
+	Value     string
+	UpdatedAt pgtype.Timestamptz
+}
+
+type PushDevice struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Platform   PushPlatform
+	Endpoint   string
+	P256dh     pgtype.Text
+	Auth       pgtype.Text
+	UserAgent  string
+	LastUsedAt pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type PushJob struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	MessageID     uuid.UUID
+	Status        PushJobStatus
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	LastError     pgtype.Text
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type PushPreference struct {
+	UserID       uuid.UUID
+	Enabled      bool
+	FormMessages bool
+	TextMessages bool
+	Digest       PushDigest
+	LastDigestAt pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+}
+
+type Question struct {
+	ID          uuid.UUID
+	SectionID   uuid.UUID
+	Required    bool
+	Type        QuestionType
+	Title       pgtype.Text
+	Description pgtype.Text
+	Metadata    []byte
+	Order       int32
+	SourceID    pgtype.UUID
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type QuestionPoint struct {
+	QuestionID       uuid.UUID
+	FormID           uuid.UUID
+	Points           int32
+	CorrectChoiceIds []uuid.UUID
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type QuotaWarning struct {
+	ID         uuid.UUID
+	OrgID      uuid.UUID
+	Resource   QuotaResource
+	Threshold  int32
+	Usage      int32
+	QuotaLimit int32
+	ResolvedAt pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+}
+
+type RecipientGroup struct {
+	ID          uuid.UUID
+	OrgID       uuid.UUID
+	Name        string
+	Description string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type RecipientGroupMember struct {
+	GroupID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type RefreshToken struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	IsActive       pgtype.Bool
+	ExpirationDate pgtype.Timestamptz
+	UserAgent      string
+	PlatformHash   []byte
+}
+
+type Resource struct {
+	ID            uuid.UUID
+	UnitID        uuid.UUID
+	Name          string
+	Kind          ResourceKind
+	Description   string
+	Location      string
+	BookingFormID pgtype.UUID
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type ResourceBooking struct {
+	ID          uuid.UUID
+	ResourceID  uuid.UUID
+	BookedBy    uuid.UUID
+	StartsAt    pgtype.Timestamptz
+	EndsAt      pgtype.Timestamptz
+	Purpose     string
+	ResponseID  pgtype.UUID
+	CancelledAt pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+}
+
+type ResponseAssignment struct {
+	ResponseID uuid.UUID
+	FormID     uuid.UUID
+	ReviewerID uuid.UUID
+	AssignedAt pgtype.Timestamptz
+}
+
+type ResponsePayment struct {
+	ResponseID        uuid.UUID
+	FormID            uuid.UUID
+	Reference         string
+	AmountDue         int32
+	AmountPaid        int32
+	Status            PaymentStatus
+	Provider          string
+	ExternalReference string
+	PaidAt            pgtype.Timestamptz
+	UpdatedBy         pgtype.UUID
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type RetentionPurge struct {
+	ID            uuid.UUID
+	FormID        uuid.UUID
+	Action        RetentionAction
+	AfterDays     int32
+	ResponseCount int32
+	PurgedAt      pgtype.Timestamptz
+}
+
+type Section struct {
+	ID          uuid.UUID
+	FormID      uuid.UUID
+	Title       pgtype.Text
+	Progress    SectionProgress
+	Description pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type SecurityEvent struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Type      SecurityEventType
+	IpAddress string
+	UserAgent string
+	Detail    string
+	CreatedAt pgtype.Timestamptz
+}
+
+type SlugHistory struct {
+	ID        int32
+	Slug      string
+	OrgID     pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	EndedAt   pgtype.Timestamptz
+}
+
+type StudentID struct {
+	UserID     uuid.UUID
+	Value      string
+	VerifiedAt pgtype.Timestamptz
+	VerifiedBy pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type Tag struct {
+	ID        uuid.UUID
+	OrgID     uuid.UUID
+	Name      string
+	Color     string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type Task struct {
+	ID          uuid.UUID
+	UnitID      uuid.UUID
+	Title       string
+	Description string
+	Status      TaskStatus
+	DueAt       pgtype.Timestamptz
+	FormID      pgtype.UUID
+	ResponseID  pgtype.UUID
+	CreatedBy   pgtype.UUID
+	CompletedAt pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type TaskAssignee struct {
+	TaskID     uuid.UUID
+	UserID     uuid.UUID
+	AssignedAt pgtype.Timestamptz
+}
+
+type Tenant struct {
+	ID         uuid.UUID
+	DbStrategy DbStrategy
+	OwnerID    pgtype.UUID
+}
+
+type TestTenant struct {
+	OrgID     uuid.UUID
+	Label     string
+	CreatedBy pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+type Unit struct {
+	ID          uuid.UUID
+	OrgID       pgtype.UUID
+	ParentID    pgtype.UUID
+	Type        UnitType
+	Name        pgtype.Text
+	Description pgtype.Text
+	Metadata    []byte
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type UnitInboxMessage struct {
+	ID         uuid.UUID
+	UnitID     uuid.UUID
+	MessageID  uuid.UUID
+	AssigneeID pgtype.UUID
+	IsRead     bool
+	IsArchived bool
+	CreatedAt  pgtype.Timestamp
+	UpdatedAt  pgtype.Timestamp
+}
+
+type UnitMember struct {
+	UnitID     uuid.UUID
+	MemberID   uuid.UUID
+	ValidUntil pgtype.Timestamptz
+}
+
+type User struct {
+	ID          uuid.UUID
 	Name        pgtype.Text
 	Username    pgtype.Text
 	AvatarUrl   pgtype.Text
@@ -450,12 +2238,13 @@ type UserEmail struct {
 }
 
 type UserInboxMessage struct {
-	ID         uuid.UUID
-	UserID     uuid.UUID
-	MessageID  uuid.UUID
-	IsRead     bool
-	IsStarred  bool
-	IsArchived bool
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	MessageID    uuid.UUID
+	IsRead       bool
+	IsStarred    bool
+	IsArchived   bool
+	SnoozedUntil pgtype.Timestamptz
 }
 
 type UsersWithEmail struct {
@@ -470,6 +2259,31 @@ type UsersWithEmail struct {
 	Emails      interface{}
 }
 
+type WikiPage struct {
+	ID         uuid.UUID
+	UnitID     uuid.UUID
+	Slug       string
+	Title      string
+	Body       string
+	Visibility WikiVisibility
+	Revision   int32
+	CreatedBy  pgtype.UUID
+	UpdatedBy  pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type WikiPageRevision struct {
+	ID        uuid.UUID
+	PageID    uuid.UUID
+	Revision  int32
+	Title     string
+	Body      string
+	Summary   string
+	EditedBy  pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
 type WorkflowVersion struct {
 	ID         uuid.UUID
 	FormID     uuid.UUID
@@ -25,6 +25,12 @@ FROM slug_history
 WHERE slug = $1
   AND ended_at IS NULL;
 
+-- name: GetCurrentSlug :one
+SELECT slug
+FROM slug_history
+WHERE org_id = $1
+  AND ended_at IS NULL;
+
 -- name: GetSlugHistory :many
 SELECT s.*, u.name
 FROM slug_history s
@@ -87,6 +87,20 @@ func (q *Queries) Get(ctx context.Context, id uuid.UUID) (Tenant, error) {
 	return i, err
 }
 
+const getCurrentSlug = `-- name: GetCurrentSlug :one
+SELECT slug
+FROM slug_history
+WHERE org_id = $1
+  AND ended_at IS NULL
+`
+
+func (q *Queries) GetCurrentSlug(ctx context.Context, orgID pgtype.UUID) (string, error) {
+	row := q.db.QueryRow(ctx, getCurrentSlug, orgID)
+	var slug string
+	err := row.Scan(&slug)
+	return slug, err
+}
+
 const getSlugHistory = `-- name: GetSlugHistory :many
 SELECT s.id, s.slug, s.org_id, s.created_at, s.ended_at, u.name
 FROM slug_history s
@@ -0,0 +1,11 @@
+package tenant
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the slug availability and history of an organization
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/status", route.Public, route.PermissionNone, h.GetStatus)
+	r.Handle("GET /orgs/{slug}/history", route.Public, route.PermissionNone, h.GetStatusWithHistory)
+}
@@ -21,6 +21,7 @@ type Querier interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	ExistsBySlug(ctx context.Context, slug string) (bool, error)
 	GetSlugStatus(ctx context.Context, slug string) (pgtype.UUID, error)
+	GetCurrentSlug(ctx context.Context, orgID pgtype.UUID) (string, error)
 	GetSlugHistory(ctx context.Context, slug string) ([]GetSlugHistoryRow, error)
 	CreateSlugHistory(ctx context.Context, arg CreateSlugHistoryParams) (SlugHistory, error)
 	UpdateSlugHistory(ctx context.Context, arg UpdateSlugHistoryParams) ([]pgtype.UUID, error)
@@ -183,3 +184,23 @@ func (s *Service) GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UU
 
 	return false, orgID.Bytes, nil
 }
+
+// GetSlugByID returns the slug the organization is currently addressed by
+func (s *Service) GetSlugByID(ctx context.Context, id uuid.UUID) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetSlugByID")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	slug, err := s.query.GetCurrentSlug(traceCtx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrOrgSlugNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "slug_history", "org_id", id.String(), logger, "get current slug")
+		}
+		span.RecordError(err)
+		return "", err
+	}
+
+	return slug, nil
+}
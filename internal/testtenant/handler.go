@@ -0,0 +1,177 @@
+package testtenant
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Provision(ctx context.Context, req ProvisionRequest, ownerID uuid.UUID) (Entry, error)
+	List(ctx context.Context) ([]Entry, error)
+	Delete(ctx context.Context, slug string) error
+}
+
+// CreateRequest provisions a test tenant owned by the caller. TTL is a duration such
+// as "2h", DefaultTTL when empty; the members are the emails of users joining it.
+type CreateRequest struct {
+	Label   string   `json:"label"`
+	TTL     string   `json:"ttl"`
+	Members []string `json:"members" validate:"dive,email"`
+}
+
+type Response struct {
+	OrgID          string   `json:"orgId"`
+	Slug           string   `json:"slug"`
+	Label          string   `json:"label"`
+	CreatedBy      string   `json:"createdBy"`
+	CreatedAt      string   `json:"createdAt"`
+	ExpiresAt      string   `json:"expiresAt"`
+	SkippedMembers []string `json:"skippedMembers,omitempty"`
+}
+
+func ToResponse(tenant Entry) Response {
+	return Response{
+		OrgID:          tenant.OrgID.String(),
+		Slug:           tenant.Slug,
+		Label:          tenant.Label,
+		CreatedBy:      tenant.CreatedBy.String(),
+		CreatedAt:      tenant.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:      tenant.ExpiresAt.Format(time.RFC3339),
+		SkippedMembers: tenant.SkippedMembers,
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("testtenant/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+// requireAdmin returns the caller, who must hold the admin role of the deployment
+func (h *Handler) requireAdmin(ctx context.Context) (*user.User, error) {
+	currentUser, ok := user.GetFromContext(ctx)
+	if !ok {
+		return nil, internal.ErrNoUserInContext
+	}
+	if !user.IsAdmin(currentUser) {
+		return nil, internal.ErrPermissionDenied
+	}
+	return currentUser, nil
+}
+
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, err := h.requireAdmin(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req CreateRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("%w: %w", internal.ErrTestTenantTTLInvalid, err), logger)
+			return
+		}
+	}
+
+	tenant, err := h.store.Provision(traceCtx, ProvisionRequest{
+		Label:   req.Label,
+		TTL:     ttl,
+		Members: req.Members,
+	}, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(tenant))
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	_, err := h.requireAdmin(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	tenants, err := h.store.List(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]Response, len(tenants))
+	for i, tenant := range tenants {
+		response[i] = ToResponse(tenant)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	_, err := h.requireAdmin(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, r.PathValue("slug"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
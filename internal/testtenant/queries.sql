@@ -0,0 +1,23 @@
+-- name: Create :one
+INSERT INTO test_tenants (org_id, label, created_by, expires_at)
+VALUES (@org_id, @label, @created_by, @expires_at)
+RETURNING *;
+
+-- name: List :many
+SELECT t.org_id, t.label, t.created_by, t.created_at, t.expires_at, sh.slug FROM test_tenants t
+JOIN slug_history sh ON sh.org_id = t.org_id AND sh.ended_at IS NULL
+ORDER BY t.created_at DESC;
+
+-- name: GetBySlug :one
+SELECT t.org_id, t.label, t.created_by, t.created_at, t.expires_at, sh.slug FROM test_tenants t
+JOIN slug_history sh ON sh.org_id = t.org_id AND sh.ended_at IS NULL
+WHERE sh.slug = @slug;
+
+-- name: ListExpired :many
+SELECT org_id FROM test_tenants
+WHERE expires_at <= @now
+ORDER BY expires_at ASC;
+
+-- name: DeleteOrg :execrows
+-- The organization goes with its units and everything below them, the test tenant row included
+DELETE FROM units WHERE id = @org_id OR org_id = @org_id;
\ No newline at end of file
@@ -0,0 +1,12 @@
+package testtenant
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the provisioning of test tenants by the admins of the deployment
+func Routes(r route.Router, h *Handler) {
+	r.Handle("POST /admin/test-tenants", route.Authenticated, route.PermissionAdmin, h.CreateHandler)
+	r.Handle("GET /admin/test-tenants", route.Authenticated, route.PermissionAdmin, h.ListHandler)
+	r.Handle("DELETE /admin/test-tenants/{slug}", route.Authenticated, route.PermissionAdmin, h.DeleteHandler)
+}
@@ -0,0 +1,67 @@
+package testtenant
+
+import (
+	"NYCU-SDC/core-system-backend/internal/backup"
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// seedArchive is what every test tenant starts from: two units under the organization
+// and a form with a short and a long answer, the given emails joining the organization
+func seedArchive(name string, emails []string, now time.Time) (backup.Archive, error) {
+	orgID := uuid.New()
+	teamID := uuid.New()
+
+	startID, sectionID, endID := uuid.New(), uuid.New(), uuid.New()
+	workflow, err := json.Marshal([]map[string]any{
+		{"id": startID, "type": "start", "label": "Start", "next": sectionID},
+		{"id": sectionID, "type": "section", "label": "Feedback", "next": endID},
+		{"id": endID, "type": "end", "label": "End"},
+	})
+	if err != nil {
+		return backup.Archive{}, err
+	}
+
+	members := make([]backup.ArchiveMember, len(emails))
+	for i, email := range emails {
+		members[i] = backup.ArchiveMember{UnitID: orgID, Email: email}
+	}
+
+	return backup.Archive{
+		Version:    backup.ArchiveVersion,
+		ExportedAt: now,
+		Org: backup.ArchiveOrg{
+			ID:          orgID,
+			Name:        name,
+			Description: "Test tenant, deleted once it expires",
+		},
+		Units: []backup.ArchiveUnit{
+			{ID: teamID, Name: "Engineering", Description: "Seeded unit"},
+			{ID: uuid.New(), ParentID: &teamID, Name: "Backend", Description: "Seeded unit below Engineering"},
+		},
+		Members: members,
+		Forms: []backup.ArchiveForm{{
+			UnitID: orgID,
+			Document: importer.Document{
+				Version:    importer.DocumentVersion,
+				ExportedAt: now,
+				Form: importer.DocumentForm{
+					Title:       "Feedback",
+					Description: "Seeded form",
+				},
+				Sections: []importer.DocumentSection{{
+					ID:    sectionID,
+					Title: "Feedback",
+					Questions: []importer.DocumentQuestion{
+						{ID: uuid.New(), Type: "short_text", Title: "Name", Required: true, Order: 1},
+						{ID: uuid.New(), Type: "long_text", Title: "Comments", Order: 2},
+					},
+				}},
+				Workflow: workflow,
+			},
+		}},
+	}, nil
+}
@@ -0,0 +1,271 @@
+package testtenant
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/backup"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultExpiryInterval is how often expired test tenants are deleted
+	DefaultExpiryInterval = 5 * time.Minute
+
+	// DefaultTTL is how long a test tenant lives when no TTL is requested
+	DefaultTTL = 24 * time.Hour
+
+	// MaxTTL bounds the TTL, test tenants being meant for one preview or QA run
+	MaxTTL = 7 * 24 * time.Hour
+
+	// SlugPrefix starts the slug of every test tenant
+	SlugPrefix = "test-"
+)
+
+type Querier interface {
+	Create(ctx context.Context, arg CreateParams) (TestTenant, error)
+	List(ctx context.Context) ([]ListRow, error)
+	GetBySlug(ctx context.Context, slug string) (GetBySlugRow, error)
+	ListExpired(ctx context.Context, now pgtype.Timestamptz) ([]uuid.UUID, error)
+	DeleteOrg(ctx context.Context, orgID uuid.UUID) (int64, error)
+}
+
+// Restorer creates the organization of a test tenant from its seed archive
+type Restorer interface {
+	Restore(ctx context.Context, archive backup.Archive, slug string, ownerID uuid.UUID) (backup.RestoreResult, error)
+}
+
+// Entry is a provisioned test tenant
+type Entry struct {
+	OrgID          uuid.UUID
+	Slug           string
+	Label          string
+	CreatedBy      uuid.UUID
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+	SkippedMembers []string
+}
+
+// ProvisionRequest describes a test tenant; a zero TTL means DefaultTTL
+type ProvisionRequest struct {
+	Label   string
+	TTL     time.Duration
+	Members []string
+}
+
+type Service struct {
+	logger   *zap.Logger
+	queries  Querier
+	tracer   trace.Tracer
+	restorer Restorer
+}
+
+func NewService(logger *zap.Logger, db DBTX, restorer Restorer) *Service {
+	return &Service{
+		logger:   logger,
+		queries:  New(db),
+		tracer:   otel.Tracer("testtenant/service"),
+		restorer: restorer,
+	}
+}
+
+func newSlug() (string, error) {
+	b := make([]byte, 4)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return SlugPrefix + hex.EncodeToString(b), nil
+}
+
+// Provision creates an organization under a random slug from the seed, owned by
+// ownerID, which is deleted once the TTL has passed
+func (s *Service) Provision(ctx context.Context, req ProvisionRequest, ownerID uuid.UUID) (Entry, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Provision")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	ttl := req.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > MaxTTL {
+		err := fmt.Errorf("%w: must be positive and at most %s", internal.ErrTestTenantTTLInvalid, MaxTTL)
+		span.RecordError(err)
+		return Entry{}, err
+	}
+
+	slug, err := newSlug()
+	if err != nil {
+		span.RecordError(err)
+		return Entry{}, err
+	}
+
+	name := req.Label
+	if name == "" {
+		name = slug
+	}
+
+	now := time.Now()
+	archive, err := seedArchive(name, req.Members, now)
+	if err != nil {
+		span.RecordError(err)
+		return Entry{}, err
+	}
+
+	result, err := s.restorer.Restore(traceCtx, archive, slug, ownerID)
+	if err != nil {
+		span.RecordError(err)
+		return Entry{}, err
+	}
+
+	row, err := s.queries.Create(traceCtx, CreateParams{
+		OrgID:     result.OrgID,
+		Label:     req.Label,
+		CreatedBy: pgtype.UUID{Bytes: ownerID, Valid: true},
+		ExpiresAt: pgtype.Timestamptz{Time: now.Add(ttl), Valid: true},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "create test tenant")
+		span.RecordError(err)
+		_, deleteErr := s.queries.DeleteOrg(traceCtx, result.OrgID)
+		if deleteErr != nil {
+			logger.Error("Failed to delete organization of unrecorded test tenant", zap.String("org_id", result.OrgID.String()), zap.Error(deleteErr))
+		}
+		return Entry{}, err
+	}
+
+	logger.Info("Provisioned test tenant",
+		zap.String("org_id", row.OrgID.String()),
+		zap.String("slug", slug),
+		zap.String("created_by", ownerID.String()),
+		zap.Time("expires_at", row.ExpiresAt.Time))
+
+	return Entry{
+		OrgID:          row.OrgID,
+		Slug:           slug,
+		Label:          row.Label,
+		CreatedBy:      ownerID,
+		CreatedAt:      row.CreatedAt.Time,
+		ExpiresAt:      row.ExpiresAt.Time,
+		SkippedMembers: result.SkippedMembers,
+	}, nil
+}
+
+// List returns the test tenants, newest first, expired ones not yet deleted included
+func (s *Service) List(ctx context.Context) ([]Entry, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	rows, err := s.queries.List(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list test tenants")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	tenants := make([]Entry, len(rows))
+	for i, row := range rows {
+		tenants[i] = Entry{
+			OrgID:     row.OrgID,
+			Slug:      row.Slug,
+			Label:     row.Label,
+			CreatedBy: row.CreatedBy.Bytes,
+			CreatedAt: row.CreatedAt.Time,
+			ExpiresAt: row.ExpiresAt.Time,
+		}
+	}
+
+	return tenants, nil
+}
+
+// Delete deletes the test tenant under slug ahead of its expiry. Organizations that are
+// not test tenants are not found.
+func (s *Service) Delete(ctx context.Context, slug string) error {
+	traceCtx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	row, err := s.queries.GetBySlug(traceCtx, slug)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrTestTenantNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "test_tenants", "slug", slug, logger, "get test tenant")
+		}
+		span.RecordError(err)
+		return err
+	}
+
+	_, err = s.queries.DeleteOrg(traceCtx, row.OrgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "units", "org_id", row.OrgID.String(), logger, "delete test tenant")
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted test tenant", zap.String("org_id", row.OrgID.String()), zap.String("slug", slug))
+	return nil
+}
+
+// Start deletes the expired test tenants every interval until the context is done
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			err := s.DeleteExpired(ctx, now)
+			if err != nil {
+				s.logger.Error("Failed to delete expired test tenants", zap.Error(err))
+			}
+		}
+	}
+}
+
+// DeleteExpired deletes every test tenant that expired by now. The tenants failing to
+// be deleted are left for the next run.
+func (s *Service) DeleteExpired(ctx context.Context, now time.Time) error {
+	traceCtx, span := s.tracer.Start(ctx, "DeleteExpired")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	orgIDs, err := s.queries.ListExpired(traceCtx, pgtype.Timestamptz{Time: now, Valid: true})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list expired test tenants")
+		span.RecordError(err)
+		return err
+	}
+
+	var errs []error
+	for _, orgID := range orgIDs {
+		_, err := s.queries.DeleteOrg(traceCtx, orgID)
+		if err != nil {
+			errs = append(errs, databaseutil.WrapDBErrorWithKeyValue(err, "units", "org_id", orgID.String(), logger, "delete expired test tenant"))
+			continue
+		}
+		logger.Info("Deleted expired test tenant", zap.String("org_id", orgID.String()))
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+package trace
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"net/http"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Get() Sampling
+	Set(sampling Sampling) error
+}
+
+type SamplingRequest struct {
+	Ratio  *float64           `json:"ratio" validate:"required,min=0,max=1"`
+	Routes map[string]float64 `json:"routes" validate:"omitempty,dive,keys,startswith=/,endkeys,min=0,max=1"`
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store Store
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("trace/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+	}
+}
+
+func (h *Handler) GetSamplingHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetSamplingHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+	if !user.IsAdmin(currentUser) {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrPermissionDenied, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, h.store.Get())
+}
+
+func (h *Handler) SetSamplingHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "SetSamplingHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, ok := user.GetFromContext(traceCtx)
+	if !ok {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrNoUserInContext, logger)
+		return
+	}
+	if !user.IsAdmin(currentUser) {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrPermissionDenied, logger)
+		return
+	}
+
+	var req SamplingRequest
+	err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Set(Sampling{Ratio: *req.Ratio, Routes: req.Routes})
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+	logger.Info("Trace sampling set by admin", zap.String("user_id", currentUser.ID.String()), zap.Float64("ratio", *req.Ratio))
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, h.store.Get())
+}
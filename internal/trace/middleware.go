@@ -4,25 +4,83 @@ import (
 	"net/http"
 
 	traceutil "github.com/NYCU-SDC/summer/pkg/trace"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type Middleware struct {
-	logger *zap.Logger
-	debug  bool
+	logger   *zap.Logger
+	tracer   trace.Tracer
+	reporter PanicReporter
+
+	panics metric.Int64Counter
 }
 
-func NewMiddleware(logger *zap.Logger, debug bool) *Middleware {
+// NewMiddleware creates the tracing middleware; reporter may be nil, panics are then
+// only recorded on their trace and logged
+func NewMiddleware(logger *zap.Logger, reporter PanicReporter) *Middleware {
+	panics, err := otel.Meter("internal/trace").Int64Counter("http.server.panics",
+		metric.WithDescription("Panics recovered from requests"),
+		metric.WithUnit("{panic}"))
+	if err != nil {
+		logger.Warn("Failed to create panics counter", zap.Error(err))
+		panics = noop.Int64Counter{}
+	}
+
 	return &Middleware{
-		logger: logger,
-		debug:  debug,
+		logger:   logger,
+		tracer:   otel.Tracer("internal/middleware"),
+		reporter: reporter,
+		panics:   panics,
 	}
 }
 
+// TraceMiddleware starts the span of the request and marks it as failed when the
+// request panics or ends with a server error, for the ErrorProcessor to export it
 func (m Middleware) TraceMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return traceutil.TraceMiddleware(next, m.logger)
+	return traceutil.TraceMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		writer := &statusWriter{ResponseWriter: w}
+
+		defer func() {
+			recovered := recover()
+			if recovered != nil {
+				span.SetStatus(codes.Error, "panic")
+				panic(recovered)
+			}
+			if writer.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(writer.status))
+			}
+		}()
+
+		next(writer, r)
+	}, m.logger)
+}
+
+// statusWriter keeps the status code written to the response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(p)
 }
 
-func (m Middleware) RecoverMiddleware(next http.HandlerFunc) http.HandlerFunc {
-	return traceutil.RecoverMiddleware(next, m.logger, m.debug)
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
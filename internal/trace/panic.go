@@ -0,0 +1,182 @@
+package trace
+
+import (
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.6.1"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// maxPanicFrames bounds the stack frames kept for a report
+const maxPanicFrames = 64
+
+// Panic is a panic recovered from a request, with what is known of the request
+type Panic struct {
+	Value  any
+	Stack  string
+	Frames []runtime.Frame
+	Time   time.Time
+
+	Method  string
+	Route   string
+	UserID  string
+	TraceID string
+	SpanID  string
+}
+
+// Message is the panic value as text
+func (p Panic) Message() string {
+	err, ok := p.Value.(error)
+	if ok {
+		return err.Error()
+	}
+	return fmt.Sprintf("%v", p.Value)
+}
+
+// Type is the Go type of the panic value, e.g. "runtime.boundsError"
+func (p Panic) Type() string {
+	return fmt.Sprintf("%T", p.Value)
+}
+
+// PanicReporter sends recovered panics to an error tracker. ReportPanic must not block
+// the request it is called from.
+type PanicReporter interface {
+	ReportPanic(ctx context.Context, p Panic)
+}
+
+type panicScopeKey struct{}
+
+// panicScope holds the innermost context of the request known to RecoverMiddleware,
+// which only sees the context it started with
+type panicScope struct {
+	ctx context.Context
+}
+
+// PanicContextMiddleware hands the context the handler runs with, one carrying the
+// authenticated user, to RecoverMiddleware for its report. It goes last in the chain.
+func (m Middleware) PanicContextMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope, ok := r.Context().Value(panicScopeKey{}).(*panicScope)
+		if ok {
+			scope.ctx = r.Context()
+		}
+		next(w, r)
+	}
+}
+
+// RecoverMiddleware starts the first span of the request, with the path kept in the
+// context for the sampler to pick the ratio of the route. A panic further down the
+// chain is recorded on the span as an exception event with its stack trace, logged,
+// sent to the panic reporter when one is configured, and answered with a 500.
+func (m Middleware) RecoverMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		scope := &panicScope{}
+		r = withRoute(r)
+		traceCtx, span := m.tracer.Start(context.WithValue(r.Context(), panicScopeKey{}, scope), "RecoverMiddleware")
+		scope.ctx = traceCtx
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				span.End()
+				return
+			}
+			// The server aborts the response on purpose, there is nothing to report
+			if recovered == http.ErrAbortHandler {
+				span.End()
+				panic(recovered)
+			}
+
+			p := newPanic(recovered, scope.ctx, r)
+			m.recordPanic(traceCtx, span, p)
+			span.End()
+
+			logger := logutil.WithContext(traceCtx, m.logger)
+			problem.New().WriteError(context.Background(), w, handlerutil.ErrInternalServer, logger)
+		}()
+
+		next(w, r.WithContext(traceCtx))
+	}
+}
+
+func newPanic(recovered any, ctx context.Context, r *http.Request) Panic {
+	p := Panic{
+		Value:  recovered,
+		Stack:  string(debug.Stack()),
+		Time:   time.Now(),
+		Method: r.Method,
+		Route:  r.Pattern,
+	}
+	if p.Route == "" {
+		p.Route = r.URL.Path
+	}
+
+	// The stack starts in the deferred function of RecoverMiddleware; the frames from the
+	// panic site on follow runtime.gopanic
+	pcs := make([]uintptr, maxPanicFrames)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			p.Frames = p.Frames[:0]
+		} else {
+			p.Frames = append(p.Frames, frame)
+		}
+		if !more {
+			break
+		}
+	}
+
+	currentUser, ok := user.GetFromContext(ctx)
+	if ok {
+		p.UserID = currentUser.ID.String()
+	}
+
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		p.TraceID = spanContext.TraceID().String()
+		p.SpanID = spanContext.SpanID().String()
+	}
+
+	return p
+}
+
+func (m Middleware) recordPanic(ctx context.Context, span trace.Span, p Panic) {
+	attributes := []attribute.KeyValue{
+		semconv.ExceptionTypeKey.String(p.Type()),
+		semconv.ExceptionMessageKey.String(p.Message()),
+		semconv.ExceptionStacktraceKey.String(p.Stack),
+		attribute.String("http.route", p.Route),
+	}
+	if p.UserID != "" {
+		attributes = append(attributes, attribute.String("user.id", p.UserID))
+	}
+	span.AddEvent(semconv.ExceptionEventName, trace.WithAttributes(attributes...))
+	span.SetStatus(codes.Error, "panic")
+
+	m.panics.Add(ctx, 1)
+
+	logger := logutil.WithContext(ctx, m.logger)
+	logger.Error("Recovered from panic",
+		zap.String("panic", p.Message()),
+		zap.String("type", p.Type()),
+		zap.String("route", p.Route),
+		zap.String("user_id", p.UserID),
+		zap.String("stack", p.Stack))
+
+	if m.reporter != nil {
+		m.reporter.ReportPanic(ctx, p)
+	}
+}
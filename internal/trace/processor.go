@@ -0,0 +1,99 @@
+package trace
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxHeldTraces bounds the unsampled requests in flight whose spans are held;
+	// spans of further requests are dropped as the sampler decided
+	maxHeldTraces = 4096
+	// maxHeldSpans bounds the spans held for one request
+	maxHeldSpans = 512
+)
+
+// ErrorProcessor exports the requests the sampler left out when they fail. Sampled
+// spans go straight to next; the recorded spans of an unsampled request are held until
+// its first span ends, then handed to next if any of them ended with an error status.
+type ErrorProcessor struct {
+	next sdktrace.SpanProcessor
+
+	mu     sync.Mutex
+	traces map[trace.TraceID]*heldTrace
+}
+
+type heldTrace struct {
+	spans  []sdktrace.ReadOnlySpan
+	failed bool
+}
+
+// sampledSpan reports a held span as sampled, the batch processor exporting no other
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	spanContext := s.ReadOnlySpan.SpanContext()
+	return spanContext.WithTraceFlags(spanContext.TraceFlags().WithSampled(true))
+}
+
+func NewErrorProcessor(next sdktrace.SpanProcessor) *ErrorProcessor {
+	return &ErrorProcessor{
+		next:   next,
+		traces: make(map[trace.TraceID]*heldTrace),
+	}
+}
+
+func (p *ErrorProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p *ErrorProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	spanContext := s.SpanContext()
+	if spanContext.IsSampled() {
+		p.next.OnEnd(s)
+		return
+	}
+
+	root := !s.Parent().IsValid() || s.Parent().IsRemote()
+	failed := s.Status().Code == codes.Error
+
+	p.mu.Lock()
+	held, ok := p.traces[spanContext.TraceID()]
+	if !ok {
+		if len(p.traces) >= maxHeldTraces && !root {
+			p.mu.Unlock()
+			return
+		}
+		held = &heldTrace{}
+		p.traces[spanContext.TraceID()] = held
+	}
+	if len(held.spans) < maxHeldSpans {
+		held.spans = append(held.spans, s)
+	}
+	held.failed = held.failed || failed
+	if root {
+		delete(p.traces, spanContext.TraceID())
+	}
+	p.mu.Unlock()
+
+	if !root || !held.failed {
+		return
+	}
+	for _, span := range held.spans {
+		p.next.OnEnd(sampledSpan{ReadOnlySpan: span})
+	}
+}
+
+func (p *ErrorProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *ErrorProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,119 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the correlation identifier in both directions
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength bounds the inbound identifiers taken over as they are
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the correlation identifier of the request
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID accepts identifiers safe to echo in a header and to log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestIDMiddleware gives every response an X-Request-Id: the one the client sent,
+// or the trace ID of the request, so the logs of a request can be found from what the
+// client saw. Problem responses carry it in the body as requestId too. It runs after
+// TraceMiddleware, which starts the trace.
+func (m Middleware) RequestIDMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = span.SpanContext().TraceID().String()
+		}
+		span.SetAttributes(attribute.String("request_id", id))
+
+		w.Header().Set(RequestIDHeader, id)
+
+		writer := &problemWriter{ResponseWriter: w, requestID: id}
+		next(writer, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		writer.finish()
+	}
+}
+
+// problemWriter holds back problem responses to add the request ID to their body
+type problemWriter struct {
+	http.ResponseWriter
+	requestID string
+
+	status  int
+	problem bool
+	buffer  bytes.Buffer
+}
+
+func (w *problemWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "application/problem+json") {
+		w.problem = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *problemWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.problem {
+		return w.buffer.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *problemWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *problemWriter) finish() {
+	if !w.problem {
+		return
+	}
+
+	body := w.buffer.Bytes()
+	var problem map[string]any
+	if json.Unmarshal(body, &problem) == nil {
+		problem["requestId"] = w.requestID
+		encoded, err := json.Marshal(problem)
+		if err == nil {
+			body = encoded
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
@@ -0,0 +1,11 @@
+package trace
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the trace sampling for admins
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /admin/trace-sampling", route.Authenticated, route.PermissionAdmin, h.GetSamplingHandler)
+	r.Handle("PUT /admin/trace-sampling", route.Authenticated, route.PermissionAdmin, h.SetSamplingHandler)
+}
@@ -0,0 +1,198 @@
+package trace
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// SamplingConfig sets the share of requests traced. Ratio is read from RatioStr, from
+// "0" to "1", and defaults to tracing every request; Routes overrides it for the paths
+// starting with each key, the longest matching prefix winning.
+type SamplingConfig struct {
+	RatioStr string             `yaml:"ratio"`
+	Routes   map[string]float64 `yaml:"routes"`
+
+	Ratio float64 `yaml:"-"`
+}
+
+func (c *SamplingConfig) Validate() error {
+	c.Ratio = 1
+	if c.RatioStr != "" {
+		ratio, err := strconv.ParseFloat(c.RatioStr, 64)
+		if err != nil {
+			return fmt.Errorf("invalid trace_sampling.ratio: %w", err)
+		}
+		c.Ratio = ratio
+	}
+
+	err := Sampling{Ratio: c.Ratio, Routes: c.Routes}.validate()
+	if err != nil {
+		return fmt.Errorf("invalid trace_sampling: %w", err)
+	}
+	return nil
+}
+
+// Sampling is the ratio of requests traced and its overrides by path prefix
+type Sampling struct {
+	Ratio  float64            `json:"ratio"`
+	Routes map[string]float64 `json:"routes"`
+}
+
+func (s Sampling) validate() error {
+	if s.Ratio < 0 || s.Ratio > 1 {
+		return fmt.Errorf("%w: %v", internal.ErrInvalidSampleRatio, s.Ratio)
+	}
+	for prefix, ratio := range s.Routes {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("%w: route %q does not start with /", internal.ErrInvalidSampleRatio, prefix)
+		}
+		if ratio < 0 || ratio > 1 {
+			return fmt.Errorf("%w: %v for %s", internal.ErrInvalidSampleRatio, ratio, prefix)
+		}
+	}
+	return nil
+}
+
+type routeRatio struct {
+	prefix string
+	bound  uint64
+}
+
+// samplingState is swapped as a whole, so a sampling decision never sees half of an update
+type samplingState struct {
+	sampling Sampling
+	bound    uint64
+	// routes is sorted by descending prefix length, the first match being the longest
+	routes []routeRatio
+}
+
+type routeKey struct{}
+
+// withRoute keeps the path of the request for the sampler, which decides on the first
+// span of the request, before the route is known from its name or attributes
+func withRoute(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, r.URL.Path))
+}
+
+// Sampler is a parent-based ratio sampler. A trace continued from a caller keeps the
+// decision of the caller; a trace started here is sampled by the ratio of its route.
+//
+// Spans left out by the ratio are still recorded, so the ErrorProcessor can export the
+// requests that failed. The ratio can be changed by an admin through the API; a change
+// does not survive a restart, which starts from the configured sampling again.
+type Sampler struct {
+	logger *zap.Logger
+	state  atomic.Pointer[samplingState]
+}
+
+func NewSampler(logger *zap.Logger, config SamplingConfig) *Sampler {
+	s := &Sampler{logger: logger}
+	s.state.Store(newSamplingState(Sampling{Ratio: config.Ratio, Routes: config.Routes}))
+	return s
+}
+
+// ratioBound turns a ratio into the bound trace IDs are compared to, as in
+// sdktrace.TraceIDRatioBased
+func ratioBound(ratio float64) uint64 {
+	if ratio >= 1 {
+		return 1 << 63
+	}
+	return uint64(ratio * (1 << 63))
+}
+
+func newSamplingState(sampling Sampling) *samplingState {
+	routes := make(map[string]float64, len(sampling.Routes))
+	state := &samplingState{bound: ratioBound(sampling.Ratio)}
+	for prefix, ratio := range sampling.Routes {
+		routes[prefix] = ratio
+		state.routes = append(state.routes, routeRatio{prefix: prefix, bound: ratioBound(ratio)})
+	}
+	sort.Slice(state.routes, func(i, j int) bool {
+		return len(state.routes[i].prefix) > len(state.routes[j].prefix)
+	})
+	state.sampling = Sampling{Ratio: sampling.Ratio, Routes: routes}
+	return state
+}
+
+// Get returns the sampling in use
+func (s *Sampler) Get() Sampling {
+	sampling := s.state.Load().sampling
+	routes := make(map[string]float64, len(sampling.Routes))
+	for prefix, ratio := range sampling.Routes {
+		routes[prefix] = ratio
+	}
+	return Sampling{Ratio: sampling.Ratio, Routes: routes}
+}
+
+// Set replaces the sampling, for the traces started from now on
+func (s *Sampler) Set(sampling Sampling) error {
+	err := sampling.validate()
+	if err != nil {
+		return err
+	}
+
+	previous := s.state.Swap(newSamplingState(sampling)).sampling
+	s.logger.Info("Changed trace sampling",
+		zap.Float64("from", previous.Ratio),
+		zap.Float64("to", sampling.Ratio),
+		zap.Int("routes", len(sampling.Routes)))
+
+	return nil
+}
+
+func (s *Sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	result := sdktrace.SamplingResult{Tracestate: parent.TraceState()}
+
+	switch {
+	case parent.IsValid() && parent.IsSampled():
+		result.Decision = sdktrace.RecordAndSample
+	case parent.IsValid() && parent.IsRemote():
+		result.Decision = sdktrace.Drop
+	case parent.IsValid():
+		// The parent was left out by the ratio; its children are recorded with it
+		if trace.SpanFromContext(p.ParentContext).IsRecording() {
+			result.Decision = sdktrace.RecordOnly
+		} else {
+			result.Decision = sdktrace.Drop
+		}
+	default:
+		result.Decision = sdktrace.RecordOnly
+		if binary.BigEndian.Uint64(p.TraceID[8:16])>>1 < s.bound(p.ParentContext) {
+			result.Decision = sdktrace.RecordAndSample
+		}
+	}
+
+	return result
+}
+
+// bound returns the bound of the route of the request, or of the default ratio
+func (s *Sampler) bound(ctx context.Context) uint64 {
+	state := s.state.Load()
+
+	path, ok := ctx.Value(routeKey{}).(string)
+	if ok {
+		for _, route := range state.routes {
+			if strings.HasPrefix(path, route.prefix) {
+				return route.bound
+			}
+		}
+	}
+
+	return state.bound
+}
+
+func (s *Sampler) Description() string {
+	return fmt.Sprintf("ParentBased{RouteRatio{%g}}", s.state.Load().sampling.Ratio)
+}
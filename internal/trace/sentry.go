@@ -0,0 +1,212 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// sentryTimeout bounds the time spent sending one event
+const sentryTimeout = 10 * time.Second
+
+// BuildInfo identifies the running build in the reports
+type BuildInfo struct {
+	Version     string
+	CommitHash  string
+	Environment string
+}
+
+// SentryReporter sends recovered panics to Sentry as events, through the store endpoint
+// of the project named by the DSN
+type SentryReporter struct {
+	logger *zap.Logger
+	client *http.Client
+	build  BuildInfo
+
+	endpoint string
+	auth     string
+}
+
+// NewSentryReporter parses dsn, of the form https://<key>@<host>/<project>
+func NewSentryReporter(logger *zap.Logger, dsn string, build BuildInfo) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry dsn: %w", err)
+	}
+	key := u.User.Username()
+	project := path.Base(u.Path)
+	if u.Scheme == "" || u.Host == "" || key == "" || project == "." || project == "/" {
+		return nil, fmt.Errorf("invalid sentry dsn: expected https://<key>@<host>/<project>")
+	}
+
+	endpoint := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   path.Join(path.Dir(u.Path), "api", project, "store") + "/",
+	}
+
+	return &SentryReporter{
+		logger:   logger,
+		client:   &http.Client{Timeout: sentryTimeout},
+		build:    build,
+		endpoint: endpoint.String(),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=core-system-backend/%s, sentry_key=%s", build.Version, key),
+	}, nil
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	User        *sentryUser       `json:"user,omitempty"`
+	Contexts    map[string]any    `json:"contexts,omitempty"`
+	Exception   sentryExceptions  `json:"exception"`
+}
+
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string           `json:"type"`
+	Value      string           `json:"value"`
+	Stacktrace sentryStacktrace `json:"stacktrace"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+func (s *SentryReporter) event(p Panic) (sentryEvent, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return sentryEvent{}, err
+	}
+
+	// Sentry lists frames from the outermost call to the one that panicked
+	frames := make([]sentryFrame, 0, len(p.Frames))
+	for i := len(p.Frames) - 1; i >= 0; i-- {
+		frame := p.Frames[i]
+		module, function := splitFunction(frame.Function)
+		frames = append(frames, sentryFrame{
+			Function: function,
+			Module:   module,
+			AbsPath:  frame.File,
+			Lineno:   frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, "NYCU-SDC/core-system-backend/"),
+		})
+	}
+
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   p.Time.UTC().Format(time.RFC3339Nano),
+		Level:       "fatal",
+		Platform:    "go",
+		Release:     s.build.Version,
+		Environment: s.build.Environment,
+		Transaction: p.Route,
+		Tags: map[string]string{
+			"commit_hash": s.build.CommitHash,
+			"method":      p.Method,
+		},
+		Exception: sentryExceptions{Values: []sentryException{{
+			Type:       p.Type(),
+			Value:      p.Message(),
+			Stacktrace: sentryStacktrace{Frames: frames},
+		}}},
+	}
+	if p.UserID != "" {
+		event.User = &sentryUser{ID: p.UserID}
+	}
+	if p.TraceID != "" {
+		event.Contexts = map[string]any{
+			"trace": map[string]string{"type": "trace", "trace_id": p.TraceID, "span_id": p.SpanID},
+		}
+	}
+
+	return event, nil
+}
+
+// splitFunction splits "example.com/pkg.(*Type).Method" into its package and the rest
+func splitFunction(name string) (string, string) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", name
+	}
+	return name[:slash+1+dot], name[slash+2+dot:]
+}
+
+// ReportPanic sends the panic in the background, the response being written meanwhile
+func (s *SentryReporter) ReportPanic(_ context.Context, p Panic) {
+	event, err := s.event(p)
+	if err != nil {
+		s.logger.Warn("Failed to build Sentry event", zap.Error(err))
+		return
+	}
+
+	go func() {
+		err := s.send(event)
+		if err != nil {
+			s.logger.Warn("Failed to report panic to Sentry", zap.Error(err), zap.String("event_id", event.EventID))
+		}
+	}()
+}
+
+func (s *SentryReporter) send(event sentryEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sentryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("sentry responded with %s", resp.Status)
+	}
+	return nil
+}
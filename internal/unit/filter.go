@@ -0,0 +1,43 @@
+package unit
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const maxMemberSearchLength = 255
+
+// MemberFilter narrows down the members of an organization or a unit
+type MemberFilter struct {
+	Search    string
+	Role      string
+	Recursive bool
+}
+
+// ParseMemberFilter parses the search, role and recursive query parameters.
+// Search matches the name, username or any email of a member; recursive also
+// includes the members of every sub-unit.
+func ParseMemberFilter(r *http.Request) (MemberFilter, error) {
+	query := r.URL.Query()
+
+	filter := MemberFilter{
+		Search: strings.TrimSpace(query.Get("search")),
+		Role:   strings.TrimSpace(query.Get("role")),
+	}
+	if len(filter.Search) > maxMemberSearchLength {
+		return MemberFilter{}, internal.ErrSearchTooLong
+	}
+
+	recursiveStr := query.Get("recursive")
+	if recursiveStr != "" {
+		recursive, err := strconv.ParseBool(recursiveStr)
+		if err != nil {
+			return MemberFilter{}, internal.ErrInvalidRecursiveParameter
+		}
+		filter.Recursive = recursive
+	}
+
+	return filter, nil
+}
@@ -3,6 +3,7 @@ package unit
 import (
 	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/profile"
 	"NYCU-SDC/core-system-backend/internal/tenant"
 	"NYCU-SDC/core-system-backend/internal/user"
 	"context"
@@ -14,9 +15,11 @@ import (
 
 	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	pagutil "github.com/NYCU-SDC/summer/pkg/pagination"
 	"github.com/NYCU-SDC/summer/pkg/problem"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -34,8 +37,11 @@ type Store interface {
 	AddParent(ctx context.Context, id uuid.UUID, parentID uuid.UUID) (Unit, error)
 	ListSubUnits(ctx context.Context, id uuid.UUID, unitType Type) ([]Unit, error)
 	ListSubUnitIDs(ctx context.Context, id uuid.UUID, unitType Type) ([]uuid.UUID, error)
-	AddMember(ctx context.Context, unitType Type, id uuid.UUID, username string) (AddMemberRow, error)
+	AddMember(ctx context.Context, unitType Type, id uuid.UUID, username string, validUntil *time.Time) (AddMemberRow, error)
 	ListMembers(ctx context.Context, id uuid.UUID) ([]user.Profile, error)
+	ListMembersFiltered(ctx context.Context, id uuid.UUID, filter MemberFilter, page int, size int) ([]Member, error)
+	CountMembers(ctx context.Context, id uuid.UUID, filter MemberFilter) (int64, error)
+	RenewMember(ctx context.Context, unitType Type, id uuid.UUID, memberID uuid.UUID, validUntil *time.Time) (UnitMember, error)
 	RemoveMember(ctx context.Context, unitType Type, id uuid.UUID, memberID uuid.UUID) error
 	GetOrganizationByIDWithSlug(ctx context.Context, id uuid.UUID) (Organization, error)
 }
@@ -56,6 +62,18 @@ type tenantStore interface {
 type userStore interface {
 	GetEmailsByID(ctx context.Context, userID uuid.UUID) ([]string, error)
 }
+
+// profileStore collects the profile fields of the organization from a member as they join
+type profileStore interface {
+	Validate(ctx context.Context, orgID uuid.UUID, values map[string]string) error
+	Save(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, values map[string]string) (profile.Profile, error)
+}
+
+// quotaStore holds an organization to its quota of members
+type quotaStore interface {
+	Check(ctx context.Context, orgID uuid.UUID) error
+	Observe(ctx context.Context, orgID uuid.UUID)
+}
 type Handler struct {
 	logger        *zap.Logger
 	tracer        trace.Tracer
@@ -65,6 +83,8 @@ type Handler struct {
 	formStore     formStore
 	tenantStore   tenantStore
 	userStore     userStore
+	profileStore  profileStore
+	quotaStore    quotaStore
 }
 
 func NewHandler(
@@ -75,6 +95,8 @@ func NewHandler(
 	formStore formStore,
 	tenantStore tenantStore,
 	userStore userStore,
+	profileStore profileStore,
+	quotaStore quotaStore,
 ) *Handler {
 	return &Handler{
 		logger:        logger,
@@ -84,6 +106,8 @@ func NewHandler(
 		formStore:     formStore,
 		tenantStore:   tenantStore,
 		userStore:     userStore,
+		profileStore:  profileStore,
+		quotaStore:    quotaStore,
 		tracer:        otel.Tracer("unit/handler"),
 	}
 }
@@ -124,11 +148,54 @@ type OrganizationResponse struct {
 type OrgMemberResponse struct {
 	OrgID      uuid.UUID            `json:"orgId"`
 	SimpleUser user.ProfileResponse `json:"member"`
+	ValidUntil *time.Time           `json:"validUntil,omitempty"`
 }
 
 type UnitMemberResponse struct {
 	UnitID     uuid.UUID            `json:"unitId"`
 	SimpleUser user.ProfileResponse `json:"member"`
+	ValidUntil *time.Time           `json:"validUntil,omitempty"`
+}
+
+// AddMemberRequest adds a member by email. Memberships without validUntil never expire.
+// Profile holds values of the profile fields of the organization, keyed by field key,
+// and is ignored when adding a unit member.
+type AddMemberRequest struct {
+	Email      string            `json:"email"`
+	ValidUntil *time.Time        `json:"validUntil"`
+	Profile    map[string]string `json:"profile" validate:"max=100"`
+}
+
+// RenewMemberRequest sets the new expiry of a membership; null keeps it indefinitely
+type RenewMemberRequest struct {
+	ValidUntil *time.Time `json:"validUntil"`
+}
+
+type MembershipResponse struct {
+	UnitID     uuid.UUID  `json:"unitId"`
+	MemberID   uuid.UUID  `json:"memberId"`
+	ValidUntil *time.Time `json:"validUntil"`
+}
+
+// MemberResponse is a member in the member listings of an organization or a unit
+type MemberResponse struct {
+	user.ProfileResponse
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
+}
+
+func ToMembershipResponse(member UnitMember) MembershipResponse {
+	return MembershipResponse{
+		UnitID:     member.UnitID,
+		MemberID:   member.MemberID,
+		ValidUntil: optionalTime(member.ValidUntil),
+	}
+}
+
+func optionalTime(value pgtype.Timestamptz) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
 }
 
 type UserFormResponse struct {
@@ -203,6 +270,9 @@ type ParentChildRequest struct {
 
 var slugPattern = `^[a-zA-Z0-9_-]+$`
 
+// idSlug cannot be taken by an organization, /orgs/id/{id} addressing organizations by ID
+const idSlug = "id"
+
 func (h *Handler) CreateUnit(w http.ResponseWriter, r *http.Request) {
 	traceCtx, span := h.tracer.Start(r.Context(), "CreateUnit")
 	defer span.End()
@@ -210,7 +280,7 @@ func (h *Handler) CreateUnit(w http.ResponseWriter, r *http.Request) {
 
 	var req Request
 
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid request body: %w", err), logger)
 		return
 	}
@@ -243,7 +313,7 @@ func (h *Handler) CreateOrg(w http.ResponseWriter, r *http.Request) {
 
 	var req OrgRequest
 
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid request body: %w", err), logger)
 		return
 	}
@@ -261,8 +331,8 @@ func (h *Handler) CreateOrg(w http.ResponseWriter, r *http.Request) {
 	}
 
 	matched, err := regexp.MatchString(slugPattern, req.Slug)
-	if err != nil || !matched {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid slug format: must contain only alphanumeric characters, dashes, and underscores"), logger)
+	if err != nil || !matched || req.Slug == idSlug {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid slug format: must contain only alphanumeric characters, dashes, and underscores, and cannot be \"id\""), logger)
 		return
 	}
 
@@ -373,7 +443,7 @@ func (h *Handler) UpdateUnit(w http.ResponseWriter, r *http.Request) {
 	logger := logutil.WithContext(traceCtx, h.logger)
 
 	var req Request
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid request body: %w", err), logger)
 		return
 	}
@@ -406,7 +476,7 @@ func (h *Handler) UpdateOrg(w http.ResponseWriter, r *http.Request) {
 	logger := logutil.WithContext(traceCtx, h.logger)
 
 	var req OrgRequest
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid request body: %w", err), logger)
 		return
 	}
@@ -488,7 +558,7 @@ func (h *Handler) AddParentChild(w http.ResponseWriter, r *http.Request) {
 	logger := logutil.WithContext(traceCtx, h.logger)
 
 	var req ParentChildRequest
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid request body: %w", err), logger)
 		return
 	}
@@ -632,11 +702,8 @@ func (h *Handler) AddOrgMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get Username from request body
-	var params struct {
-		Email string `json:"email"`
-	}
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &params); err != nil {
+	var params AddMemberRequest
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &params); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid request body: %w", err), logger)
 		return
 	}
@@ -646,15 +713,39 @@ func (h *Handler) AddOrgMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	members, err := h.store.AddMember(traceCtx, TypeOrg, orgID, params.Email)
+	if len(params.Profile) > 0 {
+		err = h.profileStore.Validate(traceCtx, orgID, params.Profile)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, err, logger)
+			return
+		}
+	}
+
+	err = h.quotaStore.Check(traceCtx, orgID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	members, err := h.store.AddMember(traceCtx, TypeOrg, orgID, params.Email, params.ValidUntil)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to add org member: %w", err), logger)
 		return
 	}
+	h.quotaStore.Observe(traceCtx, orgID)
+
+	if len(params.Profile) > 0 {
+		_, err = h.profileStore.Save(traceCtx, orgID, members.MemberID, params.Profile)
+		if err != nil {
+			h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to save org member profile: %w", err), logger)
+			return
+		}
+	}
 
 	orgMemberResponse := OrgMemberResponse{
 		OrgID:      orgID,
 		SimpleUser: h.createProfileResponseWithEmails(traceCtx, logger, members.MemberID, members.Name.String, members.Username.String, members.AvatarUrl.String),
+		ValidUntil: optionalTime(members.ValidUntil),
 	}
 	handlerutil.WriteJSONResponse(w, http.StatusCreated, orgMemberResponse)
 }
@@ -671,10 +762,8 @@ func (h *Handler) AddUnitMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var params struct {
-		Email string `json:"email"`
-	}
-	if err := handlerutil.ParseAndValidateRequestBody(traceCtx, h.validator, r, &params); err != nil {
+	var params AddMemberRequest
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &params); err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid request body: %w", err), logger)
 		return
 	}
@@ -684,7 +773,7 @@ func (h *Handler) AddUnitMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	member, err := h.store.AddMember(traceCtx, TypeUnit, id, params.Email)
+	member, err := h.store.AddMember(traceCtx, TypeUnit, id, params.Email, params.ValidUntil)
 	if err != nil {
 		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to add unit member: %w", err), logger)
 		return
@@ -693,6 +782,7 @@ func (h *Handler) AddUnitMember(w http.ResponseWriter, r *http.Request) {
 	handlerutil.WriteJSONResponse(w, http.StatusCreated, UnitMemberResponse{
 		UnitID:     id,
 		SimpleUser: h.createProfileResponseWithEmails(traceCtx, logger, member.MemberID, member.Name.String, member.Username.String, member.AvatarUrl.String),
+		ValidUntil: optionalTime(member.ValidUntil),
 	})
 }
 
@@ -713,45 +803,118 @@ func (h *Handler) ListOrgMembers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Todo: Need to recursively obtain members of the entire organization
-	members, err := h.store.ListMembers(traceCtx, orgID)
+	h.writeMembers(traceCtx, w, r, logger, orgID)
+}
+
+func (h *Handler) ListUnitMembers(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListUnitMembers")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	idStr := r.PathValue("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid unit ID: %w", err), logger)
+		return
+	}
+
+	h.writeMembers(traceCtx, w, r, logger, id)
+}
+
+// writeMembers writes one page of the members of an organization or a unit,
+// filtered by the query parameters of the request
+func (h *Handler) writeMembers(ctx context.Context, w http.ResponseWriter, r *http.Request, logger *zap.Logger, id uuid.UUID) {
+	factory := pagutil.NewFactory[MemberResponse](200, []string{"Name"})
+	request, err := factory.GetRequest(r)
+	if err != nil {
+		h.problemWriter.WriteError(ctx, w, err, logger)
+		return
+	}
+
+	filter, err := ParseMemberFilter(r)
 	if err != nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to list org members: %w", err), logger)
+		h.problemWriter.WriteError(ctx, w, err, logger)
 		return
 	}
 
-	response := make([]user.ProfileResponse, 0, len(members))
+	total, err := h.store.CountMembers(ctx, id, filter)
+	if err != nil {
+		h.problemWriter.WriteError(ctx, w, fmt.Errorf("failed to count members: %w", err), logger)
+		return
+	}
+
+	members, err := h.store.ListMembersFiltered(ctx, id, filter, request.Page, request.Size)
+	if err != nil {
+		h.problemWriter.WriteError(ctx, w, fmt.Errorf("failed to list members: %w", err), logger)
+		return
+	}
+
+	items := make([]MemberResponse, 0, len(members))
 	for _, member := range members {
-		response = append(response, user.ProfileResponse(member))
+		items = append(items, MemberResponse{
+			ProfileResponse: user.ProfileResponse(member.Profile),
+			ValidUntil:      member.ValidUntil,
+		})
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+	handlerutil.WriteJSONResponse(w, http.StatusOK, factory.NewResponse(items, int(total), request.Page, request.Size))
 }
 
-func (h *Handler) ListUnitMembers(w http.ResponseWriter, r *http.Request) {
-	traceCtx, span := h.tracer.Start(r.Context(), "ListUnitMembers")
+func (h *Handler) RenewOrgMember(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RenewOrgMember")
 	defer span.End()
 	logger := logutil.WithContext(traceCtx, h.logger)
 
-	idStr := r.PathValue("id")
-	id, err := uuid.Parse(idStr)
+	slug, err := internal.GetSlugFromContext(traceCtx)
 	if err != nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("invalid unit ID: %w", err), logger)
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org slug from context: %w", err), logger)
+		return
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(traceCtx, slug)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to get org ID by slug: %w", err), logger)
 		return
 	}
 
-	members, err := h.store.ListMembers(traceCtx, id)
+	h.renewMember(traceCtx, w, r, logger, TypeOrg, orgID)
+}
+
+func (h *Handler) RenewUnitMember(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RenewUnitMember")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
 	if err != nil {
-		h.problemWriter.WriteError(traceCtx, w, fmt.Errorf("failed to list unit members: %w", err), logger)
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
 		return
 	}
 
-	response := make([]user.ProfileResponse, 0, len(members))
-	for _, memberProfile := range members {
-		response = append(response, user.ProfileResponse(memberProfile))
+	h.renewMember(traceCtx, w, r, logger, TypeUnit, id)
+}
+
+func (h *Handler) renewMember(ctx context.Context, w http.ResponseWriter, r *http.Request, logger *zap.Logger, unitType Type, id uuid.UUID) {
+	memberID, err := internal.ParseUUID(r.PathValue("member_id"))
+	if err != nil {
+		h.problemWriter.WriteError(ctx, w, err, logger)
+		return
+	}
+
+	var req RenewMemberRequest
+	err = internal.ParseAndValidateRequestBody(ctx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(ctx, w, err, logger)
+		return
+	}
+
+	member, err := h.store.RenewMember(ctx, unitType, id, memberID, req.ValidUntil)
+	if err != nil {
+		h.problemWriter.WriteError(ctx, w, err, logger)
+		return
 	}
 
-	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToMembershipResponse(member))
 }
 
 func (h *Handler) RemoveOrgMember(w http.ResponseWriter, r *http.Request) {
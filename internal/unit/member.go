@@ -2,24 +2,48 @@ package unit
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/user"
 
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 )
 
-// AddMember adds a member to an organization or a unit
-func (s *Service) AddMember(ctx context.Context, unitType Type, id uuid.UUID, memberEmail string) (AddMemberRow, error) {
+// DefaultExpiryInterval is how often memberships past their validUntil are removed
+const DefaultExpiryInterval = time.Minute
+
+// Member is a member of an organization or a unit. ValidUntil is nil for
+// memberships that never expire.
+type Member struct {
+	user.Profile
+	ValidUntil *time.Time
+}
+
+// AddMember adds a member to an organization or a unit. Adding an existing member
+// replaces the expiry of the membership.
+func (s *Service) AddMember(ctx context.Context, unitType Type, id uuid.UUID, memberEmail string, validUntil *time.Time) (AddMemberRow, error) {
 	traceCtx, span := s.tracer.Start(ctx, fmt.Sprintf("Add%sMember", unitType.String()))
 	defer span.End()
 
 	logger := logutil.WithContext(traceCtx, s.logger)
+
+	expiry, err := membershipExpiry(validUntil)
+	if err != nil {
+		span.RecordError(err)
+		return AddMemberRow{}, err
+	}
+
 	memberRow, err := s.queries.AddMember(traceCtx, AddMemberParams{
 		UnitID:      id,
+		ValidUntil:  expiry,
 		MemberEmail: memberEmail,
 	})
 	if err != nil {
@@ -72,6 +96,72 @@ func (s *Service) ListMembers(ctx context.Context, id uuid.UUID) ([]user.Profile
 	return profiles, nil
 }
 
+// ListMembersFiltered lists one page of the members of an organization or a unit
+// matching the filter, ordered by name
+func (s *Service) ListMembersFiltered(ctx context.Context, id uuid.UUID, filter MemberFilter, page int, size int) ([]Member, error) {
+	traceCtx, span := s.tracer.Start(ctx, "ListMembersFiltered")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	params := ListMembersFilteredParams{
+		UnitID:    id,
+		Recursive: filter.Recursive,
+		Search:    filter.Search,
+		Role:      filter.Role,
+		PageLimit: int32(size),
+	}
+	if page > 1 {
+		params.PageOffset = int32((page - 1) * size)
+	}
+
+	members, err := s.queries.ListMembersFiltered(traceCtx, params)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list filtered members")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	result := make([]Member, 0, len(members))
+	for _, member := range members {
+		item := Member{
+			Profile: user.Profile{
+				ID:        member.MemberID,
+				Name:      member.Name.String,
+				Username:  member.Username.String,
+				AvatarURL: member.AvatarUrl.String,
+				Emails:    user.ConvertEmailsToSlice(member.Emails),
+			},
+		}
+		if member.ValidUntil.Valid {
+			item.ValidUntil = &member.ValidUntil.Time
+		}
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// CountMembers counts the members of an organization or a unit matching the filter
+func (s *Service) CountMembers(ctx context.Context, id uuid.UUID, filter MemberFilter) (int64, error) {
+	traceCtx, span := s.tracer.Start(ctx, "CountMembers")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	total, err := s.queries.CountMembersFiltered(traceCtx, CountMembersFilteredParams{
+		UnitID:    id,
+		Recursive: filter.Recursive,
+		Search:    filter.Search,
+		Role:      filter.Role,
+	})
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "count members")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	return total, nil
+}
+
 // ListUnitsMembers lists members for multiple units at once
 func (s *Service) ListUnitsMembers(ctx context.Context, unitIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
 	traceCtx, span := s.tracer.Start(ctx, "ListMultiUnitMembers")
@@ -101,6 +191,91 @@ func (s *Service) ListUnitsMembers(ctx context.Context, unitIDs []uuid.UUID) (ma
 	return membersMap, nil
 }
 
+// RenewMember moves the expiry of a membership; a nil validUntil makes it permanent
+func (s *Service) RenewMember(ctx context.Context, unitType Type, id uuid.UUID, memberID uuid.UUID, validUntil *time.Time) (UnitMember, error) {
+	traceCtx, span := s.tracer.Start(ctx, fmt.Sprintf("Renew%sMember", unitType.String()))
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	expiry, err := membershipExpiry(validUntil)
+	if err != nil {
+		span.RecordError(err)
+		return UnitMember{}, err
+	}
+
+	member, err := s.queries.RenewMember(traceCtx, RenewMemberParams{
+		ValidUntil: expiry,
+		UnitID:     id,
+		MemberID:   memberID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrMemberNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "member_id", memberID.String(), logger, "renew member")
+		}
+		span.RecordError(err)
+		return UnitMember{}, err
+	}
+
+	logger.Info(fmt.Sprintf("Renewed %s member", unitType.String()),
+		zap.String("unit_id", id.String()),
+		zap.String("member_id", memberID.String()))
+
+	return member, nil
+}
+
+// Start removes expired memberships every interval until the context is done
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := s.ExpireMembers(ctx)
+			if err != nil {
+				s.logger.Error("Failed to expire memberships", zap.Error(err))
+			}
+		}
+	}
+}
+
+// ExpireMembers removes every membership whose validUntil has passed
+func (s *Service) ExpireMembers(ctx context.Context) error {
+	traceCtx, span := s.tracer.Start(ctx, "ExpireMembers")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	expired, err := s.queries.ExpireMembers(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "expire members")
+		span.RecordError(err)
+		return err
+	}
+
+	for _, member := range expired {
+		logger.Info("Membership expired",
+			zap.String("unit_id", member.UnitID.String()),
+			zap.String("member_id", member.MemberID.String()),
+			zap.Time("valid_until", member.ValidUntil.Time))
+	}
+
+	return nil
+}
+
+func membershipExpiry(validUntil *time.Time) (pgtype.Timestamptz, error) {
+	if validUntil == nil {
+		return pgtype.Timestamptz{}, nil
+	}
+	if !validUntil.After(time.Now()) {
+		return pgtype.Timestamptz{}, internal.ErrValidUntilInPast
+	}
+	return pgtype.Timestamptz{Time: *validUntil, Valid: true}, nil
+}
+
 // RemoveMember removes a member from an organization or a unit
 func (s *Service) RemoveMember(ctx context.Context, unitType Type, id uuid.UUID, memberID uuid.UUID) error {
 	traceCtx, span := s.tracer.Start(ctx, fmt.Sprintf("Remove%sMember", unitType.String()))
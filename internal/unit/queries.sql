@@ -54,12 +54,13 @@ SELECT id FROM units WHERE parent_id = $1;
 
 -- name: AddMember :one
 WITH inserted_member AS (
-    INSERT INTO unit_members (unit_id, member_id)
-    SELECT sqlc.arg(unit_id), user_emails.user_id
+    INSERT INTO unit_members (unit_id, member_id, valid_until)
+    SELECT sqlc.arg(unit_id), user_emails.user_id, sqlc.narg(valid_until)
     FROM user_emails
         WHERE user_emails.value = sqlc.arg(member_email)
     ON CONFLICT (unit_id, member_id) DO UPDATE
-        SET member_id = EXCLUDED.member_id
+        SET member_id = EXCLUDED.member_id,
+            valid_until = EXCLUDED.valid_until
     RETURNING *
 )
 SELECT um.*, u.name, u.username, u.avatar_url
@@ -76,6 +77,55 @@ FROM unit_members m
 JOIN users_with_emails u ON u.id = m.member_id
 WHERE m.unit_id = $1;
 
+-- name: ListMembersFiltered :many
+WITH RECURSIVE scope AS (
+    SELECT units.id FROM units WHERE units.id = @unit_id
+    UNION
+    SELECT child.id
+    FROM units child
+    JOIN scope ON child.parent_id = scope.id OR child.org_id = scope.id
+    WHERE @recursive::boolean
+)
+SELECT u.id AS member_id,
+       u.name,
+       u.username,
+       u.avatar_url,
+       u.emails,
+       (SELECT own.valid_until FROM unit_members own
+        WHERE own.unit_id = @unit_id AND own.member_id = u.id) AS valid_until
+FROM users_with_emails u
+WHERE u.id IN (SELECT m.member_id FROM unit_members m JOIN scope ON scope.id = m.unit_id)
+  AND (@search::text = '' OR u.name ILIKE '%' || @search::text || '%'
+    OR u.username ILIKE '%' || @search::text || '%'
+    OR EXISTS (
+        SELECT 1 FROM user_emails e
+        WHERE e.user_id = u.id AND e.value ILIKE '%' || @search::text || '%'
+    ))
+  AND (@role::text = '' OR @role::text = ANY(u.role))
+ORDER BY u.name, u.id
+LIMIT @page_limit::int
+OFFSET @page_offset::int;
+
+-- name: CountMembersFiltered :one
+WITH RECURSIVE scope AS (
+    SELECT units.id FROM units WHERE units.id = @unit_id
+    UNION
+    SELECT child.id
+    FROM units child
+    JOIN scope ON child.parent_id = scope.id OR child.org_id = scope.id
+    WHERE @recursive::boolean
+)
+SELECT COUNT(*) AS total
+FROM users_with_emails u
+WHERE u.id IN (SELECT m.member_id FROM unit_members m JOIN scope ON scope.id = m.unit_id)
+  AND (@search::text = '' OR u.name ILIKE '%' || @search::text || '%'
+    OR u.username ILIKE '%' || @search::text || '%'
+    OR EXISTS (
+        SELECT 1 FROM user_emails e
+        WHERE e.user_id = u.id AND e.value ILIKE '%' || @search::text || '%'
+    ))
+  AND (@role::text = '' OR @role::text = ANY(u.role));
+
 -- name: ListUnitsMembers :many
 SELECT m.unit_id,
        m.member_id,
@@ -86,5 +136,16 @@ FROM unit_members m
 JOIN users u ON u.id = m.member_id
 WHERE m.unit_id = ANY($1::uuid[]);
 
+-- name: RenewMember :one
+UPDATE unit_members
+SET valid_until = sqlc.narg(valid_until)
+WHERE unit_id = @unit_id AND member_id = @member_id
+RETURNING *;
+
+-- name: ExpireMembers :many
+DELETE FROM unit_members
+WHERE valid_until IS NOT NULL AND valid_until <= now()
+RETURNING *;
+
 -- name: RemoveMember :exec
 DELETE FROM unit_members WHERE unit_id = $1 AND member_id = $2;
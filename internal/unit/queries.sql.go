@@ -14,38 +14,42 @@ import (
 
 const addMember = `-- name: AddMember :one
 WITH inserted_member AS (
-    INSERT INTO unit_members (unit_id, member_id)
-    SELECT $1, user_emails.user_id
+    INSERT INTO unit_members (unit_id, member_id, valid_until)
+    SELECT $1, user_emails.user_id, $2
     FROM user_emails
-        WHERE user_emails.value = $2
+        WHERE user_emails.value = $3
     ON CONFLICT (unit_id, member_id) DO UPDATE
-        SET member_id = EXCLUDED.member_id
-    RETURNING unit_id, member_id
+        SET member_id = EXCLUDED.member_id,
+            valid_until = EXCLUDED.valid_until
+    RETURNING unit_id, member_id, valid_until
 )
-SELECT um.unit_id, um.member_id, u.name, u.username, u.avatar_url
+SELECT um.unit_id, um.member_id, um.valid_until, u.name, u.username, u.avatar_url
 FROM inserted_member um
 LEFT JOIN users u ON u.id = um.member_id
 `
 
 type AddMemberParams struct {
 	UnitID      uuid.UUID
+	ValidUntil  pgtype.Timestamptz
 	MemberEmail string
 }
 
 type AddMemberRow struct {
-	UnitID    uuid.UUID
-	MemberID  uuid.UUID
-	Name      pgtype.Text
-	Username  pgtype.Text
-	AvatarUrl pgtype.Text
+	UnitID     uuid.UUID
+	MemberID   uuid.UUID
+	ValidUntil pgtype.Timestamptz
+	Name       pgtype.Text
+	Username   pgtype.Text
+	AvatarUrl  pgtype.Text
 }
 
 func (q *Queries) AddMember(ctx context.Context, arg AddMemberParams) (AddMemberRow, error) {
-	row := q.db.QueryRow(ctx, addMember, arg.UnitID, arg.MemberEmail)
+	row := q.db.QueryRow(ctx, addMember, arg.UnitID, arg.ValidUntil, arg.MemberEmail)
 	var i AddMemberRow
 	err := row.Scan(
 		&i.UnitID,
 		&i.MemberID,
+		&i.ValidUntil,
 		&i.Name,
 		&i.Username,
 		&i.AvatarUrl,
@@ -53,6 +57,46 @@ func (q *Queries) AddMember(ctx context.Context, arg AddMemberParams) (AddMember
 	return i, err
 }
 
+const countMembersFiltered = `-- name: CountMembersFiltered :one
+WITH RECURSIVE scope AS (
+    SELECT units.id FROM units WHERE units.id = $1
+    UNION
+    SELECT child.id
+    FROM units child
+    JOIN scope ON child.parent_id = scope.id OR child.org_id = scope.id
+    WHERE $2::boolean
+)
+SELECT COUNT(*) AS total
+FROM users_with_emails u
+WHERE u.id IN (SELECT m.member_id FROM unit_members m JOIN scope ON scope.id = m.unit_id)
+  AND ($3::text = '' OR u.name ILIKE '%' || $3::text || '%'
+    OR u.username ILIKE '%' || $3::text || '%'
+    OR EXISTS (
+        SELECT 1 FROM user_emails e
+        WHERE e.user_id = u.id AND e.value ILIKE '%' || $3::text || '%'
+    ))
+  AND ($4::text = '' OR $4::text = ANY(u.role))
+`
+
+type CountMembersFilteredParams struct {
+	UnitID    uuid.UUID
+	Recursive bool
+	Search    string
+	Role      string
+}
+
+func (q *Queries) CountMembersFiltered(ctx context.Context, arg CountMembersFilteredParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countMembersFiltered,
+		arg.UnitID,
+		arg.Recursive,
+		arg.Search,
+		arg.Role,
+	)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const create = `-- name: Create :one
 INSERT INTO units (name, org_id, description, metadata, type, parent_id)
 VALUES ($1, $2, $3, $4, $5, $6)
@@ -101,6 +145,32 @@ func (q *Queries) Delete(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const expireMembers = `-- name: ExpireMembers :many
+DELETE FROM unit_members
+WHERE valid_until IS NOT NULL AND valid_until <= now()
+RETURNING unit_id, member_id, valid_until
+`
+
+func (q *Queries) ExpireMembers(ctx context.Context) ([]UnitMember, error) {
+	rows, err := q.db.Query(ctx, expireMembers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnitMember
+	for rows.Next() {
+		var i UnitMember
+		if err := rows.Scan(&i.UnitID, &i.MemberID, &i.ValidUntil); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAllOrganizations = `-- name: GetAllOrganizations :many
 SELECT u.id, u.org_id, u.parent_id, u.type, u.name, u.description, u.metadata, u.created_at, u.updated_at, sh.slug
 FROM units u
@@ -256,6 +326,88 @@ func (q *Queries) ListMembers(ctx context.Context, unitID uuid.UUID) ([]ListMemb
 	return items, nil
 }
 
+const listMembersFiltered = `-- name: ListMembersFiltered :many
+WITH RECURSIVE scope AS (
+    SELECT units.id FROM units WHERE units.id = $1
+    UNION
+    SELECT child.id
+    FROM units child
+    JOIN scope ON child.parent_id = scope.id OR child.org_id = scope.id
+    WHERE $2::boolean
+)
+SELECT u.id AS member_id,
+       u.name,
+       u.username,
+       u.avatar_url,
+       u.emails,
+       (SELECT own.valid_until FROM unit_members own
+        WHERE own.unit_id = $1 AND own.member_id = u.id) AS valid_until
+FROM users_with_emails u
+WHERE u.id IN (SELECT m.member_id FROM unit_members m JOIN scope ON scope.id = m.unit_id)
+  AND ($3::text = '' OR u.name ILIKE '%' || $3::text || '%'
+    OR u.username ILIKE '%' || $3::text || '%'
+    OR EXISTS (
+        SELECT 1 FROM user_emails e
+        WHERE e.user_id = u.id AND e.value ILIKE '%' || $3::text || '%'
+    ))
+  AND ($4::text = '' OR $4::text = ANY(u.role))
+ORDER BY u.name, u.id
+LIMIT $6::int
+OFFSET $5::int
+`
+
+type ListMembersFilteredParams struct {
+	UnitID     uuid.UUID
+	Recursive  bool
+	Search     string
+	Role       string
+	PageOffset int32
+	PageLimit  int32
+}
+
+type ListMembersFilteredRow struct {
+	MemberID   uuid.UUID
+	Name       pgtype.Text
+	Username   pgtype.Text
+	AvatarUrl  pgtype.Text
+	Emails     interface{}
+	ValidUntil pgtype.Timestamptz
+}
+
+func (q *Queries) ListMembersFiltered(ctx context.Context, arg ListMembersFilteredParams) ([]ListMembersFilteredRow, error) {
+	rows, err := q.db.Query(ctx, listMembersFiltered,
+		arg.UnitID,
+		arg.Recursive,
+		arg.Search,
+		arg.Role,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMembersFilteredRow
+	for rows.Next() {
+		var i ListMembersFilteredRow
+		if err := rows.Scan(
+			&i.MemberID,
+			&i.Name,
+			&i.Username,
+			&i.AvatarUrl,
+			&i.Emails,
+			&i.ValidUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listOrganizationsOfUser = `-- name: ListOrganizationsOfUser :many
 SELECT u.id, u.org_id, u.parent_id, u.type, u.name, u.description, u.metadata, u.created_at, u.updated_at, sh.slug
 FROM unit_members um
@@ -427,6 +579,26 @@ func (q *Queries) RemoveMember(ctx context.Context, arg RemoveMemberParams) erro
 	return err
 }
 
+const renewMember = `-- name: RenewMember :one
+UPDATE unit_members
+SET valid_until = $1
+WHERE unit_id = $2 AND member_id = $3
+RETURNING unit_id, member_id, valid_until
+`
+
+type RenewMemberParams struct {
+	ValidUntil pgtype.Timestamptz
+	UnitID     uuid.UUID
+	MemberID   uuid.UUID
+}
+
+func (q *Queries) RenewMember(ctx context.Context, arg RenewMemberParams) (UnitMember, error) {
+	row := q.db.QueryRow(ctx, renewMember, arg.ValidUntil, arg.UnitID, arg.MemberID)
+	var i UnitMember
+	err := row.Scan(&i.UnitID, &i.MemberID, &i.ValidUntil)
+	return i, err
+}
+
 const update = `-- name: Update :one
 UPDATE units
 SET name = $2,
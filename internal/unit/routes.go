@@ -0,0 +1,41 @@
+package unit
+
+import (
+	"NYCU-SDC/core-system-backend/internal/conditional"
+	"NYCU-SDC/core-system-backend/internal/route"
+	"time"
+)
+
+// Routes declares the organizations, their units and their members
+func Routes(r route.Router, h *Handler) {
+	r.Handle("POST /orgs", route.Authenticated, route.PermissionNone, h.CreateOrg)
+	r.Handle("POST /orgs/{slug}/units", route.TenantAuthenticated, route.PermissionNone, h.CreateUnit)
+	r.Handle("GET /orgs/{slug}", route.TenantPublic, route.PermissionNone, h.GetOrgByID)
+	r.Handle("GET /orgs", route.Public, route.PermissionNone, h.GetAllOrganizations)
+	r.Handle("GET /orgs/me", route.Authenticated, route.PermissionSelf, h.ListOrganizationsOfCurrentUser)
+	r.Handle("GET /orgs/{slug}/units/{id}", route.TenantPublic, route.PermissionNone, h.GetUnitByID)
+	r.Handle("POST /orgs/relations", route.Authenticated, route.PermissionNone, h.AddParentChild).Deprecated(route.Deprecation{
+		Since:  time.Date(2026, time.October, 14, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC),
+	})
+	r.Handle("PUT /orgs/{slug}", route.TenantAuthenticated, route.PermissionNone, h.UpdateOrg)
+	r.Handle("PUT /orgs/{slug}/units/{id}", route.TenantAuthenticated, route.PermissionNone, h.UpdateUnit)
+	r.Handle("DELETE /orgs/{slug}", route.TenantAuthenticated, route.PermissionNone, h.DeleteOrg)
+	r.Handle("DELETE /orgs/{slug}/units/{id}", route.TenantAuthenticated, route.PermissionNone, h.DeleteUnit)
+	r.Handle("POST /orgs/{slug}/members", route.TenantAuthenticated, route.PermissionNone, h.AddOrgMember)
+	r.Handle("GET /orgs/{slug}/members", route.TenantPublic, route.PermissionNone, h.ListOrgMembers)
+	r.Handle("DELETE /orgs/{slug}/members/{member_id}", route.TenantAuthenticated, route.PermissionNone, h.RemoveOrgMember)
+	r.Handle("POST /orgs/{slug}/members/{member_id}/renew", route.TenantAuthenticated, route.PermissionNone, h.RenewOrgMember)
+	r.Handle("POST /orgs/{slug}/units/{id}/members", route.TenantAuthenticated, route.PermissionNone, h.AddUnitMember)
+	r.Handle("GET /orgs/{slug}/units/{id}/members", route.TenantPublic, route.PermissionNone, h.ListUnitMembers)
+	r.Handle("DELETE /orgs/{slug}/units/{id}/members/{member_id}", route.TenantAuthenticated, route.PermissionNone, h.RemoveUnitMember)
+	r.Handle("POST /orgs/{slug}/units/{id}/members/{member_id}/renew", route.TenantAuthenticated, route.PermissionNone, h.RenewUnitMember)
+
+	r.Handle("GET /forms/me", route.Authenticated, route.PermissionSelf, h.ListFormsOfCurrentUser)
+
+	// List sub-units
+	r.Handle("GET /orgs/{slug}/units", route.TenantPublic, route.PermissionNone, conditional.Middleware(h.ListOrgSubUnits))
+	r.Handle("GET /orgs/{slug}/units/{id}/subunits", route.TenantPublic, route.PermissionNone, conditional.Middleware(h.ListUnitSubUnits))
+	r.Handle("GET /orgs/{slug}/unit-ids", route.TenantPublic, route.PermissionNone, conditional.Middleware(h.ListOrgSubUnitIDs))
+	r.Handle("GET /orgs/{slug}/units/{id}/subunit-ids", route.TenantPublic, route.PermissionNone, conditional.Middleware(h.ListUnitSubUnitIDs))
+}
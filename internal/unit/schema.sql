@@ -19,5 +19,8 @@ CREATE INDEX idx_units_parent_id ON units(parent_id);
 CREATE TABLE IF NOT EXISTS unit_members (
     unit_id UUID REFERENCES units(id) ON DELETE CASCADE,
     member_id UUID,
+    valid_until TIMESTAMPTZ DEFAULT NULL,
     PRIMARY KEY (unit_id, member_id)
 );
+
+CREATE INDEX idx_unit_members_valid_until ON unit_members(valid_until) WHERE valid_until IS NOT NULL;
@@ -29,7 +29,11 @@ type Querier interface {
 
 	AddMember(ctx context.Context, arg AddMemberParams) (AddMemberRow, error)
 	ListMembers(ctx context.Context, unitID uuid.UUID) ([]ListMembersRow, error)
+	ListMembersFiltered(ctx context.Context, arg ListMembersFilteredParams) ([]ListMembersFilteredRow, error)
+	CountMembersFiltered(ctx context.Context, arg CountMembersFilteredParams) (int64, error)
 	ListUnitsMembers(ctx context.Context, unitIDs []uuid.UUID) ([]ListUnitsMembersRow, error)
+	RenewMember(ctx context.Context, arg RenewMemberParams) (UnitMember, error)
+	ExpireMembers(ctx context.Context) ([]UnitMember, error)
 	RemoveMember(ctx context.Context, arg RemoveMemberParams) error
 }
 
@@ -321,7 +325,7 @@ func (s *Service) UpdateOrg(ctx context.Context, originalSlug string, slug strin
 
 	if slug != originalSlug {
 		matched, err := regexp.MatchString(slugPattern, slug)
-		if err != nil || !matched {
+		if err != nil || !matched || slug == idSlug {
 			span.RecordError(err)
 			return Unit{}, internal.ErrOrgSlugInvalid
 		}
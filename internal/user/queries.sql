@@ -35,4 +35,15 @@ VALUES ($1, $2)
 ON CONFLICT (user_id, value) DO NOTHING;
 
 -- name: GetEmailsByID :many
-SELECT user_emails.value as email FROM user_emails WHERE user_id = $1;
\ No newline at end of file
+SELECT user_emails.value as email FROM user_emails WHERE user_id = $1;
+
+-- name: GetIDByEmail :one
+SELECT user_id FROM user_emails
+WHERE lower(value) = lower(@email::text)
+ORDER BY created_at ASC
+LIMIT 1;
+
+-- name: UpdateAvatarURL :exec
+UPDATE users
+SET avatar_url = $2, updated_at = now()
+WHERE id = $1;
\ No newline at end of file
@@ -180,6 +180,20 @@ func (q *Queries) GetIDByAuth(ctx context.Context, arg GetIDByAuthParams) (uuid.
 	return user_id, err
 }
 
+const getIDByEmail = `-- name: GetIDByEmail :one
+SELECT user_id FROM user_emails
+WHERE lower(value) = lower($1::text)
+ORDER BY created_at ASC
+LIMIT 1
+`
+
+func (q *Queries) GetIDByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	row := q.db.QueryRow(ctx, getIDByEmail, email)
+	var user_id uuid.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
 const update = `-- name: Update :one
 UPDATE users
 SET name = $2, username = $3, avatar_url = $4, is_onboarded = $5,
@@ -217,3 +231,19 @@ func (q *Queries) Update(ctx context.Context, arg UpdateParams) (User, error) {
 	)
 	return i, err
 }
+
+const updateAvatarURL = `-- name: UpdateAvatarURL :exec
+UPDATE users
+SET avatar_url = $2, updated_at = now()
+WHERE id = $1
+`
+
+type UpdateAvatarURLParams struct {
+	ID        uuid.UUID
+	AvatarUrl pgtype.Text
+}
+
+func (q *Queries) UpdateAvatarURL(ctx context.Context, arg UpdateAvatarURLParams) error {
+	_, err := q.db.Exec(ctx, updateAvatarURL, arg.ID, arg.AvatarUrl)
+	return err
+}
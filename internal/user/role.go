@@ -0,0 +1,11 @@
+package user
+
+import "slices"
+
+// RoleAdmin is held by the operators of the deployment
+const RoleAdmin = "admin"
+
+// IsAdmin reports whether the user operates the deployment
+func IsAdmin(u *User) bool {
+	return slices.Contains(u.Role, RoleAdmin)
+}
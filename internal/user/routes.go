@@ -0,0 +1,11 @@
+package user
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the endpoints of the current user
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /users/me", route.Authenticated, route.PermissionSelf, h.GetMe)
+	r.Handle("PUT /users/onboarding", route.Authenticated, route.PermissionSelf, h.Onboarding)
+}
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
 	logutil "github.com/NYCU-SDC/summer/pkg/log"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
@@ -23,7 +25,9 @@ type Querier interface {
 	Create(ctx context.Context, arg CreateParams) (User, error)
 	CreateAuth(ctx context.Context, arg CreateAuthParams) (Auth, error)
 	Update(ctx context.Context, arg UpdateParams) (User, error)
+	UpdateAvatarURL(ctx context.Context, arg UpdateAvatarURLParams) error
 	GetEmailsByID(ctx context.Context, userID uuid.UUID) ([]string, error)
+	GetIDByEmail(ctx context.Context, email string) (uuid.UUID, error)
 	CreateEmail(ctx context.Context, arg CreateEmailParams) error
 }
 
@@ -77,6 +81,11 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (UsersWithEmail, er
 	return user, nil
 }
 
+// AvatarPath is the route serving avatars uploaded by the user
+func AvatarPath(id uuid.UUID) string {
+	return "/api/users/" + id.String() + "/avatar"
+}
+
 func resolveAvatarUrl(name, avatarUrl string) string {
 	if avatarUrl == "" {
 		return "https://ui-avatars.com/api/?name=" + url.QueryEscape(name)
@@ -110,7 +119,19 @@ func (s *Service) FindOrCreate(ctx context.Context, name, username, avatarUrl st
 			return uuid.UUID{}, err
 		}
 
-		avatarUrl = resolveAvatarUrl(name, avatarUrl)
+		// Keep an uploaded avatar instead of replacing it with the one from the OAuth provider
+		existingUser, err := s.queries.GetByID(traceCtx, existingUserID)
+		if err != nil {
+			err = databaseutil.WrapDBError(err, logger, "get existing user")
+			span.RecordError(err)
+			return uuid.UUID{}, err
+		}
+		if strings.Contains(existingUser.AvatarUrl.String, AvatarPath(existingUserID)) {
+			avatarUrl = existingUser.AvatarUrl.String
+		} else {
+			avatarUrl = resolveAvatarUrl(name, avatarUrl)
+		}
+
 		_, err = s.queries.Update(traceCtx, UpdateParams{
 			ID:        existingUserID,
 			Name:      pgtype.Text{String: name, Valid: name != ""},
@@ -208,6 +229,26 @@ func (s *Service) GetEmailsByID(ctx context.Context, userID uuid.UUID) ([]string
 	return emails, nil
 }
 
+// GetIDByEmail finds the user with the email address, ignoring its case. An address
+// shared by several users belongs to the first one to add it.
+func (s *Service) GetIDByEmail(ctx context.Context, email string) (uuid.UUID, error) {
+	traceCtx, span := s.tracer.Start(ctx, "GetIDByEmail")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	id, err := s.queries.GetIDByEmail(traceCtx, email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.RecordError(internal.ErrUserNotFound)
+			return uuid.Nil, internal.ErrUserNotFound
+		}
+		err = databaseutil.WrapDBError(err, logger, "get user id by email")
+		span.RecordError(err)
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
 func (s *Service) Onboarding(ctx context.Context, id uuid.UUID, name, username string) (User, error) {
 	traceCtx, span := s.tracer.Start(ctx, "Onboarding")
 	defer span.End()
@@ -248,3 +289,21 @@ func (s *Service) Onboarding(ctx context.Context, id uuid.UUID, name, username s
 	}
 	return user, nil
 }
+
+func (s *Service) UpdateAvatarURL(ctx context.Context, id uuid.UUID, avatarURL string) error {
+	traceCtx, span := s.tracer.Start(ctx, "UpdateAvatarURL")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.queries.UpdateAvatarURL(traceCtx, UpdateAvatarURLParams{
+		ID:        id,
+		AvatarUrl: pgtype.Text{String: avatarURL, Valid: avatarURL != ""},
+	})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "users", "id", id.String(), logger, "update avatar url")
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+// Package web serves the built admin frontend from the binary, for deployments
+// running without a separate web server.
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// dist holds the frontend build, copied in before building the binary with
+// make embed-frontend; it is empty otherwise
+//
+//go:embed all:dist
+var dist embed.FS
+
+const indexFile = "index.html"
+
+const (
+	// immutableCacheControl is for the files under assets/, named after their content by the build
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	// revalidateCacheControl is for the rest, index.html first, kept by the browser but
+	// checked against their ETag on every use
+	revalidateCacheControl = "no-cache"
+)
+
+// Dist returns the embedded frontend build
+func Dist() fs.FS {
+	files, err := fs.Sub(dist, "dist")
+	if err != nil {
+		// dist is a valid path, checked at compile time by go:embed
+		panic(err)
+	}
+	return files
+}
+
+type file struct {
+	content []byte
+	etag    string
+}
+
+// Handler serves the files of the single page app, and index.html for the paths no
+// file matches so the client router handles them
+type Handler struct {
+	logger *zap.Logger
+	files  map[string]file
+}
+
+// NewHandler reads every file of files into memory with its ETag; files must hold an
+// index.html at its root
+func NewHandler(logger *zap.Logger, files fs.FS) (*Handler, error) {
+	h := &Handler{
+		logger: logger,
+		files:  make(map[string]file),
+	}
+
+	err := fs.WalkDir(files, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		h.files[name] = file{
+			content: content,
+			etag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frontend files: %w", err)
+	}
+	if _, ok := h.files[indexFile]; !ok {
+		return nil, errors.New("frontend has no index.html, build it into internal/web/dist with make embed-frontend")
+	}
+
+	logger.Info("Serving frontend", zap.Int("files", len(h.files)))
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	f, ok := h.files[name]
+	if !ok {
+		// A missing script or image is an error, a missing page a route of the app
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name = indexFile
+		f = h.files[indexFile]
+	}
+
+	if strings.HasPrefix(name, "assets/") {
+		w.Header().Set("Cache-Control", immutableCacheControl)
+	} else {
+		w.Header().Set("Cache-Control", revalidateCacheControl)
+	}
+	w.Header().Set("ETag", f.etag)
+
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(f.content))
+}
@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]OrgWebhookKey, error)
+	Rotate(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, gracePeriod time.Duration) (OrgWebhookKey, string, error)
+	Revoke(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID) error
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+// RotateRequest sets how many hours the replaced keys keep signing, 24 when omitted
+type RotateRequest struct {
+	GraceHours *int `json:"graceHours" validate:"omitempty,min=0,max=168"`
+}
+
+// KeyResponse describes a signing key; expiresAt is set once a rotation replaced it
+type KeyResponse struct {
+	ID        string     `json:"id"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// RotateResponse holds the secret of the new key, which cannot be read again
+type RotateResponse struct {
+	KeyResponse
+	Secret string `json:"secret"`
+}
+
+func ToKeyResponse(key OrgWebhookKey) KeyResponse {
+	response := KeyResponse{
+		ID:        key.ID.String(),
+		CreatedAt: key.CreatedAt.Time,
+	}
+	if key.ExpiresAt.Valid {
+		expiresAt := key.ExpiresAt.Time
+		response.ExpiresAt = &expiresAt
+	}
+	return response
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(logger *zap.Logger, validator *validator.Validate, problemWriter *problem.HttpWriter, store Store, tenantStore tenantStore) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("webhook/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	currentUser, orgID, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	keys, err := h.store.List(traceCtx, orgID, currentUser.ID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]KeyResponse, len(keys))
+	for i, key := range keys {
+		response[i] = ToKeyResponse(key)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) RotateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RotateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	var req RotateRequest
+	if err := internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req); err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, orgID, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	gracePeriod := DefaultGracePeriod
+	if req.GraceHours != nil {
+		gracePeriod = time.Duration(*req.GraceHours) * time.Hour
+	}
+
+	key, secret, err := h.store.Rotate(traceCtx, orgID, currentUser.ID, gracePeriod)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, RotateResponse{KeyResponse: ToKeyResponse(key), Secret: secret})
+}
+
+func (h *Handler) RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "RevokeHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	id, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	currentUser, orgID, err := h.caller(traceCtx)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Revoke(traceCtx, orgID, currentUser.ID, id)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// caller returns the current user and the organization of the path
+func (h *Handler) caller(ctx context.Context) (*user.User, uuid.UUID, error) {
+	currentUser, ok := user.GetFromContext(ctx)
+	if !ok {
+		return nil, uuid.Nil, internal.ErrNoUserInContext
+	}
+
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	return currentUser, orgID, nil
+}
@@ -0,0 +1,33 @@
+-- name: IsOrgAdmin :one
+SELECT EXISTS(SELECT 1 FROM tenants WHERE id = @org_id AND owner_id = @user_id);
+
+-- name: Create :one
+INSERT INTO org_webhook_keys (org_id, secret, created_by)
+VALUES (@org_id, @secret, @created_by)
+RETURNING *;
+
+-- name: ListActiveByOrgID :many
+SELECT * FROM org_webhook_keys
+WHERE org_id = @org_id AND (expires_at IS NULL OR expires_at > now())
+ORDER BY created_at DESC;
+
+-- name: ExpireActive :exec
+UPDATE org_webhook_keys
+SET expires_at = @expires_at
+WHERE org_id = @org_id AND (expires_at IS NULL OR expires_at > @expires_at);
+
+-- name: Revoke :execrows
+DELETE FROM org_webhook_keys
+WHERE id = @id AND org_id = @org_id;
+
+-- name: DeleteExpired :execrows
+DELETE FROM org_webhook_keys
+WHERE expires_at <= now();
+
+-- name: ListSecrets :many
+SELECT id, secret FROM org_webhook_keys;
+
+-- name: UpdateSecret :exec
+UPDATE org_webhook_keys
+SET secret = @secret
+WHERE id = @id;
\ No newline at end of file
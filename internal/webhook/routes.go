@@ -0,0 +1,12 @@
+package webhook
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the keys signing the webhook deliveries of an organization
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/webhook-keys", route.TenantAuthenticated, route.PermissionOrgAdmin, h.ListHandler)
+	r.Handle("POST /orgs/{slug}/webhook-keys/rotate", route.TenantAuthenticated, route.PermissionOrgAdmin, h.RotateHandler)
+	r.Handle("DELETE /orgs/{slug}/webhook-keys/{id}", route.TenantAuthenticated, route.PermissionOrgAdmin, h.RevokeHandler)
+}
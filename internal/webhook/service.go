@@ -0,0 +1,322 @@
+package webhook
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// SignatureHeader carries the signatures of a delivery, as
+	// "t=<unix time>,v1=<key id>:<hex HMAC-SHA256 of "<unix time>.<body>">", with one v1
+	// pair per key of the organization still accepted, the newest first
+	SignatureHeader = "X-Webhook-Signature"
+
+	// DefaultGracePeriod is how long the keys replaced by a rotation keep signing
+	DefaultGracePeriod = 24 * time.Hour
+	// MaxGracePeriod bounds the grace period, a week
+	MaxGracePeriod = 7 * 24 * time.Hour
+
+	// SignatureTolerance is how far the timestamp of an inbound signature may be from now
+	SignatureTolerance = 5 * time.Minute
+
+	secretLength = 32
+)
+
+type Querier interface {
+	IsOrgAdmin(ctx context.Context, arg IsOrgAdminParams) (bool, error)
+	Create(ctx context.Context, arg CreateParams) (OrgWebhookKey, error)
+	ListActiveByOrgID(ctx context.Context, orgID uuid.UUID) ([]OrgWebhookKey, error)
+	ExpireActive(ctx context.Context, arg ExpireActiveParams) error
+	Revoke(ctx context.Context, arg RevokeParams) (int64, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+	ListSecrets(ctx context.Context) ([]ListSecretsRow, error)
+	UpdateSecret(ctx context.Context, arg UpdateSecretParams) error
+}
+
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// SecretBox encrypts the signing secrets at rest
+type SecretBox interface {
+	Seal(value string) (string, error)
+	Open(value string) (string, error)
+	Current(value string) bool
+}
+
+type Service struct {
+	logger    *zap.Logger
+	db        DB
+	queries   Querier
+	tracer    trace.Tracer
+	secretBox SecretBox
+}
+
+func NewService(logger *zap.Logger, db DB, secretBox SecretBox) *Service {
+	return &Service{
+		logger:    logger,
+		db:        db,
+		queries:   New(db),
+		tracer:    otel.Tracer("webhook/service"),
+		secretBox: secretBox,
+	}
+}
+
+// List returns the keys of the organization still signing deliveries, the newest first.
+// Only org admins may read them, and never their secrets.
+func (s *Service) List(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) ([]OrgWebhookKey, error) {
+	traceCtx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkOrgAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	keys, err := s.queries.ListActiveByOrgID(traceCtx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_webhook_keys", "org_id", orgID.String(), logger, "list webhook keys")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Rotate creates a new signing key and returns it with its secret, which is shown only
+// this once. The keys it replaces keep signing deliveries alongside it for the grace
+// period, so receivers can switch to the new secret without missing a delivery.
+func (s *Service) Rotate(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, gracePeriod time.Duration) (OrgWebhookKey, string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Rotate")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkOrgAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return OrgWebhookKey{}, "", err
+	}
+
+	if gracePeriod < 0 || gracePeriod > MaxGracePeriod {
+		err = fmt.Errorf("%w: grace period must be between 0 and %s", internal.ErrInvalidWebhookGracePeriod, MaxGracePeriod)
+		span.RecordError(err)
+		return OrgWebhookKey{}, "", err
+	}
+
+	secret, err := randomSecret()
+	if err != nil {
+		span.RecordError(err)
+		return OrgWebhookKey{}, "", err
+	}
+	sealed, err := s.secretBox.Seal(secret)
+	if err != nil {
+		err = fmt.Errorf("failed to seal webhook secret: %w", err)
+		span.RecordError(err)
+		return OrgWebhookKey{}, "", err
+	}
+
+	var key OrgWebhookKey
+	err = s.inTx(traceCtx, logger, func(queries Querier) error {
+		err := queries.ExpireActive(traceCtx, ExpireActiveParams{
+			OrgID:     orgID,
+			ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(gracePeriod), Valid: true},
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "org_webhook_keys", "org_id", orgID.String(), logger, "expire webhook keys")
+		}
+
+		key, err = queries.Create(traceCtx, CreateParams{
+			OrgID:     orgID,
+			Secret:    sealed,
+			CreatedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			return databaseutil.WrapDBErrorWithKeyValue(err, "org_webhook_keys", "org_id", orgID.String(), logger, "create webhook key")
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return OrgWebhookKey{}, "", err
+	}
+
+	deleted, err := s.queries.DeleteExpired(traceCtx)
+	if err != nil {
+		logger.Warn("Failed to delete expired webhook keys", zap.Error(err))
+	}
+
+	logger.Info("Rotated webhook signing key",
+		zap.String("org_id", orgID.String()),
+		zap.String("key_id", key.ID.String()),
+		zap.Duration("grace_period", gracePeriod),
+		zap.Int64("deleted_expired", deleted),
+	)
+	return key, secret, nil
+}
+
+// Revoke stops a key from signing at once, ending its grace period early after a leak
+func (s *Service) Revoke(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, id uuid.UUID) error {
+	traceCtx, span := s.tracer.Start(ctx, "Revoke")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	err := s.checkOrgAdmin(traceCtx, logger, orgID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Revoke(traceCtx, RevokeParams{ID: id, OrgID: orgID})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_webhook_keys", "id", id.String(), logger, "revoke webhook key")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = fmt.Errorf("%w: %s", internal.ErrWebhookKeyNotFound, id)
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Revoked webhook signing key", zap.String("org_id", orgID.String()), zap.String("key_id", id.String()))
+	return nil
+}
+
+// Sign returns the signature header value of a delivery of the organization, or an
+// empty one when the organization has no signing key yet
+func (s *Service) Sign(ctx context.Context, orgID uuid.UUID, timestamp time.Time, body []byte) (string, error) {
+	traceCtx, span := s.tracer.Start(ctx, "Sign")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	keys, err := s.queries.ListActiveByOrgID(traceCtx, orgID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "org_webhook_keys", "org_id", orgID.String(), logger, "list webhook keys")
+		span.RecordError(err)
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", nil
+	}
+
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	parts := make([]string, 0, len(keys)+1)
+	parts = append(parts, "t="+unix)
+	for _, key := range keys {
+		secret, err := s.secretBox.Open(key.Secret)
+		if err != nil {
+			err = fmt.Errorf("failed to open webhook secret %s: %w", key.ID, err)
+			span.RecordError(err)
+			return "", err
+		}
+
+		parts = append(parts, "v1="+key.ID.String()+":"+hex.EncodeToString(sign(secret, unix, body)))
+	}
+
+	return strings.Join(parts, ","), nil
+}
+
+// RotateSecrets seals the signing secrets again with the current key of the keyring
+func (s *Service) RotateSecrets(ctx context.Context) (int, error) {
+	traceCtx, span := s.tracer.Start(ctx, "RotateSecrets")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, s.logger)
+
+	rows, err := s.queries.ListSecrets(traceCtx)
+	if err != nil {
+		err = databaseutil.WrapDBError(err, logger, "list webhook secrets")
+		span.RecordError(err)
+		return 0, err
+	}
+
+	count := 0
+	for _, row := range rows {
+		if s.secretBox.Current(row.Secret) {
+			continue
+		}
+
+		secret, err := s.secretBox.Open(row.Secret)
+		if err != nil {
+			err = fmt.Errorf("failed to open webhook secret %s: %w", row.ID, err)
+			span.RecordError(err)
+			return count, err
+		}
+		sealed, err := s.secretBox.Seal(secret)
+		if err != nil {
+			span.RecordError(err)
+			return count, err
+		}
+
+		err = s.queries.UpdateSecret(traceCtx, UpdateSecretParams{Secret: sealed, ID: row.ID})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "org_webhook_keys", "id", row.ID.String(), logger, "seal webhook secret")
+			span.RecordError(err)
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+func (s *Service) checkOrgAdmin(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, userID uuid.UUID) error {
+	isAdmin, err := s.queries.IsOrgAdmin(ctx, IsOrgAdminParams{OrgID: orgID, UserID: pgtype.UUID{Bytes: userID, Valid: true}})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "tenants", "id", orgID.String(), logger, "check org admin")
+	}
+	if !isAdmin {
+		return internal.ErrNotOrgAdmin
+	}
+	return nil
+}
+
+func randomSecret() (string, error) {
+	b := make([]byte, secretLength)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
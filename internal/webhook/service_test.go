@@ -0,0 +1,309 @@
+package webhook_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/secrets"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// keysDB keeps the signing keys of the tests in memory, answering the queries of the
+// service with now as the time of the database
+type keysDB struct {
+	now  time.Time
+	keys []webhook.OrgWebhookKey
+}
+
+func (db *keysDB) active(key webhook.OrgWebhookKey) bool {
+	return !key.ExpiresAt.Valid || key.ExpiresAt.Time.After(db.now)
+}
+
+func (db *keysDB) Begin(context.Context) (pgx.Tx, error) {
+	return keysTx{db: db}, nil
+}
+
+func (db *keysDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	switch {
+	case strings.Contains(sql, "name: ExpireActive"):
+		expiresAt, orgID := args[0].(pgtype.Timestamptz), args[1].(uuid.UUID)
+		for i, key := range db.keys {
+			if key.OrgID == orgID && (!key.ExpiresAt.Valid || key.ExpiresAt.Time.After(expiresAt.Time)) {
+				db.keys[i].ExpiresAt = expiresAt
+			}
+		}
+		return pgconn.NewCommandTag("UPDATE"), nil
+	case strings.Contains(sql, "name: DeleteExpired"):
+		kept := db.keys[:0]
+		for _, key := range db.keys {
+			if db.active(key) {
+				kept = append(kept, key)
+			}
+		}
+		deleted := len(db.keys) - len(kept)
+		db.keys = kept
+		return pgconn.NewCommandTag("DELETE " + strconv.Itoa(deleted)), nil
+	case strings.Contains(sql, "name: Revoke"):
+		id, orgID := args[0].(uuid.UUID), args[1].(uuid.UUID)
+		for i, key := range db.keys {
+			if key.ID == id && key.OrgID == orgID {
+				db.keys = append(db.keys[:i], db.keys[i+1:]...)
+				return pgconn.NewCommandTag("DELETE 1"), nil
+			}
+		}
+		return pgconn.NewCommandTag("DELETE 0"), nil
+	}
+	return pgconn.CommandTag{}, errors.New("unexpected exec")
+}
+
+func (db *keysDB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if !strings.Contains(sql, "name: ListActiveByOrgID") {
+		return nil, errors.New("unexpected query")
+	}
+
+	var keys []webhook.OrgWebhookKey
+	for _, key := range db.keys {
+		if key.OrgID == args[0].(uuid.UUID) && db.active(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].CreatedAt.Time.After(keys[j].CreatedAt.Time) })
+	return &keyRows{keys: keys, next: -1}, nil
+}
+
+func (db *keysDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	switch {
+	case strings.Contains(sql, "name: IsOrgAdmin"):
+		return adminRow{}
+	case strings.Contains(sql, "name: Create"):
+		key := webhook.OrgWebhookKey{
+			ID:        uuid.New(),
+			OrgID:     args[0].(uuid.UUID),
+			Secret:    args[1].(string),
+			CreatedBy: args[2].(pgtype.UUID),
+			// Keys created in the same test stay ordered by creation
+			CreatedAt: pgtype.Timestamptz{Time: db.now.Add(time.Duration(len(db.keys)) * time.Microsecond), Valid: true},
+		}
+		db.keys = append(db.keys, key)
+		return &keyRows{keys: []webhook.OrgWebhookKey{key}}
+	}
+	return &keyRows{err: errors.New("unexpected query")}
+}
+
+// keysTx runs the queries of a transaction straight on the keys, the tests never roll
+// back a transaction that wrote
+type keysTx struct {
+	pgx.Tx
+	db *keysDB
+}
+
+func (tx keysTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.db.Exec(ctx, sql, args...)
+}
+
+func (tx keysTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.db.Query(ctx, sql, args...)
+}
+
+func (tx keysTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.db.QueryRow(ctx, sql, args...)
+}
+
+func (tx keysTx) Commit(context.Context) error   { return nil }
+func (tx keysTx) Rollback(context.Context) error { return nil }
+
+type adminRow struct{}
+
+func (adminRow) Scan(dest ...any) error {
+	*dest[0].(*bool) = true
+	return nil
+}
+
+// keyRows scans keys as rows, from Next for a query or at once for a single row
+type keyRows struct {
+	pgx.Rows
+	keys []webhook.OrgWebhookKey
+	next int
+	err  error
+}
+
+func (r *keyRows) Next() bool {
+	r.next++
+	return r.next < len(r.keys)
+}
+
+func (r *keyRows) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	key := r.keys[max(r.next, 0)]
+	*dest[0].(*uuid.UUID) = key.ID
+	*dest[1].(*uuid.UUID) = key.OrgID
+	*dest[2].(*string) = key.Secret
+	*dest[3].(*pgtype.UUID) = key.CreatedBy
+	*dest[4].(*pgtype.Timestamptz) = key.CreatedAt
+	*dest[5].(*pgtype.Timestamptz) = key.ExpiresAt
+	return nil
+}
+
+func (r *keyRows) Close()     {}
+func (r *keyRows) Err() error { return nil }
+
+// signingKeys lists the key IDs of the v1 signatures of a header
+func signingKeys(header string) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, part := range strings.Split(header, ",") {
+		value, ok := strings.CutPrefix(part, "v1=")
+		if !ok {
+			continue
+		}
+		id, _, _ := strings.Cut(value, ":")
+		ids = append(ids, uuid.MustParse(id))
+	}
+	return ids
+}
+
+func TestService_RotateGraceWindow(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name             string
+		gracePeriod      time.Duration
+		elapsed          time.Duration
+		expectOldSigning bool
+	}
+
+	testCases := []testCase{
+		{name: "Within the grace period", gracePeriod: webhook.DefaultGracePeriod, elapsed: webhook.DefaultGracePeriod - time.Minute, expectOldSigning: true},
+		{name: "After the grace period", gracePeriod: webhook.DefaultGracePeriod, elapsed: webhook.DefaultGracePeriod + time.Minute},
+		{name: "Longest grace period", gracePeriod: webhook.MaxGracePeriod, elapsed: webhook.MaxGracePeriod - time.Minute, expectOldSigning: true},
+		{name: "Without a grace period", gracePeriod: 0, elapsed: time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			db := &keysDB{now: time.Now()}
+			service := webhook.NewService(zap.NewNop(), db, secrets.NewDevKeyring("test-secret"))
+			orgID, userID := uuid.New(), uuid.New()
+			body := []byte("id,answer\n1,yes\n")
+
+			oldKey, oldSecret, err := service.Rotate(context.Background(), orgID, userID, tc.gracePeriod)
+			require.NoError(t, err)
+			newKey, newSecret, err := service.Rotate(context.Background(), orgID, userID, tc.gracePeriod)
+			require.NoError(t, err)
+
+			db.now = db.now.Add(tc.elapsed)
+			header, err := service.Sign(context.Background(), orgID, db.now, body)
+			require.NoError(t, err)
+			if tc.expectOldSigning {
+				require.Equal(t, []uuid.UUID{newKey.ID, oldKey.ID}, signingKeys(header))
+			} else {
+				require.Equal(t, []uuid.UUID{newKey.ID}, signingKeys(header))
+			}
+
+			// Receivers still on the old secret keep verifying until the grace period ends
+			signature, err := webhook.ParseSignature(header, db.now)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectOldSigning, signature.Matches(oldKey.ID.String(), oldSecret, body))
+			require.True(t, signature.Matches(newKey.ID.String(), newSecret, body))
+		})
+	}
+}
+
+// TestService_RotateKeepsEarlierExpiry rotates twice in a row, which must not extend the
+// grace period of the key replaced first
+func TestService_RotateKeepsEarlierExpiry(t *testing.T) {
+	t.Parallel()
+
+	db := &keysDB{now: time.Now()}
+	service := webhook.NewService(zap.NewNop(), db, secrets.NewDevKeyring("test-secret"))
+	orgID, userID := uuid.New(), uuid.New()
+
+	first, _, err := service.Rotate(context.Background(), orgID, userID, 0)
+	require.NoError(t, err)
+	second, _, err := service.Rotate(context.Background(), orgID, userID, time.Hour)
+	require.NoError(t, err)
+	third, _, err := service.Rotate(context.Background(), orgID, userID, webhook.MaxGracePeriod)
+	require.NoError(t, err)
+
+	db.now = db.now.Add(30 * time.Minute)
+	header, err := service.Sign(context.Background(), orgID, db.now, nil)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{third.ID, second.ID, first.ID}, signingKeys(header))
+
+	db.now = db.now.Add(time.Hour)
+	header, err = service.Sign(context.Background(), orgID, db.now, nil)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{third.ID, second.ID}, signingKeys(header))
+}
+
+func TestService_Rotate(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		name        string
+		gracePeriod time.Duration
+		expectedErr error
+	}
+
+	testCases := []testCase{
+		{name: "Negative grace period", gracePeriod: -time.Second, expectedErr: internal.ErrInvalidWebhookGracePeriod},
+		{name: "Grace period over a week", gracePeriod: webhook.MaxGracePeriod + time.Second, expectedErr: internal.ErrInvalidWebhookGracePeriod},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			service := webhook.NewService(zap.NewNop(), &keysDB{now: time.Now()}, secrets.NewDevKeyring("test-secret"))
+			_, _, err := service.Rotate(context.Background(), uuid.New(), uuid.New(), tc.gracePeriod)
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestService_RevokeEndsGraceWindow(t *testing.T) {
+	t.Parallel()
+
+	db := &keysDB{now: time.Now()}
+	service := webhook.NewService(zap.NewNop(), db, secrets.NewDevKeyring("test-secret"))
+	orgID, userID := uuid.New(), uuid.New()
+
+	oldKey, oldSecret, err := service.Rotate(context.Background(), orgID, userID, 0)
+	require.NoError(t, err)
+	_, _, err = service.Rotate(context.Background(), orgID, userID, webhook.DefaultGracePeriod)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Revoke(context.Background(), orgID, userID, oldKey.ID))
+	header, err := service.Sign(context.Background(), orgID, db.now, nil)
+	require.NoError(t, err)
+	signature, err := webhook.ParseSignature(header, db.now)
+	require.NoError(t, err)
+	require.False(t, signature.Matches(oldKey.ID.String(), oldSecret, nil))
+
+	err = service.Revoke(context.Background(), orgID, userID, oldKey.ID)
+	require.ErrorIs(t, err, internal.ErrWebhookKeyNotFound)
+}
+
+func TestService_SignWithoutKeys(t *testing.T) {
+	t.Parallel()
+
+	service := webhook.NewService(zap.NewNop(), &keysDB{now: time.Now()}, secrets.NewDevKeyring("test-secret"))
+	header, err := service.Sign(context.Background(), uuid.New(), time.Now(), []byte("body"))
+	require.NoError(t, err)
+	require.Empty(t, header)
+}
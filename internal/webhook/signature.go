@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature is a parsed SignatureHeader value
+type Signature struct {
+	unix       string
+	signatures map[string][]byte
+}
+
+// ParseSignature reads a SignatureHeader value, refusing one without a signature or
+// with a timestamp further than SignatureTolerance from now, so a captured request
+// cannot be replayed later
+func ParseSignature(header string, now time.Time) (Signature, error) {
+	parsed := Signature{signatures: make(map[string][]byte)}
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "t":
+			parsed.unix = value
+		case "v1":
+			keyID, signature, ok := strings.Cut(value, ":")
+			decoded, err := hex.DecodeString(signature)
+			if ok && keyID != "" && err == nil && len(decoded) > 0 {
+				parsed.signatures[keyID] = decoded
+			}
+		}
+	}
+
+	timestamp, err := strconv.ParseInt(parsed.unix, 10, 64)
+	if err != nil || len(parsed.signatures) == 0 {
+		return Signature{}, fmt.Errorf("%w: missing timestamp or signature", internal.ErrWebhookSignatureInvalid)
+	}
+	age := now.Sub(time.Unix(timestamp, 0))
+	if age > SignatureTolerance || age < -SignatureTolerance {
+		return Signature{}, fmt.Errorf("%w: timestamp outside of %s", internal.ErrWebhookSignatureInvalid, SignatureTolerance)
+	}
+
+	return parsed, nil
+}
+
+// KeyIDs returns the IDs of the keys the header carries a signature of
+func (s Signature) KeyIDs() []string {
+	ids := make([]string, 0, len(s.signatures))
+	for id := range s.signatures {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Matches reports whether the header carries the signature of the body with the secret
+// of the key
+func (s Signature) Matches(keyID string, secret string, body []byte) bool {
+	signature, ok := s.signatures[keyID]
+	return ok && hmac.Equal(sign(secret, s.unix, body), signature)
+}
+
+// sign computes the HMAC-SHA256 of "<unix time>.<body>"
+func sign(secret string, unix string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix + "."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,104 @@
+package webhook_test
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// signature computes the v1 signature of the body with the secret, like the deliveries
+func signature(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func header(keyID string, secret string, timestamp time.Time, body []byte) string {
+	return "t=" + strconv.FormatInt(timestamp.Unix(), 10) + ",v1=" + keyID + ":" + signature(secret, timestamp, body)
+}
+
+func TestParseSignature(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_760_000_000, 0)
+	body := []byte(`{"source":"google-forms"}`)
+
+	type testCase struct {
+		name        string
+		header      string
+		expectedErr bool
+	}
+
+	testCases := []testCase{
+		{name: "Signed now", header: header("key", "secret", now, body)},
+		{name: "Signed at the edge of the tolerance", header: header("key", "secret", now.Add(-webhook.SignatureTolerance), body)},
+		{name: "Clock of the sender ahead within the tolerance", header: header("key", "secret", now.Add(webhook.SignatureTolerance), body)},
+		{name: "Signed before the tolerance", header: header("key", "secret", now.Add(-webhook.SignatureTolerance-time.Second), body), expectedErr: true},
+		{name: "Signed after the tolerance", header: header("key", "secret", now.Add(webhook.SignatureTolerance+time.Second), body), expectedErr: true},
+		{name: "Spaces between the parts", header: "t=" + strconv.FormatInt(now.Unix(), 10) + ", v1=key:" + signature("secret", now, body)},
+		{name: "Missing timestamp", header: "v1=key:" + signature("secret", now, body), expectedErr: true},
+		{name: "Timestamp not a number", header: "t=now,v1=key:" + signature("secret", now, body), expectedErr: true},
+		{name: "Missing signature", header: "t=" + strconv.FormatInt(now.Unix(), 10), expectedErr: true},
+		{name: "Signature not in hex", header: "t=" + strconv.FormatInt(now.Unix(), 10) + ",v1=key:zz", expectedErr: true},
+		{name: "Signature without a key", header: "t=" + strconv.FormatInt(now.Unix(), 10) + ",v1=" + signature("secret", now, body), expectedErr: true},
+		{name: "Empty header", header: "", expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := webhook.ParseSignature(tc.header, now)
+			if tc.expectedErr {
+				require.ErrorIs(t, err, internal.ErrWebhookSignatureInvalid)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSignature_Matches(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_760_000_000, 0)
+	body := []byte(`{"source":"google-forms"}`)
+	// Two keys sign during the grace period of a rotation
+	value := header("new", "new-secret", now, body) + ",v1=old:" + signature("old-secret", now, body)
+
+	parsed, err := webhook.ParseSignature(value, now)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"new", "old"}, parsed.KeyIDs())
+
+	type testCase struct {
+		name     string
+		keyID    string
+		secret   string
+		body     []byte
+		expected bool
+	}
+
+	testCases := []testCase{
+		{name: "Current key", keyID: "new", secret: "new-secret", body: body, expected: true},
+		{name: "Replaced key still signing", keyID: "old", secret: "old-secret", body: body, expected: true},
+		{name: "Wrong secret", keyID: "new", secret: "old-secret", body: body},
+		{name: "Key not in the header", keyID: "other", secret: "new-secret", body: body},
+		{name: "Tampered body", keyID: "new", secret: "new-secret", body: []byte(`{"source":"typeform"}`)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.expected, parsed.Matches(tc.keyID, tc.secret, tc.body))
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package wiki
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	handlerutil "github.com/NYCU-SDC/summer/pkg/handler"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/NYCU-SDC/summer/pkg/problem"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Store interface {
+	Create(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, input Input, userID uuid.UUID) (WikiPage, error)
+	List(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID) ([]ListByUnitRow, error)
+	Get(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, userID uuid.UUID) (WikiPage, error)
+	Update(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, input UpdateInput, userID uuid.UUID) (WikiPage, error)
+	Delete(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, userID uuid.UUID) error
+	ListRevisions(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, userID uuid.UUID) ([]ListRevisionsRow, error)
+	GetRevision(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, revision int32, userID uuid.UUID) (WikiPageRevision, error)
+}
+
+type tenantStore interface {
+	GetSlugStatus(ctx context.Context, slug string) (bool, uuid.UUID, error)
+}
+
+// Request is the content of a page; Body is markdown, rendered by the client
+type Request struct {
+	Title      string `json:"title" validate:"required,max=255"`
+	Body       string `json:"body" validate:"max=200000"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=unit organization"`
+	Summary    string `json:"summary" validate:"max=255"`
+}
+
+type CreateRequest struct {
+	Request
+	Slug string `json:"slug" validate:"required,max=100"`
+}
+
+// UpdateRequest carries the revision the edit was made on, as read from the page
+type UpdateRequest struct {
+	Request
+	Revision int32 `json:"revision" validate:"required,min=1"`
+}
+
+type Response struct {
+	ID         string    `json:"id"`
+	UnitID     string    `json:"unitId"`
+	Slug       string    `json:"slug"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	Visibility string    `json:"visibility"`
+	Revision   int32     `json:"revision"`
+	CreatedBy  *string   `json:"createdBy,omitempty"`
+	UpdatedBy  *string   `json:"updatedBy,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type SummaryResponse struct {
+	ID         string    `json:"id"`
+	Slug       string    `json:"slug"`
+	Title      string    `json:"title"`
+	Visibility string    `json:"visibility"`
+	Revision   int32     `json:"revision"`
+	UpdatedBy  *string   `json:"updatedBy,omitempty"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// RevisionResponse is an entry of the history of a page; Body is only set when a single
+// revision is asked for
+type RevisionResponse struct {
+	Revision  int32     `json:"revision"`
+	Title     string    `json:"title"`
+	Body      *string   `json:"body,omitempty"`
+	Summary   string    `json:"summary"`
+	EditedBy  *string   `json:"editedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func userIDString(id pgtype.UUID) *string {
+	if !id.Valid {
+		return nil
+	}
+	value := uuid.UUID(id.Bytes).String()
+	return &value
+}
+
+func ToResponse(page WikiPage) Response {
+	return Response{
+		ID:         page.ID.String(),
+		UnitID:     page.UnitID.String(),
+		Slug:       page.Slug,
+		Title:      page.Title,
+		Body:       page.Body,
+		Visibility: string(page.Visibility),
+		Revision:   page.Revision,
+		CreatedBy:  userIDString(page.CreatedBy),
+		UpdatedBy:  userIDString(page.UpdatedBy),
+		CreatedAt:  page.CreatedAt.Time,
+		UpdatedAt:  page.UpdatedAt.Time,
+	}
+}
+
+func ToSummaryResponse(page ListByUnitRow) SummaryResponse {
+	return SummaryResponse{
+		ID:         page.ID.String(),
+		Slug:       page.Slug,
+		Title:      page.Title,
+		Visibility: string(page.Visibility),
+		Revision:   page.Revision,
+		UpdatedBy:  userIDString(page.UpdatedBy),
+		UpdatedAt:  page.UpdatedAt.Time,
+	}
+}
+
+func ToRevisionSummaryResponse(revision ListRevisionsRow) RevisionResponse {
+	return RevisionResponse{
+		Revision:  revision.Revision,
+		Title:     revision.Title,
+		Summary:   revision.Summary,
+		EditedBy:  userIDString(revision.EditedBy),
+		CreatedAt: revision.CreatedAt.Time,
+	}
+}
+
+func ToRevisionResponse(revision WikiPageRevision) RevisionResponse {
+	return RevisionResponse{
+		Revision:  revision.Revision,
+		Title:     revision.Title,
+		Body:      &revision.Body,
+		Summary:   revision.Summary,
+		EditedBy:  userIDString(revision.EditedBy),
+		CreatedAt: revision.CreatedAt.Time,
+	}
+}
+
+func (r Request) ToInput() Input {
+	visibility := WikiVisibilityUnit
+	if r.Visibility != "" {
+		visibility = WikiVisibility(r.Visibility)
+	}
+
+	return Input{
+		Title:      strings.TrimSpace(r.Title),
+		Body:       r.Body,
+		Visibility: visibility,
+		Summary:    strings.TrimSpace(r.Summary),
+	}
+}
+
+type Handler struct {
+	logger *zap.Logger
+	tracer trace.Tracer
+
+	validator     *validator.Validate
+	problemWriter *problem.HttpWriter
+
+	store       Store
+	tenantStore tenantStore
+}
+
+func NewHandler(
+	logger *zap.Logger,
+	validator *validator.Validate,
+	problemWriter *problem.HttpWriter,
+	store Store,
+	tenantStore tenantStore,
+) *Handler {
+	return &Handler{
+		logger:        logger,
+		tracer:        otel.Tracer("wiki/handler"),
+		validator:     validator,
+		problemWriter: problemWriter,
+		store:         store,
+		tenantStore:   tenantStore,
+	}
+}
+
+// target is the wiki a request is about: the organization of the slug and the unit in
+// the path, with the current user
+type target struct {
+	orgID  uuid.UUID
+	unitID uuid.UUID
+	userID uuid.UUID
+}
+
+func (h *Handler) target(ctx context.Context, r *http.Request) (target, error) {
+	currentUser, ok := user.GetFromContext(ctx)
+	if !ok {
+		return target{}, internal.ErrNoUserInContext
+	}
+
+	slug, err := internal.GetSlugFromContext(ctx)
+	if err != nil {
+		return target{}, fmt.Errorf("failed to get org slug from context: %w", err)
+	}
+
+	_, orgID, err := h.tenantStore.GetSlugStatus(ctx, slug)
+	if err != nil {
+		return target{}, fmt.Errorf("failed to get org ID by slug: %w", err)
+	}
+
+	unitID, err := internal.ParseUUID(r.PathValue("id"))
+	if err != nil {
+		return target{}, err
+	}
+
+	return target{orgID: orgID, unitID: unitID, userID: currentUser.ID}, nil
+}
+
+func (h *Handler) CreateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "CreateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req CreateRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	input := req.ToInput()
+	input.Slug = req.Slug
+
+	page, err := h.store.Create(traceCtx, t.orgID, t.unitID, input, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusCreated, ToResponse(page))
+}
+
+func (h *Handler) ListHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	pages, err := h.store.List(traceCtx, t.orgID, t.unitID, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]SummaryResponse, len(pages))
+	for i, page := range pages {
+		response[i] = ToSummaryResponse(page)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	page, err := h.store.Get(traceCtx, t.orgID, t.unitID, r.PathValue("page"), t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(page))
+}
+
+func (h *Handler) UpdateHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "UpdateHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	var req UpdateRequest
+	err = internal.ParseAndValidateRequestBody(traceCtx, h.validator, r, &req)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	page, err := h.store.Update(traceCtx, t.orgID, t.unitID, r.PathValue("page"), UpdateInput{
+		Input:        req.ToInput(),
+		BaseRevision: req.Revision,
+	}, t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToResponse(page))
+}
+
+func (h *Handler) DeleteHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "DeleteHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	err = h.store.Delete(traceCtx, t.orgID, t.unitID, r.PathValue("page"), t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusNoContent, nil)
+}
+
+func (h *Handler) ListRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "ListRevisionsHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	revisions, err := h.store.ListRevisions(traceCtx, t.orgID, t.unitID, r.PathValue("page"), t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	response := make([]RevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		response[i] = ToRevisionSummaryResponse(revision)
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+func (h *Handler) GetRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	traceCtx, span := h.tracer.Start(r.Context(), "GetRevisionHandler")
+	defer span.End()
+	logger := logutil.WithContext(traceCtx, h.logger)
+
+	t, err := h.target(traceCtx, r)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	revision, err := strconv.ParseInt(r.PathValue("revision"), 10, 32)
+	if err != nil || revision < 1 {
+		h.problemWriter.WriteError(traceCtx, w, internal.ErrWikiRevisionNotFound, logger)
+		return
+	}
+
+	pageRevision, err := h.store.GetRevision(traceCtx, t.orgID, t.unitID, r.PathValue("page"), int32(revision), t.userID)
+	if err != nil {
+		h.problemWriter.WriteError(traceCtx, w, err, logger)
+		return
+	}
+
+	handlerutil.WriteJSONResponse(w, http.StatusOK, ToRevisionResponse(pageRevision))
+}
@@ -0,0 +1,58 @@
+-- name: GetAccess :one
+SELECT EXISTS(
+           SELECT 1 FROM unit_members
+           WHERE unit_id = u.id AND member_id = @user_id
+       ) AS unit_member,
+       EXISTS(
+           SELECT 1 FROM unit_members um
+           JOIN units ou ON ou.id = um.unit_id
+           WHERE um.member_id = @user_id
+             AND (ou.id = @org_id OR ou.org_id = @org_id)
+       ) AS org_member
+FROM units u
+WHERE u.id = @unit_id AND (u.id = @org_id OR u.org_id = @org_id);
+
+-- name: Create :one
+INSERT INTO wiki_pages (unit_id, slug, title, body, visibility, created_by, updated_by)
+VALUES (@unit_id, @slug, @title, @body, @visibility, @created_by, @created_by)
+RETURNING *;
+
+-- name: GetBySlug :one
+SELECT * FROM wiki_pages
+WHERE unit_id = @unit_id AND slug = @slug;
+
+-- name: ListByUnit :many
+SELECT id, slug, title, visibility, revision, updated_by, updated_at
+FROM wiki_pages
+WHERE unit_id = @unit_id
+  AND (visibility = 'organization' OR @include_unit::boolean)
+ORDER BY title, slug;
+
+-- name: Update :one
+UPDATE wiki_pages
+SET title = @title,
+    body = @body,
+    visibility = @visibility,
+    revision = revision + 1,
+    updated_by = @updated_by,
+    updated_at = now()
+WHERE unit_id = @unit_id AND slug = @slug AND revision = @base_revision
+RETURNING *;
+
+-- name: Delete :execrows
+DELETE FROM wiki_pages
+WHERE unit_id = @unit_id AND slug = @slug;
+
+-- name: CreateRevision :exec
+INSERT INTO wiki_page_revisions (page_id, revision, title, body, summary, edited_by)
+VALUES (@page_id, @revision, @title, @body, @summary, @edited_by);
+
+-- name: ListRevisions :many
+SELECT id, revision, title, summary, edited_by, created_at
+FROM wiki_page_revisions
+WHERE page_id = @page_id
+ORDER BY revision DESC;
+
+-- name: GetRevision :one
+SELECT * FROM wiki_page_revisions
+WHERE page_id = @page_id AND revision = @revision;
\ No newline at end of file
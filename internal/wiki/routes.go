@@ -0,0 +1,16 @@
+package wiki
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+)
+
+// Routes declares the wiki pages of a unit and their history
+func Routes(r route.Router, h *Handler) {
+	r.Handle("GET /orgs/{slug}/units/{id}/wiki", route.TenantAuthenticated, route.PermissionNone, h.ListHandler)
+	r.Handle("POST /orgs/{slug}/units/{id}/wiki", route.TenantAuthenticated, route.PermissionUnitMember, h.CreateHandler)
+	r.Handle("GET /orgs/{slug}/units/{id}/wiki/{page}", route.TenantAuthenticated, route.PermissionNone, h.GetHandler)
+	r.Handle("PUT /orgs/{slug}/units/{id}/wiki/{page}", route.TenantAuthenticated, route.PermissionUnitMember, h.UpdateHandler)
+	r.Handle("DELETE /orgs/{slug}/units/{id}/wiki/{page}", route.TenantAuthenticated, route.PermissionUnitMember, h.DeleteHandler)
+	r.Handle("GET /orgs/{slug}/units/{id}/wiki/{page}/revisions", route.TenantAuthenticated, route.PermissionNone, h.ListRevisionsHandler)
+	r.Handle("GET /orgs/{slug}/units/{id}/wiki/{page}/revisions/{revision}", route.TenantAuthenticated, route.PermissionNone, h.GetRevisionHandler)
+}
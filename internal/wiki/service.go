@@ -0,0 +1,391 @@
+package wiki
+
+import (
+	"NYCU-SDC/core-system-backend/internal"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	databaseutil "github.com/NYCU-SDC/summer/pkg/database"
+	logutil "github.com/NYCU-SDC/summer/pkg/log"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// slugPattern is the form of the slugs pages are addressed by, such as "onboarding-guide"
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type Querier interface {
+	GetAccess(ctx context.Context, arg GetAccessParams) (GetAccessRow, error)
+	Create(ctx context.Context, arg CreateParams) (WikiPage, error)
+	GetBySlug(ctx context.Context, arg GetBySlugParams) (WikiPage, error)
+	ListByUnit(ctx context.Context, arg ListByUnitParams) ([]ListByUnitRow, error)
+	Update(ctx context.Context, arg UpdateParams) (WikiPage, error)
+	Delete(ctx context.Context, arg DeleteParams) (int64, error)
+	CreateRevision(ctx context.Context, arg CreateRevisionParams) error
+	ListRevisions(ctx context.Context, pageID uuid.UUID) ([]ListRevisionsRow, error)
+	GetRevision(ctx context.Context, arg GetRevisionParams) (WikiPageRevision, error)
+}
+
+// DB is the connection the service runs on; a page and its revision are written in one
+// transaction begun on it
+type DB interface {
+	DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Input is the content of a page. Summary describes the edit in the revision history.
+type Input struct {
+	Slug       string
+	Title      string
+	Body       string
+	Visibility WikiVisibility
+	Summary    string
+}
+
+// UpdateInput is an edit of a page. BaseRevision is the revision the edit was made on;
+// the edit is refused when the page has changed since.
+type UpdateInput struct {
+	Input
+	BaseRevision int32
+}
+
+// access is what a user may do with the wiki of a unit
+type access struct {
+	unitMember bool
+	orgMember  bool
+}
+
+func (a access) canRead(visibility WikiVisibility) bool {
+	return a.unitMember || (a.orgMember && visibility == WikiVisibilityOrganization)
+}
+
+type Service struct {
+	logger  *zap.Logger
+	db      DB
+	queries Querier
+	tracer  trace.Tracer
+}
+
+func NewService(logger *zap.Logger, db DB) *Service {
+	return &Service{
+		logger:  logger,
+		db:      db,
+		queries: New(db),
+		tracer:  otel.Tracer("wiki/service"),
+	}
+}
+
+// inTx runs fn on queries bound to a new transaction, committed when fn succeeds
+func (s *Service) inTx(ctx context.Context, logger *zap.Logger, fn func(queries Querier) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "begin transaction")
+	}
+	defer func() {
+		_ = tx.Rollback(context.WithoutCancel(ctx))
+	}()
+
+	err = fn(New(tx))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return databaseutil.WrapDBError(err, logger, "commit transaction")
+	}
+
+	return nil
+}
+
+// access looks up the membership of the user in the unit and its organization. A unit
+// outside the organization is not found.
+func (s *Service) access(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID) (access, error) {
+	row, err := s.queries.GetAccess(ctx, GetAccessParams{
+		UserID: userID,
+		OrgID:  orgID,
+		UnitID: unitID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return access{}, internal.ErrUnitNotFound
+		}
+		return access{}, databaseutil.WrapDBErrorWithKeyValue(err, "unit_members", "unit_id", unitID.String(), logger, "check wiki access")
+	}
+	return access{unitMember: row.UnitMember, orgMember: row.OrgMember}, nil
+}
+
+// requireEditor allows the members of the unit only
+func (s *Service) requireEditor(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID) error {
+	a, err := s.access(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		return err
+	}
+	if !a.unitMember {
+		return fmt.Errorf("%w: only members of unit %s can edit its wiki", internal.ErrPermissionDenied, unitID)
+	}
+	return nil
+}
+
+// readable returns the page if the user may read it. A page hidden from the user is
+// not found, so its slug does not leak.
+func (s *Service) readable(ctx context.Context, logger *zap.Logger, orgID uuid.UUID, unitID uuid.UUID, slug string, userID uuid.UUID) (WikiPage, error) {
+	a, err := s.access(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		return WikiPage{}, err
+	}
+	if !a.unitMember && !a.orgMember {
+		return WikiPage{}, fmt.Errorf("%w: user is not a member of the organization", internal.ErrPermissionDenied)
+	}
+
+	page, err := s.queries.GetBySlug(ctx, GetBySlugParams{UnitID: unitID, Slug: slug})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WikiPage{}, internal.ErrWikiPageNotFound
+		}
+		return WikiPage{}, databaseutil.WrapDBErrorWithKeyValue(err, "wiki_pages", "slug", slug, logger, "get wiki page")
+	}
+	if !a.canRead(page.Visibility) {
+		return WikiPage{}, internal.ErrWikiPageNotFound
+	}
+	return page, nil
+}
+
+// Create adds a page to the wiki of the unit, with its content as the first revision
+func (s *Service) Create(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, input Input, userID uuid.UUID) (WikiPage, error) {
+	ctx, span := s.tracer.Start(ctx, "Create")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	if !slugPattern.MatchString(input.Slug) {
+		err := fmt.Errorf("%w: %q must be lowercase letters and digits separated by hyphens", internal.ErrWikiPageSlugInvalid, input.Slug)
+		span.RecordError(err)
+		return WikiPage{}, err
+	}
+
+	err := s.requireEditor(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return WikiPage{}, err
+	}
+
+	var page WikiPage
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		page, err = queries.Create(ctx, CreateParams{
+			UnitID:     unitID,
+			Slug:       input.Slug,
+			Title:      input.Title,
+			Body:       input.Body,
+			Visibility: input.Visibility,
+			CreatedBy:  pgtype.UUID{Bytes: userID, Valid: true},
+		})
+		if err != nil {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "wiki_pages", "slug", input.Slug, logger, "create wiki page")
+			if errors.Is(err, databaseutil.ErrUniqueViolation) {
+				err = internal.ErrWikiPageSlugTaken
+			}
+			return err
+		}
+
+		return s.addRevision(ctx, logger, queries, page, input.Summary, userID)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return WikiPage{}, err
+	}
+
+	logger.Info("Created wiki page", zap.String("page_id", page.ID.String()), zap.String("unit_id", unitID.String()), zap.String("slug", page.Slug))
+
+	return page, nil
+}
+
+// List returns the pages of the unit the user may read, by title, without their body
+func (s *Service) List(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, userID uuid.UUID) ([]ListByUnitRow, error) {
+	ctx, span := s.tracer.Start(ctx, "List")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	a, err := s.access(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if !a.unitMember && !a.orgMember {
+		err = fmt.Errorf("%w: user is not a member of the organization", internal.ErrPermissionDenied)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	pages, err := s.queries.ListByUnit(ctx, ListByUnitParams{UnitID: unitID, IncludeUnit: a.unitMember})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "wiki_pages", "unit_id", unitID.String(), logger, "list wiki pages")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, userID uuid.UUID) (WikiPage, error) {
+	ctx, span := s.tracer.Start(ctx, "Get")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	page, err := s.readable(ctx, logger, orgID, unitID, slug, userID)
+	if err != nil {
+		span.RecordError(err)
+		return WikiPage{}, err
+	}
+
+	return page, nil
+}
+
+// Update replaces the content of a page and records it as a new revision. Two members
+// editing the same revision do not overwrite each other: the second one is refused.
+func (s *Service) Update(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, input UpdateInput, userID uuid.UUID) (WikiPage, error) {
+	ctx, span := s.tracer.Start(ctx, "Update")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireEditor(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return WikiPage{}, err
+	}
+
+	var page WikiPage
+	err = s.inTx(ctx, logger, func(queries Querier) error {
+		page, err = queries.Update(ctx, UpdateParams{
+			Title:        input.Title,
+			Body:         input.Body,
+			Visibility:   input.Visibility,
+			UpdatedBy:    pgtype.UUID{Bytes: userID, Valid: true},
+			UnitID:       unitID,
+			Slug:         slug,
+			BaseRevision: input.BaseRevision,
+		})
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return databaseutil.WrapDBErrorWithKeyValue(err, "wiki_pages", "slug", slug, logger, "update wiki page")
+			}
+
+			current, err := queries.GetBySlug(ctx, GetBySlugParams{UnitID: unitID, Slug: slug})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return internal.ErrWikiPageNotFound
+				}
+				return databaseutil.WrapDBErrorWithKeyValue(err, "wiki_pages", "slug", slug, logger, "get wiki page")
+			}
+			return fmt.Errorf("%w: edited revision %d, page is at revision %d", internal.ErrWikiRevisionConflict, input.BaseRevision, current.Revision)
+		}
+
+		return s.addRevision(ctx, logger, queries, page, input.Summary, userID)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return WikiPage{}, err
+	}
+
+	logger.Info("Updated wiki page", zap.String("page_id", page.ID.String()), zap.Int32("revision", page.Revision))
+
+	return page, nil
+}
+
+// Delete removes a page together with its history
+func (s *Service) Delete(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, userID uuid.UUID) error {
+	ctx, span := s.tracer.Start(ctx, "Delete")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	err := s.requireEditor(ctx, logger, orgID, unitID, userID)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	rows, err := s.queries.Delete(ctx, DeleteParams{UnitID: unitID, Slug: slug})
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "wiki_pages", "slug", slug, logger, "delete wiki page")
+		span.RecordError(err)
+		return err
+	}
+	if rows == 0 {
+		err = internal.ErrWikiPageNotFound
+		span.RecordError(err)
+		return err
+	}
+
+	logger.Info("Deleted wiki page", zap.String("unit_id", unitID.String()), zap.String("slug", slug))
+
+	return nil
+}
+
+// ListRevisions returns the history of a page, latest first, without the content of
+// each revision
+func (s *Service) ListRevisions(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, userID uuid.UUID) ([]ListRevisionsRow, error) {
+	ctx, span := s.tracer.Start(ctx, "ListRevisions")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	page, err := s.readable(ctx, logger, orgID, unitID, slug, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	revisions, err := s.queries.ListRevisions(ctx, page.ID)
+	if err != nil {
+		err = databaseutil.WrapDBErrorWithKeyValue(err, "wiki_page_revisions", "page_id", page.ID.String(), logger, "list wiki page revisions")
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (s *Service) GetRevision(ctx context.Context, orgID uuid.UUID, unitID uuid.UUID, slug string, revision int32, userID uuid.UUID) (WikiPageRevision, error) {
+	ctx, span := s.tracer.Start(ctx, "GetRevision")
+	defer span.End()
+	logger := logutil.WithContext(ctx, s.logger)
+
+	page, err := s.readable(ctx, logger, orgID, unitID, slug, userID)
+	if err != nil {
+		span.RecordError(err)
+		return WikiPageRevision{}, err
+	}
+
+	pageRevision, err := s.queries.GetRevision(ctx, GetRevisionParams{PageID: page.ID, Revision: revision})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = internal.ErrWikiRevisionNotFound
+		} else {
+			err = databaseutil.WrapDBErrorWithKeyValue(err, "wiki_page_revisions", "page_id", page.ID.String(), logger, "get wiki page revision")
+		}
+		span.RecordError(err)
+		return WikiPageRevision{}, err
+	}
+
+	return pageRevision, nil
+}
+
+// addRevision records the current content of the page in its history
+func (s *Service) addRevision(ctx context.Context, logger *zap.Logger, queries Querier, page WikiPage, summary string, userID uuid.UUID) error {
+	err := queries.CreateRevision(ctx, CreateRevisionParams{
+		PageID:   page.ID,
+		Revision: page.Revision,
+		Title:    page.Title,
+		Body:     page.Body,
+		Summary:  summary,
+		EditedBy: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return databaseutil.WrapDBErrorWithKeyValue(err, "wiki_page_revisions", "page_id", page.ID.String(), logger, "create wiki page revision")
+	}
+	return nil
+}
@@ -1095,7 +1095,7 @@ func TestInboxService_ListWithFilters(t *testing.T) {
 				ctx = tc.setup(t, &params, db, logger)
 			}
 
-			service := inbox.NewService(logger, db)
+			service := inbox.NewService(logger, db, nil)
 
 			result, err := service.List(ctx, params.userID, params.filter, 1, 200)
 			require.Equal(t, tc.expectedErr, err != nil, "expected error: %v, got: %v", tc.expectedErr, err)
@@ -201,7 +201,7 @@ func TestInboxService_Create(t *testing.T) {
 				ctx = tc.setup(t, &params, db, logger)
 			}
 
-			service := inbox.NewService(logger, db)
+			service := inbox.NewService(logger, db, nil)
 
 			result, err := service.Create(ctx, params.contentType, params.contentID, params.recipients, params.unitID)
 			require.Equal(t, tc.expectedErr, err != nil, "expected error: %v, got: %v", tc.expectedErr, err)
@@ -334,7 +334,7 @@ func TestInboxService_List(t *testing.T) {
 				ctx = tc.setup(t, &params, db, logger)
 			}
 
-			service := inbox.NewService(logger, db)
+			service := inbox.NewService(logger, db, nil)
 
 			result, err := service.List(ctx, params.userID, nil, 1, 10)
 			require.Equal(t, tc.expectedErr, err != nil, "expected error: %v, got: %v", tc.expectedErr, err)
@@ -549,7 +549,7 @@ func TestInboxService_UpdateByID(t *testing.T) {
 				ctx = tc.setup(t, &params, db, logger)
 			}
 
-			service := inbox.NewService(logger, db)
+			service := inbox.NewService(logger, db, nil)
 
 			result, err := service.UpdateByID(ctx, params.messageID, params.userID, params.expected)
 			require.Equal(t, tc.expectedErr, err != nil, "expected error: %v, got: %v", tc.expectedErr, err)
@@ -671,7 +671,7 @@ func TestInboxService_DuplicateCreatesProduceMultipleMessages(t *testing.T) {
 				ctx = tc.setup(t, &params, db, logger)
 			}
 
-			service := inbox.NewService(logger, db)
+			service := inbox.NewService(logger, db, nil)
 
 			// Create multiple messages with same content/recipient intentionally
 			results := make([]uuid.UUID, params.expected)
@@ -86,7 +86,7 @@ func TestInboxService_Count(t *testing.T) {
 			}
 
 			ctx := context.Background()
-			service := inbox.NewService(logger, db)
+			service := inbox.NewService(logger, db, nil)
 
 			// Test total count
 			total, err := service.Count(ctx, user.ID, nil)
@@ -193,7 +193,7 @@ func TestInboxService_ListWithPagination(t *testing.T) {
 			tc.params.expectedIDs = messageIDs
 
 			ctx := context.Background()
-			service := inbox.NewService(logger, db)
+			service := inbox.NewService(logger, db, nil)
 
 			// Test pagination for each test case
 			// pt: pagination testcase
@@ -0,0 +1,190 @@
+package inbox
+
+import (
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/test/integration"
+	"NYCU-SDC/core-system-backend/test/testdata"
+	formbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/form"
+	inboxbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/inbox"
+	unitbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/unit"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+// explainDB runs every query twice: once under EXPLAIN to keep its plan, then for real,
+// so the generated queries can be explained with their actual arguments
+type explainDB struct {
+	t     *testing.T
+	db    inbox.DBTX
+	plans []string
+}
+
+func (e *explainDB) explain(ctx context.Context, sql string, args ...interface{}) {
+	e.t.Helper()
+
+	var plan []byte
+	err := e.db.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+sql, args...).Scan(&plan)
+	require.NoError(e.t, err)
+	e.plans = append(e.plans, string(plan))
+}
+
+func (e *explainDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return e.db.Exec(ctx, sql, args...)
+}
+
+func (e *explainDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	e.explain(ctx, sql, args...)
+	return e.db.Query(ctx, sql, args...)
+}
+
+func (e *explainDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	e.explain(ctx, sql, args...)
+	return e.db.QueryRow(ctx, sql, args...)
+}
+
+// indexesOf returns the names of the indexes scanned anywhere in a JSON plan
+func indexesOf(t *testing.T, plan string) []string {
+	var root []struct {
+		Plan map[string]any `json:"Plan"`
+	}
+	err := json.Unmarshal([]byte(plan), &root)
+	require.NoError(t, err)
+	require.Len(t, root, 1)
+
+	var indexes []string
+	var walk func(node map[string]any)
+	walk = func(node map[string]any) {
+		name, ok := node["Index Name"].(string)
+		if ok {
+			indexes = append(indexes, name)
+		}
+		children, _ := node["Plans"].([]any)
+		for _, child := range children {
+			childNode, ok := child.(map[string]any)
+			if ok {
+				walk(childNode)
+			}
+		}
+	}
+	walk(root[0].Plan)
+
+	return indexes
+}
+
+// TestInboxQueries_Plans documents the plans expected for the personal inbox list and its
+// count. Both start from the messages of the user through idx_user_inbox_messages_user_id
+// (user_id, is_archived, message_id) and reach the message, the form and the units by
+// primary key, so the cost follows the size of one inbox, not of the table.
+//
+// Sequential scans are disabled for the test, the seeded tables being small enough
+// for the planner to prefer reading them whole.
+func TestInboxQueries_Plans(t *testing.T) {
+	resourceManager, _, err := integration.GetOrInitResource()
+	if err != nil {
+		t.Fatalf("failed to get resource manager: %v", err)
+	}
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	if err != nil {
+		t.Fatalf("failed to setup postgres: %v", err)
+	}
+	defer rollback()
+
+	unitBuilder := unitbuilder.New(t, db)
+	userBuilder := userbuilder.New(t, db)
+	formBuilder := formbuilder.New(t, db)
+	inboxBuilder := inboxbuilder.New(t, db)
+
+	org := unitBuilder.Create(unit.UnitTypeOrganization, unitbuilder.WithName("plan-org"))
+	unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID), unitbuilder.WithName("plan-unit"))
+	users := []uuid.UUID{userBuilder.Create().ID, userBuilder.Create().ID}
+	for i := 0; i < 20; i++ {
+		form := formBuilder.Create(formbuilder.WithUnitID(unitRow.ID), formbuilder.WithLastEditor(users[0]))
+		message := inboxBuilder.CreateMessage(inbox.ContentTypeForm, form.ID, unitRow.ID)
+		inboxBuilder.CreateUserInboxBulk(users, message.ID)
+	}
+
+	ctx := context.Background()
+	_, err = db.Exec(ctx, "SET LOCAL enable_seqscan = off")
+	require.NoError(t, err)
+
+	explain := &explainDB{t: t, db: db}
+	queries := inbox.New(explain)
+
+	_, err = queries.List(ctx, inbox.ListParams{
+		UserID:     users[0],
+		IsStarred:  pgtype.Bool{Bool: true, Valid: true},
+		TagIds:     []uuid.UUID{},
+		PageLimit:  10,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+
+	_, err = queries.ListCount(ctx, inbox.ListCountParams{
+		UserID: users[0],
+		TagIds: []uuid.UUID{},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, explain.plans, 2)
+	for i, name := range []string{"List", "ListCount"} {
+		require.Contains(t, indexesOf(t, explain.plans[i]), "idx_user_inbox_messages_user_id", "%s does not use the inbox index:\n%s", name, explain.plans[i])
+	}
+}
+
+// TestInboxService_ListWithCount checks that the batched list returns what List and
+// Count return on their own
+func TestInboxService_ListWithCount(t *testing.T) {
+	resourceManager, logger, err := integration.GetOrInitResource()
+	if err != nil {
+		t.Fatalf("failed to get resource manager: %v", err)
+	}
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	if err != nil {
+		t.Fatalf("failed to setup postgres: %v", err)
+	}
+	defer rollback()
+
+	unitBuilder := unitbuilder.New(t, db)
+	userBuilder := userbuilder.New(t, db)
+	formBuilder := formbuilder.New(t, db)
+	inboxBuilder := inboxbuilder.New(t, db)
+
+	org := unitBuilder.Create(unit.UnitTypeOrganization, unitbuilder.WithName("batch-org"))
+	unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID), unitbuilder.WithName("batch-unit"))
+	user := userBuilder.Create()
+	email := testdata.RandomEmail()
+	userBuilder.CreateEmail(user.ID, email)
+	unitBuilder.AddMember(unitRow.ID, email)
+
+	for i := 0; i < 15; i++ {
+		form := formBuilder.Create(formbuilder.WithUnitID(unitRow.ID), formbuilder.WithLastEditor(user.ID))
+		message := inboxBuilder.CreateMessage(inbox.ContentTypeForm, form.ID, unitRow.ID)
+		inboxBuilder.CreateUserInboxMessage(user.ID, message.ID)
+	}
+
+	ctx := context.Background()
+	service := inbox.NewService(logger, db, nil)
+
+	for _, page := range []int{1, 2} {
+		expectedMessages, err := service.List(ctx, user.ID, nil, page, 10)
+		require.NoError(t, err)
+		expectedTotal, err := service.Count(ctx, user.ID, nil)
+		require.NoError(t, err)
+
+		messages, total, err := service.ListWithCount(ctx, user.ID, nil, page, 10)
+		require.NoError(t, err)
+		require.Equal(t, expectedTotal, total)
+		require.Equal(t, expectedMessages, messages)
+	}
+}
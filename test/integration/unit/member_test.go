@@ -215,7 +215,7 @@ func TestUnitService_AddMember(t *testing.T) {
 			results := make([]unit.AddMemberRow, 0, len(memberEmails))
 			var encounteredErr error
 			for _, memberEmail := range memberEmails {
-				result, err := service.AddMember(ctx, params.unitType, params.unitID, memberEmail)
+				result, err := service.AddMember(ctx, params.unitType, params.unitID, memberEmail, nil)
 				results = append(results, result)
 				if err != nil {
 					encounteredErr = err
@@ -0,0 +1,72 @@
+package user
+
+import (
+	"NYCU-SDC/core-system-backend/internal/route"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"NYCU-SDC/core-system-backend/test/integration"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	"NYCU-SDC/core-system-backend/test/testdata/golden"
+	"NYCU-SDC/core-system-backend/test/testdata/server"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserHandler_GetMe(t *testing.T) {
+	testCases := []struct {
+		name           string
+		authenticated  bool
+		expectedStatus int
+		golden         string
+	}{
+		{
+			name:           "Authenticated user gets their profile",
+			authenticated:  true,
+			expectedStatus: http.StatusOK,
+			golden:         "get_me",
+		},
+		{
+			name:           "Request without a token is rejected",
+			authenticated:  false,
+			expectedStatus: http.StatusUnauthorized,
+			golden:         "get_me_unauthenticated",
+		},
+	}
+
+	resourceManager, logger, err := integration.GetOrInitResource()
+	if err != nil {
+		t.Fatalf("failed to get resource manager: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			require.NoError(t, err)
+			defer rollback()
+
+			userBuilder := userbuilder.New(t, db)
+			currentUser := userBuilder.Create(
+				userbuilder.WithName("Golden User"),
+				userbuilder.WithUsername("golden"),
+				userbuilder.WithAvatarURL("https://example.com/avatar.png"),
+				userbuilder.WithIsOnboarded(true),
+			)
+			userBuilder.CreateEmail(currentUser.ID, "golden@example.com")
+
+			srv := server.New(t, logger, db)
+			handler := user.NewHandler(logger, srv.Validator, srv.ProblemWriter, user.NewService(logger, db))
+			srv.Handle("GET /api/users/me", route.Authenticated, route.PermissionSelf, handler.GetMe)
+
+			req := server.NewRequest(t, http.MethodGet, "/api/users/me", nil)
+			if tc.authenticated {
+				req = srv.AsUser(req, currentUser)
+			}
+			rec := srv.Do(req)
+
+			require.Equal(t, tc.expectedStatus, rec.Code)
+			require.NotEmpty(t, rec.Header().Get("X-Request-Id"))
+			golden.AssertJSON(t, tc.golden, rec.Body.Bytes(), "id", "requestId")
+		})
+	}
+}
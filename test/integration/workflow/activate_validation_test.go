@@ -4,6 +4,7 @@ import (
 	"NYCU-SDC/core-system-backend/internal"
 	"NYCU-SDC/core-system-backend/internal/form/question"
 	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/secrets"
 	"NYCU-SDC/core-system-backend/test/integration"
 	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
 	workflowbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/workflow"
@@ -329,7 +330,7 @@ func TestWorkflowService_ActivateValidation(t *testing.T) {
 			questionService := question.NewService(logger, db)
 
 			// Create workflow service with real dependencies
-			workflowService := workflow.NewService(logger, db, questionService)
+			workflowService := workflow.NewService(logger, db, questionService, secrets.NewDevKeyring("test-secret"))
 
 			// Call service.Activate which runs validation
 			result, err := workflowService.Activate(ctx, params.formID, params.userID, params.workflowJSON)
@@ -6,6 +6,7 @@ import (
 
 	"NYCU-SDC/core-system-backend/internal/form/question"
 	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/secrets"
 	"NYCU-SDC/core-system-backend/test/integration"
 	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
 	workflowbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/workflow"
@@ -296,7 +297,7 @@ func TestWorkflowService_GetValidationInfo(t *testing.T) {
 			questionService := question.NewService(logger, db)
 
 			// Create workflow service with real dependencies
-			workflowService := workflow.NewService(logger, db, questionService)
+			workflowService := workflow.NewService(logger, db, questionService, secrets.NewDevKeyring("test-secret"))
 
 			// Call GetValidationInfo which returns ValidationInfo array
 			validationInfos, err := workflowService.GetValidationInfo(ctx, params.formID, params.workflowJSON)
@@ -0,0 +1,56 @@
+//go:build load
+
+package inbox
+
+import (
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/test/load"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// BenchmarkList lists the first page of inboxes of growing size, unfiltered and with the
+// filters of the inbox tabs
+func BenchmarkList(b *testing.B) {
+	resourceManager, logger, err := load.GetOrInitResource()
+	require.NoError(b, err)
+
+	unread := false
+	starred := true
+	filters := []struct {
+		name   string
+		filter *inbox.FilterRequest
+	}{
+		{name: "all", filter: nil},
+		{name: "unread", filter: &inbox.FilterRequest{IsRead: &unread}},
+		{name: "starred", filter: &inbox.FilterRequest{IsStarred: &starred}},
+	}
+
+	for _, size := range []int{100, 1000, 5000} {
+		db, rollback, err := resourceManager.SetupPostgres()
+		require.NoError(b, err)
+
+		service := inbox.NewService(logger, db, nil)
+		org := load.SeedOrg(b, db)
+		recipient := load.SeedUsers(b, db, 1)[0]
+		load.SeedInbox(b, db, org, recipient, size)
+
+		for _, f := range filters {
+			b.Run(fmt.Sprintf("messages=%d/filter=%s", size, f.name), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_, err := service.List(context.Background(), recipient, f.filter, 1, 20)
+					if err != nil {
+						b.Fatalf("list failed: %v", err)
+					}
+				}
+			})
+		}
+
+		rollback()
+	}
+}
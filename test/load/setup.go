@@ -0,0 +1,150 @@
+//go:build load
+
+// Package load holds the benchmarks of the hot paths, run against a real PostgreSQL
+// container and excluded from the regular test run by the load build tag. See the bench
+// targets of the Makefile.
+package load
+
+import (
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/unit"
+	"NYCU-SDC/core-system-backend/test/testdata/dbbuilder"
+	formbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/form"
+	inboxbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/inbox"
+	unitbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/unit"
+	userbuilder "NYCU-SDC/core-system-backend/test/testdata/dbbuilder/user"
+	"NYCU-SDC/core-system-backend/test/testdata/setup"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var resourceManager *setup.ResourceManager
+
+// GetOrInitResource starts the PostgreSQL container once for all benchmarks of a package.
+// The benchmarks log at warn level, so the output stays readable by benchstat.
+func GetOrInitResource() (*setup.ResourceManager, *zap.Logger, error) {
+	logger, err := setup.NewTestLogger()
+	if err != nil {
+		return nil, nil, err
+	}
+	logger = logger.WithOptions(zap.IncreaseLevel(zap.WarnLevel))
+
+	if resourceManager != nil {
+		return resourceManager, logger, nil
+	}
+
+	resourceManager, err = setup.NewResourceManager(logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resourceManager, logger, nil
+}
+
+// Org is an organization with one unit, the owner of the seeded forms
+type Org struct {
+	OrgID  uuid.UUID
+	UnitID uuid.UUID
+	Editor uuid.UUID
+}
+
+func SeedOrg(tb testing.TB, db dbbuilder.DBTX) Org {
+	unitBuilder := unitbuilder.New(tb, db)
+	userBuilder := userbuilder.New(tb, db)
+
+	org := unitBuilder.Create(unit.UnitTypeOrganization)
+	unitRow := unitBuilder.Create(unit.UnitTypeUnit, unitbuilder.WithOrgID(org.ID))
+	editor := userBuilder.Create(userbuilder.WithIsOnboarded(true))
+
+	return Org{OrgID: org.ID, UnitID: unitRow.ID, Editor: editor.ID}
+}
+
+// SeedUsers creates count onboarded users, the respondents of a benchmark
+func SeedUsers(tb testing.TB, db dbbuilder.DBTX, count int) []uuid.UUID {
+	userBuilder := userbuilder.New(tb, db)
+
+	ids := make([]uuid.UUID, count)
+	for i := range ids {
+		ids[i] = userBuilder.Create(userbuilder.WithIsOnboarded(true)).ID
+	}
+	return ids
+}
+
+// SeedForm imports a form of sections sections, each holding questionsPerSection short
+// text questions, and returns it with the IDs of its questions in order
+func SeedForm(tb testing.TB, service *importer.Service, org Org, sections int, questionsPerSection int) (uuid.UUID, []uuid.UUID) {
+	tb.Helper()
+
+	startID, endID := uuid.New(), uuid.New()
+	document := importer.Document{
+		Version: importer.DocumentVersion,
+		Form:    importer.DocumentForm{Title: "Load test"},
+	}
+
+	nodes := []map[string]any{{"id": startID.String(), "type": string(workflow.NodeTypeStart), "label": "Start"}}
+	for i := 0; i < sections; i++ {
+		section := importer.DocumentSection{ID: uuid.New(), Title: fmt.Sprintf("Section %d", i+1)}
+		for j := 0; j < questionsPerSection; j++ {
+			section.Questions = append(section.Questions, importer.DocumentQuestion{
+				ID:       uuid.New(),
+				Type:     string(question.QuestionTypeShortText),
+				Title:    fmt.Sprintf("Question %d", j+1),
+				Required: true,
+				Order:    int32(j + 1),
+			})
+		}
+		document.Sections = append(document.Sections, section)
+
+		nodes[len(nodes)-1]["next"] = section.ID.String()
+		nodes = append(nodes, map[string]any{"id": section.ID.String(), "type": string(workflow.NodeTypeSection), "label": section.Title})
+	}
+	nodes[len(nodes)-1]["next"] = endID.String()
+	nodes = append(nodes, map[string]any{"id": endID.String(), "type": string(workflow.NodeTypeEnd), "label": "End"})
+
+	graph, err := json.Marshal(nodes)
+	if err != nil {
+		tb.Fatalf("failed to encode workflow: %v", err)
+	}
+	document.Workflow = graph
+
+	result, err := service.ImportDocument(tb.Context(), org.OrgID, org.UnitID, org.Editor, document)
+	if err != nil {
+		tb.Fatalf("failed to import form: %v", err)
+	}
+
+	return result.Form.ID, questionIDs(tb, service, result.Form.ID)
+}
+
+func questionIDs(tb testing.TB, service *importer.Service, formID uuid.UUID) []uuid.UUID {
+	document, err := service.Export(tb.Context(), formID)
+	if err != nil {
+		tb.Fatalf("failed to export form: %v", err)
+	}
+
+	var ids []uuid.UUID
+	for _, section := range document.Sections {
+		for _, q := range section.Questions {
+			ids = append(ids, q.ID)
+		}
+	}
+	return ids
+}
+
+// SeedInbox posts count form messages of the unit of org to the inbox of userID
+func SeedInbox(tb testing.TB, db dbbuilder.DBTX, org Org, userID uuid.UUID, count int) {
+	formBuilder := formbuilder.New(tb, db)
+	inboxBuilder := inboxbuilder.New(tb, db)
+
+	for i := 0; i < count; i++ {
+		formRow := formBuilder.Create(formbuilder.WithUnitID(org.UnitID), formbuilder.WithLastEditor(org.Editor))
+		message := inboxBuilder.CreateMessage(inbox.ContentTypeForm, formRow.ID, org.UnitID)
+		inboxBuilder.CreateUserInboxMessage(userID, message.ID)
+	}
+}
@@ -0,0 +1,128 @@
+//go:build load
+
+package submit
+
+import (
+	"NYCU-SDC/core-system-backend/internal/attendance"
+	"NYCU-SDC/core-system-backend/internal/event"
+	"NYCU-SDC/core-system-backend/internal/form"
+	"NYCU-SDC/core-system-backend/internal/form/action"
+	"NYCU-SDC/core-system-backend/internal/form/approval"
+	"NYCU-SDC/core-system-backend/internal/form/assignment"
+	"NYCU-SDC/core-system-backend/internal/form/attempt"
+	"NYCU-SDC/core-system-backend/internal/form/ballot"
+	"NYCU-SDC/core-system-backend/internal/form/eligibility"
+	"NYCU-SDC/core-system-backend/internal/form/importer"
+	"NYCU-SDC/core-system-backend/internal/form/question"
+	"NYCU-SDC/core-system-backend/internal/form/response"
+	"NYCU-SDC/core-system-backend/internal/form/shared"
+	"NYCU-SDC/core-system-backend/internal/form/submit"
+	"NYCU-SDC/core-system-backend/internal/form/workflow"
+	"NYCU-SDC/core-system-backend/internal/inbox"
+	"NYCU-SDC/core-system-backend/internal/secrets"
+	"NYCU-SDC/core-system-backend/internal/user"
+	"NYCU-SDC/core-system-backend/internal/webhook"
+	"NYCU-SDC/core-system-backend/test/load"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// BenchmarkSubmit submits a form of growing size, each iteration as a new respondent so
+// every submission takes the create path with all its checks and side effects
+func BenchmarkSubmit(b *testing.B) {
+	resourceManager, logger, err := load.GetOrInitResource()
+	require.NoError(b, err)
+
+	layouts := []struct {
+		sections            int
+		questionsPerSection int
+	}{
+		{sections: 1, questionsPerSection: 5},
+		{sections: 5, questionsPerSection: 10},
+		{sections: 10, questionsPerSection: 20},
+	}
+
+	for _, layout := range layouts {
+		b.Run(fmt.Sprintf("sections=%d/questions=%d", layout.sections, layout.sections*layout.questionsPerSection), func(b *testing.B) {
+			db, rollback, err := resourceManager.SetupPostgres()
+			require.NoError(b, err)
+			defer rollback()
+
+			questionService := question.NewService(logger, db)
+			responseService := response.NewService(logger, db)
+			formService := form.NewService(logger, db, responseService)
+			keyring := secrets.NewDevKeyring("test-secret")
+			workflowService := workflow.NewService(logger, db, questionService, keyring)
+			actionService := action.NewService(logger, db, workflowService, responseService, webhook.NewService(logger, db, keyring))
+			approvalService := approval.NewService(logger, db, workflowService, responseService, inbox.NewService(logger, db, nil), actionService)
+			eligibilityService := eligibility.NewService(logger, db, user.NewService(logger, db), nil)
+			importerService := importer.NewService(logger, db, formService, workflowService, questionService)
+			service := submit.NewService(logger, formService, questionService, responseService, eligibilityService, approvalService, actionService, attempt.NewService(logger, db), assignment.NewService(logger, db), ballot.NewService(logger, db, formService, questionService, eligibilityService), event.NewService(logger, db, attendance.NewService(logger, db)))
+
+			org := load.SeedOrg(b, db)
+			formID, questionIDs := load.SeedForm(b, importerService, org, layout.sections, layout.questionsPerSection)
+			answers := make([]shared.AnswerParam, len(questionIDs))
+			for i, id := range questionIDs {
+				answers[i] = shared.AnswerParam{QuestionID: id.String(), Value: fmt.Sprintf("answer %d", i+1)}
+			}
+			respondents := load.SeedUsers(b, db, b.N)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, errs := service.Submit(context.Background(), formID, respondents[i], answers)
+				if len(errs) > 0 {
+					b.Fatalf("submit failed: %v", errs)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkResubmit updates the answers of the same respondent, the path taken by a
+// respondent editing a submitted form
+func BenchmarkResubmit(b *testing.B) {
+	resourceManager, logger, err := load.GetOrInitResource()
+	require.NoError(b, err)
+
+	db, rollback, err := resourceManager.SetupPostgres()
+	require.NoError(b, err)
+	defer rollback()
+
+	questionService := question.NewService(logger, db)
+	responseService := response.NewService(logger, db)
+	formService := form.NewService(logger, db, responseService)
+	keyring := secrets.NewDevKeyring("test-secret")
+	workflowService := workflow.NewService(logger, db, questionService, keyring)
+	actionService := action.NewService(logger, db, workflowService, responseService, webhook.NewService(logger, db, keyring))
+	approvalService := approval.NewService(logger, db, workflowService, responseService, inbox.NewService(logger, db, nil), actionService)
+	eligibilityService := eligibility.NewService(logger, db, user.NewService(logger, db), nil)
+	importerService := importer.NewService(logger, db, formService, workflowService, questionService)
+	service := submit.NewService(logger, formService, questionService, responseService, eligibilityService, approvalService, actionService, attempt.NewService(logger, db), assignment.NewService(logger, db), ballot.NewService(logger, db, formService, questionService, eligibilityService), event.NewService(logger, db, attendance.NewService(logger, db)))
+
+	org := load.SeedOrg(b, db)
+	formID, questionIDs := load.SeedForm(b, importerService, org, 5, 10)
+	respondent := load.SeedUsers(b, db, 1)[0]
+
+	answers := make([]shared.AnswerParam, len(questionIDs))
+	submitWith := func(value string) {
+		for i, id := range questionIDs {
+			answers[i] = shared.AnswerParam{QuestionID: id.String(), Value: value}
+		}
+		_, errs := service.Submit(context.Background(), formID, respondent, answers)
+		if len(errs) > 0 {
+			b.Fatalf("submit failed: %v", errs)
+		}
+	}
+	submitWith(uuid.NewString())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		submitWith(fmt.Sprintf("revision %d", i))
+	}
+}
@@ -13,11 +13,11 @@ import (
 )
 
 type Builder struct {
-	t  *testing.T
+	t  testing.TB
 	db dbbuilder.DBTX
 }
 
-func New(t *testing.T, db dbbuilder.DBTX) *Builder {
+func New(t testing.TB, db dbbuilder.DBTX) *Builder {
 	return &Builder{t: t, db: db}
 }
 
@@ -21,11 +21,11 @@ type FactoryParams struct {
 }
 
 type Builder struct {
-	t  *testing.T
+	t  testing.TB
 	db dbbuilder.DBTX
 }
 
-func New(t *testing.T, db dbbuilder.DBTX) *Builder {
+func New(t testing.TB, db dbbuilder.DBTX) *Builder {
 	return &Builder{t: t, db: db}
 }
 
@@ -0,0 +1,77 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Placeholder replaces the values of volatile fields, such as generated IDs and times
+const Placeholder = "<volatile>"
+
+var update = flag.Bool("update", false, "rewrite the golden files with the actual responses")
+
+// AssertJSON compares body with testdata/<name>.golden.json in the directory of the test.
+// The values of the fields named in volatile are replaced with Placeholder at any depth
+// before comparing, so they may differ between runs. Run the test with -update to write
+// the golden file from the actual body.
+func AssertJSON(tb testing.TB, name string, body []byte, volatile ...string) {
+	tb.Helper()
+
+	var value any
+	err := json.Unmarshal(body, &value)
+	require.NoError(tb, err, "response body is not JSON: %s", body)
+
+	fields := make(map[string]bool, len(volatile))
+	for _, field := range volatile {
+		fields[field] = true
+	}
+	value = mask(value, fields)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(value)
+	require.NoError(tb, err)
+	actual := buf.Bytes()
+
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		require.NoError(tb, err)
+		err = os.WriteFile(path, actual, 0o644)
+		require.NoError(tb, err)
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(tb, err, "missing golden file, run the test with -update to create it")
+	require.Equal(tb, string(bytes.TrimSpace(expected)), string(bytes.TrimSpace(actual)), "response differs from %s", path)
+}
+
+func mask(value any, fields map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if fields[key] {
+				v[key] = Placeholder
+				continue
+			}
+			v[key] = mask(item, fields)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = mask(item, fields)
+		}
+		return v
+	default:
+		return v
+	}
+}